@@ -188,3 +188,57 @@ export async function queryStrategiesMetrics(): Promise<GridStrategy[]> {
 
   return testArr;
 }
+
+export interface StrategyInstance {
+  id: string;
+  strategy: string;
+  session?: string;
+  status: string;
+  canSuspend: boolean;
+  canRequote: boolean;
+}
+
+export async function queryStrategyInstances(): Promise<StrategyInstance[]> {
+  const resp = await axios.get<any>(baseURL + '/api/strategies/instances');
+  return resp.data.instances || [];
+}
+
+export interface OrderBookLevel {
+  price: number;
+  volume: number;
+  ownQuantity: number;
+  numOfOrders: number;
+  layer: number;
+}
+
+export interface StrategyOrderBook {
+  instanceId: string;
+  session: string;
+  symbol: string;
+  time: string;
+  bookTime: string;
+  bids: OrderBookLevel[];
+  asks: OrderBookLevel[];
+  ownBids: OrderBookLevel[];
+  ownAsks: OrderBookLevel[];
+}
+
+export function strategyOrderBookStreamURL(
+  instanceID: string,
+  depth: number
+): string {
+  let origin = baseURL;
+  if (origin === '' && typeof window !== 'undefined') {
+    origin = window.location.origin;
+  }
+
+  const url = new URL(
+    origin +
+      '/api/strategies/instances/' +
+      encodeURIComponent(instanceID) +
+      '/orderbook/ws'
+  );
+  url.protocol = url.protocol === 'https:' ? 'wss:' : 'ws:';
+  url.searchParams.set('depth', String(depth));
+  return url.toString();
+}
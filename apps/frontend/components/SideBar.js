@@ -8,6 +8,7 @@ import DashboardIcon from '@mui/icons-material/Dashboard';
 import ListItemText from '@mui/material/ListItemText';
 import ListIcon from '@mui/icons-material/List';
 import TrendingUpIcon from '@mui/icons-material/TrendingUp';
+import ReorderIcon from '@mui/icons-material/Reorder';
 import React from 'react';
 import { makeStyles } from '@mui/styles';
 
@@ -97,6 +98,14 @@ export default function SideBar() {
             <ListItemText primary="Strategies" />
           </ListItem>
         </Link>
+        <Link href={'/orderbook'}>
+          <ListItem button>
+            <ListItemIcon>
+              <ReorderIcon />
+            </ListItemIcon>
+            <ListItemText primary="Order Book" />
+          </ListItem>
+        </Link>
       </List>
     </Drawer>
   );
@@ -0,0 +1,216 @@
+import React, { useEffect, useState } from 'react';
+
+import { makeStyles } from '@mui/styles';
+import Typography from '@mui/material/Typography';
+import Paper from '@mui/material/Paper';
+import Select from '@mui/material/Select';
+import MenuItem from '@mui/material/MenuItem';
+import FormControl from '@mui/material/FormControl';
+import InputLabel from '@mui/material/InputLabel';
+import DashboardLayout from '../layouts/DashboardLayout';
+import {
+  queryStrategyInstances,
+  strategyOrderBookStreamURL,
+} from '../api/bbgo';
+import type {
+  OrderBookLevel,
+  StrategyInstance,
+  StrategyOrderBook,
+} from '../api/bbgo';
+
+const bookDepth = 20;
+
+const useStyles = makeStyles((theme) => ({
+  paper: {
+    margin: theme.spacing(2),
+    padding: theme.spacing(2),
+  },
+  controls: {
+    display: 'flex',
+    alignItems: 'center',
+    gap: '20px',
+    marginBottom: '20px',
+  },
+  book: {
+    display: 'grid',
+    gridTemplateColumns: '1fr 1fr',
+    gap: '20px',
+  },
+  row: {
+    position: 'relative',
+    display: 'grid',
+    gridTemplateColumns: '40px 1fr 1fr 1fr',
+    fontFamily: 'monospace',
+    fontSize: '13px',
+    lineHeight: '22px',
+    padding: '0 8px',
+  },
+  depthBar: {
+    position: 'absolute',
+    top: 0,
+    bottom: 0,
+    right: 0,
+    opacity: 0.15,
+  },
+  ownRow: {
+    fontWeight: 'bold',
+    outline: '1px solid #ff9800',
+  },
+  error: {
+    color: '#d32f2f',
+  },
+}));
+
+function BookSide({ title, levels, ownLevels, color }) {
+  const classes = useStyles();
+
+  const maxVolume = Math.max(
+    ...levels.map((level: OrderBookLevel) => Number(level.volume)),
+    0
+  );
+
+  // the own orders out of the book depth are listed after the book levels
+  const outOfDepth = ownLevels.filter(
+    (level: OrderBookLevel) => level.layer < 0
+  );
+
+  return (
+    <div>
+      <Typography variant="subtitle1" gutterBottom>
+        {title}
+      </Typography>
+      <div className={classes.row}>
+        <span>#</span>
+        <span>Price</span>
+        <span>Volume</span>
+        <span>Own</span>
+      </div>
+      {levels.concat(outOfDepth).map((level: OrderBookLevel) => {
+        const own = level.numOfOrders > 0;
+        const width =
+          maxVolume > 0 ? (Number(level.volume) / maxVolume) * 100 : 0;
+        return (
+          <div
+            key={level.price}
+            className={own ? `${classes.row} ${classes.ownRow}` : classes.row}
+          >
+            <div
+              className={classes.depthBar}
+              style={{ width: width + '%', backgroundColor: color }}
+            />
+            <span>{level.layer >= 0 ? level.layer : '-'}</span>
+            <span style={{ color }}>{level.price}</span>
+            <span>{level.volume}</span>
+            <span>
+              {own ? `${level.ownQuantity} (${level.numOfOrders})` : ''}
+            </span>
+          </div>
+        );
+      })}
+    </div>
+  );
+}
+
+export default function OrderBook() {
+  const classes = useStyles();
+
+  const [instances, setInstances] = useState<StrategyInstance[]>([]);
+  const [instanceID, setInstanceID] = useState<string>('');
+  const [book, setBook] = useState<StrategyOrderBook | null>(null);
+  const [error, setError] = useState<string>('');
+
+  useEffect(() => {
+    queryStrategyInstances().then((instances) => {
+      const mounted = instances.filter((instance) => instance.session);
+      setInstances(mounted);
+      if (mounted.length > 0) {
+        setInstanceID(mounted[0].id);
+      }
+    });
+  }, []);
+
+  useEffect(() => {
+    if (!instanceID) {
+      return;
+    }
+
+    setBook(null);
+    setError('');
+
+    const ws = new WebSocket(strategyOrderBookStreamURL(instanceID, bookDepth));
+    ws.onmessage = (event) => {
+      const data = JSON.parse(event.data);
+      if (data.error) {
+        setError(data.error);
+        return;
+      }
+
+      setBook(data.orderBook);
+    };
+    ws.onerror = () => {
+      setError('order book stream error, is the book channel subscribed?');
+    };
+
+    return () => {
+      ws.close();
+    };
+  }, [instanceID]);
+
+  return (
+    <DashboardLayout>
+      <Paper className={classes.paper}>
+        <Typography variant="h4" gutterBottom>
+          Order Book
+        </Typography>
+
+        <div className={classes.controls}>
+          <FormControl size="small" style={{ minWidth: 300 }}>
+            <InputLabel id="strategy-instance-label">Strategy</InputLabel>
+            <Select
+              labelId="strategy-instance-label"
+              label="Strategy"
+              value={instanceID}
+              onChange={(event) => setInstanceID(event.target.value as string)}
+            >
+              {instances.map((instance) => (
+                <MenuItem key={instance.id} value={instance.id}>
+                  {instance.id}
+                </MenuItem>
+              ))}
+            </Select>
+          </FormControl>
+
+          {book && (
+            <Typography variant="body2">
+              {book.session} {book.symbol} updated at{' '}
+              {new Date(book.bookTime).toLocaleTimeString()}
+            </Typography>
+          )}
+        </div>
+
+        {error && (
+          <Typography variant="body2" className={classes.error}>
+            {error}
+          </Typography>
+        )}
+
+        {book && (
+          <div className={classes.book}>
+            <BookSide
+              title="Bids"
+              levels={book.bids}
+              ownLevels={book.ownBids || []}
+              color="#2e7d32"
+            />
+            <BookSide
+              title="Asks"
+              levels={book.asks}
+              ownLevels={book.ownAsks || []}
+              color="#c62828"
+            />
+          </div>
+        )}
+      </Paper>
+    </DashboardLayout>
+  );
+}
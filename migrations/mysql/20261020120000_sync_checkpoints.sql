@@ -0,0 +1,25 @@
+-- +up
+CREATE TABLE `sync_checkpoints`
+(
+    `gid`        BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+
+    `exchange`   VARCHAR(24)     NOT NULL DEFAULT '',
+
+    `symbol`     VARCHAR(32)     NOT NULL DEFAULT '',
+
+    -- type is the record type of the checkpoint, e.g., trades, orders
+    `type`       VARCHAR(16)     NOT NULL,
+
+    -- start_time and end_time are the time range of the remote records that are completely synced
+    `start_time` DATETIME(3)     NOT NULL,
+
+    `end_time`   DATETIME(3)     NOT NULL,
+
+    `updated_at` DATETIME(3)     NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    UNIQUE KEY `sync_checkpoint` (`exchange`, `symbol`, `type`)
+);
+
+-- +down
+DROP TABLE IF EXISTS `sync_checkpoints`;
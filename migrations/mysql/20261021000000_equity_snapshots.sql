@@ -0,0 +1,35 @@
+-- +up
+CREATE TABLE `equity_snapshots`
+(
+    `gid`                  BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+
+    `strategy`             VARCHAR(32)     NOT NULL,
+
+    `strategy_instance_id` VARCHAR(64)     NOT NULL,
+
+    `symbol`               VARCHAR(20)     NOT NULL,
+
+    -- base is the position base quantity at the snapshot time
+    `base`                 DECIMAL(16, 8)  NOT NULL,
+
+    `average_cost`         DECIMAL(16, 8)  NOT NULL,
+
+    -- mid_price is the price the position is marked against
+    `mid_price`            DECIMAL(16, 8)  NOT NULL,
+
+    -- realized_pnl is the accumulated profit of the position
+    `realized_pnl`         DECIMAL(16, 8)  NOT NULL,
+
+    `unrealized_pnl`       DECIMAL(16, 8)  NOT NULL,
+
+    -- equity is the realized pnl plus the unrealized pnl, in the quote currency
+    `equity`               DECIMAL(16, 8)  NOT NULL,
+
+    `time`                 DATETIME(3)     NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    INDEX `equity_snapshots_instance_time` (`strategy_instance_id`, `symbol`, `time`)
+);
+
+-- +down
+DROP TABLE IF EXISTS `equity_snapshots`;
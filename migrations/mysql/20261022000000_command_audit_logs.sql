@@ -0,0 +1,30 @@
+-- +up
+CREATE TABLE `command_audit_logs`
+(
+    `gid`      BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+
+    -- source is the messenger or the api of the command, e.g., telegram, slack and web
+    `source`   VARCHAR(16)     NOT NULL,
+
+    `username` VARCHAR(128)    NOT NULL,
+
+    `role`     VARCHAR(16)     NOT NULL DEFAULT '',
+
+    `command`  VARCHAR(64)     NOT NULL,
+
+    `args`     VARCHAR(255)    NOT NULL DEFAULT '',
+
+    -- result is one of ok, error and denied
+    `result`   VARCHAR(16)     NOT NULL,
+
+    `error`    TEXT            NULL,
+
+    `time`     DATETIME(3)     NOT NULL,
+
+    PRIMARY KEY (`gid`),
+    INDEX `command_audit_logs_time` (`time`),
+    INDEX `command_audit_logs_username_time` (`username`, `time`)
+);
+
+-- +down
+DROP TABLE IF EXISTS `command_audit_logs`;
@@ -0,0 +1,404 @@
+-- +up
+-- +begin
+CREATE TABLE trades
+(
+    gid            BIGSERIAL PRIMARY KEY,
+    id             BIGINT,
+    order_id       BIGINT,
+    exchange       VARCHAR(24)     NOT NULL DEFAULT '',
+    symbol         VARCHAR(32)     NOT NULL,
+    price          NUMERIC(16, 8)  NOT NULL,
+    quantity       NUMERIC(16, 8)  NOT NULL,
+    quote_quantity NUMERIC(16, 8)  NOT NULL,
+    fee            NUMERIC(16, 8)  NOT NULL,
+    fee_currency   VARCHAR(16)     NOT NULL,
+    is_buyer       BOOLEAN         NOT NULL DEFAULT FALSE,
+    is_maker       BOOLEAN         NOT NULL DEFAULT FALSE,
+    side           VARCHAR(4)      NOT NULL DEFAULT '',
+    traded_at      TIMESTAMP(3)    NOT NULL,
+    is_margin      BOOLEAN         NOT NULL DEFAULT FALSE,
+    is_isolated    BOOLEAN         NOT NULL DEFAULT FALSE,
+    is_futures     BOOLEAN         NOT NULL DEFAULT FALSE,
+    strategy       VARCHAR(32)     NULL,
+    pnl            NUMERIC         NULL
+);
+-- +end
+-- +begin
+CREATE UNIQUE INDEX trade_unique_id ON trades (exchange, symbol, side, id);
+-- +end
+-- +begin
+CREATE INDEX trades_price_quantity ON trades (order_id, price, quantity);
+-- +end
+-- +begin
+CREATE INDEX trades_traded_at ON trades (traded_at, symbol, exchange, id, fee_currency, fee);
+-- +end
+-- +begin
+CREATE INDEX trades_id_traded_at ON trades (id, traded_at);
+-- +end
+-- +begin
+CREATE INDEX trades_order_id_traded_at ON trades (order_id, traded_at);
+-- +end
+
+-- +begin
+CREATE TABLE orders
+(
+    gid               BIGSERIAL PRIMARY KEY,
+    exchange          VARCHAR(24)    NOT NULL DEFAULT '',
+    -- order_id is the order id returned from the exchange
+    order_id          BIGINT         NOT NULL,
+    client_order_id   VARCHAR(122)   NOT NULL DEFAULT '',
+    order_type        VARCHAR(16)    NOT NULL,
+    symbol            VARCHAR(32)    NOT NULL,
+    status            VARCHAR(12)    NOT NULL,
+    time_in_force     VARCHAR(4)     NOT NULL,
+    price             NUMERIC(16, 8) NOT NULL,
+    stop_price        NUMERIC(16, 8) NOT NULL,
+    quantity          NUMERIC(16, 8) NOT NULL,
+    executed_quantity NUMERIC(16, 8) NOT NULL DEFAULT 0.0,
+    side              VARCHAR(4)     NOT NULL DEFAULT '',
+    is_working        BOOLEAN        NOT NULL DEFAULT FALSE,
+    is_margin         BOOLEAN        NOT NULL DEFAULT FALSE,
+    is_isolated       BOOLEAN        NOT NULL DEFAULT FALSE,
+    is_futures        BOOLEAN        NOT NULL DEFAULT FALSE,
+    created_at        TIMESTAMP(3)   NOT NULL,
+    updated_at        TIMESTAMP(3)   NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+-- +end
+-- +begin
+CREATE INDEX orders_symbol ON orders (exchange, symbol);
+-- +end
+-- +begin
+CREATE UNIQUE INDEX orders_order_id ON orders (order_id, exchange);
+-- +end
+
+-- +begin
+CREATE TABLE klines
+(
+    gid                    BIGSERIAL PRIMARY KEY,
+    exchange               VARCHAR(10)    NOT NULL,
+    start_time             TIMESTAMP(3)   NOT NULL,
+    end_time               TIMESTAMP(3)   NOT NULL,
+    interval               VARCHAR(3)     NOT NULL,
+    symbol                 VARCHAR(20)    NOT NULL,
+    open                   NUMERIC(20, 8) NOT NULL,
+    high                   NUMERIC(20, 8) NOT NULL,
+    low                    NUMERIC(20, 8) NOT NULL,
+    close                  NUMERIC(20, 8) NOT NULL DEFAULT 0.0,
+    volume                 NUMERIC(20, 8) NOT NULL DEFAULT 0.0,
+    closed                 BOOLEAN        NOT NULL DEFAULT TRUE,
+    last_trade_id          INT            NOT NULL DEFAULT 0,
+    num_trades             INT            NOT NULL DEFAULT 0,
+    quote_volume           NUMERIC(32, 4) NOT NULL DEFAULT 0.0,
+    taker_buy_base_volume  NUMERIC(32, 8) NOT NULL DEFAULT 0.0,
+    taker_buy_quote_volume NUMERIC(32, 4) NOT NULL DEFAULT 0.0
+);
+-- +end
+-- +begin
+CREATE INDEX klines_end_time_symbol_interval ON klines (end_time, symbol, interval);
+-- +end
+-- +begin
+CREATE TABLE binance_klines (LIKE klines INCLUDING ALL);
+-- +end
+-- +begin
+CREATE UNIQUE INDEX idx_kline_binance_unique ON binance_klines (symbol, interval, start_time);
+-- +end
+-- +begin
+CREATE TABLE max_klines (LIKE klines INCLUDING ALL);
+-- +end
+-- +begin
+CREATE UNIQUE INDEX idx_kline_max_unique ON max_klines (symbol, interval, start_time);
+-- +end
+-- +begin
+CREATE TABLE okex_klines (LIKE klines INCLUDING ALL);
+-- +end
+-- +begin
+CREATE UNIQUE INDEX idx_kline_okex_unique ON okex_klines (symbol, interval, start_time);
+-- +end
+-- +begin
+CREATE TABLE kucoin_klines (LIKE klines INCLUDING ALL);
+-- +end
+-- +begin
+CREATE UNIQUE INDEX idx_kline_kucoin_unique ON kucoin_klines (symbol, interval, start_time);
+-- +end
+-- +begin
+CREATE TABLE ftx_klines (LIKE klines INCLUDING ALL);
+-- +end
+-- +begin
+CREATE UNIQUE INDEX idx_kline_ftx_unique ON ftx_klines (symbol, interval, start_time);
+-- +end
+
+-- +begin
+CREATE TABLE rewards
+(
+    gid         BIGSERIAL PRIMARY KEY,
+    -- for exchange
+    exchange    VARCHAR(24)    NOT NULL DEFAULT '',
+    -- reward record id
+    uuid        VARCHAR(32)    NOT NULL,
+    reward_type VARCHAR(24)    NOT NULL DEFAULT '',
+    -- currency symbol, BTC, MAX, USDT ... etc
+    currency    VARCHAR(5)     NOT NULL,
+    -- the quantity of the rewards
+    quantity    NUMERIC(16, 8) NOT NULL,
+    state       VARCHAR(5)     NOT NULL,
+    created_at  TIMESTAMP      NOT NULL,
+    spent       BOOLEAN        NOT NULL DEFAULT FALSE,
+    note        TEXT           NULL,
+    UNIQUE (uuid)
+);
+-- +end
+
+-- +begin
+CREATE TABLE withdraws
+(
+    gid              BIGSERIAL PRIMARY KEY,
+    exchange         VARCHAR(24)    NOT NULL DEFAULT '',
+    -- asset is the asset name (currency)
+    asset            VARCHAR(10)    NOT NULL,
+    address          VARCHAR(128)   NOT NULL,
+    network          VARCHAR(32)    NOT NULL DEFAULT '',
+    amount           NUMERIC(16, 8) NOT NULL,
+    txn_id           VARCHAR(256)   NOT NULL,
+    txn_fee          NUMERIC(16, 8) NOT NULL DEFAULT 0,
+    txn_fee_currency VARCHAR(32)    NOT NULL DEFAULT '',
+    time             TIMESTAMP(3)   NOT NULL
+);
+-- +end
+-- +begin
+CREATE UNIQUE INDEX withdraws_txn_id ON withdraws (exchange, txn_id);
+-- +end
+
+-- +begin
+CREATE TABLE deposits
+(
+    gid      BIGSERIAL PRIMARY KEY,
+    exchange VARCHAR(24)    NOT NULL,
+    -- asset is the asset name (currency)
+    asset    VARCHAR(10)    NOT NULL,
+    address  VARCHAR(128)   NOT NULL DEFAULT '',
+    amount   NUMERIC(16, 8) NOT NULL,
+    txn_id   VARCHAR(256)   NOT NULL,
+    time     TIMESTAMP(3)   NOT NULL
+);
+-- +end
+-- +begin
+CREATE UNIQUE INDEX deposits_txn_id ON deposits (exchange, txn_id);
+-- +end
+
+-- +begin
+CREATE TABLE nav_history_details
+(
+    gid              BIGSERIAL PRIMARY KEY,
+    exchange         VARCHAR(30)  NOT NULL DEFAULT '',
+    subaccount       VARCHAR(30)  NOT NULL DEFAULT '',
+    session          VARCHAR(50)  NOT NULL,
+    time             TIMESTAMP(3) NOT NULL,
+    currency         VARCHAR(12)  NOT NULL,
+    balance_in_usd   NUMERIC(32, 8) DEFAULT 0.00000000 NOT NULL,
+    balance_in_btc   NUMERIC(32, 20) DEFAULT 0.00000000 NOT NULL,
+    balance          NUMERIC(32, 20) DEFAULT 0.00000000 NOT NULL,
+    available        NUMERIC(32, 20) DEFAULT 0.00000000 NOT NULL,
+    locked           NUMERIC(32, 20) DEFAULT 0.00000000 NOT NULL,
+    borrowed         NUMERIC(32, 20) DEFAULT 0.00000000 NOT NULL,
+    net_asset        NUMERIC(32, 20) DEFAULT 0.00000000 NOT NULL,
+    net_asset_in_usd NUMERIC(32, 2) DEFAULT 0.00000000 NOT NULL,
+    net_asset_in_btc NUMERIC(32, 20) DEFAULT 0.00000000 NOT NULL,
+    price_in_usd     NUMERIC(32, 8) DEFAULT 0.00000000 NOT NULL,
+    is_margin        BOOLEAN DEFAULT FALSE NOT NULL,
+    is_isolated      BOOLEAN DEFAULT FALSE NOT NULL,
+    isolated_symbol  VARCHAR(30) DEFAULT '' NOT NULL,
+    interest         NUMERIC(32, 20) DEFAULT 0.00000000 NOT NULL
+);
+-- +end
+-- +begin
+CREATE INDEX idx_nav_history_details ON nav_history_details (time, currency, exchange);
+-- +end
+
+-- +begin
+CREATE TABLE profits
+(
+    gid                  BIGSERIAL PRIMARY KEY,
+    strategy             VARCHAR(32)    NOT NULL,
+    strategy_instance_id VARCHAR(64)    NOT NULL,
+    symbol               VARCHAR(20)    NOT NULL,
+    -- average_cost is the position average cost
+    average_cost         NUMERIC(16, 8) NOT NULL,
+    -- profit is the pnl (profit and loss)
+    profit               NUMERIC(16, 8) NOT NULL,
+    -- net_profit is the pnl (profit and loss)
+    net_profit           NUMERIC(16, 8) NOT NULL,
+    -- profit_margin is the pnl (profit and loss)
+    profit_margin        NUMERIC(16, 8) NOT NULL,
+    -- net_profit_margin is the pnl (profit and loss)
+    net_profit_margin    NUMERIC(16, 8) NOT NULL,
+    quote_currency       VARCHAR(10)    NOT NULL,
+    base_currency        VARCHAR(16)    NOT NULL,
+    -- embedded trade data --
+    exchange             VARCHAR(24)    NOT NULL DEFAULT '',
+    is_futures           BOOLEAN        NOT NULL DEFAULT FALSE,
+    is_margin            BOOLEAN        NOT NULL DEFAULT FALSE,
+    is_isolated          BOOLEAN        NOT NULL DEFAULT FALSE,
+    trade_id             BIGINT         NOT NULL,
+    -- side is the side of the trade that makes profit
+    side                 VARCHAR(4)     NOT NULL DEFAULT '',
+    is_buyer             BOOLEAN        NOT NULL DEFAULT FALSE,
+    is_maker             BOOLEAN        NOT NULL DEFAULT FALSE,
+    -- price is the price of the trade that makes profit
+    price                NUMERIC(16, 8) NOT NULL,
+    -- quantity is the quantity of the trade that makes profit
+    quantity             NUMERIC(16, 8) NOT NULL,
+    -- quote_quantity is the quote quantity of the trade that makes profit
+    quote_quantity       NUMERIC(16, 8) NOT NULL,
+    traded_at            TIMESTAMP(3)   NOT NULL,
+    -- fee
+    fee_in_usd           NUMERIC(16, 8),
+    fee                  NUMERIC(16, 8) NOT NULL,
+    fee_currency         VARCHAR(16)    NOT NULL,
+    UNIQUE (trade_id)
+);
+-- +end
+
+-- +begin
+CREATE TABLE positions
+(
+    gid                  BIGSERIAL PRIMARY KEY,
+    strategy             VARCHAR(32)    NOT NULL,
+    strategy_instance_id VARCHAR(64)    NOT NULL,
+    symbol               VARCHAR(20)    NOT NULL,
+    quote_currency       VARCHAR(10)    NOT NULL,
+    base_currency        VARCHAR(16)    NOT NULL,
+    -- average_cost is the position average cost
+    average_cost         NUMERIC(16, 8) NOT NULL,
+    base                 NUMERIC(16, 8) NOT NULL,
+    quote                NUMERIC(16, 8) NOT NULL,
+    profit               NUMERIC(16, 8) NULL,
+    -- trade related columns
+    trade_id             BIGINT         NOT NULL, -- the trade id in the exchange
+    side                 VARCHAR(4)     NOT NULL, -- side of the trade
+    exchange             VARCHAR(12)    NOT NULL, -- exchange of the trade
+    traded_at            TIMESTAMP(3)   NOT NULL, -- millisecond timestamp
+    UNIQUE (trade_id, side, exchange)
+);
+-- +end
+
+-- +begin
+CREATE TABLE margin_loans
+(
+    gid             BIGSERIAL PRIMARY KEY,
+    transaction_id  BIGINT         NOT NULL,
+    exchange        VARCHAR(24)    NOT NULL DEFAULT '',
+    asset           VARCHAR(24)    NOT NULL DEFAULT '',
+    isolated_symbol VARCHAR(24)    NOT NULL DEFAULT '',
+    -- principle is the amount of the loan
+    principle       NUMERIC(16, 8) NOT NULL,
+    time            TIMESTAMP(3)   NOT NULL,
+    UNIQUE (transaction_id)
+);
+-- +end
+
+-- +begin
+CREATE TABLE margin_repays
+(
+    gid             BIGSERIAL PRIMARY KEY,
+    transaction_id  BIGINT         NOT NULL,
+    exchange        VARCHAR(24)    NOT NULL DEFAULT '',
+    asset           VARCHAR(24)    NOT NULL DEFAULT '',
+    isolated_symbol VARCHAR(24)    NOT NULL DEFAULT '',
+    -- principle is the amount of the repay
+    principle       NUMERIC(16, 8) NOT NULL,
+    time            TIMESTAMP(3)   NOT NULL,
+    UNIQUE (transaction_id)
+);
+-- +end
+
+-- +begin
+CREATE TABLE margin_interests
+(
+    gid             BIGSERIAL PRIMARY KEY,
+    exchange        VARCHAR(24)     NOT NULL DEFAULT '',
+    asset           VARCHAR(24)     NOT NULL DEFAULT '',
+    isolated_symbol VARCHAR(24)     NOT NULL DEFAULT '',
+    principle       NUMERIC(16, 8)  NOT NULL,
+    interest        NUMERIC(20, 16) NOT NULL,
+    interest_rate   NUMERIC(20, 16) NOT NULL,
+    time            TIMESTAMP(3)    NOT NULL
+);
+-- +end
+
+-- +begin
+CREATE TABLE margin_liquidations
+(
+    gid               BIGSERIAL PRIMARY KEY,
+    exchange          VARCHAR(24)    NOT NULL DEFAULT '',
+    symbol            VARCHAR(24)    NOT NULL DEFAULT '',
+    order_id          BIGINT         NOT NULL,
+    is_isolated       BOOLEAN        NOT NULL DEFAULT false,
+    average_price     NUMERIC(16, 8) NOT NULL,
+    price             NUMERIC(16, 8) NOT NULL,
+    quantity          NUMERIC(16, 8) NOT NULL,
+    executed_quantity NUMERIC(16, 8) NOT NULL,
+    side              VARCHAR(5)     NOT NULL DEFAULT '',
+    time_in_force     VARCHAR(5)     NOT NULL DEFAULT '',
+    time              TIMESTAMP(3)   NOT NULL,
+    UNIQUE (order_id, exchange)
+);
+-- +end
+
+
+
+-- +down
+
+-- +begin
+DROP TABLE IF EXISTS margin_liquidations;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS margin_interests;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS margin_repays;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS margin_loans;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS positions;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS profits;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS nav_history_details;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS deposits;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS withdraws;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS rewards;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS ftx_klines;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS kucoin_klines;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS okex_klines;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS max_klines;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS binance_klines;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS klines;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS orders;
+-- +end
+-- +begin
+DROP TABLE IF EXISTS trades;
+-- +end
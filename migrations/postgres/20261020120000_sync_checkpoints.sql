@@ -0,0 +1,18 @@
+-- +up
+CREATE TABLE sync_checkpoints
+(
+    gid        BIGSERIAL PRIMARY KEY,
+    exchange   VARCHAR(24)  NOT NULL DEFAULT '',
+    symbol     VARCHAR(32)  NOT NULL DEFAULT '',
+    -- type is the record type of the checkpoint, e.g., trades, orders
+    type       VARCHAR(16)  NOT NULL,
+    -- start_time and end_time are the time range of the remote records that are completely synced
+    start_time TIMESTAMP(3) NOT NULL,
+    end_time   TIMESTAMP(3) NOT NULL,
+    updated_at TIMESTAMP(3) NOT NULL
+);
+
+CREATE UNIQUE INDEX sync_checkpoint ON sync_checkpoints (exchange, symbol, type);
+
+-- +down
+DROP TABLE IF EXISTS sync_checkpoints;
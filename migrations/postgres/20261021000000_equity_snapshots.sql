@@ -0,0 +1,24 @@
+-- +up
+CREATE TABLE equity_snapshots
+(
+    gid                  BIGSERIAL PRIMARY KEY,
+    strategy             VARCHAR(32)    NOT NULL,
+    strategy_instance_id VARCHAR(64)    NOT NULL,
+    symbol               VARCHAR(20)    NOT NULL,
+    -- base is the position base quantity at the snapshot time
+    base                 NUMERIC(16, 8) NOT NULL,
+    average_cost         NUMERIC(16, 8) NOT NULL,
+    -- mid_price is the price the position is marked against
+    mid_price            NUMERIC(16, 8) NOT NULL,
+    -- realized_pnl is the accumulated profit of the position
+    realized_pnl         NUMERIC(16, 8) NOT NULL,
+    unrealized_pnl       NUMERIC(16, 8) NOT NULL,
+    -- equity is the realized pnl plus the unrealized pnl, in the quote currency
+    equity               NUMERIC(16, 8) NOT NULL,
+    time                 TIMESTAMP(3)   NOT NULL
+);
+
+CREATE INDEX equity_snapshots_instance_time ON equity_snapshots (strategy_instance_id, symbol, time);
+
+-- +down
+DROP TABLE IF EXISTS equity_snapshots;
@@ -0,0 +1,22 @@
+-- +up
+CREATE TABLE command_audit_logs
+(
+    gid      BIGSERIAL PRIMARY KEY,
+    -- source is the messenger or the api of the command, e.g., telegram, slack and web
+    source   VARCHAR(16)  NOT NULL,
+    username VARCHAR(128) NOT NULL,
+    role     VARCHAR(16)  NOT NULL DEFAULT '',
+    command  VARCHAR(64)  NOT NULL,
+    args     VARCHAR(255) NOT NULL DEFAULT '',
+    -- result is one of ok, error and denied
+    result   VARCHAR(16)  NOT NULL,
+    error    TEXT         NULL,
+    time     TIMESTAMP(3) NOT NULL
+);
+
+CREATE INDEX command_audit_logs_time ON command_audit_logs (time);
+
+CREATE INDEX command_audit_logs_username_time ON command_audit_logs (username, time);
+
+-- +down
+DROP TABLE IF EXISTS command_audit_logs;
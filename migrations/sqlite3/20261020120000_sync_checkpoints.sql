@@ -0,0 +1,16 @@
+-- +up
+CREATE TABLE `sync_checkpoints`
+(
+    `gid`        INTEGER PRIMARY KEY AUTOINCREMENT,
+    `exchange`   VARCHAR(24) NOT NULL DEFAULT '',
+    `symbol`     VARCHAR(32) NOT NULL DEFAULT '',
+    `type`       VARCHAR(16) NOT NULL,
+    `start_time` DATETIME(3) NOT NULL,
+    `end_time`   DATETIME(3) NOT NULL,
+    `updated_at` DATETIME(3) NOT NULL
+);
+
+CREATE UNIQUE INDEX `sync_checkpoint` ON `sync_checkpoints` (`exchange`, `symbol`, `type`);
+
+-- +down
+DROP TABLE IF EXISTS `sync_checkpoints`;
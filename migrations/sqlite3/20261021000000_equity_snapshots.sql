@@ -0,0 +1,20 @@
+-- +up
+CREATE TABLE `equity_snapshots`
+(
+    `gid`                  INTEGER PRIMARY KEY AUTOINCREMENT,
+    `strategy`             VARCHAR(32)    NOT NULL,
+    `strategy_instance_id` VARCHAR(64)    NOT NULL,
+    `symbol`               VARCHAR(20)    NOT NULL,
+    `base`                 DECIMAL(16, 8) NOT NULL,
+    `average_cost`         DECIMAL(16, 8) NOT NULL,
+    `mid_price`            DECIMAL(16, 8) NOT NULL,
+    `realized_pnl`         DECIMAL(16, 8) NOT NULL,
+    `unrealized_pnl`       DECIMAL(16, 8) NOT NULL,
+    `equity`               DECIMAL(16, 8) NOT NULL,
+    `time`                 DATETIME(3)    NOT NULL
+);
+
+CREATE INDEX `equity_snapshots_instance_time` ON `equity_snapshots` (`strategy_instance_id`, `symbol`, `time`);
+
+-- +down
+DROP TABLE IF EXISTS `equity_snapshots`;
@@ -0,0 +1,20 @@
+-- +up
+CREATE TABLE `command_audit_logs`
+(
+    `gid`      INTEGER PRIMARY KEY AUTOINCREMENT,
+    `source`   VARCHAR(16)  NOT NULL,
+    `username` VARCHAR(128) NOT NULL,
+    `role`     VARCHAR(16)  NOT NULL DEFAULT '',
+    `command`  VARCHAR(64)  NOT NULL,
+    `args`     VARCHAR(255) NOT NULL DEFAULT '',
+    `result`   VARCHAR(16)  NOT NULL,
+    `error`    TEXT         NULL,
+    `time`     DATETIME(3)  NOT NULL
+);
+
+CREATE INDEX `command_audit_logs_time` ON `command_audit_logs` (`time`);
+
+CREATE INDEX `command_audit_logs_username_time` ON `command_audit_logs` (`username`, `time`);
+
+-- +down
+DROP TABLE IF EXISTS `command_audit_logs`;
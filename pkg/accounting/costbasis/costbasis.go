@@ -0,0 +1,194 @@
+package costbasis
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Method is the cost basis method that matches the sold quantity with the acquired lots
+type Method string
+
+const (
+	// MethodFIFO sells the earliest acquired lots first
+	MethodFIFO Method = "fifo"
+
+	// MethodLIFO sells the latest acquired lots first
+	MethodLIFO Method = "lifo"
+
+	// MethodAverageCost sells at the weighted average cost of all the holdings
+	MethodAverageCost Method = "avg"
+)
+
+func ParseMethod(s string) (Method, error) {
+	switch m := Method(strings.ToLower(s)); m {
+	case MethodFIFO, MethodLIFO, MethodAverageCost:
+		return m, nil
+	case "average", "average-cost":
+		return MethodAverageCost, nil
+	}
+
+	return "", fmt.Errorf("unsupported cost basis method %q, valid methods are %q, %q and %q", s, MethodFIFO, MethodLIFO, MethodAverageCost)
+}
+
+// Lot is an acquired quantity that is not sold yet,
+// the price is the cost per unit in the quote currency, including the trading fee.
+type Lot struct {
+	TradeID  uint64           `json:"tradeId"`
+	Time     time.Time        `json:"time"`
+	Quantity fixedpoint.Value `json:"quantity"`
+	Price    fixedpoint.Value `json:"price"`
+}
+
+// Calculator computes the realized gains of the trades of one market with the selected cost basis method.
+//
+// The trading fee paid in the base or the quote currency is included in the cost basis and the proceeds,
+// the fee paid in other currencies (e.g., BNB) is not included.
+type Calculator struct {
+	Method Method
+	Market types.Market
+
+	lots      []Lot
+	disposals []Disposal
+}
+
+func NewCalculator(method Method, market types.Market) *Calculator {
+	return &Calculator{
+		Method: method,
+		Market: market,
+	}
+}
+
+// Lots returns the remaining lots, ordered by the acquired time
+func (c *Calculator) Lots() []Lot {
+	return c.lots
+}
+
+// Disposals returns the realized disposals in the order of the sell trades
+func (c *Calculator) Disposals() []Disposal {
+	return c.disposals
+}
+
+// AddTrades adds the trades in the ascending order of the trade time, the trades of the other symbols are ignored.
+func (c *Calculator) AddTrades(trades []types.Trade) []Disposal {
+	var disposals []Disposal
+	for _, trade := range types.SortTradesAscending(trades) {
+		if disposal := c.AddTrade(trade); disposal != nil {
+			disposals = append(disposals, *disposal)
+		}
+	}
+
+	return disposals
+}
+
+// AddTrade adds a lot for the buy trade, or returns the realized disposal of the sell trade
+func (c *Calculator) AddTrade(trade types.Trade) *Disposal {
+	if trade.Symbol != c.Market.Symbol {
+		return nil
+	}
+
+	quantity := trade.Quantity
+	amount := trade.QuoteQuantity
+	if amount.IsZero() {
+		amount = trade.Price.Mul(trade.Quantity)
+	}
+
+	if trade.IsBuyer {
+		switch trade.FeeCurrency {
+		case c.Market.BaseCurrency:
+			quantity = quantity.Sub(trade.Fee)
+		case c.Market.QuoteCurrency:
+			amount = amount.Add(trade.Fee)
+		}
+
+		if quantity.Sign() <= 0 {
+			return nil
+		}
+
+		c.addLot(Lot{
+			TradeID:  trade.ID,
+			Time:     trade.Time.Time(),
+			Quantity: quantity,
+			Price:    amount.Div(quantity),
+		})
+		return nil
+	}
+
+	switch trade.FeeCurrency {
+	case c.Market.BaseCurrency:
+		quantity = quantity.Add(trade.Fee)
+	case c.Market.QuoteCurrency:
+		amount = amount.Sub(trade.Fee)
+	}
+
+	costBasis, acquiredTime, uncovered := c.consume(quantity)
+	if uncovered.Sign() > 0 {
+		log.Warnf("%s sell trade %d has %s %s not covered by the acquired lots, the cost basis of the uncovered quantity is zero",
+			trade.Symbol, trade.ID, uncovered.String(), c.Market.BaseCurrency)
+	}
+
+	disposal := Disposal{
+		TradeID:           trade.ID,
+		Symbol:            trade.Symbol,
+		Currency:          c.Market.BaseCurrency,
+		QuoteCurrency:     c.Market.QuoteCurrency,
+		Method:            c.Method,
+		AcquiredTime:      acquiredTime,
+		DisposedTime:      trade.Time.Time(),
+		Quantity:          quantity,
+		UncoveredQuantity: uncovered,
+		Proceeds:          amount,
+		CostBasis:         costBasis,
+		Gain:              amount.Sub(costBasis),
+	}
+
+	c.disposals = append(c.disposals, disposal)
+	return &disposal
+}
+
+func (c *Calculator) addLot(lot Lot) {
+	if c.Method != MethodAverageCost || len(c.lots) == 0 {
+		c.lots = append(c.lots, lot)
+		return
+	}
+
+	// the average cost method keeps one pooled lot, the acquired time of the pool is the time of the first lot
+	pool := c.lots[0]
+	quantity := pool.Quantity.Add(lot.Quantity)
+	pool.Price = pool.Price.Mul(pool.Quantity).Add(lot.Price.Mul(lot.Quantity)).Div(quantity)
+	pool.Quantity = quantity
+	c.lots[0] = pool
+}
+
+// consume removes the quantity from the lots, and returns the cost basis, the acquired time of the earliest consumed lot
+// and the quantity that is not covered by the lots.
+func (c *Calculator) consume(quantity fixedpoint.Value) (costBasis fixedpoint.Value, acquiredTime time.Time, uncovered fixedpoint.Value) {
+	for quantity.Sign() > 0 && len(c.lots) > 0 {
+		idx := 0
+		if c.Method == MethodLIFO {
+			idx = len(c.lots) - 1
+		}
+
+		lot := c.lots[idx]
+		q := fixedpoint.Min(lot.Quantity, quantity)
+		costBasis = costBasis.Add(lot.Price.Mul(q))
+		if acquiredTime.IsZero() || lot.Time.Before(acquiredTime) {
+			acquiredTime = lot.Time
+		}
+
+		quantity = quantity.Sub(q)
+		lot.Quantity = lot.Quantity.Sub(q)
+		if lot.Quantity.IsZero() {
+			c.lots = append(c.lots[:idx], c.lots[idx+1:]...)
+		} else {
+			c.lots[idx] = lot
+		}
+	}
+
+	return costBasis, acquiredTime, quantity
+}
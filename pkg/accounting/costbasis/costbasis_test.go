@@ -0,0 +1,124 @@
+package costbasis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testMarket = types.Market{
+	Symbol:        "BTCUSDT",
+	BaseCurrency:  "BTC",
+	QuoteCurrency: "USDT",
+}
+
+func testTrades() []types.Trade {
+	trade := func(id uint64, month time.Month, isBuyer bool, price, quantity string) types.Trade {
+		return types.Trade{
+			ID:       id,
+			Symbol:   "BTCUSDT",
+			IsBuyer:  isBuyer,
+			Price:    fixedpoint.MustNewFromString(price),
+			Quantity: fixedpoint.MustNewFromString(quantity),
+			Time:     types.Time(time.Date(2021, month, 1, 0, 0, 0, 0, time.UTC)),
+		}
+	}
+
+	return []types.Trade{
+		trade(1, time.January, true, "100", "1"),
+		trade(2, time.February, true, "200", "1"),
+		trade(3, time.March, false, "300", "1.5"),
+	}
+}
+
+func TestCalculator(t *testing.T) {
+	t.Run("fifo", func(t *testing.T) {
+		c := NewCalculator(MethodFIFO, testMarket)
+		disposals := c.AddTrades(testTrades())
+		if assert.Len(t, disposals, 1) {
+			// 100 * 1 + 200 * 0.5
+			assert.Equal(t, "200", disposals[0].CostBasis.String())
+			assert.Equal(t, "250", disposals[0].Gain.String())
+			assert.Equal(t, time.January, disposals[0].AcquiredTime.Month())
+		}
+
+		if assert.Len(t, c.Lots(), 1) {
+			assert.Equal(t, "0.5", c.Lots()[0].Quantity.String())
+			assert.Equal(t, "200", c.Lots()[0].Price.String())
+		}
+	})
+
+	t.Run("lifo", func(t *testing.T) {
+		c := NewCalculator(MethodLIFO, testMarket)
+		disposals := c.AddTrades(testTrades())
+		if assert.Len(t, disposals, 1) {
+			// 200 * 1 + 100 * 0.5
+			assert.Equal(t, "250", disposals[0].CostBasis.String())
+			assert.Equal(t, "200", disposals[0].Gain.String())
+		}
+
+		if assert.Len(t, c.Lots(), 1) {
+			assert.Equal(t, "100", c.Lots()[0].Price.String())
+		}
+	})
+
+	t.Run("average cost", func(t *testing.T) {
+		c := NewCalculator(MethodAverageCost, testMarket)
+		disposals := c.AddTrades(testTrades())
+		if assert.Len(t, disposals, 1) {
+			assert.Equal(t, "225", disposals[0].CostBasis.String())
+			assert.Equal(t, "225", disposals[0].Gain.String())
+		}
+
+		if assert.Len(t, c.Lots(), 1) {
+			assert.Equal(t, "150", c.Lots()[0].Price.String())
+		}
+	})
+
+	t.Run("fees and uncovered quantity", func(t *testing.T) {
+		c := NewCalculator(MethodFIFO, testMarket)
+		c.AddTrade(types.Trade{
+			ID: 1, Symbol: "BTCUSDT", IsBuyer: true,
+			Price: fixedpoint.NewFromInt(100), Quantity: fixedpoint.NewFromInt(1),
+			Fee: fixedpoint.NewFromInt(1), FeeCurrency: "USDT",
+		})
+
+		disposal := c.AddTrade(types.Trade{
+			ID: 2, Symbol: "BTCUSDT",
+			Price: fixedpoint.NewFromInt(200), Quantity: fixedpoint.NewFromInt(2),
+			Fee: fixedpoint.NewFromInt(2), FeeCurrency: "USDT",
+		})
+
+		if assert.NotNil(t, disposal) {
+			assert.Equal(t, "101", disposal.CostBasis.String())
+			assert.Equal(t, "398", disposal.Proceeds.String())
+			assert.Equal(t, "1", disposal.UncoveredQuantity.String())
+		}
+	})
+}
+
+func TestNewTaxReport(t *testing.T) {
+	c := NewCalculator(MethodFIFO, testMarket)
+	trades := append(testTrades(), types.Trade{
+		ID: 4, Symbol: "BTCUSDT",
+		Price: fixedpoint.NewFromInt(100), Quantity: fixedpoint.NewFromFloat(0.5),
+		Time: types.Time(time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)),
+	})
+
+	report := NewTaxReport(c.AddTrades(trades), time.UTC)
+	if assert.Len(t, report, 2) {
+		assert.Equal(t, 2021, report[0].Year)
+		assert.Equal(t, "250", report[0].NetGain().String())
+
+		assert.Equal(t, 2022, report[1].Year)
+		assert.Equal(t, "BTC", report[1].Currency)
+		assert.Equal(t, "-50", report[1].Loss.String())
+	}
+
+	assert.Len(t, report.Year(2022), 1)
+	assert.Len(t, report.CsvRecords(), 2)
+}
@@ -0,0 +1,175 @@
+package costbasis
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// Disposal is the realized gain of a sell trade, the amounts are in the quote currency
+type Disposal struct {
+	TradeID       uint64 `json:"tradeId"`
+	Symbol        string `json:"symbol"`
+	Currency      string `json:"currency"`
+	QuoteCurrency string `json:"quoteCurrency"`
+	Method        Method `json:"method"`
+
+	AcquiredTime time.Time `json:"acquiredTime"`
+	DisposedTime time.Time `json:"disposedTime"`
+
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// UncoveredQuantity is the sold quantity that has no acquired lot, usually the trade history is incomplete
+	UncoveredQuantity fixedpoint.Value `json:"uncoveredQuantity"`
+
+	Proceeds  fixedpoint.Value `json:"proceeds"`
+	CostBasis fixedpoint.Value `json:"costBasis"`
+	Gain      fixedpoint.Value `json:"gain"`
+}
+
+func (d Disposal) CsvHeader() []string {
+	return []string{"trade_id", "symbol", "currency", "quote_currency", "method", "acquired_time", "disposed_time", "quantity", "uncovered_quantity", "proceeds", "cost_basis", "gain"}
+}
+
+func (d Disposal) CsvRecords() [][]string {
+	acquiredTime := ""
+	if !d.AcquiredTime.IsZero() {
+		acquiredTime = d.AcquiredTime.Format(time.RFC3339)
+	}
+
+	return [][]string{
+		{
+			strconv.FormatUint(d.TradeID, 10),
+			d.Symbol,
+			d.Currency,
+			d.QuoteCurrency,
+			string(d.Method),
+			acquiredTime,
+			d.DisposedTime.Format(time.RFC3339),
+			d.Quantity.String(),
+			d.UncoveredQuantity.String(),
+			d.Proceeds.String(),
+			d.CostBasis.String(),
+			d.Gain.String(),
+		},
+	}
+}
+
+// TaxReportEntry summarizes the disposals of a currency in a year
+type TaxReportEntry struct {
+	Year          int    `json:"year"`
+	Currency      string `json:"currency"`
+	QuoteCurrency string `json:"quoteCurrency"`
+	Method        Method `json:"method"`
+	NumDisposals  int    `json:"numDisposals"`
+
+	Quantity  fixedpoint.Value `json:"quantity"`
+	Proceeds  fixedpoint.Value `json:"proceeds"`
+	CostBasis fixedpoint.Value `json:"costBasis"`
+	Gain      fixedpoint.Value `json:"gain"`
+	Loss      fixedpoint.Value `json:"loss"`
+}
+
+// NetGain is the realized gain minus the realized loss
+func (e TaxReportEntry) NetGain() fixedpoint.Value {
+	return e.Gain.Add(e.Loss)
+}
+
+type TaxReport []TaxReportEntry
+
+// NewTaxReport groups the disposals by the year of the disposed time in the given location,
+// the currency and the quote currency. The entries are sorted by the year and the currency.
+func NewTaxReport(disposals []Disposal, loc *time.Location) TaxReport {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	type key struct {
+		year                    int
+		currency, quoteCurrency string
+		method                  Method
+	}
+
+	entries := map[key]*TaxReportEntry{}
+	for _, d := range disposals {
+		k := key{d.DisposedTime.In(loc).Year(), d.Currency, d.QuoteCurrency, d.Method}
+		entry, ok := entries[k]
+		if !ok {
+			entry = &TaxReportEntry{
+				Year:          k.year,
+				Currency:      k.currency,
+				QuoteCurrency: k.quoteCurrency,
+				Method:        k.method,
+			}
+			entries[k] = entry
+		}
+
+		entry.NumDisposals++
+		entry.Quantity = entry.Quantity.Add(d.Quantity)
+		entry.Proceeds = entry.Proceeds.Add(d.Proceeds)
+		entry.CostBasis = entry.CostBasis.Add(d.CostBasis)
+		if d.Gain.Sign() > 0 {
+			entry.Gain = entry.Gain.Add(d.Gain)
+		} else {
+			entry.Loss = entry.Loss.Add(d.Gain)
+		}
+	}
+
+	report := make(TaxReport, 0, len(entries))
+	for _, entry := range entries {
+		report = append(report, *entry)
+	}
+
+	sort.Slice(report, func(i, j int) bool {
+		a, b := report[i], report[j]
+		if a.Year != b.Year {
+			return a.Year < b.Year
+		}
+
+		if a.Currency != b.Currency {
+			return a.Currency < b.Currency
+		}
+
+		return a.QuoteCurrency < b.QuoteCurrency
+	})
+
+	return report
+}
+
+// Year returns the entries of the given year
+func (r TaxReport) Year(year int) (entries TaxReport) {
+	for _, entry := range r {
+		if entry.Year == year {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+func (r TaxReport) CsvHeader() []string {
+	return []string{"year", "currency", "quote_currency", "method", "disposals", "quantity", "proceeds", "cost_basis", "gain", "loss", "net_gain"}
+}
+
+func (r TaxReport) CsvRecords() [][]string {
+	var records [][]string
+	for _, e := range r {
+		records = append(records, []string{
+			strconv.Itoa(e.Year),
+			e.Currency,
+			e.QuoteCurrency,
+			string(e.Method),
+			strconv.Itoa(e.NumDisposals),
+			e.Quantity.String(),
+			e.Proceeds.String(),
+			e.CostBasis.String(),
+			e.Gain.String(),
+			e.Loss.String(),
+			e.NetGain().String(),
+		})
+	}
+
+	return records
+}
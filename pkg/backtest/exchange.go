@@ -50,6 +50,8 @@ var log = logrus.WithField("cmd", "backtest")
 var ErrUnimplemented = errors.New("unimplemented method")
 var ErrNegativeQuantity = errors.New("order quantity can not be negative")
 var ErrZeroQuantity = errors.New("order quantity can not be zero")
+var ErrMinQuantity = errors.New("order quantity is less than the min quantity")
+var ErrMinNotional = errors.New("order amount is less than the min notional")
 
 type Exchange struct {
 	sourceName     types.ExchangeName
@@ -60,6 +62,9 @@ type Exchange struct {
 	account *types.Account
 	config  *bbgo.Backtest
 
+	// margin simulates the leveraged account, it's nil for the spot account
+	margin *marginEngine
+
 	MarketDataStream types.StandardStreamEmitter
 
 	trades      map[string][]types.Trade
@@ -68,6 +73,10 @@ type Exchange struct {
 	closedOrders      map[string][]types.Order
 	closedOrdersMutex sync.Mutex
 
+	// rejectedOrders is the number of the orders rejected by the min quantity and the min notional constraints
+	rejectedOrders      map[string]int
+	rejectedOrdersMutex sync.Mutex
+
 	matchingBooks      map[string]*SimplePriceMatching
 	matchingBooksMutex sync.Mutex
 
@@ -96,6 +105,15 @@ func NewExchange(sourceName types.ExchangeName, sourceExchange types.Exchange, s
 	balances := configAccount.Balances.BalanceMap()
 	account.UpdateBalances(balances)
 
+	var margin *marginEngine
+	if configAccount.Margin != nil {
+		account.AccountType = configAccount.Margin.Type
+		margin, err = newMarginEngine(account, *configAccount.Margin)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	e := &Exchange{
 		sourceName:     sourceName,
 		publicExchange: ex,
@@ -103,8 +121,10 @@ func NewExchange(sourceName types.ExchangeName, sourceExchange types.Exchange, s
 		srv:            srv,
 		config:         config,
 		account:        account,
+		margin:         margin,
 		currentTime:    startTime,
 		closedOrders:   make(map[string][]types.Order),
+		rejectedOrders: make(map[string]int),
 		trades:         make(map[string][]types.Trade),
 	}
 
@@ -146,6 +166,14 @@ func (e *Exchange) _addMatchingBook(symbol string, market types.Market) {
 		Market:          market,
 		closedOrders:    make(map[uint64]types.Order),
 		feeModeFunction: getFeeModeFunction(e.config.FeeMode),
+
+		pricePathFunction: getPricePathFunction(e.config.PricePath),
+
+		margin: e.margin,
+	}
+
+	if e.margin != nil {
+		e.margin.books[symbol] = matching
 	}
 
 	e.matchingBooks[symbol] = matching
@@ -182,11 +210,17 @@ func (e *Exchange) SubmitOrder(ctx context.Context, order types.SubmitOrder) (cr
 		return nil, ErrNegativeQuantity
 	}
 
-	if order.Quantity.IsZero() {
+	if order.Quantity.IsZero() && !order.ClosePosition {
 		return nil, ErrZeroQuantity
 	}
 
 	createdOrder, _, err = matching.PlaceOrder(order)
+	if errors.Is(err, ErrMinQuantity) || errors.Is(err, ErrMinNotional) {
+		e.rejectedOrdersMutex.Lock()
+		e.rejectedOrders[symbol]++
+		e.rejectedOrdersMutex.Unlock()
+	}
+
 	if createdOrder != nil {
 		// market order can be closed immediately.
 		switch createdOrder.Status {
@@ -198,6 +232,13 @@ func (e *Exchange) SubmitOrder(ctx context.Context, order types.SubmitOrder) (cr
 	return createdOrder, err
 }
 
+// RejectedOrders returns the number of the orders of the symbol rejected by the min quantity and the min notional constraints
+func (e *Exchange) RejectedOrders(symbol string) int {
+	e.rejectedOrdersMutex.Lock()
+	defer e.rejectedOrdersMutex.Unlock()
+	return e.rejectedOrders[symbol]
+}
+
 func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
 	matching, ok := e.matchingBook(symbol)
 	if !ok {
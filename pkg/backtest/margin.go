@@ -0,0 +1,450 @@
+package backtest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrInsufficientMargin = errors.New("insufficient margin")
+
+var ErrReduceOnlyRejected = errors.New("reduce-only order rejected")
+
+// LiquidationOrderTag is the tag of the orders submitted by the liquidation
+const LiquidationOrderTag = "liquidation"
+
+const defaultFundingInterval = 8 * time.Hour
+
+const borrowInterestInterval = time.Hour
+
+var (
+	defaultMaintenanceMarginRatio = fixedpoint.MustNewFromString("0.5%")
+	defaultFundingRate            = fixedpoint.MustNewFromString("0.01%")
+	defaultBorrowInterestRate     = fixedpoint.MustNewFromString("0.02%")
+)
+
+// marginPosition is the leveraged position of a symbol, the base is negative for a short position
+type marginPosition struct {
+	Base        fixedpoint.Value
+	AverageCost fixedpoint.Value
+
+	// Margin is the initial margin allocated to the position, which is a part of the wallet balance
+	Margin fixedpoint.Value
+}
+
+func (p *marginPosition) UnrealizedProfit(price fixedpoint.Value) fixedpoint.Value {
+	return price.Sub(p.AverageCost).Mul(p.Base)
+}
+
+func (p *marginPosition) MaintenanceMargin(price, ratio fixedpoint.Value) fixedpoint.Value {
+	return p.Base.Abs().Mul(price).Mul(ratio)
+}
+
+// apply updates the position with the signed quantity, and returns the realized profit
+func (p *marginPosition) apply(quantity, price, leverage fixedpoint.Value) (profit fixedpoint.Value) {
+	if p.Base.IsZero() || p.Base.Sign() == quantity.Sign() {
+		base := p.Base.Add(quantity)
+		p.AverageCost = p.AverageCost.Mul(p.Base).Add(price.Mul(quantity)).Div(base)
+		p.Base = base
+		p.Margin = p.Margin.Add(quantity.Abs().Mul(price).Div(leverage))
+		return fixedpoint.Zero
+	}
+
+	// reduce the position first, the rest of the quantity opens the position of the other side
+	base := p.Base.Abs()
+	closed := fixedpoint.Min(quantity.Abs(), base)
+	profit = price.Sub(p.AverageCost).Mul(closed)
+	if p.Base.Sign() < 0 {
+		profit = profit.Neg()
+		p.Base = p.Base.Add(closed)
+	} else {
+		p.Base = p.Base.Sub(closed)
+	}
+
+	p.Margin = p.Margin.Mul(base.Sub(closed)).Div(base)
+
+	if rest := quantity.Abs().Sub(closed); rest.Sign() > 0 {
+		if quantity.Sign() < 0 {
+			rest = rest.Neg()
+		}
+
+		p.Base = rest
+		p.AverageCost = price
+		p.Margin = rest.Abs().Mul(price).Div(leverage)
+	}
+
+	if p.Base.IsZero() {
+		p.AverageCost = fixedpoint.Zero
+		p.Margin = fixedpoint.Zero
+	}
+
+	return profit
+}
+
+// marginEngine simulates the leveraged account shared by the matching books of the exchange.
+//
+// The positions are settled in the quote currency: the trades change the positions instead of the base balances,
+// the realized profits, the fees, the funding fees and the borrow interests are added to the quote balance (the wallet balance).
+// The cross margin is shared between the positions of the same quote currency.
+type marginEngine struct {
+	config  bbgo.BacktestMarginAccount
+	account *types.Account
+
+	books     map[string]*SimplePriceMatching
+	positions map[string]*marginPosition
+
+	// orderMargins are the locked initial margins of the open orders
+	orderMargins map[uint64]fixedpoint.Value
+
+	nextFundingTimes  map[string]time.Time
+	nextInterestTimes map[string]time.Time
+}
+
+func newMarginEngine(account *types.Account, config bbgo.BacktestMarginAccount) (*marginEngine, error) {
+	switch config.Type {
+	case types.AccountTypeFutures, types.AccountTypeMargin:
+	default:
+		return nil, fmt.Errorf("unsupported backtest margin account type %q, valid types are: %s, %s",
+			config.Type, types.AccountTypeFutures, types.AccountTypeMargin)
+	}
+
+	switch config.MarginMode {
+	case "":
+		config.MarginMode = bbgo.BacktestMarginModeCross
+	case bbgo.BacktestMarginModeCross, bbgo.BacktestMarginModeIsolated:
+	default:
+		return nil, fmt.Errorf("unsupported backtest margin mode %q, valid modes are: %s, %s",
+			config.MarginMode, bbgo.BacktestMarginModeCross, bbgo.BacktestMarginModeIsolated)
+	}
+
+	if config.Leverage.IsZero() {
+		config.Leverage = fixedpoint.One
+	} else if config.Leverage.Sign() < 0 {
+		return nil, fmt.Errorf("backtest margin leverage can not be negative: %s", config.Leverage)
+	}
+
+	if config.MaintenanceMarginRatio.IsZero() {
+		config.MaintenanceMarginRatio = defaultMaintenanceMarginRatio
+	}
+
+	if config.FundingRate.IsZero() {
+		config.FundingRate = defaultFundingRate
+	}
+
+	if config.FundingInterval == 0 {
+		config.FundingInterval = types.Duration(defaultFundingInterval)
+	}
+
+	if config.BorrowInterestRate.IsZero() {
+		config.BorrowInterestRate = defaultBorrowInterestRate
+	}
+
+	return &marginEngine{
+		config:            config,
+		account:           account,
+		books:             make(map[string]*SimplePriceMatching),
+		positions:         make(map[string]*marginPosition),
+		orderMargins:      make(map[uint64]fixedpoint.Value),
+		nextFundingTimes:  make(map[string]time.Time),
+		nextInterestTimes: make(map[string]time.Time),
+	}, nil
+}
+
+func (e *marginEngine) isIsolated() bool {
+	return e.config.MarginMode == bbgo.BacktestMarginModeIsolated
+}
+
+func (e *marginEngine) position(symbol string) *marginPosition {
+	pos, ok := e.positions[symbol]
+	if !ok {
+		pos = &marginPosition{}
+		e.positions[symbol] = pos
+	}
+
+	return pos
+}
+
+func (e *marginEngine) fundingRate(symbol string) fixedpoint.Value {
+	if rate, ok := e.config.FundingRates[symbol]; ok {
+		return rate
+	}
+
+	return e.config.FundingRate
+}
+
+// markPrice returns the last price of the matching book, the average cost is used before the book has a price
+func (e *marginEngine) markPrice(symbol string, pos *marginPosition) fixedpoint.Value {
+	if book, ok := e.books[symbol]; ok && !book.lastPrice.IsZero() {
+		return book.lastPrice
+	}
+
+	return pos.AverageCost
+}
+
+// availableMargin is the available wallet balance that is not allocated to the positions,
+// the unrealized profit is included in the cross margin mode.
+func (e *marginEngine) availableMargin(quoteCurrency string) fixedpoint.Value {
+	balance, _ := e.account.Balance(quoteCurrency)
+	available := balance.Available
+
+	for symbol, pos := range e.positions {
+		book, ok := e.books[symbol]
+		if !ok || book.Market.QuoteCurrency != quoteCurrency || pos.Base.IsZero() {
+			continue
+		}
+
+		available = available.Sub(pos.Margin)
+		if !e.isIsolated() {
+			available = available.Add(pos.UnrealizedProfit(e.markPrice(symbol, pos)))
+		}
+	}
+
+	return available
+}
+
+// reduceOnlyQuantity returns the quantity of the reduce-only or the close-position order,
+// the quantity is capped by the position so that the order never opens the position of the other side.
+func (e *marginEngine) reduceOnlyQuantity(o types.SubmitOrder) (fixedpoint.Value, error) {
+	pos, ok := e.positions[o.Symbol]
+	if !ok || pos.Base.IsZero() {
+		return fixedpoint.Zero, errors.Wrapf(ErrReduceOnlyRejected, "%s has no position to reduce", o.Symbol)
+	}
+
+	if (o.Side == types.SideTypeBuy && pos.Base.Sign() > 0) || (o.Side == types.SideTypeSell && pos.Base.Sign() < 0) {
+		return fixedpoint.Zero, errors.Wrapf(ErrReduceOnlyRejected, "%s %s order can not reduce the position %s", o.Symbol, o.Side, pos.Base.String())
+	}
+
+	if o.ClosePosition {
+		return pos.Base.Abs(), nil
+	}
+
+	return fixedpoint.Min(o.Quantity, pos.Base.Abs()), nil
+}
+
+// lockOrderMargin locks the initial margin of the order, only the quantity that opens the position requires the margin
+func (e *marginEngine) lockOrderMargin(market types.Market, o types.SubmitOrder, price fixedpoint.Value) (fixedpoint.Value, error) {
+	quantity := o.Quantity
+	if pos, ok := e.positions[o.Symbol]; ok {
+		if (o.Side == types.SideTypeBuy && pos.Base.Sign() < 0) || (o.Side == types.SideTypeSell && pos.Base.Sign() > 0) {
+			quantity = fixedpoint.Max(quantity.Sub(pos.Base.Abs()), fixedpoint.Zero)
+		}
+	}
+
+	margin := quantity.Mul(price).Div(e.config.Leverage)
+	if margin.IsZero() {
+		return margin, nil
+	}
+
+	if available := e.availableMargin(market.QuoteCurrency); available.Compare(margin) < 0 {
+		return fixedpoint.Zero, errors.Wrapf(ErrInsufficientMargin, "%s %s order requires initial margin %s %s, available margin %s",
+			o.Symbol, o.Side, margin.String(), market.QuoteCurrency, available.String())
+	}
+
+	if err := e.account.LockBalance(market.QuoteCurrency, margin); err != nil {
+		return fixedpoint.Zero, errors.Wrap(ErrInsufficientMargin, err.Error())
+	}
+
+	return margin, nil
+}
+
+func (e *marginEngine) unlockOrderMargin(market types.Market, orderID uint64) error {
+	margin, ok := e.orderMargins[orderID]
+	if !ok {
+		return nil
+	}
+
+	delete(e.orderMargins, orderID)
+	return e.account.UnlockBalance(market.QuoteCurrency, margin)
+}
+
+// executeTrade releases the order margin and settles the trade into the position
+func (e *marginEngine) executeTrade(m *SimplePriceMatching, trade types.Trade) error {
+	if err := e.unlockOrderMargin(m.Market, trade.OrderID); err != nil {
+		return err
+	}
+
+	e.settleTrade(m, trade)
+	return nil
+}
+
+// settleTrade updates the position and adds the realized profit and the fee into the wallet balance,
+// the loss of an isolated position can not exceed its margin.
+func (e *marginEngine) settleTrade(m *SimplePriceMatching, trade types.Trade) {
+	pos := e.position(trade.Symbol)
+	margin := pos.Margin
+
+	quantity := trade.Quantity
+	if !trade.IsBuyer {
+		quantity = quantity.Neg()
+	}
+
+	profit := pos.apply(quantity, trade.Price, e.config.Leverage)
+	if e.isIsolated() && profit.Compare(margin.Neg()) < 0 {
+		profit = margin.Neg()
+	}
+
+	// the fee is settled in the quote currency, the fee token is counted outside the balances
+	var fee fixedpoint.Value
+	switch trade.FeeCurrency {
+	case m.Market.QuoteCurrency:
+		fee = trade.Fee
+	case m.Market.BaseCurrency:
+		fee = trade.Fee.Mul(trade.Price)
+	}
+
+	m.account.AddBalance(m.Market.QuoteCurrency, profit.Sub(fee))
+}
+
+// chargePeriodicFees charges the funding fees of the futures account and the borrow interests of the margin account,
+// which are charged at the boundaries of the intervals aligned to 00:00 UTC.
+func (e *marginEngine) chargePeriodicFees(m *SimplePriceMatching, kline types.KLine) {
+	startTime := kline.StartTime.Time()
+
+	switch e.config.Type {
+	case types.AccountTypeFutures:
+		e.chargeAtIntervals(e.nextFundingTimes, m.Market.Symbol, startTime, e.config.FundingInterval.Duration(), func(pos *marginPosition) fixedpoint.Value {
+			// the long position pays the short position when the funding rate is positive
+			return pos.Base.Mul(kline.Open).Mul(e.fundingRate(m.Market.Symbol))
+		})
+
+	case types.AccountTypeMargin:
+		hourlyRate := e.config.BorrowInterestRate.Div(fixedpoint.NewFromInt(24))
+		e.chargeAtIntervals(e.nextInterestTimes, m.Market.Symbol, startTime, borrowInterestInterval, func(pos *marginPosition) fixedpoint.Value {
+			// the long position borrows the quote currency that is not covered by the margin,
+			// the short position borrows the base currency
+			var borrowed fixedpoint.Value
+			if pos.Base.Sign() > 0 {
+				borrowed = fixedpoint.Max(pos.Base.Mul(pos.AverageCost).Sub(pos.Margin), fixedpoint.Zero)
+			} else {
+				borrowed = pos.Base.Abs().Mul(kline.Open)
+			}
+
+			return borrowed.Mul(hourlyRate)
+		})
+	}
+
+	if pos, ok := e.positions[m.Market.Symbol]; ok && !pos.Base.IsZero() {
+		m.EmitBalanceUpdate(m.account.Balances())
+	}
+}
+
+func (e *marginEngine) chargeAtIntervals(nextTimes map[string]time.Time, symbol string, now time.Time, interval time.Duration, fee func(pos *marginPosition) fixedpoint.Value) {
+	next, ok := nextTimes[symbol]
+	if !ok {
+		next = now.Truncate(interval)
+		if next.Before(now) {
+			next = next.Add(interval)
+		}
+	}
+
+	for ; !now.Before(next); next = next.Add(interval) {
+		pos, ok := e.positions[symbol]
+		if !ok || pos.Base.IsZero() {
+			continue
+		}
+
+		amount := fee(pos)
+		klineMatchingLogger.Debugf("charging %s %s fee %s at %s", symbol, e.config.Type, amount.String(), next)
+
+		e.account.AddBalance(e.books[symbol].Market.QuoteCurrency, amount.Neg())
+
+		// the fee of an isolated position is paid from its margin
+		if e.isIsolated() {
+			pos.Margin = pos.Margin.Sub(amount)
+		}
+	}
+
+	nextTimes[symbol] = next
+}
+
+// checkLiquidation liquidates the positions when the margin can not cover the maintenance margin,
+// the positions are closed at the current price of the matching books.
+func (e *marginEngine) checkLiquidation(m *SimplePriceMatching) {
+	ratio := e.config.MaintenanceMarginRatio
+
+	if e.isIsolated() {
+		pos, ok := e.positions[m.Market.Symbol]
+		if !ok || pos.Base.IsZero() {
+			return
+		}
+
+		if pos.Margin.Add(pos.UnrealizedProfit(m.lastPrice)).Compare(pos.MaintenanceMargin(m.lastPrice, ratio)) <= 0 {
+			e.liquidate(m, pos)
+		}
+		return
+	}
+
+	quoteCurrency := m.Market.QuoteCurrency
+	balance, _ := e.account.Balance(quoteCurrency)
+	equity := balance.Total()
+	maintenanceMargin := fixedpoint.Zero
+
+	var books []*SimplePriceMatching
+	for symbol, pos := range e.positions {
+		book, ok := e.books[symbol]
+		if !ok || book.Market.QuoteCurrency != quoteCurrency || pos.Base.IsZero() {
+			continue
+		}
+
+		price := e.markPrice(symbol, pos)
+		equity = equity.Add(pos.UnrealizedProfit(price))
+		maintenanceMargin = maintenanceMargin.Add(pos.MaintenanceMargin(price, ratio))
+		books = append(books, book)
+	}
+
+	if len(books) == 0 || equity.Compare(maintenanceMargin) > 0 {
+		return
+	}
+
+	for _, book := range books {
+		e.liquidate(book, e.positions[book.Market.Symbol])
+	}
+}
+
+// liquidate cancels the open orders of the symbol and closes the position with a market order
+func (e *marginEngine) liquidate(m *SimplePriceMatching, pos *marginPosition) {
+	price := m.lastPrice
+	if price.IsZero() {
+		price = pos.AverageCost
+	}
+
+	log.Warnf("liquidating %s position %s at price %s", m.Market.Symbol, pos.Base.String(), price.String())
+
+	for _, o := range append(append([]types.Order{}, m.bidOrders...), m.askOrders...) {
+		if _, err := m.CancelOrder(o); err != nil {
+			log.WithError(err).Errorf("can not cancel the order %d of the liquidated position", o.OrderID)
+		}
+	}
+
+	side := types.SideTypeSell
+	if pos.Base.Sign() < 0 {
+		side = types.SideTypeBuy
+	}
+
+	order := m.newOrder(types.SubmitOrder{
+		Symbol:   m.Market.Symbol,
+		Market:   m.Market,
+		Side:     side,
+		Type:     types.OrderTypeMarket,
+		Quantity: pos.Base.Abs(),
+		Price:    price,
+		Tag:      LiquidationOrderTag,
+	}, incOrderID())
+
+	order.Status = types.OrderStatusFilled
+	order.ExecutedQuantity = order.Quantity
+	order.IsWorking = false
+
+	trade := m.newTradeFromOrder(&order, false, price)
+	e.settleTrade(m, trade)
+
+	m.closedOrders[order.OrderID] = order
+	m.EmitTradeUpdate(trade)
+	m.EmitOrderUpdate(order)
+	m.EmitBalanceUpdate(m.account.Balances())
+}
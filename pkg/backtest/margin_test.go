@@ -0,0 +1,273 @@
+package backtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newMarketOrder(symbol string, side types.SideType, quantity float64) types.SubmitOrder {
+	return types.SubmitOrder{
+		Symbol:   symbol,
+		Side:     side,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.NewFromFloat(quantity),
+	}
+}
+
+func newMarginTestEngine(t *testing.T, config bbgo.BacktestMarginAccount, quote float64, startTime time.Time) *SimplePriceMatching {
+	account := &types.Account{AccountType: config.Type}
+	account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(quote)},
+	})
+
+	margin, err := newMarginEngine(account, config)
+	require.NoError(t, err)
+
+	engine := &SimplePriceMatching{
+		account:      account,
+		Market:       getTestMarket(),
+		currentTime:  startTime,
+		closedOrders: make(map[uint64]types.Order),
+		lastPrice:    fixedpoint.NewFromFloat(20000.0),
+		margin:       margin,
+	}
+
+	margin.books[engine.Market.Symbol] = engine
+	return engine
+}
+
+func usdtBalance(engine *SimplePriceMatching) types.Balance {
+	balance, _ := engine.account.Balance("USDT")
+	return balance
+}
+
+func Test_newMarginEngine(t *testing.T) {
+	_, err := newMarginEngine(&types.Account{}, bbgo.BacktestMarginAccount{Type: types.AccountTypeSpot})
+	assert.Error(t, err)
+
+	_, err = newMarginEngine(&types.Account{}, bbgo.BacktestMarginAccount{Type: types.AccountTypeFutures, MarginMode: "portfolio"})
+	assert.Error(t, err)
+
+	engine, err := newMarginEngine(&types.Account{}, bbgo.BacktestMarginAccount{Type: types.AccountTypeFutures})
+	if assert.NoError(t, err) {
+		assert.Equal(t, bbgo.BacktestMarginModeCross, engine.config.MarginMode)
+		assert.Equal(t, "1", engine.config.Leverage.String())
+		assert.Equal(t, defaultFundingInterval, engine.config.FundingInterval.Duration())
+	}
+}
+
+func Test_marginPosition_apply(t *testing.T) {
+	leverage := fixedpoint.NewFromInt(10)
+	pos := &marginPosition{}
+
+	profit := pos.apply(fixedpoint.NewFromFloat(1.0), fixedpoint.NewFromFloat(100.0), leverage)
+	assert.Equal(t, "0", profit.String())
+	profit = pos.apply(fixedpoint.NewFromFloat(1.0), fixedpoint.NewFromFloat(200.0), leverage)
+	assert.Equal(t, "0", profit.String())
+	assert.Equal(t, "2", pos.Base.String())
+	assert.Equal(t, "150", pos.AverageCost.String())
+	assert.Equal(t, "30", pos.Margin.String())
+
+	// close 2 and open a short position of 1
+	profit = pos.apply(fixedpoint.NewFromFloat(-3.0), fixedpoint.NewFromFloat(160.0), leverage)
+	assert.Equal(t, "20", profit.String())
+	assert.Equal(t, "-1", pos.Base.String())
+	assert.Equal(t, "160", pos.AverageCost.String())
+	assert.Equal(t, "16", pos.Margin.String())
+
+	profit = pos.apply(fixedpoint.NewFromFloat(1.0), fixedpoint.NewFromFloat(150.0), leverage)
+	assert.Equal(t, "10", profit.String())
+	assert.True(t, pos.Base.IsZero())
+	assert.True(t, pos.Margin.IsZero())
+}
+
+func TestSimplePriceMatching_InsufficientMargin(t *testing.T) {
+	t1 := time.Date(2021, 7, 1, 1, 0, 0, 0, time.UTC)
+	engine := newMarginTestEngine(t, bbgo.BacktestMarginAccount{
+		Type:     types.AccountTypeFutures,
+		Leverage: fixedpoint.NewFromInt(10),
+	}, 1000.0, t1)
+
+	// requires 2000 USDT initial margin
+	_, _, err := engine.PlaceOrder(newMarketOrder("BTCUSDT", types.SideTypeBuy, 1.0))
+	assert.ErrorIs(t, err, ErrInsufficientMargin)
+
+	order, trade, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 19000.0, 0.5))
+	if assert.NoError(t, err) {
+		assert.Nil(t, trade)
+		assert.Equal(t, "950", usdtBalance(engine).Locked.String())
+	}
+
+	// the rest of the margin is not enough for another order
+	_, _, err = engine.PlaceOrder(newMarketOrder("BTCUSDT", types.SideTypeBuy, 0.1))
+	assert.ErrorIs(t, err, ErrInsufficientMargin)
+
+	_, err = engine.CancelOrder(*order)
+	assert.NoError(t, err)
+	assert.Equal(t, "0", usdtBalance(engine).Locked.String())
+
+	_, trade, err = engine.PlaceOrder(newMarketOrder("BTCUSDT", types.SideTypeSell, 0.4))
+	if assert.NoError(t, err) && assert.NotNil(t, trade) {
+		// the short position is opened without the base balance
+		assert.Equal(t, "-0.4", engine.margin.positions["BTCUSDT"].Base.String())
+		assert.Equal(t, "1000", usdtBalance(engine).Available.String())
+	}
+
+	// the reducing order does not require margin
+	_, _, err = engine.PlaceOrder(newMarketOrder("BTCUSDT", types.SideTypeBuy, 0.4))
+	assert.NoError(t, err)
+	assert.True(t, engine.margin.positions["BTCUSDT"].Base.IsZero())
+}
+
+func TestSimplePriceMatching_ReduceOnly(t *testing.T) {
+	t1 := time.Date(2021, 7, 1, 1, 0, 0, 0, time.UTC)
+	engine := newMarginTestEngine(t, bbgo.BacktestMarginAccount{
+		Type:     types.AccountTypeFutures,
+		Leverage: fixedpoint.NewFromInt(10),
+	}, 1000.0, t1)
+
+	reduceOnly := newMarketOrder("BTCUSDT", types.SideTypeSell, 0.5)
+	reduceOnly.ReduceOnly = true
+
+	// no position to reduce
+	_, _, err := engine.PlaceOrder(reduceOnly)
+	assert.ErrorIs(t, err, ErrReduceOnlyRejected)
+
+	_, _, err = engine.PlaceOrder(newMarketOrder("BTCUSDT", types.SideTypeBuy, 0.3))
+	assert.NoError(t, err)
+
+	// the reduce-only order of the same side can not increase the position
+	increase := newMarketOrder("BTCUSDT", types.SideTypeBuy, 0.1)
+	increase.ReduceOnly = true
+	_, _, err = engine.PlaceOrder(increase)
+	assert.ErrorIs(t, err, ErrReduceOnlyRejected)
+
+	// the quantity is capped by the position, the short position is not opened
+	_, trade, err := engine.PlaceOrder(reduceOnly)
+	if assert.NoError(t, err) && assert.NotNil(t, trade) {
+		assert.Equal(t, "0.3", trade.Quantity.String())
+		assert.True(t, engine.margin.positions["BTCUSDT"].Base.IsZero())
+	}
+
+	_, _, err = engine.PlaceOrder(newMarketOrder("BTCUSDT", types.SideTypeSell, 0.2))
+	assert.NoError(t, err)
+
+	closePosition := newMarketOrder("BTCUSDT", types.SideTypeBuy, 0)
+	closePosition.ClosePosition = true
+	_, trade, err = engine.PlaceOrder(closePosition)
+	if assert.NoError(t, err) && assert.NotNil(t, trade) {
+		assert.Equal(t, "0.2", trade.Quantity.String())
+		assert.True(t, engine.margin.positions["BTCUSDT"].Base.IsZero())
+	}
+}
+
+func TestSimplePriceMatching_FundingFee(t *testing.T) {
+	t1 := time.Date(2021, 7, 1, 6, 0, 0, 0, time.UTC)
+	engine := newMarginTestEngine(t, bbgo.BacktestMarginAccount{
+		Type:         types.AccountTypeFutures,
+		Leverage:     fixedpoint.NewFromInt(10),
+		FundingRates: map[string]fixedpoint.Value{"BTCUSDT": fixedpoint.NewFromFloat(0.001)},
+	}, 1000.0, t1)
+
+	_, _, err := engine.PlaceOrder(newMarketOrder("BTCUSDT", types.SideTypeBuy, 0.1))
+	require.NoError(t, err)
+
+	// no funding before 08:00
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1h, t1, 20000, 20100, 19900, 20000))
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1h, t1.Add(time.Hour), 20000, 20100, 19900, 20000))
+	assert.Equal(t, "1000", usdtBalance(engine).Available.String())
+
+	// the long position pays 0.1 * 20000 * 0.1%
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1h, t1.Add(2*time.Hour), 20000, 20100, 19900, 20000))
+	assert.Equal(t, "998", usdtBalance(engine).Available.String())
+
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1h, t1.Add(3*time.Hour), 20000, 20100, 19900, 20000))
+	assert.Equal(t, "998", usdtBalance(engine).Available.String())
+}
+
+func TestSimplePriceMatching_BorrowInterest(t *testing.T) {
+	t1 := time.Date(2021, 7, 1, 0, 30, 0, 0, time.UTC)
+	engine := newMarginTestEngine(t, bbgo.BacktestMarginAccount{
+		Type:               types.AccountTypeMargin,
+		Leverage:           fixedpoint.NewFromInt(2),
+		BorrowInterestRate: fixedpoint.NewFromFloat(0.024),
+	}, 1000.0, t1)
+
+	_, _, err := engine.PlaceOrder(newMarketOrder("BTCUSDT", types.SideTypeBuy, 0.1))
+	require.NoError(t, err)
+
+	// the interest of the borrowed 1000 USDT is charged at 01:00 and 02:00
+	engine.processKLine(newKLine("BTCUSDT", types.Interval30m, t1, 20000, 20100, 19900, 20000))
+	engine.processKLine(newKLine("BTCUSDT", types.Interval30m, t1.Add(30*time.Minute), 20000, 20100, 19900, 20000))
+	engine.processKLine(newKLine("BTCUSDT", types.Interval30m, t1.Add(60*time.Minute), 20000, 20100, 19900, 20000))
+	engine.processKLine(newKLine("BTCUSDT", types.Interval30m, t1.Add(90*time.Minute), 20000, 20100, 19900, 20000))
+	assert.Equal(t, "998", usdtBalance(engine).Available.String())
+}
+
+func TestSimplePriceMatching_IsolatedLiquidation(t *testing.T) {
+	t1 := time.Date(2021, 7, 1, 1, 0, 0, 0, time.UTC)
+	engine := newMarginTestEngine(t, bbgo.BacktestMarginAccount{
+		Type:       types.AccountTypeFutures,
+		MarginMode: bbgo.BacktestMarginModeIsolated,
+		Leverage:   fixedpoint.NewFromInt(10),
+	}, 1000.0, t1)
+
+	var trades []types.Trade
+	engine.OnTradeUpdate(func(trade types.Trade) {
+		trades = append(trades, trade)
+	})
+
+	_, _, err := engine.PlaceOrder(newMarketOrder("BTCUSDT", types.SideTypeBuy, 0.1))
+	require.NoError(t, err)
+
+	_, _, err = engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeSell, 21000.0, 0.1))
+	require.NoError(t, err)
+
+	// 19000 is still above the maintenance margin
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, t1, 20000, 20000, 19000, 19500))
+	assert.Len(t, trades, 1)
+
+	// the position is liquidated at 17000, the loss is limited to the position margin
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, t1.Add(time.Minute), 19500, 19500, 17000, 17500))
+	if assert.Len(t, trades, 2) {
+		assert.Equal(t, types.SideTypeSell, trades[1].Side)
+		assert.Equal(t, "17000", trades[1].Price.String())
+	}
+
+	assert.True(t, engine.margin.positions["BTCUSDT"].Base.IsZero())
+	assert.Len(t, engine.askOrders, 0)
+	assert.Equal(t, "800", usdtBalance(engine).Available.String())
+	assert.Equal(t, "0", usdtBalance(engine).Locked.String())
+
+	var liquidated bool
+	for _, o := range engine.closedOrders {
+		liquidated = liquidated || o.Tag == LiquidationOrderTag
+	}
+	assert.True(t, liquidated)
+}
+
+func TestSimplePriceMatching_CrossLiquidation(t *testing.T) {
+	t1 := time.Date(2021, 7, 1, 1, 0, 0, 0, time.UTC)
+	engine := newMarginTestEngine(t, bbgo.BacktestMarginAccount{
+		Type:     types.AccountTypeFutures,
+		Leverage: fixedpoint.NewFromInt(10),
+	}, 1000.0, t1)
+
+	_, _, err := engine.PlaceOrder(newMarketOrder("BTCUSDT", types.SideTypeBuy, 0.1))
+	require.NoError(t, err)
+
+	// the wallet balance covers the loss of the cross position
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, t1, 20000, 20000, 11000, 11000))
+	assert.Equal(t, "0.1", engine.margin.positions["BTCUSDT"].Base.String())
+
+	engine.processKLine(newKLine("BTCUSDT", types.Interval1m, t1.Add(time.Minute), 11000, 11000, 10000, 10500))
+	assert.True(t, engine.margin.positions["BTCUSDT"].Base.IsZero())
+	assert.Equal(t, "0", usdtBalance(engine).Available.String())
+}
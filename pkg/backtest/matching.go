@@ -66,8 +66,14 @@ type SimplePriceMatching struct {
 
 	feeModeFunction FeeModeFunction
 
+	// pricePathFunction overrides the default intra-bar price path if it's set
+	pricePathFunction PricePathFunction
+
 	account *types.Account
 
+	// margin simulates the leveraged account, it's nil for the spot account
+	margin *marginEngine
+
 	tradeUpdateCallbacks   []func(trade types.Trade)
 	orderUpdateCallbacks   []func(order types.Order)
 	balanceUpdateCallbacks []func(balances types.BalanceMap)
@@ -109,15 +115,21 @@ func (m *SimplePriceMatching) CancelOrder(o types.Order) (types.Order, error) {
 		return o, fmt.Errorf("cancel order failed, order %d not found: %+v", o.OrderID, o)
 	}
 
-	switch o.Side {
-	case types.SideTypeBuy:
-		if err := m.account.UnlockBalance(m.Market.QuoteCurrency, o.Price.Mul(o.Quantity)); err != nil {
+	if m.margin != nil {
+		if err := m.margin.unlockOrderMargin(m.Market, o.OrderID); err != nil {
 			return o, err
 		}
+	} else {
+		switch o.Side {
+		case types.SideTypeBuy:
+			if err := m.account.UnlockBalance(m.Market.QuoteCurrency, o.Price.Mul(o.Quantity)); err != nil {
+				return o, err
+			}
 
-	case types.SideTypeSell:
-		if err := m.account.UnlockBalance(m.Market.BaseCurrency, o.Quantity); err != nil {
-			return o, err
+		case types.SideTypeSell:
+			if err := m.account.UnlockBalance(m.Market.BaseCurrency, o.Quantity); err != nil {
+				return o, err
+			}
 		}
 	}
 
@@ -154,26 +166,44 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *ty
 		price = o.Price
 	}
 
+	if m.margin != nil && (o.ReduceOnly || o.ClosePosition) {
+		quantity, err := m.margin.reduceOnlyQuantity(o)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		o.Quantity = quantity
+	}
+
 	o.Quantity = m.Market.TruncateQuantity(o.Quantity)
 
 	if o.Quantity.Compare(m.Market.MinQuantity) < 0 {
-		return nil, nil, fmt.Errorf("order quantity %s is less than minQuantity %s, order: %+v", o.Quantity.String(), m.Market.MinQuantity.String(), o)
+		return nil, nil, errors.Wrapf(ErrMinQuantity, "order quantity %s is less than minQuantity %s, order: %+v", o.Quantity.String(), m.Market.MinQuantity.String(), o)
 	}
 
 	quoteQuantity := o.Quantity.Mul(price)
 	if quoteQuantity.Compare(m.Market.MinNotional) < 0 {
-		return nil, nil, fmt.Errorf("order amount %s is less than minNotional %s, order: %+v", quoteQuantity.String(), m.Market.MinNotional.String(), o)
+		return nil, nil, errors.Wrapf(ErrMinNotional, "order amount %s is less than minNotional %s, order: %+v", quoteQuantity.String(), m.Market.MinNotional.String(), o)
 	}
 
-	switch o.Side {
-	case types.SideTypeBuy:
-		if err := m.account.LockBalance(m.Market.QuoteCurrency, quoteQuantity); err != nil {
+	var orderMargin fixedpoint.Value
+	if m.margin != nil {
+		var err error
+		orderMargin, err = m.margin.lockOrderMargin(m.Market, o, price)
+		if err != nil {
 			return nil, nil, err
 		}
+	} else {
+		switch o.Side {
+		case types.SideTypeBuy:
+			if err := m.account.LockBalance(m.Market.QuoteCurrency, quoteQuantity); err != nil {
+				return nil, nil, err
+			}
 
-	case types.SideTypeSell:
-		if err := m.account.LockBalance(m.Market.BaseCurrency, o.Quantity); err != nil {
-			return nil, nil, err
+		case types.SideTypeSell:
+			if err := m.account.LockBalance(m.Market.BaseCurrency, o.Quantity); err != nil {
+				return nil, nil, err
+			}
 		}
 	}
 
@@ -183,6 +213,10 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *ty
 	orderID := incOrderID()
 	order := m.newOrder(o, orderID)
 
+	if m.margin != nil && !orderMargin.IsZero() {
+		m.margin.orderMargins[orderID] = orderMargin
+	}
+
 	if isTaker {
 		var price fixedpoint.Value
 		if order.Type == types.OrderTypeMarket {
@@ -213,8 +247,8 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *ty
 		trade := m.newTradeFromOrder(&order2, false, price)
 		m.executeTrade(trade)
 
-		// unlock the rest balances for limit taker
-		if order.Type == types.OrderTypeLimit {
+		// unlock the rest balances for limit taker, the margin account releases the whole order margin when the trade is executed
+		if order.Type == types.OrderTypeLimit && m.margin == nil {
 			if order.AveragePrice.IsZero() {
 				return nil, nil, fmt.Errorf("the average price of the given limit taker order can not be zero")
 			}
@@ -272,6 +306,16 @@ func (m *SimplePriceMatching) PlaceOrder(o types.SubmitOrder) (*types.Order, *ty
 }
 
 func (m *SimplePriceMatching) executeTrade(trade types.Trade) {
+	if m.margin != nil {
+		if err := m.margin.executeTrade(m, trade); err != nil {
+			panic(errors.Wrapf(err, "executeTrade exception, can not release the order margin"))
+		}
+
+		m.EmitTradeUpdate(trade)
+		m.EmitBalanceUpdate(m.account.Balances())
+		return
+	}
+
 	var err error
 	// execute trade, update account balances
 	if trade.IsBuyer {
@@ -486,6 +530,10 @@ func (m *SimplePriceMatching) buyToPrice(price fixedpoint.Value) (closedOrders [
 		m.closedOrders[o.OrderID] = o
 	}
 
+	if m.margin != nil {
+		m.margin.checkLiquidation(m)
+	}
+
 	return closedOrders, trades
 }
 
@@ -615,6 +663,10 @@ func (m *SimplePriceMatching) sellToPrice(price fixedpoint.Value) (closedOrders
 		m.closedOrders[o.OrderID] = o
 	}
 
+	if m.margin != nil {
+		m.margin.checkLiquidation(m)
+	}
+
 	return closedOrders, trades
 }
 
@@ -641,6 +693,10 @@ func (m *SimplePriceMatching) getOrder(orderID uint64) (types.Order, bool) {
 func (m *SimplePriceMatching) processKLine(kline types.KLine) {
 	m.currentTime = kline.EndTime.Time()
 
+	if m.margin != nil {
+		m.margin.chargePeriodicFees(m, kline)
+	}
+
 	if m.lastPrice.IsZero() {
 		m.lastPrice = kline.Open
 	} else {
@@ -651,6 +707,12 @@ func (m *SimplePriceMatching) processKLine(kline types.KLine) {
 		}
 	}
 
+	if m.pricePathFunction != nil {
+		m.walkPricePath(kline.Open, m.pricePathFunction(kline))
+		m.lastKLine = kline
+		return
+	}
+
 	switch kline.Direction() {
 	case types.DirectionDown:
 		if kline.High.Compare(kline.Open) >= 0 {
@@ -685,6 +747,21 @@ func (m *SimplePriceMatching) processKLine(kline types.KLine) {
 	m.lastKLine = kline
 }
 
+// walkPricePath moves the price along the path from the open price
+func (m *SimplePriceMatching) walkPricePath(open fixedpoint.Value, path []fixedpoint.Value) {
+	last := open
+	for _, price := range path {
+		switch price.Compare(last) {
+		case 1:
+			m.buyToPrice(price)
+		case -1:
+			m.sellToPrice(price)
+		}
+
+		last = price
+	}
+}
+
 func (m *SimplePriceMatching) newOrder(o types.SubmitOrder, orderID uint64) types.Order {
 	return types.Order{
 		OrderID:          orderID,
@@ -1,6 +1,7 @@
 package backtest
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -527,3 +528,52 @@ func TestSimplePriceMatching_LimitTakerOrder(t *testing.T) {
 		}
 	}
 }
+
+func TestExchange_RejectedOrders(t *testing.T) {
+	account := getTestAccount()
+	market := getTestMarket()
+	market.MinNotional = fixedpoint.NewFromFloat(10.0)
+
+	engine := &SimplePriceMatching{
+		account:      account,
+		Market:       market,
+		closedOrders: make(map[uint64]types.Order),
+		lastPrice:    fixedpoint.NewFromFloat(19000.0),
+	}
+
+	ex := &Exchange{
+		account:        account,
+		closedOrders:   make(map[string][]types.Order),
+		rejectedOrders: make(map[string]int),
+		matchingBooks:  map[string]*SimplePriceMatching{market.Symbol: engine},
+	}
+
+	_, err := ex.SubmitOrder(context.Background(), types.SubmitOrder{
+		Symbol:   market.Symbol,
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Quantity: fixedpoint.NewFromFloat(0.0001),
+		Price:    fixedpoint.NewFromFloat(18000.0),
+	})
+	assert.ErrorIs(t, err, ErrMinQuantity)
+
+	_, err = ex.SubmitOrder(context.Background(), types.SubmitOrder{
+		Symbol:   market.Symbol,
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Quantity: fixedpoint.NewFromFloat(0.001),
+		Price:    fixedpoint.NewFromFloat(5000.0),
+	})
+	assert.ErrorIs(t, err, ErrMinNotional)
+
+	_, err = ex.SubmitOrder(context.Background(), types.SubmitOrder{
+		Symbol:   market.Symbol,
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Quantity: fixedpoint.NewFromFloat(0.001),
+		Price:    fixedpoint.NewFromFloat(18000.0),
+	})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, ex.RejectedOrders(market.Symbol))
+}
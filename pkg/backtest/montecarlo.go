@@ -0,0 +1,179 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/fatih/color"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultMonteCarloRuns = 1000
+
+var defaultMonteCarloConfidenceLevel = fixedpoint.NewFromFloat(0.95)
+
+// MonteCarloInterval is the confidence interval of a simulated value
+type MonteCarloInterval struct {
+	Lower  fixedpoint.Value `json:"lower"`
+	Median fixedpoint.Value `json:"median"`
+	Upper  fixedpoint.Value `json:"upper"`
+}
+
+// MonteCarloReport is the result of the monte carlo analysis of the trade sequence
+type MonteCarloReport struct {
+	Method          bbgo.BacktestMonteCarloMethod `json:"method"`
+	Runs            int                           `json:"runs"`
+	Trades          int                           `json:"trades"`
+	ConfidenceLevel fixedpoint.Value              `json:"confidenceLevel"`
+
+	FinalEquity MonteCarloInterval `json:"finalEquity"`
+
+	// MaxDrawdown is the max drawdown ratio of the simulated equity curves
+	MaxDrawdown MonteCarloInterval `json:"maxDrawdown"`
+
+	// LossProbability is the ratio of the runs that end with a final equity lower than the initial equity
+	LossProbability fixedpoint.Value `json:"lossProbability"`
+}
+
+func (r *MonteCarloReport) Print(quoteCurrency string) {
+	color.Green("MONTE CARLO (%s, %d RUNS OF %d TRADES, %s CONFIDENCE):",
+		r.Method, r.Runs, r.Trades, r.ConfidenceLevel.FormatPercentage(0))
+	color.Green("  FINAL EQUITY: %s ~ %s %s (median %s)",
+		r.FinalEquity.Lower.FormatString(2), r.FinalEquity.Upper.FormatString(2), quoteCurrency, r.FinalEquity.Median.FormatString(2))
+	color.Green("  MAX DRAWDOWN: %s ~ %s (median %s)",
+		r.MaxDrawdown.Lower.FormatPercentage(2), r.MaxDrawdown.Upper.FormatPercentage(2), r.MaxDrawdown.Median.FormatPercentage(2))
+	color.Green("  LOSS PROBABILITY: %s", r.LossProbability.FormatPercentage(2))
+}
+
+// tradeOutcome is the net profit of a trade that closes the position
+type tradeOutcome struct {
+	profit        float64
+	quoteQuantity float64
+}
+
+func collectTradeOutcomes(market types.Market, trades []types.Trade) (outcomes []tradeOutcome) {
+	position := types.NewPositionFromMarket(market)
+	for _, trade := range trades {
+		if _, netProfit, madeProfit := position.AddTrade(trade); madeProfit {
+			outcomes = append(outcomes, tradeOutcome{
+				profit:        netProfit.Float64(),
+				quoteQuantity: trade.QuoteQuantity.Float64(),
+			})
+		}
+	}
+
+	return outcomes
+}
+
+// RunMonteCarlo resamples the profits of the closing trades and adds the price noise to them,
+// the confidence intervals of the final equity and the max drawdown are calculated from the simulated equity curves.
+// nil is returned if there is no closing trade.
+func RunMonteCarlo(config bbgo.BacktestMonteCarlo, market types.Market, trades []types.Trade, initialEquity fixedpoint.Value) (*MonteCarloReport, error) {
+	switch config.Method {
+	case "":
+		config.Method = bbgo.BacktestMonteCarloMethodBootstrap
+	case bbgo.BacktestMonteCarloMethodBootstrap, bbgo.BacktestMonteCarloMethodPermutation:
+	default:
+		return nil, fmt.Errorf("unsupported monte carlo method %q, valid methods are: %s, %s",
+			config.Method, bbgo.BacktestMonteCarloMethodBootstrap, bbgo.BacktestMonteCarloMethodPermutation)
+	}
+
+	if config.Runs <= 0 {
+		config.Runs = defaultMonteCarloRuns
+	}
+
+	if config.ConfidenceLevel.IsZero() {
+		config.ConfidenceLevel = defaultMonteCarloConfidenceLevel
+	} else if config.ConfidenceLevel.Sign() < 0 || config.ConfidenceLevel.Compare(fixedpoint.One) >= 0 {
+		return nil, fmt.Errorf("monte carlo confidence level should be in (0, 1), got %s", config.ConfidenceLevel.String())
+	}
+
+	outcomes := collectTradeOutcomes(market, trades)
+	if len(outcomes) == 0 {
+		return nil, nil
+	}
+
+	rnd := rand.New(rand.NewSource(config.Seed))
+	noise := config.PriceNoise.Float64()
+	initial := initialEquity.Float64()
+
+	finalEquities := make([]float64, config.Runs)
+	maxDrawdowns := make([]float64, config.Runs)
+	losses := 0
+
+	sequence := make([]tradeOutcome, len(outcomes))
+	for run := 0; run < config.Runs; run++ {
+		switch config.Method {
+		case bbgo.BacktestMonteCarloMethodBootstrap:
+			for i := range sequence {
+				sequence[i] = outcomes[rnd.Intn(len(outcomes))]
+			}
+
+		case bbgo.BacktestMonteCarloMethodPermutation:
+			copy(sequence, outcomes)
+			rnd.Shuffle(len(sequence), func(i, j int) {
+				sequence[i], sequence[j] = sequence[j], sequence[i]
+			})
+		}
+
+		equity, peak, maxDrawdown := initial, initial, 0.0
+		for _, outcome := range sequence {
+			equity += outcome.profit
+			if noise > 0 {
+				equity += rnd.NormFloat64() * noise * outcome.quoteQuantity
+			}
+
+			if equity > peak {
+				peak = equity
+			} else if peak > 0 {
+				maxDrawdown = math.Max(maxDrawdown, math.Min((peak-equity)/peak, 1.0))
+			}
+		}
+
+		finalEquities[run] = equity
+		maxDrawdowns[run] = maxDrawdown
+		if equity < initial {
+			losses++
+		}
+	}
+
+	confidence := config.ConfidenceLevel.Float64()
+	return &MonteCarloReport{
+		Method:          config.Method,
+		Runs:            config.Runs,
+		Trades:          len(outcomes),
+		ConfidenceLevel: config.ConfidenceLevel,
+		FinalEquity:     newMonteCarloInterval(finalEquities, confidence),
+		MaxDrawdown:     newMonteCarloInterval(maxDrawdowns, confidence),
+		LossProbability: fixedpoint.NewFromFloat(float64(losses) / float64(config.Runs)),
+	}, nil
+}
+
+func newMonteCarloInterval(values []float64, confidence float64) MonteCarloInterval {
+	sort.Float64s(values)
+	return MonteCarloInterval{
+		Lower:  fixedpoint.NewFromFloat(percentile(values, (1.0-confidence)/2.0)),
+		Median: fixedpoint.NewFromFloat(percentile(values, 0.5)),
+		Upper:  fixedpoint.NewFromFloat(percentile(values, (1.0+confidence)/2.0)),
+	}
+}
+
+// percentile returns the value at the given percentile of the sorted values with the linear interpolation
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 1 {
+		return sorted[0]
+	}
+
+	rank := p * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	if lower >= len(sorted)-1 {
+		return sorted[len(sorted)-1]
+	}
+
+	return sorted[lower] + (sorted[lower+1]-sorted[lower])*(rank-float64(lower))
+}
@@ -0,0 +1,111 @@
+package backtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newRoundTripTrades(entryExits ...float64) (trades []types.Trade) {
+	for i := 0; i+1 < len(entryExits); i += 2 {
+		for j, side := range []types.SideType{types.SideTypeBuy, types.SideTypeSell} {
+			price := fixedpoint.NewFromFloat(entryExits[i+j])
+			trades = append(trades, types.Trade{
+				ID:            uint64(len(trades) + 1),
+				Symbol:        "BTCUSDT",
+				Side:          side,
+				IsBuyer:       side == types.SideTypeBuy,
+				Price:         price,
+				Quantity:      fixedpoint.One,
+				QuoteQuantity: price,
+				Fee:           fixedpoint.Zero,
+				FeeCurrency:   "USDT",
+			})
+		}
+	}
+
+	return trades
+}
+
+func TestRunMonteCarlo(t *testing.T) {
+	market := getTestMarket()
+	trades := newRoundTripTrades(100, 110, 100, 95, 100, 120)
+	initialEquity := fixedpoint.NewFromFloat(1000.0)
+
+	t.Run("permutation", func(t *testing.T) {
+		report, err := RunMonteCarlo(bbgo.BacktestMonteCarlo{
+			Method: bbgo.BacktestMonteCarloMethodPermutation,
+			Runs:   200,
+		}, market, trades, initialEquity)
+		if assert.NoError(t, err) && assert.NotNil(t, report) {
+			assert.Equal(t, 3, report.Trades)
+			assert.Equal(t, "0.95", report.ConfidenceLevel.String())
+
+			// the order of the trades does not change the final equity
+			assert.Equal(t, "1025", report.FinalEquity.Lower.String())
+			assert.Equal(t, "1025", report.FinalEquity.Upper.String())
+			assert.Equal(t, "0", report.LossProbability.String())
+
+			// the loss of 5 is taken at the peak of 1000, 1010, 1020 or 1030
+			assert.InDelta(t, 5.0/1030.0, report.MaxDrawdown.Lower.Float64(), 1e-6)
+			assert.InDelta(t, 5.0/1000.0, report.MaxDrawdown.Upper.Float64(), 1e-6)
+		}
+	})
+
+	t.Run("bootstrap", func(t *testing.T) {
+		config := bbgo.BacktestMonteCarlo{Runs: 500, Seed: 7}
+		report, err := RunMonteCarlo(config, market, trades, initialEquity)
+		if assert.NoError(t, err) && assert.NotNil(t, report) {
+			assert.Equal(t, bbgo.BacktestMonteCarloMethodBootstrap, report.Method)
+			assert.True(t, report.FinalEquity.Lower.Compare(report.FinalEquity.Upper) < 0)
+			assert.True(t, report.FinalEquity.Lower.Float64() >= 985.0)
+			assert.True(t, report.FinalEquity.Upper.Float64() <= 1060.0)
+			assert.True(t, report.LossProbability.Sign() > 0)
+		}
+
+		// the same seed gives the same result
+		report2, err := RunMonteCarlo(config, market, trades, initialEquity)
+		if assert.NoError(t, err) {
+			assert.Equal(t, report, report2)
+		}
+	})
+
+	t.Run("price noise", func(t *testing.T) {
+		report, err := RunMonteCarlo(bbgo.BacktestMonteCarlo{
+			Method:     bbgo.BacktestMonteCarloMethodPermutation,
+			Runs:       500,
+			PriceNoise: fixedpoint.NewFromFloat(0.01),
+		}, market, trades, initialEquity)
+		if assert.NoError(t, err) && assert.NotNil(t, report) {
+			assert.True(t, report.FinalEquity.Lower.Float64() < 1025.0)
+			assert.True(t, report.FinalEquity.Upper.Float64() > 1025.0)
+		}
+	})
+
+	t.Run("no closing trades", func(t *testing.T) {
+		report, err := RunMonteCarlo(bbgo.BacktestMonteCarlo{}, market, trades[:1], initialEquity)
+		assert.NoError(t, err)
+		assert.Nil(t, report)
+	})
+
+	t.Run("invalid config", func(t *testing.T) {
+		_, err := RunMonteCarlo(bbgo.BacktestMonteCarlo{Method: "jackknife"}, market, trades, initialEquity)
+		assert.Error(t, err)
+
+		_, err = RunMonteCarlo(bbgo.BacktestMonteCarlo{ConfidenceLevel: fixedpoint.One}, market, trades, initialEquity)
+		assert.Error(t, err)
+	})
+}
+
+func Test_percentile(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5}
+	assert.Equal(t, 1.0, percentile(values, 0))
+	assert.Equal(t, 3.0, percentile(values, 0.5))
+	assert.Equal(t, 1.4, percentile(values, 0.1))
+	assert.Equal(t, 5.0, percentile(values, 1))
+	assert.Equal(t, 2.0, percentile([]float64{2}, 0.5))
+}
@@ -0,0 +1,132 @@
+package backtest
+
+import (
+	"math/rand"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultBrownianBridgeSteps = 20
+
+// PricePathFunction returns the prices visited within the kline after the open price, the last price must be the close price
+type PricePathFunction func(kline types.KLine) []fixedpoint.Value
+
+func pricePathOHLC(kline types.KLine) []fixedpoint.Value {
+	return []fixedpoint.Value{kline.High, kline.Low, kline.Close}
+}
+
+func pricePathOLHC(kline types.KLine) []fixedpoint.Value {
+	return []fixedpoint.Value{kline.Low, kline.High, kline.Close}
+}
+
+func pricePathNearest(kline types.KLine) []fixedpoint.Value {
+	if kline.High.Sub(kline.Open).Compare(kline.Open.Sub(kline.Low)) <= 0 {
+		return pricePathOHLC(kline)
+	}
+
+	return pricePathOLHC(kline)
+}
+
+// newBrownianBridgePricePath simulates a brownian bridge from open to close,
+// then stretches the parts above and below the body so that the path touches the high and the low exactly.
+func newBrownianBridgePricePath(steps int, seed int64) PricePathFunction {
+	if steps <= 1 {
+		steps = defaultBrownianBridgeSteps
+	}
+
+	rnd := rand.New(rand.NewSource(seed))
+
+	return func(kline types.KLine) []fixedpoint.Value {
+		open, closePrice := kline.Open.Float64(), kline.Close.Float64()
+		high, low := kline.High.Float64(), kline.Low.Float64()
+
+		// standard brownian bridge from 0 to 0, w[i] = b[i] - (i/n) * b[n]
+		walk := make([]float64, steps+1)
+		for i := 1; i <= steps; i++ {
+			walk[i] = walk[i-1] + rnd.NormFloat64()
+		}
+
+		path := make([]float64, steps+1)
+		for i := 0; i <= steps; i++ {
+			t := float64(i) / float64(steps)
+			path[i] = open + (closePrice-open)*t + (walk[i] - t*walk[steps])
+		}
+
+		bodyHigh, bodyLow := open, closePrice
+		if bodyHigh < bodyLow {
+			bodyHigh, bodyLow = bodyLow, bodyHigh
+		}
+
+		maxIdx, minIdx := 1, 1
+		for i := 1; i < steps; i++ {
+			if path[i] > path[maxIdx] {
+				maxIdx = i
+			}
+
+			if path[i] < path[minIdx] {
+				minIdx = i
+			}
+		}
+
+		pathMax, pathMin := path[maxIdx], path[minIdx]
+		for i := 1; i < steps; i++ {
+			switch {
+			case path[i] > bodyHigh:
+				path[i] = bodyHigh + (path[i]-bodyHigh)*(high-bodyHigh)/(pathMax-bodyHigh)
+			case path[i] < bodyLow:
+				path[i] = bodyLow - (bodyLow-path[i])*(bodyLow-low)/(bodyLow-pathMin)
+			}
+		}
+
+		// the walk never left the body on one side, pin the extreme at the step nearest to that side
+		if pathMax <= bodyHigh && high > bodyHigh {
+			path[maxIdx] = high
+		}
+
+		if pathMin >= bodyLow && low < bodyLow && minIdx != maxIdx {
+			path[minIdx] = low
+		}
+
+		prices := make([]fixedpoint.Value, 0, steps+2)
+		for i := 1; i < steps; i++ {
+			prices = append(prices, fixedpoint.NewFromFloat(path[i]))
+		}
+
+		// use the exact kline prices for the extremes to avoid the float rounding errors
+		if high > bodyHigh {
+			prices[maxIdx-1] = kline.High
+		}
+
+		if low < bodyLow && minIdx != maxIdx {
+			prices[minIdx-1] = kline.Low
+		}
+
+		return append(prices, kline.Close)
+	}
+}
+
+// getPricePathFunction returns nil for the default direction model, which is handled by the matching engine itself
+func getPricePathFunction(pricePath *bbgo.BacktestPricePath) PricePathFunction {
+	if pricePath == nil {
+		return nil
+	}
+
+	switch pricePath.Model {
+	case bbgo.BacktestPricePathModelOHLC:
+		return pricePathOHLC
+
+	case bbgo.BacktestPricePathModelOLHC:
+		return pricePathOLHC
+
+	case bbgo.BacktestPricePathModelNearest:
+		return pricePathNearest
+
+	case bbgo.BacktestPricePathModelBrownianBridge:
+		return newBrownianBridgePricePath(pricePath.Steps, pricePath.Seed)
+
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package backtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestPricePathNearest(t *testing.T) {
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	k := newKLine("BTCUSDT", types.Interval1m, t1, 100, 101, 90, 95)
+	assert.Equal(t, []fixedpoint.Value{k.High, k.Low, k.Close}, pricePathNearest(k))
+
+	k = newKLine("BTCUSDT", types.Interval1m, t1, 100, 110, 99, 105)
+	assert.Equal(t, []fixedpoint.Value{k.Low, k.High, k.Close}, pricePathNearest(k))
+}
+
+func TestBrownianBridgePricePath(t *testing.T) {
+	t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+	k := newKLine("BTCUSDT", types.Interval1m, t1, 100, 110, 90, 105)
+
+	pricePath := newBrownianBridgePricePath(30, 1)
+	for i := 0; i < 100; i++ {
+		path := pricePath(k)
+		assert.Len(t, path, 30)
+		assert.Equal(t, k.Close, path[len(path)-1])
+		assert.Contains(t, path, k.High)
+		assert.Contains(t, path, k.Low)
+
+		for _, price := range path {
+			assert.True(t, price.Compare(k.High) <= 0 && price.Compare(k.Low) >= 0, "price %s is out of the kline range", price.String())
+		}
+	}
+
+	// the same seed gives the same path
+	assert.Equal(t, newBrownianBridgePricePath(30, 2)(k), newBrownianBridgePricePath(30, 2)(k))
+}
+
+func TestSimplePriceMatching_processKLineWithPricePath(t *testing.T) {
+	market := getTestMarket()
+
+	testcases := []struct {
+		model       bbgo.BacktestPricePathModel
+		firstTrade  fixedpoint.Value
+		secondTrade fixedpoint.Value
+	}{
+		{model: bbgo.BacktestPricePathModelOHLC, firstTrade: fixedpoint.NewFromFloat(23000.0), secondTrade: fixedpoint.NewFromFloat(21000.0)},
+		{model: bbgo.BacktestPricePathModelOLHC, firstTrade: fixedpoint.NewFromFloat(21000.0), secondTrade: fixedpoint.NewFromFloat(23000.0)},
+	}
+
+	for _, tc := range testcases {
+		t.Run(string(tc.model), func(t *testing.T) {
+			engine := &SimplePriceMatching{
+				account:           getTestAccount(),
+				Market:            market,
+				closedOrders:      make(map[uint64]types.Order),
+				lastPrice:         fixedpoint.NewFromFloat(22000.0),
+				pricePathFunction: getPricePathFunction(&bbgo.BacktestPricePath{Model: tc.model}),
+			}
+
+			var trades []types.Trade
+			engine.OnTradeUpdate(func(trade types.Trade) {
+				trades = append(trades, trade)
+			})
+
+			_, _, err := engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeSell, 23000.0, 0.1))
+			assert.NoError(t, err)
+
+			_, _, err = engine.PlaceOrder(newLimitOrder("BTCUSDT", types.SideTypeBuy, 21000.0, 0.1))
+			assert.NoError(t, err)
+
+			t1 := time.Date(2021, 7, 1, 0, 0, 0, 0, time.UTC)
+			engine.processKLine(newKLine("BTCUSDT", types.Interval1m, t1, 22000, 23500, 20500, 22000))
+
+			if assert.Len(t, trades, 2) {
+				assert.Equal(t, tc.firstTrade, trades[0].Price)
+				assert.Equal(t, tc.secondTrade, trades[1].Price)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+package backtest
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// maxSessionEventSize is the max size of one event line, the book snapshots can be large
+const maxSessionEventSize = 16 * 1024 * 1024
+
+// SessionEventReader reads the session event log written by the session recorder
+type SessionEventReader struct {
+	scanner *bufio.Scanner
+}
+
+func NewSessionEventReader(r io.Reader) *SessionEventReader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxSessionEventSize)
+	return &SessionEventReader{scanner: scanner}
+}
+
+// Read returns the next event, io.EOF is returned when there is no more event
+func (r *SessionEventReader) Read() (*types.SessionEvent, error) {
+	for r.scanner.Scan() {
+		line := r.scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var event types.SessionEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return nil, fmt.Errorf("invalid session event: %w", err)
+		}
+
+		return &event, nil
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return nil, io.EOF
+}
+
+type replaySession struct {
+	marketDataStream types.StandardStreamEmitter
+	userDataStream   types.StandardStreamEmitter
+}
+
+// Replayer re-feeds the recorded session events into the streams in the recorded order,
+// the events of the sessions that are not bound are skipped.
+//
+// The events are emitted one by one in the sequence order, so the replay is deterministic,
+// the strategy decisions are emitted to the decision callbacks at the recorded position.
+//
+//go:generate callbackgen -type Replayer
+type Replayer struct {
+	// Until stops the replay after the given time, zero means replaying all the events
+	Until time.Time
+
+	sessions map[string]replaySession
+	lastSeq  uint64
+
+	eventCallbacks    []func(event types.SessionEvent)
+	decisionCallbacks []func(event types.SessionEvent, decision types.StrategyDecision)
+}
+
+func NewReplayer() *Replayer {
+	return &Replayer{
+		sessions: make(map[string]replaySession),
+	}
+}
+
+// BindSession binds the streams of the session, the market data events and the user data events are emitted to the streams
+func (r *Replayer) BindSession(session string, marketDataStream, userDataStream types.StandardStreamEmitter) {
+	r.sessions[session] = replaySession{
+		marketDataStream: marketDataStream,
+		userDataStream:   userDataStream,
+	}
+}
+
+// Replay emits all the events of the reader
+func (r *Replayer) Replay(reader *SessionEventReader) error {
+	for {
+		event, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if !r.Until.IsZero() && event.Time.After(r.Until) {
+			return nil
+		}
+
+		if err := r.Emit(*event); err != nil {
+			return err
+		}
+	}
+}
+
+// Emit emits one event, the events should be emitted in the sequence order
+func (r *Replayer) Emit(event types.SessionEvent) error {
+	if event.Seq <= r.lastSeq {
+		return fmt.Errorf("session event #%d is out of order, the last event is #%d", event.Seq, r.lastSeq)
+	}
+
+	r.lastSeq = event.Seq
+	r.EmitEvent(event)
+
+	if event.Type == types.SessionEventDecision {
+		var decision types.StrategyDecision
+		if err := json.Unmarshal(event.Payload, &decision); err != nil {
+			return fmt.Errorf("session event #%d: %w", event.Seq, err)
+		}
+
+		r.EmitDecision(event, decision)
+		return nil
+	}
+
+	session, ok := r.sessions[event.Session]
+	if !ok {
+		return nil
+	}
+
+	if err := emitSessionEvent(session, event); err != nil {
+		return fmt.Errorf("session event #%d: %w", event.Seq, err)
+	}
+
+	return nil
+}
+
+func emitSessionEvent(session replaySession, event types.SessionEvent) error {
+	switch event.Type {
+	case types.SessionEventKLineClosed:
+		var k types.KLine
+		if err := json.Unmarshal(event.Payload, &k); err != nil {
+			return err
+		}
+
+		session.marketDataStream.EmitKLineClosed(k)
+
+	case types.SessionEventBookSnapshot, types.SessionEventBookUpdate:
+		var book types.SliceOrderBook
+		if err := json.Unmarshal(event.Payload, &book); err != nil {
+			return err
+		}
+
+		if event.Type == types.SessionEventBookSnapshot {
+			session.marketDataStream.EmitBookSnapshot(book)
+		} else {
+			session.marketDataStream.EmitBookUpdate(book)
+		}
+
+	case types.SessionEventBookTicker:
+		var bookTicker types.BookTicker
+		if err := json.Unmarshal(event.Payload, &bookTicker); err != nil {
+			return err
+		}
+
+		session.marketDataStream.EmitBookTickerUpdate(bookTicker)
+
+	case types.SessionEventMarketTrade:
+		var trade types.Trade
+		if err := json.Unmarshal(event.Payload, &trade); err != nil {
+			return err
+		}
+
+		session.marketDataStream.EmitMarketTrade(trade)
+
+	case types.SessionEventOrderUpdate:
+		var order types.Order
+		if err := json.Unmarshal(event.Payload, &order); err != nil {
+			return err
+		}
+
+		session.userDataStream.EmitOrderUpdate(order)
+
+	case types.SessionEventTradeUpdate:
+		var trade types.Trade
+		if err := json.Unmarshal(event.Payload, &trade); err != nil {
+			return err
+		}
+
+		session.userDataStream.EmitTradeUpdate(trade)
+
+	case types.SessionEventBalanceSnapshot, types.SessionEventBalanceUpdate:
+		var balances types.BalanceMap
+		if err := json.Unmarshal(event.Payload, &balances); err != nil {
+			return err
+		}
+
+		if event.Type == types.SessionEventBalanceSnapshot {
+			session.userDataStream.EmitBalanceSnapshot(balances)
+		} else {
+			session.userDataStream.EmitBalanceUpdate(balances)
+		}
+
+	default:
+		return fmt.Errorf("unsupported session event type %q", event.Type)
+	}
+
+	return nil
+}
+
+// ReplayMarketState tracks the order books, the book tickers and the last closed klines of the replayed sessions,
+// so that the strategy decisions can be inspected with the market state at the decision time.
+type ReplayMarketState struct {
+	books   map[string]*types.MutexOrderBook
+	tickers map[string]types.BookTicker
+	kLines  map[string]types.KLine
+}
+
+func NewReplayMarketState() *ReplayMarketState {
+	return &ReplayMarketState{
+		books:   make(map[string]*types.MutexOrderBook),
+		tickers: make(map[string]types.BookTicker),
+		kLines:  make(map[string]types.KLine),
+	}
+}
+
+func replayKey(session, symbol string) string {
+	return session + ":" + symbol
+}
+
+func (s *ReplayMarketState) book(session, symbol string) *types.MutexOrderBook {
+	key := replayKey(session, symbol)
+	book, ok := s.books[key]
+	if !ok {
+		book = types.NewMutexOrderBook(symbol)
+		s.books[key] = book
+	}
+
+	return book
+}
+
+// BindSession tracks the market data of the session stream
+func (s *ReplayMarketState) BindSession(session string, stream types.Stream) {
+	stream.OnBookSnapshot(func(book types.SliceOrderBook) {
+		s.book(session, book.Symbol).Load(book)
+	})
+	stream.OnBookUpdate(func(book types.SliceOrderBook) {
+		s.book(session, book.Symbol).Update(book)
+	})
+	stream.OnBookTickerUpdate(func(bookTicker types.BookTicker) {
+		s.tickers[replayKey(session, bookTicker.Symbol)] = bookTicker
+	})
+	stream.OnKLineClosed(func(k types.KLine) {
+		s.kLines[replayKey(session, k.Symbol)] = k
+	})
+}
+
+// BestBidAndAsk returns the best bid and ask of the order book, the book ticker is used when the order book is not recorded
+func (s *ReplayMarketState) BestBidAndAsk(session, symbol string) (bid, ask types.PriceVolume, ok bool) {
+	if book, found := s.books[replayKey(session, symbol)]; found {
+		if bid, ask, ok = book.BestBidAndAsk(); ok {
+			return bid, ask, true
+		}
+	}
+
+	if ticker, found := s.tickers[replayKey(session, symbol)]; found {
+		bid = types.PriceVolume{Price: ticker.Buy, Volume: ticker.BuySize}
+		ask = types.PriceVolume{Price: ticker.Sell, Volume: ticker.SellSize}
+		return bid, ask, true
+	}
+
+	return bid, ask, false
+}
+
+// LastKLine returns the last closed kline of the symbol, the klines of all the intervals are tracked
+func (s *ReplayMarketState) LastKLine(session, symbol string) (types.KLine, bool) {
+	k, ok := s.kLines[replayKey(session, symbol)]
+	return k, ok
+}
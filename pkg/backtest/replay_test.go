@@ -0,0 +1,129 @@
+package backtest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestReplayer(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := bbgo.NewSessionRecorder(&bbgo.SessionRecorderConfig{Directory: dir})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	marketDataStream, userDataStream := &types.StandardStream{}, &types.StandardStream{}
+	recorder.Bind(&bbgo.ExchangeSession{
+		Name:             "binance",
+		MarketDataStream: marketDataStream,
+		UserDataStream:   userDataStream,
+	})
+
+	// the live session
+	marketDataStream.EmitBookSnapshot(types.SliceOrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromInt(100), Volume: fixedpoint.One}},
+		Asks:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromInt(102), Volume: fixedpoint.One}},
+	})
+	marketDataStream.EmitKLineClosed(types.KLine{Exchange: types.ExchangeBinance, Symbol: "BTCUSDT", Interval: types.Interval1m, Close: fixedpoint.NewFromInt(101)})
+	recorder.RecordDecision("binance", types.StrategyDecision{
+		StrategyInstanceID: "scmaker:BTCUSDT",
+		Symbol:             "BTCUSDT",
+		Action:             types.StrategyDecisionSubmit,
+		SubmitOrders: []types.SubmitOrder{
+			{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: fixedpoint.NewFromInt(99), Quantity: fixedpoint.One},
+		},
+	})
+	userDataStream.EmitOrderUpdate(types.Order{Exchange: types.ExchangeBinance, OrderID: 1, SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Type: types.OrderTypeLimit}, Status: types.OrderStatusNew})
+	marketDataStream.EmitBookUpdate(types.SliceOrderBook{Symbol: "BTCUSDT"})
+	assert.NoError(t, recorder.Close())
+
+	files, err := filepath.Glob(filepath.Join(dir, "session-*.jsonl"))
+	if !assert.NoError(t, err) || !assert.Len(t, files, 1) {
+		return
+	}
+
+	// replay the session
+	replayMarketDataStream, replayUserDataStream := &types.StandardStream{}, &types.StandardStream{}
+	replayer := NewReplayer()
+	replayer.BindSession("binance", replayMarketDataStream, replayUserDataStream)
+
+	state := NewReplayMarketState()
+	state.BindSession("binance", replayMarketDataStream)
+
+	var eventTypes []types.SessionEventType
+	replayer.OnEvent(func(event types.SessionEvent) {
+		eventTypes = append(eventTypes, event.Type)
+	})
+
+	var orders []types.Order
+	replayUserDataStream.OnOrderUpdate(func(order types.Order) {
+		orders = append(orders, order)
+	})
+
+	var decisions int
+	replayer.OnDecision(func(event types.SessionEvent, decision types.StrategyDecision) {
+		decisions++
+		assert.Equal(t, "scmaker:BTCUSDT", decision.StrategyInstanceID)
+		assert.Len(t, decision.SubmitOrders, 1)
+
+		// the market state at the decision time
+		bid, ask, ok := state.BestBidAndAsk("binance", "BTCUSDT")
+		if assert.True(t, ok) {
+			assert.Equal(t, "100", bid.Price.String())
+			assert.Equal(t, "102", ask.Price.String())
+		}
+
+		k, ok := state.LastKLine("binance", "BTCUSDT")
+		if assert.True(t, ok) {
+			assert.Equal(t, "101", k.Close.String())
+		}
+	})
+
+	f, err := os.Open(files[0])
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer f.Close()
+
+	assert.NoError(t, replayer.Replay(NewSessionEventReader(f)))
+	assert.Equal(t, 1, decisions)
+	assert.Len(t, orders, 1)
+
+	// the book updates are not recorded by default
+	assert.Equal(t, []types.SessionEventType{
+		types.SessionEventBookSnapshot,
+		types.SessionEventKLineClosed,
+		types.SessionEventDecision,
+		types.SessionEventOrderUpdate,
+	}, eventTypes)
+
+	// the replayed events can not be emitted again
+	assert.Error(t, replayer.Emit(types.SessionEvent{Seq: 1, Type: types.SessionEventKLineClosed}))
+}
+
+func TestReplayer_Until(t *testing.T) {
+	replayer := NewReplayer()
+	replayer.Until = time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	var numOfEvents int
+	replayer.OnEvent(func(event types.SessionEvent) {
+		numOfEvents++
+	})
+
+	reader := NewSessionEventReader(strings.NewReader(`
+{"seq":1,"time":"2023-04-30T23:59:59Z","session":"binance","type":"kline","payload":{}}
+{"seq":2,"time":"2023-05-01T00:00:01Z","session":"binance","type":"kline","payload":{}}
+`))
+	assert.NoError(t, replayer.Replay(reader))
+	assert.Equal(t, 1, numOfEvents)
+}
@@ -52,6 +52,9 @@ type SummaryReport struct {
 	SymbolReports []SessionSymbolReport `json:"symbolReports,omitempty"`
 
 	Manifests Manifests `json:"manifests,omitempty"`
+
+	// Scenario is the name of the balance scenario, it's empty for the balances of the account config
+	Scenario string `json:"scenario,omitempty"`
 }
 
 func ReadSummaryReport(filename string) (*SummaryReport, error) {
@@ -83,6 +86,18 @@ type SessionSymbolReport struct {
 	Sortino         fixedpoint.Value          `json:"sortinoRatio"`
 	ProfitFactor    fixedpoint.Value          `json:"profitFactor"`
 	WinningRatio    fixedpoint.Value          `json:"winningRatio"`
+
+	// MaxDrawdown is the max drawdown ratio of the daily compounded realized profits
+	MaxDrawdown fixedpoint.Value `json:"maxDrawdown"`
+
+	// InventoryVariance is the variance of the base asset position after each trade
+	InventoryVariance fixedpoint.Value `json:"inventoryVariance"`
+
+	// MonteCarlo is the monte carlo analysis of the trade sequence, it's set when the analysis is enabled
+	MonteCarlo *MonteCarloReport `json:"monteCarlo,omitempty"`
+
+	// RejectedOrders is the number of the orders rejected by the min quantity and the min notional constraints
+	RejectedOrders int `json:"rejectedOrders"`
 }
 
 func (r *SessionSymbolReport) InitialEquityValue() fixedpoint.Value {
@@ -133,6 +148,17 @@ func (r *SessionSymbolReport) Print(wantBaseAssetBaseline bool) {
 		color.Red("REALIZED SORTINO RATIO: %s", r.Sortino.FormatString(4))
 	}
 
+	color.Green("REALIZED MAX DRAWDOWN: %s", r.MaxDrawdown.FormatPercentage(2))
+	color.Green("INVENTORY VARIANCE: %s", r.InventoryVariance.FormatString(8))
+
+	if r.RejectedOrders > 0 {
+		color.Red("REJECTED ORDERS (MIN QUANTITY / MIN NOTIONAL): %d", r.RejectedOrders)
+	}
+
+	if r.MonteCarlo != nil {
+		r.MonteCarlo.Print(r.Market.QuoteCurrency)
+	}
+
 	if wantBaseAssetBaseline {
 		if r.LastPrice.Compare(r.StartPrice) > 0 {
 			color.Green("%s BASE ASSET PERFORMANCE: +%s (= (%s - %s) / %s)",
@@ -259,3 +285,33 @@ func loadReportIndexLocked(indexFilePath string) (*ReportIndex, error) {
 
 	return &reportIndex, nil
 }
+
+// InventoryVariance calculates the variance of the base asset position after each trade
+func InventoryVariance(trades []types.Trade) fixedpoint.Value {
+	if len(trades) == 0 {
+		return fixedpoint.Zero
+	}
+
+	var base float64
+	var positions = make([]float64, len(trades))
+	for i, trade := range trades {
+		if trade.Side == types.SideTypeBuy {
+			base += trade.Quantity.Float64()
+		} else {
+			base -= trade.Quantity.Float64()
+		}
+		positions[i] = base
+	}
+
+	var mean float64
+	for _, p := range positions {
+		mean += p
+	}
+	mean /= float64(len(positions))
+
+	var variance float64
+	for _, p := range positions {
+		variance += (p - mean) * (p - mean)
+	}
+	return fixedpoint.NewFromFloat(variance / float64(len(positions)))
+}
@@ -0,0 +1,23 @@
+package backtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestInventoryVariance(t *testing.T) {
+	assert.Equal(t, fixedpoint.Zero, InventoryVariance(nil))
+
+	// positions: 1, 2, 0, -1 => mean 0.5, variance (0.25 + 2.25 + 0.25 + 2.25) / 4
+	trades := []types.Trade{
+		{Side: types.SideTypeBuy, Quantity: fixedpoint.One},
+		{Side: types.SideTypeBuy, Quantity: fixedpoint.One},
+		{Side: types.SideTypeSell, Quantity: fixedpoint.NewFromInt(2)},
+		{Side: types.SideTypeSell, Quantity: fixedpoint.One},
+	}
+	assert.Equal(t, "1.25", InventoryVariance(trades).String())
+}
@@ -0,0 +1,71 @@
+package backtest
+
+import (
+	"github.com/fatih/color"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// DefaultBalanceScenario is the scenario name of the balances defined in the account config
+const DefaultBalanceScenario = "default"
+
+// BalanceScenarioReport is the result of a balance scenario, the scenarios are compared by the return,
+// the number of the trades and the number of the orders rejected by the market constraints
+type BalanceScenarioReport struct {
+	Scenario           string           `json:"scenario"`
+	InitialEquityValue fixedpoint.Value `json:"initialEquityValue"`
+	FinalEquityValue   fixedpoint.Value `json:"finalEquityValue"`
+	NumTrades          int              `json:"numTrades"`
+	RejectedOrders     int              `json:"rejectedOrders"`
+}
+
+// NewBalanceScenarioReport summarizes the symbol reports of the summary report
+func NewBalanceScenarioReport(summaryReport *SummaryReport) BalanceScenarioReport {
+	report := BalanceScenarioReport{
+		Scenario:           summaryReport.Scenario,
+		InitialEquityValue: summaryReport.InitialEquityValue,
+		FinalEquityValue:   summaryReport.FinalEquityValue,
+	}
+
+	if len(report.Scenario) == 0 {
+		report.Scenario = DefaultBalanceScenario
+	}
+
+	for _, symbolReport := range summaryReport.SymbolReports {
+		if symbolReport.PnL != nil {
+			report.NumTrades += symbolReport.PnL.NumTrades
+		}
+
+		report.RejectedOrders += symbolReport.RejectedOrders
+	}
+
+	return report
+}
+
+// Return is the final equity value over the initial equity value minus one
+func (r BalanceScenarioReport) Return() fixedpoint.Value {
+	if r.InitialEquityValue.IsZero() {
+		return fixedpoint.Zero
+	}
+
+	return r.FinalEquityValue.Div(r.InitialEquityValue).Sub(fixedpoint.One)
+}
+
+func PrintBalanceScenarioReports(reports []BalanceScenarioReport) {
+	color.Green("BALANCE SCENARIOS")
+	color.Green("===============================================")
+	for _, r := range reports {
+		printf := color.Green
+		if r.Return().Sign() < 0 {
+			printf = color.Red
+		}
+
+		printf("%s: INITIAL EQUITY %s, FINAL EQUITY %s, RETURN %s, TRADES %d, REJECTED ORDERS %d",
+			r.Scenario,
+			r.InitialEquityValue.FormatString(2),
+			r.FinalEquityValue.FormatString(2),
+			r.Return().FormatPercentage(2),
+			r.NumTrades,
+			r.RejectedOrders)
+	}
+}
@@ -0,0 +1,30 @@
+package backtest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/accounting/pnl"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestNewBalanceScenarioReport(t *testing.T) {
+	report := NewBalanceScenarioReport(&SummaryReport{
+		InitialEquityValue: fixedpoint.NewFromFloat(1000.0),
+		FinalEquityValue:   fixedpoint.NewFromFloat(1100.0),
+		SymbolReports: []SessionSymbolReport{
+			{PnL: &pnl.AverageCostPnLReport{NumTrades: 3}, RejectedOrders: 2},
+			{PnL: &pnl.AverageCostPnLReport{NumTrades: 4}, RejectedOrders: 1},
+		},
+	})
+
+	assert.Equal(t, DefaultBalanceScenario, report.Scenario)
+	assert.Equal(t, 7, report.NumTrades)
+	assert.Equal(t, 3, report.RejectedOrders)
+	assert.Equal(t, "0.1", report.Return().String())
+
+	report = NewBalanceScenarioReport(&SummaryReport{Scenario: "small"})
+	assert.Equal(t, "small", report.Scenario)
+	assert.Equal(t, fixedpoint.Zero, report.Return())
+}
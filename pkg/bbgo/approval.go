@@ -0,0 +1,353 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pquerna/otp"
+	"github.com/pquerna/otp/totp"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type ApprovalMode string
+
+const (
+	// ApprovalModeApprover requires a second person from the approver list to approve the command
+	ApprovalModeApprover ApprovalMode = "approver"
+
+	// ApprovalModeTOTP requires a time-based one-time password to approve the command
+	ApprovalModeTOTP ApprovalMode = "totp"
+)
+
+const defaultApprovalExpiry = 5 * time.Minute
+
+// totpPeriod is the time step of the one-time password in seconds
+const totpPeriod = 30
+
+// maxApprovalAuditEntries is the number of the audit entries kept in memory, the entries are also written to the log
+const maxApprovalAuditEntries = 500
+
+var ErrApprovalNotFound = errors.New("approval request not found")
+
+// RemoteCommandApprovalConfig enables the confirmation workflow of the destructive remote commands,
+// e.g., closing positions and emergency stops from the messengers and the web api.
+type RemoteCommandApprovalConfig struct {
+	Mode ApprovalMode `json:"mode" yaml:"mode"`
+
+	// Approvers is the list of the users who can approve the commands in the approver mode,
+	// the user is the telegram username (or user id), the slack user id, or the user name given in the web api.
+	// Any other user than the requester can approve if it's empty.
+	Approvers []string `json:"approvers,omitempty" yaml:"approvers,omitempty"`
+
+	// TOTPSecret is the base32 secret of the one-time password in the totp mode,
+	// it's loaded from the env var BBGO_APPROVAL_TOTP_SECRET if it's empty.
+	TOTPSecret string `json:"totpSecret,omitempty" yaml:"totpSecret,omitempty"`
+
+	// Expiry is the time the pending requests wait for the approval, defaults to 5 minutes
+	Expiry types.Duration `json:"expiry,omitempty" yaml:"expiry,omitempty"`
+}
+
+// ApprovalRequest is a destructive command waiting for the approval
+type ApprovalRequest struct {
+	ID          string    `json:"id"`
+	Action      string    `json:"action"`
+	Description string    `json:"description"`
+	Source      string    `json:"source"`
+	Requester   string    `json:"requester"`
+	CreatedTime time.Time `json:"createdTime"`
+	ExpiredTime time.Time `json:"expiredTime"`
+
+	execute func(ctx context.Context) error
+}
+
+// ApprovalAuditEntry records who approved or rejected what
+type ApprovalAuditEntry struct {
+	Time        time.Time `json:"time"`
+	RequestID   string    `json:"requestId"`
+	Action      string    `json:"action"`
+	Description string    `json:"description"`
+	Source      string    `json:"source"`
+	Requester   string    `json:"requester"`
+	Approver    string    `json:"approver,omitempty"`
+	Method      string    `json:"method,omitempty"`
+	Result      string    `json:"result"`
+	Error       string    `json:"error,omitempty"`
+}
+
+func (e *ApprovalAuditEntry) PlainText() string {
+	s := fmt.Sprintf("Approval %s %s: %s (%s) requested by %s via %s", e.RequestID, e.Result, e.Description, e.Action, e.Requester, e.Source)
+	if e.Approver != "" {
+		s += fmt.Sprintf(", %s by %s with %s", e.Result, e.Approver, e.Method)
+	}
+
+	if e.Error != "" {
+		s += ", error: " + e.Error
+	}
+
+	return s
+}
+
+// ApprovalManager holds the pending destructive commands until they are approved
+type ApprovalManager struct {
+	mode       ApprovalMode
+	approvers  map[string]struct{}
+	totpSecret string
+	expiry     time.Duration
+
+	mu       sync.Mutex
+	seq      int64
+	pending  map[string]*ApprovalRequest
+	auditLog []ApprovalAuditEntry
+
+	// lastTOTPStep is the time step of the last accepted one-time password,
+	// the password of the same or an earlier step can not be used again.
+	lastTOTPStep int64
+
+	logger logrus.FieldLogger
+}
+
+func NewApprovalManager(config *RemoteCommandApprovalConfig) (*ApprovalManager, error) {
+	m := &ApprovalManager{
+		mode:      config.Mode,
+		approvers: make(map[string]struct{}),
+		expiry:    config.Expiry.Duration(),
+		pending:   make(map[string]*ApprovalRequest),
+		logger:    logrus.WithField("component", "approval"),
+	}
+
+	if m.expiry == 0 {
+		m.expiry = defaultApprovalExpiry
+	}
+
+	for _, approver := range config.Approvers {
+		m.approvers[approver] = struct{}{}
+	}
+
+	switch m.mode {
+	case ApprovalModeApprover:
+	case ApprovalModeTOTP:
+		m.totpSecret = config.TOTPSecret
+		if m.totpSecret == "" {
+			m.totpSecret = os.Getenv("BBGO_APPROVAL_TOTP_SECRET")
+		}
+
+		if m.totpSecret == "" {
+			return nil, errors.New("remoteCommandApproval: totp secret is not configured, please set totpSecret or BBGO_APPROVAL_TOTP_SECRET")
+		}
+
+	default:
+		return nil, fmt.Errorf("remoteCommandApproval: unsupported mode %q, valid modes are %q and %q", m.mode, ApprovalModeApprover, ApprovalModeTOTP)
+	}
+
+	return m, nil
+}
+
+func (m *ApprovalManager) Mode() ApprovalMode {
+	return m.mode
+}
+
+// Request adds the command to the pending list, the command is executed when it's approved.
+func (m *ApprovalManager) Request(source, requester, action, description string, execute func(ctx context.Context) error) *ApprovalRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.seq++
+	now := time.Now()
+	request := &ApprovalRequest{
+		ID:          strconv.FormatInt(m.seq, 10),
+		Action:      action,
+		Description: description,
+		Source:      source,
+		Requester:   requester,
+		CreatedTime: now,
+		ExpiredTime: now.Add(m.expiry),
+		execute:     execute,
+	}
+
+	m.pending[request.ID] = request
+	m.audit(request, "", "", "requested", nil)
+	return request
+}
+
+// Pending returns the pending requests that are not expired
+func (m *ApprovalManager) Pending() []ApprovalRequest {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.removeExpired(time.Now())
+
+	requests := make([]ApprovalRequest, 0, len(m.pending))
+	for _, request := range m.pending {
+		requests = append(requests, *request)
+	}
+
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].CreatedTime.Before(requests[j].CreatedTime)
+	})
+
+	return requests
+}
+
+// AuditLog returns a copy of the audit entries, the latest entry comes last
+func (m *ApprovalManager) AuditLog() []ApprovalAuditEntry {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries := make([]ApprovalAuditEntry, len(m.auditLog))
+	copy(entries, m.auditLog)
+	return entries
+}
+
+// Approve verifies the approver (or the one-time password in the totp mode) and executes the command
+func (m *ApprovalManager) Approve(ctx context.Context, id, approver, code string) error {
+	m.mu.Lock()
+	m.removeExpired(time.Now())
+
+	request, ok := m.pending[id]
+	if !ok {
+		m.mu.Unlock()
+		return ErrApprovalNotFound
+	}
+
+	method, err := m.verify(request, approver, code)
+	if err != nil {
+		m.audit(request, approver, method, "denied", err)
+		m.mu.Unlock()
+		return err
+	}
+
+	// remove the request before executing it, so that it can't be approved twice
+	delete(m.pending, id)
+	m.mu.Unlock()
+
+	err = request.execute(ctx)
+
+	m.mu.Lock()
+	if err != nil {
+		m.audit(request, approver, method, "failed", err)
+	} else {
+		m.audit(request, approver, method, "approved", nil)
+	}
+	m.mu.Unlock()
+
+	return err
+}
+
+// Reject removes the pending request without executing it
+func (m *ApprovalManager) Reject(id, approver string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	request, ok := m.pending[id]
+	if !ok {
+		return ErrApprovalNotFound
+	}
+
+	delete(m.pending, id)
+	m.audit(request, approver, "reject", "rejected", nil)
+	return nil
+}
+
+func (m *ApprovalManager) verify(request *ApprovalRequest, approver, code string) (string, error) {
+	switch m.mode {
+	case ApprovalModeTOTP:
+		step, ok := m.validateTOTP(code, time.Now())
+		if !ok {
+			return "totp", errors.New("incorrect one-time password")
+		}
+
+		if step <= m.lastTOTPStep {
+			return "totp", errors.New("the one-time password has been used")
+		}
+
+		m.lastTOTPStep = step
+		return "totp", nil
+
+	default:
+		if approver == "" {
+			return "approver", errors.New("approver is not given")
+		}
+
+		if approver == request.Requester {
+			return "approver", errors.New("the request must be approved by another person")
+		}
+
+		if len(m.approvers) > 0 {
+			if _, ok := m.approvers[approver]; !ok {
+				return "approver", fmt.Errorf("%s is not in the approver list", approver)
+			}
+		}
+
+		return "approver", nil
+	}
+}
+
+// validateTOTP returns the time step of the code, the code of the previous or the next step is accepted for the clock skew
+func (m *ApprovalManager) validateTOTP(code string, now time.Time) (int64, bool) {
+	current := now.Unix() / totpPeriod
+	for _, step := range []int64{current - 1, current, current + 1} {
+		ok, err := totp.ValidateCustom(code, m.totpSecret, time.Unix(step*totpPeriod, 0).UTC(), totp.ValidateOpts{
+			Period:    totpPeriod,
+			Digits:    otp.DigitsSix,
+			Algorithm: otp.AlgorithmSHA1,
+		})
+		if err == nil && ok {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+func (m *ApprovalManager) removeExpired(now time.Time) {
+	for id, request := range m.pending {
+		if now.After(request.ExpiredTime) {
+			delete(m.pending, id)
+			m.audit(request, "", "", "expired", nil)
+		}
+	}
+}
+
+// audit must be called with the lock held
+func (m *ApprovalManager) audit(request *ApprovalRequest, approver, method, result string, err error) {
+	entry := ApprovalAuditEntry{
+		Time:        time.Now(),
+		RequestID:   request.ID,
+		Action:      request.Action,
+		Description: request.Description,
+		Source:      request.Source,
+		Requester:   request.Requester,
+		Approver:    approver,
+		Method:      method,
+		Result:      result,
+	}
+
+	if err != nil {
+		entry.Error = err.Error()
+	}
+
+	m.auditLog = append(m.auditLog, entry)
+	if len(m.auditLog) > maxApprovalAuditEntries {
+		m.auditLog = m.auditLog[len(m.auditLog)-maxApprovalAuditEntries:]
+	}
+
+	m.logger.WithFields(logrus.Fields{
+		"request":   entry.RequestID,
+		"action":    entry.Action,
+		"source":    entry.Source,
+		"requester": entry.Requester,
+		"approver":  entry.Approver,
+		"method":    entry.Method,
+		"result":    entry.Result,
+	}).Info(entry.PlainText())
+
+	Notify(entry.PlainText())
+}
@@ -0,0 +1,129 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pquerna/otp/totp"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestApprovalManager_Approver(t *testing.T) {
+	manager, err := NewApprovalManager(&RemoteCommandApprovalConfig{
+		Mode:      ApprovalModeApprover,
+		Approvers: []string{"alice", "bob"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	executed := 0
+	request := manager.Request("interact", "alice", "closeposition", "close position", func(ctx context.Context) error {
+		executed++
+		return nil
+	})
+
+	assert.Len(t, manager.Pending(), 1)
+
+	// the requester can not approve their own request
+	assert.Error(t, manager.Approve(context.Background(), request.ID, "alice", ""))
+
+	// the approver must be in the approver list
+	assert.Error(t, manager.Approve(context.Background(), request.ID, "mallory", ""))
+	assert.Equal(t, 0, executed)
+
+	assert.NoError(t, manager.Approve(context.Background(), request.ID, "bob", ""))
+	assert.Equal(t, 1, executed)
+	assert.Empty(t, manager.Pending())
+
+	// the request can not be approved twice
+	assert.ErrorIs(t, manager.Approve(context.Background(), request.ID, "bob", ""), ErrApprovalNotFound)
+	assert.Equal(t, 1, executed)
+
+	auditLog := manager.AuditLog()
+	if assert.NotEmpty(t, auditLog) {
+		last := auditLog[len(auditLog)-1]
+		assert.Equal(t, "approved", last.Result)
+		assert.Equal(t, "alice", last.Requester)
+		assert.Equal(t, "bob", last.Approver)
+	}
+}
+
+func TestApprovalManager_TOTP(t *testing.T) {
+	key, err := totp.Generate(totp.GenerateOpts{Issuer: "bbgo", AccountName: "test"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	manager, err := NewApprovalManager(&RemoteCommandApprovalConfig{
+		Mode:       ApprovalModeTOTP,
+		TOTPSecret: key.Secret(),
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	executed := false
+	request := manager.Request("web", "alice", "closeposition", "close position", func(ctx context.Context) error {
+		executed = true
+		return nil
+	})
+
+	assert.Error(t, manager.Approve(context.Background(), request.ID, "alice", "000000x"))
+	assert.False(t, executed)
+
+	code, err := totp.GenerateCode(key.Secret(), time.Now())
+	if assert.NoError(t, err) {
+		assert.NoError(t, manager.Approve(context.Background(), request.ID, "alice", code))
+		assert.True(t, executed)
+
+		// the used one-time password can not be replayed in its time step
+		executed = false
+		request = manager.Request("web", "alice", "closeposition", "close position", func(ctx context.Context) error {
+			executed = true
+			return nil
+		})
+
+		assert.ErrorContains(t, manager.Approve(context.Background(), request.ID, "alice", code), "has been used")
+		assert.False(t, executed)
+	}
+}
+
+func TestApprovalManager_ExpiredAndRejected(t *testing.T) {
+	manager, err := NewApprovalManager(&RemoteCommandApprovalConfig{
+		Mode:   ApprovalModeApprover,
+		Expiry: types.Duration(time.Millisecond),
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	request := manager.Request("interact", "alice", "emergencystop", "emergency stop", func(ctx context.Context) error {
+		t.Fatal("expired request should not be executed")
+		return nil
+	})
+
+	time.Sleep(5 * time.Millisecond)
+	assert.ErrorIs(t, manager.Approve(context.Background(), request.ID, "bob", ""), ErrApprovalNotFound)
+
+	manager.expiry = time.Minute
+	request = manager.Request("interact", "alice", "emergencystop", "emergency stop", func(ctx context.Context) error {
+		t.Fatal("rejected request should not be executed")
+		return nil
+	})
+
+	assert.NoError(t, manager.Reject(request.ID, "bob"))
+	assert.ErrorIs(t, manager.Approve(context.Background(), request.ID, "bob", ""), ErrApprovalNotFound)
+}
+
+func TestNewApprovalManager_InvalidConfig(t *testing.T) {
+	_, err := NewApprovalManager(&RemoteCommandApprovalConfig{Mode: "sms"})
+	assert.Error(t, err)
+
+	t.Setenv("BBGO_APPROVAL_TOTP_SECRET", "")
+	_, err = NewApprovalManager(&RemoteCommandApprovalConfig{Mode: ApprovalModeTOTP})
+	assert.Error(t, err)
+}
@@ -0,0 +1,309 @@
+package bbgo
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	defaultBookRecorderInterval  = time.Second
+	defaultBookRecorderDepth     = 20
+	defaultBookRecorderDirectory = "data/books"
+)
+
+// BookRecorderConfig enables the order book recorder, which samples the order book snapshots
+// of the symbols into the gzip compressed csv files for the offline research and replay.
+type BookRecorderConfig struct {
+	// Symbols are the recorded symbols
+	Symbols []string `json:"symbols" yaml:"symbols"`
+
+	// Sessions are the recorded sessions, all the sessions are recorded if it's empty
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+
+	// Interval is the sampling interval, defaults to 1 second
+	Interval types.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	// Depth is the number of the price levels of each side in a snapshot, defaults to 20
+	Depth int `json:"depth,omitempty" yaml:"depth,omitempty"`
+
+	// Directory is the output directory, defaults to data/books,
+	// the snapshots are written into {directory}/{session}/{symbol}/{date}.csv.gz, one file per day in UTC.
+	Directory string `json:"directory,omitempty" yaml:"directory,omitempty"`
+}
+
+// BookRecorder samples the streaming order books periodically and writes the snapshots into the daily files
+type BookRecorder struct {
+	environ  *Environment
+	config   BookRecorderConfig
+	interval time.Duration
+
+	files map[string]*bookSnapshotFile
+
+	logger logrus.FieldLogger
+}
+
+func NewBookRecorder(environ *Environment, config *BookRecorderConfig) *BookRecorder {
+	c := *config
+	if c.Depth == 0 {
+		c.Depth = defaultBookRecorderDepth
+	}
+
+	if c.Directory == "" {
+		c.Directory = defaultBookRecorderDirectory
+	}
+
+	interval := c.Interval.Duration()
+	if interval == 0 {
+		interval = defaultBookRecorderInterval
+	}
+
+	return &BookRecorder{
+		environ:  environ,
+		config:   c,
+		interval: interval,
+		files:    make(map[string]*bookSnapshotFile),
+		logger:   logrus.WithField("component", "bookRecorder"),
+	}
+}
+
+func (r *BookRecorder) sessions() []*ExchangeSession {
+	if len(r.config.Sessions) == 0 {
+		var sessions []*ExchangeSession
+		for _, session := range r.environ.Sessions() {
+			sessions = append(sessions, session)
+		}
+
+		return sessions
+	}
+
+	var sessions []*ExchangeSession
+	for _, name := range r.config.Sessions {
+		session, ok := r.environ.Session(name)
+		if !ok {
+			r.logger.Warnf("session %s is not found, skip recording", name)
+			continue
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions
+}
+
+// Subscribe subscribes the book channel of the symbols, it should be called before the streams are connected
+func (r *BookRecorder) Subscribe() {
+	for _, session := range r.sessions() {
+		for _, symbol := range r.config.Symbols {
+			if _, ok := session.Market(symbol); !ok {
+				r.logger.Warnf("market %s is not found in session %s, skip recording", symbol, session.Name)
+				continue
+			}
+
+			session.Subscribe(types.BookChannel, symbol, types.SubscribeOptions{})
+		}
+	}
+}
+
+// Run records the snapshots periodically until the context is done
+func (r *BookRecorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	defer r.Close()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			r.Record(now)
+		}
+	}
+}
+
+// Record writes the snapshots of the order books at the given time
+func (r *BookRecorder) Record(now time.Time) {
+	for _, session := range r.sessions() {
+		for _, symbol := range r.config.Symbols {
+			book, ok := session.OrderBook(symbol)
+			if !ok {
+				continue
+			}
+
+			if valid, _ := book.IsValid(); !valid {
+				continue
+			}
+
+			snapshot := types.NewBookSnapshot(symbol, book.CopyDepth(r.config.Depth), now)
+			if err := r.write(session.Name, snapshot); err != nil {
+				r.logger.WithError(err).Errorf("can not write the book snapshot of %s %s", session.Name, symbol)
+			}
+		}
+	}
+}
+
+func (r *BookRecorder) write(sessionName string, snapshot types.BookSnapshot) error {
+	date := snapshot.Time.UTC().Format("2006-01-02")
+	key := sessionName + ":" + snapshot.Symbol
+
+	f, ok := r.files[key]
+	if ok && f.date != date {
+		// rotate the file on the next day
+		if err := f.Close(); err != nil {
+			r.logger.WithError(err).Errorf("can not close the book snapshot file %s", f.filename)
+		}
+
+		ok = false
+	}
+
+	if !ok {
+		filename := filepath.Join(r.config.Directory, sessionName, snapshot.Symbol, date+".csv.gz")
+		nf, err := openBookSnapshotFile(filename, date)
+		if err != nil {
+			return err
+		}
+
+		f = nf
+		r.files[key] = f
+	}
+
+	return f.Write(snapshot)
+}
+
+// Close flushes and closes the opened files
+func (r *BookRecorder) Close() {
+	for key, f := range r.files {
+		if err := f.Close(); err != nil {
+			r.logger.WithError(err).Errorf("can not close the book snapshot file %s", f.filename)
+		}
+
+		delete(r.files, key)
+	}
+}
+
+type bookSnapshotFile struct {
+	filename string
+	date     string
+
+	file *os.File
+	gz   *gzip.Writer
+	csv  *csv.Writer
+}
+
+// openBookSnapshotFile opens the file in the append mode, the appended data is a new gzip member,
+// which can be read as one stream by the gzip readers.
+func openBookSnapshotFile(filename, date string) (*bookSnapshotFile, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	stat, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	gz := gzip.NewWriter(file)
+	f := &bookSnapshotFile{
+		filename: filename,
+		date:     date,
+		file:     file,
+		gz:       gz,
+		csv:      csv.NewWriter(gz),
+	}
+
+	if stat.Size() == 0 {
+		if err := f.csv.Write(types.BookSnapshot{}.CsvHeader()); err != nil {
+			_ = f.Close()
+			return nil, err
+		}
+	}
+
+	return f, nil
+}
+
+func (f *bookSnapshotFile) Write(snapshot types.BookSnapshot) error {
+	if err := f.csv.WriteAll(snapshot.CsvRecords()); err != nil {
+		return err
+	}
+
+	// flush the compressed block so that the file can be read while recording
+	return f.gz.Flush()
+}
+
+func (f *bookSnapshotFile) Close() error {
+	f.csv.Flush()
+	err := f.csv.Error()
+
+	if gzErr := f.gz.Close(); gzErr != nil && err == nil {
+		err = gzErr
+	}
+
+	if fileErr := f.file.Close(); fileErr != nil && err == nil {
+		err = fileErr
+	}
+
+	return err
+}
+
+// ReadBookSnapshotFile reads the snapshots from the file written by the book recorder
+func ReadBookSnapshotFile(filename string, callback func(snapshot types.BookSnapshot) error) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("can not read the book snapshot file %s: %w", filename, err)
+	}
+
+	defer gz.Close()
+
+	reader := types.NewBookSnapshotReader(&liveGzipReader{Reader: gz})
+	for {
+		snapshot, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		if err := callback(*snapshot); err != nil {
+			return err
+		}
+	}
+}
+
+// liveGzipReader reads the file that is still being recorded,
+// the last gzip member of the file has no trailer until the file is closed.
+type liveGzipReader struct {
+	*gzip.Reader
+}
+
+func (r *liveGzipReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return n, io.EOF
+	}
+
+	return n, err
+}
@@ -0,0 +1,57 @@
+package bbgo
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestBookRecorder_WriteAndRead(t *testing.T) {
+	dir := t.TempDir()
+	recorder := NewBookRecorder(NewEnvironment(), &BookRecorderConfig{Symbols: []string{"BTCUSDT"}, Directory: dir})
+
+	newSnapshot := func(t time.Time, bid float64) types.BookSnapshot {
+		return types.BookSnapshot{
+			Symbol: "BTCUSDT",
+			Time:   t,
+			Bids:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(bid), Volume: fixedpoint.One}},
+			Asks:   types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(bid + 1), Volume: fixedpoint.One}},
+		}
+	}
+
+	day1 := time.Date(2023, 5, 1, 23, 59, 58, 0, time.UTC)
+	assert.NoError(t, recorder.write("binance", newSnapshot(day1, 100)))
+
+	filename := filepath.Join(dir, "binance", "BTCUSDT", "2023-05-01.csv.gz")
+
+	// the file can be read while recording
+	var bids []string
+	read := func(s types.BookSnapshot) error {
+		bids = append(bids, s.Bids[0].Price.String())
+		return nil
+	}
+
+	assert.NoError(t, ReadBookSnapshotFile(filename, read))
+	assert.Equal(t, []string{"100"}, bids)
+
+	// restart the recorder, the snapshots are appended to the file
+	recorder.Close()
+	assert.NoError(t, recorder.write("binance", newSnapshot(day1.Add(time.Second), 101)))
+
+	// rotate the file on the next day
+	assert.NoError(t, recorder.write("binance", newSnapshot(day1.Add(2*time.Second), 102)))
+	recorder.Close()
+
+	bids = nil
+	assert.NoError(t, ReadBookSnapshotFile(filename, read))
+	assert.Equal(t, []string{"100", "101"}, bids)
+
+	bids = nil
+	assert.NoError(t, ReadBookSnapshotFile(filepath.Join(dir, "binance", "BTCUSDT", "2023-05-02.csv.gz"), read))
+	assert.Equal(t, []string{"102"}, bids)
+}
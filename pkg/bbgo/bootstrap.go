@@ -46,6 +46,14 @@ func BootstrapEnvironment(ctx context.Context, environ *Environment, userConfig
 		}
 	}
 
+	if userConfig.RemoteCommandApproval != nil {
+		if err := environ.ConfigureRemoteCommandApproval(userConfig.RemoteCommandApproval); err != nil {
+			return errors.Wrap(err, "remote command approval configure error")
+		}
+	}
+
+	environ.ConfigureCommandAuthorization(userConfig.CommandAuthorization)
+
 	if err := environ.ConfigureNotificationSystem(ctx, userConfig); err != nil {
 		return errors.Wrap(err, "notification configure error")
 	}
@@ -0,0 +1,61 @@
+package bbgo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Clock is the time source of the strategies and the order executors,
+// use Environment.Clock() instead of time.Now() so that the backtest and the tests can control the time.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock is the wall clock
+type SystemClock struct{}
+
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
+
+// SimulatedClock is the clock driven manually or by the kline stream, it's used in the backtest and the tests
+type SimulatedClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func NewSimulatedClock(now time.Time) *SimulatedClock {
+	return &SimulatedClock{now: now}
+}
+
+func (c *SimulatedClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Set moves the clock to the given time, the clock never goes backward
+func (c *SimulatedClock) Set(now time.Time) {
+	c.mu.Lock()
+	if now.After(c.now) {
+		c.now = now
+	}
+	c.mu.Unlock()
+}
+
+// Advance moves the clock forward by the duration
+func (c *SimulatedClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	c.now = c.now.Add(d)
+	c.mu.Unlock()
+}
+
+// BindStream moves the clock to the end time of the closed klines,
+// bind the stream before the strategies so that the strategies see the time of the kline.
+func (c *SimulatedClock) BindStream(stream types.Stream) {
+	stream.OnKLineClosed(func(k types.KLine) {
+		c.Set(k.EndTime.Time())
+	})
+}
@@ -0,0 +1,43 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestSimulatedClock(t *testing.T) {
+	start := time.Date(2023, 5, 20, 0, 0, 0, 0, time.UTC)
+	clock := NewSimulatedClock(start)
+	assert.Equal(t, start, clock.Now())
+
+	clock.Advance(time.Minute)
+	assert.Equal(t, start.Add(time.Minute), clock.Now())
+
+	clock.Set(start)
+	assert.Equal(t, start.Add(time.Minute), clock.Now(), "the clock never goes backward")
+
+	stream := &types.StandardStream{}
+	clock.BindStream(stream)
+	stream.EmitKLineClosed(types.KLine{
+		StartTime: types.Time(start.Add(time.Hour)),
+		EndTime:   types.Time(start.Add(2*time.Hour - time.Millisecond)),
+		Closed:    true,
+	})
+	assert.Equal(t, start.Add(2*time.Hour-time.Millisecond), clock.Now())
+}
+
+func TestEnvironment_Clock(t *testing.T) {
+	var environ *Environment
+	assert.IsType(t, SystemClock{}, environ.Clock())
+
+	environ = NewEnvironment()
+	assert.IsType(t, SystemClock{}, environ.Clock())
+
+	clock := NewSimulatedClock(time.Unix(0, 0))
+	environ.SetClock(clock)
+	assert.Equal(t, time.Unix(0, 0), environ.Clock().Now())
+}
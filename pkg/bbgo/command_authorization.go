@@ -0,0 +1,168 @@
+package bbgo
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/interact"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// CommandAuthorizationConfig enables the role-based authorization of the runtime commands from the messengers and the web api
+type CommandAuthorizationConfig struct {
+	// DefaultRole is the role of the users not listed in Users, defaults to viewer
+	DefaultRole interact.Role `json:"defaultRole,omitempty" yaml:"defaultRole,omitempty"`
+
+	// Users maps the user to the role, the user is the telegram username (or user id), the slack user id,
+	// or the web api user authenticated by the api token.
+	Users map[string]interact.Role `json:"users" yaml:"users"`
+
+	// APITokens maps the web api user to the bearer token of the Authorization header,
+	// the token is loaded from the env var if it's given as $ENV_VAR.
+	// The protected web api endpoints are not accessible without the tokens.
+	APITokens map[string]string `json:"apiTokens,omitempty" yaml:"apiTokens,omitempty"`
+}
+
+func (c *CommandAuthorizationConfig) Validate() error {
+	if c.DefaultRole != "" {
+		if err := c.DefaultRole.Validate(); err != nil {
+			return fmt.Errorf("defaultRole: %w", err)
+		}
+	}
+
+	for user, role := range c.Users {
+		if err := role.Validate(); err != nil {
+			return fmt.Errorf("users.%s: %w", user, err)
+		}
+	}
+
+	for user, token := range c.APITokens {
+		if expandToken(token) == "" {
+			return fmt.Errorf("apiTokens.%s: token is empty", user)
+		}
+	}
+
+	return nil
+}
+
+func expandToken(token string) string {
+	if strings.HasPrefix(token, "$") {
+		return os.Getenv(strings.TrimPrefix(token, "$"))
+	}
+
+	return token
+}
+
+// CommandAuthorizer checks the roles of the users and records the runtime commands in the audit log,
+// the roles are not checked if the authorization is not configured.
+type CommandAuthorizer struct {
+	config *CommandAuthorizationConfig
+
+	// auditService stores the audit log, the audit log is only written to the logger if it's nil
+	auditService *service.CommandAuditService
+
+	logger logrus.FieldLogger
+}
+
+func NewCommandAuthorizer(config *CommandAuthorizationConfig, auditService *service.CommandAuditService) *CommandAuthorizer {
+	return &CommandAuthorizer{
+		config:       config,
+		auditService: auditService,
+		logger:       logrus.WithField("component", "command_audit"),
+	}
+}
+
+// Enabled returns true if the roles of the users are checked
+func (a *CommandAuthorizer) Enabled() bool {
+	return a.config != nil
+}
+
+// Role returns the role of the user
+func (a *CommandAuthorizer) Role(user string) interact.Role {
+	if a.config == nil {
+		return interact.RoleAdmin
+	}
+
+	if role, ok := a.config.Users[user]; ok && user != "" {
+		return role
+	}
+
+	if a.config.DefaultRole != "" {
+		return a.config.DefaultRole
+	}
+
+	return interact.RoleViewer
+}
+
+// Authenticate returns the web api user of the bearer token, it returns false if the token is not configured
+func (a *CommandAuthorizer) Authenticate(token string) (string, bool) {
+	if a.config == nil || token == "" {
+		return "", false
+	}
+
+	for user, userToken := range a.config.APITokens {
+		expected := expandToken(userToken)
+		if expected != "" && subtle.ConstantTimeCompare([]byte(token), []byte(expected)) == 1 {
+			return user, true
+		}
+	}
+
+	return "", false
+}
+
+// ResolveRole is the interact.RoleResolver of the messenger sessions
+func (a *CommandAuthorizer) ResolveRole(session interact.Session) interact.Role {
+	return a.Role(interact.SessionUser(session))
+}
+
+// Authorize returns the role of the user, and an error if the role is not allowed to run the command that requires the given role
+func (a *CommandAuthorizer) Authorize(user string, required interact.Role) (interact.Role, error) {
+	role := a.Role(user)
+	if !role.Allows(required) {
+		return role, fmt.Errorf("permission denied, the %s role is required, the role of user %q is %s", required, user, role)
+	}
+
+	return role, nil
+}
+
+// LogCommand implements interact.AuditLogger
+func (a *CommandAuthorizer) LogCommand(entry interact.AuditEntry) {
+	a.logger.WithFields(logrus.Fields{
+		"source":  entry.Source,
+		"user":    entry.User,
+		"role":    entry.Role,
+		"command": entry.Command,
+		"result":  entry.Result,
+	}).Infof("[audit] %s ran %s %s: %s %s", entry.User, entry.Command, strings.Join(entry.Args, " "), entry.Result, entry.Error)
+
+	if a.auditService == nil {
+		return
+	}
+
+	record := service.CommandAuditLog{
+		Source:   entry.Source,
+		Username: entry.User,
+		Role:     string(entry.Role),
+		Command:  entry.Command,
+		Args:     strings.Join(entry.Args, " "),
+		Result:   string(entry.Result),
+		Time:     types.Time(entry.Time),
+	}
+
+	if len(record.Args) > 255 {
+		record.Args = record.Args[:255]
+	}
+
+	if entry.Error != "" {
+		record.Error = &entry.Error
+	}
+
+	if err := a.auditService.Insert(record); err != nil {
+		a.logger.WithError(err).Errorf("can not insert the command audit log")
+	}
+}
@@ -0,0 +1,89 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/interact"
+)
+
+func TestCommandAuthorizer(t *testing.T) {
+	t.Run("not configured", func(t *testing.T) {
+		authorizer := NewCommandAuthorizer(nil, nil)
+		assert.False(t, authorizer.Enabled())
+
+		role, err := authorizer.Authorize("anyone", interact.RoleAdmin)
+		assert.NoError(t, err)
+		assert.Equal(t, interact.RoleAdmin, role)
+	})
+
+	t.Run("roles", func(t *testing.T) {
+		config := &CommandAuthorizationConfig{
+			Users: map[string]interact.Role{
+				"alice": interact.RoleAdmin,
+				"bob":   interact.RoleOperator,
+			},
+		}
+		assert.NoError(t, config.Validate())
+
+		authorizer := NewCommandAuthorizer(config, nil)
+
+		_, err := authorizer.Authorize("alice", interact.RoleAdmin)
+		assert.NoError(t, err)
+
+		_, err = authorizer.Authorize("bob", interact.RoleOperator)
+		assert.NoError(t, err)
+
+		role, err := authorizer.Authorize("bob", interact.RoleAdmin)
+		assert.Error(t, err)
+		assert.Equal(t, interact.RoleOperator, role)
+
+		// the users not listed are viewers by default
+		role, err = authorizer.Authorize("", interact.RoleOperator)
+		assert.Error(t, err)
+		assert.Equal(t, interact.RoleViewer, role)
+	})
+
+	t.Run("invalid role", func(t *testing.T) {
+		config := &CommandAuthorizationConfig{
+			Users: map[string]interact.Role{"alice": "root"},
+		}
+		assert.ErrorContains(t, config.Validate(), "users.alice")
+	})
+	t.Run("api tokens", func(t *testing.T) {
+		t.Setenv("BBGO_TEST_API_TOKEN", "bob-token")
+
+		config := &CommandAuthorizationConfig{
+			Users: map[string]interact.Role{"alice": interact.RoleAdmin},
+			APITokens: map[string]string{
+				"alice": "alice-token",
+				"bob":   "$BBGO_TEST_API_TOKEN",
+			},
+		}
+		assert.NoError(t, config.Validate())
+
+		authorizer := NewCommandAuthorizer(config, nil)
+
+		user, ok := authorizer.Authenticate("alice-token")
+		assert.True(t, ok)
+		assert.Equal(t, "alice", user)
+
+		user, ok = authorizer.Authenticate("bob-token")
+		assert.True(t, ok)
+		assert.Equal(t, "bob", user)
+
+		_, ok = authorizer.Authenticate("")
+		assert.False(t, ok)
+
+		_, ok = authorizer.Authenticate("mallory-token")
+		assert.False(t, ok)
+
+		config.APITokens["carol"] = "$BBGO_TEST_UNSET_API_TOKEN"
+		assert.ErrorContains(t, config.Validate(), "apiTokens.carol")
+
+		// no user is authenticated without the authorization config
+		_, ok = NewCommandAuthorizer(nil, nil).Authenticate("alice-token")
+		assert.False(t, ok)
+	})
+}
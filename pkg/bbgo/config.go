@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"reflect"
 	"runtime"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -21,7 +21,9 @@ import (
 
 // DefaultFeeRate set the fee rate for most cases
 // BINANCE uses 0.1% for both maker and taker
-//  for BNB holders, it's 0.075% for both maker and taker
+//
+//	for BNB holders, it's 0.075% for both maker and taker
+//
 // MAX uses 0.050% for maker and 0.15% for taker
 var DefaultFeeRate = fixedpoint.NewFromFloat(0.075 * 0.01)
 
@@ -38,6 +40,17 @@ type ExchangeStrategyMount struct {
 
 	// Strategy is the strategy we loaded from config
 	Strategy SingleExchangeStrategy `json:"strategy"`
+
+	// Live acknowledges that the strategy trades with real funds right after it's added,
+	// without the dry-run warm-up period of the live trading guard.
+	Live bool `json:"live,omitempty"`
+
+	// Shadow overrides the strategy config for the shadow instance, the shadow instance runs with the live
+	// market data and the simulated fills, so that the config changes can be compared before switching.
+	Shadow map[string]interface{} `json:"shadow,omitempty"`
+
+	// ProfitLock flattens the position and suspends the strategy when the profit falls below the trailing floor
+	ProfitLock *ProfitLockConfig `json:"profitLock,omitempty"`
 }
 
 func (m *ExchangeStrategyMount) Map() (map[string]interface{}, error) {
@@ -54,10 +67,24 @@ func (m *ExchangeStrategyMount) Map() (map[string]interface{}, error) {
 		return nil, err
 	}
 
-	return map[string]interface{}{
+	mount := map[string]interface{}{
 		"on":       m.Mounts,
 		strategyID: params,
-	}, nil
+	}
+
+	if m.Live {
+		mount["live"] = true
+	}
+
+	if m.Shadow != nil {
+		mount["shadow"] = m.Shadow
+	}
+
+	if m.ProfitLock != nil {
+		mount["profitLock"] = m.ProfitLock
+	}
+
+	return mount, nil
 }
 
 type SlackNotification struct {
@@ -87,6 +114,28 @@ type NotificationConfig struct {
 	Slack    *SlackNotification    `json:"slack,omitempty" yaml:"slack,omitempty"`
 	Telegram *TelegramNotification `json:"telegram,omitempty" yaml:"telegram,omitempty"`
 	Switches *NotificationSwitches `json:"switches" yaml:"switches"`
+
+	// ProfitChart attaches the candlestick chart with the fills to the profit notifications
+	ProfitChart *ProfitChartConfig `json:"profitChart,omitempty" yaml:"profitChart,omitempty"`
+}
+
+// LiveTradingGuardConfig enables the dry-run warm-up period of the newly added strategy instances,
+// the guard is off if it's not configured.
+type LiveTradingGuardConfig struct {
+	// Disabled turns off the configured guard, so that every strategy instance trades with real funds at startup
+	Disabled bool `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+
+	// WarmUpPeriod is how long a newly added strategy instance stays in the dry-run mode, defaults to 1h
+	WarmUpPeriod types.Duration `json:"warmUpPeriod,omitempty" yaml:"warmUpPeriod,omitempty"`
+}
+
+// ShadowTradingConfig configures the shadow instances of the strategies, which are defined by the shadow config overrides
+type ShadowTradingConfig struct {
+	// Latency is the simulated latency of the shadow order submissions
+	Latency types.Duration `json:"latency,omitempty" yaml:"latency,omitempty"`
+
+	// ReportInterval is the interval of the live-vs-shadow PnL report, defaults to 1h
+	ReportInterval types.Duration `json:"reportInterval,omitempty" yaml:"reportInterval,omitempty"`
 }
 
 type LoggingConfig struct {
@@ -127,6 +176,67 @@ const (
 	BacktestFeeModeToken // BackTestFeeMode = "token"
 )
 
+// BacktestPricePathModel is the model of the price path within a kline,
+// which decides the order of the stop and limit orders being triggered within the kline.
+type BacktestPricePathModel string
+
+const (
+	// BacktestPricePathModelDirection visits the high first on a down kline, and the low first on an up kline.
+	// This is the default model.
+	BacktestPricePathModelDirection BacktestPricePathModel = "direction"
+
+	// BacktestPricePathModelOHLC always visits open, high, low and then close
+	BacktestPricePathModelOHLC BacktestPricePathModel = "ohlc"
+
+	// BacktestPricePathModelOLHC always visits open, low, high and then close
+	BacktestPricePathModelOLHC BacktestPricePathModel = "olhc"
+
+	// BacktestPricePathModelNearest visits the extreme nearer to the open price first
+	BacktestPricePathModelNearest BacktestPricePathModel = "nearest"
+
+	// BacktestPricePathModelBrownianBridge simulates a random walk from open to close that touches the high and the low
+	BacktestPricePathModelBrownianBridge BacktestPricePathModel = "brownianBridge"
+)
+
+type BacktestPricePath struct {
+	Model BacktestPricePathModel `json:"model" yaml:"model"`
+
+	// Steps is the number of the simulated steps of the brownian bridge model, defaults to 20
+	Steps int `json:"steps,omitempty" yaml:"steps,omitempty"`
+
+	// Seed is the random seed of the brownian bridge model, the same seed gives the same paths
+	Seed int64 `json:"seed,omitempty" yaml:"seed,omitempty"`
+}
+
+// BacktestMonteCarloMethod is the method of generating the simulated trade sequences
+type BacktestMonteCarloMethod string
+
+const (
+	// BacktestMonteCarloMethodBootstrap draws the trades with replacement
+	BacktestMonteCarloMethodBootstrap BacktestMonteCarloMethod = "bootstrap"
+
+	// BacktestMonteCarloMethodPermutation shuffles the order of the trades
+	BacktestMonteCarloMethodPermutation BacktestMonteCarloMethod = "permutation"
+)
+
+// BacktestMonteCarlo enables the monte carlo analysis of the trade sequence after the backtest
+type BacktestMonteCarlo struct {
+	// Method is bootstrap or permutation, defaults to bootstrap
+	Method BacktestMonteCarloMethod `json:"method,omitempty" yaml:"method,omitempty"`
+
+	// Runs is the number of the simulations, defaults to 1000
+	Runs int `json:"runs,omitempty" yaml:"runs,omitempty"`
+
+	// PriceNoise is the standard deviation of the relative price noise added to the trade prices, e.g., 0.1%
+	PriceNoise fixedpoint.Value `json:"priceNoise,omitempty" yaml:"priceNoise,omitempty"`
+
+	// ConfidenceLevel is the confidence level of the intervals, defaults to 95%
+	ConfidenceLevel fixedpoint.Value `json:"confidenceLevel,omitempty" yaml:"confidenceLevel,omitempty"`
+
+	// Seed is the random seed, the same seed gives the same result
+	Seed int64 `json:"seed,omitempty" yaml:"seed,omitempty"`
+}
+
 type Backtest struct {
 	StartTime types.LooseFormatTime  `json:"startTime,omitempty" yaml:"startTime,omitempty"`
 	EndTime   *types.LooseFormatTime `json:"endTime,omitempty" yaml:"endTime,omitempty"`
@@ -146,6 +256,12 @@ type Backtest struct {
 
 	// sync 1 second interval KLines
 	SyncSecKLines bool `json:"syncSecKLines,omitempty" yaml:"syncSecKLines,omitempty"`
+
+	// PricePath is the intra-bar price path model used for matching the orders within a kline
+	PricePath *BacktestPricePath `json:"pricePath,omitempty" yaml:"pricePath,omitempty"`
+
+	// MonteCarlo adds the confidence intervals of the final equity and the max drawdown into the report
+	MonteCarlo *BacktestMonteCarlo `json:"monteCarlo,omitempty" yaml:"monteCarlo,omitempty"`
 }
 
 func (b *Backtest) GetAccount(n string) BacktestAccount {
@@ -162,11 +278,152 @@ func (b *Backtest) GetAccount(n string) BacktestAccount {
 	return DefaultBacktestAccount
 }
 
+// BalanceScenarios returns the names of the balance scenarios defined in the accounts, in the order of the definitions
+func (b *Backtest) BalanceScenarios() (names []string) {
+	seen := make(map[string]struct{})
+	for _, accounts := range []map[string]BacktestAccount{b.Accounts, b.Account} {
+		for _, sessionName := range sortedAccountNames(accounts) {
+			for _, scenario := range accounts[sessionName].Scenarios {
+				if _, ok := seen[scenario.Name]; ok {
+					continue
+				}
+
+				seen[scenario.Name] = struct{}{}
+				names = append(names, scenario.Name)
+			}
+		}
+	}
+
+	return names
+}
+
+// WithBalanceScenario returns a copy of the backtest config, the balances of the accounts are replaced by the balances of the scenario,
+// the accounts without the scenario keep their balances.
+func (b *Backtest) WithBalanceScenario(name string) *Backtest {
+	bb := *b
+	bb.Accounts = withBalanceScenario(b.Accounts, name)
+	bb.Account = withBalanceScenario(b.Account, name)
+	return &bb
+}
+
+// Validate checks the balance scenarios of the accounts
+func (b *Backtest) Validate() error {
+	for _, accounts := range []map[string]BacktestAccount{b.Accounts, b.Account} {
+		for _, sessionName := range sortedAccountNames(accounts) {
+			seen := make(map[string]struct{})
+			for i, scenario := range accounts[sessionName].Scenarios {
+				if len(scenario.Name) == 0 {
+					return fmt.Errorf("account %s: scenarios[%d].name is required", sessionName, i)
+				}
+
+				if _, ok := seen[scenario.Name]; ok {
+					return fmt.Errorf("account %s: scenario %s is duplicated", sessionName, scenario.Name)
+				}
+
+				if len(scenario.Balances) == 0 {
+					return fmt.Errorf("account %s: scenario %s has no balances", sessionName, scenario.Name)
+				}
+
+				seen[scenario.Name] = struct{}{}
+			}
+		}
+	}
+
+	return nil
+}
+
+func withBalanceScenario(accounts map[string]BacktestAccount, name string) map[string]BacktestAccount {
+	if accounts == nil {
+		return nil
+	}
+
+	newAccounts := make(map[string]BacktestAccount, len(accounts))
+	for sessionName, account := range accounts {
+		for _, scenario := range account.Scenarios {
+			if scenario.Name == name {
+				account.Balances = scenario.Balances
+				break
+			}
+		}
+
+		newAccounts[sessionName] = account
+	}
+
+	return newAccounts
+}
+
+func sortedAccountNames(accounts map[string]BacktestAccount) []string {
+	var names []string
+	for name := range accounts {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+// BacktestBalanceScenario is an alternative set of the initial balances of the backtest account
+type BacktestBalanceScenario struct {
+	Name     string                    `json:"name" yaml:"name"`
+	Balances BacktestAccountBalanceMap `json:"balances" yaml:"balances"`
+}
+
 type BacktestAccount struct {
 	MakerFeeRate fixedpoint.Value `json:"makerFeeRate,omitempty" yaml:"makerFeeRate,omitempty"`
 	TakerFeeRate fixedpoint.Value `json:"takerFeeRate,omitempty" yaml:"takerFeeRate,omitempty"`
 
 	Balances BacktestAccountBalanceMap `json:"balances" yaml:"balances"`
+
+	// Margin enables the leveraged trading simulation, the account is a spot account if it's not set
+	Margin *BacktestMarginAccount `json:"margin,omitempty" yaml:"margin,omitempty"`
+
+	// Scenarios are the alternative initial balances, the backtest runs with the balances above and then once for each scenario,
+	// so that the strategy capacity and the effects of the min notional constraints can be compared in one run.
+	Scenarios []BacktestBalanceScenario `json:"scenarios,omitempty" yaml:"scenarios,omitempty"`
+}
+
+// BacktestMarginMode decides how the margin is shared between the positions
+type BacktestMarginMode string
+
+const (
+	// BacktestMarginModeCross shares the wallet balance between all the positions,
+	// all the positions are liquidated when the account equity drops below the total maintenance margin.
+	BacktestMarginModeCross BacktestMarginMode = "cross"
+
+	// BacktestMarginModeIsolated allocates the margin to each position,
+	// only the position is liquidated and the loss is limited to its margin.
+	BacktestMarginModeIsolated BacktestMarginMode = "isolated"
+)
+
+// BacktestMarginAccount is the leveraged account simulated by the backtest matching engine.
+// The positions are settled in the quote currency, the base currency balances are not changed by the trades.
+type BacktestMarginAccount struct {
+	// Type is the simulated account type, valid values are futures and margin.
+	// The funding fee is charged on the futures account, the borrow interest is charged on the margin account.
+	Type types.AccountType `json:"type" yaml:"type"`
+
+	// MarginMode is cross or isolated, defaults to cross
+	MarginMode BacktestMarginMode `json:"marginMode,omitempty" yaml:"marginMode,omitempty"`
+
+	// Leverage is the max leverage of the positions, the initial margin of an order is notional / leverage, defaults to 1
+	Leverage fixedpoint.Value `json:"leverage,omitempty" yaml:"leverage,omitempty"`
+
+	// MaintenanceMarginRatio is the ratio of the position notional that needs to be kept, defaults to 0.5%
+	MaintenanceMarginRatio fixedpoint.Value `json:"maintenanceMarginRatio,omitempty" yaml:"maintenanceMarginRatio,omitempty"`
+
+	// FundingRate is the funding rate of each funding interval, the long positions pay the short positions when it's positive,
+	// defaults to 0.01%
+	FundingRate fixedpoint.Value `json:"fundingRate,omitempty" yaml:"fundingRate,omitempty"`
+
+	// FundingRates overrides the funding rate by symbol
+	FundingRates map[string]fixedpoint.Value `json:"fundingRates,omitempty" yaml:"fundingRates,omitempty"`
+
+	// FundingInterval is the interval of the funding payments, aligned to 00:00 UTC, defaults to 8 hours
+	FundingInterval types.Duration `json:"fundingInterval,omitempty" yaml:"fundingInterval,omitempty"`
+
+	// BorrowInterestRate is the daily interest rate of the borrowed amount, the interest is charged hourly,
+	// defaults to 0.02%
+	BorrowInterestRate fixedpoint.Value `json:"borrowInterestRate,omitempty" yaml:"borrowInterestRate,omitempty"`
 }
 
 var DefaultBacktestAccount = BacktestAccount{
@@ -212,8 +469,9 @@ func (m BacktestAccountBalanceMap) BalanceMap() types.BalanceMap {
 }
 
 type PersistenceConfig struct {
-	Redis *service.RedisPersistenceConfig `json:"redis,omitempty" yaml:"redis,omitempty"`
-	Json  *service.JsonPersistenceConfig  `json:"json,omitempty" yaml:"json,omitempty"`
+	Redis    *service.RedisPersistenceConfig    `json:"redis,omitempty" yaml:"redis,omitempty"`
+	Postgres *service.PostgresPersistenceConfig `json:"postgres,omitempty" yaml:"postgres,omitempty"`
+	Json     *service.JsonPersistenceConfig     `json:"json,omitempty" yaml:"json,omitempty"`
 }
 
 type BuildTargetConfig struct {
@@ -333,10 +591,53 @@ type Config struct {
 
 	Logging *LoggingConfig `json:"logging,omitempty"`
 
+	LiveTradingGuard *LiveTradingGuardConfig `json:"liveTradingGuard,omitempty" yaml:"liveTradingGuard,omitempty"`
+
+	ShadowTrading *ShadowTradingConfig `json:"shadowTrading,omitempty" yaml:"shadowTrading,omitempty"`
+
+	RemoteCommandApproval *RemoteCommandApprovalConfig `json:"remoteCommandApproval,omitempty" yaml:"remoteCommandApproval,omitempty"`
+
+	CommandAuthorization *CommandAuthorizationConfig `json:"commandAuthorization,omitempty" yaml:"commandAuthorization,omitempty"`
+
+	MarkToMarket *MarkToMarketConfig `json:"markToMarket,omitempty" yaml:"markToMarket,omitempty"`
+
+	PositionNetting *PositionNettingConfig `json:"positionNetting,omitempty" yaml:"positionNetting,omitempty"`
+
+	MarginMonitor *MarginMonitorConfig `json:"marginMonitor,omitempty" yaml:"marginMonitor,omitempty"`
+
+	BookRecorder *BookRecorderConfig `json:"bookRecorder,omitempty" yaml:"bookRecorder,omitempty"`
+
+	SessionRecorder *SessionRecorderConfig `json:"sessionRecorder,omitempty" yaml:"sessionRecorder,omitempty"`
+
+	PortfolioRisk *PortfolioRiskConfig `json:"portfolioRisk,omitempty" yaml:"portfolioRisk,omitempty"`
+
+	PerformanceDigest *PerformanceDigestConfig `json:"performanceDigest,omitempty" yaml:"performanceDigest,omitempty"`
+
+	DeadManSwitch *DeadManSwitchConfig `json:"deadManSwitch,omitempty" yaml:"deadManSwitch,omitempty"`
+
+	Secrets *SecretsConfig `json:"secrets,omitempty" yaml:"secrets,omitempty"`
+
+	MessageBus *MessageBusConfig `json:"messageBus,omitempty" yaml:"messageBus,omitempty"`
+
+	WebhookSignals *WebhookSignalConfig `json:"webhookSignals,omitempty" yaml:"webhookSignals,omitempty"`
+
+	Scheduler *SchedulerConfig `json:"scheduler,omitempty" yaml:"scheduler,omitempty"`
+
+	Shutdown *ShutdownConfig `json:"shutdown,omitempty" yaml:"shutdown,omitempty"`
+
+	PanicRecovery *PanicRecoveryConfig `json:"panicRecovery,omitempty" yaml:"panicRecovery,omitempty"`
+
+	Coordinator *CoordinatorConfig `json:"coordinator,omitempty" yaml:"coordinator,omitempty"`
+
+	HealthCheck *HealthCheckConfig `json:"healthCheck,omitempty" yaml:"healthCheck,omitempty"`
+
 	ExchangeStrategies      []ExchangeStrategyMount `json:"-" yaml:"-"`
 	CrossExchangeStrategies []CrossExchangeStrategy `json:"-" yaml:"-"`
 
 	PnLReporters []PnLReporterConfig `json:"reportPnL,omitempty" yaml:"reportPnL,omitempty"`
+
+	// strategyConfigs are the strategy configs loaded from the config file, they are used for restarting the strategy instances
+	strategyConfigs map[StrategyID]json.RawMessage
 }
 
 func (c *Config) Map() (map[string]interface{}, error) {
@@ -452,7 +753,7 @@ func loadStash(config []byte) (Stash, error) {
 func LoadBuildConfig(configFile string) (*Config, error) {
 	var config Config
 
-	content, err := ioutil.ReadFile(configFile)
+	content, err := loadConfigContent(configFile)
 	if err != nil {
 		return nil, err
 	}
@@ -482,7 +783,7 @@ func LoadBuildConfig(configFile string) (*Config, error) {
 func Load(configFile string, loadStrategies bool) (*Config, error) {
 	var config Config
 
-	content, err := ioutil.ReadFile(configFile)
+	content, err := loadConfigContent(configFile)
 	if err != nil {
 		return nil, err
 	}
@@ -618,6 +919,36 @@ func loadExchangeStrategies(config *Config, stash Stash) (err error) {
 				return fmt.Errorf("unexpected mount type: %T value: %+v", val, val)
 			}
 		}
+
+		var live bool
+		if val, ok := configStash["live"]; ok {
+			if live, ok = val.(bool); !ok {
+				return fmt.Errorf("unexpected live flag type: %T value: %+v, expecting bool", val, val)
+			}
+		}
+
+		var shadow map[string]interface{}
+		if val, ok := configStash["shadow"]; ok {
+			switch tv := val.(type) {
+			case Stash:
+				shadow = tv
+			case map[string]interface{}:
+				shadow = tv
+			default:
+				return fmt.Errorf("unexpected shadow config type: %T value: %+v, expecting map", val, val)
+			}
+		}
+
+		var profitLock *ProfitLockConfig
+		if val, ok := configStash["profitLock"]; ok {
+			conf, err := reUnmarshal(val, &ProfitLockConfig{})
+			if err != nil {
+				return errors.Wrap(err, "unexpected profitLock config")
+			}
+
+			profitLock = conf.(*ProfitLockConfig)
+		}
+
 		for id, conf := range configStash {
 
 			// look up the real struct type
@@ -627,11 +958,24 @@ func loadExchangeStrategies(config *Config, stash Stash) (err error) {
 					return err
 				}
 
+				rawConfig, err := json.Marshal(conf)
+				if err != nil {
+					return err
+				}
+
+				if config.strategyConfigs == nil {
+					config.strategyConfigs = make(map[StrategyID]json.RawMessage)
+				}
+				config.strategyConfigs[st] = rawConfig
+
 				config.ExchangeStrategies = append(config.ExchangeStrategies, ExchangeStrategyMount{
-					Mounts:   mounts,
-					Strategy: st,
+					Mounts:     mounts,
+					Strategy:   st,
+					Live:       live,
+					Shadow:     shadow,
+					ProfitLock: profitLock,
 				})
-			} else if id != "on" && id != "off" {
+			} else if id != "on" && id != "off" && id != "live" && id != "shadow" && id != "profitLock" {
 				// Show error when we didn't find the Strategy
 				return fmt.Errorf("strategy %s in config not found", id)
 			}
@@ -0,0 +1,187 @@
+package bbgo
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigEnvVar is the env var of the config environment, e.g., BBGO_ENV=prod applies the prod overlay
+const ConfigEnvVar = "BBGO_ENV"
+
+// appendConfigKeys are the top-level lists that are appended instead of replaced when the config files are merged
+var appendConfigKeys = map[string]struct{}{
+	"exchangeStrategies":      {},
+	"crossExchangeStrategies": {},
+	"strategies":              {},
+}
+
+// loadConfigContent reads the config file and resolves the includes and the environment overlays.
+//
+// The "include" key lists the files (or the glob patterns) relative to the including file, the included files are merged in order,
+// and the including file is merged over them. The "overlays" key maps the environment name to the config merged last,
+// the environment is selected by the env var BBGO_ENV, e.g.,
+//
+//	include:
+//	- sessions.yaml
+//	- strategies/*.yaml
+//	overlays:
+//	  dev:
+//	    sessions:
+//	      binance:
+//	        envVarPrefix: binance_testnet
+//
+// The maps are merged recursively, the top-level strategy lists are appended, the other values are replaced,
+// and a null value removes the key.
+func loadConfigContent(configFile string) ([]byte, error) {
+	content, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc map[string]interface{}
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, err
+	}
+
+	// keep the single file config as it is
+	_, hasInclude := doc["include"]
+	_, hasOverlays := doc["overlays"]
+	if !hasInclude && !hasOverlays {
+		return content, nil
+	}
+
+	merged, err := loadConfigDocument(configFile, os.Getenv(ConfigEnvVar), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(merged)
+}
+
+func loadConfigDocument(configFile, env string, stack []string) (map[string]interface{}, error) {
+	absPath, err := filepath.Abs(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, p := range stack {
+		if p == absPath {
+			return nil, fmt.Errorf("config include cycle: %s -> %s", strings.Join(stack, " -> "), absPath)
+		}
+	}
+	stack = append(stack, absPath)
+
+	content, err := ioutil.ReadFile(configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := make(map[string]interface{})
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("%s: %w", configFile, err)
+	}
+
+	includes, err := configStringList(doc["include"])
+	if err != nil {
+		return nil, fmt.Errorf("%s: include %w", configFile, err)
+	}
+
+	overlays, ok := doc["overlays"].(map[string]interface{})
+	if !ok && doc["overlays"] != nil {
+		return nil, fmt.Errorf("%s: overlays should be a map of the environment name to the config", configFile)
+	}
+
+	delete(doc, "include")
+	delete(doc, "overlays")
+
+	merged := make(map[string]interface{})
+	for _, include := range includes {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(configFile), include)
+		}
+
+		files, err := filepath.Glob(include)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid include pattern %q: %w", configFile, include, err)
+		}
+
+		if len(files) == 0 {
+			return nil, fmt.Errorf("%s: included file %q is not found", configFile, include)
+		}
+
+		for _, file := range files {
+			included, err := loadConfigDocument(file, env, stack)
+			if err != nil {
+				return nil, err
+			}
+
+			mergeConfigMap(merged, included, true)
+		}
+	}
+
+	mergeConfigMap(merged, doc, true)
+
+	if overlay, ok := overlays[env].(map[string]interface{}); ok && env != "" {
+		mergeConfigMap(merged, overlay, true)
+	}
+
+	return merged, nil
+}
+
+// mergeConfigMap merges src into dst, see loadConfigContent for the merge rules
+func mergeConfigMap(dst, src map[string]interface{}, topLevel bool) {
+	for key, value := range src {
+		if value == nil {
+			delete(dst, key)
+			continue
+		}
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			if m, ok := dst[key].(map[string]interface{}); ok {
+				mergeConfigMap(m, v, false)
+				continue
+			}
+
+		case []interface{}:
+			if _, ok := appendConfigKeys[key]; ok && topLevel {
+				if list, ok := dst[key].([]interface{}); ok {
+					dst[key] = append(list, v...)
+					continue
+				}
+			}
+		}
+
+		dst[key] = value
+	}
+}
+
+func configStringList(value interface{}) ([]string, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+
+	case string:
+		return []string{v}, nil
+
+	case []interface{}:
+		var list []string
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, fmt.Errorf("should be a list of strings, given %T %+v", item, item)
+			}
+
+			list = append(list, s)
+		}
+
+		return list, nil
+	}
+
+	return nil, fmt.Errorf("should be a string or a list of strings, given %T", value)
+}
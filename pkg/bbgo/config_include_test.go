@@ -0,0 +1,53 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestLoadConfig_Include(t *testing.T) {
+	t.Run("without overlay", func(t *testing.T) {
+		t.Setenv(ConfigEnvVar, "")
+
+		config, err := Load("testdata/include/main.yaml", true)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Len(t, config.Sessions, 2)
+		if assert.Contains(t, config.Sessions, "binance") {
+			assert.Equal(t, "BINANCE", config.Sessions["binance"].EnvVarPrefix)
+			assert.Equal(t, fixedpoint.MustNewFromString("0.00075"), config.Sessions["binance"].MakerFeeRate)
+		}
+
+		// the strategy lists of the included files are appended in the file name order
+		if assert.Len(t, config.ExchangeStrategies, 2) {
+			assert.Equal(t, "BTCUSDT", config.ExchangeStrategies[0].Strategy.(*TestStrategy).Symbol)
+			assert.Equal(t, "ETHUSDT", config.ExchangeStrategies[1].Strategy.(*TestStrategy).Symbol)
+			assert.Equal(t, []string{"binance"}, config.ExchangeStrategies[1].Mounts)
+		}
+	})
+
+	t.Run("prod overlay", func(t *testing.T) {
+		t.Setenv(ConfigEnvVar, "prod")
+
+		config, err := Load("testdata/include/main.yaml", true)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.NotContains(t, config.Sessions, "max", "the null value removes the session")
+		if assert.Contains(t, config.Sessions, "binance") {
+			assert.Equal(t, "BINANCE_PROD", config.Sessions["binance"].EnvVarPrefix)
+			assert.Equal(t, "binance", config.Sessions["binance"].ExchangeName.String())
+		}
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		_, err := Load("testdata/include/cycle.yaml", true)
+		assert.ErrorContains(t, err, "config include cycle")
+	})
+}
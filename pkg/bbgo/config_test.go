@@ -4,6 +4,7 @@ import (
 	"context"
 	"io/ioutil"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gopkg.in/yaml.v3"
@@ -182,6 +183,48 @@ func TestLoadConfig(t *testing.T) {
 				assert.NotNil(t, executorConf)
 			},
 		},
+		{
+			name:    "shadow",
+			args:    args{configFile: "testdata/shadow.yaml"},
+			wantErr: false,
+			f: func(t *testing.T, config *Config) {
+				if assert.NotNil(t, config.ShadowTrading) {
+					assert.Equal(t, 200*time.Millisecond, config.ShadowTrading.Latency.Duration())
+					assert.Equal(t, 30*time.Minute, config.ShadowTrading.ReportInterval.Duration())
+				}
+
+				if assert.Len(t, config.ExchangeStrategies, 1) {
+					mount := config.ExchangeStrategies[0]
+					assert.Equal(t, "test", mount.Strategy.ID())
+					assert.Equal(t, map[string]interface{}{"baseQuantity": 0.2}, mount.Shadow)
+
+					shadow, err := newShadowStrategy("test", config.strategyConfigs[mount.Strategy], mount.Shadow)
+					if assert.NoError(t, err) {
+						shadowStrategy, ok := shadow.(*TestStrategy)
+						if assert.True(t, ok) {
+							assert.Equal(t, "BTCUSDT", shadowStrategy.Symbol)
+							assert.Equal(t, "0.2", shadowStrategy.BaseQuantity.String())
+						}
+					}
+				}
+			},
+		},
+		{
+			name:    "profitLock",
+			args:    args{configFile: "testdata/profit_lock.yaml"},
+			wantErr: false,
+			f: func(t *testing.T, config *Config) {
+				if assert.Len(t, config.ExchangeStrategies, 1) {
+					profitLock := config.ExchangeStrategies[0].ProfitLock
+					if assert.NotNil(t, profitLock) {
+						assert.Equal(t, "100", profitLock.Activation.String())
+						assert.Equal(t, "0.3", profitLock.TrailingRatio.String())
+						assert.Equal(t, 30*time.Second, profitLock.Interval.Duration())
+						assert.NoError(t, profitLock.Validate())
+					}
+				}
+			},
+		},
 		{
 			name:    "backtest",
 			args:    args{configFile: "testdata/backtest.yaml"},
@@ -192,6 +235,14 @@ func TestLoadConfig(t *testing.T) {
 				assert.NotNil(t, config.Backtest.Account)
 				assert.NotNil(t, config.Backtest.Account["binance"].Balances)
 				assert.Len(t, config.Backtest.Account["binance"].Balances, 2)
+
+				assert.NoError(t, config.Backtest.Validate())
+				assert.Equal(t, []string{"small", "large"}, config.Backtest.BalanceScenarios())
+
+				small := config.Backtest.WithBalanceScenario("small")
+				assert.Equal(t, BacktestAccountBalanceMap{"USDT": fixedpoint.NewFromFloat(1000.0)}, small.GetAccount("binance").Balances)
+				assert.Equal(t, "15", small.GetAccount("binance").MakerFeeRate.String())
+				assert.Len(t, config.Backtest.Account["binance"].Balances, 2, "the original balances should not be changed")
 			},
 		},
 	}
@@ -247,6 +298,26 @@ func TestSyncSymbol(t *testing.T) {
 	})
 }
 
+func TestBacktest_Validate(t *testing.T) {
+	backtest := &Backtest{
+		Accounts: map[string]BacktestAccount{
+			"binance": {
+				Scenarios: []BacktestBalanceScenario{
+					{Name: "small", Balances: BacktestAccountBalanceMap{"USDT": fixedpoint.NewFromFloat(1000.0)}},
+					{Name: "small", Balances: BacktestAccountBalanceMap{"USDT": fixedpoint.NewFromFloat(2000.0)}},
+				},
+			},
+		},
+	}
+	assert.Error(t, backtest.Validate(), "duplicated scenario")
+
+	backtest.Accounts["binance"].Scenarios[1].Name = ""
+	assert.Error(t, backtest.Validate(), "scenario without name")
+
+	backtest.Accounts["binance"].Scenarios[1].Name = "medium"
+	assert.NoError(t, backtest.Validate())
+}
+
 func TestBackTestFeeMode(t *testing.T) {
 	var mode BacktestFeeMode
 	var err = yaml.Unmarshal([]byte(`quote`), &mode)
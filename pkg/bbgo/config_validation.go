@@ -0,0 +1,165 @@
+package bbgo
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// maxConfigValidationDepth limits the nested struct fields checked by the struct tags
+const maxConfigValidationDepth = 5
+
+// ConfigError is the config validation error at the YAML path, e.g., exchangeStrategies[0].scmaker.priceRangeBollinger
+type ConfigError struct {
+	Path string
+	Err  error
+}
+
+func (e ConfigError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// ConfigErrors is the list of all the config validation errors
+type ConfigErrors []ConfigError
+
+func (errs ConfigErrors) Error() string {
+	var lines []string
+	for _, err := range errs {
+		lines = append(lines, "  "+err.Error())
+	}
+
+	return fmt.Sprintf("found %d config error(s):\n%s", len(errs), strings.Join(lines, "\n"))
+}
+
+// ValidateConfig checks the loaded config before any session is connected, it returns ConfigErrors with all the errors found.
+//
+// The strategies are checked by the fields tagged with `validate:"required"` (the zero values and the nil pointers are reported)
+// and the optional Validate() method after the optional Defaults() method.
+// The top-level configs with the Validate() method are checked, and the strategy mounts should refer to the defined sessions.
+func ValidateConfig(config *Config) error {
+	var errs ConfigErrors
+
+	rv := reflect.ValueOf(config).Elem()
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" || field.Type.Kind() != reflect.Ptr || rv.Field(i).IsNil() {
+			continue
+		}
+
+		if v, ok := rv.Field(i).Interface().(StrategyValidator); ok {
+			if err := v.Validate(); err != nil {
+				errs = append(errs, ConfigError{Path: configFieldName(field), Err: err})
+			}
+		}
+	}
+
+	for i, mount := range config.ExchangeStrategies {
+		path := fmt.Sprintf("exchangeStrategies[%d]", i)
+
+		if len(config.Sessions) > 0 {
+			for _, sessionName := range mount.Mounts {
+				if _, ok := config.Sessions[sessionName]; !ok {
+					errs = append(errs, ConfigError{Path: path + ".on", Err: fmt.Errorf("session %q is not defined", sessionName)})
+				}
+			}
+		}
+
+		errs = append(errs, validateStrategy(path+"."+mount.Strategy.ID(), mount.Strategy)...)
+	}
+
+	for i, strategy := range config.CrossExchangeStrategies {
+		errs = append(errs, validateStrategy(fmt.Sprintf("crossExchangeStrategies[%d].%s", i, strategy.ID()), strategy)...)
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func validateStrategy(path string, strategy interface{}) (errs ConfigErrors) {
+	if defaulter, ok := strategy.(StrategyDefaulter); ok {
+		if err := defaulter.Defaults(); err != nil {
+			return ConfigErrors{{Path: path, Err: err}}
+		}
+	}
+
+	errs = validateRequiredFields(path, reflect.ValueOf(strategy), 0)
+
+	// the Validate method may rely on the required fields, so it's only called when the required fields are set
+	if len(errs) > 0 {
+		return errs
+	}
+
+	if v, ok := strategy.(StrategyValidator); ok {
+		if err := v.Validate(); err != nil {
+			errs = append(errs, ConfigError{Path: path, Err: err})
+		}
+	}
+
+	return errs
+}
+
+// validateRequiredFields checks the fields tagged with `validate:"required"` recursively
+func validateRequiredFields(path string, rv reflect.Value, depth int) (errs ConfigErrors) {
+	if depth > maxConfigValidationDepth {
+		return nil
+	}
+
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return nil
+		}
+
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		field := rt.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		// the fields without the json key are injected at runtime
+		if field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		fieldPath := path
+		if !field.Anonymous {
+			fieldPath = path + "." + configFieldName(field)
+		}
+
+		fv := rv.Field(i)
+		if field.Tag.Get("validate") == "required" && fv.IsZero() {
+			errs = append(errs, ConfigError{Path: fieldPath, Err: fmt.Errorf("%s is required", configFieldName(field))})
+			continue
+		}
+
+		errs = append(errs, validateRequiredFields(fieldPath, fv, depth+1)...)
+	}
+
+	return errs
+}
+
+// configFieldName returns the YAML key of the field
+func configFieldName(field reflect.StructField) string {
+	for _, key := range []string{"yaml", "json"} {
+		if name := strings.Split(field.Tag.Get(key), ",")[0]; name != "" && name != "-" {
+			return name
+		}
+	}
+
+	return field.Name
+}
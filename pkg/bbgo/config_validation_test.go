@@ -0,0 +1,80 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type validationTestBand struct {
+	Window int `json:"window" validate:"required"`
+}
+
+type validationTestStrategy struct {
+	Symbol    string              `json:"symbol" validate:"required"`
+	NumLayers int                 `json:"numLayers" validate:"required"`
+	Band      *validationTestBand `json:"band" validate:"required"`
+	MaxLayers int                 `json:"maxLayers"`
+
+	Environment *Environment `json:"-" validate:"required"`
+}
+
+func (s *validationTestStrategy) ID() string {
+	return "validationtest"
+}
+
+func (s *validationTestStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+func (s *validationTestStrategy) Defaults() error {
+	if s.MaxLayers == 0 {
+		s.MaxLayers = 10
+	}
+	return nil
+}
+
+func (s *validationTestStrategy) Validate() error {
+	if s.NumLayers > s.MaxLayers {
+		return errors.New("numLayers should not be greater than maxLayers")
+	}
+	return nil
+}
+
+func TestValidateConfig(t *testing.T) {
+	config := &Config{
+		Sessions: map[string]*ExchangeSession{"binance": {}},
+		MessageBus: &MessageBusConfig{Topics: map[string]MessageTopicConfig{
+			"regime": {Type: "int"},
+		}},
+		ExchangeStrategies: []ExchangeStrategyMount{
+			{Mounts: []string{"binance"}, Strategy: &validationTestStrategy{Symbol: "BTCUSDT", NumLayers: 5, Band: &validationTestBand{Window: 20}}},
+			{Mounts: []string{"max"}, Strategy: &validationTestStrategy{Band: &validationTestBand{}}},
+			{Mounts: []string{"binance"}, Strategy: &validationTestStrategy{Symbol: "ETHUSDT", NumLayers: 20, Band: &validationTestBand{Window: 20}}},
+		},
+	}
+
+	err := ValidateConfig(config)
+	var errs ConfigErrors
+	if assert.True(t, errors.As(err, &errs)) {
+		var paths []string
+		for _, e := range errs {
+			paths = append(paths, e.Path)
+		}
+
+		assert.Equal(t, []string{
+			"messageBus",
+			"exchangeStrategies[1].on",
+			"exchangeStrategies[1].validationtest.symbol",
+			"exchangeStrategies[1].validationtest.numLayers",
+			"exchangeStrategies[1].validationtest.band.window",
+			"exchangeStrategies[2].validationtest",
+		}, paths)
+	}
+
+	config.MessageBus = nil
+	config.ExchangeStrategies = config.ExchangeStrategies[:1]
+	assert.NoError(t, ValidateConfig(config))
+}
@@ -0,0 +1,371 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultCoordinatorLeaseTTL = 30 * time.Second
+
+// CoordinatorConfig enables the multi-process deployment, the same config can be run by several processes or hosts.
+// Each strategy instance is guarded by a lease in redis, only the process holding the lease runs the instance,
+// and the other processes stand by and take over the instance with its persisted state when the lease expires.
+// The persistence should be shared by the processes (redis or postgres), otherwise the state is not taken over.
+//
+//	coordinator:
+//	  node: host-a
+//	  leaseTTL: 30s
+//	  renewInterval: 10s
+type CoordinatorConfig struct {
+	// Node is the owner name of the leases, defaults to {hostname}-{pid}.
+	// With the default node, a restarted process is a new owner and waits for the leases of the previous process to expire,
+	// set a fixed node to resume the instances right after a restart, but it must be unique among the processes.
+	Node string `json:"node,omitempty" yaml:"node,omitempty"`
+
+	// Redis is the redis of the leases, defaults to the redis of the persistence
+	Redis *service.RedisPersistenceConfig `json:"redis,omitempty" yaml:"redis,omitempty"`
+
+	// LeaseTTL is the expiration of the lease, a standby process takes over the instance after the lease expires, defaults to 30 seconds
+	LeaseTTL types.Duration `json:"leaseTTL,omitempty" yaml:"leaseTTL,omitempty"`
+
+	// RenewInterval is the interval of renewing the held leases and acquiring the standby leases, defaults to 1/3 of the lease ttl
+	RenewInterval types.Duration `json:"renewInterval,omitempty" yaml:"renewInterval,omitempty"`
+}
+
+func (c *CoordinatorConfig) Validate() error {
+	if c.LeaseTTL < 0 || c.RenewInterval < 0 {
+		return errors.New("coordinator: leaseTTL and renewInterval can not be negative")
+	}
+
+	if c.RenewInterval > 0 && c.RenewInterval.Duration() >= c.leaseTTL() {
+		return fmt.Errorf("coordinator: renewInterval %s should be less than leaseTTL %s", c.RenewInterval.Duration(), c.leaseTTL())
+	}
+
+	return nil
+}
+
+func (c *CoordinatorConfig) leaseTTL() time.Duration {
+	if c.LeaseTTL > 0 {
+		return c.LeaseTTL.Duration()
+	}
+
+	return defaultCoordinatorLeaseTTL
+}
+
+func (c *CoordinatorConfig) renewInterval() time.Duration {
+	if c.RenewInterval > 0 {
+		return c.RenewInterval.Duration()
+	}
+
+	return c.leaseTTL() / 3
+}
+
+// defaultCoordinatorNode includes the pid, so that the processes on the same host do not share the leases
+func defaultCoordinatorNode() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "bbgo"
+	}
+
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+// Coordinator holds the leases of the strategy instances run by this process,
+// and takes over the standby instances when their leases are released or expired.
+type Coordinator struct {
+	node          string
+	leases        service.LeaseService
+	ttl           time.Duration
+	renewInterval time.Duration
+
+	mu sync.Mutex
+
+	// held maps the instance ID to the last renewal time of the lease
+	held map[string]time.Time
+
+	// standby are the single exchange strategy instances running in the other processes
+	standby map[string]*StrategyInstance
+
+	logger logrus.FieldLogger
+}
+
+func NewCoordinator(config *CoordinatorConfig, leases service.LeaseService) *Coordinator {
+	node := config.Node
+	if node == "" {
+		node = defaultCoordinatorNode()
+	}
+
+	return &Coordinator{
+		node:          node,
+		leases:        leases,
+		ttl:           config.leaseTTL(),
+		renewInterval: config.renewInterval(),
+		held:          make(map[string]time.Time),
+		standby:       make(map[string]*StrategyInstance),
+		logger:        logrus.WithFields(logrus.Fields{"component": "coordinator", "node": node}),
+	}
+}
+
+// Node returns the owner name of the leases
+func (c *Coordinator) Node() string {
+	return c.node
+}
+
+// Held returns the sorted instance IDs of the held leases
+func (c *Coordinator) Held() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ids := make([]string, 0, len(c.held))
+	for id := range c.held {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+	return ids
+}
+
+// Standby returns the sorted instance IDs of the standby instances
+func (c *Coordinator) Standby() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	ids := make([]string, 0, len(c.standby))
+	for id := range c.standby {
+		ids = append(ids, id)
+	}
+
+	sort.Strings(ids)
+	return ids
+}
+
+// Run renews the held leases and tries to take over the standby instances until the context is done
+func (c *Coordinator) Run(ctx context.Context, trader *Trader) {
+	ticker := time.NewTicker(c.renewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			c.renew(ctx, trader)
+			c.takeOver(ctx, trader)
+		}
+	}
+}
+
+// renew extends the held leases, the instance is stopped without storing its state if the lease is lost,
+// because the other process may have taken over the instance.
+// The lease is treated as lost if it can not be renewed, e.g., redis is unreachable,
+// and the lease may expire before the next renewal, the renew interval is kept as the safety margin.
+func (c *Coordinator) renew(ctx context.Context, trader *Trader) {
+	for _, id := range c.Held() {
+		// the lease is extended from the time the renewal is sent, not the time it's acknowledged
+		started := time.Now()
+		ok, err := c.leases.Renew(ctx, id, c.node, c.ttl)
+		if err != nil {
+			c.mu.Lock()
+			lastRenewed := c.held[id]
+			c.mu.Unlock()
+
+			c.logger.WithError(err).Warnf("can not renew the lease of %s", id)
+			if time.Now().Sub(lastRenewed) < c.ttl-c.renewInterval {
+				continue
+			}
+		} else if ok {
+			c.mu.Lock()
+			c.held[id] = started
+			c.mu.Unlock()
+			continue
+		}
+
+		c.mu.Lock()
+		delete(c.held, id)
+		c.mu.Unlock()
+
+		c.logger.Warnf("lost the lease of %s, stopping the strategy instance", id)
+		if err := trader.stopStrategyInstance(ctx, id, false); err != nil {
+			c.logger.WithError(err).Errorf("can not stop %s", id)
+		}
+
+		Notify("%s lost the lease of strategy instance %s, the instance is stopped", c.node, id)
+	}
+}
+
+// takeOver acquires the leases of the standby instances and starts them with the persisted states
+func (c *Coordinator) takeOver(ctx context.Context, trader *Trader) {
+	c.mu.Lock()
+	standby := make([]*StrategyInstance, 0, len(c.standby))
+	for _, instance := range c.standby {
+		standby = append(standby, instance)
+	}
+	c.mu.Unlock()
+
+	for _, instance := range standby {
+		started := time.Now()
+		ok, err := c.leases.Acquire(ctx, instance.ID, c.node, c.ttl)
+		if err != nil {
+			c.logger.WithError(err).Warnf("can not acquire the lease of %s", instance.ID)
+			continue
+		} else if !ok {
+			continue
+		}
+
+		c.mu.Lock()
+		delete(c.standby, instance.ID)
+		c.mu.Unlock()
+
+		if err := trader.takeOverStrategyInstance(ctx, instance); err != nil {
+			c.logger.WithError(err).Errorf("can not take over %s", instance.ID)
+			if err := c.leases.Release(ctx, instance.ID, c.node); err != nil {
+				c.logger.WithError(err).Errorf("can not release the lease of %s", instance.ID)
+			}
+
+			Notify("%s failed to take over strategy instance %s: %v", c.node, instance.ID, err)
+			continue
+		}
+
+		c.mu.Lock()
+		c.held[instance.ID] = started
+		c.mu.Unlock()
+
+		c.logger.Infof("took over strategy instance %s", instance.ID)
+		Notify("%s took over strategy instance %s", c.node, instance.ID)
+	}
+}
+
+// LeaseOwner returns the process holding the lease of the strategy instance, it's empty if the instance is not running
+func (c *Coordinator) LeaseOwner(ctx context.Context, instanceID string) (string, error) {
+	return c.leases.Owner(ctx, instanceID)
+}
+
+// ReleaseAll releases the held leases, so that the standby processes take over the instances without waiting for the expiration.
+// It's called after the strategy states are stored in the shutdown.
+func (c *Coordinator) ReleaseAll(ctx context.Context) error {
+	var errs []error
+	for _, id := range c.Held() {
+		if err := c.leases.Release(ctx, id, c.node); err != nil {
+			errs = append(errs, errors.Wrapf(err, "can not release the lease of %s", id))
+			continue
+		}
+
+		c.mu.Lock()
+		delete(c.held, id)
+		c.mu.Unlock()
+	}
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+// SetCoordinator enables the leases of the strategy instances
+func (trader *Trader) SetCoordinator(coordinator *Coordinator) {
+	trader.coordinator = coordinator
+}
+
+// Coordinator returns nil if the multi-process deployment is not configured
+func (trader *Trader) Coordinator() *Coordinator {
+	return trader.coordinator
+}
+
+// applyCoordinator acquires the leases of the configured strategy instances before they are started.
+// The single exchange strategy instances held by the other processes are detached and stand by,
+// the cross exchange strategy instances held by the other processes are skipped, they are not taken over at runtime.
+func (trader *Trader) applyCoordinator(ctx context.Context) error {
+	c := trader.coordinator
+	if c == nil || trader.environment.BacktestService != nil || IsBackTesting {
+		return nil
+	}
+
+	instances, err := trader.StrategyInstances()
+	if err != nil {
+		return err
+	}
+
+	for _, instance := range instances {
+		started := time.Now()
+		ok, err := c.leases.Acquire(ctx, instance.ID, c.node, c.ttl)
+		if err != nil {
+			return errors.Wrapf(err, "can not acquire the lease of %s", instance.ID)
+		}
+
+		if ok {
+			c.mu.Lock()
+			c.held[instance.ID] = started
+			c.mu.Unlock()
+			continue
+		}
+
+		owner, err := c.leases.Owner(ctx, instance.ID)
+		if err != nil {
+			return errors.Wrapf(err, "can not query the lease owner of %s", instance.ID)
+		}
+
+		if instance.Session == "" {
+			c.logger.Warnf("cross exchange strategy instance %s is running on %s, skipping", instance.ID, owner)
+			trader.childStrategiesMutex.Lock()
+			trader.detachCrossExchangeStrategy(instance.Strategy)
+			trader.childStrategiesMutex.Unlock()
+			continue
+		}
+
+		c.logger.Infof("strategy instance %s is running on %s, standing by", instance.ID, owner)
+		trader.childStrategiesMutex.Lock()
+		trader.detachStrategy(instance.Session, instance.Strategy)
+		trader.childStrategiesMutex.Unlock()
+
+		c.mu.Lock()
+		c.standby[instance.ID] = instance
+		c.mu.Unlock()
+	}
+
+	// the leases are released after the states are stored
+	OnShutdownPhase(ctx, ShutdownPhaseDisconnect, "coordinator leases", c.ReleaseAll)
+
+	go c.Run(ctx, trader)
+	return nil
+}
+
+// takeOverStrategyInstance starts the standby strategy instance, the persisted state stored by the previous owner is loaded
+func (trader *Trader) takeOverStrategyInstance(ctx context.Context, instance *StrategyInstance) error {
+	strategy, ok := instance.Strategy.(SingleExchangeStrategy)
+	if !ok {
+		return fmt.Errorf("strategy %s is not a single exchange strategy", instance.ID)
+	}
+
+	session, orderExecutor := trader.strategySession(instance.Session, strategy)
+	if session == nil {
+		return fmt.Errorf("session %s is not defined", instance.Session)
+	}
+
+	runCtx := ctx
+	if trader.runCtx != nil {
+		runCtx = trader.runCtx
+	}
+
+	return trader.startStrategyInstance(runCtx, instance, session, orderExecutor)
+}
+
+func (trader *Trader) detachCrossExchangeStrategy(strategy StrategyID) {
+	for i, s := range trader.crossExchangeStrategies {
+		if s == strategy {
+			trader.crossExchangeStrategies = append(trader.crossExchangeStrategies[:i:i], trader.crossExchangeStrategies[i+1:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func TestCoordinatorConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&CoordinatorConfig{}).Validate())
+	assert.NoError(t, (&CoordinatorConfig{LeaseTTL: types.Duration(time.Minute), RenewInterval: types.Duration(10 * time.Second)}).Validate())
+	assert.Error(t, (&CoordinatorConfig{RenewInterval: types.Duration(time.Minute)}).Validate(), "renewInterval exceeds the default ttl")
+
+	config := &CoordinatorConfig{LeaseTTL: types.Duration(time.Minute)}
+	assert.Equal(t, 20*time.Second, config.renewInterval())
+}
+
+func newTestCoordinatorTrader(t *testing.T, mockEx types.Exchange, coordinator *Coordinator) (*Trader, *testLifecycleStrategy) {
+	environ := NewEnvironment()
+	environ.AddExchangeSession("binance", NewExchangeSession("binance", mockEx))
+
+	strategy := &testLifecycleStrategy{Market: "ETHUSDT", Counter: 1}
+	trader := NewTrader(environ)
+	trader.SetCoordinator(coordinator)
+	assert.NoError(t, trader.AttachStrategyOn("binance", strategy))
+	return trader, strategy
+}
+
+func TestCoordinator_TakeOver(t *testing.T) {
+	RegisterStrategy("lifecycle", &testLifecycleStrategy{})
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).AnyTimes()
+
+	leases := service.NewMemoryLeaseService()
+	ttl := types.Duration(20 * time.Millisecond)
+	coordinatorA := NewCoordinator(&CoordinatorConfig{Node: "node-a", LeaseTTL: ttl}, leases)
+	coordinatorB := NewCoordinator(&CoordinatorConfig{Node: "node-b", LeaseTTL: ttl}, leases)
+
+	traderA, strategyA := newTestCoordinatorTrader(t, mockEx, coordinatorA)
+	traderB, strategyB := newTestCoordinatorTrader(t, mockEx, coordinatorB)
+
+	// the renew loops are not started with the canceled context, they are driven by the test
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	const id = "binance.lifecycle:ETHUSDT"
+	if !assert.NoError(t, traderA.applyCoordinator(canceledCtx)) || !assert.NoError(t, traderB.applyCoordinator(canceledCtx)) {
+		return
+	}
+
+	assert.Equal(t, []string{id}, coordinatorA.Held())
+	assert.Equal(t, []string{id}, coordinatorB.Standby())
+
+	instances, err := traderB.StrategyInstances()
+	if assert.NoError(t, err) {
+		assert.Empty(t, instances, "the standby instance is detached")
+	}
+
+	// the state stored by node-a before it stops renewing the lease
+	ctx := context.Background()
+	store := GetIsolationFromContext(ctx).persistenceServiceFacade.Get().NewStore("state", "lifecycle:ETHUSDT", "counter")
+	assert.NoError(t, store.Save(int64(5)))
+
+	coordinatorB.takeOver(ctx, traderB)
+	assert.Equal(t, []string{id}, coordinatorA.Held(), "the lease of node-a is not expired")
+	assert.Equal(t, int64(1), strategyB.Counter)
+
+	time.Sleep(50 * time.Millisecond)
+	coordinatorB.takeOver(ctx, traderB)
+	assert.Equal(t, []string{id}, coordinatorB.Held())
+	assert.Empty(t, coordinatorB.Standby())
+
+	instances, err = traderB.StrategyInstances()
+	if assert.NoError(t, err) && assert.Len(t, instances, 1) {
+		assert.Equal(t, strategyB, instances[0].Strategy)
+	}
+
+	// the taken over instance loads the state and runs
+	assert.Equal(t, int64(6), strategyB.Counter)
+
+	// node-a finds the lease lost, the instance is stopped without storing the stale state
+	coordinatorA.renew(ctx, traderA)
+	assert.Empty(t, coordinatorA.Held())
+	assert.True(t, strategyA.stopped)
+
+	var counter int64
+	assert.NoError(t, store.Load(&counter))
+	assert.Equal(t, int64(5), counter)
+
+	assert.NoError(t, coordinatorB.ReleaseAll(ctx))
+	owner, err := leases.Owner(ctx, id)
+	assert.NoError(t, err)
+	assert.Empty(t, owner)
+}
+
+// unreachableLeaseService fails all the renewals, e.g., redis is unreachable
+type unreachableLeaseService struct {
+	*service.MemoryLeaseService
+}
+
+func (s *unreachableLeaseService) Renew(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	return false, errors.New("connection refused")
+}
+
+func TestCoordinator_RenewUnreachable(t *testing.T) {
+	RegisterStrategy("lifecycle", &testLifecycleStrategy{})
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).AnyTimes()
+
+	leases := &unreachableLeaseService{MemoryLeaseService: service.NewMemoryLeaseService()}
+	coordinator := NewCoordinator(&CoordinatorConfig{Node: "node-a", LeaseTTL: types.Duration(30 * time.Second)}, leases)
+	trader, strategy := newTestCoordinatorTrader(t, mockEx, coordinator)
+
+	canceledCtx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if !assert.NoError(t, trader.applyCoordinator(canceledCtx)) {
+		return
+	}
+
+	const id = "binance.lifecycle:ETHUSDT"
+	ctx := context.Background()
+
+	// the lease can still be renewed in the next interval
+	coordinator.renew(ctx, trader)
+	assert.Equal(t, []string{id}, coordinator.Held())
+	assert.False(t, strategy.stopped)
+
+	// the lease may expire before the next interval
+	coordinator.mu.Lock()
+	coordinator.held[id] = time.Now().Add(-20 * time.Second)
+	coordinator.mu.Unlock()
+
+	coordinator.renew(ctx, trader)
+	assert.Empty(t, coordinator.Held())
+	assert.True(t, strategy.stopped)
+}
@@ -0,0 +1,130 @@
+package bbgo
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/secrets"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// SecretsConfig loads the session credentials from the secret provider,
+// the sessions that have the secretName field use the credentials of the provider instead of the key and secret fields.
+type SecretsConfig struct {
+	secrets.Config `yaml:",inline"`
+
+	// RotationInterval is the interval to poll the provider for the rotated credentials, the rotation is disabled if it's zero
+	RotationInterval types.Duration `json:"rotationInterval,omitempty" yaml:"rotationInterval,omitempty"`
+}
+
+// CredentialRotator polls the secret provider and applies the rotated credentials to the exchanges of the sessions.
+// The user data streams are reconnected with the new credentials, the strategies keep running.
+type CredentialRotator struct {
+	environ  *Environment
+	provider secrets.Provider
+	interval time.Duration
+
+	// failed keeps the sessions that failed to load the credentials, to notify the failure only once
+	failed map[string]bool
+
+	logger logrus.FieldLogger
+}
+
+func NewCredentialRotator(environ *Environment, config *SecretsConfig) *CredentialRotator {
+	return &CredentialRotator{
+		environ:  environ,
+		provider: environ.SecretProvider(),
+		interval: config.RotationInterval.Duration(),
+		failed:   make(map[string]bool),
+		logger:   logrus.WithField("component", "credentialRotator"),
+	}
+}
+
+func (r *CredentialRotator) Run(ctx context.Context) {
+	if r.provider == nil || r.interval == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			r.Rotate(ctx)
+		}
+	}
+}
+
+// Rotate loads the credentials of the sessions and applies the changed ones
+func (r *CredentialRotator) Rotate(ctx context.Context) {
+	sessions := r.environ.Sessions()
+
+	var names []string
+	for name, session := range sessions {
+		if session.SecretName != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		session := sessions[name]
+
+		credentials, err := r.provider.Credentials(ctx, session.SecretName)
+		if err == nil {
+			err = credentials.Validate()
+		}
+
+		if err != nil {
+			r.logger.WithError(err).Errorf("can not load the credentials of session %s", name)
+			if !r.failed[name] {
+				Notify("⚠️ can not load the credentials of session %s: %v", name, err)
+			}
+			r.failed[name] = true
+			continue
+		}
+
+		r.failed[name] = false
+
+		if credentials.Key == session.Key && credentials.Secret == session.Secret && credentials.Passphrase == session.Passphrase {
+			continue
+		}
+
+		r.apply(session, credentials)
+	}
+}
+
+func (r *CredentialRotator) apply(session *ExchangeSession, credentials *secrets.Credentials) {
+	updater, ok := session.Exchange.(types.CredentialUpdater)
+	if !ok {
+		r.logger.Warnf("exchange %T of session %s does not support the credential rotation, restart is required", session.Exchange, session.Name)
+		return
+	}
+
+	session.Key = credentials.Key
+	session.Secret = credentials.Secret
+	session.Passphrase = credentials.Passphrase
+
+	updater.SetCredentials(credentials.Key, credentials.Secret, credentials.Passphrase)
+
+	if session.UserDataStream != nil {
+		if streamUpdater, ok := session.UserDataStream.(types.CredentialUpdater); ok {
+			streamUpdater.SetCredentials(credentials.Key, credentials.Secret, credentials.Passphrase)
+		}
+
+		// re-authenticate the user data stream, the market data stream is public
+		if reconnector, ok := session.UserDataStream.(interface{ Reconnect() }); ok {
+			reconnector.Reconnect()
+		}
+	}
+
+	r.logger.Infof("api credentials of session %s are rotated", session.Name)
+	Notify("🔑 api credentials of session %s are rotated", session.Name)
+}
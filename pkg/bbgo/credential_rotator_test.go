@@ -0,0 +1,100 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/secrets"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+type testSecretProvider map[string]secrets.Credentials
+
+func (p testSecretProvider) Credentials(ctx context.Context, name string) (*secrets.Credentials, error) {
+	credentials, ok := p[name]
+	if !ok {
+		return nil, secrets.ErrSecretNotFound
+	}
+
+	return &credentials, nil
+}
+
+type testCredentialUpdaterExchange struct {
+	types.Exchange
+
+	key, secret, passphrase string
+}
+
+func (e *testCredentialUpdaterExchange) SetCredentials(key, secret, passphrase string) {
+	e.key = key
+	e.secret = secret
+	e.passphrase = passphrase
+}
+
+func TestCredentialRotator_Rotate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	exchange := &testCredentialUpdaterExchange{Exchange: mockEx, key: "key1", secret: "secret1"}
+	session := NewExchangeSession("binance", exchange)
+	session.SecretName = "binance-main"
+	session.Key = "key1"
+	session.Secret = "secret1"
+
+	provider := testSecretProvider{
+		"binance-main": {Key: "key1", Secret: "secret1"},
+	}
+
+	environ := NewEnvironment()
+	environ.AddExchangeSession("binance", session)
+	environ.secretProvider = provider
+
+	rotator := NewCredentialRotator(environ, &SecretsConfig{})
+	ctx := context.Background()
+
+	// the credentials are not changed
+	rotator.Rotate(ctx)
+	assert.Equal(t, "key1", exchange.key)
+
+	provider["binance-main"] = secrets.Credentials{Key: "key2", Secret: "secret2"}
+	rotator.Rotate(ctx)
+	assert.Equal(t, "key2", exchange.key)
+	assert.Equal(t, "secret2", exchange.secret)
+	assert.Equal(t, "key2", session.Key)
+	assert.Equal(t, "secret2", session.Secret)
+
+	// the current credentials are kept when the provider fails
+	delete(provider, "binance-main")
+	rotator.Rotate(ctx)
+	assert.True(t, rotator.failed["binance"])
+	assert.Equal(t, "key2", exchange.key)
+}
+
+func TestEnvironment_loadSessionCredentials(t *testing.T) {
+	environ := NewEnvironment()
+	environ.secretProvider = testSecretProvider{
+		"okex-main": {Key: "key1", Secret: "secret1", Passphrase: "pass1"},
+	}
+
+	sessions := map[string]*ExchangeSession{
+		"okex":    {ExchangeName: types.ExchangeOKEx, SecretName: "okex-main"},
+		"binance": {ExchangeName: types.ExchangeBinance, Key: "key", Secret: "secret"},
+	}
+
+	err := environ.loadSessionCredentials(context.Background(), sessions)
+	assert.NoError(t, err)
+	assert.Equal(t, "key1", sessions["okex"].Key)
+	assert.Equal(t, "pass1", sessions["okex"].Passphrase)
+	assert.Equal(t, "key", sessions["binance"].Key)
+
+	sessions["max"] = &ExchangeSession{ExchangeName: types.ExchangeMax, SecretName: "max-main"}
+	err = environ.loadSessionCredentials(context.Background(), sessions)
+	assert.Error(t, err)
+}
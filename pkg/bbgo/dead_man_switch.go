@@ -0,0 +1,198 @@
+package bbgo
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultDeadManSwitchTimeout = time.Minute
+
+// DeadManSwitchConfig arms the exchange-native cancel-on-disconnect timers of the sessions,
+// so that the open orders are cancelled by the exchange when the process dies or loses the connectivity.
+type DeadManSwitchConfig struct {
+	// Timeout is the countdown of the cancel-all timer, defaults to 1 minute
+	Timeout types.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// RefreshInterval is the interval to refresh the timer, defaults to 1/3 of the timeout
+	RefreshInterval types.Duration `json:"refreshInterval,omitempty" yaml:"refreshInterval,omitempty"`
+
+	// Sessions are the sessions to arm the timers, defaults to all the sessions that support cancel-on-disconnect
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+
+	// Symbols are used by the exchanges that arm the timer per symbol, defaults to the symbols used by the session
+	Symbols []string `json:"symbols,omitempty" yaml:"symbols,omitempty"`
+}
+
+// DeadManSwitch refreshes the cancel-on-disconnect timers periodically.
+// The timer of the session is not refreshed while its user data stream is disconnected,
+// so the exchange pulls the orders that can not be maintained.
+type DeadManSwitch struct {
+	environ         *Environment
+	timeout         time.Duration
+	refreshInterval time.Duration
+	symbols         []string
+	sessions        []*ExchangeSession
+
+	mu           sync.Mutex
+	disconnected map[string]bool
+	failed       map[string]bool
+
+	logger logrus.FieldLogger
+}
+
+func NewDeadManSwitch(environ *Environment, config *DeadManSwitchConfig) *DeadManSwitch {
+	timeout := config.Timeout.Duration()
+	if timeout == 0 {
+		timeout = defaultDeadManSwitchTimeout
+	}
+
+	refreshInterval := config.RefreshInterval.Duration()
+	if refreshInterval == 0 {
+		refreshInterval = timeout / 3
+	}
+
+	s := &DeadManSwitch{
+		environ:         environ,
+		timeout:         timeout,
+		refreshInterval: refreshInterval,
+		symbols:         config.Symbols,
+		disconnected:    make(map[string]bool),
+		failed:          make(map[string]bool),
+		logger:          logrus.WithField("component", "deadManSwitch"),
+	}
+
+	if refreshInterval >= timeout {
+		s.logger.Warnf("the refresh interval %s is not shorter than the timeout %s, the orders may be cancelled while the process is alive", refreshInterval, timeout)
+	}
+
+	sessionNames := config.Sessions
+	if len(sessionNames) == 0 {
+		for name := range environ.Sessions() {
+			sessionNames = append(sessionNames, name)
+		}
+		sort.Strings(sessionNames)
+	}
+
+	for _, name := range sessionNames {
+		session, ok := environ.Session(name)
+		if !ok {
+			s.logger.Warnf("session %s is not found", name)
+			continue
+		}
+
+		if _, ok := session.Exchange.(types.ExchangeCancelOnDisconnectService); !ok || session.PublicOnly {
+			if len(config.Sessions) > 0 {
+				s.logger.Warnf("session %s does not support cancel-on-disconnect", name)
+			}
+			continue
+		}
+
+		s.sessions = append(s.sessions, session)
+		s.bindStream(session)
+	}
+
+	return s
+}
+
+func (s *DeadManSwitch) bindStream(session *ExchangeSession) {
+	session.UserDataStream.OnDisconnect(func() {
+		s.mu.Lock()
+		s.disconnected[session.Name] = true
+		s.mu.Unlock()
+	})
+	session.UserDataStream.OnConnect(func() {
+		s.mu.Lock()
+		s.disconnected[session.Name] = false
+		s.mu.Unlock()
+	})
+}
+
+// Run arms the timers and refreshes them until the context is done, the timers are disarmed when it returns
+func (s *DeadManSwitch) Run(ctx context.Context) {
+	if len(s.sessions) == 0 {
+		s.logger.Warn("no session supports cancel-on-disconnect, the dead-man switch is not armed")
+		return
+	}
+
+	s.Refresh(ctx)
+
+	ticker := time.NewTicker(s.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			disarmCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			s.Disarm(disarmCtx)
+			cancel()
+			return
+
+		case <-ticker.C:
+			s.Refresh(ctx)
+		}
+	}
+}
+
+// Refresh re-arms the timers of the connected sessions
+func (s *DeadManSwitch) Refresh(ctx context.Context) {
+	for _, session := range s.sessions {
+		s.mu.Lock()
+		disconnected := s.disconnected[session.Name]
+		s.mu.Unlock()
+
+		if disconnected {
+			s.logger.Warnf("user data stream of session %s is disconnected, skip refreshing the dead-man switch", session.Name)
+			continue
+		}
+
+		err := s.cancelAllAfter(ctx, session, s.timeout)
+
+		s.mu.Lock()
+		failed := s.failed[session.Name]
+		s.failed[session.Name] = err != nil
+		s.mu.Unlock()
+
+		if err != nil {
+			s.logger.WithError(err).Errorf("can not refresh the dead-man switch of session %s", session.Name)
+			if !failed {
+				Notify("⚠️ can not refresh the dead-man switch of session %s, the open orders will be cancelled in %s: %v", session.Name, s.timeout, err)
+			}
+		} else if failed {
+			Notify("dead-man switch of session %s is re-armed", session.Name)
+		}
+	}
+}
+
+// Disarm cancels the timers of the sessions
+func (s *DeadManSwitch) Disarm(ctx context.Context) {
+	for _, session := range s.sessions {
+		if err := s.cancelAllAfter(ctx, session, 0); err != nil {
+			s.logger.WithError(err).Errorf("can not disarm the dead-man switch of session %s", session.Name)
+		}
+	}
+}
+
+func (s *DeadManSwitch) cancelAllAfter(ctx context.Context, session *ExchangeSession, timeout time.Duration) error {
+	service := session.Exchange.(types.ExchangeCancelOnDisconnectService)
+	return service.CancelAllAfter(ctx, timeout, s.sessionSymbols(session)...)
+}
+
+func (s *DeadManSwitch) sessionSymbols(session *ExchangeSession) []string {
+	if len(s.symbols) > 0 {
+		return s.symbols
+	}
+
+	var symbols []string
+	for symbol := range session.usedSymbols {
+		symbols = append(symbols, symbol)
+	}
+
+	sort.Strings(symbols)
+	return symbols
+}
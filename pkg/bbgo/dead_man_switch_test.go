@@ -0,0 +1,85 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+type cancelAllAfterCall struct {
+	timeout time.Duration
+	symbols []string
+}
+
+type testCancelOnDisconnectExchange struct {
+	types.Exchange
+
+	calls []cancelAllAfterCall
+	err   error
+}
+
+func (e *testCancelOnDisconnectExchange) CancelAllAfter(ctx context.Context, timeout time.Duration, symbols ...string) error {
+	e.calls = append(e.calls, cancelAllAfterCall{timeout: timeout, symbols: symbols})
+	return e.err
+}
+
+func TestDeadManSwitch(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(4)
+
+	exchange := &testCancelOnDisconnectExchange{Exchange: mockEx}
+	session := NewExchangeSession("binance", exchange)
+	session.usedSymbols["ETHUSDT"] = struct{}{}
+	session.usedSymbols["BTCUSDT"] = struct{}{}
+
+	// the exchange of this session does not support cancel-on-disconnect
+	unsupported := NewExchangeSession("max", mockEx)
+
+	environ := NewEnvironment()
+	environ.AddExchangeSession("binance", session)
+	environ.AddExchangeSession("max", unsupported)
+
+	s := NewDeadManSwitch(environ, &DeadManSwitchConfig{})
+	if !assert.Len(t, s.sessions, 1) {
+		return
+	}
+	assert.Equal(t, 20*time.Second, s.refreshInterval)
+
+	ctx := context.Background()
+	s.Refresh(ctx)
+	if assert.Len(t, exchange.calls, 1) {
+		assert.Equal(t, time.Minute, exchange.calls[0].timeout)
+		assert.Equal(t, []string{"BTCUSDT", "ETHUSDT"}, exchange.calls[0].symbols)
+	}
+
+	// the timer is not refreshed while the user data stream is disconnected
+	stream := session.UserDataStream.(*types.StandardStream)
+	stream.EmitDisconnect()
+	s.Refresh(ctx)
+	assert.Len(t, exchange.calls, 1)
+
+	stream.EmitConnect()
+	exchange.err = errors.New("network error")
+	s.Refresh(ctx)
+	assert.Len(t, exchange.calls, 2)
+	assert.True(t, s.failed["binance"])
+
+	exchange.err = nil
+	s.Refresh(ctx)
+	assert.False(t, s.failed["binance"])
+
+	s.Disarm(ctx)
+	if assert.Len(t, exchange.calls, 4) {
+		assert.Equal(t, time.Duration(0), exchange.calls[3].timeout)
+	}
+}
@@ -0,0 +1,272 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultDustSweepInterval = 6 * time.Hour
+
+// DustSweeperConfig converts the small balances accumulated from the maker fills on a schedule
+type DustSweeperConfig struct {
+	// Interval is the sweep interval, defaults to 6 hours, binance allows one dust conversion every 6 hours
+	Interval types.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	// QuoteCurrency is the currency used for valuing the balances, defaults to USDT
+	QuoteCurrency string `json:"quoteCurrency,omitempty" yaml:"quoteCurrency,omitempty"`
+
+	// Threshold is the max value of a balance in the quote currency to be considered as dust
+	Threshold fixedpoint.Value `json:"threshold" yaml:"threshold"`
+
+	// Currencies limits the currencies to sweep, all currencies are swept if it's empty
+	Currencies []string `json:"currencies,omitempty" yaml:"currencies,omitempty"`
+
+	// ExcludeCurrencies are the currencies never swept
+	ExcludeCurrencies []string `json:"excludeCurrencies,omitempty" yaml:"excludeCurrencies,omitempty"`
+}
+
+// Dust is a balance valued below the threshold
+type Dust struct {
+	Currency string
+	Market   types.Market
+	Quantity fixedpoint.Value
+	Price    fixedpoint.Value
+}
+
+// DustSweeper detects the balances valued below the threshold and converts them.
+// The exchange dust conversion api is used if the exchange supports it,
+// otherwise the dust balances that are still above the market minimums are sold with market orders.
+type DustSweeper struct {
+	session *ExchangeSession
+	config  *DustSweeperConfig
+
+	mu          sync.Mutex
+	profitStats []*types.ProfitStats
+
+	logger logrus.FieldLogger
+
+	sweepCallbacks []func(conversion types.DustConversion)
+}
+
+func NewDustSweeper(session *ExchangeSession, config *DustSweeperConfig) *DustSweeper {
+	return &DustSweeper{
+		session: session,
+		config:  config,
+		logger:  logrus.WithFields(logrus.Fields{"session": session.Name, "component": "dustSweeper"}),
+	}
+}
+
+// BindProfitStats credits the proceeds of the swept balances of the base currency in the profit stats,
+// the proceeds of a currency are only credited in the first bound profit stats of the currency.
+func (s *DustSweeper) BindProfitStats(profitStats *types.ProfitStats) {
+	s.mu.Lock()
+	s.profitStats = append(s.profitStats, profitStats)
+	s.mu.Unlock()
+}
+
+func (s *DustSweeper) creditProfitStats(conversion *types.DustConversion) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, detail := range conversion.Details {
+		for _, profitStats := range s.profitStats {
+			if profitStats.BaseCurrency == detail.FromCurrency {
+				profitStats.AddDustProceeds(conversion.Currency, detail.Amount)
+				break
+			}
+		}
+	}
+}
+
+func (s *DustSweeper) OnSweep(cb func(conversion types.DustConversion)) {
+	s.sweepCallbacks = append(s.sweepCallbacks, cb)
+}
+
+func (s *DustSweeper) EmitSweep(conversion types.DustConversion) {
+	for _, cb := range s.sweepCallbacks {
+		cb(conversion)
+	}
+}
+
+func (s *DustSweeper) quoteCurrency() string {
+	if s.config.QuoteCurrency != "" {
+		return s.config.QuoteCurrency
+	}
+
+	return "USDT"
+}
+
+// Run sweeps the dust balances periodically until the context is done
+func (s *DustSweeper) Run(ctx context.Context) {
+	interval := s.config.Interval.Duration()
+	if interval == 0 {
+		interval = defaultDustSweepInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if _, err := s.Sweep(ctx); err != nil {
+				s.logger.WithError(err).Error("dust sweep error")
+			}
+		}
+	}
+}
+
+// FindDust returns the balances valued below the threshold
+func (s *DustSweeper) FindDust(ctx context.Context) ([]Dust, error) {
+	quoteCurrency := s.quoteCurrency()
+	feeCurrency := s.session.Exchange.PlatformFeeCurrency()
+
+	excluded := map[string]struct{}{quoteCurrency: {}, feeCurrency: {}}
+	for _, currency := range s.config.ExcludeCurrencies {
+		excluded[currency] = struct{}{}
+	}
+
+	included := map[string]struct{}{}
+	for _, currency := range s.config.Currencies {
+		included[currency] = struct{}{}
+	}
+
+	var candidates []Dust
+	var symbols []string
+	for currency, balance := range s.session.GetAccount().Balances() {
+		if _, ok := excluded[currency]; ok {
+			continue
+		}
+
+		if _, ok := included[currency]; len(included) > 0 && !ok {
+			continue
+		}
+
+		if balance.Available.Sign() <= 0 {
+			continue
+		}
+
+		market, ok := s.session.Market(currency + quoteCurrency)
+		if !ok {
+			continue
+		}
+
+		candidates = append(candidates, Dust{Currency: currency, Market: market, Quantity: balance.Available})
+		symbols = append(symbols, market.Symbol)
+	}
+
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	tickers, err := s.session.Exchange.QueryTickers(ctx, symbols...)
+	if err != nil {
+		return nil, err
+	}
+
+	var dusts []Dust
+	for _, dust := range candidates {
+		ticker, ok := tickers[dust.Market.Symbol]
+		if !ok || ticker.Buy.IsZero() {
+			continue
+		}
+
+		dust.Price = ticker.Buy
+		if dust.Quantity.Mul(dust.Price).Compare(s.config.Threshold) < 0 {
+			dusts = append(dusts, dust)
+		}
+	}
+
+	return dusts, nil
+}
+
+// Sweep converts the dust balances and credits the proceeds in the bound profit stats,
+// it returns nil if there is nothing to sweep.
+func (s *DustSweeper) Sweep(ctx context.Context) (*types.DustConversion, error) {
+	dusts, err := s.FindDust(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dusts) == 0 {
+		return nil, nil
+	}
+
+	var conversion *types.DustConversion
+	if service, ok := s.session.Exchange.(types.ExchangeDustConversionService); ok {
+		var assets []string
+		for _, dust := range dusts {
+			assets = append(assets, dust.Currency)
+		}
+
+		conversion, err = service.ConvertDust(ctx, assets)
+	} else {
+		conversion, err = s.sell(ctx, dusts)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if conversion == nil || conversion.Amount.IsZero() {
+		return nil, nil
+	}
+
+	s.creditProfitStats(conversion)
+
+	s.logger.Infof("swept %d dust balances into %s %s", len(conversion.Details), conversion.Amount.String(), conversion.Currency)
+	Notify(fmt.Sprintf("%s: swept %d dust balances into %s %s", s.session.Name, len(conversion.Details), conversion.Amount.String(), conversion.Currency))
+
+	s.EmitSweep(*conversion)
+	return conversion, nil
+}
+
+// sell submits the market orders of the dust balances that are still tradable,
+// the proceeds are estimated by the bid prices.
+func (s *DustSweeper) sell(ctx context.Context, dusts []Dust) (*types.DustConversion, error) {
+	conversion := &types.DustConversion{Currency: s.quoteCurrency()}
+
+	for _, dust := range dusts {
+		quantity := dust.Market.TruncateQuantity(dust.Quantity)
+		if dust.Market.IsDustQuantity(quantity, dust.Price) {
+			s.logger.Debugf("%s %s is below the market minimum, skipping", quantity.String(), dust.Currency)
+			continue
+		}
+
+		createdOrder, err := s.session.Exchange.SubmitOrder(ctx, types.SubmitOrder{
+			Symbol:   dust.Market.Symbol,
+			Market:   dust.Market,
+			Side:     types.SideTypeSell,
+			Type:     types.OrderTypeMarket,
+			Quantity: quantity,
+		})
+		if err != nil {
+			s.logger.WithError(err).Errorf("unable to sell the dust %s %s", quantity.String(), dust.Currency)
+			continue
+		}
+
+		amount := quantity.Mul(dust.Price)
+		fee := amount.Mul(s.session.TakerFeeRate)
+		conversion.Amount = conversion.Amount.Add(amount.Sub(fee))
+		conversion.Fee = conversion.Fee.Add(fee)
+		conversion.Details = append(conversion.Details, types.DustConversionDetail{
+			FromCurrency: dust.Currency,
+			FromAmount:   quantity,
+			Amount:       amount.Sub(fee),
+			Fee:          fee,
+			Time:         createdOrder.CreationTime.Time(),
+		})
+	}
+
+	return conversion, nil
+}
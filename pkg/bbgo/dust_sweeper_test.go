@@ -0,0 +1,84 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func TestDustSweeper_Sweep(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ethMarket := types.Market{
+		Symbol:          "ETHUSDT",
+		BaseCurrency:    "ETH",
+		QuoteCurrency:   "USDT",
+		StepSize:        fixedpoint.NewFromFloat(0.0001),
+		VolumePrecision: 4,
+		MinQuantity:     fixedpoint.NewFromFloat(0.0001),
+		MinNotional:     fixedpoint.NewFromFloat(5.0),
+	}
+
+	btcMarket := getTestMarket()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+	mockEx.EXPECT().PlatformFeeCurrency().Return("BNB").AnyTimes()
+	mockEx.EXPECT().QueryTickers(gomock.Any(), gomock.Any()).Return(map[string]types.Ticker{
+		"ETHUSDT": {Buy: fixedpoint.NewFromFloat(1000.0)},
+		"BTCUSDT": {Buy: fixedpoint.NewFromFloat(20000.0)},
+	}, nil)
+
+	// ETH is valued at 8 USDT, which is below the threshold but still above the market minimum
+	mockEx.EXPECT().SubmitOrder(gomock.Any(), types.SubmitOrder{
+		Symbol:   "ETHUSDT",
+		Market:   ethMarket,
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.NewFromFloat(0.008),
+	}).Return(&types.Order{}, nil)
+
+	session := NewExchangeSession("test", mockEx)
+	session.markets[ethMarket.Symbol] = ethMarket
+	session.markets[btcMarket.Symbol] = btcMarket
+	session.TakerFeeRate = fixedpoint.NewFromFloat(0.001)
+	session.Account = types.NewAccount()
+	session.Account.UpdateBalances(types.BalanceMap{
+		"ETH":  {Currency: "ETH", Available: fixedpoint.NewFromFloat(0.00801)},
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromFloat(0.1)},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromFloat(3.0)},
+	})
+
+	sweeper := NewDustSweeper(session, &DustSweeperConfig{Threshold: fixedpoint.NewFromFloat(10.0)})
+	session.dustSweeper = sweeper
+
+	// the profit stats are bound to the sweeper of the session by the order executors
+	ethProfitStats := types.NewProfitStats(ethMarket)
+	NewGeneralOrderExecutor(session, ethMarket.Symbol, "test", "test:ETHUSDT", types.NewPositionFromMarket(ethMarket)).BindProfitStats(ethProfitStats)
+
+	// the proceeds of a currency are only credited once
+	ethProfitStats2 := types.NewProfitStats(ethMarket)
+	NewGeneralOrderExecutor(session, ethMarket.Symbol, "test", "test2:ETHUSDT", types.NewPositionFromMarket(ethMarket)).BindProfitStats(ethProfitStats2)
+
+	btcProfitStats := types.NewProfitStats(btcMarket)
+	NewGeneralOrderExecutor(session, btcMarket.Symbol, "test", "test:BTCUSDT", types.NewPositionFromMarket(btcMarket)).BindProfitStats(btcProfitStats)
+
+	conversion, err := sweeper.Sweep(context.Background())
+	if assert.NoError(t, err) && assert.NotNil(t, conversion) {
+		assert.Equal(t, "USDT", conversion.Currency)
+		assert.Len(t, conversion.Details, 1)
+
+		// 0.008 * 1000 * (1 - 0.001)
+		assert.Equal(t, "7.992", conversion.Amount.String())
+		assert.Equal(t, "7.992", ethProfitStats.DustProceeds["USDT"].String())
+		assert.Empty(t, ethProfitStats2.DustProceeds)
+		assert.Empty(t, btcProfitStats.DustProceeds)
+	}
+}
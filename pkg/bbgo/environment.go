@@ -25,6 +25,7 @@ import (
 	"github.com/c9s/bbgo/pkg/interact"
 	"github.com/c9s/bbgo/pkg/notifier/slacknotifier"
 	"github.com/c9s/bbgo/pkg/notifier/telegramnotifier"
+	"github.com/c9s/bbgo/pkg/secrets"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/slack/slacklog"
 	"github.com/c9s/bbgo/pkg/types"
@@ -92,6 +93,15 @@ type Environment struct {
 	DepositService    *service.DepositService
 	PersistentService *service.PersistenceServiceFacade
 
+	// EquitySnapshotService stores the mark-to-market snapshots of the strategy positions
+	EquitySnapshotService *service.EquitySnapshotService
+
+	// CommandAuditService stores the audit log of the runtime commands
+	CommandAuditService *service.CommandAuditService
+
+	// TreasuryService reconciles the on-exchange balances with the recorded deposits, withdrawals and trades
+	TreasuryService *service.TreasuryService
+
 	// startTime is the time of start point (which is used in the backtest)
 	startTime time.Time
 
@@ -105,6 +115,34 @@ type Environment struct {
 
 	loggingConfig *LoggingConfig
 
+	// approvalManager holds the destructive remote commands until they are approved
+	approvalManager *ApprovalManager
+
+	// commandAuthorizer checks the roles of the runtime commands and records the audit log
+	commandAuthorizer *CommandAuthorizer
+
+	// profitChartConfig attaches the charts to the profit notifications if it's set
+	profitChartConfig *ProfitChartConfig
+
+	// sessionRecorder records the session events and the strategy decisions when the session recording is enabled
+	sessionRecorder *SessionRecorder
+
+	// secretProvider loads the session credentials when the secrets are configured
+	secretProvider secrets.Provider
+
+	// portfolioRisk calculates the portfolio value at risk when the portfolio risk service is enabled
+	portfolioRisk *PortfolioRiskService
+
+	// healthMonitor reports the health of the streams, the persistence and the strategies
+	healthMonitor *HealthMonitor
+
+	// scheduler runs the cron jobs of the strategies
+	scheduler     *Scheduler
+	schedulerOnce sync.Once
+
+	// clock is the time source of the strategies, it's the simulated clock in the backtest
+	clock Clock
+
 	sessions map[string]*ExchangeSession
 }
 
@@ -121,6 +159,68 @@ func NewEnvironment() *Environment {
 	}
 }
 
+// SetSessionRecorder binds the session recorder to the sessions, it should be called before the streams are connected
+func (environ *Environment) SetSessionRecorder(recorder *SessionRecorder) {
+	environ.sessionRecorder = recorder
+	for _, session := range environ.sessions {
+		recorder.Bind(session)
+	}
+}
+
+func (environ *Environment) SessionRecorder() *SessionRecorder {
+	return environ.sessionRecorder
+}
+
+func (environ *Environment) SetPortfolioRiskService(service *PortfolioRiskService) {
+	environ.portfolioRisk = service
+}
+
+// PortfolioRiskService returns nil if the portfolio risk service is not configured
+func (environ *Environment) PortfolioRiskService() *PortfolioRiskService {
+	return environ.portfolioRisk
+}
+
+// SetHealthMonitor sets the health monitor of the /healthz and /readyz endpoints
+func (environ *Environment) SetHealthMonitor(monitor *HealthMonitor) {
+	environ.healthMonitor = monitor
+}
+
+// HealthMonitor returns nil if the health monitor is not set
+func (environ *Environment) HealthMonitor() *HealthMonitor {
+	return environ.healthMonitor
+}
+
+// SetScheduler sets the cron scheduler, it should be called before the strategies are started
+func (environ *Environment) SetScheduler(scheduler *Scheduler) {
+	environ.scheduler = scheduler
+}
+
+// SetClock sets the time source of the strategies, it should be called before the strategies are started
+func (environ *Environment) SetClock(clock Clock) {
+	environ.clock = clock
+}
+
+// Clock returns the time source of the strategies, it's the system clock if the clock is not set,
+// it's safe to call on the nil environment.
+func (environ *Environment) Clock() Clock {
+	if environ == nil || environ.clock == nil {
+		return SystemClock{}
+	}
+
+	return environ.clock
+}
+
+// Scheduler returns the cron scheduler, the default scheduler runs the cron specs in UTC
+func (environ *Environment) Scheduler() *Scheduler {
+	environ.schedulerOnce.Do(func() {
+		if environ.scheduler == nil {
+			environ.scheduler = NewScheduler(time.UTC)
+		}
+	})
+
+	return environ.scheduler
+}
+
 func (environ *Environment) Session(name string) (*ExchangeSession, bool) {
 	s, ok := environ.sessions[name]
 	return s, ok
@@ -134,6 +234,43 @@ func (environ *Environment) SetLogging(config *LoggingConfig) {
 	environ.loggingConfig = config
 }
 
+// ConfigureRemoteCommandApproval enables the approval workflow of the destructive remote commands
+func (environ *Environment) ConfigureRemoteCommandApproval(config *RemoteCommandApprovalConfig) error {
+	manager, err := NewApprovalManager(config)
+	if err != nil {
+		return err
+	}
+
+	environ.approvalManager = manager
+	return nil
+}
+
+// ApprovalManager returns nil if the remote command approval is not configured
+func (environ *Environment) ApprovalManager() *ApprovalManager {
+	return environ.approvalManager
+}
+
+// ConfigureCommandAuthorization sets up the audit log of the runtime commands,
+// and the role-based authorization if the config is given.
+func (environ *Environment) ConfigureCommandAuthorization(config *CommandAuthorizationConfig) {
+	environ.commandAuthorizer = NewCommandAuthorizer(config, environ.CommandAuditService)
+
+	interact.SetAuditLogger(environ.commandAuthorizer)
+	if config != nil {
+		interact.SetRoleResolver(environ.commandAuthorizer.ResolveRole)
+	}
+}
+
+// ProfitChartConfig returns nil if the profit chart is not enabled
+func (environ *Environment) ProfitChartConfig() *ProfitChartConfig {
+	return environ.profitChartConfig
+}
+
+// CommandAuthorizer returns nil if the command authorization is not configured
+func (environ *Environment) CommandAuthorizer() *CommandAuthorizer {
+	return environ.commandAuthorizer
+}
+
 func (environ *Environment) SelectSessions(names ...string) map[string]*ExchangeSession {
 	if len(names) == 0 {
 		return environ.sessions
@@ -192,6 +329,9 @@ func (environ *Environment) ConfigureDatabaseDriver(ctx context.Context, driver
 	environ.MarginService = &service.MarginService{DB: db}
 	environ.WithdrawService = &service.WithdrawService{DB: db}
 	environ.DepositService = &service.DepositService{DB: db}
+	environ.EquitySnapshotService = &service.EquitySnapshotService{DB: db}
+	environ.CommandAuditService = &service.CommandAuditService{DB: db}
+	environ.TreasuryService = &service.TreasuryService{DB: db}
 	environ.SyncService = &service.SyncService{
 		TradeService:    environ.TradeService,
 		OrderService:    environ.OrderService,
@@ -222,9 +362,54 @@ func (environ *Environment) ConfigureExchangeSessions(userConfig *Config) error
 		return environ.AddExchangesByViperKeys()
 	}
 
+	if userConfig.Secrets != nil {
+		provider, err := secrets.NewProvider(userConfig.Secrets.Config)
+		if err != nil {
+			return err
+		}
+
+		environ.secretProvider = provider
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		if err := environ.loadSessionCredentials(ctx, userConfig.Sessions); err != nil {
+			return err
+		}
+	}
+
 	return environ.AddExchangesFromSessionConfig(userConfig.Sessions)
 }
 
+// loadSessionCredentials loads the credentials of the sessions that have the secret name from the secret provider
+func (environ *Environment) loadSessionCredentials(ctx context.Context, sessions map[string]*ExchangeSession) error {
+	for sessionName, session := range sessions {
+		if session.SecretName == "" {
+			continue
+		}
+
+		credentials, err := environ.secretProvider.Credentials(ctx, session.SecretName)
+		if err != nil {
+			return fmt.Errorf("can not load the credentials of session %s: %w", sessionName, err)
+		}
+
+		if err := credentials.Validate(); err != nil {
+			return fmt.Errorf("invalid credentials %s of session %s: %w", session.SecretName, sessionName, err)
+		}
+
+		session.Key = credentials.Key
+		session.Secret = credentials.Secret
+		session.Passphrase = credentials.Passphrase
+	}
+
+	return nil
+}
+
+// SecretProvider returns nil if the secrets are not configured
+func (environ *Environment) SecretProvider() secrets.Provider {
+	return environ.secretProvider
+}
+
 func (environ *Environment) AddExchangesByViperKeys() error {
 	for _, n := range types.SupportedExchanges {
 		if viper.IsSet(string(n) + "-api-key") {
@@ -251,6 +436,40 @@ func (environ *Environment) AddExchangesFromSessionConfig(sessions map[string]*E
 		}
 
 		environ.AddExchangeSession(sessionName, session)
+
+		if err := environ.addFacetSessions(session, sessions); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addFacetSessions creates and registers the account facet sessions of the given session
+// configuredSessions is used for checking the name conflicts between the facet sessions and the configured sessions.
+func (environ *Environment) addFacetSessions(session *ExchangeSession, configuredSessions map[string]*ExchangeSession) error {
+	for _, accountType := range session.Facets {
+		if _, ok := session.facetSessions[accountType]; ok {
+			return fmt.Errorf("duplicated account facet %s in session %s", accountType, session.Name)
+		}
+
+		facet, err := session.newFacetSession(accountType)
+		if err != nil {
+			return err
+		}
+
+		facetName := FacetSessionName(session.Name, accountType)
+		_, configured := configuredSessions[facetName]
+		if _, ok := environ.sessions[facetName]; ok || configured {
+			return fmt.Errorf("session %s is already defined, can not create account facet %s", facetName, accountType)
+		}
+
+		if err := facet.InitExchange(facetName, nil); err != nil {
+			return errors.Wrapf(err, "unable to initialize account facet %s of session %s", accountType, session.Name)
+		}
+
+		session.facetSessions[accountType] = facet
+		environ.AddExchangeSession(facetName, facet)
 	}
 
 	return nil
@@ -387,10 +606,19 @@ func (environ *Environment) Connect(ctx context.Context) error {
 		if len(session.Subscriptions) == 0 {
 			logger.Warnf("exchange session %s has no subscriptions", session.Name)
 		} else {
-			// add the subscribe requests to the stream
-			for _, s := range session.Subscriptions {
-				logger.Infof("subscribing %s %s %v", s.Symbol, s.Channel, s.Options)
-				session.MarketDataStream.Subscribe(s.Channel, s.Symbol, s.Options)
+			// add the subscribe requests to the stream,
+			// the custom interval klines are aggregated from their source subscriptions
+			subscribed := make(map[types.Subscription]struct{})
+			for _, s := range session.EffectiveSubscriptions() {
+				logger.Infof("subscribing %s %s %v, declared by %v", s.Symbol, s.Channel, s.Options, s.Subscribers)
+				for _, sub := range session.streamSubscriptions(ctx, s.Subscription) {
+					if _, ok := subscribed[sub]; ok {
+						continue
+					}
+
+					subscribed[sub] = struct{}{}
+					session.MarketDataStream.Subscribe(sub.Channel, sub.Symbol, sub.Options)
+				}
 			}
 		}
 
@@ -505,6 +733,11 @@ func (environ *Environment) Sync(ctx context.Context, userConfig ...*Config) err
 		}
 	}
 
+	// the transfer history is only used by the treasury report, so the sync errors are not fatal
+	if err := environ.syncTransferHistory(ctx, environ.syncStartTime); err != nil {
+		log.WithError(err).Warnf("can not sync the deposit and withdraw history")
+	}
+
 	return nil
 }
 
@@ -647,6 +880,15 @@ func (environ *Environment) ConfigureNotificationSystem(ctx context.Context, use
 }
 
 func (environ *Environment) ConfigureNotification(config *NotificationConfig) error {
+	if config.ProfitChart != nil {
+		if err := config.ProfitChart.Validate(); err != nil {
+			return err
+		}
+
+		config.ProfitChart.Defaults()
+		environ.profitChartConfig = config.ProfitChart
+	}
+
 	if config.Switches != nil {
 		if config.Switches.Trade {
 			tradeHandler := func(trade types.Trade) {
@@ -0,0 +1,186 @@
+package bbgo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// dryRunOrderIDBase is the starting order id of the paper orders, it's high enough to not collide with the real order ids
+const dryRunOrderIDBase = uint64(1) << 62
+
+// DryRunExchange wraps an exchange and fakes the order submissions until the live time is reached.
+// The paper orders are never filled, their updates are emitted through the given user data stream,
+// so that the order stores and the active order books can still track them.
+// The dry-run sessions use dryRunStream, so that the paper order updates only reach the strategy placing them.
+type DryRunExchange struct {
+	types.Exchange
+
+	stream types.Stream
+
+	liveAfter time.Time
+
+	mu      sync.Mutex
+	orderID uint64
+	orders  map[uint64]types.Order
+}
+
+func NewDryRunExchange(exchange types.Exchange, stream types.Stream, liveAfter time.Time) *DryRunExchange {
+	return &DryRunExchange{
+		Exchange:  exchange,
+		stream:    stream,
+		liveAfter: liveAfter,
+		orderID:   dryRunOrderIDBase,
+		orders:    make(map[uint64]types.Order),
+	}
+}
+
+// IsDryRun returns true if the exchange is still in the warm-up period
+func (e *DryRunExchange) IsDryRun() bool {
+	return time.Now().Before(e.liveAfter)
+}
+
+// LiveAfter returns the time that the exchange starts to submit real orders
+func (e *DryRunExchange) LiveAfter() time.Time {
+	return e.liveAfter
+}
+
+func (e *DryRunExchange) SubmitOrder(ctx context.Context, submitOrder types.SubmitOrder) (*types.Order, error) {
+	if !e.IsDryRun() {
+		return e.Exchange.SubmitOrder(ctx, submitOrder)
+	}
+
+	// the reduce-only and the close position orders can only reduce the real position, they are never faked
+	if submitOrder.ReduceOnly || submitOrder.ClosePosition {
+		log.Warnf("[dryrun] submitting the reduce-only order to the exchange: %s", submitOrder.String())
+		return e.Exchange.SubmitOrder(ctx, submitOrder)
+	}
+
+	now := time.Now()
+
+	e.mu.Lock()
+	e.orderID++
+	order := types.Order{
+		SubmitOrder:      submitOrder,
+		Exchange:         e.Exchange.Name(),
+		OrderID:          e.orderID,
+		Status:           types.OrderStatusNew,
+		IsWorking:        true,
+		ExecutedQuantity: fixedpoint.Zero,
+		CreationTime:     types.Time(now),
+		UpdateTime:       types.Time(now),
+	}
+
+	// market orders can not rest on the book, and we don't fill the paper orders
+	if submitOrder.Type == types.OrderTypeMarket {
+		order.Status = types.OrderStatusCanceled
+		order.IsWorking = false
+	} else {
+		e.orders[order.OrderID] = order
+	}
+	e.mu.Unlock()
+
+	if order.Status == types.OrderStatusCanceled {
+		log.Warnf("[dryrun] paper market order is canceled without fill: %s", order.String())
+	} else {
+		log.Infof("[dryrun] paper order submitted: %s", order.String())
+	}
+
+	e.emitOrderUpdate(order)
+	return &order, nil
+}
+
+func (e *DryRunExchange) QueryOpenOrders(ctx context.Context, symbol string) ([]types.Order, error) {
+	orders, err := e.Exchange.QueryOpenOrders(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	e.mu.Lock()
+	for _, order := range e.orders {
+		if order.Symbol == symbol {
+			orders = append(orders, order)
+		}
+	}
+	e.mu.Unlock()
+
+	return orders, nil
+}
+
+func (e *DryRunExchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	var realOrders []types.Order
+	var canceledOrders []types.Order
+
+	e.mu.Lock()
+	for _, order := range orders {
+		if order.OrderID <= dryRunOrderIDBase {
+			realOrders = append(realOrders, order)
+			continue
+		}
+
+		if paperOrder, ok := e.orders[order.OrderID]; ok {
+			delete(e.orders, order.OrderID)
+
+			paperOrder.Status = types.OrderStatusCanceled
+			paperOrder.IsWorking = false
+			paperOrder.UpdateTime = types.Time(time.Now())
+			canceledOrders = append(canceledOrders, paperOrder)
+		}
+	}
+	e.mu.Unlock()
+
+	for _, order := range canceledOrders {
+		e.emitOrderUpdate(order)
+	}
+
+	if len(realOrders) == 0 {
+		return nil
+	}
+
+	return e.Exchange.CancelOrders(ctx, realOrders...)
+}
+
+func (e *DryRunExchange) emitOrderUpdate(order types.Order) {
+	if emitter, ok := e.stream.(interface{ EmitOrderUpdate(order types.Order) }); ok {
+		emitter.EmitOrderUpdate(order)
+	}
+}
+
+// dryRunStream is the user data stream of a dry-run session, the callbacks are registered on the session stream,
+// but the paper order updates are only emitted to the callbacks registered through this stream.
+// The session stream is shared by the strategies on the session, emitting the paper orders on it
+// would add them to the order stores and the active order books of the other strategies.
+type dryRunStream struct {
+	types.Stream
+
+	mu                   sync.Mutex
+	orderUpdateCallbacks []func(order types.Order)
+}
+
+func newDryRunStream(stream types.Stream) *dryRunStream {
+	return &dryRunStream{Stream: stream}
+}
+
+func (s *dryRunStream) OnOrderUpdate(cb func(order types.Order)) {
+	s.Stream.OnOrderUpdate(cb)
+
+	s.mu.Lock()
+	s.orderUpdateCallbacks = append(s.orderUpdateCallbacks, cb)
+	s.mu.Unlock()
+}
+
+// EmitOrderUpdate emits the paper order update to the callbacks of this stream only
+func (s *dryRunStream) EmitOrderUpdate(order types.Order) {
+	s.mu.Lock()
+	callbacks := append([]func(order types.Order){}, s.orderUpdateCallbacks...)
+	s.mu.Unlock()
+
+	for _, cb := range callbacks {
+		cb(order)
+	}
+}
@@ -0,0 +1,163 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func TestDryRunExchange(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	ctx := context.Background()
+	mockExchange := mocks.NewMockExchange(mockCtrl)
+	mockExchange.EXPECT().Name().Return(types.ExchangeBinance).AnyTimes()
+
+	stream := &types.StandardStream{}
+	var updates []types.Order
+	stream.OnOrderUpdate(func(order types.Order) {
+		updates = append(updates, order)
+	})
+
+	ex := NewDryRunExchange(mockExchange, stream, time.Now().Add(time.Hour))
+	assert.True(t, ex.IsDryRun())
+
+	t.Run("limit order rests on the paper book", func(t *testing.T) {
+		order, err := ex.SubmitOrder(ctx, types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeBuy,
+			Type:     types.OrderTypeLimit,
+			Price:    fixedpoint.NewFromFloat(20000.0),
+			Quantity: fixedpoint.NewFromFloat(0.01),
+		})
+		if assert.NoError(t, err) {
+			assert.Equal(t, types.OrderStatusNew, order.Status)
+			assert.Greater(t, order.OrderID, dryRunOrderIDBase)
+		}
+
+		mockExchange.EXPECT().QueryOpenOrders(ctx, "BTCUSDT").Return(nil, nil)
+		openOrders, err := ex.QueryOpenOrders(ctx, "BTCUSDT")
+		assert.NoError(t, err)
+		assert.Len(t, openOrders, 1)
+
+		// the real orders are passed to the wrapped exchange
+		realOrder := types.Order{OrderID: 123}
+		mockExchange.EXPECT().CancelOrders(ctx, realOrder).Return(nil)
+		assert.NoError(t, ex.CancelOrders(ctx, *order, realOrder))
+
+		if assert.Len(t, updates, 2) {
+			assert.Equal(t, types.OrderStatusCanceled, updates[1].Status)
+		}
+	})
+
+	t.Run("market order is not filled", func(t *testing.T) {
+		order, err := ex.SubmitOrder(ctx, types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     types.SideTypeSell,
+			Type:     types.OrderTypeMarket,
+			Quantity: fixedpoint.NewFromFloat(0.01),
+		})
+		if assert.NoError(t, err) {
+			assert.Equal(t, types.OrderStatusCanceled, order.Status)
+		}
+	})
+
+	t.Run("reduce-only order is submitted to the exchange", func(t *testing.T) {
+		submitOrder := types.SubmitOrder{
+			Symbol:     "BTCUSDT",
+			Side:       types.SideTypeSell,
+			Type:       types.OrderTypeMarket,
+			Quantity:   fixedpoint.MustNewFromString("0.01"),
+			ReduceOnly: true,
+		}
+		mockExchange.EXPECT().SubmitOrder(ctx, submitOrder).Return(&types.Order{OrderID: 2, Status: types.OrderStatusFilled}, nil)
+
+		order, err := ex.SubmitOrder(ctx, submitOrder)
+		if assert.NoError(t, err) {
+			assert.Equal(t, uint64(2), order.OrderID)
+			assert.Equal(t, types.OrderStatusFilled, order.Status)
+		}
+	})
+
+	t.Run("live after the warm-up period", func(t *testing.T) {
+		ex.liveAfter = time.Now().Add(-time.Second)
+		submitOrder := types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeLimit}
+		mockExchange.EXPECT().SubmitOrder(ctx, submitOrder).Return(&types.Order{OrderID: 1}, nil)
+
+		order, err := ex.SubmitOrder(ctx, submitOrder)
+		if assert.NoError(t, err) {
+			assert.Equal(t, uint64(1), order.OrderID)
+		}
+	})
+}
+
+func TestLoadLiveAfter(t *testing.T) {
+	ps := service.NewMemoryService()
+	now := time.Now()
+
+	liveAfter, err := loadLiveAfter(ps, "binance.scmaker.BTCUSDT", false, now, time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), liveAfter)
+
+	// the first run time is kept for the existing instance
+	liveAfter, err = loadLiveAfter(ps, "binance.scmaker.BTCUSDT", true, now.Add(2*time.Hour), time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, now.Add(time.Hour), liveAfter)
+
+	// the instance with the persisted state before the guard is enabled is live
+	liveAfter, err = loadLiveAfter(ps, "binance.scmaker.ETHUSDT", true, now, time.Hour)
+	assert.NoError(t, err)
+	assert.False(t, now.Before(liveAfter))
+}
+
+func TestTrader_applyLiveTradingGuard_NotConfigured(t *testing.T) {
+	trader := NewTrader(&Environment{sessions: map[string]*ExchangeSession{"binance": {}}})
+	trader.exchangeStrategies = map[string][]SingleExchangeStrategy{"binance": {&TestStrategy{Symbol: "BTCUSDT"}}}
+
+	assert.NoError(t, trader.applyLiveTradingGuard(context.Background()))
+	assert.Empty(t, trader.dryRunSessions)
+}
+
+func TestExchangeSession_newDryRunSession(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+	mockEx.EXPECT().Name().Return(types.ExchangeBinance).AnyTimes()
+
+	session := NewExchangeSession("binance", mockEx)
+	liveAfter := time.Now().Add(time.Hour)
+	dryRunA := session.newDryRunSession(liveAfter)
+	dryRunB := session.newDryRunSession(liveAfter)
+
+	var updatesA, updatesB []types.Order
+	dryRunA.UserDataStream.OnOrderUpdate(func(order types.Order) { updatesA = append(updatesA, order) })
+	dryRunB.UserDataStream.OnOrderUpdate(func(order types.Order) { updatesB = append(updatesB, order) })
+
+	// the paper order update is only seen by the session placing it
+	_, err := dryRunA.Exchange.SubmitOrder(context.Background(), types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Price:    fixedpoint.MustNewFromString("20000"),
+		Quantity: fixedpoint.MustNewFromString("0.01"),
+	})
+	assert.NoError(t, err)
+	assert.Len(t, updatesA, 1)
+	assert.Empty(t, updatesB)
+
+	// the real order updates of the session are relayed to both
+	session.UserDataStream.(*types.StandardStream).EmitOrderUpdate(types.Order{OrderID: 1})
+	assert.Len(t, updatesA, 2)
+	assert.Len(t, updatesB, 1)
+}
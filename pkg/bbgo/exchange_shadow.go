@@ -0,0 +1,271 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// shadowOrder is the paper order of the shadow exchange, it can only be filled after activeAt
+type shadowOrder struct {
+	types.Order
+
+	activeAt time.Time
+}
+
+// ShadowExchange wraps an exchange and simulates the order fills with the live market data,
+// the orders are never sent to the exchange. The order updates, the trades and the balance updates
+// are emitted through the given user data stream, and the paper balances are kept in the given account.
+//
+// The latency is applied to the order submissions: the limit orders are only filled by the prices after the latency,
+// and the market orders are filled at the first price after the latency.
+// The limit order is filled at its price when the price trades through it, the paper balances are not locked.
+type ShadowExchange struct {
+	types.Exchange
+
+	stream  types.Stream
+	account *types.Account
+
+	latency      time.Duration
+	makerFeeRate fixedpoint.Value
+	takerFeeRate fixedpoint.Value
+
+	// now is the clock of the shadow exchange, it's replaced in the tests
+	now func() time.Time
+
+	mu      sync.Mutex
+	orderID uint64
+	tradeID uint64
+	orders  map[uint64]*shadowOrder
+}
+
+func NewShadowExchange(exchange types.Exchange, stream types.Stream, account *types.Account, latency time.Duration, makerFeeRate, takerFeeRate fixedpoint.Value) *ShadowExchange {
+	return &ShadowExchange{
+		Exchange:     exchange,
+		stream:       stream,
+		account:      account,
+		latency:      latency,
+		makerFeeRate: makerFeeRate,
+		takerFeeRate: takerFeeRate,
+		now:          time.Now,
+		orders:       make(map[uint64]*shadowOrder),
+	}
+}
+
+func (e *ShadowExchange) SubmitOrder(ctx context.Context, submitOrder types.SubmitOrder) (*types.Order, error) {
+	switch submitOrder.Type {
+	case types.OrderTypeMarket, types.OrderTypeLimit, types.OrderTypeLimitMaker:
+	default:
+		return nil, fmt.Errorf("order type %s is not supported by the shadow exchange", submitOrder.Type)
+	}
+
+	now := e.now()
+
+	e.mu.Lock()
+	e.orderID++
+	order := types.Order{
+		SubmitOrder:      submitOrder,
+		Exchange:         e.Exchange.Name(),
+		OrderID:          e.orderID,
+		Status:           types.OrderStatusNew,
+		IsWorking:        true,
+		ExecutedQuantity: fixedpoint.Zero,
+		CreationTime:     types.Time(now),
+		UpdateTime:       types.Time(now),
+	}
+	e.orders[order.OrderID] = &shadowOrder{Order: order, activeAt: now.Add(e.latency)}
+	e.mu.Unlock()
+
+	log.Debugf("[shadow] paper order submitted: %s", order.String())
+
+	e.emitOrderUpdate(order)
+	return &order, nil
+}
+
+func (e *ShadowExchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	e.mu.Lock()
+	for _, order := range e.orders {
+		if order.Symbol == symbol {
+			orders = append(orders, order.Order)
+		}
+	}
+	e.mu.Unlock()
+
+	return orders, nil
+}
+
+func (e *ShadowExchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	var canceledOrders []types.Order
+
+	e.mu.Lock()
+	for _, order := range orders {
+		paperOrder, ok := e.orders[order.OrderID]
+		if !ok {
+			continue
+		}
+
+		delete(e.orders, order.OrderID)
+
+		paperOrder.Status = types.OrderStatusCanceled
+		paperOrder.IsWorking = false
+		paperOrder.UpdateTime = types.Time(e.now())
+		canceledOrders = append(canceledOrders, paperOrder.Order)
+	}
+	e.mu.Unlock()
+
+	for _, order := range canceledOrders {
+		e.emitOrderUpdate(order)
+	}
+
+	return nil
+}
+
+func (e *ShadowExchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	return e.account, nil
+}
+
+func (e *ShadowExchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	return e.account.Balances(), nil
+}
+
+// BindMarketData fills the paper orders with the kline updates of the market data stream
+func (e *ShadowExchange) BindMarketData(stream types.Stream) {
+	stream.OnKLine(e.processKLine)
+	stream.OnKLineClosed(e.processKLine)
+}
+
+// processKLine fills the active paper orders of the kline symbol,
+// the buy limit order is filled if the low price is lower than the order price, and vice versa.
+func (e *ShadowExchange) processKLine(kline types.KLine) {
+	now := e.now()
+
+	var filledOrders []types.Order
+	var trades []types.Trade
+
+	e.mu.Lock()
+	var orderIDs []uint64
+	for orderID, order := range e.orders {
+		if order.Symbol == kline.Symbol && !now.Before(order.activeAt) {
+			orderIDs = append(orderIDs, orderID)
+		}
+	}
+	sort.Slice(orderIDs, func(i, j int) bool { return orderIDs[i] < orderIDs[j] })
+
+	for _, orderID := range orderIDs {
+		order := e.orders[orderID]
+
+		price, isMaker, ok := matchShadowOrder(order.Order, kline)
+		if !ok {
+			continue
+		}
+
+		delete(e.orders, orderID)
+
+		e.tradeID++
+		trade := e.fill(&order.Order, price, isMaker, now)
+		filledOrders = append(filledOrders, order.Order)
+		trades = append(trades, trade)
+	}
+	e.mu.Unlock()
+
+	for i := range filledOrders {
+		e.emitOrderUpdate(filledOrders[i])
+		e.emitTradeUpdate(trades[i])
+	}
+
+	if len(trades) > 0 {
+		e.emitBalanceUpdate(e.account.Balances())
+	}
+}
+
+// fill updates the order to the filled status and settles the trade into the paper balances, the fee is in the quote currency
+func (e *ShadowExchange) fill(order *types.Order, price fixedpoint.Value, isMaker bool, now time.Time) types.Trade {
+	quantity := order.Quantity.Sub(order.ExecutedQuantity)
+	quoteQuantity := quantity.Mul(price)
+
+	feeRate := e.takerFeeRate
+	if isMaker {
+		feeRate = e.makerFeeRate
+	}
+	fee := quoteQuantity.Mul(feeRate)
+
+	order.ExecutedQuantity = order.Quantity
+	order.Status = types.OrderStatusFilled
+	order.IsWorking = false
+	order.UpdateTime = types.Time(now)
+
+	base, quote := order.Market.BaseCurrency, order.Market.QuoteCurrency
+	if base != "" && quote != "" {
+		if order.Side == types.SideTypeBuy {
+			e.account.AddBalance(base, quantity)
+			e.account.AddBalance(quote, quoteQuantity.Add(fee).Neg())
+		} else {
+			e.account.AddBalance(base, quantity.Neg())
+			e.account.AddBalance(quote, quoteQuantity.Sub(fee))
+		}
+	}
+
+	return types.Trade{
+		ID:            e.tradeID,
+		OrderID:       order.OrderID,
+		Exchange:      order.Exchange,
+		Symbol:        order.Symbol,
+		Side:          order.Side,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: quoteQuantity,
+		IsBuyer:       order.Side == types.SideTypeBuy,
+		IsMaker:       isMaker,
+		Fee:           fee,
+		FeeCurrency:   quote,
+		Time:          types.Time(now),
+	}
+}
+
+// matchShadowOrder returns the fill price of the order with the kline, the market order is filled at the close price as a taker
+func matchShadowOrder(order types.Order, kline types.KLine) (price fixedpoint.Value, isMaker bool, ok bool) {
+	switch order.Type {
+	case types.OrderTypeMarket:
+		return kline.Close, false, true
+
+	case types.OrderTypeLimit, types.OrderTypeLimitMaker:
+		switch order.Side {
+		case types.SideTypeBuy:
+			if kline.Low.Compare(order.Price) < 0 {
+				return order.Price, true, true
+			}
+
+		case types.SideTypeSell:
+			if kline.High.Compare(order.Price) > 0 {
+				return order.Price, true, true
+			}
+		}
+	}
+
+	return fixedpoint.Zero, false, false
+}
+
+func (e *ShadowExchange) emitOrderUpdate(order types.Order) {
+	if emitter, ok := e.stream.(types.StandardStreamEmitter); ok {
+		emitter.EmitOrderUpdate(order)
+	}
+}
+
+func (e *ShadowExchange) emitTradeUpdate(trade types.Trade) {
+	if emitter, ok := e.stream.(types.StandardStreamEmitter); ok {
+		emitter.EmitTradeUpdate(trade)
+	}
+}
+
+func (e *ShadowExchange) emitBalanceUpdate(balances types.BalanceMap) {
+	if emitter, ok := e.stream.(types.StandardStreamEmitter); ok {
+		emitter.EmitBalanceUpdate(balances)
+	}
+}
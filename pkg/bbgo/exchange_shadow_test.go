@@ -0,0 +1,131 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func TestShadowExchange(t *testing.T) {
+	market := getTestMarket()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().Name().Return(types.ExchangeBinance).AnyTimes()
+
+	account := types.NewAccount()
+	account.UpdateBalances(types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.Zero},
+		"USDT": {Currency: "USDT", Available: fixedpoint.MustNewFromString("10000")},
+	})
+
+	var trades []types.Trade
+	userDataStream := &types.StandardStream{}
+	userDataStream.OnTradeUpdate(func(trade types.Trade) {
+		trades = append(trades, trade)
+	})
+
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	ex := NewShadowExchange(mockEx, userDataStream, account, time.Second, fixedpoint.MustNewFromString("0.001"), fixedpoint.MustNewFromString("0.002"))
+	ex.now = func() time.Time { return now }
+
+	marketDataStream := &types.StandardStream{}
+	ex.BindMarketData(marketDataStream)
+
+	_, err := ex.SubmitOrder(context.Background(), types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Price:    fixedpoint.MustNewFromString("20000"),
+		Quantity: fixedpoint.MustNewFromString("0.1"),
+		Market:   market,
+	})
+	assert.NoError(t, err)
+
+	_, err = ex.SubmitOrder(context.Background(), types.SubmitOrder{
+		Symbol:    "BTCUSDT",
+		Side:      types.SideTypeSell,
+		Type:      types.OrderTypeStopLimit,
+		Price:     fixedpoint.MustNewFromString("20000"),
+		StopPrice: fixedpoint.MustNewFromString("20000"),
+		Quantity:  fixedpoint.MustNewFromString("0.1"),
+		Market:    market,
+	})
+	assert.Error(t, err, "the stop limit order is not supported")
+
+	kline := types.KLine{
+		Symbol: "BTCUSDT",
+		Open:   fixedpoint.MustNewFromString("20100"),
+		High:   fixedpoint.MustNewFromString("20200"),
+		Low:    fixedpoint.MustNewFromString("19900"),
+		Close:  fixedpoint.MustNewFromString("20150"),
+	}
+
+	// the order is not active before the latency
+	marketDataStream.EmitKLine(kline)
+	assert.Empty(t, trades)
+
+	openOrders, err := ex.QueryOpenOrders(context.Background(), "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Len(t, openOrders, 1)
+
+	now = now.Add(time.Second)
+	marketDataStream.EmitKLine(kline)
+	if assert.Len(t, trades, 1) {
+		assert.Equal(t, "20000", trades[0].Price.String())
+		assert.Equal(t, "0.1", trades[0].Quantity.String())
+		assert.True(t, trades[0].IsMaker)
+		assert.Equal(t, "2", trades[0].Fee.String())
+	}
+
+	openOrders, err = ex.QueryOpenOrders(context.Background(), "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Empty(t, openOrders)
+
+	// the market order is filled at the close price as a taker
+	_, err = ex.SubmitOrder(context.Background(), types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeMarket,
+		Quantity: fixedpoint.MustNewFromString("0.1"),
+		Market:   market,
+	})
+	assert.NoError(t, err)
+
+	now = now.Add(time.Second)
+	marketDataStream.EmitKLineClosed(kline)
+	if assert.Len(t, trades, 2) {
+		assert.Equal(t, "20150", trades[1].Price.String())
+		assert.False(t, trades[1].IsMaker)
+		assert.Equal(t, "4.03", trades[1].Fee.String())
+	}
+
+	balances := account.Balances()
+	assert.Equal(t, "0", balances["BTC"].Available.String())
+	assert.Equal(t, "10008.97", balances["USDT"].Available.String())
+
+	// the canceled order is never filled
+	order, err := ex.SubmitOrder(context.Background(), types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeLimit,
+		Price:    fixedpoint.MustNewFromString("20100"),
+		Quantity: fixedpoint.MustNewFromString("0.1"),
+		Market:   market,
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, ex.CancelOrders(context.Background(), *order))
+
+	now = now.Add(time.Second)
+	marketDataStream.EmitKLineClosed(kline)
+	assert.Len(t, trades, 2)
+}
@@ -23,12 +23,32 @@ func (g *GracefulShutdown) Shutdown(shutdownCtx context.Context) {
 	wg.Wait()
 }
 
+// OnShutdown registers the shutdown handler in the cancel orders phase of the shutdown manager of the isolation
 func OnShutdown(ctx context.Context, f ShutdownHandler) {
 	isolatedContext := GetIsolationFromContext(ctx)
-	isolatedContext.gracefulShutdown.OnShutdown(f)
+	isolatedContext.shutdownManager.OnShutdown(f)
 }
 
-func Shutdown(shutdownCtx context.Context) {
+// OnShutdownPhase registers the named shutdown hook in the phase of the shutdown manager of the isolation
+func OnShutdownPhase(ctx context.Context, phase ShutdownPhase, name string, hook ShutdownHook) {
+	isolatedContext := GetIsolationFromContext(ctx)
+	isolatedContext.shutdownManager.OnPhase(phase, name, hook)
+}
+
+// ConfigureShutdown applies the shutdown config to the shutdown manager of the isolation
+func ConfigureShutdown(ctx context.Context, config *ShutdownConfig) error {
+	isolatedContext := GetIsolationFromContext(ctx)
+	return isolatedContext.shutdownManager.Configure(config)
+}
+
+// ValidateShutdown checks the shutdown hooks registered by the strategies of the isolation against the shutdown config
+func ValidateShutdown(ctx context.Context) error {
+	isolatedContext := GetIsolationFromContext(ctx)
+	return isolatedContext.shutdownManager.Validate()
+}
+
+// Shutdown executes the shutdown phases of the isolation and reports the failed hooks
+func Shutdown(shutdownCtx context.Context) *ShutdownReport {
 
 	isolatedContext := GetIsolationFromContext(shutdownCtx)
 	if isolatedContext == defaultIsolation {
@@ -37,5 +57,13 @@ func Shutdown(shutdownCtx context.Context) {
 		logrus.Infof("bbgo shutting down (custom isolation)...")
 	}
 
-	isolatedContext.gracefulShutdown.Shutdown(shutdownCtx)
+	report := isolatedContext.shutdownManager.Shutdown(shutdownCtx)
+	if failed := report.Failed(); len(failed) > 0 {
+		logrus.Warnf(report.PlainText())
+		Notify(report)
+	} else {
+		logrus.Infof(report.PlainText())
+	}
+
+	return report
 }
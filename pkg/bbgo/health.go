@@ -0,0 +1,328 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	defaultHealthStreamTimeout      = 5 * time.Minute
+	defaultHealthStrategyTimeout    = 15 * time.Minute
+	defaultHealthPersistenceTimeout = 3 * time.Second
+)
+
+// StrategyLivenessReader is implemented by the strategies that report the time of their last trading decision,
+// e.g., the last time the quotes are updated, so that a stuck strategy can be detected by the health check.
+type StrategyLivenessReader interface {
+	LastDecisionTime() time.Time
+}
+
+// DecisionClock records the time of the last trading decision of a strategy, it's safe for the concurrent use.
+type DecisionClock struct {
+	unixNano int64
+}
+
+// Tick records the decision time
+func (c *DecisionClock) Tick(t time.Time) {
+	atomic.StoreInt64(&c.unixNano, t.UnixNano())
+}
+
+// LastDecisionTime returns the zero time if there is no decision yet
+func (c *DecisionClock) LastDecisionTime() time.Time {
+	n := atomic.LoadInt64(&c.unixNano)
+	if n == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(0, n)
+}
+
+// pingablePersistenceService can check the connection of the persistence backend
+type pingablePersistenceService interface {
+	Ping(ctx context.Context) error
+}
+
+// HealthCheckConfig is the thresholds of the /healthz and /readyz endpoints
+//
+//	healthCheck:
+//	  streamTimeout: 5m
+//	  strategyTimeout: 15m
+type HealthCheckConfig struct {
+	// StreamTimeout is how long a stream can stay disconnected before the process is unhealthy, defaults to 5 minutes.
+	// The readiness fails as soon as a stream is disconnected.
+	StreamTimeout types.Duration `json:"streamTimeout,omitempty" yaml:"streamTimeout,omitempty"`
+
+	// StrategyTimeout is the max age of the last decision of the strategies implementing StrategyLivenessReader, defaults to 15 minutes
+	StrategyTimeout types.Duration `json:"strategyTimeout,omitempty" yaml:"strategyTimeout,omitempty"`
+
+	// PersistenceTimeout is the timeout of the persistence ping, defaults to 3 seconds
+	PersistenceTimeout types.Duration `json:"persistenceTimeout,omitempty" yaml:"persistenceTimeout,omitempty"`
+}
+
+func (c *HealthCheckConfig) Validate() error {
+	if c.StreamTimeout < 0 || c.StrategyTimeout < 0 || c.PersistenceTimeout < 0 {
+		return fmt.Errorf("healthCheck: the timeouts can not be negative")
+	}
+
+	return nil
+}
+
+func durationOrDefault(d types.Duration, defaultDuration time.Duration) time.Duration {
+	if d > 0 {
+		return d.Duration()
+	}
+
+	return defaultDuration
+}
+
+type HealthStatus string
+
+const (
+	HealthOK   HealthStatus = "ok"
+	HealthFail HealthStatus = "fail"
+)
+
+// ComponentHealth is the status of one component, the component is stream, persistence, trader or strategy
+type ComponentHealth struct {
+	Component string       `json:"component"`
+	Name      string       `json:"name"`
+	Status    HealthStatus `json:"status"`
+	Message   string       `json:"message,omitempty"`
+
+	// Since is the time of the last state change of the stream, or the last decision time of the strategy
+	Since time.Time `json:"since,omitempty"`
+}
+
+// HealthReport is the response of the /healthz and /readyz endpoints, the status fails if any component fails
+type HealthReport struct {
+	Status     HealthStatus      `json:"status"`
+	Components []ComponentHealth `json:"components"`
+}
+
+func (r *HealthReport) add(component ComponentHealth) {
+	r.Components = append(r.Components, component)
+	if component.Status == HealthFail {
+		r.Status = HealthFail
+	}
+}
+
+func (r *HealthReport) Healthy() bool {
+	return r.Status == HealthOK
+}
+
+type streamHealth struct {
+	connected bool
+	since     time.Time
+}
+
+// HealthMonitor collects the status of the session streams, the persistence and the strategy instances.
+// The liveness (/healthz) fails if a stream is disconnected longer than the stream timeout, or a strategy is stuck,
+// the orchestrator should restart the process. The readiness (/readyz) fails until the strategies are started,
+// and while any stream is disconnected or the persistence is unreachable.
+type HealthMonitor struct {
+	config  *HealthCheckConfig
+	environ *Environment
+	trader  *Trader
+
+	// startTime is the reference time of the strategies without any decision
+	startTime time.Time
+
+	mu      sync.Mutex
+	streams map[string]*streamHealth
+}
+
+func NewHealthMonitor(environ *Environment, trader *Trader, config *HealthCheckConfig) *HealthMonitor {
+	if config == nil {
+		config = &HealthCheckConfig{}
+	}
+
+	return &HealthMonitor{
+		config:    config,
+		environ:   environ,
+		trader:    trader,
+		startTime: time.Now(),
+		streams:   make(map[string]*streamHealth),
+	}
+}
+
+// BindStreams binds the connection status of the session streams, it should be called before the streams are connected
+func (m *HealthMonitor) BindStreams() {
+	now := time.Now()
+	for _, session := range m.environ.Sessions() {
+		m.bindStream(session.MarketDataStream, session.Name+".market", now)
+		if !session.PublicOnly {
+			m.bindStream(session.UserDataStream, session.Name+".user", now)
+		}
+	}
+}
+
+func (m *HealthMonitor) bindStream(stream types.Stream, name string, now time.Time) {
+	if stream == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.streams[name] = &streamHealth{since: now}
+	m.mu.Unlock()
+
+	stream.OnConnect(func() { m.setConnected(name, true, time.Now()) })
+	stream.OnDisconnect(func() { m.setConnected(name, false, time.Now()) })
+}
+
+func (m *HealthMonitor) setConnected(name string, connected bool, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	health, ok := m.streams[name]
+	if !ok || health.connected == connected {
+		return
+	}
+
+	health.connected = connected
+	health.since = now
+}
+
+// Liveness reports the streams and the strategies, the disconnected streams fail after the stream timeout
+func (m *HealthMonitor) Liveness(ctx context.Context, now time.Time) *HealthReport {
+	report := &HealthReport{Status: HealthOK}
+	m.checkStreams(report, now, durationOrDefault(m.config.StreamTimeout, defaultHealthStreamTimeout))
+	m.checkStrategies(report, now)
+	return report
+}
+
+// Readiness reports the trader, the streams and the persistence
+func (m *HealthMonitor) Readiness(ctx context.Context, now time.Time) *HealthReport {
+	report := &HealthReport{Status: HealthOK}
+	m.checkTrader(report)
+	m.checkStreams(report, now, 0)
+	m.checkPersistence(ctx, report)
+	return report
+}
+
+func (m *HealthMonitor) checkTrader(report *HealthReport) {
+	component := ComponentHealth{Component: "trader", Name: "trader", Status: HealthOK}
+	if m.trader == nil {
+		component.Status = HealthFail
+		component.Message = "trader is not running"
+	} else {
+		m.trader.childStrategiesMutex.Lock()
+		started := m.trader.marketDataConnected
+		m.trader.childStrategiesMutex.Unlock()
+
+		if !started {
+			component.Status = HealthFail
+			component.Message = "the strategies are not started"
+		}
+	}
+
+	report.add(component)
+}
+
+// checkStreams fails the streams disconnected longer than the grace period
+func (m *HealthMonitor) checkStreams(report *HealthReport, now time.Time, grace time.Duration) {
+	m.mu.Lock()
+	names := make([]string, 0, len(m.streams))
+	for name := range m.streams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	components := make([]ComponentHealth, 0, len(names))
+	for _, name := range names {
+		health := m.streams[name]
+		component := ComponentHealth{Component: "stream", Name: name, Status: HealthOK, Since: health.since}
+		if !health.connected {
+			component.Message = fmt.Sprintf("disconnected for %s", now.Sub(health.since).Round(time.Second))
+			if now.Sub(health.since) >= grace {
+				component.Status = HealthFail
+			}
+		}
+
+		components = append(components, component)
+	}
+	m.mu.Unlock()
+
+	for _, component := range components {
+		report.add(component)
+	}
+}
+
+func (m *HealthMonitor) checkPersistence(ctx context.Context, report *HealthReport) {
+	facade := GetIsolationFromContext(ctx).persistenceServiceFacade
+	component := ComponentHealth{Component: "persistence", Name: persistenceBackendName(facade), Status: HealthOK}
+
+	if pinger, ok := facade.Get().(pingablePersistenceService); ok {
+		pingCtx, cancel := context.WithTimeout(ctx, durationOrDefault(m.config.PersistenceTimeout, defaultHealthPersistenceTimeout))
+		defer cancel()
+
+		if err := pinger.Ping(pingCtx); err != nil {
+			component.Status = HealthFail
+			component.Message = err.Error()
+		}
+	}
+
+	report.add(component)
+}
+
+func persistenceBackendName(facade *service.PersistenceServiceFacade) string {
+	switch {
+	case facade.Redis != nil:
+		return "redis"
+	case facade.Postgres != nil:
+		return "postgres"
+	case facade.Json != nil:
+		return "json"
+	}
+
+	return "memory"
+}
+
+// checkStrategies fails the strategy instances without a decision within the strategy timeout,
+// the instances not implementing StrategyLivenessReader are not reported.
+func (m *HealthMonitor) checkStrategies(report *HealthReport, now time.Time) {
+	if m.trader == nil {
+		return
+	}
+
+	instances, err := m.trader.StrategyInstances()
+	if err != nil {
+		report.add(ComponentHealth{Component: "strategy", Name: "*", Status: HealthFail, Message: err.Error()})
+		return
+	}
+
+	timeout := durationOrDefault(m.config.StrategyTimeout, defaultHealthStrategyTimeout)
+	for _, instance := range instances {
+		reader, ok := instance.Strategy.(StrategyLivenessReader)
+		if !ok {
+			continue
+		}
+
+		component := ComponentHealth{Component: "strategy", Name: instance.ID, Status: HealthOK}
+		if status, ok := instance.Strategy.(StrategyStatusReader); ok && status.GetStatus() != types.StrategyStatusRunning {
+			component.Message = "suspended"
+			report.add(component)
+			continue
+		}
+
+		lastDecision := reader.LastDecisionTime()
+		component.Since = lastDecision
+		if lastDecision.IsZero() {
+			component.Message = "no decision yet"
+			if now.Sub(m.startTime) > timeout {
+				component.Status = HealthFail
+			}
+		} else if age := now.Sub(lastDecision); age > timeout {
+			component.Status = HealthFail
+			component.Message = fmt.Sprintf("no decision for %s", age.Round(time.Second))
+		}
+
+		report.add(component)
+	}
+}
@@ -0,0 +1,127 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+type testLivenessStrategy struct {
+	StrategyController
+
+	Market string `json:"market"`
+
+	decisionClock DecisionClock
+}
+
+func (s *testLivenessStrategy) ID() string { return "liveness" }
+
+func (s *testLivenessStrategy) InstanceID() string { return "liveness:" + s.Market }
+
+func (s *testLivenessStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+func (s *testLivenessStrategy) LastDecisionTime() time.Time {
+	return s.decisionClock.LastDecisionTime()
+}
+
+func findComponentHealth(report *HealthReport, name string) (ComponentHealth, bool) {
+	for _, component := range report.Components {
+		if component.Name == name {
+			return component, true
+		}
+	}
+
+	return ComponentHealth{}, false
+}
+
+func TestHealthMonitor(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	marketDataStream, userDataStream := &types.StandardStream{}, &types.StandardStream{}
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(userDataStream).Times(1)
+	mockEx.EXPECT().NewStream().Return(marketDataStream).Times(1)
+
+	environ := NewEnvironment()
+	environ.AddExchangeSession("binance", NewExchangeSession("binance", mockEx))
+
+	strategy := &testLivenessStrategy{Market: "BTCUSDT"}
+	strategy.Status = types.StrategyStatusRunning
+
+	trader := NewTrader(environ)
+	assert.NoError(t, trader.AttachStrategyOn("binance", strategy))
+
+	monitor := NewHealthMonitor(environ, trader, &HealthCheckConfig{
+		StreamTimeout:   types.Duration(time.Minute),
+		StrategyTimeout: types.Duration(10 * time.Minute),
+	})
+	monitor.BindStreams()
+
+	ctx := context.Background()
+	now := time.Now()
+
+	// not ready before the strategies are started and the streams are connected
+	readiness := monitor.Readiness(ctx, now)
+	assert.False(t, readiness.Healthy())
+	if component, ok := findComponentHealth(readiness, "trader"); assert.True(t, ok) {
+		assert.Equal(t, HealthFail, component.Status)
+	}
+
+	// the streams are in the grace period of the liveness
+	assert.True(t, monitor.Liveness(ctx, now).Healthy())
+
+	marketDataStream.EmitConnect()
+	userDataStream.EmitConnect()
+	trader.marketDataConnected = true
+
+	readiness = monitor.Readiness(ctx, now)
+	assert.True(t, readiness.Healthy(), "%+v", readiness)
+	if component, ok := findComponentHealth(readiness, "memory"); assert.True(t, ok) {
+		assert.Equal(t, "persistence", component.Component)
+	}
+
+	userDataStream.EmitDisconnect()
+	now = time.Now()
+	assert.False(t, monitor.Readiness(ctx, now).Healthy())
+	assert.True(t, monitor.Liveness(ctx, now).Healthy())
+
+	liveness := monitor.Liveness(ctx, now.Add(2*time.Minute))
+	assert.False(t, liveness.Healthy())
+	if component, ok := findComponentHealth(liveness, "binance.user"); assert.True(t, ok) {
+		assert.Equal(t, HealthFail, component.Status)
+	}
+
+	if component, ok := findComponentHealth(liveness, "binance.market"); assert.True(t, ok) {
+		assert.Equal(t, HealthOK, component.Status)
+	}
+
+	userDataStream.EmitConnect()
+
+	// the strategy without any decision fails after the strategy timeout since the start
+	liveness = monitor.Liveness(ctx, now.Add(11*time.Minute))
+	if component, ok := findComponentHealth(liveness, "binance.liveness:BTCUSDT"); assert.True(t, ok) {
+		assert.Equal(t, HealthFail, component.Status)
+		assert.Equal(t, "no decision yet", component.Message)
+	}
+
+	strategy.decisionClock.Tick(now.Add(5 * time.Minute))
+	assert.True(t, monitor.Liveness(ctx, now.Add(11*time.Minute)).Healthy())
+
+	liveness = monitor.Liveness(ctx, now.Add(16*time.Minute))
+	if component, ok := findComponentHealth(liveness, "binance.liveness:BTCUSDT"); assert.True(t, ok) {
+		assert.Equal(t, HealthFail, component.Status)
+	}
+
+	// the suspended strategy is not checked
+	strategy.Status = types.StrategyStatusStopped
+	assert.True(t, monitor.Liveness(ctx, now.Add(16*time.Minute)).Healthy())
+}
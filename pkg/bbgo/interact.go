@@ -46,6 +46,7 @@ type CoreInteraction struct {
 	exchangeStrategies    map[string]SingleExchangeStrategy
 	closePositionContext  closePositionContext
 	modifyPositionContext modifyPositionContext
+	approvalContext       approvalContext
 }
 
 func NewCoreInteraction(environment *Environment, trader *Trader) *CoreInteraction {
@@ -92,7 +93,7 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 
 		reply.Message(message)
 		return nil
-	})
+	}).RequireRole(interact.RoleViewer)
 
 	i.PrivateCommand("/balances", "Show balances", func(reply interact.Reply) error {
 		reply.Message("Please select an exchange session")
@@ -119,7 +120,7 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 
 		reply.Message(message)
 		return nil
-	})
+	}).RequireRole(interact.RoleViewer)
 
 	i.PrivateCommand("/position", "Show Position", func(reply interact.Reply) error {
 		// it.trader.exchangeStrategies
@@ -158,7 +159,7 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 		}
 
 		return nil
-	})
+	}).RequireRole(interact.RoleViewer)
 
 	i.PrivateCommand("/resetposition", "Reset position", func(reply interact.Reply) error {
 		strategies, err := filterStrategies(it.exchangeStrategies, func(s SingleExchangeStrategy) bool {
@@ -201,7 +202,7 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 		}
 
 		return err
-	})
+	}).RequireRole(interact.RoleAdmin)
 
 	i.PrivateCommand("/closeposition", "Close position", func(reply interact.Reply) error {
 		// it.trader.exchangeStrategies
@@ -251,7 +252,7 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 		}
 
 		return nil
-	}).Next(func(percentageStr string, reply interact.Reply) error {
+	}).Next(func(percentageStr string, reply interact.Reply, session interact.Session) error {
 		percentage, err := fixedpoint.NewFromString(percentageStr)
 		if err != nil {
 			reply.Message(fmt.Sprintf("%q is not a valid percentage string", percentageStr))
@@ -262,7 +263,15 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 			kc.RemoveKeyboard()
 		}
 
-		err = it.closePositionContext.closer.ClosePosition(context.Background(), percentage)
+		closer := it.closePositionContext.closer
+		description := fmt.Sprintf("Close %s of the position of %s", percentage.Percentage(), it.closePositionContext.signature)
+		if it.requestApproval(reply, session, "closeposition", description, func(ctx context.Context) error {
+			return closer.ClosePosition(ctx, percentage)
+		}) {
+			return nil
+		}
+
+		err = closer.ClosePosition(context.Background(), percentage)
 		if err != nil {
 			reply.Message(fmt.Sprintf("Failed to close the position, %s", err.Error()))
 			return err
@@ -270,7 +279,7 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 
 		reply.Message("Done")
 		return nil
-	})
+	}).RequireRole(interact.RoleAdmin)
 
 	i.PrivateCommand("/status", "Strategy Status", func(reply interact.Reply) error {
 		// it.trader.exchangeStrategies
@@ -310,7 +319,7 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 		}
 
 		return nil
-	})
+	}).RequireRole(interact.RoleViewer)
 
 	i.PrivateCommand("/suspend", "Suspend Strategy", func(reply interact.Reply) error {
 		// it.trader.exchangeStrategies
@@ -410,7 +419,7 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 			reply.Message("No strategy supports EmergencyStopper")
 		}
 		return nil
-	}).Next(func(signature string, reply interact.Reply) error {
+	}).Next(func(signature string, reply interact.Reply, session interact.Session) error {
 		strategy, ok := it.exchangeStrategies[signature]
 		if !ok {
 			reply.Message("Strategy not found")
@@ -427,6 +436,12 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 			kc.RemoveKeyboard()
 		}
 
+		if it.requestApproval(reply, session, "emergencystop", fmt.Sprintf("Emergency stop %s", signature), func(ctx context.Context) error {
+			return controller.EmergencyStop()
+		}) {
+			return nil
+		}
+
 		if err := controller.EmergencyStop(); err != nil {
 			reply.Message(fmt.Sprintf("Failed to emergency stop the strategy, %s", err.Error()))
 			return err
@@ -434,7 +449,7 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 
 		reply.Message(fmt.Sprintf("Strategy %s stopped and the position closed.", signature))
 		return nil
-	})
+	}).RequireRole(interact.RoleAdmin)
 
 	// Position updater
 	i.PrivateCommand("/modifyposition", "Modify Strategy Position", func(reply interact.Reply) error {
@@ -508,7 +523,9 @@ func (it *CoreInteraction) Commands(i *interact.Interact) {
 
 		reply.Message(fmt.Sprintf("Position of strategy %s modified.", it.modifyPositionContext.signature))
 		return nil
-	})
+	}).RequireRole(interact.RoleAdmin)
+
+	it.approvalCommands(i)
 }
 
 func (it *CoreInteraction) Initialize() error {
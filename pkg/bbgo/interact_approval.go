@@ -0,0 +1,136 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/interact"
+)
+
+// approvalContext holds the request chosen by each user in the /approve conversation
+type approvalContext struct {
+	mu         sync.Mutex
+	requestIDs map[string]string
+}
+
+func (c *approvalContext) set(user, requestID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.requestIDs == nil {
+		c.requestIDs = make(map[string]string)
+	}
+
+	c.requestIDs[user] = requestID
+}
+
+// take returns and removes the request chosen by the user
+func (c *approvalContext) take(user string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	requestID, ok := c.requestIDs[user]
+	delete(c.requestIDs, user)
+	return requestID, ok
+}
+
+// requestApproval holds the command for the approval if the remote command approval is enabled,
+// it returns false if the command should be executed directly.
+func (it *CoreInteraction) requestApproval(reply interact.Reply, session interact.Session, action, description string, execute func(ctx context.Context) error) bool {
+	approvals := it.environment.ApprovalManager()
+	if approvals == nil {
+		return false
+	}
+
+	request := approvals.Request("interact", interact.SessionUser(session), action, description, execute)
+	reply.Message(fmt.Sprintf("%s requires approval, request id: %s. Please use /approve before %s.",
+		description, request.ID, request.ExpiredTime.Format(time.RFC3339)))
+	return true
+}
+
+func (it *CoreInteraction) approvalCommands(i *interact.Interact) {
+	approvals := it.environment.ApprovalManager()
+	if approvals == nil {
+		return
+	}
+
+	i.PrivateCommand("/approve", "Approve a pending command", func(reply interact.Reply) error {
+		requests := approvals.Pending()
+		if len(requests) == 0 {
+			reply.Message("No pending command")
+			return nil
+		}
+
+		for _, request := range requests {
+			reply.AddButton(fmt.Sprintf("#%s %s (%s)", request.ID, request.Description, request.Requester), "request", request.ID)
+		}
+
+		reply.Message("Please choose the command to approve")
+		return nil
+	}).Next(func(requestID string, reply interact.Reply, session interact.Session) error {
+		requestID = strings.TrimPrefix(strings.Fields(requestID + " ")[0], "#")
+		it.approvalContext.set(interact.SessionUser(session), requestID)
+
+		if kc, ok := reply.(interact.KeyboardController); ok {
+			kc.RemoveKeyboard()
+		}
+
+		if approvals.Mode() == ApprovalModeTOTP {
+			reply.Message(fmt.Sprintf("Enter the one-time password to approve #%s", requestID))
+		} else {
+			reply.Message(fmt.Sprintf("Enter \"yes\" to approve #%s", requestID))
+		}
+
+		return nil
+	}).Next(func(code string, reply interact.Reply, session interact.Session) error {
+		requestID, ok := it.approvalContext.take(interact.SessionUser(session))
+		if !ok {
+			reply.Message("No command is chosen, please use /approve again")
+			return nil
+		}
+
+		if approvals.Mode() != ApprovalModeTOTP && !strings.EqualFold(code, "yes") {
+			reply.Message("Approval is cancelled")
+			return nil
+		}
+		if err := approvals.Approve(context.Background(), requestID, interact.SessionUser(session), code); err != nil {
+			reply.Message(fmt.Sprintf("Failed to approve #%s, %s", requestID, err.Error()))
+			return err
+		}
+
+		reply.Message(fmt.Sprintf("Command #%s is approved and executed", requestID))
+		return nil
+	}).RequireRole(interact.RoleAdmin).Sensitive()
+
+	i.PrivateCommand("/reject", "Reject a pending command", func(reply interact.Reply) error {
+		requests := approvals.Pending()
+		if len(requests) == 0 {
+			reply.Message("No pending command")
+			return nil
+		}
+
+		for _, request := range requests {
+			reply.AddButton(fmt.Sprintf("#%s %s (%s)", request.ID, request.Description, request.Requester), "request", request.ID)
+		}
+
+		reply.Message("Please choose the command to reject")
+		return nil
+	}).Next(func(requestID string, reply interact.Reply, session interact.Session) error {
+		requestID = strings.TrimPrefix(strings.Fields(requestID + " ")[0], "#")
+
+		if kc, ok := reply.(interact.KeyboardController); ok {
+			kc.RemoveKeyboard()
+		}
+
+		if err := approvals.Reject(requestID, interact.SessionUser(session)); err != nil {
+			reply.Message(fmt.Sprintf("Failed to reject #%s, %s", requestID, err.Error()))
+			return err
+		}
+
+		reply.Message(fmt.Sprintf("Command #%s is rejected", requestID))
+		return nil
+	})
+}
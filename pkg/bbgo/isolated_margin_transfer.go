@@ -0,0 +1,166 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// IsolatedMarginTransferConfig moves the assets between the spot wallet and the isolated margin pair of the session
+//
+//	isolatedMarginTransfer:
+//	  amounts:
+//	    USDT: 1000
+//	    BTC: 0.01
+//	  transferOutOnShutdown: true
+type IsolatedMarginTransferConfig struct {
+	// Amounts are the target balances of the assets in the isolated margin pair,
+	// the shortfall is transferred in from the spot wallet when the session is initialized
+	Amounts map[string]fixedpoint.Value `json:"amounts,omitempty" yaml:"amounts,omitempty"`
+
+	// TransferOutOnShutdown transfers the available balances back to the spot wallet on shutdown,
+	// it's skipped if the isolated margin pair still has debts
+	TransferOutOnShutdown bool `json:"transferOutOnShutdown,omitempty" yaml:"transferOutOnShutdown,omitempty"`
+}
+
+func (c *IsolatedMarginTransferConfig) validate(session *ExchangeSession) error {
+	if !session.Margin || !session.IsolatedMargin {
+		return fmt.Errorf("session %s: isolatedMarginTransfer requires the isolated margin", session.Name)
+	}
+
+	market, ok := session.Market(session.IsolatedMarginSymbol)
+	if !ok {
+		return fmt.Errorf("session %s: market %s is not defined", session.Name, session.IsolatedMarginSymbol)
+	}
+
+	for asset, amount := range c.Amounts {
+		if asset != market.BaseCurrency && asset != market.QuoteCurrency {
+			return fmt.Errorf("session %s: %s is not an asset of the isolated margin pair %s", session.Name, asset, market.Symbol)
+		}
+
+		if amount.Sign() < 0 {
+			return fmt.Errorf("session %s: the transfer amount of %s can not be negative", session.Name, asset)
+		}
+	}
+
+	return nil
+}
+
+// TopUpIsolatedMargin transfers the shortfall of the isolated margin balances below the target amounts from the spot wallet,
+// the transfer is partial if the spot balance is not enough.
+func TopUpIsolatedMargin(ctx context.Context, service types.IsolatedMarginTransferService, isolated, spot types.BalanceMap, targets map[string]fixedpoint.Value) (transferred types.ValueMap, err error) {
+	transferred = make(types.ValueMap)
+	for asset, target := range targets {
+		shortfall := target.Sub(isolated[asset].Available)
+		if shortfall.Sign() <= 0 {
+			continue
+		}
+
+		amount := fixedpoint.Min(shortfall, spot[asset].Available)
+		if amount.Sign() <= 0 {
+			log.Warnf("no available %s balance in the spot wallet to top up the isolated margin shortfall %s", asset, shortfall.String())
+			continue
+		}
+
+		if err2 := service.TransferIsolatedMarginAccountAsset(ctx, asset, amount, types.TransferIn); err2 != nil {
+			err = multierr.Append(err, err2)
+			continue
+		}
+
+		transferred[asset] = amount
+	}
+
+	return transferred, err
+}
+
+// WithdrawIsolatedMargin transfers the available isolated margin balances back to the spot wallet,
+// nothing is transferred if any of the assets has debts, since the collateral is still needed.
+func WithdrawIsolatedMargin(ctx context.Context, service types.IsolatedMarginTransferService, isolated types.BalanceMap) (transferred types.ValueMap, err error) {
+	for asset, balance := range isolated {
+		if debt := balance.Debt(); debt.Sign() > 0 {
+			return nil, fmt.Errorf("isolated margin %s has debt %s, skip transferring out", asset, debt.String())
+		}
+	}
+
+	transferred = make(types.ValueMap)
+	for asset, balance := range isolated {
+		if balance.Available.Sign() <= 0 {
+			continue
+		}
+
+		if err2 := service.TransferIsolatedMarginAccountAsset(ctx, asset, balance.Available, types.TransferOut); err2 != nil {
+			err = multierr.Append(err, err2)
+			continue
+		}
+
+		transferred[asset] = balance.Available
+	}
+
+	return transferred, err
+}
+
+// topUpIsolatedMargin applies the isolated margin transfer config after the account is queried in the session initialization
+func (session *ExchangeSession) topUpIsolatedMargin(ctx context.Context) error {
+	service, ok := session.Exchange.(types.IsolatedMarginTransferService)
+	if !ok {
+		return fmt.Errorf("exchange %s does not support the isolated margin transfer", session.ExchangeName)
+	}
+
+	if len(session.IsolatedMarginTransfer.Amounts) == 0 {
+		return nil
+	}
+
+	spotAccount, err := service.QuerySpotAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	transferred, err := TopUpIsolatedMargin(ctx, service, session.GetAccount().Balances(), spotAccount.Balances(), session.IsolatedMarginTransfer.Amounts)
+	if err != nil {
+		return err
+	}
+
+	if len(transferred) == 0 {
+		return nil
+	}
+
+	log.Infof("session %s transferred %v into the isolated margin %s", session.Name, transferred, session.IsolatedMarginSymbol)
+	Notify("session %s transferred %v into the isolated margin %s", session.Name, transferred, session.IsolatedMarginSymbol)
+
+	_, err = session.UpdateAccount(ctx)
+	return err
+}
+
+// withdrawIsolatedMargin is the shutdown hook of the transferOutOnShutdown option, it's executed after the positions are flattened
+func (session *ExchangeSession) withdrawIsolatedMargin(ctx context.Context) error {
+	service, ok := session.Exchange.(types.IsolatedMarginTransferService)
+	if !ok {
+		return nil
+	}
+
+	account, err := session.UpdateAccount(ctx)
+	if err != nil {
+		return err
+	}
+
+	transferred, err := WithdrawIsolatedMargin(ctx, service, account.Balances())
+	if len(transferred) > 0 {
+		log.Infof("session %s transferred %v out of the isolated margin %s", session.Name, transferred, session.IsolatedMarginSymbol)
+	}
+
+	return err
+}
+
+// isolatedMarginLeverage returns the max leverage of the isolated margin pair, the pair leverage varies from 3x to 10x on binance
+func (session *ExchangeSession) isolatedMarginLeverage() fixedpoint.Value {
+	if session.IsolatedMarginLeverage > 0 {
+		return fixedpoint.NewFromInt(int64(session.IsolatedMarginLeverage))
+	}
+
+	return maxIsolatedMarginLeverage
+}
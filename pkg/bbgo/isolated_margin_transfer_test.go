@@ -0,0 +1,137 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testIsolatedMarginTransferService struct {
+	transferredIn  types.ValueMap
+	transferredOut types.ValueMap
+	failure        map[string]error
+}
+
+func newTestIsolatedMarginTransferService() *testIsolatedMarginTransferService {
+	return &testIsolatedMarginTransferService{
+		transferredIn:  make(types.ValueMap),
+		transferredOut: make(types.ValueMap),
+	}
+}
+
+func (s *testIsolatedMarginTransferService) TransferIsolatedMarginAccountAsset(ctx context.Context, asset string, amount fixedpoint.Value, io types.TransferDirection) error {
+	if err, ok := s.failure[asset]; ok {
+		return err
+	}
+
+	if io == types.TransferIn {
+		s.transferredIn[asset] = amount
+	} else {
+		s.transferredOut[asset] = amount
+	}
+
+	return nil
+}
+
+func (s *testIsolatedMarginTransferService) QuerySpotAccount(ctx context.Context) (*types.Account, error) {
+	return types.NewAccount(), nil
+}
+
+func TestTopUpIsolatedMargin(t *testing.T) {
+	isolated := types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.MustNewFromString("0.02")},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(200)},
+	}
+
+	spot := types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.One},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(500)},
+	}
+
+	t.Run("top up the shortfall", func(t *testing.T) {
+		service := newTestIsolatedMarginTransferService()
+		transferred, err := TopUpIsolatedMargin(context.Background(), service, isolated, spot, map[string]fixedpoint.Value{
+			"BTC":  fixedpoint.MustNewFromString("0.01"),
+			"USDT": fixedpoint.NewFromInt(1000),
+		})
+		assert.NoError(t, err)
+
+		// BTC is above the target, USDT is partially topped up with the spot balance
+		assert.Equal(t, types.ValueMap{"USDT": fixedpoint.NewFromInt(500)}, transferred)
+		assert.Equal(t, transferred, service.transferredIn)
+	})
+
+	t.Run("transfer error", func(t *testing.T) {
+		service := newTestIsolatedMarginTransferService()
+		service.failure = map[string]error{"USDT": errors.New("transfer failed")}
+
+		transferred, err := TopUpIsolatedMargin(context.Background(), service, isolated, spot, map[string]fixedpoint.Value{
+			"BTC":  fixedpoint.MustNewFromString("0.5"),
+			"USDT": fixedpoint.NewFromInt(300),
+		})
+		assert.Error(t, err)
+		assert.Equal(t, types.ValueMap{"BTC": fixedpoint.MustNewFromString("0.48")}, transferred)
+	})
+}
+
+func TestWithdrawIsolatedMargin(t *testing.T) {
+	t.Run("withdraw the available balances", func(t *testing.T) {
+		service := newTestIsolatedMarginTransferService()
+		transferred, err := WithdrawIsolatedMargin(context.Background(), service, types.BalanceMap{
+			"BTC":  {Currency: "BTC", Available: fixedpoint.MustNewFromString("0.1")},
+			"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(100), Locked: fixedpoint.NewFromInt(50)},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, types.ValueMap{
+			"BTC":  fixedpoint.MustNewFromString("0.1"),
+			"USDT": fixedpoint.NewFromInt(100),
+		}, service.transferredOut)
+		assert.Equal(t, service.transferredOut, transferred)
+	})
+
+	t.Run("skip with debts", func(t *testing.T) {
+		service := newTestIsolatedMarginTransferService()
+		transferred, err := WithdrawIsolatedMargin(context.Background(), service, types.BalanceMap{
+			"BTC":  {Currency: "BTC", Available: fixedpoint.MustNewFromString("0.1"), Borrowed: fixedpoint.MustNewFromString("0.05")},
+			"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(100)},
+		})
+		assert.Error(t, err)
+		assert.Empty(t, transferred)
+		assert.Empty(t, service.transferredOut)
+	})
+}
+
+func TestIsolatedMarginTransferConfig_Validate(t *testing.T) {
+	session := &ExchangeSession{
+		Name:                 "binance",
+		Margin:               true,
+		IsolatedMargin:       true,
+		IsolatedMarginSymbol: "BTCUSDT",
+		markets: types.MarketMap{
+			"BTCUSDT": {Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+		},
+	}
+
+	config := &IsolatedMarginTransferConfig{Amounts: map[string]fixedpoint.Value{"USDT": fixedpoint.NewFromInt(100)}}
+	assert.NoError(t, config.validate(session))
+
+	config = &IsolatedMarginTransferConfig{Amounts: map[string]fixedpoint.Value{"ETH": fixedpoint.One}}
+	assert.Error(t, config.validate(session))
+
+	session.IsolatedMargin = false
+	config = &IsolatedMarginTransferConfig{Amounts: map[string]fixedpoint.Value{"USDT": fixedpoint.NewFromInt(100)}}
+	assert.Error(t, config.validate(session))
+}
+
+func TestExchangeSession_isolatedMarginLeverage(t *testing.T) {
+	session := &ExchangeSession{Margin: true, IsolatedMargin: true}
+	assert.Equal(t, maxIsolatedMarginLeverage, session.isolatedMarginLeverage())
+
+	session.IsolatedMarginLeverage = 5
+	assert.Equal(t, fixedpoint.NewFromInt(5), session.isolatedMarginLeverage())
+}
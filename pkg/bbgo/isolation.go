@@ -11,20 +11,20 @@ const IsolationContextKey = "bbgo"
 var defaultIsolation = NewDefaultIsolation()
 
 type Isolation struct {
-	gracefulShutdown         GracefulShutdown
+	shutdownManager          *ShutdownManager
 	persistenceServiceFacade *service.PersistenceServiceFacade
 }
 
 func NewDefaultIsolation() *Isolation {
 	return &Isolation{
-		gracefulShutdown:         GracefulShutdown{},
+		shutdownManager:          NewShutdownManager(),
 		persistenceServiceFacade: defaultPersistenceServiceFacade,
 	}
 }
 
 func NewIsolation(persistenceFacade *service.PersistenceServiceFacade) *Isolation {
 	return &Isolation{
-		gracefulShutdown:         GracefulShutdown{},
+		shutdownManager:          NewShutdownManager(),
 		persistenceServiceFacade: persistenceFacade,
 	}
 }
@@ -12,13 +12,13 @@ func TestGetIsolationFromContext(t *testing.T) {
 	isolation := GetIsolationFromContext(ctx)
 	assert.NotNil(t, isolation)
 	assert.NotNil(t, isolation.persistenceServiceFacade)
-	assert.NotNil(t, isolation.gracefulShutdown)
+	assert.NotNil(t, isolation.shutdownManager)
 }
 
 func TestNewDefaultIsolation(t *testing.T) {
 	isolation := NewDefaultIsolation()
 	assert.NotNil(t, isolation)
 	assert.NotNil(t, isolation.persistenceServiceFacade)
-	assert.NotNil(t, isolation.gracefulShutdown)
+	assert.NotNil(t, isolation.shutdownManager)
 	assert.Equal(t, defaultPersistenceServiceFacade, isolation.persistenceServiceFacade)
 }
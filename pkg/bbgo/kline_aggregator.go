@@ -0,0 +1,151 @@
+package bbgo
+
+import (
+	"context"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// klineAggregationFlushDelay delays the closing of the trade aggregated klines,
+// so the trades of the window that arrive late are still aggregated into the window.
+var klineAggregationFlushDelay = 500 * time.Millisecond
+
+// supportedIntervals returns the kline intervals provided by the exchange natively
+func (session *ExchangeSession) supportedIntervals() map[types.Interval]int {
+	if provider, ok := session.Exchange.(types.CustomIntervalProvider); ok {
+		return provider.SupportedInterval()
+	}
+
+	return types.SupportedIntervals
+}
+
+func (session *ExchangeSession) isSupportedInterval(interval types.Interval) bool {
+	if provider, ok := session.Exchange.(types.CustomIntervalProvider); ok {
+		return provider.IsSupportedInterval(interval)
+	}
+
+	_, ok := types.SupportedIntervals[interval]
+	return ok
+}
+
+// klineAggregationSource returns the source interval of the custom interval that is not provided by the exchange,
+// aggregated is false if the exchange provides the interval natively.
+// The empty source interval means the klines are aggregated from the market trades.
+func (session *ExchangeSession) klineAggregationSource(interval types.Interval) (source types.Interval, aggregated bool) {
+	if session.isSupportedInterval(interval) {
+		return "", false
+	}
+
+	source, _ = types.AggregationSourceInterval(interval, session.supportedIntervals())
+	return source, true
+}
+
+// streamSubscriptions returns the subscriptions sent to the market data stream for the session subscription.
+// The kline subscription of the custom interval is replaced by the subscription of its source,
+// and the aggregated klines are emitted to the market data stream, so the strategies receive them like the exchange klines.
+func (session *ExchangeSession) streamSubscriptions(ctx context.Context, sub types.Subscription) []types.Subscription {
+	if sub.Channel != types.KLineChannel {
+		return []types.Subscription{sub}
+	}
+
+	interval := sub.Options.Interval
+	source, aggregated := session.klineAggregationSource(interval)
+	if !aggregated {
+		return []types.Subscription{sub}
+	}
+
+	emitter, ok := session.MarketDataStream.(types.StandardStreamEmitter)
+	if !ok {
+		session.logger.Errorf("%s %s kline is not supported by the exchange and the market data stream can not emit the aggregated klines", sub.Symbol, interval)
+		return []types.Subscription{sub}
+	}
+
+	aggregator := types.NewKLineAggregator(sub.Symbol, interval, source)
+	aggregator.OnKLine(emitter.EmitKLine)
+	aggregator.OnKLineClosed(emitter.EmitKLineClosed)
+
+	if source != "" {
+		session.logger.Infof("aggregating %s %s klines from the %s klines", sub.Symbol, interval, source)
+		session.MarketDataStream.OnKLineClosed(aggregator.AddKLine)
+		return []types.Subscription{{
+			Channel: types.KLineChannel,
+			Symbol:  sub.Symbol,
+			Options: types.SubscribeOptions{Interval: source},
+		}}
+	}
+
+	session.logger.Infof("aggregating %s %s klines from the market trades", sub.Symbol, interval)
+	session.MarketDataStream.OnMarketTrade(aggregator.AddTrade)
+	go flushKLineAggregator(ctx, aggregator)
+	return []types.Subscription{{
+		Channel: types.MarketTradeChannel,
+		Symbol:  sub.Symbol,
+	}}
+}
+
+// flushKLineAggregator closes the trade aggregated klines on the interval boundaries
+func flushKLineAggregator(ctx context.Context, aggregator *types.KLineAggregator) {
+	for {
+		boundary := aggregator.Interval.NextBoundary(time.Now())
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-time.After(time.Until(boundary) + klineAggregationFlushDelay):
+			aggregator.Flush(boundary)
+		}
+	}
+}
+
+// preloadAggregatedKLines queries the source klines of the custom interval and returns the aggregated closed klines,
+// nothing is returned if the klines are aggregated from the market trades, since the historical trades are not queried.
+func (session *ExchangeSession) preloadAggregatedKLines(ctx context.Context, symbol string, interval, source types.Interval, endTime time.Time, limit int64) ([]types.KLine, error) {
+	if source == "" {
+		session.logger.Infof("%s %s klines are aggregated from the market trades, skip preloading", symbol, interval)
+		return nil, nil
+	}
+
+	var klines []types.KLine
+	aggregator := types.NewKLineAggregator(symbol, interval, source)
+	aggregator.OnKLineClosed(func(kline types.KLine) {
+		klines = append(klines, kline)
+	})
+
+	// query the source klines forward, the first window is aligned to the custom interval
+	startTime := interval.Truncate(interval.Advance(endTime, -int(limit)))
+	for startTime.Before(endTime) {
+		since := startTime
+		sourceKLines, err := session.Exchange.QueryKLines(ctx, symbol, source, types.KLineQueryOptions{
+			StartTime: &since,
+			EndTime:   &endTime,
+			Limit:     1000,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if len(sourceKLines) == 0 {
+			break
+		}
+
+		for _, k := range sourceKLines {
+			// the last unclosed kline is not aggregated
+			if !k.EndTime.Time().Before(endTime) {
+				break
+			}
+
+			k.Closed = true
+			aggregator.AddKLine(k)
+		}
+
+		next := source.Advance(sourceKLines[len(sourceKLines)-1].StartTime.Time(), 1)
+		if !next.After(startTime) {
+			break
+		}
+
+		startTime = next
+	}
+
+	return klines, nil
+}
@@ -0,0 +1,92 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func newTestAggregationSession(t *testing.T) (*ExchangeSession, *types.StandardStream, *mocks.MockExchange) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).AnyTimes()
+
+	stream := &types.StandardStream{}
+	session := NewExchangeSession("binance", mockEx)
+	session.MarketDataStream = stream
+	return session, stream, mockEx
+}
+
+func TestExchangeSession_streamSubscriptions(t *testing.T) {
+	session, stream, _ := newTestAggregationSession(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	native := types.Subscription{Channel: types.KLineChannel, Symbol: "BTCUSDT", Options: types.SubscribeOptions{Interval: types.Interval1m}}
+	assert.Equal(t, []types.Subscription{native}, session.streamSubscriptions(ctx, native))
+
+	subs := session.streamSubscriptions(ctx, types.Subscription{Channel: types.KLineChannel, Symbol: "BTCUSDT", Options: types.SubscribeOptions{Interval: "2m"}})
+	assert.Equal(t, []types.Subscription{native}, subs, "the 2m klines are aggregated from the 1m klines")
+
+	var closed []types.KLine
+	stream.OnKLineClosed(types.KLineWith("BTCUSDT", "2m", func(kline types.KLine) {
+		closed = append(closed, kline)
+	}))
+
+	startTime := time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		stream.EmitKLineClosed(types.KLine{
+			Symbol:    "BTCUSDT",
+			StartTime: types.Time(startTime.Add(time.Duration(i) * time.Minute)),
+			Interval:  types.Interval1m,
+			Close:     fixedpoint.NewFromInt(int64(100 + i)),
+			Closed:    true,
+		})
+	}
+
+	if assert.Len(t, closed, 2) {
+		assert.Equal(t, int64(101), closed[0].Close.Int64())
+		assert.Equal(t, int64(103), closed[1].Close.Int64())
+	}
+}
+
+func TestExchangeSession_preloadAggregatedKLines(t *testing.T) {
+	session, _, mockEx := newTestAggregationSession(t)
+
+	endTime := time.Date(2023, time.July, 1, 0, 10, 0, 0, time.UTC)
+	mockEx.EXPECT().QueryKLines(gomock.Any(), "BTCUSDT", types.Interval1m, gomock.Any()).DoAndReturn(
+		func(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+			var klines []types.KLine
+			for t := *options.StartTime; t.Before(*options.EndTime); t = t.Add(time.Minute) {
+				klines = append(klines, types.KLine{
+					Symbol:    symbol,
+					StartTime: types.Time(t),
+					EndTime:   types.Time(t.Add(time.Minute - time.Millisecond)),
+					Interval:  interval,
+					Close:     fixedpoint.NewFromInt(t.Unix()),
+				})
+			}
+			return klines, nil
+		}).Times(1)
+
+	source, aggregated := session.klineAggregationSource("2m")
+	assert.True(t, aggregated)
+	assert.Equal(t, types.Interval1m, source)
+
+	klines, err := session.preloadAggregatedKLines(context.Background(), "BTCUSDT", "2m", source, endTime, 3)
+	if assert.NoError(t, err) && assert.Len(t, klines, 3) {
+		assert.Equal(t, endTime.Add(-6*time.Minute), klines[0].StartTime.Time())
+		assert.Equal(t, endTime.Add(-time.Minute).Unix(), klines[2].Close.Int64())
+		assert.True(t, klines[2].Closed)
+	}
+}
@@ -0,0 +1,218 @@
+package bbgo
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/dynamic"
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+const defaultLiveTradingWarmUpPeriod = time.Hour
+
+// liveGuardStoreTag is the persistence tag of the first run time of a strategy instance
+const liveGuardStoreTag = "live_guard_first_run"
+
+type sessionStrategyKey struct {
+	session  string
+	strategy SingleExchangeStrategy
+}
+
+// SetLiveTradingGuard sets the live trading guard config
+func (trader *Trader) SetLiveTradingGuard(config *LiveTradingGuardConfig) {
+	trader.liveTradingGuard = config
+}
+
+// DisableLiveTradingGuard lets all the strategy instances trade with real funds at startup
+func (trader *Trader) DisableLiveTradingGuard() {
+	trader.liveTradingGuard = &LiveTradingGuardConfig{Disabled: true}
+}
+
+// AcknowledgeLive marks the strategy as live, the strategy will not go through the dry-run warm-up period
+func (trader *Trader) AcknowledgeLive(strategy StrategyID) {
+	if trader.liveStrategies == nil {
+		trader.liveStrategies = make(map[StrategyID]struct{})
+	}
+
+	trader.liveStrategies[strategy] = struct{}{}
+}
+
+func (trader *Trader) isLive(strategy StrategyID) bool {
+	_, ok := trader.liveStrategies[strategy]
+	return ok
+}
+
+func (trader *Trader) liveTradingWarmUpPeriod() time.Duration {
+	if trader.liveTradingGuard != nil && trader.liveTradingGuard.WarmUpPeriod > 0 {
+		return trader.liveTradingGuard.WarmUpPeriod.Duration()
+	}
+
+	return defaultLiveTradingWarmUpPeriod
+}
+
+// applyLiveTradingGuard puts the newly added strategy instances into the dry-run mode if the guard is configured.
+// The first run time of each instance is stored in the persistence layer,
+// the instances without the live acknowledgement submit paper orders until the warm-up period is over.
+// The instances which already have the persisted state are not new, they are never put into the dry-run mode.
+func (trader *Trader) applyLiveTradingGuard(ctx context.Context) error {
+	if trader.environment.BacktestService != nil || IsBackTesting {
+		return nil
+	}
+
+	if trader.liveTradingGuard == nil || trader.liveTradingGuard.Disabled {
+		return nil
+	}
+
+	ps := GetIsolationFromContext(ctx).persistenceServiceFacade.Get()
+	if _, ok := ps.(*service.MemoryService); ok {
+		log.Warnf("live trading guard is disabled, the first run time of the strategy instances can not be stored in the memory persistence")
+		return nil
+	}
+	now := time.Now()
+	warmUpPeriod := trader.liveTradingWarmUpPeriod()
+
+	for sessionName, strategies := range trader.exchangeStrategies {
+		session := trader.environment.sessions[sessionName]
+		for _, strategy := range strategies {
+			if trader.isLive(strategy) {
+				continue
+			}
+
+			signature, err := getStrategySignature(strategy)
+			if err != nil {
+				return err
+			}
+
+			instanceID := sessionName + "." + signature
+			liveAfter, err := loadLiveAfter(ps, instanceID, hasPersistedState(ps, strategy), now, warmUpPeriod)
+			if err != nil {
+				return err
+			}
+
+			if !now.Before(liveAfter) {
+				continue
+			}
+
+			if trader.dryRunSessions == nil {
+				trader.dryRunSessions = make(map[sessionStrategyKey]*ExchangeSession)
+			}
+
+			trader.dryRunSessions[sessionStrategyKey{session: sessionName, strategy: strategy}] = session.newDryRunSession(liveAfter)
+			notifyDryRun(instanceID, liveAfter)
+		}
+	}
+
+	for _, strategy := range trader.crossExchangeStrategies {
+		if trader.isLive(strategy) {
+			continue
+		}
+
+		instanceID := dynamic.CallID(strategy)
+		if len(instanceID) == 0 {
+			instanceID = strategy.ID()
+		}
+
+		liveAfter, err := loadLiveAfter(ps, instanceID, hasPersistedState(ps, strategy), now, warmUpPeriod)
+		if err != nil {
+			return err
+		}
+
+		if !now.Before(liveAfter) {
+			continue
+		}
+
+		sessions := make(map[string]*ExchangeSession, len(trader.environment.sessions))
+		for sessionName, session := range trader.environment.sessions {
+			sessions[sessionName] = session.newDryRunSession(liveAfter)
+		}
+
+		if trader.dryRunCrossSessions == nil {
+			trader.dryRunCrossSessions = make(map[CrossExchangeStrategy]map[string]*ExchangeSession)
+		}
+
+		trader.dryRunCrossSessions[strategy] = sessions
+		notifyDryRun(instanceID, liveAfter)
+	}
+
+	return nil
+}
+
+// loadLiveAfter loads the first run time of the strategy instance, the current time is saved if it's a new instance.
+// The instance which existed before the guard is enabled is saved with the zero first run time, so it's always live.
+func loadLiveAfter(ps service.PersistenceService, instanceID string, existing bool, now time.Time, warmUpPeriod time.Duration) (time.Time, error) {
+	var firstRunTime time.Time
+
+	store := ps.NewStore("state", instanceID, liveGuardStoreTag)
+	if err := store.Load(&firstRunTime); err != nil {
+		if err != service.ErrPersistenceNotExists {
+			return firstRunTime, errors.Wrapf(err, "failed to load the first run time of %s", instanceID)
+		}
+
+		if existing {
+			firstRunTime = time.Time{}
+		} else {
+			firstRunTime = now
+		}
+
+		if err := store.Save(firstRunTime); err != nil {
+			return firstRunTime, errors.Wrapf(err, "failed to save the first run time of %s", instanceID)
+		}
+	}
+
+	return firstRunTime.Add(warmUpPeriod), nil
+}
+
+// hasPersistedState returns true if any of the persistence fields of the strategy is stored
+func hasPersistedState(ps service.PersistenceService, strategy StrategyID) bool {
+	id := dynamic.CallID(strategy)
+	found := false
+	_ = dynamic.IterateFieldsByTag(strategy, "persistence", func(tag string, field reflect.StructField, value reflect.Value) error {
+		v := dynamic.NewTypeValueInterface(value.Type())
+		if err := ps.NewStore("state", id, tag).Load(&v); err == nil {
+			found = true
+		}
+
+		return nil
+	})
+
+	return found
+}
+
+func notifyDryRun(instanceID string, liveAfter time.Time) {
+	log.Warnf("strategy instance %s is new, it will run in the dry-run mode until %s", instanceID, liveAfter)
+	Notify("Strategy instance %s is running in the dry-run mode until %s, set `live: true` in the strategy config or use --i-really-want-to-trade to trade with real funds now",
+		instanceID, liveAfter.Format(time.RFC3339))
+}
+
+// strategySession returns the session and the order executor for the strategy,
+// the dry-run session is returned if the strategy instance is in the warm-up period,
+// and the guarded session is returned if the panic recovery is enabled.
+func (trader *Trader) strategySession(sessionName string, strategy SingleExchangeStrategy) (*ExchangeSession, OrderExecutor) {
+	if guarded, ok := trader.guardedSessions[sessionStrategyKey{session: sessionName, strategy: strategy}]; ok {
+		return guarded.session, guarded.orderExecutor
+	}
+
+	if session, ok := trader.dryRunSessions[sessionStrategyKey{session: sessionName, strategy: strategy}]; ok {
+		return session, session.OrderExecutor
+	}
+
+	return trader.environment.sessions[sessionName], trader.getSessionOrderExecutor(sessionName)
+}
+
+// crossStrategySessions returns the sessions for the cross exchange strategy,
+// the dry-run sessions are returned if the strategy instance is in the warm-up period.
+func (trader *Trader) crossStrategySessions(strategy CrossExchangeStrategy) map[string]*ExchangeSession {
+	if sessions, ok := trader.guardedCrossSessions[strategy]; ok {
+		return sessions
+	}
+
+	if sessions, ok := trader.dryRunCrossSessions[strategy]; ok {
+		return sessions
+	}
+
+	return trader.environment.sessions
+}
@@ -0,0 +1,93 @@
+package bbgo
+
+import (
+	"context"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// RepayMarginDebts repays the debts (borrowed and interest) of the assets with the available balances,
+// the debt is partially repaid if the available balance is not enough.
+func RepayMarginDebts(ctx context.Context, service types.MarginBorrowRepayService, balances types.BalanceMap, assets ...string) (repaid types.ValueMap, err error) {
+	repaid = make(types.ValueMap)
+	for _, asset := range assets {
+		balance, ok := balances[asset]
+		if !ok {
+			continue
+		}
+
+		debt := balance.Debt()
+		if debt.Sign() <= 0 {
+			continue
+		}
+
+		amount := fixedpoint.Min(debt, balance.Available)
+		if amount.Sign() <= 0 {
+			log.Infof("no available %s balance to repay the debt %s", asset, debt.String())
+			continue
+		}
+
+		if err2 := service.RepayMarginAsset(ctx, asset, amount); err2 != nil {
+			err = multierr.Append(err, err2)
+			continue
+		}
+
+		repaid[asset] = amount
+	}
+
+	return repaid, err
+}
+
+// autoRepayMarginDebts repays the debts of the base and the quote assets after the position is closed,
+// so that the interest doesn't accrue on the idle borrowed funds.
+func (e *GeneralOrderExecutor) autoRepayMarginDebts(trade types.Trade) {
+	if !e.position.IsClosed() && !e.position.IsDust(trade.Price) {
+		return
+	}
+
+	service, ok := e.session.Exchange.(types.MarginBorrowRepayService)
+	if !ok {
+		return
+	}
+
+	go func() {
+		e.autoRepayMu.Lock()
+		defer e.autoRepayMu.Unlock()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		// the balances of the stream could be outdated right after the trade
+		account, err := e.session.UpdateAccount(ctx)
+		if err != nil {
+			log.WithError(err).Errorf("can not update the account of session %s for the margin auto-repay", e.session.Name)
+			return
+		}
+
+		repaid, err := RepayMarginDebts(ctx, service, account.Balances(), e.position.BaseCurrency, e.position.QuoteCurrency)
+		if err != nil {
+			log.WithError(err).Errorf("%s margin auto-repay error", e.symbol)
+		}
+
+		if len(repaid) == 0 {
+			return
+		}
+
+		for asset, amount := range repaid {
+			log.Infof("%s position closed, repaid %s %s", e.symbol, amount.String(), asset)
+		}
+
+		if !e.disableNotify {
+			Notify("%s position closed, repaid the margin debts %v", e.symbol, repaid)
+		}
+
+		if _, err := e.session.UpdateAccount(ctx); err != nil {
+			log.WithError(err).Errorf("can not update the account of session %s after the margin auto-repay", e.session.Name)
+		}
+	}()
+}
@@ -0,0 +1,80 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testMarginBorrowRepayService struct {
+	repaid  types.ValueMap
+	failure map[string]error
+}
+
+func (s *testMarginBorrowRepayService) RepayMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	if err, ok := s.failure[asset]; ok {
+		return err
+	}
+
+	s.repaid[asset] = amount
+	return nil
+}
+
+func (s *testMarginBorrowRepayService) BorrowMarginAsset(ctx context.Context, asset string, amount fixedpoint.Value) error {
+	return nil
+}
+
+func (s *testMarginBorrowRepayService) QueryMarginAssetMaxBorrowable(ctx context.Context, asset string) (fixedpoint.Value, error) {
+	return fixedpoint.Zero, nil
+}
+
+func TestRepayMarginDebts(t *testing.T) {
+	balances := types.BalanceMap{
+		"BTC": {
+			Currency:  "BTC",
+			Available: fixedpoint.MustNewFromString("0.5"),
+			Borrowed:  fixedpoint.MustNewFromString("0.2"),
+			Interest:  fixedpoint.MustNewFromString("0.001"),
+		},
+		"USDT": {
+			Currency:  "USDT",
+			Available: fixedpoint.MustNewFromString("100"),
+			Borrowed:  fixedpoint.MustNewFromString("300"),
+		},
+		"ETH": {
+			Currency:  "ETH",
+			Available: fixedpoint.One,
+		},
+		"BNB": {
+			Currency: "BNB",
+			Borrowed: fixedpoint.One,
+		},
+	}
+
+	t.Run("repay", func(t *testing.T) {
+		service := &testMarginBorrowRepayService{repaid: make(types.ValueMap)}
+		repaid, err := RepayMarginDebts(context.Background(), service, balances, "BTC", "USDT", "ETH", "BNB", "DOGE")
+		assert.NoError(t, err)
+		assert.Equal(t, types.ValueMap{
+			"BTC":  fixedpoint.MustNewFromString("0.201"),
+			"USDT": fixedpoint.MustNewFromString("100"),
+		}, repaid)
+		assert.Equal(t, repaid, service.repaid)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		service := &testMarginBorrowRepayService{
+			repaid:  make(types.ValueMap),
+			failure: map[string]error{"BTC": errors.New("repay error")},
+		}
+
+		repaid, err := RepayMarginDebts(context.Background(), service, balances, "BTC", "USDT")
+		assert.Error(t, err)
+		assert.Equal(t, types.ValueMap{"USDT": fixedpoint.MustNewFromString("100")}, repaid)
+	})
+}
@@ -0,0 +1,380 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultMarginMonitorInterval = time.Minute
+
+var (
+	defaultWarningMarginRatio = fixedpoint.NewFromFloat(0.5)
+	defaultDeleverageRatio    = fixedpoint.NewFromFloat(0.25)
+)
+
+// MarginMonitorConfig enables the margin monitor of the futures sessions
+type MarginMonitorConfig struct {
+	// Interval is the check interval, defaults to 1 minute
+	Interval types.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	// Sessions are the monitored sessions, all the futures sessions are monitored if it's empty
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+
+	// WarningMarginRatio is the maintenance margin ratio (maintenance margin / margin balance) to send the warning, defaults to 0.5
+	WarningMarginRatio fixedpoint.Value `json:"warningMarginRatio,omitempty" yaml:"warningMarginRatio,omitempty"`
+
+	// DeleverageMarginRatio is the maintenance margin ratio to deleverage, the deleveraging is disabled if it's zero
+	DeleverageMarginRatio fixedpoint.Value `json:"deleverageMarginRatio,omitempty" yaml:"deleverageMarginRatio,omitempty"`
+
+	// WarningLiquidationDistance is the distance from the mark price to the liquidation price in ratio to send the warning
+	WarningLiquidationDistance fixedpoint.Value `json:"warningLiquidationDistance,omitempty" yaml:"warningLiquidationDistance,omitempty"`
+
+	// DeleverageLiquidationDistance is the distance to the liquidation price in ratio to deleverage
+	DeleverageLiquidationDistance fixedpoint.Value `json:"deleverageLiquidationDistance,omitempty" yaml:"deleverageLiquidationDistance,omitempty"`
+
+	// DeleverageRatio is the ratio of the position reduced by the reduce-only orders in each deleveraging, defaults to 0.25
+	DeleverageRatio fixedpoint.Value `json:"deleverageRatio,omitempty" yaml:"deleverageRatio,omitempty"`
+}
+
+type MarginRiskLevel int
+
+const (
+	MarginRiskLevelNormal MarginRiskLevel = iota
+	MarginRiskLevelWarning
+	MarginRiskLevelDeleverage
+)
+
+func (l MarginRiskLevel) String() string {
+	switch l {
+	case MarginRiskLevelWarning:
+		return "warning"
+	case MarginRiskLevelDeleverage:
+		return "deleverage"
+	}
+
+	return "normal"
+}
+
+// PositionMarginRisk is the liquidation distance of one futures position
+type PositionMarginRisk struct {
+	Symbol           string           `json:"symbol"`
+	Base             fixedpoint.Value `json:"base"`
+	MarkPrice        fixedpoint.Value `json:"markPrice"`
+	LiquidationPrice fixedpoint.Value `json:"liquidationPrice"`
+
+	// LiquidationDistance is the adverse price move in ratio to reach the liquidation price
+	LiquidationDistance fixedpoint.Value `json:"liquidationDistance"`
+}
+
+// MarginRisk is the margin status of one futures session
+type MarginRisk struct {
+	Session string `json:"session"`
+
+	MarginBalance     fixedpoint.Value `json:"marginBalance"`
+	MaintenanceMargin fixedpoint.Value `json:"maintenanceMargin"`
+
+	// MarginRatio is the maintenance margin divided by the margin balance, the account is liquidated when it reaches 1
+	MarginRatio fixedpoint.Value `json:"marginRatio"`
+
+	// LiquidationDistance is the smallest liquidation distance of the positions
+	LiquidationDistance fixedpoint.Value `json:"liquidationDistance"`
+
+	Positions []PositionMarginRisk `json:"positions"`
+
+	Level MarginRiskLevel `json:"level"`
+}
+
+func (r MarginRisk) String() string {
+	return fmt.Sprintf("%s margin ratio %s, liquidation distance %s (%s)",
+		r.Session, r.MarginRatio.Percentage(), r.LiquidationDistance.Percentage(), r.Level)
+}
+
+// MarginMonitor checks the maintenance margin ratio and the distance to liquidation of the futures sessions periodically,
+// it sends the warnings through the notifier and deleverages the positions with the reduce-only orders when the thresholds are breached.
+type MarginMonitor struct {
+	environ  *Environment
+	config   MarginMonitorConfig
+	interval time.Duration
+
+	levels map[string]MarginRiskLevel
+
+	logger logrus.FieldLogger
+}
+
+func NewMarginMonitor(environ *Environment, config *MarginMonitorConfig) *MarginMonitor {
+	c := *config
+	if c.WarningMarginRatio.IsZero() {
+		c.WarningMarginRatio = defaultWarningMarginRatio
+	}
+
+	if c.DeleverageRatio.IsZero() {
+		c.DeleverageRatio = defaultDeleverageRatio
+	}
+
+	interval := c.Interval.Duration()
+	if interval == 0 {
+		interval = defaultMarginMonitorInterval
+	}
+
+	return &MarginMonitor{
+		environ:  environ,
+		config:   c,
+		interval: interval,
+		levels:   make(map[string]MarginRiskLevel),
+		logger:   logrus.WithField("component", "marginMonitor"),
+	}
+}
+
+// Run checks the sessions periodically until the context is done
+func (m *MarginMonitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			for _, session := range m.sessions() {
+				if _, err := m.Check(ctx, session); err != nil {
+					m.logger.WithError(err).Errorf("%s margin check error", session.Name)
+				}
+			}
+		}
+	}
+}
+
+// Check updates the account of the session and evaluates the margin risk,
+// the warning is sent when the risk level is raised, and the positions are deleveraged on the deleverage level.
+func (m *MarginMonitor) Check(ctx context.Context, session *ExchangeSession) (*MarginRisk, error) {
+	account, err := session.UpdateAccount(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	risk, ok := calculateMarginRisk(session, account)
+	if !ok {
+		return nil, nil
+	}
+
+	risk.Level = m.riskLevel(risk)
+
+	lastLevel := m.levels[session.Name]
+	m.levels[session.Name] = risk.Level
+
+	if risk.Level > lastLevel {
+		Notify("⚠️ %s", risk.String())
+	} else if risk.Level < lastLevel {
+		Notify("%s margin risk is back to %s", session.Name, risk.Level)
+	}
+
+	m.logger.Debugf("%s", risk.String())
+
+	if risk.Level == MarginRiskLevelDeleverage {
+		if err := m.deleverage(ctx, session, risk); err != nil {
+			return &risk, err
+		}
+	}
+
+	return &risk, nil
+}
+
+func (m *MarginMonitor) riskLevel(risk MarginRisk) MarginRiskLevel {
+	hasDistance := len(risk.Positions) > 0
+
+	if m.config.DeleverageMarginRatio.Sign() > 0 && risk.MarginRatio.Compare(m.config.DeleverageMarginRatio) >= 0 {
+		return MarginRiskLevelDeleverage
+	}
+
+	if hasDistance && m.config.DeleverageLiquidationDistance.Sign() > 0 &&
+		risk.LiquidationDistance.Compare(m.config.DeleverageLiquidationDistance) <= 0 {
+		return MarginRiskLevelDeleverage
+	}
+
+	if risk.MarginRatio.Compare(m.config.WarningMarginRatio) >= 0 {
+		return MarginRiskLevelWarning
+	}
+
+	if hasDistance && m.config.WarningLiquidationDistance.Sign() > 0 &&
+		risk.LiquidationDistance.Compare(m.config.WarningLiquidationDistance) <= 0 {
+		return MarginRiskLevelWarning
+	}
+
+	return MarginRiskLevelNormal
+}
+
+// deleverage submits the reduce-only market orders to reduce every position by the deleverage ratio
+func (m *MarginMonitor) deleverage(ctx context.Context, session *ExchangeSession, risk MarginRisk) error {
+	orders := deleverageOrders(session, risk, m.config.DeleverageRatio)
+	if len(orders) == 0 {
+		return nil
+	}
+
+	Notify("%s margin ratio %s breached the deleverage threshold, reducing the positions by %s",
+		session.Name, risk.MarginRatio.Percentage(), m.config.DeleverageRatio.Percentage())
+
+	for _, order := range orders {
+		if _, err := session.Exchange.SubmitOrder(ctx, order); err != nil {
+			return fmt.Errorf("%s deleverage order error: %w", order.Symbol, err)
+		}
+	}
+
+	return nil
+}
+
+func (m *MarginMonitor) sessions() []*ExchangeSession {
+	if len(m.config.Sessions) == 0 {
+		var sessions []*ExchangeSession
+		for _, session := range m.environ.Sessions() {
+			if session.Futures {
+				sessions = append(sessions, session)
+			}
+		}
+
+		return sessions
+	}
+
+	var sessions []*ExchangeSession
+	for _, name := range m.config.Sessions {
+		session, ok := m.environ.Session(name)
+		if !ok {
+			m.logger.Warnf("session %s is not defined", name)
+			continue
+		}
+
+		sessions = append(sessions, session)
+	}
+
+	return sessions
+}
+
+// calculateMarginRisk evaluates the margin ratio and the liquidation distances of the futures account.
+// When the position does not have the liquidation price, it's estimated from the margin left before the maintenance margin,
+// assuming the adverse moves of all the positions.
+func calculateMarginRisk(session *ExchangeSession, account *types.Account) (MarginRisk, bool) {
+	info := account.FuturesInfo
+	if info == nil {
+		return MarginRisk{}, false
+	}
+
+	risk := MarginRisk{
+		Session:           session.Name,
+		MarginBalance:     info.TotalMarginBalance,
+		MaintenanceMargin: info.TotalMaintMargin,
+	}
+
+	if info.TotalMarginBalance.Sign() > 0 {
+		risk.MarginRatio = info.TotalMaintMargin.Div(info.TotalMarginBalance)
+	} else if info.TotalMaintMargin.Sign() > 0 {
+		risk.MarginRatio = fixedpoint.One
+	}
+
+	symbols := make([]string, 0, len(info.Positions))
+	for symbol := range info.Positions {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var totalNotional fixedpoint.Value
+	var positions []PositionMarginRisk
+	for _, symbol := range symbols {
+		position := info.Positions[symbol]
+		if position.Base.IsZero() {
+			continue
+		}
+
+		markPrice, ok := sessionMidPrice(session, symbol)
+		if !ok {
+			markPrice = position.Quote.Div(position.Base).Abs()
+		}
+
+		if markPrice.IsZero() {
+			continue
+		}
+
+		p := PositionMarginRisk{
+			Symbol:    symbol,
+			Base:      position.Base,
+			MarkPrice: markPrice,
+		}
+
+		if position.PositionRisk != nil {
+			p.LiquidationPrice = position.PositionRisk.LiquidationPrice
+		}
+
+		totalNotional = totalNotional.Add(position.Base.Abs().Mul(markPrice))
+		positions = append(positions, p)
+	}
+
+	// the adverse move of all the positions that uses up the margin left
+	var estimatedDistance fixedpoint.Value
+	if totalNotional.Sign() > 0 {
+		estimatedDistance = fixedpoint.Max(info.TotalMarginBalance.Sub(info.TotalMaintMargin), fixedpoint.Zero).Div(totalNotional)
+	}
+
+	for i, p := range positions {
+		if p.LiquidationPrice.Sign() > 0 {
+			positions[i].LiquidationDistance = p.MarkPrice.Sub(p.LiquidationPrice).Abs().Div(p.MarkPrice)
+		} else {
+			move := p.MarkPrice.Mul(estimatedDistance)
+			positions[i].LiquidationDistance = estimatedDistance
+			if p.Base.Sign() > 0 {
+				positions[i].LiquidationPrice = fixedpoint.Max(p.MarkPrice.Sub(move), fixedpoint.Zero)
+			} else {
+				positions[i].LiquidationPrice = p.MarkPrice.Add(move)
+			}
+		}
+
+		if i == 0 || positions[i].LiquidationDistance.Compare(risk.LiquidationDistance) < 0 {
+			risk.LiquidationDistance = positions[i].LiquidationDistance
+		}
+	}
+
+	risk.Positions = positions
+	return risk, true
+}
+
+// deleverageOrders creates the reduce-only market orders, the dust orders are skipped
+func deleverageOrders(session *ExchangeSession, risk MarginRisk, ratio fixedpoint.Value) []types.SubmitOrder {
+	var orders []types.SubmitOrder
+	for _, position := range risk.Positions {
+		quantity := position.Base.Abs().Mul(ratio)
+
+		market, hasMarket := session.Market(position.Symbol)
+		if hasMarket {
+			quantity = market.TruncateQuantity(quantity)
+			if market.IsDustQuantity(quantity, position.MarkPrice) {
+				continue
+			}
+		}
+
+		if quantity.IsZero() {
+			continue
+		}
+
+		side := types.SideTypeSell
+		if position.Base.Sign() < 0 {
+			side = types.SideTypeBuy
+		}
+
+		orders = append(orders, types.SubmitOrder{
+			Symbol:     position.Symbol,
+			Market:     market,
+			Side:       side,
+			Type:       types.OrderTypeMarket,
+			Quantity:   quantity,
+			ReduceOnly: true,
+			Tag:        "deleverage",
+		})
+	}
+
+	return orders
+}
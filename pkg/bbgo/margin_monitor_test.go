@@ -0,0 +1,101 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestMarginMonitor(t *testing.T) {
+	session := &ExchangeSession{
+		Name: "binance-futures",
+		lastPrices: map[string]fixedpoint.Value{
+			"BTCUSDT": fixedpoint.NewFromInt(20000),
+		},
+		markets: map[string]types.Market{
+			"BTCUSDT": {
+				Symbol:      "BTCUSDT",
+				StepSize:    fixedpoint.NewFromFloat(0.001),
+				MinQuantity: fixedpoint.NewFromFloat(0.001),
+				MinNotional: fixedpoint.NewFromInt(10),
+			},
+		},
+	}
+
+	account := types.NewAccount()
+	account.FuturesInfo = &types.FuturesAccountInfo{
+		TotalMarginBalance: fixedpoint.NewFromInt(1000),
+		TotalMaintMargin:   fixedpoint.NewFromInt(600),
+		Positions: types.FuturesPositionMap{
+			"BTCUSDT": {
+				Symbol: "BTCUSDT",
+				Base:   fixedpoint.NewFromFloat(0.1),
+				Quote:  fixedpoint.NewFromInt(2000),
+			},
+			"ETHUSDT": {
+				Symbol: "ETHUSDT",
+				Base:   fixedpoint.NewFromInt(-2),
+				Quote:  fixedpoint.NewFromInt(-3000),
+				PositionRisk: &types.PositionRisk{
+					LiquidationPrice: fixedpoint.NewFromInt(1650),
+				},
+			},
+			"LTCUSDT": {
+				Symbol: "LTCUSDT",
+			},
+		},
+	}
+
+	risk, ok := calculateMarginRisk(session, account)
+	if !assert.True(t, ok) || !assert.Len(t, risk.Positions, 2) {
+		return
+	}
+
+	assert.Equal(t, "0.6", risk.MarginRatio.String())
+
+	// the margin left 400 over the total notional 5000
+	btc := risk.Positions[0]
+	assert.Equal(t, "BTCUSDT", btc.Symbol)
+	assert.Equal(t, "0.08", btc.LiquidationDistance.String())
+	assert.Equal(t, "18400", btc.LiquidationPrice.String())
+
+	eth := risk.Positions[1]
+	assert.Equal(t, "1500", eth.MarkPrice.String())
+	assert.Equal(t, "0.1", eth.LiquidationDistance.String())
+
+	assert.Equal(t, "0.08", risk.LiquidationDistance.String())
+
+	monitor := NewMarginMonitor(NewEnvironment(), &MarginMonitorConfig{
+		DeleverageMarginRatio: fixedpoint.NewFromFloat(0.8),
+	})
+	assert.Equal(t, MarginRiskLevelWarning, monitor.riskLevel(risk))
+
+	monitor = NewMarginMonitor(NewEnvironment(), &MarginMonitorConfig{
+		WarningMarginRatio:            fixedpoint.NewFromFloat(0.7),
+		DeleverageLiquidationDistance: fixedpoint.NewFromFloat(0.1),
+	})
+	assert.Equal(t, MarginRiskLevelDeleverage, monitor.riskLevel(risk))
+
+	monitor = NewMarginMonitor(NewEnvironment(), &MarginMonitorConfig{
+		WarningMarginRatio: fixedpoint.NewFromFloat(0.7),
+	})
+	assert.Equal(t, MarginRiskLevelNormal, monitor.riskLevel(risk))
+
+	orders := deleverageOrders(session, risk, fixedpoint.NewFromFloat(0.25))
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, types.SideTypeSell, orders[0].Side)
+		assert.Equal(t, "0.025", orders[0].Quantity.String())
+		assert.True(t, orders[0].ReduceOnly)
+
+		assert.Equal(t, types.SideTypeBuy, orders[1].Side)
+		assert.Equal(t, "0.5", orders[1].Quantity.String())
+		assert.True(t, orders[1].ReduceOnly)
+	}
+
+	// the spot account is not monitored
+	_, ok = calculateMarginRisk(session, types.NewAccount())
+	assert.False(t, ok)
+}
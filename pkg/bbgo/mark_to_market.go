@@ -0,0 +1,141 @@
+package bbgo
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultMarkToMarketInterval = 5 * time.Minute
+
+// MarkToMarketConfig enables the periodic equity snapshots of the strategy positions
+type MarkToMarketConfig struct {
+	// Interval is the snapshot interval, defaults to 5 minutes
+	Interval types.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+}
+
+// MarkToMarketService marks every strategy position against the current mid price periodically,
+// and stores the snapshots as the equity curves of the strategy instances.
+type MarkToMarketService struct {
+	environ  *Environment
+	trader   *Trader
+	interval time.Duration
+
+	logger logrus.FieldLogger
+}
+
+func NewMarkToMarketService(environ *Environment, trader *Trader, config *MarkToMarketConfig) *MarkToMarketService {
+	interval := config.Interval.Duration()
+	if interval == 0 {
+		interval = defaultMarkToMarketInterval
+	}
+
+	return &MarkToMarketService{
+		environ:  environ,
+		trader:   trader,
+		interval: interval,
+		logger:   logrus.WithField("component", "markToMarket"),
+	}
+}
+
+// Run takes the snapshots periodically until the context is done
+func (s *MarkToMarketService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			snapshots, err := s.Snapshot(time.Now())
+			if err != nil {
+				s.logger.WithError(err).Error("mark-to-market snapshot error")
+				continue
+			}
+
+			if s.environ.EquitySnapshotService == nil {
+				continue
+			}
+
+			for _, snapshot := range snapshots {
+				if err := s.environ.EquitySnapshotService.Insert(snapshot); err != nil {
+					s.logger.WithError(err).Errorf("can not insert the equity snapshot of %s %s", snapshot.StrategyInstanceID, snapshot.Symbol)
+				}
+			}
+		}
+	}
+}
+
+// Snapshot marks the positions of all the strategy instances, the positions without a price are skipped
+func (s *MarkToMarketService) Snapshot(now time.Time) ([]service.EquitySnapshot, error) {
+	instances, err := s.trader.StrategyInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []service.EquitySnapshot
+	for _, instance := range instances {
+		for _, position := range collectStrategyPositions(instance.Strategy) {
+			midPrice, ok := s.midPrice(instance.Session, position.Symbol)
+			if !ok {
+				s.logger.Debugf("no price of %s for %s, skipping", position.Symbol, instance.ID)
+				continue
+			}
+
+			snapshots = append(snapshots, markPosition(instance, position, midPrice, now))
+		}
+	}
+
+	return snapshots, nil
+}
+
+// midPrice looks up the price in the session of the instance,
+// or in all the sessions for the cross exchange strategy instances.
+func (s *MarkToMarketService) midPrice(sessionName, symbol string) (fixedpoint.Value, bool) {
+	if sessionName != "" {
+		if session, ok := s.environ.Session(sessionName); ok {
+			return sessionMidPrice(session, symbol)
+		}
+	}
+
+	for _, session := range s.environ.Sessions() {
+		if price, ok := sessionMidPrice(session, symbol); ok {
+			return price, true
+		}
+	}
+
+	return fixedpoint.Zero, false
+}
+
+func markPosition(instance *StrategyInstance, position *types.Position, midPrice fixedpoint.Value, now time.Time) service.EquitySnapshot {
+	position.Lock()
+	base := position.Base
+	averageCost := position.AverageCost
+	realizedPnL := position.AccumulatedProfit
+	position.Unlock()
+
+	unrealizedPnL := midPrice.Sub(averageCost).Mul(base)
+	if base.IsZero() {
+		unrealizedPnL = fixedpoint.Zero
+	}
+
+	return service.EquitySnapshot{
+		Strategy:           instance.Strategy.ID(),
+		StrategyInstanceID: instance.ID,
+		Symbol:             position.Symbol,
+		Base:               base,
+		AverageCost:        averageCost,
+		MidPrice:           midPrice,
+		RealizedPnL:        realizedPnL,
+		UnrealizedPnL:      unrealizedPnL,
+		Equity:             realizedPnL.Add(unrealizedPnL),
+		Time:               types.Time(now),
+	}
+}
@@ -0,0 +1,55 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func TestMarkToMarketService_Snapshot(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	session := NewExchangeSession("binance", mockEx)
+	session.lastPrices["BTCUSDT"] = fixedpoint.NewFromInt(21000)
+
+	environ := NewEnvironment()
+	environ.AddExchangeSession("binance", session)
+
+	strategy := &testInstanceStrategy{
+		Symbol: "BTCUSDT",
+		Position: &types.Position{
+			Symbol:            "BTCUSDT",
+			Base:              fixedpoint.NewFromFloat(0.5),
+			AverageCost:       fixedpoint.NewFromInt(20000),
+			AccumulatedProfit: fixedpoint.NewFromInt(100),
+		},
+	}
+
+	trader := &Trader{
+		environment: environ,
+		exchangeStrategies: map[string][]SingleExchangeStrategy{
+			"binance": {strategy},
+		},
+	}
+
+	now := time.Now()
+	snapshots, err := NewMarkToMarketService(environ, trader, &MarkToMarketConfig{}).Snapshot(now)
+	if assert.NoError(t, err) && assert.Len(t, snapshots, 1) {
+		snapshot := snapshots[0]
+		assert.Equal(t, "binance.test:BTCUSDT", snapshot.StrategyInstanceID)
+		assert.Equal(t, "test", snapshot.Strategy)
+		assert.Equal(t, "21000", snapshot.MidPrice.String())
+		assert.Equal(t, "500", snapshot.UnrealizedPnL.String())
+		assert.Equal(t, "600", snapshot.Equity.String())
+	}
+}
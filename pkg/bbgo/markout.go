@@ -0,0 +1,204 @@
+package bbgo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// DefaultMarkOutHorizons are the horizons of the post-trade mark-out analytics
+var DefaultMarkOutHorizons = []time.Duration{
+	time.Second,
+	5 * time.Second,
+	30 * time.Second,
+	5 * time.Minute,
+}
+
+type pendingMarkOut struct {
+	trade   types.Trade
+	horizon time.Duration
+	dueTime time.Time
+}
+
+// MarkOutCollector samples the mid price at the horizons after each fill,
+// and records the mark-out into the mark-out stats and the metrics.
+// The horizons are measured on the market data timeline instead of the wall clock, so that it works in the back-test,
+// a sample is taken at the first market data event at or after the horizon.
+type MarkOutCollector struct {
+	symbol             string
+	strategy           string
+	strategyInstanceID string
+
+	stats    *types.MarkOutStats
+	horizons []time.Duration
+
+	midPrice func() (fixedpoint.Value, bool)
+
+	mu      sync.Mutex
+	pending []pendingMarkOut
+
+	markOutCallbacks []func(trade types.Trade, horizon time.Duration, bps, pnl fixedpoint.Value)
+}
+
+func NewMarkOutCollector(session *ExchangeSession, symbol, strategy, strategyInstanceID string, stats *types.MarkOutStats) *MarkOutCollector {
+	horizons := DefaultMarkOutHorizons
+	if len(stats.Horizons) > 0 {
+		horizons = nil
+		for _, horizonStats := range stats.Horizons {
+			horizons = append(horizons, horizonStats.Horizon)
+		}
+	}
+
+	return &MarkOutCollector{
+		symbol:             symbol,
+		strategy:           strategy,
+		strategyInstanceID: strategyInstanceID,
+		stats:              stats,
+		horizons:           horizons,
+		midPrice: func() (fixedpoint.Value, bool) {
+			return sessionMidPrice(session, symbol)
+		},
+	}
+}
+
+func (c *MarkOutCollector) OnMarkOut(cb func(trade types.Trade, horizon time.Duration, bps, pnl fixedpoint.Value)) {
+	c.markOutCallbacks = append(c.markOutCallbacks, cb)
+}
+
+func (c *MarkOutCollector) EmitMarkOut(trade types.Trade, horizon time.Duration, bps, pnl fixedpoint.Value) {
+	for _, cb := range c.markOutCallbacks {
+		cb(trade, horizon, bps, pnl)
+	}
+}
+
+// Stats returns the mark-out stats
+func (c *MarkOutCollector) Stats() *types.MarkOutStats {
+	return c.stats
+}
+
+// Add schedules the mid price sampling of the trade at the horizons after the trade time
+func (c *MarkOutCollector) Add(trade types.Trade) {
+	if trade.Symbol != c.symbol {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, horizon := range c.horizons {
+		c.pending = append(c.pending, pendingMarkOut{
+			trade:   trade,
+			horizon: horizon,
+			dueTime: trade.Time.Time().Add(horizon),
+		})
+	}
+}
+
+// Update samples the mid price for the pending trades whose horizons are reached at the given market data time
+func (c *MarkOutCollector) Update(now time.Time) {
+	c.mu.Lock()
+	var due []pendingMarkOut
+	pending := c.pending[:0]
+	for _, p := range c.pending {
+		if now.Before(p.dueTime) {
+			pending = append(pending, p)
+		} else {
+			due = append(due, p)
+		}
+	}
+	c.pending = pending
+	c.mu.Unlock()
+
+	if len(due) == 0 {
+		return
+	}
+
+	midPrice, ok := c.midPrice()
+	if !ok {
+		return
+	}
+
+	for _, p := range due {
+		c.record(p.trade, p.horizon, midPrice)
+	}
+}
+
+// BindStream drives the mark-out sampling by the kline and the market trade times of the stream,
+// the pending samples are dropped when the context is done.
+func (c *MarkOutCollector) BindStream(ctx context.Context, stream types.Stream) {
+	update := func(now time.Time) {
+		if ctx.Err() != nil {
+			return
+		}
+
+		c.Update(now)
+	}
+
+	stream.OnKLine(func(k types.KLine) {
+		if k.Symbol == c.symbol {
+			update(k.EndTime.Time())
+		}
+	})
+	stream.OnKLineClosed(func(k types.KLine) {
+		if k.Symbol == c.symbol {
+			update(k.EndTime.Time())
+		}
+	})
+	stream.OnMarketTrade(func(trade types.Trade) {
+		if trade.Symbol == c.symbol {
+			update(trade.Time.Time())
+		}
+	})
+}
+
+func (c *MarkOutCollector) record(trade types.Trade, horizon time.Duration, midPrice fixedpoint.Value) {
+	bps, pnl := c.stats.Add(trade, horizon, midPrice)
+
+	labels := prometheus.Labels{
+		"strategy_type": c.strategy,
+		"strategy_id":   c.strategyInstanceID,
+		"symbol":        c.symbol,
+		"horizon":       horizon.String(),
+		"liquidity":     trade.Liquidity(),
+	}
+
+	metricsMarkOutBps.With(labels).Observe(bps.Float64())
+	metricsMarkOutPnL.With(prometheus.Labels{
+		"strategy_type": c.strategy,
+		"strategy_id":   c.strategyInstanceID,
+		"symbol":        c.symbol,
+		"horizon":       horizon.String(),
+	}).Set(c.stats.TotalMarkOutPnL(horizon).Float64())
+	if bps.Sign() < 0 {
+		metricsMarkOutAdverseTrades.With(labels).Inc()
+	}
+
+	c.EmitMarkOut(trade, horizon, bps, pnl)
+}
+
+// sessionMidPrice returns the mid price of the session order book, or the last price if the book is not subscribed
+func sessionMidPrice(session *ExchangeSession, symbol string) (fixedpoint.Value, bool) {
+	if book, ok := session.OrderBook(symbol); ok {
+		if bid, ask, ok := book.BestBidAndAsk(); ok {
+			return bid.Price.Add(ask.Price).Div(fixedpoint.Two), true
+		}
+	}
+
+	return session.LastPrice(symbol)
+}
+
+// BindMarkOutStats records the post-trade mark-out of the strategy trades into the given stats
+func (e *GeneralOrderExecutor) BindMarkOutStats(ctx context.Context, stats *types.MarkOutStats) *MarkOutCollector {
+	collector := NewMarkOutCollector(e.session, e.symbol, e.strategy, e.strategyInstanceID, stats)
+	collector.BindStream(ctx, e.session.MarketDataStream)
+	e.tradeCollector.OnTrade(func(trade types.Trade, profit, netProfit fixedpoint.Value) {
+		collector.Add(trade)
+	})
+
+	return collector
+}
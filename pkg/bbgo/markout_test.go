@@ -0,0 +1,52 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestMarkOutCollector_Update(t *testing.T) {
+	number := fixedpoint.MustNewFromString
+	start := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	midPrice := number("101")
+	stats := types.NewMarkOutStats("BTCUSDT", time.Second, time.Minute)
+	collector := &MarkOutCollector{
+		symbol:   "BTCUSDT",
+		stats:    stats,
+		horizons: []time.Duration{time.Second, time.Minute},
+		midPrice: func() (fixedpoint.Value, bool) {
+			return midPrice, true
+		},
+	}
+
+	var sampled []time.Duration
+	collector.OnMarkOut(func(trade types.Trade, horizon time.Duration, bps, pnl fixedpoint.Value) {
+		sampled = append(sampled, horizon)
+	})
+
+	collector.Add(types.Trade{Symbol: "ETHUSDT", Price: number("100"), Quantity: number("1"), Time: types.Time(start)})
+	collector.Add(types.Trade{Symbol: "BTCUSDT", Side: types.SideTypeBuy, Price: number("100"), Quantity: number("1"), Time: types.Time(start)})
+
+	// the horizons are measured on the market data timeline
+	collector.Update(start.Add(500 * time.Millisecond))
+	assert.Empty(t, sampled)
+
+	collector.Update(start.Add(time.Second))
+	assert.Equal(t, []time.Duration{time.Second}, sampled)
+
+	midPrice = number("99")
+	collector.Update(start.Add(2 * time.Minute))
+	assert.Equal(t, []time.Duration{time.Second, time.Minute}, sampled)
+
+	collector.Update(start.Add(3 * time.Minute))
+	assert.Len(t, sampled, 2)
+
+	assert.Equal(t, number("1"), stats.TotalMarkOutPnL(time.Second))
+	assert.Equal(t, number("-1"), stats.TotalMarkOutPnL(time.Minute))
+}
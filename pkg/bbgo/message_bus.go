@@ -0,0 +1,271 @@
+package bbgo
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// MessageType is the value type of a message bus topic
+type MessageType string
+
+const (
+	MessageTypeString MessageType = "string"
+	MessageTypeNumber MessageType = "number"
+	MessageTypeBool   MessageType = "bool"
+)
+
+// MessageTopicConfig declares a typed topic, the producers and the consumers are the strategy instance IDs (e.g., scmaker:BTCUSDT),
+// any strategy instance can publish or subscribe when the list is empty.
+type MessageTopicConfig struct {
+	Type      MessageType `json:"type" yaml:"type"`
+	Producers []string    `json:"producers,omitempty" yaml:"producers,omitempty"`
+	Consumers []string    `json:"consumers,omitempty" yaml:"consumers,omitempty"`
+}
+
+// MessageBusConfig declares the topics of the in-process message bus between the strategies
+//
+//	messageBus:
+//	  topics:
+//	    regime:
+//	      type: string
+//	      producers: [ "supertrend:BTCUSDT" ]
+//	      consumers: [ "scmaker:BTCUSDT" ]
+type MessageBusConfig struct {
+	Topics map[string]MessageTopicConfig `json:"topics" yaml:"topics"`
+}
+
+func (c *MessageBusConfig) Validate() error {
+	for name, topic := range c.Topics {
+		switch topic.Type {
+		case MessageTypeString, MessageTypeNumber, MessageTypeBool:
+		default:
+			return fmt.Errorf("messageBus: topic %s has invalid type %q, valid types are: %s, %s, %s",
+				name, topic.Type, MessageTypeString, MessageTypeNumber, MessageTypeBool)
+		}
+	}
+
+	return nil
+}
+
+// Message is a value published to a topic
+type Message struct {
+	Topic     string      `json:"topic"`
+	Type      MessageType `json:"type"`
+	Publisher string      `json:"publisher"`
+	Value     interface{} `json:"value"`
+	Time      time.Time   `json:"time"`
+}
+
+// String returns the value of the string topic message
+func (m Message) String() string {
+	s, _ := m.Value.(string)
+	return s
+}
+
+// Number returns the value of the number topic message
+func (m Message) Number() fixedpoint.Value {
+	v, _ := m.Value.(fixedpoint.Value)
+	return v
+}
+
+// Bool returns the value of the bool topic message
+func (m Message) Bool() bool {
+	b, _ := m.Value.(bool)
+	return b
+}
+
+type MessageHandler func(msg Message)
+
+type messageSubscriber struct {
+	consumer string
+	handler  MessageHandler
+}
+
+type messageTopic struct {
+	config      MessageTopicConfig
+	subscribers []messageSubscriber
+	last        *Message
+}
+
+// MessageBus is the in-process publish/subscribe bus between the strategy instances,
+// for example, a trend strategy publishes the market regime, and a market maker subscribes it to pause the quotes.
+// The last message of each topic is retained and delivered to the late subscribers.
+type MessageBus struct {
+	mu     sync.Mutex
+	topics map[string]*messageTopic
+}
+
+func NewMessageBus(config *MessageBusConfig) *MessageBus {
+	bus := &MessageBus{
+		topics: make(map[string]*messageTopic),
+	}
+
+	if config != nil {
+		for name, topic := range config.Topics {
+			bus.topics[name] = &messageTopic{config: topic}
+		}
+	}
+
+	return bus
+}
+
+// DeclareTopic declares the topic at runtime, it returns an error if the topic is declared with another type
+func (b *MessageBus) DeclareTopic(name string, config MessageTopicConfig) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if topic, ok := b.topics[name]; ok {
+		if topic.config.Type != config.Type {
+			return fmt.Errorf("message topic %s is already declared as %s", name, topic.config.Type)
+		}
+
+		return nil
+	}
+
+	b.topics[name] = &messageTopic{config: config}
+	return nil
+}
+
+// Publish publishes the value to the topic, the handlers of the subscribers are called synchronously
+func (b *MessageBus) Publish(publisher, topicName string, value interface{}) error {
+	b.mu.Lock()
+	topic, ok := b.topics[topicName]
+	if !ok {
+		b.mu.Unlock()
+		return fmt.Errorf("message topic %s is not declared", topicName)
+	}
+
+	if !containsOrEmpty(topic.config.Producers, publisher) {
+		b.mu.Unlock()
+		return fmt.Errorf("%s is not a producer of the message topic %s", publisher, topicName)
+	}
+
+	value, err := convertMessageValue(topic.config.Type, value)
+	if err != nil {
+		b.mu.Unlock()
+		return fmt.Errorf("message topic %s: %w", topicName, err)
+	}
+
+	msg := Message{
+		Topic:     topicName,
+		Type:      topic.config.Type,
+		Publisher: publisher,
+		Value:     value,
+		Time:      time.Now(),
+	}
+	topic.last = &msg
+
+	subscribers := make([]messageSubscriber, len(topic.subscribers))
+	copy(subscribers, topic.subscribers)
+	b.mu.Unlock()
+
+	for _, subscriber := range subscribers {
+		subscriber.handler(msg)
+	}
+
+	return nil
+}
+
+// Subscribe registers the handler of the consumer on the topic,
+// the retained message is delivered to the handler immediately if the topic was published before.
+func (b *MessageBus) Subscribe(consumer, topicName string, handler MessageHandler) error {
+	b.mu.Lock()
+	topic, ok := b.topics[topicName]
+	if !ok {
+		b.mu.Unlock()
+		return fmt.Errorf("message topic %s is not declared", topicName)
+	}
+
+	if !containsOrEmpty(topic.config.Consumers, consumer) {
+		b.mu.Unlock()
+		return fmt.Errorf("%s is not a consumer of the message topic %s", consumer, topicName)
+	}
+
+	topic.subscribers = append(topic.subscribers, messageSubscriber{consumer: consumer, handler: handler})
+	last := topic.last
+	b.mu.Unlock()
+
+	if last != nil {
+		handler(*last)
+	}
+
+	return nil
+}
+
+// Unsubscribe removes all the handlers of the consumer
+func (b *MessageBus) Unsubscribe(consumer string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, topic := range b.topics {
+		subscribers := topic.subscribers[:0]
+		for _, subscriber := range topic.subscribers {
+			if subscriber.consumer != consumer {
+				subscribers = append(subscribers, subscriber)
+			}
+		}
+		topic.subscribers = subscribers
+	}
+}
+
+// Last returns the last message published to the topic
+func (b *MessageBus) Last(topicName string) (Message, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if topic, ok := b.topics[topicName]; ok && topic.last != nil {
+		return *topic.last, true
+	}
+
+	return Message{}, false
+}
+
+func containsOrEmpty(list []string, s string) bool {
+	if len(list) == 0 {
+		return true
+	}
+
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+func convertMessageValue(messageType MessageType, value interface{}) (interface{}, error) {
+	switch messageType {
+	case MessageTypeString:
+		switch v := value.(type) {
+		case string:
+			return v, nil
+		case fmt.Stringer:
+			return v.String(), nil
+		}
+
+	case MessageTypeNumber:
+		switch v := value.(type) {
+		case fixedpoint.Value:
+			return v, nil
+		case float64:
+			return fixedpoint.NewFromFloat(v), nil
+		case int:
+			return fixedpoint.NewFromInt(int64(v)), nil
+		case int64:
+			return fixedpoint.NewFromInt(v), nil
+		case string:
+			return fixedpoint.NewFromString(v)
+		}
+
+	case MessageTypeBool:
+		if v, ok := value.(bool); ok {
+			return v, nil
+		}
+	}
+
+	return nil, fmt.Errorf("value %v (%T) can not be converted to %s", value, value, messageType)
+}
@@ -0,0 +1,60 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMessageBus(t *testing.T) {
+	bus := NewMessageBus(&MessageBusConfig{
+		Topics: map[string]MessageTopicConfig{
+			"regime": {
+				Type:      MessageTypeString,
+				Producers: []string{"supertrend:BTCUSDT"},
+				Consumers: []string{"scmaker:BTCUSDT"},
+			},
+			"spread": {Type: MessageTypeNumber},
+		},
+	})
+
+	var regimes []string
+	assert.NoError(t, bus.Subscribe("scmaker:BTCUSDT", "regime", func(msg Message) {
+		regimes = append(regimes, msg.String())
+	}))
+
+	assert.Error(t, bus.Subscribe("grid2:BTCUSDT", "regime", func(msg Message) {}), "grid2 is not a consumer")
+	assert.Error(t, bus.Subscribe("scmaker:BTCUSDT", "undeclared", func(msg Message) {}))
+
+	assert.NoError(t, bus.Publish("supertrend:BTCUSDT", "regime", "trending"))
+	assert.Error(t, bus.Publish("scmaker:BTCUSDT", "regime", "chop"), "scmaker is not a producer")
+	assert.Error(t, bus.Publish("supertrend:BTCUSDT", "regime", 1.0), "float is not a string")
+	assert.Equal(t, []string{"trending"}, regimes)
+
+	// the number topic converts the numeric values to fixedpoint
+	assert.NoError(t, bus.Publish("xmaker:BTCUSDT", "spread", 0.5))
+	msg, ok := bus.Last("spread")
+	if assert.True(t, ok) {
+		assert.Equal(t, "0.5", msg.Number().String())
+		assert.Equal(t, "xmaker:BTCUSDT", msg.Publisher)
+	}
+
+	t.Run("retained message", func(t *testing.T) {
+		var spread string
+		assert.NoError(t, bus.Subscribe("scmaker:BTCUSDT", "spread", func(msg Message) {
+			spread = msg.Number().String()
+		}))
+		assert.Equal(t, "0.5", spread)
+	})
+
+	t.Run("unsubscribe", func(t *testing.T) {
+		bus.Unsubscribe("scmaker:BTCUSDT")
+		assert.NoError(t, bus.Publish("supertrend:BTCUSDT", "regime", "chop"))
+		assert.Equal(t, []string{"trending"}, regimes)
+	})
+}
+
+func TestMessageBusConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&MessageBusConfig{Topics: map[string]MessageTopicConfig{"a": {Type: MessageTypeBool}}}).Validate())
+	assert.Error(t, (&MessageBusConfig{Topics: map[string]MessageTopicConfig{"a": {Type: "int"}}}).Validate())
+}
@@ -99,6 +99,41 @@ var (
 	)
 )
 
+var (
+	markOutLabels = []string{
+		"strategy_type",
+		"strategy_id",
+		"symbol",
+		"horizon",   // mark-out horizon, e.g. 1s, 5s, 30s, 5m0s
+		"liquidity", // maker or taker
+	}
+
+	metricsMarkOutBps = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "bbgo_markout_bps",
+			Help:    "bbgo post-trade mark-out against the mid price in basis points, negative means adverse selection",
+			Buckets: []float64{-100, -50, -20, -10, -5, -2, -1, 0, 1, 2, 5, 10, 20, 50, 100},
+		},
+		markOutLabels,
+	)
+
+	metricsMarkOutPnL = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bbgo_markout_pnl",
+			Help: "bbgo accumulated post-trade mark-out pnl in quote currency",
+		},
+		[]string{"strategy_type", "strategy_id", "symbol", "horizon"},
+	)
+
+	metricsMarkOutAdverseTrades = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bbgo_markout_adverse_trades_total",
+			Help: "bbgo number of trades with negative post-trade mark-out",
+		},
+		markOutLabels,
+	)
+)
+
 func init() {
 	prometheus.MustRegister(
 		metricsConnectionStatus,
@@ -108,5 +143,8 @@ func init() {
 		metricsTradesTotal,
 		metricsTradingVolume,
 		metricsLastUpdateTimeBalance,
+		metricsMarkOutBps,
+		metricsMarkOutPnL,
+		metricsMarkOutAdverseTrades,
 	)
 }
@@ -0,0 +1,351 @@
+package bbgo
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"gonum.org/v1/gonum/mat"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type MidPricePredictorModel string
+
+const (
+	// MidPricePredictorModelLinear extrapolates the least squares line of the recent microprices
+	MidPricePredictorModelLinear MidPricePredictorModel = "linear"
+
+	// MidPricePredictorModelAR fits an auto-regressive model over the recent microprice changes
+	MidPricePredictorModelAR MidPricePredictorModel = "ar"
+)
+
+const (
+	defaultMidPricePredictorWindow  = 50
+	defaultMidPricePredictorAROrder = 2
+	defaultMidPricePredictorLatency = 200 * time.Millisecond
+)
+
+// MidPricePredictorConfig configures the short-horizon mid price prediction of the maker strategies
+type MidPricePredictorConfig struct {
+	// Model is either "linear" or "ar", defaults to "linear"
+	Model MidPricePredictorModel `json:"model,omitempty"`
+
+	// Window is the number of the recent microprice samples used for the prediction, defaults to 50
+	Window int `json:"window,omitempty"`
+
+	// Order is the order of the auto-regressive model, defaults to 2
+	Order int `json:"order,omitempty"`
+
+	// Latency is the order placement latency, the quotes are anchored at the predicted mid price after the latency
+	Latency types.Duration `json:"latency,omitempty"`
+
+	// MaxOffset caps the offset of the quote anchor, the offset is not capped if it's zero
+	MaxOffset fixedpoint.Value `json:"maxOffset,omitempty"`
+
+	// Shadow evaluates the predictions without moving the quotes
+	Shadow bool `json:"shadow,omitempty"`
+}
+
+func (c *MidPricePredictorConfig) Validate() error {
+	switch c.Model {
+	case "", MidPricePredictorModelLinear, MidPricePredictorModelAR:
+	default:
+		return fmt.Errorf("unknown mid price predictor model: %s", c.Model)
+	}
+
+	if c.Window < 0 || c.Order < 0 {
+		return fmt.Errorf("mid price predictor window and order can not be negative")
+	}
+
+	return nil
+}
+
+// MidPricePredictionStats is the A/B evaluation of the predictions against the realized microprice after the latency.
+// The baseline is the microprice at the prediction time, which is the anchor without prediction.
+type MidPricePredictionStats struct {
+	Count int `json:"count"`
+
+	PredictedAbsError fixedpoint.Value `json:"predictedAbsError"`
+	BaselineAbsError  fixedpoint.Value `json:"baselineAbsError"`
+
+	// DirectionHits is the number of the predictions that moved the anchor in the direction of the realized price change
+	DirectionHits int `json:"directionHits"`
+}
+
+// Improvement is the reduction ratio of the absolute error of the predictions compared to the baseline,
+// a positive value means the predicted anchor is closer to the realized price.
+func (s MidPricePredictionStats) Improvement() float64 {
+	if s.BaselineAbsError.IsZero() {
+		return 0
+	}
+
+	return 1.0 - s.PredictedAbsError.Div(s.BaselineAbsError).Float64()
+}
+
+func (s MidPricePredictionStats) String() string {
+	return fmt.Sprintf("predictions: %d, predicted abs error: %s, baseline abs error: %s, improvement: %.2f%%, direction hits: %d",
+		s.Count, s.PredictedAbsError.String(), s.BaselineAbsError.String(), s.Improvement()*100.0, s.DirectionHits)
+}
+
+type microPriceSample struct {
+	time  time.Time
+	price float64
+}
+
+type pendingPrediction struct {
+	target    time.Time
+	baseline  float64
+	predicted float64
+}
+
+// MidPricePredictor predicts the mid price after the order placement latency from the recent microprice changes,
+// so that the maker strategies can offset their quote anchor by the expected drift.
+type MidPricePredictor struct {
+	config MidPricePredictorConfig
+
+	mu      sync.Mutex
+	samples []microPriceSample
+	pending []pendingPrediction
+	stats   MidPricePredictionStats
+
+	// clock is the time of the samples from the stream, defaults to the system clock
+	clock Clock
+}
+
+func NewMidPricePredictor(config MidPricePredictorConfig) *MidPricePredictor {
+	if config.Model == "" {
+		config.Model = MidPricePredictorModelLinear
+	}
+
+	if config.Window <= 0 {
+		config.Window = defaultMidPricePredictorWindow
+	}
+
+	if config.Order <= 0 {
+		config.Order = defaultMidPricePredictorAROrder
+	}
+
+	if config.Latency.Duration() <= 0 {
+		config.Latency = types.Duration(defaultMidPricePredictorLatency)
+	}
+
+	return &MidPricePredictor{config: config, clock: SystemClock{}}
+}
+
+// SetClock sets the time source of the stream samples, e.g., the simulated clock of the backtest
+func (p *MidPricePredictor) SetClock(clock Clock) {
+	p.clock = clock
+}
+
+// BindStream samples the microprice from the order book updates of the market data stream
+func (p *MidPricePredictor) BindStream(stream types.Stream, symbol string) {
+	book := types.NewStreamBook(symbol)
+	book.BindStream(stream)
+
+	update := func(_ types.SliceOrderBook) {
+		if bid, ask, ok := book.BestBidAndAsk(); ok {
+			p.Update(p.clock.Now(), bid, ask)
+		}
+	}
+
+	book.OnUpdate(update)
+	book.OnSnapshot(update)
+}
+
+// Update adds the microprice sample of the best bid and ask, and evaluates the due predictions
+func (p *MidPricePredictor) Update(now time.Time, bid, ask types.PriceVolume) {
+	microPrice, ok := calculateMicroPrice(bid, ask)
+	if !ok {
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.samples = append(p.samples, microPriceSample{time: now, price: microPrice})
+	if len(p.samples) > p.config.Window {
+		p.samples = p.samples[len(p.samples)-p.config.Window:]
+	}
+
+	p.evaluate(now, microPrice)
+}
+
+// Predict returns the predicted microprice after the latency, it returns false if there are not enough samples
+func (p *MidPricePredictor) Predict() (fixedpoint.Value, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	predicted, _, ok := p.predict()
+	if !ok {
+		return fixedpoint.Zero, false
+	}
+
+	return fixedpoint.NewFromFloat(predicted), true
+}
+
+// Anchor offsets the quote anchor by the expected drift over the placement latency,
+// the prediction is recorded for the evaluation. The anchor is returned as it is in the shadow mode.
+func (p *MidPricePredictor) Anchor(now time.Time, anchor fixedpoint.Value) fixedpoint.Value {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	predicted, current, ok := p.predict()
+	if !ok {
+		return anchor
+	}
+
+	p.pending = append(p.pending, pendingPrediction{
+		target:    now.Add(p.config.Latency.Duration()),
+		baseline:  current,
+		predicted: predicted,
+	})
+
+	if p.config.Shadow {
+		return anchor
+	}
+
+	offset := fixedpoint.NewFromFloat(predicted - current)
+	if p.config.MaxOffset.Sign() > 0 {
+		offset = fixedpoint.Max(fixedpoint.Min(offset, p.config.MaxOffset), p.config.MaxOffset.Neg())
+	}
+
+	return anchor.Add(offset)
+}
+
+// Stats returns the evaluation of the recorded predictions
+func (p *MidPricePredictor) Stats() MidPricePredictionStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.stats
+}
+
+func (p *MidPricePredictor) evaluate(now time.Time, realized float64) {
+	i := 0
+	for ; i < len(p.pending); i++ {
+		prediction := p.pending[i]
+		if now.Before(prediction.target) {
+			break
+		}
+
+		p.stats.Count++
+		p.stats.PredictedAbsError = p.stats.PredictedAbsError.Add(fixedpoint.NewFromFloat(math.Abs(realized - prediction.predicted)))
+		p.stats.BaselineAbsError = p.stats.BaselineAbsError.Add(fixedpoint.NewFromFloat(math.Abs(realized - prediction.baseline)))
+
+		if (prediction.predicted-prediction.baseline)*(realized-prediction.baseline) > 0 {
+			p.stats.DirectionHits++
+		}
+	}
+
+	p.pending = p.pending[i:]
+}
+
+// predict returns the predicted microprice and the current microprice
+func (p *MidPricePredictor) predict() (predicted, current float64, ok bool) {
+	if len(p.samples) < 2 {
+		return 0, 0, false
+	}
+
+	current = p.samples[len(p.samples)-1].price
+	latency := p.config.Latency.Duration()
+
+	var drift float64
+	switch p.config.Model {
+	case MidPricePredictorModelAR:
+		drift, ok = predictARDrift(p.samples, p.config.Order, latency)
+	default:
+		drift, ok = predictLinearDrift(p.samples, latency)
+	}
+
+	if !ok || math.IsNaN(drift) || math.IsInf(drift, 0) {
+		return 0, 0, false
+	}
+
+	return current + drift, current, true
+}
+
+// calculateMicroPrice weights the best prices by the volume of the opposite side
+func calculateMicroPrice(bid, ask types.PriceVolume) (float64, bool) {
+	bidVolume, askVolume := bid.Volume.Float64(), ask.Volume.Float64()
+	if bidVolume+askVolume <= 0 || bid.Price.IsZero() || ask.Price.IsZero() {
+		return 0, false
+	}
+
+	return (bid.Price.Float64()*askVolume + ask.Price.Float64()*bidVolume) / (bidVolume + askVolume), true
+}
+
+// predictLinearDrift extrapolates the least squares line of the samples over the latency
+func predictLinearDrift(samples []microPriceSample, latency time.Duration) (float64, bool) {
+	t0 := samples[0].time
+	n := float64(len(samples))
+
+	var sumX, sumY, sumXY, sumXX float64
+	for _, sample := range samples {
+		x := sample.time.Sub(t0).Seconds()
+		sumX += x
+		sumY += sample.price
+		sumXY += x * sample.price
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0, false
+	}
+
+	slope := (n*sumXY - sumX*sumY) / denominator
+	return slope * latency.Seconds(), true
+}
+
+// predictARDrift fits the auto-regressive model of the given order over the price changes with the least squares,
+// and sums the predicted changes of the steps within the latency, the step is the average sample interval.
+func predictARDrift(samples []microPriceSample, order int, latency time.Duration) (float64, bool) {
+	changes := make([]float64, len(samples)-1)
+	for i := 1; i < len(samples); i++ {
+		changes[i-1] = samples[i].price - samples[i-1].price
+	}
+
+	rows := len(changes) - order
+	if rows < order+1 {
+		return 0, false
+	}
+
+	x := mat.NewDense(rows, order, nil)
+	y := mat.NewVecDense(rows, nil)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < order; c++ {
+			x.Set(r, c, changes[r+order-1-c])
+		}
+		y.SetVec(r, changes[r+order])
+	}
+
+	var coefficients mat.VecDense
+	if err := coefficients.SolveVec(x, y); err != nil {
+		return 0, false
+	}
+
+	interval := samples[len(samples)-1].time.Sub(samples[0].time) / time.Duration(len(samples)-1)
+	steps := 1
+	if interval > 0 {
+		steps = int(math.Round(float64(latency) / float64(interval)))
+		if steps < 1 {
+			steps = 1
+		}
+	}
+
+	history := append([]float64{}, changes[len(changes)-order:]...)
+	var drift float64
+	for s := 0; s < steps; s++ {
+		var change float64
+		for c := 0; c < order; c++ {
+			change += coefficients.AtVec(c) * history[len(history)-1-c]
+		}
+
+		drift += change
+		history = append(history, change)
+	}
+
+	return drift, true
+}
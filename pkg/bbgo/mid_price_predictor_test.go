@@ -0,0 +1,102 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func updateMidPricePredictor(p *MidPricePredictor, now time.Time, mid float64) {
+	p.Update(now,
+		types.PriceVolume{Price: fixedpoint.NewFromFloat(mid - 0.5), Volume: fixedpoint.One},
+		types.PriceVolume{Price: fixedpoint.NewFromFloat(mid + 0.5), Volume: fixedpoint.One})
+}
+
+func TestMidPricePredictor_Linear(t *testing.T) {
+	p := NewMidPricePredictor(MidPricePredictorConfig{
+		Model:   MidPricePredictorModelLinear,
+		Latency: types.Duration(time.Second),
+	})
+
+	now := time.Now()
+	_, ok := p.Predict()
+	assert.False(t, ok)
+
+	// the mid price goes up 2 per second
+	for i := 0; i < 10; i++ {
+		updateMidPricePredictor(p, now.Add(time.Duration(i)*time.Second), 100.0+2.0*float64(i))
+	}
+
+	predicted, ok := p.Predict()
+	if assert.True(t, ok) {
+		assert.InDelta(t, 120.0, predicted.Float64(), 1e-6)
+	}
+
+	now = now.Add(9 * time.Second)
+	assert.InDelta(t, 102.0, p.Anchor(now, fixedpoint.NewFromFloat(100.0)).Float64(), 1e-6)
+
+	// the realized price follows the trend, the prediction beats the baseline
+	updateMidPricePredictor(p, now.Add(time.Second), 120.0)
+	stats := p.Stats()
+	assert.Equal(t, 1, stats.Count)
+	assert.Equal(t, 1, stats.DirectionHits)
+	assert.InDelta(t, 0.0, stats.PredictedAbsError.Float64(), 1e-6)
+	assert.InDelta(t, 2.0, stats.BaselineAbsError.Float64(), 1e-6)
+	assert.InDelta(t, 1.0, stats.Improvement(), 1e-6)
+}
+
+func TestMidPricePredictor_AR(t *testing.T) {
+	p := NewMidPricePredictor(MidPricePredictorConfig{
+		Model:   MidPricePredictorModelAR,
+		Order:   1,
+		Latency: types.Duration(time.Second),
+	})
+
+	// the price changes alternate between +1 and -1
+	now := time.Now()
+	mid := 100.0
+	for i := 0; i < 11; i++ {
+		updateMidPricePredictor(p, now.Add(time.Duration(i)*time.Second), mid)
+		if i%2 == 0 {
+			mid += 1.0
+		} else {
+			mid -= 1.0
+		}
+	}
+
+	// the last change is -1, the next change is predicted to be +1
+	predicted, ok := p.Predict()
+	if assert.True(t, ok) {
+		assert.InDelta(t, 101.0, predicted.Float64(), 1e-6)
+	}
+}
+
+func TestMidPricePredictor_Shadow(t *testing.T) {
+	p := NewMidPricePredictor(MidPricePredictorConfig{
+		Latency:   types.Duration(time.Second),
+		MaxOffset: fixedpoint.One,
+		Shadow:    true,
+	})
+
+	now := time.Now()
+	for i := 0; i < 5; i++ {
+		updateMidPricePredictor(p, now.Add(time.Duration(i)*time.Second), 100.0+2.0*float64(i))
+	}
+
+	now = now.Add(4 * time.Second)
+	assert.Equal(t, "100", p.Anchor(now, fixedpoint.NewFromInt(100)).String())
+
+	// the prediction is still evaluated in the shadow mode, the realized price reverts
+	updateMidPricePredictor(p, now.Add(time.Second), 107.0)
+	stats := p.Stats()
+	assert.Equal(t, 1, stats.Count)
+	assert.Equal(t, 0, stats.DirectionHits)
+	assert.True(t, stats.Improvement() < 0)
+
+	p.config.Shadow = false
+	assert.Equal(t, "101", p.Anchor(now, fixedpoint.NewFromInt(100)).String())
+}
@@ -306,7 +306,7 @@ func BatchPlaceOrder(ctx context.Context, exchange types.Exchange, orderCallback
 
 	var errIndexes []int
 	for i, submitOrder := range submitOrders {
-		createdOrder, err2 := exchange.SubmitOrder(ctx, submitOrder)
+		createdOrder, err2 := submitOrderWithSpan(ctx, exchange, submitOrder)
 		if err2 != nil {
 			err = multierr.Append(err, err2)
 			errIndexes = append(errIndexes, i)
@@ -368,7 +368,7 @@ batchRetryOrder:
 
 			op := func() error {
 				// can allocate permanent error backoff.Permanent(err) to stop backoff
-				createdOrder, err2 := exchange.SubmitOrder(timeoutCtx, submitOrder)
+				createdOrder, err2 := submitOrderWithSpan(timeoutCtx, exchange, submitOrder)
 				if err2 != nil {
 					logger.WithError(err2).Errorf("submit order error: %s", submitOrder.String())
 				}
@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
 	log "github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/multierr"
 
 	"github.com/c9s/bbgo/pkg/exchange/retry"
@@ -25,9 +28,15 @@ var quantityReduceDelta = fixedpoint.NewFromFloat(0.005)
 // This is for the maximum retries
 const submitOrderRetryLimit = 5
 
+// maxNumOfRecentTrades is the maximum number of the recent trades kept by the order executor
+const maxNumOfRecentTrades = 100
+
 // GeneralOrderExecutor implements the general order executor for strategy
+//
+//go:generate callbackgen -type GeneralOrderExecutor
 type GeneralOrderExecutor struct {
 	session            *ExchangeSession
+	environ            *Environment
 	symbol             string
 	strategy           string
 	strategyInstanceID string
@@ -35,6 +44,7 @@ type GeneralOrderExecutor struct {
 	activeMakerOrders  *ActiveOrderBook
 	orderStore         *OrderStore
 	tradeCollector     *TradeCollector
+	orderSpans         *orderSpanTracker
 
 	logger log.FieldLogger
 
@@ -42,6 +52,20 @@ type GeneralOrderExecutor struct {
 
 	maxRetries    uint
 	disableNotify bool
+
+	recentTrades   []types.Trade
+	recentTradesMu sync.Mutex
+
+	// postOnlyRetry reprices the rejected LimitMaker orders when it's enabled
+	postOnlyRetry *PostOnlyRetryOptions
+
+	postOnlyRetryExhaustedCallbacks []func(order types.SubmitOrder, err error)
+
+	// autoRepayMu serializes the margin auto-repay runs
+	autoRepayMu sync.Mutex
+
+	// positionSide is the position side of the futures hedge mode, the executor tracks only one side of the position
+	positionSide types.PositionSide
 }
 
 func NewGeneralOrderExecutor(session *ExchangeSession, symbol, strategy, strategyInstanceID string, position *types.Position) *GeneralOrderExecutor {
@@ -60,8 +84,12 @@ func NewGeneralOrderExecutor(session *ExchangeSession, symbol, strategy, strateg
 		activeMakerOrders:  NewActiveOrderBook(symbol),
 		orderStore:         orderStore,
 		tradeCollector:     NewTradeCollector(symbol, position, orderStore),
+		orderSpans:         newOrderSpanTracker(),
 	}
 
+	// convert the fees paid in the other currency with the session prices by default
+	executor.tradeCollector.SetFeePriceSource(session)
+
 	if session.Margin {
 		executor.startMarginAssetUpdater(context.Background())
 	}
@@ -69,6 +97,28 @@ func NewGeneralOrderExecutor(session *ExchangeSession, symbol, strategy, strateg
 	return executor
 }
 
+// SetFeePriceSource overrides the price source for converting the fees paid in the other currency (e.g., BNB)
+// into the quote currency, the session last prices are used by default.
+func (e *GeneralOrderExecutor) SetFeePriceSource(source types.FeePriceSource) {
+	e.tradeCollector.SetFeePriceSource(source)
+}
+
+// EnableTradeRecovery keeps the position correct during the websocket instability:
+// the matched trades are held for the reorder delay and applied by the trade time,
+// and the missing trades of the orders are queried from the REST API after the gap timeout.
+// The trade collector runs in the background until the context is canceled.
+func (e *GeneralOrderExecutor) EnableTradeRecovery(ctx context.Context, reorderDelay, gapTimeout time.Duration) {
+	e.tradeCollector.SetReorderDelay(reorderDelay)
+
+	if service, ok := e.session.Exchange.(types.ExchangeTradeHistoryService); ok {
+		e.tradeCollector.EnableGapRecovery(service, gapTimeout)
+	} else {
+		log.Warnf("exchange %s does not support the trade history service, the trade gap recovery is disabled", e.session.ExchangeName)
+	}
+
+	go e.tradeCollector.Run(ctx)
+}
+
 func (e *GeneralOrderExecutor) DisableNotify() {
 	e.disableNotify = true
 }
@@ -77,6 +127,17 @@ func (e *GeneralOrderExecutor) SetMaxRetries(maxRetries uint) {
 	e.maxRetries = maxRetries
 }
 
+// SetPositionSide sets the position side for the futures hedge mode (dual-side position mode),
+// the submitted orders are sent with the position side, and the position of the executor is the position of that side.
+// To trade both the long and the short positions of the same symbol, use one executor for each side.
+func (e *GeneralOrderExecutor) SetPositionSide(side types.PositionSide) {
+	e.positionSide = side
+}
+
+func (e *GeneralOrderExecutor) PositionSide() types.PositionSide {
+	return e.positionSide
+}
+
 func (e *GeneralOrderExecutor) startMarginAssetUpdater(ctx context.Context) {
 	marginService, ok := e.session.Exchange.(types.MarginBorrowRepayService)
 	if !ok {
@@ -130,6 +191,7 @@ func (e *GeneralOrderExecutor) ActiveMakerOrders() *ActiveOrderBook {
 }
 
 func (e *GeneralOrderExecutor) BindEnvironment(environ *Environment) {
+	e.environ = environ
 	e.tradeCollector.OnProfit(func(trade types.Trade, profit *types.Profit) {
 		environ.RecordPosition(e.position, trade, profit)
 	})
@@ -146,6 +208,10 @@ func (e *GeneralOrderExecutor) BindTradeStats(tradeStats *types.TradeStats) {
 }
 
 func (e *GeneralOrderExecutor) BindProfitStats(profitStats *types.ProfitStats) {
+	if e.session != nil {
+		e.session.bindDustSweepProfitStats(profitStats)
+	}
+
 	e.tradeCollector.OnProfit(func(trade types.Trade, profit *types.Profit) {
 		profitStats.AddTrade(trade)
 		if profit == nil {
@@ -157,6 +223,7 @@ func (e *GeneralOrderExecutor) BindProfitStats(profitStats *types.ProfitStats) {
 		if !e.disableNotify {
 			Notify(profit)
 			Notify(profitStats)
+			e.notifyProfitChart(trade)
 		}
 	})
 }
@@ -164,6 +231,16 @@ func (e *GeneralOrderExecutor) BindProfitStats(profitStats *types.ProfitStats) {
 func (e *GeneralOrderExecutor) Bind() {
 	e.activeMakerOrders.BindStream(e.session.UserDataStream)
 	e.orderStore.BindStream(e.session.UserDataStream)
+	e.orderSpans.BindStream(e.session.UserDataStream)
+
+	e.tradeCollector.OnTrade(func(trade types.Trade, profit, netProfit fixedpoint.Value) {
+		e.recentTradesMu.Lock()
+		e.recentTrades = append(e.recentTrades, trade)
+		if len(e.recentTrades) > maxNumOfRecentTrades {
+			e.recentTrades = e.recentTrades[len(e.recentTrades)-maxNumOfRecentTrades:]
+		}
+		e.recentTradesMu.Unlock()
+	})
 
 	if !e.disableNotify {
 		// trade notify
@@ -177,11 +254,39 @@ func (e *GeneralOrderExecutor) Bind() {
 		})
 	}
 
+	if e.session.Margin && e.session.AutoRepay {
+		e.tradeCollector.OnTrade(func(trade types.Trade, profit, netProfit fixedpoint.Value) {
+			e.autoRepayMarginDebts(trade)
+		})
+	}
+
 	e.tradeCollector.BindStream(e.session.UserDataStream)
 }
 
+// RecordDecision records the reason of the strategy decision into the session event log when the session recording is enabled
+func (e *GeneralOrderExecutor) RecordDecision(message string, fields map[string]interface{}) {
+	e.recordDecision(types.StrategyDecision{
+		Action:  types.StrategyDecisionNote,
+		Message: message,
+		Fields:  fields,
+	})
+}
+
+func (e *GeneralOrderExecutor) recordDecision(decision types.StrategyDecision) {
+	if e.environ == nil || e.environ.sessionRecorder == nil {
+		return
+	}
+
+	decision.Strategy = e.strategy
+	decision.StrategyInstanceID = e.strategyInstanceID
+	decision.Symbol = e.symbol
+	e.environ.sessionRecorder.RecordDecision(e.session.Name, decision)
+}
+
 // CancelOrders cancels the given order objects directly
 func (e *GeneralOrderExecutor) CancelOrders(ctx context.Context, orders ...types.Order) error {
+	e.recordDecision(types.StrategyDecision{Action: types.StrategyDecisionCancel, Orders: orders})
+
 	err := e.session.Exchange.CancelOrders(ctx, orders...)
 	if err != nil { // Retry once
 		err = e.session.Exchange.CancelOrders(ctx, orders...)
@@ -193,18 +298,60 @@ func (e *GeneralOrderExecutor) SetLogger(logger log.FieldLogger) {
 	e.logger = logger
 }
 
-func (e *GeneralOrderExecutor) SubmitOrders(ctx context.Context, submitOrders ...types.SubmitOrder) (types.OrderSlice, error) {
+func (e *GeneralOrderExecutor) SubmitOrders(ctx context.Context, submitOrders ...types.SubmitOrder) (createdOrders types.OrderSlice, err error) {
+	submitTime := e.environ.Clock().Now()
+	ctx, span := Tracer().Start(ctx, "bbgo.SubmitOrders", trace.WithAttributes(
+		attribute.String("strategy", e.strategy),
+		attribute.String("strategy_instance_id", e.strategyInstanceID),
+		attribute.String("exchange", e.session.ExchangeName.String()),
+		attribute.String("symbol", e.symbol),
+		attribute.Int("orders", len(submitOrders)),
+	))
+	defer func() {
+		recordSpanError(span, err)
+		span.End()
+	}()
+
 	formattedOrders, err := e.session.FormatOrders(submitOrders)
 	if err != nil {
 		return nil, err
 	}
 
+	if e.positionSide != "" {
+		for i := range formattedOrders {
+			if formattedOrders[i].PositionSide == "" {
+				formattedOrders[i].PositionSide = e.positionSide
+			}
+		}
+	}
+
+	e.recordDecision(types.StrategyDecision{Action: types.StrategyDecisionSubmit, SubmitOrders: formattedOrders})
+
 	orderCreateCallback := func(createdOrder types.Order) {
+		e.orderSpans.Start(ctx, submitTime, createdOrder)
 		e.orderStore.Add(createdOrder)
 		e.activeMakerOrders.Add(createdOrder)
 		e.tradeCollector.Process()
 	}
 
+	if e.postOnlyRetry != nil {
+		var postOnlyOrders []types.SubmitOrder
+		postOnlyOrders, formattedOrders = splitPostOnlyOrders(formattedOrders)
+		if len(postOnlyOrders) > 0 {
+			createdOrders, err := e.submitPostOnlyOrders(ctx, orderCreateCallback, postOnlyOrders...)
+			if len(formattedOrders) == 0 {
+				return createdOrders, err
+			}
+
+			createdOrders2, err2 := e.submitOrders(ctx, orderCreateCallback, formattedOrders)
+			return append(createdOrders, createdOrders2...), multierr.Append(err, err2)
+		}
+	}
+
+	return e.submitOrders(ctx, orderCreateCallback, formattedOrders)
+}
+
+func (e *GeneralOrderExecutor) submitOrders(ctx context.Context, orderCreateCallback OrderCallback, formattedOrders []types.SubmitOrder) (types.OrderSlice, error) {
 	if e.maxRetries == 0 {
 		createdOrders, _, err := BatchPlaceOrder(ctx, e.session.Exchange, orderCreateCallback, formattedOrders...)
 		return createdOrders, err
@@ -290,11 +437,16 @@ func (e *GeneralOrderExecutor) reduceQuantityAndSubmitOrder(ctx context.Context,
 // @return *types.SubmitOrder: SubmitOrder with calculated quantity and price.
 // @return error: Error message.
 func (e *GeneralOrderExecutor) NewOrderFromOpenPosition(ctx context.Context, options *OpenPositionOptions) (*types.SubmitOrder, error) {
+	if (e.positionSide == types.PositionSideLong && options.Short) || (e.positionSide == types.PositionSideShort && options.Long) {
+		return nil, fmt.Errorf("can not open the opposite position with the %s position side executor", e.positionSide)
+	}
+
 	price := options.Price
 	submitOrder := types.SubmitOrder{
 		Symbol:           e.position.Symbol,
 		Type:             types.OrderTypeMarket,
 		MarginSideEffect: types.SideEffectTypeMarginBuy,
+		PositionSide:     e.positionSide,
 		Tag:              strings.Join(options.Tags, ","),
 	}
 
@@ -438,6 +590,8 @@ func (e *GeneralOrderExecutor) GracefulCancelActiveOrderBook(ctx context.Context
 
 // GracefulCancel cancels all active maker orders if orders are not given, otherwise cancel all the given orders
 func (e *GeneralOrderExecutor) GracefulCancel(ctx context.Context, orders ...types.Order) error {
+	e.recordDecision(types.StrategyDecision{Action: types.StrategyDecisionCancel, Orders: orders})
+
 	if err := e.activeMakerOrders.GracefulCancel(ctx, e.session.Exchange, orders...); err != nil {
 		return errors.Wrap(err, "graceful cancel error")
 	}
@@ -445,8 +599,59 @@ func (e *GeneralOrderExecutor) GracefulCancel(ctx context.Context, orders ...typ
 	return nil
 }
 
+// CancelOrdersOnShutdown cancels all the active maker orders, it's used as the shutdown hook of the cancel orders phase,
+// the orders that are still active after the cancellation are returned in the CancelOrdersError
+//
+//	bbgo.OnShutdownPhase(ctx, bbgo.ShutdownPhaseCancelOrders, s.InstanceID(), s.orderExecutor.CancelOrdersOnShutdown)
+func (e *GeneralOrderExecutor) CancelOrdersOnShutdown(ctx context.Context) error {
+	err := e.GracefulCancel(ctx)
+	if orders := e.activeMakerOrders.Orders(); len(orders) > 0 {
+		return &CancelOrdersError{Orders: orders, Err: err}
+	}
+
+	return err
+}
+
 var ErrPositionAlreadyClosing = errors.New("position is already in closing process")
 
+// NewClosePositionOrder creates the market order that closes the current position by a percentage.
+// For the futures session, the order is a reduce-only order, so the exit order never opens the opposite position.
+// It returns nil if the quantity to close is less than the min quantity of the market.
+func (e *GeneralOrderExecutor) NewClosePositionOrder(percentage fixedpoint.Value) (*types.SubmitOrder, error) {
+	submitOrder := e.position.NewMarketCloseOrder(percentage)
+	if submitOrder == nil {
+		return nil, nil
+	}
+
+	if e.session.Futures {
+		// in the hedge mode, reduceOnly can not be used, the order with the position side reduces the position of that side
+		submitOrder.PositionSide = e.positionSide
+		submitOrder.ReduceOnly = !e.positionSide.IsHedge()
+		return submitOrder, nil
+	}
+
+	// Spot and spot margin
+	// check base balance and adjust the close position order
+	if e.position.IsLong() {
+		if baseBalance, ok := e.session.Account.Balance(e.position.Market.BaseCurrency); ok {
+			submitOrder.Quantity = fixedpoint.Min(submitOrder.Quantity, baseBalance.Available)
+		}
+		if submitOrder.Quantity.IsZero() {
+			return nil, fmt.Errorf("insufficient base balance, can not sell: %+v", submitOrder)
+		}
+	} else if e.position.IsShort() {
+		// TODO: check quote balance here, we also need the current price to validate, need to design.
+		/*
+			if quoteBalance, ok := e.session.Account.Balance(e.position.Market.QuoteCurrency); ok {
+				// AdjustQuantityByMaxAmount(submitOrder.Quantity, quoteBalance.Available)
+				// submitOrder.Quantity = fixedpoint.Min(submitOrder.Quantity,)
+			}
+		*/
+	}
+
+	return submitOrder, nil
+}
+
 // ClosePosition closes the current position by a percentage.
 // percentage 0.1 means close 10% position
 // tag is the order tag you want to attach, you may pass multiple tags, the tags will be combined into one tag string by commas.
@@ -456,43 +661,13 @@ func (e *GeneralOrderExecutor) ClosePosition(ctx context.Context, percentage fix
 	}
 	defer e.position.SetClosing(false)
 
-	submitOrder := e.position.NewMarketCloseOrder(percentage)
-	if submitOrder == nil {
+	submitOrder, err := e.NewClosePositionOrder(percentage)
+	if err != nil {
+		return err
+	} else if submitOrder == nil {
 		return nil
 	}
 
-	if e.session.Futures { // Futures: Use base qty in e.position
-		submitOrder.Quantity = e.position.GetBase().Abs()
-		submitOrder.ReduceOnly = true
-
-		if e.position.IsLong() {
-			submitOrder.Side = types.SideTypeSell
-		} else if e.position.IsShort() {
-			submitOrder.Side = types.SideTypeBuy
-		} else {
-			return fmt.Errorf("unexpected position side: %+v", e.position)
-		}
-
-	} else { // Spot and spot margin
-		// check base balance and adjust the close position order
-		if e.position.IsLong() {
-			if baseBalance, ok := e.session.Account.Balance(e.position.Market.BaseCurrency); ok {
-				submitOrder.Quantity = fixedpoint.Min(submitOrder.Quantity, baseBalance.Available)
-			}
-			if submitOrder.Quantity.IsZero() {
-				return fmt.Errorf("insufficient base balance, can not sell: %+v", submitOrder)
-			}
-		} else if e.position.IsShort() {
-			// TODO: check quote balance here, we also need the current price to validate, need to design.
-			/*
-				if quoteBalance, ok := e.session.Account.Balance(e.position.Market.QuoteCurrency); ok {
-					// AdjustQuantityByMaxAmount(submitOrder.Quantity, quoteBalance.Available)
-					// submitOrder.Quantity = fixedpoint.Min(submitOrder.Quantity,)
-				}
-			*/
-		}
-	}
-
 	tagStr := strings.Join(tags, ",")
 	submitOrder.Tag = tagStr
 
@@ -516,6 +691,16 @@ func (e *GeneralOrderExecutor) ClosePosition(ctx context.Context, percentage fix
 	return nil
 }
 
+// RecentTrades returns a copy of the recent trades collected by the order executor
+func (e *GeneralOrderExecutor) RecentTrades() []types.Trade {
+	e.recentTradesMu.Lock()
+	defer e.recentTradesMu.Unlock()
+
+	trades := make([]types.Trade, len(e.recentTrades))
+	copy(trades, e.recentTrades)
+	return trades
+}
+
 func (e *GeneralOrderExecutor) TradeCollector() *TradeCollector {
 	return e.tradeCollector
 }
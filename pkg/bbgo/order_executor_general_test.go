@@ -0,0 +1,99 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func TestGeneralOrderExecutor_PositionSide(t *testing.T) {
+	market := getTestMarket()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	var submitted []types.SubmitOrder
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+	mockEx.EXPECT().SubmitOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+		submitted = append(submitted, order)
+		return &types.Order{SubmitOrder: order, OrderID: uint64(len(submitted)), Status: types.OrderStatusNew}, nil
+	}).Times(2)
+
+	session := NewExchangeSession("test", mockEx)
+	session.Futures = true
+	session.markets[market.Symbol] = market
+
+	position := types.NewPositionFromMarket(market)
+	orderExecutor := NewGeneralOrderExecutor(session, "BTCUSDT", "test", "test-01", position)
+	orderExecutor.SetPositionSide(types.PositionSideLong)
+
+	_, err := orderExecutor.SubmitOrders(context.Background(), types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Price:    fixedpoint.MustNewFromString("20000"),
+		Quantity: fixedpoint.MustNewFromString("0.1"),
+		Market:   market,
+	})
+	assert.NoError(t, err)
+
+	_, err = orderExecutor.NewOrderFromOpenPosition(context.Background(), &OpenPositionOptions{
+		Short:    true,
+		Quantity: fixedpoint.MustNewFromString("0.1"),
+		Price:    fixedpoint.MustNewFromString("20000"),
+	})
+	assert.Error(t, err, "the long side executor should not open a short position")
+
+	position.Base = fixedpoint.MustNewFromString("0.1")
+	position.AverageCost = fixedpoint.MustNewFromString("20000")
+	assert.NoError(t, orderExecutor.ClosePosition(context.Background(), fixedpoint.One))
+
+	if assert.Len(t, submitted, 2) {
+		assert.Equal(t, types.PositionSideLong, submitted[0].PositionSide)
+
+		closeOrder := submitted[1]
+		assert.Equal(t, types.PositionSideLong, closeOrder.PositionSide)
+		assert.Equal(t, types.SideTypeSell, closeOrder.Side)
+		assert.Equal(t, "0.1", closeOrder.Quantity.String())
+		assert.False(t, closeOrder.ReduceOnly, "reduceOnly is not allowed in the hedge mode")
+	}
+}
+
+func TestGeneralOrderExecutor_NewClosePositionOrder(t *testing.T) {
+	market := getTestMarket()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	session := NewExchangeSession("test", mockEx)
+	session.Futures = true
+	session.markets[market.Symbol] = market
+
+	position := types.NewPositionFromMarket(market)
+	position.Base = fixedpoint.MustNewFromString("-0.4")
+	position.AverageCost = fixedpoint.MustNewFromString("20000")
+
+	orderExecutor := NewGeneralOrderExecutor(session, "BTCUSDT", "test", "test-01", position)
+
+	submitOrder, err := orderExecutor.NewClosePositionOrder(fixedpoint.MustNewFromString("0.5"))
+	if assert.NoError(t, err) && assert.NotNil(t, submitOrder) {
+		assert.Equal(t, types.SideTypeBuy, submitOrder.Side)
+		assert.Equal(t, "0.2", submitOrder.Quantity.String())
+		assert.True(t, submitOrder.ReduceOnly)
+	}
+
+	// the quantity less than the min quantity is not closed
+	submitOrder, err = orderExecutor.NewClosePositionOrder(fixedpoint.MustNewFromString("0.001"))
+	assert.NoError(t, err)
+	assert.Nil(t, submitOrder)
+}
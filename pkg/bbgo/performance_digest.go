@@ -0,0 +1,412 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/dynamic"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultDailyDigestSpec = "0 0 * * *"
+
+// PerformanceDigestConfig schedules the performance digests sent via the configured notifiers
+type PerformanceDigestConfig struct {
+	// Daily is the cron spec of the daily digest, defaults to "0 0 * * *" when both of the specs are empty
+	Daily string `json:"daily,omitempty" yaml:"daily,omitempty"`
+
+	// Weekly is the cron spec of the weekly digest, for example "0 0 * * MON"
+	Weekly string `json:"weekly,omitempty" yaml:"weekly,omitempty"`
+
+	// TimeZone is the IANA time zone of the cron specs, defaults to the local time zone
+	TimeZone string `json:"timeZone,omitempty" yaml:"timeZone,omitempty"`
+}
+
+// StrategyDigest is the performance of a strategy position in the digest period
+type StrategyDigest struct {
+	StrategyInstanceID string `json:"strategyInstanceID"`
+	Symbol             string `json:"symbol"`
+	QuoteCurrency      string `json:"quoteCurrency,omitempty"`
+
+	PnL       fixedpoint.Value `json:"pnl"`
+	NetProfit fixedpoint.Value `json:"netProfit"`
+	Volume    fixedpoint.Value `json:"volume"`
+
+	Fees map[string]fixedpoint.Value `json:"fees,omitempty"`
+
+	// Base is the position base at the end of the period, InventoryDrift is the base change in the period
+	Base           fixedpoint.Value `json:"base"`
+	InventoryDrift fixedpoint.Value `json:"inventoryDrift"`
+
+	// EquityChange is the change of the realized and the unrealized profit in the period
+	EquityChange fixedpoint.Value `json:"equityChange"`
+}
+
+// FillDigest summarizes the fills of a symbol in a session
+type FillDigest struct {
+	Session       string           `json:"session"`
+	Symbol        string           `json:"symbol"`
+	Fills         int              `json:"fills"`
+	QuoteQuantity fixedpoint.Value `json:"quoteQuantity"`
+}
+
+// PerformanceDigestReport is the digest of a period
+type PerformanceDigestReport struct {
+	Period     string           `json:"period"`
+	StartTime  time.Time        `json:"startTime"`
+	EndTime    time.Time        `json:"endTime"`
+	Strategies []StrategyDigest `json:"strategies"`
+	Fills      []FillDigest     `json:"fills"`
+}
+
+func (r *PerformanceDigestReport) PlainText() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s Performance Digest %s ~ %s\n",
+		strings.ToUpper(r.Period[:1])+r.Period[1:], r.StartTime.Format(time.RFC822), r.EndTime.Format(time.RFC822)))
+
+	for _, s := range r.Strategies {
+		sb.WriteString(fmt.Sprintf("\n%s %s\n", s.StrategyInstanceID, s.Symbol))
+		sb.WriteString(fmt.Sprintf("Profit %s %s, Net Profit %s %s\n",
+			s.PnL.String(), s.QuoteCurrency, s.NetProfit.String(), s.QuoteCurrency))
+		sb.WriteString(fmt.Sprintf("Volume %s\n", s.Volume.String()))
+
+		if len(s.Fees) > 0 {
+			var fees []string
+			for _, currency := range sortedFeeCurrencies(s.Fees) {
+				fees = append(fees, s.Fees[currency].String()+" "+currency)
+			}
+			sb.WriteString("Fees " + strings.Join(fees, ", ") + "\n")
+		}
+
+		sb.WriteString(fmt.Sprintf("Inventory %s (drift %s)\n", s.Base.String(), s.InventoryDrift.String()))
+		sb.WriteString(fmt.Sprintf("Equity Change %s %s\n", s.EquityChange.String(), s.QuoteCurrency))
+	}
+
+	if len(r.Fills) > 0 {
+		sb.WriteString("\nFills:\n")
+		for _, f := range r.Fills {
+			sb.WriteString(fmt.Sprintf("%s %s %d fills, %s in quote\n", f.Session, f.Symbol, f.Fills, f.QuoteQuantity.String()))
+		}
+	}
+
+	return sb.String()
+}
+
+func sortedFeeCurrencies(fees map[string]fixedpoint.Value) []string {
+	var currencies []string
+	for currency := range fees {
+		currencies = append(currencies, currency)
+	}
+
+	sort.Strings(currencies)
+	return currencies
+}
+
+// digestSnapshot is the accumulated values of a strategy position at the beginning of the period
+type digestSnapshot struct {
+	quoteCurrency          string
+	pnl, netProfit, volume fixedpoint.Value
+	base, equity           fixedpoint.Value
+	fees                   map[string]fixedpoint.Value
+}
+
+type fillKey struct {
+	session, symbol string
+}
+
+type digestPeriod struct {
+	name      string
+	startTime time.Time
+	baselines map[string]digestSnapshot
+	fills     map[fillKey]*FillDigest
+}
+
+// PerformanceDigest sends the daily and the weekly performance digests of the strategy instances,
+// the changes are calculated against the snapshots taken at the previous digest or at the start.
+type PerformanceDigest struct {
+	environ  *Environment
+	trader   *Trader
+	location *time.Location
+	specs    map[string]string
+
+	mu      sync.Mutex
+	periods map[string]*digestPeriod
+
+	logger logrus.FieldLogger
+}
+
+func NewPerformanceDigest(environ *Environment, trader *Trader, config *PerformanceDigestConfig) (*PerformanceDigest, error) {
+	location := time.Local
+	if config.TimeZone != "" {
+		var err error
+		location, err = time.LoadLocation(config.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid performance digest time zone %q: %w", config.TimeZone, err)
+		}
+	}
+
+	specs := map[string]string{}
+	if config.Daily != "" {
+		specs["daily"] = config.Daily
+	}
+	if config.Weekly != "" {
+		specs["weekly"] = config.Weekly
+	}
+	if len(specs) == 0 {
+		specs["daily"] = defaultDailyDigestSpec
+	}
+
+	for name, spec := range specs {
+		if _, err := cron.ParseStandard(spec); err != nil {
+			return nil, fmt.Errorf("invalid %s performance digest spec %q: %w", name, spec, err)
+		}
+	}
+
+	return &PerformanceDigest{
+		environ:  environ,
+		trader:   trader,
+		location: location,
+		specs:    specs,
+		periods:  make(map[string]*digestPeriod),
+		logger:   logrus.WithField("component", "performanceDigest"),
+	}, nil
+}
+
+// BindStreams counts the fills of the sessions, it should be called before the streams are connected
+func (d *PerformanceDigest) BindStreams() {
+	for sessionName, session := range d.environ.Sessions() {
+		sessionName := sessionName
+		session.UserDataStream.OnTradeUpdate(func(trade types.Trade) {
+			d.addFill(sessionName, trade)
+		})
+	}
+}
+
+func (d *PerformanceDigest) addFill(sessionName string, trade types.Trade) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	key := fillKey{session: sessionName, symbol: trade.Symbol}
+	for _, period := range d.periods {
+		fill, ok := period.fills[key]
+		if !ok {
+			fill = &FillDigest{Session: sessionName, Symbol: trade.Symbol}
+			period.fills[key] = fill
+		}
+
+		fill.Fills++
+		fill.QuoteQuantity = fill.QuoteQuantity.Add(trade.QuoteQuantity)
+	}
+}
+
+// Run takes the baseline snapshots and sends the digests on schedule until the context is done
+func (d *PerformanceDigest) Run(ctx context.Context) {
+	now := time.Now()
+	c := cron.New(cron.WithLocation(d.location))
+	for name, spec := range d.specs {
+		name := name
+		d.reset(name, now)
+
+		if _, err := c.AddFunc(spec, func() {
+			report, err := d.Digest(name, time.Now())
+			if err != nil {
+				d.logger.WithError(err).Errorf("can not compile the %s performance digest", name)
+				return
+			}
+
+			Notify(report)
+		}); err != nil {
+			d.logger.WithError(err).Errorf("can not schedule the %s performance digest", name)
+		}
+	}
+
+	c.Start()
+	<-ctx.Done()
+	<-c.Stop().Done()
+}
+
+// reset starts a new period with the current snapshots as the baselines
+func (d *PerformanceDigest) reset(name string, now time.Time) {
+	baselines := d.snapshot()
+
+	d.mu.Lock()
+	d.periods[name] = &digestPeriod{
+		name:      name,
+		startTime: now,
+		baselines: baselines,
+		fills:     make(map[fillKey]*FillDigest),
+	}
+	d.mu.Unlock()
+}
+
+// Digest compiles the report of the period and starts the next period
+func (d *PerformanceDigest) Digest(name string, now time.Time) (*PerformanceDigestReport, error) {
+	d.mu.Lock()
+	period, ok := d.periods[name]
+	d.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("performance digest period %s is not started", name)
+	}
+
+	current := d.snapshot()
+
+	report := &PerformanceDigestReport{
+		Period:    name,
+		StartTime: period.startTime.In(d.location),
+		EndTime:   now.In(d.location),
+	}
+
+	var keys []string
+	for key := range current {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		end := current[key]
+		begin := period.baselines[key]
+		instanceID, symbol := splitDigestKey(key)
+
+		fees := make(map[string]fixedpoint.Value)
+		for currency, fee := range end.fees {
+			if change := fee.Sub(begin.fees[currency]); !change.IsZero() {
+				fees[currency] = change
+			}
+		}
+
+		report.Strategies = append(report.Strategies, StrategyDigest{
+			StrategyInstanceID: instanceID,
+			Symbol:             symbol,
+			QuoteCurrency:      end.quoteCurrency,
+			PnL:                end.pnl.Sub(begin.pnl),
+			NetProfit:          end.netProfit.Sub(begin.netProfit),
+			Volume:             end.volume.Sub(begin.volume),
+			Fees:               fees,
+			Base:               end.base,
+			InventoryDrift:     end.base.Sub(begin.base),
+			EquityChange:       end.equity.Sub(begin.equity),
+		})
+	}
+
+	d.mu.Lock()
+	for _, fill := range period.fills {
+		report.Fills = append(report.Fills, *fill)
+	}
+	d.periods[name] = &digestPeriod{
+		name:      name,
+		startTime: now,
+		baselines: current,
+		fills:     make(map[fillKey]*FillDigest),
+	}
+	d.mu.Unlock()
+
+	sort.Slice(report.Fills, func(i, j int) bool {
+		if report.Fills[i].Session != report.Fills[j].Session {
+			return report.Fills[i].Session < report.Fills[j].Session
+		}
+		return report.Fills[i].Symbol < report.Fills[j].Symbol
+	})
+
+	return report, nil
+}
+
+// snapshot collects the accumulated values of the positions and the profit stats keyed by "{instanceID} {symbol}"
+func (d *PerformanceDigest) snapshot() map[string]digestSnapshot {
+	snapshots := make(map[string]digestSnapshot)
+
+	instances, err := d.trader.StrategyInstances()
+	if err != nil {
+		d.logger.WithError(err).Error("can not list the strategy instances")
+		return snapshots
+	}
+
+	for _, instance := range instances {
+		for _, position := range collectStrategyPositions(instance.Strategy) {
+			position.Lock()
+			s := digestSnapshot{
+				quoteCurrency: position.QuoteCurrency,
+				base:          position.Base,
+				equity:        position.AccumulatedProfit,
+				fees:          make(map[string]fixedpoint.Value, len(position.TotalFee)),
+			}
+			for currency, fee := range position.TotalFee {
+				s.fees[currency] = fee
+			}
+			averageCost := position.AverageCost
+			position.Unlock()
+
+			if !s.base.IsZero() {
+				if midPrice, ok := d.midPrice(instance.Session, position.Symbol); ok {
+					s.equity = s.equity.Add(midPrice.Sub(averageCost).Mul(s.base))
+				}
+			}
+
+			snapshots[digestKey(instance.ID, position.Symbol)] = s
+		}
+
+		for _, stats := range collectStrategyProfitStats(instance.Strategy) {
+			key := digestKey(instance.ID, stats.Symbol)
+			s := snapshots[key]
+			s.pnl = stats.AccumulatedPnL
+			s.netProfit = stats.AccumulatedNetProfit
+			s.volume = stats.AccumulatedVolume
+			if s.quoteCurrency == "" {
+				s.quoteCurrency = stats.QuoteCurrency
+			}
+			snapshots[key] = s
+		}
+	}
+
+	return snapshots
+}
+
+func (d *PerformanceDigest) midPrice(sessionName, symbol string) (fixedpoint.Value, bool) {
+	if sessionName != "" {
+		if session, ok := d.environ.Session(sessionName); ok {
+			return sessionMidPrice(session, symbol)
+		}
+	}
+
+	for _, session := range d.environ.Sessions() {
+		if price, ok := sessionMidPrice(session, symbol); ok {
+			return price, true
+		}
+	}
+
+	return fixedpoint.Zero, false
+}
+
+func digestKey(instanceID, symbol string) string {
+	return instanceID + " " + symbol
+}
+
+func splitDigestKey(key string) (instanceID, symbol string) {
+	i := strings.LastIndex(key, " ")
+	return key[:i], key[i+1:]
+}
+
+// collectStrategyProfitStats returns the exported *types.ProfitStats fields of the strategy struct
+func collectStrategyProfitStats(strategy interface{}) (stats []*types.ProfitStats) {
+	profitStatsType := reflect.TypeOf(&types.ProfitStats{})
+	_ = dynamic.IterateFields(strategy, func(ft reflect.StructField, fv reflect.Value) error {
+		if ft.Type != profitStatsType || fv.IsNil() {
+			return nil
+		}
+
+		if s, ok := fv.Interface().(*types.ProfitStats); ok {
+			stats = append(stats, s)
+		}
+		return nil
+	})
+
+	return stats
+}
@@ -0,0 +1,104 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func TestPerformanceDigest_Digest(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	session := NewExchangeSession("binance", mockEx)
+	session.lastPrices["BTCUSDT"] = fixedpoint.NewFromInt(20000)
+
+	environ := NewEnvironment()
+	environ.AddExchangeSession("binance", session)
+
+	position := &types.Position{
+		Symbol:        "BTCUSDT",
+		QuoteCurrency: "USDT",
+		Base:          fixedpoint.NewFromFloat(0.5),
+		AverageCost:   fixedpoint.NewFromInt(20000),
+		TotalFee:      map[string]fixedpoint.Value{"USDT": fixedpoint.NewFromInt(1)},
+	}
+	profitStats := types.NewProfitStats(types.Market{Symbol: "BTCUSDT", QuoteCurrency: "USDT"})
+
+	trader := &Trader{
+		environment: environ,
+		exchangeStrategies: map[string][]SingleExchangeStrategy{
+			"binance": {
+				&testInstanceStrategy{Symbol: "BTCUSDT", Position: position, ProfitStats: profitStats},
+			},
+		},
+	}
+
+	digest, err := NewPerformanceDigest(environ, trader, &PerformanceDigestConfig{TimeZone: "Asia/Taipei"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	digest.BindStreams()
+
+	_, err = digest.Digest("daily", time.Now())
+	assert.Error(t, err, "the period is not started")
+
+	startTime := time.Now()
+	digest.reset("daily", startTime)
+
+	// sell 0.2 BTC at 21000, and the price goes up to 21000
+	session.UserDataStream.(*types.StandardStream).EmitTradeUpdate(types.Trade{Symbol: "BTCUSDT", Quantity: fixedpoint.NewFromFloat(0.2), QuoteQuantity: fixedpoint.NewFromInt(4200)})
+	position.Base = fixedpoint.NewFromFloat(0.3)
+	position.AccumulatedProfit = fixedpoint.NewFromInt(200)
+	position.TotalFee["USDT"] = fixedpoint.NewFromInt(3)
+	profitStats.AddProfit(types.Profit{Profit: fixedpoint.NewFromInt(200), NetProfit: fixedpoint.NewFromInt(198), TradedAt: startTime})
+	profitStats.AccumulatedVolume = fixedpoint.NewFromFloat(0.2)
+	session.lastPrices["BTCUSDT"] = fixedpoint.NewFromInt(21000)
+
+	report, err := digest.Digest("daily", startTime.Add(24*time.Hour))
+	if !assert.NoError(t, err) || !assert.Len(t, report.Strategies, 1) {
+		return
+	}
+
+	s := report.Strategies[0]
+	assert.Equal(t, "binance.test:BTCUSDT", s.StrategyInstanceID)
+	assert.Equal(t, "200", s.PnL.String())
+	assert.Equal(t, "198", s.NetProfit.String())
+	assert.Equal(t, "0.2", s.Volume.String())
+	assert.Equal(t, "2", s.Fees["USDT"].String())
+	assert.Equal(t, "-0.2", s.InventoryDrift.String())
+	// equity goes from 0 to 200 realized + 300 unrealized
+	assert.Equal(t, "500", s.EquityChange.String())
+	assert.Equal(t, "Asia/Taipei", report.EndTime.Location().String())
+
+	if assert.Len(t, report.Fills, 1) {
+		assert.Equal(t, 1, report.Fills[0].Fills)
+		assert.Equal(t, "4200", report.Fills[0].QuoteQuantity.String())
+	}
+	assert.Contains(t, report.PlainText(), "Daily Performance Digest")
+
+	// the next period starts from the last digest
+	report, err = digest.Digest("daily", startTime.Add(48*time.Hour))
+	if assert.NoError(t, err) && assert.Len(t, report.Strategies, 1) {
+		assert.Equal(t, "0", report.Strategies[0].PnL.String())
+		assert.Empty(t, report.Strategies[0].Fees)
+		assert.Empty(t, report.Fills)
+	}
+}
+
+func TestNewPerformanceDigest_InvalidConfig(t *testing.T) {
+	_, err := NewPerformanceDigest(NewEnvironment(), &Trader{}, &PerformanceDigestConfig{Weekly: "every monday"})
+	assert.Error(t, err)
+
+	_, err = NewPerformanceDigest(NewEnvironment(), &Trader{}, &PerformanceDigestConfig{TimeZone: "Mars/Olympus"})
+	assert.Error(t, err)
+}
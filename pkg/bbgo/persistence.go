@@ -42,7 +42,18 @@ func Sync(ctx context.Context, obj interface{}) {
 	}
 }
 
+// recoverablePersistenceService re-applies the uncommitted transaction, see JsonPersistenceService.Recover
+type recoverablePersistenceService interface {
+	Recover(id string) error
+}
+
 func loadPersistenceFields(obj interface{}, id string, persistence service.PersistenceService) error {
+	if recoverable, ok := persistence.(recoverablePersistenceService); ok {
+		if err := recoverable.Recover(id); err != nil {
+			return errors.Wrapf(err, "failed to recover the persistence of %s", id)
+		}
+	}
+
 	return dynamic.IterateFieldsByTag(obj, "persistence", func(tag string, field reflect.StructField, value reflect.Value) error {
 		log.Debugf("[loadPersistenceFields] loading value into field %v, tag = %s, original value = %v", field, tag, value)
 
@@ -71,6 +82,10 @@ func loadPersistenceFields(obj interface{}, id string, persistence service.Persi
 }
 
 func storePersistenceFields(obj interface{}, id string, persistence service.PersistenceService) error {
+	if tps, ok := persistence.(service.TransactionalPersistenceService); ok {
+		return storePersistenceFieldsAtomic(obj, id, tps)
+	}
+
 	return dynamic.IterateFieldsByTag(obj, "persistence", func(tag string, ft reflect.StructField, fv reflect.Value) error {
 		log.Debugf("[storePersistenceFields] storing value from field %v, tag = %s, original value = %v", ft, tag, fv)
 
@@ -80,6 +95,71 @@ func storePersistenceFields(obj interface{}, id string, persistence service.Pers
 	})
 }
 
+// storePersistenceFieldsAtomic saves all the persistence fields in one transaction,
+// so that a crash between the writes won't leave the position and the profit stats inconsistent.
+func storePersistenceFieldsAtomic(obj interface{}, id string, persistence service.TransactionalPersistenceService) error {
+	values := make(map[string]interface{})
+	if err := dynamic.IterateFieldsByTag(obj, "persistence", func(tag string, ft reflect.StructField, fv reflect.Value) error {
+		values[tag] = fv.Interface()
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	version, err := persistence.SaveAll(id, values)
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("[storePersistenceFieldsAtomic] saved %d fields of %s, version = %d", len(values), id, version)
+	return nil
+}
+
+// PersistenceSnapshots returns the persistence snapshot versions of the object, the latest version comes first
+func PersistenceSnapshots(ctx context.Context, obj interface{}) ([]int64, error) {
+	id := dynamic.CallID(obj)
+	if len(id) == 0 {
+		return nil, errors.New("InstanceID() is not provided")
+	}
+
+	tps, ok := GetIsolationFromContext(ctx).persistenceServiceFacade.Get().(service.TransactionalPersistenceService)
+	if !ok {
+		return nil, errors.New("the persistence service does not support snapshots")
+	}
+
+	return tps.Snapshots(id)
+}
+
+// RollbackPersistence restores the persistence fields of the object from the snapshot version
+func RollbackPersistence(ctx context.Context, obj interface{}, version int64) error {
+	id := dynamic.CallID(obj)
+	if len(id) == 0 {
+		return errors.New("InstanceID() is not provided")
+	}
+
+	ps := GetIsolationFromContext(ctx).persistenceServiceFacade.Get()
+	tps, ok := ps.(service.TransactionalPersistenceService)
+	if !ok {
+		return errors.New("the persistence service does not support snapshots")
+	}
+
+	if err := tps.Rollback(id, version); err != nil {
+		return errors.Wrapf(err, "failed to rollback %s to version %d", id, version)
+	}
+
+	locker, ok := obj.(sync.Locker)
+	if ok {
+		locker.Lock()
+		defer locker.Unlock()
+	}
+
+	return loadPersistenceFields(obj, id, ps)
+}
+
 func NewPersistenceServiceFacade(conf *PersistenceConfig) (*service.PersistenceServiceFacade, error) {
 	facade := &service.PersistenceServiceFacade{
 		Memory: service.NewMemoryService(),
@@ -94,6 +174,19 @@ func NewPersistenceServiceFacade(conf *PersistenceConfig) (*service.PersistenceS
 		facade.Redis = redisPersistence
 	}
 
+	if conf.Postgres != nil {
+		if err := env.Set(conf.Postgres); err != nil {
+			return nil, err
+		}
+
+		postgresPersistence, err := service.NewPostgresPersistenceService(conf.Postgres)
+		if err != nil {
+			return nil, errors.Wrap(err, "can not connect to the postgres persistence")
+		}
+
+		facade.Postgres = postgresPersistence
+	}
+
 	if conf.Json != nil {
 		if _, err := os.Stat(conf.Json.Directory); os.IsNotExist(err) {
 			if err2 := os.MkdirAll(conf.Json.Directory, 0777); err2 != nil {
@@ -0,0 +1,451 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	defaultPortfolioRiskInterval = 5 * time.Minute
+	defaultPortfolioRiskWindow   = 30
+
+	// portfolioReturnsTTL is how long the daily returns are cached, the daily klines only change once a day
+	portfolioReturnsTTL = time.Hour
+)
+
+var defaultPortfolioRiskConfidenceLevel = fixedpoint.NewFromFloat(0.99)
+
+// PortfolioRiskConfig enables the portfolio risk service, which calculates the daily value at risk
+// and the expected shortfall of the strategy positions across all the sessions.
+type PortfolioRiskConfig struct {
+	// Interval is the calculation interval, defaults to 5 minutes
+	Interval types.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	// Window is the number of the daily returns for the volatilities and the correlations, defaults to 30
+	Window int `json:"window,omitempty" yaml:"window,omitempty"`
+
+	// ConfidenceLevel is the confidence level of the value at risk, defaults to 99%
+	ConfidenceLevel fixedpoint.Value `json:"confidenceLevel,omitempty" yaml:"confidenceLevel,omitempty"`
+
+	// MaxValueAtRisk sends the alert when the daily value at risk exceeds it, in the quote currency
+	MaxValueAtRisk fixedpoint.Value `json:"maxValueAtRisk,omitempty" yaml:"maxValueAtRisk,omitempty"`
+
+	// MaxExpectedShortfall sends the alert when the daily expected shortfall exceeds it, in the quote currency
+	MaxExpectedShortfall fixedpoint.Value `json:"maxExpectedShortfall,omitempty" yaml:"maxExpectedShortfall,omitempty"`
+}
+
+// SymbolRisk is the exposure and the volatility of the aggregated positions of one symbol
+type SymbolRisk struct {
+	Symbol   string           `json:"symbol"`
+	Base     fixedpoint.Value `json:"base"`
+	Price    fixedpoint.Value `json:"price"`
+	Exposure fixedpoint.Value `json:"exposure"`
+
+	// Volatility is the standard deviation of the daily log returns
+	Volatility fixedpoint.Value `json:"volatility"`
+
+	// ValueAtRisk is the standalone daily value at risk of the symbol
+	ValueAtRisk fixedpoint.Value `json:"valueAtRisk"`
+}
+
+// PortfolioRisk is the daily value at risk of the positions, estimated by the variance-covariance method.
+// The positions are valued in their quote currencies, which are assumed to be the same currency.
+type PortfolioRisk struct {
+	Time            time.Time        `json:"time"`
+	ConfidenceLevel fixedpoint.Value `json:"confidenceLevel"`
+
+	// GrossExposure is the sum of the absolute position values
+	GrossExposure fixedpoint.Value `json:"grossExposure"`
+
+	// NetExposure is the sum of the signed position values
+	NetExposure fixedpoint.Value `json:"netExposure"`
+
+	ValueAtRisk       fixedpoint.Value `json:"valueAtRisk"`
+	ExpectedShortfall fixedpoint.Value `json:"expectedShortfall"`
+
+	Symbols []SymbolRisk `json:"symbols"`
+
+	// Correlations are the correlations of the daily returns between the symbols
+	Correlations map[string]map[string]fixedpoint.Value `json:"correlations,omitempty"`
+}
+
+func (r PortfolioRisk) String() string {
+	return fmt.Sprintf("portfolio daily VaR(%s) %s, ES %s, gross exposure %s, net exposure %s",
+		r.ConfidenceLevel.Percentage(), r.ValueAtRisk.FormatString(2), r.ExpectedShortfall.FormatString(2),
+		r.GrossExposure.FormatString(2), r.NetExposure.FormatString(2))
+}
+
+// dailyReturns are the log returns keyed by the kline start time
+type dailyReturns struct {
+	updatedAt time.Time
+	returns   map[int64]float64
+}
+
+// PortfolioRiskService aggregates the positions of all the strategy instances by symbol,
+// and calculates the portfolio risk with the volatilities and the correlations of the daily kline history.
+type PortfolioRiskService struct {
+	environ  *Environment
+	trader   *Trader
+	config   PortfolioRiskConfig
+	interval time.Duration
+
+	mu       sync.Mutex
+	last     *PortfolioRisk
+	returns  map[string]dailyReturns
+	breached bool
+
+	logger logrus.FieldLogger
+}
+
+func NewPortfolioRiskService(environ *Environment, trader *Trader, config *PortfolioRiskConfig) *PortfolioRiskService {
+	c := *config
+	if c.Window <= 1 {
+		c.Window = defaultPortfolioRiskWindow
+	}
+
+	if c.ConfidenceLevel.IsZero() {
+		c.ConfidenceLevel = defaultPortfolioRiskConfidenceLevel
+	}
+
+	interval := c.Interval.Duration()
+	if interval == 0 {
+		interval = defaultPortfolioRiskInterval
+	}
+
+	return &PortfolioRiskService{
+		environ:  environ,
+		trader:   trader,
+		config:   c,
+		interval: interval,
+		returns:  make(map[string]dailyReturns),
+		logger:   logrus.WithField("component", "portfolioRisk"),
+	}
+}
+
+// Run calculates the portfolio risk periodically until the context is done
+func (s *PortfolioRiskService) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			risk, err := s.Calculate(ctx, time.Now())
+			if err != nil {
+				s.logger.WithError(err).Error("portfolio risk calculation error")
+				continue
+			}
+
+			s.alert(risk)
+		}
+	}
+}
+
+// Last returns the last calculated portfolio risk, nil is returned before the first calculation
+func (s *PortfolioRiskService) Last() *PortfolioRisk {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.last
+}
+
+// Calculate aggregates the current positions and calculates the portfolio risk
+func (s *PortfolioRiskService) Calculate(ctx context.Context, now time.Time) (*PortfolioRisk, error) {
+	symbols, sessions, err := s.aggregatePositions()
+	if err != nil {
+		return nil, err
+	}
+
+	returns := make(map[string]map[int64]float64)
+	for i, symbolRisk := range symbols {
+		r, err := s.dailyReturns(ctx, sessions[i], symbolRisk.Symbol, now)
+		if err != nil {
+			return nil, err
+		}
+
+		returns[symbolRisk.Symbol] = r
+	}
+
+	risk := calculatePortfolioRisk(symbols, returns, s.config.ConfidenceLevel)
+	risk.Time = now
+
+	s.mu.Lock()
+	s.last = &risk
+	s.mu.Unlock()
+
+	s.logger.Debugf("%s", risk.String())
+	return &risk, nil
+}
+
+// alert notifies when the risk breaches the thresholds, and when it's back under the thresholds
+func (s *PortfolioRiskService) alert(risk *PortfolioRisk) {
+	breached := (s.config.MaxValueAtRisk.Sign() > 0 && risk.ValueAtRisk.Compare(s.config.MaxValueAtRisk) > 0) ||
+		(s.config.MaxExpectedShortfall.Sign() > 0 && risk.ExpectedShortfall.Compare(s.config.MaxExpectedShortfall) > 0)
+
+	if breached && !s.breached {
+		var limits []string
+		if s.config.MaxValueAtRisk.Sign() > 0 {
+			limits = append(limits, "max VaR "+s.config.MaxValueAtRisk.FormatString(2))
+		}
+
+		if s.config.MaxExpectedShortfall.Sign() > 0 {
+			limits = append(limits, "max ES "+s.config.MaxExpectedShortfall.FormatString(2))
+		}
+
+		Notify("⚠️ %s exceeds the limit (%s)", risk.String(), strings.Join(limits, ", "))
+	} else if !breached && s.breached {
+		Notify("%s is back under the limit", risk.String())
+	}
+
+	s.breached = breached
+}
+
+// aggregatePositions sums the positions of the strategy instances by symbol,
+// the session of the first position is used for querying the price and the klines of the symbol.
+func (s *PortfolioRiskService) aggregatePositions() ([]SymbolRisk, []*ExchangeSession, error) {
+	instances, err := s.trader.StrategyInstances()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	bases := make(map[string]fixedpoint.Value)
+	symbolSessions := make(map[string]*ExchangeSession)
+	for _, instance := range instances {
+		for _, position := range collectStrategyPositions(instance.Strategy) {
+			position.Lock()
+			symbol, base := position.Symbol, position.Base
+			position.Unlock()
+
+			if base.IsZero() {
+				continue
+			}
+
+			if _, ok := symbolSessions[symbol]; !ok {
+				session, ok := s.positionSession(instance.Session, symbol)
+				if !ok {
+					s.logger.Warnf("no session of %s for %s, skipping", symbol, instance.ID)
+					continue
+				}
+
+				symbolSessions[symbol] = session
+			}
+
+			bases[symbol] = bases[symbol].Add(base)
+		}
+	}
+
+	var symbols []string
+	for symbol := range bases {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var risks []SymbolRisk
+	var sessions []*ExchangeSession
+	for _, symbol := range symbols {
+		session := symbolSessions[symbol]
+		price, ok := sessionMidPrice(session, symbol)
+		if !ok {
+			s.logger.Warnf("no price of %s in session %s, skipping", symbol, session.Name)
+			continue
+		}
+
+		risks = append(risks, SymbolRisk{
+			Symbol:   symbol,
+			Base:     bases[symbol],
+			Price:    price,
+			Exposure: bases[symbol].Mul(price),
+		})
+		sessions = append(sessions, session)
+	}
+
+	return risks, sessions, nil
+}
+
+// positionSession returns the session of the instance, or the first session that has the market for the cross exchange strategies
+func (s *PortfolioRiskService) positionSession(sessionName, symbol string) (*ExchangeSession, bool) {
+	if sessionName != "" {
+		return s.environ.Session(sessionName)
+	}
+
+	var names []string
+	for name := range s.environ.Sessions() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		session, _ := s.environ.Session(name)
+		if _, ok := session.Market(symbol); ok {
+			return session, true
+		}
+	}
+
+	return nil, false
+}
+
+func (s *PortfolioRiskService) dailyReturns(ctx context.Context, session *ExchangeSession, symbol string, now time.Time) (map[int64]float64, error) {
+	s.mu.Lock()
+	cached, ok := s.returns[symbol]
+	s.mu.Unlock()
+
+	if ok && now.Sub(cached.updatedAt) < portfolioReturnsTTL {
+		return cached.returns, nil
+	}
+
+	kLines, err := session.Exchange.QueryKLines(ctx, symbol, types.Interval1d, types.KLineQueryOptions{
+		EndTime: &now,
+		Limit:   s.config.Window + 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("can not query the daily klines of %s: %w", symbol, err)
+	}
+
+	returns := logReturnsOf(kLines)
+
+	s.mu.Lock()
+	s.returns[symbol] = dailyReturns{updatedAt: now, returns: returns}
+	s.mu.Unlock()
+
+	return returns, nil
+}
+
+// logReturnsOf returns the log returns of the close prices keyed by the kline start time
+func logReturnsOf(kLines []types.KLine) map[int64]float64 {
+	returns := make(map[int64]float64)
+	for i := 1; i < len(kLines); i++ {
+		prev, cur := kLines[i-1].Close.Float64(), kLines[i].Close.Float64()
+		if prev <= 0 || cur <= 0 {
+			continue
+		}
+
+		returns[kLines[i].StartTime.Time().Unix()] = math.Log(cur / prev)
+	}
+
+	return returns
+}
+
+// calculatePortfolioRisk calculates the parametric value at risk with zero mean returns:
+// the portfolio deviation is sqrt(w' C w), where w are the exposures and C is the covariance matrix of the daily returns.
+// The correlations are calculated from the returns of the same days, the volatilities are calculated from all the returns.
+func calculatePortfolioRisk(symbols []SymbolRisk, returns map[string]map[int64]float64, confidenceLevel fixedpoint.Value) PortfolioRisk {
+	confidence := confidenceLevel.Float64()
+	z := math.Sqrt2 * math.Erfinv(2*confidence-1)
+
+	risk := PortfolioRisk{
+		ConfidenceLevel: confidenceLevel,
+		Correlations:    make(map[string]map[string]fixedpoint.Value),
+	}
+
+	n := len(symbols)
+	exposures := make([]float64, n)
+	volatilities := make([]float64, n)
+	for i := range symbols {
+		exposures[i] = symbols[i].Exposure.Float64()
+		volatilities[i] = stddevOf(returns[symbols[i].Symbol])
+
+		symbols[i].Volatility = fixedpoint.NewFromFloat(volatilities[i])
+		symbols[i].ValueAtRisk = fixedpoint.NewFromFloat(z * volatilities[i] * math.Abs(exposures[i]))
+
+		risk.GrossExposure = risk.GrossExposure.Add(symbols[i].Exposure.Abs())
+		risk.NetExposure = risk.NetExposure.Add(symbols[i].Exposure)
+	}
+
+	var variance float64
+	for i := 0; i < n; i++ {
+		for j := 0; j < n; j++ {
+			correlation := 1.0
+			if i != j {
+				correlation = correlationOf(returns[symbols[i].Symbol], returns[symbols[j].Symbol])
+			}
+
+			if i < j {
+				if risk.Correlations[symbols[i].Symbol] == nil {
+					risk.Correlations[symbols[i].Symbol] = make(map[string]fixedpoint.Value)
+				}
+
+				risk.Correlations[symbols[i].Symbol][symbols[j].Symbol] = fixedpoint.NewFromFloat(correlation)
+			}
+
+			variance += exposures[i] * exposures[j] * volatilities[i] * volatilities[j] * correlation
+		}
+	}
+
+	deviation := math.Sqrt(math.Max(variance, 0))
+	risk.ValueAtRisk = fixedpoint.NewFromFloat(z * deviation)
+
+	// the expected loss beyond the value at risk of the normal distribution
+	density := math.Exp(-z*z/2) / math.Sqrt(2*math.Pi)
+	risk.ExpectedShortfall = fixedpoint.NewFromFloat(deviation * density / (1 - confidence))
+
+	risk.Symbols = symbols
+	return risk
+}
+
+func stddevOf(returns map[int64]float64) float64 {
+	if len(returns) < 2 {
+		return 0
+	}
+
+	var mean float64
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+
+	var variance float64
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+
+	return math.Sqrt(variance / float64(len(returns)-1))
+}
+
+// correlationOf calculates the correlation of the returns of the same days, zero is returned without enough common days
+func correlationOf(a, b map[int64]float64) float64 {
+	var xs, ys []float64
+	for t, x := range a {
+		if y, ok := b[t]; ok {
+			xs = append(xs, x)
+			ys = append(ys, y)
+		}
+	}
+
+	if len(xs) < 2 {
+		return 0
+	}
+
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+
+	if varX == 0 || varY == 0 {
+		return 0
+	}
+
+	return cov / math.Sqrt(varX*varY)
+}
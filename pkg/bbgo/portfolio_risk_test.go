@@ -0,0 +1,127 @@
+package bbgo
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func Test_calculatePortfolioRisk(t *testing.T) {
+	a := map[int64]float64{1: 0.01, 2: -0.01, 3: 0.01, 4: -0.01}
+	b := map[int64]float64{1: 0.01, 2: 0.01, 3: -0.01, 4: -0.01}
+	sigma := math.Sqrt(4 * 0.0001 / 3)
+	z := 2.3263478740408408
+	confidenceLevel := fixedpoint.NewFromFloat(0.99)
+
+	newSymbols := func(exposureA, exposureB float64) []SymbolRisk {
+		return []SymbolRisk{
+			{Symbol: "AUSDT", Exposure: fixedpoint.NewFromFloat(exposureA)},
+			{Symbol: "BUSDT", Exposure: fixedpoint.NewFromFloat(exposureB)},
+		}
+	}
+
+	t.Run("single", func(t *testing.T) {
+		risk := calculatePortfolioRisk(newSymbols(10000, 0), map[string]map[int64]float64{"AUSDT": a, "BUSDT": b}, confidenceLevel)
+		assert.InDelta(t, z*sigma*10000, risk.ValueAtRisk.Float64(), 1e-4)
+		assert.InDelta(t, sigma*10000*2.665214220345808, risk.ExpectedShortfall.Float64(), 1e-4)
+		assert.InDelta(t, sigma, risk.Symbols[0].Volatility.Float64(), 1e-7)
+		assert.Equal(t, "10000", risk.GrossExposure.String())
+	})
+
+	t.Run("hedged", func(t *testing.T) {
+		risk := calculatePortfolioRisk(newSymbols(10000, -10000), map[string]map[int64]float64{"AUSDT": a, "BUSDT": a}, confidenceLevel)
+		assert.InDelta(t, 0.0, risk.ValueAtRisk.Float64(), 1e-4)
+		assert.InDelta(t, z*sigma*10000, risk.Symbols[1].ValueAtRisk.Float64(), 1e-4)
+		assert.Equal(t, "20000", risk.GrossExposure.String())
+		assert.Equal(t, "0", risk.NetExposure.String())
+		assert.InDelta(t, 1.0, risk.Correlations["AUSDT"]["BUSDT"].Float64(), 1e-9)
+	})
+
+	t.Run("uncorrelated", func(t *testing.T) {
+		risk := calculatePortfolioRisk(newSymbols(10000, 10000), map[string]map[int64]float64{"AUSDT": a, "BUSDT": b}, confidenceLevel)
+		assert.InDelta(t, z*sigma*10000*math.Sqrt2, risk.ValueAtRisk.Float64(), 1e-4)
+		assert.InDelta(t, 0.0, risk.Correlations["AUSDT"]["BUSDT"].Float64(), 1e-9)
+	})
+}
+
+func newDailyKLines(symbol string, closes ...float64) (kLines []types.KLine) {
+	startTime := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, c := range closes {
+		kLines = append(kLines, types.KLine{
+			Symbol:    symbol,
+			Interval:  types.Interval1d,
+			StartTime: types.Time(startTime.AddDate(0, 0, i)),
+			Close:     fixedpoint.NewFromFloat(c),
+		})
+	}
+
+	return kLines
+}
+
+func TestPortfolioRiskService_Calculate(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	// the klines are cached in the calculations of the same hour
+	mockEx.EXPECT().QueryKLines(gomock.Any(), "BTCUSDT", types.Interval1d, gomock.Any()).
+		Return(newDailyKLines("BTCUSDT", 100, 101, 100, 101, 100), nil).Times(1)
+	mockEx.EXPECT().QueryKLines(gomock.Any(), "ETHUSDT", types.Interval1d, gomock.Any()).
+		Return(newDailyKLines("ETHUSDT", 100, 101, 102, 101, 100), nil).Times(1)
+
+	session := NewExchangeSession("binance", mockEx)
+	session.lastPrices["BTCUSDT"] = fixedpoint.NewFromInt(20000)
+	session.lastPrices["ETHUSDT"] = fixedpoint.NewFromInt(2000)
+
+	environ := NewEnvironment()
+	environ.AddExchangeSession("binance", session)
+
+	trader := &Trader{
+		environment: environ,
+		exchangeStrategies: map[string][]SingleExchangeStrategy{
+			"binance": {
+				&testInstanceStrategy{
+					Symbol:   "BTCUSDT",
+					Position: &types.Position{Symbol: "BTCUSDT", Base: fixedpoint.NewFromFloat(0.5)},
+				},
+				&testInstanceStrategy{
+					Symbol:   "ETHUSDT",
+					Position: &types.Position{Symbol: "ETHUSDT", Base: fixedpoint.NewFromFloat(-5)},
+				},
+			},
+		},
+	}
+
+	service := NewPortfolioRiskService(environ, trader, &PortfolioRiskConfig{
+		MaxValueAtRisk: fixedpoint.NewFromInt(100),
+	})
+	assert.Nil(t, service.Last())
+
+	now := time.Now()
+	risk, err := service.Calculate(context.Background(), now)
+	if assert.NoError(t, err) && assert.Len(t, risk.Symbols, 2) {
+		assert.Equal(t, "BTCUSDT", risk.Symbols[0].Symbol)
+		assert.Equal(t, "10000", risk.Symbols[0].Exposure.String())
+		assert.Equal(t, "-10000", risk.Symbols[1].Exposure.String())
+		assert.Equal(t, "0", risk.NetExposure.String())
+		assert.True(t, risk.ValueAtRisk.Sign() > 0)
+		assert.True(t, risk.ExpectedShortfall.Compare(risk.ValueAtRisk) > 0)
+		assert.Equal(t, risk, service.Last())
+	}
+
+	service.alert(risk)
+	assert.True(t, service.breached)
+
+	_, err = service.Calculate(context.Background(), now.Add(time.Minute))
+	assert.NoError(t, err)
+}
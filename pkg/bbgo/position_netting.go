@@ -0,0 +1,183 @@
+package bbgo
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// PositionNettingConfig enables the position netting of the strategy instances trading the same symbol on the same session
+type PositionNettingConfig struct {
+	// Symbols are the symbols to be netted, all the symbols are netted if it's empty
+	Symbols []string `json:"symbols,omitempty" yaml:"symbols,omitempty"`
+}
+
+// NetPosition is the netted position of one symbol on one session
+type NetPosition struct {
+	Session string `json:"session"`
+	Symbol  string `json:"symbol"`
+
+	// Long is the sum of the long positions, Short is the sum of the short positions (negative)
+	Long  fixedpoint.Value `json:"long"`
+	Short fixedpoint.Value `json:"short"`
+
+	// Net is the exposure after netting the opposing positions
+	Net fixedpoint.Value `json:"net"`
+
+	// Instances are the base positions of the registered strategy instances
+	Instances map[string]fixedpoint.Value `json:"instances"`
+}
+
+type positionNettingKey struct {
+	session, symbol string
+}
+
+// PositionNettingService collects the positions of the strategy instances trading the same symbol on the same session,
+// so that the opposing exposures are netted before hedging or margin calculations.
+// For example, the long position of a market maker and the short position of a trend strategy offset each other.
+type PositionNettingService struct {
+	symbols map[string]struct{}
+
+	mu        sync.Mutex
+	positions map[positionNettingKey]map[string]*types.Position
+}
+
+func NewPositionNettingService(config *PositionNettingConfig) *PositionNettingService {
+	service := &PositionNettingService{
+		positions: make(map[positionNettingKey]map[string]*types.Position),
+	}
+
+	if config != nil && len(config.Symbols) > 0 {
+		service.symbols = make(map[string]struct{}, len(config.Symbols))
+		for _, symbol := range config.Symbols {
+			service.symbols[symbol] = struct{}{}
+		}
+	}
+
+	return service
+}
+
+// Register adds the position of the strategy instance, it returns false if the symbol is not netted
+func (s *PositionNettingService) Register(session, instanceID string, position *types.Position) bool {
+	if s.symbols != nil {
+		if _, ok := s.symbols[position.Symbol]; !ok {
+			return false
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := positionNettingKey{session: session, symbol: position.Symbol}
+	if s.positions[key] == nil {
+		s.positions[key] = make(map[string]*types.Position)
+	}
+
+	s.positions[key][instanceID] = position
+	return true
+}
+
+// Unregister removes all the positions of the strategy instance
+func (s *PositionNettingService) Unregister(session, instanceID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for key, positions := range s.positions {
+		if key.session != session {
+			continue
+		}
+
+		delete(positions, instanceID)
+		if len(positions) == 0 {
+			delete(s.positions, key)
+		}
+	}
+}
+
+// NetPosition returns the netted position of the symbol on the session
+func (s *PositionNettingService) NetPosition(session, symbol string) NetPosition {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	netPosition := NetPosition{
+		Session:   session,
+		Symbol:    symbol,
+		Instances: make(map[string]fixedpoint.Value),
+	}
+
+	for instanceID, position := range s.positions[positionNettingKey{session: session, symbol: symbol}] {
+		base := position.GetBase()
+		netPosition.Instances[instanceID] = base
+
+		if base.Sign() > 0 {
+			netPosition.Long = netPosition.Long.Add(base)
+		} else {
+			netPosition.Short = netPosition.Short.Add(base)
+		}
+	}
+
+	netPosition.Net = netPosition.Long.Add(netPosition.Short)
+	return netPosition
+}
+
+// NetPositions returns the netted positions of all the registered symbols sorted by the session and the symbol
+func (s *PositionNettingService) NetPositions() []NetPosition {
+	s.mu.Lock()
+	keys := make([]positionNettingKey, 0, len(s.positions))
+	for key := range s.positions {
+		keys = append(keys, key)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].session == keys[j].session {
+			return keys[i].symbol < keys[j].symbol
+		}
+		return keys[i].session < keys[j].session
+	})
+
+	netPositions := make([]NetPosition, 0, len(keys))
+	for _, key := range keys {
+		netPositions = append(netPositions, s.NetPosition(key.session, key.symbol))
+	}
+
+	return netPositions
+}
+
+// NettedBase returns the share of the net exposure held by the given position.
+// The net exposure is allocated to the positions on the same side as the net exposure pro rata,
+// the positions on the opposite side are fully offset and get zero.
+// The position base is returned as it is if the position is not registered.
+func (s *PositionNettingService) NettedBase(session string, position *types.Position) fixedpoint.Value {
+	base := position.GetBase()
+
+	s.mu.Lock()
+	positions := s.positions[positionNettingKey{session: session, symbol: position.Symbol}]
+
+	registered := false
+	for _, p := range positions {
+		if p == position {
+			registered = true
+			break
+		}
+	}
+	s.mu.Unlock()
+
+	if !registered || base.IsZero() {
+		return base
+	}
+
+	netPosition := s.NetPosition(session, position.Symbol)
+	if netPosition.Net.Sign() != base.Sign() {
+		return fixedpoint.Zero
+	}
+
+	sameSide := netPosition.Long
+	if base.Sign() < 0 {
+		sameSide = netPosition.Short
+	}
+
+	return netPosition.Net.Mul(base).Div(sameSide)
+}
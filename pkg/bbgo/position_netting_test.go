@@ -0,0 +1,47 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestPositionNettingService(t *testing.T) {
+	service := NewPositionNettingService(&PositionNettingConfig{Symbols: []string{"BTCUSDT"}})
+
+	maker := &types.Position{Symbol: "BTCUSDT", Base: fixedpoint.NewFromFloat(3.0)}
+	grid := &types.Position{Symbol: "BTCUSDT", Base: fixedpoint.NewFromFloat(1.0)}
+	trend := &types.Position{Symbol: "BTCUSDT", Base: fixedpoint.NewFromFloat(-2.0)}
+	other := &types.Position{Symbol: "ETHUSDT", Base: fixedpoint.NewFromFloat(5.0)}
+
+	assert.True(t, service.Register("binance", "binance.scmaker:BTCUSDT", maker))
+	assert.True(t, service.Register("binance", "binance.grid2:BTCUSDT", grid))
+	assert.True(t, service.Register("binance", "binance.trend:BTCUSDT", trend))
+	assert.False(t, service.Register("binance", "binance.scmaker:ETHUSDT", other))
+
+	netPosition := service.NetPosition("binance", "BTCUSDT")
+	assert.Equal(t, "4", netPosition.Long.String())
+	assert.Equal(t, "-2", netPosition.Short.String())
+	assert.Equal(t, "2", netPosition.Net.String())
+	assert.Len(t, netPosition.Instances, 3)
+
+	// the net long exposure 2 is allocated to the long positions pro rata, the short position is fully offset
+	assert.Equal(t, "1.5", service.NettedBase("binance", maker).String())
+	assert.Equal(t, "0.5", service.NettedBase("binance", grid).String())
+	assert.Equal(t, "0", service.NettedBase("binance", trend).String())
+
+	// the unregistered position is not netted
+	assert.Equal(t, "5", service.NettedBase("binance", other).String())
+	assert.Equal(t, "3", service.NettedBase("okex", maker).String())
+
+	service.Unregister("binance", "binance.trend:BTCUSDT")
+	assert.Equal(t, "3", service.NettedBase("binance", maker).String())
+
+	netPositions := service.NetPositions()
+	if assert.Len(t, netPositions, 1) {
+		assert.Equal(t, "4", netPositions[0].Net.String())
+	}
+}
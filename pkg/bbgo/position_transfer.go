@@ -0,0 +1,161 @@
+package bbgo
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/interact"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// PositionTransfer is the record of a position moved between the strategy instances,
+// or an external position imported into a strategy instance when From is empty.
+type PositionTransfer struct {
+	From   string `json:"from,omitempty"`
+	To     string `json:"to"`
+	Symbol string `json:"symbol"`
+
+	// Base is the signed base quantity added to the target position, it's negative for a short position
+	Base fixedpoint.Value `json:"base"`
+
+	// Price is the cost basis of the moved quantity
+	Price fixedpoint.Value `json:"price"`
+
+	Time time.Time `json:"time"`
+}
+
+func (t *PositionTransfer) String() string {
+	if t.From == "" {
+		return fmt.Sprintf("import %s %s @ %s into %s", t.Base.String(), t.Symbol, t.Price.String(), t.To)
+	}
+
+	return fmt.Sprintf("transfer %s %s @ %s from %s to %s", t.Base.String(), t.Symbol, t.Price.String(), t.From, t.To)
+}
+
+// AuditEntry returns the audit log entry of the transfer
+func (t *PositionTransfer) AuditEntry(source, user string, role interact.Role) interact.AuditEntry {
+	command := "transferposition"
+	args := []string{t.From, t.To, t.Symbol, t.Base.String(), t.Price.String()}
+	if t.From == "" {
+		command = "importposition"
+		args = args[1:]
+	}
+
+	return interact.AuditEntry{
+		Time:    t.Time,
+		Source:  source,
+		User:    user,
+		Role:    role,
+		Command: command,
+		Args:    args,
+		Result:  interact.AuditResultOK,
+	}
+}
+
+// LookupPosition returns the position of the symbol held by the strategy instance
+func (i *StrategyInstance) LookupPosition(symbol string) (*types.Position, error) {
+	for _, position := range i.Positions() {
+		if position.Symbol == symbol {
+			return position, nil
+		}
+	}
+
+	return nil, fmt.Errorf("strategy instance %s has no %s position", i.ID, symbol)
+}
+
+// TransferPosition moves the quantity of the symbol position from one strategy instance to another at the average cost of the source,
+// so no profit is realized on the source and the target averages the moved quantity into its cost basis.
+// The whole position is moved if the quantity is zero. The open orders are not moved, they stay with the source strategy.
+func TransferPosition(from, to *StrategyInstance, symbol string, quantity fixedpoint.Value, now time.Time) (*PositionTransfer, error) {
+	if from.ID == to.ID {
+		return nil, fmt.Errorf("can not transfer the position of %s to itself", from.ID)
+	}
+
+	source, err := from.LookupPosition(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := to.LookupPosition(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	base := source.GetBase()
+	if base.IsZero() {
+		return nil, fmt.Errorf("the %s position of %s is empty", symbol, from.ID)
+	}
+
+	if quantity.Sign() < 0 {
+		return nil, fmt.Errorf("quantity must be positive, got %s", quantity.String())
+	} else if quantity.IsZero() {
+		quantity = base.Abs()
+	} else if quantity.Compare(base.Abs()) > 0 {
+		return nil, fmt.Errorf("quantity %s exceeds the %s position %s of %s", quantity.String(), symbol, base.String(), from.ID)
+	}
+
+	if base.Sign() < 0 {
+		quantity = quantity.Neg()
+	}
+
+	price := source.AverageCost
+	source.AddTrade(positionAdjustmentTrade(source, quantity.Neg(), price, now))
+	target.AddTrade(positionAdjustmentTrade(target, quantity, price, now))
+
+	return &PositionTransfer{
+		From:   from.ID,
+		To:     to.ID,
+		Symbol: symbol,
+		Base:   quantity,
+		Price:  price,
+		Time:   now,
+	}, nil
+}
+
+// ImportPosition adds the externally held base quantity (e.g., the manual buys) at the given price into the symbol position of the strategy instance,
+// the base is negative for a short position.
+func ImportPosition(to *StrategyInstance, symbol string, base, price fixedpoint.Value, now time.Time) (*PositionTransfer, error) {
+	if base.IsZero() {
+		return nil, fmt.Errorf("base must not be zero")
+	}
+
+	if price.Sign() <= 0 {
+		return nil, fmt.Errorf("price must be positive, got %s", price.String())
+	}
+
+	target, err := to.LookupPosition(symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	target.AddTrade(positionAdjustmentTrade(target, base, price, now))
+
+	return &PositionTransfer{
+		To:     to.ID,
+		Symbol: symbol,
+		Base:   base,
+		Price:  price,
+		Time:   now,
+	}, nil
+}
+
+// positionAdjustmentTrade returns the fee-less trade that adds the signed base quantity to the position
+func positionAdjustmentTrade(position *types.Position, base, price fixedpoint.Value, now time.Time) types.Trade {
+	side := types.SideTypeBuy
+	if base.Sign() < 0 {
+		side = types.SideTypeSell
+	}
+
+	quantity := base.Abs()
+	return types.Trade{
+		Symbol:        position.Symbol,
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: quantity.Mul(price),
+		FeeCurrency:   position.QuoteCurrency,
+		Time:          types.Time(now),
+	}
+}
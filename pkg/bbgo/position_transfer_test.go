@@ -0,0 +1,96 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newTestTransferInstance(id string, base, averageCost fixedpoint.Value) *StrategyInstance {
+	position := types.NewPositionFromMarket(types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"})
+	position.Base = base
+	position.Quote = base.Mul(averageCost).Neg()
+	position.AverageCost = averageCost
+	position.ApproximateAverageCost = averageCost
+
+	return &StrategyInstance{ID: id, Strategy: &testProfitLockStrategy{Position: position}}
+}
+
+func TestTransferPosition(t *testing.T) {
+	now := time.Now()
+	from := newTestTransferInstance("binance.a", fixedpoint.NewFromInt(2), fixedpoint.NewFromInt(20_000))
+	to := newTestTransferInstance("binance.b", fixedpoint.One, fixedpoint.NewFromInt(23_000))
+
+	transfer, err := TransferPosition(from, to, "BTCUSDT", fixedpoint.One, now)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "1", transfer.Base.String())
+		assert.Equal(t, "20000", transfer.Price.String())
+		assert.Equal(t, []string{"binance.a", "binance.b", "BTCUSDT", "1", "20000"}, transfer.AuditEntry("cli", "", "").Args)
+	}
+
+	source, _ := from.LookupPosition("BTCUSDT")
+	target, _ := to.LookupPosition("BTCUSDT")
+	assert.Equal(t, "1", source.Base.String())
+	assert.Equal(t, "20000", source.AverageCost.String())
+	assert.True(t, source.AccumulatedProfit.IsZero())
+	assert.Equal(t, "2", target.Base.String())
+	assert.Equal(t, "21500", target.AverageCost.String())
+
+	// the whole position is moved if the quantity is zero
+	_, err = TransferPosition(from, to, "BTCUSDT", fixedpoint.Zero, now)
+	assert.NoError(t, err)
+	assert.True(t, source.Base.IsZero())
+	assert.Equal(t, "3", target.Base.String())
+
+	_, err = TransferPosition(from, to, "BTCUSDT", fixedpoint.Zero, now)
+	assert.Error(t, err, "the source position is empty")
+
+	_, err = TransferPosition(to, from, "BTCUSDT", fixedpoint.NewFromInt(4), now)
+	assert.Error(t, err, "the quantity exceeds the position")
+
+	_, err = TransferPosition(to, to, "BTCUSDT", fixedpoint.One, now)
+	assert.Error(t, err)
+
+	_, err = TransferPosition(to, from, "ETHUSDT", fixedpoint.One, now)
+	assert.Error(t, err)
+}
+
+func TestTransferPosition_Short(t *testing.T) {
+	from := newTestTransferInstance("binance.a", fixedpoint.NewFromInt(-2), fixedpoint.NewFromInt(20_000))
+	to := newTestTransferInstance("binance.b", fixedpoint.Zero, fixedpoint.Zero)
+
+	transfer, err := TransferPosition(from, to, "BTCUSDT", fixedpoint.One, time.Now())
+	if assert.NoError(t, err) {
+		assert.Equal(t, "-1", transfer.Base.String())
+	}
+
+	source, _ := from.LookupPosition("BTCUSDT")
+	target, _ := to.LookupPosition("BTCUSDT")
+	assert.Equal(t, "-1", source.Base.String())
+	assert.Equal(t, "-1", target.Base.String())
+	assert.Equal(t, "20000", target.AverageCost.String())
+}
+
+func TestImportPosition(t *testing.T) {
+	to := newTestTransferInstance("binance.b", fixedpoint.One, fixedpoint.NewFromInt(20_000))
+
+	transfer, err := ImportPosition(to, "BTCUSDT", fixedpoint.One, fixedpoint.NewFromInt(22_000), time.Now())
+	if assert.NoError(t, err) {
+		assert.Empty(t, transfer.From)
+		assert.Equal(t, "importposition", transfer.AuditEntry("web", "alice", "").Command)
+	}
+
+	target, _ := to.LookupPosition("BTCUSDT")
+	assert.Equal(t, "2", target.Base.String())
+	assert.Equal(t, "21000", target.AverageCost.String())
+
+	_, err = ImportPosition(to, "BTCUSDT", fixedpoint.Zero, fixedpoint.NewFromInt(22_000), time.Now())
+	assert.Error(t, err)
+
+	_, err = ImportPosition(to, "BTCUSDT", fixedpoint.One, fixedpoint.Zero, time.Now())
+	assert.Error(t, err)
+}
@@ -0,0 +1,190 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/cenkalti/backoff/v4"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// PostOnlyRetryOptions reprices the LimitMaker orders rejected because they would cross the book
+type PostOnlyRetryOptions struct {
+	// Ticks is the number of the ticks to move the price away from the book on each retry, defaults to 1
+	Ticks int `json:"ticks,omitempty"`
+
+	// MaxRetries is the max number of the retries of one order, defaults to 3
+	MaxRetries int `json:"maxRetries,omitempty"`
+}
+
+// postOnlyRejectionMatchers match the error codes of the exchanges when a post-only order would take the liquidity.
+// The exchanges not listed here accept the post-only order and cancel it afterward, the canceled order is not repriced.
+var postOnlyRejectionMatchers = map[types.ExchangeName]func(err error) bool{
+	types.ExchangeBinance: isBinancePostOnlyRejection,
+}
+
+func isBinancePostOnlyRejection(err error) bool {
+	var apiErr *common.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	switch apiErr.Code {
+	case -2010:
+		// NEW_ORDER_REJECTED is also returned for the other reasons, e.g., the insufficient balance
+		return strings.Contains(apiErr.Message, "would immediately match")
+
+	case -5022:
+		// the futures GTX order could not be executed as maker
+		return true
+	}
+
+	return false
+}
+
+// IsPostOnlyRejection checks if the order submission error of the exchange is the rejection of a post-only (LimitMaker) order
+func IsPostOnlyRejection(exchange types.ExchangeName, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if match, ok := postOnlyRejectionMatchers[exchange]; ok {
+		return match(err)
+	}
+
+	return false
+}
+
+// EnablePostOnlyRetry enables the repricing of the rejected LimitMaker orders,
+// the OnPostOnlyRetryExhausted callbacks are called when the order is still rejected after the max retries.
+func (e *GeneralOrderExecutor) EnablePostOnlyRetry(options PostOnlyRetryOptions) {
+	if options.Ticks <= 0 {
+		options.Ticks = 1
+	}
+
+	if options.MaxRetries <= 0 {
+		options.MaxRetries = 3
+	}
+
+	e.postOnlyRetry = &options
+}
+
+func splitPostOnlyOrders(submitOrders []types.SubmitOrder) (postOnlyOrders, otherOrders []types.SubmitOrder) {
+	for _, submitOrder := range submitOrders {
+		if submitOrder.Type == types.OrderTypeLimitMaker {
+			postOnlyOrders = append(postOnlyOrders, submitOrder)
+		} else {
+			otherOrders = append(otherOrders, submitOrder)
+		}
+	}
+
+	return postOnlyOrders, otherOrders
+}
+
+// submitPostOnlyOrders submits the LimitMaker orders one by one,
+// the order rejected because it would cross the book is repriced away from the book by the ticks and retried.
+func (e *GeneralOrderExecutor) submitPostOnlyOrders(ctx context.Context, orderCallback OrderCallback, submitOrders ...types.SubmitOrder) (types.OrderSlice, error) {
+	var createdOrders types.OrderSlice
+	var err error
+
+	for _, submitOrder := range submitOrders {
+		createdOrder, err2 := e.submitPostOnlyOrder(ctx, submitOrder)
+		if err2 != nil {
+			err = multierr.Append(err, err2)
+			continue
+		}
+
+		if createdOrder != nil {
+			createdOrder.Tag = submitOrder.Tag
+			if orderCallback != nil {
+				orderCallback(*createdOrder)
+			}
+
+			createdOrders = append(createdOrders, *createdOrder)
+		}
+	}
+
+	return createdOrders, err
+}
+
+func (e *GeneralOrderExecutor) submitPostOnlyOrder(ctx context.Context, submitOrder types.SubmitOrder) (*types.Order, error) {
+	tickSize := submitOrder.Market.TickSize
+	priceStep := tickSize.Mul(fixedpoint.NewFromInt(int64(e.postOnlyRetry.Ticks)))
+
+	for retry := 0; ; retry++ {
+		createdOrder, err := e.submitPostOnlyAttempt(ctx, submitOrder)
+		if err == nil || !IsPostOnlyRejection(e.session.ExchangeName, err) || tickSize.IsZero() {
+			return createdOrder, err
+		}
+
+		if retry >= e.postOnlyRetry.MaxRetries {
+			e.EmitPostOnlyRetryExhausted(submitOrder, err)
+			return nil, err
+		}
+
+		// move the price away from the book, the buy order is lowered and the sell order is raised
+		price := submitOrder.Price
+		if submitOrder.Side == types.SideTypeBuy {
+			price = price.Sub(priceStep)
+		} else {
+			price = price.Add(priceStep)
+		}
+
+		if price.Sign() <= 0 {
+			e.EmitPostOnlyRetryExhausted(submitOrder, err)
+			return nil, err
+		}
+
+		log.Warnf("post-only order %s is rejected, repricing from %s to %s (retry #%d): %v",
+			submitOrder.String(), submitOrder.Price.String(), price.String(), retry+1, err)
+
+		submitOrder.Price = price
+	}
+}
+
+// submitPostOnlyAttempt submits the order at the current price, the transport errors are retried like BatchRetryPlaceOrder
+// when the max retries of the executor is set, and the post-only rejection is returned to be repriced.
+func (e *GeneralOrderExecutor) submitPostOnlyAttempt(ctx context.Context, submitOrder types.SubmitOrder) (*types.Order, error) {
+	if e.maxRetries == 0 {
+		return submitOrderWithSpan(ctx, e.session.Exchange, submitOrder)
+	}
+
+	var logger log.FieldLogger = log.StandardLogger()
+	if e.logger != nil {
+		logger = e.logger
+	}
+
+	timeoutCtx, cancelTimeout := context.WithTimeout(ctx, DefaultSubmitOrderRetryTimeout)
+	defer cancelTimeout()
+
+	var createdOrder *types.Order
+	op := func() error {
+		order, err := submitOrderWithSpan(timeoutCtx, e.session.Exchange, submitOrder)
+		if err != nil {
+			if IsPostOnlyRejection(e.session.ExchangeName, err) {
+				return backoff.Permanent(err)
+			}
+
+			logger.WithError(err).Errorf("submit order error: %s", submitOrder.String())
+			return err
+		}
+
+		createdOrder = order
+		return nil
+	}
+
+	var bo backoff.BackOff = backoff.NewExponentialBackOff()
+	bo = backoff.WithMaxRetries(bo, uint64(e.maxRetries))
+	bo = backoff.WithContext(bo, timeoutCtx)
+	if err := backoff.Retry(op, bo); err != nil {
+		return nil, err
+	}
+
+	return createdOrder, nil
+}
@@ -0,0 +1,135 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/adshao/go-binance/v2/common"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func TestIsPostOnlyRejection(t *testing.T) {
+	binance := types.ExchangeBinance
+	assert.True(t, IsPostOnlyRejection(binance, &common.APIError{Code: -2010, Message: "Order would immediately match and take."}))
+	assert.True(t, IsPostOnlyRejection(binance, fmt.Errorf("submit order error: %w", &common.APIError{Code: -5022, Message: "Due to the order could not be executed as maker, the Post Only order will be rejected."})))
+	assert.False(t, IsPostOnlyRejection(binance, &common.APIError{Code: -2010, Message: "Account has insufficient balance for requested action."}))
+	assert.False(t, IsPostOnlyRejection(binance, errors.New("Order would immediately match and take.")), "only the api error codes are matched")
+	assert.False(t, IsPostOnlyRejection(types.ExchangeMax, errors.New("post_only order would take")))
+	assert.False(t, IsPostOnlyRejection(binance, nil))
+}
+
+func TestGeneralOrderExecutor_PostOnlyRetry(t *testing.T) {
+	market := getTestMarket()
+	market.TickSize = fixedpoint.NewFromFloat(0.01)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	rejection := &common.APIError{Code: -2010, Message: "Order would immediately match and take."}
+
+	var buyPrices, sellPrices []string
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+	mockEx.EXPECT().SubmitOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+		switch order.Side {
+		case types.SideTypeBuy:
+			buyPrices = append(buyPrices, order.Price.String())
+			if len(buyPrices) < 3 {
+				return nil, rejection
+			}
+
+		case types.SideTypeSell:
+			sellPrices = append(sellPrices, order.Price.String())
+			return nil, rejection
+		}
+
+		return &types.Order{SubmitOrder: order, OrderID: 1, Status: types.OrderStatusNew}, nil
+	}).Times(6)
+
+	session := NewExchangeSession("test", mockEx)
+	session.ExchangeName = types.ExchangeBinance
+	session.markets[market.Symbol] = market
+
+	orderExecutor := NewGeneralOrderExecutor(session, "BTCUSDT", "test", "test-01", types.NewPositionFromMarket(market))
+	orderExecutor.EnablePostOnlyRetry(PostOnlyRetryOptions{Ticks: 2, MaxRetries: 2})
+
+	var exhausted []types.SubmitOrder
+	orderExecutor.OnPostOnlyRetryExhausted(func(order types.SubmitOrder, err error) {
+		exhausted = append(exhausted, order)
+	})
+
+	createdOrders, err := orderExecutor.SubmitOrders(context.Background(), types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimitMaker,
+		Price:    fixedpoint.NewFromFloat(20000.0),
+		Quantity: fixedpoint.NewFromFloat(0.1),
+		Market:   market,
+	}, types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeLimitMaker,
+		Price:    fixedpoint.NewFromFloat(20001.0),
+		Quantity: fixedpoint.NewFromFloat(0.1),
+		Market:   market,
+	})
+
+	assert.Error(t, err)
+	if assert.Len(t, createdOrders, 1) {
+		assert.Equal(t, "19999.96", createdOrders[0].Price.String())
+	}
+
+	assert.Equal(t, []string{"20000", "19999.98", "19999.96"}, buyPrices)
+	assert.Equal(t, []string{"20001", "20001.02", "20001.04"}, sellPrices)
+
+	if assert.Len(t, exhausted, 1) {
+		assert.Equal(t, types.SideTypeSell, exhausted[0].Side)
+	}
+}
+
+func TestGeneralOrderExecutor_PostOnlyRetry_TransportError(t *testing.T) {
+	market := getTestMarket()
+	market.TickSize = fixedpoint.NewFromFloat(0.01)
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	submitOrder := types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimitMaker,
+		Price:    fixedpoint.NewFromFloat(20000.0),
+		Quantity: fixedpoint.NewFromFloat(0.1),
+		Market:   market,
+	}
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+	gomock.InOrder(
+		mockEx.EXPECT().SubmitOrder(gomock.Any(), gomock.Any()).Return(nil, errors.New("connection reset by peer")),
+		mockEx.EXPECT().SubmitOrder(gomock.Any(), gomock.Any()).DoAndReturn(func(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+			return &types.Order{SubmitOrder: order, OrderID: 1, Status: types.OrderStatusNew}, nil
+		}),
+	)
+
+	session := NewExchangeSession("test", mockEx)
+	session.ExchangeName = types.ExchangeBinance
+	session.markets[market.Symbol] = market
+
+	orderExecutor := NewGeneralOrderExecutor(session, "BTCUSDT", "test", "test-01", types.NewPositionFromMarket(market))
+	orderExecutor.SetMaxRetries(3)
+	orderExecutor.EnablePostOnlyRetry(PostOnlyRetryOptions{})
+
+	// the transport error is retried at the same price
+	createdOrders, err := orderExecutor.SubmitOrders(context.Background(), submitOrder)
+	if assert.NoError(t, err) && assert.Len(t, createdOrders, 1) {
+		assert.Equal(t, "20000", createdOrders[0].Price.String())
+	}
+}
@@ -0,0 +1,275 @@
+package bbgo
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/wcharczuk/go-chart/v2"
+	"github.com/wcharczuk/go-chart/v2/drawing"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	defaultProfitChartWindow = 48
+	defaultProfitChartWidth  = 640
+	defaultProfitChartHeight = 320
+)
+
+var (
+	candleUpColor   = drawing.ColorFromHex("26a69a")
+	candleDownColor = drawing.ColorFromHex("ef5350")
+	buyFillColor    = drawing.ColorFromHex("1565c0")
+	sellFillColor   = drawing.ColorFromHex("ff8f00")
+)
+
+// ProfitChartConfig attaches the candlestick chart of the recent klines with the entry and exit fills to the profit notifications
+type ProfitChartConfig struct {
+	// Interval is the kline interval of the chart, defaults to 5m
+	Interval types.Interval `json:"interval,omitempty" yaml:"interval,omitempty"`
+
+	// Window is the number of the klines in the chart, defaults to 48
+	Window int `json:"window,omitempty" yaml:"window,omitempty"`
+
+	// Width and Height are the image size in pixels, defaults to 640x320
+	Width  int `json:"width,omitempty" yaml:"width,omitempty"`
+	Height int `json:"height,omitempty" yaml:"height,omitempty"`
+}
+
+func (c *ProfitChartConfig) Validate() error {
+	if c.Interval != "" {
+		if _, ok := types.SupportedIntervals[c.Interval]; !ok {
+			return fmt.Errorf("unsupported profit chart interval %s", c.Interval)
+		}
+	}
+
+	if c.Window < 0 || c.Width < 0 || c.Height < 0 {
+		return fmt.Errorf("profit chart window and size can not be negative")
+	}
+
+	return nil
+}
+
+func (c *ProfitChartConfig) Defaults() {
+	if c.Interval == "" {
+		c.Interval = types.Interval5m
+	}
+
+	if c.Window == 0 {
+		c.Window = defaultProfitChartWindow
+	}
+
+	if c.Width == 0 {
+		c.Width = defaultProfitChartWidth
+	}
+
+	if c.Height == 0 {
+		c.Height = defaultProfitChartHeight
+	}
+}
+
+// candlestickSeries renders the klines as the candlesticks
+type candlestickSeries struct {
+	klines []types.KLine
+}
+
+func (s *candlestickSeries) GetName() string           { return "klines" }
+func (s *candlestickSeries) GetYAxis() chart.YAxisType { return chart.YAxisPrimary }
+func (s *candlestickSeries) GetStyle() chart.Style     { return chart.Style{} }
+
+func (s *candlestickSeries) Validate() error {
+	if len(s.klines) == 0 {
+		return fmt.Errorf("candlestick series has no kline")
+	}
+
+	return nil
+}
+
+func (s *candlestickSeries) Len() int {
+	return len(s.klines)
+}
+
+// GetBoundedValues implements chart.BoundedValuesProvider, so that the y range covers the highs and the lows
+func (s *candlestickSeries) GetBoundedValues(index int) (x, y1, y2 float64) {
+	k := s.klines[index]
+	return chart.TimeToFloat64(k.StartTime.Time()), k.High.Float64(), k.Low.Float64()
+}
+
+func (s *candlestickSeries) Render(r chart.Renderer, canvasBox chart.Box, xrange, yrange chart.Range, defaults chart.Style) {
+	halfWidth := canvasBox.Width() / len(s.klines) / 3
+	if halfWidth < 1 {
+		halfWidth = 1
+	}
+
+	toY := func(v float64) int {
+		return canvasBox.Bottom - yrange.Translate(v)
+	}
+
+	for _, k := range s.klines {
+		x := canvasBox.Left + xrange.Translate(chart.TimeToFloat64(k.StartTime.Time()))
+
+		color := candleUpColor
+		if k.Close.Compare(k.Open) < 0 {
+			color = candleDownColor
+		}
+
+		r.SetStrokeColor(color)
+		r.SetFillColor(color)
+		r.SetStrokeWidth(1)
+
+		// wick
+		r.MoveTo(x, toY(k.High.Float64()))
+		r.LineTo(x, toY(k.Low.Float64()))
+		r.Stroke()
+
+		// body
+		top, bottom := toY(k.Open.Float64()), toY(k.Close.Float64())
+		if top > bottom {
+			top, bottom = bottom, top
+		}
+
+		if bottom == top {
+			bottom++
+		}
+
+		r.MoveTo(x-halfWidth, top)
+		r.LineTo(x+halfWidth, top)
+		r.LineTo(x+halfWidth, bottom)
+		r.LineTo(x-halfWidth, bottom)
+		r.Close()
+		r.FillStroke()
+	}
+}
+
+func fillSeries(name string, color drawing.Color, trades []types.Trade) chart.TimeSeries {
+	series := chart.TimeSeries{
+		Name: name,
+		Style: chart.Style{
+			StrokeWidth: chart.Disabled,
+			DotWidth:    5,
+			DotColor:    color,
+		},
+	}
+
+	for _, trade := range trades {
+		series.XValues = append(series.XValues, trade.Time.Time())
+		series.YValues = append(series.YValues, trade.Price.Float64())
+	}
+
+	return series
+}
+
+// RenderProfitChart renders the candlestick chart PNG of the klines, the buy and the sell fills are marked at their prices
+func RenderProfitChart(title string, klines []types.KLine, trades []types.Trade, width, height int) (*bytes.Buffer, error) {
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("can not render the chart of %s without klines", title)
+	}
+
+	var buys, sells []types.Trade
+	for _, trade := range trades {
+		if trade.Side == types.SideTypeBuy {
+			buys = append(buys, trade)
+		} else {
+			sells = append(sells, trade)
+		}
+	}
+
+	graph := chart.Chart{
+		Title:  title,
+		Width:  width,
+		Height: height,
+		Background: chart.Style{
+			Padding: chart.Box{Top: 40, Left: 10, Right: 10, Bottom: 10},
+		},
+		XAxis: chart.XAxis{
+			ValueFormatter: chart.TimeMinuteValueFormatter,
+		},
+		YAxis: chart.YAxis{
+			ValueFormatter: func(v interface{}) string {
+				if f, ok := v.(float64); ok {
+					return fmt.Sprintf("%.6g", f)
+				}
+				return ""
+			},
+		},
+		Series: []chart.Series{&candlestickSeries{klines: klines}},
+	}
+
+	if len(buys) > 0 {
+		graph.Series = append(graph.Series, fillSeries("buy", buyFillColor, buys))
+	}
+
+	if len(sells) > 0 {
+		graph.Series = append(graph.Series, fillSeries("sell", sellFillColor, sells))
+	}
+
+	graph.Elements = []chart.Renderable{chart.LegendThin(&graph)}
+
+	var buffer bytes.Buffer
+	if err := graph.Render(chart.PNG, &buffer); err != nil {
+		return nil, err
+	}
+
+	return &buffer, nil
+}
+
+// notifyProfitChart sends the chart of the recent klines with the fills of the executor after the profit notification
+func (e *GeneralOrderExecutor) notifyProfitChart(trade types.Trade) {
+	if e.environ == nil {
+		return
+	}
+
+	config := e.environ.ProfitChartConfig()
+	if config == nil {
+		return
+	}
+
+	trades := e.RecentTrades()
+	if len(trades) == 0 || trades[len(trades)-1].ID != trade.ID {
+		trades = append(trades, trade)
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		endTime := e.environ.Clock().Now()
+		klines, err := e.session.Exchange.QueryKLines(ctx, e.symbol, config.Interval, types.KLineQueryOptions{
+			EndTime: &endTime,
+			Limit:   config.Window,
+		})
+		if err != nil {
+			log.WithError(err).Errorf("can not query the klines of the profit chart")
+			return
+		}
+
+		if len(klines) == 0 {
+			return
+		}
+
+		// only the fills within the chart are marked
+		startTime := klines[0].StartTime.Time()
+		var fills []types.Trade
+		for _, t := range trades {
+			if !t.Time.Time().Before(startTime) {
+				fills = append(fills, t)
+			}
+		}
+
+		title := fmt.Sprintf("%s %s %s", e.strategyInstanceID, e.symbol, config.Interval)
+		buffer, err := RenderProfitChart(title, klines, fills, config.Width, config.Height)
+		if err != nil {
+			log.WithError(err).Errorf("can not render the profit chart")
+			return
+		}
+
+		if channel, ok := Notification.RouteSymbol(e.symbol); ok {
+			SendPhotoTo(channel, buffer)
+		} else {
+			SendPhoto(buffer)
+		}
+	}()
+}
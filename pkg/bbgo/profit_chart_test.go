@@ -0,0 +1,50 @@
+package bbgo
+
+import (
+	"image/png"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestRenderProfitChart(t *testing.T) {
+	base := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	var klines []types.KLine
+	for i := 0; i < 10; i++ {
+		open := 20000.0 + float64(i*10)
+		klines = append(klines, types.KLine{
+			Symbol:    "BTCUSDT",
+			Interval:  types.Interval5m,
+			StartTime: types.Time(base.Add(time.Duration(i) * 5 * time.Minute)),
+			EndTime:   types.Time(base.Add(time.Duration(i+1)*5*time.Minute - time.Millisecond)),
+			Open:      fixedpoint.NewFromFloat(open),
+			Close:     fixedpoint.NewFromFloat(open + float64(i%3-1)*15),
+			High:      fixedpoint.NewFromFloat(open + 30),
+			Low:       fixedpoint.NewFromFloat(open - 30),
+		})
+	}
+
+	trades := []types.Trade{
+		{ID: 1, Side: types.SideTypeBuy, Price: fixedpoint.NewFromFloat(20010), Time: types.Time(base.Add(7 * time.Minute))},
+		{ID: 2, Side: types.SideTypeSell, Price: fixedpoint.NewFromFloat(20080), Time: types.Time(base.Add(42 * time.Minute))},
+	}
+
+	buffer, err := RenderProfitChart("BTCUSDT", klines, trades, 640, 320)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	img, err := png.Decode(buffer)
+	if assert.NoError(t, err) {
+		assert.Equal(t, 640, img.Bounds().Dx())
+		assert.Equal(t, 320, img.Bounds().Dy())
+	}
+
+	_, err = RenderProfitChart("BTCUSDT", nil, trades, 640, 320)
+	assert.Error(t, err)
+}
@@ -0,0 +1,279 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultProfitLockInterval = time.Minute
+
+// profitLockStoreTag is the persistence tag of the profit lock state of a strategy instance
+const profitLockStoreTag = "profit_lock"
+
+// ProfitLockConfig protects the profit of a strategy instance, it's configured by the `profitLock` key of the strategy mount.
+// Once the profit of the instance reaches the activation, a trailing floor is kept at peak profit * (1 - trailingRatio),
+// the position is flattened and the strategy is suspended when the profit falls below the floor.
+type ProfitLockConfig struct {
+	// Activation is the profit in the quote currency that arms the lock
+	Activation fixedpoint.Value `json:"activation" yaml:"activation"`
+
+	// TrailingRatio is the ratio of the peak profit that can be given back, e.g., 0.3 keeps 70% of the peak profit
+	TrailingRatio fixedpoint.Value `json:"trailingRatio" yaml:"trailingRatio"`
+
+	// Interval is the check interval, defaults to 1 minute
+	Interval types.Duration `json:"interval,omitempty" yaml:"interval,omitempty"`
+}
+
+func (c *ProfitLockConfig) Validate() error {
+	if c.Activation.Sign() <= 0 {
+		return fmt.Errorf("profitLock: activation must be positive, got %s", c.Activation.String())
+	}
+
+	if c.TrailingRatio.Sign() <= 0 || c.TrailingRatio.Compare(fixedpoint.One) >= 0 {
+		return fmt.Errorf("profitLock: trailingRatio must be between 0 and 1, got %s", c.TrailingRatio.String())
+	}
+
+	return nil
+}
+
+// ProfitLockState is the persisted state of the profit lock.
+// The profit is the equity of the instance positions (realized + unrealized) minus the baseline,
+// the baseline is reset to the current equity when the strategy is resumed after the lock is triggered.
+type ProfitLockState struct {
+	Baseline fixedpoint.Value `json:"baseline"`
+	Peak     fixedpoint.Value `json:"peak"`
+	Floor    fixedpoint.Value `json:"floor"`
+	Armed    bool             `json:"armed"`
+
+	Locked   bool      `json:"locked"`
+	LockedAt time.Time `json:"lockedAt,omitempty"`
+}
+
+// update updates the peak and the floor with the current equity and returns true if the floor is breached
+func (s *ProfitLockState) update(config *ProfitLockConfig, equity fixedpoint.Value) bool {
+	profit := equity.Sub(s.Baseline)
+	if !s.Armed {
+		if profit.Compare(config.Activation) < 0 {
+			return false
+		}
+
+		s.Armed = true
+		s.Peak = profit
+	}
+
+	s.Peak = fixedpoint.Max(s.Peak, profit)
+	s.Floor = s.Peak.Mul(fixedpoint.One.Sub(config.TrailingRatio))
+	return profit.Compare(s.Floor) < 0
+}
+
+// reset re-arms the lock from the current equity
+func (s *ProfitLockState) reset(equity fixedpoint.Value) {
+	*s = ProfitLockState{Baseline: equity}
+}
+
+// ProfitLock is the trailing equity protection of a strategy instance
+type ProfitLock struct {
+	config   *ProfitLockConfig
+	instance *StrategyInstance
+	session  *ExchangeSession
+
+	// store persists the state, the lock still holds after the restart; it's nil in the backtest
+	store service.Store
+
+	State ProfitLockState
+
+	logger logrus.FieldLogger
+}
+
+func NewProfitLock(config *ProfitLockConfig, instance *StrategyInstance, session *ExchangeSession, ps service.PersistenceService) *ProfitLock {
+	l := &ProfitLock{
+		config:   config,
+		instance: instance,
+		session:  session,
+		logger:   logrus.WithFields(logrus.Fields{"component": "profitLock", "strategy": instance.ID}),
+	}
+
+	if ps != nil {
+		l.store = ps.NewStore("state", instance.ID, profitLockStoreTag)
+	}
+
+	return l
+}
+
+// Load loads the persisted state, and suspends the strategy again if the lock was triggered before the restart
+func (l *ProfitLock) Load() error {
+	if l.store == nil {
+		return nil
+	}
+
+	if err := l.store.Load(&l.State); err != nil && err != service.ErrPersistenceNotExists {
+		return errors.Wrapf(err, "failed to load the profit lock state of %s", l.instance.ID)
+	}
+
+	if l.State.Locked {
+		l.logger.Warnf("profit lock of %s was triggered at %s, suspending the strategy", l.instance.ID, l.State.LockedAt)
+		if err := l.instance.Suspend(); err != nil {
+			l.logger.WithError(err).Errorf("can not suspend %s", l.instance.ID)
+		}
+	}
+
+	return nil
+}
+
+func (l *ProfitLock) save() {
+	if l.store == nil {
+		return
+	}
+
+	if err := l.store.Save(l.State); err != nil {
+		l.logger.WithError(err).Errorf("can not save the profit lock state of %s", l.instance.ID)
+	}
+}
+
+// Run checks the profit periodically until the context is done
+func (l *ProfitLock) Run(ctx context.Context) {
+	interval := l.config.Interval.Duration()
+	if interval == 0 {
+		interval = defaultProfitLockInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case now := <-ticker.C:
+			l.Check(ctx, now)
+		}
+	}
+}
+
+// Check updates the lock with the current equity of the instance positions,
+// the position is closed and the strategy is suspended if the trailing floor is breached.
+func (l *ProfitLock) Check(ctx context.Context, now time.Time) {
+	equity, ok := l.equity()
+	if !ok {
+		return
+	}
+
+	if l.State.Locked {
+		// the strategy is resumed manually, re-arm the lock from the current equity
+		if reader, ok := l.instance.Strategy.(StrategyStatusReader); ok && reader.GetStatus() == types.StrategyStatusRunning {
+			l.logger.Infof("%s is resumed, re-arming the profit lock from equity %s", l.instance.ID, equity.String())
+			l.State.reset(equity)
+			l.save()
+		}
+
+		return
+	}
+
+	wasArmed := l.State.Armed
+	breached := l.State.update(l.config, equity)
+	if !wasArmed && l.State.Armed {
+		Notify("Profit lock of %s is armed, profit %s reached the activation %s, floor %s",
+			l.instance.ID, l.State.Peak.String(), l.config.Activation.String(), l.State.Floor.String())
+	}
+
+	if breached {
+		l.trigger(ctx, equity, now)
+	}
+
+	l.save()
+}
+
+func (l *ProfitLock) trigger(ctx context.Context, equity fixedpoint.Value, now time.Time) {
+	profit := equity.Sub(l.State.Baseline)
+	l.logger.Warnf("%s profit %s breached the profit lock floor %s (peak %s), flattening the position",
+		l.instance.ID, profit.String(), l.State.Floor.String(), l.State.Peak.String())
+
+	l.State.Locked = true
+	l.State.LockedAt = now
+
+	if err := l.instance.ClosePosition(ctx, fixedpoint.One); err != nil {
+		l.logger.WithError(err).Errorf("can not close the position of %s", l.instance.ID)
+	}
+
+	if err := l.instance.Suspend(); err != nil {
+		l.logger.WithError(err).Errorf("can not suspend %s", l.instance.ID)
+	}
+
+	Notify("Profit lock of %s is triggered, profit %s fell below the floor %s (peak %s), the position is closed and the strategy is suspended",
+		l.instance.ID, profit.String(), l.State.Floor.String(), l.State.Peak.String())
+}
+
+// equity returns the realized and unrealized profit of the instance positions,
+// false is returned if the instance has no position or a position has no price.
+func (l *ProfitLock) equity() (fixedpoint.Value, bool) {
+	positions := l.instance.Positions()
+	if len(positions) == 0 {
+		return fixedpoint.Zero, false
+	}
+
+	equity := fixedpoint.Zero
+	for _, position := range positions {
+		price, ok := sessionMidPrice(l.session, position.Symbol)
+		if !ok {
+			l.logger.Debugf("no price of %s for %s, skipping", position.Symbol, l.instance.ID)
+			return fixedpoint.Zero, false
+		}
+
+		equity = equity.Add(markPosition(l.instance, position, price, time.Now()).Equity)
+	}
+
+	return equity, true
+}
+
+// SetProfitLock enables the profit lock of the strategy instances of the strategy
+func (trader *Trader) SetProfitLock(strategy StrategyID, config *ProfitLockConfig) {
+	if trader.profitLocks == nil {
+		trader.profitLocks = make(map[StrategyID]*ProfitLockConfig)
+	}
+
+	trader.profitLocks[strategy] = config
+}
+
+// runProfitLocks starts the profit locks of the single exchange strategy instances,
+// the profit locks are not supported in the backtest.
+func (trader *Trader) runProfitLocks(ctx context.Context) error {
+	if len(trader.profitLocks) == 0 || trader.environment.BacktestService != nil || IsBackTesting {
+		return nil
+	}
+
+	instances, err := trader.StrategyInstances()
+	if err != nil {
+		return err
+	}
+
+	ps := GetIsolationFromContext(ctx).persistenceServiceFacade.Get()
+	for _, instance := range instances {
+		config, ok := trader.profitLocks[instance.Strategy]
+		if !ok || instance.Session == "" {
+			continue
+		}
+
+		session, ok := trader.environment.Session(instance.Session)
+		if !ok {
+			continue
+		}
+
+		lock := NewProfitLock(config, instance, session, ps)
+		if err := lock.Load(); err != nil {
+			return err
+		}
+
+		go lock.Run(ctx)
+	}
+
+	return nil
+}
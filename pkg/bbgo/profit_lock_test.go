@@ -0,0 +1,123 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+type testProfitLockStrategy struct {
+	StrategyController
+
+	Position *types.Position `json:"position,omitempty"`
+
+	closed []fixedpoint.Value
+}
+
+func (s *testProfitLockStrategy) ID() string { return "test" }
+
+func (s *testProfitLockStrategy) ClosePosition(ctx context.Context, percentage fixedpoint.Value) error {
+	s.closed = append(s.closed, percentage)
+	s.Position.Base = fixedpoint.Zero
+	return nil
+}
+
+func TestProfitLockConfig_Validate(t *testing.T) {
+	assert.NoError(t, (&ProfitLockConfig{Activation: fixedpoint.NewFromInt(100), TrailingRatio: fixedpoint.MustNewFromString("0.3")}).Validate())
+	assert.Error(t, (&ProfitLockConfig{TrailingRatio: fixedpoint.MustNewFromString("0.3")}).Validate())
+	assert.Error(t, (&ProfitLockConfig{Activation: fixedpoint.NewFromInt(100), TrailingRatio: fixedpoint.One}).Validate())
+}
+
+func TestProfitLockState_update(t *testing.T) {
+	config := &ProfitLockConfig{Activation: fixedpoint.NewFromInt(100), TrailingRatio: fixedpoint.MustNewFromString("0.2")}
+
+	var state ProfitLockState
+	assert.False(t, state.update(config, fixedpoint.NewFromInt(50)))
+	assert.False(t, state.Armed)
+
+	// the floor is not enforced before the activation
+	assert.False(t, state.update(config, fixedpoint.NewFromInt(-10)))
+
+	assert.False(t, state.update(config, fixedpoint.NewFromInt(100)))
+	assert.True(t, state.Armed)
+	assert.Equal(t, "80", state.Floor.String())
+
+	// the floor trails the peak
+	assert.False(t, state.update(config, fixedpoint.NewFromInt(150)))
+	assert.Equal(t, "120", state.Floor.String())
+	assert.False(t, state.update(config, fixedpoint.NewFromInt(125)))
+	assert.Equal(t, "120", state.Floor.String())
+	assert.True(t, state.update(config, fixedpoint.NewFromInt(119)))
+
+	// the profit is measured from the baseline after the reset
+	state.reset(fixedpoint.NewFromInt(119))
+	assert.False(t, state.update(config, fixedpoint.NewFromInt(200)))
+	assert.False(t, state.Armed)
+}
+
+func TestProfitLock_Check(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).AnyTimes()
+
+	session := NewExchangeSession("binance", mockEx)
+	session.lastPrices["BTCUSDT"] = fixedpoint.NewFromInt(20000)
+
+	strategy := &testProfitLockStrategy{
+		Position: &types.Position{
+			Symbol:      "BTCUSDT",
+			Base:        fixedpoint.NewFromInt(1),
+			AverageCost: fixedpoint.NewFromInt(20000),
+		},
+	}
+	strategy.Status = types.StrategyStatusRunning
+
+	instance := &StrategyInstance{ID: "binance.test:BTCUSDT", Session: "binance", Strategy: strategy}
+	config := &ProfitLockConfig{Activation: fixedpoint.NewFromInt(100), TrailingRatio: fixedpoint.MustNewFromString("0.5")}
+	ps := service.NewMemoryService()
+
+	ctx := context.Background()
+	now := time.Now()
+	lock := NewProfitLock(config, instance, session, ps)
+	assert.NoError(t, lock.Load())
+
+	lock.Check(ctx, now)
+	assert.False(t, lock.State.Armed)
+
+	// unrealized profit 300 arms the lock with the floor 150
+	session.lastPrices["BTCUSDT"] = fixedpoint.NewFromInt(20300)
+	lock.Check(ctx, now)
+	assert.True(t, lock.State.Armed)
+	assert.Equal(t, "150", lock.State.Floor.String())
+
+	session.lastPrices["BTCUSDT"] = fixedpoint.NewFromInt(20140)
+	lock.Check(ctx, now)
+	assert.True(t, lock.State.Locked)
+	assert.Equal(t, []fixedpoint.Value{fixedpoint.One}, strategy.closed)
+	assert.Equal(t, types.StrategyStatusStopped, strategy.GetStatus())
+
+	// the lock still holds after the restart
+	strategy.Status = types.StrategyStatusRunning
+	restarted := NewProfitLock(config, instance, session, ps)
+	assert.NoError(t, restarted.Load())
+	assert.True(t, restarted.State.Locked)
+	assert.Equal(t, types.StrategyStatusStopped, strategy.GetStatus())
+
+	// resuming the strategy re-arms the lock from the current equity
+	assert.NoError(t, instance.Resume())
+	strategy.Position.AccumulatedProfit = fixedpoint.NewFromInt(140)
+	restarted.Check(ctx, now)
+	assert.False(t, restarted.State.Locked)
+	assert.Equal(t, "140", restarted.State.Baseline.String())
+	assert.Len(t, strategy.closed, 1)
+}
@@ -10,6 +10,11 @@ type Quota struct {
 	mu        sync.Mutex
 	Available fixedpoint.Value
 	Locked    fixedpoint.Value
+
+	// pool is the shared quota pool of the session, the locked fund is also drawn from the partition of the pool
+	pool      *QuotaPool
+	partition string
+	currency  string
 }
 
 func (q *Quota) Add(fund fixedpoint.Value) {
@@ -23,6 +28,10 @@ func (q *Quota) Lock(fund fixedpoint.Value) bool {
 		return false
 	}
 
+	if q.pool != nil && !q.pool.lock(q.partition, q.currency, fund) {
+		return false
+	}
+
 	q.mu.Lock()
 	q.Available = q.Available.Sub(fund)
 	q.Locked = q.Locked.Add(fund)
@@ -33,12 +42,20 @@ func (q *Quota) Lock(fund fixedpoint.Value) bool {
 
 func (q *Quota) Commit() {
 	q.mu.Lock()
+	if q.pool != nil {
+		q.pool.commit(q.partition, q.currency, q.Locked)
+	}
+
 	q.Locked = fixedpoint.Zero
 	q.mu.Unlock()
 }
 
 func (q *Quota) Rollback() {
 	q.mu.Lock()
+	if q.pool != nil {
+		q.pool.unlock(q.partition, q.currency, q.Locked)
+	}
+
 	q.Available = q.Available.Add(q.Locked)
 	q.Locked = fixedpoint.Zero
 	q.mu.Unlock()
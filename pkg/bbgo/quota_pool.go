@@ -0,0 +1,169 @@
+package bbgo
+
+import (
+	"sync"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// QuotaPoolConfig partitions the balances of the session among the strategy instances
+type QuotaPoolConfig struct {
+	// Partitions maps the strategy instance ID to its partition,
+	// the strategy instances without a partition share the balances left by the partitions
+	Partitions map[string]QuotaPartition `json:"partitions,omitempty" yaml:"partitions,omitempty"`
+}
+
+type QuotaPartition struct {
+	// Priority decides which partition gets the balance first,
+	// a partition can use the unused share of the partitions with the lower priority.
+	Priority int `json:"priority,omitempty" yaml:"priority,omitempty"`
+
+	// Ratio is the share of the balance reserved for the partition
+	Ratio fixedpoint.Value `json:"ratio,omitempty" yaml:"ratio,omitempty"`
+}
+
+// QuotaPool is the session level quota shared by the strategy instances on the same account,
+// the QuotaTransaction created by the pool draws the funds from the partition of the strategy instance,
+// so that the strategies do not place orders with the same balance.
+//
+// The locked funds are held by the partition until the transaction is rolled back,
+// and the committed funds are held until the next balance update, which deducts the balance locked by the orders.
+type QuotaPool struct {
+	account *types.Account
+
+	mu         sync.Mutex
+	partitions map[string]QuotaPartition
+
+	// currency -> partition -> fund
+	locked    map[string]map[string]fixedpoint.Value
+	committed map[string]map[string]fixedpoint.Value
+}
+
+func NewQuotaPool(account *types.Account, config *QuotaPoolConfig) *QuotaPool {
+	pool := &QuotaPool{
+		account:    account,
+		partitions: make(map[string]QuotaPartition),
+		locked:     make(map[string]map[string]fixedpoint.Value),
+		committed:  make(map[string]map[string]fixedpoint.Value),
+	}
+
+	if config != nil {
+		for name, partition := range config.Partitions {
+			pool.partitions[name] = partition
+		}
+	}
+
+	return pool
+}
+
+// SetPartition adds or updates the partition
+func (p *QuotaPool) SetPartition(name string, partition QuotaPartition) {
+	p.mu.Lock()
+	p.partitions[name] = partition
+	p.mu.Unlock()
+}
+
+// NewTransaction creates the quota transaction that draws the funds from the partition
+func (p *QuotaPool) NewTransaction(partition, baseCurrency, quoteCurrency string) *QuotaTransaction {
+	tx := &QuotaTransaction{}
+	tx.BaseAsset.pool, tx.BaseAsset.partition, tx.BaseAsset.currency = p, partition, baseCurrency
+	tx.QuoteAsset.pool, tx.QuoteAsset.partition, tx.QuoteAsset.currency = p, partition, quoteCurrency
+	return tx
+}
+
+// Available returns the fund of the currency that can be locked by the partition
+func (p *QuotaPool) Available(partition, currency string) fixedpoint.Value {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.available(partition, currency)
+}
+
+// Reset releases the committed funds, it's called when the balances are updated
+func (p *QuotaPool) Reset() {
+	p.mu.Lock()
+	p.committed = make(map[string]map[string]fixedpoint.Value)
+	p.mu.Unlock()
+}
+
+func (p *QuotaPool) lock(partition, currency string, fund fixedpoint.Value) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if fund.Compare(p.available(partition, currency)) > 0 {
+		return false
+	}
+
+	addQuotaFund(p.locked, currency, partition, fund)
+	return true
+}
+
+func (p *QuotaPool) unlock(partition, currency string, fund fixedpoint.Value) {
+	p.mu.Lock()
+	addQuotaFund(p.locked, currency, partition, fund.Neg())
+	p.mu.Unlock()
+}
+
+func (p *QuotaPool) commit(partition, currency string, fund fixedpoint.Value) {
+	p.mu.Lock()
+	addQuotaFund(p.locked, currency, partition, fund.Neg())
+	addQuotaFund(p.committed, currency, partition, fund)
+	p.mu.Unlock()
+}
+
+func (p *QuotaPool) used(partition, currency string) fixedpoint.Value {
+	return p.locked[currency][partition].Add(p.committed[currency][partition])
+}
+
+// available is the balance minus the funds used by the other partitions,
+// and minus the unused shares reserved by the other partitions with the same or the higher priority.
+func (p *QuotaPool) available(partition, currency string) fixedpoint.Value {
+	balance, ok := p.account.Balance(currency)
+	if !ok {
+		return fixedpoint.Zero
+	}
+
+	total := balance.Available
+	priority := p.partitions[partition].Priority
+
+	available := total.Sub(p.used(partition, currency))
+
+	users := make(map[string]struct{}, len(p.partitions))
+	for name := range p.partitions {
+		users[name] = struct{}{}
+	}
+	for name := range p.locked[currency] {
+		users[name] = struct{}{}
+	}
+	for name := range p.committed[currency] {
+		users[name] = struct{}{}
+	}
+
+	for name := range users {
+		if name == partition {
+			continue
+		}
+
+		used := p.used(name, currency)
+		available = available.Sub(used)
+
+		other, ok := p.partitions[name]
+		if !ok || other.Priority < priority {
+			continue
+		}
+
+		if unused := total.Mul(other.Ratio).Sub(used); unused.Sign() > 0 {
+			available = available.Sub(unused)
+		}
+	}
+
+	return fixedpoint.Max(available, fixedpoint.Zero)
+}
+
+func addQuotaFund(funds map[string]map[string]fixedpoint.Value, currency, partition string, fund fixedpoint.Value) {
+	if funds[currency] == nil {
+		funds[currency] = make(map[string]fixedpoint.Value)
+	}
+
+	funds[currency][partition] = funds[currency][partition].Add(fund)
+}
@@ -0,0 +1,60 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestQuotaPool(t *testing.T) {
+	account := types.NewAccount()
+	account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(1000)},
+		"BTC":  {Currency: "BTC", Available: fixedpoint.NewFromInt(1)},
+	})
+
+	pool := NewQuotaPool(account, &QuotaPoolConfig{
+		Partitions: map[string]QuotaPartition{
+			"maker-a": {Priority: 10, Ratio: fixedpoint.NewFromFloat(0.6)},
+			"maker-b": {Priority: 1, Ratio: fixedpoint.NewFromFloat(0.4)},
+		},
+	})
+
+	// the higher priority partition can use the unused share of the lower priority partition
+	assert.Equal(t, "1000", pool.Available("maker-a", "USDT").String())
+	assert.Equal(t, "400", pool.Available("maker-b", "USDT").String())
+	assert.Equal(t, "0", pool.Available("other", "USDT").String())
+
+	txA := pool.NewTransaction("maker-a", "BTC", "USDT")
+	txA.QuoteAsset.Add(fixedpoint.NewFromInt(1000))
+	assert.True(t, txA.QuoteAsset.Lock(fixedpoint.NewFromInt(800)))
+	assert.Equal(t, "200", pool.Available("maker-b", "USDT").String())
+
+	txB := pool.NewTransaction("maker-b", "BTC", "USDT")
+	txB.QuoteAsset.Add(fixedpoint.NewFromInt(1000))
+	assert.False(t, txB.QuoteAsset.Lock(fixedpoint.NewFromInt(300)))
+	assert.True(t, txB.QuoteAsset.Lock(fixedpoint.NewFromInt(200)))
+	assert.False(t, txA.QuoteAsset.Lock(fixedpoint.NewFromInt(1)))
+
+	// the rolled back funds are returned to the pool
+	txA.Rollback()
+	assert.Equal(t, "800", pool.Available("maker-a", "USDT").String())
+
+	// the committed funds are held until the balances are updated
+	txB.Commit()
+	assert.Equal(t, "800", pool.Available("maker-a", "USDT").String())
+	assert.Equal(t, "200", pool.Available("maker-b", "USDT").String())
+
+	account.UpdateBalances(types.BalanceMap{
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(800), Locked: fixedpoint.NewFromInt(200)},
+	})
+	pool.Reset()
+	assert.Equal(t, "800", pool.Available("maker-a", "USDT").String())
+	assert.Equal(t, "320", pool.Available("maker-b", "USDT").String())
+
+	// the quota of the base asset is independent
+	assert.Equal(t, "1", pool.Available("maker-a", "BTC").String())
+}
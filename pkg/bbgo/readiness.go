@@ -0,0 +1,215 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"text/tabwriter"
+
+	"github.com/c9s/bbgo/pkg/dynamic"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type ReadinessStatus string
+
+const (
+	ReadinessOK   ReadinessStatus = "OK"
+	ReadinessWarn ReadinessStatus = "WARN"
+	ReadinessFail ReadinessStatus = "FAIL"
+)
+
+// ReadinessCheck is one check item of the readiness report
+type ReadinessCheck struct {
+	Status  ReadinessStatus `json:"status"`
+	Session string          `json:"session,omitempty"`
+	Target  string          `json:"target"`
+	Message string          `json:"message,omitempty"`
+}
+
+// ReadinessReport is the result of the dry checks of the config, the sessions and the strategies before trading
+type ReadinessReport struct {
+	Checks []ReadinessCheck `json:"checks"`
+}
+
+func (r *ReadinessReport) add(status ReadinessStatus, session, target, format string, args ...interface{}) {
+	r.Checks = append(r.Checks, ReadinessCheck{
+		Status:  status,
+		Session: session,
+		Target:  target,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Ready returns false if any of the checks failed, the warnings don't block trading
+func (r *ReadinessReport) Ready() bool {
+	for _, check := range r.Checks {
+		if check.Status == ReadinessFail {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (r *ReadinessReport) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tSESSION\tTARGET\tMESSAGE")
+	for _, check := range r.Checks {
+		session := check.Session
+		if session == "" {
+			session = "-"
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", check.Status, session, check.Target, check.Message)
+	}
+	_ = tw.Flush()
+
+	if r.Ready() {
+		fmt.Fprintln(w, "READY")
+	} else {
+		fmt.Fprintln(w, "NOT READY")
+	}
+}
+
+// CheckReadiness validates the config, and queries the account and the markets of the configured sessions
+// without starting any stream or strategy:
+//
+//   - the API key should be able to read the account and trade, a key with the withdrawal permission is warned
+//   - the symbol of every exchange strategy should be listed on the mounted sessions
+//   - the strategy quantity should pass the min quantity and the min notional filters of the market
+//
+// The cross exchange strategies are only checked by the config validation since their symbols are strategy-specific.
+func CheckReadiness(ctx context.Context, config *Config, environ *Environment) *ReadinessReport {
+	report := &ReadinessReport{}
+
+	if err := ValidateConfig(config); err != nil {
+		if errs, ok := err.(ConfigErrors); ok {
+			for _, configErr := range errs {
+				report.add(ReadinessFail, "", configErr.Path, "%s", configErr.Err.Error())
+			}
+		} else {
+			report.add(ReadinessFail, "", "config", "%s", err.Error())
+		}
+	} else {
+		report.add(ReadinessOK, "", "config", "config is valid")
+	}
+
+	markets := make(map[string]types.MarketMap)
+	for name, session := range environ.Sessions() {
+		if !session.PublicOnly {
+			checkAccountPermissions(ctx, report, session)
+		}
+
+		sessionMarkets, err := session.Exchange.QueryMarkets(ctx)
+		if err != nil {
+			report.add(ReadinessFail, name, "markets", "can not query markets: %s", err.Error())
+			continue
+		}
+
+		markets[name] = sessionMarkets
+	}
+
+	for i, mount := range config.ExchangeStrategies {
+		target := fmt.Sprintf("exchangeStrategies[%d].%s", i, mount.Strategy.ID())
+
+		symbol, ok := dynamic.LookupSymbolField(reflect.ValueOf(mount.Strategy))
+		if !ok || symbol == "" {
+			continue
+		}
+
+		for _, sessionName := range mount.Mounts {
+			session, ok := environ.Session(sessionName)
+			if !ok {
+				report.add(ReadinessFail, sessionName, target, "session is not configured")
+				continue
+			}
+
+			sessionMarkets, ok := markets[sessionName]
+			if !ok {
+				// the market query error is already reported
+				continue
+			}
+
+			market, ok := sessionMarkets[symbol]
+			if !ok {
+				report.add(ReadinessFail, sessionName, target, "symbol %s is not available", symbol)
+				continue
+			}
+
+			checkMarketFilters(ctx, report, session, target, market, mount.Strategy)
+		}
+	}
+
+	return report
+}
+
+func checkAccountPermissions(ctx context.Context, report *ReadinessReport, session *ExchangeSession) {
+	account, err := session.Exchange.QueryAccount(ctx)
+	if err != nil {
+		report.add(ReadinessFail, session.Name, "account", "can not read the account: %s", err.Error())
+		return
+	}
+
+	report.add(ReadinessOK, session.Name, "account", "account is readable")
+
+	// some exchanges don't return the permissions of the API key
+	if !account.CanTrade && !account.CanWithdraw && !account.CanDeposit {
+		report.add(ReadinessWarn, session.Name, "permissions", "the API key permissions are not reported by the exchange")
+		return
+	}
+
+	if account.CanTrade {
+		report.add(ReadinessOK, session.Name, "permissions", "trading is enabled")
+	} else {
+		report.add(ReadinessFail, session.Name, "permissions", "trading is not enabled for the API key")
+	}
+
+	if account.CanWithdraw {
+		report.add(ReadinessWarn, session.Name, "permissions", "withdrawal is enabled, consider using an API key without the withdrawal permission")
+	}
+}
+
+// checkMarketFilters checks the Quantity field of the strategy (if any) with the market filters
+func checkMarketFilters(ctx context.Context, report *ReadinessReport, session *ExchangeSession, target string, market types.Market, strategy interface{}) {
+	rv := reflect.ValueOf(strategy)
+	for rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+
+	field := rv.FieldByName("Quantity")
+	if !field.IsValid() || field.Type() != reflect.TypeOf(fixedpoint.Zero) {
+		report.add(ReadinessOK, session.Name, target, "symbol %s is available", market.Symbol)
+		return
+	}
+
+	quantity := field.Interface().(fixedpoint.Value)
+	if quantity.IsZero() {
+		report.add(ReadinessOK, session.Name, target, "symbol %s is available", market.Symbol)
+		return
+	}
+
+	if market.MinQuantity.Sign() > 0 && quantity.Compare(market.MinQuantity) < 0 {
+		report.add(ReadinessFail, session.Name, target, "quantity %s is less than the min quantity %s of %s",
+			quantity.String(), market.MinQuantity.String(), market.Symbol)
+		return
+	}
+
+	if market.MinNotional.Sign() > 0 {
+		ticker, err := session.Exchange.QueryTicker(ctx, market.Symbol)
+		if err != nil {
+			report.add(ReadinessWarn, session.Name, target, "can not query the ticker of %s for the min notional check: %s", market.Symbol, err.Error())
+			return
+		}
+
+		if notional := quantity.Mul(ticker.Last); notional.Compare(market.MinNotional) < 0 {
+			report.add(ReadinessFail, session.Name, target, "quantity %s (notional %s) is less than the min notional %s of %s",
+				quantity.String(), notional.String(), market.MinNotional.String(), market.Symbol)
+			return
+		}
+	}
+
+	report.add(ReadinessOK, session.Name, target, "symbol %s is available and quantity %s passes the market filters",
+		market.Symbol, quantity.String())
+}
@@ -0,0 +1,71 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+type readinessTestStrategy struct {
+	Symbol   string           `json:"symbol"`
+	Quantity fixedpoint.Value `json:"quantity"`
+}
+
+func (s *readinessTestStrategy) ID() string {
+	return "readiness-test"
+}
+
+func (s *readinessTestStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+func TestCheckReadiness(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+	mockEx.EXPECT().QueryAccount(gomock.Any()).Return(&types.Account{CanTrade: true, CanWithdraw: true}, nil)
+	mockEx.EXPECT().QueryMarkets(gomock.Any()).Return(types.MarketMap{
+		"BTCUSDT": types.Market{
+			Symbol:      "BTCUSDT",
+			MinQuantity: fixedpoint.MustNewFromString("0.0001"),
+			MinNotional: fixedpoint.NewFromFloat(10.0),
+		},
+	}, nil)
+	mockEx.EXPECT().QueryTicker(gomock.Any(), "BTCUSDT").Return(&types.Ticker{Last: fixedpoint.NewFromFloat(20000.0)}, nil).Times(2)
+
+	session := NewExchangeSession("binance", mockEx)
+	environ := NewEnvironment()
+	environ.AddExchangeSession("binance", session)
+
+	config := &Config{
+		Sessions: map[string]*ExchangeSession{"binance": session},
+		ExchangeStrategies: []ExchangeStrategyMount{
+			{Mounts: []string{"binance"}, Strategy: &readinessTestStrategy{Symbol: "BTCUSDT", Quantity: fixedpoint.MustNewFromString("0.001")}},
+			{Mounts: []string{"binance"}, Strategy: &readinessTestStrategy{Symbol: "BTCUSDT", Quantity: fixedpoint.MustNewFromString("0.0002")}},
+			{Mounts: []string{"binance"}, Strategy: &readinessTestStrategy{Symbol: "ETHUSDT"}},
+		},
+	}
+
+	report := CheckReadiness(context.Background(), config, environ)
+	assert.False(t, report.Ready())
+
+	statuses := make(map[string]ReadinessStatus)
+	for _, check := range report.Checks {
+		statuses[check.Target] = check.Status
+	}
+
+	assert.Equal(t, ReadinessOK, statuses["config"])
+	assert.Equal(t, ReadinessOK, statuses["account"])
+	assert.Equal(t, ReadinessWarn, statuses["permissions"], "the withdrawal permission is warned")
+	assert.Equal(t, ReadinessOK, statuses["exchangeStrategies[0].readiness-test"])
+	assert.Equal(t, ReadinessFail, statuses["exchangeStrategies[1].readiness-test"], "the notional 4 is less than the min notional 10")
+	assert.Equal(t, ReadinessFail, statuses["exchangeStrategies[2].readiness-test"], "ETHUSDT is not listed")
+}
@@ -289,9 +289,10 @@ func CalculateBaseQuantity(session *ExchangeSession, market types.Market, price,
 
 		originLeverage := leverage
 		if session.IsolatedMargin {
-			leverage = fixedpoint.Min(leverage, maxIsolatedMarginLeverage)
+			maxLeverage := session.isolatedMarginLeverage()
+			leverage = fixedpoint.Min(leverage, maxLeverage)
 			log.Infof("using isolated margin, maxLeverage=%f originalLeverage=%f currentLeverage=%f",
-				maxIsolatedMarginLeverage.Float64(),
+				maxLeverage.Float64(),
 				originLeverage.Float64(),
 				leverage.Float64())
 		} else {
@@ -345,9 +346,10 @@ func CalculateQuoteQuantity(ctx context.Context, session *ExchangeSession, quote
 
 	originLeverage := leverage
 	if session.IsolatedMargin {
-		leverage = fixedpoint.Min(leverage, maxIsolatedMarginLeverage)
+		maxLeverage := session.isolatedMarginLeverage()
+		leverage = fixedpoint.Min(leverage, maxLeverage)
 		log.Infof("using isolated margin, maxLeverage=%f originalLeverage=%f currentLeverage=%f",
-			maxIsolatedMarginLeverage.Float64(),
+			maxLeverage.Float64(),
 			originLeverage.Float64(),
 			leverage.Float64())
 	} else {
@@ -3,6 +3,8 @@ package bbgo
 import (
 	"fmt"
 	"math"
+	"sort"
+	"strings"
 
 	"github.com/pkg/errors"
 )
@@ -20,6 +22,9 @@ func init() {
 	_ = Scale(&LogarithmicScale{})
 	_ = Scale(&LinearScale{})
 	_ = Scale(&QuadraticScale{})
+	_ = Scale(&LogisticScale{})
+	_ = Scale(&PiecewiseScale{})
+	_ = Scale(&TableScale{})
 }
 
 // f(x) := ab^x
@@ -247,13 +252,235 @@ func (s *QuadraticScale) FormulaOf(x float64) string {
 	return fmt.Sprintf("f(%f) = %f * %f ^ 2 + %f * %f + %f", x, s.a, x, s.b, x, s.c)
 }
 
+// LogisticScale is the S-shaped scale from range[0] to range[1],
+// the curve is normalized so that f(domain[0]) = range[0] and f(domain[1]) = range[1].
+//
+// l(x) := 1 / (1 + e^(-k * ((x - d0) / (d1 - d0) - m)))
+// y := r0 + (r1 - r0) * (l(x) - l(d0)) / (l(d1) - l(d0))
+type LogisticScale struct {
+	Domain [2]float64 `json:"domain"`
+	Range  [2]float64 `json:"range"`
+
+	// Steepness is the growth rate over the normalized domain, defaults to 10
+	Steepness float64 `json:"steepness,omitempty"`
+
+	// Midpoint is the x of the inflection point, defaults to the center of the domain
+	Midpoint *float64 `json:"midpoint,omitempty"`
+
+	k, m   float64
+	l0, l1 float64
+}
+
+func (s *LogisticScale) Solve() error {
+	if s.Domain[0] >= s.Domain[1] {
+		return errors.New("for LogisticScale, domain[0] should be less than domain[1]")
+	}
+
+	if s.Steepness < 0 {
+		return errors.New("for LogisticScale, steepness can not be negative")
+	}
+
+	s.k = s.Steepness
+	if s.k == 0 {
+		s.k = 10.0
+	}
+
+	s.m = 0.5
+	if s.Midpoint != nil {
+		if *s.Midpoint < s.Domain[0] || *s.Midpoint > s.Domain[1] {
+			return fmt.Errorf("for LogisticScale, midpoint %f is out of the domain", *s.Midpoint)
+		}
+
+		s.m = (*s.Midpoint - s.Domain[0]) / (s.Domain[1] - s.Domain[0])
+	}
+
+	s.l0 = s.logistic(0)
+	s.l1 = s.logistic(1)
+	return nil
+}
+
+func (s *LogisticScale) logistic(t float64) float64 {
+	return 1.0 / (1.0 + math.Exp(-s.k*(t-s.m)))
+}
+
+func (s *LogisticScale) Call(x float64) (y float64) {
+	if x < s.Domain[0] {
+		x = s.Domain[0]
+	} else if x > s.Domain[1] {
+		x = s.Domain[1]
+	}
+
+	t := (x - s.Domain[0]) / (s.Domain[1] - s.Domain[0])
+	y = s.Range[0] + (s.Range[1]-s.Range[0])*(s.logistic(t)-s.l0)/(s.l1-s.l0)
+	return y
+}
+
+func (s *LogisticScale) Sum(step float64) float64 {
+	sum := 0.0
+	for x := s.Domain[0]; x <= s.Domain[1]; x += step {
+		sum += s.Call(x)
+	}
+	return sum
+}
+
+func (s *LogisticScale) String() string {
+	return s.Formula()
+}
+
+func (s *LogisticScale) Formula() string {
+	return fmt.Sprintf("f(x) = %f + %f * (1 / (1 + e ^ (-%f * ((x - %f) / %f - %f))) - %f) / %f",
+		s.Range[0], s.Range[1]-s.Range[0], s.k, s.Domain[0], s.Domain[1]-s.Domain[0], s.m, s.l0, s.l1-s.l0)
+}
+
+func (s *LogisticScale) FormulaOf(x float64) string {
+	return fmt.Sprintf("f(%f) = %f + %f * (1 / (1 + e ^ (-%f * ((%f - %f) / %f - %f))) - %f) / %f",
+		x, s.Range[0], s.Range[1]-s.Range[0], s.k, x, s.Domain[0], s.Domain[1]-s.Domain[0], s.m, s.l0, s.l1-s.l0)
+}
+
+// PiecewiseScale is the piecewise-linear scale defined by the breakpoints (domain[i], range[i]),
+// the value is interpolated linearly between the breakpoints, and clamped outside the domain.
+type PiecewiseScale struct {
+	Domain []float64 `json:"domain"`
+	Range  []float64 `json:"range"`
+}
+
+func (s *PiecewiseScale) Solve() error {
+	if len(s.Domain) < 2 {
+		return errors.New("for PiecewiseScale, at least 2 breakpoints are required")
+	}
+
+	if len(s.Domain) != len(s.Range) {
+		return fmt.Errorf("for PiecewiseScale, the length of domain (%d) and range (%d) should be the same", len(s.Domain), len(s.Range))
+	}
+
+	for i := 1; i < len(s.Domain); i++ {
+		if s.Domain[i] <= s.Domain[i-1] {
+			return errors.New("for PiecewiseScale, domain should be strictly increasing")
+		}
+	}
+
+	return nil
+}
+
+func (s *PiecewiseScale) segment(x float64) int {
+	i := sort.SearchFloat64s(s.Domain, x)
+	if i < 1 {
+		return 1
+	} else if i > len(s.Domain)-1 {
+		return len(s.Domain) - 1
+	}
+
+	return i
+}
+
+func (s *PiecewiseScale) Call(x float64) (y float64) {
+	n := len(s.Domain)
+	if x <= s.Domain[0] {
+		return s.Range[0]
+	} else if x >= s.Domain[n-1] {
+		return s.Range[n-1]
+	}
+
+	i := s.segment(x)
+	x0, x1 := s.Domain[i-1], s.Domain[i]
+	y0, y1 := s.Range[i-1], s.Range[i]
+	y = y0 + (x-x0)*(y1-y0)/(x1-x0)
+	return y
+}
+
+func (s *PiecewiseScale) Sum(step float64) float64 {
+	sum := 0.0
+	for x := s.Domain[0]; x <= s.Domain[len(s.Domain)-1]; x += step {
+		sum += s.Call(x)
+	}
+	return sum
+}
+
+func (s *PiecewiseScale) String() string {
+	return s.Formula()
+}
+
+func (s *PiecewiseScale) Formula() string {
+	var segments []string
+	for i := 1; i < len(s.Domain); i++ {
+		segments = append(segments, fmt.Sprintf("(%f, %f) -> (%f, %f)", s.Domain[i-1], s.Range[i-1], s.Domain[i], s.Range[i]))
+	}
+
+	return "f(x) = piecewise " + strings.Join(segments, ", ")
+}
+
+func (s *PiecewiseScale) FormulaOf(x float64) string {
+	if x <= s.Domain[0] {
+		return fmt.Sprintf("f(%f) = %f", x, s.Range[0])
+	} else if x >= s.Domain[len(s.Domain)-1] {
+		return fmt.Sprintf("f(%f) = %f", x, s.Range[len(s.Range)-1])
+	}
+
+	i := s.segment(x)
+	return fmt.Sprintf("f(%f) = %f + (%f - %f) * (%f - %f) / (%f - %f)",
+		x, s.Range[i-1], x, s.Domain[i-1], s.Range[i], s.Range[i-1], s.Domain[i], s.Domain[i-1])
+}
+
+// TableScale is the lookup table scale, f(start + i) = values[i],
+// x is rounded to the nearest index and clamped by the table.
+type TableScale struct {
+	Start  float64   `json:"start"`
+	Values []float64 `json:"values"`
+}
+
+func (s *TableScale) Solve() error {
+	if len(s.Values) == 0 {
+		return errors.New("for TableScale, values can not be empty")
+	}
+
+	return nil
+}
+
+func (s *TableScale) index(x float64) int {
+	i := int(math.Round(x - s.Start))
+	if i < 0 {
+		return 0
+	} else if i >= len(s.Values) {
+		return len(s.Values) - 1
+	}
+
+	return i
+}
+
+func (s *TableScale) Call(x float64) (y float64) {
+	return s.Values[s.index(x)]
+}
+
+func (s *TableScale) Sum(step float64) float64 {
+	sum := 0.0
+	for x := s.Start; x <= s.Start+float64(len(s.Values)-1); x += step {
+		sum += s.Call(x)
+	}
+	return sum
+}
+
+func (s *TableScale) String() string {
+	return s.Formula()
+}
+
+func (s *TableScale) Formula() string {
+	return fmt.Sprintf("f(x) = %v[x - %f]", s.Values, s.Start)
+}
+
+func (s *TableScale) FormulaOf(x float64) string {
+	return fmt.Sprintf("f(%f) = %v[%d]", x, s.Values, s.index(x))
+}
+
 type SlideRule struct {
-	// Scale type could be one of "log", "exp", "linear", "quadratic"
+	// Scale type could be one of "log", "exp", "linear", "quadratic", "logistic", "piecewise", "table"
 	// this is similar to the d3.scale
 	LinearScale    *LinearScale      `json:"linear"`
 	LogScale       *LogarithmicScale `json:"log"`
 	ExpScale       *ExponentialScale `json:"exp"`
 	QuadraticScale *QuadraticScale   `json:"quadratic"`
+	LogisticScale  *LogisticScale    `json:"logistic,omitempty"`
+	PiecewiseScale *PiecewiseScale   `json:"piecewise,omitempty"`
+	TableScale     *TableScale       `json:"table,omitempty"`
 }
 
 func (rule *SlideRule) Range() ([2]float64, error) {
@@ -274,6 +501,20 @@ func (rule *SlideRule) Range() ([2]float64, error) {
 		return [2]float64{r[0], r[len(r)-1]}, nil
 	}
 
+	if rule.LogisticScale != nil {
+		return rule.LogisticScale.Range, nil
+	}
+
+	if rule.PiecewiseScale != nil && len(rule.PiecewiseScale.Range) > 0 {
+		r := rule.PiecewiseScale.Range
+		return [2]float64{r[0], r[len(r)-1]}, nil
+	}
+
+	if rule.TableScale != nil && len(rule.TableScale.Values) > 0 {
+		v := rule.TableScale.Values
+		return [2]float64{v[0], v[len(v)-1]}, nil
+	}
+
 	return [2]float64{}, errors.New("no any scale domain is defined")
 }
 
@@ -294,6 +535,18 @@ func (rule *SlideRule) Scale() (Scale, error) {
 		return rule.QuadraticScale, nil
 	}
 
+	if rule.LogisticScale != nil {
+		return rule.LogisticScale, nil
+	}
+
+	if rule.PiecewiseScale != nil {
+		return rule.PiecewiseScale, nil
+	}
+
+	if rule.TableScale != nil {
+		return rule.TableScale, nil
+	}
+
 	return nil, errors.New("no any scale is defined")
 }
 
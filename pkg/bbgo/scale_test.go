@@ -1,6 +1,7 @@
 package bbgo
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -130,6 +131,87 @@ func TestQuadraticScale(t *testing.T) {
 	}
 }
 
+func TestLogisticScale(t *testing.T) {
+	scale := LogisticScale{
+		Domain: [2]float64{0, 10},
+		Range:  [2]float64{1, 5},
+	}
+
+	err := scale.Solve()
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, scale.Call(0), delta)
+	assert.InDelta(t, 3, scale.Call(5), delta, "the midpoint is the center of the range")
+	assert.InDelta(t, 5, scale.Call(10), delta)
+	assert.InDelta(t, 5, scale.Call(20), delta)
+	assert.Less(t, scale.Call(1)-scale.Call(0), scale.Call(5)-scale.Call(4), "the curve is steeper around the midpoint")
+
+	midpoint := 8.0
+	scale.Midpoint = &midpoint
+	assert.NoError(t, scale.Solve())
+	assert.Less(t, scale.Call(5), 3.0)
+
+	midpoint = 11.0
+	assert.Error(t, scale.Solve())
+
+	assert.Error(t, (&LogisticScale{Domain: [2]float64{1, 1}}).Solve())
+	for x := 0; x <= 10; x++ {
+		t.Logf("%s = %f", scale.FormulaOf(float64(x)), scale.Call(float64(x)))
+	}
+}
+
+func TestPiecewiseScale(t *testing.T) {
+	scale := PiecewiseScale{
+		Domain: []float64{0, 2, 10},
+		Range:  []float64{1, 3, 3.8},
+	}
+
+	err := scale.Solve()
+	assert.NoError(t, err)
+	assert.InDelta(t, 1, scale.Call(-1), delta)
+	assert.InDelta(t, 2, scale.Call(1), delta)
+	assert.InDelta(t, 3, scale.Call(2), delta)
+	assert.InDelta(t, 3.4, scale.Call(6), delta)
+	assert.InDelta(t, 3.8, scale.Call(11), delta)
+	assert.InDelta(t, 1+2+3+3.1+3.2+3.3+3.4+3.5+3.6+3.7+3.8, scale.Sum(1.0), delta)
+	assert.Equal(t, "f(3.000000) = 3.000000 + (3.000000 - 2.000000) * (3.800000 - 3.000000) / (10.000000 - 2.000000)", scale.FormulaOf(3))
+
+	assert.Error(t, (&PiecewiseScale{Domain: []float64{0}, Range: []float64{1}}).Solve())
+	assert.Error(t, (&PiecewiseScale{Domain: []float64{0, 1}, Range: []float64{1}}).Solve())
+	assert.Error(t, (&PiecewiseScale{Domain: []float64{0, 2, 1}, Range: []float64{1, 2, 3}}).Solve())
+}
+
+func TestTableScale(t *testing.T) {
+	scale := TableScale{
+		Start:  1,
+		Values: []float64{1, 1, 2, 4},
+	}
+
+	err := scale.Solve()
+	assert.NoError(t, err)
+	assert.Equal(t, 1.0, scale.Call(0))
+	assert.Equal(t, 2.0, scale.Call(3))
+	assert.Equal(t, 2.0, scale.Call(3.4), "x is rounded to the nearest index")
+	assert.Equal(t, 4.0, scale.Call(10))
+	assert.Equal(t, 8.0, scale.Sum(1.0))
+
+	assert.Error(t, (&TableScale{}).Solve())
+}
+
+func TestSlideRule_Scale(t *testing.T) {
+	var rule SlideRule
+	assert.NoError(t, json.Unmarshal([]byte(`{"piecewise":{"domain":[0,9],"range":[1,4]}}`), &rule))
+
+	scale, err := rule.Scale()
+	if assert.NoError(t, err) {
+		assert.NoError(t, scale.Solve())
+		assert.InDelta(t, 4.0, scale.Call(9), delta)
+	}
+
+	r, err := rule.Range()
+	assert.NoError(t, err)
+	assert.Equal(t, [2]float64{1, 4}, r)
+}
+
 func TestPercentageScale(t *testing.T) {
 	t.Run("from 0.0 to 1.0", func(t *testing.T) {
 		s := &PercentageScale{
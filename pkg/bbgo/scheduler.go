@@ -0,0 +1,174 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/sirupsen/logrus"
+)
+
+// SchedulerConfig is the config of the cron scheduler of the strategies
+//
+//	scheduler:
+//	  timeZone: UTC
+type SchedulerConfig struct {
+	// TimeZone is the IANA time zone of the cron specs, defaults to UTC
+	TimeZone string `json:"timeZone,omitempty" yaml:"timeZone,omitempty"`
+}
+
+// ScheduledJob is the registered cron job
+type ScheduledJob struct {
+	Owner string    `json:"owner"`
+	Name  string    `json:"name"`
+	Spec  string    `json:"spec"`
+	Next  time.Time `json:"next"`
+	Prev  time.Time `json:"prev,omitempty"`
+}
+
+type scheduledEntry struct {
+	owner, name, spec string
+}
+
+// Scheduler runs the cron jobs registered by the strategies, e.g., flatten the inventory at 00:00 UTC,
+// the jobs can be registered before the scheduler is started.
+// The same job doesn't run concurrently, the next run is skipped when the previous run is still running.
+//
+// The scheduler runs on the wall clock, so the jobs are not triggered in the backtest.
+type Scheduler struct {
+	cron     *cron.Cron
+	location *time.Location
+
+	mu      sync.Mutex
+	ctx     context.Context
+	entries map[cron.EntryID]scheduledEntry
+
+	logger logrus.FieldLogger
+}
+
+func NewScheduler(location *time.Location) *Scheduler {
+	if location == nil {
+		location = time.UTC
+	}
+
+	return &Scheduler{
+		cron:     cron.New(cron.WithLocation(location)),
+		location: location,
+		ctx:      context.Background(),
+		entries:  make(map[cron.EntryID]scheduledEntry),
+		logger:   logrus.WithField("component", "scheduler"),
+	}
+}
+
+func NewSchedulerFromConfig(config *SchedulerConfig) (*Scheduler, error) {
+	location := time.UTC
+	if config != nil && config.TimeZone != "" {
+		var err error
+		location, err = time.LoadLocation(config.TimeZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid scheduler time zone %q: %w", config.TimeZone, err)
+		}
+	}
+
+	return NewScheduler(location), nil
+}
+
+// Location returns the time zone of the cron specs
+func (s *Scheduler) Location() *time.Location {
+	return s.location
+}
+
+// Schedule registers the job of the owner (the strategy instance ID) with the standard cron spec, e.g., "0 0 * * *",
+// the descriptors like "@hourly" and "@every 30m" are also supported.
+// The job is called with the scheduler context, which is canceled when the scheduler is stopped.
+func (s *Scheduler) Schedule(owner, name, spec string, job func(ctx context.Context)) error {
+	wrapped := cron.NewChain(cron.SkipIfStillRunning(cron.DiscardLogger)).Then(cron.FuncJob(func() {
+		defer func() {
+			if r := recover(); r != nil {
+				s.logger.Errorf("scheduled job %s of %s panic: %v", name, owner, r)
+			}
+		}()
+
+		s.mu.Lock()
+		ctx := s.ctx
+		s.mu.Unlock()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		s.logger.Infof("running scheduled job %s of %s", name, owner)
+		job(ctx)
+	}))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id, err := s.cron.AddJob(spec, wrapped)
+	if err != nil {
+		return fmt.Errorf("invalid cron spec %q of the scheduled job %s: %w", spec, name, err)
+	}
+
+	s.entries[id] = scheduledEntry{owner: owner, name: name, spec: spec}
+	return nil
+}
+
+// Unschedule removes all the jobs of the owner
+func (s *Scheduler) Unschedule(owner string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, entry := range s.entries {
+		if entry.owner == owner {
+			s.cron.Remove(id)
+			delete(s.entries, id)
+		}
+	}
+}
+
+// Jobs returns the registered jobs sorted by the next run time
+func (s *Scheduler) Jobs() []ScheduledJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var jobs []ScheduledJob
+	for _, e := range s.cron.Entries() {
+		entry, ok := s.entries[e.ID]
+		if !ok {
+			continue
+		}
+
+		next := e.Next
+		if next.IsZero() {
+			next = e.Schedule.Next(time.Now().In(s.location))
+		}
+
+		jobs = append(jobs, ScheduledJob{
+			Owner: entry.owner,
+			Name:  entry.name,
+			Spec:  entry.spec,
+			Next:  next,
+			Prev:  e.Prev,
+		})
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].Next.Before(jobs[j].Next)
+	})
+
+	return jobs
+}
+
+// Run starts the scheduler and stops it when the context is done, the running jobs are waited
+func (s *Scheduler) Run(ctx context.Context) {
+	s.mu.Lock()
+	s.ctx = ctx
+	s.mu.Unlock()
+
+	s.cron.Start()
+	<-ctx.Done()
+	<-s.cron.Stop().Done()
+}
@@ -0,0 +1,58 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler(t *testing.T) {
+	scheduler := NewScheduler(nil)
+	assert.Equal(t, time.UTC, scheduler.Location())
+
+	assert.Error(t, scheduler.Schedule("scmaker:USDCUSDT", "requote", "every day", func(ctx context.Context) {}))
+
+	ran := make(chan struct{}, 1)
+	assert.NoError(t, scheduler.Schedule("scmaker:USDCUSDT", "requote", "@every 1s", func(ctx context.Context) {
+		select {
+		case ran <- struct{}{}:
+		default:
+		}
+	}))
+	assert.NoError(t, scheduler.Schedule("xmaker:BTCUSDT", "flatten", "0 0 * * *", func(ctx context.Context) {}))
+
+	jobs := scheduler.Jobs()
+	if assert.Len(t, jobs, 2) {
+		assert.Equal(t, "requote", jobs[0].Name)
+		assert.Equal(t, "flatten", jobs[1].Name)
+		assert.Equal(t, 0, jobs[1].Next.Hour())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go scheduler.Run(ctx)
+
+	select {
+	case <-ran:
+	case <-time.After(3 * time.Second):
+		t.Fatal("the scheduled job is not triggered")
+	}
+
+	scheduler.Unschedule("scmaker:USDCUSDT")
+	jobs = scheduler.Jobs()
+	if assert.Len(t, jobs, 1) {
+		assert.Equal(t, "xmaker:BTCUSDT", jobs[0].Owner)
+	}
+}
+
+func TestNewSchedulerFromConfig(t *testing.T) {
+	scheduler, err := NewSchedulerFromConfig(&SchedulerConfig{TimeZone: "Asia/Taipei"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Asia/Taipei", scheduler.Location().String())
+	}
+
+	_, err = NewSchedulerFromConfig(&SchedulerConfig{TimeZone: "Mars/Olympus"})
+	assert.Error(t, err)
+}
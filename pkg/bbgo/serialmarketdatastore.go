@@ -85,10 +85,9 @@ func (store *SerialMarketDataStore) handleMarketTrade(trade types.Trade) {
 
 func (store *SerialMarketDataStore) tickerProcessor(ctx context.Context) {
 	duration := store.MinInterval.Duration()
-	relativeTime := time.Now().UnixNano() % int64(duration)
-	waitTime := int64(duration) - relativeTime
+	waitTime := time.Until(store.MinInterval.NextBoundary(time.Now()))
 	select {
-	case <-time.After(time.Duration(waitTime)):
+	case <-time.After(waitTime):
 	case <-ctx.Done():
 		return
 	}
@@ -100,7 +99,7 @@ func (store *SerialMarketDataStore) tickerProcessor(ctx context.Context) {
 		case time := <-intervalCloseTicker.C:
 			kline := types.KLine{
 				Symbol:    store.Symbol,
-				StartTime: types.Time(time.Add(-1 * duration).Round(duration)),
+				StartTime: types.Time(store.MinInterval.Advance(store.MinInterval.Truncate(time), -1)),
 				EndTime:   types.Time(time),
 				Interval:  store.MinInterval,
 				Closed:    true,
@@ -145,8 +144,7 @@ func (store *SerialMarketDataStore) AddKLine(kline types.KLine, async ...bool) {
 		return
 	}
 	// endtime
-	duration := store.MinInterval.Duration()
-	timestamp := kline.StartTime.Time().Add(duration)
+	timestamp := store.MinInterval.Advance(kline.StartTime.Time(), 1)
 	for _, val := range store.Subscription {
 		k, ok := store.KLines[val]
 		if !ok {
@@ -159,7 +157,7 @@ func (store *SerialMarketDataStore) AddKLine(kline types.KLine, async ...bool) {
 			k.Merge(&kline)
 			k.Closed = false
 		}
-		if timestamp.Round(val.Duration()) == timestamp {
+		if val.Truncate(timestamp).Equal(timestamp) {
 			k.Closed = true
 			if len(async) > 0 && async[0] {
 				go store.MarketDataStore.AddKLine(*k)
@@ -18,6 +18,7 @@ import (
 	"github.com/c9s/bbgo/pkg/util/templateutil"
 
 	exchange2 "github.com/c9s/bbgo/pkg/exchange"
+	exchangemetrics "github.com/c9s/bbgo/pkg/exchange/metrics"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
@@ -44,6 +45,10 @@ type ExchangeSession struct {
 	Passphrase   string             `json:"passphrase,omitempty" yaml:"passphrase,omitempty"`
 	SubAccount   string             `json:"subAccount,omitempty" yaml:"subAccount,omitempty"`
 
+	// SecretName is the name of the credentials in the secret provider,
+	// the key, secret and passphrase are loaded from the provider when it's set
+	SecretName string `json:"secretName,omitempty" yaml:"secretName,omitempty"`
+
 	// Withdrawal is used for enabling withdrawal functions
 	Withdrawal              bool             `json:"withdrawal,omitempty" yaml:"withdrawal,omitempty"`
 	MakerFeeRate            fixedpoint.Value `json:"makerFeeRate" yaml:"makerFeeRate"`
@@ -55,10 +60,34 @@ type ExchangeSession struct {
 	IsolatedMargin       bool   `json:"isolatedMargin,omitempty" yaml:"isolatedMargin,omitempty"`
 	IsolatedMarginSymbol string `json:"isolatedMarginSymbol,omitempty" yaml:"isolatedMarginSymbol,omitempty"`
 
+	// IsolatedMarginLeverage is the max leverage of the isolated margin pair used by the order sizing, defaults to 10
+	IsolatedMarginLeverage int `json:"isolatedMarginLeverage,omitempty" yaml:"isolatedMarginLeverage,omitempty"`
+
+	// IsolatedMarginTransfer moves the assets between the spot wallet and the isolated margin pair
+	IsolatedMarginTransfer *IsolatedMarginTransferConfig `json:"isolatedMarginTransfer,omitempty" yaml:"isolatedMarginTransfer,omitempty"`
+
+	// AutoRepay repays the borrowed base and quote assets with the available balances after the position of the order executor is closed
+	AutoRepay bool `json:"autoRepay,omitempty" yaml:"autoRepay,omitempty"`
+
 	Futures               bool   `json:"futures,omitempty" yaml:"futures"`
 	IsolatedFutures       bool   `json:"isolatedFutures,omitempty" yaml:"isolatedFutures,omitempty"`
 	IsolatedFuturesSymbol string `json:"isolatedFuturesSymbol,omitempty" yaml:"isolatedFuturesSymbol,omitempty"`
 
+	// SelfTradePrevention is the default self-trade prevention mode of the orders submitted through this session,
+	// it's applied when the submit order does not set its own mode.
+	SelfTradePrevention types.SelfTradePreventionMode `json:"selfTradePrevention,omitempty" yaml:"selfTradePrevention,omitempty"`
+
+	// Facets defines the additional account types (spot, margin, futures) that share the credentials of this session.
+	// Each facet will be registered as a separated session named "{session}:{accountType}",
+	// so that strategies can mount on it or look it up via the Facet method.
+	Facets []types.AccountType `json:"facets,omitempty" yaml:"facets,omitempty"`
+
+	// DustSweep converts the small balances of the session on a schedule
+	DustSweep *DustSweeperConfig `json:"dustSweep,omitempty" yaml:"dustSweep,omitempty"`
+
+	// QuotaPool partitions the balances of the session among the strategy instances
+	QuotaPool *QuotaPoolConfig `json:"quotaPool,omitempty" yaml:"quotaPool,omitempty"`
+
 	// ---------------------------
 	// Runtime fields
 	// ---------------------------
@@ -79,6 +108,13 @@ type ExchangeSession struct {
 	// this is a read-only field when running strategy
 	Subscriptions map[types.Subscription]types.Subscription `json:"-" yaml:"-"`
 
+	// subscribers records the strategy instances that declared each subscription
+	subscribers map[types.Subscription][]string
+	subscriber  string
+
+	// guard recovers the panics of the strategy instance that uses the guarded session
+	guard *StrategyGuard
+
 	Exchange types.Exchange `json:"-" yaml:"-"`
 
 	UseHeikinAshi bool `json:"heikinAshi,omitempty" yaml:"heikinAshi,omitempty"`
@@ -104,6 +140,10 @@ type ExchangeSession struct {
 
 	positions map[string]*types.Position
 
+	dustSweeper *DustSweeper
+
+	quotaPool *QuotaPool
+
 	// standard indicators of each market
 	standardIndicatorSets map[string]*StandardIndicatorSet
 
@@ -112,6 +152,9 @@ type ExchangeSession struct {
 	usedSymbols        map[string]struct{}
 	initializedSymbols map[string]struct{}
 
+	// facetSessions is the account facet sessions created from the Facets config
+	facetSessions map[types.AccountType]*ExchangeSession
+
 	logger *log.Entry
 }
 
@@ -129,6 +172,7 @@ func NewExchangeSession(name string, exchange types.Exchange) *ExchangeSession {
 		Account:          &types.Account{},
 		Trades:           make(map[string]*types.TradeSlice),
 
+		subscribers:           make(map[types.Subscription][]string),
 		orderBooks:            make(map[string]*types.StreamOrderBook),
 		markets:               make(map[string]types.Market),
 		startPrices:           make(map[string]fixedpoint.Value),
@@ -139,6 +183,7 @@ func NewExchangeSession(name string, exchange types.Exchange) *ExchangeSession {
 		orderStores:           make(map[string]*OrderStore),
 		usedSymbols:           make(map[string]struct{}),
 		initializedSymbols:    make(map[string]struct{}),
+		facetSessions:         make(map[types.AccountType]*ExchangeSession),
 		logger:                log.WithField("session", name),
 	}
 
@@ -165,6 +210,8 @@ func (session *ExchangeSession) UpdateAccount(ctx context.Context) (*types.Accou
 	}
 
 	session.accountMutex.Lock()
+	// keep the funds reserved by the strategies for the orders being placed
+	account.CopyReservations(session.Account)
 	session.Account = account
 	session.accountMutex.Unlock()
 	return account, nil
@@ -179,6 +226,16 @@ func (session *ExchangeSession) Init(ctx context.Context, environ *Environment)
 
 	var log = log.WithField("session", session.Name)
 
+	// if metrics mode is enabled, instrument the REST clients and count the stream reconnects before any request is sent
+	if viper.GetBool("metrics") {
+		if instrumenter, ok := session.Exchange.(exchangemetrics.Instrumenter); ok {
+			instrumenter.EnableMetrics(session.Name)
+		}
+
+		session.bindStreamReconnectMetrics(session.MarketDataStream, "market")
+		session.bindStreamReconnectMetrics(session.UserDataStream, "user")
+	}
+
 	// load markets first
 	log.Infof("querying market info from %s...", session.Name)
 
@@ -239,6 +296,22 @@ func (session *ExchangeSession) Init(ctx context.Context, environ *Environment)
 		session.Account = account
 		session.accountMutex.Unlock()
 
+		if session.IsolatedMarginTransfer != nil {
+			if err := session.IsolatedMarginTransfer.validate(session); err != nil {
+				return err
+			}
+
+			if err := session.topUpIsolatedMargin(ctx); err != nil {
+				return err
+			}
+
+			if session.IsolatedMarginTransfer.TransferOutOnShutdown {
+				OnShutdownPhase(ctx, ShutdownPhaseDisconnect, session.Name+" isolated margin transfer", session.withdrawIsolatedMargin)
+			}
+
+			account = session.GetAccount()
+		}
+
 		log.Infof("account %s balances:", session.Name)
 		account.Balances().Print()
 
@@ -246,20 +319,38 @@ func (session *ExchangeSession) Init(ctx context.Context, environ *Environment)
 		session.UserDataStream.OnTradeUpdate(session.OrderExecutor.EmitTradeUpdate)
 		session.UserDataStream.OnOrderUpdate(session.OrderExecutor.EmitOrderUpdate)
 
+		if session.QuotaPool != nil {
+			session.quotaPool = NewQuotaPool(account, session.QuotaPool)
+		}
+
 		session.UserDataStream.OnBalanceSnapshot(func(balances types.BalanceMap) {
 			session.accountMutex.Lock()
 			session.Account.UpdateBalances(balances)
 			session.accountMutex.Unlock()
+
+			if session.quotaPool != nil {
+				session.quotaPool.Reset()
+			}
 		})
 
 		session.UserDataStream.OnBalanceUpdate(func(balances types.BalanceMap) {
 			session.accountMutex.Lock()
 			session.Account.UpdateBalances(balances)
 			session.accountMutex.Unlock()
+
+			if session.quotaPool != nil {
+				session.quotaPool.Reset()
+			}
 		})
 
 		session.bindConnectionStatusNotification(session.UserDataStream, "user data")
 
+		// the profit stats of the strategies are bound to the sweeper by their order executors
+		if session.DustSweep != nil {
+			session.dustSweeper = NewDustSweeper(session, session.DustSweep)
+			go session.dustSweeper.Run(ctx)
+		}
+
 		// if metrics mode is enabled, we bind the callbacks to update metrics
 		if viper.GetBool("metrics") {
 			session.metricsBalancesUpdater(account.Balances())
@@ -431,7 +522,8 @@ func (session *ExchangeSession) initSymbol(ctx context.Context, environ *Environ
 				continue
 			}
 
-			if minInterval.Seconds() > sub.Options.Interval.Seconds() {
+			// the last price is loaded from the native interval, the custom interval might not be preloaded
+			if minInterval.Seconds() > sub.Options.Interval.Seconds() && session.isSupportedInterval(sub.Options.Interval) {
 				minInterval = sub.Options.Interval
 			}
 
@@ -447,10 +539,22 @@ func (session *ExchangeSession) initSymbol(ctx context.Context, environ *Environ
 	for interval := range klineSubscriptions {
 		// avoid querying the last unclosed kline
 		endTime := environ.startTime
+
+		if source, aggregated := session.klineAggregationSource(interval); aggregated {
+			kLines, err := session.preloadAggregatedKLines(ctx, symbol, interval, source, endTime, KLinePreloadLimit)
+			if err != nil {
+				return err
+			}
+
+			for _, k := range kLines {
+				marketDataStore.AddKLine(k)
+			}
+			continue
+		}
+
 		var i int64
 		for i = 0; i < KLinePreloadLimit; i += 1000 {
-			var duration time.Duration = time.Duration(-i * int64(interval.Duration()))
-			e := endTime.Add(duration)
+			e := interval.Advance(endTime, -int(i))
 
 			kLines, err := session.Exchange.QueryKLines(ctx, symbol, interval, types.KLineQueryOptions{
 				EndTime: &e,
@@ -573,11 +677,53 @@ func (session *ExchangeSession) StartPrice(symbol string) (price fixedpoint.Valu
 	return price, ok
 }
 
+// NewQuotaTransaction creates the quota transaction of the strategy instance,
+// the transaction draws the funds from the quota pool of the session if the quota pool is configured.
+func (session *ExchangeSession) NewQuotaTransaction(instanceID, baseCurrency, quoteCurrency string) *QuotaTransaction {
+	if session.quotaPool == nil {
+		return &QuotaTransaction{}
+	}
+
+	return session.quotaPool.NewTransaction(instanceID, baseCurrency, quoteCurrency)
+}
+
+// DustSweeper returns the dust sweeper of the session, it's nil if the dust sweep is not configured
+func (session *ExchangeSession) DustSweeper() *DustSweeper {
+	return session.dustSweeper
+}
+
+// bindDustSweepProfitStats credits the dust sweep proceeds in the profit stats of the strategy,
+// it's called by the order executors when their profit stats are bound.
+func (session *ExchangeSession) bindDustSweepProfitStats(profitStats *types.ProfitStats) {
+	if session.dustSweeper != nil {
+		session.dustSweeper.BindProfitStats(profitStats)
+	}
+}
+
 func (session *ExchangeSession) LastPrice(symbol string) (price fixedpoint.Value, ok bool) {
 	price, ok = session.lastPrices[symbol]
 	return price, ok
 }
 
+// FeePrice implements types.FeePriceSource with the last prices of the session,
+// the price is looked up from the fee currency market (e.g., BNBUSDT) or the inverse market (e.g., USDTBNB).
+// The time is ignored since the last price is the latest price we have.
+func (session *ExchangeSession) FeePrice(feeCurrency, quoteCurrency string, _ time.Time) (fixedpoint.Value, bool) {
+	if feeCurrency == quoteCurrency {
+		return fixedpoint.One, true
+	}
+
+	if price, ok := session.LastPrice(feeCurrency + quoteCurrency); ok && price.Sign() > 0 {
+		return price, true
+	}
+
+	if price, ok := session.LastPrice(quoteCurrency + feeCurrency); ok && price.Sign() > 0 {
+		return fixedpoint.One.Div(price), true
+	}
+
+	return fixedpoint.Zero, false
+}
+
 func (session *ExchangeSession) AllLastPrices() map[string]fixedpoint.Value {
 	return session.lastPrices
 }
@@ -610,15 +756,17 @@ func (session *ExchangeSession) Subscribe(channel types.Channel, symbol string,
 		panic("subscription interval for kline can not be empty")
 	}
 
-	sub := types.Subscription{
+	// identical subscriptions from different strategies are merged into one
+	sub := normalizeSubscription(types.Subscription{
 		Channel: channel,
 		Symbol:  symbol,
 		Options: options,
-	}
+	})
 
 	// add to the loaded symbol table
 	session.usedSymbols[symbol] = struct{}{}
 	session.Subscriptions[sub] = sub
+	session.addSubscriber(sub)
 	return session
 }
 
@@ -629,6 +777,11 @@ func (session *ExchangeSession) FormatOrder(order types.SubmitOrder) (types.Subm
 	}
 
 	order.Market = market
+
+	if order.SelfTradePrevention == types.SelfTradePreventionNone {
+		order.SelfTradePrevention = session.SelfTradePrevention
+	}
+
 	return order, nil
 }
 
@@ -778,6 +931,7 @@ func (session *ExchangeSession) InitExchange(name string, ex types.Exchange) err
 
 	// pointer fields
 	session.Subscriptions = make(map[types.Subscription]types.Subscription)
+	session.subscribers = make(map[types.Subscription][]string)
 	session.Account = &types.Account{}
 	session.Trades = make(map[string]*types.TradeSlice)
 
@@ -796,10 +950,202 @@ func (session *ExchangeSession) InitExchange(name string, ex types.Exchange) err
 
 	session.usedSymbols = make(map[string]struct{})
 	session.initializedSymbols = make(map[string]struct{})
+	session.facetSessions = make(map[types.AccountType]*ExchangeSession)
 	session.logger = log.WithField("session", name)
 	return nil
 }
 
+// AccountType returns the account type of this session by the margin and futures settings
+func (session *ExchangeSession) AccountType() types.AccountType {
+	if session.Futures {
+		return types.AccountTypeFutures
+	}
+
+	if session.Margin {
+		if session.IsolatedMargin {
+			return types.AccountTypeIsolatedMargin
+		}
+
+		return types.AccountTypeMargin
+	}
+
+	return types.AccountTypeSpot
+}
+
+// Facet returns the session of the given account type.
+// If the given account type is the account type of this session, the session itself will be returned.
+func (session *ExchangeSession) Facet(accountType types.AccountType) (*ExchangeSession, bool) {
+	if session.AccountType() == accountType {
+		return session, true
+	}
+
+	facet, ok := session.facetSessions[accountType]
+	return facet, ok
+}
+
+// FacetSessions returns the account facet sessions created from the Facets config
+func (session *ExchangeSession) FacetSessions() map[types.AccountType]*ExchangeSession {
+	return session.facetSessions
+}
+
+// cloneSession creates a session copy with the same config, and shares the exchange, the streams,
+// the account, the market data and the stores of the session.
+// The derived sessions override the fields they change on the copy.
+func (session *ExchangeSession) cloneSession() *ExchangeSession {
+	clone := &ExchangeSession{
+		Name:                    session.Name,
+		ExchangeName:            session.ExchangeName,
+		EnvVarPrefix:            session.EnvVarPrefix,
+		Key:                     session.Key,
+		Secret:                  session.Secret,
+		Passphrase:              session.Passphrase,
+		SubAccount:              session.SubAccount,
+		SecretName:              session.SecretName,
+		Withdrawal:              session.Withdrawal,
+		MakerFeeRate:            session.MakerFeeRate,
+		TakerFeeRate:            session.TakerFeeRate,
+		ModifyOrderAmountForFee: session.ModifyOrderAmountForFee,
+		PublicOnly:              session.PublicOnly,
+		Margin:                  session.Margin,
+		IsolatedMargin:          session.IsolatedMargin,
+		IsolatedMarginSymbol:    session.IsolatedMarginSymbol,
+		IsolatedMarginLeverage:  session.IsolatedMarginLeverage,
+		AutoRepay:               session.AutoRepay,
+		Futures:                 session.Futures,
+		IsolatedFutures:         session.IsolatedFutures,
+		IsolatedFuturesSymbol:   session.IsolatedFuturesSymbol,
+		SelfTradePrevention:     session.SelfTradePrevention,
+		Facets:                  session.Facets,
+		Account:                 session.GetAccount(),
+		IsInitialized:           session.IsInitialized,
+		UserDataStream:          session.UserDataStream,
+		MarketDataStream:        session.MarketDataStream,
+		Subscriptions:           session.Subscriptions,
+		subscribers:             session.subscribers,
+		Exchange:                session.Exchange,
+		UseHeikinAshi:           session.UseHeikinAshi,
+		Trades:                  session.Trades,
+		markets:                 session.markets,
+		orderBooks:              session.orderBooks,
+		startPrices:             session.startPrices,
+		lastPrices:              session.lastPrices,
+		lastPriceUpdatedAt:      session.lastPriceUpdatedAt,
+		marketDataStores:        session.marketDataStores,
+		positions:               session.positions,
+		standardIndicatorSets:   session.standardIndicatorSets,
+		orderStores:             session.orderStores,
+		usedSymbols:             session.usedSymbols,
+		initializedSymbols:      session.initializedSymbols,
+		facetSessions:           session.facetSessions,
+		logger:                  session.logger,
+	}
+
+	clone.OrderExecutor = &ExchangeOrderExecutor{
+		Session: clone,
+	}
+
+	return clone
+}
+
+// newFacetSession creates a new session config that shares the same credentials and fee settings with this session,
+// the margin and futures settings are configured by the given account type.
+func (session *ExchangeSession) newFacetSession(accountType types.AccountType) (*ExchangeSession, error) {
+	if accountType == session.AccountType() {
+		return nil, fmt.Errorf("session %s is already a %s account, facet %s is redundant", session.Name, accountType, accountType)
+	}
+
+	// the exchange, the streams and the stores of the facet are created by InitExchange
+	facet := session.cloneSession()
+	facet.Margin = false
+	facet.IsolatedMargin = false
+	facet.IsolatedMarginSymbol = ""
+	facet.IsolatedMarginLeverage = 0
+	facet.Futures = false
+	facet.IsolatedFutures = false
+	facet.IsolatedFuturesSymbol = ""
+	facet.Facets = nil
+	facet.IsInitialized = false
+
+	switch accountType {
+	case types.AccountTypeSpot:
+	case types.AccountTypeMargin:
+		facet.Margin = true
+	case types.AccountTypeFutures:
+		facet.Futures = true
+	default:
+		return nil, fmt.Errorf("unsupported account facet type: %s, valid types are: %s, %s, %s",
+			accountType, types.AccountTypeSpot, types.AccountTypeMargin, types.AccountTypeFutures)
+	}
+
+	return facet, nil
+}
+
+// newDryRunSession creates a session copy that shares the streams and the market data of the session,
+// but submits paper orders through DryRunExchange until the liveAfter time.
+// The paper order updates are emitted on its own user data stream, see dryRunStream.
+func (session *ExchangeSession) newDryRunSession(liveAfter time.Time) *ExchangeSession {
+	userDataStream := newDryRunStream(session.UserDataStream)
+
+	dryRun := session.cloneSession()
+	dryRun.UserDataStream = userDataStream
+	dryRun.Exchange = NewDryRunExchange(session.Exchange, userDataStream, liveAfter)
+	dryRun.logger = session.logger.WithField("dryRun", true)
+	return dryRun
+}
+
+// newGuardedSession creates a session copy that shares the exchange and the market data of the session,
+// the callbacks registered on its streams are guarded by the strategy guard.
+func (session *ExchangeSession) newGuardedSession(guard *StrategyGuard) *ExchangeSession {
+	guarded := session.cloneSession()
+	guarded.UserDataStream = newGuardedStream(session.UserDataStream, guard)
+	guarded.MarketDataStream = newGuardedStream(session.MarketDataStream, guard)
+	guarded.logger = session.logger.WithField("guarded", true)
+	guarded.guard = guard
+	return guarded
+}
+
+// newShadowSession creates a paper session that shares the market data of the session, the orders are filled by
+// ShadowExchange with the live market data. The shadow session has its own user data stream, account and order stores,
+// the paper balances are copied from the session account.
+func (session *ExchangeSession) newShadowSession(latency time.Duration) *ExchangeSession {
+	account := types.NewAccount()
+	account.UpdateBalances(session.GetAccount().Balances())
+
+	userDataStream := &types.StandardStream{}
+	exchange := NewShadowExchange(session.Exchange, userDataStream, account, latency, session.MakerFeeRate, session.TakerFeeRate)
+	exchange.BindMarketData(session.MarketDataStream)
+
+	// the shadow stream is never connected, it starts with the user data stream of the session
+	session.UserDataStream.OnStart(userDataStream.EmitStart)
+
+	shadow := session.cloneSession()
+
+	// the shadow session never touches the real account
+	shadow.Key = ""
+	shadow.Secret = ""
+	shadow.Passphrase = ""
+	shadow.SubAccount = ""
+	shadow.SecretName = ""
+	shadow.Withdrawal = false
+	shadow.AutoRepay = false
+	shadow.Facets = nil
+
+	shadow.Account = account
+	shadow.UserDataStream = userDataStream
+	shadow.Exchange = exchange
+	shadow.Trades = make(map[string]*types.TradeSlice)
+	shadow.positions = make(map[string]*types.Position)
+	shadow.orderStores = make(map[string]*OrderStore)
+	shadow.facetSessions = make(map[types.AccountType]*ExchangeSession)
+	shadow.logger = session.logger.WithField("shadow", true)
+	return shadow
+}
+
+// FacetSessionName returns the session name of the account facet
+func FacetSessionName(sessionName string, accountType types.AccountType) string {
+	return sessionName + ":" + string(accountType)
+}
+
 func (session *ExchangeSession) MarginType() string {
 	margin := "none"
 	if session.Margin {
@@ -912,6 +1258,17 @@ func (session *ExchangeSession) bindUserDataStreamMetrics(stream types.Stream) {
 	})
 }
 
+// bindStreamReconnectMetrics counts the connections after the first one as the reconnects
+func (session *ExchangeSession) bindStreamReconnectMetrics(stream types.Stream, channel string) {
+	connected := false
+	stream.OnConnect(func() {
+		if connected {
+			exchangemetrics.IncStreamReconnects(session.ExchangeName.String(), session.Name, channel)
+		}
+		connected = true
+	})
+}
+
 func (session *ExchangeSession) bindConnectionStatusNotification(stream types.Stream, streamName string) {
 	stream.OnDisconnect(func() {
 		Notify("session %s %s stream disconnected", session.Name, streamName)
@@ -0,0 +1,208 @@
+package bbgo
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultSessionRecorderDirectory = "data/sessions"
+
+// SessionRecorderConfig enables the session recording, all the stream events and the strategy decisions
+// of the sessions are written into an event log, which can be replayed by the backtest replayer.
+type SessionRecorderConfig struct {
+	// Sessions are the recorded sessions, all the sessions are recorded if it's empty
+	Sessions []string `json:"sessions,omitempty" yaml:"sessions,omitempty"`
+
+	// Directory is the output directory, defaults to data/sessions,
+	// the events are written into {directory}/session-{start time}.jsonl, one file per run.
+	Directory string `json:"directory,omitempty" yaml:"directory,omitempty"`
+
+	// BookUpdates records the order book updates, the order book can not be replayed without the updates,
+	// it's disabled by default since the updates are the most of the events.
+	BookUpdates bool `json:"bookUpdates,omitempty" yaml:"bookUpdates,omitempty"`
+}
+
+// SessionRecorder writes the session events in the received order, each event has an increasing sequence number,
+// so that the events can be re-fed in the same order deterministically.
+type SessionRecorder struct {
+	config SessionRecorderConfig
+
+	mu     sync.Mutex
+	seq    uint64
+	closer io.Closer
+	w      *bufio.Writer
+
+	// now returns the recording time of the event
+	now func() time.Time
+
+	logger logrus.FieldLogger
+}
+
+// NewSessionRecorder creates the event log file of this run
+func NewSessionRecorder(config *SessionRecorderConfig) (*SessionRecorder, error) {
+	c := *config
+	if c.Directory == "" {
+		c.Directory = defaultSessionRecorderDirectory
+	}
+
+	if err := os.MkdirAll(c.Directory, 0755); err != nil {
+		return nil, err
+	}
+
+	filename := filepath.Join(c.Directory, fmt.Sprintf("session-%s.jsonl", time.Now().Format("20060102-150405")))
+	file, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	r := newSessionRecorder(c, file)
+	r.logger.Infof("recording the session events into %s", filename)
+	return r, nil
+}
+
+func newSessionRecorder(config SessionRecorderConfig, w io.WriteCloser) *SessionRecorder {
+	return &SessionRecorder{
+		config: config,
+		closer: w,
+		w:      bufio.NewWriter(w),
+		now:    time.Now,
+		logger: logrus.WithField("component", "sessionRecorder"),
+	}
+}
+
+func (r *SessionRecorder) isRecorded(session *ExchangeSession) bool {
+	if len(r.config.Sessions) == 0 {
+		return true
+	}
+
+	for _, name := range r.config.Sessions {
+		if name == session.Name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Bind records the events of the session streams, it should be called before the streams are connected
+func (r *SessionRecorder) Bind(session *ExchangeSession) {
+	if !r.isRecorded(session) {
+		return
+	}
+
+	name := session.Name
+	marketStream := session.MarketDataStream
+	marketStream.OnKLineClosed(func(k types.KLine) {
+		r.Record(name, types.SessionEventKLineClosed, k)
+	})
+	marketStream.OnBookSnapshot(func(book types.SliceOrderBook) {
+		r.Record(name, types.SessionEventBookSnapshot, book)
+	})
+	marketStream.OnBookTickerUpdate(func(bookTicker types.BookTicker) {
+		r.Record(name, types.SessionEventBookTicker, bookTicker)
+	})
+	marketStream.OnMarketTrade(func(trade types.Trade) {
+		r.Record(name, types.SessionEventMarketTrade, trade)
+	})
+
+	if r.config.BookUpdates {
+		marketStream.OnBookUpdate(func(book types.SliceOrderBook) {
+			r.Record(name, types.SessionEventBookUpdate, book)
+		})
+	}
+
+	userStream := session.UserDataStream
+	userStream.OnOrderUpdate(func(order types.Order) {
+		r.Record(name, types.SessionEventOrderUpdate, order)
+	})
+	userStream.OnTradeUpdate(func(trade types.Trade) {
+		r.Record(name, types.SessionEventTradeUpdate, trade)
+	})
+	userStream.OnBalanceSnapshot(func(balances types.BalanceMap) {
+		r.Record(name, types.SessionEventBalanceSnapshot, balances)
+	})
+	userStream.OnBalanceUpdate(func(balances types.BalanceMap) {
+		r.Record(name, types.SessionEventBalanceUpdate, balances)
+	})
+}
+
+// RecordDecision records the strategy decision made on the session
+func (r *SessionRecorder) RecordDecision(session string, decision types.StrategyDecision) {
+	r.Record(session, types.SessionEventDecision, decision)
+}
+
+// Record writes the event with the next sequence number
+func (r *SessionRecorder) Record(session string, eventType types.SessionEventType, payload interface{}) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		r.logger.WithError(err).Errorf("can not encode the %s event", eventType)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.seq++
+	event := types.SessionEvent{
+		Seq:     r.seq,
+		Time:    r.now(),
+		Session: session,
+		Type:    eventType,
+		Payload: data,
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		r.logger.WithError(err).Errorf("can not encode the %s event", eventType)
+		return
+	}
+
+	if _, err := r.w.Write(append(line, '\n')); err != nil {
+		r.logger.WithError(err).Errorf("can not write the %s event", eventType)
+	}
+}
+
+// Flush writes the buffered events into the file
+func (r *SessionRecorder) Flush() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.w.Flush()
+}
+
+// Run flushes the buffered events periodically until the context is done
+func (r *SessionRecorder) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			if err := r.Flush(); err != nil {
+				r.logger.WithError(err).Error("can not flush the session events")
+			}
+		}
+	}
+}
+
+// Close flushes the buffered events and closes the file
+func (r *SessionRecorder) Close() error {
+	if err := r.Flush(); err != nil {
+		return err
+	}
+
+	return r.closer.Close()
+}
@@ -0,0 +1,130 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestExchangeSession_AccountType(t *testing.T) {
+	assert.Equal(t, types.AccountTypeSpot, (&ExchangeSession{}).AccountType())
+	assert.Equal(t, types.AccountTypeMargin, (&ExchangeSession{Margin: true}).AccountType())
+	assert.Equal(t, types.AccountTypeIsolatedMargin, (&ExchangeSession{Margin: true, IsolatedMargin: true}).AccountType())
+	assert.Equal(t, types.AccountTypeFutures, (&ExchangeSession{Futures: true}).AccountType())
+}
+
+func TestExchangeSession_newFacetSession(t *testing.T) {
+	session := &ExchangeSession{
+		Name:         "binance",
+		ExchangeName: types.ExchangeBinance,
+		EnvVarPrefix: "BINANCE",
+		MakerFeeRate: fixedpoint.MustNewFromString("0.02%"),
+		TakerFeeRate: fixedpoint.MustNewFromString("0.04%"),
+	}
+
+	t.Run("futures", func(t *testing.T) {
+		facet, err := session.newFacetSession(types.AccountTypeFutures)
+		if assert.NoError(t, err) {
+			assert.True(t, facet.Futures)
+			assert.False(t, facet.Margin)
+			assert.Equal(t, session.EnvVarPrefix, facet.EnvVarPrefix)
+			assert.Equal(t, session.MakerFeeRate, facet.MakerFeeRate)
+			assert.Equal(t, types.AccountTypeFutures, facet.AccountType())
+		}
+	})
+
+	t.Run("margin", func(t *testing.T) {
+		facet, err := session.newFacetSession(types.AccountTypeMargin)
+		if assert.NoError(t, err) {
+			assert.True(t, facet.Margin)
+			assert.Equal(t, types.AccountTypeMargin, facet.AccountType())
+		}
+	})
+
+	t.Run("redundant", func(t *testing.T) {
+		_, err := session.newFacetSession(types.AccountTypeSpot)
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		_, err := session.newFacetSession(types.AccountTypeIsolatedMargin)
+		assert.Error(t, err)
+	})
+}
+
+func TestExchangeSession_cloneSession(t *testing.T) {
+	session := &ExchangeSession{
+		Name:                "binance",
+		ExchangeName:        types.ExchangeBinance,
+		SelfTradePrevention: types.SelfTradePreventionExpireTaker,
+		Account:             types.NewAccount(),
+		markets:             map[string]types.Market{"BTCUSDT": {Symbol: "BTCUSDT"}},
+	}
+
+	clone := session.cloneSession()
+	assert.Equal(t, session.Name, clone.Name)
+	assert.Equal(t, session.SelfTradePrevention, clone.SelfTradePrevention)
+	assert.Same(t, session.Account, clone.Account)
+	assert.Same(t, clone, clone.OrderExecutor.Session)
+
+	// the market data is shared with the session
+	session.markets["ETHUSDT"] = types.Market{Symbol: "ETHUSDT"}
+	_, ok := clone.Market("ETHUSDT")
+	assert.True(t, ok)
+}
+
+func TestFacetSessionName(t *testing.T) {
+	assert.Equal(t, "binance:futures", FacetSessionName("binance", types.AccountTypeFutures))
+}
+
+func TestExchangeSession_FormatOrder_SelfTradePrevention(t *testing.T) {
+	session := &ExchangeSession{
+		SelfTradePrevention: types.SelfTradePreventionExpireTaker,
+		markets:             map[string]types.Market{"BTCUSDT": {Symbol: "BTCUSDT"}},
+	}
+
+	order, err := session.FormatOrder(types.SubmitOrder{Symbol: "BTCUSDT"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.SelfTradePreventionExpireTaker, order.SelfTradePrevention)
+	}
+
+	order, err = session.FormatOrder(types.SubmitOrder{Symbol: "BTCUSDT", SelfTradePrevention: types.SelfTradePreventionExpireBoth})
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.SelfTradePreventionExpireBoth, order.SelfTradePrevention)
+	}
+}
+
+func TestExchangeSession_EffectiveSubscriptions(t *testing.T) {
+	session := &ExchangeSession{
+		Subscriptions: make(map[types.Subscription]types.Subscription),
+		usedSymbols:   make(map[string]struct{}),
+	}
+
+	session.subscribeAs("binance.a", func() {
+		session.Subscribe(types.KLineChannel, "BTCUSDT", types.SubscribeOptions{Interval: types.Interval1m})
+		session.Subscribe(types.BookChannel, "BTCUSDT", types.SubscribeOptions{Depth: types.DepthLevelFull})
+	})
+
+	session.subscribeAs("binance.b", func() {
+		// the depth option does not apply to the kline channel
+		session.Subscribe(types.KLineChannel, "BTCUSDT", types.SubscribeOptions{Interval: types.Interval1m, Depth: types.DepthLevelFull})
+		session.Subscribe(types.KLineChannel, "BTCUSDT", types.SubscribeOptions{Interval: types.Interval1m})
+		session.Subscribe(types.KLineChannel, "ETHUSDT", types.SubscribeOptions{Interval: types.Interval5m})
+	})
+
+	subscriptions := session.EffectiveSubscriptions()
+	if assert.Len(t, subscriptions, 3) {
+		assert.Equal(t, types.BookChannel, subscriptions[0].Channel)
+		assert.Equal(t, []string{"binance.a"}, subscriptions[0].Subscribers)
+
+		assert.Equal(t, "BTCUSDT", subscriptions[1].Symbol)
+		assert.Equal(t, types.SubscribeOptions{Interval: types.Interval1m}, subscriptions[1].Options)
+		assert.Equal(t, []string{"binance.a", "binance.b"}, subscriptions[1].Subscribers)
+
+		assert.Equal(t, "ETHUSDT", subscriptions[2].Symbol)
+		assert.Equal(t, []string{"binance.b"}, subscriptions[2].Subscribers)
+	}
+}
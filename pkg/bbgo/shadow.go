@@ -0,0 +1,250 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/slack-go/slack"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultShadowReportInterval = time.Hour
+
+// shadowStrategy is the shadow instance of a live strategy instance, it runs with the overridden config on a shadow session
+type shadowStrategy struct {
+	sessionName string
+	live        SingleExchangeStrategy
+	strategy    SingleExchangeStrategy
+
+	session *ExchangeSession
+	ctx     context.Context
+
+	mu        sync.Mutex
+	startTime time.Time
+
+	// liveBaseline and shadowBaseline are the PnL of the instances when the market data is ready,
+	// the divergence is calculated from the PnL changes since then
+	liveBaseline, shadowBaseline fixedpoint.Value
+}
+
+// ShadowReport is the PnL comparison between the live instance and the shadow instance of a strategy
+type ShadowReport struct {
+	Session   string           `json:"session"`
+	Strategy  string           `json:"strategy"`
+	Since     time.Time        `json:"since"`
+	LivePnL   fixedpoint.Value `json:"livePnL"`
+	ShadowPnL fixedpoint.Value `json:"shadowPnL"`
+
+	// Divergence is the shadow PnL minus the live PnL
+	Divergence fixedpoint.Value `json:"divergence"`
+}
+
+func (r *ShadowReport) String() string {
+	return fmt.Sprintf("%s %s shadow report since %s: live pnl %s, shadow pnl %s, divergence %s",
+		r.Session, r.Strategy, r.Since.Format(time.RFC3339),
+		r.LivePnL.String(), r.ShadowPnL.String(), r.Divergence.String())
+}
+
+func (r *ShadowReport) SlackAttachment() slack.Attachment {
+	color := "#228B22"
+	if r.Divergence.Sign() < 0 {
+		color = "#DC143C"
+	}
+
+	return slack.Attachment{
+		Title: fmt.Sprintf("Shadow Report %s %s", r.Session, r.Strategy),
+		Color: color,
+		Fields: []slack.AttachmentField{
+			{Title: "Live PnL", Value: r.LivePnL.String(), Short: true},
+			{Title: "Shadow PnL", Value: r.ShadowPnL.String(), Short: true},
+			{Title: "Divergence", Value: r.Divergence.String(), Short: true},
+		},
+		Footer: "Since " + r.Since.Format(time.RFC822),
+	}
+}
+
+// newShadowStrategy creates the shadow instance from the raw config of the live instance and the config overrides,
+// the overrides replace the top-level fields of the strategy config.
+func newShadowStrategy(strategyID string, rawConfig json.RawMessage, overrides map[string]interface{}) (SingleExchangeStrategy, error) {
+	conf := make(map[string]interface{})
+	if len(rawConfig) > 0 {
+		if err := json.Unmarshal(rawConfig, &conf); err != nil {
+			return nil, err
+		}
+	}
+
+	for key, value := range overrides {
+		conf[key] = value
+	}
+
+	return NewStrategyFromMap(strategyID, conf)
+}
+
+// SetShadowTrading sets the shadow trading config
+func (trader *Trader) SetShadowTrading(config *ShadowTradingConfig) {
+	trader.shadowTrading = config
+}
+
+// AttachShadowStrategy attaches the shadow instance of the live strategy on the session,
+// the shadow instance runs with the simulated fills when the trader runs.
+func (trader *Trader) AttachShadowStrategy(session string, live, shadow SingleExchangeStrategy) error {
+	if _, ok := trader.environment.sessions[session]; !ok {
+		return fmt.Errorf("session %s is not defined, valid sessions are: %v", session, trader.environment.sessions)
+	}
+
+	trader.shadowStrategies = append(trader.shadowStrategies, &shadowStrategy{
+		sessionName: session,
+		live:        live,
+		strategy:    shadow,
+	})
+	return nil
+}
+
+func (trader *Trader) isShadowStrategy(strategy interface{}) bool {
+	for _, s := range trader.shadowStrategies {
+		if s.strategy == strategy {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (trader *Trader) shadowLatency() time.Duration {
+	if trader.shadowTrading != nil {
+		return trader.shadowTrading.Latency.Duration()
+	}
+
+	return 0
+}
+
+func (trader *Trader) shadowReportInterval() time.Duration {
+	if trader.shadowTrading != nil && trader.shadowTrading.ReportInterval > 0 {
+		return trader.shadowTrading.ReportInterval.Duration()
+	}
+
+	return defaultShadowReportInterval
+}
+
+// injectShadowStrategies creates the shadow sessions and injects the shadow instances,
+// the shadow instances use the memory persistence, so that they never touch the states of the live instances.
+func (trader *Trader) injectShadowStrategies(ctx context.Context) error {
+	for _, s := range trader.shadowStrategies {
+		s.session = trader.environment.sessions[s.sessionName].newShadowSession(trader.shadowLatency())
+		s.ctx = NewContextWithIsolation(ctx, NewIsolation(&service.PersistenceServiceFacade{
+			Memory: service.NewMemoryService(),
+		}))
+
+		if err := trader.injectSingleExchangeStrategy(s.ctx, s.sessionName, s.session, s.session.OrderExecutor, s.strategy); err != nil {
+			return errors.Wrapf(err, "failed to inject the shadow instance of %s", s.live.ID())
+		}
+	}
+
+	return nil
+}
+
+func (trader *Trader) runShadowStrategies() error {
+	for _, s := range trader.shadowStrategies {
+		s := s
+		if err := trader.RunSingleExchangeStrategy(s.ctx, s.strategy, s.session, s.session.OrderExecutor); err != nil {
+			return errors.Wrapf(err, "failed to run the shadow instance of %s", s.live.ID())
+		}
+
+		// the baselines are taken when the prices are ready
+		var once sync.Once
+		s.session.MarketDataStream.OnKLineClosed(func(kline types.KLine) {
+			once.Do(s.setBaseline)
+		})
+
+		log.Infof("shadow instance of %s is running on session %s", s.live.ID(), s.sessionName)
+	}
+
+	if len(trader.shadowStrategies) > 0 {
+		go trader.reportShadowStrategies(trader.runCtx, trader.shadowReportInterval())
+	}
+
+	return nil
+}
+
+// ShadowReports returns the PnL reports of the shadow instances, the instances without the baselines are skipped
+func (trader *Trader) ShadowReports() (reports []*ShadowReport) {
+	for _, s := range trader.shadowStrategies {
+		if report := s.Report(); report != nil {
+			reports = append(reports, report)
+		}
+	}
+
+	return reports
+}
+
+func (trader *Trader) reportShadowStrategies(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+
+		case <-ticker.C:
+			for _, report := range trader.ShadowReports() {
+				log.Info(report.String())
+				Notify(report)
+			}
+		}
+	}
+}
+
+func (s *shadowStrategy) setBaseline() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.startTime = time.Now()
+	s.liveBaseline = strategyPnL(s.live, s.session)
+	s.shadowBaseline = strategyPnL(s.strategy, s.session)
+}
+
+// Report returns the PnL changes of the live instance and the shadow instance since the baseline,
+// it returns nil if the baseline is not taken yet.
+func (s *shadowStrategy) Report() *ShadowReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.startTime.IsZero() {
+		return nil
+	}
+
+	livePnL := strategyPnL(s.live, s.session).Sub(s.liveBaseline)
+	shadowPnL := strategyPnL(s.strategy, s.session).Sub(s.shadowBaseline)
+
+	return &ShadowReport{
+		Session:    s.sessionName,
+		Strategy:   s.live.ID(),
+		Since:      s.startTime,
+		LivePnL:    livePnL,
+		ShadowPnL:  shadowPnL,
+		Divergence: shadowPnL.Sub(livePnL),
+	}
+}
+
+// strategyPnL sums the realized profits and the unrealized profits of the strategy positions in the quote currency
+func strategyPnL(strategy interface{}, session *ExchangeSession) fixedpoint.Value {
+	pnl := fixedpoint.Zero
+	for _, position := range collectStrategyPositions(strategy) {
+		pnl = pnl.Add(position.AccumulatedProfit)
+
+		if price, ok := session.LastPrice(position.Symbol); ok {
+			pnl = pnl.Add(position.UnrealizedProfit(price))
+		}
+	}
+
+	return pnl
+}
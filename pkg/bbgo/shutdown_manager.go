@@ -0,0 +1,359 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// ShutdownPhase is the phase of the graceful shutdown, the phases are executed in the declared order
+type ShutdownPhase int
+
+const (
+	// ShutdownPhaseStopQuoting stops the strategies from placing the new orders
+	ShutdownPhaseStopQuoting ShutdownPhase = iota
+
+	// ShutdownPhaseCancelOrders cancels the open orders, the handlers registered by OnShutdown are executed in this phase
+	ShutdownPhaseCancelOrders
+
+	// ShutdownPhaseFlatten closes the positions, it's executed only when the flatten phase is enabled,
+	// the strategies register the flatten hooks, nothing is closed by default
+	ShutdownPhaseFlatten
+
+	// ShutdownPhasePersist stores the strategy states
+	ShutdownPhasePersist
+
+	// ShutdownPhaseDisconnect closes the streams and the connections
+	ShutdownPhaseDisconnect
+)
+
+var shutdownPhases = []ShutdownPhase{
+	ShutdownPhaseStopQuoting,
+	ShutdownPhaseCancelOrders,
+	ShutdownPhaseFlatten,
+	ShutdownPhasePersist,
+	ShutdownPhaseDisconnect,
+}
+
+var shutdownPhaseNames = map[ShutdownPhase]string{
+	ShutdownPhaseStopQuoting:  "stopQuoting",
+	ShutdownPhaseCancelOrders: "cancelOrders",
+	ShutdownPhaseFlatten:      "flatten",
+	ShutdownPhasePersist:      "persist",
+	ShutdownPhaseDisconnect:   "disconnect",
+}
+
+func (p ShutdownPhase) String() string {
+	if name, ok := shutdownPhaseNames[p]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("ShutdownPhase(%d)", int(p))
+}
+
+// ParseShutdownPhase parses the phase name, e.g., "cancelOrders"
+func ParseShutdownPhase(name string) (ShutdownPhase, error) {
+	for phase, phaseName := range shutdownPhaseNames {
+		if phaseName == name {
+			return phase, nil
+		}
+	}
+
+	return 0, fmt.Errorf("unknown shutdown phase %q", name)
+}
+
+// DefaultShutdownPhaseTimeouts bounds each phase, so a stuck phase can not use up the time of the following phases
+var DefaultShutdownPhaseTimeouts = map[ShutdownPhase]time.Duration{
+	ShutdownPhaseStopQuoting:  3 * time.Second,
+	ShutdownPhaseCancelOrders: 15 * time.Second,
+	ShutdownPhaseFlatten:      5 * time.Second,
+	ShutdownPhasePersist:      4 * time.Second,
+	ShutdownPhaseDisconnect:   2 * time.Second,
+}
+
+// ShutdownConfig configures the graceful shutdown
+type ShutdownConfig struct {
+	// Timeout is the timeout of the whole shutdown, defaults to 30 seconds
+	Timeout types.Duration `json:"timeout,omitempty" yaml:"timeout,omitempty"`
+
+	// Flatten enables the flatten phase, the positions are closed by the flatten hooks of the strategies,
+	// the run fails if none of the strategies registers a flatten hook
+	Flatten bool `json:"flatten,omitempty" yaml:"flatten,omitempty"`
+
+	// PhaseTimeouts overrides the timeouts of the phases, the keys are the phase names:
+	// stopQuoting, cancelOrders, flatten, persist and disconnect
+	PhaseTimeouts map[string]types.Duration `json:"phaseTimeouts,omitempty" yaml:"phaseTimeouts,omitempty"`
+}
+
+func (c *ShutdownConfig) Validate() error {
+	if c.Timeout < 0 {
+		return fmt.Errorf("shutdown timeout can not be negative")
+	}
+
+	for name, timeout := range c.PhaseTimeouts {
+		if _, err := ParseShutdownPhase(name); err != nil {
+			return err
+		}
+
+		if timeout < 0 {
+			return fmt.Errorf("shutdown phase %s timeout can not be negative", name)
+		}
+	}
+
+	return nil
+}
+
+// ShutdownHook is executed in a shutdown phase, the returned error is listed in the shutdown report
+type ShutdownHook func(ctx context.Context) error
+
+type namedShutdownHook struct {
+	name string
+	hook ShutdownHook
+}
+
+// CancelOrdersError is returned by the shutdown hook when some orders are not canceled,
+// the orders are listed in the shutdown report
+type CancelOrdersError struct {
+	Orders types.OrderSlice
+	Err    error
+}
+
+func (e *CancelOrdersError) Error() string {
+	msg := fmt.Sprintf("%d orders are not canceled", len(e.Orders))
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+
+	return msg
+}
+
+func (e *CancelOrdersError) Unwrap() error {
+	return e.Err
+}
+
+// ShutdownHookResult is the result of a shutdown hook
+type ShutdownHookResult struct {
+	Phase    ShutdownPhase
+	Name     string
+	Err      error
+	TimedOut bool
+	Duration time.Duration
+}
+
+// ShutdownReport is the final status of the shutdown
+type ShutdownReport struct {
+	Results []ShutdownHookResult
+}
+
+// Failed returns the results of the failed or timed out hooks
+func (r *ShutdownReport) Failed() (results []ShutdownHookResult) {
+	for _, result := range r.Results {
+		if result.Err != nil || result.TimedOut {
+			results = append(results, result)
+		}
+	}
+
+	return results
+}
+
+// FailedOrders returns the orders that failed to cancel
+func (r *ShutdownReport) FailedOrders() (orders types.OrderSlice) {
+	for _, result := range r.Results {
+		var cancelErr *CancelOrdersError
+		if errors.As(result.Err, &cancelErr) {
+			orders = append(orders, cancelErr.Orders...)
+		}
+	}
+
+	return orders
+}
+
+func (r *ShutdownReport) PlainText() string {
+	failed := r.Failed()
+	if len(failed) == 0 {
+		return fmt.Sprintf("shutdown completed, %d hooks executed", len(r.Results))
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("shutdown completed with %d failed hooks:", len(failed)))
+	for _, result := range failed {
+		if result.TimedOut {
+			sb.WriteString(fmt.Sprintf("\n- [%s] %s: timed out after %s", result.Phase, result.Name, result.Duration))
+		} else {
+			sb.WriteString(fmt.Sprintf("\n- [%s] %s: %v", result.Phase, result.Name, result.Err))
+		}
+	}
+
+	for _, order := range r.FailedOrders() {
+		sb.WriteString("\nnot canceled: " + order.String())
+	}
+
+	return sb.String()
+}
+
+// ShutdownManager executes the shutdown hooks phase by phase,
+// the hooks of the same phase are executed concurrently and each phase is bounded by its timeout.
+type ShutdownManager struct {
+	mu       sync.Mutex
+	hooks    map[ShutdownPhase][]namedShutdownHook
+	timeouts map[ShutdownPhase]time.Duration
+	flatten  bool
+}
+
+func NewShutdownManager() *ShutdownManager {
+	timeouts := make(map[ShutdownPhase]time.Duration, len(DefaultShutdownPhaseTimeouts))
+	for phase, timeout := range DefaultShutdownPhaseTimeouts {
+		timeouts[phase] = timeout
+	}
+
+	return &ShutdownManager{
+		hooks:    make(map[ShutdownPhase][]namedShutdownHook),
+		timeouts: timeouts,
+	}
+}
+
+// Configure applies the flatten option and the phase timeouts of the config
+func (m *ShutdownManager) Configure(config *ShutdownConfig) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	m.SetFlatten(config.Flatten)
+	for name, timeout := range config.PhaseTimeouts {
+		phase, _ := ParseShutdownPhase(name)
+		m.SetPhaseTimeout(phase, timeout.Duration())
+	}
+
+	return nil
+}
+
+// SetPhaseTimeout sets the timeout of the phase, zero means the phase is bounded by the shutdown context only
+func (m *ShutdownManager) SetPhaseTimeout(phase ShutdownPhase, timeout time.Duration) {
+	m.mu.Lock()
+	m.timeouts[phase] = timeout
+	m.mu.Unlock()
+}
+
+// SetFlatten enables or disables the flatten phase
+func (m *ShutdownManager) SetFlatten(flatten bool) {
+	m.mu.Lock()
+	m.flatten = flatten
+	m.mu.Unlock()
+}
+
+// Validate checks the registered hooks against the config, it's called after the strategies are started.
+// The flatten phase without any flatten hook would leave the positions open silently.
+func (m *ShutdownManager) Validate() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.flatten && len(m.hooks[ShutdownPhaseFlatten]) == 0 {
+		return errors.New("shutdown: flatten is enabled, but none of the strategies registers a flatten hook, the positions would not be closed")
+	}
+
+	return nil
+}
+
+// OnPhase registers the hook in the phase, the name is shown in the shutdown report
+func (m *ShutdownManager) OnPhase(phase ShutdownPhase, name string, hook ShutdownHook) {
+	m.mu.Lock()
+	m.hooks[phase] = append(m.hooks[phase], namedShutdownHook{name: name, hook: hook})
+	m.mu.Unlock()
+}
+
+// OnShutdown registers the wait group style shutdown handler in the cancel orders phase
+func (m *ShutdownManager) OnShutdown(handler ShutdownHandler) {
+	m.mu.Lock()
+	name := fmt.Sprintf("shutdown handler #%d", len(m.hooks[ShutdownPhaseCancelOrders])+1)
+	m.mu.Unlock()
+
+	m.OnPhase(ShutdownPhaseCancelOrders, name, func(ctx context.Context) error {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		handler(ctx, &wg)
+		wg.Wait()
+		return nil
+	})
+}
+
+// Shutdown is a blocking call that executes the phases in order and returns the report of the hooks
+func (m *ShutdownManager) Shutdown(ctx context.Context) *ShutdownReport {
+	report := &ShutdownReport{}
+
+	for _, phase := range shutdownPhases {
+		m.mu.Lock()
+		hooks := append([]namedShutdownHook(nil), m.hooks[phase]...)
+		timeout := m.timeouts[phase]
+		skip := phase == ShutdownPhaseFlatten && !m.flatten
+		m.mu.Unlock()
+
+		if skip || len(hooks) == 0 {
+			continue
+		}
+
+		report.Results = append(report.Results, runShutdownPhase(ctx, phase, timeout, hooks)...)
+	}
+
+	return report
+}
+
+func runShutdownPhase(ctx context.Context, phase ShutdownPhase, timeout time.Duration, hooks []namedShutdownHook) []ShutdownHookResult {
+	phaseCtx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		phaseCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	type indexedResult struct {
+		index  int
+		result ShutdownHookResult
+	}
+
+	startTime := time.Now()
+	resultC := make(chan indexedResult, len(hooks))
+	for i, hook := range hooks {
+		go func(i int, hook namedShutdownHook) {
+			err := hook.hook(phaseCtx)
+			resultC <- indexedResult{
+				index: i,
+				result: ShutdownHookResult{
+					Phase:    phase,
+					Name:     hook.name,
+					Err:      err,
+					Duration: time.Since(startTime),
+				},
+			}
+		}(i, hook)
+	}
+
+	results := make([]ShutdownHookResult, len(hooks))
+	finished := make([]bool, len(hooks))
+	for n := 0; n < len(hooks); n++ {
+		select {
+		case r := <-resultC:
+			results[r.index] = r.result
+			finished[r.index] = true
+
+		case <-phaseCtx.Done():
+			for i, hook := range hooks {
+				if !finished[i] {
+					results[i] = ShutdownHookResult{
+						Phase:    phase,
+						Name:     hook.name,
+						Err:      phaseCtx.Err(),
+						TimedOut: true,
+						Duration: time.Since(startTime),
+					}
+				}
+			}
+			return results
+		}
+	}
+
+	return results
+}
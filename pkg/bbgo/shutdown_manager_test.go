@@ -0,0 +1,118 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestShutdownManager_Phases(t *testing.T) {
+	manager := NewShutdownManager()
+
+	var mu sync.Mutex
+	var executed []string
+	record := func(name string) ShutdownHook {
+		return func(ctx context.Context) error {
+			mu.Lock()
+			executed = append(executed, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	// the hooks are registered in the reversed order
+	manager.OnPhase(ShutdownPhaseDisconnect, "disconnect", record("disconnect"))
+	manager.OnPhase(ShutdownPhasePersist, "persist", record("persist"))
+	manager.OnPhase(ShutdownPhaseFlatten, "flatten", record("flatten"))
+	manager.OnPhase(ShutdownPhaseCancelOrders, "cancel", record("cancel"))
+	manager.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+		record("legacy")(ctx)
+	})
+	manager.OnPhase(ShutdownPhaseStopQuoting, "stop", record("stop"))
+
+	report := manager.Shutdown(context.Background())
+	assert.Empty(t, report.Failed())
+	assert.Len(t, report.Results, 5)
+	if assert.Len(t, executed, 5) {
+		assert.Equal(t, "stop", executed[0])
+		assert.ElementsMatch(t, []string{"cancel", "legacy"}, executed[1:3])
+		assert.Equal(t, []string{"persist", "disconnect"}, executed[3:], "the flatten phase is disabled by default")
+	}
+
+	executed = nil
+	manager.SetFlatten(true)
+	manager.Shutdown(context.Background())
+	assert.Contains(t, executed, "flatten")
+}
+
+func TestShutdownManager_PhaseTimeout(t *testing.T) {
+	manager := NewShutdownManager()
+	manager.SetPhaseTimeout(ShutdownPhaseCancelOrders, 10*time.Millisecond)
+
+	persisted := false
+	manager.OnPhase(ShutdownPhaseCancelOrders, "stuck", func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	manager.OnPhase(ShutdownPhaseCancelOrders, "failed", func(ctx context.Context) error {
+		return &CancelOrdersError{
+			Orders: types.OrderSlice{{OrderID: 1}, {OrderID: 2}},
+			Err:    errors.New("cancel error"),
+		}
+	})
+	manager.OnPhase(ShutdownPhasePersist, "persist", func(ctx context.Context) error {
+		persisted = true
+		return nil
+	})
+
+	report := manager.Shutdown(context.Background())
+	assert.True(t, persisted, "the following phases should be executed after the timed out phase")
+
+	failed := report.Failed()
+	if assert.Len(t, failed, 2) {
+		assert.Equal(t, "stuck", failed[0].Name)
+		assert.True(t, failed[0].TimedOut)
+		assert.Equal(t, "failed", failed[1].Name)
+		assert.False(t, failed[1].TimedOut)
+	}
+
+	assert.Len(t, report.FailedOrders(), 2)
+	assert.Contains(t, report.PlainText(), "2 orders are not canceled: cancel error")
+}
+
+func TestShutdownConfig_Validate(t *testing.T) {
+	config := &ShutdownConfig{
+		PhaseTimeouts: map[string]types.Duration{
+			"cancelOrders": types.Duration(time.Second),
+		},
+	}
+	assert.NoError(t, config.Validate())
+
+	manager := NewShutdownManager()
+	assert.NoError(t, manager.Configure(config))
+	assert.Equal(t, time.Second, manager.timeouts[ShutdownPhaseCancelOrders])
+
+	config.PhaseTimeouts["cancel"] = types.Duration(time.Second)
+	assert.Error(t, config.Validate())
+}
+
+func TestShutdownManager_Validate(t *testing.T) {
+	manager := NewShutdownManager()
+	assert.NoError(t, manager.Validate())
+
+	manager.SetFlatten(true)
+	assert.Error(t, manager.Validate(), "no flatten hook is registered")
+
+	manager.OnPhase(ShutdownPhaseFlatten, "binance.test:BTCUSDT", func(ctx context.Context) error {
+		return nil
+	})
+	assert.NoError(t, manager.Validate())
+}
@@ -0,0 +1,312 @@
+package bbgo
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Signal is a composed boolean signal, it's evaluated on the latest values of the indicator streams
+type Signal interface {
+	Evaluate() bool
+}
+
+// SignalComparison compares the indicator with another indicator (than) or a constant value
+type SignalComparison struct {
+	Indicator string `json:"indicator"`
+
+	// Than is the name of the indicator to compare with, the constant value is used when it's empty
+	Than string `json:"than,omitempty"`
+
+	Value float64 `json:"value,omitempty"`
+}
+
+// SignalConfig declares a signal, exactly one clause should be set in a signal config
+//
+//	entry:
+//	  all:
+//	  - crossOver: { indicator: fast, than: slow }
+//	  - below: { indicator: rsi, value: 70 }
+//	exit:
+//	  any:
+//	  - crossUnder: { indicator: fast, than: slow }
+//	  - not:
+//	      above: { indicator: close, than: bollDown }
+type SignalConfig struct {
+	All []SignalConfig `json:"all,omitempty"`
+	Any []SignalConfig `json:"any,omitempty"`
+	Not *SignalConfig  `json:"not,omitempty"`
+
+	// CrossOver is true when the indicator crosses over the comparison target on the latest value
+	CrossOver *SignalComparison `json:"crossOver,omitempty"`
+
+	// CrossUnder is true when the indicator crosses under the comparison target on the latest value
+	CrossUnder *SignalComparison `json:"crossUnder,omitempty"`
+
+	Above *SignalComparison `json:"above,omitempty"`
+	Below *SignalComparison `json:"below,omitempty"`
+}
+
+// Compile builds the signal tree with the indicator streams
+func (c *SignalConfig) Compile(streams map[string]indicator.Float64Source) (Signal, error) {
+	var signals []Signal
+	var numOfClauses int
+
+	if len(c.All) > 0 || len(c.Any) > 0 {
+		clauses := c.All
+		if len(c.Any) > 0 {
+			clauses = c.Any
+		}
+
+		for i := range clauses {
+			signal, err := clauses[i].Compile(streams)
+			if err != nil {
+				return nil, err
+			}
+
+			signals = append(signals, signal)
+		}
+	}
+
+	var signal Signal
+	if len(c.All) > 0 {
+		numOfClauses++
+		signal = allSignal(signals)
+	}
+
+	if len(c.Any) > 0 {
+		numOfClauses++
+		signal = anySignal(signals)
+	}
+
+	if c.Not != nil {
+		numOfClauses++
+		inner, err := c.Not.Compile(streams)
+		if err != nil {
+			return nil, err
+		}
+
+		signal = &notSignal{signal: inner}
+	}
+
+	comparisons := []struct {
+		op         comparisonOperator
+		comparison *SignalComparison
+	}{
+		{opCrossOver, c.CrossOver},
+		{opCrossUnder, c.CrossUnder},
+		{opAbove, c.Above},
+		{opBelow, c.Below},
+	}
+
+	for _, cmp := range comparisons {
+		if cmp.comparison == nil {
+			continue
+		}
+
+		numOfClauses++
+		s, err := newComparisonSignal(cmp.op, cmp.comparison, streams)
+		if err != nil {
+			return nil, err
+		}
+
+		signal = s
+	}
+
+	if numOfClauses == 0 {
+		return nil, errors.New("empty signal config, one of all, any, not, crossOver, crossUnder, above or below is required")
+	} else if numOfClauses > 1 {
+		return nil, errors.New("a signal config should have exactly one clause, use all or any to combine the signals")
+	}
+
+	return signal, nil
+}
+
+type allSignal []Signal
+
+func (s allSignal) Evaluate() bool {
+	for _, signal := range s {
+		if !signal.Evaluate() {
+			return false
+		}
+	}
+
+	return true
+}
+
+type anySignal []Signal
+
+func (s anySignal) Evaluate() bool {
+	for _, signal := range s {
+		if signal.Evaluate() {
+			return true
+		}
+	}
+
+	return false
+}
+
+type notSignal struct {
+	signal Signal
+}
+
+func (s *notSignal) Evaluate() bool {
+	return !s.signal.Evaluate()
+}
+
+type comparisonOperator string
+
+const (
+	opCrossOver  comparisonOperator = "crossOver"
+	opCrossUnder comparisonOperator = "crossUnder"
+	opAbove      comparisonOperator = "above"
+	opBelow      comparisonOperator = "below"
+)
+
+type comparisonSignal struct {
+	op    comparisonOperator
+	a, b  types.Series
+	value float64
+}
+
+func newComparisonSignal(op comparisonOperator, c *SignalComparison, streams map[string]indicator.Float64Source) (*comparisonSignal, error) {
+	a, ok := streams[c.Indicator]
+	if !ok {
+		return nil, fmt.Errorf("%s: indicator %q is not declared", op, c.Indicator)
+	}
+
+	s := &comparisonSignal{op: op, a: a, value: c.Value}
+	if c.Than != "" {
+		b, ok := streams[c.Than]
+		if !ok {
+			return nil, fmt.Errorf("%s: indicator %q is not declared", op, c.Than)
+		}
+
+		s.b = b
+	}
+
+	return s, nil
+}
+
+// diff returns the difference between the indicator and the comparison target of the i-th last value
+func (s *comparisonSignal) diff(i int) (float64, bool) {
+	if s.a.Length() <= i {
+		return 0, false
+	}
+
+	if s.b == nil {
+		return s.a.Last(i) - s.value, true
+	}
+
+	if s.b.Length() <= i {
+		return 0, false
+	}
+
+	return s.a.Last(i) - s.b.Last(i), true
+}
+
+func (s *comparisonSignal) Evaluate() bool {
+	current, ok := s.diff(0)
+	if !ok {
+		return false
+	}
+
+	switch s.op {
+	case opAbove:
+		return current > 0
+
+	case opBelow:
+		return current < 0
+
+	case opCrossOver, opCrossUnder:
+		previous, ok := s.diff(1)
+		if !ok {
+			return false
+		}
+
+		if s.op == opCrossOver {
+			return previous <= 0 && current > 0
+		}
+
+		return previous >= 0 && current < 0
+	}
+
+	return false
+}
+
+// SignalComposer builds the declared indicators on a kline stream and compiles the signals on them,
+// the indicators are warmed up with the history klines before the signals are evaluated.
+type SignalComposer struct {
+	Symbol   string
+	Interval types.Interval
+
+	// source is the private stream that feeds the klines to the indicators
+	source  *types.StandardStream
+	streams map[string]indicator.Float64Source
+}
+
+func NewSignalComposer(symbol string, interval types.Interval, configs map[string]IndicatorConfig) (*SignalComposer, error) {
+	source := &types.StandardStream{}
+	kLines := indicator.KLines(source, symbol, interval)
+	b := newIndicatorBuilder(kLines, configs)
+	if err := b.buildAll(); err != nil {
+		return nil, err
+	}
+
+	// the price streams can be referenced by the signals directly
+	for _, name := range []string{"close", "open", "high", "low", "volume"} {
+		if _, ok := b.streams[name]; ok {
+			continue
+		}
+
+		s, err := b.source(name)
+		if err != nil {
+			return nil, err
+		}
+
+		b.streams[name] = s
+	}
+
+	return &SignalComposer{
+		Symbol:   symbol,
+		Interval: interval,
+		source:   source,
+		streams:  b.streams,
+	}, nil
+}
+
+// Compile compiles the signal config with the indicators of the composer
+func (c *SignalComposer) Compile(config *SignalConfig) (Signal, error) {
+	return config.Compile(c.streams)
+}
+
+// Indicator returns the indicator stream of the given name
+func (c *SignalComposer) Indicator(name string) (indicator.Float64Source, bool) {
+	s, ok := c.streams[name]
+	return s, ok
+}
+
+// Update pushes the closed kline to the indicators
+func (c *SignalComposer) Update(k types.KLine) {
+	c.source.EmitKLineClosed(k)
+}
+
+// WarmUp pushes the history klines to the indicators
+func (c *SignalComposer) WarmUp(kLines []types.KLine) {
+	for _, k := range kLines {
+		c.Update(k)
+	}
+}
+
+// Bind pushes the closed klines of the market data stream to the indicators,
+// the callback is called after the indicators are updated.
+func (c *SignalComposer) Bind(stream types.Stream, callback func(k types.KLine)) {
+	stream.OnKLineClosed(types.KLineWith(c.Symbol, c.Interval, func(k types.KLine) {
+		c.Update(k)
+		if callback != nil {
+			callback(k)
+		}
+	}))
+}
@@ -0,0 +1,155 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newSignalTestKLine(i int, c float64) types.KLine {
+	t := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	return types.KLine{
+		Symbol:    "BTCUSDT",
+		Interval:  types.Interval1h,
+		StartTime: types.Time(t.Add(time.Duration(i) * time.Hour)),
+		Open:      fixedpoint.NewFromFloat(c),
+		High:      fixedpoint.NewFromFloat(c),
+		Low:       fixedpoint.NewFromFloat(c),
+		Close:     fixedpoint.NewFromFloat(c),
+		Closed:    true,
+	}
+}
+
+func TestSignalComposer(t *testing.T) {
+	composer, err := NewSignalComposer("BTCUSDT", types.Interval1h, map[string]IndicatorConfig{
+		"fast":   {Type: "sma", Window: 2},
+		"slow":   {Type: "sma", Window: 4},
+		"fastMA": {Type: "ewma", Window: 2, Source: "fast"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	entry, err := composer.Compile(&SignalConfig{
+		All: []SignalConfig{
+			{CrossOver: &SignalComparison{Indicator: "fast", Than: "slow"}},
+			{Below: &SignalComparison{Indicator: "close", Value: 120}},
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	exit, err := composer.Compile(&SignalConfig{
+		Any: []SignalConfig{
+			{CrossUnder: &SignalComparison{Indicator: "fast", Than: "slow"}},
+			{Not: &SignalConfig{Above: &SignalComparison{Indicator: "fastMA", Value: 50}}},
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var history []types.KLine
+	for i, c := range []float64{100, 99, 98, 97, 96} {
+		history = append(history, newSignalTestKLine(i, c))
+	}
+
+	composer.WarmUp(history)
+	assert.False(t, entry.Evaluate())
+	assert.False(t, exit.Evaluate())
+
+	// the fast sma crosses over the slow sma
+	composer.Update(newSignalTestKLine(5, 110))
+	assert.True(t, entry.Evaluate())
+	assert.False(t, exit.Evaluate())
+
+	// no more cross on the next kline
+	composer.Update(newSignalTestKLine(6, 115))
+	assert.False(t, entry.Evaluate())
+	assert.False(t, exit.Evaluate())
+
+	// the fast sma crosses under the slow sma
+	composer.Update(newSignalTestKLine(7, 80))
+	assert.True(t, exit.Evaluate())
+
+	composer.Update(newSignalTestKLine(8, 70))
+	assert.False(t, exit.Evaluate())
+
+	fastMA, ok := composer.Indicator("fastMA")
+	if assert.True(t, ok) {
+		assert.Equal(t, 9, fastMA.Length())
+	}
+}
+
+func TestSignalConfig_Compile(t *testing.T) {
+	composer, err := NewSignalComposer("BTCUSDT", types.Interval1h, map[string]IndicatorConfig{
+		"rsi": {Type: "rsi", Window: 14},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = composer.Compile(&SignalConfig{})
+	assert.Error(t, err)
+
+	_, err = composer.Compile(&SignalConfig{
+		Above: &SignalComparison{Indicator: "rsi", Value: 70},
+		Below: &SignalComparison{Indicator: "rsi", Value: 30},
+	})
+	assert.Error(t, err)
+
+	_, err = composer.Compile(&SignalConfig{Above: &SignalComparison{Indicator: "macd"}})
+	assert.Error(t, err)
+
+	_, err = NewSignalComposer("BTCUSDT", types.Interval1h, map[string]IndicatorConfig{
+		"a": {Type: "ewma", Window: 5, Source: "b"},
+		"b": {Type: "ewma", Window: 5, Source: "a"},
+	})
+	assert.Error(t, err)
+
+	_, err = NewSignalComposer("BTCUSDT", types.Interval1h, map[string]IndicatorConfig{
+		"a": {Type: "ewma", Window: 5, Source: "unknown"},
+	})
+	assert.Error(t, err)
+
+	_, err = NewSignalComposer("BTCUSDT", types.Interval1h, map[string]IndicatorConfig{
+		"a": {Type: "candlePattern", Output: "unknown"},
+	})
+	assert.Error(t, err)
+}
+
+func TestSignalComposer_CandlePattern(t *testing.T) {
+	composer, err := NewSignalComposer("BTCUSDT", types.Interval1h, map[string]IndicatorConfig{
+		"engulfing": {Type: "candlePattern", Output: "bullishEngulfing"},
+		"patterns":  {Type: "candlePattern"},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	entry, err := composer.Compile(&SignalConfig{Above: &SignalComparison{Indicator: "engulfing", Value: 0}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	bearish, err := composer.Compile(&SignalConfig{Below: &SignalComparison{Indicator: "patterns", Value: 0}})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	k := newSignalTestKLine(0, 100)
+	k.Open, k.High, k.Low = fixedpoint.NewFromFloat(102), fixedpoint.NewFromFloat(103), fixedpoint.NewFromFloat(99)
+	composer.Update(k)
+	assert.False(t, entry.Evaluate())
+
+	k = newSignalTestKLine(1, 103)
+	k.Open, k.High, k.Low = fixedpoint.NewFromFloat(99.5), fixedpoint.NewFromFloat(104), fixedpoint.NewFromFloat(99)
+	composer.Update(k)
+	assert.True(t, entry.Evaluate())
+	assert.False(t, bearish.Evaluate())
+}
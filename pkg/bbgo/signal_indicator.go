@@ -0,0 +1,237 @@
+package bbgo
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/indicator"
+)
+
+// IndicatorConfig declares an indicator stream that can be referenced by name in the signal config
+//
+//	indicators:
+//	  fast: { type: ewma, window: 7 }
+//	  slow: { type: ewma, window: 25 }
+//	  rsi: { type: rsi, window: 14 }
+//	  rsiEMA: { type: ewma, window: 5, source: rsi }
+//	  bollUp: { type: boll, window: 20, k: 2, output: up }
+type IndicatorConfig struct {
+	// Type is one of price, sma, ewma, rma, rsi, stddev, boll, macd, atr, atrp, candlePattern
+	Type string `json:"type"`
+
+	// Source is the input of the indicator, it can be close, open, high, low, volume or the name of another indicator.
+	// defaults to close
+	Source string `json:"source,omitempty"`
+
+	Window int `json:"window,omitempty"`
+
+	// K is the band width multiplier of boll, defaults to 2
+	K float64 `json:"k,omitempty"`
+
+	// ShortWindow, LongWindow and SignalWindow are the windows of macd, defaults to 12, 26 and 9
+	ShortWindow  int `json:"shortWindow,omitempty"`
+	LongWindow   int `json:"longWindow,omitempty"`
+	SignalWindow int `json:"signalWindow,omitempty"`
+
+	// Output selects the output series of the multi-series indicators,
+	// boll: mid, up, down or band (defaults to mid), macd: macd, signal or histogram (defaults to macd),
+	// candlePattern: the pattern name, or the net direction of the patterns when it's empty
+	Output string `json:"output,omitempty"`
+}
+
+// BuildIndicators creates the indicator streams of the configs on the given kline source,
+// the indicators can be chained by using the name of another indicator as the source.
+func BuildIndicators(kLines indicator.KLineSubscription, configs map[string]IndicatorConfig) (map[string]indicator.Float64Source, error) {
+	b := newIndicatorBuilder(kLines, configs)
+	if err := b.buildAll(); err != nil {
+		return nil, err
+	}
+
+	return b.streams, nil
+}
+
+type indicatorBuilder struct {
+	kLines   indicator.KLineSubscription
+	configs  map[string]IndicatorConfig
+	streams  map[string]indicator.Float64Source
+	building map[string]bool
+
+	prices map[string]indicator.Float64Source
+}
+
+func newIndicatorBuilder(kLines indicator.KLineSubscription, configs map[string]IndicatorConfig) *indicatorBuilder {
+	return &indicatorBuilder{
+		kLines:   kLines,
+		configs:  configs,
+		streams:  make(map[string]indicator.Float64Source, len(configs)),
+		building: make(map[string]bool),
+		prices:   make(map[string]indicator.Float64Source),
+	}
+}
+
+func (b *indicatorBuilder) buildAll() error {
+	for name := range b.configs {
+		if _, err := b.build(name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b *indicatorBuilder) source(name string) (indicator.Float64Source, error) {
+	if name == "" {
+		name = "close"
+	}
+
+	if _, ok := b.configs[name]; ok {
+		return b.build(name)
+	}
+
+	// the price streams are shared by the indicators
+	if s, ok := b.prices[name]; ok {
+		return s, nil
+	}
+
+	var s indicator.Float64Source
+	switch name {
+	case "close":
+		s = indicator.ClosePrices(b.kLines)
+	case "open":
+		s = indicator.OpenPrices(b.kLines)
+	case "high":
+		s = indicator.HighPrices(b.kLines)
+	case "low":
+		s = indicator.LowPrices(b.kLines)
+	case "volume":
+		s = indicator.Price(b.kLines, indicator.KLineVolumeMapper)
+	default:
+		return nil, fmt.Errorf("source %q is neither a price nor a declared indicator", name)
+	}
+
+	b.prices[name] = s
+	return s, nil
+}
+
+func (b *indicatorBuilder) build(name string) (indicator.Float64Source, error) {
+	if s, ok := b.streams[name]; ok {
+		return s, nil
+	}
+
+	if b.building[name] {
+		return nil, fmt.Errorf("indicator %q has a circular source reference", name)
+	}
+
+	b.building[name] = true
+	defer delete(b.building, name)
+
+	config := b.configs[name]
+	needsWindow := config.Type != "price" && config.Type != "macd" && config.Type != "candlePattern"
+	if needsWindow && config.Window <= 0 {
+		return nil, fmt.Errorf("indicator %q: window should be greater than zero", name)
+	}
+
+	var s indicator.Float64Source
+	switch config.Type {
+	case "atr":
+		s = indicator.ATR2(b.kLines, config.Window)
+
+	case "atrp":
+		s = indicator.ATRP2(b.kLines, config.Window)
+
+	case "candlePattern":
+		patterns := indicator.CandlePatterns(b.kLines)
+		if config.Output == "" {
+			s = patterns
+			break
+		}
+
+		pattern := indicator.CandlePattern(config.Output)
+		if !pattern.IsValid() {
+			return nil, fmt.Errorf("indicator %q: invalid candle pattern %q", name, config.Output)
+		}
+
+		s = patterns.Detected(pattern)
+
+	default:
+		source, err := b.source(config.Source)
+		if err != nil {
+			return nil, fmt.Errorf("indicator %q: %w", name, err)
+		}
+
+		s, err = config.newStream(source)
+		if err != nil {
+			return nil, fmt.Errorf("indicator %q: %w", name, err)
+		}
+	}
+
+	b.streams[name] = s
+	return s, nil
+}
+
+func (c IndicatorConfig) newStream(source indicator.Float64Source) (indicator.Float64Source, error) {
+	switch c.Type {
+	case "price":
+		return source, nil
+
+	case "sma":
+		return indicator.SMA2(source, c.Window), nil
+
+	case "ewma", "ema":
+		return indicator.EWMA2(source, c.Window), nil
+
+	case "rma":
+		return indicator.RMA2(source, c.Window, true), nil
+
+	case "rsi":
+		return indicator.RSI2(source, c.Window), nil
+
+	case "stddev":
+		return indicator.StdDev2(source, c.Window), nil
+
+	case "boll":
+		k := c.K
+		if k == 0 {
+			k = 2.0
+		}
+
+		boll := indicator.BOLL2(source, c.Window, k)
+		switch c.Output {
+		case "", "mid":
+			return boll.SMA, nil
+		case "up":
+			return boll.UpBand, nil
+		case "down":
+			return boll.DownBand, nil
+		case "band":
+			return boll, nil
+		}
+
+		return nil, fmt.Errorf("invalid boll output %q, valid outputs: mid, up, down, band", c.Output)
+
+	case "macd":
+		shortWindow, longWindow, signalWindow := c.ShortWindow, c.LongWindow, c.SignalWindow
+		if shortWindow == 0 {
+			shortWindow = 12
+		}
+		if longWindow == 0 {
+			longWindow = 26
+		}
+		if signalWindow == 0 {
+			signalWindow = 9
+		}
+
+		macd := indicator.MACD2(source, shortWindow, longWindow, signalWindow)
+		switch c.Output {
+		case "", "macd":
+			return macd, nil
+		case "signal":
+			return macd.Signal, nil
+		case "histogram":
+			return macd.Histogram, nil
+		}
+
+		return nil, fmt.Errorf("invalid macd output %q, valid outputs: macd, signal, histogram", c.Output)
+	}
+
+	return nil, fmt.Errorf("unsupported indicator type %q", c.Type)
+}
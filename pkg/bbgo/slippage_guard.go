@@ -0,0 +1,132 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrSlippageExceeded = errors.New("order book is not deep enough within the max slippage")
+
+// SlippageGuardOrderOptions is the options of the market order placed by MarketOrderWithSlippageGuard
+type SlippageGuardOrderOptions struct {
+	Side     types.SideType
+	Quantity fixedpoint.Value
+
+	// MaxSlippage is the max price deviation ratio from the best price, e.g., 0.5%
+	MaxSlippage fixedpoint.Value
+
+	// FillOrKill submits a FOK order instead of an IOC order,
+	// the order is not submitted if the order book can not fill the whole quantity within the max slippage.
+	FillOrKill bool
+
+	ReduceOnly bool
+	Tags       []string
+}
+
+// MarketOrderWithSlippageGuard takes the liquidity like a market order, but submits an IOC (or FOK) limit order
+// at the worst acceptable price, which is computed by walking the order book of the session.
+// If the order book is not deep enough within the max slippage, the limit price is capped at the max slippage,
+// and the IOC order is partially filled.
+func (e *GeneralOrderExecutor) MarketOrderWithSlippageGuard(ctx context.Context, options SlippageGuardOrderOptions) (types.OrderSlice, error) {
+	book, ok := e.session.OrderBook(e.symbol)
+	if !ok {
+		return nil, fmt.Errorf("order book of %s is not available, please subscribe the book channel", e.symbol)
+	}
+
+	// the buy order takes the asks, and the sell order takes the bids
+	var sideBook types.PriceVolumeSlice
+	switch options.Side {
+	case types.SideTypeBuy:
+		sideBook = book.Copy().SideBook(types.SideTypeSell)
+	case types.SideTypeSell:
+		sideBook = book.Copy().SideBook(types.SideTypeBuy)
+	default:
+		return nil, fmt.Errorf("unexpected side type: %s", options.Side)
+	}
+
+	market, _ := e.session.Market(e.symbol)
+	price, fillable, err := slippageGuardPrice(sideBook, options.Side, options.Quantity, options.MaxSlippage, market.TickSize)
+	if err != nil {
+		return nil, err
+	}
+
+	timeInForce := types.TimeInForceIOC
+	if options.FillOrKill {
+		if fillable.Compare(options.Quantity) < 0 {
+			return nil, fmt.Errorf("%w: %s %s fillable %s of %s",
+				ErrSlippageExceeded, e.symbol, options.Side, fillable.String(), options.Quantity.String())
+		}
+
+		timeInForce = types.TimeInForceFOK
+	}
+
+	if fillable.Compare(options.Quantity) < 0 {
+		log.Warnf("%s %s order book depth within the max slippage %s is %s, less than the quantity %s",
+			e.symbol, options.Side, options.MaxSlippage.Percentage(), fillable.String(), options.Quantity.String())
+	}
+
+	return e.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:      e.symbol,
+		Side:        options.Side,
+		Type:        types.OrderTypeLimit,
+		Price:       price,
+		Quantity:    options.Quantity,
+		Market:      market,
+		TimeInForce: timeInForce,
+		ReduceOnly:  options.ReduceOnly,
+		Tag:         strings.Join(options.Tags, ","),
+	})
+}
+
+// slippageGuardPrice walks the side book taken by the order, and returns the worst price to fill the quantity,
+// the price is capped at the max slippage from the best price, and the fillable quantity within the price.
+// The capped price is rounded to the tick size towards the best price, so the order never exceeds the max slippage.
+func slippageGuardPrice(sideBook types.PriceVolumeSlice, side types.SideType, quantity, maxSlippage, tickSize fixedpoint.Value) (price, fillable fixedpoint.Value, err error) {
+	best, ok := sideBook.First()
+	if !ok {
+		return fixedpoint.Zero, fixedpoint.Zero, fmt.Errorf("%w: the order book is empty", ErrSlippageExceeded)
+	}
+
+	limitPrice := best.Price.Mul(fixedpoint.One.Add(maxSlippage))
+	if side == types.SideTypeSell {
+		limitPrice = best.Price.Mul(fixedpoint.One.Sub(maxSlippage))
+	}
+
+	if tickSize.Sign() > 0 {
+		if side == types.SideTypeSell {
+			limitPrice = limitPrice.Div(tickSize).Round(0, fixedpoint.Up).Mul(tickSize)
+		} else {
+			limitPrice = limitPrice.Div(tickSize).Round(0, fixedpoint.Down).Mul(tickSize)
+		}
+	}
+
+	price = best.Price
+	for _, pv := range sideBook {
+		if fillable.Compare(quantity) >= 0 {
+			break
+		}
+
+		if side == types.SideTypeBuy && pv.Price.Compare(limitPrice) > 0 ||
+			side == types.SideTypeSell && pv.Price.Compare(limitPrice) < 0 {
+			// the rest quantity can only be filled beyond the max slippage
+			return limitPrice, fillable, nil
+		}
+
+		price = pv.Price
+		fillable = fillable.Add(pv.Volume)
+	}
+
+	if fillable.Compare(quantity) < 0 {
+		// the book is exhausted, the quantity beyond the book can still be filled within the limit price
+		return limitPrice, fillable, nil
+	}
+
+	return price, fixedpoint.Min(fillable, quantity), nil
+}
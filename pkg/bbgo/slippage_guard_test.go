@@ -0,0 +1,74 @@
+package bbgo
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_slippageGuardPrice(t *testing.T) {
+	number := fixedpoint.MustNewFromString
+	asks := types.PriceVolumeSlice{
+		{Price: number("100"), Volume: number("1")},
+		{Price: number("100.5"), Volume: number("1")},
+		{Price: number("102"), Volume: number("5")},
+	}
+
+	bids := types.PriceVolumeSlice{
+		{Price: number("99"), Volume: number("2")},
+		{Price: number("98"), Volume: number("2")},
+	}
+
+	t.Run("filled within the book", func(t *testing.T) {
+		price, fillable, err := slippageGuardPrice(asks, types.SideTypeBuy, number("1.5"), number("0.01"), number("0.01"))
+		if assert.NoError(t, err) {
+			assert.Equal(t, "100.5", price.String())
+			assert.Equal(t, "1.5", fillable.String())
+		}
+	})
+
+	t.Run("capped at the max slippage", func(t *testing.T) {
+		price, fillable, err := slippageGuardPrice(asks, types.SideTypeBuy, number("3"), number("0.01"), number("0.01"))
+		if assert.NoError(t, err) {
+			assert.Equal(t, "101", price.String())
+			assert.Equal(t, "2", fillable.String())
+		}
+	})
+
+	t.Run("sell side", func(t *testing.T) {
+		price, fillable, err := slippageGuardPrice(bids, types.SideTypeSell, number("3"), number("0.02"), number("0.01"))
+		if assert.NoError(t, err) {
+			assert.Equal(t, "98", price.String())
+			assert.Equal(t, "3", fillable.String())
+		}
+	})
+
+	t.Run("book exhausted", func(t *testing.T) {
+		price, fillable, err := slippageGuardPrice(bids, types.SideTypeSell, number("5"), number("0.05"), number("0.01"))
+		if assert.NoError(t, err) {
+			assert.Equal(t, "94.05", price.String())
+			assert.Equal(t, "4", fillable.String())
+		}
+	})
+
+	t.Run("rounded to the tick size", func(t *testing.T) {
+		price, _, err := slippageGuardPrice(asks, types.SideTypeBuy, number("3"), number("0.013"), number("0.5"))
+		if assert.NoError(t, err) {
+			assert.Equal(t, "101", price.String(), "101.3 is rounded down for the buy order")
+		}
+
+		price, _, err = slippageGuardPrice(bids, types.SideTypeSell, number("5"), number("0.05"), number("0.1"))
+		if assert.NoError(t, err) {
+			assert.Equal(t, "94.1", price.String(), "94.05 is rounded up for the sell order")
+		}
+	})
+
+	t.Run("empty book", func(t *testing.T) {
+		_, _, err := slippageGuardPrice(nil, types.SideTypeSell, number("1"), number("0.05"), number("0.01"))
+		assert.True(t, errors.Is(err, ErrSlippageExceeded))
+	})
+}
@@ -0,0 +1,438 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var sorLogger = logrus.WithField("component", "sor")
+
+// SmartOrderRouter splits a taker order across the sessions listing the symbol,
+// by the displayed depth of the session order books and the taker fee rates of the sessions.
+//
+// The order books of the symbol must be subscribed in the sessions.
+type SmartOrderRouter struct {
+	Sessions []*ExchangeSession
+}
+
+func NewSmartOrderRouter(sessions ...*ExchangeSession) *SmartOrderRouter {
+	return &SmartOrderRouter{Sessions: sessions}
+}
+
+// RouteLeg is the part of the order routed to one session
+type RouteLeg struct {
+	Session  *ExchangeSession `json:"-"`
+	Venue    string           `json:"venue"`
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// Price is the expected average price of the leg before the fee
+	Price fixedpoint.Value `json:"price"`
+
+	// LimitPrice is the worst price level the leg consumes, the leg is submitted as an IOC limit order at this price
+	LimitPrice fixedpoint.Value `json:"limitPrice"`
+
+	FeeRate fixedpoint.Value `json:"feeRate"`
+}
+
+// RoutePlan is the split of the order with the expected prices, the prices include the taker fee
+type RoutePlan struct {
+	Symbol   string           `json:"symbol"`
+	Side     types.SideType   `json:"side"`
+	Quantity fixedpoint.Value `json:"quantity"`
+	Legs     []RouteLeg       `json:"legs"`
+
+	// ExpectedPrice is the expected blended price of the legs, including the fee
+	ExpectedPrice fixedpoint.Value `json:"expectedPrice"`
+
+	// SingleVenue is the best venue that can fill the whole quantity alone, it's empty if no venue has enough depth
+	SingleVenue      string           `json:"singleVenue,omitempty"`
+	SingleVenuePrice fixedpoint.Value `json:"singleVenuePrice"`
+}
+
+// Improvement returns the price improvement in bps of the routed order against the single venue alternative,
+// a positive value means the routed order is better.
+func (p *RoutePlan) Improvement(price fixedpoint.Value) fixedpoint.Value {
+	if p.SingleVenuePrice.IsZero() || price.IsZero() {
+		return fixedpoint.Zero
+	}
+
+	diff := p.SingleVenuePrice.Sub(price)
+	if p.Side == types.SideTypeSell {
+		diff = diff.Neg()
+	}
+
+	return diff.Div(p.SingleVenuePrice).Mul(fixedpoint.NewFromInt(10000))
+}
+
+// RouteLegResult is the execution of a leg
+type RouteLegResult struct {
+	RouteLeg
+	Order            *types.Order     `json:"order,omitempty"`
+	ExecutedQuantity fixedpoint.Value `json:"executedQuantity"`
+	AveragePrice     fixedpoint.Value `json:"averagePrice"`
+	Fee              fixedpoint.Value `json:"fee"`
+	Error            error            `json:"-"`
+}
+
+// RouteReport compares the blended execution price with the single venue alternative
+type RouteReport struct {
+	Plan    *RoutePlan       `json:"plan"`
+	Results []RouteLegResult `json:"results"`
+
+	ExecutedQuantity fixedpoint.Value `json:"executedQuantity"`
+
+	// BlendedPrice is the average executed price of all the legs, including the fee
+	BlendedPrice fixedpoint.Value `json:"blendedPrice"`
+
+	// ImprovementBps is the improvement of the blended price against the expected single venue price
+	ImprovementBps fixedpoint.Value `json:"improvementBps"`
+}
+
+func (r *RouteReport) String() string {
+	s := fmt.Sprintf("SOR %s %s %s: executed %s at blended price %s over %d legs",
+		r.Plan.Symbol, r.Plan.Side, r.Plan.Quantity.String(), r.ExecutedQuantity.String(), r.BlendedPrice.String(), len(r.Results))
+	if r.Plan.SingleVenue != "" {
+		s += fmt.Sprintf(", single venue %s price %s, improvement %s bps",
+			r.Plan.SingleVenue, r.Plan.SingleVenuePrice.String(), r.ImprovementBps.Round(2, fixedpoint.HalfUp).String())
+	}
+
+	return s
+}
+
+// Errors returns the errors of the failed legs
+func (r *RouteReport) Errors() (errs []error) {
+	for _, result := range r.Results {
+		if result.Error != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", result.Venue, result.Error))
+		}
+	}
+
+	return errs
+}
+
+type routeVenue struct {
+	session *ExchangeSession
+	name    string
+	market  types.Market
+	feeRate fixedpoint.Value
+
+	// book is the side book the taker order consumes, ordered from the best price
+	book types.PriceVolumeSlice
+}
+
+// Plan splits the order by the current order books of the sessions
+func (r *SmartOrderRouter) Plan(symbol string, side types.SideType, quantity fixedpoint.Value) (*RoutePlan, error) {
+	var venues []routeVenue
+	for _, session := range r.Sessions {
+		market, ok := session.Market(symbol)
+		if !ok {
+			continue
+		}
+
+		book, ok := session.OrderBook(symbol)
+		if !ok {
+			sorLogger.Warnf("%s order book is not subscribed in session %s, skipping", symbol, session.Name)
+			continue
+		}
+
+		venues = append(venues, routeVenue{
+			session: session,
+			name:    session.Name,
+			market:  market,
+			feeRate: session.TakerFeeRate,
+			book:    book.Copy().SideBook(side.Reverse()),
+		})
+	}
+
+	if len(venues) == 0 {
+		return nil, fmt.Errorf("no session has the %s order book", symbol)
+	}
+
+	return planRoute(symbol, side, quantity, venues)
+}
+
+// Execute submits the legs of the plan concurrently as IOC limit orders, and reports the blended execution price
+func (r *SmartOrderRouter) Execute(ctx context.Context, symbol string, side types.SideType, quantity fixedpoint.Value) (*RouteReport, error) {
+	plan, err := r.Plan(symbol, side, quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]RouteLegResult, len(plan.Legs))
+
+	var wg sync.WaitGroup
+	for i, leg := range plan.Legs {
+		wg.Add(1)
+		go func(i int, leg RouteLeg) {
+			defer wg.Done()
+			results[i] = executeRouteLeg(ctx, symbol, side, leg)
+		}(i, leg)
+	}
+	wg.Wait()
+
+	report := &RouteReport{
+		Plan:    plan,
+		Results: results,
+	}
+
+	var amount fixedpoint.Value
+	for _, result := range results {
+		report.ExecutedQuantity = report.ExecutedQuantity.Add(result.ExecutedQuantity)
+		legAmount := result.AveragePrice.Mul(result.ExecutedQuantity)
+		if side == types.SideTypeBuy {
+			amount = amount.Add(legAmount).Add(result.Fee)
+		} else {
+			amount = amount.Add(legAmount).Sub(result.Fee)
+		}
+	}
+
+	if report.ExecutedQuantity.Sign() > 0 {
+		report.BlendedPrice = amount.Div(report.ExecutedQuantity)
+		report.ImprovementBps = plan.Improvement(report.BlendedPrice)
+	}
+
+	sorLogger.Info(report.String())
+
+	if errs := report.Errors(); len(errs) > 0 {
+		return report, multierr.Combine(errs...)
+	}
+
+	return report, nil
+}
+
+func executeRouteLeg(ctx context.Context, symbol string, side types.SideType, leg RouteLeg) (result RouteLegResult) {
+	result.RouteLeg = leg
+
+	market, _ := leg.Session.Market(symbol)
+	createdOrder, err := leg.Session.Exchange.SubmitOrder(ctx, types.SubmitOrder{
+		Symbol:      symbol,
+		Market:      market,
+		Side:        side,
+		Type:        types.OrderTypeLimit,
+		Quantity:    leg.Quantity,
+		Price:       leg.LimitPrice,
+		TimeInForce: types.TimeInForceIOC,
+	})
+	if err != nil {
+		result.Error = err
+		return result
+	}
+
+	result.Order = createdOrder
+
+	// the fee is estimated by the fee rate unless the trades of the order can be queried
+	result.ExecutedQuantity = createdOrder.ExecutedQuantity
+	result.AveragePrice = leg.Price
+	result.Fee = leg.Price.Mul(result.ExecutedQuantity).Mul(leg.FeeRate)
+
+	service, ok := leg.Session.Exchange.(types.ExchangeOrderQueryService)
+	if !ok {
+		return result
+	}
+
+	trades, err := service.QueryOrderTrades(ctx, types.OrderQuery{
+		Symbol:  symbol,
+		OrderID: strconv.FormatUint(createdOrder.OrderID, 10),
+	})
+	if err != nil {
+		sorLogger.WithError(err).Warnf("unable to query the trades of order %d on %s, using the expected price", createdOrder.OrderID, leg.Venue)
+		return result
+	}
+
+	if len(trades) == 0 {
+		return result
+	}
+
+	var quantity, amount, fee fixedpoint.Value
+	for _, trade := range trades {
+		quantity = quantity.Add(trade.Quantity)
+		amount = amount.Add(trade.Price.Mul(trade.Quantity))
+		if trade.FeeCurrency == market.QuoteCurrency {
+			fee = fee.Add(trade.Fee)
+		} else {
+			fee = fee.Add(trade.Price.Mul(trade.Quantity).Mul(leg.FeeRate))
+		}
+	}
+
+	result.ExecutedQuantity = quantity
+	result.AveragePrice = amount.Div(quantity)
+	result.Fee = fee
+	return result
+}
+
+type routeLevel struct {
+	venue          int
+	price          fixedpoint.Value
+	effectivePrice fixedpoint.Value
+	volume         fixedpoint.Value
+}
+
+// planRoute consumes the price levels of all the venues from the best effective price (the price including the fee),
+// until the quantity is filled.
+func planRoute(symbol string, side types.SideType, quantity fixedpoint.Value, venues []routeVenue) (*RoutePlan, error) {
+	var levels []routeLevel
+	for i, venue := range venues {
+		for _, pv := range venue.book {
+			levels = append(levels, routeLevel{
+				venue:          i,
+				price:          pv.Price,
+				effectivePrice: effectiveRoutePrice(side, pv.Price, venue.feeRate),
+				volume:         pv.Volume,
+			})
+		}
+	}
+
+	sort.SliceStable(levels, func(i, j int) bool {
+		if side == types.SideTypeBuy {
+			return levels[i].effectivePrice.Compare(levels[j].effectivePrice) < 0
+		}
+
+		return levels[i].effectivePrice.Compare(levels[j].effectivePrice) > 0
+	})
+
+	legQuantities := make([]fixedpoint.Value, len(venues))
+	limitPrices := make([]fixedpoint.Value, len(venues))
+	remaining := quantity
+	for _, level := range levels {
+		if remaining.Sign() <= 0 {
+			break
+		}
+
+		q := fixedpoint.Min(level.volume, remaining)
+		legQuantities[level.venue] = legQuantities[level.venue].Add(q)
+		limitPrices[level.venue] = level.price
+		remaining = remaining.Sub(q)
+	}
+
+	if remaining.Sign() > 0 {
+		return nil, fmt.Errorf("insufficient %s depth to %s %s, missing %s", symbol, side, quantity.String(), remaining.String())
+	}
+
+	plan := &RoutePlan{
+		Symbol:   symbol,
+		Side:     side,
+		Quantity: quantity,
+	}
+
+	// truncate the leg quantities by the step sizes, and move the dust legs to the largest leg
+	var dust fixedpoint.Value
+	var largest = -1
+	for i, venue := range venues {
+		q := venue.market.TruncateQuantity(legQuantities[i])
+		dust = dust.Add(legQuantities[i].Sub(q))
+		if q.Sign() > 0 && venue.market.IsDustQuantity(q, limitPrices[i]) {
+			dust = dust.Add(q)
+			q = fixedpoint.Zero
+		}
+
+		legQuantities[i] = q
+		if q.Sign() > 0 && (largest < 0 || q.Compare(legQuantities[largest]) > 0) {
+			largest = i
+		}
+	}
+
+	if largest < 0 {
+		return nil, fmt.Errorf("%s quantity %s is too small to route", symbol, quantity.String())
+	}
+
+	if dust.Sign() > 0 {
+		legQuantities[largest] = venues[largest].market.TruncateQuantity(legQuantities[largest].Add(dust))
+		limitPrices[largest] = sweepPrice(venues[largest].book, legQuantities[largest], limitPrices[largest])
+	}
+
+	var totalQuantity, totalAmount fixedpoint.Value
+	for i, venue := range venues {
+		if legQuantities[i].IsZero() {
+			continue
+		}
+
+		price, ok := averageFillPrice(venue.book, legQuantities[i])
+		if !ok {
+			// the dust moved to the leg exceeds the displayed depth of the venue
+			price = limitPrices[i]
+		}
+
+		plan.Legs = append(plan.Legs, RouteLeg{
+			Session:    venue.session,
+			Venue:      venue.name,
+			Quantity:   legQuantities[i],
+			Price:      price,
+			LimitPrice: limitPrices[i],
+			FeeRate:    venue.feeRate,
+		})
+
+		totalQuantity = totalQuantity.Add(legQuantities[i])
+		totalAmount = totalAmount.Add(effectiveRoutePrice(side, price, venue.feeRate).Mul(legQuantities[i]))
+	}
+
+	plan.ExpectedPrice = totalAmount.Div(totalQuantity)
+
+	for _, venue := range venues {
+		price, ok := averageFillPrice(venue.book, quantity)
+		if !ok {
+			continue
+		}
+
+		price = effectiveRoutePrice(side, price, venue.feeRate)
+		if plan.SingleVenue == "" ||
+			(side == types.SideTypeBuy && price.Compare(plan.SingleVenuePrice) < 0) ||
+			(side == types.SideTypeSell && price.Compare(plan.SingleVenuePrice) > 0) {
+			plan.SingleVenue = venue.name
+			plan.SingleVenuePrice = price
+		}
+	}
+
+	return plan, nil
+}
+
+// effectiveRoutePrice returns the price including the taker fee
+func effectiveRoutePrice(side types.SideType, price, feeRate fixedpoint.Value) fixedpoint.Value {
+	if side == types.SideTypeBuy {
+		return price.Mul(fixedpoint.One.Add(feeRate))
+	}
+
+	return price.Mul(fixedpoint.One.Sub(feeRate))
+}
+
+// averageFillPrice returns the average price to fill the quantity from the best price of the side book
+func averageFillPrice(book types.PriceVolumeSlice, quantity fixedpoint.Value) (fixedpoint.Value, bool) {
+	var amount fixedpoint.Value
+	remaining := quantity
+	for _, pv := range book {
+		if remaining.Sign() <= 0 {
+			break
+		}
+
+		q := fixedpoint.Min(pv.Volume, remaining)
+		amount = amount.Add(pv.Price.Mul(q))
+		remaining = remaining.Sub(q)
+	}
+
+	if remaining.Sign() > 0 || quantity.IsZero() {
+		return fixedpoint.Zero, false
+	}
+
+	return amount.Div(quantity), true
+}
+
+// sweepPrice returns the worst price level to fill the quantity, or the fallback price if the depth is not enough
+func sweepPrice(book types.PriceVolumeSlice, quantity, fallback fixedpoint.Value) fixedpoint.Value {
+	remaining := quantity
+	for _, pv := range book {
+		remaining = remaining.Sub(pv.Volume)
+		if remaining.Sign() <= 0 {
+			return pv.Price
+		}
+	}
+
+	return fallback
+}
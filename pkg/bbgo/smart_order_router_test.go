@@ -0,0 +1,98 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_planRoute(t *testing.T) {
+	market := types.Market{
+		Symbol:          "BTCUSDT",
+		StepSize:        fixedpoint.NewFromFloat(0.0001),
+		MinQuantity:     fixedpoint.NewFromFloat(0.0001),
+		MinNotional:     fixedpoint.NewFromFloat(10.0),
+		VolumePrecision: 4,
+	}
+
+	venues := []routeVenue{
+		{
+			name:    "binance",
+			market:  market,
+			feeRate: fixedpoint.NewFromFloat(0.001),
+			book: types.PriceVolumeSlice{
+				{Price: fixedpoint.NewFromFloat(100.0), Volume: fixedpoint.NewFromFloat(1.0)},
+				{Price: fixedpoint.NewFromFloat(101.0), Volume: fixedpoint.NewFromFloat(5.0)},
+			},
+		},
+		{
+			name:    "max",
+			market:  market,
+			feeRate: fixedpoint.NewFromFloat(0.0),
+			book: types.PriceVolumeSlice{
+				{Price: fixedpoint.NewFromFloat(100.05), Volume: fixedpoint.NewFromFloat(1.0)},
+				{Price: fixedpoint.NewFromFloat(102.0), Volume: fixedpoint.NewFromFloat(5.0)},
+			},
+		},
+	}
+
+	t.Run("buy across venues", func(t *testing.T) {
+		plan, err := planRoute("BTCUSDT", types.SideTypeBuy, fixedpoint.NewFromFloat(2.5), venues)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		// max 100.05 (no fee) < binance 100.1 (100 with fee) < binance 101.101
+		if assert.Len(t, plan.Legs, 2) {
+			assert.Equal(t, "binance", plan.Legs[0].Venue)
+			assert.Equal(t, "1.5", plan.Legs[0].Quantity.String())
+			assert.Equal(t, "101", plan.Legs[0].LimitPrice.String())
+
+			assert.Equal(t, "max", plan.Legs[1].Venue)
+			assert.Equal(t, "1", plan.Legs[1].Quantity.String())
+			assert.Equal(t, "100.05", plan.Legs[1].LimitPrice.String())
+		}
+
+		// binance alone: (100 + 101 * 1.5) / 2.5 * 1.001
+		assert.Equal(t, "binance", plan.SingleVenue)
+		assert.InDelta(t, 100.7006, plan.SingleVenuePrice.Float64(), 1e-4)
+		assert.True(t, plan.ExpectedPrice.Compare(plan.SingleVenuePrice) < 0)
+		assert.True(t, plan.Improvement(plan.ExpectedPrice).Sign() > 0)
+	})
+
+	t.Run("dust leg", func(t *testing.T) {
+		plan, err := planRoute("BTCUSDT", types.SideTypeBuy, fixedpoint.NewFromFloat(1.05), venues)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		// the 0.05 binance leg is below the min notional, it's moved to the max leg
+		if assert.Len(t, plan.Legs, 1) {
+			assert.Equal(t, "max", plan.Legs[0].Venue)
+			assert.Equal(t, "1.05", plan.Legs[0].Quantity.String())
+			assert.Equal(t, "102", plan.Legs[0].LimitPrice.String())
+		}
+	})
+
+	t.Run("insufficient depth", func(t *testing.T) {
+		_, err := planRoute("BTCUSDT", types.SideTypeBuy, fixedpoint.NewFromFloat(20.0), venues)
+		assert.Error(t, err)
+	})
+
+	t.Run("sell", func(t *testing.T) {
+		bids := []routeVenue{
+			{name: "binance", market: market, feeRate: fixedpoint.NewFromFloat(0.001), book: types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(100.0), Volume: fixedpoint.NewFromFloat(1.0)}}},
+			{name: "max", market: market, feeRate: fixedpoint.Zero, book: types.PriceVolumeSlice{{Price: fixedpoint.NewFromFloat(99.95), Volume: fixedpoint.NewFromFloat(1.0)}}},
+		}
+
+		plan, err := planRoute("BTCUSDT", types.SideTypeSell, fixedpoint.NewFromFloat(0.5), bids)
+		if assert.NoError(t, err) && assert.Len(t, plan.Legs, 1) {
+			// max 99.95 > binance 99.9 with fee
+			assert.Equal(t, "max", plan.Legs[0].Venue)
+			assert.Equal(t, "max", plan.SingleVenue)
+		}
+	})
+}
@@ -0,0 +1,497 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/dynamic"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const (
+	defaultPanicRecoveryMaxRestarts = 3
+	defaultPanicRecoveryBackoff     = 10 * time.Second
+	defaultPanicRecoveryMaxBackoff  = 5 * time.Minute
+)
+
+// PanicRecoveryConfig isolates the panics of the strategy instances, the panic of the stream callbacks is recovered,
+// the instance is marked as failed, its open orders are canceled and it's optionally restarted with the backoff.
+//
+// The callbacks registered on the streams of the session given to the strategy are guarded,
+// the callbacks registered on the indicators or the shared components are not guarded.
+type PanicRecoveryConfig struct {
+	// Restart restarts the failed strategy instance by calling its Run method again
+	Restart bool `json:"restart,omitempty" yaml:"restart,omitempty"`
+
+	// MaxRestarts is the max number of the restarts of an instance, defaults to 3
+	MaxRestarts int `json:"maxRestarts,omitempty" yaml:"maxRestarts,omitempty"`
+
+	// Backoff is the delay of the first restart, the delay is doubled after each restart, defaults to 10 seconds
+	Backoff types.Duration `json:"backoff,omitempty" yaml:"backoff,omitempty"`
+
+	// MaxBackoff is the max delay of the restarts, defaults to 5 minutes
+	MaxBackoff types.Duration `json:"maxBackoff,omitempty" yaml:"maxBackoff,omitempty"`
+}
+
+func (c *PanicRecoveryConfig) Validate() error {
+	if c.MaxRestarts < 0 {
+		return fmt.Errorf("panicRecovery.maxRestarts can not be negative")
+	}
+
+	if c.Backoff < 0 || c.MaxBackoff < 0 {
+		return fmt.Errorf("panicRecovery backoff can not be negative")
+	}
+
+	return nil
+}
+
+func (c *PanicRecoveryConfig) maxRestarts() int {
+	if c.MaxRestarts > 0 {
+		return c.MaxRestarts
+	}
+
+	return defaultPanicRecoveryMaxRestarts
+}
+
+// backoff returns the delay of the restart, restarts is the number of the previous restarts
+func (c *PanicRecoveryConfig) backoff(restarts int) time.Duration {
+	delay := defaultPanicRecoveryBackoff
+	if c.Backoff > 0 {
+		delay = c.Backoff.Duration()
+	}
+
+	maxDelay := defaultPanicRecoveryMaxBackoff
+	if c.MaxBackoff > 0 {
+		maxDelay = c.MaxBackoff.Duration()
+	}
+
+	for i := 0; i < restarts && delay < maxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	return delay
+}
+
+// StrategyGuard recovers the panics of a strategy instance.
+// Each run of the instance is a generation, the guarded callbacks of the failed generation are no longer called,
+// so the callbacks registered again by the restarted run are not duplicated.
+type StrategyGuard struct {
+	instanceID string
+	strategy   StrategyID
+	config     *PanicRecoveryConfig
+
+	// sessions are used for canceling the open orders of the failed instance
+	sessions map[string]*ExchangeSession
+
+	ctx context.Context
+
+	mu             sync.Mutex
+	generation     int
+	failed         bool
+	restarts       int
+	restartPending bool
+	runFunc        func() error
+}
+
+func NewStrategyGuard(ctx context.Context, instanceID string, strategy StrategyID, sessions map[string]*ExchangeSession, config *PanicRecoveryConfig) *StrategyGuard {
+	return &StrategyGuard{
+		instanceID: instanceID,
+		strategy:   strategy,
+		config:     config,
+		sessions:   sessions,
+		ctx:        ctx,
+	}
+}
+
+// Failed returns true if the current run of the instance panicked
+func (g *StrategyGuard) Failed() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.failed
+}
+
+// Restarts returns the number of the restarts
+func (g *StrategyGuard) Restarts() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.restarts
+}
+
+func (g *StrategyGuard) currentGeneration() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.generation
+}
+
+// Run calls the run function with the panic recovery, the function is stored for the restarts.
+// The panic of the run function is not returned as an error, the instance is marked as failed instead.
+func (g *StrategyGuard) Run(run func() error) (err error) {
+	g.mu.Lock()
+	g.runFunc = run
+	generation := g.generation
+	g.mu.Unlock()
+
+	g.call(generation, func() {
+		err = run()
+	})
+	return err
+}
+
+// call calls the function if the generation is still running, the panic of the function is recovered.
+// The pending restart is executed by the call, so the restarted run registers its callbacks in the stream goroutine.
+func (g *StrategyGuard) call(generation int, f func()) {
+	g.mu.Lock()
+	restart := g.restartPending
+	g.restartPending = false
+	g.mu.Unlock()
+
+	if restart {
+		g.restart()
+	}
+
+	g.mu.Lock()
+	skip := g.failed || generation != g.generation
+	g.mu.Unlock()
+
+	if skip {
+		return
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			g.fail(generation, r, debug.Stack())
+		}
+	}()
+
+	f()
+}
+
+func (g *StrategyGuard) fail(generation int, r interface{}, stack []byte) {
+	g.mu.Lock()
+	if g.failed || generation != g.generation {
+		g.mu.Unlock()
+		return
+	}
+
+	g.failed = true
+	g.mu.Unlock()
+
+	log.Errorf("strategy instance %s panic: %v\n%s", g.instanceID, r, stack)
+
+	// the recovery is executed in the background, so the stream goroutine is not blocked by the order cancellation
+	go g.recoverInstance(r)
+}
+
+func (g *StrategyGuard) recoverInstance(r interface{}) {
+	Notify("Strategy instance %s panic: %v, the instance is marked as failed", g.instanceID, r)
+
+	if err := g.cancelOrders(g.ctx); err != nil {
+		log.WithError(err).Errorf("failed to cancel the orders of the failed strategy instance %s", g.instanceID)
+		Notify("Failed to cancel the orders of the failed strategy instance %s: %v", g.instanceID, err)
+	}
+
+	if !g.config.Restart {
+		return
+	}
+
+	g.mu.Lock()
+	restarts := g.restarts
+	run := g.runFunc
+	g.mu.Unlock()
+
+	if restarts >= g.config.maxRestarts() {
+		Notify("Strategy instance %s reached the max restarts %d, it will not be restarted", g.instanceID, g.config.maxRestarts())
+		return
+	}
+
+	if run == nil {
+		return
+	}
+
+	delay := g.config.backoff(restarts)
+	log.Infof("restarting strategy instance %s in %s", g.instanceID, delay)
+
+	select {
+	case <-g.ctx.Done():
+		return
+	case <-time.After(delay):
+	}
+
+	// the instance is restarted by the next guarded callback
+	g.mu.Lock()
+	g.restartPending = true
+	g.mu.Unlock()
+}
+
+func (g *StrategyGuard) restart() {
+	g.mu.Lock()
+	g.generation++
+	g.failed = false
+	g.restarts++
+	restarts := g.restarts
+	run := g.runFunc
+	g.mu.Unlock()
+
+	Notify("Restarting strategy instance %s (%d/%d)", g.instanceID, restarts, g.config.maxRestarts())
+	if err := g.Run(run); err != nil {
+		log.WithError(err).Errorf("failed to restart strategy instance %s", g.instanceID)
+	}
+}
+
+// cancelOrders cancels the open orders reported by the strategy through the OpenOrdersReader interface
+func (g *StrategyGuard) cancelOrders(ctx context.Context) error {
+	reader, ok := g.strategy.(OpenOrdersReader)
+	if !ok {
+		log.Warnf("strategy instance %s does not implement OpenOrdersReader, its orders are not canceled", g.instanceID)
+		return nil
+	}
+
+	ordersByExchange := make(map[types.ExchangeName][]types.Order)
+	for _, order := range reader.OpenOrders() {
+		ordersByExchange[order.Exchange] = append(ordersByExchange[order.Exchange], order)
+	}
+
+	for exchangeName, orders := range ordersByExchange {
+		session := g.findSession(exchangeName)
+		if session == nil {
+			return fmt.Errorf("session of exchange %s is not found", exchangeName)
+		}
+
+		if err := session.Exchange.CancelOrders(ctx, orders...); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (g *StrategyGuard) findSession(exchangeName types.ExchangeName) *ExchangeSession {
+	for _, session := range g.sessions {
+		if session.ExchangeName == exchangeName {
+			return session
+		}
+	}
+
+	// the orders of the paper exchanges might not have the exchange name
+	if len(g.sessions) == 1 {
+		for _, session := range g.sessions {
+			return session
+		}
+	}
+
+	return nil
+}
+
+// guardedStream wraps the callbacks registered on the stream with the panic recovery of the strategy guard
+type guardedStream struct {
+	types.Stream
+
+	guard *StrategyGuard
+}
+
+func newGuardedStream(stream types.Stream, guard *StrategyGuard) *guardedStream {
+	return &guardedStream{Stream: stream, guard: guard}
+}
+
+func (s *guardedStream) wrap(f func()) func() {
+	generation := s.guard.currentGeneration()
+	return func() {
+		s.guard.call(generation, f)
+	}
+}
+
+func (s *guardedStream) OnStart(cb func()) {
+	s.Stream.OnStart(s.wrap(cb))
+}
+
+func (s *guardedStream) OnConnect(cb func()) {
+	s.Stream.OnConnect(s.wrap(cb))
+}
+
+func (s *guardedStream) OnDisconnect(cb func()) {
+	s.Stream.OnDisconnect(s.wrap(cb))
+}
+
+func (s *guardedStream) OnTradeUpdate(cb func(trade types.Trade)) {
+	generation := s.guard.currentGeneration()
+	s.Stream.OnTradeUpdate(func(trade types.Trade) {
+		s.guard.call(generation, func() { cb(trade) })
+	})
+}
+
+func (s *guardedStream) OnOrderUpdate(cb func(order types.Order)) {
+	generation := s.guard.currentGeneration()
+	s.Stream.OnOrderUpdate(func(order types.Order) {
+		s.guard.call(generation, func() { cb(order) })
+	})
+}
+
+func (s *guardedStream) OnBalanceSnapshot(cb func(balances types.BalanceMap)) {
+	generation := s.guard.currentGeneration()
+	s.Stream.OnBalanceSnapshot(func(balances types.BalanceMap) {
+		s.guard.call(generation, func() { cb(balances) })
+	})
+}
+
+func (s *guardedStream) OnBalanceUpdate(cb func(balances types.BalanceMap)) {
+	generation := s.guard.currentGeneration()
+	s.Stream.OnBalanceUpdate(func(balances types.BalanceMap) {
+		s.guard.call(generation, func() { cb(balances) })
+	})
+}
+
+func (s *guardedStream) OnKLineClosed(cb func(kline types.KLine)) {
+	generation := s.guard.currentGeneration()
+	s.Stream.OnKLineClosed(func(kline types.KLine) {
+		s.guard.call(generation, func() { cb(kline) })
+	})
+}
+
+func (s *guardedStream) OnKLine(cb func(kline types.KLine)) {
+	generation := s.guard.currentGeneration()
+	s.Stream.OnKLine(func(kline types.KLine) {
+		s.guard.call(generation, func() { cb(kline) })
+	})
+}
+
+func (s *guardedStream) OnBookUpdate(cb func(book types.SliceOrderBook)) {
+	generation := s.guard.currentGeneration()
+	s.Stream.OnBookUpdate(func(book types.SliceOrderBook) {
+		s.guard.call(generation, func() { cb(book) })
+	})
+}
+
+func (s *guardedStream) OnBookTickerUpdate(cb func(bookTicker types.BookTicker)) {
+	generation := s.guard.currentGeneration()
+	s.Stream.OnBookTickerUpdate(func(bookTicker types.BookTicker) {
+		s.guard.call(generation, func() { cb(bookTicker) })
+	})
+}
+
+func (s *guardedStream) OnBookSnapshot(cb func(book types.SliceOrderBook)) {
+	generation := s.guard.currentGeneration()
+	s.Stream.OnBookSnapshot(func(book types.SliceOrderBook) {
+		s.guard.call(generation, func() { cb(book) })
+	})
+}
+
+func (s *guardedStream) OnMarketTrade(cb func(trade types.Trade)) {
+	generation := s.guard.currentGeneration()
+	s.Stream.OnMarketTrade(func(trade types.Trade) {
+		s.guard.call(generation, func() { cb(trade) })
+	})
+}
+
+func (s *guardedStream) OnAggTrade(cb func(trade types.Trade)) {
+	generation := s.guard.currentGeneration()
+	s.Stream.OnAggTrade(func(trade types.Trade) {
+		s.guard.call(generation, func() { cb(trade) })
+	})
+}
+
+func (s *guardedStream) OnLiquidation(cb func(info types.LiquidationInfo)) {
+	generation := s.guard.currentGeneration()
+	s.Stream.OnLiquidation(func(info types.LiquidationInfo) {
+		s.guard.call(generation, func() { cb(info) })
+	})
+}
+
+func (s *guardedStream) OnFuturesPositionUpdate(cb func(futuresPositions types.FuturesPositionMap)) {
+	generation := s.guard.currentGeneration()
+	s.Stream.OnFuturesPositionUpdate(func(futuresPositions types.FuturesPositionMap) {
+		s.guard.call(generation, func() { cb(futuresPositions) })
+	})
+}
+
+func (s *guardedStream) OnFuturesPositionSnapshot(cb func(futuresPositions types.FuturesPositionMap)) {
+	generation := s.guard.currentGeneration()
+	s.Stream.OnFuturesPositionSnapshot(func(futuresPositions types.FuturesPositionMap) {
+		s.guard.call(generation, func() { cb(futuresPositions) })
+	})
+}
+
+// SetPanicRecovery enables the panic isolation of the strategy instances
+func (trader *Trader) SetPanicRecovery(config *PanicRecoveryConfig) {
+	trader.panicRecovery = config
+}
+
+// applyPanicRecovery creates the guarded sessions of the strategy instances,
+// the guarded session wraps the session (or the dry-run session) given to the strategy.
+func (trader *Trader) applyPanicRecovery(ctx context.Context) error {
+	if trader.panicRecovery == nil || trader.environment.BacktestService != nil || IsBackTesting {
+		return nil
+	}
+
+	if trader.strategyGuards == nil {
+		trader.strategyGuards = make(map[string]*StrategyGuard)
+	}
+
+	for sessionName, strategies := range trader.exchangeStrategies {
+		for _, strategy := range strategies {
+			signature, err := getStrategySignature(strategy)
+			if err != nil {
+				return err
+			}
+
+			instanceID := sessionName + "." + signature
+			session, orderExecutor := trader.strategySession(sessionName, strategy)
+			guard := NewStrategyGuard(ctx, instanceID, strategy, map[string]*ExchangeSession{sessionName: session}, trader.panicRecovery)
+
+			if trader.guardedSessions == nil {
+				trader.guardedSessions = make(map[sessionStrategyKey]guardedSession)
+			}
+
+			trader.guardedSessions[sessionStrategyKey{session: sessionName, strategy: strategy}] = guardedSession{
+				session:       session.newGuardedSession(guard),
+				orderExecutor: orderExecutor,
+			}
+			trader.strategyGuards[instanceID] = guard
+		}
+	}
+
+	for _, strategy := range trader.crossExchangeStrategies {
+		instanceID := dynamic.CallID(strategy)
+		if len(instanceID) == 0 {
+			instanceID = strategy.ID()
+		}
+
+		sessions := trader.crossStrategySessions(strategy)
+		guard := NewStrategyGuard(ctx, instanceID, strategy, sessions, trader.panicRecovery)
+
+		guarded := make(map[string]*ExchangeSession, len(sessions))
+		for sessionName, session := range sessions {
+			guarded[sessionName] = session.newGuardedSession(guard)
+		}
+
+		if trader.guardedCrossSessions == nil {
+			trader.guardedCrossSessions = make(map[CrossExchangeStrategy]map[string]*ExchangeSession)
+		}
+
+		trader.guardedCrossSessions[strategy] = guarded
+		trader.strategyGuards[instanceID] = guard
+	}
+
+	return nil
+}
+
+func (trader *Trader) crossStrategyGuard(strategy CrossExchangeStrategy) (*StrategyGuard, bool) {
+	for _, session := range trader.guardedCrossSessions[strategy] {
+		return session.guard, true
+	}
+
+	return nil, false
+}
+
+type guardedSession struct {
+	session       *ExchangeSession
+	orderExecutor OrderExecutor
+}
@@ -0,0 +1,148 @@
+package bbgo
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+type panicStrategy struct {
+	mu       sync.Mutex
+	runs     int
+	klines   int
+	panicked bool
+	orders   types.OrderSlice
+}
+
+func (s *panicStrategy) ID() string { return "panic" }
+
+func (s *panicStrategy) OpenOrders() types.OrderSlice { return s.orders }
+
+func (s *panicStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	s.mu.Lock()
+	s.runs++
+	s.mu.Unlock()
+
+	session.MarketDataStream.OnKLineClosed(func(kline types.KLine) {
+		s.mu.Lock()
+		s.klines++
+		shouldPanic := !s.panicked
+		s.panicked = true
+		s.mu.Unlock()
+
+		if shouldPanic {
+			panic("index out of range")
+		}
+	})
+	return nil
+}
+
+func (s *panicStrategy) counts() (runs, klines int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.runs, s.klines
+}
+
+func newTestGuardedSession(t *testing.T, strategy *panicStrategy, config *PanicRecoveryConfig) (*ExchangeSession, *types.StandardStream, *mocks.MockExchange) {
+	mockCtrl := gomock.NewController(t)
+	t.Cleanup(mockCtrl.Finish)
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).AnyTimes()
+	stream := &types.StandardStream{}
+
+	session := NewExchangeSession("binance", mockEx)
+	session.ExchangeName = types.ExchangeBinance
+	session.MarketDataStream = stream
+	session.UserDataStream = &types.StandardStream{}
+
+	guard := NewStrategyGuard(context.Background(), "binance.panic", strategy, map[string]*ExchangeSession{"binance": session}, config)
+	return session.newGuardedSession(guard), stream, mockEx
+}
+
+func TestStrategyGuard_RecoverPanic(t *testing.T) {
+	strategy := &panicStrategy{
+		orders: types.OrderSlice{{OrderID: 1, Exchange: types.ExchangeBinance}},
+	}
+
+	guarded, stream, mockEx := newTestGuardedSession(t, strategy, &PanicRecoveryConfig{})
+
+	canceled := make(chan struct{})
+	mockEx.EXPECT().CancelOrders(gomock.Any(), strategy.orders[0]).DoAndReturn(func(ctx context.Context, orders ...types.Order) error {
+		close(canceled)
+		return nil
+	}).Times(1)
+
+	assert.NoError(t, guarded.guard.Run(func() error {
+		return strategy.Run(context.Background(), nil, guarded)
+	}))
+
+	assert.NotPanics(t, func() {
+		stream.EmitKLineClosed(types.KLine{})
+	})
+	assert.True(t, guarded.guard.Failed())
+
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("the orders of the failed instance are not canceled")
+	}
+
+	// the callbacks of the failed instance are not called
+	stream.EmitKLineClosed(types.KLine{})
+	_, klines := strategy.counts()
+	assert.Equal(t, 1, klines)
+}
+
+func TestStrategyGuard_Restart(t *testing.T) {
+	strategy := &panicStrategy{}
+	guarded, stream, _ := newTestGuardedSession(t, strategy, &PanicRecoveryConfig{
+		Restart: true,
+		Backoff: types.Duration(time.Millisecond),
+	})
+
+	assert.NoError(t, guarded.guard.Run(func() error {
+		return strategy.Run(context.Background(), nil, guarded)
+	}))
+
+	stream.EmitKLineClosed(types.KLine{})
+	assert.True(t, guarded.guard.Failed())
+	assert.Eventually(t, func() bool {
+		guarded.guard.mu.Lock()
+		defer guarded.guard.mu.Unlock()
+		return guarded.guard.restartPending
+	}, time.Second, time.Millisecond)
+
+	// the pending restart is executed by the next event
+	stream.EmitKLineClosed(types.KLine{})
+	runs, klines := strategy.counts()
+	assert.Equal(t, 2, runs)
+	assert.Equal(t, 1, klines, "the callback of the restarted run is registered after the event")
+	assert.False(t, guarded.guard.Failed())
+	assert.Equal(t, 1, guarded.guard.Restarts())
+
+	// only the callback of the restarted run is called
+	stream.EmitKLineClosed(types.KLine{})
+	_, klines = strategy.counts()
+	assert.Equal(t, 2, klines)
+}
+
+func TestPanicRecoveryConfig_Backoff(t *testing.T) {
+	config := &PanicRecoveryConfig{
+		Backoff:    types.Duration(time.Second),
+		MaxBackoff: types.Duration(5 * time.Second),
+	}
+
+	assert.Equal(t, time.Second, config.backoff(0))
+	assert.Equal(t, 2*time.Second, config.backoff(1))
+	assert.Equal(t, 4*time.Second, config.backoff(2))
+	assert.Equal(t, 5*time.Second, config.backoff(3))
+	assert.Equal(t, defaultPanicRecoveryMaxRestarts, config.maxRestarts())
+}
@@ -0,0 +1,300 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/c9s/bbgo/pkg/dynamic"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrStrategyInstanceNotFound = errors.New("strategy instance not found")
+
+// OpenOrdersReader is implemented by the strategies that can report their own resting orders
+type OpenOrdersReader interface {
+	OpenOrders() types.OrderSlice
+}
+
+// RecentTradesReader is implemented by the strategies that can report their recent fills
+type RecentTradesReader interface {
+	RecentTrades() []types.Trade
+}
+
+// StrategyRequoter is implemented by the strategies that can cancel and re-place their quotes on demand
+type StrategyRequoter interface {
+	Requote(ctx context.Context) error
+}
+
+// StrategyAnalyticsReader is implemented by the strategies that report their own analytics, e.g., the fill statistics
+type StrategyAnalyticsReader interface {
+	Analytics() interface{}
+}
+
+// StrategyConfigSnapshotter is implemented by the strategies that marshal their config under their own lock,
+// the config fields may be updated by the market data callbacks while the state is collected
+type StrategyConfigSnapshotter interface {
+	ConfigSnapshot() ([]byte, error)
+}
+
+// StrategyInstance is a running strategy instance mounted on a session.
+// Cross exchange strategy instances have an empty session name.
+type StrategyInstance struct {
+	ID      string
+	Session string
+
+	// Parent is the instance ID of the parent strategy if the instance is spawned at runtime
+	Parent string
+
+	Strategy StrategyID
+
+	// guard is the strategy guard of the instance when the panic recovery is enabled
+	guard *StrategyGuard
+}
+
+// StrategyInstanceState is the snapshot of the live state of a strategy instance
+type StrategyInstanceState struct {
+	ID         string                 `json:"id"`
+	Strategy   string                 `json:"strategy"`
+	Session    string                 `json:"session,omitempty"`
+	Parent     string                 `json:"parent,omitempty"`
+	Status     types.StrategyStatus   `json:"status"`
+	Config     map[string]interface{} `json:"config,omitempty"`
+	Positions  []*types.Position      `json:"positions,omitempty"`
+	OpenOrders []types.Order          `json:"openOrders,omitempty"`
+	Trades     []types.Trade          `json:"trades,omitempty"`
+
+	CanSuspend bool `json:"canSuspend"`
+	CanRequote bool `json:"canRequote"`
+}
+
+// State collects the current state of the strategy instance
+func (i *StrategyInstance) State() (*StrategyInstanceState, error) {
+	state := &StrategyInstanceState{
+		ID:       i.ID,
+		Strategy: i.Strategy.ID(),
+		Session:  i.Session,
+		Parent:   i.Parent,
+		Status:   types.StrategyStatusUnknown,
+	}
+
+	if reader, ok := i.Strategy.(StrategyStatusReader); ok {
+		state.Status = reader.GetStatus()
+	}
+
+	if i.guard != nil && i.guard.Failed() {
+		state.Status = types.StrategyStatusFailed
+	}
+
+	_, state.CanSuspend = i.Strategy.(StrategyToggler)
+	_, state.CanRequote = i.Strategy.(StrategyRequoter)
+
+	out, err := marshalStrategyConfig(i.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(out, &state.Config); err != nil {
+		return nil, err
+	}
+
+	state.Positions = collectStrategyPositions(i.Strategy)
+
+	if reader, ok := i.Strategy.(OpenOrdersReader); ok {
+		state.OpenOrders = reader.OpenOrders()
+	}
+
+	if reader, ok := i.Strategy.(RecentTradesReader); ok {
+		state.Trades = reader.RecentTrades()
+	}
+
+	return state, nil
+}
+
+// marshalStrategyConfig marshals the strategy with the same locking as the persistence sync
+func marshalStrategyConfig(strategy StrategyID) ([]byte, error) {
+	if snapshotter, ok := strategy.(StrategyConfigSnapshotter); ok {
+		return snapshotter.ConfigSnapshot()
+	}
+
+	if locker, ok := strategy.(sync.Locker); ok {
+		locker.Lock()
+		defer locker.Unlock()
+	}
+
+	return json.Marshal(strategy)
+}
+
+// Suspend suspends the strategy instance if it implements StrategyToggler
+func (i *StrategyInstance) Suspend() error {
+	toggler, ok := i.Strategy.(StrategyToggler)
+	if !ok {
+		return fmt.Errorf("strategy %s does not implement StrategyToggler", i.ID)
+	}
+
+	if toggler.GetStatus() == types.StrategyStatusStopped {
+		return fmt.Errorf("strategy %s is not running", i.ID)
+	}
+
+	return toggler.Suspend()
+}
+
+// Resume resumes the strategy instance if it implements StrategyToggler
+func (i *StrategyInstance) Resume() error {
+	toggler, ok := i.Strategy.(StrategyToggler)
+	if !ok {
+		return fmt.Errorf("strategy %s does not implement StrategyToggler", i.ID)
+	}
+
+	if toggler.GetStatus() == types.StrategyStatusRunning {
+		return fmt.Errorf("strategy %s is already running", i.ID)
+	}
+
+	return toggler.Resume()
+}
+
+// Requote asks the strategy instance to re-place its quotes if it implements StrategyRequoter
+func (i *StrategyInstance) Requote(ctx context.Context) error {
+	requoter, ok := i.Strategy.(StrategyRequoter)
+	if !ok {
+		return fmt.Errorf("strategy %s does not implement StrategyRequoter", i.ID)
+	}
+
+	return requoter.Requote(ctx)
+}
+
+// Analytics returns the analytics of the strategy if the strategy instance implements StrategyAnalyticsReader
+func (i *StrategyInstance) Analytics() (interface{}, error) {
+	reader, ok := i.Strategy.(StrategyAnalyticsReader)
+	if !ok {
+		return nil, fmt.Errorf("strategy %s does not implement StrategyAnalyticsReader", i.ID)
+	}
+
+	return reader.Analytics(), nil
+}
+
+// ClosePosition closes the percentage of the position if the strategy instance implements PositionCloser
+func (i *StrategyInstance) ClosePosition(ctx context.Context, percentage fixedpoint.Value) error {
+	closer, ok := i.Strategy.(PositionCloser)
+	if !ok {
+		return fmt.Errorf("strategy %s does not implement PositionCloser", i.ID)
+	}
+
+	return closer.ClosePosition(ctx, percentage)
+}
+
+// Positions returns the positions held by the strategy instance
+func (i *StrategyInstance) Positions() []*types.Position {
+	return collectStrategyPositions(i.Strategy)
+}
+
+// collectStrategyPositions returns the position from the PositionReader interface,
+// or the exported *types.Position fields of the strategy struct.
+func collectStrategyPositions(strategy interface{}) (positions []*types.Position) {
+	if reader, ok := strategy.(PositionReader); ok {
+		if position := reader.CurrentPosition(); position != nil {
+			return []*types.Position{position}
+		}
+	}
+
+	positionType := reflect.TypeOf(&types.Position{})
+	_ = dynamic.IterateFields(strategy, func(ft reflect.StructField, fv reflect.Value) error {
+		if ft.Type != positionType || fv.IsNil() {
+			return nil
+		}
+
+		if position, ok := fv.Interface().(*types.Position); ok {
+			positions = append(positions, position)
+		}
+		return nil
+	})
+
+	return positions
+}
+
+// StrategyInstances returns the attached strategy instances sorted by the instance ID.
+// The instance ID of the single exchange strategy is "{session}.{signature}", which is the same as the interaction commands use.
+func (trader *Trader) StrategyInstances() ([]*StrategyInstance, error) {
+	// the strategies and the guards are copied under the lock, they are changed by the restarts and the coordinator at runtime
+	trader.childStrategiesMutex.Lock()
+	exchangeStrategies := make(map[string][]SingleExchangeStrategy, len(trader.exchangeStrategies))
+	for sessionName, strategies := range trader.exchangeStrategies {
+		exchangeStrategies[sessionName] = append([]SingleExchangeStrategy(nil), strategies...)
+	}
+
+	crossExchangeStrategies := append([]CrossExchangeStrategy(nil), trader.crossExchangeStrategies...)
+
+	guards := make(map[string]*StrategyGuard, len(trader.strategyGuards))
+	for id, guard := range trader.strategyGuards {
+		guards[id] = guard
+	}
+	trader.childStrategiesMutex.Unlock()
+
+	var instances []*StrategyInstance
+	for sessionName, strategies := range exchangeStrategies {
+		for _, strategy := range strategies {
+			if trader.isStrategyStopped(strategy) {
+				continue
+			}
+
+			signature, err := getStrategySignature(strategy)
+			if err != nil {
+				return nil, err
+			}
+
+			instanceID := sessionName + "." + signature
+			instances = append(instances, &StrategyInstance{
+				ID:       instanceID,
+				Session:  sessionName,
+				Strategy: strategy,
+				guard:    guards[instanceID],
+			})
+		}
+	}
+
+	for _, strategy := range crossExchangeStrategies {
+		if trader.isStrategyStopped(strategy) {
+			continue
+		}
+
+		signature := dynamic.CallID(strategy)
+		if len(signature) == 0 {
+			signature = strategy.ID()
+		}
+
+		instances = append(instances, &StrategyInstance{
+			ID:       signature,
+			Strategy: strategy,
+			guard:    guards[signature],
+		})
+	}
+
+	instances = append(instances, trader.childStrategyInstances()...)
+
+	sort.Slice(instances, func(a, b int) bool {
+		return instances[a].ID < instances[b].ID
+	})
+
+	return instances, nil
+}
+
+// LookupStrategyInstance finds the strategy instance by the instance ID
+func (trader *Trader) LookupStrategyInstance(id string) (*StrategyInstance, error) {
+	instances, err := trader.StrategyInstances()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, instance := range instances {
+		if instance.ID == id {
+			return instance, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrStrategyInstanceNotFound, id)
+}
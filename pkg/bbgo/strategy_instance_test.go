@@ -0,0 +1,121 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type testInstanceStrategy struct {
+	StrategyController
+
+	Symbol      string             `json:"symbol"`
+	Position    *types.Position    `json:"position,omitempty"`
+	ProfitStats *types.ProfitStats `json:"profitStats,omitempty"`
+
+	requoted int
+}
+
+func (s *testInstanceStrategy) ID() string { return "test" }
+
+func (s *testInstanceStrategy) InstanceID() string { return "test:" + s.Symbol }
+
+func (s *testInstanceStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+func (s *testInstanceStrategy) Requote(ctx context.Context) error {
+	s.requoted++
+	return nil
+}
+
+func TestTrader_StrategyInstances(t *testing.T) {
+	strategy := &testInstanceStrategy{
+		Symbol:   "BTCUSDT",
+		Position: &types.Position{Symbol: "BTCUSDT", Base: fixedpoint.NewFromFloat(1.0)},
+	}
+	strategy.Status = types.StrategyStatusRunning
+
+	trader := &Trader{
+		exchangeStrategies: map[string][]SingleExchangeStrategy{
+			"binance": {strategy},
+		},
+	}
+
+	instances, err := trader.StrategyInstances()
+	if assert.NoError(t, err) && assert.Len(t, instances, 1) {
+		assert.Equal(t, "binance.test:BTCUSDT", instances[0].ID)
+		assert.Equal(t, "binance", instances[0].Session)
+	}
+
+	instance, err := trader.LookupStrategyInstance("binance.test:BTCUSDT")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	state, err := instance.State()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "test", state.Strategy)
+		assert.Equal(t, types.StrategyStatusRunning, state.Status)
+		assert.Equal(t, "BTCUSDT", state.Config["symbol"])
+		assert.True(t, state.CanSuspend)
+		assert.True(t, state.CanRequote)
+		assert.Len(t, state.Positions, 1)
+	}
+
+	assert.NoError(t, instance.Suspend())
+	assert.Equal(t, types.StrategyStatusStopped, strategy.GetStatus())
+	assert.Error(t, instance.Suspend())
+
+	assert.NoError(t, instance.Resume())
+	assert.Equal(t, types.StrategyStatusRunning, strategy.GetStatus())
+
+	assert.NoError(t, instance.Requote(context.Background()))
+	assert.Equal(t, 1, strategy.requoted)
+
+	_, err = trader.LookupStrategyInstance("binance.unknown")
+	assert.True(t, errors.Is(err, ErrStrategyInstanceNotFound))
+}
+
+type testLockedInstanceStrategy struct {
+	sync.Mutex
+
+	Symbol  string `json:"symbol"`
+	Counter int    `json:"counter"`
+}
+
+func (s *testLockedInstanceStrategy) ID() string { return "locked" }
+
+func (s *testLockedInstanceStrategy) InstanceID() string { return "locked:" + s.Symbol }
+
+func TestStrategyInstance_State_Locked(t *testing.T) {
+	strategy := &testLockedInstanceStrategy{Symbol: "BTCUSDT"}
+	instance := &StrategyInstance{ID: "binance.locked:BTCUSDT", Session: "binance", Strategy: strategy}
+
+	// the strategy updates its fields under its own lock while the api collects the state
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			strategy.Lock()
+			strategy.Counter++
+			strategy.Unlock()
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		state, err := instance.State()
+		if assert.NoError(t, err) {
+			assert.Equal(t, "BTCUSDT", state.Config["symbol"])
+		}
+	}
+
+	wg.Wait()
+}
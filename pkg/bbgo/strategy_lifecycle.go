@@ -0,0 +1,267 @@
+package bbgo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/dynamic"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// StrategyStopper is implemented by the strategies that can stop trading without shutting down the process.
+// Stop should cancel the open orders of the strategy, and the strategy must not place new orders after it's stopped.
+type StrategyStopper interface {
+	Stop(ctx context.Context) error
+}
+
+// StopStrategyInstance stops a single strategy instance without shutting down the process:
+// the strategy is stopped by StrategyStopper or suspended by StrategyToggler, which cancels its orders,
+// then the persistence fields are flushed, and the instance is removed from the running strategy instances.
+// The stopped instance can be started again by RestartStrategyInstance.
+func (trader *Trader) StopStrategyInstance(ctx context.Context, id string) error {
+	return trader.stopStrategyInstance(ctx, id, true)
+}
+
+// stopStrategyInstance stops the strategy instance, the persistence fields are not stored if persist is false,
+// e.g., the instance lost its lease and another process owns the persisted state.
+func (trader *Trader) stopStrategyInstance(ctx context.Context, id string, persist bool) error {
+	instance, err := trader.LookupStrategyInstance(id)
+	if err != nil {
+		return err
+	}
+
+	switch strategy := instance.Strategy.(type) {
+	case StrategyStopper:
+		if err := strategy.Stop(ctx); err != nil {
+			return err
+		}
+
+	case StrategyToggler:
+		if strategy.GetStatus() != types.StrategyStatusStopped {
+			if err := strategy.Suspend(); err != nil {
+				return err
+			}
+		}
+
+	default:
+		return fmt.Errorf("strategy %s implements neither StrategyStopper nor StrategyToggler", id)
+	}
+
+	trader.childStrategiesMutex.Lock()
+	if child, ok := trader.childStrategies[id]; ok {
+		child.terminated = true
+		child.cancel()
+		delete(trader.childStrategies, id)
+	}
+
+	if trader.stoppedStrategies == nil {
+		trader.stoppedStrategies = make(map[string]*StrategyInstance)
+	}
+
+	trader.stoppedStrategies[id] = instance
+	trader.childStrategiesMutex.Unlock()
+
+	if trader.positionNetting != nil && instance.Session != "" {
+		trader.positionNetting.Unregister(instance.Session, id)
+	}
+
+	if trader.messageBus != nil {
+		trader.messageBus.Unsubscribe(dynamic.CallID(instance.Strategy))
+	}
+
+	if trader.webhookSignals != nil {
+		trader.webhookSignals.Unsubscribe(dynamic.CallID(instance.Strategy))
+	}
+
+	trader.environment.Scheduler().Unschedule(dynamic.CallID(instance.Strategy))
+
+	log.Infof("strategy instance %s is stopped", id)
+
+	if trader.environment.BacktestService != nil || !persist {
+		return nil
+	}
+
+	persistenceID := dynamic.CallID(instance.Strategy)
+	if len(persistenceID) == 0 {
+		return nil
+	}
+
+	ps := GetIsolationFromContext(ctx).persistenceServiceFacade.Get()
+	return storePersistenceFields(instance.Strategy, persistenceID, ps)
+}
+
+// RestartStrategyInstance stops the strategy instance if it's running, and starts a new instance of the same strategy.
+// The config is merged over the config of the stopped instance, only the given keys are changed,
+// and the persisted state, e.g., the position, is loaded by the new instance.
+// Only the single exchange strategy instances can be restarted.
+func (trader *Trader) RestartStrategyInstance(ctx context.Context, id string, config json.RawMessage) (*StrategyInstance, error) {
+	if _, err := trader.LookupStrategyInstance(id); err == nil {
+		if err := trader.StopStrategyInstance(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	trader.childStrategiesMutex.Lock()
+	stopped, ok := trader.stoppedStrategies[id]
+	trader.childStrategiesMutex.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrStrategyInstanceNotFound, id)
+	}
+
+	if stopped.Session == "" {
+		return nil, fmt.Errorf("restarting the cross exchange strategy instance %s is not supported", id)
+	}
+
+	baseConfig, err := trader.strategyConfig(stopped.Strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	mergedConfig, err := mergeStrategyConfig(baseConfig, config)
+	if err != nil {
+		return nil, err
+	}
+
+	strategy, err := NewStrategyFromMap(stopped.Strategy.ID(), mergedConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := getStrategySignature(strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	instance := &StrategyInstance{
+		ID:       stopped.Session + "." + signature,
+		Session:  stopped.Session,
+		Parent:   stopped.Parent,
+		Strategy: strategy,
+	}
+
+	// the restarted instance keeps running after the request context is done
+	runCtx := ctx
+	if trader.runCtx != nil {
+		runCtx = trader.runCtx
+	}
+
+	session, orderExecutor := trader.strategySession(instance.Session, strategy)
+	if session == nil {
+		return nil, fmt.Errorf("session %s is not defined", instance.Session)
+	}
+
+	if err := trader.startStrategyInstance(runCtx, instance, session, orderExecutor); err != nil {
+		return nil, err
+	}
+
+	rawConfig, err := json.Marshal(mergedConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	trader.childStrategiesMutex.Lock()
+	delete(trader.stoppedStrategies, id)
+	delete(trader.strategyConfigs, stopped.Strategy)
+	trader.detachStrategy(stopped.Session, stopped.Strategy)
+	if trader.strategyConfigs == nil {
+		trader.strategyConfigs = make(map[StrategyID]json.RawMessage)
+	}
+	trader.strategyConfigs[strategy] = rawConfig
+	trader.childStrategiesMutex.Unlock()
+
+	log.Infof("strategy instance %s is restarted as %s", id, instance.ID)
+	return instance, nil
+}
+
+// StoppedStrategyInstances returns the stopped strategy instances sorted by the instance ID
+func (trader *Trader) StoppedStrategyInstances() []*StrategyInstance {
+	trader.childStrategiesMutex.Lock()
+	defer trader.childStrategiesMutex.Unlock()
+
+	instances := make([]*StrategyInstance, 0, len(trader.stoppedStrategies))
+	for _, instance := range trader.stoppedStrategies {
+		instances = append(instances, instance)
+	}
+
+	sort.Slice(instances, func(a, b int) bool {
+		return instances[a].ID < instances[b].ID
+	})
+
+	return instances
+}
+
+// detachStrategy removes the configured strategy from the session, the restarted instance runs as a child strategy
+func (trader *Trader) detachStrategy(sessionName string, strategy StrategyID) {
+	strategies := trader.exchangeStrategies[sessionName]
+	for i, s := range strategies {
+		if s == strategy {
+			trader.exchangeStrategies[sessionName] = append(strategies[:i:i], strategies[i+1:]...)
+			return
+		}
+	}
+}
+
+func (trader *Trader) isStrategyStopped(strategy StrategyID) bool {
+	trader.childStrategiesMutex.Lock()
+	defer trader.childStrategiesMutex.Unlock()
+
+	for _, instance := range trader.stoppedStrategies {
+		if instance.Strategy == strategy {
+			return true
+		}
+	}
+
+	return false
+}
+
+// strategyConfig returns the raw config of the strategy loaded from the config file,
+// or the JSON of the strategy if the strategy is not loaded from the config file.
+func (trader *Trader) strategyConfig(strategy StrategyID) (map[string]interface{}, error) {
+	trader.childStrategiesMutex.Lock()
+	rawConfig, ok := trader.strategyConfigs[strategy]
+	trader.childStrategiesMutex.Unlock()
+
+	if !ok {
+		out, err := json.Marshal(strategy)
+		if err != nil {
+			return nil, err
+		}
+
+		rawConfig = out
+	}
+
+	var config map[string]interface{}
+	if err := json.Unmarshal(rawConfig, &config); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// mergeStrategyConfig overrides the top-level keys of the base config with the given config
+func mergeStrategyConfig(base map[string]interface{}, config json.RawMessage) (map[string]interface{}, error) {
+	merged := make(map[string]interface{}, len(base))
+	for k, v := range base {
+		merged[k] = v
+	}
+
+	if len(config) == 0 {
+		return merged, nil
+	}
+
+	var overrides map[string]interface{}
+	if err := json.Unmarshal(config, &overrides); err != nil {
+		return nil, fmt.Errorf("invalid strategy config: %w", err)
+	}
+
+	for k, v := range overrides {
+		merged[k] = v
+	}
+
+	return merged, nil
+}
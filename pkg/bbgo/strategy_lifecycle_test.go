@@ -0,0 +1,156 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+type testLifecycleStrategy struct {
+	Market string  `json:"market"`
+	Spread float64 `json:"spread"`
+
+	Counter int64 `persistence:"counter"`
+
+	stopped bool
+}
+
+func (s *testLifecycleStrategy) ID() string { return "lifecycle" }
+
+func (s *testLifecycleStrategy) InstanceID() string { return "lifecycle:" + s.Market }
+
+func (s *testLifecycleStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	s.Counter++
+	return nil
+}
+
+func (s *testLifecycleStrategy) Stop(ctx context.Context) error {
+	s.stopped = true
+	return nil
+}
+
+func TestTrader_StopAndRestartStrategyInstance(t *testing.T) {
+	RegisterStrategy("lifecycle", &testLifecycleStrategy{})
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	session := NewExchangeSession("binance", mockEx)
+
+	environ := NewEnvironment()
+	environ.AddExchangeSession("binance", session)
+
+	strategy := &testLifecycleStrategy{Market: "BTCUSDT", Spread: 0.1, Counter: 1}
+	trader := NewTrader(environ)
+	assert.NoError(t, trader.AttachStrategyOn("binance", strategy))
+
+	ctx := context.Background()
+	if !assert.NoError(t, trader.injectFieldsAndSubscribe(ctx)) {
+		return
+	}
+
+	if !assert.NoError(t, trader.StopStrategyInstance(ctx, "binance.lifecycle:BTCUSDT")) {
+		return
+	}
+
+	assert.True(t, strategy.stopped)
+	assert.Len(t, trader.StoppedStrategyInstances(), 1)
+
+	_, err := trader.LookupStrategyInstance("binance.lifecycle:BTCUSDT")
+	assert.True(t, errors.Is(err, ErrStrategyInstanceNotFound))
+
+	err = trader.StopStrategyInstance(ctx, "binance.lifecycle:BTCUSDT")
+	assert.True(t, errors.Is(err, ErrStrategyInstanceNotFound))
+
+	instance, err := trader.RestartStrategyInstance(ctx, "binance.lifecycle:BTCUSDT", []byte(`{"spread":0.2}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "binance.lifecycle:BTCUSDT", instance.ID)
+	assert.Len(t, trader.StoppedStrategyInstances(), 0)
+
+	restarted, ok := instance.Strategy.(*testLifecycleStrategy)
+	if assert.True(t, ok) {
+		assert.Equal(t, "BTCUSDT", restarted.Market)
+		assert.Equal(t, 0.2, restarted.Spread)
+
+		// the restarted instance loads the state stored by the stopped instance
+		assert.Equal(t, int64(2), restarted.Counter)
+	}
+
+	instances, err := trader.StrategyInstances()
+	if assert.NoError(t, err) && assert.Len(t, instances, 1) {
+		assert.Equal(t, restarted, instances[0].Strategy)
+	}
+
+	_, err = trader.RestartStrategyInstance(ctx, "binance.lifecycle:BTCUSDT", []byte(`{"spread":`))
+	assert.Error(t, err)
+}
+
+func TestTrader_RestartStrategyInstance_ConcurrentLookup(t *testing.T) {
+	RegisterStrategy("lifecycle", &testLifecycleStrategy{})
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	session := NewExchangeSession("binance", mockEx)
+
+	environ := NewEnvironment()
+	environ.AddExchangeSession("binance", session)
+
+	// each restart detaches a configured strategy from the session
+	trader := NewTrader(environ)
+	for i := 0; i < 50; i++ {
+		assert.NoError(t, trader.AttachStrategyOn("binance", &testLifecycleStrategy{Market: fmt.Sprintf("COIN%dUSDT", i)}))
+	}
+
+	ctx := context.Background()
+	if !assert.NoError(t, trader.injectFieldsAndSubscribe(ctx)) {
+		return
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+
+			_, err := trader.StrategyInstances()
+			assert.NoError(t, err)
+		}
+	}()
+
+	for i := 0; i < 50; i++ {
+		_, err := trader.RestartStrategyInstance(ctx, fmt.Sprintf("binance.lifecycle:COIN%dUSDT", i), nil)
+		assert.NoError(t, err)
+	}
+
+	close(done)
+	wg.Wait()
+
+	instances, err := trader.StrategyInstances()
+	if assert.NoError(t, err) {
+		assert.Len(t, instances, 50)
+	}
+}
@@ -0,0 +1,157 @@
+package bbgo
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/dynamic"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultStrategyOrderBookDepth = 20
+
+// OrderBookLevel is a price level of the order book with the strategy's own resting orders at the price
+type OrderBookLevel struct {
+	Price  fixedpoint.Value `json:"price"`
+	Volume fixedpoint.Value `json:"volume"`
+
+	// OwnQuantity is the remaining quantity of the strategy's orders at the price
+	OwnQuantity fixedpoint.Value `json:"ownQuantity"`
+	NumOfOrders int              `json:"numOfOrders"`
+
+	// Layer is the index of the price level counted from the best price, it's -1 if the price is out of the book depth
+	Layer int `json:"layer"`
+}
+
+// StrategyOrderBookSnapshot is the order book of the strategy symbol with the strategy's own orders overlaid
+type StrategyOrderBookSnapshot struct {
+	InstanceID string    `json:"instanceId"`
+	Session    string    `json:"session"`
+	Symbol     string    `json:"symbol"`
+	Time       time.Time `json:"time"`
+
+	// BookTime is the last update time of the order book
+	BookTime time.Time `json:"bookTime"`
+
+	Bids []OrderBookLevel `json:"bids"`
+	Asks []OrderBookLevel `json:"asks"`
+
+	// OwnBids and OwnAsks are the price levels of the strategy's orders, including the ones out of the book depth
+	OwnBids []OrderBookLevel `json:"ownBids"`
+	OwnAsks []OrderBookLevel `json:"ownAsks"`
+
+	Orders []types.Order `json:"orders"`
+}
+
+// OrderBookSnapshot takes the snapshot of the session order book of the strategy symbol,
+// the strategy should subscribe the book channel, and implement OpenOrdersReader to overlay its own orders.
+func (i *StrategyInstance) OrderBookSnapshot(session *ExchangeSession, depth int) (*StrategyOrderBookSnapshot, error) {
+	if session == nil {
+		return nil, fmt.Errorf("strategy %s is not mounted on a session", i.ID)
+	}
+
+	symbol, ok := dynamic.LookupSymbolField(reflect.ValueOf(i.Strategy))
+	if !ok || len(symbol) == 0 {
+		return nil, fmt.Errorf("strategy %s does not have the symbol field", i.ID)
+	}
+
+	book, ok := session.OrderBook(symbol)
+	if !ok {
+		return nil, fmt.Errorf("order book of %s is not subscribed in session %s", symbol, session.Name)
+	}
+
+	if depth <= 0 {
+		depth = defaultStrategyOrderBookDepth
+	}
+
+	var orders []types.Order
+	if reader, ok := i.Strategy.(OpenOrdersReader); ok {
+		for _, order := range reader.OpenOrders() {
+			if order.Symbol == symbol {
+				orders = append(orders, order)
+			}
+		}
+	}
+
+	snapshot := book.CopyDepth(depth)
+	bids, ownBids := overlayOrderBookSide(snapshot.SideBook(types.SideTypeBuy), orders, types.SideTypeBuy)
+	asks, ownAsks := overlayOrderBookSide(snapshot.SideBook(types.SideTypeSell), orders, types.SideTypeSell)
+
+	return &StrategyOrderBookSnapshot{
+		InstanceID: i.ID,
+		Session:    session.Name,
+		Symbol:     symbol,
+		Time:       time.Now(),
+		BookTime:   snapshot.LastUpdateTime(),
+		Bids:       bids,
+		Asks:       asks,
+		OwnBids:    ownBids,
+		OwnAsks:    ownAsks,
+		Orders:     orders,
+	}, nil
+}
+
+// overlayOrderBookSide merges the orders of the given side into the price levels.
+// The own levels are sorted from the best price, the same as the book side.
+func overlayOrderBookSide(pvs types.PriceVolumeSlice, orders []types.Order, side types.SideType) (levels []OrderBookLevel, ownLevels []OrderBookLevel) {
+	levels = make([]OrderBookLevel, 0, len(pvs))
+	layerOfPrice := make(map[fixedpoint.Value]int, len(pvs))
+	for idx, pv := range pvs {
+		layerOfPrice[pv.Price] = idx
+		levels = append(levels, OrderBookLevel{
+			Price:  pv.Price,
+			Volume: pv.Volume,
+			Layer:  idx,
+		})
+	}
+
+	ownLevelOfPrice := make(map[fixedpoint.Value]*OrderBookLevel)
+	for _, order := range orders {
+		if order.Side != side {
+			continue
+		}
+
+		remaining := order.Quantity.Sub(order.ExecutedQuantity)
+		if ownLevel, ok := ownLevelOfPrice[order.Price]; ok {
+			ownLevel.OwnQuantity = ownLevel.OwnQuantity.Add(remaining)
+			ownLevel.NumOfOrders++
+			continue
+		}
+
+		ownLevel := &OrderBookLevel{
+			Price:       order.Price,
+			OwnQuantity: remaining,
+			NumOfOrders: 1,
+			Layer:       -1,
+		}
+
+		if idx, ok := layerOfPrice[order.Price]; ok {
+			ownLevel.Volume = pvs[idx].Volume
+			ownLevel.Layer = idx
+		}
+
+		ownLevelOfPrice[order.Price] = ownLevel
+	}
+
+	for _, ownLevel := range ownLevelOfPrice {
+		if ownLevel.Layer >= 0 {
+			levels[ownLevel.Layer].OwnQuantity = ownLevel.OwnQuantity
+			levels[ownLevel.Layer].NumOfOrders = ownLevel.NumOfOrders
+		}
+
+		ownLevels = append(ownLevels, *ownLevel)
+	}
+
+	sort.Slice(ownLevels, func(a, b int) bool {
+		if side == types.SideTypeBuy {
+			return ownLevels[a].Price.Compare(ownLevels[b].Price) > 0
+		}
+
+		return ownLevels[a].Price.Compare(ownLevels[b].Price) < 0
+	})
+
+	return levels, ownLevels
+}
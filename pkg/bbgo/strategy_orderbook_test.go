@@ -0,0 +1,45 @@
+package bbgo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestOverlayOrderBookSide(t *testing.T) {
+	number := fixedpoint.MustNewFromString
+
+	bids := types.PriceVolumeSlice{
+		{Price: number("100"), Volume: number("1")},
+		{Price: number("99"), Volume: number("2")},
+		{Price: number("98"), Volume: number("3")},
+	}
+
+	orders := []types.Order{
+		{SubmitOrder: types.SubmitOrder{Side: types.SideTypeBuy, Price: number("99"), Quantity: number("0.5")}},
+		{SubmitOrder: types.SubmitOrder{Side: types.SideTypeBuy, Price: number("99"), Quantity: number("0.5")}, ExecutedQuantity: number("0.2")},
+		{SubmitOrder: types.SubmitOrder{Side: types.SideTypeBuy, Price: number("90"), Quantity: number("1")}},
+		{SubmitOrder: types.SubmitOrder{Side: types.SideTypeSell, Price: number("101"), Quantity: number("1")}},
+	}
+
+	levels, ownLevels := overlayOrderBookSide(bids, orders, types.SideTypeBuy)
+	if assert.Len(t, levels, 3) {
+		assert.Equal(t, fixedpoint.Zero, levels[0].OwnQuantity)
+		assert.Equal(t, number("0.8"), levels[1].OwnQuantity)
+		assert.Equal(t, 2, levels[1].NumOfOrders)
+		assert.Equal(t, 1, levels[1].Layer)
+	}
+
+	if assert.Len(t, ownLevels, 2) {
+		assert.Equal(t, number("99"), ownLevels[0].Price)
+		assert.Equal(t, 1, ownLevels[0].Layer)
+		assert.Equal(t, number("2"), ownLevels[0].Volume)
+
+		// the order out of the book depth
+		assert.Equal(t, number("90"), ownLevels[1].Price)
+		assert.Equal(t, -1, ownLevels[1].Layer)
+	}
+}
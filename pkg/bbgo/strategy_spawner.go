@@ -0,0 +1,301 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/dynamic"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// StrategySpawner is injected into the strategies that declare a *bbgo.StrategySpawner field.
+// It lets the parent strategy create and terminate its child strategy instances at runtime,
+// e.g., spawn a grid strategy on the symbol whose volatility exceeds a threshold.
+//
+// The child strategies go through the same life cycle as the configured strategies:
+// the persistence fields are loaded, the fields are injected, Defaults, Initialize, Subscribe, Validate and Run are called.
+// The new subscriptions declared after the market data stream is connected are applied by reconnecting the stream.
+type StrategySpawner struct {
+	trader   *Trader
+	parent   StrategyID
+	parentID string
+}
+
+type childStrategy struct {
+	instance   *StrategyInstance
+	parentID   string
+	cancel     context.CancelFunc
+	terminated bool
+}
+
+func (trader *Trader) newStrategySpawner(parentID string, parent StrategyID) *StrategySpawner {
+	return &StrategySpawner{
+		trader:   trader,
+		parent:   parent,
+		parentID: parentID,
+	}
+}
+
+// Spawn runs the child strategy on the given session, the child strategy runs until it's terminated or the trader is shut down.
+// The child instance ID is "{session}.{signature}", which must not be used by the other strategy instances.
+func (s *StrategySpawner) Spawn(ctx context.Context, sessionName string, strategy SingleExchangeStrategy) (*StrategyInstance, error) {
+	trader := s.trader
+
+	session, orderExecutor, err := s.childSession(sessionName)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := getStrategySignature(strategy)
+	if err != nil {
+		return nil, err
+	}
+
+	instance := &StrategyInstance{
+		ID:       sessionName + "." + signature,
+		Session:  sessionName,
+		Parent:   s.parentID,
+		Strategy: strategy,
+	}
+
+	if err := trader.startStrategyInstance(ctx, instance, session, orderExecutor); err != nil {
+		return nil, err
+	}
+
+	log.Infof("strategy %s spawned child strategy %s", s.parentID, instance.ID)
+	return instance, nil
+}
+
+// startStrategyInstance runs the strategy instance at runtime,
+// it goes through the same life cycle as the strategies started by the trader.
+func (trader *Trader) startStrategyInstance(ctx context.Context, instance *StrategyInstance, session *ExchangeSession, orderExecutor OrderExecutor) error {
+	strategy, ok := instance.Strategy.(SingleExchangeStrategy)
+	if !ok {
+		return fmt.Errorf("strategy %s is not a single exchange strategy", instance.ID)
+	}
+
+	if _, err := trader.LookupStrategyInstance(instance.ID); err == nil {
+		return fmt.Errorf("strategy instance %s already exists", instance.ID)
+	}
+
+	if trader.environment.BacktestService == nil {
+		ps := GetIsolationFromContext(ctx).persistenceServiceFacade.Get()
+		if err := loadPersistenceFields(strategy, dynamic.CallID(strategy), ps); err != nil {
+			return err
+		}
+	}
+
+	subscriptions := make(map[types.Subscription]struct{}, len(session.Subscriptions))
+	for sub := range session.Subscriptions {
+		subscriptions[sub] = struct{}{}
+	}
+
+	if err := trader.injectSingleExchangeStrategy(ctx, instance.Session, session, orderExecutor, strategy); err != nil {
+		return err
+	}
+
+	if v, ok := strategy.(StrategyValidator); ok {
+		if err := v.Validate(); err != nil {
+			return fmt.Errorf("failed to validate the config: %w", err)
+		}
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	child := &childStrategy{
+		instance: instance,
+		parentID: instance.Parent,
+		cancel:   cancel,
+	}
+
+	trader.childStrategiesMutex.Lock()
+	if _, exists := trader.childStrategies[instance.ID]; exists {
+		trader.childStrategiesMutex.Unlock()
+		cancel()
+		return fmt.Errorf("strategy instance %s already exists", instance.ID)
+	}
+
+	if trader.childStrategies == nil {
+		trader.childStrategies = make(map[string]*childStrategy)
+	}
+
+	trader.childStrategies[instance.ID] = child
+
+	// the market data stream subscribes the declared subscriptions when it's connected,
+	// so only the new subscriptions declared after that need to be applied here.
+	if trader.marketDataConnected {
+		applyNewSubscriptions(session, subscriptions)
+	}
+	trader.childStrategiesMutex.Unlock()
+
+	if shutdown, ok := strategy.(StrategyShutdown); ok {
+		trader.gracefulShutdown.OnShutdown(func(ctx context.Context, wg *sync.WaitGroup) {
+			trader.childStrategiesMutex.Lock()
+			terminated := child.terminated
+			trader.childStrategiesMutex.Unlock()
+
+			// the terminated child strategy is already shut down
+			if terminated {
+				wg.Done()
+				return
+			}
+
+			shutdown.Shutdown(ctx, wg)
+		})
+	}
+
+	if err := strategy.Run(runCtx, orderExecutor, session); err != nil {
+		trader.childStrategiesMutex.Lock()
+		child.terminated = true
+		delete(trader.childStrategies, instance.ID)
+		trader.childStrategiesMutex.Unlock()
+
+		cancel()
+		return err
+	}
+
+	trader.registerNettingPositions(instance)
+	return nil
+}
+
+// Terminate shuts down the child strategy, stores its persistence fields and removes it from the trader.
+// The child strategy should stop reacting to the market data when its context is canceled or it is shut down.
+func (s *StrategySpawner) Terminate(ctx context.Context, id string) error {
+	trader := s.trader
+
+	trader.childStrategiesMutex.Lock()
+	child, ok := trader.childStrategies[id]
+	if !ok || child.parentID != s.parentID {
+		trader.childStrategiesMutex.Unlock()
+		return fmt.Errorf("%w: %s", ErrStrategyInstanceNotFound, id)
+	}
+
+	child.terminated = true
+	delete(trader.childStrategies, id)
+	trader.childStrategiesMutex.Unlock()
+
+	strategy := child.instance.Strategy
+	if shutdown, ok := strategy.(StrategyShutdown); ok {
+		var wg sync.WaitGroup
+		wg.Add(1)
+		shutdown.Shutdown(ctx, &wg)
+		wg.Wait()
+	}
+
+	child.cancel()
+
+	if trader.positionNetting != nil {
+		trader.positionNetting.Unregister(child.instance.Session, id)
+	}
+
+	if trader.messageBus != nil {
+		trader.messageBus.Unsubscribe(dynamic.CallID(strategy))
+	}
+
+	if trader.webhookSignals != nil {
+		trader.webhookSignals.Unsubscribe(dynamic.CallID(strategy))
+	}
+
+	trader.environment.Scheduler().Unschedule(dynamic.CallID(strategy))
+
+	log.Infof("strategy %s terminated child strategy %s", s.parentID, id)
+
+	if trader.environment.BacktestService != nil {
+		return nil
+	}
+
+	id = dynamic.CallID(strategy)
+	if len(id) == 0 {
+		return nil
+	}
+
+	ps := GetIsolationFromContext(ctx).persistenceServiceFacade.Get()
+	return storePersistenceFields(strategy, id, ps)
+}
+
+// Children returns the running child strategy instances spawned by the parent strategy, sorted by the instance ID
+func (s *StrategySpawner) Children() []*StrategyInstance {
+	instances := s.trader.childStrategyInstances()
+
+	var children []*StrategyInstance
+	for _, instance := range instances {
+		if instance.Parent == s.parentID {
+			children = append(children, instance)
+		}
+	}
+
+	return children
+}
+
+// childSession returns the session of the child strategy,
+// the child strategy uses the dry-run session if the parent strategy is in the warm-up period.
+func (s *StrategySpawner) childSession(sessionName string) (*ExchangeSession, OrderExecutor, error) {
+	trader := s.trader
+
+	if _, ok := trader.environment.sessions[sessionName]; !ok {
+		return nil, nil, fmt.Errorf("session %s is not defined", sessionName)
+	}
+
+	switch parent := s.parent.(type) {
+	case SingleExchangeStrategy:
+		session, orderExecutor := trader.strategySession(sessionName, parent)
+		return session, orderExecutor, nil
+
+	case CrossExchangeStrategy:
+		if sessions, ok := trader.dryRunCrossSessions[parent]; ok {
+			session, ok := sessions[sessionName]
+			if !ok {
+				return nil, nil, fmt.Errorf("dry-run session %s is not defined", sessionName)
+			}
+
+			return session, session.OrderExecutor, nil
+		}
+	}
+
+	return trader.environment.sessions[sessionName], trader.getSessionOrderExecutor(sessionName), nil
+}
+
+// childStrategyInstances returns the running child strategy instances sorted by the instance ID
+func (trader *Trader) childStrategyInstances() []*StrategyInstance {
+	trader.childStrategiesMutex.Lock()
+	defer trader.childStrategiesMutex.Unlock()
+
+	instances := make([]*StrategyInstance, 0, len(trader.childStrategies))
+	for _, child := range trader.childStrategies {
+		instances = append(instances, child.instance)
+	}
+
+	sort.Slice(instances, func(a, b int) bool {
+		return instances[a].ID < instances[b].ID
+	})
+
+	return instances
+}
+
+// applyNewSubscriptions subscribes the session subscriptions that are not in the given set on the connected market data stream
+func applyNewSubscriptions(session *ExchangeSession, subscribed map[types.Subscription]struct{}) {
+	var changed bool
+	for _, sub := range session.EffectiveSubscriptions() {
+		if _, ok := subscribed[sub.Subscription]; ok {
+			continue
+		}
+
+		log.Infof("subscribing %s %s %v at runtime, declared by %v", sub.Symbol, sub.Channel, sub.Options, sub.Subscribers)
+		session.MarketDataStream.Subscribe(sub.Channel, sub.Symbol, sub.Options)
+		changed = true
+	}
+
+	if !changed {
+		return
+	}
+
+	// the exchange streams send the subscriptions when the connection is established
+	if reconnector, ok := session.MarketDataStream.(interface{ Reconnect() }); ok {
+		reconnector.Reconnect()
+	} else {
+		log.Warnf("market data stream of session %s can not be reconnected, the new subscriptions will be applied on the next connection", session.Name)
+	}
+}
@@ -0,0 +1,128 @@
+package bbgo
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+type testParentStrategy struct {
+	Spawner *StrategySpawner
+}
+
+func (s *testParentStrategy) ID() string { return "parent" }
+
+func (s *testParentStrategy) InstanceID() string { return "parent" }
+
+func (s *testParentStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	return nil
+}
+
+type testChildStrategy struct {
+	Market string `json:"market"`
+
+	Counter int64 `persistence:"counter"`
+
+	ran      bool
+	shutdown bool
+}
+
+func (s *testChildStrategy) ID() string { return "child" }
+
+func (s *testChildStrategy) InstanceID() string { return "child:" + s.Market }
+
+func (s *testChildStrategy) Subscribe(session *ExchangeSession) {
+	session.Subscribe(types.KLineChannel, s.Market, types.SubscribeOptions{Interval: types.Interval1m})
+}
+
+func (s *testChildStrategy) Run(ctx context.Context, orderExecutor OrderExecutor, session *ExchangeSession) error {
+	s.ran = true
+	s.Counter++
+	return nil
+}
+
+func (s *testChildStrategy) Shutdown(ctx context.Context, wg *sync.WaitGroup) {
+	defer wg.Done()
+	s.shutdown = true
+}
+
+func TestStrategySpawner(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	session := NewExchangeSession("binance", mockEx)
+
+	environ := NewEnvironment()
+	environ.AddExchangeSession("binance", session)
+
+	parent := &testParentStrategy{}
+	trader := NewTrader(environ)
+	assert.NoError(t, trader.AttachStrategyOn("binance", parent))
+
+	ctx := context.Background()
+	if !assert.NoError(t, trader.injectFieldsAndSubscribe(ctx)) || !assert.NotNil(t, parent.Spawner) {
+		return
+	}
+
+	child := &testChildStrategy{Market: "ETHUSDT"}
+	instance, err := parent.Spawner.Spawn(ctx, "binance", child)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, "binance.child:ETHUSDT", instance.ID)
+	assert.Equal(t, "binance.parent", instance.Parent)
+	assert.True(t, child.ran)
+	assert.Len(t, parent.Spawner.Children(), 1)
+
+	// the market data stream is not connected yet, the subscription is collected by the session
+	assert.Len(t, session.MarketDataStream.GetSubscriptions(), 0)
+	assert.Contains(t, session.Subscriptions, types.Subscription{
+		Channel: types.KLineChannel, Symbol: "ETHUSDT", Options: types.SubscribeOptions{Interval: types.Interval1m},
+	})
+
+	instances, err := trader.StrategyInstances()
+	if assert.NoError(t, err) {
+		assert.Len(t, instances, 2)
+	}
+
+	_, err = parent.Spawner.Spawn(ctx, "binance", &testChildStrategy{Market: "ETHUSDT"})
+	assert.Error(t, err)
+
+	_, err = parent.Spawner.Spawn(ctx, "okex", &testChildStrategy{Market: "BTCUSDT"})
+	assert.Error(t, err)
+
+	// the new subscriptions are applied on the connected market data stream
+	trader.marketDataConnected = true
+	_, err = parent.Spawner.Spawn(ctx, "binance", &testChildStrategy{Market: "BTCUSDT"})
+	if assert.NoError(t, err) {
+		subscriptions := session.MarketDataStream.GetSubscriptions()
+		if assert.Len(t, subscriptions, 1) {
+			assert.Equal(t, "BTCUSDT", subscriptions[0].Symbol)
+		}
+	}
+
+	assert.NoError(t, parent.Spawner.Terminate(ctx, "binance.child:ETHUSDT"))
+	assert.True(t, child.shutdown)
+	assert.Len(t, parent.Spawner.Children(), 1)
+
+	err = parent.Spawner.Terminate(ctx, "binance.child:ETHUSDT")
+	assert.True(t, errors.Is(err, ErrStrategyInstanceNotFound))
+
+	// the respawned child strategy restores the persisted state
+	respawned := &testChildStrategy{Market: "ETHUSDT"}
+	_, err = parent.Spawner.Spawn(ctx, "binance", respawned)
+	if assert.NoError(t, err) {
+		assert.Equal(t, int64(2), respawned.Counter)
+	}
+}
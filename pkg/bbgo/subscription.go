@@ -0,0 +1,88 @@
+package bbgo
+
+import (
+	"sort"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// SubscriptionInfo is an effective subscription of a session with the subscribers that declared it
+type SubscriptionInfo struct {
+	types.Subscription
+
+	Subscribers []string `json:"subscribers,omitempty"`
+}
+
+// normalizeSubscription clears the options that do not apply to the channel,
+// so that the identical subscriptions declared by different strategies share the same key.
+func normalizeSubscription(sub types.Subscription) types.Subscription {
+	switch sub.Channel {
+	case types.KLineChannel:
+		sub.Options = types.SubscribeOptions{Interval: sub.Options.Interval}
+
+	case types.BookChannel:
+		sub.Options.Interval = ""
+
+	}
+
+	return sub
+}
+
+// subscribeAs runs the subscribe function with the given subscriber name,
+// the subscriptions declared inside the function are recorded under the subscriber.
+func (session *ExchangeSession) subscribeAs(subscriber string, subscribe func()) {
+	session.subscriber = subscriber
+	defer func() {
+		session.subscriber = ""
+	}()
+
+	subscribe()
+}
+
+func (session *ExchangeSession) addSubscriber(sub types.Subscription) {
+	if session.subscriber == "" {
+		return
+	}
+
+	if session.subscribers == nil {
+		session.subscribers = make(map[types.Subscription][]string)
+	}
+
+	for _, subscriber := range session.subscribers[sub] {
+		if subscriber == session.subscriber {
+			return
+		}
+	}
+
+	session.subscribers[sub] = append(session.subscribers[sub], session.subscriber)
+}
+
+// EffectiveSubscriptions returns the deduplicated subscriptions that will be sent to the market data stream,
+// sorted by the channel, the symbol and the options.
+func (session *ExchangeSession) EffectiveSubscriptions() []SubscriptionInfo {
+	var infos []SubscriptionInfo
+	for _, sub := range session.Subscriptions {
+		subscribers := append([]string(nil), session.subscribers[sub]...)
+		sort.Strings(subscribers)
+
+		infos = append(infos, SubscriptionInfo{
+			Subscription: sub,
+			Subscribers:  subscribers,
+		})
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		a, b := infos[i], infos[j]
+		if a.Channel != b.Channel {
+			return a.Channel < b.Channel
+		}
+
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+
+		return a.Options.String() < b.Options.String()
+	})
+
+	return infos
+}
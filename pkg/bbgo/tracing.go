@@ -0,0 +1,183 @@
+package bbgo
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const tracerName = "github.com/c9s/bbgo"
+
+// maxNumOfEarlyOrderUpdates is the maximum number of the final order updates kept for the orders
+// that are confirmed by the stream before the REST response is returned
+const maxNumOfEarlyOrderUpdates = 100
+
+// Tracer returns the bbgo tracer of the global tracer provider.
+// The spans are dropped unless a tracer provider is registered with otel.SetTracerProvider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(tracerName)
+}
+
+// StartDecisionSpan starts the span of a strategy decision,
+// the orders submitted with the returned context are traced as the children of the decision span.
+func StartDecisionSpan(ctx context.Context, strategyInstanceID, decision string) (context.Context, trace.Span) {
+	return Tracer().Start(ctx, "bbgo.Decision", trace.WithAttributes(
+		attribute.String("strategy_instance_id", strategyInstanceID),
+		attribute.String("decision", decision),
+	))
+}
+
+func submitOrderAttributes(exchange types.ExchangeName, submitOrder types.SubmitOrder) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("exchange", exchange.String()),
+		attribute.String("symbol", submitOrder.Symbol),
+		attribute.String("side", string(submitOrder.Side)),
+		attribute.String("order_type", string(submitOrder.Type)),
+	}
+}
+
+func recordSpanError(span trace.Span, err error) {
+	if err == nil {
+		return
+	}
+
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+}
+
+// submitOrderWithSpan submits the order to the exchange with the span of the REST call
+func submitOrderWithSpan(ctx context.Context, exchange types.Exchange, order types.SubmitOrder) (*types.Order, error) {
+	ctx, span := Tracer().Start(ctx, "exchange.SubmitOrder", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+
+	// only ask the exchange name when the span is recorded
+	if span.IsRecording() {
+		span.SetAttributes(submitOrderAttributes(exchange.Name(), order)...)
+	}
+
+	createdOrder, err := exchange.SubmitOrder(ctx, order)
+	recordSpanError(span, err)
+	if createdOrder != nil {
+		span.SetAttributes(attribute.Int64("order_id", int64(createdOrder.OrderID)))
+	}
+
+	return createdOrder, err
+}
+
+// orderSpanTracker traces the order lifecycle from the submission to the final order update of the user data stream
+type orderSpanTracker struct {
+	mu    sync.Mutex
+	spans map[uint64]trace.Span
+
+	// earlyUpdates keeps the final order updates received before the order span is started
+	earlyUpdates map[uint64]types.Order
+}
+
+func newOrderSpanTracker() *orderSpanTracker {
+	return &orderSpanTracker{
+		spans:        make(map[uint64]trace.Span),
+		earlyUpdates: make(map[uint64]types.Order),
+	}
+}
+
+// Start starts the lifecycle span of the created order at the submission time
+func (t *orderSpanTracker) Start(ctx context.Context, submitTime time.Time, order types.Order) {
+	_, span := Tracer().Start(ctx, "bbgo.OrderLifecycle",
+		trace.WithTimestamp(submitTime),
+		trace.WithAttributes(append(submitOrderAttributes(order.Exchange, order.SubmitOrder),
+			attribute.Int64("order_id", int64(order.OrderID)))...))
+
+	if !span.IsRecording() {
+		return
+	}
+
+	span.AddEvent("order.created")
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if update, ok := t.earlyUpdates[order.OrderID]; ok {
+		delete(t.earlyUpdates, order.OrderID)
+		endOrderSpan(span, update)
+		return
+	}
+
+	t.spans[order.OrderID] = span
+}
+
+func (t *orderSpanTracker) BindStream(stream types.Stream) {
+	stream.OnOrderUpdate(t.handleOrderUpdate)
+	stream.OnTradeUpdate(t.handleTradeUpdate)
+}
+
+func (t *orderSpanTracker) handleOrderUpdate(order types.Order) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	span, ok := t.spans[order.OrderID]
+	if !ok {
+		// the updates of the orders submitted by the other executors are also kept here, so reset it when it's full
+		if isFinalOrderStatus(order.Status) {
+			if len(t.earlyUpdates) >= maxNumOfEarlyOrderUpdates {
+				t.earlyUpdates = make(map[uint64]types.Order)
+			}
+			t.earlyUpdates[order.OrderID] = order
+		}
+		return
+	}
+
+	if !isFinalOrderStatus(order.Status) {
+		span.AddEvent("stream.order_update", trace.WithAttributes(
+			attribute.String("status", string(order.Status)),
+			attribute.String("executed_quantity", order.ExecutedQuantity.String()),
+		))
+		return
+	}
+
+	delete(t.spans, order.OrderID)
+	endOrderSpan(span, order)
+}
+
+func (t *orderSpanTracker) handleTradeUpdate(trade types.Trade) {
+	t.mu.Lock()
+	span, ok := t.spans[trade.OrderID]
+	t.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	span.AddEvent("stream.trade", trace.WithAttributes(
+		attribute.Int64("trade_id", int64(trade.ID)),
+		attribute.String("price", trade.Price.String()),
+		attribute.String("quantity", trade.Quantity.String()),
+	))
+}
+
+func endOrderSpan(span trace.Span, order types.Order) {
+	span.AddEvent("stream.order_update", trace.WithAttributes(
+		attribute.String("status", string(order.Status)),
+		attribute.String("executed_quantity", order.ExecutedQuantity.String()),
+	))
+	span.SetAttributes(attribute.String("status", string(order.Status)))
+	if order.Status == types.OrderStatusRejected {
+		span.SetStatus(codes.Error, "order rejected")
+	}
+
+	span.End()
+}
+
+func isFinalOrderStatus(status types.OrderStatus) bool {
+	switch status {
+	case types.OrderStatusFilled, types.OrderStatusCanceled, types.OrderStatusRejected:
+		return true
+	}
+
+	return false
+}
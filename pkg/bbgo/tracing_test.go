@@ -0,0 +1,117 @@
+package bbgo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/oteltest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func findSpan(spans []*oteltest.Span, name string) *oteltest.Span {
+	for _, span := range spans {
+		if span.Name() == name {
+			return span
+		}
+	}
+
+	return nil
+}
+
+func TestGeneralOrderExecutor_SubmitOrdersTracing(t *testing.T) {
+	recorder := new(oteltest.SpanRecorder)
+	otel.SetTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(recorder)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	market := getTestMarket()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	submitOrder := types.SubmitOrder{
+		Symbol:   "BTCUSDT",
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeLimit,
+		Market:   market,
+		Price:    fixedpoint.NewFromFloat(20000.0),
+		Quantity: fixedpoint.NewFromFloat(0.1),
+	}
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+	mockEx.EXPECT().Name().Return(types.ExchangeBinance).AnyTimes()
+	mockEx.EXPECT().SubmitOrder(gomock.Any(), gomock.Any()).Return(&types.Order{
+		SubmitOrder: submitOrder,
+		Exchange:    types.ExchangeBinance,
+		OrderID:     1,
+		Status:      types.OrderStatusNew,
+	}, nil)
+
+	session := NewExchangeSession("binance", mockEx)
+	session.markets[market.Symbol] = market
+
+	orderExecutor := NewGeneralOrderExecutor(session, "BTCUSDT", "test", "test-01", types.NewPositionFromMarket(market))
+	orderExecutor.Bind()
+
+	ctx, decisionSpan := StartDecisionSpan(context.Background(), "test-01", "open long")
+	_, err := orderExecutor.SubmitOrders(ctx, submitOrder)
+	decisionSpan.End()
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	stream := session.UserDataStream.(*types.StandardStream)
+	stream.EmitTradeUpdate(types.Trade{ID: 2, OrderID: 1, Symbol: "BTCUSDT", Side: types.SideTypeBuy,
+		Price: submitOrder.Price, Quantity: submitOrder.Quantity})
+	stream.EmitOrderUpdate(types.Order{SubmitOrder: submitOrder, OrderID: 1, Status: types.OrderStatusFilled,
+		ExecutedQuantity: submitOrder.Quantity})
+
+	completed := recorder.Completed()
+	decision := findSpan(completed, "bbgo.Decision")
+	submit := findSpan(completed, "bbgo.SubmitOrders")
+	rest := findSpan(completed, "exchange.SubmitOrder")
+	lifecycle := findSpan(completed, "bbgo.OrderLifecycle")
+	if !assert.NotNil(t, decision) || !assert.NotNil(t, submit) || !assert.NotNil(t, rest) || !assert.NotNil(t, lifecycle) {
+		return
+	}
+
+	assert.Equal(t, decision.SpanContext().SpanID(), submit.ParentSpanID())
+	assert.Equal(t, submit.SpanContext().SpanID(), rest.ParentSpanID())
+	assert.Equal(t, submit.SpanContext().SpanID(), lifecycle.ParentSpanID())
+	assert.Equal(t, "binance", rest.Attributes()["exchange"].AsString())
+	assert.Equal(t, attribute.StringValue("FILLED"), lifecycle.Attributes()["status"])
+	assert.False(t, lifecycle.StartTime().After(rest.StartTime()))
+
+	var events []string
+	for _, event := range lifecycle.Events() {
+		events = append(events, event.Name)
+	}
+	assert.Equal(t, []string{"order.created", "stream.trade", "stream.order_update"}, events)
+}
+
+func TestOrderSpanTracker_EarlyUpdate(t *testing.T) {
+	recorder := new(oteltest.SpanRecorder)
+	otel.SetTracerProvider(oteltest.NewTracerProvider(oteltest.WithSpanRecorder(recorder)))
+	defer otel.SetTracerProvider(trace.NewNoopTracerProvider())
+
+	tracker := newOrderSpanTracker()
+
+	// the stream confirms the order before the REST response is returned
+	tracker.handleOrderUpdate(types.Order{OrderID: 1, Status: types.OrderStatusCanceled})
+	tracker.Start(context.Background(), time.Now(), types.Order{OrderID: 1, Status: types.OrderStatusNew})
+
+	assert.Empty(t, tracker.spans)
+	assert.Empty(t, tracker.earlyUpdates)
+	if completed := recorder.Completed(); assert.Len(t, completed, 1) {
+		assert.Equal(t, attribute.StringValue("CANCELED"), completed[0].Attributes()["status"])
+	}
+}
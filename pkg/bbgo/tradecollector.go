@@ -2,6 +2,7 @@ package bbgo
 
 import (
 	"context"
+	"sort"
 	"sync"
 	"time"
 
@@ -23,6 +24,29 @@ type TradeCollector struct {
 	orderStore *OrderStore
 	doneTrades map[types.TradeKey]struct{}
 
+	// feePriceSource converts the fees paid in the other currency (e.g., BNB) into the quote currency
+	feePriceSource types.FeePriceSource
+
+	// reorderDelay holds the matched trades for the delay, so that the out-of-order trades are applied by the trade time
+	reorderDelay  time.Duration
+	pendingTrades map[types.TradeKey]pendingTrade
+	lastTradeTime time.Time
+
+	// collectedQuantities is the trade quantity collected for each order, it's compared with the executed quantity of the order
+	// to detect the missing trades
+	collectedQuantities map[uint64]fixedpoint.Value
+
+	// tradeHistoryService is used for recovering the missing trades of the sequence gaps
+	tradeHistoryService types.ExchangeTradeHistoryService
+	gapTimeout          time.Duration
+	gapSince            time.Time
+	gapDetectedAt       map[uint64]time.Time
+
+	now func() time.Time
+
+	// appliedTrades are the trades applied under the lock, their callbacks are emitted after the lock is released
+	appliedTrades []appliedTrade
+
 	mu sync.Mutex
 
 	recoverCallbacks []func(trade types.Trade)
@@ -43,9 +67,27 @@ func NewTradeCollector(symbol string, position *types.Position, orderStore *Orde
 		doneTrades: make(map[types.TradeKey]struct{}),
 		position:   position,
 		orderStore: orderStore,
+
+		pendingTrades:       make(map[types.TradeKey]pendingTrade),
+		collectedQuantities: make(map[uint64]fixedpoint.Value),
+		gapDetectedAt:       make(map[uint64]time.Time),
+		now:                 time.Now,
 	}
 }
 
+type pendingTrade struct {
+	trade      types.Trade
+	receivedAt time.Time
+}
+
+// appliedTrade is the trade added to the position and its profit, the profit is nil if the trade does not make profit
+type appliedTrade struct {
+	trade             types.Trade
+	profit, netProfit fixedpoint.Value
+	profitRecord      *types.Profit
+	hasPosition       bool
+}
+
 // OrderStore returns the order store used by the trade collector
 func (c *TradeCollector) OrderStore() *OrderStore {
 	return c.orderStore
@@ -64,6 +106,48 @@ func (c *TradeCollector) SetPosition(position *types.Position) {
 	c.position = position
 }
 
+// SetFeePriceSource sets the price source for converting the fees paid in the other currency into the quote currency,
+// the converted fee is counted in the average cost of the position and the profit stats.
+func (c *TradeCollector) SetFeePriceSource(source types.FeePriceSource) {
+	c.feePriceSource = source
+}
+
+// SetReorderDelay holds the matched trades for the delay before adding them to the position,
+// the held trades are applied by the trade time, so the out-of-order fills from the websocket don't break the average cost.
+// The held trades are released by ProcessTrade and Process, call Process periodically (see Run) when the delay is set.
+func (c *TradeCollector) SetReorderDelay(delay time.Duration) {
+	c.mu.Lock()
+	c.reorderDelay = delay
+	c.mu.Unlock()
+}
+
+// EnableGapRecovery enables the sequence gap recovery, when the executed quantity of an order is not covered by the collected trades
+// for the timeout, the trades of the order are queried from the trade history service (REST API) and processed.
+// Only the orders created after this call are checked, the trades of the earlier orders might be processed before the restart.
+func (c *TradeCollector) EnableGapRecovery(service types.ExchangeTradeHistoryService, timeout time.Duration) {
+	c.mu.Lock()
+	c.tradeHistoryService = service
+	c.gapTimeout = timeout
+	c.gapSince = c.now()
+	c.mu.Unlock()
+}
+
+// convertFee sets the fee in quote of the trade with the position currencies
+func (c *TradeCollector) convertFee(trade types.Trade) types.Trade {
+	if !trade.FeeInQuote.IsZero() {
+		return trade
+	}
+
+	if feeInQuote, ok := types.FeeInQuote(trade, c.position.BaseCurrency, c.position.QuoteCurrency, c.feePriceSource); ok {
+		trade.FeeInQuote = feeInQuote
+	} else {
+		log.Debugf("can not convert the %s fee %s %s into %s, the fee will be estimated by the fee rate",
+			trade.Symbol, trade.Fee.String(), trade.FeeCurrency, c.position.QuoteCurrency)
+	}
+
+	return trade
+}
+
 // QueueTrade sends the trade object to the trade channel,
 // so that the goroutine can receive the trade and process in the background.
 func (c *TradeCollector) QueueTrade(trade types.Trade) {
@@ -113,49 +197,234 @@ func (c *TradeCollector) setDone(key types.TradeKey) {
 	c.mu.Unlock()
 }
 
+// RecoverGaps queries the trades of the orders with the missing trades and processes them,
+// the duplicated trades are skipped by the trade key, so it's safe to be called periodically.
+func (c *TradeCollector) RecoverGaps(ctx context.Context) error {
+	if c.tradeHistoryService == nil {
+		return nil
+	}
+
+	orders := c.detectGaps()
+	if len(orders) == 0 {
+		return nil
+	}
+
+	orderIDs := make(map[uint64]struct{}, len(orders))
+	since := orders[0].CreationTime.Time()
+	for _, order := range orders {
+		orderIDs[order.OrderID] = struct{}{}
+		if order.CreationTime.Time().Before(since) {
+			since = order.CreationTime.Time()
+		}
+
+		log.Warnf("%s order %d executed quantity %s is not covered by the collected trades, recovering the trades since %s",
+			c.Symbol, order.OrderID, order.ExecutedQuantity.String(), since)
+	}
+
+	trades, err := c.tradeHistoryService.QueryTrades(ctx, c.Symbol, &types.TradeQueryOptions{
+		StartTime: &since,
+	})
+	if err != nil {
+		return err
+	}
+
+	sortTrades(trades)
+	for _, td := range trades {
+		// only the trades of the gap orders are processed, the other trades might be processed before the restart
+		if _, ok := orderIDs[td.OrderID]; !ok {
+			continue
+		}
+
+		if c.ProcessTrade(td) {
+			log.Infof("recovered gap trade: %s", td.String())
+			c.EmitRecover(td)
+		}
+	}
+
+	return nil
+}
+
+// detectGaps returns the orders whose executed quantity is not covered by the collected trades for the gap timeout,
+// the detected time is reset after the orders are returned, so the gap is recovered once per timeout.
+func (c *TradeCollector) detectGaps() (orders []types.Order) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	orderIDs := make(map[uint64]struct{})
+	for _, order := range c.orderStore.Orders() {
+		orderIDs[order.OrderID] = struct{}{}
+
+		if order.CreationTime.Time().Before(c.gapSince) {
+			continue
+		}
+
+		if order.ExecutedQuantity.Compare(c.collectedQuantities[order.OrderID]) <= 0 {
+			delete(c.gapDetectedAt, order.OrderID)
+			continue
+		}
+
+		detectedAt, ok := c.gapDetectedAt[order.OrderID]
+		if !ok {
+			c.gapDetectedAt[order.OrderID] = now
+			continue
+		}
+
+		if now.Sub(detectedAt) >= c.gapTimeout {
+			orders = append(orders, order)
+			c.gapDetectedAt[order.OrderID] = now
+		}
+	}
+
+	// prune the quantities of the removed orders
+	for orderID := range c.collectedQuantities {
+		if _, ok := orderIDs[orderID]; !ok {
+			delete(c.collectedQuantities, orderID)
+			delete(c.gapDetectedAt, orderID)
+		}
+	}
+
+	return orders
+}
+
+// isCollected checks if the trade is applied or held in the reorder buffer, the caller must hold the lock
+func (c *TradeCollector) isCollected(key types.TradeKey) bool {
+	if _, done := c.doneTrades[key]; done {
+		return true
+	}
+
+	_, pending := c.pendingTrades[key]
+	return pending
+}
+
+// collectTrade applies the matched trade, or holds it in the reorder buffer when the reorder delay is set,
+// it returns true when the position is changed. The caller must hold the lock.
+func (c *TradeCollector) collectTrade(trade types.Trade) bool {
+	c.collectedQuantities[trade.OrderID] = c.collectedQuantities[trade.OrderID].Add(trade.Quantity)
+
+	if c.reorderDelay > 0 {
+		c.pendingTrades[trade.Key()] = pendingTrade{trade: trade, receivedAt: c.now()}
+		return c.flushPendingTrades()
+	}
+
+	return c.applyTrade(trade)
+}
+
+// flushPendingTrades applies the held trades received before the reorder delay by the trade time,
+// it returns true when the position is changed. The caller must hold the lock.
+func (c *TradeCollector) flushPendingTrades() bool {
+	now := c.now()
+
+	var trades []types.Trade
+	for key, pending := range c.pendingTrades {
+		if now.Sub(pending.receivedAt) >= c.reorderDelay {
+			trades = append(trades, pending.trade)
+			delete(c.pendingTrades, key)
+		}
+	}
+
+	sortTrades(trades)
+
+	positionChanged := false
+	for _, trade := range trades {
+		if c.applyTrade(trade) {
+			positionChanged = true
+		}
+	}
+
+	return positionChanged
+}
+
+// applyTrade adds the trade to the position and queues the trade callbacks, see emitAppliedTrades,
+// it returns true when the position is changed. The caller must hold the lock.
+func (c *TradeCollector) applyTrade(trade types.Trade) bool {
+	c.doneTrades[trade.Key()] = struct{}{}
+
+	tradeTime := trade.Time.Time()
+	if tradeTime.Before(c.lastTradeTime) {
+		log.Warnf("%s trade %d at %s is out of order, the last trade time is %s", c.Symbol, trade.ID, tradeTime, c.lastTradeTime)
+	} else {
+		c.lastTradeTime = tradeTime
+	}
+
+	if c.position == nil {
+		c.appliedTrades = append(c.appliedTrades, appliedTrade{trade: trade})
+		return false
+	}
+
+	trade = c.convertFee(trade)
+	applied := appliedTrade{trade: trade, hasPosition: true}
+	profit, netProfit, madeProfit := c.position.AddTrade(trade)
+	if madeProfit {
+		p := c.position.NewProfit(trade, profit, netProfit)
+		applied.profit, applied.netProfit, applied.profitRecord = profit, netProfit, &p
+	}
+
+	c.appliedTrades = append(c.appliedTrades, applied)
+	return true
+}
+
+// takeAppliedTrades returns and clears the queued trades, the caller must hold the lock
+func (c *TradeCollector) takeAppliedTrades() []appliedTrade {
+	applied := c.appliedTrades
+	c.appliedTrades = nil
+	return applied
+}
+
+// emitAppliedTrades emits the callbacks of the applied trades and the position update without holding the lock,
+// so that the handlers can submit orders, which process the trades of the collector again.
+func (c *TradeCollector) emitAppliedTrades(applied []appliedTrade, positionChanged bool) {
+	for _, a := range applied {
+		if a.profitRecord != nil {
+			c.EmitTrade(a.trade, a.profit, a.netProfit)
+			c.EmitProfit(a.trade, a.profitRecord)
+		} else if a.hasPosition {
+			c.EmitTrade(a.trade, fixedpoint.Zero, fixedpoint.Zero)
+			c.EmitProfit(a.trade, nil)
+		} else {
+			c.EmitTrade(a.trade, fixedpoint.Zero, fixedpoint.Zero)
+		}
+	}
+
+	if positionChanged && c.position != nil {
+		c.EmitPositionUpdate(c.position)
+	}
+}
+
 // Process filters the received trades and see if there are orders matching the trades
 // if we have the order in the order store, then the trade will be considered for the position.
 // profit will also be calculated.
+// The trades are processed by the trade time, and the held trades of the reorder buffer are released.
 func (c *TradeCollector) Process() bool {
+	c.mu.Lock()
+
 	positionChanged := false
 
-	c.tradeStore.Filter(func(trade types.Trade) bool {
-		key := trade.Key()
-
-		c.mu.Lock()
-		defer c.mu.Unlock()
-
-		// if it's already done, remove the trade from the trade store
-		if _, done := c.doneTrades[key]; done {
-			return true
-		}
-
-		if c.orderStore.Exists(trade.OrderID) {
-			if c.position != nil {
-				profit, netProfit, madeProfit := c.position.AddTrade(trade)
-				if madeProfit {
-					p := c.position.NewProfit(trade, profit, netProfit)
-					c.EmitTrade(trade, profit, netProfit)
-					c.EmitProfit(trade, &p)
-				} else {
-					c.EmitTrade(trade, fixedpoint.Zero, fixedpoint.Zero)
-					c.EmitProfit(trade, nil)
-				}
-				positionChanged = true
-			} else {
-				c.EmitTrade(trade, fixedpoint.Zero, fixedpoint.Zero)
-			}
+	trades := c.tradeStore.Trades()
+	sortTrades(trades)
+	for _, trade := range trades {
+		if c.isCollected(trade.Key()) {
+			continue
+		}
 
-			c.doneTrades[key] = struct{}{}
-			return true
+		if c.orderStore.Exists(trade.OrderID) && c.collectTrade(trade) {
+			positionChanged = true
 		}
-		return false
+	}
+
+	// remove the collected trades from the trade store
+	c.tradeStore.Filter(func(trade types.Trade) bool {
+		return c.isCollected(trade.Key())
 	})
 
-	if positionChanged && c.position != nil {
-		c.EmitPositionUpdate(c.position)
+	if c.flushPendingTrades() {
+		positionChanged = true
 	}
 
+	applied := c.takeAppliedTrades()
+	c.mu.Unlock()
+
+	c.emitAppliedTrades(applied, positionChanged)
 	return positionChanged
 }
 
@@ -165,47 +434,38 @@ func (c *TradeCollector) Process() bool {
 // return false when the given trade is not added
 func (c *TradeCollector) processTrade(trade types.Trade) bool {
 	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	key := trade.Key()
 
 	// if it's already done, remove the trade from the trade store
-	if _, done := c.doneTrades[key]; done {
+	if c.isCollected(trade.Key()) {
+		c.mu.Unlock()
 		return false
 	}
 
-	if c.orderStore.Exists(trade.OrderID) {
-		if c.position != nil {
-			profit, netProfit, madeProfit := c.position.AddTrade(trade)
-			if madeProfit {
-				p := c.position.NewProfit(trade, profit, netProfit)
-				c.EmitTrade(trade, profit, netProfit)
-				c.EmitProfit(trade, &p)
-			} else {
-				c.EmitTrade(trade, fixedpoint.Zero, fixedpoint.Zero)
-				c.EmitProfit(trade, nil)
-			}
-			c.EmitPositionUpdate(c.position)
-		} else {
-			c.EmitTrade(trade, fixedpoint.Zero, fixedpoint.Zero)
-		}
-
-		c.doneTrades[key] = struct{}{}
-		return true
+	if !c.orderStore.Exists(trade.OrderID) {
+		c.mu.Unlock()
+		return false
 	}
-	return false
+
+	positionChanged := c.collectTrade(trade)
+	applied := c.takeAppliedTrades()
+	c.mu.Unlock()
+
+	c.emitAppliedTrades(applied, positionChanged)
+	return true
 }
 
 // return true when the given trade is added
 // return false when the given trade is not added
+// When the reorder delay is set, the added trade is held in the reorder buffer before it's applied to the position.
 func (c *TradeCollector) ProcessTrade(trade types.Trade) bool {
 	key := trade.Key()
 	// if it's already done, remove the trade from the trade store
 	c.mu.Lock()
-	if _, done := c.doneTrades[key]; done {
+	collected := c.isCollected(key)
+	c.mu.Unlock()
+	if collected {
 		return false
 	}
-	c.mu.Unlock()
 
 	if c.processTrade(trade) {
 		return true
@@ -217,8 +477,11 @@ func (c *TradeCollector) ProcessTrade(trade types.Trade) bool {
 
 // Run is a goroutine executed in the background
 // Do not use this function if you need back-testing
+// The missing trades are recovered on the ticker when the gap recovery is enabled.
 func (c *TradeCollector) Run(ctx context.Context) {
 	var ticker = time.NewTicker(3 * time.Second)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -227,6 +490,10 @@ func (c *TradeCollector) Run(ctx context.Context) {
 		case <-ticker.C:
 			c.Process()
 
+			if err := c.RecoverGaps(ctx); err != nil {
+				log.WithError(err).Errorf("%s trade gap recovery error", c.Symbol)
+			}
+
 		case <-c.orderSig:
 			c.Process()
 
@@ -235,3 +502,14 @@ func (c *TradeCollector) Run(ctx context.Context) {
 		}
 	}
 }
+
+// sortTrades sorts the trades by the trade time and the trade id
+func sortTrades(trades []types.Trade) {
+	sort.Slice(trades, func(i, j int) bool {
+		ti, tj := trades[i].Time.Time(), trades[j].Time.Time()
+		if ti.Equal(tj) {
+			return trades[i].ID < trades[j].ID
+		}
+		return ti.Before(tj)
+	})
+}
@@ -1,12 +1,16 @@
 package bbgo
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
 )
 
 func TestTradeCollector_ShouldNotCountDuplicatedTrade(t *testing.T) {
@@ -63,3 +67,213 @@ func TestTradeCollector_ShouldNotCountDuplicatedTrade(t *testing.T) {
 	assert.False(t, matched, "the same trade should not match")
 	assert.Equal(t, 0, len(collector.tradeStore.Trades()), "the same trade should not be added to the trade store")
 }
+
+type testFeePriceSource map[string]fixedpoint.Value
+
+func (s testFeePriceSource) FeePrice(feeCurrency, quoteCurrency string, _ time.Time) (fixedpoint.Value, bool) {
+	price, ok := s[feeCurrency+quoteCurrency]
+	return price, ok
+}
+
+func TestTradeCollector_FeePriceSource(t *testing.T) {
+	symbol := "BTCUSDT"
+	position := types.NewPosition(symbol, "BTC", "USDT")
+	orderStore := NewOrderStore(symbol)
+	collector := NewTradeCollector(symbol, position, orderStore)
+	collector.SetFeePriceSource(testFeePriceSource{"BNBUSDT": fixedpoint.NewFromInt(500)})
+
+	profitStats := types.NewProfitStats(types.Market{Symbol: symbol, BaseCurrency: "BTC", QuoteCurrency: "USDT"})
+	collector.OnTrade(func(trade types.Trade, profit, netProfit fixedpoint.Value) {
+		profitStats.AddTrade(trade)
+	})
+
+	orderStore.Add(types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol:   symbol,
+			Side:     types.SideTypeBuy,
+			Type:     types.OrderTypeLimit,
+			Quantity: fixedpoint.One,
+			Price:    fixedpoint.NewFromInt(40000),
+		},
+		Exchange: types.ExchangeBinance,
+		OrderID:  399,
+		Status:   types.OrderStatusFilled,
+	})
+
+	matched := collector.ProcessTrade(types.Trade{
+		ID:            1,
+		OrderID:       399,
+		Exchange:      types.ExchangeBinance,
+		Price:         fixedpoint.NewFromInt(40000),
+		Quantity:      fixedpoint.One,
+		QuoteQuantity: fixedpoint.NewFromInt(40000),
+		Symbol:        symbol,
+		Side:          types.SideTypeBuy,
+		IsBuyer:       true,
+		Fee:           fixedpoint.NewFromFloat(0.08),
+		FeeCurrency:   "BNB",
+	})
+	assert.True(t, matched)
+	assert.Equal(t, "40", position.TotalFeeInQuote.String())
+	assert.Equal(t, "40040", position.ApproximateAverageCost.String())
+	assert.Equal(t, "40", profitStats.AccumulatedFee.String())
+	assert.Equal(t, "40", profitStats.TodayFee.String())
+}
+
+func newTestCollectorTrade(id, orderID uint64, side types.SideType, price, quantity int64, tradeTime time.Time) types.Trade {
+	return types.Trade{
+		ID:            id,
+		OrderID:       orderID,
+		Exchange:      types.ExchangeBinance,
+		Price:         fixedpoint.NewFromInt(price),
+		Quantity:      fixedpoint.NewFromInt(quantity),
+		QuoteQuantity: fixedpoint.NewFromInt(price * quantity),
+		Symbol:        "BTCUSDT",
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		Time:          types.Time(tradeTime),
+	}
+}
+
+func newTestCollectorOrder(orderID uint64, side types.SideType, executedQuantity int64, creationTime time.Time) types.Order {
+	return types.Order{
+		SubmitOrder: types.SubmitOrder{
+			Symbol:   "BTCUSDT",
+			Side:     side,
+			Type:     types.OrderTypeMarket,
+			Quantity: fixedpoint.NewFromInt(executedQuantity),
+		},
+		Exchange:         types.ExchangeBinance,
+		OrderID:          orderID,
+		Status:           types.OrderStatusFilled,
+		ExecutedQuantity: fixedpoint.NewFromInt(executedQuantity),
+		CreationTime:     types.Time(creationTime),
+	}
+}
+
+func TestTradeCollector_DuplicatedTradeIsSkipped(t *testing.T) {
+	symbol := "BTCUSDT"
+	position := types.NewPosition(symbol, "BTC", "USDT")
+	orderStore := NewOrderStore(symbol)
+	collector := NewTradeCollector(symbol, position, orderStore)
+
+	now := time.Now()
+	orderStore.Add(newTestCollectorOrder(1, types.SideTypeBuy, 1, now))
+
+	trade := newTestCollectorTrade(1, 1, types.SideTypeBuy, 40000, 1, now)
+	assert.True(t, collector.ProcessTrade(trade))
+	assert.False(t, collector.ProcessTrade(trade), "the duplicated trade should be skipped")
+	assert.False(t, collector.ProcessTrade(trade), "the duplicated trade should not block the collector")
+	assert.Equal(t, "1", position.Base.String())
+}
+
+func TestTradeCollector_ReorderDelay(t *testing.T) {
+	symbol := "BTCUSDT"
+	position := types.NewPosition(symbol, "BTC", "USDT")
+	orderStore := NewOrderStore(symbol)
+	collector := NewTradeCollector(symbol, position, orderStore)
+
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	collector.now = func() time.Time { return now }
+	collector.SetReorderDelay(time.Second)
+
+	var tradeIDs []uint64
+	collector.OnTrade(func(trade types.Trade, profit, netProfit fixedpoint.Value) {
+		tradeIDs = append(tradeIDs, trade.ID)
+	})
+
+	orderStore.Add(
+		newTestCollectorOrder(1, types.SideTypeBuy, 1, now),
+		newTestCollectorOrder(2, types.SideTypeSell, 1, now),
+	)
+
+	// the sell trade is received before the buy trade
+	assert.True(t, collector.ProcessTrade(newTestCollectorTrade(2, 2, types.SideTypeSell, 41000, 1, now.Add(time.Second))))
+	assert.True(t, collector.ProcessTrade(newTestCollectorTrade(1, 1, types.SideTypeBuy, 40000, 1, now)))
+	assert.False(t, collector.ProcessTrade(newTestCollectorTrade(1, 1, types.SideTypeBuy, 40000, 1, now)), "the held trade should not be added again")
+	assert.Empty(t, tradeIDs, "the trades are held for the reorder delay")
+
+	now = now.Add(time.Second)
+	assert.True(t, collector.Process())
+	assert.Equal(t, []uint64{1, 2}, tradeIDs, "the trades are applied by the trade time")
+	assert.Equal(t, "0", position.Base.String())
+	assert.Equal(t, "1000", position.AccumulatedProfit.String())
+}
+
+func TestTradeCollector_PositionUpdateHandlerProcessesTrades(t *testing.T) {
+	symbol := "BTCUSDT"
+	position := types.NewPosition(symbol, "BTC", "USDT")
+	orderStore := NewOrderStore(symbol)
+	collector := NewTradeCollector(symbol, position, orderStore)
+
+	now := time.Now()
+	orderStore.Add(newTestCollectorOrder(1, types.SideTypeBuy, 1, now))
+
+	// the handler submits an exit order, the order callback of the executor processes the trades again
+	var updates int
+	collector.OnPositionUpdate(func(position *types.Position) {
+		updates++
+		collector.Process()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.True(t, collector.ProcessTrade(newTestCollectorTrade(1, 1, types.SideTypeBuy, 40000, 1, now)))
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("the position update handler is blocked by the collector lock")
+	}
+
+	assert.Equal(t, 1, updates)
+	assert.Equal(t, "1", position.Base.String())
+}
+
+func TestTradeCollector_RecoverGaps(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	symbol := "BTCUSDT"
+	position := types.NewPosition(symbol, "BTC", "USDT")
+	orderStore := NewOrderStore(symbol)
+	collector := NewTradeCollector(symbol, position, orderStore)
+
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	collector.now = func() time.Time { return now }
+
+	mockService := mocks.NewMockExchangeTradeHistoryService(mockCtrl)
+	collector.EnableGapRecovery(mockService, 5*time.Second)
+
+	// the order was created before the gap recovery is enabled
+	orderStore.Add(newTestCollectorOrder(1, types.SideTypeBuy, 1, now.Add(-time.Minute)))
+
+	// the order is filled with 2 trades, but only the first trade is received from the websocket
+	orderStore.Add(newTestCollectorOrder(2, types.SideTypeBuy, 2, now))
+	assert.True(t, collector.ProcessTrade(newTestCollectorTrade(10, 2, types.SideTypeBuy, 40000, 1, now)))
+
+	// the gap is detected but not timed out yet
+	assert.NoError(t, collector.RecoverGaps(context.Background()))
+
+	now = now.Add(5 * time.Second)
+	mockService.EXPECT().QueryTrades(gomock.Any(), symbol, gomock.Any()).Return([]types.Trade{
+		newTestCollectorTrade(9, 1, types.SideTypeBuy, 39000, 1, now.Add(-time.Minute)),
+		newTestCollectorTrade(10, 2, types.SideTypeBuy, 40000, 1, now.Add(-5*time.Second)),
+		newTestCollectorTrade(11, 2, types.SideTypeBuy, 40000, 1, now.Add(-4*time.Second)),
+	}, nil).Times(1)
+
+	var recovered []uint64
+	collector.OnRecover(func(trade types.Trade) {
+		recovered = append(recovered, trade.ID)
+	})
+
+	assert.NoError(t, collector.RecoverGaps(context.Background()))
+	assert.Equal(t, []uint64{11}, recovered, "only the missing trade of the gap order is recovered")
+	assert.Equal(t, "2", position.Base.String())
+
+	// the gap is closed
+	now = now.Add(5 * time.Second)
+	assert.NoError(t, collector.RecoverGaps(context.Background()))
+}
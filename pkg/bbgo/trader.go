@@ -2,6 +2,7 @@ package bbgo
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"sync"
@@ -13,6 +14,7 @@ import (
 
 	"github.com/c9s/bbgo/pkg/dynamic"
 	"github.com/c9s/bbgo/pkg/interact"
+	"github.com/c9s/bbgo/pkg/service"
 )
 
 // Strategy method calls:
@@ -88,6 +90,57 @@ type Trader struct {
 	crossExchangeStrategies []CrossExchangeStrategy
 	exchangeStrategies      map[string][]SingleExchangeStrategy
 
+	// liveTradingGuard is the config of the dry-run warm-up period of the newly added strategy instances
+	liveTradingGuard *LiveTradingGuardConfig
+
+	// liveStrategies are the strategies that are acknowledged to trade with real funds
+	liveStrategies map[StrategyID]struct{}
+
+	// dryRunSessions are the sessions used by the strategy instances in the warm-up period
+	dryRunSessions      map[sessionStrategyKey]*ExchangeSession
+	dryRunCrossSessions map[CrossExchangeStrategy]map[string]*ExchangeSession
+
+	// shadowTrading is the config of the shadow instances
+	shadowTrading *ShadowTradingConfig
+
+	// shadowStrategies are the shadow instances running with the simulated fills
+	shadowStrategies []*shadowStrategy
+
+	// childStrategies are the strategy instances started at runtime, spawned by the other strategies or restarted.
+	// childStrategiesMutex also guards the stopped strategy instances,
+	// and the changes of the strategies and the strategy guards after the trader is started.
+	childStrategiesMutex sync.Mutex
+	childStrategies      map[string]*childStrategy
+	stoppedStrategies    map[string]*StrategyInstance
+	marketDataConnected  bool
+
+	// strategyConfigs are the raw configs of the configured strategies, they are the base configs of the restarts
+	strategyConfigs map[StrategyID]json.RawMessage
+
+	// runCtx is the trading context, the restarted strategy instances run with it
+	runCtx context.Context
+
+	// profitLocks are the profit lock configs of the strategies
+	profitLocks map[StrategyID]*ProfitLockConfig
+
+	// positionNetting nets the positions of the strategy instances trading the same symbol on the same session
+	positionNetting *PositionNettingService
+
+	// messageBus is the publish/subscribe bus between the strategy instances
+	messageBus *MessageBus
+
+	// webhookSignals dispatches the signal events received by the webhook to the strategy instances
+	webhookSignals *WebhookSignalService
+
+	// coordinator holds the leases of the strategy instances in the multi-process deployment
+	coordinator *Coordinator
+
+	// panicRecovery isolates the panics of the strategy instances, the guarded sessions are given to the guarded instances
+	panicRecovery        *PanicRecoveryConfig
+	strategyGuards       map[string]*StrategyGuard
+	guardedSessions      map[sessionStrategyKey]guardedSession
+	guardedCrossSessions map[CrossExchangeStrategy]map[string]*ExchangeSession
+
 	// gracefulShutdown is used for registering strategy's Shutdown calls
 	// when strategy implements Shutdown(ctx), the func ref will be stored in the callback.
 	gracefulShutdown GracefulShutdown
@@ -116,12 +169,95 @@ func (trader *Trader) Configure(userConfig *Config) error {
 		trader.SetRiskControls(userConfig.RiskControls)
 	}
 
+	if userConfig.LiveTradingGuard != nil {
+		trader.SetLiveTradingGuard(userConfig.LiveTradingGuard)
+	}
+
+	if userConfig.ShadowTrading != nil {
+		trader.SetShadowTrading(userConfig.ShadowTrading)
+	}
+
+	if userConfig.PanicRecovery != nil {
+		trader.SetPanicRecovery(userConfig.PanicRecovery)
+	}
+
+	if userConfig.PositionNetting != nil {
+		trader.SetPositionNetting(NewPositionNettingService(userConfig.PositionNetting))
+	}
+
+	if userConfig.MessageBus != nil {
+		if err := userConfig.MessageBus.Validate(); err != nil {
+			return err
+		}
+
+		trader.SetMessageBus(NewMessageBus(userConfig.MessageBus))
+	}
+
+	if userConfig.WebhookSignals != nil {
+		if err := userConfig.WebhookSignals.Validate(); err != nil {
+			return err
+		}
+
+		trader.SetWebhookSignals(NewWebhookSignalService(userConfig.WebhookSignals))
+	}
+
+	if userConfig.Coordinator != nil {
+		redisConfig := userConfig.Coordinator.Redis
+		if redisConfig == nil && userConfig.Persistence != nil {
+			redisConfig = userConfig.Persistence.Redis
+		}
+
+		if redisConfig == nil {
+			return errors.New("coordinator: redis is required, please configure coordinator.redis or persistence.redis")
+		}
+
+		if userConfig.Persistence == nil || (userConfig.Persistence.Redis == nil && userConfig.Persistence.Postgres == nil) {
+			log.Warnf("coordinator: the persistence is not shared by the processes, the strategy states will not be taken over")
+		}
+
+		trader.SetCoordinator(NewCoordinator(userConfig.Coordinator, service.NewRedisLeaseService(redisConfig)))
+	}
+
 	for _, entry := range userConfig.ExchangeStrategies {
+		if entry.Live {
+			trader.AcknowledgeLive(entry.Strategy)
+		}
+
+		if entry.ProfitLock != nil {
+			if err := entry.ProfitLock.Validate(); err != nil {
+				return err
+			}
+
+			trader.SetProfitLock(entry.Strategy, entry.ProfitLock)
+		}
+
+		if rawConfig, ok := userConfig.strategyConfigs[entry.Strategy]; ok {
+			if trader.strategyConfigs == nil {
+				trader.strategyConfigs = make(map[StrategyID]json.RawMessage)
+			}
+
+			trader.strategyConfigs[entry.Strategy] = rawConfig
+		}
+
 		for _, mount := range entry.Mounts {
 			log.Infof("attaching strategy %T on %s...", entry.Strategy, mount)
 			if err := trader.AttachStrategyOn(mount, entry.Strategy); err != nil {
 				return err
 			}
+
+			if entry.Shadow == nil {
+				continue
+			}
+
+			shadow, err := newShadowStrategy(entry.Strategy.ID(), userConfig.strategyConfigs[entry.Strategy], entry.Shadow)
+			if err != nil {
+				return errors.Wrapf(err, "failed to create the shadow instance of %s", entry.Strategy.ID())
+			}
+
+			log.Infof("attaching shadow instance of strategy %T on %s...", entry.Strategy, mount)
+			if err := trader.AttachShadowStrategy(mount, entry.Strategy, shadow); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -162,6 +298,56 @@ func (trader *Trader) AttachCrossExchangeStrategy(strategy CrossExchangeStrategy
 	return trader
 }
 
+// SetPositionNetting sets the position netting service, the service is injected into the strategies
+// and the positions of the strategy instances are registered after the strategies are started.
+func (trader *Trader) SetPositionNetting(service *PositionNettingService) {
+	trader.positionNetting = service
+}
+
+// PositionNetting returns the position netting service, it's nil if the position netting is not enabled
+func (trader *Trader) PositionNetting() *PositionNettingService {
+	return trader.positionNetting
+}
+
+// SetMessageBus sets the message bus, the bus is injected into the strategies with a *MessageBus field
+func (trader *Trader) SetMessageBus(bus *MessageBus) {
+	trader.messageBus = bus
+}
+
+// MessageBus returns the message bus, it's nil if the message bus is not configured
+func (trader *Trader) MessageBus() *MessageBus {
+	return trader.messageBus
+}
+
+// SetWebhookSignals sets the webhook signal service, the service is injected into the strategies with a *WebhookSignalService field
+func (trader *Trader) SetWebhookSignals(service *WebhookSignalService) {
+	trader.webhookSignals = service
+}
+
+// WebhookSignals returns the webhook signal service, it's nil if the webhook signals are not enabled
+func (trader *Trader) WebhookSignals() *WebhookSignalService {
+	return trader.webhookSignals
+}
+
+// registerNettingPositions registers the positions of the single exchange strategy instances to the position netting service
+func (trader *Trader) registerNettingPositions(instances ...*StrategyInstance) {
+	if trader.positionNetting == nil {
+		return
+	}
+
+	for _, instance := range instances {
+		if instance.Session == "" {
+			continue
+		}
+
+		for _, position := range instance.Positions() {
+			if trader.positionNetting.Register(instance.Session, instance.ID, position) {
+				log.Infof("registered %s position of %s to the position netting", position.Symbol, instance.ID)
+			}
+		}
+	}
+}
+
 // SetRiskControls sets the risk controller
 // TODO: provide a more DSL way to configure risk controls
 func (trader *Trader) SetRiskControls(riskControls *RiskControls) {
@@ -179,6 +365,13 @@ func (trader *Trader) RunSingleExchangeStrategy(ctx context.Context, strategy Si
 		trader.gracefulShutdown.OnShutdown(shutdown.Shutdown)
 	}
 
+	// the panic of the guarded strategy is recovered by the strategy guard of the session
+	if session.guard != nil {
+		return session.guard.Run(func() error {
+			return strategy.Run(ctx, orderExecutor, session)
+		})
+	}
+
 	return strategy.Run(ctx, orderExecutor, session)
 }
 
@@ -206,9 +399,8 @@ func (trader *Trader) getSessionOrderExecutor(sessionName string) OrderExecutor
 func (trader *Trader) RunAllSingleExchangeStrategy(ctx context.Context) error {
 	// load and run Session strategies
 	for sessionName, strategies := range trader.exchangeStrategies {
-		var session = trader.environment.sessions[sessionName]
-		var orderExecutor = trader.getSessionOrderExecutor(sessionName)
 		for _, strategy := range strategies {
+			session, orderExecutor := trader.strategySession(sessionName, strategy)
 			if err := trader.RunSingleExchangeStrategy(ctx, strategy, session, orderExecutor); err != nil {
 				return err
 			}
@@ -221,76 +413,11 @@ func (trader *Trader) RunAllSingleExchangeStrategy(ctx context.Context) error {
 func (trader *Trader) injectFieldsAndSubscribe(ctx context.Context) error {
 	// load and run Session strategies
 	for sessionName, strategies := range trader.exchangeStrategies {
-		var session = trader.environment.sessions[sessionName]
-		var orderExecutor = trader.getSessionOrderExecutor(sessionName)
 		for _, strategy := range strategies {
-			rs := reflect.ValueOf(strategy)
-
-			// get the struct element
-			rs = rs.Elem()
-
-			if rs.Kind() != reflect.Struct {
-				return errors.New("strategy object is not a struct")
-			}
-
-			if err := trader.injectCommonServices(ctx, strategy); err != nil {
+			session, orderExecutor := trader.strategySession(sessionName, strategy)
+			if err := trader.injectSingleExchangeStrategy(ctx, sessionName, session, orderExecutor, strategy); err != nil {
 				return err
 			}
-
-			if err := dynamic.InjectField(rs, "OrderExecutor", orderExecutor, false); err != nil {
-				return errors.Wrapf(err, "failed to inject OrderExecutor on %T", strategy)
-			}
-
-			if defaulter, ok := strategy.(StrategyDefaulter); ok {
-				if err := defaulter.Defaults(); err != nil {
-					panic(err)
-				}
-			}
-
-			if initializer, ok := strategy.(StrategyInitializer); ok {
-				if err := initializer.Initialize(); err != nil {
-					panic(err)
-				}
-			}
-
-			if subscriber, ok := strategy.(ExchangeSessionSubscriber); ok {
-				subscriber.Subscribe(session)
-			} else {
-				log.Errorf("strategy %s does not implement ExchangeSessionSubscriber", strategy.ID())
-			}
-
-			if symbol, ok := dynamic.LookupSymbolField(rs); ok {
-				log.Infof("found symbol %s based strategy from %s", symbol, rs.Type())
-
-				if err := session.initSymbol(ctx, trader.environment, symbol); err != nil {
-					return errors.Wrapf(err, "failed to inject object into %T when initSymbol", strategy)
-				}
-
-				market, ok := session.Market(symbol)
-				if !ok {
-					return fmt.Errorf("market of symbol %s not found", symbol)
-				}
-
-				indicatorSet := session.StandardIndicatorSet(symbol)
-				if !ok {
-					return fmt.Errorf("standardIndicatorSet of symbol %s not found", symbol)
-				}
-
-				store, ok := session.MarketDataStore(symbol)
-				if !ok {
-					return fmt.Errorf("marketDataStore of symbol %s not found", symbol)
-				}
-
-				if err := dynamic.ParseStructAndInject(strategy,
-					market,
-					session,
-					session.OrderExecutor,
-					indicatorSet,
-					store,
-				); err != nil {
-					return errors.Wrapf(err, "failed to inject object into %T", strategy)
-				}
-			}
 		}
 	}
 
@@ -307,6 +434,15 @@ func (trader *Trader) injectFieldsAndSubscribe(ctx context.Context) error {
 			return err
 		}
 
+		instanceID := dynamic.CallID(strategy)
+		if len(instanceID) == 0 {
+			instanceID = strategy.ID()
+		}
+
+		if err := dynamic.ParseStructAndInject(strategy, trader.newStrategySpawner(instanceID, strategy)); err != nil {
+			return errors.Wrapf(err, "failed to inject StrategySpawner on %T", strategy)
+		}
+
 		if defaulter, ok := strategy.(StrategyDefaulter); ok {
 			if err := defaulter.Defaults(); err != nil {
 				return err
@@ -320,7 +456,16 @@ func (trader *Trader) injectFieldsAndSubscribe(ctx context.Context) error {
 		}
 
 		if subscriber, ok := strategy.(CrossExchangeSessionSubscriber); ok {
-			subscriber.CrossSubscribe(trader.environment.sessions)
+			sessions := trader.crossStrategySessions(strategy)
+			for _, session := range sessions {
+				session.subscriber = instanceID
+			}
+
+			subscriber.CrossSubscribe(sessions)
+
+			for _, session := range sessions {
+				session.subscriber = ""
+			}
 		} else {
 			log.Errorf("strategy %s does not implement CrossExchangeSessionSubscriber", strategy.ID())
 		}
@@ -329,16 +474,119 @@ func (trader *Trader) injectFieldsAndSubscribe(ctx context.Context) error {
 	return nil
 }
 
+// injectSingleExchangeStrategy injects the fields of the single exchange strategy, calls Defaults and Initialize,
+// and collects the subscriptions of the strategy.
+func (trader *Trader) injectSingleExchangeStrategy(ctx context.Context, sessionName string, session *ExchangeSession, orderExecutor OrderExecutor, strategy SingleExchangeStrategy) error {
+	rs := reflect.ValueOf(strategy)
+
+	// get the struct element
+	rs = rs.Elem()
+
+	if rs.Kind() != reflect.Struct {
+		return errors.New("strategy object is not a struct")
+	}
+
+	if err := trader.injectCommonServices(ctx, strategy); err != nil {
+		return err
+	}
+
+	if err := dynamic.InjectField(rs, "OrderExecutor", orderExecutor, false); err != nil {
+		return errors.Wrapf(err, "failed to inject OrderExecutor on %T", strategy)
+	}
+
+	instanceID := sessionName + "." + strategy.ID()
+	if signature, err := getStrategySignature(strategy); err == nil {
+		instanceID = sessionName + "." + signature
+	}
+
+	if err := dynamic.ParseStructAndInject(strategy, trader.newStrategySpawner(instanceID, strategy)); err != nil {
+		return errors.Wrapf(err, "failed to inject StrategySpawner on %T", strategy)
+	}
+
+	if defaulter, ok := strategy.(StrategyDefaulter); ok {
+		if err := defaulter.Defaults(); err != nil {
+			panic(err)
+		}
+	}
+
+	if initializer, ok := strategy.(StrategyInitializer); ok {
+		if err := initializer.Initialize(); err != nil {
+			panic(err)
+		}
+	}
+
+	if subscriber, ok := strategy.(ExchangeSessionSubscriber); ok {
+		session.subscribeAs(instanceID, func() {
+			subscriber.Subscribe(session)
+		})
+	} else {
+		log.Errorf("strategy %s does not implement ExchangeSessionSubscriber", strategy.ID())
+	}
+
+	if symbol, ok := dynamic.LookupSymbolField(rs); ok {
+		log.Infof("found symbol %s based strategy from %s", symbol, rs.Type())
+
+		if err := session.initSymbol(ctx, trader.environment, symbol); err != nil {
+			return errors.Wrapf(err, "failed to inject object into %T when initSymbol", strategy)
+		}
+
+		market, ok := session.Market(symbol)
+		if !ok {
+			return fmt.Errorf("market of symbol %s not found", symbol)
+		}
+
+		indicatorSet := session.StandardIndicatorSet(symbol)
+		if !ok {
+			return fmt.Errorf("standardIndicatorSet of symbol %s not found", symbol)
+		}
+
+		store, ok := session.MarketDataStore(symbol)
+		if !ok {
+			return fmt.Errorf("marketDataStore of symbol %s not found", symbol)
+		}
+
+		if err := dynamic.ParseStructAndInject(strategy,
+			market,
+			session,
+			session.OrderExecutor,
+			indicatorSet,
+			store,
+		); err != nil {
+			return errors.Wrapf(err, "failed to inject object into %T", strategy)
+		}
+	}
+
+	return nil
+}
+
 func (trader *Trader) Run(ctx context.Context) error {
 	// before we start the interaction,
 	// register the core interaction, because we can only get the strategies in this scope
 	// trader.environment.Connect will call interact.Start
 	interact.AddCustomInteraction(NewCoreInteraction(trader.environment, trader))
 
+	trader.runCtx = ctx
+
+	if err := trader.applyCoordinator(ctx); err != nil {
+		return err
+	}
+
+	if err := trader.applyLiveTradingGuard(ctx); err != nil {
+		return err
+	}
+
+	if err := trader.applyPanicRecovery(ctx); err != nil {
+		return err
+	}
+
 	if err := trader.injectFieldsAndSubscribe(ctx); err != nil {
 		return err
 	}
 
+	if err := trader.injectShadowStrategies(ctx); err != nil {
+		return err
+	}
+
 	if err := trader.environment.Start(ctx); err != nil {
 		return err
 	}
@@ -347,6 +595,10 @@ func (trader *Trader) Run(ctx context.Context) error {
 		return err
 	}
 
+	if err := trader.runShadowStrategies(); err != nil {
+		return err
+	}
+
 	router := &ExchangeOrderExecutionRouter{
 		sessions:  trader.environment.sessions,
 		executors: make(map[string]OrderExecutor),
@@ -357,12 +609,52 @@ func (trader *Trader) Run(ctx context.Context) error {
 	}
 
 	for _, strategy := range trader.crossExchangeStrategies {
-		if err := strategy.CrossRun(ctx, router, trader.environment.sessions); err != nil {
+		sessions := trader.crossStrategySessions(strategy)
+		strategyRouter := router
+		if _, ok := trader.dryRunCrossSessions[strategy]; ok {
+			strategyRouter = &ExchangeOrderExecutionRouter{
+				sessions:  sessions,
+				executors: make(map[string]OrderExecutor),
+			}
+			for sessionID, session := range sessions {
+				strategyRouter.executors[sessionID] = session.OrderExecutor
+			}
+		}
+
+		crossRun := func() error {
+			return strategy.CrossRun(ctx, strategyRouter, sessions)
+		}
+
+		if guard, ok := trader.crossStrategyGuard(strategy); ok {
+			if err := guard.Run(crossRun); err != nil {
+				return err
+			}
+		} else if err := crossRun(); err != nil {
 			return err
 		}
 	}
 
-	return trader.environment.Connect(ctx)
+	if trader.positionNetting != nil {
+		instances, err := trader.StrategyInstances()
+		if err != nil {
+			return err
+		}
+
+		trader.registerNettingPositions(instances...)
+	}
+
+	if err := trader.runProfitLocks(ctx); err != nil {
+		return err
+	}
+
+	if err := trader.environment.Connect(ctx); err != nil {
+		return err
+	}
+
+	trader.childStrategiesMutex.Lock()
+	trader.marketDataConnected = true
+	trader.childStrategiesMutex.Unlock()
+	return nil
 }
 
 func (trader *Trader) LoadState(ctx context.Context) error {
@@ -385,6 +677,10 @@ func (trader *Trader) LoadState(ctx context.Context) error {
 func (trader *Trader) IterateStrategies(f func(st StrategyID) error) error {
 	for _, strategies := range trader.exchangeStrategies {
 		for _, strategy := range strategies {
+			if trader.isStrategyStopped(strategy) {
+				continue
+			}
+
 			if err := f(strategy); err != nil {
 				return err
 			}
@@ -392,11 +688,21 @@ func (trader *Trader) IterateStrategies(f func(st StrategyID) error) error {
 	}
 
 	for _, strategy := range trader.crossExchangeStrategies {
+		if trader.isStrategyStopped(strategy) {
+			continue
+		}
+
 		if err := f(strategy); err != nil {
 			return err
 		}
 	}
 
+	for _, instance := range trader.childStrategyInstances() {
+		if err := f(instance.Strategy); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -453,6 +759,34 @@ func (trader *Trader) injectCommonServices(ctx context.Context, s interface{}) e
 		}
 	}
 
+	// the shadow instances don't share the positions and the messages with the live instances
+	if trader.isShadowStrategy(s) {
+		return dynamic.ParseStructAndInject(s,
+			&trader.logger,
+			Notification,
+			trader.environment,
+			ps,
+		)
+	}
+
+	if trader.positionNetting != nil {
+		if err := dynamic.ParseStructAndInject(s, trader.positionNetting); err != nil {
+			return err
+		}
+	}
+
+	if trader.messageBus != nil {
+		if err := dynamic.ParseStructAndInject(s, trader.messageBus); err != nil {
+			return err
+		}
+	}
+
+	if trader.webhookSignals != nil {
+		if err := dynamic.ParseStructAndInject(s, trader.webhookSignals); err != nil {
+			return err
+		}
+	}
+
 	return dynamic.ParseStructAndInject(s,
 		&trader.logger,
 		Notification,
@@ -0,0 +1,103 @@
+package bbgo
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// TreasuryReport is the reconciliation of the spot balances of the exchange with the recorded flows,
+// the balances of the spot sessions on the same exchange are summed up since the flows are recorded by exchange.
+type TreasuryReport struct {
+	Exchange types.ExchangeName            `json:"exchange"`
+	Sessions []string                      `json:"sessions"`
+	Assets   []service.TreasuryAssetReport `json:"assets"`
+}
+
+// treasurySessions returns the private spot sessions grouped by the exchange name, the sessions are sorted by name
+func (environ *Environment) treasurySessions() map[types.ExchangeName][]*ExchangeSession {
+	var names []string
+	for name := range environ.sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sessions := make(map[types.ExchangeName][]*ExchangeSession)
+	for _, name := range names {
+		session := environ.sessions[name]
+		if session.PublicOnly || session.Margin || session.IsolatedMargin || session.Futures || session.IsolatedFutures {
+			continue
+		}
+
+		sessions[session.ExchangeName] = append(sessions[session.ExchangeName], session)
+	}
+
+	return sessions
+}
+
+// SyncTransferHistory syncs the deposit and the withdraw history of all the exchanges since the given time
+func (environ *Environment) SyncTransferHistory(ctx context.Context, since time.Time) error {
+	if environ.SyncService == nil {
+		return nil
+	}
+
+	environ.syncMutex.Lock()
+	defer environ.syncMutex.Unlock()
+
+	return environ.syncTransferHistory(ctx, since)
+}
+
+func (environ *Environment) syncTransferHistory(ctx context.Context, since time.Time) error {
+	for exchangeName, sessions := range environ.treasurySessions() {
+		// the records are stored by exchange, syncing one session of the exchange is enough
+		if err := environ.SyncService.SyncTransferHistory(ctx, sessions[0].Exchange, since); err != nil {
+			return fmt.Errorf("%s transfer history sync error: %w", exchangeName, err)
+		}
+	}
+
+	return nil
+}
+
+// TreasuryReport reconciles the spot balances of the exchanges with the recorded deposits, withdrawals, trades and rewards,
+// the reports are sorted by the exchange name.
+func (environ *Environment) TreasuryReport(ctx context.Context) ([]TreasuryReport, error) {
+	if environ.TreasuryService == nil {
+		return nil, fmt.Errorf("treasury service is not configured, the database is required")
+	}
+
+	var reports []TreasuryReport
+	for exchangeName, sessions := range environ.treasurySessions() {
+		report := TreasuryReport{Exchange: exchangeName}
+		balances := types.BalanceMap{}
+		markets := types.MarketMap{}
+		for _, session := range sessions {
+			report.Sessions = append(report.Sessions, session.Name)
+
+			if account := session.GetAccount(); account != nil {
+				balances = balances.Add(account.Balances())
+			}
+
+			for symbol, market := range session.Markets() {
+				markets[symbol] = market
+			}
+		}
+
+		assets, err := environ.TreasuryService.Report(ctx, exchangeName, balances, markets)
+		if err != nil {
+			return nil, err
+		}
+
+		report.Assets = assets
+		reports = append(reports, report)
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Exchange < reports[j].Exchange
+	})
+
+	return reports, nil
+}
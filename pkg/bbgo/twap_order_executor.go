@@ -14,6 +14,11 @@ import (
 	"github.com/c9s/bbgo/pkg/types"
 )
 
+// TwapExecution executes the target quantity with the maker orders on the best price slice by slice.
+// The execution can be paused and resumed, the rest quantity is completed with a taker order after the deadline,
+// and the fill report of each slice is emitted when the slice order is closed.
+//
+//go:generate callbackgen -type TwapExecution
 type TwapExecution struct {
 	Session        *ExchangeSession
 	Symbol         string
@@ -45,7 +50,16 @@ type TwapExecution struct {
 
 	stoppedC chan struct{}
 
-	state int
+	paused bool
+
+	// takerOrder is the taker order submitted for the forced completion after the deadline
+	takerOrder *types.Order
+
+	// slices are the slice orders by the order ID, sliceOrderIDs keeps the submission order
+	slices        map[uint64]*TwapSliceReport
+	sliceOrderIDs []uint64
+
+	sliceReportCallbacks []func(report TwapSliceReport)
 
 	mu sync.Mutex
 }
@@ -172,20 +186,6 @@ func (e *TwapExecution) newBestPriceOrder() (orderForm types.SubmitOrder, err er
 		}
 	}
 
-	if e.DeadlineTime != emptyTime {
-		now := time.Now()
-		if now.After(e.DeadlineTime) {
-			orderForm = types.SubmitOrder{
-				Symbol:   e.Symbol,
-				Side:     e.Side,
-				Type:     types.OrderTypeMarket,
-				Quantity: restQuantity,
-				Market:   e.market,
-			}
-			return orderForm, nil
-		}
-	}
-
 	orderForm = types.SubmitOrder{
 		// ClientOrderID:    "",
 		Symbol:      e.Symbol,
@@ -200,6 +200,14 @@ func (e *TwapExecution) newBestPriceOrder() (orderForm types.SubmitOrder, err er
 }
 
 func (e *TwapExecution) updateOrder(ctx context.Context) error {
+	if e.IsPaused() {
+		return nil
+	}
+
+	if e.deadlineExceeded(time.Now()) {
+		return e.forceComplete(ctx)
+	}
+
 	book := e.orderBook.Copy()
 	sideBook := book.SideBook(e.Side)
 
@@ -279,9 +287,91 @@ func (e *TwapExecution) updateOrder(ctx context.Context) error {
 
 	e.activeMakerOrders.Add(createdOrders...)
 	e.orderStore.Add(createdOrders...)
+	e.addSlices(false, createdOrders...)
+	return nil
+}
+
+func (e *TwapExecution) deadlineExceeded(now time.Time) bool {
+	return e.DeadlineTime != emptyTime && now.After(e.DeadlineTime)
+}
+
+// forceComplete cancels the maker orders and completes the rest quantity with a taker order
+func (e *TwapExecution) forceComplete(ctx context.Context) error {
+	e.mu.Lock()
+	takerOrder := e.takerOrder
+	e.mu.Unlock()
+
+	// the taker order is still working
+	if takerOrder != nil && e.activeMakerOrders.Exists(*takerOrder) {
+		return nil
+	}
+
+	e.cancelActiveOrders()
+
+	restQuantity := e.TargetQuantity.Sub(e.position.GetBase().Abs())
+	if restQuantity.Compare(e.market.MinQuantity) < 0 {
+		if restQuantity.Sign() > 0 {
+			log.Warnf("the rest quantity %s is less than the min quantity %s, can not complete the execution", restQuantity.String(), e.market.MinQuantity.String())
+		}
+
+		e.cancelExecution()
+		return nil
+	}
+
+	log.Infof("deadline %s exceeded, submitting the taker order for the rest quantity %s", e.DeadlineTime, restQuantity.String())
+
+	createdOrders, err := e.Session.OrderExecutor.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:   e.Symbol,
+		Side:     e.Side,
+		Type:     types.OrderTypeMarket,
+		Quantity: restQuantity,
+		Market:   e.market,
+	})
+	if err != nil {
+		return err
+	}
+
+	e.activeMakerOrders.Add(createdOrders...)
+	e.orderStore.Add(createdOrders...)
+	e.addSlices(true, createdOrders...)
+
+	if len(createdOrders) > 0 {
+		e.mu.Lock()
+		e.takerOrder = &createdOrders[0]
+		e.mu.Unlock()
+	}
+
 	return nil
 }
 
+// Pause cancels the active orders and stops placing new orders until the execution is resumed,
+// the deadline completion is also suspended while the execution is paused.
+func (e *TwapExecution) Pause() {
+	e.mu.Lock()
+	e.paused = true
+	e.mu.Unlock()
+
+	log.Infof("pausing %s twap execution", e.Symbol)
+	if e.activeMakerOrders != nil {
+		e.cancelActiveOrders()
+	}
+}
+
+// Resume continues placing the orders, the rest quantity is completed right away if the deadline is exceeded
+func (e *TwapExecution) Resume() {
+	e.mu.Lock()
+	e.paused = false
+	e.mu.Unlock()
+
+	log.Infof("resuming %s twap execution", e.Symbol)
+}
+
+func (e *TwapExecution) IsPaused() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.paused
+}
+
 func (e *TwapExecution) cancelActiveOrders() {
 	gracefulCtx, gracefulCancel := context.WithTimeout(context.TODO(), 30*time.Second)
 	defer gracefulCancel()
@@ -293,6 +383,14 @@ func (e *TwapExecution) orderUpdater(ctx context.Context) {
 	ticker := time.NewTimer(e.UpdateInterval)
 	defer ticker.Stop()
 
+	// the deadline timer completes the rest quantity even if the order book is not changed
+	var deadlineC <-chan time.Time
+	if e.DeadlineTime != emptyTime {
+		deadlineTimer := time.NewTimer(time.Until(e.DeadlineTime))
+		defer deadlineTimer.Stop()
+		deadlineC = deadlineTimer.C
+	}
+
 	// we should stop updater and clean up our open orders, if
 	// 1. the given context is canceled.
 	// 2. the base quantity equals to or greater than the target quantity
@@ -321,6 +419,15 @@ func (e *TwapExecution) orderUpdater(ctx context.Context) {
 				log.WithError(err).Errorf("order update failed")
 			}
 
+		case <-deadlineC:
+			if e.cancelContextIfTargetQuantityFilled() {
+				return
+			}
+
+			if err := e.updateOrder(ctx); err != nil {
+				log.WithError(err).Errorf("order update failed")
+			}
+
 		case <-ticker.C:
 			if !updateLimiter.Allow() {
 				break
@@ -362,11 +469,13 @@ func (e *TwapExecution) handleTradeUpdate(trade types.Trade) {
 	log.Info(trade.String())
 
 	e.position.AddTrade(trade)
+	e.updateSlice(trade)
 	log.Infof("position updated: %+v", e.position)
 }
 
 func (e *TwapExecution) handleFilledOrder(order types.Order) {
 	log.Info(order.String())
+	e.closeSlice(order)
 
 	// filled event triggers the order removal from the active order store
 	// we need to ensure we received every order update event before the execution is done.
@@ -376,6 +485,7 @@ func (e *TwapExecution) handleFilledOrder(order types.Order) {
 func (e *TwapExecution) Run(parentCtx context.Context) error {
 	e.mu.Lock()
 	e.stoppedC = make(chan struct{})
+	e.slices = make(map[uint64]*TwapSliceReport)
 	e.executionCtx, e.cancelExecution = context.WithCancel(parentCtx)
 	e.userDataStreamCtx, e.cancelUserDataStream = context.WithCancel(context.Background())
 	e.mu.Unlock()
@@ -410,6 +520,7 @@ func (e *TwapExecution) Run(parentCtx context.Context) error {
 	e.orderStore.BindStream(e.userDataStream)
 	e.activeMakerOrders = NewActiveOrderBook(e.Symbol)
 	e.activeMakerOrders.OnFilled(e.handleFilledOrder)
+	e.activeMakerOrders.OnCanceled(e.closeSlice)
 	e.activeMakerOrders.BindStream(e.userDataStream)
 
 	go e.connectUserData(e.userDataStreamCtx)
@@ -0,0 +1,154 @@
+package bbgo
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// TwapSliceReport is the fill report of one slice order of the twap execution
+type TwapSliceReport struct {
+	OrderID  uint64           `json:"orderID"`
+	Price    fixedpoint.Value `json:"price"`
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	ExecutedQuantity fixedpoint.Value `json:"executedQuantity"`
+	QuoteQuantity    fixedpoint.Value `json:"quoteQuantity"`
+	AveragePrice     fixedpoint.Value `json:"averagePrice"`
+	Fee              fixedpoint.Value `json:"fee"`
+	FeeCurrency      string           `json:"feeCurrency"`
+
+	// Taker is true for the taker order submitted after the deadline
+	Taker bool `json:"taker"`
+
+	Status    types.OrderStatus `json:"status"`
+	CreatedAt time.Time         `json:"createdAt"`
+	ClosedAt  time.Time         `json:"closedAt,omitempty"`
+
+	// tradeQuantity is the quantity of the received trades, the order update could arrive before the trade updates
+	tradeQuantity fixedpoint.Value
+}
+
+func (r TwapSliceReport) String() string {
+	kind := "maker"
+	if r.Taker {
+		kind = "taker"
+	}
+
+	return fmt.Sprintf("slice #%d %s %s @ %s: executed %s/%s, average price %s",
+		r.OrderID, kind, r.Status, r.Price.String(), r.ExecutedQuantity.String(), r.Quantity.String(), r.AveragePrice.String())
+}
+
+// TwapExecutionReport is the progress of the twap execution
+type TwapExecutionReport struct {
+	Symbol         string           `json:"symbol"`
+	Side           types.SideType   `json:"side"`
+	TargetQuantity fixedpoint.Value `json:"targetQuantity"`
+	FilledQuantity fixedpoint.Value `json:"filledQuantity"`
+	AveragePrice   fixedpoint.Value `json:"averagePrice"`
+	Paused         bool             `json:"paused"`
+
+	Slices []TwapSliceReport `json:"slices"`
+}
+
+func (r TwapExecutionReport) String() string {
+	return fmt.Sprintf("%s %s twap execution: filled %s/%s, average price %s, %d slices",
+		r.Symbol, r.Side, r.FilledQuantity.String(), r.TargetQuantity.String(), r.AveragePrice.String(), len(r.Slices))
+}
+
+// Report returns the progress and the slice reports of the execution
+func (e *TwapExecution) Report() TwapExecutionReport {
+	report := TwapExecutionReport{
+		Symbol:         e.Symbol,
+		Side:           e.Side,
+		TargetQuantity: e.TargetQuantity,
+	}
+
+	if e.position != nil {
+		report.FilledQuantity = e.position.GetBase().Abs()
+		report.AveragePrice = e.position.AverageCost
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	report.Paused = e.paused
+	for _, orderID := range e.sliceOrderIDs {
+		report.Slices = append(report.Slices, *e.slices[orderID])
+	}
+
+	return report
+}
+
+func (e *TwapExecution) addSlices(taker bool, orders ...types.Order) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, order := range orders {
+		if _, ok := e.slices[order.OrderID]; ok {
+			continue
+		}
+
+		createdAt := order.CreationTime.Time()
+		if createdAt.IsZero() {
+			createdAt = time.Now()
+		}
+
+		e.slices[order.OrderID] = &TwapSliceReport{
+			OrderID:   order.OrderID,
+			Price:     order.Price,
+			Quantity:  order.Quantity,
+			Taker:     taker,
+			Status:    order.Status,
+			CreatedAt: createdAt,
+		}
+		e.sliceOrderIDs = append(e.sliceOrderIDs, order.OrderID)
+	}
+}
+
+func (e *TwapExecution) updateSlice(trade types.Trade) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	slice, ok := e.slices[trade.OrderID]
+	if !ok {
+		return
+	}
+
+	slice.tradeQuantity = slice.tradeQuantity.Add(trade.Quantity)
+	slice.ExecutedQuantity = fixedpoint.Max(slice.ExecutedQuantity, slice.tradeQuantity)
+	slice.QuoteQuantity = slice.QuoteQuantity.Add(trade.QuoteQuantity)
+	slice.AveragePrice = slice.QuoteQuantity.Div(slice.tradeQuantity)
+	slice.Fee = slice.Fee.Add(trade.Fee)
+	slice.FeeCurrency = trade.FeeCurrency
+}
+
+// closeSlice emits the slice report when the slice order is filled or canceled
+func (e *TwapExecution) closeSlice(order types.Order) {
+	e.mu.Lock()
+	slice, ok := e.slices[order.OrderID]
+	if !ok || !slice.ClosedAt.IsZero() {
+		e.mu.Unlock()
+		return
+	}
+
+	slice.Status = order.Status
+	slice.ClosedAt = time.Now()
+
+	if order.ExecutedQuantity.Compare(slice.ExecutedQuantity) > 0 {
+		slice.ExecutedQuantity = order.ExecutedQuantity
+		if slice.AveragePrice.IsZero() {
+			slice.AveragePrice = order.Price
+			if order.AveragePrice.Sign() > 0 {
+				slice.AveragePrice = order.AveragePrice
+			}
+		}
+	}
+
+	report := *slice
+	e.mu.Unlock()
+
+	e.EmitSliceReport(report)
+}
@@ -0,0 +1,80 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestTwapExecution_SliceReports(t *testing.T) {
+	number := fixedpoint.MustNewFromString
+	execution := &TwapExecution{
+		Symbol:         "BTCUSDT",
+		Side:           types.SideTypeBuy,
+		TargetQuantity: number("2"),
+		DeadlineTime:   time.Now().Add(time.Minute),
+		slices:         make(map[uint64]*TwapSliceReport),
+	}
+
+	var reports []TwapSliceReport
+	execution.OnSliceReport(func(report TwapSliceReport) {
+		reports = append(reports, report)
+	})
+
+	execution.addSlices(false, types.Order{
+		SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Price: number("100"), Quantity: number("1")},
+		OrderID:     1,
+		Status:      types.OrderStatusNew,
+	})
+	execution.addSlices(true, types.Order{
+		SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Type: types.OrderTypeMarket, Quantity: number("1")},
+		OrderID:     2,
+		Status:      types.OrderStatusNew,
+	})
+
+	execution.updateSlice(types.Trade{OrderID: 1, Quantity: number("0.4"), QuoteQuantity: number("40"), Fee: number("0.01"), FeeCurrency: "USDT"})
+	execution.updateSlice(types.Trade{OrderID: 1, Quantity: number("0.6"), QuoteQuantity: number("60.6"), Fee: number("0.01"), FeeCurrency: "USDT"})
+	execution.closeSlice(types.Order{OrderID: 1, Status: types.OrderStatusFilled, ExecutedQuantity: number("1")})
+
+	// the order update arrives before the trade update
+	execution.closeSlice(types.Order{
+		SubmitOrder:      types.SubmitOrder{AveragePrice: number("102")},
+		OrderID:          2,
+		Status:           types.OrderStatusFilled,
+		ExecutedQuantity: number("1"),
+	})
+	execution.updateSlice(types.Trade{OrderID: 2, Quantity: number("1"), QuoteQuantity: number("101")})
+
+	// the closed slice is reported once
+	execution.closeSlice(types.Order{OrderID: 1, Status: types.OrderStatusFilled, ExecutedQuantity: number("1")})
+
+	if assert.Len(t, reports, 2) {
+		assert.Equal(t, "1", reports[0].ExecutedQuantity.String())
+		assert.Equal(t, "100.6", reports[0].AveragePrice.String())
+		assert.Equal(t, "0.02", reports[0].Fee.String())
+		assert.False(t, reports[0].Taker)
+
+		assert.Equal(t, "102", reports[1].AveragePrice.String())
+		assert.True(t, reports[1].Taker)
+	}
+
+	report := execution.Report()
+	if assert.Len(t, report.Slices, 2) {
+		assert.Equal(t, "1", report.Slices[1].ExecutedQuantity.String())
+		assert.Equal(t, "101", report.Slices[1].AveragePrice.String())
+	}
+
+	execution.Pause()
+	assert.True(t, execution.IsPaused())
+	assert.True(t, execution.Report().Paused)
+
+	execution.Resume()
+	assert.False(t, execution.IsPaused())
+
+	assert.False(t, execution.deadlineExceeded(time.Now()))
+	assert.True(t, execution.deadlineExceeded(time.Now().Add(2*time.Minute)))
+}
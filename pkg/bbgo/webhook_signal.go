@@ -0,0 +1,277 @@
+package bbgo
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var ErrInvalidWebhookSecret = errors.New("invalid webhook secret")
+
+// WebhookSignalConfig enables the webhook endpoint POST /api/webhook/signals on the http server,
+// the endpoint accepts the TradingView-style alerts and routes the converted signal events to the subscribed strategies.
+//
+//	webhookSignals:
+//	  secret: "my-shared-secret"
+type WebhookSignalConfig struct {
+	// Secret is the shared secret of the alerts, it's loaded from the env var WEBHOOK_SIGNAL_SECRET when it's empty.
+	// The secret is sent in the "secret" field of the alert message, or the X-Webhook-Secret header.
+	Secret string `json:"secret,omitempty" yaml:"secret,omitempty"`
+
+	// MaxSignalAge drops the alerts that are older than the age by the alert time, 0 to disable the check
+	MaxSignalAge types.Duration `json:"maxSignalAge,omitempty" yaml:"maxSignalAge,omitempty"`
+}
+
+func (c *WebhookSignalConfig) Validate() error {
+	if c.Secret == "" {
+		c.Secret = os.Getenv("WEBHOOK_SIGNAL_SECRET")
+	}
+
+	if c.Secret == "" {
+		return errors.New("webhookSignals: secret is required, set the secret or the env var WEBHOOK_SIGNAL_SECRET")
+	}
+
+	return nil
+}
+
+// SignalAction is the action of the signal event
+type SignalAction string
+
+const (
+	SignalActionBuy   SignalAction = "buy"
+	SignalActionSell  SignalAction = "sell"
+	SignalActionClose SignalAction = "close"
+)
+
+// SignalEvent is the typed signal converted from the external alerts
+type SignalEvent struct {
+	Source   string           `json:"source"`
+	Name     string           `json:"name,omitempty"`
+	Exchange string           `json:"exchange,omitempty"`
+	Symbol   string           `json:"symbol"`
+	Action   SignalAction     `json:"action"`
+	Price    fixedpoint.Value `json:"price,omitempty"`
+	Quantity fixedpoint.Value `json:"quantity,omitempty"`
+	Comment  string           `json:"comment,omitempty"`
+	Time     time.Time        `json:"time"`
+}
+
+func (e SignalEvent) String() string {
+	s := fmt.Sprintf("%s signal %s %s", e.Source, e.Action, e.Symbol)
+	if e.Name != "" {
+		s += " (" + e.Name + ")"
+	}
+
+	if e.Quantity.Sign() > 0 {
+		s += " quantity " + e.Quantity.String()
+	}
+
+	if e.Price.Sign() > 0 {
+		s += " @ " + e.Price.String()
+	}
+
+	return s
+}
+
+// TradingViewAlert is the alert message of TradingView, the message is a JSON template with the placeholders, e.g.,
+//
+//	{
+//	  "secret": "my-shared-secret",
+//	  "signal": "{{strategy.order.id}}",
+//	  "ticker": "{{exchange}}:{{ticker}}",
+//	  "action": "{{strategy.order.action}}",
+//	  "marketPosition": "{{strategy.market_position}}",
+//	  "price": "{{close}}",
+//	  "quantity": "{{strategy.order.contracts}}",
+//	  "time": "{{timenow}}"
+//	}
+type TradingViewAlert struct {
+	Secret         string           `json:"secret"`
+	Signal         string           `json:"signal"`
+	Exchange       string           `json:"exchange"`
+	Ticker         string           `json:"ticker"`
+	Action         string           `json:"action"`
+	MarketPosition string           `json:"marketPosition"`
+	Price          fixedpoint.Value `json:"price"`
+	Quantity       fixedpoint.Value `json:"quantity"`
+	Comment        string           `json:"comment"`
+	Time           string           `json:"time"`
+}
+
+// SignalFilter selects the signal events of the subscriber, the empty field matches any value
+type SignalFilter struct {
+	Symbol string
+	Name   string
+}
+
+func (f SignalFilter) Match(e SignalEvent) bool {
+	return (f.Symbol == "" || f.Symbol == e.Symbol) && (f.Name == "" || f.Name == e.Name)
+}
+
+// SignalHandler handles the signal event, it's called by the webhook request, so the handler should not block
+type SignalHandler func(e SignalEvent)
+
+type signalSubscriber struct {
+	consumer string
+	filter   SignalFilter
+	handler  SignalHandler
+}
+
+// WebhookSignalService authenticates and converts the webhook alerts into the signal events,
+// and dispatches the signal events to the subscribed strategy instances.
+type WebhookSignalService struct {
+	secret       string
+	maxSignalAge time.Duration
+
+	mu          sync.Mutex
+	subscribers []signalSubscriber
+}
+
+func NewWebhookSignalService(config *WebhookSignalConfig) *WebhookSignalService {
+	return &WebhookSignalService{
+		secret:       config.Secret,
+		maxSignalAge: config.MaxSignalAge.Duration(),
+	}
+}
+
+func (s *WebhookSignalService) authenticate(secret string) bool {
+	return s.secret != "" && subtle.ConstantTimeCompare([]byte(s.secret), []byte(secret)) == 1
+}
+
+// ParseTradingViewAlert validates the secret and converts the alert payload into the signal event,
+// headerSecret is used when the payload does not carry the secret.
+func (s *WebhookSignalService) ParseTradingViewAlert(payload []byte, headerSecret string, now time.Time) (*SignalEvent, error) {
+	var alert TradingViewAlert
+	if err := json.Unmarshal(payload, &alert); err != nil {
+		return nil, fmt.Errorf("unable to parse the alert message: %w", err)
+	}
+
+	secret := alert.Secret
+	if secret == "" {
+		secret = headerSecret
+	}
+
+	if !s.authenticate(secret) {
+		return nil, ErrInvalidWebhookSecret
+	}
+
+	exchange, symbol := parseTradingViewTicker(alert.Ticker)
+	if alert.Exchange != "" {
+		exchange = strings.ToLower(alert.Exchange)
+	}
+
+	if symbol == "" {
+		return nil, errors.New("ticker is required")
+	}
+
+	action, err := parseTradingViewAction(alert.Action, alert.MarketPosition)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &SignalEvent{
+		Source:   "tradingview",
+		Name:     alert.Signal,
+		Exchange: exchange,
+		Symbol:   symbol,
+		Action:   action,
+		Price:    alert.Price,
+		Quantity: alert.Quantity,
+		Comment:  alert.Comment,
+		Time:     now,
+	}
+
+	if alert.Time != "" {
+		t, err := time.Parse(time.RFC3339, alert.Time)
+		if err != nil {
+			return nil, fmt.Errorf("invalid alert time %q: %w", alert.Time, err)
+		}
+
+		event.Time = t
+	}
+
+	if s.maxSignalAge > 0 && now.Sub(event.Time) > s.maxSignalAge {
+		return nil, fmt.Errorf("the alert is expired, alert time %s is older than %s", event.Time, s.maxSignalAge)
+	}
+
+	return event, nil
+}
+
+// Dispatch delivers the signal event to the matched subscribers, it returns the number of the delivered subscribers
+func (s *WebhookSignalService) Dispatch(e SignalEvent) int {
+	s.mu.Lock()
+	var handlers []SignalHandler
+	for _, subscriber := range s.subscribers {
+		if subscriber.filter.Match(e) {
+			handlers = append(handlers, subscriber.handler)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, handler := range handlers {
+		handler(e)
+	}
+
+	return len(handlers)
+}
+
+// Subscribe registers the signal handler of the consumer (the strategy instance ID)
+func (s *WebhookSignalService) Subscribe(consumer string, filter SignalFilter, handler SignalHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers = append(s.subscribers, signalSubscriber{consumer: consumer, filter: filter, handler: handler})
+}
+
+// Unsubscribe removes all the signal handlers of the consumer
+func (s *WebhookSignalService) Unsubscribe(consumer string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	subscribers := s.subscribers[:0]
+	for _, subscriber := range s.subscribers {
+		if subscriber.consumer != consumer {
+			subscribers = append(subscribers, subscriber)
+		}
+	}
+	s.subscribers = subscribers
+}
+
+// parseTradingViewTicker splits the TradingView ticker, e.g., BINANCE:BTCUSDT.P to binance and BTCUSDT
+func parseTradingViewTicker(ticker string) (exchange, symbol string) {
+	symbol = strings.ToUpper(strings.TrimSpace(ticker))
+	if i := strings.Index(symbol, ":"); i >= 0 {
+		exchange = strings.ToLower(symbol[:i])
+		symbol = symbol[i+1:]
+	}
+
+	// the perpetual contracts are suffixed with .P
+	symbol = strings.TrimSuffix(symbol, ".P")
+	symbol = strings.NewReplacer("/", "", "-", "", "_", "").Replace(symbol)
+	return exchange, symbol
+}
+
+// parseTradingViewAction converts the order action, the flat market position closes the position
+func parseTradingViewAction(action, marketPosition string) (SignalAction, error) {
+	if strings.EqualFold(marketPosition, "flat") {
+		return SignalActionClose, nil
+	}
+
+	switch strings.ToLower(action) {
+	case "buy", "long":
+		return SignalActionBuy, nil
+	case "sell", "short":
+		return SignalActionSell, nil
+	case "close", "exit", "flat":
+		return SignalActionClose, nil
+	}
+
+	return "", fmt.Errorf("invalid alert action %q, valid actions are: buy, sell, close", action)
+}
@@ -0,0 +1,84 @@
+package bbgo
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestWebhookSignalService_ParseTradingViewAlert(t *testing.T) {
+	service := NewWebhookSignalService(&WebhookSignalConfig{
+		Secret:       "s3cret",
+		MaxSignalAge: types.Duration(time.Minute),
+	})
+
+	now := time.Date(2023, 6, 1, 0, 0, 30, 0, time.UTC)
+
+	t.Run("buy", func(t *testing.T) {
+		payload := `{"secret":"s3cret","signal":"breakout","ticker":"BINANCE:BTCUSDT.P","action":"buy","marketPosition":"long","price":27000.5,"quantity":"0.01","time":"2023-06-01T00:00:00Z"}`
+		event, err := service.ParseTradingViewAlert([]byte(payload), "", now)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "tradingview", event.Source)
+			assert.Equal(t, "breakout", event.Name)
+			assert.Equal(t, "binance", event.Exchange)
+			assert.Equal(t, "BTCUSDT", event.Symbol)
+			assert.Equal(t, SignalActionBuy, event.Action)
+			assert.Equal(t, "27000.5", event.Price.String())
+			assert.Equal(t, "0.01", event.Quantity.String())
+		}
+	})
+
+	t.Run("flat market position closes the position", func(t *testing.T) {
+		payload := `{"ticker":"ETH/USDT","action":"sell","marketPosition":"flat"}`
+		event, err := service.ParseTradingViewAlert([]byte(payload), "s3cret", now)
+		if assert.NoError(t, err) {
+			assert.Equal(t, "ETHUSDT", event.Symbol)
+			assert.Equal(t, SignalActionClose, event.Action)
+			assert.Equal(t, now, event.Time)
+		}
+	})
+
+	t.Run("invalid secret", func(t *testing.T) {
+		_, err := service.ParseTradingViewAlert([]byte(`{"secret":"wrong","ticker":"BTCUSDT","action":"buy"}`), "", now)
+		assert.ErrorIs(t, err, ErrInvalidWebhookSecret)
+
+		_, err = service.ParseTradingViewAlert([]byte(`{"ticker":"BTCUSDT","action":"buy"}`), "", now)
+		assert.ErrorIs(t, err, ErrInvalidWebhookSecret)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		_, err := service.ParseTradingViewAlert([]byte(`{"secret":"s3cret","ticker":"BTCUSDT","action":"buy","time":"2023-05-31T23:58:00Z"}`), "", now)
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid action", func(t *testing.T) {
+		_, err := service.ParseTradingViewAlert([]byte(`{"secret":"s3cret","ticker":"BTCUSDT","action":"hold"}`), "", now)
+		assert.Error(t, err)
+	})
+}
+
+func TestWebhookSignalService_Dispatch(t *testing.T) {
+	service := NewWebhookSignalService(&WebhookSignalConfig{Secret: "s3cret"})
+
+	var btc, breakout []SignalEvent
+	service.Subscribe("signalexec:BTCUSDT", SignalFilter{Symbol: "BTCUSDT"}, func(e SignalEvent) {
+		btc = append(btc, e)
+	})
+	service.Subscribe("signalexec:BTCUSDT:breakout", SignalFilter{Symbol: "BTCUSDT", Name: "breakout"}, func(e SignalEvent) {
+		breakout = append(breakout, e)
+	})
+
+	assert.Equal(t, 2, service.Dispatch(SignalEvent{Symbol: "BTCUSDT", Name: "breakout", Action: SignalActionBuy}))
+	assert.Equal(t, 1, service.Dispatch(SignalEvent{Symbol: "BTCUSDT", Name: "reversal", Action: SignalActionSell}))
+	assert.Equal(t, 0, service.Dispatch(SignalEvent{Symbol: "ETHUSDT", Action: SignalActionBuy}))
+	assert.Len(t, btc, 2)
+	assert.Len(t, breakout, 1)
+
+	service.Unsubscribe("signalexec:BTCUSDT")
+	assert.Equal(t, 1, service.Dispatch(SignalEvent{Symbol: "BTCUSDT", Name: "breakout", Action: SignalActionClose}))
+	assert.Len(t, btc, 2)
+	assert.Len(t, breakout, 2)
+}
@@ -37,6 +37,9 @@ func init() {
 	BacktestCmd.Flags().Bool("sync-only", false, "sync backtest data only, do not run backtest")
 	BacktestCmd.Flags().String("sync-from", "", "sync backtest data from the given time, which will override the time range in the backtest config")
 	BacktestCmd.Flags().String("sync-exchange", "", "specify only one exchange to sync backtest data")
+	BacktestCmd.Flags().Int("sync-concurrency", 1, "the number of the symbol intervals to sync at the same time")
+	BacktestCmd.Flags().Uint64("sync-retry", 3, "the max retry times of a failed symbol interval sync")
+	BacktestCmd.Flags().Bool("repair", false, "find the missing time ranges in the synced data and sync them again")
 	BacktestCmd.Flags().String("session", "", "specify only one exchange session to run backtest")
 
 	BacktestCmd.Flags().Bool("verify", false, "verify the kline back-test data")
@@ -125,6 +128,22 @@ var BacktestCmd = &cobra.Command{
 			return err
 		}
 
+		syncOptions := service.BacktestSyncOptions{}
+		syncOptions.Concurrency, err = cmd.Flags().GetInt("sync-concurrency")
+		if err != nil {
+			return err
+		}
+
+		syncOptions.MaxRetries, err = cmd.Flags().GetUint64("sync-retry")
+		if err != nil {
+			return err
+		}
+
+		syncOptions.Repair, err = cmd.Flags().GetBool("repair")
+		if err != nil {
+			return err
+		}
+
 		userConfig, err := bbgo.Load(configFile, true)
 		if err != nil {
 			return err
@@ -134,6 +153,10 @@ var BacktestCmd = &cobra.Command{
 			return errors.New("backtest config is not defined")
 		}
 
+		if err := bbgo.ValidateConfig(userConfig); err != nil {
+			return err
+		}
+
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
 
@@ -216,7 +239,7 @@ var BacktestCmd = &cobra.Command{
 
 		if wantSync {
 			log.Infof("starting synchronization: %v", userConfig.Backtest.Symbols)
-			if err := sync(ctx, userConfig, backtestService, sourceExchanges, syncFromTime, endTime); err != nil {
+			if err := sync(ctx, userConfig, backtestService, sourceExchanges, syncFromTime, endTime, syncOptions); err != nil {
 				return err
 			}
 			log.Info("synchronization done")
@@ -248,324 +271,400 @@ var BacktestCmd = &cobra.Command{
 			}
 		}
 
-		if verboseCnt == 2 {
-			log.SetLevel(log.DebugLevel)
-		} else if verboseCnt > 0 {
-			log.SetLevel(log.InfoLevel)
-		} else {
-			// default mode, disable strategy logging and order executor logging
-			log.SetLevel(log.ErrorLevel)
-		}
-
-		environ.SetStartTime(startTime)
-
-		// exchangeNameStr is the session name.
-		for name, sourceExchange := range sourceExchanges {
-			backtestExchange, err := backtest.NewExchange(sourceExchange.Name(), sourceExchange, backtestService, userConfig.Backtest)
-			if err != nil {
-				return errors.Wrap(err, "failed to create backtest exchange")
-			}
-			session := environ.AddExchange(name.String(), backtestExchange)
-			exchangeFromConfig := userConfig.Sessions[name.String()]
-			if exchangeFromConfig != nil {
-				session.UseHeikinAshi = exchangeFromConfig.UseHeikinAshi
+		var runID = userConfig.GetSignature() + "_" + uuid.NewString()
+		var reportDir = outputDirectory
+		if generatingReport && reportFileInSubDir {
+			// reportDir = filepath.Join(reportDir, backtestSessionName)
+			reportDir = filepath.Join(reportDir, runID)
+		}
+
+		// runScenario runs the backtest with the given config and the given environment,
+		// the scenario is empty for the balances defined in the account config
+		runScenario := func(ctx context.Context, environ *bbgo.Environment, userConfig *bbgo.Config, scenario string, reportDir string) (*backtest.SummaryReport, error) {
+			if verboseCnt == 2 {
+				log.SetLevel(log.DebugLevel)
+			} else if verboseCnt > 0 {
+				log.SetLevel(log.InfoLevel)
+			} else {
+				// default mode, disable strategy logging and order executor logging
+				log.SetLevel(log.ErrorLevel)
 			}
-		}
 
-		if err := environ.Init(ctx); err != nil {
-			return err
-		}
+			environ.SetStartTime(startTime)
 
-		for _, session := range environ.Sessions() {
-			userDataStream := session.UserDataStream.(types.StandardStreamEmitter)
-			backtestEx := session.Exchange.(*backtest.Exchange)
-			backtestEx.MarketDataStream = session.MarketDataStream.(types.StandardStreamEmitter)
-			backtestEx.BindUserData(userDataStream)
-		}
-
-		trader := bbgo.NewTrader(environ)
-		if verboseCnt == 0 {
-			trader.DisableLogging()
-		}
-
-		if err := trader.Configure(userConfig); err != nil {
-			return err
-		}
-
-		if err := trader.Run(ctx); err != nil {
-			return err
-		}
+			// exchangeNameStr is the session name.
+			for name, sourceExchange := range sourceExchanges {
+				backtestExchange, err := backtest.NewExchange(sourceExchange.Name(), sourceExchange, backtestService, userConfig.Backtest)
+				if err != nil {
+					return nil, errors.Wrap(err, "failed to create backtest exchange")
+				}
+				session := environ.AddExchange(name.String(), backtestExchange)
+				exchangeFromConfig := userConfig.Sessions[name.String()]
+				if exchangeFromConfig != nil {
+					session.UseHeikinAshi = exchangeFromConfig.UseHeikinAshi
+				}
+			}
 
-		allKLineIntervals, requiredInterval, backTestIntervals := backtest.CollectSubscriptionIntervals(environ)
-		exchangeSources, err := backtest.InitializeExchangeSources(environ.Sessions(), startTime, endTime, requiredInterval, backTestIntervals...)
-		if err != nil {
-			return err
-		}
+			if err := environ.Init(ctx); err != nil {
+				return nil, err
+			}
 
-		var kLineHandlers []func(k types.KLine, exSource *backtest.ExchangeDataSource)
-		var manifests backtest.Manifests
-		var runID = userConfig.GetSignature() + "_" + uuid.NewString()
-		var reportDir = outputDirectory
-		var sessionTradeStats = make(map[string]map[string]*types.TradeStats)
-
-		var tradeCollectorList []*bbgo.TradeCollector
-		for _, exSource := range exchangeSources {
-			sessionName := exSource.Session.Name
-			tradeStatsMap := make(map[string]*types.TradeStats)
-			for usedSymbol := range exSource.Session.Positions() {
-				market, _ := exSource.Session.Market(usedSymbol)
-				position := types.NewPositionFromMarket(market)
-				orderStore := bbgo.NewOrderStore(usedSymbol)
-				orderStore.AddOrderUpdate = true
-				tradeCollector := bbgo.NewTradeCollector(usedSymbol, position, orderStore)
-
-				tradeStats := types.NewTradeStats(usedSymbol)
-				tradeStats.SetIntervalProfitCollector(types.NewIntervalProfitCollector(types.Interval1d, startTime))
-				tradeCollector.OnProfit(func(trade types.Trade, profit *types.Profit) {
-					if profit == nil {
-						return
-					}
-					tradeStats.Add(profit)
-				})
-				tradeStatsMap[usedSymbol] = tradeStats
+			// the strategy clock is driven by the kline time, the clock is bound before the strategies,
+			// so that the strategies see the end time of the kline in the kline callbacks
+			clock := bbgo.NewSimulatedClock(startTime)
+			environ.SetClock(clock)
 
-				orderStore.BindStream(exSource.Session.UserDataStream)
-				tradeCollector.BindStream(exSource.Session.UserDataStream)
-				tradeCollectorList = append(tradeCollectorList, tradeCollector)
+			for _, session := range environ.Sessions() {
+				userDataStream := session.UserDataStream.(types.StandardStreamEmitter)
+				backtestEx := session.Exchange.(*backtest.Exchange)
+				backtestEx.MarketDataStream = session.MarketDataStream.(types.StandardStreamEmitter)
+				backtestEx.BindUserData(userDataStream)
+				clock.BindStream(session.MarketDataStream)
 			}
-			sessionTradeStats[sessionName] = tradeStatsMap
-		}
-		kLineHandlers = append(kLineHandlers, func(k types.KLine, _ *backtest.ExchangeDataSource) {
-			if k.Interval == types.Interval1d && k.Closed {
-				for _, collector := range tradeCollectorList {
-					collector.Process()
-				}
-			}
-		})
 
-		if generatingReport {
-			if reportFileInSubDir {
-				// reportDir = filepath.Join(reportDir, backtestSessionName)
-				reportDir = filepath.Join(reportDir, runID)
-			}
-			if err := util.SafeMkdirAll(reportDir); err != nil {
-				return err
+			trader := bbgo.NewTrader(environ)
+			if verboseCnt == 0 {
+				trader.DisableLogging()
 			}
 
-			startTimeStr := startTime.Format("20060102")
-			endTimeStr := endTime.Format("20060102")
-			kLineSubDir := strings.Join([]string{"klines", "_", startTimeStr, "-", endTimeStr}, "")
-			kLineDataDir := filepath.Join(outputDirectory, "shared", kLineSubDir)
-			if err := util.SafeMkdirAll(kLineDataDir); err != nil {
-				return err
+			if err := trader.Configure(userConfig); err != nil {
+				return nil, err
 			}
 
-			stateRecorder := backtest.NewStateRecorder(reportDir)
-			err = trader.IterateStrategies(func(st bbgo.StrategyID) error {
-				return stateRecorder.Scan(st.(backtest.Instance))
-			})
-			if err != nil {
-				return err
+			if err := trader.Run(ctx); err != nil {
+				return nil, err
 			}
 
-			manifests = stateRecorder.Manifests()
-			manifests, err = rewriteManifestPaths(manifests, reportDir)
+			allKLineIntervals, requiredInterval, backTestIntervals := backtest.CollectSubscriptionIntervals(environ)
+			exchangeSources, err := backtest.InitializeExchangeSources(environ.Sessions(), startTime, endTime, requiredInterval, backTestIntervals...)
 			if err != nil {
-				return err
+				return nil, err
 			}
 
-			// state snapshot
+			var kLineHandlers []func(k types.KLine, exSource *backtest.ExchangeDataSource)
+			var manifests backtest.Manifests
+			var sessionTradeStats = make(map[string]map[string]*types.TradeStats)
+
+			var tradeCollectorList []*bbgo.TradeCollector
+			for _, exSource := range exchangeSources {
+				sessionName := exSource.Session.Name
+				tradeStatsMap := make(map[string]*types.TradeStats)
+				for usedSymbol := range exSource.Session.Positions() {
+					market, _ := exSource.Session.Market(usedSymbol)
+					position := types.NewPositionFromMarket(market)
+					orderStore := bbgo.NewOrderStore(usedSymbol)
+					orderStore.AddOrderUpdate = true
+					tradeCollector := bbgo.NewTradeCollector(usedSymbol, position, orderStore)
+
+					tradeStats := types.NewTradeStats(usedSymbol)
+					tradeStats.SetIntervalProfitCollector(types.NewIntervalProfitCollector(types.Interval1d, startTime))
+					tradeCollector.OnProfit(func(trade types.Trade, profit *types.Profit) {
+						if profit == nil {
+							return
+						}
+						tradeStats.Add(profit)
+					})
+					tradeStatsMap[usedSymbol] = tradeStats
+
+					orderStore.BindStream(exSource.Session.UserDataStream)
+					tradeCollector.BindStream(exSource.Session.UserDataStream)
+					tradeCollectorList = append(tradeCollectorList, tradeCollector)
+				}
+				sessionTradeStats[sessionName] = tradeStatsMap
+			}
 			kLineHandlers = append(kLineHandlers, func(k types.KLine, _ *backtest.ExchangeDataSource) {
-				// snapshot per 1m
-				if k.Interval == types.Interval1m && k.Closed {
-					if _, err := stateRecorder.Snapshot(); err != nil {
-						log.WithError(err).Errorf("state record failed to snapshot the strategy state")
+				if k.Interval == types.Interval1d && k.Closed {
+					for _, collector := range tradeCollectorList {
+						collector.Process()
 					}
 				}
 			})
 
-			dumper := backtest.NewKLineDumper(kLineDataDir)
-			defer func() {
-				if err := dumper.Close(); err != nil {
-					log.WithError(err).Errorf("kline dumper can not close files")
+			if generatingReport {
+				if err := util.SafeMkdirAll(reportDir); err != nil {
+					return nil, err
 				}
-			}()
 
-			kLineHandlers = append(kLineHandlers, func(k types.KLine, _ *backtest.ExchangeDataSource) {
-				if err := dumper.Record(k); err != nil {
-					log.WithError(err).Errorf("can not write kline to file")
+				startTimeStr := startTime.Format("20060102")
+				endTimeStr := endTime.Format("20060102")
+				kLineSubDir := strings.Join([]string{"klines", "_", startTimeStr, "-", endTimeStr}, "")
+				kLineDataDir := filepath.Join(outputDirectory, "shared", kLineSubDir)
+				if err := util.SafeMkdirAll(kLineDataDir); err != nil {
+					return nil, err
 				}
-			})
 
-			// equity curve recording -- record per 1h kline
-			equityCurveTsv, err := tsv.NewWriterFile(filepath.Join(reportDir, "equity_curve.tsv"))
-			if err != nil {
-				return err
-			}
-			defer func() { _ = equityCurveTsv.Close() }()
+				stateRecorder := backtest.NewStateRecorder(reportDir)
+				err = trader.IterateStrategies(func(st bbgo.StrategyID) error {
+					return stateRecorder.Scan(st.(backtest.Instance))
+				})
+				if err != nil {
+					return nil, err
+				}
 
-			_ = equityCurveTsv.Write([]string{
-				"time",
-				"in_usd",
-			})
-			defer equityCurveTsv.Flush()
+				manifests = stateRecorder.Manifests()
+				manifests, err = rewriteManifestPaths(manifests, reportDir)
+				if err != nil {
+					return nil, err
+				}
 
-			kLineHandlers = append(kLineHandlers, func(k types.KLine, exSource *backtest.ExchangeDataSource) {
-				if k.Interval != types.Interval1h {
-					return
+				// state snapshot
+				kLineHandlers = append(kLineHandlers, func(k types.KLine, _ *backtest.ExchangeDataSource) {
+					// snapshot per 1m
+					if k.Interval == types.Interval1m && k.Closed {
+						if _, err := stateRecorder.Snapshot(); err != nil {
+							log.WithError(err).Errorf("state record failed to snapshot the strategy state")
+						}
+					}
+				})
+
+				// the klines are shared by the scenarios, they are only dumped once
+				if len(scenario) == 0 {
+					dumper := backtest.NewKLineDumper(kLineDataDir)
+					defer func() {
+						if err := dumper.Close(); err != nil {
+							log.WithError(err).Errorf("kline dumper can not close files")
+						}
+					}()
+
+					kLineHandlers = append(kLineHandlers, func(k types.KLine, _ *backtest.ExchangeDataSource) {
+						if err := dumper.Record(k); err != nil {
+							log.WithError(err).Errorf("can not write kline to file")
+						}
+					})
 				}
 
-				balances, err := exSource.Exchange.QueryAccountBalances(ctx)
+				// equity curve recording -- record per 1h kline
+				equityCurveTsv, err := tsv.NewWriterFile(filepath.Join(reportDir, "equity_curve.tsv"))
 				if err != nil {
-					log.WithError(err).Errorf("query back-test account balance error")
-				} else {
-					assets := balances.Assets(exSource.Session.AllLastPrices(), k.EndTime.Time())
-					_ = equityCurveTsv.Write([]string{
-						k.EndTime.Time().Format(time.RFC1123),
-						assets.InUSD().String(),
-					})
+					return nil, err
 				}
-			})
+				defer func() { _ = equityCurveTsv.Close() }()
 
-			ordersTsv, err := tsv.NewWriterFile(filepath.Join(reportDir, "orders.tsv"))
-			if err != nil {
-				return err
-			}
-			defer func() { _ = ordersTsv.Close() }()
-			_ = ordersTsv.Write(types.Order{}.CsvHeader())
+				_ = equityCurveTsv.Write([]string{
+					"time",
+					"in_usd",
+				})
+				defer equityCurveTsv.Flush()
 
-			for _, exSource := range exchangeSources {
-				exSource.Session.UserDataStream.OnOrderUpdate(func(order types.Order) {
-					if order.Status == types.OrderStatusFilled {
-						for _, record := range order.CsvRecords() {
-							_ = ordersTsv.Write(record)
-						}
+				kLineHandlers = append(kLineHandlers, func(k types.KLine, exSource *backtest.ExchangeDataSource) {
+					if k.Interval != types.Interval1h {
+						return
+					}
+
+					balances, err := exSource.Exchange.QueryAccountBalances(ctx)
+					if err != nil {
+						log.WithError(err).Errorf("query back-test account balance error")
+					} else {
+						assets := balances.Assets(exSource.Session.AllLastPrices(), k.EndTime.Time())
+						_ = equityCurveTsv.Write([]string{
+							k.EndTime.Time().Format(time.RFC1123),
+							assets.InUSD().String(),
+						})
 					}
 				})
+
+				ordersTsv, err := tsv.NewWriterFile(filepath.Join(reportDir, "orders.tsv"))
+				if err != nil {
+					return nil, err
+				}
+				defer func() { _ = ordersTsv.Close() }()
+				_ = ordersTsv.Write(types.Order{}.CsvHeader())
+
+				for _, exSource := range exchangeSources {
+					exSource.Session.UserDataStream.OnOrderUpdate(func(order types.Order) {
+						if order.Status == types.OrderStatusFilled {
+							for _, record := range order.CsvRecords() {
+								_ = ordersTsv.Write(record)
+							}
+						}
+					})
+				}
 			}
-		}
 
-		runCtx, cancelRun := context.WithCancel(ctx)
-		for _, exK := range exchangeSources {
-			exK.Callbacks = kLineHandlers
-		}
-		go func() {
-			defer cancelRun()
+			runCtx, cancelRun := context.WithCancel(ctx)
+			for _, exK := range exchangeSources {
+				exK.Callbacks = kLineHandlers
+			}
+			go func() {
+				defer cancelRun()
 
-			// Optimize back-test speed for single exchange source
-			var numOfExchangeSources = len(exchangeSources)
-			if numOfExchangeSources == 1 {
-				exSource := exchangeSources[0]
-				for k := range exSource.C {
-					exSource.Exchange.ConsumeKLine(k, requiredInterval)
-				}
+				// Optimize back-test speed for single exchange source
+				var numOfExchangeSources = len(exchangeSources)
+				if numOfExchangeSources == 1 {
+					exSource := exchangeSources[0]
+					for k := range exSource.C {
+						exSource.Exchange.ConsumeKLine(k, requiredInterval)
+					}
 
-				if err := exSource.Exchange.CloseMarketData(); err != nil {
-					log.WithError(err).Errorf("close market data error")
+					if err := exSource.Exchange.CloseMarketData(); err != nil {
+						log.WithError(err).Errorf("close market data error")
+					}
+					return
 				}
-				return
-			}
 
-		RunMultiExchangeData:
-			for {
-				for _, exK := range exchangeSources {
-					k, more := <-exK.C
-					if !more {
-						if err := exK.Exchange.CloseMarketData(); err != nil {
-							log.WithError(err).Errorf("close market data error")
-							return
+			RunMultiExchangeData:
+				for {
+					for _, exK := range exchangeSources {
+						k, more := <-exK.C
+						if !more {
+							if err := exK.Exchange.CloseMarketData(); err != nil {
+								log.WithError(err).Errorf("close market data error")
+								return
+							}
+							break RunMultiExchangeData
 						}
-						break RunMultiExchangeData
+
+						exK.Exchange.ConsumeKLine(k, requiredInterval)
+					}
+				}
+			}()
+
+			cmdutil.WaitForSignal(runCtx, syscall.SIGINT, syscall.SIGTERM)
+
+			log.Infof("shutting down trader...")
+
+			gracefulShutdownPeriod := 30 * time.Second
+			shtCtx, cancelShutdown := context.WithTimeout(bbgo.NewTodoContextWithExistingIsolation(ctx), gracefulShutdownPeriod)
+			bbgo.Shutdown(shtCtx)
+			cancelShutdown()
+
+			// put the logger back to print the pnl
+			log.SetLevel(log.InfoLevel)
+
+			// aggregate total balances
+			initTotalBalances := types.BalanceMap{}
+			finalTotalBalances := types.BalanceMap{}
+			var sessionNames []string
+			for _, session := range environ.Sessions() {
+				sessionNames = append(sessionNames, session.Name)
+				accountConfig := userConfig.Backtest.GetAccount(session.Name)
+				initBalances := accountConfig.Balances.BalanceMap()
+				initTotalBalances = initTotalBalances.Add(initBalances)
+
+				finalBalances := session.GetAccount().Balances()
+				finalTotalBalances = finalTotalBalances.Add(finalBalances)
+			}
+
+			summaryReport := &backtest.SummaryReport{
+				StartTime:            startTime,
+				EndTime:              endTime,
+				Sessions:             sessionNames,
+				InitialTotalBalances: initTotalBalances,
+				FinalTotalBalances:   finalTotalBalances,
+				Manifests:            manifests,
+				Symbols:              nil,
+				Scenario:             scenario,
+			}
+
+			for interval := range allKLineIntervals {
+				summaryReport.Intervals = append(summaryReport.Intervals, interval)
+			}
+
+			for _, session := range environ.Sessions() {
+				for symbol, trades := range session.Trades {
+					tradeState := sessionTradeStats[session.Name][symbol]
+					profitFactor := tradeState.ProfitFactor
+					winningRatio := tradeState.WinningRatio
+					intervalProfits := tradeState.IntervalProfits[types.Interval1d]
+					symbolReport, err := createSymbolReport(userConfig, session, symbol, trades.Trades, intervalProfits, profitFactor, winningRatio)
+					if err != nil {
+						return nil, err
 					}
 
-					exK.Exchange.ConsumeKLine(k, requiredInterval)
+					summaryReport.Symbols = append(summaryReport.Symbols, symbol)
+					summaryReport.SymbolReports = append(summaryReport.SymbolReports, *symbolReport)
+					summaryReport.TotalProfit = symbolReport.PnL.Profit
+					summaryReport.TotalUnrealizedProfit = symbolReport.PnL.UnrealizedProfit
+					summaryReport.InitialEquityValue = summaryReport.InitialEquityValue.Add(symbolReport.InitialEquityValue())
+					summaryReport.FinalEquityValue = summaryReport.FinalEquityValue.Add(symbolReport.FinalEquityValue())
+					summaryReport.TotalGrossProfit.Add(symbolReport.PnL.GrossProfit)
+					summaryReport.TotalGrossLoss.Add(symbolReport.PnL.GrossLoss)
+
+					// write report to a file
+					if generatingReport {
+						reportFileName := fmt.Sprintf("symbol_report_%s_%s.json", session.Name, symbol)
+						if err := util.WriteJsonFile(filepath.Join(reportDir, reportFileName), &symbolReport); err != nil {
+							return nil, err
+						}
+					}
 				}
 			}
-		}()
 
-		cmdutil.WaitForSignal(runCtx, syscall.SIGINT, syscall.SIGTERM)
+			if generatingReport {
+				summaryReportFile := filepath.Join(reportDir, "summary.json")
 
-		log.Infof("shutting down trader...")
+				// output summary report filepath to stdout, so that our optimizer can read from it
+				fmt.Println(summaryReportFile)
 
-		gracefulShutdownPeriod := 30 * time.Second
-		shtCtx, cancelShutdown := context.WithTimeout(bbgo.NewTodoContextWithExistingIsolation(ctx), gracefulShutdownPeriod)
-		bbgo.Shutdown(shtCtx)
-		cancelShutdown()
+				if err := util.WriteJsonFile(summaryReportFile, summaryReport); err != nil {
+					return nil, errors.Wrapf(err, "can not write summary report json file: %s", summaryReportFile)
+				}
 
-		// put the logger back to print the pnl
-		log.SetLevel(log.InfoLevel)
+				configJsonFile := filepath.Join(reportDir, "config.json")
+				if err := util.WriteJsonFile(configJsonFile, userConfig); err != nil {
+					return nil, errors.Wrapf(err, "can not write config json file: %s", configJsonFile)
+				}
 
-		// aggregate total balances
-		initTotalBalances := types.BalanceMap{}
-		finalTotalBalances := types.BalanceMap{}
-		var sessionNames []string
-		for _, session := range environ.Sessions() {
-			sessionNames = append(sessionNames, session.Name)
-			accountConfig := userConfig.Backtest.GetAccount(session.Name)
-			initBalances := accountConfig.Balances.BalanceMap()
-			initTotalBalances = initTotalBalances.Add(initBalances)
+			} else {
+				color.Green("BACK-TEST REPORT")
+				color.Green("===============================================\n")
+				if len(scenario) > 0 {
+					color.Green("BALANCE SCENARIO: %s\n", scenario)
+				}
+				color.Green("START TIME: %s\n", startTime.Format(time.RFC1123))
+				color.Green("END TIME: %s\n", endTime.Format(time.RFC1123))
+				color.Green("INITIAL TOTAL BALANCE: %v\n", initTotalBalances)
+				color.Green("FINAL TOTAL BALANCE: %v\n", finalTotalBalances)
+				for _, symbolReport := range summaryReport.SymbolReports {
+					symbolReport.Print(wantBaseAssetBaseline)
+				}
+			}
 
-			finalBalances := session.GetAccount().Balances()
-			finalTotalBalances = finalTotalBalances.Add(finalBalances)
+			return summaryReport, nil
 		}
 
-		summaryReport := &backtest.SummaryReport{
-			StartTime:            startTime,
-			EndTime:              endTime,
-			Sessions:             sessionNames,
-			InitialTotalBalances: initTotalBalances,
-			FinalTotalBalances:   finalTotalBalances,
-			Manifests:            manifests,
-			Symbols:              nil,
-		}
+		var scenarioReports []backtest.BalanceScenarioReport
 
-		for interval := range allKLineIntervals {
-			summaryReport.Intervals = append(summaryReport.Intervals, interval)
+		summaryReport, err := runScenario(ctx, environ, userConfig, "", reportDir)
+		if err != nil {
+			return err
 		}
+		scenarioReports = append(scenarioReports, backtest.NewBalanceScenarioReport(summaryReport))
 
-		for _, session := range environ.Sessions() {
-			for symbol, trades := range session.Trades {
-				tradeState := sessionTradeStats[session.Name][symbol]
-				profitFactor := tradeState.ProfitFactor
-				winningRatio := tradeState.WinningRatio
-				intervalProfits := tradeState.IntervalProfits[types.Interval1d]
-				symbolReport, err := createSymbolReport(userConfig, session, symbol, trades.Trades, intervalProfits, profitFactor, winningRatio)
-				if err != nil {
-					return err
-				}
+		for _, scenario := range userConfig.Backtest.BalanceScenarios() {
+			log.Infof("running backtest with the balance scenario %s", scenario)
 
-				summaryReport.Symbols = append(summaryReport.Symbols, symbol)
-				summaryReport.SymbolReports = append(summaryReport.SymbolReports, *symbolReport)
-				summaryReport.TotalProfit = symbolReport.PnL.Profit
-				summaryReport.TotalUnrealizedProfit = symbolReport.PnL.UnrealizedProfit
-				summaryReport.InitialEquityValue = summaryReport.InitialEquityValue.Add(symbolReport.InitialEquityValue())
-				summaryReport.FinalEquityValue = summaryReport.FinalEquityValue.Add(symbolReport.FinalEquityValue())
-				summaryReport.TotalGrossProfit.Add(symbolReport.PnL.GrossProfit)
-				summaryReport.TotalGrossLoss.Add(symbolReport.PnL.GrossLoss)
-
-				// write report to a file
-				if generatingReport {
-					reportFileName := fmt.Sprintf("symbol_report_%s_%s.json", session.Name, symbol)
-					if err := util.WriteJsonFile(filepath.Join(reportDir, reportFileName), &symbolReport); err != nil {
-						return err
-					}
-				}
+			// the strategy instances keep their states, so the config is loaded again for each scenario
+			scenarioConfig, err := bbgo.Load(configFile, true)
+			if err != nil {
+				return err
 			}
-		}
+			scenarioConfig.Backtest = userConfig.Backtest.WithBalanceScenario(scenario)
 
-		if generatingReport {
-			summaryReportFile := filepath.Join(reportDir, "summary.json")
+			scenarioEnviron := bbgo.NewEnvironment()
+			if err := bbgo.BootstrapBacktestEnvironment(ctx, scenarioEnviron); err != nil {
+				return err
+			}
+			scenarioEnviron.BacktestService = backtestService
 
-			// output summary report filepath to stdout, so that our optimizer can read from it
-			fmt.Println(summaryReportFile)
+			// the scenario uses its own persistence and graceful shutdown callbacks
+			scenarioCtx := bbgo.NewContextWithIsolation(ctx, bbgo.NewIsolation(&service.PersistenceServiceFacade{
+				Memory: service.NewMemoryService(),
+			}))
 
-			if err := util.WriteJsonFile(summaryReportFile, summaryReport); err != nil {
-				return errors.Wrapf(err, "can not write summary report json file: %s", summaryReportFile)
+			summaryReport, err := runScenario(scenarioCtx, scenarioEnviron, scenarioConfig, scenario, filepath.Join(reportDir, "scenario_"+scenario))
+			if err != nil {
+				return errors.Wrapf(err, "failed to run the balance scenario %s", scenario)
 			}
+			scenarioReports = append(scenarioReports, backtest.NewBalanceScenarioReport(summaryReport))
+		}
 
-			configJsonFile := filepath.Join(reportDir, "config.json")
-			if err := util.WriteJsonFile(configJsonFile, userConfig); err != nil {
-				return errors.Wrapf(err, "can not write config json file: %s", configJsonFile)
+		if generatingReport {
+			if len(scenarioReports) > 1 {
+				scenariosReportFile := filepath.Join(reportDir, "scenarios.json")
+				if err := util.WriteJsonFile(scenariosReportFile, scenarioReports); err != nil {
+					return errors.Wrapf(err, "can not write scenarios report json file: %s", scenariosReportFile)
+				}
 			}
 
 			// append report index
@@ -578,16 +677,8 @@ var BacktestCmd = &cobra.Command{
 					return err
 				}
 			}
-		} else {
-			color.Green("BACK-TEST REPORT")
-			color.Green("===============================================\n")
-			color.Green("START TIME: %s\n", startTime.Format(time.RFC1123))
-			color.Green("END TIME: %s\n", endTime.Format(time.RFC1123))
-			color.Green("INITIAL TOTAL BALANCE: %v\n", initTotalBalances)
-			color.Green("FINAL TOTAL BALANCE: %v\n", finalTotalBalances)
-			for _, symbolReport := range summaryReport.SymbolReports {
-				symbolReport.Print(wantBaseAssetBaseline)
-			}
+		} else if len(scenarioReports) > 1 {
+			backtest.PrintBalanceScenarioReports(scenarioReports)
 		}
 
 		return nil
@@ -645,6 +736,19 @@ func createSymbolReport(userConfig *bbgo.Config, session *bbgo.ExchangeSession,
 		Sortino:      sortinoRatio,
 		ProfitFactor: profitFactor,
 		WinningRatio: winningRatio,
+
+		MaxDrawdown:       fixedpoint.NewFromFloat(intervalProfit.GetMaxDrawdown()),
+		InventoryVariance: backtest.InventoryVariance(trades),
+		RejectedOrders:    backtestExchange.RejectedOrders(symbol),
+	}
+
+	if userConfig.Backtest.MonteCarlo != nil {
+		monteCarlo, err := backtest.RunMonteCarlo(*userConfig.Backtest.MonteCarlo, market, trades, symbolReport.InitialEquityValue())
+		if err != nil {
+			return nil, err
+		}
+
+		symbolReport.MonteCarlo = monteCarlo
 	}
 
 	for _, s := range session.Subscriptions {
@@ -697,7 +801,8 @@ func confirmation(s string) bool {
 	}
 }
 
-func sync(ctx context.Context, userConfig *bbgo.Config, backtestService *service.BacktestService, sourceExchanges map[types.ExchangeName]types.Exchange, syncFrom, syncTo time.Time) error {
+func sync(ctx context.Context, userConfig *bbgo.Config, backtestService *service.BacktestService, sourceExchanges map[types.ExchangeName]types.Exchange, syncFrom, syncTo time.Time, options service.BacktestSyncOptions) error {
+	var jobs []service.BacktestSyncJob
 	for _, symbol := range userConfig.Backtest.Symbols {
 		for _, sourceExchange := range sourceExchanges {
 			exCustom, ok := sourceExchange.(types.CustomIntervalProvider)
@@ -722,13 +827,25 @@ func sync(ctx context.Context, userConfig *bbgo.Config, backtestService *service
 			})
 
 			for _, interval := range intervals {
-				if err := backtestService.Sync(ctx, sourceExchange, symbol, interval, syncFrom, syncTo); err != nil {
-					return err
-				}
+				jobs = append(jobs, service.BacktestSyncJob{
+					Exchange: sourceExchange,
+					Symbol:   symbol,
+					Interval: interval,
+				})
 			}
 		}
 	}
-	return nil
+
+	options.OnProgress = func(progress service.BacktestSyncProgress) {
+		if progress.Err != nil {
+			log.WithError(progress.Err).Errorf("[%d/%d] %s sync failed, run the sync again to resume", progress.Done, progress.Total, progress.Job.String())
+			return
+		}
+
+		log.Infof("[%d/%d] %s synced", progress.Done, progress.Total, progress.Job.String())
+	}
+
+	return backtestService.SyncJobs(ctx, jobs, syncFrom, syncTo, options)
 }
 
 func rewriteManifestPaths(manifests backtest.Manifests, basePath string) (backtest.Manifests, error) {
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/backtest"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	backtestReplayCmd.Flags().String("log", "", "the session event log recorded by the session recorder")
+	backtestReplayCmd.Flags().String("symbol", "", "only show the decisions of the symbol")
+	backtestReplayCmd.Flags().String("until", "", "stop the replay after the given time, in RFC3339 format")
+	BacktestCmd.AddCommand(backtestReplayCmd)
+}
+
+// go run ./cmd/bbgo backtest replay --log data/sessions/session-20230501-000000.jsonl --symbol BTCUSDT
+var backtestReplayCmd = &cobra.Command{
+	Use:          "replay --log [session_event_log]",
+	Short:        "replay the recorded live session and show the strategy decisions with the market state at the decision time",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		logFile, err := cmd.Flags().GetString("log")
+		if err != nil {
+			return err
+		}
+
+		if logFile == "" {
+			return fmt.Errorf("--log option is required")
+		}
+
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return err
+		}
+
+		untilStr, err := cmd.Flags().GetString("until")
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(logFile)
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		replayer := backtest.NewReplayer()
+		if untilStr != "" {
+			replayer.Until, err = time.Parse(time.RFC3339, untilStr)
+			if err != nil {
+				return err
+			}
+		}
+
+		// bind the streams of the recorded sessions before their first events are emitted
+		state := backtest.NewReplayMarketState()
+		bound := make(map[string]struct{})
+		replayer.OnEvent(func(event types.SessionEvent) {
+			if _, ok := bound[event.Session]; ok {
+				return
+			}
+
+			bound[event.Session] = struct{}{}
+			marketDataStream, userDataStream := &types.StandardStream{}, &types.StandardStream{}
+			state.BindSession(event.Session, marketDataStream)
+			replayer.BindSession(event.Session, marketDataStream, userDataStream)
+		})
+
+		replayer.OnDecision(func(event types.SessionEvent, decision types.StrategyDecision) {
+			if symbol != "" && decision.Symbol != symbol {
+				return
+			}
+
+			fmt.Printf("#%d %s [%s] %s %s %s\n",
+				event.Seq, event.Time.Format(time.RFC3339Nano), event.Session,
+				decision.StrategyInstanceID, decision.Symbol, decision.Action)
+
+			if bid, ask, ok := state.BestBidAndAsk(event.Session, decision.Symbol); ok {
+				fmt.Printf("  book: bid %s x %s, ask %s x %s\n", bid.Price, bid.Volume, ask.Price, ask.Volume)
+			}
+
+			if k, ok := state.LastKLine(event.Session, decision.Symbol); ok {
+				fmt.Printf("  last kline: %s %s close %s\n", k.Interval, k.EndTime.Time().Format(time.RFC3339), k.Close)
+			}
+
+			for _, order := range decision.SubmitOrders {
+				fmt.Printf("  submit: %s %s %s @ %s %s\n", order.Side, order.Type, order.Quantity, order.Price, order.Tag)
+			}
+
+			for _, order := range decision.Orders {
+				fmt.Printf("  cancel: #%d %s %s @ %s\n", order.OrderID, order.Side, order.Quantity, order.Price)
+			}
+
+			if decision.Action == types.StrategyDecisionCancel && len(decision.Orders) == 0 {
+				fmt.Printf("  cancel: all active orders\n")
+			}
+
+			if decision.Message != "" {
+				fmt.Printf("  note: %s\n", decision.Message)
+			}
+
+			if len(decision.Fields) > 0 {
+				var fields []string
+				for k, v := range decision.Fields {
+					fields = append(fields, fmt.Sprintf("%s=%v", k, v))
+				}
+
+				sort.Strings(fields)
+				fmt.Printf("  fields: %s\n", strings.Join(fields, " "))
+			}
+		})
+
+		return replayer.Replay(backtest.NewSessionEventReader(f))
+	},
+}
@@ -164,6 +164,14 @@ var hoptimizeCmd = &cobra.Command{
 					color.Red("  - %s: (invalid parameter definition)", label)
 				}
 			}
+
+			if len(report.ParetoFront) > 0 {
+				color.Green("PARETO FRONT:")
+				for _, result := range report.ParetoFront {
+					color.Green("  - metrics: %v", result.Metrics)
+					color.Green("    parameters: %v", result.Parameters)
+				}
+			}
 		}
 
 		return nil
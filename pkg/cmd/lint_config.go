@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+func init() {
+	lintConfigCmd.Flags().Bool("json", false, "print the readiness report in JSON")
+	RootCmd.AddCommand(lintConfigCmd)
+}
+
+// go run ./cmd/bbgo lint-config --config=config/bbgo.yaml
+var lintConfigCmd = &cobra.Command{
+	Use:          "lint-config",
+	Short:        "validate the config and check the sessions, the API key permissions and the strategy symbols without trading",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		printJSON, err := cmd.Flags().GetBool("json")
+		if err != nil {
+			return err
+		}
+
+		if userConfig == nil {
+			return errors.New("config is not loaded")
+		}
+
+		environ := bbgo.NewEnvironment()
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+			return err
+		}
+
+		report := bbgo.CheckReadiness(ctx, userConfig, environ)
+		if printJSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			if err := encoder.Encode(report); err != nil {
+				return err
+			}
+		} else {
+			report.Print(os.Stdout)
+		}
+
+		if !report.Ready() {
+			return errors.New("the config is not ready for trading")
+		}
+
+		return nil
+	},
+}
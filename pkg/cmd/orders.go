@@ -264,6 +264,10 @@ var executeOrderCmd = &cobra.Command{
 			DeadlineTime:   deadlineTime,
 		}
 
+		execution.OnSliceReport(func(report bbgo.TwapSliceReport) {
+			log.Info(report.String())
+		})
+
 		if err := execution.Run(executionCtx); err != nil {
 			return err
 		}
@@ -287,6 +291,7 @@ var executeOrderCmd = &cobra.Command{
 
 		}
 
+		log.Info(execution.Report().String())
 		return nil
 	},
 }
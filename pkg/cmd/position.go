@@ -0,0 +1,511 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/dynamic"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	positionCmd.Flags().String("session", "", "only show the strategy instances mounted on the session")
+	positionCmd.Flags().String("instance", "", "only show the strategy instance of the instance ID")
+	positionCmd.Flags().Bool("close", false, "flatten the positions with market orders, the strategy instances must be stopped")
+
+	instanceOrdersCmd.Flags().String("session", "", "only show the strategy instances mounted on the session")
+	instanceOrdersCmd.Flags().String("instance", "", "only show the strategy instance of the instance ID")
+
+	transferPositionCmd.Flags().String("from", "", "the instance ID of the source strategy instance")
+	transferPositionCmd.Flags().String("to", "", "the instance ID of the target strategy instance")
+	transferPositionCmd.Flags().String("symbol", "", "the symbol of the position")
+	transferPositionCmd.Flags().String("quantity", "", "the quantity to transfer, the whole position is transferred if it's not given")
+	transferPositionCmd.Flags().String("user", "", "the user recorded in the audit log, defaults to $USER")
+
+	importPositionCmd.Flags().String("instance", "", "the instance ID of the target strategy instance")
+	importPositionCmd.Flags().String("symbol", "", "the symbol of the position")
+	importPositionCmd.Flags().String("base", "", "the base quantity held outside of the strategy, negative for a short position")
+	importPositionCmd.Flags().String("price", "", "the average price of the base quantity")
+	importPositionCmd.Flags().String("user", "", "the user recorded in the audit log, defaults to $USER")
+
+	positionCmd.AddCommand(transferPositionCmd)
+	positionCmd.AddCommand(importPositionCmd)
+
+	RootCmd.AddCommand(positionCmd)
+	RootCmd.AddCommand(instanceOrdersCmd)
+}
+
+// go run ./cmd/bbgo position --session=binance --instance=binance.bollmaker:ETHUSDT [--close]
+var positionCmd = &cobra.Command{
+	Use:          "position [--session SESSION] [--instance INSTANCE_ID] [--close]",
+	Short:        "show the persisted positions of the strategy instances",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		closePosition, err := cmd.Flags().GetBool("close")
+		if err != nil {
+			return err
+		}
+
+		environ, trader, instances, err := loadStrategyInstances(ctx, cmd)
+		if err != nil {
+			return err
+		}
+
+		for _, instance := range instances {
+			positions := instance.Positions()
+			if len(positions) == 0 {
+				continue
+			}
+
+			log.Infof("STRATEGY INSTANCE %s", instance.ID)
+			for _, position := range positions {
+				log.Info(position.PlainText())
+
+				if !closePosition || position.GetBase().IsZero() {
+					continue
+				}
+
+				if err := checkInstanceStopped(ctx, trader.Coordinator(), instance); err != nil {
+					return err
+				}
+
+				if err := flattenPosition(ctx, environ, instance, position); err != nil {
+					// the positions flattened before are stored, so that they are not closed again by the rerun
+					if saveErr := trader.SaveState(ctx); saveErr != nil {
+						log.WithError(saveErr).Errorf("can not save the strategy states")
+					}
+
+					return errors.Wrapf(err, "can not close the %s position of %s", position.Symbol, instance.ID)
+				}
+			}
+		}
+
+		if closePosition {
+			return trader.SaveState(ctx)
+		}
+
+		return nil
+	},
+}
+
+// go run ./cmd/bbgo orders --session=binance --instance=binance.bollmaker:ETHUSDT
+var instanceOrdersCmd = &cobra.Command{
+	Use:          "orders [--session SESSION] [--instance INSTANCE_ID]",
+	Short:        "show the open orders of the symbols traded by the strategy instances",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		environ, _, instances, err := loadStrategyInstances(ctx, cmd)
+		if err != nil {
+			return err
+		}
+
+		for _, instance := range instances {
+			if instance.Session == "" {
+				log.Warnf("skipping cross exchange strategy instance %s", instance.ID)
+				continue
+			}
+
+			session, ok := environ.Session(instance.Session)
+			if !ok {
+				return fmt.Errorf("session %s not found", instance.Session)
+			}
+
+			for _, symbol := range strategyInstanceSymbols(instance) {
+				orders, err := session.Exchange.QueryOpenOrders(ctx, symbol)
+				if err != nil {
+					return err
+				}
+
+				log.Infof("OPEN ORDERS OF %s %s", instance.ID, symbol)
+				for _, o := range orders {
+					log.Info(o.String())
+				}
+			}
+		}
+
+		return nil
+	},
+}
+
+// go run ./cmd/bbgo position transfer --from=binance.bollmaker:ETHUSDT --to=binance.grid2:ETHUSDT --symbol=ETHUSDT [--quantity=0.5]
+var transferPositionCmd = &cobra.Command{
+	Use:          "transfer --from INSTANCE_ID --to INSTANCE_ID --symbol SYMBOL [--quantity QUANTITY]",
+	Short:        "transfer the persisted position from one strategy instance to another at the average cost, the strategy instances must be stopped",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		fromID, err := cmd.Flags().GetString("from")
+		if err != nil {
+			return err
+		}
+
+		toID, err := cmd.Flags().GetString("to")
+		if err != nil {
+			return err
+		}
+
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return err
+		}
+
+		quantity, err := getFixedpointFlag(cmd, "quantity")
+		if err != nil {
+			return err
+		}
+
+		if fromID == "" || toID == "" || symbol == "" {
+			return errors.New("--from, --to and --symbol are required")
+		}
+
+		environ, trader, err := loadTrader(ctx)
+		if err != nil {
+			return err
+		}
+
+		from, err := trader.LookupStrategyInstance(fromID)
+		if err != nil {
+			return err
+		}
+
+		to, err := trader.LookupStrategyInstance(toID)
+		if err != nil {
+			return err
+		}
+
+		transfer, err := bbgo.TransferPosition(from, to, symbol, quantity, time.Now())
+		if err != nil {
+			return err
+		}
+
+		return savePositionTransfer(ctx, cmd, environ, trader, transfer)
+	},
+}
+
+// go run ./cmd/bbgo position import --instance=binance.bollmaker:ETHUSDT --symbol=ETHUSDT --base=1.5 --price=1800
+var importPositionCmd = &cobra.Command{
+	Use:          "import --instance INSTANCE_ID --symbol SYMBOL --base QUANTITY --price PRICE",
+	Short:        "import the position held outside of the strategy (e.g., the manual buys) into the cost basis of the strategy instance, the strategy instance must be stopped",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		instanceID, err := cmd.Flags().GetString("instance")
+		if err != nil {
+			return err
+		}
+
+		symbol, err := cmd.Flags().GetString("symbol")
+		if err != nil {
+			return err
+		}
+
+		base, err := getFixedpointFlag(cmd, "base")
+		if err != nil {
+			return err
+		}
+
+		price, err := getFixedpointFlag(cmd, "price")
+		if err != nil {
+			return err
+		}
+
+		if instanceID == "" || symbol == "" {
+			return errors.New("--instance and --symbol are required")
+		}
+
+		environ, trader, err := loadTrader(ctx)
+		if err != nil {
+			return err
+		}
+
+		instance, err := trader.LookupStrategyInstance(instanceID)
+		if err != nil {
+			return err
+		}
+
+		transfer, err := bbgo.ImportPosition(instance, symbol, base, price, time.Now())
+		if err != nil {
+			return err
+		}
+
+		return savePositionTransfer(ctx, cmd, environ, trader, transfer)
+	},
+}
+
+func getFixedpointFlag(cmd *cobra.Command, name string) (fixedpoint.Value, error) {
+	s, err := cmd.Flags().GetString(name)
+	if err != nil || s == "" {
+		return fixedpoint.Zero, err
+	}
+
+	v, err := fixedpoint.NewFromString(s)
+	if err != nil {
+		return fixedpoint.Zero, errors.Wrapf(err, "invalid --%s", name)
+	}
+
+	return v, nil
+}
+
+// savePositionTransfer persists the changed positions and records the transfer in the command audit log,
+// the audit log is stored in the database if the database is configured by the environment variables.
+func savePositionTransfer(ctx context.Context, cmd *cobra.Command, environ *bbgo.Environment, trader *bbgo.Trader, transfer *bbgo.PositionTransfer) error {
+	user, err := cmd.Flags().GetString("user")
+	if err != nil {
+		return err
+	}
+
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+
+	if err := trader.SaveState(ctx); err != nil {
+		return err
+	}
+
+	if err := environ.ConfigureDatabase(ctx); err != nil {
+		return err
+	}
+
+	environ.ConfigureCommandAuthorization(userConfig.CommandAuthorization)
+	environ.CommandAuthorizer().LogCommand(transfer.AuditEntry("cli", user, ""))
+
+	log.Infof("POSITION %s", transfer.String())
+	return nil
+}
+
+// loadTrader configures the strategies from the config file and loads their persisted states
+func loadTrader(ctx context.Context) (*bbgo.Environment, *bbgo.Trader, error) {
+	if userConfig == nil {
+		return nil, nil, errors.New("config file is required")
+	}
+
+	environ := bbgo.NewEnvironment()
+	if err := bbgo.BootstrapEnvironmentLightweight(ctx, environ, userConfig); err != nil {
+		return nil, nil, err
+	}
+
+	trader := bbgo.NewTrader(environ)
+	if err := trader.Configure(userConfig); err != nil {
+		return nil, nil, err
+	}
+
+	if err := trader.LoadState(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	return environ, trader, nil
+}
+
+// loadStrategyInstances configures the strategies from the config file, loads their persisted states,
+// and returns the strategy instances filtered by the --session and --instance flags.
+func loadStrategyInstances(ctx context.Context, cmd *cobra.Command) (*bbgo.Environment, *bbgo.Trader, []*bbgo.StrategyInstance, error) {
+	sessionName, err := cmd.Flags().GetString("session")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	instanceID, err := cmd.Flags().GetString("instance")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	environ, trader, err := loadTrader(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	instances, err := trader.StrategyInstances()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var filtered []*bbgo.StrategyInstance
+	for _, instance := range instances {
+		if sessionName != "" && instance.Session != sessionName {
+			continue
+		}
+
+		if instanceID != "" && instance.ID != instanceID {
+			continue
+		}
+
+		filtered = append(filtered, instance)
+	}
+
+	if instanceID != "" && len(filtered) == 0 {
+		return nil, nil, nil, fmt.Errorf("%w: %s", bbgo.ErrStrategyInstanceNotFound, instanceID)
+	}
+
+	return environ, trader, filtered, nil
+}
+
+// strategyInstanceSymbols returns the symbol field and the position symbols of the strategy instance
+func strategyInstanceSymbols(instance *bbgo.StrategyInstance) (symbols []string) {
+	seen := map[string]struct{}{}
+	add := func(symbol string) {
+		if _, ok := seen[symbol]; ok || symbol == "" {
+			return
+		}
+
+		seen[symbol] = struct{}{}
+		symbols = append(symbols, symbol)
+	}
+
+	if symbol, ok := dynamic.LookupSymbolField(reflect.ValueOf(instance.Strategy)); ok {
+		add(symbol)
+	}
+
+	for _, position := range instance.Positions() {
+		add(position.Symbol)
+	}
+
+	return symbols
+}
+
+// flattenPosition submits the market order that closes the position,
+// and applies the filled trades to the position so that the persisted position stays consistent.
+func flattenPosition(ctx context.Context, environ *bbgo.Environment, instance *bbgo.StrategyInstance, position *types.Position) error {
+	if instance.Session == "" {
+		return errors.New("closing the position of a cross exchange strategy instance is not supported")
+	}
+
+	session, ok := environ.Session(instance.Session)
+	if !ok {
+		return fmt.Errorf("session %s not found", instance.Session)
+	}
+
+	markets, err := session.Exchange.QueryMarkets(ctx)
+	if err != nil {
+		return err
+	}
+
+	market, ok := markets[position.Symbol]
+	if !ok {
+		return fmt.Errorf("market %s not found", position.Symbol)
+	}
+
+	position.Market = market
+
+	submitOrder := position.NewMarketCloseOrder(fixedpoint.One)
+	if submitOrder == nil {
+		log.Warnf("the %s position of %s is below the min quantity, skipping", position.Symbol, instance.ID)
+		return nil
+	}
+
+	log.Infof("closing the position with %s", submitOrder.String())
+
+	createdOrder, err := session.Exchange.SubmitOrder(ctx, *submitOrder)
+	if err != nil {
+		return err
+	}
+
+	log.Infof("SUBMITTED %s", createdOrder.String())
+
+	service, ok := session.Exchange.(types.ExchangeOrderQueryService)
+	if !ok {
+		log.Warnf("exchange %s can not query the order trades, please reset the position of %s manually", session.Exchange.Name(), instance.ID)
+		return nil
+	}
+
+	trades, err := waitOrderTrades(ctx, service, *createdOrder, orderTradesPollInterval, orderTradesTimeout)
+	if err != nil {
+		return errors.Wrapf(err, "the close order %d is submitted, please check the position of %s manually", createdOrder.OrderID, instance.ID)
+	}
+
+	for _, trade := range trades {
+		position.AddTrade(trade)
+	}
+
+	log.Info(position.PlainText())
+	return nil
+}
+
+const (
+	orderTradesPollInterval = time.Second
+	orderTradesTimeout      = 30 * time.Second
+)
+
+// checkInstanceStopped returns an error if the strategy instance is running in a process,
+// the lease of the instance is held by the running process in the multi-process deployment.
+func checkInstanceStopped(ctx context.Context, coordinator *bbgo.Coordinator, instance *bbgo.StrategyInstance) error {
+	if coordinator == nil {
+		log.Warnf("coordinator is not configured, can not check if %s is stopped, please make sure it's not running", instance.ID)
+		return nil
+	}
+
+	owner, err := coordinator.LeaseOwner(ctx, instance.ID)
+	if err != nil {
+		return errors.Wrapf(err, "can not query the lease owner of %s", instance.ID)
+	}
+
+	if owner != "" {
+		return fmt.Errorf("strategy instance %s is running on %s, please stop it before closing the position", instance.ID, owner)
+	}
+
+	return nil
+}
+
+// waitOrderTrades polls the order until it's closed and its trades cover the executed quantity,
+// the trades are not settled right after the market order is submitted on some exchanges.
+func waitOrderTrades(ctx context.Context, service types.ExchangeOrderQueryService, order types.Order, interval, timeout time.Duration) ([]types.Trade, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	query := types.OrderQuery{
+		Symbol:  order.Symbol,
+		OrderID: fmt.Sprintf("%d", order.OrderID),
+	}
+
+	for {
+		queriedOrder, err := service.QueryOrder(ctx, query)
+		if err == nil && isOrderClosed(queriedOrder.Status) {
+			trades, err := service.QueryOrderTrades(ctx, query)
+			if err == nil && tradeQuantity(trades).Compare(queriedOrder.ExecutedQuantity) >= 0 {
+				return trades, nil
+			} else if err != nil {
+				log.WithError(err).Warnf("can not query the trades of order %d", order.OrderID)
+			}
+		} else if err != nil {
+			log.WithError(err).Warnf("can not query order %d", order.OrderID)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrapf(ctx.Err(), "the trades of order %d are not settled", order.OrderID)
+		case <-time.After(interval):
+		}
+	}
+}
+
+func isOrderClosed(status types.OrderStatus) bool {
+	switch status {
+	case types.OrderStatusFilled, types.OrderStatusCanceled, types.OrderStatusRejected:
+		return true
+	}
+
+	return false
+}
+
+func tradeQuantity(trades []types.Trade) fixedpoint.Value {
+	quantity := fixedpoint.Zero
+	for _, trade := range trades {
+		quantity = quantity.Add(trade.Quantity)
+	}
+
+	return quantity
+}
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func Test_checkInstanceStopped(t *testing.T) {
+	ctx := context.Background()
+	instance := &bbgo.StrategyInstance{ID: "binance.scmaker:BTCUSDT", Session: "binance"}
+
+	assert.NoError(t, checkInstanceStopped(ctx, nil, instance))
+
+	leases := service.NewMemoryLeaseService()
+	coordinator := bbgo.NewCoordinator(&bbgo.CoordinatorConfig{Node: "cli"}, leases)
+	assert.NoError(t, checkInstanceStopped(ctx, coordinator, instance))
+
+	ok, err := leases.Acquire(ctx, instance.ID, "node-1", time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	err = checkInstanceStopped(ctx, coordinator, instance)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "node-1")
+	}
+
+	assert.NoError(t, leases.Release(ctx, instance.ID, "node-1"))
+	assert.NoError(t, checkInstanceStopped(ctx, coordinator, instance))
+}
+
+func Test_waitOrderTrades(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	order := types.Order{
+		SubmitOrder: types.SubmitOrder{Symbol: "BTCUSDT", Side: types.SideTypeSell, Type: types.OrderTypeMarket},
+		OrderID:     1,
+	}
+
+	filledOrder := order
+	filledOrder.Status = types.OrderStatusFilled
+	filledOrder.ExecutedQuantity = fixedpoint.MustNewFromString("0.3")
+
+	trade1 := types.Trade{ID: 1, OrderID: 1, Symbol: "BTCUSDT", Quantity: fixedpoint.MustNewFromString("0.1")}
+	trade2 := types.Trade{ID: 2, OrderID: 1, Symbol: "BTCUSDT", Quantity: fixedpoint.MustNewFromString("0.2")}
+
+	t.Run("settled", func(t *testing.T) {
+		mockService := mocks.NewMockExchangeOrderQueryService(mockCtrl)
+
+		newOrder := order
+		newOrder.Status = types.OrderStatusNew
+
+		gomock.InOrder(
+			mockService.EXPECT().QueryOrder(gomock.Any(), gomock.Any()).Return(&newOrder, nil),
+			mockService.EXPECT().QueryOrder(gomock.Any(), gomock.Any()).Return(&filledOrder, nil),
+			mockService.EXPECT().QueryOrderTrades(gomock.Any(), gomock.Any()).Return([]types.Trade{trade1}, nil),
+			mockService.EXPECT().QueryOrder(gomock.Any(), gomock.Any()).Return(&filledOrder, nil),
+			mockService.EXPECT().QueryOrderTrades(gomock.Any(), gomock.Any()).Return([]types.Trade{trade1, trade2}, nil),
+		)
+
+		trades, err := waitOrderTrades(context.Background(), mockService, order, time.Millisecond, time.Second)
+		assert.NoError(t, err)
+		assert.Len(t, trades, 2)
+	})
+
+	t.Run("timeout", func(t *testing.T) {
+		mockService := mocks.NewMockExchangeOrderQueryService(mockCtrl)
+		mockService.EXPECT().QueryOrder(gomock.Any(), gomock.Any()).Return(&filledOrder, nil).AnyTimes()
+		mockService.EXPECT().QueryOrderTrades(gomock.Any(), gomock.Any()).Return([]types.Trade{trade1}, nil).AnyTimes()
+
+		_, err := waitOrderTrades(context.Background(), mockService, order, time.Millisecond, 20*time.Millisecond)
+		assert.Error(t, err)
+	})
+}
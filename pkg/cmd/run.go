@@ -35,6 +35,7 @@ func init() {
 	RunCmd.Flags().String("grpc-bind", ":50051", "grpc server binding")
 
 	RunCmd.Flags().Bool("setup", false, "use setup mode")
+	RunCmd.Flags().Bool("i-really-want-to-trade", false, "skip the dry-run warm-up period of the newly added strategy instances and trade with real funds")
 	RootCmd.AddCommand(RunCmd)
 }
 
@@ -124,6 +125,11 @@ func runConfig(basectx context.Context, cmd *cobra.Command, userConfig *bbgo.Con
 	_ = grpcBind
 	_ = enableGrpc
 
+	// report all the config errors before any session is connected
+	if err := bbgo.ValidateConfig(userConfig); err != nil {
+		return err
+	}
+
 	tradingCtx, cancelTrading := context.WithCancel(basectx)
 	defer cancelTrading()
 
@@ -134,6 +140,11 @@ func runConfig(basectx context.Context, cmd *cobra.Command, userConfig *bbgo.Con
 		return err
 	}
 
+	reallyWantToTrade, err := cmd.Flags().GetBool("i-really-want-to-trade")
+	if err != nil {
+		return err
+	}
+
 	if lightweight {
 		if err := bbgo.BootstrapEnvironmentLightweight(tradingCtx, environ, userConfig); err != nil {
 			return err
@@ -163,14 +174,111 @@ func runConfig(basectx context.Context, cmd *cobra.Command, userConfig *bbgo.Con
 		return err
 	}
 
+	if reallyWantToTrade {
+		log.Warnf("--i-really-want-to-trade is set, all the strategy instances will trade with real funds")
+		trader.DisableLiveTradingGuard()
+	}
+
 	if err := trader.LoadState(tradingCtx); err != nil {
 		return err
 	}
 
+	// the recorders should be bound before the streams are connected
+	var sessionRecorder *bbgo.SessionRecorder
+	if userConfig.SessionRecorder != nil {
+		sessionRecorder, err = bbgo.NewSessionRecorder(userConfig.SessionRecorder)
+		if err != nil {
+			return err
+		}
+
+		environ.SetSessionRecorder(sessionRecorder)
+		go sessionRecorder.Run(tradingCtx)
+	}
+
+	var bookRecorder *bbgo.BookRecorder
+	if userConfig.BookRecorder != nil {
+		bookRecorder = bbgo.NewBookRecorder(environ, userConfig.BookRecorder)
+		bookRecorder.Subscribe()
+	}
+
+	var performanceDigest *bbgo.PerformanceDigest
+	if userConfig.PerformanceDigest != nil {
+		performanceDigest, err = bbgo.NewPerformanceDigest(environ, trader, userConfig.PerformanceDigest)
+		if err != nil {
+			return err
+		}
+
+		performanceDigest.BindStreams()
+	}
+
+	// the scheduler should be set before the strategies register the jobs
+	if userConfig.Scheduler != nil {
+		scheduler, err := bbgo.NewSchedulerFromConfig(userConfig.Scheduler)
+		if err != nil {
+			return err
+		}
+
+		environ.SetScheduler(scheduler)
+	}
+
+	// the dead-man switch binds the connection status of the user data streams
+	var deadManSwitch *bbgo.DeadManSwitch
+	if userConfig.DeadManSwitch != nil {
+		deadManSwitch = bbgo.NewDeadManSwitch(environ, userConfig.DeadManSwitch)
+	}
+
+	// the health monitor binds the connection status of the streams
+	healthMonitor := bbgo.NewHealthMonitor(environ, trader, userConfig.HealthCheck)
+	healthMonitor.BindStreams()
+	environ.SetHealthMonitor(healthMonitor)
+
+	if userConfig.Shutdown != nil {
+		if err := bbgo.ConfigureShutdown(tradingCtx, userConfig.Shutdown); err != nil {
+			return err
+		}
+	}
+
 	if err := trader.Run(tradingCtx); err != nil {
 		return err
 	}
 
+	// the shutdown hooks are registered by the strategies in Run
+	if err := bbgo.ValidateShutdown(tradingCtx); err != nil {
+		return err
+	}
+
+	go environ.Scheduler().Run(tradingCtx)
+
+	if deadManSwitch != nil {
+		go deadManSwitch.Run(tradingCtx)
+	}
+
+	if userConfig.Secrets != nil && userConfig.Secrets.RotationInterval > 0 {
+		go bbgo.NewCredentialRotator(environ, userConfig.Secrets).Run(tradingCtx)
+	}
+
+	if bookRecorder != nil {
+		go bookRecorder.Run(tradingCtx)
+	}
+
+	if userConfig.MarkToMarket != nil {
+		go bbgo.NewMarkToMarketService(environ, trader, userConfig.MarkToMarket).Run(tradingCtx)
+	}
+
+	if userConfig.MarginMonitor != nil {
+		go bbgo.NewMarginMonitor(environ, userConfig.MarginMonitor).Run(tradingCtx)
+	}
+
+	if userConfig.PortfolioRisk != nil {
+		portfolioRisk := bbgo.NewPortfolioRiskService(environ, trader, userConfig.PortfolioRisk)
+		environ.SetPortfolioRiskService(portfolioRisk)
+		go portfolioRisk.Run(tradingCtx)
+	}
+
+	if performanceDigest != nil {
+		go performanceDigest.Run(tradingCtx)
+	}
+
 	if enableWebServer {
 		go func() {
 			s := &server.Server{
@@ -201,25 +309,38 @@ func runConfig(basectx context.Context, cmd *cobra.Command, userConfig *bbgo.Con
 	cmdutil.WaitForSignal(tradingCtx, syscall.SIGINT, syscall.SIGTERM)
 	cancelTrading()
 
-	gracefulShutdownPeriod := 30 * time.Second
-	shtCtx, cancelShutdown := context.WithTimeout(bbgo.NewTodoContextWithExistingIsolation(tradingCtx), gracefulShutdownPeriod)
-	bbgo.Shutdown(shtCtx)
+	bbgo.OnShutdownPhase(tradingCtx, bbgo.ShutdownPhasePersist, "trader states", trader.SaveState)
 
-	if err := trader.SaveState(shtCtx); err != nil {
-		log.WithError(err).Errorf("can not save strategy persistence states")
+	if sessionRecorder != nil {
+		bbgo.OnShutdownPhase(tradingCtx, bbgo.ShutdownPhasePersist, "session recorder", func(ctx context.Context) error {
+			return sessionRecorder.Close()
+		})
 	}
 
-	cancelShutdown()
-
 	for _, session := range environ.Sessions() {
-		if err := session.MarketDataStream.Close(); err != nil {
-			log.WithError(err).Errorf("[%s] market data stream close error", session.Name)
-		}
-		if err := session.UserDataStream.Close(); err != nil {
-			log.WithError(err).Errorf("[%s] user data stream close error", session.Name)
-		}
+		session := session
+		bbgo.OnShutdownPhase(tradingCtx, bbgo.ShutdownPhaseDisconnect, session.Name+" streams", func(ctx context.Context) error {
+			if err := session.MarketDataStream.Close(); err != nil {
+				return errors.Wrap(err, "market data stream close error")
+			}
+
+			if err := session.UserDataStream.Close(); err != nil {
+				return errors.Wrap(err, "user data stream close error")
+			}
+
+			return nil
+		})
+	}
+
+	gracefulShutdownPeriod := 30 * time.Second
+	if userConfig.Shutdown != nil && userConfig.Shutdown.Timeout > 0 {
+		gracefulShutdownPeriod = userConfig.Shutdown.Timeout.Duration()
 	}
 
+	shtCtx, cancelShutdown := context.WithTimeout(bbgo.NewTodoContextWithExistingIsolation(tradingCtx), gracefulShutdownPeriod)
+	bbgo.Shutdown(shtCtx)
+	cancelShutdown()
+
 	return nil
 }
 
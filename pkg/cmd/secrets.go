@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/secrets"
+)
+
+func init() {
+	secretsEncryptCmd.Flags().String("input", "", "the plain JSON file of the credentials, e.g. {\"binance-main\": {\"key\": \"...\", \"secret\": \"...\"}}")
+	secretsEncryptCmd.Flags().String("output", "", "the encrypted secrets file")
+	secretsEncryptCmd.Flags().String("password-env", "BBGO_SECRETS_PASSWORD", "the environment variable of the password")
+	secretsCmd.AddCommand(secretsEncryptCmd)
+	RootCmd.AddCommand(secretsCmd)
+}
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "manage the encrypted secrets file of the exchange credentials",
+}
+
+// go run ./cmd/bbgo secrets encrypt --input credentials.json --output secrets.enc
+var secretsEncryptCmd = &cobra.Command{
+	Use:          "encrypt",
+	Short:        "encrypt the credentials into the secrets file",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		input, err := cmd.Flags().GetString("input")
+		if err != nil {
+			return err
+		}
+
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		passwordEnv, err := cmd.Flags().GetString("password-env")
+		if err != nil {
+			return err
+		}
+
+		if input == "" || output == "" {
+			return errors.New("--input and --output are required")
+		}
+
+		password := os.Getenv(passwordEnv)
+		if password == "" {
+			return fmt.Errorf("password env var %s is not set", passwordEnv)
+		}
+
+		plain, err := ioutil.ReadFile(input)
+		if err != nil {
+			return err
+		}
+
+		var credentials map[string]secrets.Credentials
+		if err := json.Unmarshal(plain, &credentials); err != nil {
+			return err
+		}
+
+		for name, c := range credentials {
+			if err := c.Validate(); err != nil {
+				return fmt.Errorf("invalid credentials %s: %w", name, err)
+			}
+		}
+
+		data, err := secrets.Encrypt(credentials, password)
+		if err != nil {
+			return err
+		}
+
+		return ioutil.WriteFile(output, data, 0600)
+	},
+}
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+func init() {
+	stateCmd.PersistentFlags().String("backend", "", "the persistence backend: redis, postgres or json, defaults to the preferred configured backend")
+
+	stateExportCmd.Flags().String("output", "", "the archive file path, print to stdout if it's not given")
+
+	stateCmd.AddCommand(stateExportCmd)
+	stateCmd.AddCommand(stateImportCmd)
+	RootCmd.AddCommand(stateCmd)
+}
+
+var stateCmd = &cobra.Command{
+	Use:   "state",
+	Short: "export or import the persisted strategy states",
+}
+
+// go run ./cmd/bbgo state export --backend redis --output state.json
+var stateExportCmd = &cobra.Command{
+	Use:          "export [--backend BACKEND] [--output FILE]",
+	Short:        "export all the persisted strategy states as a versioned archive",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		persistence, err := statePersistenceService(cmd)
+		if err != nil {
+			return err
+		}
+
+		archive, err := service.ExportPersistence(persistence)
+		if err != nil {
+			return err
+		}
+
+		data, err := json.MarshalIndent(archive, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		if output == "" {
+			fmt.Println(string(data))
+			return nil
+		}
+
+		if err := ioutil.WriteFile(output, data, 0600); err != nil {
+			return err
+		}
+
+		log.Infof("exported %d keys to %s", len(archive.Values), output)
+		return nil
+	},
+}
+
+// go run ./cmd/bbgo state import --backend postgres state.json
+var stateImportCmd = &cobra.Command{
+	Use:          "import [--backend BACKEND] FILE",
+	Short:        "import the persisted strategy states from an archive, the existing keys are overwritten",
+	Args:         cobra.ExactArgs(1),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		data, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+
+		var archive service.PersistenceArchive
+		if err := json.Unmarshal(data, &archive); err != nil {
+			return errors.Wrapf(err, "can not parse the archive %s", args[0])
+		}
+
+		persistence, err := statePersistenceService(cmd)
+		if err != nil {
+			return err
+		}
+
+		if err := service.ImportPersistence(persistence, &archive); err != nil {
+			return err
+		}
+
+		log.Infof("imported %d keys from %s", len(archive.Values), args[0])
+		return nil
+	},
+}
+
+func statePersistenceService(cmd *cobra.Command) (service.PersistenceService, error) {
+	backend, err := cmd.Flags().GetString("backend")
+	if err != nil {
+		return nil, err
+	}
+
+	if userConfig == nil || userConfig.Persistence == nil {
+		return nil, errors.New("persistence is not configured")
+	}
+
+	facade, err := bbgo.NewPersistenceServiceFacade(userConfig.Persistence)
+	if err != nil {
+		return nil, err
+	}
+
+	if backend == "" {
+		return facade.Get(), nil
+	}
+
+	return facade.GetByName(backend)
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/c9s/bbgo/pkg/accounting/costbasis"
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/service"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func init() {
+	TaxReportCmd.Flags().StringArray("session", []string{}, "target exchange sessions")
+	TaxReportCmd.Flags().StringArray("symbol", []string{}, "trading symbols")
+	TaxReportCmd.Flags().String("method", string(costbasis.MethodFIFO), "cost basis method: fifo, lifo or avg")
+	TaxReportCmd.Flags().Int("year", 0, "the tax year of the report, all years are reported if it's not given")
+	TaxReportCmd.Flags().Bool("sync", false, "sync before loading trades")
+	TaxReportCmd.Flags().String("output", "", "the csv file of the tax report, defaults to stdout")
+	TaxReportCmd.Flags().String("disposals", "", "the csv file of the realized disposals")
+	RootCmd.AddCommand(TaxReportCmd)
+}
+
+// TaxReportCmd computes the realized gains from the synced trades with the selected cost basis method
+// bbgo tax-report --session binance --symbol BTCUSDT --method fifo --year 2022 --output tax-2022.csv
+var TaxReportCmd = &cobra.Command{
+	Use:          "tax-report",
+	Short:        "Export the realized gains per year and currency with the FIFO, LIFO or average cost basis",
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		ctx := context.Background()
+
+		sessionNames, err := cmd.Flags().GetStringArray("session")
+		if err != nil {
+			return err
+		}
+
+		if len(sessionNames) == 0 {
+			return errors.New("--session [SESSION] is required")
+		}
+
+		symbols, err := cmd.Flags().GetStringArray("symbol")
+		if err != nil {
+			return err
+		}
+
+		if len(symbols) == 0 {
+			return errors.New("--symbol [SYMBOL] is required")
+		}
+
+		methodOpt, err := cmd.Flags().GetString("method")
+		if err != nil {
+			return err
+		}
+
+		method, err := costbasis.ParseMethod(methodOpt)
+		if err != nil {
+			return err
+		}
+
+		year, err := cmd.Flags().GetInt("year")
+		if err != nil {
+			return err
+		}
+
+		wantSync, err := cmd.Flags().GetBool("sync")
+		if err != nil {
+			return err
+		}
+
+		output, err := cmd.Flags().GetString("output")
+		if err != nil {
+			return err
+		}
+
+		disposalsOutput, err := cmd.Flags().GetString("disposals")
+		if err != nil {
+			return err
+		}
+
+		environ := bbgo.NewEnvironment()
+
+		if err := environ.ConfigureDatabase(ctx); err != nil {
+			return err
+		}
+
+		if err := environ.ConfigureExchangeSessions(userConfig); err != nil {
+			return err
+		}
+
+		for _, sessionName := range sessionNames {
+			session, ok := environ.Session(sessionName)
+			if !ok {
+				return fmt.Errorf("session %s not found", sessionName)
+			}
+
+			if wantSync {
+				for _, symbol := range symbols {
+					if err := environ.SyncSession(ctx, session, symbol); err != nil {
+						return err
+					}
+				}
+			}
+		}
+
+		if err := environ.Init(ctx); err != nil {
+			return err
+		}
+
+		var disposals []costbasis.Disposal
+		for _, symbol := range symbols {
+			var market types.Market
+			var found bool
+			for _, sessionName := range sessionNames {
+				session, _ := environ.Session(sessionName)
+				if market, found = session.Market(symbol); found {
+					break
+				}
+			}
+
+			if !found {
+				return fmt.Errorf("market %s not found", symbol)
+			}
+
+			// the lots must be matched from the first trade, so the trades of all the years are loaded
+			trades, err := environ.TradeService.Query(service.QueryTradesOptions{
+				Symbol:   symbol,
+				Sessions: sessionNames,
+			})
+			if err != nil {
+				return err
+			}
+
+			log.Infof("%d %s trades loaded", len(trades), symbol)
+
+			calculator := costbasis.NewCalculator(method, market)
+			for _, disposal := range calculator.AddTrades(trades) {
+				if year == 0 || disposal.DisposedTime.In(time.Local).Year() == year {
+					disposals = append(disposals, disposal)
+				}
+			}
+		}
+
+		report := costbasis.NewTaxReport(disposals, time.Local)
+
+		if disposalsOutput != "" {
+			if err := writeCsvFile(disposalsOutput, func(w *csv.Writer) error {
+				if err := w.Write(costbasis.Disposal{}.CsvHeader()); err != nil {
+					return err
+				}
+
+				for _, disposal := range disposals {
+					if err := w.WriteAll(disposal.CsvRecords()); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		return writeCsvFile(output, func(w *csv.Writer) error {
+			if err := w.Write(report.CsvHeader()); err != nil {
+				return err
+			}
+
+			return w.WriteAll(report.CsvRecords())
+		})
+	},
+}
+
+// writeCsvFile writes the csv to the file, or to stdout if the filename is empty
+func writeCsvFile(filename string, write func(w *csv.Writer) error) error {
+	var out io.Writer = os.Stdout
+	if filename != "" {
+		f, err := os.Create(filename)
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+		out = f
+	}
+
+	w := csv.NewWriter(out)
+	if err := write(w); err != nil {
+		return err
+	}
+
+	w.Flush()
+	return w.Error()
+}
@@ -0,0 +1,74 @@
+package binanceapi
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type DustTransferResult struct {
+	Amount              fixedpoint.Value           `json:"amount"`
+	FromAsset           string                     `json:"fromAsset"`
+	OperateTime         types.MillisecondTimestamp `json:"operateTime"`
+	ServiceChargeAmount fixedpoint.Value           `json:"serviceChargeAmount"`
+	TranId              int64                      `json:"tranId"`
+	TransferedAmount    fixedpoint.Value           `json:"transferedAmount"`
+}
+
+type DustTransferResponse struct {
+	TotalServiceCharge fixedpoint.Value     `json:"totalServiceCharge"`
+	TotalTransfered    fixedpoint.Value     `json:"totalTransfered"`
+	TransferResult     []DustTransferResult `json:"transferResult"`
+}
+
+// DustTransferRequest converts the small balances into BNB.
+//
+// This request is not generated by requestgen, since the assets are sent as the repeated "asset" parameters,
+// e.g., asset=BTC&asset=USDT, which requestgen does not support.
+type DustTransferRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	assets []string
+}
+
+func (c *RestClient) NewDustTransferRequest() *DustTransferRequest {
+	return &DustTransferRequest{client: c}
+}
+
+func (r *DustTransferRequest) Assets(assets ...string) *DustTransferRequest {
+	r.assets = assets
+	return r
+}
+
+func (r *DustTransferRequest) Do(ctx context.Context) (*DustTransferResponse, error) {
+	if len(r.assets) == 0 {
+		return nil, errors.New("asset is required")
+	}
+
+	params := url.Values{}
+	for _, asset := range r.assets {
+		params.Add("asset", asset)
+	}
+
+	req, err := r.client.NewAuthenticatedRequest(ctx, "POST", "/sapi/v1/asset/dust", nil, params.Encode())
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse DustTransferResponse
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+
+	return &apiResponse, nil
+}
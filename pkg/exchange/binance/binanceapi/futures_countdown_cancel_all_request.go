@@ -0,0 +1,25 @@
+package binanceapi
+
+import (
+	"github.com/c9s/requestgen"
+)
+
+type FuturesCountdownCancelAllResponse struct {
+	Symbol        string `json:"symbol"`
+	CountdownTime string `json:"countdownTime"`
+}
+
+// FuturesCountdownCancelAllRequest cancels all the open orders of the symbol when the countdown ends,
+// the countdown time is in milliseconds, and zero cancels the timer.
+//
+//go:generate requestgen -method POST -url "/fapi/v1/countdownCancelAll" -type FuturesCountdownCancelAllRequest -responseType FuturesCountdownCancelAllResponse
+type FuturesCountdownCancelAllRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	symbol        string `param:"symbol"`
+	countdownTime int64  `param:"countdownTime"`
+}
+
+func (c *FuturesRestClient) NewFuturesCountdownCancelAllRequest() *FuturesCountdownCancelAllRequest {
+	return &FuturesCountdownCancelAllRequest{client: c}
+}
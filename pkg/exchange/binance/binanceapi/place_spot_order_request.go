@@ -0,0 +1,40 @@
+package binanceapi
+
+import (
+	"github.com/adshao/go-binance/v2"
+	"github.com/c9s/requestgen"
+)
+
+type SelfTradePreventionMode string
+
+const (
+	SelfTradePreventionModeNone        SelfTradePreventionMode = "NONE"
+	SelfTradePreventionModeExpireTaker SelfTradePreventionMode = "EXPIRE_TAKER"
+	SelfTradePreventionModeExpireMaker SelfTradePreventionMode = "EXPIRE_MAKER"
+	SelfTradePreventionModeExpireBoth  SelfTradePreventionMode = "EXPIRE_BOTH"
+)
+
+type CreateOrderResponse = binance.CreateOrderResponse
+
+// PlaceSpotOrderRequest places the spot order with the parameters that are not supported by the go-binance client,
+// e.g., the self-trade prevention mode.
+//
+//go:generate requestgen -method POST -url "/api/v3/order" -type PlaceSpotOrderRequest -responseType .CreateOrderResponse
+type PlaceSpotOrderRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	symbol                  string                   `param:"symbol"`
+	side                    SideType                 `param:"side"`
+	orderType               OrderType                `param:"type"`
+	quantity                *string                  `param:"quantity"`
+	price                   *string                  `param:"price"`
+	stopPrice               *string                  `param:"stopPrice"`
+	timeInForce             *string                  `param:"timeInForce"`
+	newClientOrderId        *string                  `param:"newClientOrderId"`
+	newOrderRespType        *OrderRespType           `param:"newOrderRespType"`
+	selfTradePreventionMode *SelfTradePreventionMode `param:"selfTradePreventionMode"`
+}
+
+func (c *RestClient) NewPlaceSpotOrderRequest() *PlaceSpotOrderRequest {
+	return &PlaceSpotOrderRequest{client: c}
+}
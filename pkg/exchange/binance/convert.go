@@ -9,6 +9,7 @@ import (
 	"github.com/adshao/go-binance/v2/futures"
 	"github.com/pkg/errors"
 
+	"github.com/c9s/bbgo/pkg/exchange/binance/binanceapi"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -149,6 +150,21 @@ func toLocalOrderType(orderType types.OrderType) (binance.OrderType, error) {
 	return "", fmt.Errorf("can not convert to local order, order type %s not supported", orderType)
 }
 
+func toLocalSelfTradePreventionMode(mode types.SelfTradePreventionMode) (binanceapi.SelfTradePreventionMode, error) {
+	switch mode {
+	case types.SelfTradePreventionExpireTaker:
+		return binanceapi.SelfTradePreventionModeExpireTaker, nil
+
+	case types.SelfTradePreventionExpireMaker:
+		return binanceapi.SelfTradePreventionModeExpireMaker, nil
+
+	case types.SelfTradePreventionExpireBoth:
+		return binanceapi.SelfTradePreventionModeExpireBoth, nil
+	}
+
+	return "", fmt.Errorf("self-trade prevention mode %s not supported", mode)
+}
+
 func toGlobalOrders(binanceOrders []*binance.Order, isMargin bool) (orders []types.Order, err error) {
 	for _, binanceOrder := range binanceOrders {
 		order, err := toGlobalOrder(binanceOrder, isMargin)
@@ -340,6 +356,12 @@ func convertSubscription(s types.Subscription) string {
 		return fmt.Sprintf("%s@trade", strings.ToLower(s.Symbol))
 	case types.AggTradeChannel:
 		return fmt.Sprintf("%s@aggTrade", strings.ToLower(s.Symbol))
+	case types.LiquidationChannel:
+		// "<symbol>@forceOrder" for one symbol, "!forceOrder@arr" for all the symbols
+		if s.Symbol == "" {
+			return "!forceOrder@arr"
+		}
+		return fmt.Sprintf("%s@forceOrder", strings.ToLower(s.Symbol))
 	}
 
 	return fmt.Sprintf("%s@%s", strings.ToLower(s.Symbol), s.Channel)
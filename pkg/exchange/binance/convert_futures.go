@@ -40,7 +40,8 @@ func toGlobalFuturesBalance(balances []*futures.Balance) types.BalanceMap {
 func toGlobalFuturesPositions(futuresPositions []*futures.AccountPosition) types.FuturesPositionMap {
 	retFuturesPositions := make(types.FuturesPositionMap)
 	for _, futuresPosition := range futuresPositions {
-		retFuturesPositions[futuresPosition.Symbol] = types.FuturesPosition{ // TODO: types.FuturesPosition
+		positionSide := types.PositionSide(futuresPosition.PositionSide)
+		retFuturesPositions[types.FuturesPositionKey(futuresPosition.Symbol, positionSide)] = types.FuturesPosition{ // TODO: types.FuturesPosition
 			Isolated:               futuresPosition.Isolated,
 			AverageCost:            fixedpoint.MustNewFromString(futuresPosition.EntryPrice),
 			ApproximateAverageCost: fixedpoint.MustNewFromString(futuresPosition.EntryPrice),
@@ -50,8 +51,9 @@ func toGlobalFuturesPositions(futuresPositions []*futures.AccountPosition) types
 			PositionRisk: &types.PositionRisk{
 				Leverage: fixedpoint.MustNewFromString(futuresPosition.Leverage),
 			},
-			Symbol:     futuresPosition.Symbol,
-			UpdateTime: futuresPosition.UpdateTime,
+			Symbol:       futuresPosition.Symbol,
+			PositionSide: positionSide,
+			UpdateTime:   futuresPosition.UpdateTime,
 		}
 	}
 
@@ -121,6 +123,7 @@ func toGlobalFuturesOrder(futuresOrder *futures.Order, isIsolated bool) (*types.
 			Type:          toGlobalFuturesOrderType(futuresOrder.Type),
 			ReduceOnly:    futuresOrder.ReduceOnly,
 			ClosePosition: futuresOrder.ClosePosition,
+			PositionSide:  types.PositionSide(futuresOrder.PositionSide),
 			Quantity:      fixedpoint.MustNewFromString(futuresOrder.OrigQuantity),
 			Price:         fixedpoint.MustNewFromString(futuresOrder.Price),
 			TimeInForce:   types.TimeInForce(futuresOrder.TimeInForce),
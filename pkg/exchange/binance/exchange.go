@@ -23,6 +23,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/c9s/bbgo/pkg/exchange/binance/binanceapi"
+	"github.com/c9s/bbgo/pkg/exchange/metrics"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 	"github.com/c9s/bbgo/pkg/util"
@@ -54,6 +55,8 @@ func init() {
 	_ = types.Exchange(&Exchange{})
 	_ = types.MarginExchange(&Exchange{})
 	_ = types.FuturesExchange(&Exchange{})
+	_ = types.MarginBorrowRepayService(&Exchange{})
+	_ = types.IsolatedMarginTransferService(&Exchange{})
 
 	if n, ok := util.GetEnvVarInt("BINANCE_ORDER_RATE_LIMITER"); ok {
 		orderLimiter = rate.NewLimiter(rate.Every(time.Duration(n)*time.Minute), 2)
@@ -64,6 +67,18 @@ func init() {
 	}
 }
 
+// spotRateLimitHeaders and futuresRateLimitHeaders are the used weight and order count headers of the REST responses,
+// the limits are the default limits of the binance api
+var spotRateLimitHeaders = []metrics.RateLimitHeader{
+	{Name: "weight_1m", UsedHeader: "X-Mbx-Used-Weight-1m", Limit: 6000},
+	{Name: "order_count_10s", UsedHeader: "X-Mbx-Order-Count-10s", Limit: 100},
+}
+
+var futuresRateLimitHeaders = []metrics.RateLimitHeader{
+	{Name: "futures_weight_1m", UsedHeader: "X-Mbx-Used-Weight-1m", Limit: 2400},
+	{Name: "futures_order_count_1m", UsedHeader: "X-Mbx-Order-Count-1m", Limit: 1200},
+}
+
 func isBinanceUs() bool {
 	v, err := strconv.ParseBool(os.Getenv("BINANCE_US"))
 	return err == nil && v
@@ -169,6 +184,30 @@ func (e *Exchange) Name() types.ExchangeName {
 	return types.ExchangeBinance
 }
 
+// EnableMetrics instruments the spot and the futures REST clients with the session label
+func (e *Exchange) EnableMetrics(session string) {
+	name := e.Name().String()
+	e.client.HTTPClient = metrics.InstrumentClient(e.client.HTTPClient, name, session, spotRateLimitHeaders...)
+	e.client2.HttpClient = metrics.InstrumentClient(e.client2.HttpClient, name, session, spotRateLimitHeaders...)
+	e.futuresClient.HTTPClient = metrics.InstrumentClient(e.futuresClient.HTTPClient, name, session, futuresRateLimitHeaders...)
+	e.futuresClient2.HttpClient = metrics.InstrumentClient(e.futuresClient2.HttpClient, name, session, futuresRateLimitHeaders...)
+}
+
+// SetCredentials replaces the api key of the spot and the futures clients, the stream shares the same clients
+func (e *Exchange) SetCredentials(key, secret, passphrase string) {
+	e.key = key
+	// pragma: allowlist nextline secret
+	e.secret = secret
+	e.client.APIKey = key
+	// pragma: allowlist nextline secret
+	e.client.SecretKey = secret
+	e.futuresClient.APIKey = key
+	// pragma: allowlist nextline secret
+	e.futuresClient.SecretKey = secret
+	e.client2.Auth(key, secret)
+	e.futuresClient2.Auth(key, secret)
+}
+
 func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
 	if e.IsFutures {
 		req := e.futuresClient.NewListPriceChangeStatsService()
@@ -395,6 +434,39 @@ func (e *Exchange) transferCrossMarginAccountAsset(ctx context.Context, asset st
 	return err
 }
 
+// TransferIsolatedMarginAccountAsset transfers asset to the isolated margin account of the isolated margin symbol or back to the spot account
+func (e *Exchange) TransferIsolatedMarginAccountAsset(ctx context.Context, asset string, amount fixedpoint.Value, io types.TransferDirection) error {
+	if !e.IsIsolatedMargin {
+		return fmt.Errorf("isolated margin is not enabled, can not transfer %s", asset)
+	}
+
+	req := e.client.NewIsolatedMarginTransferService()
+	req.Symbol(e.IsolatedMarginSymbol)
+	req.Asset(asset)
+	req.Amount(amount.String())
+
+	if io == types.TransferIn {
+		req.TransFrom(binance.AccountTypeSpot)
+		req.TransTo(binance.AccountTypeIsolatedMargin)
+	} else if io == types.TransferOut {
+		req.TransFrom(binance.AccountTypeIsolatedMargin)
+		req.TransTo(binance.AccountTypeSpot)
+	} else {
+		return fmt.Errorf("unexpected transfer direction: %d given", io)
+	}
+
+	resp, err := req.Do(ctx)
+
+	switch io {
+	case types.TransferIn:
+		log.Infof("internal transfer (spot) => (isolated margin %s) %s %s, transaction = %+v, err = %+v", e.IsolatedMarginSymbol, amount.String(), asset, resp, err)
+	case types.TransferOut:
+		log.Infof("internal transfer (isolated margin %s) => (spot) %s %s, transaction = %+v, err = %+v", e.IsolatedMarginSymbol, amount.String(), asset, resp, err)
+	}
+
+	return err
+}
+
 func (e *Exchange) QueryCrossMarginAccount(ctx context.Context) (*types.Account, error) {
 	marginAccount, err := e.client.NewGetMarginAccountService().Do(ctx)
 	if err != nil {
@@ -507,6 +579,32 @@ func (e *Exchange) Withdraw(ctx context.Context, asset string, amount fixedpoint
 	return nil
 }
 
+// ConvertDust converts the small balances into BNB via the dust transfer api
+func (e *Exchange) ConvertDust(ctx context.Context, assets []string) (*types.DustConversion, error) {
+	resp, err := e.client2.NewDustTransferRequest().Assets(assets...).Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conversion := &types.DustConversion{
+		Currency: "BNB",
+		Amount:   resp.TotalTransfered,
+		Fee:      resp.TotalServiceCharge,
+	}
+
+	for _, result := range resp.TransferResult {
+		conversion.Details = append(conversion.Details, types.DustConversionDetail{
+			FromCurrency: result.FromAsset,
+			FromAmount:   result.Amount,
+			Amount:       result.TransferedAmount,
+			Fee:          result.ServiceChargeAmount,
+			Time:         result.OperateTime.Time(),
+		})
+	}
+
+	return conversion, nil
+}
+
 func (e *Exchange) QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (withdraws []types.Withdraw, err error) {
 	var emptyTime = time.Time{}
 	if since == emptyTime {
@@ -1003,6 +1101,10 @@ func newSpotClientOrderID(originalID string) (clientOrderID string) {
 }
 
 func (e *Exchange) submitSpotOrder(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+	if order.SelfTradePrevention != types.SelfTradePreventionNone {
+		return e.submitSpotOrderWithSelfTradePrevention(ctx, order)
+	}
+
 	orderType, err := toLocalOrderType(order.Type)
 	if err != nil {
 		return nil, err
@@ -1064,8 +1166,79 @@ func (e *Exchange) submitSpotOrder(ctx context.Context, order types.SubmitOrder)
 	}
 
 	log.Infof("spot order creation response: %+v", response)
+	return toGlobalCreatedSpotOrder(response)
+}
 
-	createdOrder, err := toGlobalOrder(&binance.Order{
+// submitSpotOrderWithSelfTradePrevention submits the spot order with the binance api client,
+// the go-binance client doesn't support the self-trade prevention mode.
+func (e *Exchange) submitSpotOrderWithSelfTradePrevention(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+	orderType, err := toLocalOrderType(order.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	selfTradePreventionMode, err := toLocalSelfTradePreventionMode(order.SelfTradePrevention)
+	if err != nil {
+		return nil, err
+	}
+
+	req := e.client2.NewPlaceSpotOrderRequest()
+	req.Symbol(order.Symbol)
+	req.Side(binance.SideType(order.Side))
+	req.OrderType(orderType)
+	req.SelfTradePreventionMode(selfTradePreventionMode)
+
+	clientOrderID := newSpotClientOrderID(order.ClientOrderID)
+	if len(clientOrderID) > 0 {
+		req.NewClientOrderId(clientOrderID)
+	}
+
+	if order.Market.Symbol != "" {
+		req.Quantity(order.Market.FormatQuantity(order.Quantity))
+	} else {
+		req.Quantity(order.Quantity.FormatString(8))
+	}
+
+	switch order.Type {
+	case types.OrderTypeStopLimit, types.OrderTypeLimit, types.OrderTypeLimitMaker:
+		if order.Market.Symbol != "" {
+			req.Price(order.Market.FormatPrice(order.Price))
+		} else {
+			req.Price(order.Price.FormatString(8))
+		}
+	}
+
+	switch order.Type {
+	case types.OrderTypeStopLimit, types.OrderTypeStopMarket:
+		if order.Market.Symbol != "" {
+			req.StopPrice(order.Market.FormatPrice(order.StopPrice))
+		} else {
+			req.StopPrice(order.StopPrice.FormatString(8))
+		}
+	}
+
+	if len(order.TimeInForce) > 0 {
+		req.TimeInForce(string(order.TimeInForce))
+	} else {
+		switch order.Type {
+		case types.OrderTypeLimit, types.OrderTypeStopLimit:
+			req.TimeInForce(string(binance.TimeInForceTypeGTC))
+		}
+	}
+
+	req.NewOrderRespType(binanceapi.Result)
+
+	response, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Infof("spot order creation response: %+v", response)
+	return toGlobalCreatedSpotOrder(response)
+}
+
+func toGlobalCreatedSpotOrder(response *binance.CreateOrderResponse) (*types.Order, error) {
+	return toGlobalOrder(&binance.Order{
 		Symbol:                   response.Symbol,
 		OrderID:                  response.OrderID,
 		ClientOrderID:            response.ClientOrderID,
@@ -1081,8 +1254,6 @@ func (e *Exchange) submitSpotOrder(ctx context.Context, order types.SubmitOrder)
 		Time:                     response.TransactTime,
 		IsIsolated:               response.IsIsolated,
 	}, false)
-
-	return createdOrder, err
 }
 
 func (e *Exchange) SubmitOrder(ctx context.Context, order types.SubmitOrder) (createdOrder *types.Order, err error) {
@@ -1090,6 +1261,10 @@ func (e *Exchange) SubmitOrder(ctx context.Context, order types.SubmitOrder) (cr
 		log.WithError(err).Errorf("order rate limiter wait error")
 	}
 
+	if (e.IsMargin || e.IsFutures) && order.SelfTradePrevention != types.SelfTradePreventionNone {
+		log.Warnf("self-trade prevention is only supported by the binance spot orders, %s is ignored", order.SelfTradePrevention)
+	}
+
 	if e.IsMargin {
 		createdOrder, err = e.submitMarginOrder(ctx, order)
 	} else if e.IsFutures {
@@ -135,7 +135,12 @@ func (e *Exchange) submitFuturesOrder(ctx context.Context, order types.SubmitOrd
 		Type(orderType).
 		Side(futures.SideType(order.Side))
 
-	if order.ReduceOnly {
+	if order.PositionSide != "" {
+		req.PositionSide(futures.PositionSideType(order.PositionSide))
+	}
+
+	// reduceOnly can not be sent in the hedge mode, the position side determines the reduction
+	if order.ReduceOnly && !order.PositionSide.IsHedge() {
 		req.ReduceOnly(order.ReduceOnly)
 	} else if order.ClosePosition {
 		req.ClosePosition(order.ClosePosition)
@@ -211,6 +216,8 @@ func (e *Exchange) submitFuturesOrder(ctx context.Context, order types.SubmitOrd
 		Type:             response.Type,
 		Side:             response.Side,
 		ReduceOnly:       response.ReduceOnly,
+		ClosePosition:    response.ClosePosition,
+		PositionSide:     response.PositionSide,
 	}, false)
 
 	return createdOrder, err
@@ -335,6 +342,30 @@ func (e *Exchange) QueryFuturesPositionRisks(ctx context.Context, symbol string)
 // BBGO is a futures broker on Binance
 const futuresBrokerID = "gBhMvywy"
 
+// CancelAllAfter arms the countdown cancel-all timer of the symbols, it's only supported by the futures api
+func (e *Exchange) CancelAllAfter(ctx context.Context, timeout time.Duration, symbols ...string) error {
+	if !e.IsFutures {
+		return fmt.Errorf("cancel-on-disconnect is only supported by the binance futures account")
+	}
+
+	if len(symbols) == 0 {
+		return fmt.Errorf("binance countdown cancel-all requires the symbols")
+	}
+
+	var err error
+	for _, symbol := range symbols {
+		_, err2 := e.futuresClient2.NewFuturesCountdownCancelAllRequest().
+			Symbol(symbol).
+			CountdownTime(timeout.Milliseconds()).
+			Do(ctx)
+		if err2 != nil {
+			err = multierr.Append(err, fmt.Errorf("can not set the countdown cancel-all of %s: %w", symbol, err2))
+		}
+	}
+
+	return err
+}
+
 func newFuturesClientOrderID(originalID string) (clientOrderID string) {
 	if originalID == types.NoClientOrderID {
 		return ""
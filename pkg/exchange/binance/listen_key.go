@@ -0,0 +1,286 @@
+package binance
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/metrics"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+const (
+	// listenKeyValidity is the validity of a listen key since its last keepalive
+	listenKeyValidity = 60 * time.Minute
+
+	// listenKeyRenewMargin renews the listen key if the keepalive keeps failing and the key expires within the margin
+	listenKeyRenewMargin = 20 * time.Minute
+
+	// listenKeyIdleGap is the silent period of the user data streams to verify the listen key with the exchange,
+	// a listen key that expired silently stops pushing the events without closing the connection.
+	listenKeyIdleGap = 30 * time.Minute
+)
+
+type listenKeyService interface {
+	fetchListenKey(ctx context.Context) (string, error)
+	keepaliveListenKey(ctx context.Context, listenKey string) error
+	closeListenKey(ctx context.Context, listenKey string) error
+}
+
+type listenKeySubscriber interface {
+	Reconnect()
+	lastUserDataEventTime() time.Time
+}
+
+// listenKeyEntry is a listen key shared by the user data streams of the same api key and account scope
+type listenKeyEntry struct {
+	scope   string
+	service listenKeyService
+
+	mu            sync.Mutex
+	listenKey     string
+	lastKeepAlive time.Time
+	subscribers   map[listenKeySubscriber]struct{}
+
+	done chan struct{}
+}
+
+// get returns the current listen key, a new listen key is created if it's not created or it's renewed
+func (e *listenKeyEntry) get(ctx context.Context) (string, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.listenKey != "" {
+		return e.listenKey, nil
+	}
+
+	listenKey, err := e.service.fetchListenKey(ctx)
+	if err != nil {
+		metrics.IncListenKeyFailures(types.ExchangeBinance.String(), e.scope, "create")
+		return "", err
+	}
+
+	log.Debugf("%s listen key is created: %s", e.scope, util.MaskKey(listenKey))
+	e.listenKey = listenKey
+	e.lastKeepAlive = time.Now()
+	return listenKey, nil
+}
+
+// replace replaces the listen key and resubscribes the streams, it's a no-op if the listen key is already replaced.
+// An empty new listen key makes the streams create a new one when they reconnect.
+func (e *listenKeyEntry) replace(oldListenKey, newListenKey, reason string) {
+	e.mu.Lock()
+	if e.listenKey != oldListenKey {
+		e.mu.Unlock()
+		return
+	}
+
+	e.listenKey = newListenKey
+	e.lastKeepAlive = time.Now()
+
+	var subscribers []listenKeySubscriber
+	for subscriber := range e.subscribers {
+		subscribers = append(subscribers, subscriber)
+	}
+	e.mu.Unlock()
+
+	log.Warnf("%s listen key %s is renewed (%s), resubscribing %d user data streams", e.scope, util.MaskKey(oldListenKey), reason, len(subscribers))
+	metrics.IncListenKeyRenewals(types.ExchangeBinance.String(), e.scope, reason)
+
+	for _, subscriber := range subscribers {
+		subscriber.Reconnect()
+	}
+}
+
+func (e *listenKeyEntry) lastEventTime() (lastEventTime time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for subscriber := range e.subscribers {
+		if t := subscriber.lastUserDataEventTime(); t.After(lastEventTime) {
+			lastEventTime = t
+		}
+	}
+
+	return lastEventTime
+}
+
+// listenKeyPool shares the listen keys between the user data streams of the same api key and account scope,
+// so that the streams don't invalidate the listen keys of each other,
+// and keeps the listen keys alive with one worker per listen key.
+type listenKeyPool struct {
+	mu      sync.Mutex
+	entries map[string]*listenKeyEntry
+
+	keepAliveInterval time.Duration
+	retryDelay        time.Duration
+}
+
+var defaultListenKeyPool = newListenKeyPool(listenKeyKeepAliveInterval, 5*time.Second)
+
+func newListenKeyPool(keepAliveInterval, retryDelay time.Duration) *listenKeyPool {
+	return &listenKeyPool{
+		entries:           make(map[string]*listenKeyEntry),
+		keepAliveInterval: keepAliveInterval,
+		retryDelay:        retryDelay,
+	}
+}
+
+// Acquire subscribes the listen key of the pool key and returns it, acquiring the same key again is a no-op
+func (p *listenKeyPool) Acquire(ctx context.Context, poolKey, scope string, service listenKeyService, subscriber listenKeySubscriber) (string, error) {
+	p.mu.Lock()
+	entry, ok := p.entries[poolKey]
+	if !ok {
+		entry = &listenKeyEntry{
+			scope:       scope,
+			service:     service,
+			subscribers: make(map[listenKeySubscriber]struct{}),
+			done:        make(chan struct{}),
+		}
+		p.entries[poolKey] = entry
+		go p.keepAlive(entry)
+	}
+
+	entry.mu.Lock()
+	entry.subscribers[subscriber] = struct{}{}
+	entry.mu.Unlock()
+	p.mu.Unlock()
+
+	return entry.get(ctx)
+}
+
+// Release unsubscribes the listen key, the listen key is closed when there is no subscriber
+func (p *listenKeyPool) Release(poolKey string, subscriber listenKeySubscriber) {
+	p.mu.Lock()
+	entry, ok := p.entries[poolKey]
+	if !ok {
+		p.mu.Unlock()
+		return
+	}
+
+	entry.mu.Lock()
+	delete(entry.subscribers, subscriber)
+	if len(entry.subscribers) > 0 {
+		entry.mu.Unlock()
+		p.mu.Unlock()
+		return
+	}
+
+	delete(p.entries, poolKey)
+	close(entry.done)
+	listenKey := entry.listenKey
+	entry.mu.Unlock()
+	p.mu.Unlock()
+
+	if listenKey == "" {
+		return
+	}
+
+	// should use background context to invalidate the user stream
+	if err := entry.service.closeListenKey(context.Background(), listenKey); err != nil {
+		log.WithError(err).Errorf("close listen key error: %v key: %s", err, util.MaskKey(listenKey))
+	}
+}
+
+// Expire renews the listen key that is reported as expired by the stream
+func (p *listenKeyPool) Expire(poolKey, listenKey string) {
+	p.mu.Lock()
+	entry, ok := p.entries[poolKey]
+	p.mu.Unlock()
+
+	if ok {
+		entry.replace(listenKey, "", "expired")
+	}
+}
+
+// keepAlive
+// From Binance
+// Keepalive a user data stream to prevent a time out. User data streams will close after 60 minutes.
+// It's recommended to send a ping about every 30 minutes.
+func (p *listenKeyPool) keepAlive(entry *listenKeyEntry) {
+	ticker := time.NewTicker(p.keepAliveInterval)
+	defer ticker.Stop()
+
+	log.Debugf("starting %s listen key keep alive worker with interval %s", entry.scope, p.keepAliveInterval)
+
+	for {
+		select {
+		case <-entry.done:
+			log.Debugf("%s listen key keepalive worker stopped", entry.scope)
+			return
+
+		case <-ticker.C:
+			p.check(context.Background(), entry)
+		}
+	}
+}
+
+// check keeps the listen key alive, and renews it before it expires if the keepalive keeps failing.
+// If the streams are silent for a long time, the listen key is verified with the exchange to detect the silent expiry.
+func (p *listenKeyPool) check(ctx context.Context, entry *listenKeyEntry) {
+	entry.mu.Lock()
+	listenKey := entry.listenKey
+	lastKeepAlive := entry.lastKeepAlive
+	entry.mu.Unlock()
+
+	// the listen key is being renewed, the streams create the new one when they reconnect
+	if listenKey == "" {
+		return
+	}
+
+	if err := p.keepaliveWithRetry(ctx, entry, listenKey); err != nil {
+		metrics.IncListenKeyFailures(types.ExchangeBinance.String(), entry.scope, "keepalive")
+
+		_, isNetworkError := err.(net.Error)
+		if !isNetworkError || time.Until(lastKeepAlive.Add(listenKeyValidity)) < listenKeyRenewMargin {
+			entry.replace(listenKey, "", "keepalive_failure")
+		}
+		return
+	}
+
+	entry.mu.Lock()
+	if entry.listenKey == listenKey {
+		entry.lastKeepAlive = time.Now()
+	}
+	entry.mu.Unlock()
+
+	if time.Since(entry.lastEventTime()) < listenKeyIdleGap {
+		return
+	}
+
+	// the exchange returns the active listen key of the account, a different key means ours is expired
+	activeListenKey, err := entry.service.fetchListenKey(ctx)
+	if err != nil {
+		metrics.IncListenKeyFailures(types.ExchangeBinance.String(), entry.scope, "create")
+		log.WithError(err).Errorf("can not verify %s listen key", entry.scope)
+		return
+	}
+
+	if activeListenKey != listenKey {
+		entry.replace(listenKey, activeListenKey, "silent_expiry")
+	}
+}
+
+func (p *listenKeyPool) keepaliveWithRetry(ctx context.Context, entry *listenKeyEntry, listenKey string) (err error) {
+	for i := 0; i < 5; i++ {
+		err = entry.service.keepaliveListenKey(ctx, listenKey)
+		if err == nil {
+			return nil
+		}
+
+		switch err.(type) {
+		case net.Error:
+			log.WithError(err).Errorf("listen key keep-alive network error: %v key: %s", err, util.MaskKey(listenKey))
+			time.Sleep(p.retryDelay)
+			continue
+
+		default:
+			log.WithError(err).Errorf("listen key keep-alive unexpected error: %v key: %s", err, util.MaskKey(listenKey))
+			return err
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,139 @@
+package binance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testListenKeyService struct {
+	listenKeys   []string
+	fetches      int
+	keepaliveErr error
+	closed       []string
+}
+
+func (s *testListenKeyService) fetchListenKey(ctx context.Context) (string, error) {
+	listenKey := s.listenKeys[s.fetches%len(s.listenKeys)]
+	s.fetches++
+	return listenKey, nil
+}
+
+func (s *testListenKeyService) keepaliveListenKey(ctx context.Context, listenKey string) error {
+	return s.keepaliveErr
+}
+
+func (s *testListenKeyService) closeListenKey(ctx context.Context, listenKey string) error {
+	s.closed = append(s.closed, listenKey)
+	return nil
+}
+
+type testListenKeySubscriber struct {
+	reconnects int
+	lastEvent  time.Time
+}
+
+func (s *testListenKeySubscriber) Reconnect() {
+	s.reconnects++
+}
+
+func (s *testListenKeySubscriber) lastUserDataEventTime() time.Time {
+	return s.lastEvent
+}
+
+func TestListenKeyPool_AcquireRelease(t *testing.T) {
+	pool := newListenKeyPool(time.Hour, time.Millisecond)
+	service := &testListenKeyService{listenKeys: []string{"key1"}}
+	s1 := &testListenKeySubscriber{}
+	s2 := &testListenKeySubscriber{}
+
+	ctx := context.Background()
+	listenKey, err := pool.Acquire(ctx, "api:spot", "spot", service, s1)
+	assert.NoError(t, err)
+	assert.Equal(t, "key1", listenKey)
+
+	// the streams of the same api key and scope share the listen key
+	listenKey, err = pool.Acquire(ctx, "api:spot", "spot", service, s2)
+	assert.NoError(t, err)
+	assert.Equal(t, "key1", listenKey)
+	assert.Equal(t, 1, service.fetches)
+
+	pool.Release("api:spot", s1)
+	assert.Empty(t, service.closed)
+
+	pool.Release("api:spot", s2)
+	assert.Equal(t, []string{"key1"}, service.closed)
+	assert.Empty(t, pool.entries)
+}
+
+func TestListenKeyPool_check(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("keepalive failure", func(t *testing.T) {
+		pool := newListenKeyPool(time.Hour, time.Millisecond)
+		service := &testListenKeyService{listenKeys: []string{"key1", "key2"}}
+		subscriber := &testListenKeySubscriber{lastEvent: time.Now()}
+
+		_, err := pool.Acquire(ctx, "api:spot", "spot", service, subscriber)
+		assert.NoError(t, err)
+		defer pool.Release("api:spot", subscriber)
+
+		entry := pool.entries["api:spot"]
+		pool.check(ctx, entry)
+		assert.Equal(t, 0, subscriber.reconnects)
+
+		// the listen key does not exist
+		service.keepaliveErr = errors.New("<APIError> code=-1125, msg=This listenKey does not exist.")
+		pool.check(ctx, entry)
+		assert.Equal(t, 1, subscriber.reconnects)
+
+		// the stream creates a new listen key when it reconnects
+		listenKey, err := pool.Acquire(ctx, "api:spot", "spot", service, subscriber)
+		assert.NoError(t, err)
+		assert.Equal(t, "key2", listenKey)
+	})
+
+	t.Run("silent expiry", func(t *testing.T) {
+		pool := newListenKeyPool(time.Hour, time.Millisecond)
+		service := &testListenKeyService{listenKeys: []string{"key1", "key1", "key2"}}
+		subscriber := &testListenKeySubscriber{lastEvent: time.Now().Add(-time.Hour)}
+
+		_, err := pool.Acquire(ctx, "api:futures", "futures", service, subscriber)
+		assert.NoError(t, err)
+		defer pool.Release("api:futures", subscriber)
+
+		entry := pool.entries["api:futures"]
+
+		// the active listen key is not changed
+		pool.check(ctx, entry)
+		assert.Equal(t, 0, subscriber.reconnects)
+
+		pool.check(ctx, entry)
+		assert.Equal(t, 1, subscriber.reconnects)
+		assert.Equal(t, "key2", entry.listenKey)
+	})
+
+	t.Run("expired event", func(t *testing.T) {
+		pool := newListenKeyPool(time.Hour, time.Millisecond)
+		service := &testListenKeyService{listenKeys: []string{"key1", "key2"}}
+		subscribers := []*testListenKeySubscriber{{}, {}}
+
+		for i, subscriber := range subscribers {
+			_, err := pool.Acquire(ctx, "api:margin", "margin", service, subscriber)
+			assert.NoError(t, err, fmt.Sprintf("subscriber %d", i))
+			defer pool.Release("api:margin", subscriber)
+		}
+
+		pool.Expire("api:margin", "key1")
+		// the expired event of the other stream is ignored after the listen key is renewed
+		pool.Expire("api:margin", "key1")
+
+		for _, subscriber := range subscribers {
+			assert.Equal(t, 1, subscriber.reconnects)
+		}
+	})
+}
@@ -380,6 +380,11 @@ func parseWebSocketEvent(message []byte) (interface{}, error) {
 		err = json.Unmarshal([]byte(message), &event)
 		return &event, err
 
+	case "forceOrder":
+		var event ForceOrderEvent
+		err = json.Unmarshal([]byte(message), &event)
+		return &event, err
+
 	// futures user data stream
 	// ========================================================
 	case "ORDER_TRADE_UPDATE":
@@ -764,6 +769,60 @@ type MarkPriceUpdateEvent struct {
 }
 */
 
+type ForceOrderEvent struct {
+	EventBase
+
+	Order struct {
+		Symbol         string                     `json:"s"`
+		Side           futures.SideType           `json:"S"`
+		OrderType      futures.OrderType          `json:"o"`
+		TimeInForce    futures.TimeInForceType    `json:"f"`
+		Quantity       fixedpoint.Value           `json:"q"`
+		Price          fixedpoint.Value           `json:"p"`
+		AveragePrice   fixedpoint.Value           `json:"ap"`
+		Status         futures.OrderStatusType    `json:"X"`
+		LastFilledQty  fixedpoint.Value           `json:"l"`
+		AccumulatedQty fixedpoint.Value           `json:"z"`
+		TradeTime      types.MillisecondTimestamp `json:"T"`
+	} `json:"o"`
+}
+
+/*
+{
+  "e":"forceOrder",         // Event Type
+  "E":1568014460893,        // Event Time
+  "o":{
+    "s":"BTCUSDT",          // Symbol
+    "S":"SELL",             // Side
+    "o":"LIMIT",            // Order Type
+    "f":"IOC",              // Time in Force
+    "q":"0.014",            // Original Quantity
+    "p":"9910",             // Price
+    "ap":"9910",            // Average Price
+    "X":"FILLED",           // Order Status
+    "l":"0.014",            // Order Last Filled Quantity
+    "z":"0.014",            // Order Filled Accumulated Quantity
+    "T":1568014460893       // Order Trade Time
+  }
+}
+*/
+
+func (e *ForceOrderEvent) LiquidationInfo() types.LiquidationInfo {
+	o := e.Order
+	return types.LiquidationInfo{
+		Exchange:         types.ExchangeBinance,
+		Symbol:           o.Symbol,
+		Side:             toGlobalFuturesSideType(o.Side),
+		Type:             toGlobalFuturesOrderType(o.OrderType),
+		Price:            o.Price,
+		AveragePrice:     o.AveragePrice,
+		Quantity:         o.Quantity,
+		ExecutedQuantity: o.AccumulatedQty,
+		Status:           toGlobalFuturesOrderStatus(o.Status),
+		TradeTime:        types.Time(o.TradeTime.Time()),
+	}
+}
+
 type ContinuousKLineEvent struct {
 	EventBase
 	Symbol string `json:"ps"`
@@ -906,6 +965,7 @@ func (e *OrderTradeUpdateEvent) OrderFutures() (*types.Order, error) {
 			Quantity:      e.OrderTrade.OriginalQuantity,
 			Price:         e.OrderTrade.OriginalPrice,
 			TimeInForce:   types.TimeInForce(e.OrderTrade.TimeInForce),
+			PositionSide:  types.PositionSide(e.OrderTrade.PositionSide),
 		},
 		OrderID:          uint64(e.OrderTrade.OrderId),
 		Status:           toGlobalFuturesOrderStatus(futures.OrderStatusType(e.OrderTrade.CurrentOrderStatus)),
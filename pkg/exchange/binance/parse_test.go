@@ -412,3 +412,41 @@ func TestParseOrderFuturesUpdate(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, orderUpdate)
 }
+
+func TestParseForceOrderEvent(t *testing.T) {
+	payload := `{
+		"e":"forceOrder",
+		"E":1568014460893,
+		"o":{
+			"s":"BTCUSDT",
+			"S":"SELL",
+			"o":"LIMIT",
+			"f":"IOC",
+			"q":"0.014",
+			"p":"9910",
+			"ap":"9910",
+			"X":"FILLED",
+			"l":"0.014",
+			"z":"0.014",
+			"T":1568014460893
+		}
+	}`
+
+	event, err := parseWebSocketEvent([]byte(payload))
+	assert.NoError(t, err)
+
+	forceOrderEvent, ok := event.(*ForceOrderEvent)
+	if assert.True(t, ok) {
+		info := forceOrderEvent.LiquidationInfo()
+		assert.Equal(t, "BTCUSDT", info.Symbol)
+		assert.Equal(t, types.SideTypeSell, info.Side)
+		assert.Equal(t, types.OrderTypeLimit, info.Type)
+		assert.Equal(t, types.OrderStatusFilled, info.Status)
+		assert.Equal(t, fixedpoint.MustNewFromString("0.014"), info.ExecutedQuantity)
+		assert.Equal(t, fixedpoint.MustNewFromString("138.74"), info.QuoteQuantity())
+		assert.Equal(t, time.UnixMilli(1568014460893), info.TradeTime.Time())
+	}
+
+	assert.Equal(t, "btcusdt@forceOrder", convertSubscription(types.Subscription{Symbol: "BTCUSDT", Channel: types.LiquidationChannel}))
+	assert.Equal(t, "!forceOrder@arr", convertSubscription(types.Subscription{Channel: types.LiquidationChannel}))
+}
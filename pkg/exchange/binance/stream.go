@@ -2,7 +2,8 @@ package binance
 
 import (
 	"context"
-	"net"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/c9s/bbgo/pkg/depth"
@@ -57,6 +58,7 @@ type Stream struct {
 
 	// futures market data stream
 	markPriceUpdateEventCallbacks       []func(e *MarkPriceUpdateEvent)
+	forceOrderEventCallbacks            []func(e *ForceOrderEvent)
 	continuousKLineEventCallbacks       []func(e *ContinuousKLineEvent)
 	continuousKLineClosedEventCallbacks []func(e *ContinuousKLineEvent)
 
@@ -69,6 +71,14 @@ type Stream struct {
 
 	// depthBuffers is used for storing the depth info
 	depthBuffers map[string]*depth.Buffer
+
+	// listenKeyMu protects the pool key and the listen key acquired from the listen key pool
+	listenKeyMu      sync.Mutex
+	listenKeyPoolKey string
+	listenKey        string
+
+	// lastUserDataEvent is the unix nano time of the last user data event, used for detecting the silent expiry
+	lastUserDataEvent int64
 }
 
 func NewStream(ex *Exchange, client *binance.Client, futuresClient *futures.Client) *Stream {
@@ -125,6 +135,7 @@ func NewStream(ex *Exchange, client *binance.Client, futuresClient *futures.Clie
 	stream.OnContinuousKLineEvent(stream.handleContinuousKLineEvent)
 	stream.OnMarketTradeEvent(stream.handleMarketTradeEvent)
 	stream.OnAggTradeEvent(stream.handleAggTradeEvent)
+	stream.OnForceOrderEvent(stream.handleForceOrderEvent)
 
 	// Futures User Data Stream
 	// ===================================
@@ -135,6 +146,11 @@ func NewStream(ex *Exchange, client *binance.Client, futuresClient *futures.Clie
 	stream.OnDisconnect(stream.handleDisconnect)
 	stream.OnConnect(stream.handleConnect)
 	stream.OnListenKeyExpired(func(e *ListenKeyExpired) {
+		stream.listenKeyMu.Lock()
+		poolKey, listenKey := stream.listenKeyPoolKey, stream.listenKey
+		stream.listenKeyMu.Unlock()
+
+		defaultListenKeyPool.Expire(poolKey, listenKey)
 		stream.Reconnect()
 	})
 	return stream
@@ -149,6 +165,7 @@ func (s *Stream) handleDisconnect() {
 
 func (s *Stream) handleConnect() {
 	if !s.PublicOnly {
+		atomic.StoreInt64(&s.lastUserDataEvent, time.Now().UnixNano())
 		return
 	}
 
@@ -229,6 +246,10 @@ func (s *Stream) handleAggTradeEvent(e *AggTradeEvent) {
 	s.EmitAggTrade(e.Trade())
 }
 
+func (s *Stream) handleForceOrderEvent(e *ForceOrderEvent) {
+	s.EmitLiquidation(e.LiquidationInfo())
+}
+
 func (s *Stream) handleKLineEvent(e *KLineEvent) {
 	kline := e.KLine.KLine()
 	if e.KLine.Closed {
@@ -313,20 +334,87 @@ func (s *Stream) createEndpoint(ctx context.Context) (string, error) {
 	if s.PublicOnly {
 		log.Debugf("stream is set to public only mode")
 	} else {
-		listenKey, err = s.fetchListenKey(ctx)
+		listenKey, err = s.acquireListenKey(ctx)
 		if err != nil {
 			return "", err
 		}
-
-		log.Debugf("listen key is created: %s", util.MaskKey(listenKey))
-		go s.listenKeyKeepAlive(ctx, listenKey)
 	}
 
 	url := s.getEndpointUrl(listenKey)
 	return url, nil
 }
 
+// listenKeyScope is the account scope of the listen key
+func (s *Stream) listenKeyScope() string {
+	if s.IsMargin {
+		if s.IsIsolatedMargin {
+			return "isolated_margin:" + s.IsolatedMarginSymbol
+		}
+		return "margin"
+	} else if s.IsFutures {
+		return "futures"
+	}
+
+	return "spot"
+}
+
+// acquireListenKey acquires the listen key from the pool, the pool key is changed when the api key is rotated
+func (s *Stream) acquireListenKey(ctx context.Context) (string, error) {
+	apiKey := s.client.APIKey
+	if s.IsFutures {
+		apiKey = s.futuresClient.APIKey
+	}
+
+	scope := s.listenKeyScope()
+	poolKey := apiKey + ":" + scope
+
+	s.listenKeyMu.Lock()
+	prevPoolKey := s.listenKeyPoolKey
+	s.listenKeyPoolKey = poolKey
+	s.listenKeyMu.Unlock()
+
+	if prevPoolKey == "" {
+		go s.releaseListenKeyOnClose(ctx)
+	} else if prevPoolKey != poolKey {
+		defaultListenKeyPool.Release(prevPoolKey, s)
+	}
+
+	listenKey, err := defaultListenKeyPool.Acquire(ctx, poolKey, scope, s, s)
+	if err != nil {
+		return "", err
+	}
+
+	s.listenKeyMu.Lock()
+	s.listenKey = listenKey
+	s.listenKeyMu.Unlock()
+	return listenKey, nil
+}
+
+// releaseListenKeyOnClose releases the listen key when the stream is closed, the listen key is closed by the pool
+// if there is no other stream using it.
+func (s *Stream) releaseListenKeyOnClose(ctx context.Context) {
+	select {
+	case <-ctx.Done():
+	case <-s.CloseC:
+	}
+
+	s.listenKeyMu.Lock()
+	poolKey := s.listenKeyPoolKey
+	s.listenKeyPoolKey = ""
+	s.listenKeyMu.Unlock()
+
+	defaultListenKeyPool.Release(poolKey, s)
+}
+
+func (s *Stream) lastUserDataEventTime() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&s.lastUserDataEvent))
+}
+
 func (s *Stream) dispatchEvent(e interface{}) {
+	if !s.PublicOnly {
+		atomic.StoreInt64(&s.lastUserDataEvent, time.Now().UnixNano())
+	}
+
 	switch e := e.(type) {
 
 	case *OutboundAccountPositionEvent:
@@ -359,6 +447,9 @@ func (s *Stream) dispatchEvent(e interface{}) {
 	case *MarkPriceUpdateEvent:
 		s.EmitMarkPriceUpdateEvent(e)
 
+	case *ForceOrderEvent:
+		s.EmitForceOrderEvent(e)
+
 	case *ContinuousKLineEvent:
 		s.EmitContinuousKLineEvent(e)
 
@@ -442,55 +533,3 @@ func (s *Stream) closeListenKey(ctx context.Context, listenKey string) (err erro
 
 	return err
 }
-
-// listenKeyKeepAlive
-// From Binance
-// Keepalive a user data stream to prevent a time out. User data streams will close after 60 minutes.
-// It's recommended to send a ping about every 30 minutes.
-func (s *Stream) listenKeyKeepAlive(ctx context.Context, listenKey string) {
-	keepAliveTicker := time.NewTicker(listenKeyKeepAliveInterval)
-	defer keepAliveTicker.Stop()
-
-	// if we exit, we should invalidate the existing listen key
-	defer func() {
-		log.Debugf("keepalive worker stopped")
-		if err := s.closeListenKey(context.Background(), listenKey); err != nil {
-			log.WithError(err).Errorf("close listen key error: %v key: %s", err, util.MaskKey(listenKey))
-		}
-	}()
-
-	log.Debugf("starting listen key keep alive worker with interval %s, listen key = %s", listenKeyKeepAliveInterval, util.MaskKey(listenKey))
-
-	for {
-		select {
-
-		case <-s.CloseC:
-			return
-
-		case <-ctx.Done():
-			return
-
-		case <-keepAliveTicker.C:
-			for i := 0; i < 5; i++ {
-				err := s.keepaliveListenKey(ctx, listenKey)
-				if err != nil {
-					time.Sleep(5 * time.Second)
-					switch err.(type) {
-					case net.Error:
-						log.WithError(err).Errorf("listen key keep-alive network error: %v key: %s", err, util.MaskKey(listenKey))
-						continue
-
-					default:
-						log.WithError(err).Errorf("listen key keep-alive unexpected error: %v key: %s", err, util.MaskKey(listenKey))
-						s.Reconnect()
-						return
-
-					}
-				} else {
-					break
-				}
-			}
-
-		}
-	}
-}
@@ -114,6 +114,16 @@ func (s *Stream) EmitMarkPriceUpdateEvent(e *MarkPriceUpdateEvent) {
 	}
 }
 
+func (s *Stream) OnForceOrderEvent(cb func(e *ForceOrderEvent)) {
+	s.forceOrderEventCallbacks = append(s.forceOrderEventCallbacks, cb)
+}
+
+func (s *Stream) EmitForceOrderEvent(e *ForceOrderEvent) {
+	for _, cb := range s.forceOrderEventCallbacks {
+		cb(e)
+	}
+}
+
 func (s *Stream) OnContinuousKLineEvent(cb func(e *ContinuousKLineEvent)) {
 	s.continuousKLineEventCallbacks = append(s.continuousKLineEventCallbacks, cb)
 }
@@ -207,6 +217,8 @@ type StreamEventHub interface {
 
 	OnMarkPriceUpdateEvent(cb func(e *MarkPriceUpdateEvent))
 
+	OnForceOrderEvent(cb func(e *ForceOrderEvent))
+
 	OnContinuousKLineEvent(cb func(e *ContinuousKLineEvent))
 
 	OnContinuousKLineClosedEvent(cb func(e *ContinuousKLineEvent))
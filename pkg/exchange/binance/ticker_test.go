@@ -6,6 +6,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/recorder"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
 )
 
 func TestExchange_QueryTickers_AllSymbols(t *testing.T) {
@@ -52,3 +55,26 @@ func TestExchange_QueryTickers_SingleSymbol(t *testing.T) {
 		assert.Len(t, got, 1, "binance: attempting to get one symbol, but number of tickers do not match")
 	}
 }
+
+func TestExchange_QueryTicker_Recorder(t *testing.T) {
+	r, err := recorder.New("testdata/recorder-ticker.json", recorder.ModeFromEnv())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	defer func() {
+		assert.NoError(t, r.Stop())
+	}()
+
+	e := New("", "")
+	e.client.HTTPClient = r.Client(e.client.HTTPClient)
+
+	ticker, err := e.QueryTicker(context.Background(), "BTCUSDT")
+	if assert.NoError(t, err) {
+		assert.Equal(t, fixedpoint.MustNewFromString("29101.00"), ticker.Last)
+		assert.Equal(t, fixedpoint.MustNewFromString("29100.10"), ticker.Buy)
+		assert.Equal(t, fixedpoint.MustNewFromString("29101.50"), ticker.Sell)
+	}
+
+	assert.Empty(t, r.Unreplayed())
+}
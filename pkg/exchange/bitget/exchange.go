@@ -4,6 +4,7 @@ import (
 	"github.com/sirupsen/logrus"
 
 	"github.com/c9s/bbgo/pkg/exchange/bitget/bitgetapi"
+	"github.com/c9s/bbgo/pkg/exchange/metrics"
 	"github.com/c9s/bbgo/pkg/types"
 )
 
@@ -40,6 +41,20 @@ func (e *Exchange) Name() types.ExchangeName {
 	return types.ExchangeBitget
 }
 
+// EnableMetrics instruments the REST client with the session label
+func (e *Exchange) EnableMetrics(session string) {
+	e.client.HttpClient = metrics.InstrumentClient(e.client.HttpClient, e.Name().String(), session)
+}
+
+// SetCredentials replaces the api key of the REST client, the stream shares the same client
+func (e *Exchange) SetCredentials(key, secret, passphrase string) {
+	e.key = key
+	// pragma: allowlist nextline secret
+	e.secret = secret
+	e.passphrase = passphrase
+	e.client.Auth(key, secret, passphrase)
+}
+
 func (e *Exchange) PlatformFeeCurrency() string {
 	return PlatformToken
 }
@@ -121,6 +121,9 @@ func convertSubscriptions(ss []types.Subscription) ([]WebSocketCommand, error) {
 		case types.KLineChannel:
 			subscribeTopic = "/market/candles" + ":" + toLocalSymbol(s.Symbol) + "_" + toLocalInterval(types.Interval(s.Options.Interval))
 
+		case types.MarketTradeChannel:
+			subscribeTopic = "/market/match" + ":" + toLocalSymbol(s.Symbol)
+
 		default:
 			return nil, fmt.Errorf("websocket channel %s is not supported by kucoin", s.Channel)
 		}
@@ -202,6 +205,21 @@ func toLocalSide(side types.SideType) kucoinapi.SideType {
 	return ""
 }
 
+func toLocalSelfTradePrevention(mode types.SelfTradePreventionMode) (kucoinapi.SelfTradePreventionType, error) {
+	switch mode {
+	case types.SelfTradePreventionExpireTaker:
+		return kucoinapi.SelfTradePreventionCancelNewest, nil
+
+	case types.SelfTradePreventionExpireMaker:
+		return kucoinapi.SelfTradePreventionCancelOldest, nil
+
+	case types.SelfTradePreventionExpireBoth:
+		return kucoinapi.SelfTradePreventionCancelBoth, nil
+	}
+
+	return "", fmt.Errorf("self-trade prevention mode %s is not supported by kucoin", mode)
+}
+
 func toGlobalOrder(o kucoinapi.Order) types.Order {
 	var status = toGlobalOrderStatus(o)
 	var order = types.Order{
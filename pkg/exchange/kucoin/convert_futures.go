@@ -0,0 +1,152 @@
+package kucoin
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/exchange/kucoin/kucoinapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// toLocalFuturesSymbol converts the global symbol to the perpetual contract symbol, e.g., BTCUSDT to XBTUSDTM
+func toLocalFuturesSymbol(symbol string) string {
+	if strings.HasPrefix(symbol, "BTC") {
+		symbol = "XBT" + strings.TrimPrefix(symbol, "BTC")
+	}
+
+	return symbol + "M"
+}
+
+// toGlobalFuturesSymbol converts the perpetual contract symbol to the global symbol, e.g., XBTUSDTM to BTCUSDT
+func toGlobalFuturesSymbol(symbol string) string {
+	symbol = strings.TrimSuffix(symbol, "M")
+	if strings.HasPrefix(symbol, "XBT") {
+		symbol = "BTC" + strings.TrimPrefix(symbol, "XBT")
+	}
+
+	return symbol
+}
+
+// toGlobalFuturesCurrency converts the futures currency, kucoin futures uses XBT for bitcoin
+func toGlobalFuturesCurrency(currency string) string {
+	if currency == "XBT" {
+		return "BTC"
+	}
+
+	return currency
+}
+
+func toGlobalFuturesMarket(c kucoinapi.FuturesContract) types.Market {
+	// the order size of the futures api is in lots, one lot is the multiplier of the base currency
+	stepSize := c.LotSize.Mul(c.Multiplier)
+	return types.Market{
+		Symbol:          toGlobalFuturesSymbol(c.Symbol),
+		LocalSymbol:     c.Symbol,
+		PricePrecision:  c.TickSize.NumFractionalDigits(),
+		VolumePrecision: stepSize.NumFractionalDigits(),
+		QuoteCurrency:   toGlobalFuturesCurrency(c.QuoteCurrency),
+		BaseCurrency:    toGlobalFuturesCurrency(c.BaseCurrency),
+		MinNotional:     fixedpoint.Zero, // not used
+		MinAmount:       fixedpoint.Zero, // not used
+		MinQuantity:     stepSize,
+		MaxQuantity:     c.MaxOrderQty.Mul(c.Multiplier),
+		StepSize:        stepSize,
+
+		MinPrice: fixedpoint.Zero, // not used
+		MaxPrice: c.MaxPrice,
+		TickSize: c.TickSize,
+	}
+}
+
+func toGlobalFuturesTicker(c kucoinapi.FuturesContract) types.Ticker {
+	return types.Ticker{
+		Volume: c.VolumeOf24h,
+		Last:   c.LastTradePrice,
+		High:   c.HighPrice,
+		Low:    c.LowPrice,
+	}
+}
+
+// toLocalFuturesGranularity converts the interval to the kline granularity in minutes
+func toLocalFuturesGranularity(interval types.Interval) int {
+	return interval.Minutes()
+}
+
+func toGlobalFuturesPosition(p kucoinapi.FuturesPosition, multiplier fixedpoint.Value) types.FuturesPosition {
+	symbol := toGlobalFuturesSymbol(p.Symbol)
+	quoteCurrency := toGlobalFuturesCurrency(p.SettleCurrency)
+	base := p.CurrentQty.Mul(multiplier)
+	return types.FuturesPosition{
+		Symbol:                 symbol,
+		BaseCurrency:           strings.TrimSuffix(symbol, quoteCurrency),
+		QuoteCurrency:          quoteCurrency,
+		Base:                   base,
+		Quote:                  p.MarkValue,
+		AverageCost:            p.AvgEntryPrice,
+		ApproximateAverageCost: p.AvgEntryPrice,
+		Isolated:               p.MarginMode != kucoinapi.MarginModeCross && !p.CrossMode,
+		UpdateTime:             p.CurrentTimestamp.Time().UnixMilli(),
+		PositionRisk: &types.PositionRisk{
+			Leverage:         p.RealLeverage,
+			LiquidationPrice: p.LiquidationPrice,
+		},
+	}
+}
+
+func toGlobalFuturesOrder(o kucoinapi.Order, multiplier fixedpoint.Value) types.Order {
+	order := toGlobalOrder(o)
+	order.Symbol = toGlobalFuturesSymbol(o.Symbol)
+	order.Quantity = o.Size.Mul(multiplier)
+	order.ExecutedQuantity = o.DealSize.Mul(multiplier)
+	return order
+}
+
+func toGlobalFuturesTrade(fill kucoinapi.FuturesFill, multiplier fixedpoint.Value) types.Trade {
+	return types.Trade{
+		ID:            hashStringID(fill.TradeId),
+		OrderID:       hashStringID(fill.OrderId),
+		Exchange:      types.ExchangeKucoin,
+		Price:         fill.Price,
+		Quantity:      fill.Size.Mul(multiplier),
+		QuoteQuantity: fill.Value,
+		Symbol:        toGlobalFuturesSymbol(fill.Symbol),
+		Side:          toGlobalSide(string(fill.Side)),
+		IsBuyer:       fill.Side == kucoinapi.SideTypeBuy,
+		IsMaker:       fill.Liquidity == kucoinapi.LiquidityTypeMaker,
+		IsFutures:     true,
+		Time:          types.Time(fill.CreatedAt.Time()),
+		Fee:           fill.Fee,
+		FeeCurrency:   toGlobalFuturesCurrency(fill.FeeCurrency),
+	}
+}
+
+// convertFuturesSubscriptions converts the global subscriptions to the futures websocket commands,
+// only the kline channel is supported by the futures stream for now.
+func convertFuturesSubscriptions(ss []types.Subscription) ([]WebSocketCommand, error) {
+	var id = time.Now().UnixNano() / int64(time.Millisecond)
+	var cmds []WebSocketCommand
+	for _, s := range ss {
+		id++
+
+		var subscribeTopic string
+		switch s.Channel {
+		case types.KLineChannel:
+			subscribeTopic = "/contractMarket/limitCandle" + ":" + toLocalFuturesSymbol(s.Symbol) + "_" + toLocalInterval(types.Interval(s.Options.Interval))
+
+		default:
+			return nil, fmt.Errorf("websocket channel %s is not supported by kucoin futures", s.Channel)
+		}
+
+		cmds = append(cmds, WebSocketCommand{
+			Id:             id,
+			Type:           WebSocketMessageTypeSubscribe,
+			Topic:          subscribeTopic,
+			PrivateChannel: false,
+			Response:       true,
+		})
+	}
+
+	return cmds, nil
+}
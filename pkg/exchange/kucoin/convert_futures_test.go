@@ -0,0 +1,46 @@
+package kucoin
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func Test_toLocalFuturesSymbol(t *testing.T) {
+	assert.Equal(t, "XBTUSDTM", toLocalFuturesSymbol("BTCUSDT"))
+	assert.Equal(t, "ETHUSDTM", toLocalFuturesSymbol("ETHUSDT"))
+
+	assert.Equal(t, "BTCUSDT", toGlobalFuturesSymbol("XBTUSDTM"))
+	assert.Equal(t, "ETHUSDT", toGlobalFuturesSymbol("ETHUSDTM"))
+}
+
+func Test_parseFuturesPositionEvent(t *testing.T) {
+	payload, err := os.ReadFile("testdata/futures-01-position-change.json")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	e, err := parseWebSocketEvent(payload)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	positionEvent, ok := e.(*WebSocketEvent).Object.(*WebSocketFuturesPositionEvent)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	assert.Equal(t, "positionChange", positionEvent.ChangeReason)
+
+	position := toGlobalFuturesPosition(positionEvent.FuturesPosition, fixedpoint.NewFromFloat(0.001))
+	assert.Equal(t, "BTCUSDT", position.Symbol)
+	assert.Equal(t, "BTC", position.BaseCurrency)
+	assert.Equal(t, "USDT", position.QuoteCurrency)
+	assert.Equal(t, "-0.02", position.Base.String())
+	assert.Equal(t, "42706", position.AverageCost.String())
+	assert.Equal(t, "46820", position.PositionRisk.LiquidationPrice.String())
+	assert.True(t, position.Isolated)
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -13,6 +14,7 @@ import (
 	"golang.org/x/time/rate"
 
 	"github.com/c9s/bbgo/pkg/exchange/kucoin/kucoinapi"
+	"github.com/c9s/bbgo/pkg/exchange/metrics"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -21,6 +23,11 @@ var marketDataLimiter = rate.NewLimiter(rate.Every(6*time.Second), 1)
 var queryTradeLimiter = rate.NewLimiter(rate.Every(6*time.Second), 1)
 var queryOrderLimiter = rate.NewLimiter(rate.Every(6*time.Second), 1)
 
+// rateLimitHeaders is the resource pool quota of the kucoin gateway
+var rateLimitHeaders = []metrics.RateLimitHeader{
+	{Name: "gateway", RemainingHeader: "Gw-Ratelimit-Remaining", LimitHeader: "Gw-Ratelimit-Limit"},
+}
+
 var ErrMissingSequence = errors.New("sequence is missing")
 
 // OKB is the platform currency of OKEx, pre-allocate static string here
@@ -31,24 +38,40 @@ var log = logrus.WithFields(logrus.Fields{
 })
 
 type Exchange struct {
+	types.FuturesSettings
+
 	key, secret, passphrase string
 	client                  *kucoinapi.RestClient
+	futuresClient           *kucoinapi.RestClient
+
+	futuresMutex sync.Mutex
+
+	// futuresMultipliers is the lot multipliers of the futures contracts, keyed by the contract symbol
+	futuresMultipliers map[string]fixedpoint.Value
+
+	// futuresLeverages is the order leverage of the futures symbols
+	futuresLeverages map[string]int
 }
 
 func New(key, secret, passphrase string) *Exchange {
 	client := kucoinapi.NewClient()
+	futuresClient := kucoinapi.NewFuturesClient()
 
 	// for public access mode
 	if len(key) > 0 && len(secret) > 0 && len(passphrase) > 0 {
 		client.Auth(key, secret, passphrase)
+		futuresClient.Auth(key, secret, passphrase)
 	}
 
 	return &Exchange{
 		key: key,
 		// pragma: allowlist nextline secret
-		secret:     secret,
-		passphrase: passphrase,
-		client:     client,
+		secret:             secret,
+		passphrase:         passphrase,
+		client:             client,
+		futuresClient:      futuresClient,
+		futuresMultipliers: make(map[string]fixedpoint.Value),
+		futuresLeverages:   make(map[string]int),
 	}
 }
 
@@ -56,11 +79,31 @@ func (e *Exchange) Name() types.ExchangeName {
 	return types.ExchangeKucoin
 }
 
+// EnableMetrics instruments the REST client with the session label
+func (e *Exchange) EnableMetrics(session string) {
+	e.client.HttpClient = metrics.InstrumentClient(e.client.HttpClient, e.Name().String(), session, rateLimitHeaders...)
+	e.futuresClient.HttpClient = metrics.InstrumentClient(e.futuresClient.HttpClient, e.Name().String(), session, rateLimitHeaders...)
+}
+
+// SetCredentials replaces the api key of the REST client, the stream shares the same client
+func (e *Exchange) SetCredentials(key, secret, passphrase string) {
+	e.key = key
+	// pragma: allowlist nextline secret
+	e.secret = secret
+	e.passphrase = passphrase
+	e.client.Auth(key, secret, passphrase)
+	e.futuresClient.Auth(key, secret, passphrase)
+}
+
 func (e *Exchange) PlatformFeeCurrency() string {
 	return KCS
 }
 
 func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
+	if e.IsFutures {
+		return e.QueryFuturesAccount(ctx)
+	}
+
 	req := e.client.AccountService.NewListAccountsRequest()
 	accounts, err := req.Do(ctx)
 	if err != nil {
@@ -75,6 +118,15 @@ func (e *Exchange) QueryAccount(ctx context.Context) (*types.Account, error) {
 }
 
 func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap, error) {
+	if e.IsFutures {
+		account, err := e.QueryFuturesAccount(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return account.Balances(), nil
+	}
+
 	req := e.client.AccountService.NewListAccountsRequest()
 	accounts, err := req.Do(ctx)
 	if err != nil {
@@ -85,6 +137,10 @@ func (e *Exchange) QueryAccountBalances(ctx context.Context) (types.BalanceMap,
 }
 
 func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
+	if e.IsFutures {
+		return e.queryFuturesMarkets(ctx)
+	}
+
 	markets, err := e.client.MarketDataService.ListSymbols()
 	if err != nil {
 		return nil, err
@@ -100,6 +156,10 @@ func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
 }
 
 func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	if e.IsFutures {
+		return e.queryFuturesTicker(ctx, symbol)
+	}
+
 	s, err := e.client.MarketDataService.GetTicker24HStat(symbol)
 	if err != nil {
 		return nil, err
@@ -124,6 +184,10 @@ func (e *Exchange) QueryTickers(ctx context.Context, symbols ...string) (map[str
 		return tickers, nil
 	}
 
+	if e.IsFutures {
+		return e.queryFuturesTickers(ctx)
+	}
+
 	allTickers, err := e.client.MarketDataService.ListTickers()
 	if err != nil {
 		return nil, err
@@ -161,6 +225,10 @@ func (e *Exchange) IsSupportedInterval(interval types.Interval) bool {
 }
 
 func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	if e.IsFutures {
+		return e.queryFuturesKLines(ctx, symbol, interval, options)
+	}
+
 	if err := marketDataLimiter.Wait(ctx); err != nil {
 		return nil, err
 	}
@@ -208,6 +276,10 @@ func (e *Exchange) QueryKLines(ctx context.Context, symbol string, interval type
 }
 
 func (e *Exchange) SubmitOrder(ctx context.Context, order types.SubmitOrder) (createdOrder *types.Order, err error) {
+	if e.IsFutures {
+		return e.submitFuturesOrder(ctx, order)
+	}
+
 	req := e.client.TradeService.NewPlaceOrderRequest()
 	req.Symbol(toLocalSymbol(order.Symbol))
 	req.Side(toLocalSide(order.Side))
@@ -238,6 +310,15 @@ func (e *Exchange) SubmitOrder(ctx context.Context, order types.SubmitOrder) (cr
 		req.PostOnly(true)
 	}
 
+	if order.SelfTradePrevention != types.SelfTradePreventionNone {
+		selfTradePrevention, err := toLocalSelfTradePrevention(order.SelfTradePrevention)
+		if err != nil {
+			return nil, err
+		}
+
+		req.SelfTradePrevention(selfTradePrevention)
+	}
+
 	switch order.TimeInForce {
 	case "FOK":
 		req.TimeInForce(kucoinapi.TimeInForceFOK)
@@ -298,6 +379,10 @@ The history for cancelled orders is only kept for one month.
 You will not be able to query for cancelled orders that have happened more than a month ago.
 */
 func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders []types.Order, err error) {
+	if e.IsFutures {
+		return e.queryFuturesOpenOrders(ctx, symbol)
+	}
+
 	req := e.client.TradeService.NewListOrdersRequest()
 	req.Symbol(toLocalSymbol(symbol))
 	req.Status("active")
@@ -316,6 +401,10 @@ func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders [
 }
 
 func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since, until time.Time, lastOrderID uint64) (orders []types.Order, err error) {
+	if e.IsFutures {
+		return e.queryFuturesClosedOrders(ctx, symbol, since, until)
+	}
+
 	req := e.client.TradeService.NewListOrdersRequest()
 	req.Symbol(toLocalSymbol(symbol))
 	req.Status("done")
@@ -350,6 +439,10 @@ func (e *Exchange) QueryClosedOrders(ctx context.Context, symbol string, since,
 var launchDate = time.Date(2017, 9, 0, 0, 0, 0, 0, time.UTC)
 
 func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	if e.IsFutures {
+		return e.queryFuturesTrades(ctx, symbol, options)
+	}
+
 	req := e.client.TradeService.NewGetFillsRequest()
 	req.Symbol(toLocalSymbol(symbol))
 
@@ -391,6 +484,10 @@ func (e *Exchange) QueryTrades(ctx context.Context, symbol string, options *type
 }
 
 func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (errs error) {
+	if e.IsFutures {
+		return e.cancelFuturesOrders(ctx, orders...)
+	}
+
 	for _, o := range orders {
 		req := e.client.TradeService.NewCancelOrderRequest()
 
@@ -419,6 +516,10 @@ func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err
 }
 
 func (e *Exchange) NewStream() types.Stream {
+	if e.IsFutures {
+		return NewStream(e.futuresClient, e)
+	}
+
 	return NewStream(e.client, e)
 }
 
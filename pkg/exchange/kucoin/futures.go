@@ -0,0 +1,468 @@
+package kucoin
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"go.uber.org/multierr"
+
+	"github.com/c9s/bbgo/pkg/exchange/kucoin/kucoinapi"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// futuresSettleCurrency is the settle currency of the USDT-margined perpetual contracts
+const futuresSettleCurrency = "USDT"
+
+// futuresKLineLimit is the max number of the klines of one futures kline query
+const futuresKLineLimit = 500
+
+func (e *Exchange) queryFuturesMarkets(ctx context.Context) (types.MarketMap, error) {
+	contracts, err := e.futuresClient.FuturesService.NewGetContractsRequest().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	e.futuresMutex.Lock()
+	defer e.futuresMutex.Unlock()
+
+	marketMap := types.MarketMap{}
+	for _, c := range contracts {
+		e.futuresMultipliers[c.Symbol] = c.Multiplier
+		marketMap.Add(toGlobalFuturesMarket(c))
+	}
+
+	return marketMap, nil
+}
+
+// futuresMultiplier returns the lot multiplier of the contract, the contracts are queried if the multiplier is not loaded
+func (e *Exchange) futuresMultiplier(ctx context.Context, localSymbol string) (fixedpoint.Value, error) {
+	e.futuresMutex.Lock()
+	multiplier, ok := e.futuresMultipliers[localSymbol]
+	e.futuresMutex.Unlock()
+
+	if ok {
+		return multiplier, nil
+	}
+
+	if _, err := e.queryFuturesMarkets(ctx); err != nil {
+		return fixedpoint.Zero, err
+	}
+
+	e.futuresMutex.Lock()
+	multiplier, ok = e.futuresMultipliers[localSymbol]
+	e.futuresMutex.Unlock()
+
+	if !ok {
+		return fixedpoint.Zero, fmt.Errorf("kucoin futures contract %s not found", localSymbol)
+	}
+
+	return multiplier, nil
+}
+
+func (e *Exchange) queryFuturesTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
+	req := e.futuresClient.FuturesService.NewGetTickerRequest()
+	req.Symbol(toLocalFuturesSymbol(symbol))
+	t, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.Ticker{
+		Time: time.Unix(0, t.Time),
+		Last: t.Price,
+		Buy:  t.BestBidPrice,
+		Sell: t.BestAskPrice,
+	}, nil
+}
+
+func (e *Exchange) queryFuturesTickers(ctx context.Context) (map[string]types.Ticker, error) {
+	contracts, err := e.futuresClient.FuturesService.NewGetContractsRequest().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tickers := map[string]types.Ticker{}
+	for _, c := range contracts {
+		tickers[toGlobalFuturesSymbol(c.Symbol)] = toGlobalFuturesTicker(c)
+	}
+
+	return tickers, nil
+}
+
+func (e *Exchange) queryFuturesKLines(ctx context.Context, symbol string, interval types.Interval, options types.KLineQueryOptions) ([]types.KLine, error) {
+	localSymbol := toLocalFuturesSymbol(symbol)
+	multiplier, err := e.futuresMultiplier(ctx, localSymbol)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := marketDataLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req := e.futuresClient.FuturesService.NewGetKLinesRequest()
+	req.Symbol(localSymbol)
+	req.Granularity(toLocalFuturesGranularity(interval))
+	if options.StartTime != nil {
+		req.From(*options.StartTime)
+		req.To(options.StartTime.Add(futuresKLineLimit * interval.Duration()))
+	} else if options.EndTime != nil {
+		req.To(*options.EndTime)
+	}
+
+	ks, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var klines []types.KLine
+	for _, k := range ks {
+		// the volume of the futures klines is in lots
+		volume := k.Volume.Mul(multiplier)
+		klines = append(klines, types.KLine{
+			Exchange:  types.ExchangeKucoin,
+			Symbol:    symbol,
+			StartTime: types.Time(k.StartTime),
+			EndTime:   types.Time(k.StartTime.Time().Add(interval.Duration() - time.Millisecond)),
+			Interval:  interval,
+			Open:      k.Open,
+			Close:     k.Close,
+			High:      k.High,
+			Low:       k.Low,
+			Volume:    volume,
+			Closed:    true,
+		})
+	}
+
+	sort.Slice(klines, func(i, j int) bool {
+		return klines[i].StartTime.Before(klines[j].StartTime.Time())
+	})
+
+	return klines, nil
+}
+
+// QueryFuturesAccount queries the futures account overview of the settle currency and the positions
+func (e *Exchange) QueryFuturesAccount(ctx context.Context) (*types.Account, error) {
+	req := e.futuresClient.FuturesService.NewGetAccountOverviewRequest()
+	req.Currency(futuresSettleCurrency)
+	overview, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	positions, err := e.QueryFuturesPositions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	currency := toGlobalFuturesCurrency(overview.Currency)
+	walletBalance := overview.AccountEquity.Sub(overview.UnrealisedPNL)
+
+	a := &types.Account{
+		AccountType: types.AccountTypeFutures,
+		FuturesInfo: &types.FuturesAccountInfo{
+			Assets: types.FuturesAssetMap{
+				currency: types.FuturesUserAsset{
+					Asset:                  currency,
+					InitialMargin:          overview.PositionMargin.Add(overview.OrderMargin),
+					MarginBalance:          overview.MarginBalance,
+					MaxWithdrawAmount:      overview.AvailableBalance,
+					OpenOrderInitialMargin: overview.OrderMargin,
+					PositionInitialMargin:  overview.PositionMargin,
+					UnrealizedProfit:       overview.UnrealisedPNL,
+					WalletBalance:          walletBalance,
+				},
+			},
+			Positions:                   positions,
+			TotalInitialMargin:          overview.PositionMargin.Add(overview.OrderMargin),
+			TotalMarginBalance:          overview.MarginBalance,
+			TotalOpenOrderInitialMargin: overview.OrderMargin,
+			TotalPositionInitialMargin:  overview.PositionMargin,
+			TotalUnrealizedProfit:       overview.UnrealisedPNL,
+			TotalWalletBalance:          walletBalance,
+			UpdateTime:                  time.Now().UnixMilli(),
+		},
+		CanDeposit:  true,
+		CanTrade:    true,
+		CanWithdraw: true,
+	}
+
+	a.UpdateBalances(types.BalanceMap{
+		currency: {
+			Currency:  currency,
+			Available: overview.AvailableBalance,
+			Locked:    overview.PositionMargin.Add(overview.OrderMargin).Add(overview.FrozenFunds),
+		},
+	})
+	return a, nil
+}
+
+// QueryFuturesPositions queries the open positions of the futures account
+func (e *Exchange) QueryFuturesPositions(ctx context.Context) (types.FuturesPositionMap, error) {
+	positions, err := e.futuresClient.FuturesService.NewGetPositionsRequest().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	positionMap := types.FuturesPositionMap{}
+	for _, p := range positions {
+		if !p.IsOpen {
+			continue
+		}
+
+		multiplier, err := e.futuresMultiplier(ctx, p.Symbol)
+		if err != nil {
+			return nil, err
+		}
+
+		position := toGlobalFuturesPosition(p, multiplier)
+		positionMap[position.Symbol] = position
+	}
+
+	return positionMap, nil
+}
+
+// SetLeverage sets the leverage of the symbol. In the cross margin mode, the leverage is updated on the exchange,
+// in the isolated margin mode, kucoin applies the leverage to the position of the next order.
+func (e *Exchange) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	if leverage <= 0 {
+		return fmt.Errorf("invalid leverage %d", leverage)
+	}
+
+	if !e.IsIsolatedFutures {
+		req := e.futuresClient.FuturesService.NewChangeCrossLeverageRequest()
+		req.Symbol(toLocalFuturesSymbol(symbol))
+		req.Leverage(strconv.Itoa(leverage))
+		if _, err := req.Do(ctx); err != nil {
+			return err
+		}
+	}
+
+	e.futuresMutex.Lock()
+	e.futuresLeverages[symbol] = leverage
+	e.futuresMutex.Unlock()
+	return nil
+}
+
+// AddMargin deposits the margin to the isolated position of the symbol
+func (e *Exchange) AddMargin(ctx context.Context, symbol string, margin fixedpoint.Value) error {
+	req := e.futuresClient.FuturesService.NewDepositMarginRequest()
+	req.Symbol(toLocalFuturesSymbol(symbol))
+	req.Margin(margin.String())
+	req.BizNo(uuid.New().String())
+	_, err := req.Do(ctx)
+	return err
+}
+
+func (e *Exchange) futuresLeverage(symbol string) int {
+	e.futuresMutex.Lock()
+	defer e.futuresMutex.Unlock()
+
+	if leverage, ok := e.futuresLeverages[symbol]; ok {
+		return leverage
+	}
+
+	return 1
+}
+
+func (e *Exchange) submitFuturesOrder(ctx context.Context, order types.SubmitOrder) (*types.Order, error) {
+	localSymbol := toLocalFuturesSymbol(order.Symbol)
+	multiplier, err := e.futuresMultiplier(ctx, localSymbol)
+	if err != nil {
+		return nil, err
+	}
+
+	// the order size is in lots
+	lots := order.Quantity.Div(multiplier).Int64()
+	if lots <= 0 && !order.ClosePosition {
+		return nil, fmt.Errorf("order quantity %s is less than the contract multiplier %s", order.Quantity.String(), multiplier.String())
+	}
+
+	clientOrderID := order.ClientOrderID
+	if clientOrderID == "" {
+		clientOrderID = uuid.New().String()
+	}
+
+	req := e.futuresClient.FuturesService.NewPlaceOrderRequest()
+	req.ClientOrderID(clientOrderID)
+	req.Symbol(localSymbol)
+	req.Side(toLocalSide(order.Side))
+	req.Size(lots)
+	req.Leverage(strconv.Itoa(e.futuresLeverage(order.Symbol)))
+
+	if e.IsIsolatedFutures {
+		req.MarginMode(kucoinapi.MarginModeIsolated)
+	} else {
+		req.MarginMode(kucoinapi.MarginModeCross)
+	}
+
+	if order.ClosePosition {
+		// the close order closes the whole position, the size is ignored
+		req.CloseOrder(true)
+	} else if order.ReduceOnly {
+		req.ReduceOnly(true)
+	}
+
+	switch order.Type {
+	case types.OrderTypeLimit, types.OrderTypeLimitMaker:
+		req.OrderType(kucoinapi.OrderTypeLimit)
+		if order.Market.Symbol != "" {
+			req.Price(order.Market.FormatPrice(order.Price))
+		} else {
+			req.Price(order.Price.String())
+		}
+
+	case types.OrderTypeMarket:
+		req.OrderType(kucoinapi.OrderTypeMarket)
+
+	default:
+		return nil, fmt.Errorf("order type %s is not supported by kucoin futures", order.Type)
+	}
+
+	if order.Type == types.OrderTypeLimitMaker {
+		req.PostOnly(true)
+	}
+
+	if order.SelfTradePrevention != types.SelfTradePreventionNone {
+		selfTradePrevention, err := toLocalSelfTradePrevention(order.SelfTradePrevention)
+		if err != nil {
+			return nil, err
+		}
+
+		req.SelfTradePrevention(selfTradePrevention)
+	}
+
+	// kucoin futures only supports GTC and IOC
+	if order.TimeInForce == types.TimeInForceIOC {
+		req.TimeInForce(kucoinapi.TimeInForceIOC)
+	}
+
+	orderResponse, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	order.ClientOrderID = clientOrderID
+	order.Quantity = multiplier.Mul(fixedpoint.NewFromInt(lots))
+	return &types.Order{
+		SubmitOrder:      order,
+		Exchange:         types.ExchangeKucoin,
+		OrderID:          hashStringID(orderResponse.OrderID),
+		UUID:             orderResponse.OrderID,
+		Status:           types.OrderStatusNew,
+		ExecutedQuantity: fixedpoint.Zero,
+		IsWorking:        true,
+		IsFutures:        true,
+		CreationTime:     types.Time(time.Now()),
+		UpdateTime:       types.Time(time.Now()),
+	}, nil
+}
+
+func (e *Exchange) queryFuturesOrders(ctx context.Context, req *kucoinapi.ListFuturesOrdersRequest, localSymbol string) (orders []types.Order, err error) {
+	multiplier, err := e.futuresMultiplier(ctx, localSymbol)
+	if err != nil {
+		return nil, err
+	}
+
+	orderList, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range orderList.Items {
+		order := toGlobalFuturesOrder(o, multiplier)
+		order.IsFutures = true
+		orders = append(orders, order)
+	}
+
+	return orders, nil
+}
+
+func (e *Exchange) queryFuturesOpenOrders(ctx context.Context, symbol string) ([]types.Order, error) {
+	localSymbol := toLocalFuturesSymbol(symbol)
+	req := e.futuresClient.FuturesService.NewListOrdersRequest()
+	req.Symbol(localSymbol)
+	req.Status("active")
+	return e.queryFuturesOrders(ctx, req, localSymbol)
+}
+
+func (e *Exchange) queryFuturesClosedOrders(ctx context.Context, symbol string, since, until time.Time) ([]types.Order, error) {
+	localSymbol := toLocalFuturesSymbol(symbol)
+	req := e.futuresClient.FuturesService.NewListOrdersRequest()
+	req.Symbol(localSymbol)
+	req.Status("done")
+	req.StartAt(since)
+	if until.Sub(since) < 7*24*time.Hour {
+		req.EndAt(until)
+	}
+
+	if err := queryOrderLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	return e.queryFuturesOrders(ctx, req, localSymbol)
+}
+
+func (e *Exchange) queryFuturesTrades(ctx context.Context, symbol string, options *types.TradeQueryOptions) (trades []types.Trade, err error) {
+	localSymbol := toLocalFuturesSymbol(symbol)
+	multiplier, err := e.futuresMultiplier(ctx, localSymbol)
+	if err != nil {
+		return nil, err
+	}
+
+	req := e.futuresClient.FuturesService.NewGetFillsRequest()
+	req.Symbol(localSymbol)
+
+	if options.StartTime != nil {
+		req.StartAt(*options.StartTime)
+		if options.EndTime != nil && options.EndTime.Sub(*options.StartTime) < 7*24*time.Hour {
+			req.EndAt(*options.EndTime)
+		}
+	} else if options.EndTime != nil {
+		req.EndAt(*options.EndTime)
+	}
+
+	if err := queryTradeLimiter.Wait(ctx); err != nil {
+		return trades, err
+	}
+
+	response, err := req.Do(ctx)
+	if err != nil {
+		return trades, err
+	}
+
+	for _, fill := range response.Items {
+		trades = append(trades, toGlobalFuturesTrade(fill, multiplier))
+	}
+
+	return trades, nil
+}
+
+func (e *Exchange) cancelFuturesOrders(ctx context.Context, orders ...types.Order) (errs error) {
+	for _, o := range orders {
+		// the cancel by client order id api of kucoin futures is different from the spot api, only the order uuid is supported
+		if o.UUID == "" {
+			errs = multierr.Append(errs, fmt.Errorf("the order uuid is empty, order: %#v", o))
+			continue
+		}
+
+		req := e.futuresClient.TradeService.NewCancelOrderRequest()
+		req.OrderID(o.UUID)
+
+		response, err := req.Do(ctx)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+
+		log.Infof("cancelled futures orders: %v", response.CancelledOrderIDs)
+	}
+
+	return errors.Wrap(errs, "futures order cancel error")
+}
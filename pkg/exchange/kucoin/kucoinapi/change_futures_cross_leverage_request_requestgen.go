@@ -0,0 +1,149 @@
+// Code generated by "requestgen -method POST -responseType .APIResponse -responseDataField Data -url /api/v2/changeCrossUserLeverage -type ChangeFuturesCrossLeverageRequest -responseDataType bool"; DO NOT EDIT.
+
+package kucoinapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+func (c *ChangeFuturesCrossLeverageRequest) Symbol(symbol string) *ChangeFuturesCrossLeverageRequest {
+	c.symbol = symbol
+	return c
+}
+
+func (c *ChangeFuturesCrossLeverageRequest) Leverage(leverage string) *ChangeFuturesCrossLeverageRequest {
+	c.leverage = leverage
+	return c
+}
+
+// GetQueryParameters builds and checks the query parameters and returns url.Values
+func (c *ChangeFuturesCrossLeverageRequest) GetQueryParameters() (url.Values, error) {
+	var params = map[string]interface{}{}
+
+	query := url.Values{}
+	for k, v := range params {
+		query.Add(k, fmt.Sprintf("%v", v))
+	}
+
+	return query, nil
+}
+
+// GetParameters builds and checks the parameters and return the result in a map object
+func (c *ChangeFuturesCrossLeverageRequest) GetParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+	// check symbol field -> json key symbol
+	symbol := c.symbol
+
+	// TEMPLATE check-required
+	if len(symbol) == 0 {
+		return nil, fmt.Errorf("symbol is required, empty string given")
+	}
+	// END TEMPLATE check-required
+
+	// assign parameter of symbol
+	params["symbol"] = symbol
+	// check leverage field -> json key leverage
+	leverage := c.leverage
+
+	// TEMPLATE check-required
+	if len(leverage) == 0 {
+		return nil, fmt.Errorf("leverage is required, empty string given")
+	}
+	// END TEMPLATE check-required
+
+	// assign parameter of leverage
+	params["leverage"] = leverage
+
+	return params, nil
+}
+
+// GetParametersQuery converts the parameters from GetParameters into the url.Values format
+func (c *ChangeFuturesCrossLeverageRequest) GetParametersQuery() (url.Values, error) {
+	query := url.Values{}
+
+	params, err := c.GetParameters()
+	if err != nil {
+		return query, err
+	}
+
+	for k, v := range params {
+		query.Add(k, fmt.Sprintf("%v", v))
+	}
+
+	return query, nil
+}
+
+// GetParametersJSON converts the parameters from GetParameters into the JSON format
+func (c *ChangeFuturesCrossLeverageRequest) GetParametersJSON() ([]byte, error) {
+	params, err := c.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(params)
+}
+
+// GetSlugParameters builds and checks the slug parameters and return the result in a map object
+func (c *ChangeFuturesCrossLeverageRequest) GetSlugParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+
+	return params, nil
+}
+
+func (c *ChangeFuturesCrossLeverageRequest) applySlugsToUrl(url string, slugs map[string]string) string {
+	for k, v := range slugs {
+		needleRE := regexp.MustCompile(":" + k + "\\b")
+		url = needleRE.ReplaceAllString(url, v)
+	}
+
+	return url
+}
+
+func (c *ChangeFuturesCrossLeverageRequest) GetSlugsMap() (map[string]string, error) {
+	slugs := map[string]string{}
+	params, err := c.GetSlugParameters()
+	if err != nil {
+		return slugs, nil
+	}
+
+	for k, v := range params {
+		slugs[k] = fmt.Sprintf("%v", v)
+	}
+
+	return slugs, nil
+}
+
+func (c *ChangeFuturesCrossLeverageRequest) Do(ctx context.Context) (*bool, error) {
+
+	params, err := c.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+
+	apiURL := "/api/v2/changeCrossUserLeverage"
+
+	req, err := c.client.NewAuthenticatedRequest(ctx, "POST", apiURL, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.client.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse APIResponse
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+	var data bool
+	if err := json.Unmarshal(apiResponse.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
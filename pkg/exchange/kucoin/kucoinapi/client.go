@@ -20,6 +20,7 @@ import (
 const defaultHTTPTimeout = time.Second * 15
 const RestBaseURL = "https://api.kucoin.com/api"
 const SandboxRestBaseURL = "https://openapi-sandbox.kucoin.com/api"
+const FuturesRestBaseURL = "https://api-futures.kucoin.com"
 
 type RestClient struct {
 	requestgen.BaseAPIClient
@@ -31,10 +32,20 @@ type RestClient struct {
 	MarketDataService *MarketDataService
 	TradeService      *TradeService
 	BulletService     *BulletService
+	FuturesService    *FuturesService
 }
 
 func NewClient() *RestClient {
-	u, err := url.Parse(RestBaseURL)
+	return newClient(RestBaseURL)
+}
+
+// NewFuturesClient creates the client of the KuCoin Futures api, the futures api shares the authentication of the spot api
+func NewFuturesClient() *RestClient {
+	return newClient(FuturesRestBaseURL)
+}
+
+func newClient(baseURL string) *RestClient {
+	u, err := url.Parse(baseURL)
 	if err != nil {
 		panic(err)
 	}
@@ -53,6 +64,7 @@ func NewClient() *RestClient {
 	client.MarketDataService = &MarketDataService{client: client}
 	client.TradeService = &TradeService{client: client}
 	client.BulletService = &BulletService{client: client}
+	client.FuturesService = &FuturesService{client: client}
 	return client
 }
 
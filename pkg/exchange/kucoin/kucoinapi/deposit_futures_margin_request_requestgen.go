@@ -0,0 +1,165 @@
+// Code generated by "requestgen -method POST -responseType .APIResponse -responseDataField Data -url /api/v1/position/margin/deposit-margin -type DepositFuturesMarginRequest -responseDataType .FuturesPosition"; DO NOT EDIT.
+
+package kucoinapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+)
+
+func (d *DepositFuturesMarginRequest) Symbol(symbol string) *DepositFuturesMarginRequest {
+	d.symbol = symbol
+	return d
+}
+
+func (d *DepositFuturesMarginRequest) Margin(margin string) *DepositFuturesMarginRequest {
+	d.margin = margin
+	return d
+}
+
+func (d *DepositFuturesMarginRequest) BizNo(bizNo string) *DepositFuturesMarginRequest {
+	d.bizNo = bizNo
+	return d
+}
+
+// GetQueryParameters builds and checks the query parameters and returns url.Values
+func (d *DepositFuturesMarginRequest) GetQueryParameters() (url.Values, error) {
+	var params = map[string]interface{}{}
+
+	query := url.Values{}
+	for k, v := range params {
+		query.Add(k, fmt.Sprintf("%v", v))
+	}
+
+	return query, nil
+}
+
+// GetParameters builds and checks the parameters and return the result in a map object
+func (d *DepositFuturesMarginRequest) GetParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+	// check symbol field -> json key symbol
+	symbol := d.symbol
+
+	// TEMPLATE check-required
+	if len(symbol) == 0 {
+		return nil, fmt.Errorf("symbol is required, empty string given")
+	}
+	// END TEMPLATE check-required
+
+	// assign parameter of symbol
+	params["symbol"] = symbol
+	// check margin field -> json key margin
+	margin := d.margin
+
+	// TEMPLATE check-required
+	if len(margin) == 0 {
+		return nil, fmt.Errorf("margin is required, empty string given")
+	}
+	// END TEMPLATE check-required
+
+	// assign parameter of margin
+	params["margin"] = margin
+	// check bizNo field -> json key bizNo
+	bizNo := d.bizNo
+
+	// TEMPLATE check-required
+	if len(bizNo) == 0 {
+		return nil, fmt.Errorf("bizNo is required, empty string given")
+	}
+	// END TEMPLATE check-required
+
+	// assign parameter of bizNo
+	params["bizNo"] = bizNo
+
+	return params, nil
+}
+
+// GetParametersQuery converts the parameters from GetParameters into the url.Values format
+func (d *DepositFuturesMarginRequest) GetParametersQuery() (url.Values, error) {
+	query := url.Values{}
+
+	params, err := d.GetParameters()
+	if err != nil {
+		return query, err
+	}
+
+	for k, v := range params {
+		query.Add(k, fmt.Sprintf("%v", v))
+	}
+
+	return query, nil
+}
+
+// GetParametersJSON converts the parameters from GetParameters into the JSON format
+func (d *DepositFuturesMarginRequest) GetParametersJSON() ([]byte, error) {
+	params, err := d.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(params)
+}
+
+// GetSlugParameters builds and checks the slug parameters and return the result in a map object
+func (d *DepositFuturesMarginRequest) GetSlugParameters() (map[string]interface{}, error) {
+	var params = map[string]interface{}{}
+
+	return params, nil
+}
+
+func (d *DepositFuturesMarginRequest) applySlugsToUrl(url string, slugs map[string]string) string {
+	for k, v := range slugs {
+		needleRE := regexp.MustCompile(":" + k + "\\b")
+		url = needleRE.ReplaceAllString(url, v)
+	}
+
+	return url
+}
+
+func (d *DepositFuturesMarginRequest) GetSlugsMap() (map[string]string, error) {
+	slugs := map[string]string{}
+	params, err := d.GetSlugParameters()
+	if err != nil {
+		return slugs, nil
+	}
+
+	for k, v := range params {
+		slugs[k] = fmt.Sprintf("%v", v)
+	}
+
+	return slugs, nil
+}
+
+func (d *DepositFuturesMarginRequest) Do(ctx context.Context) (*FuturesPosition, error) {
+
+	params, err := d.GetParameters()
+	if err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+
+	apiURL := "/api/v1/position/margin/deposit-margin"
+
+	req, err := d.client.NewAuthenticatedRequest(ctx, "POST", apiURL, query, params)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := d.client.SendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse APIResponse
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+	var data FuturesPosition
+	if err := json.Unmarshal(apiResponse.Data, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
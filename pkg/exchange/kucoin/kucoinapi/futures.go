@@ -0,0 +1,331 @@
+package kucoinapi
+
+//go:generate -command GetRequest requestgen -method GET -responseType .APIResponse -responseDataField Data
+//go:generate -command PostRequest requestgen -method POST -responseType .APIResponse -responseDataField Data
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/c9s/requestgen"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type MarginModeType string
+
+const (
+	MarginModeIsolated MarginModeType = "ISOLATED"
+	MarginModeCross    MarginModeType = "CROSS"
+)
+
+// FuturesService is the service of the KuCoin Futures api, the client must be created by NewFuturesClient
+type FuturesService struct {
+	client *RestClient
+}
+
+func (s *FuturesService) NewGetContractsRequest() *GetFuturesContractsRequest {
+	return &GetFuturesContractsRequest{client: s.client}
+}
+
+func (s *FuturesService) NewGetTickerRequest() *GetFuturesTickerRequest {
+	return &GetFuturesTickerRequest{client: s.client}
+}
+
+func (s *FuturesService) NewGetKLinesRequest() *GetFuturesKLinesRequest {
+	return &GetFuturesKLinesRequest{client: s.client}
+}
+
+func (s *FuturesService) NewGetAccountOverviewRequest() *GetFuturesAccountOverviewRequest {
+	return &GetFuturesAccountOverviewRequest{client: s.client}
+}
+
+func (s *FuturesService) NewGetPositionsRequest() *GetFuturesPositionsRequest {
+	return &GetFuturesPositionsRequest{client: s.client}
+}
+
+func (s *FuturesService) NewChangeCrossLeverageRequest() *ChangeFuturesCrossLeverageRequest {
+	return &ChangeFuturesCrossLeverageRequest{client: s.client}
+}
+
+func (s *FuturesService) NewDepositMarginRequest() *DepositFuturesMarginRequest {
+	return &DepositFuturesMarginRequest{client: s.client}
+}
+
+func (s *FuturesService) NewPlaceOrderRequest() *PlaceFuturesOrderRequest {
+	return &PlaceFuturesOrderRequest{client: s.client}
+}
+
+func (s *FuturesService) NewListOrdersRequest() *ListFuturesOrdersRequest {
+	return &ListFuturesOrdersRequest{client: s.client}
+}
+
+func (s *FuturesService) NewGetFillsRequest() *GetFuturesFillsRequest {
+	return &GetFuturesFillsRequest{client: s.client}
+}
+
+type FuturesContract struct {
+	Symbol         string           `json:"symbol"`
+	RootSymbol     string           `json:"rootSymbol"`
+	Type           string           `json:"type"`
+	BaseCurrency   string           `json:"baseCurrency"`
+	QuoteCurrency  string           `json:"quoteCurrency"`
+	SettleCurrency string           `json:"settleCurrency"`
+	MaxOrderQty    fixedpoint.Value `json:"maxOrderQty"`
+	MaxPrice       fixedpoint.Value `json:"maxPrice"`
+	LotSize        fixedpoint.Value `json:"lotSize"`
+	TickSize       fixedpoint.Value `json:"tickSize"`
+
+	// Multiplier is the base quantity of one lot, the order size of the futures api is in lots
+	Multiplier     fixedpoint.Value `json:"multiplier"`
+	InitialMargin  fixedpoint.Value `json:"initialMargin"`
+	MaintainMargin fixedpoint.Value `json:"maintainMargin"`
+	MaxLeverage    int              `json:"maxLeverage"`
+	IsInverse      bool             `json:"isInverse"`
+	Status         string           `json:"status"`
+	MarkPrice      fixedpoint.Value `json:"markPrice"`
+	LastTradePrice fixedpoint.Value `json:"lastTradePrice"`
+	HighPrice      fixedpoint.Value `json:"highPrice"`
+	LowPrice       fixedpoint.Value `json:"lowPrice"`
+	VolumeOf24h    fixedpoint.Value `json:"volumeOf24h"`
+}
+
+//go:generate GetRequest -url "/api/v1/contracts/active" -type GetFuturesContractsRequest -responseDataType []FuturesContract
+type GetFuturesContractsRequest struct {
+	client requestgen.APIClient
+}
+
+type FuturesTicker struct {
+	Sequence     int64            `json:"sequence"`
+	Symbol       string           `json:"symbol"`
+	Side         SideType         `json:"side"`
+	Size         fixedpoint.Value `json:"size"`
+	Price        fixedpoint.Value `json:"price"`
+	BestBidSize  fixedpoint.Value `json:"bestBidSize"`
+	BestBidPrice fixedpoint.Value `json:"bestBidPrice"`
+	BestAskSize  fixedpoint.Value `json:"bestAskSize"`
+	BestAskPrice fixedpoint.Value `json:"bestAskPrice"`
+	TradeId      string           `json:"tradeId"`
+
+	// Time is the nanosecond timestamp of the ticker
+	Time int64 `json:"ts"`
+}
+
+//go:generate GetRequest -url "/api/v1/ticker" -type GetFuturesTickerRequest -responseDataType .FuturesTicker
+type GetFuturesTickerRequest struct {
+	client requestgen.APIClient
+
+	symbol string `param:"symbol,query,required"`
+}
+
+// FuturesKLine is the kline of the futures api, it's encoded as [time, open, high, low, close, volume]
+type FuturesKLine struct {
+	StartTime types.MillisecondTimestamp
+	Open      fixedpoint.Value
+	High      fixedpoint.Value
+	Low       fixedpoint.Value
+	Close     fixedpoint.Value
+	Volume    fixedpoint.Value
+}
+
+func (k *FuturesKLine) UnmarshalJSON(data []byte) error {
+	var values []json.Number
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	if len(values) < 6 {
+		return fmt.Errorf("unexpected futures kline length %d: %s", len(values), data)
+	}
+
+	startTime, err := values[0].Int64()
+	if err != nil {
+		return err
+	}
+
+	k.StartTime = types.NewMillisecondTimestampFromInt(startTime)
+
+	fields := []*fixedpoint.Value{&k.Open, &k.High, &k.Low, &k.Close, &k.Volume}
+	for i, field := range fields {
+		if *field, err = fixedpoint.NewFromString(values[i+1].String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//go:generate GetRequest -url "/api/v1/kline/query" -type GetFuturesKLinesRequest -responseDataType []FuturesKLine
+type GetFuturesKLinesRequest struct {
+	client requestgen.APIClient
+
+	symbol string `param:"symbol,query,required"`
+
+	// granularity is the interval in minutes
+	granularity int `param:"granularity,query"`
+
+	from *time.Time `param:"from,query,milliseconds"`
+
+	to *time.Time `param:"to,query,milliseconds"`
+}
+
+type FuturesAccountOverview struct {
+	AccountEquity    fixedpoint.Value `json:"accountEquity"`
+	UnrealisedPNL    fixedpoint.Value `json:"unrealisedPNL"`
+	MarginBalance    fixedpoint.Value `json:"marginBalance"`
+	PositionMargin   fixedpoint.Value `json:"positionMargin"`
+	OrderMargin      fixedpoint.Value `json:"orderMargin"`
+	FrozenFunds      fixedpoint.Value `json:"frozenFunds"`
+	AvailableBalance fixedpoint.Value `json:"availableBalance"`
+	Currency         string           `json:"currency"`
+}
+
+//go:generate GetRequest -url "/api/v1/account-overview" -type GetFuturesAccountOverviewRequest -responseDataType .FuturesAccountOverview
+type GetFuturesAccountOverviewRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	currency *string `param:"currency,query"`
+}
+
+type FuturesPosition struct {
+	ID               string                     `json:"id"`
+	Symbol           string                     `json:"symbol"`
+	AutoDeposit      bool                       `json:"autoDeposit"`
+	CrossMode        bool                       `json:"crossMode"`
+	MarginMode       MarginModeType             `json:"marginMode"`
+	IsOpen           bool                       `json:"isOpen"`
+	SettleCurrency   string                     `json:"settleCurrency"`
+	OpeningTimestamp types.MillisecondTimestamp `json:"openingTimestamp"`
+	CurrentTimestamp types.MillisecondTimestamp `json:"currentTimestamp"`
+
+	// CurrentQty is the signed position size in lots, a negative size is a short position
+	CurrentQty fixedpoint.Value `json:"currentQty"`
+
+	CurrentCost      fixedpoint.Value `json:"currentCost"`
+	AvgEntryPrice    fixedpoint.Value `json:"avgEntryPrice"`
+	MarkPrice        fixedpoint.Value `json:"markPrice"`
+	MarkValue        fixedpoint.Value `json:"markValue"`
+	LiquidationPrice fixedpoint.Value `json:"liquidationPrice"`
+	BankruptPrice    fixedpoint.Value `json:"bankruptPrice"`
+	Leverage         fixedpoint.Value `json:"leverage"`
+	RealLeverage     fixedpoint.Value `json:"realLeverage"`
+	PosMargin        fixedpoint.Value `json:"posMargin"`
+	MaintMarginReq   fixedpoint.Value `json:"maintMarginReq"`
+	RealisedPnl      fixedpoint.Value `json:"realisedPnl"`
+	UnrealisedPnl    fixedpoint.Value `json:"unrealisedPnl"`
+}
+
+//go:generate GetRequest -url "/api/v1/positions" -type GetFuturesPositionsRequest -responseDataType []FuturesPosition
+type GetFuturesPositionsRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	currency *string `param:"currency,query"`
+}
+
+//go:generate PostRequest -url "/api/v2/changeCrossUserLeverage" -type ChangeFuturesCrossLeverageRequest -responseDataType bool
+type ChangeFuturesCrossLeverageRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	symbol string `param:"symbol,required"`
+
+	leverage string `param:"leverage,required"`
+}
+
+//go:generate PostRequest -url "/api/v1/position/margin/deposit-margin" -type DepositFuturesMarginRequest -responseDataType .FuturesPosition
+type DepositFuturesMarginRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	symbol string `param:"symbol,required"`
+
+	margin string `param:"margin,required"`
+
+	// bizNo is the unique id of the request to prevent the duplicated deposits
+	bizNo string `param:"bizNo,required"`
+}
+
+//go:generate PostRequest -url "/api/v1/orders" -type PlaceFuturesOrderRequest -responseDataType .OrderResponse
+type PlaceFuturesOrderRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	clientOrderID string `param:"clientOid,required"`
+
+	symbol string `param:"symbol,required"`
+
+	side SideType `param:"side"`
+
+	orderType OrderType `param:"type"`
+
+	// size is the order size in lots
+	size int64 `param:"size"`
+
+	price *string `param:"price"`
+
+	leverage *string `param:"leverage"`
+
+	marginMode *MarginModeType `param:"marginMode"`
+
+	timeInForce *TimeInForceType `param:"timeInForce"`
+
+	postOnly *bool `param:"postOnly"`
+
+	reduceOnly *bool `param:"reduceOnly"`
+
+	// closeOrder closes the whole position, the side and the size are ignored
+	closeOrder *bool `param:"closeOrder"`
+
+	selfTradePrevention *SelfTradePreventionType `param:"stp"`
+}
+
+// ListFuturesOrdersRequest lists the futures orders, the order sizes of the response are in lots
+//
+//go:generate GetRequest -url "/api/v1/orders" -type ListFuturesOrdersRequest -responseDataType .OrderListPage
+type ListFuturesOrdersRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	status *string `param:"status,query" validValues:"active,done"`
+
+	symbol *string `param:"symbol,query"`
+
+	startAt *time.Time `param:"startAt,query,milliseconds"`
+
+	endAt *time.Time `param:"endAt,query,milliseconds"`
+}
+
+type FuturesFill struct {
+	Symbol         string                     `json:"symbol"`
+	TradeId        string                     `json:"tradeId"`
+	OrderId        string                     `json:"orderId"`
+	Side           SideType                   `json:"side"`
+	Liquidity      LiquidityType              `json:"liquidity"`
+	ForceTaker     bool                       `json:"forceTaker"`
+	Price          fixedpoint.Value           `json:"price"`
+	Size           fixedpoint.Value           `json:"size"`
+	Value          fixedpoint.Value           `json:"value"`
+	Fee            fixedpoint.Value           `json:"fee"`
+	FeeRate        fixedpoint.Value           `json:"feeRate"`
+	FeeCurrency    string                     `json:"feeCurrency"`
+	OrderType      OrderType                  `json:"orderType"`
+	SettleCurrency string                     `json:"settleCurrency"`
+	CreatedAt      types.MillisecondTimestamp `json:"createdAt"`
+}
+
+type FuturesFillListPage struct {
+	CurrentPage int           `json:"currentPage"`
+	PageSize    int           `json:"pageSize"`
+	TotalNumber int           `json:"totalNum"`
+	TotalPage   int           `json:"totalPage"`
+	Items       []FuturesFill `json:"items"`
+}
+
+//go:generate GetRequest -url "/api/v1/fills" -type GetFuturesFillsRequest -responseDataType .FuturesFillListPage
+type GetFuturesFillsRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	symbol *string `param:"symbol,query"`
+
+	startAt *time.Time `param:"startAt,query,milliseconds"`
+
+	endAt *time.Time `param:"endAt,query,milliseconds"`
+}
@@ -56,6 +56,11 @@ func (r *PlaceOrderRequest) PostOnly(postOnly bool) *PlaceOrderRequest {
 	return r
 }
 
+func (r *PlaceOrderRequest) SelfTradePrevention(selfTradePrevention SelfTradePreventionType) *PlaceOrderRequest {
+	r.selfTradePrevention = &selfTradePrevention
+	return r
+}
+
 // GetQueryParameters builds and checks the query parameters and returns url.Values
 func (r *PlaceOrderRequest) GetQueryParameters() (url.Values, error) {
 	var params = map[string]interface{}{}
@@ -159,6 +164,14 @@ func (r *PlaceOrderRequest) GetParameters() (map[string]interface{}, error) {
 		params["postOnly"] = postOnly
 	} else {
 	}
+	// check selfTradePrevention field -> json key stp
+	if r.selfTradePrevention != nil {
+		selfTradePrevention := *r.selfTradePrevention
+
+		// assign parameter of selfTradePrevention
+		params["stp"] = selfTradePrevention
+	} else {
+	}
 
 	return params, nil
 }
@@ -208,6 +208,8 @@ type PlaceOrderRequest struct {
 	timeInForce *TimeInForceType `param:"timeInForce,required"`
 
 	postOnly *bool `param:"postOnly"`
+
+	selfTradePrevention *SelfTradePreventionType `param:"stp"`
 }
 
 type CancelOrderResponse struct {
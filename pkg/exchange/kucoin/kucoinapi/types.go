@@ -46,6 +46,20 @@ const (
 	LiquidityTypeTaker LiquidityType = "taker"
 )
 
+// SelfTradePreventionType is the strategy to prevent the orders of the same user from matching each other
+type SelfTradePreventionType string
+
+const (
+	// SelfTradePreventionCancelNewest cancels the newest order, which is the taker order
+	SelfTradePreventionCancelNewest SelfTradePreventionType = "CN"
+
+	// SelfTradePreventionCancelOldest cancels the oldest order, which is the maker order
+	SelfTradePreventionCancelOldest SelfTradePreventionType = "CO"
+
+	// SelfTradePreventionCancelBoth cancels both orders
+	SelfTradePreventionCancelBoth SelfTradePreventionType = "CB"
+)
+
 type OrderType string
 
 const (
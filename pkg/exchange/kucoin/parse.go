@@ -38,7 +38,21 @@ func parseWebSocketEvent(in []byte) (interface{}, error) {
 			}
 			resp.Object = &o
 
-		case WebSocketSubjectTradeCandlesUpdate, WebSocketSubjectTradeCandlesAdd:
+		case WebSocketSubjectPositionChange:
+			var o WebSocketFuturesPositionEvent
+			if err := json.Unmarshal(resp.Data, &o); err != nil {
+				return &resp, err
+			}
+			resp.Object = &o
+
+		case WebSocketSubjectAvailableBalanceChange:
+			var o WebSocketFuturesBalanceEvent
+			if err := json.Unmarshal(resp.Data, &o); err != nil {
+				return &resp, err
+			}
+			resp.Object = &o
+
+		case WebSocketSubjectTradeCandlesUpdate, WebSocketSubjectTradeCandlesAdd, WebSocketSubjectCandleStick:
 			var o WebSocketCandleEvent
 			if err := json.Unmarshal(resp.Data, &o); err != nil {
 				return &resp, err
@@ -55,6 +69,13 @@ func parseWebSocketEvent(in []byte) (interface{}, error) {
 			}
 			resp.Object = &o
 
+		case WebSocketSubjectTradeL3Match:
+			var o WebSocketMatchEvent
+			if err := json.Unmarshal(resp.Data, &o); err != nil {
+				return &resp, err
+			}
+			resp.Object = &o
+
 		case WebSocketSubjectTradeTicker:
 			var o WebSocketTickerEvent
 			if err := json.Unmarshal(resp.Data, &o); err != nil {
@@ -0,0 +1,57 @@
+package kucoin
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_parseWebSocketEvent_match(t *testing.T) {
+	in := `{
+		"type": "message",
+		"topic": "/market/match:BTC-USDT",
+		"subject": "trade.l3match",
+		"data": {
+			"sequence": "1545896669145",
+			"type": "match",
+			"symbol": "BTC-USDT",
+			"side": "sell",
+			"price": "30000.1",
+			"size": "0.01",
+			"tradeId": "5c24c5da03aa673885cd67aa",
+			"takerOrderId": "5c24c5d903aa6772d55b371e",
+			"makerOrderId": "5c2187d003aa677bd09d5c93",
+			"time": "1545913818099033203"
+		}
+	}`
+
+	e, err := parseWebSocketEvent([]byte(in))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	event, ok := e.(*WebSocketEvent)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	match, ok := event.Object.(*WebSocketMatchEvent)
+	if !assert.True(t, ok) {
+		return
+	}
+
+	trade, err := match.Trade()
+	if assert.NoError(t, err) {
+		assert.Equal(t, "BTCUSDT", trade.Symbol)
+		assert.Equal(t, types.SideTypeSell, trade.Side)
+		assert.False(t, trade.IsBuyer)
+		assert.Equal(t, hashStringID("5c24c5da03aa673885cd67aa"), trade.ID)
+		assert.Equal(t, fixedpoint.MustNewFromString("30000.1"), trade.Price)
+		assert.Equal(t, fixedpoint.MustNewFromString("0.01"), trade.Quantity)
+		assert.Equal(t, time.Unix(0, 1545913818099033203), trade.Time.Time())
+	}
+}
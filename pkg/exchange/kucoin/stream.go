@@ -27,11 +27,18 @@ type Stream struct {
 	candleEventCallbacks         []func(candle *WebSocketCandleEvent, e *WebSocketEvent)
 	orderBookL2EventCallbacks    []func(e *WebSocketOrderBookL2Event)
 	tickerEventCallbacks         []func(e *WebSocketTickerEvent)
+	matchEventCallbacks          []func(e *WebSocketMatchEvent)
 	accountBalanceEventCallbacks []func(e *WebSocketAccountBalanceEvent)
 	privateOrderEventCallbacks   []func(e *WebSocketPrivateOrderEvent)
 
+	futuresPositionEventCallbacks []func(e *WebSocketFuturesPositionEvent)
+	futuresBalanceEventCallbacks  []func(e *WebSocketFuturesBalanceEvent)
+
 	lastCandle   map[string]types.KLine
 	depthBuffers map[string]*depth.Buffer
+
+	// futuresPositions is the last position of the contracts, the mark price events are merged into it
+	futuresPositions map[string]kucoinapi.FuturesPosition
 }
 
 func NewStream(client *kucoinapi.RestClient, ex *Exchange) *Stream {
@@ -41,6 +48,8 @@ func NewStream(client *kucoinapi.RestClient, ex *Exchange) *Stream {
 		exchange:       ex,
 		lastCandle:     make(map[string]types.KLine),
 		depthBuffers:   make(map[string]*depth.Buffer),
+
+		futuresPositions: make(map[string]kucoinapi.FuturesPosition),
 	}
 
 	stream.SetParser(parseWebSocketEvent)
@@ -51,13 +60,28 @@ func NewStream(client *kucoinapi.RestClient, ex *Exchange) *Stream {
 	stream.OnCandleEvent(stream.handleCandleEvent)
 	stream.OnOrderBookL2Event(stream.handleOrderBookL2Event)
 	stream.OnTickerEvent(stream.handleTickerEvent)
+	stream.OnMatchEvent(stream.handleMatchEvent)
 	stream.OnPrivateOrderEvent(stream.handlePrivateOrderEvent)
 	stream.OnAccountBalanceEvent(stream.handleAccountBalanceEvent)
+	stream.OnFuturesPositionEvent(stream.handleFuturesPositionEvent)
+	stream.OnFuturesBalanceEvent(stream.handleFuturesBalanceEvent)
 	return stream
 }
 
 func (s *Stream) handleCandleEvent(candle *WebSocketCandleEvent, e *WebSocketEvent) {
 	kline := candle.KLine()
+	if s.exchange.IsFutures {
+		kline.Symbol = toGlobalFuturesSymbol(candle.Symbol)
+
+		// the volume of the futures candles is in lots
+		multiplier, err := s.exchange.futuresMultiplier(context.Background(), candle.Symbol)
+		if err != nil {
+			log.WithError(err).Errorf("can not convert the futures candle volume of %s", candle.Symbol)
+		} else {
+			kline.Volume = kline.Volume.Mul(multiplier)
+		}
+	}
+
 	last, ok := s.lastCandle[e.Topic]
 	if ok && kline.StartTime.After(last.StartTime.Time()) || e.Subject == WebSocketSubjectTradeCandlesAdd {
 		last.Closed = true
@@ -68,6 +92,16 @@ func (s *Stream) handleCandleEvent(candle *WebSocketCandleEvent, e *WebSocketEve
 	s.lastCandle[e.Topic] = kline
 }
 
+func (s *Stream) handleMatchEvent(e *WebSocketMatchEvent) {
+	trade, err := e.Trade()
+	if err != nil {
+		log.WithError(err).Error("match event convert error")
+		return
+	}
+
+	s.EmitMarketTrade(trade)
+}
+
 func (s *Stream) handleOrderBookL2Event(e *WebSocketOrderBookL2Event) {
 	f, ok := s.depthBuffers[e.Symbol]
 	if ok {
@@ -111,7 +145,69 @@ func (s *Stream) handleAccountBalanceEvent(e *WebSocketAccountBalanceEvent) {
 	s.StandardStream.EmitBalanceUpdate(bm)
 }
 
+func (s *Stream) handleFuturesBalanceEvent(e *WebSocketFuturesBalanceEvent) {
+	currency := toGlobalFuturesCurrency(e.Currency)
+	s.StandardStream.EmitBalanceUpdate(types.BalanceMap{
+		currency: {
+			Currency:  currency,
+			Available: e.AvailableBalance,
+			Locked:    e.HoldBalance,
+		},
+	})
+}
+
+func (s *Stream) handleFuturesPositionEvent(e *WebSocketFuturesPositionEvent) {
+	position := e.FuturesPosition
+	if e.ChangeReason == PositionChangeReasonMarkPriceChange {
+		// the mark price events don't contain the position size, merge the mark price fields into the last position
+		last, ok := s.futuresPositions[e.Symbol]
+		if !ok {
+			return
+		}
+
+		last.MarkPrice = e.MarkPrice
+		last.MarkValue = e.MarkValue
+		last.RealLeverage = e.RealLeverage
+		last.UnrealisedPnl = e.UnrealisedPnl
+		last.CurrentTimestamp = e.CurrentTimestamp
+		if !e.LiquidationPrice.IsZero() {
+			last.LiquidationPrice = e.LiquidationPrice
+		}
+
+		position = last
+	}
+
+	s.futuresPositions[e.Symbol] = position
+
+	multiplier, err := s.exchange.futuresMultiplier(context.Background(), e.Symbol)
+	if err != nil {
+		log.WithError(err).Errorf("can not convert the futures position of %s", e.Symbol)
+		return
+	}
+
+	futuresPosition := toGlobalFuturesPosition(position, multiplier)
+	s.StandardStream.EmitFuturesPositionUpdate(types.FuturesPositionMap{
+		futuresPosition.Symbol: futuresPosition,
+	})
+}
+
 func (s *Stream) handlePrivateOrderEvent(e *WebSocketPrivateOrderEvent) {
+	symbol := toGlobalSymbol(e.Symbol)
+	if s.exchange.IsFutures {
+		// the sizes of the futures order events are in lots
+		multiplier, err := s.exchange.futuresMultiplier(context.Background(), e.Symbol)
+		if err != nil {
+			log.WithError(err).Errorf("can not convert the futures order event of %s", e.Symbol)
+			return
+		}
+
+		symbol = toGlobalFuturesSymbol(e.Symbol)
+		e.Size = e.Size.Mul(multiplier)
+		e.FilledSize = e.FilledSize.Mul(multiplier)
+		e.RemainSize = e.RemainSize.Mul(multiplier)
+		e.MatchSize = e.MatchSize.Mul(multiplier)
+	}
+
 	if e.Type == "match" {
 		s.StandardStream.EmitTradeUpdate(types.Trade{
 			OrderID:       hashStringID(e.OrderId),
@@ -120,9 +216,10 @@ func (s *Stream) handlePrivateOrderEvent(e *WebSocketPrivateOrderEvent) {
 			Price:         e.MatchPrice,
 			Quantity:      e.MatchSize,
 			QuoteQuantity: e.MatchPrice.Mul(e.MatchSize),
-			Symbol:        toGlobalSymbol(e.Symbol),
+			Symbol:        symbol,
 			Side:          toGlobalSide(e.Side),
 			IsBuyer:       e.Side == "buy",
+			IsFutures:     s.exchange.IsFutures,
 			IsMaker:       e.Liquidity == "maker",
 			Time:          types.Time(e.Ts.Time()),
 			Fee:           fixedpoint.Zero, // not supported
@@ -148,7 +245,7 @@ func (s *Stream) handlePrivateOrderEvent(e *WebSocketPrivateOrderEvent) {
 		s.StandardStream.EmitOrderUpdate(types.Order{
 			SubmitOrder: types.SubmitOrder{
 				ClientOrderID: e.ClientOid,
-				Symbol:        toGlobalSymbol(e.Symbol),
+				Symbol:        symbol,
 				Side:          toGlobalSide(e.Side),
 				Type:          toGlobalOrderType(e.OrderType),
 				Quantity:      e.Size,
@@ -160,6 +257,7 @@ func (s *Stream) handlePrivateOrderEvent(e *WebSocketPrivateOrderEvent) {
 			Status:           status,
 			ExecutedQuantity: e.FilledSize,
 			IsWorking:        e.Status == "open",
+			IsFutures:        s.exchange.IsFutures,
 			CreationTime:     types.Time(e.OrderTime.Time()),
 			UpdateTime:       types.Time(e.Ts.Time()),
 		})
@@ -176,6 +274,36 @@ func (s *Stream) handleConnect() {
 			log.WithError(err).Errorf("subscription error")
 			return
 		}
+	} else if s.exchange.IsFutures {
+		id := time.Now().UnixNano() / int64(time.Millisecond)
+		cmds := []WebSocketCommand{
+			{
+				Id:             id,
+				Type:           WebSocketMessageTypeSubscribe,
+				Topic:          "/contractMarket/tradeOrders",
+				PrivateChannel: true,
+				Response:       true,
+			},
+			{
+				Id:             id + 1,
+				Type:           WebSocketMessageTypeSubscribe,
+				Topic:          "/contract/positionAll",
+				PrivateChannel: true,
+				Response:       true,
+			},
+			{
+				Id:             id + 2,
+				Type:           WebSocketMessageTypeSubscribe,
+				Topic:          "/contractAccount/wallet",
+				PrivateChannel: true,
+				Response:       true,
+			},
+		}
+		for _, cmd := range cmds {
+			if err := s.Conn.WriteJSON(cmd); err != nil {
+				log.WithError(err).Errorf("private subscribe write error, cmd: %+v", cmd)
+			}
+		}
 	} else {
 		id := time.Now().UnixNano() / int64(time.Millisecond)
 		cmds := []WebSocketCommand{
@@ -203,7 +331,12 @@ func (s *Stream) handleConnect() {
 }
 
 func (s *Stream) sendSubscriptions() error {
-	cmds, err := convertSubscriptions(s.Subscriptions)
+	convert := convertSubscriptions
+	if s.exchange.IsFutures {
+		convert = convertFuturesSubscriptions
+	}
+
+	cmds, err := convert(s.Subscriptions)
 	if err != nil {
 		return errors.Wrapf(err, "subscription convert error, subscriptions: %+v", s.Subscriptions)
 	}
@@ -260,6 +393,9 @@ func (s *Stream) dispatchEvent(event interface{}) {
 	case *WebSocketOrderBookL2Event:
 		s.EmitOrderBookL2Event(et)
 
+	case *WebSocketMatchEvent:
+		s.EmitMatchEvent(et)
+
 	case *WebSocketCandleEvent:
 		s.EmitCandleEvent(et, e)
 
@@ -269,6 +405,12 @@ func (s *Stream) dispatchEvent(event interface{}) {
 	case *WebSocketPrivateOrderEvent:
 		s.EmitPrivateOrderEvent(et)
 
+	case *WebSocketFuturesPositionEvent:
+		s.EmitFuturesPositionEvent(et)
+
+	case *WebSocketFuturesBalanceEvent:
+		s.EmitFuturesBalanceEvent(et)
+
 	default:
 		log.Warnf("unhandled event: %+v", et)
 
@@ -32,6 +32,16 @@ func (s *Stream) EmitTickerEvent(e *WebSocketTickerEvent) {
 	}
 }
 
+func (s *Stream) OnMatchEvent(cb func(e *WebSocketMatchEvent)) {
+	s.matchEventCallbacks = append(s.matchEventCallbacks, cb)
+}
+
+func (s *Stream) EmitMatchEvent(e *WebSocketMatchEvent) {
+	for _, cb := range s.matchEventCallbacks {
+		cb(e)
+	}
+}
+
 func (s *Stream) OnAccountBalanceEvent(cb func(e *WebSocketAccountBalanceEvent)) {
 	s.accountBalanceEventCallbacks = append(s.accountBalanceEventCallbacks, cb)
 }
@@ -52,6 +62,26 @@ func (s *Stream) EmitPrivateOrderEvent(e *WebSocketPrivateOrderEvent) {
 	}
 }
 
+func (s *Stream) OnFuturesPositionEvent(cb func(e *WebSocketFuturesPositionEvent)) {
+	s.futuresPositionEventCallbacks = append(s.futuresPositionEventCallbacks, cb)
+}
+
+func (s *Stream) EmitFuturesPositionEvent(e *WebSocketFuturesPositionEvent) {
+	for _, cb := range s.futuresPositionEventCallbacks {
+		cb(e)
+	}
+}
+
+func (s *Stream) OnFuturesBalanceEvent(cb func(e *WebSocketFuturesBalanceEvent)) {
+	s.futuresBalanceEventCallbacks = append(s.futuresBalanceEventCallbacks, cb)
+}
+
+func (s *Stream) EmitFuturesBalanceEvent(e *WebSocketFuturesBalanceEvent) {
+	for _, cb := range s.futuresBalanceEventCallbacks {
+		cb(e)
+	}
+}
+
 type StreamEventHub interface {
 	OnCandleEvent(cb func(candle *WebSocketCandleEvent, e *WebSocketEvent))
 
@@ -59,7 +89,13 @@ type StreamEventHub interface {
 
 	OnTickerEvent(cb func(e *WebSocketTickerEvent))
 
+	OnMatchEvent(cb func(e *WebSocketMatchEvent))
+
 	OnAccountBalanceEvent(cb func(e *WebSocketAccountBalanceEvent))
 
 	OnPrivateOrderEvent(cb func(e *WebSocketPrivateOrderEvent))
+
+	OnFuturesPositionEvent(cb func(e *WebSocketFuturesPositionEvent))
+
+	OnFuturesBalanceEvent(cb func(e *WebSocketFuturesBalanceEvent))
 }
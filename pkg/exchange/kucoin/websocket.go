@@ -2,8 +2,11 @@ package kucoin
 
 import (
 	"encoding/json"
+	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/c9s/bbgo/pkg/exchange/kucoin/kucoinapi"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -30,11 +33,23 @@ const (
 	WebSocketSubjectLevel2             WebSocketSubject = "level2"         // level2
 	WebSocketSubjectTradeCandlesUpdate WebSocketSubject = "trade.candles.update"
 	WebSocketSubjectTradeCandlesAdd    WebSocketSubject = "trade.candles.add"
+	WebSocketSubjectTradeL3Match       WebSocketSubject = "trade.l3match" // public trades of the match topic
 
 	// private subjects
 	WebSocketSubjectOrderChange    WebSocketSubject = "orderChange"
 	WebSocketSubjectAccountBalance WebSocketSubject = "account.balance"
 	WebSocketSubjectStopOrder      WebSocketSubject = "stopOrder"
+
+	// futures subjects
+	WebSocketSubjectCandleStick            WebSocketSubject = "candle.stick"
+	WebSocketSubjectPositionChange         WebSocketSubject = "position.change"
+	WebSocketSubjectAvailableBalanceChange WebSocketSubject = "availableBalance.change"
+)
+
+// futures position change reasons
+const (
+	// PositionChangeReasonMarkPriceChange is pushed periodically with the mark price fields only
+	PositionChangeReasonMarkPriceChange = "markPriceChange"
 )
 
 type WebSocketCommand struct {
@@ -72,6 +87,38 @@ type WebSocketTickerEvent struct {
 	BestBidSize fixedpoint.Value `json:"bestBidSize"`
 }
 
+// WebSocketMatchEvent is the public trade of the match topic, the side is the taker side
+type WebSocketMatchEvent struct {
+	Symbol  string           `json:"symbol"`
+	TradeId string           `json:"tradeId"`
+	Side    string           `json:"side"`
+	Price   fixedpoint.Value `json:"price"`
+	Size    fixedpoint.Value `json:"size"`
+
+	// Time is the trade time in nanoseconds
+	Time string `json:"time"`
+}
+
+func (e *WebSocketMatchEvent) Trade() (types.Trade, error) {
+	ns, err := strconv.ParseInt(e.Time, 10, 64)
+	if err != nil {
+		return types.Trade{}, fmt.Errorf("unexpected match time %q: %w", e.Time, err)
+	}
+
+	side := toGlobalSide(e.Side)
+	return types.Trade{
+		ID:            hashStringID(e.TradeId),
+		Exchange:      types.ExchangeKucoin,
+		Symbol:        toGlobalSymbol(e.Symbol),
+		Side:          side,
+		Price:         e.Price,
+		Quantity:      e.Size,
+		QuoteQuantity: e.Price.Mul(e.Size),
+		IsBuyer:       side == types.SideTypeBuy,
+		Time:          types.Time(time.Unix(0, ns)),
+	}, nil
+}
+
 type WebSocketOrderBookL2Event struct {
 	SequenceStart int64  `json:"sequenceStart"`
 	SequenceEnd   int64  `json:"sequenceEnd"`
@@ -140,6 +187,21 @@ type WebSocketPrivateOrderEvent struct {
 	Ts         types.MillisecondTimestamp `json:"ts"`
 }
 
+// WebSocketFuturesPositionEvent is the position change of the futures account,
+// the sizes of the position are in lots.
+type WebSocketFuturesPositionEvent struct {
+	kucoinapi.FuturesPosition
+
+	ChangeReason string `json:"changeReason"`
+}
+
+type WebSocketFuturesBalanceEvent struct {
+	AvailableBalance fixedpoint.Value           `json:"availableBalance"`
+	HoldBalance      fixedpoint.Value           `json:"holdBalance"`
+	Currency         string                     `json:"currency"`
+	Timestamp        types.MillisecondTimestamp `json:"timestamp"`
+}
+
 type WebSocketAccountBalanceEvent struct {
 	Total           fixedpoint.Value `json:"total"`
 	Available       fixedpoint.Value `json:"available"`
@@ -153,6 +153,21 @@ func toLocalOrderType(orderType types.OrderType) (max.OrderType, error) {
 	return "", fmt.Errorf("order type %s not supported", orderType)
 }
 
+func toLocalSelfTradePrevention(mode types.SelfTradePreventionMode) (string, error) {
+	switch mode {
+	case types.SelfTradePreventionExpireTaker:
+		return "cancel_taker", nil
+
+	case types.SelfTradePreventionExpireMaker:
+		return "cancel_maker", nil
+
+	case types.SelfTradePreventionExpireBoth:
+		return "cancel_both", nil
+	}
+
+	return "", fmt.Errorf("self-trade prevention mode %s not supported", mode)
+}
+
 func toGlobalOrders(maxOrders []max.Order) (orders []types.Order, err error) {
 	for _, localOrder := range maxOrders {
 		o, err := toGlobalOrder(localOrder)
@@ -322,6 +337,36 @@ func convertWebSocketTrade(t max.TradeUpdate) (*types.Trade, error) {
 	}, nil
 }
 
+// convertWebSocketPublicTrade converts the public trade, the trend "up" is the trade taken by the buyer,
+// and "down" is the trade taken by the seller, so the side is the taker side like the other exchanges.
+func convertWebSocketPublicTrade(market string, t max.TradeEntry) (*types.Trade, error) {
+	price, err := fixedpoint.NewFromString(t.Price)
+	if err != nil {
+		return nil, err
+	}
+
+	quantity, err := fixedpoint.NewFromString(t.Volume)
+	if err != nil {
+		return nil, err
+	}
+
+	side := types.SideTypeSell
+	if t.Trend == "up" {
+		side = types.SideTypeBuy
+	}
+
+	return &types.Trade{
+		Symbol:        toGlobalSymbol(market),
+		Exchange:      types.ExchangeMax,
+		Price:         price,
+		Quantity:      quantity,
+		QuoteQuantity: price.Mul(quantity),
+		Side:          side,
+		IsBuyer:       side == types.SideTypeBuy,
+		Time:          types.Time(t.Time()),
+	}, nil
+}
+
 func convertWebSocketOrderUpdate(u max.OrderUpdate) (*types.Order, error) {
 	timeInForce := types.TimeInForceGTC
 	if u.OrderType == max.OrderTypeIOCLimit {
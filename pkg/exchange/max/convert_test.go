@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"testing"
 
+	max "github.com/c9s/bbgo/pkg/exchange/max/maxapi"
 	v3 "github.com/c9s/bbgo/pkg/exchange/max/maxapi/v3"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -114,3 +116,37 @@ func Test_toGlobalTradeV3(t *testing.T) {
 		assert.Equal(types.SideTypeSell, trades[1].Side)
 	})
 }
+
+func Test_toLocalSelfTradePrevention(t *testing.T) {
+	stp, err := toLocalSelfTradePrevention(types.SelfTradePreventionExpireMaker)
+	assert.NoError(t, err)
+	assert.Equal(t, "cancel_maker", stp)
+
+	stp, err = toLocalSelfTradePrevention(types.SelfTradePreventionExpireBoth)
+	assert.NoError(t, err)
+	assert.Equal(t, "cancel_both", stp)
+
+	_, err = toLocalSelfTradePrevention("DECREMENT")
+	assert.Error(t, err)
+}
+
+func Test_convertWebSocketPublicTrade(t *testing.T) {
+	trade, err := convertWebSocketPublicTrade("btcusdt", max.TradeEntry{
+		Trend:     "down",
+		Price:     "30000.5",
+		Volume:    "0.02",
+		Timestamp: 1690000000000,
+	})
+	if assert.NoError(t, err) {
+		assert.Equal(t, "BTCUSDT", trade.Symbol)
+		assert.Equal(t, types.SideTypeSell, trade.Side)
+		assert.False(t, trade.IsBuyer)
+		assert.Equal(t, fixedpoint.MustNewFromString("600.01"), trade.QuoteQuantity)
+	}
+
+	trade, err = convertWebSocketPublicTrade("btcusdt", max.TradeEntry{Trend: "up", Price: "30000.5", Volume: "0.02"})
+	if assert.NoError(t, err) {
+		assert.Equal(t, types.SideTypeBuy, trade.Side)
+		assert.True(t, trade.IsBuyer)
+	}
+}
@@ -16,12 +16,19 @@ import (
 
 	maxapi "github.com/c9s/bbgo/pkg/exchange/max/maxapi"
 	v3 "github.com/c9s/bbgo/pkg/exchange/max/maxapi/v3"
+	"github.com/c9s/bbgo/pkg/exchange/metrics"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 )
 
 var log = logrus.WithField("exchange", "max")
 
+func init() {
+	_ = types.Exchange(&Exchange{})
+	_ = types.MarginExchange(&Exchange{})
+	_ = types.MarginBorrowRepayService(&Exchange{})
+}
+
 type Exchange struct {
 	types.MarginSettings
 
@@ -64,6 +71,19 @@ func (e *Exchange) Name() types.ExchangeName {
 	return types.ExchangeMax
 }
 
+// EnableMetrics instruments the REST client with the session label, the v3 client shares the same http client
+func (e *Exchange) EnableMetrics(session string) {
+	e.client.HttpClient = metrics.InstrumentClient(e.client.HttpClient, e.Name().String(), session)
+}
+
+// SetCredentials replaces the api key of the REST client, the v3 client shares the same client
+func (e *Exchange) SetCredentials(key, secret, passphrase string) {
+	e.key = key
+	// pragma: allowlist nextline secret
+	e.secret = secret
+	e.client.Auth(key, secret)
+}
+
 func (e *Exchange) QueryTicker(ctx context.Context, symbol string) (*types.Ticker, error) {
 	ticker, err := e.client.PublicService.Ticker(toLocalSymbol(symbol))
 	if err != nil {
@@ -357,35 +377,47 @@ func (e *Exchange) CancelOrdersBySymbol(ctx context.Context, symbol string) ([]t
 }
 
 func (e *Exchange) CancelOrdersByGroupID(ctx context.Context, groupID uint32) ([]types.Order, error) {
+	return e.CancelOrdersByGroupIDs(ctx, groupID)
+}
+
+// CancelOrdersByGroupIDs cancels the orders of the groups, one request per group.
+// The orders of the succeeded groups are returned with the last error of the failed groups.
+func (e *Exchange) CancelOrdersByGroupIDs(ctx context.Context, groupIDs ...uint32) (orders []types.Order, err2 error) {
 	walletType := maxapi.WalletTypeSpot
 	if e.MarginSettings.IsMargin {
 		walletType = maxapi.WalletTypeMargin
 	}
 
-	req := e.v3client.NewCancelWalletOrderAllRequest(walletType)
-	req.GroupID(groupID)
+	for _, groupID := range groupIDs {
+		req := e.v3client.NewCancelWalletOrderAllRequest(walletType)
+		req.GroupID(groupID)
 
-	var orderResponses, err = req.Do(ctx)
-	if err != nil {
-		return nil, err
-	}
+		orderResponses, err := req.Do(ctx)
+		if err != nil {
+			log.WithError(err).Errorf("group id %d order cancel error", groupID)
+			err2 = err
+			continue
+		}
 
-	var maxOrders []maxapi.Order
-	for _, resp := range orderResponses {
-		if resp.Error == nil {
-			maxOrders = append(maxOrders, resp.Order)
+		var maxOrders []maxapi.Order
+		for _, resp := range orderResponses {
+			if resp.Error == nil {
+				maxOrders = append(maxOrders, resp.Order)
+			}
+		}
+
+		canceledOrders, err := toGlobalOrders(maxOrders)
+		if err != nil {
+			err2 = err
 		}
+
+		orders = append(orders, canceledOrders...)
 	}
 
-	return toGlobalOrders(maxOrders)
+	return orders, err2
 }
 
 func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err2 error) {
-	walletType := maxapi.WalletTypeSpot
-	if e.MarginSettings.IsMargin {
-		walletType = maxapi.WalletTypeMargin
-	}
-
 	var groupIDs = make(map[uint32]struct{})
 	var orphanOrders []types.Order
 	for _, o := range orders {
@@ -397,14 +429,13 @@ func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) (err
 	}
 
 	if len(groupIDs) > 0 {
+		var ids []uint32
 		for groupID := range groupIDs {
-			req := e.v3client.NewCancelWalletOrderAllRequest(walletType)
-			req.GroupID(groupID)
+			ids = append(ids, groupID)
+		}
 
-			if _, err := req.Do(ctx); err != nil {
-				log.WithError(err).Errorf("group id order cancel error")
-				err2 = err
-			}
+		if _, err := e.CancelOrdersByGroupIDs(ctx, ids...); err != nil {
+			err2 = err
 		}
 	}
 
@@ -509,6 +540,15 @@ func (e *Exchange) SubmitOrder(ctx context.Context, order types.SubmitOrder) (cr
 		req.GroupID(strconv.FormatUint(uint64(o.GroupID%math.MaxInt32), 10))
 	}
 
+	if o.SelfTradePrevention != types.SelfTradePreventionNone {
+		selfTradePrevention, err := toLocalSelfTradePrevention(o.SelfTradePrevention)
+		if err != nil {
+			return createdOrder, err
+		}
+
+		req.SelfTradePrevention(selfTradePrevention)
+	}
+
 	switch o.Type {
 	case types.OrderTypeStopLimit, types.OrderTypeLimit, types.OrderTypeLimitMaker:
 		var priceInString string
@@ -545,6 +585,53 @@ func (e *Exchange) SubmitOrder(ctx context.Context, order types.SubmitOrder) (cr
 	return createdOrder, err
 }
 
+// AmendOrder amends the price or the quantity of the open order, the zero price or quantity is not changed
+func (e *Exchange) AmendOrder(ctx context.Context, order types.Order, price, quantity fixedpoint.Value) (*types.Order, error) {
+	if price.IsZero() && quantity.IsZero() {
+		return nil, errors.New("either price or quantity should be given to amend the order")
+	}
+
+	if err := e.submitOrderLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	req := e.v3client.NewAmendOrderRequest()
+	if order.OrderID > 0 {
+		req.Id(order.OrderID)
+	} else if len(order.ClientOrderID) > 0 && order.ClientOrderID != types.NoClientOrderID {
+		req.ClientOrderID(order.ClientOrderID)
+	} else {
+		return nil, fmt.Errorf("order id or client order id is not defined, order=%+v", order)
+	}
+
+	if !price.IsZero() {
+		if order.Market.Symbol != "" {
+			req.Price(order.Market.FormatPrice(price))
+		} else {
+			req.Price(price.String())
+		}
+	}
+
+	if !quantity.IsZero() {
+		if order.Market.Symbol != "" {
+			req.Volume(order.Market.FormatQuantity(quantity))
+		} else {
+			req.Volume(quantity.String())
+		}
+	}
+
+	retOrder, err := req.Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if retOrder == nil {
+		return nil, errors.New("returned nil order")
+	}
+
+	return toGlobalOrder(*retOrder)
+}
+
 // PlatformFeeCurrency
 func (e *Exchange) PlatformFeeCurrency() string {
 	return toGlobalCurrency("max")
@@ -0,0 +1,24 @@
+package v3
+
+//go:generate -command GetRequest requestgen -method GET
+//go:generate -command PostRequest requestgen -method POST
+//go:generate -command PutRequest requestgen -method PUT
+
+import "github.com/c9s/requestgen"
+
+// AmendOrderRequest amends the price or the volume of an open order in place,
+// the amended order keeps its order id and group id.
+//
+//go:generate PutRequest -url "/api/v3/order" -type AmendOrderRequest -responseType .Order
+type AmendOrderRequest struct {
+	client requestgen.AuthenticatedAPIClient
+
+	id            *uint64 `param:"id,omitempty"`
+	clientOrderID *string `param:"client_oid,omitempty"`
+	price         *string `param:"price,omitempty"`
+	volume        *string `param:"volume,omitempty"`
+}
+
+func (s *Client) NewAmendOrderRequest() *AmendOrderRequest {
+	return &AmendOrderRequest{client: s.Client}
+}
@@ -20,6 +20,8 @@ type CreateWalletOrderRequest struct {
 	stopPrice     *string `param:"stop_price"`
 	clientOrderID *string `param:"client_oid"`
 	groupID       *string `param:"group_id"`
+
+	selfTradePrevention *string `param:"self_trade_prevention"`
 }
 
 func (s *Client) NewCreateWalletOrderRequest(walletType WalletType) *CreateWalletOrderRequest {
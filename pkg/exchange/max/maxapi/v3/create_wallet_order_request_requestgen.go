@@ -52,6 +52,11 @@ func (c *CreateWalletOrderRequest) GroupID(groupID string) *CreateWalletOrderReq
 	return c
 }
 
+func (c *CreateWalletOrderRequest) SelfTradePrevention(selfTradePrevention string) *CreateWalletOrderRequest {
+	c.selfTradePrevention = &selfTradePrevention
+	return c
+}
+
 func (c *CreateWalletOrderRequest) WalletType(walletType max.WalletType) *CreateWalletOrderRequest {
 	c.walletType = walletType
 	return c
@@ -142,6 +147,14 @@ func (c *CreateWalletOrderRequest) GetParameters() (map[string]interface{}, erro
 		params["group_id"] = groupID
 	} else {
 	}
+	// check selfTradePrevention field -> json key self_trade_prevention
+	if c.selfTradePrevention != nil {
+		selfTradePrevention := *c.selfTradePrevention
+
+		// assign parameter of selfTradePrevention
+		params["self_trade_prevention"] = selfTradePrevention
+	} else {
+	}
 
 	return params, nil
 }
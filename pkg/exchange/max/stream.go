@@ -55,6 +55,7 @@ func NewStream(key, secret string) *Stream {
 		log.Infof("max websocket connection authenticated: %+v", e)
 	})
 	stream.OnKLineEvent(stream.handleKLineEvent)
+	stream.OnTradeEvent(stream.handlePublicTradeEvent)
 	stream.OnOrderSnapshotEvent(stream.handleOrderSnapshotEvent)
 	stream.OnOrderUpdateEvent(stream.handleOrderUpdateEvent)
 	stream.OnTradeUpdateEvent(stream.handleTradeEvent)
@@ -64,6 +65,13 @@ func NewStream(key, secret string) *Stream {
 	return stream
 }
 
+// SetCredentials replaces the api key used by the authentication of the next connection
+func (s *Stream) SetCredentials(key, secret, passphrase string) {
+	s.key = key
+	// pragma: allowlist nextline secret
+	s.secret = secret
+}
+
 func (s *Stream) getEndpoint(ctx context.Context) (string, error) {
 	url := os.Getenv("MAX_API_WS_URL")
 	if url == "" {
@@ -144,6 +152,18 @@ func (s *Stream) handleKLineEvent(e max.KLineEvent) {
 	}
 }
 
+func (s *Stream) handlePublicTradeEvent(e max.PublicTradeEvent) {
+	for _, entry := range e.Trades {
+		trade, err := convertWebSocketPublicTrade(e.Market, entry)
+		if err != nil {
+			log.WithError(err).Error("websocket public trade convert error")
+			return
+		}
+
+		s.EmitMarketTrade(*trade)
+	}
+}
+
 func (s *Stream) handleOrderSnapshotEvent(e max.OrderSnapshotEvent) {
 	for _, o := range e.Orders {
 		globalOrder, err := convertWebSocketOrderUpdate(o)
@@ -4,8 +4,13 @@ import (
 	"context"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/exchange/recorder"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
 )
 
 func TestExchange_QueryTickers_AllSymbols(t *testing.T) {
@@ -52,3 +57,38 @@ func TestExchange_QueryTickers_SingleSymbol(t *testing.T) {
 		assert.Len(t, got, 1, "max: attempting to get 1 symbols, but number of tickers do not match")
 	}
 }
+
+func TestStream_Recorder(t *testing.T) {
+	r, err := recorder.New("testdata/recorder-kline-stream.json", recorder.ModeFromEnv())
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	defer func() {
+		assert.NoError(t, r.Stop())
+	}()
+
+	stream := NewStream("", "")
+	stream.SetPublicOnly()
+	stream.SetDialer(r.Dialer())
+	stream.Subscribe(types.KLineChannel, "BTCUSDT", types.SubscribeOptions{Interval: types.Interval1m})
+
+	klineC := make(chan types.KLine, 1)
+	stream.OnKLineClosed(func(kline types.KLine) {
+		klineC <- kline
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if !assert.NoError(t, stream.Connect(ctx)) {
+		return
+	}
+
+	select {
+	case kline := <-klineC:
+		assert.Equal(t, "BTCUSDT", kline.Symbol)
+		assert.Equal(t, fixedpoint.MustNewFromString("29101.0"), kline.Close)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the recorded kline is not received")
+	}
+}
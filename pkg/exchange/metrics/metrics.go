@@ -0,0 +1,139 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	requestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "bbgo_exchange_request_duration_seconds",
+			Help:    "bbgo exchange REST request latency",
+			Buckets: []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+		},
+		[]string{
+			"exchange", // exchange name
+			"session",  // session name
+			"method",   // http method
+			"endpoint", // request path, the numeric segments are replaced with ":id"
+		},
+	)
+
+	requestErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bbgo_exchange_request_errors_total",
+			Help: "bbgo exchange REST request errors",
+		},
+		[]string{
+			"exchange", // exchange name
+			"session",  // session name
+			"method",   // http method
+			"endpoint", // request path, the numeric segments are replaced with ":id"
+			"status",   // http status code, or "error" for the transport errors
+		},
+	)
+
+	requestsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bbgo_exchange_requests_total",
+			Help: "bbgo exchange REST requests",
+		},
+		[]string{
+			"exchange", // exchange name
+			"session",  // session name
+			"method",   // http method
+			"endpoint", // request path, the numeric segments are replaced with ":id"
+		},
+	)
+
+	rateLimitUtilization = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "bbgo_exchange_rate_limit_utilization",
+			Help: "bbgo exchange rate limit utilization ratio reported by the response headers",
+		},
+		[]string{
+			"exchange", // exchange name
+			"session",  // session name
+			"limit",    // rate limit name
+		},
+	)
+
+	streamReconnects = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bbgo_exchange_stream_reconnects_total",
+			Help: "bbgo exchange websocket stream reconnects",
+		},
+		[]string{
+			"exchange", // exchange name
+			"session",  // session name
+			"channel",  // channel: user or market
+		},
+	)
+
+	listenKeyRenewals = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bbgo_exchange_listen_key_renewals_total",
+			Help: "bbgo exchange user data stream listen key renewals",
+		},
+		[]string{
+			"exchange", // exchange name
+			"scope",    // account scope of the listen key: spot, margin, isolated_margin:{symbol} or futures
+			"reason",   // expired, keepalive_failure or silent_expiry
+		},
+	)
+
+	listenKeyFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "bbgo_exchange_listen_key_failures_total",
+			Help: "bbgo exchange user data stream listen key request failures",
+		},
+		[]string{
+			"exchange",  // exchange name
+			"scope",     // account scope of the listen key: spot, margin, isolated_margin:{symbol} or futures
+			"operation", // create or keepalive
+		},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(
+		requestDuration,
+		requestErrors,
+		requestsTotal,
+		rateLimitUtilization,
+		streamReconnects,
+		listenKeyRenewals,
+		listenKeyFailures,
+	)
+}
+
+// Instrumenter is implemented by the exchange adapters that can instrument their REST clients
+type Instrumenter interface {
+	// EnableMetrics wraps the http clients of the exchange with the metrics transport labeled by the session name
+	EnableMetrics(session string)
+}
+
+// IncStreamReconnects increases the websocket reconnect counter of the session stream
+func IncStreamReconnects(exchange, session, channel string) {
+	streamReconnects.With(prometheus.Labels{
+		"exchange": exchange,
+		"session":  session,
+		"channel":  channel,
+	}).Inc()
+}
+
+// IncListenKeyRenewals increases the listen key renewal counter
+func IncListenKeyRenewals(exchange, scope, reason string) {
+	listenKeyRenewals.With(prometheus.Labels{
+		"exchange": exchange,
+		"scope":    scope,
+		"reason":   reason,
+	}).Inc()
+}
+
+// IncListenKeyFailures increases the listen key request failure counter
+func IncListenKeyFailures(exchange, scope, operation string) {
+	listenKeyFailures.With(prometheus.Labels{
+		"exchange":  exchange,
+		"scope":     scope,
+		"operation": operation,
+	}).Inc()
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RateLimitHeader describes the response headers of a rate limit.
+// The utilization is UsedHeader / limit, or (limit - RemainingHeader) / limit,
+// the limit is read from LimitHeader, or Limit if the header is not given.
+type RateLimitHeader struct {
+	Name            string
+	UsedHeader      string
+	RemainingHeader string
+	LimitHeader     string
+	Limit           float64
+}
+
+func (h RateLimitHeader) utilization(header http.Header) (float64, bool) {
+	limit := h.Limit
+	if h.LimitHeader != "" {
+		if v, ok := parseHeaderFloat(header, h.LimitHeader); ok {
+			limit = v
+		}
+	}
+
+	if limit <= 0 {
+		return 0, false
+	}
+
+	if h.UsedHeader != "" {
+		if used, ok := parseHeaderFloat(header, h.UsedHeader); ok {
+			return used / limit, true
+		}
+	}
+
+	if h.RemainingHeader != "" {
+		if remaining, ok := parseHeaderFloat(header, h.RemainingHeader); ok {
+			return (limit - remaining) / limit, true
+		}
+	}
+
+	return 0, false
+}
+
+func parseHeaderFloat(header http.Header, key string) (float64, bool) {
+	s := header.Get(key)
+	if s == "" {
+		return 0, false
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	return v, err == nil
+}
+
+// Transport is a http.RoundTripper that records the latency, the errors and the rate limit utilization of the requests
+type Transport struct {
+	Base     http.RoundTripper
+	Exchange string
+	Session  string
+
+	RateLimitHeaders []RateLimitHeader
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	labels := prometheus.Labels{
+		"exchange": t.Exchange,
+		"session":  t.Session,
+		"method":   req.Method,
+		"endpoint": NormalizeEndpoint(req.URL.Path),
+	}
+
+	startTime := time.Now()
+	resp, err := base.RoundTrip(req)
+	requestDuration.With(labels).Observe(time.Since(startTime).Seconds())
+	requestsTotal.With(labels).Inc()
+
+	if err != nil {
+		requestErrors.With(errorLabels(labels, "error")).Inc()
+		return resp, err
+	}
+
+	if resp.StatusCode >= 400 {
+		requestErrors.With(errorLabels(labels, strconv.Itoa(resp.StatusCode))).Inc()
+	}
+
+	for _, h := range t.RateLimitHeaders {
+		if utilization, ok := h.utilization(resp.Header); ok {
+			rateLimitUtilization.With(prometheus.Labels{
+				"exchange": t.Exchange,
+				"session":  t.Session,
+				"limit":    h.Name,
+			}).Set(utilization)
+		}
+	}
+
+	return resp, nil
+}
+
+func errorLabels(labels prometheus.Labels, status string) prometheus.Labels {
+	return prometheus.Labels{
+		"exchange": labels["exchange"],
+		"session":  labels["session"],
+		"method":   labels["method"],
+		"endpoint": labels["endpoint"],
+		"status":   status,
+	}
+}
+
+// InstrumentClient returns a copy of the http client with the metrics transport,
+// the client is copied because the default clients are shared between the sessions.
+func InstrumentClient(client *http.Client, exchange, session string, rateLimitHeaders ...RateLimitHeader) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	instrumented := *client
+	instrumented.Transport = &Transport{
+		Base:             client.Transport,
+		Exchange:         exchange,
+		Session:          session,
+		RateLimitHeaders: rateLimitHeaders,
+	}
+
+	return &instrumented
+}
+
+// NormalizeEndpoint replaces the numeric path segments with ":id" to keep the label cardinality low
+func NormalizeEndpoint(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment == "" {
+			continue
+		}
+
+		if _, err := strconv.ParseUint(segment, 10, 64); err == nil {
+			segments[i] = ":id"
+		}
+	}
+
+	return strings.Join(segments, "/")
+}
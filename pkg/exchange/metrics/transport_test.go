@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Mbx-Used-Weight-1m", "600")
+		w.Header().Set("Gw-Ratelimit-Limit", "2000")
+		w.Header().Set("Gw-Ratelimit-Remaining", "1500")
+		if r.URL.Path == "/api/v3/order/123" {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	client := InstrumentClient(server.Client(), "binance", "test", []RateLimitHeader{
+		{Name: "weight_1m", UsedHeader: "X-Mbx-Used-Weight-1m", Limit: 6000},
+		{Name: "gateway", RemainingHeader: "Gw-Ratelimit-Remaining", LimitHeader: "Gw-Ratelimit-Limit"},
+		{Name: "missing", UsedHeader: "X-Missing", Limit: 100},
+	}...)
+	assert.NotSame(t, server.Client(), client)
+
+	for _, path := range []string{"/api/v3/order/123", "/api/v3/account"} {
+		resp, err := client.Get(server.URL + path)
+		if assert.NoError(t, err) {
+			_ = resp.Body.Close()
+		}
+	}
+
+	labels := prometheus.Labels{"exchange": "binance", "session": "test", "method": "GET", "endpoint": "/api/v3/order/:id"}
+	assert.Equal(t, 1.0, testutil.ToFloat64(requestsTotal.With(labels)))
+	labels["status"] = "429"
+	assert.Equal(t, 1.0, testutil.ToFloat64(requestErrors.With(labels)))
+
+	assert.InDelta(t, 0.1, testutil.ToFloat64(rateLimitUtilization.With(prometheus.Labels{
+		"exchange": "binance", "session": "test", "limit": "weight_1m",
+	})), 1e-9)
+	assert.InDelta(t, 0.25, testutil.ToFloat64(rateLimitUtilization.With(prometheus.Labels{
+		"exchange": "binance", "session": "test", "limit": "gateway",
+	})), 1e-9)
+	// the missing header is not reported
+	assert.Equal(t, 2, testutil.CollectAndCount(rateLimitUtilization))
+}
+
+func TestNormalizeEndpoint(t *testing.T) {
+	assert.Equal(t, "/api/v2/orders/:id/trades", NormalizeEndpoint("/api/v2/orders/12345/trades"))
+	assert.Equal(t, "/api/v3/order", NormalizeEndpoint("/api/v3/order"))
+	assert.Equal(t, "", NormalizeEndpoint(""))
+}
@@ -105,6 +105,12 @@ func convertSubscription(s types.Subscription) (WebsocketSubscription, error) {
 			Channel:      "books5",
 			InstrumentID: toLocalSymbol(s.Symbol),
 		}, nil
+
+	case types.MarketTradeChannel:
+		return WebsocketSubscription{
+			Channel:      "trades",
+			InstrumentID: toLocalSymbol(s.Symbol),
+		}, nil
 	}
 
 	return WebsocketSubscription{}, fmt.Errorf("unsupported public stream channel %s", s.Channel)
@@ -255,6 +261,21 @@ func toLocalOrderType(orderType types.OrderType) (okexapi.OrderType, error) {
 	return "", fmt.Errorf("unknown or unsupported okex order type: %s", orderType)
 }
 
+func toLocalSelfTradePreventionMode(mode types.SelfTradePreventionMode) (string, error) {
+	switch mode {
+	case types.SelfTradePreventionExpireTaker:
+		return "cancel_taker", nil
+
+	case types.SelfTradePreventionExpireMaker:
+		return "cancel_maker", nil
+
+	case types.SelfTradePreventionExpireBoth:
+		return "cancel_both", nil
+	}
+
+	return "", fmt.Errorf("unknown or unsupported okex self-trade prevention mode: %s", mode)
+}
+
 func toGlobalOrderType(orderType okexapi.OrderType) (types.OrderType, error) {
 	switch orderType {
 	case okexapi.OrderTypeMarket:
@@ -10,6 +10,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"golang.org/x/time/rate"
 
+	"github.com/c9s/bbgo/pkg/exchange/metrics"
 	"github.com/c9s/bbgo/pkg/exchange/okex/okexapi"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
@@ -51,6 +52,20 @@ func (e *Exchange) Name() types.ExchangeName {
 	return types.ExchangeOKEx
 }
 
+// EnableMetrics instruments the REST client with the session label
+func (e *Exchange) EnableMetrics(session string) {
+	e.client.SetHTTPClient(metrics.InstrumentClient(e.client.HTTPClient(), e.Name().String(), session))
+}
+
+// SetCredentials replaces the api key of the REST client, the stream shares the same client
+func (e *Exchange) SetCredentials(key, secret, passphrase string) {
+	e.key = key
+	// pragma: allowlist nextline secret
+	e.secret = secret
+	e.passphrase = passphrase
+	e.client.Auth(key, secret, passphrase)
+}
+
 func (e *Exchange) QueryMarkets(ctx context.Context) (types.MarketMap, error) {
 	instruments, err := e.client.PublicDataService.NewGetInstrumentsRequest().
 		InstrumentType(okexapi.InstrumentTypeSpot).
@@ -189,6 +204,15 @@ func (e *Exchange) SubmitOrder(ctx context.Context, order types.SubmitOrder) (*t
 		}
 	}
 
+	if order.SelfTradePrevention != types.SelfTradePreventionNone {
+		selfTradePreventionMode, err := toLocalSelfTradePreventionMode(order.SelfTradePrevention)
+		if err != nil {
+			return nil, err
+		}
+
+		orderReq.SelfTradePreventionMode(selfTradePreventionMode)
+	}
+
 	switch order.TimeInForce {
 	case "FOK":
 		orderReq.OrderType(okexapi.OrderTypeFOK)
@@ -265,6 +289,20 @@ func (e *Exchange) QueryOpenOrders(ctx context.Context, symbol string) (orders [
 	return orders, err
 }
 
+// CancelAllAfter arms the cancel-all-after timer of the account, the symbols are ignored because the timer is account-wide.
+// The timeout is rounded up to the range of [10s, 120s] accepted by okx.
+func (e *Exchange) CancelAllAfter(ctx context.Context, timeout time.Duration, symbols ...string) error {
+	seconds := int(math.Ceil(timeout.Seconds()))
+	if seconds > 0 && seconds < 10 {
+		seconds = 10
+	} else if seconds > 120 {
+		seconds = 120
+	}
+
+	_, err := e.client.TradeService.NewCancelAllAfterRequest().Timeout(seconds).Do(ctx)
+	return err
+}
+
 func (e *Exchange) CancelOrders(ctx context.Context, orders ...types.Order) error {
 	if len(orders) == 0 {
 		return nil
@@ -89,6 +89,16 @@ func NewClient() *RestClient {
 	return client
 }
 
+// HTTPClient returns the http client used to send the requests
+func (c *RestClient) HTTPClient() *http.Client {
+	return c.client
+}
+
+// SetHTTPClient replaces the http client used to send the requests
+func (c *RestClient) SetHTTPClient(client *http.Client) {
+	c.client = client
+}
+
 func (c *RestClient) Auth(key, secret, passphrase string) {
 	c.Key = key
 	// pragma: allowlist nextline secret
@@ -48,6 +48,11 @@ func (p *PlaceOrderRequest) Price(price string) *PlaceOrderRequest {
 	return p
 }
 
+func (p *PlaceOrderRequest) SelfTradePreventionMode(selfTradePreventionMode string) *PlaceOrderRequest {
+	p.selfTradePreventionMode = &selfTradePreventionMode
+	return p
+}
+
 func (p *PlaceOrderRequest) GetParameters() (map[string]interface{}, error) {
 	var params = map[string]interface{}{}
 
@@ -123,6 +128,23 @@ func (p *PlaceOrderRequest) GetParameters() (map[string]interface{}, error) {
 		params["px"] = price
 	}
 
+	// check selfTradePreventionMode field -> json key stpMode
+	if p.selfTradePreventionMode != nil {
+		selfTradePreventionMode := *p.selfTradePreventionMode
+
+		switch selfTradePreventionMode {
+		case "cancel_maker", "cancel_taker", "cancel_both":
+			params["stpMode"] = selfTradePreventionMode
+
+		default:
+			return params, fmt.Errorf("stpMode value %v is invalid", selfTradePreventionMode)
+
+		}
+
+		// assign parameter of selfTradePreventionMode
+		params["stpMode"] = selfTradePreventionMode
+	}
+
 	return params, nil
 }
 
@@ -2,7 +2,9 @@ package okexapi
 
 import (
 	"context"
+	"fmt"
 	"net/url"
+	"strconv"
 	"strings"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
@@ -46,6 +48,12 @@ func (c *TradeService) NewBatchCancelOrderRequest() *BatchCancelOrderRequest {
 	}
 }
 
+func (c *TradeService) NewCancelAllAfterRequest() *CancelAllAfterRequest {
+	return &CancelAllAfterRequest{
+		client: c.client,
+	}
+}
+
 func (c *TradeService) NewGetOrderDetailsRequest() *GetOrderDetailsRequest {
 	return &GetOrderDetailsRequest{
 		client: c.client,
@@ -90,6 +98,9 @@ type PlaceOrderRequest struct {
 
 	// price
 	price *string `param:"px"`
+
+	// self-trade prevention mode
+	selfTradePreventionMode *string `param:"stpMode" validValues:"cancel_maker,cancel_taker,cancel_both"`
 }
 
 func (r *PlaceOrderRequest) Parameters() map[string]interface{} {
@@ -171,6 +182,56 @@ func (r *CancelOrderRequest) Do(ctx context.Context) ([]OrderResponse, error) {
 	return orderResponse.Data, nil
 }
 
+type CancelAllAfterResponse struct {
+	TriggerTime types.MillisecondTimestamp `json:"triggerTime"`
+	Tag         string                     `json:"tag"`
+	Timestamp   types.MillisecondTimestamp `json:"ts"`
+}
+
+// CancelAllAfterRequest cancels all the pending orders after the timeout,
+// the timeout is in seconds, it can be 0 to disable the timer, or in the range of [10, 120].
+type CancelAllAfterRequest struct {
+	client *RestClient
+
+	timeout int
+}
+
+func (r *CancelAllAfterRequest) Timeout(seconds int) *CancelAllAfterRequest {
+	r.timeout = seconds
+	return r
+}
+
+func (r *CancelAllAfterRequest) Do(ctx context.Context) (*CancelAllAfterResponse, error) {
+	payload := map[string]interface{}{
+		"timeOut": strconv.Itoa(r.timeout),
+	}
+
+	req, err := r.client.newAuthenticatedRequest("POST", "/api/v5/trade/cancel-all-after", nil, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.client.sendRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var apiResponse struct {
+		Code    string                   `json:"code"`
+		Message string                   `json:"msg"`
+		Data    []CancelAllAfterResponse `json:"data"`
+	}
+	if err := response.DecodeJSON(&apiResponse); err != nil {
+		return nil, err
+	}
+
+	if len(apiResponse.Data) == 0 {
+		return nil, fmt.Errorf("cancel-all-after error: %s %s", apiResponse.Code, apiResponse.Message)
+	}
+
+	return &apiResponse.Data[0], nil
+}
+
 type BatchCancelOrderRequest struct {
 	client *RestClient
 
@@ -294,6 +294,47 @@ func parseCandle(channel string, v *fastjson.Value) (*Candle, error) {
 	}, nil
 }
 
+// MarketTradeEvent is the public trade of the trades channel, the side is the taker side
+type MarketTradeEvent struct {
+	InstrumentID string                     `json:"instId"`
+	TradeID      string                     `json:"tradeId"`
+	Price        fixedpoint.Value           `json:"px"`
+	Size         fixedpoint.Value           `json:"sz"`
+	Side         string                     `json:"side"`
+	Timestamp    types.MillisecondTimestamp `json:"ts"`
+}
+
+func (e *MarketTradeEvent) Trade() (types.Trade, error) {
+	tradeID, err := strconv.ParseUint(e.TradeID, 10, 64)
+	if err != nil {
+		return types.Trade{}, fmt.Errorf("unexpected trade id %q: %w", e.TradeID, err)
+	}
+
+	side := types.SideType(strings.ToUpper(e.Side))
+	return types.Trade{
+		ID:            tradeID,
+		Exchange:      types.ExchangeOKEx,
+		Symbol:        toGlobalSymbol(e.InstrumentID),
+		Side:          side,
+		Price:         e.Price,
+		Quantity:      e.Size,
+		QuoteQuantity: e.Price.Mul(e.Size),
+		IsBuyer:       side == types.SideTypeBuy,
+		Time:          types.Time(e.Timestamp.Time()),
+	}, nil
+}
+
+func parseMarketTrades(v *fastjson.Value) ([]MarketTradeEvent, error) {
+	data := v.Get("data").MarshalTo(nil)
+
+	var trades []MarketTradeEvent
+	if err := json.Unmarshal(data, &trades); err != nil {
+		return nil, err
+	}
+
+	return trades, nil
+}
+
 func parseAccount(v *fastjson.Value) (*okexapi.Account, error) {
 	data := v.Get("data").MarshalTo(nil)
 
@@ -335,6 +376,8 @@ func parseData(v *fastjson.Value) (interface{}, error) {
 		data, err := parseBookData(v)
 		data.channel = channel
 		return data, err
+	case "trades":
+		return parseMarketTrades(v)
 	case "account":
 		return parseAccount(v)
 	case "orders":
@@ -33,6 +33,7 @@ type Stream struct {
 	eventCallbacks             []func(event WebSocketEvent)
 	accountEventCallbacks      []func(account okexapi.Account)
 	orderDetailsEventCallbacks []func(orderDetails []okexapi.OrderDetails)
+	marketTradeEventCallbacks  []func(trades []MarketTradeEvent)
 
 	lastCandle map[CandleKey]Candle
 }
@@ -55,6 +56,7 @@ func NewStream(client *okexapi.RestClient) *Stream {
 
 	stream.OnCandleEvent(stream.handleCandleEvent)
 	stream.OnBookEvent(stream.handleBookEvent)
+	stream.OnMarketTradeEvent(stream.handleMarketTradeEvent)
 	stream.OnAccountEvent(stream.handleAccountEvent)
 	stream.OnOrderDetailsEvent(stream.handleOrderDetailsEvent)
 	stream.OnEvent(stream.handleEvent)
@@ -173,6 +175,18 @@ func (s *Stream) handleBookEvent(data BookEvent) {
 	}
 }
 
+func (s *Stream) handleMarketTradeEvent(trades []MarketTradeEvent) {
+	for _, e := range trades {
+		trade, err := e.Trade()
+		if err != nil {
+			log.WithError(err).Error("market trade convert error")
+			continue
+		}
+
+		s.EmitMarketTrade(trade)
+	}
+}
+
 func (s *Stream) handleCandleEvent(candle Candle) {
 	key := CandleKey{Channel: candle.Channel, InstrumentID: candle.InstrumentID}
 	kline := candle.KLine()
@@ -213,6 +227,9 @@ func (s *Stream) dispatchEvent(e interface{}) {
 	case *Candle:
 		s.EmitCandleEvent(*et)
 
+	case []MarketTradeEvent:
+		s.EmitMarketTradeEvent(et)
+
 	case *okexapi.Account:
 		s.EmitAccountEvent(*et)
 
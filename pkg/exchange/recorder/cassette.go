@@ -0,0 +1,88 @@
+package recorder
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// Cassette is the fixture file of the recorded REST interactions and websocket sessions
+type Cassette struct {
+	Interactions []Interaction      `json:"interactions"`
+	Websockets   []WebsocketSession `json:"websockets,omitempty"`
+}
+
+// Interaction is a recorded REST request and its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the sanitized request, the ignored parameters (the signature, the timestamp and the nonce)
+// and the request headers are not recorded, so the fixtures contain no credential.
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body"`
+}
+
+// MessageDirection is the direction of a websocket message, seen from the client
+type MessageDirection string
+
+const (
+	// MessageSend is the message sent by the client, e.g., the subscribe command
+	MessageSend MessageDirection = "send"
+
+	// MessageRecv is the message received from the server
+	MessageRecv MessageDirection = "recv"
+)
+
+type WebsocketMessage struct {
+	Direction MessageDirection `json:"direction"`
+
+	// Type is the websocket message type, websocket.TextMessage or websocket.BinaryMessage
+	Type int    `json:"type"`
+	Data string `json:"data"`
+}
+
+// WebsocketSession is the recorded messages of a websocket connection
+type WebsocketSession struct {
+	URL      string             `json:"url"`
+	Messages []WebsocketMessage `json:"messages"`
+}
+
+// LoadCassette reads the cassette from the fixture file
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var cassette Cassette
+	if err := json.Unmarshal(data, &cassette); err != nil {
+		return nil, err
+	}
+
+	return &cassette, nil
+}
+
+// Save writes the cassette to the fixture file, the parent directory is created if it does not exist
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,318 @@
+package recorder
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/c9s/bbgo/pkg/util"
+)
+
+// Mode is the mode of the recorder
+type Mode int
+
+const (
+	// ModeReplay replays the recorded fixtures, the requests that are not recorded fail
+	ModeReplay Mode = iota
+
+	// ModeRecord sends the requests to the exchange and records the traffic to the fixtures
+	ModeRecord
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	}
+
+	return fmt.Sprintf("Mode(%d)", int(m))
+}
+
+// RecordEnvVar is the environment variable that switches the recorder to the record mode
+const RecordEnvVar = "BBGO_RECORD_FIXTURES"
+
+// ModeFromEnv returns ModeRecord if BBGO_RECORD_FIXTURES is set to true, otherwise ModeReplay,
+// so the regression tests replay the fixtures by default and re-record them with the live keys on demand.
+func ModeFromEnv() Mode {
+	if v, ok := util.GetEnvVarBool(RecordEnvVar); ok && v {
+		return ModeRecord
+	}
+
+	return ModeReplay
+}
+
+// DefaultIgnoredParams are the parameters that change on every request,
+// they are removed from the recorded requests and ignored when matching the requests.
+var DefaultIgnoredParams = []string{
+	"signature",
+	"timestamp",
+	"recvWindow",
+	"nonce",
+	"sign",
+}
+
+// sensitiveResponseHeaders are not recorded
+var sensitiveResponseHeaders = []string{
+	"Set-Cookie",
+}
+
+// Recorder is a VCR-style recorder of the REST and the websocket traffic.
+// In the record mode, the requests are sent to the exchange and the interactions are saved to the cassette file by Stop.
+// In the replay mode, the responses are read from the cassette file, no request is sent to the exchange.
+type Recorder struct {
+	mode Mode
+	path string
+
+	// Base is the transport used in the record mode, defaults to http.DefaultTransport
+	Base http.RoundTripper
+
+	// IgnoredParams are the query, form and top-level JSON parameters that are not recorded and not matched
+	IgnoredParams []string
+
+	mu       sync.Mutex
+	cassette *Cassette
+
+	// replayed marks the replayed interactions and websocket sessions
+	replayed          []bool
+	replayedWebsocket []bool
+
+	servers []*httptest.Server
+}
+
+// New creates the recorder of the cassette file, the cassette is loaded in the replay mode
+func New(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{
+		mode:          mode,
+		path:          path,
+		IgnoredParams: DefaultIgnoredParams,
+		cassette:      &Cassette{},
+	}
+
+	if mode == ModeReplay {
+		cassette, err := LoadCassette(path)
+		if err != nil {
+			return nil, fmt.Errorf("can not load the cassette %s: %w", path, err)
+		}
+
+		r.cassette = cassette
+		r.replayed = make([]bool, len(cassette.Interactions))
+		r.replayedWebsocket = make([]bool, len(cassette.Websockets))
+	}
+
+	return r, nil
+}
+
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Client returns a copy of the http client with the recorder transport,
+// the client is copied because the default clients are shared between the exchange instances.
+func (r *Recorder) Client(client *http.Client) *http.Client {
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	if r.mode == ModeRecord && r.Base == nil {
+		r.Base = client.Transport
+	}
+
+	recorded := *client
+	recorded.Transport = r
+	return &recorded
+}
+
+// Stop closes the replay and the proxy servers of the websocket connections,
+// in the record mode the cassette is saved.
+func (r *Recorder) Stop() error {
+	r.mu.Lock()
+	servers := r.servers
+	r.servers = nil
+	r.mu.Unlock()
+
+	for _, server := range servers {
+		server.CloseClientConnections()
+		server.Close()
+	}
+
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.path)
+}
+
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	recordedReq := Request{
+		Method: req.Method,
+		URL:    r.normalizeURL(req.URL),
+		Body:   r.normalizeBody(body),
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recordedReq)
+	}
+
+	base := r.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	header := resp.Header.Clone()
+	for _, key := range sensitiveResponseHeaders {
+		header.Del(key)
+	}
+
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recordedReq,
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     header,
+			Body:       string(respBody),
+		},
+	})
+	r.mu.Unlock()
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return resp, nil
+}
+
+// replay returns the response of the first interaction that matches the request and is not replayed yet
+func (r *Recorder) replay(req *http.Request, recordedReq Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.replayed[i] || interaction.Request != recordedReq {
+			continue
+		}
+
+		r.replayed[i] = true
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        interaction.Response.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("recorder: no recorded interaction for %s %s in %s", recordedReq.Method, recordedReq.URL, r.path)
+}
+
+// Unreplayed returns the recorded interactions that are not replayed,
+// the regression tests can use it to verify that the adapter sends all the expected requests.
+func (r *Recorder) Unreplayed() (interactions []Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, replayed := range r.replayed {
+		if !replayed {
+			interactions = append(interactions, r.cassette.Interactions[i])
+		}
+	}
+
+	return interactions
+}
+
+func (r *Recorder) isIgnored(key string) bool {
+	for _, param := range r.IgnoredParams {
+		if param == key {
+			return true
+		}
+	}
+
+	return false
+}
+
+// normalizeURL removes the ignored query parameters and sorts the remaining ones
+func (r *Recorder) normalizeURL(u *url.URL) string {
+	normalized := *u
+	normalized.RawQuery = r.normalizeValues(u.Query())
+	normalized.User = nil
+	return normalized.String()
+}
+
+func (r *Recorder) normalizeValues(values url.Values) string {
+	for key := range values {
+		if r.isIgnored(key) {
+			values.Del(key)
+		}
+	}
+
+	// url.Values.Encode sorts the keys
+	return values.Encode()
+}
+
+// normalizeBody removes the ignored parameters from the JSON object or the form encoded body
+func (r *Recorder) normalizeBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+
+	var object map[string]interface{}
+	if err := json.Unmarshal(body, &object); err == nil {
+		for key := range object {
+			if r.isIgnored(key) {
+				delete(object, key)
+			}
+		}
+
+		// the map keys are sorted by the json encoder
+		if data, err := json.Marshal(object); err == nil {
+			return string(data)
+		}
+	}
+
+	if values, err := url.ParseQuery(string(body)); err == nil && len(values) > 0 {
+		return r.normalizeValues(values)
+	}
+
+	return string(body)
+}
+
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
@@ -0,0 +1,132 @@
+package recorder
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestExchangeServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v3/order", func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("X-Used-Weight", "2")
+		_, _ = w.Write([]byte(`{"symbol":"` + req.URL.Query().Get("symbol") + `","body":"` + string(body) + `"}`))
+	})
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			return
+		}
+
+		defer conn.Close()
+		_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"event":"welcome"}`))
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				return
+			}
+
+			_ = conn.WriteMessage(websocket.TextMessage, []byte(`{"ack":`+string(data)+`}`))
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func doTestRequest(t *testing.T, client *http.Client, serverURL, timestamp string) (*http.Response, string) {
+	req, err := http.NewRequest(http.MethodPost, serverURL+"/api/v3/order?symbol=BTCUSDT&timestamp="+timestamp+"&signature=abc"+timestamp, strings.NewReader("side=BUY&nonce="+timestamp))
+	require.NoError(t, err)
+	req.Header.Set("X-MBX-APIKEY", "key")
+
+	resp, err := client.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp, string(body)
+}
+
+func dialTestStream(t *testing.T, r *Recorder, serverURL string) []string {
+	conn, _, err := r.Dialer().Dial("ws"+strings.TrimPrefix(serverURL, "http")+"/ws", nil)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	var messages []string
+	_, data, err := conn.ReadMessage()
+	require.NoError(t, err)
+	messages = append(messages, string(data))
+
+	require.NoError(t, conn.WriteMessage(websocket.TextMessage, []byte(`"subscribe"`)))
+	_, data, err = conn.ReadMessage()
+	require.NoError(t, err)
+	messages = append(messages, string(data))
+	return messages
+}
+
+func TestRecorder_RecordAndReplay(t *testing.T) {
+	server := newTestExchangeServer(t)
+	path := filepath.Join(t.TempDir(), "fixtures", "order.json")
+
+	recording, err := New(path, ModeRecord)
+	require.NoError(t, err)
+
+	resp, body := doTestRequest(t, recording.Client(nil), server.URL, "1000")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, `{"symbol":"BTCUSDT","body":"side=BUY&nonce=1000"}`, body)
+
+	messages := dialTestStream(t, recording, server.URL)
+	assert.Equal(t, []string{`{"event":"welcome"}`, `{"ack":"subscribe"}`}, messages)
+	require.NoError(t, recording.Stop())
+
+	cassette, err := LoadCassette(path)
+	require.NoError(t, err)
+	if assert.Len(t, cassette.Interactions, 1) {
+		interaction := cassette.Interactions[0]
+		assert.Equal(t, server.URL+"/api/v3/order?symbol=BTCUSDT", interaction.Request.URL, "the signature and the timestamp are not recorded")
+		assert.Equal(t, "side=BUY", interaction.Request.Body)
+		assert.Empty(t, interaction.Response.Header.Get("Set-Cookie"))
+	}
+
+	if assert.Len(t, cassette.Websockets, 1) {
+		assert.Len(t, cassette.Websockets[0].Messages, 3)
+	}
+
+	// the exchange is not available in the replay mode
+	server.Close()
+
+	replaying, err := New(path, ModeReplay)
+	require.NoError(t, err)
+	defer replaying.Stop()
+
+	resp, body = doTestRequest(t, replaying.Client(nil), server.URL, "2000")
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "2", resp.Header.Get("X-Used-Weight"))
+	assert.Equal(t, `{"symbol":"BTCUSDT","body":"side=BUY&nonce=1000"}`, body)
+	assert.Empty(t, replaying.Unreplayed())
+
+	messages = dialTestStream(t, replaying, server.URL)
+	assert.Equal(t, []string{`{"event":"welcome"}`, `{"ack":"subscribe"}`}, messages)
+
+	// every interaction is replayed once
+	_, err = replaying.Client(nil).Get(server.URL + "/api/v3/order?symbol=BTCUSDT")
+	assert.Error(t, err)
+}
+
+func TestRecorder_NormalizeBody(t *testing.T) {
+	r := &Recorder{IgnoredParams: DefaultIgnoredParams}
+	assert.Equal(t, `{"market":"btcusdt","volume":"0.1"}`, r.normalizeBody([]byte(`{"volume":"0.1","nonce":1690000000000,"market":"btcusdt"}`)))
+	assert.Equal(t, "side=BUY&symbol=BTCUSDT", r.normalizeBody([]byte("symbol=BTCUSDT&side=BUY&timestamp=1")))
+	assert.Equal(t, "", r.normalizeBody(nil))
+}
@@ -0,0 +1,173 @@
+package recorder
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var upgrader = websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// websocketDialer dials a local server instead of the exchange,
+// the local server proxies and records the messages in the record mode and sends the recorded messages in the replay mode.
+type websocketDialer struct {
+	recorder *Recorder
+}
+
+// Dialer returns the websocket dialer of the recorder, it's passed to StandardStream.SetDialer
+func (r *Recorder) Dialer() types.WebsocketDialer {
+	return &websocketDialer{recorder: r}
+}
+
+func (d *websocketDialer) Dial(urlStr string, requestHeader http.Header) (*websocket.Conn, *http.Response, error) {
+	r := d.recorder
+
+	u, err := url.Parse(urlStr)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	normalizedURL := r.normalizeURL(u)
+
+	var handler http.Handler
+	if r.mode == ModeRecord {
+		handler = r.newProxyHandler(urlStr, requestHeader, normalizedURL)
+	} else {
+		session, err := r.nextWebsocketSession(normalizedURL)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		handler = newReplayHandler(session)
+	}
+
+	server := httptest.NewServer(handler)
+	r.mu.Lock()
+	r.servers = append(r.servers, server)
+	r.mu.Unlock()
+
+	return websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+}
+
+// nextWebsocketSession returns the first recorded session of the url that is not replayed yet
+func (r *Recorder) nextWebsocketSession(normalizedURL string) (*WebsocketSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i := range r.cassette.Websockets {
+		if r.replayedWebsocket[i] || r.cassette.Websockets[i].URL != normalizedURL {
+			continue
+		}
+
+		r.replayedWebsocket[i] = true
+		return &r.cassette.Websockets[i], nil
+	}
+
+	return nil, fmt.Errorf("recorder: no recorded websocket session for %s in %s", normalizedURL, r.path)
+}
+
+// newReplayHandler sends the received messages of the session in order,
+// a sent message of the session is replayed by waiting for the next client message, e.g., the subscribe command.
+func newReplayHandler(session *WebsocketSession) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			log.WithError(err).Error("recorder: websocket upgrade error")
+			return
+		}
+
+		defer conn.Close()
+
+		for _, message := range session.Messages {
+			switch message.Direction {
+			case MessageSend:
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+
+			case MessageRecv:
+				if err := conn.WriteMessage(message.Type, []byte(message.Data)); err != nil {
+					return
+				}
+			}
+		}
+
+		// keep the connection until the client closes it, so the stream does not reconnect
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// newProxyHandler forwards the messages between the client and the exchange and records them
+func (r *Recorder) newProxyHandler(urlStr string, requestHeader http.Header, normalizedURL string) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		upstream, _, err := websocket.DefaultDialer.Dial(urlStr, requestHeader)
+		if err != nil {
+			log.WithError(err).Errorf("recorder: can not dial %s", normalizedURL)
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+
+		defer upstream.Close()
+
+		conn, err := upgrader.Upgrade(w, req, nil)
+		if err != nil {
+			log.WithError(err).Error("recorder: websocket upgrade error")
+			return
+		}
+
+		defer conn.Close()
+
+		r.mu.Lock()
+		r.cassette.Websockets = append(r.cassette.Websockets, WebsocketSession{URL: normalizedURL})
+		index := len(r.cassette.Websockets) - 1
+		r.mu.Unlock()
+
+		record := func(direction MessageDirection, messageType int, data []byte) {
+			r.mu.Lock()
+			session := &r.cassette.Websockets[index]
+			session.Messages = append(session.Messages, WebsocketMessage{
+				Direction: direction,
+				Type:      messageType,
+				Data:      string(data),
+			})
+			r.mu.Unlock()
+		}
+
+		var once sync.Once
+		done := make(chan struct{})
+		closeDone := func() { once.Do(func() { close(done) }) }
+
+		pipe := func(direction MessageDirection, from, to *websocket.Conn) {
+			defer closeDone()
+			for {
+				messageType, data, err := from.ReadMessage()
+				if err != nil {
+					return
+				}
+
+				record(direction, messageType, data)
+				if err := to.WriteMessage(messageType, data); err != nil {
+					return
+				}
+			}
+		}
+
+		go pipe(MessageSend, conn, upstream)
+		go pipe(MessageRecv, upstream, conn)
+		<-done
+	}
+}
@@ -345,6 +345,11 @@ func (s *Server) ListenAndServe(bind string) error {
 		Trader:  s.Trader,
 	})
 
+	RegisterStrategyInstanceServiceServer(grpcServer, &StrategyInstanceService{
+		Environ: s.Environ,
+		Trader:  s.Trader,
+	})
+
 	reflection.Register(grpcServer)
 
 	if err := grpcServer.Serve(conn); err != nil {
@@ -0,0 +1,269 @@
+package grpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/interact"
+)
+
+// StrategyInstanceService is the gRPC service of the strategy instance management, the same as the web api.
+// The messages are the protobuf well-known types, the instance states are the JSON objects of bbgo.StrategyInstanceState:
+//
+//	service StrategyInstanceService {
+//	  rpc ListStrategyInstances(google.protobuf.Empty) returns (google.protobuf.Struct) {}
+//	  rpc GetStrategyInstance(google.protobuf.StringValue) returns (google.protobuf.Struct) {}
+//	  rpc SuspendStrategyInstance(google.protobuf.StringValue) returns (google.protobuf.Empty) {}
+//	  rpc ResumeStrategyInstance(google.protobuf.StringValue) returns (google.protobuf.Empty) {}
+//	  rpc RequoteStrategyInstance(google.protobuf.StringValue) returns (google.protobuf.Empty) {}
+//	}
+//
+// The suspend, resume and requote calls require the operator role when the command authorization is enabled,
+// the user is authenticated by the api token in the "authorization: Bearer <token>" metadata.
+type StrategyInstanceService struct {
+	Environ *bbgo.Environment
+	Trader  *bbgo.Trader
+}
+
+func (s *StrategyInstanceService) ListStrategyInstances(ctx context.Context, _ *emptypb.Empty) (*structpb.Struct, error) {
+	instances, err := s.Trader.StrategyInstances()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	states := make([]*bbgo.StrategyInstanceState, 0, len(instances))
+	for _, instance := range instances {
+		state, err := instance.State()
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		// keep the list response small, the details are available in GetStrategyInstance
+		state.Config = nil
+		state.Trades = nil
+		states = append(states, state)
+	}
+
+	return toStruct(map[string]interface{}{"instances": states})
+}
+
+func (s *StrategyInstanceService) GetStrategyInstance(ctx context.Context, id *wrapperspb.StringValue) (*structpb.Struct, error) {
+	instance, err := s.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := instance.State()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return toStruct(state)
+}
+
+func (s *StrategyInstanceService) SuspendStrategyInstance(ctx context.Context, id *wrapperspb.StringValue) (*emptypb.Empty, error) {
+	return s.control(ctx, "SuspendStrategyInstance", id, func(instance *bbgo.StrategyInstance) error {
+		return instance.Suspend()
+	})
+}
+
+func (s *StrategyInstanceService) ResumeStrategyInstance(ctx context.Context, id *wrapperspb.StringValue) (*emptypb.Empty, error) {
+	return s.control(ctx, "ResumeStrategyInstance", id, func(instance *bbgo.StrategyInstance) error {
+		return instance.Resume()
+	})
+}
+
+func (s *StrategyInstanceService) RequoteStrategyInstance(ctx context.Context, id *wrapperspb.StringValue) (*emptypb.Empty, error) {
+	return s.control(ctx, "RequoteStrategyInstance", id, func(instance *bbgo.StrategyInstance) error {
+		return instance.Requote(ctx)
+	})
+}
+
+// control authorizes the operator role, runs the command on the instance and records it in the audit log
+func (s *StrategyInstanceService) control(ctx context.Context, command string, id *wrapperspb.StringValue, f func(instance *bbgo.StrategyInstance) error) (*emptypb.Empty, error) {
+	authorizer := s.Environ.CommandAuthorizer()
+	if authorizer == nil || !authorizer.Enabled() {
+		return s.run(id, f)
+	}
+
+	entry := interact.AuditEntry{
+		Time:    time.Now(),
+		Source:  "grpc",
+		Command: command,
+		Args:    []string{id.GetValue()},
+		Result:  interact.AuditResultOK,
+	}
+
+	user, ok := authorizer.Authenticate(bearerToken(ctx))
+	if !ok {
+		entry.Result = interact.AuditResultDenied
+		entry.Error = "invalid api token"
+		authorizer.LogCommand(entry)
+		return nil, status.Error(codes.Unauthenticated, "invalid api token")
+	}
+
+	entry.User = user
+	role, err := authorizer.Authorize(user, interact.RoleOperator)
+	entry.Role = role
+	if err != nil {
+		entry.Result = interact.AuditResultDenied
+		entry.Error = err.Error()
+		authorizer.LogCommand(entry)
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+
+	resp, err := s.run(id, f)
+	if err != nil {
+		entry.Result = interact.AuditResultError
+		entry.Error = err.Error()
+	}
+
+	authorizer.LogCommand(entry)
+	return resp, err
+}
+
+func (s *StrategyInstanceService) run(id *wrapperspb.StringValue, f func(instance *bbgo.StrategyInstance) error) (*emptypb.Empty, error) {
+	instance, err := s.lookup(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f(instance); err != nil {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *StrategyInstanceService) lookup(id *wrapperspb.StringValue) (*bbgo.StrategyInstance, error) {
+	instance, err := s.Trader.LookupStrategyInstance(id.GetValue())
+	if err != nil {
+		if errors.Is(err, bbgo.ErrStrategyInstanceNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return instance, nil
+}
+
+func bearerToken(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	for _, value := range md.Get("authorization") {
+		if len(value) > 7 && strings.EqualFold(value[:7], "Bearer ") {
+			return strings.TrimSpace(value[7:])
+		}
+	}
+
+	return ""
+}
+
+// toStruct converts the JSON object into the protobuf struct
+func toStruct(v interface{}) (*structpb.Struct, error) {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	var m map[string]interface{}
+	if err := json.Unmarshal(out, &m); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	st, err := structpb.NewStruct(m)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return st, nil
+}
+
+// StrategyInstanceServiceServer is the server interface of StrategyInstanceService
+type StrategyInstanceServiceServer interface {
+	ListStrategyInstances(context.Context, *emptypb.Empty) (*structpb.Struct, error)
+	GetStrategyInstance(context.Context, *wrapperspb.StringValue) (*structpb.Struct, error)
+	SuspendStrategyInstance(context.Context, *wrapperspb.StringValue) (*emptypb.Empty, error)
+	ResumeStrategyInstance(context.Context, *wrapperspb.StringValue) (*emptypb.Empty, error)
+	RequoteStrategyInstance(context.Context, *wrapperspb.StringValue) (*emptypb.Empty, error)
+}
+
+func RegisterStrategyInstanceServiceServer(s grpc.ServiceRegistrar, srv StrategyInstanceServiceServer) {
+	s.RegisterService(&StrategyInstanceService_ServiceDesc, srv)
+}
+
+func strategyInstanceHandler(method string, newRequest func() interface{}, call func(srv StrategyInstanceServiceServer, ctx context.Context, req interface{}) (interface{}, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: method,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := newRequest()
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+
+			if interceptor == nil {
+				return call(srv.(StrategyInstanceServiceServer), ctx, in)
+			}
+
+			info := &grpc.UnaryServerInfo{
+				Server:     srv,
+				FullMethod: "/bbgo.StrategyInstanceService/" + method,
+			}
+
+			return interceptor(ctx, in, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return call(srv.(StrategyInstanceServiceServer), ctx, req)
+			})
+		},
+	}
+}
+
+func newStringValue() interface{} {
+	return new(wrapperspb.StringValue)
+}
+
+// StrategyInstanceService_ServiceDesc is the grpc.ServiceDesc of StrategyInstanceService,
+// it's written by hand since the messages are the well-known types.
+var StrategyInstanceService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "bbgo.StrategyInstanceService",
+	HandlerType: (*StrategyInstanceServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		strategyInstanceHandler("ListStrategyInstances", func() interface{} { return new(emptypb.Empty) },
+			func(srv StrategyInstanceServiceServer, ctx context.Context, req interface{}) (interface{}, error) {
+				return srv.ListStrategyInstances(ctx, req.(*emptypb.Empty))
+			}),
+		strategyInstanceHandler("GetStrategyInstance", newStringValue,
+			func(srv StrategyInstanceServiceServer, ctx context.Context, req interface{}) (interface{}, error) {
+				return srv.GetStrategyInstance(ctx, req.(*wrapperspb.StringValue))
+			}),
+		strategyInstanceHandler("SuspendStrategyInstance", newStringValue,
+			func(srv StrategyInstanceServiceServer, ctx context.Context, req interface{}) (interface{}, error) {
+				return srv.SuspendStrategyInstance(ctx, req.(*wrapperspb.StringValue))
+			}),
+		strategyInstanceHandler("ResumeStrategyInstance", newStringValue,
+			func(srv StrategyInstanceServiceServer, ctx context.Context, req interface{}) (interface{}, error) {
+				return srv.ResumeStrategyInstance(ctx, req.(*wrapperspb.StringValue))
+			}),
+		strategyInstanceHandler("RequoteStrategyInstance", newStringValue,
+			func(srv StrategyInstanceServiceServer, ctx context.Context, req interface{}) (interface{}, error) {
+				return srv.RequoteStrategyInstance(ctx, req.(*wrapperspb.StringValue))
+			}),
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "strategy_instance.go",
+}
@@ -0,0 +1,49 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/interact"
+)
+
+func TestStrategyInstanceService(t *testing.T) {
+	environ := bbgo.NewEnvironment()
+	service := &StrategyInstanceService{
+		Environ: environ,
+		Trader:  bbgo.NewTrader(environ),
+	}
+
+	ctx := context.Background()
+	list, err := service.ListStrategyInstances(ctx, &emptypb.Empty{})
+	if assert.NoError(t, err) {
+		assert.Contains(t, list.AsMap(), "instances")
+	}
+
+	_, err = service.SuspendStrategyInstance(ctx, wrapperspb.String("binance.scmaker.BTCUSDT"))
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	environ.ConfigureCommandAuthorization(&bbgo.CommandAuthorizationConfig{
+		Users:     map[string]interact.Role{"alice": interact.RoleOperator},
+		APITokens: map[string]string{"alice": "alice-token", "bob": "bob-token"},
+	})
+
+	_, err = service.SuspendStrategyInstance(ctx, wrapperspb.String("binance.scmaker.BTCUSDT"))
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	bobCtx := metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer bob-token"))
+	_, err = service.SuspendStrategyInstance(bobCtx, wrapperspb.String("binance.scmaker.BTCUSDT"))
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	aliceCtx := metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "Bearer alice-token"))
+	_, err = service.SuspendStrategyInstance(aliceCtx, wrapperspb.String("binance.scmaker.BTCUSDT"))
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
@@ -0,0 +1,71 @@
+package indicator
+
+import "github.com/c9s/bbgo/pkg/types"
+
+const MaxNumOfTrades = 10_000
+
+// TradeStream pushes the public market trades of the symbol to the subscribers,
+// the side of the trade is the taker side.
+//
+//go:generate callbackgen -type TradeStream
+type TradeStream struct {
+	updateCallbacks []func(trade types.Trade)
+
+	trades []types.Trade
+}
+
+func (s *TradeStream) Length() int {
+	return len(s.trades)
+}
+
+func (s *TradeStream) Last(i int) *types.Trade {
+	l := len(s.trades)
+	if i < 0 || l-1-i < 0 {
+		return nil
+	}
+
+	return &s.trades[l-1-i]
+}
+
+// AddSubscriber adds the subscriber function and push historical data to the subscriber
+func (s *TradeStream) AddSubscriber(f func(trade types.Trade)) {
+	s.OnUpdate(f)
+
+	// push historical trades to the subscriber
+	for _, trade := range s.trades {
+		f(trade)
+	}
+}
+
+func (s *TradeStream) push(trade types.Trade) {
+	s.trades = append(s.trades, trade)
+	s.EmitUpdate(trade)
+
+	if len(s.trades) > MaxNumOfTrades {
+		s.trades = s.trades[len(s.trades)-1-MaxNumOfTrades:]
+	}
+}
+
+func tradeWith(symbol string, f func(trade types.Trade)) func(trade types.Trade) {
+	return func(trade types.Trade) {
+		if trade.Symbol != symbol {
+			return
+		}
+
+		f(trade)
+	}
+}
+
+// Trades creates a trade stream of the market trades, the source stream must subscribe the MarketTradeChannel
+func Trades(source types.Stream, symbol string) *TradeStream {
+	s := &TradeStream{}
+	source.OnMarketTrade(tradeWith(symbol, s.push))
+	return s
+}
+
+// AggTrades creates a trade stream of the aggregated trades, the source stream must subscribe the AggTradeChannel
+func AggTrades(source types.Stream, symbol string) *TradeStream {
+	s := &TradeStream{}
+	source.OnAggTrade(tradeWith(symbol, s.push))
+	return s
+}
@@ -0,0 +1,247 @@
+package indicator
+
+import (
+	"math"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// CandlePattern is the name of the candle pattern detected by the CandlePatternStream
+type CandlePattern string
+
+const (
+	CandlePatternDoji               CandlePattern = "doji"
+	CandlePatternBullishPinBar      CandlePattern = "bullishPinBar"
+	CandlePatternBearishPinBar      CandlePattern = "bearishPinBar"
+	CandlePatternBullishEngulfing   CandlePattern = "bullishEngulfing"
+	CandlePatternBearishEngulfing   CandlePattern = "bearishEngulfing"
+	CandlePatternThreeWhiteSoldiers CandlePattern = "threeWhiteSoldiers"
+	CandlePatternThreeBlackCrows    CandlePattern = "threeBlackCrows"
+)
+
+var candlePatterns = []CandlePattern{
+	CandlePatternDoji,
+	CandlePatternBullishPinBar,
+	CandlePatternBearishPinBar,
+	CandlePatternBullishEngulfing,
+	CandlePatternBearishEngulfing,
+	CandlePatternThreeWhiteSoldiers,
+	CandlePatternThreeBlackCrows,
+}
+
+func (p CandlePattern) IsValid() bool {
+	for _, pattern := range candlePatterns {
+		if p == pattern {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Direction returns the direction implied by the pattern, doji is neutral
+func (p CandlePattern) Direction() types.Direction {
+	switch p {
+	case CandlePatternBullishPinBar, CandlePatternBullishEngulfing, CandlePatternThreeWhiteSoldiers:
+		return types.DirectionUp
+	case CandlePatternBearishPinBar, CandlePatternBearishEngulfing, CandlePatternThreeBlackCrows:
+		return types.DirectionDown
+	}
+
+	return types.DirectionNone
+}
+
+// CandlePatternEvent is emitted when the pattern is detected on the closed kline
+type CandlePatternEvent struct {
+	Pattern   CandlePattern
+	Direction types.Direction
+	KLine     types.KLine
+}
+
+// CandlePatternStream detects the candle patterns on the closed klines and emits the pattern events.
+//
+// The series is the net direction of the patterns detected on each kline,
+// positive for the bullish patterns, negative for the bearish patterns and zero for none,
+// so that it can be used as a signal source.
+//
+//go:generate callbackgen -type CandlePatternStream
+type CandlePatternStream struct {
+	*Float64Series
+
+	// DojiBodyRatio is the max ratio of the body to the range of a doji, defaults to 0.1
+	DojiBodyRatio float64
+
+	// PinBarShadowRatio is the min ratio of the long shadow to the range of a pin bar, defaults to 2/3
+	PinBarShadowRatio float64
+
+	kLines   []types.KLine
+	detected map[CandlePattern]*Float64Series
+
+	patternCallbacks []func(e CandlePatternEvent)
+}
+
+// CandlePatterns creates the CandlePatternStream from the kline source
+func CandlePatterns(source KLineSubscription) *CandlePatternStream {
+	s := &CandlePatternStream{
+		Float64Series:     NewFloat64Series(),
+		DojiBodyRatio:     0.1,
+		PinBarShadowRatio: 2.0 / 3.0,
+		detected:          make(map[CandlePattern]*Float64Series),
+	}
+
+	source.AddSubscriber(func(k types.KLine) {
+		s.kLines = append(s.kLines, k)
+		if len(s.kLines) > 3 {
+			s.kLines = s.kLines[len(s.kLines)-3:]
+		}
+
+		s.calculateAndPush(k)
+	})
+	return s
+}
+
+// Detected returns the series of the pattern, the value is 1 when the pattern is detected on the kline, otherwise 0
+func (s *CandlePatternStream) Detected(pattern CandlePattern) *Float64Series {
+	if series, ok := s.detected[pattern]; ok {
+		return series
+	}
+
+	series := NewFloat64Series()
+	s.detected[pattern] = series
+	return series
+}
+
+func (s *CandlePatternStream) calculateAndPush(k types.KLine) {
+	patterns := s.Detect()
+
+	for pattern, series := range s.detected {
+		v := 0.0
+		for _, p := range patterns {
+			if p == pattern {
+				v = 1.0
+				break
+			}
+		}
+
+		series.PushAndEmit(v)
+	}
+
+	net := 0.0
+	for _, p := range patterns {
+		net += float64(p.Direction())
+		s.EmitPattern(CandlePatternEvent{Pattern: p, Direction: p.Direction(), KLine: k})
+	}
+
+	s.PushAndEmit(net)
+}
+
+// Detect returns the patterns detected on the latest kline
+func (s *CandlePatternStream) Detect() (patterns []CandlePattern) {
+	n := len(s.kLines)
+	if n == 0 {
+		return nil
+	}
+
+	last := newCandle(s.kLines[n-1])
+	if last.isDoji(s.DojiBodyRatio) {
+		patterns = append(patterns, CandlePatternDoji)
+	}
+
+	if last.rng > 0 {
+		if last.lowerShadow >= s.PinBarShadowRatio*last.rng {
+			patterns = append(patterns, CandlePatternBullishPinBar)
+		} else if last.upperShadow >= s.PinBarShadowRatio*last.rng {
+			patterns = append(patterns, CandlePatternBearishPinBar)
+		}
+	}
+
+	if n >= 2 {
+		prev := newCandle(s.kLines[n-2])
+		if prev.bearish() && last.bullish() && last.open <= prev.close && last.close >= prev.open && last.body > prev.body {
+			patterns = append(patterns, CandlePatternBullishEngulfing)
+		} else if prev.bullish() && last.bearish() && last.open >= prev.close && last.close <= prev.open && last.body > prev.body {
+			patterns = append(patterns, CandlePatternBearishEngulfing)
+		}
+	}
+
+	if n >= 3 {
+		candles := []candle{newCandle(s.kLines[n-3]), newCandle(s.kLines[n-2]), last}
+		if s.isThreeSoldiers(candles, true) {
+			patterns = append(patterns, CandlePatternThreeWhiteSoldiers)
+		} else if s.isThreeSoldiers(candles, false) {
+			patterns = append(patterns, CandlePatternThreeBlackCrows)
+		}
+	}
+
+	return patterns
+}
+
+// isThreeSoldiers checks the three consecutive candles of the same direction,
+// each candle opens within the body of the previous candle and closes beyond the previous close.
+func (s *CandlePatternStream) isThreeSoldiers(candles []candle, up bool) bool {
+	for i, c := range candles {
+		if c.isDoji(s.DojiBodyRatio) {
+			return false
+		}
+
+		if up && !c.bullish() || !up && !c.bearish() {
+			return false
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		prev := candles[i-1]
+		if up {
+			if c.open < prev.open || c.open > prev.close || c.close <= prev.close {
+				return false
+			}
+		} else if c.open > prev.open || c.open < prev.close || c.close >= prev.close {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (s *CandlePatternStream) Truncate() {
+	s.slice = s.slice.Truncate(MaxNumOfEWMA)
+	for _, series := range s.detected {
+		series.slice = series.slice.Truncate(MaxNumOfEWMA)
+	}
+}
+
+type candle struct {
+	open, high, low, close float64
+
+	body, rng                float64
+	upperShadow, lowerShadow float64
+}
+
+func newCandle(k types.KLine) candle {
+	c := candle{
+		open:  k.Open.Float64(),
+		high:  k.High.Float64(),
+		low:   k.Low.Float64(),
+		close: k.Close.Float64(),
+	}
+
+	c.body = math.Abs(c.close - c.open)
+	c.rng = c.high - c.low
+	c.upperShadow = c.high - math.Max(c.open, c.close)
+	c.lowerShadow = math.Min(c.open, c.close) - c.low
+	return c
+}
+
+func (c candle) bullish() bool {
+	return c.close > c.open
+}
+
+func (c candle) bearish() bool {
+	return c.close < c.open
+}
+
+func (c candle) isDoji(bodyRatio float64) bool {
+	return c.rng > 0 && c.body <= bodyRatio*c.rng
+}
@@ -0,0 +1,120 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newPatternKLine(open, high, low, close float64) types.KLine {
+	return types.KLine{
+		Open:  fixedpoint.NewFromFloat(open),
+		High:  fixedpoint.NewFromFloat(high),
+		Low:   fixedpoint.NewFromFloat(low),
+		Close: fixedpoint.NewFromFloat(close),
+	}
+}
+
+func TestCandlePatternStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		kLines []types.KLine
+		want   []CandlePattern
+		net    float64
+	}{
+		{
+			name:   "doji",
+			kLines: []types.KLine{newPatternKLine(100, 105, 95, 100.5)},
+			want:   []CandlePattern{CandlePatternDoji},
+		},
+		{
+			name:   "bullish pin bar",
+			kLines: []types.KLine{newPatternKLine(108, 110, 90, 109)},
+			want:   []CandlePattern{CandlePatternDoji, CandlePatternBullishPinBar},
+			net:    1,
+		},
+		{
+			name:   "bearish pin bar",
+			kLines: []types.KLine{newPatternKLine(94, 110, 90, 91)},
+			want:   []CandlePattern{CandlePatternBearishPinBar},
+			net:    -1,
+		},
+		{
+			name: "bullish engulfing",
+			kLines: []types.KLine{
+				newPatternKLine(102, 103, 99, 100),
+				newPatternKLine(99.5, 104, 99, 103),
+			},
+			want: []CandlePattern{CandlePatternBullishEngulfing},
+			net:  1,
+		},
+		{
+			name: "bearish engulfing",
+			kLines: []types.KLine{
+				newPatternKLine(100, 103, 99, 102),
+				newPatternKLine(102.5, 103, 98, 99),
+			},
+			want: []CandlePattern{CandlePatternBearishEngulfing},
+			net:  -1,
+		},
+		{
+			name: "three white soldiers",
+			kLines: []types.KLine{
+				newPatternKLine(100, 103, 99.5, 102.5),
+				newPatternKLine(101.5, 105, 101, 104.5),
+				newPatternKLine(103.5, 107, 103, 106.5),
+			},
+			want: []CandlePattern{CandlePatternThreeWhiteSoldiers},
+			net:  1,
+		},
+		{
+			name: "three black crows",
+			kLines: []types.KLine{
+				newPatternKLine(106.5, 107, 103, 103.5),
+				newPatternKLine(104.5, 105, 101, 101.5),
+				newPatternKLine(102.5, 103, 99.5, 100),
+			},
+			want: []CandlePattern{CandlePatternThreeBlackCrows},
+			net:  -1,
+		},
+		{
+			name: "no pattern",
+			kLines: []types.KLine{
+				newPatternKLine(100, 103, 99, 102),
+				newPatternKLine(102, 104, 101, 103),
+			},
+			want: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := &types.StandardStream{}
+			patterns := CandlePatterns(KLines(stream, "", ""))
+			engulfing := patterns.Detected(CandlePatternBullishEngulfing)
+
+			var events []CandlePattern
+			patterns.OnPattern(func(e CandlePatternEvent) {
+				assert.Equal(t, e.Pattern.Direction(), e.Direction)
+				events = append(events, e.Pattern)
+			})
+
+			for _, k := range tt.kLines {
+				events = nil
+				stream.EmitKLineClosed(k)
+			}
+
+			assert.Equal(t, tt.want, events)
+			assert.Equal(t, tt.net, patterns.Last(0))
+			assert.Equal(t, len(tt.kLines), engulfing.Length())
+
+			if tt.name == "bullish engulfing" {
+				assert.Equal(t, 1.0, engulfing.Last(0))
+				assert.Equal(t, 0.0, engulfing.Last(1))
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package indicator
+
+import (
+	"math"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// pairReturns pairs the updates of the 2 price sources by their sequence and keeps the rolling log returns of them,
+// the calculate function is called when a new pair of the returns is collected.
+type pairReturns struct {
+	pendingA, pendingB []float64
+	lastA, lastB       float64
+
+	returnsA, returnsB *types.Queue
+}
+
+func newPairReturns(a, b Float64Source, window int, calculate func()) *pairReturns {
+	p := &pairReturns{
+		returnsA: types.NewQueue(window),
+		returnsB: types.NewQueue(window),
+	}
+
+	a.OnUpdate(func(v float64) {
+		p.pendingA = append(p.pendingA, v)
+		p.update(calculate)
+	})
+	b.OnUpdate(func(v float64) {
+		p.pendingB = append(p.pendingB, v)
+		p.update(calculate)
+	})
+	return p
+}
+
+func (p *pairReturns) update(calculate func()) {
+	for len(p.pendingA) > 0 && len(p.pendingB) > 0 {
+		a, b := p.pendingA[0], p.pendingB[0]
+		p.pendingA, p.pendingB = p.pendingA[1:], p.pendingB[1:]
+
+		// the prices must be positive to have the log returns
+		if a <= 0 || b <= 0 {
+			continue
+		}
+
+		if p.lastA > 0 && p.lastB > 0 {
+			p.returnsA.Update(math.Log(a / p.lastA))
+			p.returnsB.Update(math.Log(b / p.lastB))
+		}
+
+		p.lastA, p.lastB = a, b
+		calculate()
+	}
+}
+
+// moments returns the covariance of the returns and the variances of the returns of a and b
+func (p *pairReturns) moments() (cov, varA, varB float64, ok bool) {
+	n := p.returnsA.Length()
+	if n < 2 {
+		return 0, 0, 0, false
+	}
+
+	meanA, meanB := p.returnsA.Mean(n), p.returnsB.Mean(n)
+	for i := 0; i < n; i++ {
+		da := p.returnsA.Last(i) - meanA
+		db := p.returnsB.Last(i) - meanB
+		cov += da * db
+		varA += da * da
+		varB += db * db
+	}
+
+	return cov / float64(n), varA / float64(n), varB / float64(n), true
+}
+
+// CorrelationStream is the rolling Pearson correlation of the log returns of 2 price sources,
+// the updates of the sources are paired by their sequence, so the sources should be updated by the same kline intervals.
+type CorrelationStream struct {
+	*Float64Series
+
+	returns *pairReturns
+}
+
+// Correlation creates the CorrelationStream of the returns of the last window updates of a and b
+// correlation := Correlation(ClosePrices(btcKLines), ClosePrices(ethKLines), 30)
+func Correlation(a, b Float64Source, window int) *CorrelationStream {
+	s := &CorrelationStream{
+		Float64Series: NewFloat64Series(),
+	}
+	s.returns = newPairReturns(a, b, window, s.calculate)
+	return s
+}
+
+func (s *CorrelationStream) calculate() {
+	var corr float64
+	if cov, varA, varB, ok := s.returns.moments(); ok && varA > 0 && varB > 0 {
+		corr = cov / math.Sqrt(varA*varB)
+	}
+
+	s.PushAndEmit(corr)
+	s.Truncate()
+}
+
+func (s *CorrelationStream) Truncate() {
+	s.slice = s.slice.Truncate(MaxNumOfEWMA)
+}
+
+// BetaStream is the rolling beta of the log returns of the source a against the log returns of the benchmark b,
+// beta = cov(a, b) / var(b), which is the hedge ratio of a in the units of b.
+type BetaStream struct {
+	*Float64Series
+
+	returns *pairReturns
+}
+
+// Beta creates the BetaStream of the returns of the last window updates of a and the benchmark b
+func Beta(a, b Float64Source, window int) *BetaStream {
+	s := &BetaStream{
+		Float64Series: NewFloat64Series(),
+	}
+	s.returns = newPairReturns(a, b, window, s.calculate)
+	return s
+}
+
+func (s *BetaStream) calculate() {
+	var beta float64
+	if cov, _, varB, ok := s.returns.moments(); ok && varB > 0 {
+		beta = cov / varB
+	}
+
+	s.PushAndEmit(beta)
+	s.Truncate()
+}
+
+func (s *BetaStream) Truncate() {
+	s.slice = s.slice.Truncate(MaxNumOfEWMA)
+}
@@ -0,0 +1,67 @@
+package indicator
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCorrelationStream(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	a := NewFloat64Series()
+	b := NewFloat64Series()
+	inverse := NewFloat64Series()
+	noise := NewFloat64Series()
+
+	correlation := Correlation(a, b, 50)
+	inverseCorrelation := Correlation(a, inverse, 50)
+	noiseCorrelation := Correlation(a, noise, 50)
+	beta := Beta(a, b, 50)
+
+	priceA, priceB, priceInverse, priceNoise := 100.0, 100.0, 100.0, 100.0
+	for i := 0; i < 200; i++ {
+		r := 0.01 * rnd.NormFloat64()
+
+		// a moves twice as much as b
+		priceA *= math.Exp(2 * r)
+		priceB *= math.Exp(r)
+		priceInverse *= math.Exp(-r)
+		priceNoise *= math.Exp(0.01 * rnd.NormFloat64())
+
+		a.PushAndEmit(priceA)
+		b.PushAndEmit(priceB)
+		inverse.PushAndEmit(priceInverse)
+		noise.PushAndEmit(priceNoise)
+	}
+
+	assert.Equal(t, 200, correlation.Length())
+	assert.InDelta(t, 1.0, correlation.Last(0), 1e-9)
+	assert.InDelta(t, -1.0, inverseCorrelation.Last(0), 1e-9)
+	assert.InDelta(t, 0.0, noiseCorrelation.Last(0), 0.4)
+	assert.InDelta(t, 2.0, beta.Last(0), 1e-9)
+}
+
+func TestCorrelationStream_PairedUpdates(t *testing.T) {
+	a := NewFloat64Series()
+	b := NewFloat64Series()
+	correlation := Correlation(a, b, 10)
+
+	// the values are paired by the update sequence
+	a.PushAndEmit(100)
+	a.PushAndEmit(101)
+	assert.Equal(t, 0, correlation.Length())
+
+	b.PushAndEmit(200)
+	b.PushAndEmit(202)
+	assert.Equal(t, 2, correlation.Length())
+
+	// not enough returns
+	assert.Equal(t, 0.0, correlation.Last(0))
+
+	a.PushAndEmit(100)
+	b.PushAndEmit(200)
+	assert.InDelta(t, 1.0, correlation.Last(0), 1e-9)
+}
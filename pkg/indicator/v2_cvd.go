@@ -0,0 +1,129 @@
+package indicator
+
+import (
+	"math"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type DivergenceType float64
+
+const (
+	NoDivergence DivergenceType = 0.0
+
+	// BullishDivergence is the price making a new low while the flow does not, the selling is absorbed
+	BullishDivergence DivergenceType = 1.0
+
+	// BearishDivergence is the price making a new high while the flow does not, the buying is exhausted
+	BearishDivergence DivergenceType = -1.0
+)
+
+// Divergence compares the last value of the price and the flow (e.g., CVD) with the previous window values,
+// the price breaking the low (high) of the window while the flow stays above its low (below its high) is a divergence.
+// NoDivergence is returned if there are not enough values.
+func Divergence(price, flow types.Series, window int) DivergenceType {
+	if window < 1 || price.Length() < window+1 || flow.Length() < window+1 {
+		return NoDivergence
+	}
+
+	priceLow, priceHigh := price.Last(1), price.Last(1)
+	flowLow, flowHigh := flow.Last(1), flow.Last(1)
+	for i := 2; i <= window; i++ {
+		priceLow = math.Min(priceLow, price.Last(i))
+		priceHigh = math.Max(priceHigh, price.Last(i))
+		flowLow = math.Min(flowLow, flow.Last(i))
+		flowHigh = math.Max(flowHigh, flow.Last(i))
+	}
+
+	p, f := price.Last(0), flow.Last(0)
+	switch {
+	case p < priceLow && f > flowLow:
+		return BullishDivergence
+	case p > priceHigh && f < flowHigh:
+		return BearishDivergence
+	}
+
+	return NoDivergence
+}
+
+// CVDStream is the cumulative volume delta of the trades bucketed by the interval.
+// The series is the CVD at the end of each interval, Delta is the volume delta of each interval,
+// and Prices is the last trade price of each interval, so the flow can be compared with the price.
+//
+// A bucket is closed by the first trade of a later interval, the intervals without trades are skipped.
+// Call Flush on the interval boundary (e.g., on the kline closed event of the same interval)
+// to close the bucket without waiting for the next trade.
+type CVDStream struct {
+	*Float64Series
+
+	Delta  *Float64Series
+	Prices *Float64Series
+
+	interval types.Interval
+
+	// startTime is the start time of the current bucket, it's zero if there is no trade in the bucket
+	startTime time.Time
+	delta     float64
+	price     float64
+	cvd       float64
+}
+
+func CVD(source TradeSubscription, interval types.Interval) *CVDStream {
+	s := &CVDStream{
+		Float64Series: NewFloat64Series(),
+		Delta:         NewFloat64Series(),
+		Prices:        NewFloat64Series(),
+		interval:      interval,
+	}
+
+	source.AddSubscriber(s.add)
+	return s
+}
+
+func (s *CVDStream) add(trade types.Trade) {
+	startTime := s.interval.Truncate(trade.Time.Time())
+	if !s.startTime.IsZero() && startTime.After(s.startTime) {
+		s.closeBucket()
+	}
+
+	if s.startTime.IsZero() {
+		s.startTime = startTime
+	}
+
+	s.delta += signedTradeVolume(trade)
+	s.price = trade.Price.Float64()
+}
+
+// Flush closes the current bucket if its interval ends before now
+func (s *CVDStream) Flush(now time.Time) {
+	if s.startTime.IsZero() || s.interval.Advance(s.startTime, 1).After(now) {
+		return
+	}
+
+	s.closeBucket()
+}
+
+func (s *CVDStream) closeBucket() {
+	s.cvd += s.delta
+
+	// push the delta and the price before the CVD, so the subscribers of the CVD read the same bucket
+	s.Delta.PushAndEmit(s.delta)
+	s.Prices.PushAndEmit(s.price)
+	s.PushAndEmit(s.cvd)
+	s.Truncate()
+
+	s.startTime = time.Time{}
+	s.delta = 0
+}
+
+// Divergence detects the divergence of the CVD and the price of the closed buckets
+func (s *CVDStream) Divergence(window int) DivergenceType {
+	return Divergence(s.Prices, s, window)
+}
+
+func (s *CVDStream) Truncate() {
+	s.slice = s.slice.Truncate(MaxNumOfTrades)
+	s.Delta.slice = s.Delta.slice.Truncate(MaxNumOfTrades)
+	s.Prices.slice = s.Prices.slice.Truncate(MaxNumOfTrades)
+}
@@ -0,0 +1,97 @@
+package indicator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_CVD(t *testing.T) {
+	stream := &types.StandardStream{}
+	cvd := CVD(Trades(stream, "BTCUSDT"), types.Interval1m)
+
+	baseTime := time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC)
+	emit := func(offset time.Duration, side types.SideType, price, quantity string) {
+		stream.EmitMarketTrade(types.Trade{
+			Symbol:   "BTCUSDT",
+			Side:     side,
+			Price:    fixedpoint.MustNewFromString(price),
+			Quantity: fixedpoint.MustNewFromString(quantity),
+			Time:     types.Time(baseTime.Add(offset)),
+		})
+	}
+
+	// the price goes down with the selling flow
+	emit(10*time.Second, types.SideTypeBuy, "100", "2")
+	emit(20*time.Second, types.SideTypeSell, "99", "1")
+	emit(70*time.Second, types.SideTypeSell, "98", "3")
+	assert.Equal(t, 1, cvd.Length(), "the first bucket is closed by the trade of the next minute")
+	assert.InDelta(t, 1.0, cvd.Last(0), 1e-9)
+
+	emit(130*time.Second, types.SideTypeSell, "97", "1")
+	assert.InDeltaSlice(t, []float64{1, -2}, []float64(cvd.Slice()), 1e-9)
+	assert.InDeltaSlice(t, []float64{1, -3}, []float64(cvd.Delta.Slice()), 1e-9)
+	assert.InDeltaSlice(t, []float64{99, 98}, []float64(cvd.Prices.Slice()), 1e-9)
+	assert.Equal(t, NoDivergence, cvd.Divergence(1))
+
+	// the price makes a new low while the buyers absorb the selling
+	emit(131*time.Second, types.SideTypeBuy, "96", "4")
+	cvd.Flush(baseTime.Add(3 * time.Minute))
+	assert.InDeltaSlice(t, []float64{1, -2, 1}, []float64(cvd.Slice()), 1e-9)
+	assert.Equal(t, BullishDivergence, cvd.Divergence(2))
+
+	// the bucket is not closed before the interval ends
+	emit(190*time.Second, types.SideTypeBuy, "101", "1")
+	cvd.Flush(baseTime.Add(200 * time.Second))
+	assert.Equal(t, 3, cvd.Length())
+}
+
+func Test_Divergence(t *testing.T) {
+	tests := []struct {
+		name   string
+		price  []float64
+		flow   []float64
+		window int
+		want   DivergenceType
+	}{
+		{
+			name:   "bearish",
+			price:  []float64{10, 12, 11, 13},
+			flow:   []float64{5, 8, 6, 7},
+			window: 3,
+			want:   BearishDivergence,
+		},
+		{
+			name:   "confirmed high",
+			price:  []float64{10, 12, 11, 13},
+			flow:   []float64{5, 8, 6, 9},
+			window: 3,
+			want:   NoDivergence,
+		},
+		{
+			name:   "bullish",
+			price:  []float64{10, 8, 9, 7},
+			flow:   []float64{5, 2, 4, 3},
+			window: 3,
+			want:   BullishDivergence,
+		},
+		{
+			name:   "not enough values",
+			price:  []float64{10, 8, 9, 7},
+			flow:   []float64{5, 2, 4, 3},
+			window: 4,
+			want:   NoDivergence,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Divergence(NewFloat64Series(tt.price...), NewFloat64Series(tt.flow...), tt.window)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package indicator
+
+import (
+	"math"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// DMIStream is the directional movement index, the stream values are the ADX (average directional index),
+// and the +DI and the -DI are in the DIPlus and the DIMinus series.
+//
+// The ADX measures the trend strength regardless of the direction, it's usually considered as a strong trend when it's above 20 or 25,
+// the direction is given by the comparison of the +DI and the -DI.
+//
+// The values are only emitted after the window of the directional movements is filled,
+// and the ADX is zero before the window of the ADX smoothing is filled, which is the same as the legacy DMI indicator.
+type DMIStream struct {
+	// the ADX series
+	*Float64Series
+
+	DIPlus, DIMinus *Float64Series
+
+	window, adxSmoothing int
+
+	// the raw series of the true range, the directional movements and the directional index,
+	// they're smoothed by the RMA streams
+	trueRange, dmPlus, dmMinus, dx *Float64Series
+	atr, dmpRMA, dmnRMA, adx       *RMAStream
+
+	prevHigh, prevLow, prevClose float64
+	numOfMoves, numOfDx          int
+}
+
+// DMI2 creates the DMIStream, the common settings are window 14 and adxSmoothing 14
+func DMI2(source KLineSubscription, window, adxSmoothing int) *DMIStream {
+	s := &DMIStream{
+		Float64Series: NewFloat64Series(),
+		DIPlus:        NewFloat64Series(),
+		DIMinus:       NewFloat64Series(),
+		window:        window,
+		adxSmoothing:  adxSmoothing,
+		trueRange:     NewFloat64Series(),
+		dmPlus:        NewFloat64Series(),
+		dmMinus:       NewFloat64Series(),
+		dx:            NewFloat64Series(),
+	}
+
+	s.atr = RMA2(s.trueRange, window, true)
+	s.dmpRMA = RMA2(s.dmPlus, window, true)
+	s.dmnRMA = RMA2(s.dmMinus, window, true)
+	s.adx = RMA2(s.dx, adxSmoothing, true)
+
+	source.AddSubscriber(func(k types.KLine) {
+		s.calculateAndPush(k.High.Float64(), k.Low.Float64(), k.Close.Float64())
+	})
+	return s
+}
+
+func (s *DMIStream) calculateAndPush(high, low, cls float64) {
+	if s.prevClose == 0 {
+		s.prevHigh, s.prevLow, s.prevClose = high, low, cls
+		return
+	}
+
+	trueRange := math.Max(high-low, math.Max(math.Abs(high-s.prevClose), math.Abs(low-s.prevClose)))
+
+	up := high - s.prevHigh
+	dn := s.prevLow - low
+	s.prevHigh, s.prevLow, s.prevClose = high, low, cls
+
+	pos, neg := 0.0, 0.0
+	if up > dn && up > 0 {
+		pos = up
+	}
+
+	if dn > up && dn > 0 {
+		neg = dn
+	}
+
+	s.trueRange.PushAndEmit(trueRange)
+	s.dmPlus.PushAndEmit(pos)
+	s.dmMinus.PushAndEmit(neg)
+	s.trueRange.slice = s.trueRange.slice.Truncate(MaxNumOfRMA)
+	s.dmPlus.slice = s.dmPlus.slice.Truncate(MaxNumOfRMA)
+	s.dmMinus.slice = s.dmMinus.slice.Truncate(MaxNumOfRMA)
+
+	s.numOfMoves++
+	if s.numOfMoves < s.window {
+		return
+	}
+
+	atr := s.atr.Last(0)
+	dmp := s.dmpRMA.Last(0)
+	dmn := s.dmnRMA.Last(0)
+
+	var diPlus, diMinus, dx float64
+	if atr > 0 {
+		diPlus = 100.0 * dmp / atr
+		diMinus = 100.0 * dmn / atr
+	}
+
+	if dmp+dmn > 0 {
+		dx = 100.0 * math.Abs(dmp-dmn) / (dmp + dmn)
+	}
+
+	s.dx.PushAndEmit(dx)
+	s.dx.slice = s.dx.slice.Truncate(MaxNumOfRMA)
+	s.numOfDx++
+
+	adx := 0.0
+	if s.numOfDx >= s.adxSmoothing {
+		adx = s.adx.Last(0)
+	}
+
+	s.DIPlus.PushAndEmit(diPlus)
+	s.DIMinus.PushAndEmit(diMinus)
+	s.PushAndEmit(adx)
+	s.Truncate()
+}
+
+// ADX returns the latest ADX value
+func (s *DMIStream) ADX() float64 {
+	return s.Last(0)
+}
+
+func (s *DMIStream) Truncate() {
+	s.slice = s.slice.Truncate(MaxNumOfEWMA)
+	s.DIPlus.slice = s.DIPlus.slice.Truncate(MaxNumOfEWMA)
+	s.DIMinus.slice = s.DIMinus.slice.Truncate(MaxNumOfEWMA)
+}
@@ -0,0 +1,40 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestDMIStream(t *testing.T) {
+	highs := []float64{100, 101, 102, 103, 104, 105, 106, 107, 108, 109, 100, 101, 102, 103, 104, 105, 106, 107, 108, 109, 100, 101, 102, 103, 104, 105, 106, 107, 108, 109}
+	lows := []float64{80, 81, 82, 83, 84, 85, 86, 87, 88, 89, 80, 81, 82, 83, 84, 85, 86, 87, 88, 89, 80, 81, 82, 83, 84, 85, 86, 87, 88, 89}
+	closes := []float64{90, 91, 92, 93, 94, 95, 96, 97, 98, 99, 90, 91, 92, 93, 94, 95, 96, 97, 98, 99, 90, 91, 92, 93, 94, 95, 96, 97, 98, 99}
+
+	stream := &types.StandardStream{}
+	kLines := KLines(stream, "", "")
+	dmi := DMI2(kLines, 5, 14)
+
+	for i := range highs {
+		stream.EmitKLineClosed(types.KLine{
+			High:  fixedpoint.NewFromFloat(highs[i]),
+			Low:   fixedpoint.NewFromFloat(lows[i]),
+			Close: fixedpoint.NewFromFloat(closes[i]),
+		})
+	}
+
+	// the same values as the legacy DMI indicator
+	assert.InDelta(t, 4.85114, dmi.DIPlus.Last(0), 0.001)
+	assert.InDelta(t, 1.339736, dmi.DIMinus.Last(0), 0.001)
+	assert.InDelta(t, 37.857156, dmi.ADX(), 0.001)
+
+	// the values are emitted after the window of the directional movements is filled
+	assert.Equal(t, len(highs)-5, dmi.Length())
+	assert.Equal(t, dmi.Length(), dmi.DIPlus.Length())
+
+	// the ADX is zero before the window of the ADX smoothing is filled
+	assert.Equal(t, 0.0, dmi.Last(dmi.Length()-1))
+}
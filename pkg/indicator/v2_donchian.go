@@ -0,0 +1,67 @@
+package indicator
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// DonchianStream is the Donchian channel, the up band is the highest high and the down band is the lowest low
+// of the last window klines including the current kline, the stream values are the middle of the bands.
+//
+// For the breakout signals, compare the close price with the bands of the previous kline, e.g., UpBand.Last(1).
+type DonchianStream struct {
+	// the middle band series
+	*Float64Series
+
+	UpBand, DownBand *Float64Series
+
+	window      int
+	highs, lows *types.Queue
+}
+
+func Donchian(source KLineSubscription, window int) *DonchianStream {
+	s := &DonchianStream{
+		Float64Series: NewFloat64Series(),
+		UpBand:        NewFloat64Series(),
+		DownBand:      NewFloat64Series(),
+		window:        window,
+		highs:         types.NewQueue(window),
+		lows:          types.NewQueue(window),
+	}
+
+	source.AddSubscriber(func(k types.KLine) {
+		s.calculateAndPush(k.High.Float64(), k.Low.Float64())
+	})
+	return s
+}
+
+func (s *DonchianStream) calculateAndPush(high, low float64) {
+	s.highs.Update(high)
+	s.lows.Update(low)
+
+	upper := s.highs.Highest(s.window)
+	lower := s.lows.Lowest(s.window)
+
+	s.UpBand.PushAndEmit(upper)
+	s.DownBand.PushAndEmit(lower)
+	s.PushAndEmit((upper + lower) / 2.0)
+	s.Truncate()
+}
+
+// Upper returns the latest up band value
+func (s *DonchianStream) Upper() float64 {
+	return s.UpBand.Last(0)
+}
+
+// Lower returns the latest down band value
+func (s *DonchianStream) Lower() float64 {
+	return s.DownBand.Last(0)
+}
+
+// Mid returns the latest middle band value
+func (s *DonchianStream) Mid() float64 {
+	return s.Last(0)
+}
+
+func (s *DonchianStream) Truncate() {
+	s.slice = s.slice.Truncate(MaxNumOfEWMA)
+	s.UpBand.slice = s.UpBand.slice.Truncate(MaxNumOfEWMA)
+	s.DownBand.slice = s.DownBand.slice.Truncate(MaxNumOfEWMA)
+}
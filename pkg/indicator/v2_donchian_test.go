@@ -0,0 +1,38 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestDonchianStream(t *testing.T) {
+	stream := &types.StandardStream{}
+	kLines := KLines(stream, "", "")
+	donchian := Donchian(kLines, 3)
+
+	for _, hl := range [][2]float64{{10, 8}, {12, 9}, {11, 7}, {9, 8}} {
+		stream.EmitKLineClosed(types.KLine{
+			High: fixedpoint.NewFromFloat(hl[0]),
+			Low:  fixedpoint.NewFromFloat(hl[1]),
+		})
+	}
+
+	assert.Equal(t, 4, donchian.Length())
+
+	// the last 3 klines: (12, 9), (11, 7), (9, 8)
+	assert.InDelta(t, 12.0, donchian.Upper(), 1e-9)
+	assert.InDelta(t, 7.0, donchian.Lower(), 1e-9)
+	assert.InDelta(t, 9.5, donchian.Mid(), 1e-9)
+
+	// the first 3 klines: (10, 8), (12, 9), (11, 7)
+	assert.InDelta(t, 12.0, donchian.UpBand.Last(1), 1e-9)
+	assert.InDelta(t, 7.0, donchian.DownBand.Last(1), 1e-9)
+
+	// the window is not filled yet
+	assert.InDelta(t, 10.0, donchian.UpBand.Last(3), 1e-9)
+	assert.InDelta(t, 8.0, donchian.DownBand.Last(3), 1e-9)
+}
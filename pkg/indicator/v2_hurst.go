@@ -0,0 +1,102 @@
+package indicator
+
+import (
+	"math"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// HurstStream is the rolling Hurst exponent of the source,
+// H > 0.5 means the series is trending (persistent), H < 0.5 means the series is mean-reverting (anti-persistent),
+// and H ~= 0.5 means the series is a random walk.
+//
+// The exponent is estimated from the scaling of the root mean square of the lagged differences of the log values:
+// rms(x[t+lag] - x[t]) ~ lag^H, the differences are not demeaned so that the drift counts as the persistence.
+type HurstStream struct {
+	*Float64Series
+
+	window, maxLag int
+	rawValues      *types.Queue
+}
+
+// Hurst creates the HurstStream, maxLag should be less than the window, and defaults to window / 4 when it's zero
+func Hurst(source Float64Source, window, maxLag int) *HurstStream {
+	if maxLag <= 0 {
+		maxLag = window / 4
+	}
+
+	if maxLag < 2 {
+		maxLag = 2
+	}
+
+	s := &HurstStream{
+		Float64Series: NewFloat64Series(),
+		window:        window,
+		maxLag:        maxLag,
+		rawValues:     types.NewQueue(window),
+	}
+	s.Bind(source, s)
+	return s
+}
+
+func (s *HurstStream) Calculate(v float64) float64 {
+	if v <= 0 {
+		return s.slice.Last(0)
+	}
+
+	s.rawValues.Update(math.Log(v))
+	if s.rawValues.Length() < s.window {
+		// not enough values, assume it's a random walk
+		return 0.5
+	}
+
+	return hurstExponent(queueValues(s.rawValues), s.maxLag)
+}
+
+func (s *HurstStream) Truncate() {
+	s.slice = s.slice.Truncate(MaxNumOfEWMA)
+}
+
+// hurstExponent returns the slope of log(rms of the lagged differences) against log(lag)
+func hurstExponent(values []float64, maxLag int) float64 {
+	var xs, ys []float64
+	for lag := 1; lag <= maxLag && lag < len(values)-1; lag++ {
+		var sumSq float64
+		for i := lag; i < len(values); i++ {
+			d := values[i] - values[i-lag]
+			sumSq += d * d
+		}
+
+		meanSq := sumSq / float64(len(values)-lag)
+		if meanSq <= 0 {
+			continue
+		}
+
+		xs = append(xs, math.Log(float64(lag)))
+		ys = append(ys, 0.5*math.Log(meanSq))
+	}
+
+	if len(xs) < 2 {
+		return 0.5
+	}
+
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(len(xs))
+	meanY /= float64(len(ys))
+
+	var cov, varX float64
+	for i := range xs {
+		cov += (xs[i] - meanX) * (ys[i] - meanY)
+		varX += (xs[i] - meanX) * (xs[i] - meanX)
+	}
+
+	if varX == 0 {
+		return 0.5
+	}
+
+	return cov / varX
+}
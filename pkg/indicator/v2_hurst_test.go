@@ -0,0 +1,99 @@
+package indicator
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_hurstExponent(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	// random walk
+	var walk []float64
+	x := 0.0
+	for i := 0; i < 2000; i++ {
+		x += rnd.NormFloat64()
+		walk = append(walk, x)
+	}
+	assert.InDelta(t, 0.5, hurstExponent(walk, 20), 0.1)
+
+	// mean-reverting: the white noise around a level
+	var noise []float64
+	for i := 0; i < 2000; i++ {
+		noise = append(noise, rnd.NormFloat64())
+	}
+	assert.Less(t, hurstExponent(noise, 20), 0.2)
+
+	// trending: the drift dominates the noise
+	var trend []float64
+	x = 0.0
+	for i := 0; i < 2000; i++ {
+		x += 1.0 + 0.1*rnd.NormFloat64()
+		trend = append(trend, x)
+	}
+	assert.Greater(t, hurstExponent(trend, 20), 0.9)
+}
+
+func TestRegimeStream(t *testing.T) {
+	source := NewFloat64Series()
+	hurst := Hurst(source, 100, 10)
+	regime := RegimeOf(hurst, 0, 0)
+
+	var changes []Regime
+	regime.OnChange(func(previous, current Regime) {
+		assert.NotEqual(t, previous, current)
+		assert.Equal(t, current, regime.Regime())
+		changes = append(changes, current)
+	})
+
+	// not enough values, it's chop
+	source.PushAndEmit(100)
+	assert.Equal(t, 0.5, hurst.Last(0))
+	assert.Equal(t, RegimeChop, regime.Regime())
+
+	// trending prices
+	price := 100.0
+	for i := 0; i < 150; i++ {
+		price *= 1.01
+		source.PushAndEmit(price + math.Sin(float64(i)))
+	}
+	assert.Equal(t, RegimeTrending, regime.Regime())
+
+	// mean-reverting prices
+	for i := 0; i < 150; i++ {
+		if i%2 == 0 {
+			source.PushAndEmit(price * 1.01)
+		} else {
+			source.PushAndEmit(price * 0.99)
+		}
+	}
+	assert.Equal(t, RegimeMeanReverting, regime.Regime())
+	assert.Equal(t, RegimeMeanReverting, changes[len(changes)-1])
+	assert.Equal(t, "mean-reverting", regime.Regime().String())
+}
+
+func TestRegimeStream_Hysteresis(t *testing.T) {
+	source := NewFloat64Series()
+	regime := RegimeOf(source, 0.55, 0.45)
+	regime.Hysteresis = 0.05
+
+	source.PushAndEmit(0.5)
+	assert.Equal(t, RegimeChop, regime.Regime())
+
+	// within the hysteresis band, stay in chop
+	source.PushAndEmit(0.58)
+	assert.Equal(t, RegimeChop, regime.Regime())
+
+	source.PushAndEmit(0.61)
+	assert.Equal(t, RegimeTrending, regime.Regime())
+
+	// stay trending until the exponent falls below the threshold minus the hysteresis
+	source.PushAndEmit(0.52)
+	assert.Equal(t, RegimeTrending, regime.Regime())
+
+	source.PushAndEmit(0.49)
+	assert.Equal(t, RegimeChop, regime.Regime())
+}
@@ -0,0 +1,58 @@
+package indicator
+
+// KalmanFilterStream is a one-dimensional Kalman filter smoother,
+// the true value is assumed to follow a random walk with the process noise, and the input is measured with the measurement noise.
+//
+// The smoothness is decided by the ratio of the process noise to the measurement noise,
+// the lower ratio gives the smoother but more lagging output.
+type KalmanFilterStream struct {
+	*Float64Series
+
+	// ProcessNoise is the variance of the random walk of the true value
+	ProcessNoise float64
+
+	// MeasurementNoise is the variance of the measurement noise of the input
+	MeasurementNoise float64
+
+	// errorCovariance is the variance of the current estimation
+	errorCovariance float64
+	initialized     bool
+}
+
+func KalmanFilter2(source Float64Source, processNoise, measurementNoise float64) *KalmanFilterStream {
+	s := &KalmanFilterStream{
+		Float64Series:    NewFloat64Series(),
+		ProcessNoise:     processNoise,
+		MeasurementNoise: measurementNoise,
+	}
+	s.Bind(source, s)
+	return s
+}
+
+func (s *KalmanFilterStream) Calculate(v float64) float64 {
+	if !s.initialized {
+		s.initialized = true
+		s.errorCovariance = s.MeasurementNoise
+		return v
+	}
+
+	// predict
+	estimate := s.slice.Last(0)
+	p := s.errorCovariance + s.ProcessNoise
+
+	// update
+	gain := p / (p + s.MeasurementNoise)
+	estimate += gain * (v - estimate)
+	s.errorCovariance = (1 - gain) * p
+	return estimate
+}
+
+// Gain returns the Kalman gain of the next update
+func (s *KalmanFilterStream) Gain() float64 {
+	p := s.errorCovariance + s.ProcessNoise
+	return p / (p + s.MeasurementNoise)
+}
+
+func (s *KalmanFilterStream) Truncate() {
+	s.slice = s.slice.Truncate(MaxNumOfEWMA)
+}
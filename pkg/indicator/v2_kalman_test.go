@@ -0,0 +1,57 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKalmanFilterStream(t *testing.T) {
+	source := NewFloat64Series()
+	kf := KalmanFilter2(source, 0.01, 1.0)
+
+	source.PushAndEmit(100)
+	assert.Equal(t, 100.0, kf.Last(0))
+
+	// the measurement noise is smoothed out
+	for i := 0; i < 100; i++ {
+		if i%2 == 0 {
+			source.PushAndEmit(101)
+		} else {
+			source.PushAndEmit(99)
+		}
+	}
+	assert.InDelta(t, 100.0, kf.Last(0), 0.2)
+
+	// the steady state gain is decided by the noise ratio
+	assert.InDelta(t, 0.095, kf.Gain(), 0.001)
+
+	// the filter follows the level shift
+	for i := 0; i < 100; i++ {
+		source.PushAndEmit(110)
+	}
+	assert.InDelta(t, 110.0, kf.Last(0), 0.01)
+}
+
+func TestKalmanFilterStream_RestoreState(t *testing.T) {
+	source := NewFloat64Series()
+	kf := KalmanFilter2(source, 0.01, 1.0)
+	for _, v := range stateTestValues[:10] {
+		source.PushAndEmit(v)
+	}
+
+	var state KalmanFilterState
+	roundTrip(t, kf.State(), &state)
+
+	restoredSource := NewFloat64Series()
+	restored := KalmanFilter2(restoredSource, 0.01, 1.0)
+	assert.NoError(t, restored.RestoreState(&state))
+
+	for _, v := range stateTestValues[10:] {
+		source.PushAndEmit(v)
+		restoredSource.PushAndEmit(v)
+	}
+
+	assert.InDelta(t, kf.Last(0), restored.Last(0), 1e-9)
+	assert.Error(t, KalmanFilter2(NewFloat64Series(), 0.1, 1.0).RestoreState(&state))
+}
@@ -0,0 +1,70 @@
+package indicator
+
+import "github.com/c9s/bbgo/pkg/types"
+
+// KeltnerStream is the Keltner channel, the middle band is the EWMA of the close prices,
+// and the bands are the middle band plus and minus the multiplier times the ATR. The stream values are the middle band.
+type KeltnerStream struct {
+	// the middle band series
+	*Float64Series
+
+	UpBand, DownBand *Float64Series
+
+	multiplier float64
+
+	EWMA *EWMAStream
+	ATR  *ATRStream
+}
+
+// Keltner creates the KeltnerStream, the common settings are window 20, atrWindow 10 and multiplier 2
+func Keltner(source KLineSubscription, window, atrWindow int, multiplier float64) *KeltnerStream {
+	// bind these indicators before our main calculator
+	ewma := EWMA2(ClosePrices(source), window)
+	atr := ATR2(source, atrWindow)
+
+	s := &KeltnerStream{
+		Float64Series: NewFloat64Series(),
+		UpBand:        NewFloat64Series(),
+		DownBand:      NewFloat64Series(),
+		multiplier:    multiplier,
+		EWMA:          ewma,
+		ATR:           atr,
+	}
+
+	source.AddSubscriber(func(k types.KLine) {
+		s.calculateAndPush()
+	})
+	return s
+}
+
+func (s *KeltnerStream) calculateAndPush() {
+	// the ATR is zero before its window is filled, the bands are the same as the middle band then
+	mid := s.EWMA.Last(0)
+	band := s.ATR.Last(0) * s.multiplier
+
+	s.UpBand.PushAndEmit(mid + band)
+	s.DownBand.PushAndEmit(mid - band)
+	s.PushAndEmit(mid)
+	s.Truncate()
+}
+
+// Upper returns the latest up band value
+func (s *KeltnerStream) Upper() float64 {
+	return s.UpBand.Last(0)
+}
+
+// Lower returns the latest down band value
+func (s *KeltnerStream) Lower() float64 {
+	return s.DownBand.Last(0)
+}
+
+// Mid returns the latest middle band value
+func (s *KeltnerStream) Mid() float64 {
+	return s.Last(0)
+}
+
+func (s *KeltnerStream) Truncate() {
+	s.slice = s.slice.Truncate(MaxNumOfEWMA)
+	s.UpBand.slice = s.UpBand.slice.Truncate(MaxNumOfEWMA)
+	s.DownBand.slice = s.DownBand.slice.Truncate(MaxNumOfEWMA)
+}
@@ -0,0 +1,39 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestKeltnerStream(t *testing.T) {
+	stream := &types.StandardStream{}
+	kLines := KLines(stream, "", "")
+	keltner := Keltner(kLines, 5, 3, 2.0)
+
+	closePrice := 100.0
+	for i := 0; i < 20; i++ {
+		closePrice += 1.0
+		stream.EmitKLineClosed(types.KLine{
+			High:  fixedpoint.NewFromFloat(closePrice + 2.0),
+			Low:   fixedpoint.NewFromFloat(closePrice - 2.0),
+			Close: fixedpoint.NewFromFloat(closePrice),
+		})
+	}
+
+	assert.Equal(t, 20, keltner.Length())
+	assert.Equal(t, 20, keltner.UpBand.Length())
+	assert.Equal(t, 20, keltner.DownBand.Length())
+
+	// the true range is always 4 since the previous close is within the high and the low
+	assert.InDelta(t, 4.0, keltner.ATR.Last(0), 1e-9)
+	assert.Equal(t, keltner.EWMA.Last(0), keltner.Mid())
+	assert.InDelta(t, keltner.Mid()+8.0, keltner.Upper(), 1e-9)
+	assert.InDelta(t, keltner.Mid()-8.0, keltner.Lower(), 1e-9)
+
+	// the bands are the same as the middle band before the ATR window is filled
+	assert.Equal(t, keltner.Last(19), keltner.UpBand.Last(19))
+}
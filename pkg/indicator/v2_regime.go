@@ -0,0 +1,104 @@
+package indicator
+
+// Regime is the market regime classified by the RegimeStream
+type Regime float64
+
+const (
+	RegimeChop          Regime = 0.0
+	RegimeTrending      Regime = 1.0
+	RegimeMeanReverting Regime = -1.0
+)
+
+func (r Regime) String() string {
+	switch r {
+	case RegimeTrending:
+		return "trending"
+	case RegimeMeanReverting:
+		return "mean-reverting"
+	}
+
+	return "chop"
+}
+
+// RegimeStream classifies the market regime by the Hurst exponent,
+// H above the trending threshold is trending, H below the mean-reverting threshold is mean-reverting, otherwise it's chop.
+//
+// The hysteresis keeps the current regime until the exponent moves beyond the threshold by the hysteresis,
+// so that the regime doesn't flip back and forth around the threshold.
+//
+//go:generate callbackgen -type RegimeStream
+type RegimeStream struct {
+	*Float64Series
+
+	TrendingThreshold      float64
+	MeanRevertingThreshold float64
+	Hysteresis             float64
+
+	changeCallbacks []func(previous, current Regime)
+}
+
+// RegimeOf creates the RegimeStream from the Hurst exponent stream, the default thresholds are 0.55 and 0.45
+func RegimeOf(hurst Float64Source, trendingThreshold, meanRevertingThreshold float64) *RegimeStream {
+	if trendingThreshold == 0 {
+		trendingThreshold = 0.55
+	}
+
+	if meanRevertingThreshold == 0 {
+		meanRevertingThreshold = 0.45
+	}
+
+	s := &RegimeStream{
+		Float64Series:          NewFloat64Series(),
+		TrendingThreshold:      trendingThreshold,
+		MeanRevertingThreshold: meanRevertingThreshold,
+	}
+	s.Bind(hurst, s)
+	return s
+}
+
+func (s *RegimeStream) Calculate(h float64) float64 {
+	current := s.Regime()
+	next := RegimeChop
+
+	trending, meanReverting := s.TrendingThreshold, s.MeanRevertingThreshold
+	switch current {
+	case RegimeTrending:
+		trending -= s.Hysteresis
+	case RegimeMeanReverting:
+		meanReverting += s.Hysteresis
+	default:
+		if s.Length() > 0 {
+			trending += s.Hysteresis
+			meanReverting -= s.Hysteresis
+		}
+	}
+
+	if h > trending {
+		next = RegimeTrending
+	} else if h < meanReverting {
+		next = RegimeMeanReverting
+	}
+
+	return float64(next)
+}
+
+// PushAndEmit pushes the regime and emits the change event after the update event when the regime is changed
+func (s *RegimeStream) PushAndEmit(v float64) {
+	hasPrevious := s.Length() > 0
+	previous := s.Regime()
+
+	s.Float64Series.PushAndEmit(v)
+
+	if hasPrevious && Regime(v) != previous {
+		s.EmitChange(previous, Regime(v))
+	}
+}
+
+// Regime returns the current regime
+func (s *RegimeStream) Regime() Regime {
+	return Regime(s.Last(0))
+}
+
+func (s *RegimeStream) Truncate() {
+	s.slice = s.slice.Truncate(MaxNumOfEWMA)
+}
@@ -0,0 +1,113 @@
+package indicator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/c9s/bbgo/pkg/datatype/floats"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// RollingWindowFunc calculates the statistic of the window values, the values are ordered from the oldest to the latest
+type RollingWindowFunc func(values floats.Slice) float64
+
+// RollingWindowStream applies the function on the last window values of the source,
+// the function is applied on the available values before the window is filled.
+//
+// stream := RollingWindow(ClosePrices(kLines), 20, func(values floats.Slice) float64 { return values.Max() - values.Min() })
+type RollingWindowStream struct {
+	*Float64Series
+
+	window    int
+	rawValues *types.Queue
+	fn        RollingWindowFunc
+}
+
+func RollingWindow(source Float64Source, window int, fn RollingWindowFunc) *RollingWindowStream {
+	s := &RollingWindowStream{
+		Float64Series: NewFloat64Series(),
+		window:        window,
+		rawValues:     types.NewQueue(window),
+		fn:            fn,
+	}
+	s.Bind(source, s)
+	return s
+}
+
+func (s *RollingWindowStream) Calculate(v float64) float64 {
+	s.rawValues.Update(v)
+	return s.fn(queueValues(s.rawValues))
+}
+
+func (s *RollingWindowStream) Truncate() {
+	s.slice = s.slice.Truncate(MaxNumOfEWMA)
+}
+
+// RollingMean is the mean of the last window values
+func RollingMean(source Float64Source, window int) *RollingWindowStream {
+	return RollingWindow(source, window, rollingMean)
+}
+
+// RollingStd is the population standard deviation of the last window values
+func RollingStd(source Float64Source, window int) *RollingWindowStream {
+	return RollingWindow(source, window, rollingStd)
+}
+
+// ZScore is the number of the standard deviations of the latest value from the mean of the last window values,
+// it's zero when the window values are all the same.
+func ZScore(source Float64Source, window int) *RollingWindowStream {
+	return RollingWindow(source, window, func(values floats.Slice) float64 {
+		std := rollingStd(values)
+		if std == 0 {
+			return 0
+		}
+
+		return (values.Last(0) - rollingMean(values)) / std
+	})
+}
+
+// Percentile is the p-th percentile of the last window values, p is in [0, 100],
+// the value between the 2 nearest ranks is linearly interpolated.
+func Percentile(source Float64Source, window int, p float64) *RollingWindowStream {
+	return RollingWindow(source, window, func(values floats.Slice) float64 {
+		return percentile(values, p)
+	})
+}
+
+func rollingMean(values floats.Slice) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	return values.Mean()
+}
+
+func rollingStd(values floats.Slice) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	mean := values.Mean()
+
+	var sumSq float64
+	for _, v := range values {
+		sumSq += (v - mean) * (v - mean)
+	}
+
+	return math.Sqrt(sumSq / float64(len(values)))
+}
+
+func percentile(values floats.Slice, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := append(floats.Slice{}, values...)
+	sort.Float64s(sorted)
+
+	p = math.Max(0, math.Min(100, p))
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
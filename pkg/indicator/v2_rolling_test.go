@@ -0,0 +1,54 @@
+package indicator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/datatype/floats"
+)
+
+func TestRollingWindowStreams(t *testing.T) {
+	source := NewFloat64Series()
+	mean := RollingMean(source, 4)
+	std := RollingStd(source, 4)
+	zScore := ZScore(source, 4)
+	median := Percentile(source, 4, 50)
+	p75 := Percentile(source, 4, 75)
+	rangeStream := RollingWindow(source, 4, func(values floats.Slice) float64 {
+		return values.Max() - values.Min()
+	})
+
+	// the available values are used before the window is filled
+	source.PushAndEmit(2)
+	assert.Equal(t, 2.0, mean.Last(0))
+	assert.Equal(t, 0.0, std.Last(0))
+	assert.Equal(t, 0.0, zScore.Last(0))
+
+	for _, v := range []float64{4, 6, 8, 10} {
+		source.PushAndEmit(v)
+	}
+
+	// window values: 4, 6, 8, 10
+	assert.Equal(t, 5, mean.Length())
+	assert.Equal(t, 7.0, mean.Last(0))
+	assert.InDelta(t, math.Sqrt(5), std.Last(0), 1e-9)
+	assert.InDelta(t, 3/math.Sqrt(5), zScore.Last(0), 1e-9)
+	assert.Equal(t, 7.0, median.Last(0))
+	assert.Equal(t, 8.5, p75.Last(0))
+	assert.Equal(t, 6.0, rangeStream.Last(0))
+}
+
+func Test_percentile(t *testing.T) {
+	values := floats.Slice{5, 1, 3, 2, 4}
+	assert.Equal(t, 1.0, percentile(values, 0))
+	assert.Equal(t, 3.0, percentile(values, 50))
+	assert.Equal(t, 5.0, percentile(values, 100))
+	assert.Equal(t, 1.4, percentile(values, 10))
+	assert.Equal(t, 5.0, percentile(values, 150))
+	assert.Equal(t, 0.0, percentile(nil, 50))
+
+	// the input values are not sorted in place
+	assert.Equal(t, floats.Slice{5, 1, 3, 2, 4}, values)
+}
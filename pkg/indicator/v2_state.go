@@ -0,0 +1,231 @@
+package indicator
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/datatype/floats"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// MaxNumOfStateValues is the max number of the latest values saved in the indicator state
+const MaxNumOfStateValues = 500
+
+// Snapshot returns the copy of the latest values of the series for the state persistence
+func (f *Float64Series) Snapshot() []float64 {
+	values := f.slice
+	if len(values) > MaxNumOfStateValues {
+		values = values[len(values)-MaxNumOfStateValues:]
+	}
+
+	return append([]float64{}, values...)
+}
+
+// Restore replaces the values of the series with the saved values, the update callbacks are not triggered
+func (f *Float64Series) Restore(values []float64) {
+	f.slice = append(floats.Slice{}, values...)
+	f.SeriesBase.Series = f.slice
+}
+
+func queueValues(q *types.Queue) []float64 {
+	values := make([]float64, 0, q.Length())
+	for i := q.Length() - 1; i >= 0; i-- {
+		values = append(values, q.Last(i))
+	}
+
+	return values
+}
+
+func restoreQueue(size int, values []float64) *types.Queue {
+	q := types.NewQueue(size)
+	for _, v := range values {
+		q.Update(v)
+	}
+
+	return q
+}
+
+func checkStateWindow(name string, window, stateWindow int) error {
+	if window != stateWindow {
+		return fmt.Errorf("%s state window %d does not match the indicator window %d", name, stateWindow, window)
+	}
+
+	return nil
+}
+
+// EWMAState is the serializable state of EWMAStream
+type EWMAState struct {
+	Window int       `json:"window"`
+	Values []float64 `json:"values"`
+}
+
+func (s *EWMAStream) State() *EWMAState {
+	return &EWMAState{Window: s.window, Values: s.Snapshot()}
+}
+
+// RestoreState restores the saved state, the state of another window is rejected
+func (s *EWMAStream) RestoreState(state *EWMAState) error {
+	if err := checkStateWindow("ewma", s.window, state.Window); err != nil {
+		return err
+	}
+
+	s.Restore(state.Values)
+	return nil
+}
+
+// SMAState is the serializable state of SMAStream
+type SMAState struct {
+	Window    int       `json:"window"`
+	Values    []float64 `json:"values"`
+	RawValues []float64 `json:"rawValues"`
+}
+
+func (s *SMAStream) State() *SMAState {
+	return &SMAState{Window: s.window, Values: s.Snapshot(), RawValues: queueValues(s.rawValues)}
+}
+
+func (s *SMAStream) RestoreState(state *SMAState) error {
+	if err := checkStateWindow("sma", s.window, state.Window); err != nil {
+		return err
+	}
+
+	s.Restore(state.Values)
+	s.rawValues = restoreQueue(s.window, state.RawValues)
+	return nil
+}
+
+// StdDevState is the serializable state of StdDevStream
+type StdDevState struct {
+	Window    int       `json:"window"`
+	Values    []float64 `json:"values"`
+	RawValues []float64 `json:"rawValues"`
+}
+
+func (s *StdDevStream) State() *StdDevState {
+	return &StdDevState{Window: s.window, Values: s.Snapshot(), RawValues: queueValues(s.rawValues)}
+}
+
+func (s *StdDevStream) RestoreState(state *StdDevState) error {
+	if err := checkStateWindow("stddev", s.window, state.Window); err != nil {
+		return err
+	}
+
+	s.Restore(state.Values)
+	s.rawValues = restoreQueue(s.window, state.RawValues)
+	return nil
+}
+
+// BOLLState is the serializable state of BOLLStream
+type BOLLState struct {
+	Window   int          `json:"window"`
+	K        float64      `json:"k"`
+	Band     []float64    `json:"band"`
+	UpBand   []float64    `json:"upBand"`
+	DownBand []float64    `json:"downBand"`
+	SMA      *SMAState    `json:"sma"`
+	StdDev   *StdDevState `json:"stdDev"`
+}
+
+func (s *BOLLStream) State() *BOLLState {
+	return &BOLLState{
+		Window:   s.window,
+		K:        s.k,
+		Band:     s.Snapshot(),
+		UpBand:   s.UpBand.Snapshot(),
+		DownBand: s.DownBand.Snapshot(),
+		SMA:      s.SMA.State(),
+		StdDev:   s.StdDev.State(),
+	}
+}
+
+func (s *BOLLStream) RestoreState(state *BOLLState) error {
+	if err := checkStateWindow("boll", s.window, state.Window); err != nil {
+		return err
+	}
+
+	if state.K != s.k {
+		return fmt.Errorf("boll state k %f does not match the indicator k %f", state.K, s.k)
+	}
+
+	if state.SMA == nil || state.StdDev == nil {
+		return fmt.Errorf("boll state is incomplete")
+	}
+
+	if err := s.SMA.RestoreState(state.SMA); err != nil {
+		return err
+	}
+
+	if err := s.StdDev.RestoreState(state.StdDev); err != nil {
+		return err
+	}
+
+	s.Restore(state.Band)
+	s.UpBand.Restore(state.UpBand)
+	s.DownBand.Restore(state.DownBand)
+	return nil
+}
+
+// RMAState is the serializable state of RMAStream
+type RMAState struct {
+	Window   int       `json:"window"`
+	Adjust   bool      `json:"adjust"`
+	Counter  int       `json:"counter"`
+	Sum      float64   `json:"sum"`
+	Previous float64   `json:"previous"`
+	Values   []float64 `json:"values"`
+}
+
+func (s *RMAStream) State() *RMAState {
+	return &RMAState{
+		Window:   s.window,
+		Adjust:   s.Adjust,
+		Counter:  s.counter,
+		Sum:      s.sum,
+		Previous: s.previous,
+		Values:   s.Snapshot(),
+	}
+}
+
+func (s *RMAStream) RestoreState(state *RMAState) error {
+	if err := checkStateWindow("rma", s.window, state.Window); err != nil {
+		return err
+	}
+
+	if state.Adjust != s.Adjust {
+		return fmt.Errorf("rma state adjust %t does not match the indicator adjust %t", state.Adjust, s.Adjust)
+	}
+
+	s.counter = state.Counter
+	s.sum = state.Sum
+	s.previous = state.Previous
+	s.Restore(state.Values)
+	return nil
+}
+
+// KalmanFilterState is the serializable state of KalmanFilterStream
+type KalmanFilterState struct {
+	ProcessNoise     float64   `json:"processNoise"`
+	MeasurementNoise float64   `json:"measurementNoise"`
+	ErrorCovariance  float64   `json:"errorCovariance"`
+	Values           []float64 `json:"values"`
+}
+
+func (s *KalmanFilterStream) State() *KalmanFilterState {
+	return &KalmanFilterState{
+		ProcessNoise:     s.ProcessNoise,
+		MeasurementNoise: s.MeasurementNoise,
+		ErrorCovariance:  s.errorCovariance,
+		Values:           s.Snapshot(),
+	}
+}
+
+func (s *KalmanFilterStream) RestoreState(state *KalmanFilterState) error {
+	if state.ProcessNoise != s.ProcessNoise || state.MeasurementNoise != s.MeasurementNoise {
+		return fmt.Errorf("kalman filter state noise (%f, %f) does not match the indicator noise (%f, %f)",
+			state.ProcessNoise, state.MeasurementNoise, s.ProcessNoise, s.MeasurementNoise)
+	}
+
+	s.errorCovariance = state.ErrorCovariance
+	s.initialized = len(state.Values) > 0
+	s.Restore(state.Values)
+	return nil
+}
@@ -0,0 +1,109 @@
+package indicator
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var stateTestValues = []float64{10, 11, 12, 11, 13, 15, 14, 13, 16, 18, 17, 19, 21, 20, 22}
+
+// roundTrip marshals the state to json and unmarshals it back like the persistence service
+func roundTrip(t *testing.T, in, out interface{}) {
+	data, err := json.Marshal(in)
+	if assert.NoError(t, err) {
+		assert.NoError(t, json.Unmarshal(data, out))
+	}
+}
+
+func TestEWMAStream_RestoreState(t *testing.T) {
+	source := NewFloat64Series()
+	ewma := EWMA2(source, 5)
+	for _, v := range stateTestValues[:10] {
+		source.PushAndEmit(v)
+	}
+
+	var state EWMAState
+	roundTrip(t, ewma.State(), &state)
+
+	restoredSource := NewFloat64Series()
+	restored := EWMA2(restoredSource, 5)
+	assert.NoError(t, restored.RestoreState(&state))
+	assert.Equal(t, ewma.Length(), restored.Length())
+
+	for _, v := range stateTestValues[10:] {
+		source.PushAndEmit(v)
+		restoredSource.PushAndEmit(v)
+	}
+
+	assert.InDelta(t, ewma.Last(0), restored.Last(0), 1e-9)
+	assert.InDelta(t, ewma.Last(1), restored.Last(1), 1e-9)
+
+	assert.Error(t, EWMA2(NewFloat64Series(), 10).RestoreState(&state))
+}
+
+func TestBOLLStream_RestoreState(t *testing.T) {
+	source := NewFloat64Series()
+	boll := BOLL2(source, 5, 2.0)
+	for _, v := range stateTestValues[:10] {
+		source.PushAndEmit(v)
+	}
+
+	var state BOLLState
+	roundTrip(t, boll.State(), &state)
+
+	restoredSource := NewFloat64Series()
+	restored := BOLL2(restoredSource, 5, 2.0)
+	assert.NoError(t, restored.RestoreState(&state))
+
+	for _, v := range stateTestValues[10:] {
+		source.PushAndEmit(v)
+		restoredSource.PushAndEmit(v)
+	}
+
+	assert.InDelta(t, boll.SMA.Last(0), restored.SMA.Last(0), 1e-9)
+	assert.InDelta(t, boll.UpBand.Last(0), restored.UpBand.Last(0), 1e-9)
+	assert.InDelta(t, boll.DownBand.Last(0), restored.DownBand.Last(0), 1e-9)
+	assert.Equal(t, boll.UpBand.Length(), restored.UpBand.Length())
+
+	assert.Error(t, BOLL2(NewFloat64Series(), 5, 3.0).RestoreState(&state))
+}
+
+func TestRMAStream_RestoreState(t *testing.T) {
+	source := NewFloat64Series()
+	rma := RMA2(source, 5, true)
+	for _, v := range stateTestValues[:3] {
+		source.PushAndEmit(v)
+	}
+
+	var state RMAState
+	roundTrip(t, rma.State(), &state)
+
+	restoredSource := NewFloat64Series()
+	restored := RMA2(restoredSource, 5, true)
+	assert.NoError(t, restored.RestoreState(&state))
+
+	for _, v := range stateTestValues[3:] {
+		source.PushAndEmit(v)
+		restoredSource.PushAndEmit(v)
+	}
+
+	assert.InDelta(t, rma.Last(0), restored.Last(0), 1e-9)
+	assert.Equal(t, rma.Length(), restored.Length())
+}
+
+func TestFloat64Series_Snapshot(t *testing.T) {
+	s := NewFloat64Series()
+	for i := 0; i < MaxNumOfStateValues+10; i++ {
+		s.PushAndEmit(float64(i))
+	}
+
+	values := s.Snapshot()
+	assert.Len(t, values, MaxNumOfStateValues)
+	assert.Equal(t, float64(MaxNumOfStateValues+9), values[len(values)-1])
+
+	// the snapshot is a copy
+	values[0] = -1
+	assert.Equal(t, 10.0, s.Last(MaxNumOfStateValues-1))
+}
@@ -0,0 +1,65 @@
+package indicator
+
+import (
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type TradeSubscription interface {
+	AddSubscriber(f func(trade types.Trade))
+	Length() int
+	Last(i int) *types.Trade
+}
+
+// VolumeDeltaStream is the signed volume of the trades,
+// the quantity of a taker buy is positive and the quantity of a taker sell is negative.
+type VolumeDeltaStream struct {
+	*Float64Series
+}
+
+func VolumeDelta(source TradeSubscription) *VolumeDeltaStream {
+	s := &VolumeDeltaStream{
+		Float64Series: NewFloat64Series(),
+	}
+
+	source.AddSubscriber(func(trade types.Trade) {
+		s.PushAndEmit(signedTradeVolume(trade))
+		s.Truncate()
+	})
+	return s
+}
+
+func (s *VolumeDeltaStream) Truncate() {
+	s.slice = s.slice.Truncate(MaxNumOfTrades)
+}
+
+// CumulativeVolumeDeltaStream is the running sum of the volume delta (CVD)
+type CumulativeVolumeDeltaStream struct {
+	*Float64Series
+
+	sum float64
+}
+
+func CumulativeVolumeDelta(source TradeSubscription) *CumulativeVolumeDeltaStream {
+	s := &CumulativeVolumeDeltaStream{
+		Float64Series: NewFloat64Series(),
+	}
+
+	source.AddSubscriber(func(trade types.Trade) {
+		s.sum += signedTradeVolume(trade)
+		s.PushAndEmit(s.sum)
+		s.Truncate()
+	})
+	return s
+}
+
+func (s *CumulativeVolumeDeltaStream) Truncate() {
+	s.slice = s.slice.Truncate(MaxNumOfTrades)
+}
+
+func signedTradeVolume(trade types.Trade) float64 {
+	if trade.Side == types.SideTypeSell {
+		return -trade.Quantity.Float64()
+	}
+
+	return trade.Quantity.Float64()
+}
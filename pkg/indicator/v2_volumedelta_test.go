@@ -0,0 +1,39 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_VolumeDelta(t *testing.T) {
+	stream := &types.StandardStream{}
+	trades := Trades(stream, "BTCUSDT")
+	delta := VolumeDelta(trades)
+	cvd := CumulativeVolumeDelta(trades)
+
+	emit := func(symbol string, side types.SideType, quantity string) {
+		stream.EmitMarketTrade(types.Trade{
+			Symbol:   symbol,
+			Side:     side,
+			Price:    fixedpoint.NewFromInt(30000),
+			Quantity: fixedpoint.MustNewFromString(quantity),
+		})
+	}
+
+	emit("BTCUSDT", types.SideTypeBuy, "1.5")
+	emit("BTCUSDT", types.SideTypeSell, "0.5")
+	emit("ETHUSDT", types.SideTypeSell, "10")
+	emit("BTCUSDT", types.SideTypeSell, "2")
+
+	assert.Equal(t, 3, trades.Length())
+	assert.InDeltaSlice(t, []float64{1.5, -0.5, -2}, []float64(delta.Slice()), 1e-9)
+	assert.InDeltaSlice(t, []float64{1.5, 1.0, -1.0}, []float64(cvd.Slice()), 1e-9)
+
+	// the late subscriber receives the historical trades
+	late := CumulativeVolumeDelta(trades)
+	assert.InDelta(t, -1.0, late.Last(0), 1e-9)
+}
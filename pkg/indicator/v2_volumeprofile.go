@@ -0,0 +1,251 @@
+package indicator
+
+import (
+	"math"
+	"sort"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// DefaultValueAreaRatio is the ratio of the volume covered by the value area
+const DefaultValueAreaRatio = 0.7
+
+// VolumeProfileBin is a price bin of the volume profile, the bin covers [Low, High)
+type VolumeProfileBin struct {
+	Low, High float64
+	Volume    float64
+}
+
+// Price returns the mid price of the bin
+func (b VolumeProfileBin) Price() float64 {
+	return (b.Low + b.High) / 2.0
+}
+
+type volumeProfileBin struct {
+	volume float64
+
+	// count is the number of the klines in the window that contribute to the bin
+	count int
+}
+
+type volumeContribution struct {
+	index  int64
+	volume float64
+}
+
+// VolumeProfileStream builds the price-binned volume histogram of the klines in the rolling window,
+// the volume of a kline is distributed evenly to the bins between its low and high.
+//
+// The series is the point of control (POC), the mid price of the bin with the most volume.
+// ValueAreaHigh and ValueAreaLow are the bounds of the value area, the bins around the POC
+// that cover ValueAreaRatio of the total volume.
+type VolumeProfileStream struct {
+	// the point of control series
+	*Float64Series
+
+	ValueAreaHigh, ValueAreaLow *Float64Series
+
+	// ValueAreaRatio is the ratio of the volume covered by the value area, defaults to 0.7
+	ValueAreaRatio float64
+
+	window  int
+	binSize float64
+
+	bins map[int64]*volumeProfileBin
+
+	// contributions is the bin volumes of each kline in the window, the oldest one is removed when the window is full
+	contributions [][]volumeContribution
+}
+
+// VolumeProfile2 creates the VolumeProfileStream of the latest window klines with the given price bin size
+func VolumeProfile2(source KLineSubscription, window int, binSize float64) *VolumeProfileStream {
+	s := &VolumeProfileStream{
+		Float64Series:  NewFloat64Series(),
+		ValueAreaHigh:  NewFloat64Series(),
+		ValueAreaLow:   NewFloat64Series(),
+		ValueAreaRatio: DefaultValueAreaRatio,
+		window:         window,
+		binSize:        binSize,
+		bins:           make(map[int64]*volumeProfileBin),
+	}
+
+	source.AddSubscriber(func(k types.KLine) {
+		s.calculateAndPush(k.High.Float64(), k.Low.Float64(), k.Volume.Float64())
+	})
+	return s
+}
+
+func (s *VolumeProfileStream) binIndex(price float64) int64 {
+	return int64(math.Floor(price / s.binSize))
+}
+
+func (s *VolumeProfileStream) bin(index int64) VolumeProfileBin {
+	b := VolumeProfileBin{
+		Low:  float64(index) * s.binSize,
+		High: float64(index+1) * s.binSize,
+	}
+
+	if pb, ok := s.bins[index]; ok {
+		b.Volume = pb.volume
+	}
+
+	return b
+}
+
+func (s *VolumeProfileStream) add(high, low, volume float64) {
+	lowIndex, highIndex := s.binIndex(low), s.binIndex(high)
+	if highIndex < lowIndex {
+		lowIndex, highIndex = highIndex, lowIndex
+	}
+
+	binVolume := volume / float64(highIndex-lowIndex+1)
+
+	var contribution []volumeContribution
+	for i := lowIndex; i <= highIndex; i++ {
+		pb, ok := s.bins[i]
+		if !ok {
+			pb = &volumeProfileBin{}
+			s.bins[i] = pb
+		}
+
+		pb.volume += binVolume
+		pb.count++
+		contribution = append(contribution, volumeContribution{index: i, volume: binVolume})
+	}
+
+	s.contributions = append(s.contributions, contribution)
+}
+
+func (s *VolumeProfileStream) removeOldest() {
+	for _, c := range s.contributions[0] {
+		pb, ok := s.bins[c.index]
+		if !ok {
+			continue
+		}
+
+		pb.volume -= c.volume
+		pb.count--
+		if pb.count <= 0 {
+			delete(s.bins, c.index)
+		}
+	}
+
+	s.contributions = s.contributions[1:]
+}
+
+func (s *VolumeProfileStream) calculateAndPush(high, low, volume float64) {
+	if s.binSize <= 0 {
+		return
+	}
+
+	s.add(high, low, volume)
+	if len(s.contributions) > s.window {
+		s.removeOldest()
+	}
+
+	poc, ok := s.pointOfControl()
+	if !ok {
+		return
+	}
+
+	vah, val := s.valueArea(poc)
+	s.ValueAreaHigh.PushAndEmit(vah)
+	s.ValueAreaLow.PushAndEmit(val)
+	s.PushAndEmit(s.bin(poc).Price())
+}
+
+// pointOfControl returns the index of the bin with the most volume, the lower bin is picked on a tie
+func (s *VolumeProfileStream) pointOfControl() (int64, bool) {
+	var poc int64
+	var found bool
+	maxVolume := math.Inf(-1)
+	for index, pb := range s.bins {
+		if pb.volume > maxVolume || (pb.volume == maxVolume && index < poc) {
+			poc = index
+			maxVolume = pb.volume
+			found = true
+		}
+	}
+
+	return poc, found
+}
+
+// valueArea expands the value area from the POC to the neighbor bin with more volume
+// until the value area covers the value area ratio of the total volume
+func (s *VolumeProfileStream) valueArea(poc int64) (high, low float64) {
+	var total float64
+	minIndex, maxIndex := poc, poc
+	for index, pb := range s.bins {
+		total += pb.volume
+		if index < minIndex {
+			minIndex = index
+		}
+
+		if index > maxIndex {
+			maxIndex = index
+		}
+	}
+
+	target := total * s.ValueAreaRatio
+	lower, upper := poc, poc
+	covered := s.bin(poc).Volume
+	for covered < target && (lower > minIndex || upper < maxIndex) {
+		below, above := math.Inf(-1), math.Inf(-1)
+		if lower > minIndex {
+			below = s.bin(lower - 1).Volume
+		}
+
+		if upper < maxIndex {
+			above = s.bin(upper + 1).Volume
+		}
+
+		if above >= below {
+			upper++
+			covered += above
+		} else {
+			lower--
+			covered += below
+		}
+	}
+
+	return s.bin(upper).High, s.bin(lower).Low
+}
+
+// PointOfControl returns the mid price of the bin with the most volume
+func (s *VolumeProfileStream) PointOfControl() float64 {
+	return s.Last(0)
+}
+
+// Profile returns the non-empty bins of the profile sorted by price
+func (s *VolumeProfileStream) Profile() []VolumeProfileBin {
+	indexes := make([]int64, 0, len(s.bins))
+	for index := range s.bins {
+		indexes = append(indexes, index)
+	}
+
+	sort.Slice(indexes, func(i, j int) bool {
+		return indexes[i] < indexes[j]
+	})
+
+	bins := make([]VolumeProfileBin, 0, len(indexes))
+	for _, index := range indexes {
+		bins = append(bins, s.bin(index))
+	}
+
+	return bins
+}
+
+// VolumeAt returns the volume of the bin that contains the price
+func (s *VolumeProfileStream) VolumeAt(price float64) float64 {
+	if s.binSize <= 0 {
+		return 0
+	}
+
+	return s.bin(s.binIndex(price)).Volume
+}
+
+func (s *VolumeProfileStream) Truncate() {
+	s.slice = s.slice.Truncate(MaxNumOfEWMA)
+	s.ValueAreaHigh.slice = s.ValueAreaHigh.slice.Truncate(MaxNumOfEWMA)
+	s.ValueAreaLow.slice = s.ValueAreaLow.slice.Truncate(MaxNumOfEWMA)
+}
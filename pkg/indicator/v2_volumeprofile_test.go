@@ -0,0 +1,63 @@
+package indicator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestVolumeProfile2(t *testing.T) {
+	stream := &types.StandardStream{}
+	kLines := KLines(stream, "", "")
+	vp := VolumeProfile2(kLines, 3, 1.0)
+
+	push := func(low, high, volume float64) {
+		stream.EmitKLineClosed(types.KLine{
+			Low:    fixedpoint.NewFromFloat(low),
+			High:   fixedpoint.NewFromFloat(high),
+			Volume: fixedpoint.NewFromFloat(volume),
+		})
+	}
+
+	// bins: 10 => 10, 11 => 10, 12 => 10
+	push(10.2, 12.5, 30)
+	assert.Equal(t, 10.5, vp.PointOfControl())
+
+	// bins: 10 => 10, 11 => 70, 12 => 10
+	push(11.1, 11.9, 60)
+	assert.Equal(t, 11.5, vp.PointOfControl())
+	assert.Equal(t, 70.0, vp.VolumeAt(11.3))
+
+	// 70 / 90 covers the value area ratio already
+	assert.Equal(t, 12.0, vp.ValueAreaHigh.Last(0))
+	assert.Equal(t, 11.0, vp.ValueAreaLow.Last(0))
+
+	// bins: 10 => 10, 11 => 70, 12 => 10, 14 => 20, 15 => 20
+	push(14.0, 15.5, 40)
+	assert.Equal(t, 11.5, vp.PointOfControl())
+
+	// 70 / 130 is not enough, expand to 12 on a tie, then 10, and then to 14 over the empty bin 13
+	assert.Equal(t, 15.0, vp.ValueAreaHigh.Last(0))
+	assert.Equal(t, 10.0, vp.ValueAreaLow.Last(0))
+	assert.Equal(t, []VolumeProfileBin{
+		{Low: 10, High: 11, Volume: 10},
+		{Low: 11, High: 12, Volume: 70},
+		{Low: 12, High: 13, Volume: 10},
+		{Low: 14, High: 15, Volume: 20},
+		{Low: 15, High: 16, Volume: 20},
+	}, vp.Profile())
+
+	// the first kline is out of the window
+	// bins: 11 => 60, 14 => 20, 15 => 20, 16 => 50
+	push(16.2, 16.8, 50)
+	assert.Equal(t, 11.5, vp.PointOfControl())
+	assert.Equal(t, 0.0, vp.VolumeAt(10.5))
+	assert.Len(t, vp.Profile(), 4)
+
+	// the value area expands over the empty bins to the larger volume
+	assert.Equal(t, 11.0, vp.ValueAreaLow.Last(0))
+	assert.Equal(t, 17.0, vp.ValueAreaHigh.Last(0))
+}
@@ -14,6 +14,12 @@ type Command struct {
 	// StateF is the command handler function
 	F interface{}
 
+	// role is the required role of the private command, the authorized users are allowed if no role resolver is set
+	role Role
+
+	// sensitive hides the arguments in the audit log, e.g., the tokens and the one-time passwords
+	sensitive bool
+
 	stateID              int
 	states               map[State]State
 	statesFunc           map[State]interface{}
@@ -32,6 +38,18 @@ func NewCommand(name, desc string, f interface{}) *Command {
 	return c.Next(f)
 }
 
+// RequireRole sets the role required to run the command
+func (c *Command) RequireRole(role Role) *Command {
+	c.role = role
+	return c
+}
+
+// Sensitive hides the arguments of the command and its steps in the audit log
+func (c *Command) Sensitive() *Command {
+	c.sensitive = true
+	return c
+}
+
 // Transit defines the state transition that is not related to the last defined state.
 func (c *Command) Transit(state1, state2 State, f interface{}) *Command {
 	c.states[state1] = state2
@@ -19,3 +19,11 @@ func AddCustomInteraction(custom CustomInteraction) {
 func Start(ctx context.Context) error {
 	return defaultInteraction.Start(ctx)
 }
+
+func SetRoleResolver(resolver RoleResolver) {
+	defaultInteraction.SetRoleResolver(resolver)
+}
+
+func SetAuditLogger(logger AuditLogger) {
+	defaultInteraction.SetAuditLogger(logger)
+}
@@ -47,6 +47,15 @@ type Interact struct {
 	states     map[State]State
 	statesFunc map[State]interface{}
 
+	// stateCommands maps the states to the commands, which is used for the authorization and the audit of the command steps
+	stateCommands map[State]*Command
+
+	// roleResolver resolves the role of the session user, the private commands are not checked by the roles if it's nil
+	roleResolver RoleResolver
+
+	// auditLogger records the private commands if it's set
+	auditLogger AuditLogger
+
 	customInteractions []CustomInteraction
 
 	messengers []Messenger
@@ -61,9 +70,24 @@ func New() *Interact {
 		privateCommands: make(map[string]*Command),
 		states:          make(map[State]State),
 		statesFunc:      make(map[State]interface{}),
+		stateCommands:   make(map[State]*Command),
 	}
 }
 
+// SetRoleResolver enables the role-based authorization of the private commands
+func (it *Interact) SetRoleResolver(resolver RoleResolver) {
+	it.mu.Lock()
+	it.roleResolver = resolver
+	it.mu.Unlock()
+}
+
+// SetAuditLogger sets the logger of the private commands run by the users
+func (it *Interact) SetAuditLogger(logger AuditLogger) {
+	it.mu.Lock()
+	it.auditLogger = logger
+	it.mu.Unlock()
+}
+
 func (it *Interact) AddCustomInteraction(custom CustomInteraction) {
 	custom.Commands(it)
 
@@ -72,8 +96,10 @@ func (it *Interact) AddCustomInteraction(custom CustomInteraction) {
 	it.mu.Unlock()
 }
 
+// PrivateCommand registers the command that needs auth, the command requires the operator role by default,
+// use RequireRole to change the role.
 func (it *Interact) PrivateCommand(command, desc string, f interface{}) *Command {
-	cmd := NewCommand(command, desc, f)
+	cmd := NewCommand(command, desc, f).RequireRole(RoleOperator)
 	it.mu.Lock()
 	it.privateCommands[command] = cmd
 	it.mu.Unlock()
@@ -127,6 +153,15 @@ func (it *Interact) handleResponse(session Session, text string, ctxObjects ...i
 
 	ctxObjects = append(ctxObjects, session)
 	_, err := ParseFuncArgsAndCall(f, args, ctxObjects...)
+
+	it.mu.Lock()
+	cmd := it.stateCommands[state]
+	it.mu.Unlock()
+
+	if cmd != nil && it.isPrivateCommand(cmd) {
+		it.audit(session, cmd, args, err)
+	}
+
 	if err != nil {
 		return err
 	}
@@ -147,6 +182,12 @@ func (it *Interact) getCommand(session Session, command string) (*Command, error
 
 	if session.IsAuthorized() {
 		if cmd, ok := it.privateCommands[command]; ok {
+			if it.roleResolver != nil {
+				if role := it.roleResolver(session); !role.Allows(cmd.role) {
+					return nil, &permissionDeniedError{command: cmd, role: role}
+				}
+			}
+
 			return cmd, nil
 		}
 	} else {
@@ -166,12 +207,22 @@ func (it *Interact) getCommand(session Session, command string) (*Command, error
 func (it *Interact) runCommand(session Session, command string, args []string, ctxObjects ...interface{}) error {
 	cmd, err := it.getCommand(session, command)
 	if err != nil {
+		if denied, ok := err.(*permissionDeniedError); ok {
+			it.audit(session, denied.command, args, err)
+		}
+
 		return err
 	}
 
 	ctxObjects = append(ctxObjects, session)
 	session.SetState(cmd.initState)
-	if _, err := ParseFuncArgsAndCall(cmd.F, args, ctxObjects...); err != nil {
+	_, err = ParseFuncArgsAndCall(cmd.F, args, ctxObjects...)
+
+	if it.isPrivateCommand(cmd) {
+		it.audit(session, cmd, args, err)
+	}
+
+	if err != nil {
 		return err
 	}
 
@@ -187,6 +238,51 @@ func (it *Interact) runCommand(session Session, command string, args []string, c
 	return nil
 }
 
+func (it *Interact) isPrivateCommand(cmd *Command) bool {
+	it.mu.Lock()
+	defer it.mu.Unlock()
+	return it.privateCommands[cmd.Name] == cmd
+}
+
+// audit records the command (or the step of the command) run by the session user
+func (it *Interact) audit(session Session, cmd *Command, args []string, err error) {
+	it.mu.Lock()
+	logger, resolver := it.auditLogger, it.roleResolver
+	it.mu.Unlock()
+
+	if logger == nil {
+		return
+	}
+
+	entry := AuditEntry{
+		Time:    time.Now(),
+		Source:  SessionSource(session),
+		User:    SessionUser(session),
+		Command: cmd.Name,
+		Args:    args,
+		Result:  AuditResultOK,
+	}
+
+	if cmd.sensitive && len(args) > 0 {
+		entry.Args = []string{"******"}
+	}
+
+	if resolver != nil {
+		entry.Role = resolver(session)
+	}
+
+	if err != nil {
+		entry.Result = AuditResultError
+		entry.Error = err.Error()
+
+		if _, ok := err.(*permissionDeniedError); ok {
+			entry.Result = AuditResultDenied
+		}
+	}
+
+	logger.LogCommand(entry)
+}
+
 func (it *Interact) AddMessenger(messenger Messenger) {
 	// pass Responder function
 	messenger.SetTextMessageResponder(func(session Session, message string, reply Reply, ctxObjects ...interface{}) error {
@@ -234,6 +330,7 @@ func (it *Interact) registerCommands(commands map[string]*Command) error {
 		}
 		for s, f := range cmd.statesFunc {
 			it.statesFunc[s] = f
+			it.stateCommands[s] = cmd
 		}
 
 		// register commands to the service
@@ -279,3 +376,17 @@ func (it *Interact) Start(ctx context.Context) error {
 	}
 	return nil
 }
+
+type permissionDeniedError struct {
+	command *Command
+	role    Role
+}
+
+func (e *permissionDeniedError) Error() string {
+	role := string(e.role)
+	if role == "" {
+		role = "none"
+	}
+
+	return fmt.Sprintf("permission denied, %s requires the %s role, your role is %s", e.command.Name, e.command.role, role)
+}
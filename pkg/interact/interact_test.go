@@ -141,3 +141,84 @@ func TestCustomInteraction(t *testing.T) {
 		confirmed:  true,
 	}, testInteraction.closePositionTask)
 }
+
+type testAuditLogger struct {
+	entries []AuditEntry
+}
+
+func (l *testAuditLogger) LogCommand(entry AuditEntry) {
+	l.entries = append(l.entries, entry)
+}
+
+func TestPrivateCommandRoles(t *testing.T) {
+	b, err := tb.NewBot(tb.Settings{
+		Offline: true,
+	})
+	if !assert.NoError(t, err, "should have bot setup without error") {
+		return
+	}
+
+	it := New()
+
+	telegram := &Telegram{
+		Bot: b,
+	}
+	it.AddMessenger(telegram)
+
+	var closed float64
+	it.PrivateCommand("/position", "", func(reply Reply) error {
+		return nil
+	}).RequireRole(RoleViewer)
+	it.PrivateCommand("/closeposition", "", func(reply Reply) error {
+		return nil
+	}).Next(func(percentage float64) error {
+		closed = percentage
+		return nil
+	}).RequireRole(RoleAdmin)
+
+	roles := map[string]Role{"viewer": RoleViewer, "admin": RoleAdmin}
+	it.SetRoleResolver(func(session Session) Role {
+		return roles[SessionUser(session)]
+	})
+
+	auditLogger := &testAuditLogger{}
+	it.SetAuditLogger(auditLogger)
+
+	err = it.init()
+	assert.NoError(t, err)
+
+	viewer := telegram.loadSession(&tb.Message{
+		Chat:   &tb.Chat{ID: 1},
+		Sender: &tb.User{ID: 1, Username: "viewer"},
+	})
+	viewer.SetAuthorized()
+
+	err = it.runCommand(viewer, "/position", nil, telegram.newReply(viewer))
+	assert.NoError(t, err)
+
+	err = it.runCommand(viewer, "/closeposition", nil, telegram.newReply(viewer))
+	assert.ErrorContains(t, err, "requires the admin role")
+
+	admin := telegram.loadSession(&tb.Message{
+		Chat:   &tb.Chat{ID: 2},
+		Sender: &tb.User{ID: 2, Username: "admin"},
+	})
+	admin.SetAuthorized()
+
+	err = it.runCommand(admin, "/closeposition", nil, telegram.newReply(admin))
+	assert.NoError(t, err)
+
+	err = it.handleResponse(admin, "0.5", telegram.newReply(admin))
+	assert.NoError(t, err)
+	assert.Equal(t, 0.5, closed)
+
+	if assert.Len(t, auditLogger.entries, 4) {
+		assert.Equal(t, AuditResultOK, auditLogger.entries[0].Result)
+		assert.Equal(t, AuditResultDenied, auditLogger.entries[1].Result)
+		assert.Equal(t, "viewer", auditLogger.entries[1].User)
+		assert.Equal(t, "/closeposition", auditLogger.entries[3].Command)
+		assert.Equal(t, []string{"0.5"}, auditLogger.entries[3].Args)
+		assert.Equal(t, RoleAdmin, auditLogger.entries[3].Role)
+		assert.Equal(t, "telegram", auditLogger.entries[3].Source)
+	}
+}
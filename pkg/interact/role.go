@@ -0,0 +1,110 @@
+package interact
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Role is the authorization role of the user, a role is allowed to run the commands of the lower roles
+type Role string
+
+const (
+	// RoleViewer can run the read-only commands, e.g., /position and /balances
+	RoleViewer Role = "viewer"
+
+	// RoleOperator can control the strategies, e.g., /suspend and /resume
+	RoleOperator Role = "operator"
+
+	// RoleAdmin can run the commands that move funds, e.g., /closeposition and /emergencystop
+	RoleAdmin Role = "admin"
+)
+
+func (r Role) level() int {
+	switch r {
+	case RoleViewer:
+		return 1
+	case RoleOperator:
+		return 2
+	case RoleAdmin:
+		return 3
+	}
+
+	return 0
+}
+
+func (r Role) Validate() error {
+	if r.level() == 0 {
+		return fmt.Errorf("unknown role %q, valid roles are viewer, operator and admin", r)
+	}
+
+	return nil
+}
+
+// Allows returns true if the role is allowed to run the command that requires the given role
+func (r Role) Allows(required Role) bool {
+	if required == "" {
+		return true
+	}
+
+	return r.level() >= required.level()
+}
+
+// RoleResolver returns the role of the user of the session
+type RoleResolver func(session Session) Role
+
+type AuditResult string
+
+const (
+	AuditResultOK     AuditResult = "ok"
+	AuditResultError  AuditResult = "error"
+	AuditResultDenied AuditResult = "denied"
+)
+
+// AuditEntry records a private command (or a step of the command) run by the user
+type AuditEntry struct {
+	Time    time.Time
+	Source  string
+	User    string
+	Role    Role
+	Command string
+	Args    []string
+	Result  AuditResult
+	Error   string
+}
+
+// AuditLogger records the audit entries of the private commands
+type AuditLogger interface {
+	LogCommand(entry AuditEntry)
+}
+
+// SessionSource returns the messenger name of the session
+func SessionSource(session Session) string {
+	switch session.(type) {
+	case *TelegramSession:
+		return "telegram"
+	case *SlackSession:
+		return "slack"
+	}
+
+	return "unknown"
+}
+
+// SessionUser returns the user of the messenger session, the telegram username (or user id) or the slack user id
+func SessionUser(session Session) string {
+	switch s := session.(type) {
+	case *TelegramSession:
+		if s.User != nil {
+			if s.User.Username != "" {
+				return s.User.Username
+			}
+
+			return strconv.FormatInt(s.User.ID, 10)
+		}
+
+	case *SlackSession:
+		return s.UserID
+	}
+
+	return session.ID()
+}
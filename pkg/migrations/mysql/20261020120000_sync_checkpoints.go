@@ -0,0 +1,34 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upSyncCheckpoints, downSyncCheckpoints)
+
+}
+
+func upSyncCheckpoints(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `sync_checkpoints`\n(\n    `gid`        BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n\n    `exchange`   VARCHAR(24)     NOT NULL DEFAULT '',\n\n    `symbol`     VARCHAR(32)     NOT NULL DEFAULT '',\n\n    -- type is the record type of the checkpoint, e.g., trades, orders\n    `type`       VARCHAR(16)     NOT NULL,\n\n    -- start_time and end_time are the time range of the remote records that are completely synced\n    `start_time` DATETIME(3)     NOT NULL,\n\n    `end_time`   DATETIME(3)     NOT NULL,\n\n    `updated_at` DATETIME(3)     NOT NULL,\n\n    PRIMARY KEY (`gid`),\n    UNIQUE KEY `sync_checkpoint` (`exchange`, `symbol`, `type`)\n);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downSyncCheckpoints(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `sync_checkpoints`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,34 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upEquitySnapshots, downEquitySnapshots)
+
+}
+
+func upEquitySnapshots(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `equity_snapshots`\n(\n    `gid`                  BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n\n    `strategy`             VARCHAR(32)     NOT NULL,\n\n    `strategy_instance_id` VARCHAR(64)     NOT NULL,\n\n    `symbol`               VARCHAR(20)     NOT NULL,\n\n    -- base is the position base quantity at the snapshot time\n    `base`                 DECIMAL(16, 8)  NOT NULL,\n\n    `average_cost`         DECIMAL(16, 8)  NOT NULL,\n\n    -- mid_price is the price the position is marked against\n    `mid_price`            DECIMAL(16, 8)  NOT NULL,\n\n    -- realized_pnl is the accumulated profit of the position\n    `realized_pnl`         DECIMAL(16, 8)  NOT NULL,\n\n    `unrealized_pnl`       DECIMAL(16, 8)  NOT NULL,\n\n    -- equity is the realized pnl plus the unrealized pnl, in the quote currency\n    `equity`               DECIMAL(16, 8)  NOT NULL,\n\n    `time`                 DATETIME(3)     NOT NULL,\n\n    PRIMARY KEY (`gid`),\n    INDEX `equity_snapshots_instance_time` (`strategy_instance_id`, `symbol`, `time`)\n);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downEquitySnapshots(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `equity_snapshots`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,34 @@
+package mysql
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upCommandAuditLogs, downCommandAuditLogs)
+
+}
+
+func upCommandAuditLogs(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `command_audit_logs`\n(\n    `gid`      BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,\n\n    -- source is the messenger or the api of the command, e.g., telegram, slack and web\n    `source`   VARCHAR(16)     NOT NULL,\n\n    `username` VARCHAR(128)    NOT NULL,\n\n    `role`     VARCHAR(16)     NOT NULL DEFAULT '',\n\n    `command`  VARCHAR(64)     NOT NULL,\n\n    `args`     VARCHAR(255)    NOT NULL DEFAULT '',\n\n    -- result is one of ok, error and denied\n    `result`   VARCHAR(16)     NOT NULL,\n\n    `error`    TEXT            NULL,\n\n    `time`     DATETIME(3)     NOT NULL,\n\n    PRIMARY KEY (`gid`),\n    INDEX `command_audit_logs_time` (`time`),\n    INDEX `command_audit_logs_username_time` (`username`, `time`)\n);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downCommandAuditLogs(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `command_audit_logs`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
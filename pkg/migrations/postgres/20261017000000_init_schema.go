@@ -0,0 +1,284 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upInitSchema, downInitSchema)
+
+}
+
+func upInitSchema(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE trades\n(\n    gid            BIGSERIAL PRIMARY KEY,\n    id             BIGINT,\n    order_id       BIGINT,\n    exchange       VARCHAR(24)     NOT NULL DEFAULT '',\n    symbol         VARCHAR(32)     NOT NULL,\n    price          NUMERIC(16, 8)  NOT NULL,\n    quantity       NUMERIC(16, 8)  NOT NULL,\n    quote_quantity NUMERIC(16, 8)  NOT NULL,\n    fee            NUMERIC(16, 8)  NOT NULL,\n    fee_currency   VARCHAR(16)     NOT NULL,\n    is_buyer       BOOLEAN         NOT NULL DEFAULT FALSE,\n    is_maker       BOOLEAN         NOT NULL DEFAULT FALSE,\n    side           VARCHAR(4)      NOT NULL DEFAULT '',\n    traded_at      TIMESTAMP(3)    NOT NULL,\n    is_margin      BOOLEAN         NOT NULL DEFAULT FALSE,\n    is_isolated    BOOLEAN         NOT NULL DEFAULT FALSE,\n    is_futures     BOOLEAN         NOT NULL DEFAULT FALSE,\n    strategy       VARCHAR(32)     NULL,\n    pnl            NUMERIC         NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX trade_unique_id ON trades (exchange, symbol, side, id);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX trades_price_quantity ON trades (order_id, price, quantity);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX trades_traded_at ON trades (traded_at, symbol, exchange, id, fee_currency, fee);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX trades_id_traded_at ON trades (id, traded_at);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX trades_order_id_traded_at ON trades (order_id, traded_at);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE orders\n(\n    gid               BIGSERIAL PRIMARY KEY,\n    exchange          VARCHAR(24)    NOT NULL DEFAULT '',\n    -- order_id is the order id returned from the exchange\n    order_id          BIGINT         NOT NULL,\n    client_order_id   VARCHAR(122)   NOT NULL DEFAULT '',\n    order_type        VARCHAR(16)    NOT NULL,\n    symbol            VARCHAR(32)    NOT NULL,\n    status            VARCHAR(12)    NOT NULL,\n    time_in_force     VARCHAR(4)     NOT NULL,\n    price             NUMERIC(16, 8) NOT NULL,\n    stop_price        NUMERIC(16, 8) NOT NULL,\n    quantity          NUMERIC(16, 8) NOT NULL,\n    executed_quantity NUMERIC(16, 8) NOT NULL DEFAULT 0.0,\n    side              VARCHAR(4)     NOT NULL DEFAULT '',\n    is_working        BOOLEAN        NOT NULL DEFAULT FALSE,\n    is_margin         BOOLEAN        NOT NULL DEFAULT FALSE,\n    is_isolated       BOOLEAN        NOT NULL DEFAULT FALSE,\n    is_futures        BOOLEAN        NOT NULL DEFAULT FALSE,\n    created_at        TIMESTAMP(3)   NOT NULL,\n    updated_at        TIMESTAMP(3)   NOT NULL DEFAULT CURRENT_TIMESTAMP\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX orders_symbol ON orders (exchange, symbol);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX orders_order_id ON orders (order_id, exchange);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE klines\n(\n    gid                    BIGSERIAL PRIMARY KEY,\n    exchange               VARCHAR(10)    NOT NULL,\n    start_time             TIMESTAMP(3)   NOT NULL,\n    end_time               TIMESTAMP(3)   NOT NULL,\n    interval               VARCHAR(3)     NOT NULL,\n    symbol                 VARCHAR(20)    NOT NULL,\n    open                   NUMERIC(20, 8) NOT NULL,\n    high                   NUMERIC(20, 8) NOT NULL,\n    low                    NUMERIC(20, 8) NOT NULL,\n    close                  NUMERIC(20, 8) NOT NULL DEFAULT 0.0,\n    volume                 NUMERIC(20, 8) NOT NULL DEFAULT 0.0,\n    closed                 BOOLEAN        NOT NULL DEFAULT TRUE,\n    last_trade_id          INT            NOT NULL DEFAULT 0,\n    num_trades             INT            NOT NULL DEFAULT 0,\n    quote_volume           NUMERIC(32, 4) NOT NULL DEFAULT 0.0,\n    taker_buy_base_volume  NUMERIC(32, 8) NOT NULL DEFAULT 0.0,\n    taker_buy_quote_volume NUMERIC(32, 4) NOT NULL DEFAULT 0.0\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX klines_end_time_symbol_interval ON klines (end_time, symbol, interval);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE binance_klines (LIKE klines INCLUDING ALL);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX idx_kline_binance_unique ON binance_klines (symbol, interval, start_time);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE max_klines (LIKE klines INCLUDING ALL);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX idx_kline_max_unique ON max_klines (symbol, interval, start_time);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE okex_klines (LIKE klines INCLUDING ALL);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX idx_kline_okex_unique ON okex_klines (symbol, interval, start_time);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE kucoin_klines (LIKE klines INCLUDING ALL);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX idx_kline_kucoin_unique ON kucoin_klines (symbol, interval, start_time);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE ftx_klines (LIKE klines INCLUDING ALL);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX idx_kline_ftx_unique ON ftx_klines (symbol, interval, start_time);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE rewards\n(\n    gid         BIGSERIAL PRIMARY KEY,\n    -- for exchange\n    exchange    VARCHAR(24)    NOT NULL DEFAULT '',\n    -- reward record id\n    uuid        VARCHAR(32)    NOT NULL,\n    reward_type VARCHAR(24)    NOT NULL DEFAULT '',\n    -- currency symbol, BTC, MAX, USDT ... etc\n    currency    VARCHAR(5)     NOT NULL,\n    -- the quantity of the rewards\n    quantity    NUMERIC(16, 8) NOT NULL,\n    state       VARCHAR(5)     NOT NULL,\n    created_at  TIMESTAMP      NOT NULL,\n    spent       BOOLEAN        NOT NULL DEFAULT FALSE,\n    note        TEXT           NULL,\n    UNIQUE (uuid)\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE withdraws\n(\n    gid              BIGSERIAL PRIMARY KEY,\n    exchange         VARCHAR(24)    NOT NULL DEFAULT '',\n    -- asset is the asset name (currency)\n    asset            VARCHAR(10)    NOT NULL,\n    address          VARCHAR(128)   NOT NULL,\n    network          VARCHAR(32)    NOT NULL DEFAULT '',\n    amount           NUMERIC(16, 8) NOT NULL,\n    txn_id           VARCHAR(256)   NOT NULL,\n    txn_fee          NUMERIC(16, 8) NOT NULL DEFAULT 0,\n    txn_fee_currency VARCHAR(32)    NOT NULL DEFAULT '',\n    time             TIMESTAMP(3)   NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX withdraws_txn_id ON withdraws (exchange, txn_id);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE deposits\n(\n    gid      BIGSERIAL PRIMARY KEY,\n    exchange VARCHAR(24)    NOT NULL,\n    -- asset is the asset name (currency)\n    asset    VARCHAR(10)    NOT NULL,\n    address  VARCHAR(128)   NOT NULL DEFAULT '',\n    amount   NUMERIC(16, 8) NOT NULL,\n    txn_id   VARCHAR(256)   NOT NULL,\n    time     TIMESTAMP(3)   NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX deposits_txn_id ON deposits (exchange, txn_id);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE nav_history_details\n(\n    gid              BIGSERIAL PRIMARY KEY,\n    exchange         VARCHAR(30)  NOT NULL DEFAULT '',\n    subaccount       VARCHAR(30)  NOT NULL DEFAULT '',\n    session          VARCHAR(50)  NOT NULL,\n    time             TIMESTAMP(3) NOT NULL,\n    currency         VARCHAR(12)  NOT NULL,\n    balance_in_usd   NUMERIC(32, 8) DEFAULT 0.00000000 NOT NULL,\n    balance_in_btc   NUMERIC(32, 20) DEFAULT 0.00000000 NOT NULL,\n    balance          NUMERIC(32, 20) DEFAULT 0.00000000 NOT NULL,\n    available        NUMERIC(32, 20) DEFAULT 0.00000000 NOT NULL,\n    locked           NUMERIC(32, 20) DEFAULT 0.00000000 NOT NULL,\n    borrowed         NUMERIC(32, 20) DEFAULT 0.00000000 NOT NULL,\n    net_asset        NUMERIC(32, 20) DEFAULT 0.00000000 NOT NULL,\n    net_asset_in_usd NUMERIC(32, 2) DEFAULT 0.00000000 NOT NULL,\n    net_asset_in_btc NUMERIC(32, 20) DEFAULT 0.00000000 NOT NULL,\n    price_in_usd     NUMERIC(32, 8) DEFAULT 0.00000000 NOT NULL,\n    is_margin        BOOLEAN DEFAULT FALSE NOT NULL,\n    is_isolated      BOOLEAN DEFAULT FALSE NOT NULL,\n    isolated_symbol  VARCHAR(30) DEFAULT '' NOT NULL,\n    interest         NUMERIC(32, 20) DEFAULT 0.00000000 NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX idx_nav_history_details ON nav_history_details (time, currency, exchange);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE profits\n(\n    gid                  BIGSERIAL PRIMARY KEY,\n    strategy             VARCHAR(32)    NOT NULL,\n    strategy_instance_id VARCHAR(64)    NOT NULL,\n    symbol               VARCHAR(20)    NOT NULL,\n    -- average_cost is the position average cost\n    average_cost         NUMERIC(16, 8) NOT NULL,\n    -- profit is the pnl (profit and loss)\n    profit               NUMERIC(16, 8) NOT NULL,\n    -- net_profit is the pnl (profit and loss)\n    net_profit           NUMERIC(16, 8) NOT NULL,\n    -- profit_margin is the pnl (profit and loss)\n    profit_margin        NUMERIC(16, 8) NOT NULL,\n    -- net_profit_margin is the pnl (profit and loss)\n    net_profit_margin    NUMERIC(16, 8) NOT NULL,\n    quote_currency       VARCHAR(10)    NOT NULL,\n    base_currency        VARCHAR(16)    NOT NULL,\n    -- embedded trade data --\n    exchange             VARCHAR(24)    NOT NULL DEFAULT '',\n    is_futures           BOOLEAN        NOT NULL DEFAULT FALSE,\n    is_margin            BOOLEAN        NOT NULL DEFAULT FALSE,\n    is_isolated          BOOLEAN        NOT NULL DEFAULT FALSE,\n    trade_id             BIGINT         NOT NULL,\n    -- side is the side of the trade that makes profit\n    side                 VARCHAR(4)     NOT NULL DEFAULT '',\n    is_buyer             BOOLEAN        NOT NULL DEFAULT FALSE,\n    is_maker             BOOLEAN        NOT NULL DEFAULT FALSE,\n    -- price is the price of the trade that makes profit\n    price                NUMERIC(16, 8) NOT NULL,\n    -- quantity is the quantity of the trade that makes profit\n    quantity             NUMERIC(16, 8) NOT NULL,\n    -- quote_quantity is the quote quantity of the trade that makes profit\n    quote_quantity       NUMERIC(16, 8) NOT NULL,\n    traded_at            TIMESTAMP(3)   NOT NULL,\n    -- fee\n    fee_in_usd           NUMERIC(16, 8),\n    fee                  NUMERIC(16, 8) NOT NULL,\n    fee_currency         VARCHAR(16)    NOT NULL,\n    UNIQUE (trade_id)\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE positions\n(\n    gid                  BIGSERIAL PRIMARY KEY,\n    strategy             VARCHAR(32)    NOT NULL,\n    strategy_instance_id VARCHAR(64)    NOT NULL,\n    symbol               VARCHAR(20)    NOT NULL,\n    quote_currency       VARCHAR(10)    NOT NULL,\n    base_currency        VARCHAR(16)    NOT NULL,\n    -- average_cost is the position average cost\n    average_cost         NUMERIC(16, 8) NOT NULL,\n    base                 NUMERIC(16, 8) NOT NULL,\n    quote                NUMERIC(16, 8) NOT NULL,\n    profit               NUMERIC(16, 8) NULL,\n    -- trade related columns\n    trade_id             BIGINT         NOT NULL, -- the trade id in the exchange\n    side                 VARCHAR(4)     NOT NULL, -- side of the trade\n    exchange             VARCHAR(12)    NOT NULL, -- exchange of the trade\n    traded_at            TIMESTAMP(3)   NOT NULL, -- millisecond timestamp\n    UNIQUE (trade_id, side, exchange)\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE margin_loans\n(\n    gid             BIGSERIAL PRIMARY KEY,\n    transaction_id  BIGINT         NOT NULL,\n    exchange        VARCHAR(24)    NOT NULL DEFAULT '',\n    asset           VARCHAR(24)    NOT NULL DEFAULT '',\n    isolated_symbol VARCHAR(24)    NOT NULL DEFAULT '',\n    -- principle is the amount of the loan\n    principle       NUMERIC(16, 8) NOT NULL,\n    time            TIMESTAMP(3)   NOT NULL,\n    UNIQUE (transaction_id)\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE margin_repays\n(\n    gid             BIGSERIAL PRIMARY KEY,\n    transaction_id  BIGINT         NOT NULL,\n    exchange        VARCHAR(24)    NOT NULL DEFAULT '',\n    asset           VARCHAR(24)    NOT NULL DEFAULT '',\n    isolated_symbol VARCHAR(24)    NOT NULL DEFAULT '',\n    -- principle is the amount of the repay\n    principle       NUMERIC(16, 8) NOT NULL,\n    time            TIMESTAMP(3)   NOT NULL,\n    UNIQUE (transaction_id)\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE margin_interests\n(\n    gid             BIGSERIAL PRIMARY KEY,\n    exchange        VARCHAR(24)     NOT NULL DEFAULT '',\n    asset           VARCHAR(24)     NOT NULL DEFAULT '',\n    isolated_symbol VARCHAR(24)     NOT NULL DEFAULT '',\n    principle       NUMERIC(16, 8)  NOT NULL,\n    interest        NUMERIC(20, 16) NOT NULL,\n    interest_rate   NUMERIC(20, 16) NOT NULL,\n    time            TIMESTAMP(3)    NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE margin_liquidations\n(\n    gid               BIGSERIAL PRIMARY KEY,\n    exchange          VARCHAR(24)    NOT NULL DEFAULT '',\n    symbol            VARCHAR(24)    NOT NULL DEFAULT '',\n    order_id          BIGINT         NOT NULL,\n    is_isolated       BOOLEAN        NOT NULL DEFAULT false,\n    average_price     NUMERIC(16, 8) NOT NULL,\n    price             NUMERIC(16, 8) NOT NULL,\n    quantity          NUMERIC(16, 8) NOT NULL,\n    executed_quantity NUMERIC(16, 8) NOT NULL,\n    side              VARCHAR(5)     NOT NULL DEFAULT '',\n    time_in_force     VARCHAR(5)     NOT NULL DEFAULT '',\n    time              TIMESTAMP(3)   NOT NULL,\n    UNIQUE (order_id, exchange)\n);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downInitSchema(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS margin_liquidations;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS margin_interests;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS margin_repays;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS margin_loans;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS positions;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS profits;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS nav_history_details;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS deposits;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS withdraws;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS rewards;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS ftx_klines;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS kucoin_klines;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS okex_klines;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS max_klines;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS binance_klines;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS klines;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS orders;")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS trades;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
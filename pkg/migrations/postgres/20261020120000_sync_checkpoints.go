@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upSyncCheckpoints, downSyncCheckpoints)
+
+}
+
+func upSyncCheckpoints(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE sync_checkpoints\n(\n    gid        BIGSERIAL PRIMARY KEY,\n    exchange   VARCHAR(24)  NOT NULL DEFAULT '',\n    symbol     VARCHAR(32)  NOT NULL DEFAULT '',\n    -- type is the record type of the checkpoint, e.g., trades, orders\n    type       VARCHAR(16)  NOT NULL,\n    -- start_time and end_time are the time range of the remote records that are completely synced\n    start_time TIMESTAMP(3) NOT NULL,\n    end_time   TIMESTAMP(3) NOT NULL,\n    updated_at TIMESTAMP(3) NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE UNIQUE INDEX sync_checkpoint ON sync_checkpoints (exchange, symbol, type);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downSyncCheckpoints(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS sync_checkpoints;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upEquitySnapshots, downEquitySnapshots)
+
+}
+
+func upEquitySnapshots(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE equity_snapshots\n(\n    gid                  BIGSERIAL PRIMARY KEY,\n    strategy             VARCHAR(32)    NOT NULL,\n    strategy_instance_id VARCHAR(64)    NOT NULL,\n    symbol               VARCHAR(20)    NOT NULL,\n    -- base is the position base quantity at the snapshot time\n    base                 NUMERIC(16, 8) NOT NULL,\n    average_cost         NUMERIC(16, 8) NOT NULL,\n    -- mid_price is the price the position is marked against\n    mid_price            NUMERIC(16, 8) NOT NULL,\n    -- realized_pnl is the accumulated profit of the position\n    realized_pnl         NUMERIC(16, 8) NOT NULL,\n    unrealized_pnl       NUMERIC(16, 8) NOT NULL,\n    -- equity is the realized pnl plus the unrealized pnl, in the quote currency\n    equity               NUMERIC(16, 8) NOT NULL,\n    time                 TIMESTAMP(3)   NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX equity_snapshots_instance_time ON equity_snapshots (strategy_instance_id, symbol, time);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downEquitySnapshots(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS equity_snapshots;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
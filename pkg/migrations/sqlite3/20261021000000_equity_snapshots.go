@@ -0,0 +1,39 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upEquitySnapshots, downEquitySnapshots)
+
+}
+
+func upEquitySnapshots(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `equity_snapshots`\n(\n    `gid`                  INTEGER PRIMARY KEY AUTOINCREMENT,\n    `strategy`             VARCHAR(32)    NOT NULL,\n    `strategy_instance_id` VARCHAR(64)    NOT NULL,\n    `symbol`               VARCHAR(20)    NOT NULL,\n    `base`                 DECIMAL(16, 8) NOT NULL,\n    `average_cost`         DECIMAL(16, 8) NOT NULL,\n    `mid_price`            DECIMAL(16, 8) NOT NULL,\n    `realized_pnl`         DECIMAL(16, 8) NOT NULL,\n    `unrealized_pnl`       DECIMAL(16, 8) NOT NULL,\n    `equity`               DECIMAL(16, 8) NOT NULL,\n    `time`                 DATETIME(3)    NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX `equity_snapshots_instance_time` ON `equity_snapshots` (`strategy_instance_id`, `symbol`, `time`);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downEquitySnapshots(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `equity_snapshots`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
@@ -0,0 +1,44 @@
+package sqlite3
+
+import (
+	"context"
+
+	"github.com/c9s/rockhopper"
+)
+
+func init() {
+	AddMigration(upCommandAuditLogs, downCommandAuditLogs)
+
+}
+
+func upCommandAuditLogs(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is applied.
+
+	_, err = tx.ExecContext(ctx, "CREATE TABLE `command_audit_logs`\n(\n    `gid`      INTEGER PRIMARY KEY AUTOINCREMENT,\n    `source`   VARCHAR(16)  NOT NULL,\n    `username` VARCHAR(128) NOT NULL,\n    `role`     VARCHAR(16)  NOT NULL DEFAULT '',\n    `command`  VARCHAR(64)  NOT NULL,\n    `args`     VARCHAR(255) NOT NULL DEFAULT '',\n    `result`   VARCHAR(16)  NOT NULL,\n    `error`    TEXT         NULL,\n    `time`     DATETIME(3)  NOT NULL\n);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX `command_audit_logs_time` ON `command_audit_logs` (`time`);")
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, "CREATE INDEX `command_audit_logs_username_time` ON `command_audit_logs` (`username`, `time`);")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
+
+func downCommandAuditLogs(ctx context.Context, tx rockhopper.SQLExecutor) (err error) {
+	// This code is executed when the migration is rolled back.
+
+	_, err = tx.ExecContext(ctx, "DROP TABLE IF EXISTS `command_audit_logs`;")
+	if err != nil {
+		return err
+	}
+
+	return err
+}
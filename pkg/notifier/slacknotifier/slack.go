@@ -19,6 +19,9 @@ var limiter = rate.NewLimiter(rate.Every(1*time.Second), 1)
 type notifyTask struct {
 	Channel string
 	Opts    []slack.MsgOption
+
+	// photoBuffer is the PNG image uploaded to the channel
+	photoBuffer *bytes.Buffer
 }
 
 type slackAttachmentCreator interface {
@@ -59,6 +62,22 @@ func (n *Notifier) worker() {
 
 		case task := <-n.taskC:
 			limiter.Wait(ctx)
+
+			if task.photoBuffer != nil {
+				_, err := n.client.UploadFileContext(ctx, slack.FileUploadParameters{
+					Reader:   bytes.NewReader(task.photoBuffer.Bytes()),
+					Filetype: "png",
+					Filename: "chart.png",
+					Channels: []string{task.Channel},
+				})
+				if err != nil {
+					log.WithError(err).
+						WithField("channel", task.Channel).
+						Errorf("slack file upload error: %s", err.Error())
+				}
+				continue
+			}
+
 			_, _, err := n.client.PostMessageContext(ctx, task.Channel, task.Opts...)
 			if err != nil {
 				log.WithError(err).
@@ -156,5 +175,16 @@ func (n *Notifier) SendPhoto(buffer *bytes.Buffer) {
 }
 
 func (n *Notifier) SendPhotoTo(channel string, buffer *bytes.Buffer) {
-	// TODO
+	if channel == "" {
+		channel = n.channel
+	}
+
+	select {
+	case n.taskC <- notifyTask{
+		Channel:     channel,
+		photoBuffer: buffer,
+	}:
+	case <-time.After(50 * time.Millisecond):
+		return
+	}
 }
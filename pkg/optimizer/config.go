@@ -38,13 +38,25 @@ type ExecutorConfig struct {
 	LocalExecutorConfig *LocalExecutorConfig `json:"local" yaml:"local"`
 }
 
+// ObjectiveConfig is one of the objectives of the multi-objective optimization
+type ObjectiveConfig struct {
+	Metric string `json:"metric" yaml:"metric"`
+
+	// Direction is either "maximize" or "minimize", defaults to the natural direction of the metric
+	Direction string `json:"direction" yaml:"direction,omitempty"`
+
+	// Weight is the weight of the metric in the scalarized value that the search algorithm optimizes, defaults to 1
+	Weight float64 `json:"weight" yaml:"weight,omitempty"`
+}
+
 type Config struct {
-	Executor      *ExecutorConfig  `json:"executor" yaml:"executor"`
-	MaxThread     int              `yaml:"maxThread,omitempty"`
-	Matrix        []SelectorConfig `yaml:"matrix"`
-	Algorithm     string           `yaml:"algorithm,omitempty"`
-	Objective     string           `yaml:"objectiveBy,omitempty"`
-	MaxEvaluation int              `yaml:"maxEvaluation"`
+	Executor      *ExecutorConfig   `json:"executor" yaml:"executor"`
+	MaxThread     int               `yaml:"maxThread,omitempty"`
+	Matrix        []SelectorConfig  `yaml:"matrix"`
+	Algorithm     string            `yaml:"algorithm,omitempty"`
+	Objective     string            `yaml:"objectiveBy,omitempty"`
+	Objectives    []ObjectiveConfig `yaml:"objectives,omitempty"`
+	MaxEvaluation int               `yaml:"maxEvaluation"`
 }
 
 var defaultExecutorConfig = &ExecutorConfig{
@@ -79,10 +91,35 @@ func LoadConfig(yamlConfigFileName string) (*Config, error) {
 	switch objective := strings.ToLower(optConfig.Objective); objective {
 	case "", "default":
 		optConfig.Objective = HpOptimizerObjectiveEquity
-	case HpOptimizerObjectiveEquity, HpOptimizerObjectiveProfit, HpOptimizerObjectiveVolume, HpOptimizerObjectiveProfitFactor:
-		optConfig.Objective = objective
 	default:
-		return nil, fmt.Errorf(`unknown objective "%s"`, optConfig.Objective)
+		if _, ok := objectiveMetricValueFuncs[objective]; !ok {
+			return nil, fmt.Errorf(`unknown objective "%s"`, optConfig.Objective)
+		}
+		optConfig.Objective = objective
+	}
+
+	for i, objective := range optConfig.Objectives {
+		metric := strings.ToLower(objective.Metric)
+		if _, ok := objectiveMetricValueFuncs[metric]; !ok {
+			return nil, fmt.Errorf(`unknown objective metric "%s"`, objective.Metric)
+		}
+
+		direction := strings.ToLower(objective.Direction)
+		switch direction {
+		case "":
+			direction = objectiveDefaultDirections[metric]
+		case HpOptimizerDirectionMaximize, HpOptimizerDirectionMinimize:
+		default:
+			return nil, fmt.Errorf(`unknown objective direction "%s" of metric "%s"`, objective.Direction, objective.Metric)
+		}
+
+		if objective.Weight == 0 {
+			objective.Weight = 1
+		} else if objective.Weight < 0 {
+			return nil, fmt.Errorf(`objective weight of metric "%s" must be positive`, objective.Metric)
+		}
+
+		optConfig.Objectives[i] = ObjectiveConfig{Metric: metric, Direction: direction, Weight: objective.Weight}
 	}
 
 	if optConfig.MaxEvaluation <= 0 {
@@ -53,6 +53,24 @@ var ProfitFactorMetricValueFunc = func(summaryReport *backtest.SummaryReport) fl
 	return pf*0.9 + win*0.1
 }
 
+var MaxDrawdownMetricValueFunc = func(summaryReport *backtest.SummaryReport) float64 {
+	var maxDrawdown float64
+	for _, report := range summaryReport.SymbolReports {
+		if v := report.MaxDrawdown.Float64(); v > maxDrawdown {
+			maxDrawdown = v
+		}
+	}
+	return maxDrawdown
+}
+
+var InventoryVarianceMetricValueFunc = func(summaryReport *backtest.SummaryReport) float64 {
+	var variance float64
+	for _, report := range summaryReport.SymbolReports {
+		variance += report.InventoryVariance.Float64()
+	}
+	return variance
+}
+
 type Metric struct {
 	// Labels is the labels of the given parameters
 	Labels []string `json:"labels,omitempty"`
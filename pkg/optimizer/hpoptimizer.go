@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"strconv"
 	"sync"
 
 	"github.com/c-bata/goptuna"
@@ -26,8 +27,35 @@ const (
 	HpOptimizerObjectiveVolume = "volume"
 	// HpOptimizerObjectiveProfitFactor optimize the parameters to maximize profit factor
 	HpOptimizerObjectiveProfitFactor = "profitfactor"
+	// HpOptimizerObjectiveMaxDrawdown optimize the parameters to minimize the max drawdown
+	HpOptimizerObjectiveMaxDrawdown = "maxdrawdown"
+	// HpOptimizerObjectiveInventoryVariance optimize the parameters to minimize the variance of the base asset position
+	HpOptimizerObjectiveInventoryVariance = "inventoryvariance"
 )
 
+const (
+	HpOptimizerDirectionMaximize = "maximize"
+	HpOptimizerDirectionMinimize = "minimize"
+)
+
+var objectiveMetricValueFuncs = map[string]MetricValueFunc{
+	HpOptimizerObjectiveEquity:            TotalEquityDiff,
+	HpOptimizerObjectiveProfit:            TotalProfitMetricValueFunc,
+	HpOptimizerObjectiveVolume:            TotalVolume,
+	HpOptimizerObjectiveProfitFactor:      ProfitFactorMetricValueFunc,
+	HpOptimizerObjectiveMaxDrawdown:       MaxDrawdownMetricValueFunc,
+	HpOptimizerObjectiveInventoryVariance: InventoryVarianceMetricValueFunc,
+}
+
+var objectiveDefaultDirections = map[string]string{
+	HpOptimizerObjectiveEquity:            HpOptimizerDirectionMaximize,
+	HpOptimizerObjectiveProfit:            HpOptimizerDirectionMaximize,
+	HpOptimizerObjectiveVolume:            HpOptimizerDirectionMaximize,
+	HpOptimizerObjectiveProfitFactor:      HpOptimizerDirectionMaximize,
+	HpOptimizerObjectiveMaxDrawdown:       HpOptimizerDirectionMinimize,
+	HpOptimizerObjectiveInventoryVariance: HpOptimizerDirectionMinimize,
+}
+
 const (
 	// HpOptimizerAlgorithmTPE is the implementation of Tree-structured Parzen Estimators
 	HpOptimizerAlgorithmTPE = "tpe"
@@ -44,14 +72,21 @@ type HyperparameterOptimizeTrialResult struct {
 	Parameters map[string]interface{} `json:"parameters"`
 	ID         *int                   `json:"id,omitempty"`
 	State      string                 `json:"state,omitempty"`
+
+	// Metrics is the metric vector of the objectives, only available in the multi-objective optimization
+	Metrics map[string]fixedpoint.Value `json:"metrics,omitempty"`
 }
 
 type HyperparameterOptimizeReport struct {
 	Name       string                               `json:"studyName"`
 	Objective  string                               `json:"objective"`
+	Objectives []ObjectiveConfig                    `json:"objectives,omitempty"`
 	Parameters map[string]string                    `json:"domains"`
 	Best       *HyperparameterOptimizeTrialResult   `json:"best"`
 	Trials     []*HyperparameterOptimizeTrialResult `json:"trials,omitempty"`
+
+	// ParetoFront is the trials that are not dominated by any other trial, only available in the multi-objective optimization
+	ParetoFront []*HyperparameterOptimizeTrialResult `json:"paretoFront,omitempty"`
 }
 
 func buildBestHyperparameterOptimizeResult(study *goptuna.Study) *HyperparameterOptimizeTrialResult {
@@ -72,12 +107,65 @@ func buildHyperparameterOptimizeTrialResults(study *goptuna.Study) []*Hyperparam
 			ID:         &trialId,
 			Value:      fixedpoint.NewFromFloat(trial.Value),
 			Parameters: trial.Params,
+			State:      trial.State.String(),
+		}
+		for metric, attr := range trial.UserAttrs {
+			if val, err := fixedpoint.NewFromString(attr); err == nil {
+				if trialResult.Metrics == nil {
+					trialResult.Metrics = make(map[string]fixedpoint.Value)
+				}
+				trialResult.Metrics[metric] = val
+			}
 		}
 		results[i] = trialResult
 	}
 	return results
 }
 
+// dominates returns true if the metric vector of a is no worse than b in all the objectives and better in at least one
+func dominates(a, b *HyperparameterOptimizeTrialResult, objectives []ObjectiveConfig) bool {
+	better := false
+	for _, objective := range objectives {
+		cmp := a.Metrics[objective.Metric].Compare(b.Metrics[objective.Metric])
+		if objective.Direction == HpOptimizerDirectionMinimize {
+			cmp = -cmp
+		}
+
+		if cmp < 0 {
+			return false
+		} else if cmp > 0 {
+			better = true
+		}
+	}
+	return better
+}
+
+// buildParetoFront filters the completed trials that are not dominated by any other completed trial
+func buildParetoFront(results []*HyperparameterOptimizeTrialResult, objectives []ObjectiveConfig) []*HyperparameterOptimizeTrialResult {
+	var candidates []*HyperparameterOptimizeTrialResult
+	for _, result := range results {
+		if len(result.Metrics) == len(objectives) {
+			candidates = append(candidates, result)
+		}
+	}
+
+	var front []*HyperparameterOptimizeTrialResult
+	for _, candidate := range candidates {
+		dominated := false
+		for _, other := range candidates {
+			if other != candidate && dominates(other, candidate, objectives) {
+				dominated = true
+				break
+			}
+		}
+
+		if !dominated {
+			front = append(front, candidate)
+		}
+	}
+	return front
+}
+
 type HyperparameterOptimizer struct {
 	SessionName string
 	Config      *Config
@@ -91,7 +179,7 @@ func (o *HyperparameterOptimizer) buildStudy(trialFinishChan chan goptuna.Frozen
 	var studyOpts = make([]goptuna.StudyOption, 0, 2)
 
 	// maximum the profit, volume, equity gain, ...etc
-	studyOpts = append(studyOpts, goptuna.StudyOptionDirection(goptuna.StudyDirectionMaximize))
+	studyOpts = append(studyOpts, goptuna.StudyOptionDirection(o.studyDirection()))
 
 	// disable search log and collect trial progress
 	studyOpts = append(studyOpts, goptuna.StudyOptionLogger(nil))
@@ -119,6 +207,15 @@ func (o *HyperparameterOptimizer) buildStudy(trialFinishChan chan goptuna.Frozen
 	return goptuna.CreateStudy(o.SessionName, studyOpts...)
 }
 
+// studyDirection returns the direction of the single objective,
+// the scalarized value of the multiple objectives is always maximized.
+func (o *HyperparameterOptimizer) studyDirection() goptuna.StudyDirection {
+	if len(o.Config.Objectives) == 0 && objectiveDefaultDirections[o.Config.Objective] == HpOptimizerDirectionMinimize {
+		return goptuna.StudyDirectionMinimize
+	}
+	return goptuna.StudyDirectionMaximize
+}
+
 func (o *HyperparameterOptimizer) buildParamDomains() (map[string]string, []paramDomain) {
 	labelPaths := make(map[string]string)
 	domains := make([]paramDomain, 0, len(o.Config.Matrix))
@@ -194,17 +291,7 @@ func (o *HyperparameterOptimizer) buildParamDomains() (map[string]string, []para
 }
 
 func (o *HyperparameterOptimizer) buildObjective(executor Executor, configJson []byte, paramDomains []paramDomain) goptuna.FuncObjective {
-	var metricValueFunc MetricValueFunc
-	switch o.Config.Objective {
-	case HpOptimizerObjectiveProfit:
-		metricValueFunc = TotalProfitMetricValueFunc
-	case HpOptimizerObjectiveVolume:
-		metricValueFunc = TotalVolume
-	case HpOptimizerObjectiveEquity:
-		metricValueFunc = TotalEquityDiff
-	case HpOptimizerObjectiveProfitFactor:
-		metricValueFunc = ProfitFactorMetricValueFunc
-	}
+	metricValueFunc := objectiveMetricValueFuncs[o.Config.Objective]
 
 	return func(trial goptuna.Trial) (float64, error) {
 		trialConfig, err := func(trialConfig []byte) ([]byte, error) {
@@ -230,8 +317,28 @@ func (o *HyperparameterOptimizer) buildObjective(executor Executor, configJson [
 		if err != nil {
 			return 0.0, err
 		}
-		// By config, the Goptuna optimize the parameters by maximize the objective output.
-		return metricValueFunc(summary), nil
+
+		if len(o.Config.Objectives) == 0 {
+			// By config, the Goptuna optimize the parameters by maximize the objective output.
+			return metricValueFunc(summary), nil
+		}
+
+		// The search algorithms only support one objective, so the metrics are scalarized by the weighted sum,
+		// and the metric vector is stored in the trial for building the Pareto front.
+		var value float64
+		for _, objective := range o.Config.Objectives {
+			metricValue := objectiveMetricValueFuncs[objective.Metric](summary)
+			if err := trial.SetUserAttr(objective.Metric, strconv.FormatFloat(metricValue, 'f', -1, 64)); err != nil {
+				return 0.0, err
+			}
+
+			if objective.Direction == HpOptimizerDirectionMinimize {
+				value -= objective.Weight * metricValue
+			} else {
+				value += objective.Weight * metricValue
+			}
+		}
+		return value, nil
 	}
 }
 
@@ -256,13 +363,17 @@ func (o *HyperparameterOptimizer) Run(ctx context.Context, executor Executor, co
 
 	go func() {
 		defer close(allTrailFinishChan)
+		var minimize = o.studyDirection() == goptuna.StudyDirectionMinimize
 		var bestVal = math.Inf(-1)
+		if minimize {
+			bestVal = math.Inf(1)
+		}
 		for result := range trialFinishChan {
 			log.WithFields(logrus.Fields{"ID": result.ID, "evaluation": result.Value, "state": result.State}).Debug("trial finished")
 			if result.State == goptuna.TrialStateFail {
 				log.WithFields(result.Params).Errorf("failed at trial #%d", result.ID)
 			}
-			if result.Value > bestVal {
+			if result.State == goptuna.TrialStateComplete && (minimize && result.Value < bestVal || !minimize && result.Value > bestVal) {
 				bestVal = result.Value
 			}
 			bar.Set("log", fmt.Sprintf("best value: %v", bestVal))
@@ -293,11 +404,18 @@ func (o *HyperparameterOptimizer) Run(ctx context.Context, executor Executor, co
 	<-allTrailFinishChan
 	bar.Finish()
 
-	return &HyperparameterOptimizeReport{
+	report := &HyperparameterOptimizeReport{
 		Name:       o.SessionName,
 		Objective:  o.Config.Objective,
+		Objectives: o.Config.Objectives,
 		Parameters: labelPaths,
 		Best:       buildBestHyperparameterOptimizeResult(study),
 		Trials:     buildHyperparameterOptimizeTrialResults(study),
-	}, nil
+	}
+
+	if len(o.Config.Objectives) > 0 {
+		report.ParetoFront = buildParetoFront(report.Trials, o.Config.Objectives)
+	}
+
+	return report, nil
 }
@@ -199,3 +199,30 @@ func TestBuildParamDomains(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildParetoFront(t *testing.T) {
+	objectives := []ObjectiveConfig{
+		{Metric: HpOptimizerObjectiveProfit, Direction: HpOptimizerDirectionMaximize, Weight: 1},
+		{Metric: HpOptimizerObjectiveMaxDrawdown, Direction: HpOptimizerDirectionMinimize, Weight: 1},
+	}
+
+	newResult := func(profit, drawdown float64) *HyperparameterOptimizeTrialResult {
+		return &HyperparameterOptimizeTrialResult{
+			Metrics: map[string]fixedpoint.Value{
+				HpOptimizerObjectiveProfit:      fixedpoint.NewFromFloat(profit),
+				HpOptimizerObjectiveMaxDrawdown: fixedpoint.NewFromFloat(drawdown),
+			},
+		}
+	}
+
+	highProfit := newResult(100, 0.3)
+	lowDrawdown := newResult(50, 0.1)
+	dominated := newResult(40, 0.2)
+	duplicated := newResult(50, 0.1)
+	failed := &HyperparameterOptimizeTrialResult{}
+
+	front := buildParetoFront([]*HyperparameterOptimizeTrialResult{highProfit, lowDrawdown, dominated, duplicated, failed}, objectives)
+	if !reflect.DeepEqual(front, []*HyperparameterOptimizeTrialResult{highProfit, lowDrawdown, duplicated}) {
+		t.Errorf("unexpected pareto front: %+v", front)
+	}
+}
@@ -0,0 +1,65 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// the get-vanilla case of the AWS signature version 4 test suite
+func TestSignV4(t *testing.T) {
+	req, err := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	require.NoError(t, err)
+
+	signV4(req, nil, "AKIDEXAMPLE", "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY", "us-east-1", "service",
+		time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	assert.Equal(t, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "+
+		"SignedHeaders=host;x-amz-date, "+
+		"Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31", req.Header.Get("Authorization"))
+}
+
+func TestAWSSecretsManagerProvider_Credentials(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.True(t, strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"))
+
+		var payload struct {
+			SecretId string
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&payload))
+
+		if payload.SecretId != "bbgo/okex" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"__type":"ResourceNotFoundException"}`))
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"Name":"bbgo/okex","SecretString":"{\"key\":\"key1\",\"secret\":\"secret1\",\"passphrase\":\"pass1\"}"}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewAWSSecretsManagerProvider(AWSSecretsManagerProviderConfig{
+		Region:   "us-east-1",
+		Endpoint: server.URL,
+	})
+	require.NoError(t, err)
+
+	credentials, err := provider.Credentials(context.Background(), "bbgo/okex")
+	require.NoError(t, err)
+	assert.Equal(t, &Credentials{Key: "key1", Secret: "secret1", Passphrase: "pass1"}, credentials)
+
+	_, err = provider.Credentials(context.Background(), "bbgo/max")
+	assert.ErrorIs(t, err, ErrSecretNotFound)
+}
@@ -0,0 +1,161 @@
+package secrets
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const defaultPasswordEnvVar = "BBGO_SECRETS_PASSWORD"
+
+// scrypt parameters recommended for the interactive logins
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// FileProviderConfig is the config of the encrypted secrets file
+type FileProviderConfig struct {
+	Path string `json:"path" yaml:"path"`
+
+	// PasswordEnvVar is the environment variable of the password, defaults to BBGO_SECRETS_PASSWORD
+	PasswordEnvVar string `json:"passwordEnvVar,omitempty" yaml:"passwordEnvVar,omitempty"`
+}
+
+// encryptedFile is the file format of the encrypted secrets,
+// the plaintext is the JSON object of the credentials keyed by the secret name.
+type encryptedFile struct {
+	Version    int    `json:"version"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// FileProvider reads the credentials from the encrypted secrets file,
+// the file is read on every call so that the rotated file is picked up.
+type FileProvider struct {
+	config FileProviderConfig
+}
+
+func NewFileProvider(config FileProviderConfig) *FileProvider {
+	if config.PasswordEnvVar == "" {
+		config.PasswordEnvVar = defaultPasswordEnvVar
+	}
+
+	return &FileProvider{config: config}
+}
+
+func (p *FileProvider) Credentials(ctx context.Context, name string) (*Credentials, error) {
+	password := os.Getenv(p.config.PasswordEnvVar)
+	if password == "" {
+		return nil, fmt.Errorf("secrets password env var %s is not set", p.config.PasswordEnvVar)
+	}
+
+	data, err := os.ReadFile(p.config.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	all, err := Decrypt(data, password)
+	if err != nil {
+		return nil, err
+	}
+
+	credentials, ok := all[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s in %s", ErrSecretNotFound, name, p.config.Path)
+	}
+
+	return &credentials, nil
+}
+
+func deriveKey(password string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(password), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+}
+
+// Encrypt encrypts the credentials with AES-256-GCM, the key is derived from the password by scrypt
+func Encrypt(credentials map[string]Credentials, password string) ([]byte, error) {
+	plaintext, err := json.Marshal(credentials)
+	if err != nil {
+		return nil, err
+	}
+
+	salt := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(password, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(encryptedFile{
+		Version:    1,
+		Salt:       salt,
+		Nonce:      nonce,
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, nil),
+	}, "", "  ")
+}
+
+// Decrypt decrypts the data created by Encrypt
+func Decrypt(data []byte, password string) (map[string]Credentials, error) {
+	var file encryptedFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid secrets file: %w", err)
+	}
+
+	if file.Version != 1 {
+		return nil, fmt.Errorf("unsupported secrets file version %d", file.Version)
+	}
+
+	gcm, err := newGCM(password, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(file.Nonce) != gcm.NonceSize() {
+		return nil, errors.New("invalid secrets file nonce")
+	}
+
+	plaintext, err := gcm.Open(nil, file.Nonce, file.Ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("can not decrypt the secrets file, the password may be wrong")
+	}
+
+	var credentials map[string]Credentials
+	if err := json.Unmarshal(plaintext, &credentials); err != nil {
+		return nil, err
+	}
+
+	return credentials, nil
+}
+
+func newGCM(password string, salt []byte) (cipher.AEAD, error) {
+	key, err := deriveKey(password, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,77 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	credentials := map[string]Credentials{
+		"binance-main": {Key: "key1", Secret: "secret1"},
+		"okex-main":    {Key: "key2", Secret: "secret2", Passphrase: "pass2"},
+	}
+
+	data, err := Encrypt(credentials, "password")
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "secret1")
+
+	decrypted, err := Decrypt(data, "password")
+	require.NoError(t, err)
+	assert.Equal(t, credentials, decrypted)
+
+	_, err = Decrypt(data, "wrong password")
+	assert.Error(t, err)
+}
+
+func TestFileProvider_Credentials(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.enc")
+	t.Setenv("TEST_SECRETS_PASSWORD", "password")
+
+	data, err := Encrypt(map[string]Credentials{
+		"binance-main": {Key: "key1", Secret: "secret1"},
+	}, "password")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	provider := NewFileProvider(FileProviderConfig{Path: path, PasswordEnvVar: "TEST_SECRETS_PASSWORD"})
+
+	credentials, err := provider.Credentials(context.Background(), "binance-main")
+	require.NoError(t, err)
+	assert.Equal(t, "key1", credentials.Key)
+	assert.Equal(t, "secret1", credentials.Secret)
+
+	_, err = provider.Credentials(context.Background(), "max-main")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+
+	// the rotated file is picked up by the next call
+	data, err = Encrypt(map[string]Credentials{
+		"binance-main": {Key: "key2", Secret: "secret2"},
+	}, "password")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0600))
+
+	credentials, err = provider.Credentials(context.Background(), "binance-main")
+	require.NoError(t, err)
+	assert.Equal(t, "key2", credentials.Key)
+}
+
+func TestNewProvider(t *testing.T) {
+	_, err := NewProvider(Config{})
+	assert.Error(t, err)
+
+	_, err = NewProvider(Config{
+		File:  &FileProviderConfig{Path: "secrets.enc"},
+		Vault: &VaultProviderConfig{Address: "http://127.0.0.1:8200"},
+	})
+	assert.Error(t, err)
+
+	provider, err := NewProvider(Config{File: &FileProviderConfig{Path: "secrets.enc"}})
+	require.NoError(t, err)
+	assert.IsType(t, &FileProvider{}, provider)
+}
@@ -0,0 +1,69 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var ErrSecretNotFound = errors.New("secret not found")
+
+// Credentials are the api credentials of an exchange session
+type Credentials struct {
+	Key        string `json:"key"`
+	Secret     string `json:"secret"`
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+func (c Credentials) Validate() error {
+	if c.Key == "" || c.Secret == "" {
+		return errors.New("empty key or secret")
+	}
+
+	return nil
+}
+
+// Provider loads the credentials by the secret name
+type Provider interface {
+	Credentials(ctx context.Context, name string) (*Credentials, error)
+}
+
+// Config selects the provider of the exchange credentials, only one of the providers should be configured
+type Config struct {
+	File              *FileProviderConfig              `json:"file,omitempty" yaml:"file,omitempty"`
+	Vault             *VaultProviderConfig             `json:"vault,omitempty" yaml:"vault,omitempty"`
+	AWSSecretsManager *AWSSecretsManagerProviderConfig `json:"awsSecretsManager,omitempty" yaml:"awsSecretsManager,omitempty"`
+}
+
+// NewProvider creates the provider from the config
+func NewProvider(config Config) (Provider, error) {
+	var providers []Provider
+	if config.File != nil {
+		providers = append(providers, NewFileProvider(*config.File))
+	}
+
+	if config.Vault != nil {
+		provider, err := NewVaultProvider(*config.Vault)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+
+	if config.AWSSecretsManager != nil {
+		provider, err := NewAWSSecretsManagerProvider(*config.AWSSecretsManager)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+
+	switch len(providers) {
+	case 0:
+		return nil, errors.New("no secret provider is configured")
+	case 1:
+		return providers[0], nil
+	default:
+		return nil, fmt.Errorf("only one secret provider can be configured, got %d", len(providers))
+	}
+}
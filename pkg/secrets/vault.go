@@ -0,0 +1,108 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+const defaultVaultMount = "secret"
+
+// VaultProviderConfig is the config of the HashiCorp Vault KV version 2 secrets engine,
+// the secret data should contain the "key", "secret" and the optional "passphrase" fields.
+type VaultProviderConfig struct {
+	// Address is the vault server address, defaults to the VAULT_ADDR env var
+	Address string `json:"address,omitempty" yaml:"address,omitempty"`
+
+	// TokenEnvVar is the environment variable of the vault token, defaults to VAULT_TOKEN
+	TokenEnvVar string `json:"tokenEnvVar,omitempty" yaml:"tokenEnvVar,omitempty"`
+
+	// Mount is the mount path of the KV secrets engine, defaults to "secret"
+	Mount string `json:"mount,omitempty" yaml:"mount,omitempty"`
+
+	Namespace string `json:"namespace,omitempty" yaml:"namespace,omitempty"`
+}
+
+type VaultProvider struct {
+	config VaultProviderConfig
+	client *http.Client
+}
+
+func NewVaultProvider(config VaultProviderConfig) (*VaultProvider, error) {
+	if config.Address == "" {
+		config.Address = os.Getenv("VAULT_ADDR")
+	}
+
+	if config.Address == "" {
+		return nil, fmt.Errorf("vault address is not set")
+	}
+
+	if config.TokenEnvVar == "" {
+		config.TokenEnvVar = "VAULT_TOKEN"
+	}
+
+	if config.Mount == "" {
+		config.Mount = defaultVaultMount
+	}
+
+	return &VaultProvider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (p *VaultProvider) Credentials(ctx context.Context, name string) (*Credentials, error) {
+	token := os.Getenv(p.config.TokenEnvVar)
+	if token == "" {
+		return nil, fmt.Errorf("vault token env var %s is not set", p.config.TokenEnvVar)
+	}
+
+	u, err := url.Parse(strings.TrimSuffix(p.config.Address, "/") + "/v1/" +
+		strings.Trim(p.config.Mount, "/") + "/data/" + strings.TrimPrefix(name, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("X-Vault-Token", token)
+	if p.config.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.config.Namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %s in vault", ErrSecretNotFound, name)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("vault response error %d: %s", resp.StatusCode, body)
+	}
+
+	var apiResponse struct {
+		Data struct {
+			Data Credentials `json:"data"`
+		} `json:"data"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&apiResponse); err != nil {
+		return nil, err
+	}
+
+	return &apiResponse.Data.Data, nil
+}
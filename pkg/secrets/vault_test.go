@@ -0,0 +1,45 @@
+package secrets
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVaultProvider_Credentials(t *testing.T) {
+	t.Setenv("TEST_VAULT_TOKEN", "token")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+
+		if r.URL.Path != "/v1/kv/data/bbgo/binance" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		_, _ = w.Write([]byte(`{"data":{"data":{"key":"key1","secret":"secret1"},"metadata":{"version":2}}}`))
+	}))
+	defer server.Close()
+
+	provider, err := NewVaultProvider(VaultProviderConfig{
+		Address:     server.URL,
+		TokenEnvVar: "TEST_VAULT_TOKEN",
+		Mount:       "kv",
+	})
+	require.NoError(t, err)
+
+	credentials, err := provider.Credentials(context.Background(), "bbgo/binance")
+	require.NoError(t, err)
+	assert.Equal(t, &Credentials{Key: "key1", Secret: "secret1"}, credentials)
+
+	_, err = provider.Credentials(context.Background(), "bbgo/max")
+	assert.True(t, errors.Is(err, ErrSecretNotFound))
+}
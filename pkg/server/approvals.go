@@ -0,0 +1,111 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type closePositionRequest struct {
+	Percentage fixedpoint.Value `json:"percentage"`
+}
+
+type approvalRequest struct {
+	Code string `json:"code"`
+}
+
+func (s *Server) closeStrategyInstancePosition(c *gin.Context) {
+	instance, ok := s.lookupStrategyInstance(c)
+	if !ok {
+		return
+	}
+
+	var req closePositionRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.Percentage.Sign() <= 0 || req.Percentage.Compare(fixedpoint.One) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "percentage must be in (0, 1]"})
+		return
+	}
+
+	approvals, ok := s.lookupApprovalManager(c)
+	if !ok {
+		return
+	}
+
+	description := fmt.Sprintf("Close %s of the position of %s", req.Percentage.Percentage(), instance.ID)
+	request := approvals.Request("web", principal(c), "closeposition", description, func(ctx context.Context) error {
+		return instance.ClosePosition(ctx, req.Percentage)
+	})
+
+	c.JSON(http.StatusAccepted, gin.H{"approval": request})
+}
+
+func (s *Server) listApprovals(c *gin.Context) {
+	approvals, ok := s.lookupApprovalManager(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pending":  approvals.Pending(),
+		"auditLog": approvals.AuditLog(),
+	})
+}
+
+func (s *Server) approveApproval(c *gin.Context) {
+	approvals, ok := s.lookupApprovalManager(c)
+	if !ok {
+		return
+	}
+
+	var req approvalRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := approvals.Approve(c, c.Param("id"), principal(c), req.Code); err != nil {
+		if errors.Is(err, bbgo.ErrApprovalNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (s *Server) rejectApproval(c *gin.Context) {
+	approvals, ok := s.lookupApprovalManager(c)
+	if !ok {
+		return
+	}
+
+	if err := approvals.Reject(c.Param("id"), principal(c)); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (s *Server) lookupApprovalManager(c *gin.Context) (*bbgo.ApprovalManager, bool) {
+	approvals := s.Environ.ApprovalManager()
+	if approvals == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "remote command approval is not enabled"})
+		return nil, false
+	}
+
+	return approvals, true
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/c9s/bbgo/pkg/interact"
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+// principalKey is the gin context key of the user authenticated by the api token
+const principalKey = "bbgo.principal"
+
+// authorizationEnabled returns true if the roles of the web api users are checked
+func (s *Server) authorizationEnabled() bool {
+	authorizer := s.Environ.CommandAuthorizer()
+	return authorizer != nil && authorizer.Enabled()
+}
+
+// principal returns the user authenticated by requireRole, it's empty if the authorization is not enabled
+func principal(c *gin.Context) string {
+	return c.GetString(principalKey)
+}
+
+func bearerToken(c *gin.Context) string {
+	header := c.GetHeader("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+
+	return ""
+}
+
+// requireRole authenticates the user by the bearer token, checks the role of the user
+// and records the runtime command in the audit log
+func (s *Server) requireRole(role interact.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !s.authorizationEnabled() {
+			c.Next()
+			return
+		}
+
+		authorizer := s.Environ.CommandAuthorizer()
+		user, authenticated := authorizer.Authenticate(bearerToken(c))
+		entry := interact.AuditEntry{
+			Time:    time.Now(),
+			Source:  "web",
+			User:    user,
+			Command: c.Request.Method + " " + c.FullPath(),
+			Result:  interact.AuditResultOK,
+		}
+
+		for _, param := range c.Params {
+			entry.Args = append(entry.Args, param.Value)
+		}
+
+		if !authenticated {
+			entry.Result = interact.AuditResultDenied
+			entry.Error = "invalid api token"
+			authorizer.LogCommand(entry)
+
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "invalid api token"})
+			return
+		}
+
+		userRole, err := authorizer.Authorize(user, role)
+		entry.Role = userRole
+		if err != nil {
+			entry.Result = interact.AuditResultDenied
+			entry.Error = err.Error()
+			authorizer.LogCommand(entry)
+
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(principalKey, user)
+		c.Next()
+
+		if status := c.Writer.Status(); status >= http.StatusBadRequest {
+			entry.Result = interact.AuditResultError
+			entry.Error = http.StatusText(status)
+		}
+
+		authorizer.LogCommand(entry)
+	}
+}
+
+func (s *Server) listCommandAuditLogs(c *gin.Context) {
+	if s.Environ.CommandAuditService == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "database is not configured"})
+		return
+	}
+
+	options := service.QueryCommandAuditLogsOptions{
+		Username: c.Query("username"),
+		Command:  c.Query("command"),
+		Limit:    100,
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.ParseUint(limit, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit: " + err.Error()})
+			return
+		}
+
+		options.Limit = n
+	}
+
+	logs, err := s.Environ.CommandAuditService.Query(c, options)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"logs": logs})
+}
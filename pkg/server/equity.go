@@ -0,0 +1,55 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/c9s/bbgo/pkg/service"
+)
+
+// getStrategyInstanceEquity returns the equity curve of the strategy instance from the mark-to-market snapshots,
+// the snapshots are kept after the instance stops, so the instance does not need to be running.
+func (s *Server) getStrategyInstanceEquity(c *gin.Context) {
+	if s.Environ.EquitySnapshotService == nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "database is not configured"})
+		return
+	}
+
+	options := service.QueryEquitySnapshotsOptions{
+		StrategyInstanceID: c.Param("id"),
+		Symbol:             c.Query("symbol"),
+	}
+
+	for name, target := range map[string]**time.Time{"since": &options.Since, "until": &options.Until} {
+		if value := c.Query(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be in the RFC3339 format"})
+				return
+			}
+
+			*target = &t
+		}
+	}
+
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+
+		options.Limit = limit
+	}
+
+	snapshots, err := s.Environ.EquitySnapshotService.Query(c, options)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"snapshots": snapshots})
+}
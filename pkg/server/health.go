@@ -0,0 +1,46 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+// healthz is the liveness probe, the orchestrator should restart the process if it returns 503
+func (s *Server) healthz(c *gin.Context) {
+	monitor := s.Environ.HealthMonitor()
+	if monitor == nil {
+		c.JSON(http.StatusOK, gin.H{"status": bbgo.HealthOK})
+		return
+	}
+
+	writeHealthReport(c, monitor.Liveness(c, time.Now()))
+}
+
+// readyz is the readiness probe, it returns 503 until the strategies are started
+func (s *Server) readyz(c *gin.Context) {
+	monitor := s.Environ.HealthMonitor()
+	if monitor == nil {
+		if s.Trader == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"status": bbgo.HealthFail, "error": "trader is not running"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"status": bbgo.HealthOK})
+		return
+	}
+
+	writeHealthReport(c, monitor.Readiness(c, time.Now()))
+}
+
+func writeHealthReport(c *gin.Context, report *bbgo.HealthReport) {
+	status := http.StatusOK
+	if !report.Healthy() {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.JSON(status, report)
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+type transferPositionRequest struct {
+	// To is the instance ID of the target strategy instance
+	To     string `json:"to"`
+	Symbol string `json:"symbol"`
+
+	// Quantity is the quantity to be moved, the whole position is moved if it's zero
+	Quantity fixedpoint.Value `json:"quantity"`
+}
+
+type importPositionRequest struct {
+	Symbol string `json:"symbol"`
+
+	// Base is the externally held base quantity, it's negative for a short position
+	Base  fixedpoint.Value `json:"base"`
+	Price fixedpoint.Value `json:"price"`
+}
+
+// transferStrategyInstancePosition moves the position of the instance to another strategy instance
+func (s *Server) transferStrategyInstancePosition(c *gin.Context) {
+	from, ok := s.lookupStrategyInstance(c)
+	if !ok {
+		return
+	}
+
+	var req transferPositionRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	to, err := s.Trader.LookupStrategyInstance(req.To)
+	if err != nil {
+		writeStrategyInstanceError(c, err)
+		return
+	}
+
+	transfer, err := bbgo.TransferPosition(from, to, req.Symbol, req.Quantity, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.savePositionTransfer(c, transfer)
+}
+
+// importStrategyInstancePosition adds an externally held position into the position of the instance
+func (s *Server) importStrategyInstancePosition(c *gin.Context) {
+	instance, ok := s.lookupStrategyInstance(c)
+	if !ok {
+		return
+	}
+
+	var req importPositionRequest
+	if err := c.BindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer, err := bbgo.ImportPosition(instance, req.Symbol, req.Base, req.Price, time.Now())
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	s.savePositionTransfer(c, transfer)
+}
+
+// savePositionTransfer records the transfer in the audit log and persists the changed positions
+func (s *Server) savePositionTransfer(c *gin.Context, transfer *bbgo.PositionTransfer) {
+	if authorizer := s.Environ.CommandAuthorizer(); authorizer != nil {
+		user := principal(c)
+		authorizer.LogCommand(transfer.AuditEntry("web", user, authorizer.Role(user)))
+	}
+
+	bbgo.Notify("Position %s", transfer.String())
+
+	if err := s.Trader.SaveState(c); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transfer": transfer})
+}
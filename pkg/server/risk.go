@@ -0,0 +1,30 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getPortfolioRisk returns the last calculated portfolio risk, the risk is calculated on demand with ?refresh=true
+// or when it has not been calculated yet.
+func (s *Server) getPortfolioRisk(c *gin.Context) {
+	service := s.Environ.PortfolioRiskService()
+	if service == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "portfolio risk service is not enabled"})
+		return
+	}
+
+	risk := service.Last()
+	if risk == nil || c.Query("refresh") == "true" {
+		var err error
+		risk, err = service.Calculate(c, time.Now())
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{"risk": risk})
+}
@@ -19,6 +19,7 @@ import (
 
 	"github.com/c9s/bbgo/pkg/bbgo"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/interact"
 	"github.com/c9s/bbgo/pkg/service"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -52,7 +53,7 @@ func (s *Server) newEngine(ctx context.Context) *gin.Engine {
 	r := gin.Default()
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowHeaders:     []string{"Origin", "Content-Type"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
 		ExposeHeaders:    []string{"Content-Length"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE"},
 		AllowWebSockets:  true,
@@ -61,6 +62,8 @@ func (s *Server) newEngine(ctx context.Context) *gin.Engine {
 	}))
 
 	r.GET("/api/ping", s.ping)
+	r.GET("/healthz", s.healthz)
+	r.GET("/readyz", s.readyz)
 
 	if s.Setup != nil {
 		r.POST("/api/setup/test-db", s.setupTestDB)
@@ -230,6 +233,7 @@ func (s *Server) newEngine(ctx context.Context) *gin.Engine {
 	r.GET("/api/sessions/:session/account", s.getSessionAccount)
 	r.GET("/api/sessions/:session/account/balances", s.getSessionAccountBalance)
 	r.GET("/api/sessions/:session/symbols", s.listSessionSymbols)
+	r.GET("/api/sessions/:session/subscriptions", s.listSessionSubscriptions)
 
 	r.GET("/api/sessions/:session/pnl", func(c *gin.Context) {
 		c.JSON(200, gin.H{"message": "pong"})
@@ -248,6 +252,42 @@ func (s *Server) newEngine(ctx context.Context) *gin.Engine {
 	})
 
 	r.GET("/api/strategies/single", s.listStrategies)
+
+	r.GET("/api/strategies/instances", s.listStrategyInstances)
+	r.GET("/api/strategies/instances/:id", s.getStrategyInstance)
+	r.POST("/api/strategies/instances/:id/suspend", s.requireRole(interact.RoleOperator), s.suspendStrategyInstance)
+	r.POST("/api/strategies/instances/:id/resume", s.requireRole(interact.RoleOperator), s.resumeStrategyInstance)
+	r.POST("/api/strategies/instances/:id/requote", s.requireRole(interact.RoleOperator), s.requoteStrategyInstance)
+	r.POST("/api/strategies/instances/:id/stop", s.requireRole(interact.RoleOperator), s.stopStrategyInstance)
+	r.POST("/api/strategies/instances/:id/restart", s.requireRole(interact.RoleOperator), s.restartStrategyInstance)
+	r.POST("/api/strategies/instances/:id/position/transfer", s.requireRole(interact.RoleAdmin), s.transferStrategyInstancePosition)
+	r.POST("/api/strategies/instances/:id/position/import", s.requireRole(interact.RoleAdmin), s.importStrategyInstancePosition)
+	r.GET("/api/strategies/instances/:id/equity", s.getStrategyInstanceEquity)
+	r.GET("/api/strategies/instances/:id/analytics", s.getStrategyInstanceAnalytics)
+	r.GET("/api/strategies/instances/:id/orderbook", s.getStrategyInstanceOrderBook)
+	r.GET("/api/strategies/instances/:id/orderbook/ws", s.streamStrategyInstanceOrderBook)
+
+	r.GET("/api/risk/portfolio", s.getPortfolioRisk)
+
+	r.GET("/api/treasury/report", s.getTreasuryReport)
+	r.POST("/api/treasury/sync", s.requireRole(interact.RoleOperator), s.syncTreasury)
+
+	r.POST("/api/webhook/signals", s.receiveWebhookSignal)
+
+	r.GET("/api/scheduler/jobs", s.listScheduledJobs)
+
+	// closing the position requires the approval of another authenticated user (or the one-time password),
+	// the endpoints are not registered without the authorization and the approval configured.
+	if s.authorizationEnabled() && s.Environ.ApprovalManager() != nil {
+		r.POST("/api/strategies/instances/:id/closeposition", s.requireRole(interact.RoleAdmin), s.closeStrategyInstancePosition)
+
+		r.GET("/api/approvals", s.requireRole(interact.RoleViewer), s.listApprovals)
+		r.POST("/api/approvals/:id/approve", s.requireRole(interact.RoleAdmin), s.approveApproval)
+		r.POST("/api/approvals/:id/reject", s.requireRole(interact.RoleOperator), s.rejectApproval)
+	}
+
+	r.GET("/api/commands/audit", s.requireRole(interact.RoleAdmin), s.listCommandAuditLogs)
+
 	r.NoRoute(s.assetsHandler)
 	return r
 }
@@ -346,6 +386,18 @@ func (s *Server) listSessions(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"session": session})
 }
 
+func (s *Server) listSessionSubscriptions(c *gin.Context) {
+	sessionName := c.Param("session")
+	session, ok := s.Environ.Session(sessionName)
+
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("session %s not found", sessionName)})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscriptions": session.EffectiveSubscriptions()})
+}
+
 func (s *Server) listSessionSymbols(c *gin.Context) {
 	sessionName := c.Param("session")
 	session, ok := s.Environ.Session(sessionName)
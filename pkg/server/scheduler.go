@@ -0,0 +1,15 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// listScheduledJobs returns the cron jobs registered by the strategies
+func (s *Server) listScheduledJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"timeZone": s.Environ.Scheduler().Location().String(),
+		"jobs":     s.Environ.Scheduler().Jobs(),
+	})
+}
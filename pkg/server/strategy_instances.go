@@ -0,0 +1,176 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+func (s *Server) listStrategyInstances(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	instances, err := s.Trader.StrategyInstances()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	states := make([]*bbgo.StrategyInstanceState, 0, len(instances))
+	for _, instance := range instances {
+		state, err := instance.State()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+
+		// keep the list response small, the details are available in the instance endpoint
+		state.Config = nil
+		state.Trades = nil
+		states = append(states, state)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"instances": states})
+}
+
+func (s *Server) getStrategyInstance(c *gin.Context) {
+	instance, ok := s.lookupStrategyInstance(c)
+	if !ok {
+		return
+	}
+
+	state, err := instance.State()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"instance": state})
+}
+
+func (s *Server) suspendStrategyInstance(c *gin.Context) {
+	instance, ok := s.lookupStrategyInstance(c)
+	if !ok {
+		return
+	}
+
+	if err := instance.Suspend(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (s *Server) resumeStrategyInstance(c *gin.Context) {
+	instance, ok := s.lookupStrategyInstance(c)
+	if !ok {
+		return
+	}
+
+	if err := instance.Resume(); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (s *Server) requoteStrategyInstance(c *gin.Context) {
+	instance, ok := s.lookupStrategyInstance(c)
+	if !ok {
+		return
+	}
+
+	if err := instance.Requote(c); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (s *Server) getStrategyInstanceAnalytics(c *gin.Context) {
+	instance, ok := s.lookupStrategyInstance(c)
+	if !ok {
+		return
+	}
+
+	analytics, err := instance.Analytics()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"analytics": analytics})
+}
+
+func (s *Server) stopStrategyInstance(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	if err := s.Trader.StopStrategyInstance(c, c.Param("id")); err != nil {
+		writeStrategyInstanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// restartStrategyInstance restarts the strategy instance, the request body is the strategy config to be changed
+func (s *Server) restartStrategyInstance(c *gin.Context) {
+	if s.Trader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "trader is not running"})
+		return
+	}
+
+	config, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	instance, err := s.Trader.RestartStrategyInstance(c, c.Param("id"), config)
+	if err != nil {
+		writeStrategyInstanceError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true, "id": instance.ID})
+}
+
+func writeStrategyInstanceError(c *gin.Context, err error) {
+	if errors.Is(err, bbgo.ErrStrategyInstanceNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	} else {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
+
+// lookupStrategyInstance finds the strategy instance by the id parameter,
+// it writes the error response and returns false if the instance is not found.
+func (s *Server) lookupStrategyInstance(c *gin.Context) (*bbgo.StrategyInstance, bool) {
+	if s.Trader == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "trader is not running"})
+		return nil, false
+	}
+
+	instance, err := s.Trader.LookupStrategyInstance(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, bbgo.ErrStrategyInstanceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return nil, false
+	}
+
+	return instance, true
+}
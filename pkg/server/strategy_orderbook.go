@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+const (
+	defaultOrderBookStreamInterval = 500 * time.Millisecond
+	minOrderBookStreamInterval     = 100 * time.Millisecond
+	orderBookStreamWriteTimeout    = 5 * time.Second
+)
+
+var orderBookStreamUpgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+	// the web server allows all origins, see the cors config in newEngine
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+func (s *Server) getStrategyInstanceOrderBook(c *gin.Context) {
+	instance, ok := s.lookupStrategyInstance(c)
+	if !ok {
+		return
+	}
+
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	snapshot, err := s.strategyOrderBookSnapshot(instance, depth)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"orderBook": snapshot})
+}
+
+// streamStrategyInstanceOrderBook pushes the order book snapshot with the strategy's own orders
+// through the websocket connection at the given interval.
+func (s *Server) streamStrategyInstanceOrderBook(c *gin.Context) {
+	instance, ok := s.lookupStrategyInstance(c)
+	if !ok {
+		return
+	}
+
+	depth, err := strconv.Atoi(c.DefaultQuery("depth", "0"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	interval := defaultOrderBookStreamInterval
+	if str := c.Query("interval"); len(str) > 0 {
+		interval, err = time.ParseDuration(str)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if interval < minOrderBookStreamInterval {
+			interval = minOrderBookStreamInterval
+		}
+	}
+
+	// check the snapshot before upgrading, so that the error can be responded in the normal http response
+	if _, err := s.strategyOrderBookSnapshot(instance, depth); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	conn, err := orderBookStreamUpgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		logrus.WithError(err).Errorf("websocket upgrade error")
+		return
+	}
+
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(c.Request.Context())
+	defer cancel()
+
+	// read the client messages to handle the close frame, the stream is one-way
+	go func() {
+		defer cancel()
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		snapshot, err := s.strategyOrderBookSnapshot(instance, depth)
+		if err != nil {
+			_ = conn.WriteJSON(gin.H{"error": err.Error()})
+			return
+		}
+
+		_ = conn.SetWriteDeadline(time.Now().Add(orderBookStreamWriteTimeout))
+		if err := conn.WriteJSON(gin.H{"orderBook": snapshot}); err != nil {
+			logrus.WithError(err).Warnf("order book stream of %s is closed", instance.ID)
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) strategyOrderBookSnapshot(instance *bbgo.StrategyInstance, depth int) (*bbgo.StrategyOrderBookSnapshot, error) {
+	session, ok := s.Environ.Session(instance.Session)
+	if !ok {
+		return nil, fmt.Errorf("session %s of strategy %s not found", instance.Session, instance.ID)
+	}
+
+	return instance.OrderBookSnapshot(session, depth)
+}
@@ -0,0 +1,51 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getTreasuryReport returns the reconciliation of the spot balances with the recorded deposits, withdrawals and trades by exchange
+func (s *Server) getTreasuryReport(c *gin.Context) {
+	if s.Environ.TreasuryService == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "database is not configured"})
+		return
+	}
+
+	reports, err := s.Environ.TreasuryReport(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"reports": reports})
+}
+
+// syncTreasury syncs the deposit and the withdraw history of all the exchanges,
+// the history is synced since ?since=(RFC3339 time), defaults to 6 months ago.
+func (s *Server) syncTreasury(c *gin.Context) {
+	if s.Environ.SyncService == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "database is not configured"})
+		return
+	}
+
+	since := time.Now().AddDate(0, -6, 0)
+	if str := c.Query("since"); str != "" {
+		t, err := time.Parse(time.RFC3339, str)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid since: " + err.Error()})
+			return
+		}
+
+		since = t
+	}
+
+	if err := s.Environ.SyncTransferHistory(c, since); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"success": true})
+}
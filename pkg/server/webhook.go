@@ -0,0 +1,49 @@
+package server
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+)
+
+// maxWebhookPayloadSize limits the size of the alert message
+const maxWebhookPayloadSize = 64 * 1024
+
+// receiveWebhookSignal receives the TradingView-style alert, and dispatches the signal event to the subscribed strategies
+func (s *Server) receiveWebhookSignal(c *gin.Context) {
+	if s.Trader == nil || s.Trader.WebhookSignals() == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "webhook signals are not enabled"})
+		return
+	}
+
+	service := s.Trader.WebhookSignals()
+
+	payload, err := ioutil.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookPayloadSize))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	event, err := service.ParseTradingViewAlert(payload, c.GetHeader("X-Webhook-Secret"), time.Now())
+	if err != nil {
+		if errors.Is(err, bbgo.ErrInvalidWebhookSecret) {
+			logrus.Warnf("rejected the webhook signal from %s: %v", c.ClientIP(), err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	delivered := service.Dispatch(*event)
+	logrus.Infof("received %s, delivered to %d subscribers", event.String(), delivered)
+
+	c.JSON(http.StatusOK, gin.H{"signal": event, "delivered": delivered})
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
@@ -20,6 +21,9 @@ import (
 
 type BacktestService struct {
 	DB *sqlx.DB
+
+	// sqliteWriteMutex serializes the batch insertions of the concurrent sync jobs, sqlite only allows one writer
+	sqliteWriteMutex sync.Mutex
 }
 
 func (s *BacktestService) SyncKLineByInterval(ctx context.Context, exchange types.Exchange, symbol string, interval types.Interval, startTime, endTime time.Time) error {
@@ -331,6 +335,11 @@ func (s *BacktestService) BatchInsert(kline []types.KLine) error {
 	sql := fmt.Sprintf("INSERT INTO `%s` (`exchange`, `start_time`, `end_time`, `symbol`, `interval`, `open`, `high`, `low`, `close`, `closed`, `volume`, `quote_volume`, `taker_buy_base_volume`, `taker_buy_quote_volume`)"+
 		" VALUES (:exchange, :start_time, :end_time, :symbol, :interval, :open, :high, :low, :close, :closed, :volume, :quote_volume, :taker_buy_base_volume, :taker_buy_quote_volume); ", tableName)
 
+	if s.DB.DriverName() == "sqlite3" {
+		s.sqliteWriteMutex.Lock()
+		defer s.sqliteWriteMutex.Unlock()
+	}
+
 	tx := s.DB.MustBegin()
 	if _, err := tx.NamedExec(sql, kline); err != nil {
 		if e := tx.Rollback(); e != nil {
@@ -435,7 +444,6 @@ func (s *BacktestService) FindMissingTimeRanges(ctx context.Context, ex types.Ex
 
 	var timeRanges []TimeRange
 	var lastTime = since
-	var intervalDuration = interval.Duration()
 	for rows.Next() {
 		var tt types.Time
 		if err := rows.Scan(&tt); err != nil {
@@ -443,7 +451,7 @@ func (s *BacktestService) FindMissingTimeRanges(ctx context.Context, ex types.Ex
 		}
 
 		var t = time.Time(tt)
-		if t.Sub(lastTime) > intervalDuration {
+		if interval.Count(lastTime, t) > 1 {
 			timeRanges = append(timeRanges, TimeRange{
 				Start: lastTime,
 				End:   t,
@@ -453,7 +461,7 @@ func (s *BacktestService) FindMissingTimeRanges(ctx context.Context, ex types.Ex
 		lastTime = t
 	}
 
-	if lastTime.Before(until) && until.Sub(lastTime) > intervalDuration {
+	if lastTime.Before(until) && interval.Count(lastTime, until) > 1 {
 		timeRanges = append(timeRanges, TimeRange{
 			Start: lastTime,
 			End:   until,
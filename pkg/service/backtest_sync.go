@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	log "github.com/sirupsen/logrus"
+	"go.uber.org/multierr"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// BacktestSyncJob is the kline data of one symbol and one interval to be synchronized
+type BacktestSyncJob struct {
+	Exchange types.Exchange
+	Symbol   string
+	Interval types.Interval
+}
+
+func (j BacktestSyncJob) String() string {
+	return j.Exchange.Name().String() + " " + j.Symbol + " " + j.Interval.String()
+}
+
+// BacktestSyncProgress is reported when a sync job is finished, Err is set if the job failed after the retries
+type BacktestSyncProgress struct {
+	Job   BacktestSyncJob
+	Done  int
+	Total int
+	Err   error
+}
+
+type BacktestSyncOptions struct {
+	// Concurrency is the number of the jobs running at the same time, defaults to 1
+	Concurrency int
+
+	// MaxRetries is the max retry times of a failed job
+	MaxRetries uint64
+
+	// Repair syncs the missing time ranges inside the existing data range again after the job is synchronized
+	Repair bool
+
+	OnProgress func(progress BacktestSyncProgress)
+}
+
+// SyncJobs synchronizes the jobs concurrently.
+// The synchronized klines are stored in batches, so a failed or interrupted job resumes from the stored data next time.
+// A failed job does not stop the other jobs, the errors are combined and returned after all the jobs are finished.
+func (s *BacktestService) SyncJobs(ctx context.Context, jobs []BacktestSyncJob, since, until time.Time, options BacktestSyncOptions) error {
+	return runBacktestSyncJobs(ctx, jobs, options, func(ctx context.Context, job BacktestSyncJob) error {
+		if err := s.Sync(ctx, job.Exchange, job.Symbol, job.Interval, since, until); err != nil {
+			return err
+		}
+
+		if !options.Repair {
+			return nil
+		}
+
+		_, err := s.Repair(ctx, job.Exchange, job.Symbol, job.Interval, since, until)
+		return err
+	})
+}
+
+// Repair finds the missing time ranges in the stored klines and synchronizes them again,
+// it returns the time ranges that are still missing after the repair, e.g., the exchange maintenance periods.
+func (s *BacktestService) Repair(ctx context.Context, ex types.Exchange, symbol string, interval types.Interval, since, until time.Time) ([]TimeRange, error) {
+	t1, t2, err := s.QueryExistingDataRange(ctx, ex, symbol, interval, since, until)
+	if err != nil || t1 == nil || t2 == nil {
+		return nil, err
+	}
+
+	timeRanges, err := s.FindMissingTimeRanges(ctx, ex, symbol, interval, t1.Time(), t2.Time())
+	if err != nil || len(timeRanges) == 0 {
+		return nil, err
+	}
+
+	log.Infof("repairing %d missing time ranges of %s %s %s", len(timeRanges), ex.Name(), symbol, interval)
+	for _, timeRange := range timeRanges {
+		if err := s.SyncKLineByInterval(ctx, ex, symbol, interval, timeRange.Start.Add(time.Second), timeRange.End.Add(-time.Second)); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.FindMissingTimeRanges(ctx, ex, symbol, interval, t1.Time(), t2.Time())
+}
+
+func runBacktestSyncJobs(ctx context.Context, jobs []BacktestSyncJob, options BacktestSyncOptions, syncJob func(ctx context.Context, job BacktestSyncJob) error) error {
+	concurrency := options.Concurrency
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	var mu sync.Mutex
+	var errs error
+	var done int
+
+	var eg errgroup.Group
+	eg.SetLimit(concurrency)
+
+	for _, job := range jobs {
+		job := job
+		eg.Go(func() error {
+			err := backoff.Retry(func() error {
+				return syncJob(ctx, job)
+			}, backoff.WithContext(backoff.WithMaxRetries(backoff.NewExponentialBackOff(), options.MaxRetries), ctx))
+
+			mu.Lock()
+			done++
+			progress := BacktestSyncProgress{Job: job, Done: done, Total: len(jobs), Err: err}
+			if err != nil {
+				errs = multierr.Append(errs, err)
+			}
+
+			if options.OnProgress != nil {
+				options.OnProgress(progress)
+			}
+			mu.Unlock()
+
+			// keep the other jobs running
+			return nil
+		})
+	}
+
+	_ = eg.Wait()
+	return errs
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_runBacktestSyncJobs(t *testing.T) {
+	jobs := []BacktestSyncJob{
+		{Symbol: "BTCUSDT", Interval: types.Interval1m},
+		{Symbol: "BTCUSDT", Interval: types.Interval1h},
+		{Symbol: "ETHUSDT", Interval: types.Interval1m},
+		{Symbol: "ETHUSDT", Interval: types.Interval1h},
+	}
+
+	var mu sync.Mutex
+	attempts := map[BacktestSyncJob]int{}
+
+	var progresses []BacktestSyncProgress
+	err := runBacktestSyncJobs(context.Background(), jobs, BacktestSyncOptions{
+		Concurrency: 2,
+		MaxRetries:  1,
+		OnProgress: func(progress BacktestSyncProgress) {
+			progresses = append(progresses, progress)
+		},
+	}, func(ctx context.Context, job BacktestSyncJob) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts[job]++
+		switch {
+		case job.Symbol == "ETHUSDT" && job.Interval == types.Interval1h:
+			return errors.New("always fails")
+
+		case job.Symbol == "BTCUSDT" && job.Interval == types.Interval1h && attempts[job] == 1:
+			return errors.New("fails once")
+		}
+
+		return nil
+	})
+
+	assert.EqualError(t, err, "always fails")
+	assert.Equal(t, 2, attempts[jobs[1]], "the failed job should be retried")
+	assert.Equal(t, 2, attempts[jobs[3]], "the failed job should be retried up to the max retries")
+
+	if assert.Len(t, progresses, 4) {
+		for i, progress := range progresses {
+			assert.Equal(t, i+1, progress.Done)
+			assert.Equal(t, 4, progress.Total)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// CommandAuditLog records who ran the runtime command, when, and the result
+type CommandAuditLog struct {
+	GID      int64      `json:"gid" db:"gid"`
+	Source   string     `json:"source" db:"source"`
+	Username string     `json:"username" db:"username"`
+	Role     string     `json:"role" db:"role"`
+	Command  string     `json:"command" db:"command"`
+	Args     string     `json:"args" db:"args"`
+	Result   string     `json:"result" db:"result"`
+	Error    *string    `json:"error,omitempty" db:"error"`
+	Time     types.Time `json:"time" db:"time"`
+}
+
+type QueryCommandAuditLogsOptions struct {
+	Username string
+	Command  string
+	Since    *time.Time
+	Limit    uint64
+}
+
+type CommandAuditService struct {
+	DB *sqlx.DB
+}
+
+func (s *CommandAuditService) Insert(log CommandAuditLog) error {
+	_, err := s.DB.NamedExec(`
+		INSERT INTO command_audit_logs (source, username, role, command, args, result, error, time)
+		VALUES (:source, :username, :role, :command, :args, :result, :error, :time)`,
+		log)
+	return err
+}
+
+// Query returns the latest audit logs first
+func (s *CommandAuditService) Query(ctx context.Context, options QueryCommandAuditLogsOptions) ([]CommandAuditLog, error) {
+	sel := sq.Select("*").From("command_audit_logs")
+
+	if options.Username != "" {
+		sel = sel.Where(sq.Eq{"username": options.Username})
+	}
+
+	if options.Command != "" {
+		sel = sel.Where(sq.Eq{"command": options.Command})
+	}
+
+	if options.Since != nil {
+		sel = sel.Where(sq.GtOrEq{"time": *options.Since})
+	}
+
+	sel = sel.OrderBy("time DESC", "gid DESC")
+	if options.Limit > 0 {
+		sel = sel.Limit(options.Limit)
+	}
+
+	records, err := selectAndScanType(ctx, s.DB, sel, CommandAuditLog{})
+	if err != nil {
+		return nil, err
+	}
+
+	return records.([]CommandAuditLog), nil
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestCommandAuditService(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	s := &CommandAuditService{DB: xdb}
+
+	base := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	denied := "permission denied"
+	logs := []CommandAuditLog{
+		{Source: "telegram", Username: "alice", Role: "admin", Command: "/closeposition", Args: "0.5", Result: "ok"},
+		{Source: "slack", Username: "bob", Role: "viewer", Command: "/closeposition", Result: "denied", Error: &denied},
+		{Source: "web", Username: "alice", Role: "admin", Command: "suspend", Result: "ok"},
+	}
+
+	for i, log := range logs {
+		log.Time = types.Time(base.Add(time.Duration(i) * time.Minute))
+		assert.NoError(t, s.Insert(log))
+	}
+
+	ctx := context.Background()
+	records, err := s.Query(ctx, QueryCommandAuditLogsOptions{Username: "alice"})
+	if assert.NoError(t, err) && assert.Len(t, records, 2) {
+		assert.Equal(t, "suspend", records[0].Command)
+		assert.Equal(t, "0.5", records[1].Args)
+	}
+
+	records, err = s.Query(ctx, QueryCommandAuditLogsOptions{Command: "/closeposition", Limit: 1})
+	if assert.NoError(t, err) && assert.Len(t, records, 1) {
+		assert.Equal(t, "bob", records[0].Username)
+		if assert.NotNil(t, records[0].Error) {
+			assert.Equal(t, denied, *records[0].Error)
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/jmoiron/sqlx"
 
 	mysqlMigrations "github.com/c9s/bbgo/pkg/migrations/mysql"
+	postgresMigrations "github.com/c9s/bbgo/pkg/migrations/postgres"
 	sqlite3Migrations "github.com/c9s/bbgo/pkg/migrations/sqlite3"
 )
 
@@ -66,6 +67,8 @@ func (s *DatabaseService) Upgrade(ctx context.Context) error {
 		migrations = sqlite3Migrations.Migrations()
 	case "mysql":
 		migrations = mysqlMigrations.Migrations()
+	case "postgres":
+		migrations = postgresMigrations.Migrations()
 
 	}
 
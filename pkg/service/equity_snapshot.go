@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// EquitySnapshot is the strategy position marked against the mid price at the snapshot time
+type EquitySnapshot struct {
+	GID                int64            `json:"gid" db:"gid"`
+	Strategy           string           `json:"strategy" db:"strategy"`
+	StrategyInstanceID string           `json:"strategyInstanceID" db:"strategy_instance_id"`
+	Symbol             string           `json:"symbol" db:"symbol"`
+	Base               fixedpoint.Value `json:"base" db:"base"`
+	AverageCost        fixedpoint.Value `json:"averageCost" db:"average_cost"`
+	MidPrice           fixedpoint.Value `json:"midPrice" db:"mid_price"`
+	RealizedPnL        fixedpoint.Value `json:"realizedPnL" db:"realized_pnl"`
+	UnrealizedPnL      fixedpoint.Value `json:"unrealizedPnL" db:"unrealized_pnl"`
+	Equity             fixedpoint.Value `json:"equity" db:"equity"`
+	Time               types.Time       `json:"time" db:"time"`
+}
+
+type QueryEquitySnapshotsOptions struct {
+	StrategyInstanceID string
+	Symbol             string
+	Since              *time.Time
+	Until              *time.Time
+	Limit              uint64
+}
+
+type EquitySnapshotService struct {
+	DB *sqlx.DB
+}
+
+func (s *EquitySnapshotService) Insert(snapshot EquitySnapshot) error {
+	_, err := s.DB.NamedExec(`
+		INSERT INTO equity_snapshots (strategy, strategy_instance_id, symbol, base, average_cost, mid_price, realized_pnl, unrealized_pnl, equity, time)
+		VALUES (:strategy, :strategy_instance_id, :symbol, :base, :average_cost, :mid_price, :realized_pnl, :unrealized_pnl, :equity, :time)`,
+		snapshot)
+	return err
+}
+
+// Query returns the snapshots in the ascending order of the snapshot time
+func (s *EquitySnapshotService) Query(ctx context.Context, options QueryEquitySnapshotsOptions) ([]EquitySnapshot, error) {
+	sel := sq.Select("*").From("equity_snapshots")
+
+	if options.StrategyInstanceID != "" {
+		sel = sel.Where(sq.Eq{"strategy_instance_id": options.StrategyInstanceID})
+	}
+
+	if options.Symbol != "" {
+		sel = sel.Where(sq.Eq{"symbol": options.Symbol})
+	}
+
+	if options.Since != nil {
+		sel = sel.Where(sq.GtOrEq{"time": *options.Since})
+	}
+
+	if options.Until != nil {
+		sel = sel.Where(sq.LtOrEq{"time": *options.Until})
+	}
+
+	if options.Limit > 0 {
+		// keep the latest snapshots
+		sel = sel.OrderBy("time DESC").Limit(options.Limit)
+	} else {
+		sel = sel.OrderBy("time ASC")
+	}
+
+	records, err := selectAndScanType(ctx, s.DB, sel, EquitySnapshot{})
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots := records.([]EquitySnapshot)
+	if options.Limit > 0 {
+		for i, j := 0, len(snapshots)-1; i < j; i, j = i+1, j-1 {
+			snapshots[i], snapshots[j] = snapshots[j], snapshots[i]
+		}
+	}
+
+	return snapshots, nil
+}
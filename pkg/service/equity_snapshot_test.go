@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestEquitySnapshotService(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	s := &EquitySnapshotService{DB: xdb}
+
+	base := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		err := s.Insert(EquitySnapshot{
+			Strategy:           "bollmaker",
+			StrategyInstanceID: "binance.bollmaker:BTCUSDT",
+			Symbol:             "BTCUSDT",
+			Base:               fixedpoint.NewFromFloat(0.1),
+			AverageCost:        fixedpoint.NewFromInt(20000),
+			MidPrice:           fixedpoint.NewFromInt(int64(20000 + i*100)),
+			UnrealizedPnL:      fixedpoint.NewFromInt(int64(i * 10)),
+			Equity:             fixedpoint.NewFromInt(int64(i * 10)),
+			Time:               types.Time(base.Add(time.Duration(i) * time.Minute)),
+		})
+		assert.NoError(t, err)
+	}
+
+	ctx := context.Background()
+	snapshots, err := s.Query(ctx, QueryEquitySnapshotsOptions{StrategyInstanceID: "binance.bollmaker:BTCUSDT"})
+	if assert.NoError(t, err) && assert.Len(t, snapshots, 3) {
+		assert.Equal(t, "0", snapshots[0].Equity.String())
+		assert.Equal(t, "20", snapshots[2].Equity.String())
+	}
+
+	// the latest snapshots are returned in the ascending order
+	snapshots, err = s.Query(ctx, QueryEquitySnapshotsOptions{StrategyInstanceID: "binance.bollmaker:BTCUSDT", Limit: 2})
+	if assert.NoError(t, err) && assert.Len(t, snapshots, 2) {
+		assert.Equal(t, "10", snapshots[0].Equity.String())
+		assert.Equal(t, "20", snapshots[1].Equity.String())
+	}
+
+	snapshots, err = s.Query(ctx, QueryEquitySnapshotsOptions{StrategyInstanceID: "max.xmaker"})
+	assert.NoError(t, err)
+	assert.Empty(t, snapshots)
+}
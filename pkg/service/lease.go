@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// LeaseService grants the exclusive leases of the keys to the owners, a lease expires if it's not renewed within the ttl.
+// The leases are used by the coordinator of the multi-process deployment, so that a strategy instance runs in only one process.
+type LeaseService interface {
+	// Acquire grants the lease of the key to the owner if the key is free or the owner already holds it
+	Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+
+	// Renew extends the lease of the key, false is returned if the owner does not hold the lease anymore
+	Renew(ctx context.Context, key, owner string, ttl time.Duration) (bool, error)
+
+	// Release releases the lease of the key if the owner holds it
+	Release(ctx context.Context, key, owner string) error
+
+	// Owner returns the current owner of the key, it's empty if the key is free
+	Owner(ctx context.Context, key string) (string, error)
+}
+
+type memoryLease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+// MemoryLeaseService is the in-process LeaseService, it's used by the tests and the single process deployment
+type MemoryLeaseService struct {
+	mu     sync.Mutex
+	leases map[string]memoryLease
+
+	// now is the clock of the expiration, it's replaced in the tests
+	now func() time.Time
+}
+
+func NewMemoryLeaseService() *MemoryLeaseService {
+	return &MemoryLeaseService{
+		leases: make(map[string]memoryLease),
+		now:    time.Now,
+	}
+}
+
+func (s *MemoryLeaseService) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if lease, ok := s.leases[key]; ok && lease.owner != owner && now.Before(lease.expiresAt) {
+		return false, nil
+	}
+
+	s.leases[key] = memoryLease{owner: owner, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *MemoryLeaseService) Renew(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	lease, ok := s.leases[key]
+	if !ok || lease.owner != owner || !now.Before(lease.expiresAt) {
+		return false, nil
+	}
+
+	s.leases[key] = memoryLease{owner: owner, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *MemoryLeaseService) Release(ctx context.Context, key, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if lease, ok := s.leases[key]; ok && lease.owner == owner {
+		delete(s.leases, key)
+	}
+
+	return nil
+}
+
+func (s *MemoryLeaseService) Owner(ctx context.Context, key string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	lease, ok := s.leases[key]
+	if !ok || !s.now().Before(lease.expiresAt) {
+		return "", nil
+	}
+
+	return lease.owner, nil
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// redisRenewLeaseScript extends the expiration only if the lease is still held by the owner
+var redisRenewLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+// redisReleaseLeaseScript deletes the lease only if it's held by the owner
+var redisReleaseLeaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisLeaseService implements LeaseService with the redis keys expiring by the ttl,
+// the value of the key is the owner, and the keys are under the "lease" prefix of the namespace.
+type RedisLeaseService struct {
+	redis  *redis.Client
+	config *RedisPersistenceConfig
+}
+
+func NewRedisLeaseService(config *RedisPersistenceConfig) *RedisLeaseService {
+	return &RedisLeaseService{
+		redis:  newRedisClient(config),
+		config: config,
+	}
+}
+
+func (s *RedisLeaseService) key(key string) string {
+	key = "lease:" + key
+	if s.config.Namespace != "" {
+		key = s.config.Namespace + ":" + key
+	}
+
+	return key
+}
+
+func (s *RedisLeaseService) Acquire(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	ok, err := s.redis.SetNX(ctx, s.key(key), owner, ttl).Result()
+	if err != nil || ok {
+		return ok, err
+	}
+
+	// the owner already holds the lease, e.g., a process with a fixed node name restarted before the lease expired
+	return s.Renew(ctx, key, owner, ttl)
+}
+
+func (s *RedisLeaseService) Renew(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	n, err := redisRenewLeaseScript.Run(ctx, s.redis, []string{s.key(key)}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
+
+func (s *RedisLeaseService) Release(ctx context.Context, key, owner string) error {
+	return redisReleaseLeaseScript.Run(ctx, s.redis, []string{s.key(key)}, owner).Err()
+}
+
+func (s *RedisLeaseService) Owner(ctx context.Context, key string) (string, error) {
+	owner, err := s.redis.Get(ctx, s.key(key)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+
+	return owner, err
+}
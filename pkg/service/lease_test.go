@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryLeaseService(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	leases := NewMemoryLeaseService()
+	leases.now = func() time.Time { return now }
+
+	ok, err := leases.Acquire(ctx, "binance.grid2", "node-a", 10*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, _ = leases.Acquire(ctx, "binance.grid2", "node-b", 10*time.Second)
+	assert.False(t, ok, "the lease is held by node-a")
+
+	ok, _ = leases.Acquire(ctx, "binance.grid2", "node-a", 10*time.Second)
+	assert.True(t, ok, "the owner can acquire the lease again")
+
+	now = now.Add(5 * time.Second)
+	ok, _ = leases.Renew(ctx, "binance.grid2", "node-a", 10*time.Second)
+	assert.True(t, ok)
+
+	ok, _ = leases.Renew(ctx, "binance.grid2", "node-b", 10*time.Second)
+	assert.False(t, ok)
+
+	// node-a stops renewing, node-b takes over after the lease expires
+	now = now.Add(11 * time.Second)
+	owner, _ := leases.Owner(ctx, "binance.grid2")
+	assert.Empty(t, owner)
+
+	ok, _ = leases.Acquire(ctx, "binance.grid2", "node-b", 10*time.Second)
+	assert.True(t, ok)
+
+	ok, _ = leases.Renew(ctx, "binance.grid2", "node-a", 10*time.Second)
+	assert.False(t, ok, "node-a lost the lease")
+
+	assert.NoError(t, leases.Release(ctx, "binance.grid2", "node-a"))
+	owner, _ = leases.Owner(ctx, "binance.grid2")
+	assert.Equal(t, "node-b", owner, "only the owner can release the lease")
+
+	assert.NoError(t, leases.Release(ctx, "binance.grid2", "node-b"))
+	owner, _ = leases.Owner(ctx, "binance.grid2")
+	assert.Empty(t, owner)
+}
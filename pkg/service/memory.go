@@ -1,6 +1,7 @@
 package service
 
 import (
+	"encoding/json"
 	"reflect"
 	"strings"
 	"sync"
@@ -44,6 +45,11 @@ func (store *MemoryStore) Load(val interface{}) error {
 
 	v := reflect.ValueOf(val)
 	if data, ok := store.memory.Slots[store.Key]; ok {
+		// the imported values are kept in json
+		if raw, ok := data.(json.RawMessage); ok {
+			return json.Unmarshal(raw, val)
+		}
+
 		dataRV := reflect.ValueOf(data)
 		v.Elem().Set(dataRV)
 	} else {
@@ -60,3 +66,27 @@ func (store *MemoryStore) Reset() error {
 	delete(store.memory.Slots, store.Key)
 	return nil
 }
+
+// Export marshals all the slots into json, see ExportablePersistenceService
+func (s *MemoryService) Export() (map[string]json.RawMessage, error) {
+	values := make(map[string]json.RawMessage, len(s.Slots))
+	for key, val := range s.Slots {
+		data, err := json.Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+
+		values[key] = data
+	}
+
+	return values, nil
+}
+
+// Import keeps the values in json, they are unmarshalled when they are loaded
+func (s *MemoryService) Import(values map[string]json.RawMessage) error {
+	for key, data := range values {
+		s.Slots[key] = data
+	}
+
+	return nil
+}
@@ -31,9 +31,35 @@ func (s *OrderService) Sync(ctx context.Context, exchange types.Exchange, symbol
 		return nil
 	}
 
-	lastOrderID := uint64(0)
-	tasks := []SyncTask{
-		{
+	checkpointType := syncCheckpointType("orders", isMargin, isFutures, isIsolated)
+	checkpoints := &SyncCheckpointService{DB: s.DB}
+	checkpoint, err := checkpoints.Load(ctx, exchange.Name(), symbol, checkpointType)
+	if err != nil {
+		return err
+	}
+
+	var lastRecordTime time.Time
+	if checkpoint == nil {
+		checkpoint = &SyncCheckpoint{
+			Exchange: exchange.Name(),
+			Symbol:   symbol,
+			Type:     checkpointType,
+		}
+
+		records, err := selectAndScanType(ctx, s.DB, SelectLastOrders(exchange.Name(), symbol, isMargin, isFutures, isIsolated, 1), types.Order{})
+		if err != nil {
+			return err
+		}
+
+		if orders := records.([]types.Order); len(orders) > 0 {
+			lastRecordTime = orders[0].CreationTime.Time()
+		}
+	}
+
+	return syncWithCheckpoint(ctx, checkpoints, checkpoint, startTime, time.Now(), lastRecordTime, func(ctx context.Context, startTime, endTime time.Time, backfill bool) error {
+		// query the orders by the time range, unless the chunk was partially synced
+		lastOrderID := uint64(0)
+		task := SyncTask{
 			Type: types.Order{},
 			Time: func(obj interface{}) time.Time {
 				return obj.(types.Order).CreationTime.Time()
@@ -42,7 +68,10 @@ func (s *OrderService) Sync(ctx context.Context, exchange types.Exchange, symbol
 				order := obj.(types.Order)
 				return strconv.FormatUint(order.OrderID, 10)
 			},
-			Select: SelectLastOrders(exchange.Name(), symbol, isMargin, isFutures, isIsolated, 100),
+			Select: SelectLastOrders(exchange.Name(), symbol, isMargin, isFutures, isIsolated, 0).
+				RemoveLimit().
+				Where(sq.GtOrEq{"created_at": startTime}).
+				Where(sq.Lt{"created_at": endTime}),
 			OnLoad: func(objs interface{}) {
 				// update last order ID
 				orders := objs.([]types.Order)
@@ -72,17 +101,12 @@ func (s *OrderService) Sync(ctx context.Context, exchange types.Exchange, symbol
 				order := obj.(types.Order)
 				return s.Insert(order)
 			},
-			LogInsert: true,
-		},
-	}
-
-	for _, sel := range tasks {
-		if err := sel.execute(ctx, s.DB, startTime); err != nil {
-			return err
+			LogInsert:      true,
+			FixedTimeRange: true,
 		}
-	}
 
-	return nil
+		return task.execute(ctx, s.DB, startTime, endTime)
+	})
 }
 
 func SelectLastOrders(ex types.ExchangeName, symbol string, isMargin, isFutures, isIsolated bool, limit uint64) sq.SelectBuilder {
@@ -202,6 +226,14 @@ func (s *OrderService) Insert(order types.Order) (err error) {
 		return err
 	}
 
+	if s.DB.DriverName() == "postgres" {
+		_, err = s.DB.NamedExec(`
+			INSERT INTO orders (exchange, order_id, client_order_id, order_type, status, symbol, price, stop_price, quantity, executed_quantity, side, is_working, time_in_force, created_at, updated_at, is_margin, is_futures, is_isolated)
+			VALUES (:exchange, :order_id, :client_order_id, :order_type, :status, :symbol, :price, :stop_price, :quantity, :executed_quantity, :side, :is_working, :time_in_force, :created_at, :updated_at, :is_margin, :is_futures, :is_isolated)
+			ON CONFLICT (order_id, exchange) DO UPDATE SET status=EXCLUDED.status, executed_quantity=EXCLUDED.executed_quantity, is_working=EXCLUDED.is_working, updated_at=EXCLUDED.updated_at`, order)
+		return err
+	}
+
 	_, err = s.DB.NamedExec(`
 			INSERT INTO orders (exchange, order_id, client_order_id, order_type, status, symbol, price, stop_price, quantity, executed_quantity, side, is_working, time_in_force, created_at, updated_at, is_margin, is_futures, is_isolated)
 			VALUES (:exchange, :order_id, :client_order_id, :order_type, :status, :symbol, :price, :stop_price, :quantity, :executed_quantity, :side, :is_working, :time_in_force, :created_at, :updated_at, :is_margin, :is_futures, :is_isolated)
@@ -1,11 +1,68 @@
 package service
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 type PersistenceService interface {
 	NewStore(id string, subIDs ...string) Store
 }
 
+// TransactionalPersistenceService saves the persistence fields of one strategy instance all-or-nothing.
+// The values are keyed by the persistence tag, and they are stored in the same keys as NewStore("state", id, tag),
+// so that they can still be loaded by the store.
+type TransactionalPersistenceService interface {
+	PersistenceService
+
+	// SaveAll saves the values in one transaction, and keeps the values as a versioned snapshot for rollback
+	SaveAll(id string, values map[string]interface{}) (version int64, err error)
+
+	// Snapshots returns the kept snapshot versions, the latest version comes first
+	Snapshots(id string) ([]int64, error)
+
+	// Rollback restores the values of the snapshot version, the restored values are saved as a new version
+	Rollback(id string, version int64) error
+}
+
+// PersistenceSnapshot is the versioned snapshot of the persistence fields of one strategy instance
+type PersistenceSnapshot struct {
+	Version int64                      `json:"version"`
+	Time    time.Time                  `json:"time"`
+	Values  map[string]json.RawMessage `json:"values"`
+}
+
+// maxPersistenceSnapshots is the number of the snapshots kept for each strategy instance
+const maxPersistenceSnapshots = 20
+
+func newPersistenceSnapshot(version int64, values map[string]interface{}) (*PersistenceSnapshot, error) {
+	snapshot := &PersistenceSnapshot{
+		Version: version,
+		Time:    time.Now(),
+		Values:  make(map[string]json.RawMessage, len(values)),
+	}
+
+	for tag, val := range values {
+		data, err := json.Marshal(val)
+		if err != nil {
+			return nil, err
+		}
+
+		snapshot.Values[tag] = data
+	}
+
+	return snapshot, nil
+}
+
+func (snapshot *PersistenceSnapshot) values() map[string]interface{} {
+	values := make(map[string]interface{}, len(snapshot.Values))
+	for tag, data := range snapshot.Values {
+		values[tag] = data
+	}
+
+	return values
+}
+
 type Store interface {
 	Load(val interface{}) error
 	Save(val interface{}) error
@@ -24,6 +81,11 @@ type RedisPersistenceConfig struct {
 	Namespace string `yaml:"namespace" json:"namespace" env:"REDIS_NAMESPACE"`
 }
 
+type PostgresPersistenceConfig struct {
+	DSN       string `yaml:"dsn" json:"dsn" env:"POSTGRES_PERSISTENCE_DSN"`
+	Namespace string `yaml:"namespace" json:"namespace" env:"POSTGRES_PERSISTENCE_NAMESPACE"`
+}
+
 type JsonPersistenceConfig struct {
 	Directory string `yaml:"directory" json:"directory"`
 }
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PersistenceArchiveVersion is the current version of the persistence archive format
+const PersistenceArchiveVersion = 1
+
+// ExportablePersistenceService is implemented by the persistence services that can list and restore all the stored values.
+// The keys are in the form of "id:subID1:subID2", which is the key of NewStore(id, subID1, subID2) without the namespace,
+// so that the values can be moved between the different persistence backends.
+// The versioned snapshots are not exported.
+type ExportablePersistenceService interface {
+	PersistenceService
+
+	Export() (map[string]json.RawMessage, error)
+	Import(values map[string]json.RawMessage) error
+}
+
+// PersistenceArchive is the dump of all the persisted values of a persistence service
+type PersistenceArchive struct {
+	Version   int                        `json:"version"`
+	CreatedAt time.Time                  `json:"createdAt"`
+	Values    map[string]json.RawMessage `json:"values"`
+}
+
+func ExportPersistence(persistence PersistenceService) (*PersistenceArchive, error) {
+	exporter, ok := persistence.(ExportablePersistenceService)
+	if !ok {
+		return nil, fmt.Errorf("persistence service %T does not support export", persistence)
+	}
+
+	values, err := exporter.Export()
+	if err != nil {
+		return nil, err
+	}
+
+	return &PersistenceArchive{
+		Version:   PersistenceArchiveVersion,
+		CreatedAt: time.Now(),
+		Values:    values,
+	}, nil
+}
+
+func ImportPersistence(persistence PersistenceService, archive *PersistenceArchive) error {
+	if archive.Version > PersistenceArchiveVersion {
+		return fmt.Errorf("persistence archive version %d is not supported, the latest supported version is %d", archive.Version, PersistenceArchiveVersion)
+	}
+
+	importer, ok := persistence.(ExportablePersistenceService)
+	if !ok {
+		return fmt.Errorf("persistence service %T does not support import", persistence)
+	}
+
+	return importer.Import(archive.Values)
+}
+
+// splitPersistenceKey splits the key into the NewStore arguments.
+// The strategy state keys are "state:{instanceID}:{tag}" and the instance ID may contain ":",
+// so the segments between the first one and the last one are joined back as one sub ID.
+func splitPersistenceKey(key string) (id string, subIDs []string) {
+	segments := strings.Split(key, ":")
+	if len(segments) <= 2 {
+		return segments[0], segments[1:]
+	}
+
+	return segments[0], []string{
+		strings.Join(segments[1:len(segments)-1], ":"),
+		segments[len(segments)-1],
+	}
+}
+
+func joinPersistenceKey(id string, subIDs ...string) string {
+	return strings.Join(append([]string{id}, subIDs...), ":")
+}
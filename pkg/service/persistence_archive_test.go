@@ -0,0 +1,75 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestSplitPersistenceKey(t *testing.T) {
+	id, subIDs := splitPersistenceKey("state:bollmaker:ETHUSDT:Position")
+	assert.Equal(t, "state", id)
+	assert.Equal(t, []string{"bollmaker:ETHUSDT", "Position"}, subIDs)
+
+	id, subIDs = splitPersistenceKey("orders:grid")
+	assert.Equal(t, "orders", id)
+	assert.Equal(t, []string{"grid"}, subIDs)
+}
+
+func TestPersistenceArchive_JsonToMemoryToJson(t *testing.T) {
+	source := &JsonPersistenceService{Directory: t.TempDir()}
+
+	position := types.NewPositionFromMarket(types.Market{Symbol: "ETHUSDT", BaseCurrency: "ETH", QuoteCurrency: "USDT"})
+	position.Base = fixedpoint.NewFromFloat(1.5)
+	position.AverageCost = fixedpoint.NewFromFloat(1800.0)
+
+	assert.NoError(t, source.NewStore("state", "bollmaker:ETHUSDT", "Position").Save(position))
+	assert.NoError(t, source.NewStore("custom", "grid").Save(map[string]int{"level": 3}))
+
+	// the snapshots are not exported
+	_, err := source.SaveAll("bollmaker:ETHUSDT", map[string]interface{}{"Position": position})
+	assert.NoError(t, err)
+
+	archive, err := ExportPersistence(source)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, PersistenceArchiveVersion, archive.Version)
+	assert.Len(t, archive.Values, 2)
+
+	memory := NewMemoryService()
+	assert.NoError(t, ImportPersistence(memory, archive))
+
+	var loadedPosition types.Position
+	if assert.NoError(t, memory.NewStore("state", "bollmaker:ETHUSDT", "Position").Load(&loadedPosition)) {
+		assert.Equal(t, "1.5", loadedPosition.Base.String())
+		assert.Equal(t, "1800", loadedPosition.AverageCost.String())
+	}
+
+	archive, err = ExportPersistence(memory)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	target := &JsonPersistenceService{Directory: t.TempDir()}
+	assert.NoError(t, ImportPersistence(target, archive))
+
+	var custom map[string]int
+	if assert.NoError(t, target.NewStore("custom", "grid").Load(&custom)) {
+		assert.Equal(t, 3, custom["level"])
+	}
+
+	loadedPosition = types.Position{}
+	if assert.NoError(t, target.NewStore("state", "bollmaker:ETHUSDT", "Position").Load(&loadedPosition)) {
+		assert.Equal(t, "1.5", loadedPosition.Base.String())
+	}
+}
+
+func TestImportPersistence_UnsupportedVersion(t *testing.T) {
+	err := ImportPersistence(NewMemoryService(), &PersistenceArchive{Version: PersistenceArchiveVersion + 1})
+	assert.Error(t, err)
+}
@@ -1,21 +1,61 @@
 package service
 
+import "fmt"
+
 type PersistenceServiceFacade struct {
-	Redis  *RedisPersistenceService
-	Json   *JsonPersistenceService
-	Memory *MemoryService
+	Redis    *RedisPersistenceService
+	Postgres *PostgresPersistenceService
+	Json     *JsonPersistenceService
+	Memory   *MemoryService
 }
 
 // Get returns the preferred persistence service by fallbacks
-// Redis will be preferred at the first position.
+// Redis will be preferred at the first position, and then Postgres.
 func (facade *PersistenceServiceFacade) Get() PersistenceService {
 	if facade.Redis != nil {
 		return facade.Redis
 	}
 
+	if facade.Postgres != nil {
+		return facade.Postgres
+	}
+
 	if facade.Json != nil {
 		return facade.Json
 	}
 
 	return facade.Memory
 }
+
+// GetByName returns the persistence service of the backend name: redis, postgres, json or memory
+func (facade *PersistenceServiceFacade) GetByName(name string) (PersistenceService, error) {
+	var persistence PersistenceService
+	switch name {
+	case "redis":
+		if facade.Redis != nil {
+			persistence = facade.Redis
+		}
+
+	case "postgres":
+		if facade.Postgres != nil {
+			persistence = facade.Postgres
+		}
+
+	case "json":
+		if facade.Json != nil {
+			persistence = facade.Json
+		}
+
+	case "memory":
+		persistence = facade.Memory
+
+	default:
+		return nil, fmt.Errorf("unknown persistence backend %q", name)
+	}
+
+	if persistence == nil {
+		return nil, fmt.Errorf("persistence backend %q is not configured", name)
+	}
+
+	return persistence, nil
+}
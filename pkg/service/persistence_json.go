@@ -2,9 +2,13 @@ package service
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 type JsonPersistenceService struct {
@@ -76,3 +80,233 @@ func (store JsonStore) Save(val interface{}) error {
 	p := filepath.Join(store.Directory, store.ID) + ".json"
 	return ioutil.WriteFile(p, data, 0666)
 }
+
+// SaveAll writes the snapshot file first, then replaces the value files one by one and marks the version as committed.
+// All the files are replaced by renaming, if the process crashes in the middle,
+// the uncommitted snapshot will be applied again by Recover.
+func (s *JsonPersistenceService) SaveAll(id string, values map[string]interface{}) (int64, error) {
+	versions, err := s.Snapshots(id)
+	if err != nil {
+		return 0, err
+	}
+
+	var version int64 = 1
+	if len(versions) > 0 {
+		version = versions[0] + 1
+	}
+
+	snapshot, err := newPersistenceSnapshot(version, values)
+	if err != nil {
+		return 0, err
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := writeFileAtomic(s.snapshotPath(id, version), data); err != nil {
+		return 0, err
+	}
+
+	if err := s.apply(id, snapshot); err != nil {
+		return 0, err
+	}
+
+	// prune the old snapshots
+	for _, v := range versions {
+		if v <= version-maxPersistenceSnapshots {
+			if err := os.Remove(s.snapshotPath(id, v)); err != nil && !os.IsNotExist(err) {
+				return version, err
+			}
+		}
+	}
+
+	return version, nil
+}
+
+func (s *JsonPersistenceService) Snapshots(id string) ([]int64, error) {
+	entries, err := ioutil.ReadDir(s.snapshotDir(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var versions []int64
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".json" {
+			continue
+		}
+
+		version, err := strconv.ParseInt(strings.TrimSuffix(name, ".json"), 10, 64)
+		if err != nil {
+			continue
+		}
+
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i] > versions[j]
+	})
+
+	return versions, nil
+}
+
+func (s *JsonPersistenceService) Rollback(id string, version int64) error {
+	snapshot, err := s.loadSnapshot(id, version)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.SaveAll(id, snapshot.values())
+	return err
+}
+
+// Recover applies the latest snapshot again if it was not committed
+func (s *JsonPersistenceService) Recover(id string) error {
+	versions, err := s.Snapshots(id)
+	if err != nil || len(versions) == 0 {
+		return err
+	}
+
+	data, err := ioutil.ReadFile(s.committedPath(id))
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	committed, _ := strconv.ParseInt(strings.TrimSpace(string(data)), 10, 64)
+	if committed >= versions[0] {
+		return nil
+	}
+
+	snapshot, err := s.loadSnapshot(id, versions[0])
+	if err != nil {
+		return err
+	}
+
+	return s.apply(id, snapshot)
+}
+
+func (s *JsonPersistenceService) apply(id string, snapshot *PersistenceSnapshot) error {
+	for tag, data := range snapshot.Values {
+		store := s.NewStore("state", id, tag).(*JsonStore)
+		if err := os.MkdirAll(store.Directory, 0777); err != nil {
+			return err
+		}
+
+		if err := writeFileAtomic(filepath.Join(store.Directory, store.ID)+".json", data); err != nil {
+			return err
+		}
+	}
+
+	return writeFileAtomic(s.committedPath(id), []byte(strconv.FormatInt(snapshot.Version, 10)))
+}
+
+func (s *JsonPersistenceService) loadSnapshot(id string, version int64) (*PersistenceSnapshot, error) {
+	data, err := ioutil.ReadFile(s.snapshotPath(id, version))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("snapshot version %d of %s not found", version, id)
+		}
+
+		return nil, err
+	}
+
+	var snapshot PersistenceSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+func (s *JsonPersistenceService) snapshotDir(id string) string {
+	return filepath.Join(s.Directory, "snapshots", id)
+}
+
+func (s *JsonPersistenceService) snapshotPath(id string, version int64) string {
+	return filepath.Join(s.snapshotDir(id), strconv.FormatInt(version, 10)+".json")
+}
+
+func (s *JsonPersistenceService) committedPath(id string) string {
+	return filepath.Join(s.snapshotDir(id), "committed")
+}
+
+// writeFileAtomic writes the data into a temporary file and renames it to the path
+func writeFileAtomic(p string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(p), 0777); err != nil {
+		return err
+	}
+
+	tmp := p + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0666); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, p)
+}
+
+// Export reads all the value files except the snapshots, see ExportablePersistenceService
+func (s *JsonPersistenceService) Export() (map[string]json.RawMessage, error) {
+	values := make(map[string]json.RawMessage)
+	err := filepath.Walk(s.Directory, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			if p == filepath.Join(s.Directory, "snapshots") {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if filepath.Ext(p) != ".json" {
+			return nil
+		}
+
+		rel, err := filepath.Rel(s.Directory, p)
+		if err != nil {
+			return err
+		}
+
+		data, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+
+		if len(data) == 0 {
+			return nil
+		}
+
+		// the store ID is the file name and the sub IDs are the directories
+		subIDs := strings.Split(filepath.ToSlash(filepath.Dir(rel)), "/")
+		if subIDs[0] == "." {
+			subIDs = nil
+		}
+
+		values[joinPersistenceKey(strings.TrimSuffix(filepath.Base(rel), ".json"), subIDs...)] = data
+		return nil
+	})
+
+	return values, err
+}
+
+// Import writes the values into the value files, see ExportablePersistenceService
+func (s *JsonPersistenceService) Import(values map[string]json.RawMessage) error {
+	for key, data := range values {
+		id, subIDs := splitPersistenceKey(key)
+		store := s.NewStore(id, subIDs...).(*JsonStore)
+		if err := writeFileAtomic(filepath.Join(store.Directory, store.ID)+".json", data); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
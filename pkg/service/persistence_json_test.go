@@ -0,0 +1,72 @@
+package service
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestJsonPersistenceService_SaveAll(t *testing.T) {
+	ps := &JsonPersistenceService{Directory: t.TempDir()}
+
+	version, err := ps.SaveAll("test", map[string]interface{}{
+		"position":     fixedpoint.NewFromFloat(1.0),
+		"profit_stats": fixedpoint.NewFromFloat(10.0),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), version)
+
+	version, err = ps.SaveAll("test", map[string]interface{}{
+		"position":     fixedpoint.NewFromFloat(2.0),
+		"profit_stats": fixedpoint.NewFromFloat(20.0),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), version)
+
+	// the values can be loaded by the store
+	var position fixedpoint.Value
+	assert.NoError(t, ps.NewStore("state", "test", "position").Load(&position))
+	assert.Equal(t, "2", position.String())
+
+	versions, err := ps.Snapshots("test")
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{2, 1}, versions)
+
+	t.Run("rollback", func(t *testing.T) {
+		assert.NoError(t, ps.Rollback("test", 1))
+
+		var profitStats fixedpoint.Value
+		assert.NoError(t, ps.NewStore("state", "test", "profit_stats").Load(&profitStats))
+		assert.Equal(t, "10", profitStats.String())
+
+		versions, err := ps.Snapshots("test")
+		assert.NoError(t, err)
+		assert.Equal(t, []int64{3, 2, 1}, versions)
+	})
+
+	t.Run("recover the uncommitted snapshot", func(t *testing.T) {
+		// simulate a crash after the snapshot is written
+		snapshot, err := newPersistenceSnapshot(4, map[string]interface{}{
+			"position":     fixedpoint.NewFromFloat(4.0),
+			"profit_stats": fixedpoint.NewFromFloat(40.0),
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, os.Remove(filepath.Join(ps.Directory, "test", "position", "state.json")))
+
+		data, err := json.Marshal(snapshot)
+		assert.NoError(t, err)
+		assert.NoError(t, ioutil.WriteFile(ps.snapshotPath("test", 4), data, 0666))
+
+		assert.NoError(t, ps.Recover("test"))
+
+		var position fixedpoint.Value
+		assert.NoError(t, ps.NewStore("state", "test", "position").Load(&position))
+		assert.Equal(t, "4", position.String())
+	})
+}
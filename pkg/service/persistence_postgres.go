@@ -0,0 +1,279 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	log "github.com/sirupsen/logrus"
+)
+
+var postgresLogger = log.WithFields(log.Fields{
+	"persistence": "postgres",
+})
+
+const postgresPersistenceSchema = `
+CREATE TABLE IF NOT EXISTS persistence_states
+(
+    key        VARCHAR(255) PRIMARY KEY,
+    value      TEXT         NOT NULL,
+    expired_at TIMESTAMP(3) NULL,
+    updated_at TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS persistence_snapshots
+(
+    id         VARCHAR(255) NOT NULL,
+    version    BIGINT       NOT NULL,
+    data       TEXT         NOT NULL,
+    created_at TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id, version)
+);
+`
+
+const upsertPersistenceStateSQL = `
+INSERT INTO persistence_states (key, value, expired_at, updated_at) VALUES ($1, $2, $3, NOW())
+ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, expired_at = EXCLUDED.expired_at, updated_at = EXCLUDED.updated_at`
+
+type PostgresPersistenceService struct {
+	db     *sqlx.DB
+	config *PostgresPersistenceConfig
+}
+
+// NewPostgresPersistenceService connects to the postgres database and creates the persistence tables if they do not exist.
+func NewPostgresPersistenceService(config *PostgresPersistenceConfig) (*PostgresPersistenceService, error) {
+	db, err := sqlx.Connect("postgres", config.DSN)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := db.Exec(postgresPersistenceSchema); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &PostgresPersistenceService{
+		db:     db,
+		config: config,
+	}, nil
+}
+
+// Ping checks the connection of the postgres database
+func (s *PostgresPersistenceService) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+func (s *PostgresPersistenceService) NewStore(id string, subIDs ...string) Store {
+	return &PostgresStore{
+		db: s.db,
+		ID: s.key(id, subIDs...),
+	}
+}
+
+func (s *PostgresPersistenceService) key(id string, subIDs ...string) string {
+	if len(subIDs) > 0 {
+		id += ":" + strings.Join(subIDs, ":")
+	}
+
+	if s.config != nil && s.config.Namespace != "" {
+		id = s.config.Namespace + ":" + id
+	}
+
+	return id
+}
+
+// SaveAll upserts all the value rows and inserts the snapshot in one database transaction
+func (s *PostgresPersistenceService) SaveAll(id string, values map[string]interface{}) (version int64, err error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return 0, err
+	}
+
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	snapshotID := s.key("snapshots", id)
+	if err = tx.Get(&version, `SELECT COALESCE(MAX(version), 0) + 1 FROM persistence_snapshots WHERE id = $1`, snapshotID); err != nil {
+		return 0, err
+	}
+
+	snapshot, err := newPersistenceSnapshot(version, values)
+	if err != nil {
+		return 0, err
+	}
+
+	for tag, data := range snapshot.Values {
+		var expiredAt *time.Time
+		if expiringData, ok := values[tag].(Expirable); ok && expiringData.Expiration() > 0 {
+			t := time.Now().Add(expiringData.Expiration())
+			expiredAt = &t
+		}
+
+		if _, err = tx.Exec(upsertPersistenceStateSQL, s.key("state", id, tag), string(data), expiredAt); err != nil {
+			return 0, err
+		}
+	}
+
+	snapshotData, err := json.Marshal(snapshot)
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err = tx.Exec(`INSERT INTO persistence_snapshots (id, version, data) VALUES ($1, $2, $3)`, snapshotID, version, string(snapshotData)); err != nil {
+		return 0, err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM persistence_snapshots WHERE id = $1 AND version <= $2`, snapshotID, version-maxPersistenceSnapshots); err != nil {
+		return 0, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	postgresLogger.Debugf("[postgres] saved %d keys of %q in one transaction, version = %d", len(values), id, version)
+	return version, nil
+}
+
+func (s *PostgresPersistenceService) Snapshots(id string) ([]int64, error) {
+	var versions []int64
+	err := s.db.Select(&versions, `SELECT version FROM persistence_snapshots WHERE id = $1 ORDER BY version DESC`, s.key("snapshots", id))
+	return versions, err
+}
+
+func (s *PostgresPersistenceService) Rollback(id string, version int64) error {
+	var data string
+	err := s.db.Get(&data, `SELECT data FROM persistence_snapshots WHERE id = $1 AND version = $2`, s.key("snapshots", id), version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("snapshot version %d of %s not found", version, id)
+		}
+
+		return err
+	}
+
+	var snapshot PersistenceSnapshot
+	if err := json.Unmarshal([]byte(data), &snapshot); err != nil {
+		return err
+	}
+
+	_, err = s.SaveAll(id, snapshot.values())
+	return err
+}
+
+type PostgresStore struct {
+	db *sqlx.DB
+
+	ID string
+}
+
+func (store *PostgresStore) Load(val interface{}) error {
+	if store.db == nil {
+		return fmt.Errorf("can not load from postgres, possible cause: postgres persistence is not configured, or you are trying to use postgres in back-test")
+	}
+
+	var row struct {
+		Value     string       `db:"value"`
+		ExpiredAt sql.NullTime `db:"expired_at"`
+	}
+
+	err := store.db.Get(&row, `SELECT value, expired_at FROM persistence_states WHERE key = $1`, store.ID)
+
+	postgresLogger.Debugf("[postgres] get key %q, data = %s", store.ID, row.Value)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrPersistenceNotExists
+		}
+
+		return err
+	}
+
+	if row.ExpiredAt.Valid && row.ExpiredAt.Time.Before(time.Now()) {
+		return ErrPersistenceNotExists
+	}
+
+	// skip null data
+	if len(row.Value) == 0 || row.Value == "null" {
+		return ErrPersistenceNotExists
+	}
+
+	return json.Unmarshal([]byte(row.Value), val)
+}
+
+func (store *PostgresStore) Save(val interface{}) error {
+	if val == nil {
+		return nil
+	}
+
+	var expiredAt *time.Time
+	if expiringData, ok := val.(Expirable); ok && expiringData.Expiration() > 0 {
+		t := time.Now().Add(expiringData.Expiration())
+		expiredAt = &t
+	}
+
+	data, err := json.Marshal(val)
+	if err != nil {
+		return err
+	}
+
+	_, err = store.db.Exec(upsertPersistenceStateSQL, store.ID, string(data), expiredAt)
+
+	postgresLogger.Debugf("[postgres] set key %q, data = %s, expired at = %v", store.ID, string(data), expiredAt)
+
+	return err
+}
+
+func (store *PostgresStore) Reset() error {
+	_, err := store.db.Exec(`DELETE FROM persistence_states WHERE key = $1`, store.ID)
+	return err
+}
+
+// Export reads all the unexpired values under the namespace, see ExportablePersistenceService
+func (s *PostgresPersistenceService) Export() (map[string]json.RawMessage, error) {
+	prefix := s.key("")
+
+	var rows []struct {
+		Key   string `db:"key"`
+		Value string `db:"value"`
+	}
+
+	if err := s.db.Select(&rows, `SELECT key, value FROM persistence_states WHERE key LIKE $1 AND (expired_at IS NULL OR expired_at > NOW())`, prefix+"%"); err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]json.RawMessage, len(rows))
+	for _, row := range rows {
+		if len(row.Value) == 0 || row.Value == "null" {
+			continue
+		}
+
+		values[strings.TrimPrefix(row.Key, prefix)] = json.RawMessage(row.Value)
+	}
+
+	return values, nil
+}
+
+// Import upserts the values under the namespace in one transaction, see ExportablePersistenceService
+func (s *PostgresPersistenceService) Import(values map[string]json.RawMessage) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return err
+	}
+
+	for key, data := range values {
+		if _, err := tx.Exec(upsertPersistenceStateSQL, s.key(key), string(data), nil); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
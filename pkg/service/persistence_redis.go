@@ -4,7 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,21 +25,35 @@ type RedisPersistenceService struct {
 }
 
 func NewRedisPersistenceService(config *RedisPersistenceConfig) *RedisPersistenceService {
-	client := redis.NewClient(&redis.Options{
+	return &RedisPersistenceService{
+		redis:  newRedisClient(config),
+		config: config,
+	}
+}
+
+func newRedisClient(config *RedisPersistenceConfig) *redis.Client {
+	return redis.NewClient(&redis.Options{
 		Addr: net.JoinHostPort(config.Host, config.Port),
 		// Username:           "", // username is only for redis 6.0
 		// pragma: allowlist nextline secret
 		Password: config.Password, // no password set
 		DB:       config.DB,       // use default DB
 	})
+}
 
-	return &RedisPersistenceService{
-		redis:  client,
-		config: config,
-	}
+// Ping checks the connection of the redis server
+func (s *RedisPersistenceService) Ping(ctx context.Context) error {
+	return s.redis.Ping(ctx).Err()
 }
 
 func (s *RedisPersistenceService) NewStore(id string, subIDs ...string) Store {
+	return &RedisStore{
+		redis: s.redis,
+		ID:    s.key(id, subIDs...),
+	}
+}
+
+func (s *RedisPersistenceService) key(id string, subIDs ...string) string {
 	if len(subIDs) > 0 {
 		id += ":" + strings.Join(subIDs, ":")
 	}
@@ -45,10 +62,91 @@ func (s *RedisPersistenceService) NewStore(id string, subIDs ...string) Store {
 		id = s.config.Namespace + ":" + id
 	}
 
-	return &RedisStore{
-		redis: s.redis,
-		ID:    id,
+	return id
+}
+
+// SaveAll sets all the value keys and the snapshot in one MULTI/EXEC transaction
+func (s *RedisPersistenceService) SaveAll(id string, values map[string]interface{}) (int64, error) {
+	ctx := context.Background()
+	snapshotKey := s.key("snapshots", id)
+
+	version, err := s.redis.Incr(ctx, s.key("snapshots", id, "version")).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	snapshot, err := newPersistenceSnapshot(version, values)
+	if err != nil {
+		return 0, err
+	}
+
+	snapshotData, err := json.Marshal(snapshot)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for tag, data := range snapshot.Values {
+			var expiration time.Duration
+			if expiringData, ok := values[tag].(Expirable); ok {
+				expiration = expiringData.Expiration()
+			}
+
+			pipe.Set(ctx, s.key("state", id, tag), []byte(data), expiration)
+		}
+
+		pipe.HSet(ctx, snapshotKey, strconv.FormatInt(version, 10), snapshotData)
+		if version > maxPersistenceSnapshots {
+			pipe.HDel(ctx, snapshotKey, strconv.FormatInt(version-maxPersistenceSnapshots, 10))
+		}
+
+		return nil
+	})
+
+	redisLogger.Debugf("[redis] saved %d keys of %q in one transaction, version = %d", len(values), id, version)
+	return version, err
+}
+
+func (s *RedisPersistenceService) Snapshots(id string) ([]int64, error) {
+	fields, err := s.redis.HKeys(context.Background(), s.key("snapshots", id)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []int64
+	for _, field := range fields {
+		version, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, version)
+	}
+
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i] > versions[j]
+	})
+
+	return versions, nil
+}
+
+func (s *RedisPersistenceService) Rollback(id string, version int64) error {
+	data, err := s.redis.HGet(context.Background(), s.key("snapshots", id), strconv.FormatInt(version, 10)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return fmt.Errorf("snapshot version %d of %s not found", version, id)
+		}
+
+		return err
+	}
+
+	var snapshot PersistenceSnapshot
+	if err := json.Unmarshal([]byte(data), &snapshot); err != nil {
+		return err
 	}
+
+	_, err = s.SaveAll(id, snapshot.values())
+	return err
 }
 
 type RedisStore struct {
@@ -110,3 +208,70 @@ func (store *RedisStore) Reset() error {
 	_, err := store.redis.Del(context.Background(), store.ID).Result()
 	return err
 }
+
+// redisExportKeyPrefixes are the key prefixes of the values stored by bbgo,
+// the other keys in the redis db are not exported since the db might be shared with the other applications.
+var redisExportKeyPrefixes = []string{"state", "bbgo"}
+
+// Export reads all the string keys of bbgo under the namespace except the snapshots, see ExportablePersistenceService
+func (s *RedisPersistenceService) Export() (map[string]json.RawMessage, error) {
+	values := make(map[string]json.RawMessage)
+	for _, keyPrefix := range redisExportKeyPrefixes {
+		if err := s.export(keyPrefix, values); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+func (s *RedisPersistenceService) export(keyPrefix string, values map[string]json.RawMessage) error {
+	ctx := context.Background()
+	prefix := s.key("")
+
+	iter := s.redis.Scan(ctx, 0, s.key(keyPrefix)+":*", 100).Iterator()
+	for iter.Next(ctx) {
+		redisKey := iter.Val()
+		key := strings.TrimPrefix(redisKey, prefix)
+
+		keyType, err := s.redis.Type(ctx, redisKey).Result()
+		if err != nil {
+			return err
+		}
+
+		if keyType != "string" {
+			continue
+		}
+
+		data, err := s.redis.Get(ctx, redisKey).Result()
+		if err != nil {
+			if err == redis.Nil {
+				continue
+			}
+
+			return err
+		}
+
+		if len(data) == 0 || data == "null" {
+			continue
+		}
+
+		values[key] = json.RawMessage(data)
+	}
+
+	return iter.Err()
+}
+
+// Import sets the values under the namespace, see ExportablePersistenceService
+func (s *RedisPersistenceService) Import(values map[string]json.RawMessage) error {
+	ctx := context.Background()
+	_, err := s.redis.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for key, data := range values {
+			pipe.Set(ctx, s.key(key), []byte(data), 0)
+		}
+
+		return nil
+	})
+
+	return err
+}
@@ -111,3 +111,12 @@ func (s *SyncService) SyncWithdrawHistory(ctx context.Context, exchange types.Ex
 
 	return nil
 }
+
+// SyncTransferHistory syncs both the deposit and the withdraw records of the exchange
+func (s *SyncService) SyncTransferHistory(ctx context.Context, exchange types.Exchange, startTime time.Time) error {
+	if err := s.SyncDepositHistory(ctx, exchange, startTime); err != nil {
+		return err
+	}
+
+	return s.SyncWithdrawHistory(ctx, exchange, startTime)
+}
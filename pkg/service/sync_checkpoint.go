@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// SyncChunkDuration is the time range of each sync chunk, the checkpoint is saved after each chunk is synced.
+// It's shorter than 24 hours since binance only accepts the time range of the trade query shorter than 24 hours.
+var SyncChunkDuration = 12 * time.Hour
+
+// SyncRateLimitBackoff is the initial waiting time when the exchange rejects the sync query because of the rate limit,
+// the waiting time is doubled on each retry.
+var SyncRateLimitBackoff = 30 * time.Second
+
+// SyncRateLimitMaxRetries is the max retries of a sync chunk that hits the rate limit
+var SyncRateLimitMaxRetries = 5
+
+// SyncCheckpoint records the time range of the remote records that are completely synced,
+// so that an interrupted sync can be resumed, and the deeper history can be backfilled without syncing from scratch.
+type SyncCheckpoint struct {
+	GID       int64              `db:"gid" json:"gid"`
+	Exchange  types.ExchangeName `db:"exchange" json:"exchange"`
+	Symbol    string             `db:"symbol" json:"symbol"`
+	Type      string             `db:"type" json:"type"`
+	StartTime types.Time         `db:"start_time" json:"startTime"`
+	EndTime   types.Time         `db:"end_time" json:"endTime"`
+	UpdatedAt types.Time         `db:"updated_at" json:"updatedAt"`
+}
+
+type SyncCheckpointService struct {
+	DB *sqlx.DB
+}
+
+// Load returns nil if the checkpoint does not exist
+func (s *SyncCheckpointService) Load(ctx context.Context, ex types.ExchangeName, symbol, recordType string) (*SyncCheckpoint, error) {
+	var checkpoint SyncCheckpoint
+	query := s.DB.Rebind(`SELECT * FROM sync_checkpoints WHERE exchange = ? AND symbol = ? AND type = ?`)
+	if err := s.DB.GetContext(ctx, &checkpoint, query, ex, symbol, recordType); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &checkpoint, nil
+}
+
+func (s *SyncCheckpointService) Save(ctx context.Context, checkpoint *SyncCheckpoint) error {
+	checkpoint.UpdatedAt = types.Time(time.Now())
+
+	query := `INSERT INTO sync_checkpoints (exchange, symbol, type, start_time, end_time, updated_at)
+		VALUES (:exchange, :symbol, :type, :start_time, :end_time, :updated_at)`
+
+	if s.DB.DriverName() == "mysql" {
+		query += ` ON DUPLICATE KEY UPDATE start_time=:start_time, end_time=:end_time, updated_at=:updated_at`
+	} else {
+		query += ` ON CONFLICT (exchange, symbol, type) DO UPDATE SET start_time=:start_time, end_time=:end_time, updated_at=:updated_at`
+	}
+
+	_, err := s.DB.NamedExecContext(ctx, query, checkpoint)
+	return err
+}
+
+// syncCheckpointType returns the checkpoint type of the record type,
+// the margin and futures records are synced separately from the spot records.
+func syncCheckpointType(recordType string, isMargin, isFutures, isIsolated bool) string {
+	switch {
+	case isFutures:
+		return recordType + ":futures"
+	case isIsolated:
+		return recordType + ":isolated"
+	case isMargin:
+		return recordType + ":margin"
+	}
+
+	return recordType
+}
+
+// syncChunkFunc syncs the remote records in the time range,
+// backfill is true when the time range is before the synced range.
+type syncChunkFunc func(ctx context.Context, startTime, endTime time.Time, backfill bool) error
+
+// syncWithCheckpoint backfills the history before the checkpoint, and then syncs the records after the checkpoint,
+// in chunks of SyncChunkDuration. The checkpoint is saved after each chunk, so the sync resumes from the last chunk.
+//
+// When there is no checkpoint, the records already in the database are considered synced,
+// this keeps the existing databases from re-syncing from scratch.
+func syncWithCheckpoint(ctx context.Context, checkpoints *SyncCheckpointService, checkpoint *SyncCheckpoint, startTime, endTime time.Time, lastRecordTime time.Time, f syncChunkFunc) error {
+	if checkpoint.StartTime.Time().IsZero() {
+		checkpoint.StartTime = types.Time(startTime)
+		checkpoint.EndTime = types.Time(startTime)
+		if lastRecordTime.After(startTime) {
+			checkpoint.EndTime = types.Time(lastRecordTime)
+		}
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"exchange": checkpoint.Exchange,
+		"symbol":   checkpoint.Symbol,
+		"type":     checkpoint.Type,
+	})
+
+	// backfill the deeper history backward, so that the synced range is always continuous
+	for startTime.Before(checkpoint.StartTime.Time()) {
+		chunkEndTime := checkpoint.StartTime.Time()
+		chunkStartTime := chunkEndTime.Add(-SyncChunkDuration)
+		if chunkStartTime.Before(startTime) {
+			chunkStartTime = startTime
+		}
+
+		logger.Infof("backfilling %s %s %s from %s to %s", checkpoint.Exchange, checkpoint.Symbol, checkpoint.Type, chunkStartTime, chunkEndTime)
+		if err := syncChunkWithRetry(ctx, f, chunkStartTime, chunkEndTime, true); err != nil {
+			return err
+		}
+
+		checkpoint.StartTime = types.Time(chunkStartTime)
+		if err := checkpoints.Save(ctx, checkpoint); err != nil {
+			return err
+		}
+	}
+
+	for checkpoint.EndTime.Time().Before(endTime) {
+		chunkStartTime := checkpoint.EndTime.Time()
+		chunkEndTime := chunkStartTime.Add(SyncChunkDuration)
+		if chunkEndTime.After(endTime) {
+			chunkEndTime = endTime
+		}
+
+		logger.Debugf("syncing %s %s %s from %s to %s", checkpoint.Exchange, checkpoint.Symbol, checkpoint.Type, chunkStartTime, chunkEndTime)
+		if err := syncChunkWithRetry(ctx, f, chunkStartTime, chunkEndTime, false); err != nil {
+			return err
+		}
+
+		checkpoint.EndTime = types.Time(chunkEndTime)
+		if err := checkpoints.Save(ctx, checkpoint); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func syncChunkWithRetry(ctx context.Context, f syncChunkFunc, startTime, endTime time.Time, backfill bool) error {
+	wait := SyncRateLimitBackoff
+	for retry := 0; ; retry++ {
+		err := f(ctx, startTime, endTime, backfill)
+		if err == nil || !isRateLimitError(err) || retry >= SyncRateLimitMaxRetries {
+			return err
+		}
+
+		logrus.WithError(err).Warnf("sync query hits the rate limit, retrying in %s...", wait)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		wait *= 2
+	}
+}
+
+// isRateLimitError checks the error message of the exchange apis, since they don't share the error types
+func isRateLimitError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"429", "418", "too many requests", "rate limit", "-1003"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+)
+
+type syncChunk struct {
+	startTime, endTime time.Time
+	backfill           bool
+}
+
+func Test_syncWithCheckpoint(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	checkpoints := &SyncCheckpointService{DB: xdb}
+
+	base := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	hours := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+
+	var chunks []syncChunk
+	record := func(ctx context.Context, startTime, endTime time.Time, backfill bool) error {
+		chunks = append(chunks, syncChunk{startTime.UTC(), endTime.UTC(), backfill})
+		return nil
+	}
+
+	// the existing records before 30h are considered synced
+	checkpoint := &SyncCheckpoint{Exchange: "binance", Symbol: "BTCUSDT", Type: "trades"}
+	err = syncWithCheckpoint(ctx, checkpoints, checkpoint, hours(0), hours(40), hours(30), record)
+	assert.NoError(t, err)
+	assert.Equal(t, []syncChunk{{hours(30), hours(40), false}}, chunks)
+
+	// backfill the deeper history backward, and then sync forward
+	chunks = nil
+	checkpoint, err = checkpoints.Load(ctx, "binance", "BTCUSDT", "trades")
+	if assert.NoError(t, err) && assert.NotNil(t, checkpoint) {
+		assert.True(t, hours(0).Equal(checkpoint.StartTime.Time()))
+		assert.True(t, hours(40).Equal(checkpoint.EndTime.Time()))
+	}
+
+	err = syncWithCheckpoint(ctx, checkpoints, checkpoint, hours(-30), hours(50), time.Time{}, record)
+	assert.NoError(t, err)
+	assert.Equal(t, []syncChunk{
+		{hours(-12), hours(0), true},
+		{hours(-24), hours(-12), true},
+		{hours(-30), hours(-24), true},
+		{hours(40), hours(50), false},
+	}, chunks)
+
+	// resume from the last saved chunk after a failure
+	chunks = nil
+	fail := errors.New("connection reset")
+	err = syncWithCheckpoint(ctx, checkpoints, checkpoint, hours(-30), hours(80), time.Time{}, func(ctx context.Context, startTime, endTime time.Time, backfill bool) error {
+		if startTime.Equal(hours(62)) {
+			return fail
+		}
+
+		return record(ctx, startTime, endTime, backfill)
+	})
+	assert.Equal(t, fail, err)
+
+	checkpoint, err = checkpoints.Load(ctx, "binance", "BTCUSDT", "trades")
+	if assert.NoError(t, err) && assert.NotNil(t, checkpoint) {
+		assert.True(t, hours(-30).Equal(checkpoint.StartTime.Time()))
+		assert.True(t, hours(62).Equal(checkpoint.EndTime.Time()))
+	}
+}
+
+func Test_syncChunkWithRetry(t *testing.T) {
+	defer func(backoff time.Duration) { SyncRateLimitBackoff = backoff }(SyncRateLimitBackoff)
+	SyncRateLimitBackoff = time.Millisecond
+
+	calls := 0
+	err := syncChunkWithRetry(context.Background(), func(ctx context.Context, startTime, endTime time.Time, backfill bool) error {
+		calls++
+		if calls < 3 {
+			return errors.New("request error: status code 429, too many requests")
+		}
+
+		return nil
+	}, time.Now(), time.Now(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	err = syncChunkWithRetry(context.Background(), func(ctx context.Context, startTime, endTime time.Time, backfill bool) error {
+		calls++
+		return errors.New("invalid symbol")
+	}, time.Now(), time.Now(), false)
+	assert.Error(t, err)
+	assert.Equal(t, 1, calls)
+}
@@ -52,6 +52,10 @@ type SyncTask struct {
 
 	// LogInsert logs the insert record in INFO level
 	LogInsert bool
+
+	// FixedTimeRange syncs the given time range as it is instead of resuming from the last record time,
+	// and the end time of the range is excluded, so that the adjacent ranges do not overlap.
+	FixedTimeRange bool
 }
 
 func (sel SyncTask) execute(ctx context.Context, db *sqlx.DB, startTime time.Time, args ...time.Time) error {
@@ -81,7 +85,9 @@ func (sel SyncTask) execute(ctx context.Context, db *sqlx.DB, startTime time.Tim
 	}
 
 	// default since time point
-	startTime = lastRecordTime(sel, recordSliceRef, startTime)
+	if !sel.FixedTimeRange {
+		startTime = lastRecordTime(sel, recordSliceRef, startTime)
+	}
 
 	endTime := time.Now()
 	if len(args) > 0 {
@@ -122,7 +128,7 @@ func (sel SyncTask) execute(ctx context.Context, db *sqlx.DB, startTime time.Tim
 			}
 
 			tt := sel.Time(obj)
-			if tt.Before(startTime) || tt.After(endTime) {
+			if tt.Before(startTime) || tt.After(endTime) || (sel.FixedTimeRange && tt.Equal(endTime)) {
 				logrus.Debugf("object %s time %s is outside of the time range", id, tt)
 				continue
 			}
@@ -65,11 +65,40 @@ func (s *TradeService) Sync(ctx context.Context, exchange types.Exchange, symbol
 		return nil
 	}
 
-	lastTradeID := uint64(1)
-	tasks := []SyncTask{
-		{
-			Type:   types.Trade{},
-			Select: SelectLastTrades(exchange.Name(), symbol, isMargin, isFutures, isIsolated, 100),
+	checkpointType := syncCheckpointType("trades", isMargin, isFutures, isIsolated)
+	checkpoints := &SyncCheckpointService{DB: s.DB}
+	checkpoint, err := checkpoints.Load(ctx, exchange.Name(), symbol, checkpointType)
+	if err != nil {
+		return err
+	}
+
+	var lastRecordTime time.Time
+	if checkpoint == nil {
+		checkpoint = &SyncCheckpoint{
+			Exchange: exchange.Name(),
+			Symbol:   symbol,
+			Type:     checkpointType,
+		}
+
+		records, err := selectAndScanType(ctx, s.DB, SelectLastTrades(exchange.Name(), symbol, isMargin, isFutures, isIsolated, 1), types.Trade{})
+		if err != nil {
+			return err
+		}
+
+		if trades := records.([]types.Trade); len(trades) > 0 {
+			lastRecordTime = trades[0].Time.Time()
+		}
+	}
+
+	return syncWithCheckpoint(ctx, checkpoints, checkpoint, startTime, time.Now(), lastRecordTime, func(ctx context.Context, startTime, endTime time.Time, backfill bool) error {
+		// query the trades by the time range, unless the chunk was partially synced
+		lastTradeID := uint64(0)
+		task := SyncTask{
+			Type: types.Trade{},
+			Select: SelectLastTrades(exchange.Name(), symbol, isMargin, isFutures, isIsolated, 0).
+				RemoveLimit().
+				Where(sq.GtOrEq{"traded_at": startTime}).
+				Where(sq.Lt{"traded_at": endTime}),
 			OnLoad: func(objs interface{}) {
 				// update last trade ID
 				trades := objs.([]types.Trade)
@@ -96,17 +125,12 @@ func (s *TradeService) Sync(ctx context.Context, exchange types.Exchange, symbol
 				trade := obj.(types.Trade)
 				return strconv.FormatUint(trade.ID, 10) + trade.Side.String()
 			},
-			LogInsert: true,
-		},
-	}
-
-	for _, sel := range tasks {
-		if err := sel.execute(ctx, s.DB, startTime); err != nil {
-			return err
+			LogInsert:      true,
+			FixedTimeRange: true,
 		}
-	}
 
-	return nil
+		return task.execute(ctx, s.DB, startTime, endTime)
+	})
 }
 
 func (s *TradeService) QueryTradingVolume(startTime time.Time, options TradingVolumeQueryOptions) ([]TradingVolume, error) {
@@ -122,6 +146,8 @@ func (s *TradeService) QueryTradingVolume(startTime time.Time, options TradingVo
 	driverName := s.DB.DriverName()
 	if driverName == "mysql" {
 		sql = generateMysqlTradingVolumeQuerySQL(options)
+	} else if driverName == "postgres" {
+		sql = generatePostgresTradingVolumeQuerySQL(options)
 	} else {
 		sql = generateSqliteTradingVolumeSQL(options)
 	}
@@ -252,6 +278,59 @@ func generateMysqlTradingVolumeQuerySQL(options TradingVolumeQueryOptions) strin
 	return sql
 }
 
+func generatePostgresTimeRangeClauses(timeRangeColumn, period string) (selectors []string, groupBys []string, orderBys []string) {
+	extract := func(field string) string {
+		return "CAST(EXTRACT(" + field + " FROM " + timeRangeColumn + ") AS INT)"
+	}
+
+	switch period {
+	case "month":
+		selectors = append(selectors, extract("YEAR")+" AS year", extract("MONTH")+" AS month")
+		groupBys = append([]string{"month", "year"}, groupBys...)
+		orderBys = append(orderBys, "year ASC", "month ASC")
+
+	case "year":
+		selectors = append(selectors, extract("YEAR")+" AS year")
+		groupBys = append([]string{"year"}, groupBys...)
+		orderBys = append(orderBys, "year ASC")
+
+	case "day":
+		fallthrough
+
+	default:
+		selectors = append(selectors, extract("YEAR")+" AS year", extract("MONTH")+" AS month", extract("DAY")+" AS day")
+		groupBys = append([]string{"day", "month", "year"}, groupBys...)
+		orderBys = append(orderBys, "year ASC", "month ASC", "day ASC")
+	}
+
+	return
+}
+
+func generatePostgresTradingVolumeQuerySQL(options TradingVolumeQueryOptions) string {
+	timeRangeColumn := "traded_at"
+	sel, groupBys, orderBys := generatePostgresTimeRangeClauses(timeRangeColumn, options.GroupByPeriod)
+
+	switch options.SegmentBy {
+	case "symbol":
+		sel = append(sel, "symbol")
+		groupBys = append([]string{"symbol"}, groupBys...)
+		orderBys = append(orderBys, "symbol")
+	case "exchange":
+		sel = append(sel, "exchange")
+		groupBys = append([]string{"exchange"}, groupBys...)
+		orderBys = append(orderBys, "exchange")
+	}
+
+	sel = append(sel, "SUM(quantity * price) AS quote_volume")
+	where := []string{timeRangeColumn + " > :start_time"}
+	sql := `SELECT ` + strings.Join(sel, ", ") + ` FROM trades` +
+		` WHERE ` + strings.Join(where, " AND ") +
+		` GROUP BY ` + strings.Join(groupBys, ", ") +
+		` ORDER BY ` + strings.Join(orderBys, ", ")
+
+	return sql
+}
+
 func (s *TradeService) QueryForTradingFeeCurrency(ex types.ExchangeName, symbol string, feeCurrency string) ([]types.Trade, error) {
 	sql := "SELECT * FROM trades WHERE exchange = :exchange AND (symbol = :symbol OR fee_currency = :fee_currency) ORDER BY traded_at ASC"
 	rows, err := s.DB.NamedQuery(sql, map[string]interface{}{
@@ -302,6 +381,9 @@ func (s *TradeService) Query(options QueryTradesOptions) ([]types.Trade, error)
 		return nil, err
 	}
 
+	// rebind the placeholders for the drivers that do not use "?", e.g., postgres
+	sql = s.DB.Rebind(sql)
+
 	log.Debug(sql)
 	log.Debug(args)
 
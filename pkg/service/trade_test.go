@@ -54,6 +54,14 @@ func Test_queryTradingVolumeSQL(t *testing.T) {
 		}
 	})
 
+	t.Run("postgres", func(t *testing.T) {
+		o := TradingVolumeQueryOptions{
+			GroupByPeriod: "month",
+			SegmentBy:     "symbol",
+		}
+		assert.Equal(t, "SELECT CAST(EXTRACT(YEAR FROM traded_at) AS INT) AS year, CAST(EXTRACT(MONTH FROM traded_at) AS INT) AS month, symbol, SUM(quantity * price) AS quote_volume FROM trades WHERE traded_at > :start_time GROUP BY symbol, month, year ORDER BY year ASC, month ASC, symbol", generatePostgresTradingVolumeQuerySQL(o))
+	})
+
 }
 
 func Test_queryTradesSQL(t *testing.T) {
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"sort"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// TreasuryAssetFlow is the recorded flows of the asset on the exchange,
+// the trade flows only include the spot trades.
+type TreasuryAssetFlow struct {
+	Asset          string           `json:"asset"`
+	Deposits       fixedpoint.Value `json:"deposits"`
+	Withdrawals    fixedpoint.Value `json:"withdrawals"`
+	WithdrawalFees fixedpoint.Value `json:"withdrawalFees"`
+	TradeIn        fixedpoint.Value `json:"tradeIn"`
+	TradeOut       fixedpoint.Value `json:"tradeOut"`
+	TradeFees      fixedpoint.Value `json:"tradeFees"`
+	Rewards        fixedpoint.Value `json:"rewards"`
+}
+
+// Net is the balance expected from the recorded flows
+func (f *TreasuryAssetFlow) Net() fixedpoint.Value {
+	return f.Deposits.
+		Sub(f.Withdrawals).
+		Sub(f.WithdrawalFees).
+		Add(f.TradeIn).
+		Sub(f.TradeOut).
+		Sub(f.TradeFees).
+		Add(f.Rewards)
+}
+
+// TreasuryAssetReport reconciles the on-exchange balance with the recorded flows of the asset,
+// the difference is the flows not recorded, e.g., the balance before the sync start time and the transfers between the accounts.
+type TreasuryAssetReport struct {
+	TreasuryAssetFlow
+
+	NetFlow    fixedpoint.Value `json:"netFlow"`
+	Balance    fixedpoint.Value `json:"balance"`
+	Difference fixedpoint.Value `json:"difference"`
+}
+
+const withdrawFeeCurrency = "CASE WHEN txn_fee_currency = '' THEN asset ELSE txn_fee_currency END"
+
+type TreasuryService struct {
+	DB *sqlx.DB
+}
+
+type treasuryAssetSum struct {
+	Asset  string           `db:"asset"`
+	Amount fixedpoint.Value `db:"amount"`
+}
+
+type treasuryTradeSum struct {
+	Symbol        string           `db:"symbol"`
+	IsBuyer       bool             `db:"is_buyer"`
+	Quantity      fixedpoint.Value `db:"quantity"`
+	QuoteQuantity fixedpoint.Value `db:"quote_quantity"`
+}
+
+// QueryAssetFlows sums the recorded deposits, withdrawals, spot trades and rewards of the exchange by asset,
+// the markets are used for the base and the quote currencies of the trades.
+func (s *TreasuryService) QueryAssetFlows(ctx context.Context, ex types.ExchangeName, markets types.MarketMap) (map[string]*TreasuryAssetFlow, error) {
+	flows := make(map[string]*TreasuryAssetFlow)
+	flow := func(asset string) *TreasuryAssetFlow {
+		f, ok := flows[asset]
+		if !ok {
+			f = &TreasuryAssetFlow{Asset: asset}
+			flows[asset] = f
+		}
+		return f
+	}
+
+	sums := []struct {
+		sel   sq.SelectBuilder
+		apply func(f *TreasuryAssetFlow, amount fixedpoint.Value)
+	}{
+		{
+			sel: sq.Select("asset", "SUM(amount) AS amount").From("deposits").
+				Where(sq.Eq{"exchange": ex}).GroupBy("asset"),
+			apply: func(f *TreasuryAssetFlow, amount fixedpoint.Value) { f.Deposits = f.Deposits.Add(amount) },
+		},
+		{
+			sel: sq.Select("asset", "SUM(amount) AS amount").From("withdraws").
+				Where(sq.Eq{"exchange": ex}).GroupBy("asset"),
+			apply: func(f *TreasuryAssetFlow, amount fixedpoint.Value) { f.Withdrawals = f.Withdrawals.Add(amount) },
+		},
+		{
+			// the fee is charged in the withdrawn asset if the fee currency is not recorded
+			sel: sq.Select(withdrawFeeCurrency+" AS asset", "SUM(txn_fee) AS amount").From("withdraws").
+				Where(sq.Eq{"exchange": ex}).GroupBy(withdrawFeeCurrency),
+			apply: func(f *TreasuryAssetFlow, amount fixedpoint.Value) { f.WithdrawalFees = f.WithdrawalFees.Add(amount) },
+		},
+		{
+			sel: sq.Select("fee_currency AS asset", "SUM(fee) AS amount").From("trades").
+				Where(spotTradeCondition(ex)).GroupBy("fee_currency"),
+			apply: func(f *TreasuryAssetFlow, amount fixedpoint.Value) { f.TradeFees = f.TradeFees.Add(amount) },
+		},
+		{
+			sel: sq.Select("currency AS asset", "SUM(quantity) AS amount").From("rewards").
+				Where(sq.Eq{"exchange": ex}).GroupBy("currency"),
+			apply: func(f *TreasuryAssetFlow, amount fixedpoint.Value) { f.Rewards = f.Rewards.Add(amount) },
+		},
+	}
+
+	for _, sum := range sums {
+		records, err := selectAndScanType(ctx, s.DB, sum.sel, treasuryAssetSum{})
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range records.([]treasuryAssetSum) {
+			sum.apply(flow(record.Asset), record.Amount)
+		}
+	}
+
+	sel := sq.Select("symbol", "is_buyer", "SUM(quantity) AS quantity", "SUM(quote_quantity) AS quote_quantity").
+		From("trades").
+		Where(spotTradeCondition(ex)).
+		GroupBy("symbol", "is_buyer")
+
+	records, err := selectAndScanType(ctx, s.DB, sel, treasuryTradeSum{})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records.([]treasuryTradeSum) {
+		market, ok := markets[record.Symbol]
+		if !ok {
+			continue
+		}
+
+		base, quote := flow(market.BaseCurrency), flow(market.QuoteCurrency)
+		if record.IsBuyer {
+			base.TradeIn = base.TradeIn.Add(record.Quantity)
+			quote.TradeOut = quote.TradeOut.Add(record.QuoteQuantity)
+		} else {
+			base.TradeOut = base.TradeOut.Add(record.Quantity)
+			quote.TradeIn = quote.TradeIn.Add(record.QuoteQuantity)
+		}
+	}
+
+	return flows, nil
+}
+
+// Report reconciles the balances with the recorded flows, the reports are sorted by asset
+func (s *TreasuryService) Report(ctx context.Context, ex types.ExchangeName, balances types.BalanceMap, markets types.MarketMap) ([]TreasuryAssetReport, error) {
+	flows, err := s.QueryAssetFlows(ctx, ex, markets)
+	if err != nil {
+		return nil, err
+	}
+
+	for currency := range balances {
+		if _, ok := flows[currency]; !ok {
+			flows[currency] = &TreasuryAssetFlow{Asset: currency}
+		}
+	}
+
+	var reports []TreasuryAssetReport
+	for asset, flow := range flows {
+		balance := fixedpoint.Zero
+		if b, ok := balances[asset]; ok {
+			balance = b.Total()
+		}
+
+		netFlow := flow.Net()
+		if balance.IsZero() && netFlow.IsZero() {
+			continue
+		}
+
+		reports = append(reports, TreasuryAssetReport{
+			TreasuryAssetFlow: *flow,
+			NetFlow:           netFlow,
+			Balance:           balance,
+			Difference:        balance.Sub(netFlow),
+		})
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Asset < reports[j].Asset
+	})
+
+	return reports, nil
+}
+
+func spotTradeCondition(ex types.ExchangeName) sq.And {
+	return sq.And{
+		sq.Eq{"exchange": ex},
+		sq.Eq{"is_margin": false},
+		sq.Eq{"is_futures": false},
+		sq.Eq{"is_isolated": false},
+	}
+}
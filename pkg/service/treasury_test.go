@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestTreasuryService_Report(t *testing.T) {
+	db, err := prepareDB(t)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer db.Close()
+
+	ctx := context.Background()
+	xdb := sqlx.NewDb(db.DB, "sqlite3")
+	now := types.Time(time.Now())
+
+	assert.NoError(t, insertType(xdb, types.Deposit{
+		Exchange:      types.ExchangeBinance,
+		Asset:         "USDT",
+		Amount:        fixedpoint.NewFromInt(10000),
+		TransactionID: "deposit-1",
+		Time:          now,
+	}))
+
+	// the deposit of another exchange is not included
+	assert.NoError(t, insertType(xdb, types.Deposit{
+		Exchange:      types.ExchangeMax,
+		Asset:         "USDT",
+		Amount:        fixedpoint.NewFromInt(500),
+		TransactionID: "deposit-2",
+		Time:          now,
+	}))
+
+	withdrawService := &WithdrawService{DB: xdb}
+	assert.NoError(t, withdrawService.Insert(types.Withdraw{
+		Exchange:       types.ExchangeBinance,
+		Asset:          "BTC",
+		Amount:         fixedpoint.NewFromFloat(0.05),
+		TransactionID:  "withdraw-1",
+		TransactionFee: fixedpoint.NewFromFloat(0.0005),
+		ApplyTime:      now,
+	}))
+
+	tradeService := &TradeService{DB: xdb}
+	assert.NoError(t, tradeService.Insert(types.Trade{
+		ID:            1,
+		OrderID:       1,
+		Exchange:      types.ExchangeBinance,
+		Symbol:        "BTCUSDT",
+		Side:          types.SideTypeBuy,
+		IsBuyer:       true,
+		Price:         fixedpoint.NewFromInt(20000),
+		Quantity:      fixedpoint.NewFromFloat(0.2),
+		QuoteQuantity: fixedpoint.NewFromInt(4000),
+		Fee:           fixedpoint.NewFromFloat(0.0002),
+		FeeCurrency:   "BTC",
+		Time:          now,
+	}))
+	assert.NoError(t, tradeService.Insert(types.Trade{
+		ID:            2,
+		OrderID:       2,
+		Exchange:      types.ExchangeBinance,
+		Symbol:        "BTCUSDT",
+		Side:          types.SideTypeSell,
+		Price:         fixedpoint.NewFromInt(21000),
+		Quantity:      fixedpoint.NewFromFloat(0.1),
+		QuoteQuantity: fixedpoint.NewFromInt(2100),
+		Fee:           fixedpoint.NewFromFloat(2.1),
+		FeeCurrency:   "USDT",
+		Time:          now,
+	}))
+
+	// the margin trades are not included
+	assert.NoError(t, tradeService.Insert(types.Trade{
+		ID:            3,
+		OrderID:       3,
+		Exchange:      types.ExchangeBinance,
+		Symbol:        "BTCUSDT",
+		Side:          types.SideTypeBuy,
+		IsBuyer:       true,
+		IsMargin:      true,
+		Price:         fixedpoint.NewFromInt(20000),
+		Quantity:      fixedpoint.NewFromInt(1),
+		QuoteQuantity: fixedpoint.NewFromInt(20000),
+		Fee:           fixedpoint.NewFromFloat(0.001),
+		FeeCurrency:   "BTC",
+		Time:          now,
+	}))
+
+	markets := types.MarketMap{
+		"BTCUSDT": types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"},
+	}
+
+	balances := types.BalanceMap{
+		"BTC":  types.Balance{Currency: "BTC", Available: fixedpoint.NewFromFloat(0.0493)},
+		"USDT": types.Balance{Currency: "USDT", Available: fixedpoint.NewFromInt(8000), Locked: fixedpoint.NewFromFloat(97.9)},
+		"BNB":  types.Balance{Currency: "BNB", Available: fixedpoint.NewFromInt(1)},
+	}
+
+	service := &TreasuryService{DB: xdb}
+	reports, err := service.Report(ctx, types.ExchangeBinance, balances, markets)
+	if assert.NoError(t, err) && assert.Len(t, reports, 3) {
+		assert.Equal(t, "BNB", reports[0].Asset)
+		assert.Equal(t, "1", reports[0].Difference.String())
+
+		btc := reports[1]
+		assert.Equal(t, "BTC", btc.Asset)
+		assert.Equal(t, "0.05", btc.Withdrawals.String())
+		assert.Equal(t, "0.0005", btc.WithdrawalFees.String())
+		assert.Equal(t, "0.2", btc.TradeIn.String())
+		assert.Equal(t, "0.1", btc.TradeOut.String())
+		assert.Equal(t, "0.0002", btc.TradeFees.String())
+		assert.Equal(t, "0.0493", btc.NetFlow.String())
+		assert.Equal(t, "0", btc.Difference.String())
+
+		usdt := reports[2]
+		assert.Equal(t, "USDT", usdt.Asset)
+		assert.Equal(t, "10000", usdt.Deposits.String())
+		assert.Equal(t, "2100", usdt.TradeIn.String())
+		assert.Equal(t, "4000", usdt.TradeOut.String())
+		assert.Equal(t, "2.1", usdt.TradeFees.String())
+		assert.Equal(t, "8097.9", usdt.NetFlow.String())
+		assert.Equal(t, "0", usdt.Difference.String())
+	}
+}
@@ -0,0 +1,45 @@
+package askew
+
+import (
+	"math"
+	"time"
+)
+
+// Model is the Avellaneda–Stoikov optimal market making model:
+//
+//	reservation price r = s - q * γ * σ² * τ
+//	optimal spread    δ = γ * σ² * τ + (2 / γ) * ln(1 + γ / κ)
+//
+// s is the mid price, q is the inventory, σ² is the variance of the price change per interval,
+// and τ is the remaining time to the end of the horizon in intervals.
+type Model struct {
+	// Gamma is the inventory risk aversion, the higher gamma skews the quotes more aggressively to reduce the inventory
+	Gamma float64
+
+	// Kappa is the order book liquidity, the higher kappa means the denser book and the narrower spread
+	Kappa float64
+}
+
+// ReservationPrice returns the indifference price of the inventory, it's below the mid price when holding the long inventory
+func (m Model) ReservationPrice(midPrice, inventory, variance, tau float64) float64 {
+	return midPrice - inventory*m.Gamma*variance*tau
+}
+
+// OptimalSpread returns the total spread between the bid and the ask prices
+func (m Model) OptimalSpread(variance, tau float64) float64 {
+	return m.Gamma*variance*tau + 2.0/m.Gamma*math.Log(1.0+m.Gamma/m.Kappa)
+}
+
+// Quotes returns the bid and the ask prices around the reservation price
+func (m Model) Quotes(midPrice, inventory, variance, tau float64) (bid, ask float64) {
+	reservationPrice := m.ReservationPrice(midPrice, inventory, variance, tau)
+	halfSpread := m.OptimalSpread(variance, tau) / 2.0
+	return reservationPrice - halfSpread, reservationPrice + halfSpread
+}
+
+// remainingHorizon returns τ in intervals, the horizon cycles are aligned to the unix epoch,
+// e.g., the 24h horizon ends at 00:00 UTC every day.
+func remainingHorizon(now time.Time, horizon, interval time.Duration) float64 {
+	elapsed := time.Duration(now.UnixNano() % int64(horizon))
+	return float64(horizon-elapsed) / float64(interval)
+}
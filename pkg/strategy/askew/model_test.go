@@ -0,0 +1,61 @@
+package askew
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestModel(t *testing.T) {
+	model := Model{Gamma: 0.1, Kappa: 1.5}
+
+	// the long inventory lowers the reservation price
+	assert.InDelta(t, 96.0, model.ReservationPrice(100, 2, 4, 5), 1e-9)
+	assert.InDelta(t, 104.0, model.ReservationPrice(100, -2, 4, 5), 1e-9)
+	assert.Equal(t, 100.0, model.ReservationPrice(100, 0, 4, 5))
+
+	spread := model.OptimalSpread(4, 5)
+	assert.InDelta(t, 2.0+2.0/0.1*math.Log(1.0+0.1/1.5), spread, 1e-9)
+
+	bid, ask := model.Quotes(100, 2, 4, 5)
+	assert.InDelta(t, 96.0-spread/2, bid, 1e-9)
+	assert.InDelta(t, 96.0+spread/2, ask, 1e-9)
+
+	// the spread narrows when the horizon is close to the end
+	assert.Less(t, model.OptimalSpread(4, 1), spread)
+}
+
+func TestRemainingHorizon(t *testing.T) {
+	now := time.Date(2024, 1, 1, 18, 0, 0, 0, time.UTC)
+	assert.Equal(t, 6.0, remainingHorizon(now, 24*time.Hour, time.Hour))
+	assert.Equal(t, 24.0, remainingHorizon(now.Truncate(24*time.Hour), 24*time.Hour, time.Hour))
+	assert.Equal(t, 1.0, remainingHorizon(now, time.Hour, time.Hour))
+}
+
+func TestStrategy_quotePrices(t *testing.T) {
+	s := &Strategy{
+		Gamma: 0.1,
+		Kappa: 1.5,
+		Market: types.Market{
+			Symbol:          "BTCUSDT",
+			PricePrecision:  2,
+			VolumePrecision: 5,
+			TickSize:        fixedpoint.NewFromFloat(0.01),
+		},
+	}
+
+	bid, ask := s.quotePrices(fixedpoint.NewFromInt(100), fixedpoint.NewFromInt(2), 4, 5)
+	assert.Equal(t, "94.35", s.Market.FormatPrice(bid))
+	assert.Equal(t, "97.64", s.Market.FormatPrice(ask))
+
+	// widened to the min spread around the reservation price
+	s.MinSpreadRatio = fixedpoint.NewFromFloat(0.1)
+	bid, ask = s.quotePrices(fixedpoint.NewFromInt(100), fixedpoint.NewFromInt(2), 4, 5)
+	assert.Equal(t, "10.00", s.Market.FormatPrice(ask.Sub(bid)))
+	assert.InDelta(t, 96.0, bid.Add(ask).Float64()/2, 0.02)
+}
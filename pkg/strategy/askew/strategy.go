@@ -0,0 +1,324 @@
+package askew
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "askew"
+
+const defaultVolatilityWindow = 30
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// Strategy askew is the Avellaneda–Stoikov market maker, it quotes a bid and an ask around the reservation price
+// skewed by the inventory, with the optimal spread from the volatility and the inventory risk aversion.
+// It's an alternative of the layer-based scmaker.
+type Strategy struct {
+	Environment *bbgo.Environment
+	Market      types.Market
+
+	Symbol string `json:"symbol"`
+
+	// Interval is the quote update interval, the volatility is estimated by the close price changes of the interval
+	Interval types.Interval `json:"interval"`
+
+	// Window is the number of the close price changes of the volatility estimation, defaults to 30
+	Window int `json:"window"`
+
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// Gamma is the inventory risk aversion
+	Gamma float64 `json:"gamma"`
+
+	// Kappa is the order book liquidity parameter
+	Kappa float64 `json:"kappa"`
+
+	// Horizon is the length of the trading session of the model, e.g., 24h,
+	// the quotes are skewed less when the session is close to the end
+	Horizon types.Duration `json:"horizon"`
+
+	// TargetInventory is the base position the inventory is measured from
+	TargetInventory fixedpoint.Value `json:"targetInventory"`
+
+	// MaxInventory stops quoting the side which increases the inventory beyond it, disabled if zero
+	MaxInventory fixedpoint.Value `json:"maxInventory"`
+
+	// MinSpreadRatio is the minimal spread ratio to the mid price, e.g., 0.1%
+	MinSpreadRatio fixedpoint.Value `json:"minSpreadRatio"`
+
+	DryRun bool `json:"dryRun"`
+
+	Position    *types.Position    `json:"position,omitempty" persistence:"position"`
+	ProfitStats *types.ProfitStats `json:"profitStats,omitempty" persistence:"profit_stats"`
+
+	// StrategyController
+	bbgo.StrategyController
+
+	session       *bbgo.ExchangeSession
+	orderExecutor *bbgo.GeneralOrderExecutor
+
+	volatility *indicator.StdDevStream
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) InstanceID() string {
+	return fmt.Sprintf("%s:%s", ID, s.Symbol)
+}
+
+func (s *Strategy) Defaults() error {
+	if s.Window == 0 {
+		s.Window = defaultVolatilityWindow
+	}
+
+	return nil
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return errors.New("symbol is required")
+	}
+
+	if len(s.Interval) == 0 {
+		return errors.New("interval is required")
+	}
+
+	if s.Quantity.Sign() <= 0 {
+		return errors.New("quantity should be positive")
+	}
+
+	if s.Gamma <= 0 || s.Kappa <= 0 {
+		return errors.New("gamma and kappa should be positive")
+	}
+
+	if s.Horizon.Duration() < s.Interval.Duration() {
+		return fmt.Errorf("horizon should not be shorter than the interval %s", s.Interval)
+	}
+
+	if s.Window < 2 {
+		return errors.New("window should be greater than 1")
+	}
+
+	if s.MaxInventory.Sign() < 0 || s.MinSpreadRatio.Sign() < 0 {
+		return errors.New("maxInventory and minSpreadRatio should not be negative")
+	}
+
+	return nil
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.Interval})
+}
+
+func (s *Strategy) Run(ctx context.Context, _ bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	instanceID := s.InstanceID()
+
+	s.session = session
+
+	if s.Position == nil {
+		s.Position = types.NewPositionFromMarket(s.Market)
+	}
+
+	// Always update the position fields
+	s.Position.Strategy = ID
+	s.Position.StrategyInstanceID = instanceID
+
+	if s.session.MakerFeeRate.Sign() > 0 || s.session.TakerFeeRate.Sign() > 0 {
+		s.Position.SetExchangeFeeRate(s.session.ExchangeName, types.ExchangeFee{
+			MakerFeeRate: s.session.MakerFeeRate,
+			TakerFeeRate: s.session.TakerFeeRate,
+		})
+	}
+
+	if s.ProfitStats == nil {
+		s.ProfitStats = types.NewProfitStats(s.Market)
+	}
+
+	s.orderExecutor = bbgo.NewGeneralOrderExecutor(session, s.Symbol, ID, instanceID, s.Position)
+	s.orderExecutor.BindEnvironment(s.Environment)
+	s.orderExecutor.BindProfitStats(s.ProfitStats)
+	s.orderExecutor.Bind()
+	s.orderExecutor.TradeCollector().OnPositionUpdate(func(position *types.Position) {
+		bbgo.Sync(ctx, s)
+	})
+
+	s.Status = types.StrategyStatusRunning
+	s.OnSuspend(func() {
+		_ = s.orderExecutor.GracefulCancel(ctx)
+		bbgo.Sync(ctx, s)
+	})
+	s.OnEmergencyStop(func() {
+		_ = s.orderExecutor.GracefulCancel(ctx)
+		_ = s.orderExecutor.ClosePosition(ctx, fixedpoint.One)
+	})
+
+	kLines := indicator.KLines(session.MarketDataStream, s.Symbol, s.Interval)
+	s.volatility = indicator.StdDev2(closeChanges(kLines), s.Window)
+	if store, ok := session.MarketDataStore(s.Symbol); ok {
+		if kLinesData, ok := store.KLinesOfInterval(s.Interval); ok {
+			for _, k := range *kLinesData {
+				kLines.EmitUpdate(k)
+			}
+		}
+	}
+
+	session.MarketDataStream.OnKLineClosed(types.KLineWith(s.Symbol, s.Interval, func(k types.KLine) {
+		s.updateQuotes(ctx, k.EndTime.Time())
+	}))
+
+	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		_ = s.orderExecutor.GracefulCancel(ctx)
+		bbgo.Sync(ctx, s)
+	})
+
+	return nil
+}
+
+func (s *Strategy) updateQuotes(ctx context.Context, now time.Time) {
+	if s.Status != types.StrategyStatusRunning {
+		return
+	}
+
+	if err := s.orderExecutor.GracefulCancel(ctx); err != nil {
+		log.WithError(err).Errorf("unable to cancel orders")
+		return
+	}
+
+	if s.volatility.Length() < s.Window {
+		log.Infof("%s volatility is not ready, %d < %d price changes", s.Symbol, s.volatility.Length(), s.Window)
+		return
+	}
+
+	ticker, err := s.session.Exchange.QueryTicker(ctx, s.Symbol)
+	if err != nil {
+		log.WithError(err).Errorf("unable to query ticker")
+		return
+	}
+
+	sigma := s.volatility.Last(0)
+	variance := sigma * sigma
+	midPrice := ticker.Buy.Add(ticker.Sell).Div(fixedpoint.Two)
+	inventory := s.Position.GetBase().Sub(s.TargetInventory)
+	tau := remainingHorizon(now, s.Horizon.Duration(), s.Interval.Duration())
+
+	bidPrice, askPrice := s.quotePrices(midPrice, inventory, variance, tau)
+
+	// the maker orders must not cross the book
+	bidPrice = fixedpoint.Min(bidPrice, ticker.Sell.Sub(s.Market.TickSize))
+	askPrice = fixedpoint.Max(askPrice, ticker.Buy.Add(s.Market.TickSize))
+
+	log.Infof("%s mid price: %f, inventory: %f, sigma: %f, tau: %f, bid: %f, ask: %f",
+		s.Symbol, midPrice.Float64(), inventory.Float64(), sigma, tau, bidPrice.Float64(), askPrice.Float64())
+
+	orders := s.generateSubmitOrders(inventory, bidPrice, askPrice)
+	if len(orders) == 0 || s.DryRun {
+		return
+	}
+
+	if _, err := s.orderExecutor.SubmitOrders(ctx, orders...); err != nil {
+		log.WithError(err).Errorf("unable to submit orders")
+	}
+}
+
+// quotePrices returns the bid and the ask prices of the model, widened to the min spread and truncated by the tick size
+func (s *Strategy) quotePrices(midPrice, inventory fixedpoint.Value, variance, tau float64) (bidPrice, askPrice fixedpoint.Value) {
+	model := Model{Gamma: s.Gamma, Kappa: s.Kappa}
+	bid, ask := model.Quotes(midPrice.Float64(), inventory.Float64(), variance, tau)
+	bidPrice, askPrice = fixedpoint.NewFromFloat(bid), fixedpoint.NewFromFloat(ask)
+
+	minSpread := midPrice.Mul(s.MinSpreadRatio)
+	if askPrice.Sub(bidPrice).Compare(minSpread) < 0 {
+		reservationPrice := bidPrice.Add(askPrice).Div(fixedpoint.Two)
+		bidPrice = reservationPrice.Sub(minSpread.Div(fixedpoint.Two))
+		askPrice = reservationPrice.Add(minSpread.Div(fixedpoint.Two))
+	}
+
+	bidPrice = s.Market.TruncatePrice(bidPrice)
+	askPrice = s.Market.TruncatePrice(askPrice)
+	if askPrice.Compare(bidPrice) <= 0 {
+		askPrice = bidPrice.Add(s.Market.TickSize)
+	}
+
+	return bidPrice, askPrice
+}
+
+func (s *Strategy) generateSubmitOrders(inventory, bidPrice, askPrice fixedpoint.Value) (orders []types.SubmitOrder) {
+	baseBalance, _ := s.session.GetAccount().Balance(s.Market.BaseCurrency)
+	quoteBalance, _ := s.session.GetAccount().Balance(s.Market.QuoteCurrency)
+
+	canBuy := bidPrice.Sign() > 0 && quoteBalance.Available.Compare(s.Quantity.Mul(bidPrice)) >= 0
+	canSell := baseBalance.Available.Compare(s.Quantity) >= 0
+
+	if s.MaxInventory.Sign() > 0 {
+		if inventory.Compare(s.MaxInventory) >= 0 {
+			log.Infof("%s inventory %f reaches the max inventory, stop buying", s.Symbol, inventory.Float64())
+			canBuy = false
+		} else if inventory.Neg().Compare(s.MaxInventory) >= 0 {
+			log.Infof("%s inventory %f reaches the max inventory, stop selling", s.Symbol, inventory.Float64())
+			canSell = false
+		}
+	}
+
+	if canBuy {
+		orders = append(orders, types.SubmitOrder{
+			Symbol:      s.Symbol,
+			Market:      s.Market,
+			Side:        types.SideTypeBuy,
+			Type:        types.OrderTypeLimitMaker,
+			Price:       bidPrice,
+			Quantity:    s.Quantity,
+			TimeInForce: types.TimeInForceGTC,
+			Tag:         "askewBid",
+		})
+	}
+
+	if canSell {
+		orders = append(orders, types.SubmitOrder{
+			Symbol:      s.Symbol,
+			Market:      s.Market,
+			Side:        types.SideTypeSell,
+			Type:        types.OrderTypeLimitMaker,
+			Price:       askPrice,
+			Quantity:    s.Quantity,
+			TimeInForce: types.TimeInForceGTC,
+			Tag:         "askewAsk",
+		})
+	}
+
+	return orders
+}
+
+// closeChanges pushes the close price change of each kline from the previous kline
+func closeChanges(source indicator.KLineSubscription) *indicator.Float64Series {
+	s := indicator.NewFloat64Series()
+
+	var lastClose fixedpoint.Value
+	source.AddSubscriber(func(k types.KLine) {
+		if !lastClose.IsZero() {
+			s.PushAndEmit(k.Close.Sub(lastClose).Float64())
+		}
+
+		lastClose = k.Close
+	})
+
+	return s
+}
@@ -0,0 +1,70 @@
+package basis
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var oneYear = fixedpoint.NewFromFloat((365 * 24 * time.Hour).Seconds())
+
+// Contract is a dated futures contract of the futures session
+type Contract struct {
+	// Symbol is the futures market symbol of the contract, for example, BTCUSDT_230929
+	Symbol string `json:"symbol"`
+
+	// Expiry is the delivery time of the contract
+	Expiry types.LooseFormatTime `json:"expiry"`
+}
+
+func (c Contract) ExpiryTime() time.Time {
+	return c.Expiry.Time()
+}
+
+// shouldRoll returns true when the contract is going to expire in the roll period
+func (c Contract) shouldRoll(now time.Time, rollBefore time.Duration) bool {
+	return !now.Before(c.ExpiryTime().Add(-rollBefore))
+}
+
+// findContract returns the contract of the given symbol
+func findContract(contracts []Contract, symbol string) (Contract, bool) {
+	for _, c := range contracts {
+		if c.Symbol == symbol {
+			return c, true
+		}
+	}
+
+	return Contract{}, false
+}
+
+// nextContract returns the nearest contract that is not in the roll period at the given time,
+// the contracts expire before the given contract are skipped.
+func nextContract(contracts []Contract, after time.Time, now time.Time, rollBefore time.Duration) (Contract, bool) {
+	var found Contract
+	var ok bool
+	for _, c := range contracts {
+		if !c.ExpiryTime().After(after) || c.shouldRoll(now, rollBefore) {
+			continue
+		}
+
+		if !ok || c.ExpiryTime().Before(found.ExpiryTime()) {
+			found = c
+			ok = true
+		}
+	}
+
+	return found, ok
+}
+
+// annualizedBasis returns the futures premium over the spot price in annual rate,
+// for example, 1% premium with 36.5 days to the expiry is 10% annualized basis.
+func annualizedBasis(spotPrice, futuresPrice fixedpoint.Value, expiry, now time.Time) fixedpoint.Value {
+	ttl := expiry.Sub(now)
+	if ttl <= 0 || spotPrice.Sign() <= 0 {
+		return fixedpoint.Zero
+	}
+
+	premium := futuresPrice.Sub(spotPrice).Div(spotPrice)
+	return premium.Mul(oneYear).Div(fixedpoint.NewFromFloat(ttl.Seconds()))
+}
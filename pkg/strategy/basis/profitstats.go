@@ -0,0 +1,70 @@
+package basis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/style"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// CarryRecord is the basis captured when the hedge is reduced, closed or rolled
+type CarryRecord struct {
+	Contract   string           `json:"contract"`
+	Reason     string           `json:"reason"`
+	Quantity   fixedpoint.Value `json:"quantity"`
+	EntryBasis fixedpoint.Value `json:"entryBasis"`
+	ExitBasis  fixedpoint.Value `json:"exitBasis"`
+	PnL        fixedpoint.Value `json:"pnl"`
+	Time       time.Time        `json:"time"`
+}
+
+func (r *CarryRecord) SlackAttachment() slack.Attachment {
+	return slack.Attachment{
+		Title: fmt.Sprintf("Basis Carry (%s) %s", r.Reason, style.PnLSignString(r.PnL)),
+		Color: style.PnLColor(r.PnL),
+		Fields: []slack.AttachmentField{
+			{Title: "Contract", Value: r.Contract, Short: true},
+			{Title: "Quantity", Value: r.Quantity.String(), Short: true},
+			{Title: "Entry Basis", Value: r.EntryBasis.String(), Short: true},
+			{Title: "Exit Basis", Value: r.ExitBasis.String(), Short: true},
+		},
+		Footer: r.Time.Format(time.RFC822),
+	}
+}
+
+// ProfitStats reports the carry PnL (the basis captured between the spot leg and the futures leg)
+// separately from the trading profit stats of the legs
+type ProfitStats struct {
+	*types.ProfitStats
+
+	CarryCurrency string           `json:"carryCurrency"`
+	TotalCarryPnL fixedpoint.Value `json:"totalCarryPnL"`
+	CarryRecords  []CarryRecord    `json:"carryRecords"`
+}
+
+func newProfitStats(market types.Market) *ProfitStats {
+	return &ProfitStats{
+		ProfitStats:   types.NewProfitStats(market),
+		CarryCurrency: market.QuoteCurrency,
+		TotalCarryPnL: fixedpoint.Zero,
+	}
+}
+
+func (s *ProfitStats) AddCarry(record CarryRecord) {
+	s.CarryRecords = append(s.CarryRecords, record)
+	s.TotalCarryPnL = s.TotalCarryPnL.Add(record.PnL)
+}
+
+func (s *ProfitStats) SlackAttachment() slack.Attachment {
+	attachment := s.ProfitStats.SlackAttachment()
+	attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+		Title: "Carry PnL",
+		Value: style.PnLSignString(s.TotalCarryPnL) + " " + s.CarryCurrency,
+		Short: true,
+	})
+	return attachment
+}
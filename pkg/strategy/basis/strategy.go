@@ -0,0 +1,619 @@
+package basis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "basis"
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// OrderExecutor submits the orders of a leg
+//
+//go:generate mockgen -destination=mocks/order_executor.go -package=mocks . OrderExecutor
+type OrderExecutor interface {
+	SubmitOrders(ctx context.Context, submitOrders ...types.SubmitOrder) (types.OrderSlice, error)
+}
+
+// State is the hedge of the basis trade
+type State struct {
+	// Contract is the futures contract symbol of the short leg
+	Contract string `json:"contract"`
+
+	// HedgedQuantity is the base quantity of the spot long leg and the futures short leg
+	HedgedQuantity fixedpoint.Value `json:"hedgedQuantity"`
+
+	// EntryBasis is the average futures premium (futures price - spot price) of the hedged quantity
+	EntryBasis fixedpoint.Value `json:"entryBasis"`
+
+	// PendingSpotUnwind is the unhedged spot quantity that failed to be sold after its futures leg was closed,
+	// the spot sell is retried in the next intervals
+	PendingSpotUnwind fixedpoint.Value `json:"pendingSpotUnwind,omitempty"`
+}
+
+func (s *State) addHedge(quantity, basis fixedpoint.Value) {
+	total := s.HedgedQuantity.Add(quantity)
+	if total.Sign() <= 0 {
+		return
+	}
+
+	s.EntryBasis = s.EntryBasis.Mul(s.HedgedQuantity).Add(basis.Mul(quantity)).Div(total)
+	s.HedgedQuantity = total
+}
+
+// reduceHedge reduces the hedged quantity and returns the carry pnl of the reduced quantity
+func (s *State) reduceHedge(quantity, basis fixedpoint.Value) fixedpoint.Value {
+	quantity = fixedpoint.Min(quantity, s.HedgedQuantity)
+	pnl := s.EntryBasis.Sub(basis).Mul(quantity)
+
+	s.HedgedQuantity = s.HedgedQuantity.Sub(quantity)
+	if s.HedgedQuantity.Sign() <= 0 {
+		s.HedgedQuantity = fixedpoint.Zero
+		s.EntryBasis = fixedpoint.Zero
+	}
+
+	return pnl
+}
+
+// Strategy is the delta-neutral basis trade strategy,
+// it buys the spot and shorts the same quantity of the dated futures contract when the annualized basis is high,
+// then closes both legs when the basis converges, the short leg is rolled to the next contract before the expiry.
+type Strategy struct {
+	Environment *bbgo.Environment
+
+	SpotSession    string `json:"spotSession"`
+	FuturesSession string `json:"futuresSession"`
+
+	// Symbol is the spot market symbol
+	Symbol string `json:"symbol"`
+
+	// Contracts are the dated futures contracts for the short leg,
+	// the nearest contract is used and the hedge is rolled to the next contract before the expiry
+	Contracts []Contract `json:"contracts"`
+
+	// Interval is the interval to check the basis
+	Interval types.Interval `json:"interval"`
+
+	// EntryBasis is the min annualized basis to open the hedge, for example, 0.1 for 10%
+	EntryBasis fixedpoint.Value `json:"entryBasis"`
+
+	// ExitBasis is the annualized basis to close the hedge
+	ExitBasis fixedpoint.Value `json:"exitBasis"`
+
+	// Quantity is the base quantity to open or close in each interval
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// QuoteInvestment is split into the spot leg and the margin of the futures leg by the leverage
+	QuoteInvestment fixedpoint.Value `json:"quoteInvestment"`
+
+	// Leverage is the leverage of the futures leg, defaults to 1
+	Leverage fixedpoint.Value `json:"leverage,omitempty"`
+
+	// RollBefore is the duration before the expiry to roll the hedge to the next contract, defaults to 24h
+	RollBefore types.Duration `json:"rollBefore,omitempty"`
+
+	// MaxMarginRatio is the max ratio of the used margin to the margin balance of the futures account,
+	// both legs are reduced by the DeleverageRatio when it's exceeded. defaults to 0.8
+	MaxMarginRatio fixedpoint.Value `json:"maxMarginRatio,omitempty"`
+
+	// DeleverageRatio is the ratio of the hedged quantity to reduce, defaults to 0.25
+	DeleverageRatio fixedpoint.Value `json:"deleverageRatio,omitempty"`
+
+	// Reset resets the positions, the profit stats and the hedge state
+	Reset bool `json:"reset"`
+
+	ProfitStats *ProfitStats `persistence:"profit_stats"`
+
+	// SpotPosition is the long leg position
+	SpotPosition *types.Position `persistence:"spot_position"`
+
+	// FuturesPosition is the short leg position of the current contract
+	FuturesPosition *types.Position `persistence:"futures_position"`
+
+	State *State `persistence:"state"`
+
+	mu sync.Mutex
+
+	spotSession, futuresSession             *bbgo.ExchangeSession
+	spotOrderExecutor, futuresOrderExecutor OrderExecutor
+	spotMarket, futuresMarket               types.Market
+
+	// futuresOrderExecutors are the order executors of the contracts, they are allocated once in CrossRun
+	// since the executors can not be unbound from the user data stream, the roll switches to the executor of the next contract.
+	futuresOrderExecutors map[string]*bbgo.GeneralOrderExecutor
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) InstanceID() string {
+	return fmt.Sprintf("%s-%s", ID, s.Symbol)
+}
+
+func (s *Strategy) Defaults() error {
+	if s.Interval == "" {
+		s.Interval = types.Interval1m
+	}
+
+	if s.Leverage.IsZero() {
+		s.Leverage = fixedpoint.One
+	}
+
+	if s.RollBefore == 0 {
+		s.RollBefore = types.Duration(24 * time.Hour)
+	}
+
+	if s.MaxMarginRatio.IsZero() {
+		s.MaxMarginRatio = fixedpoint.NewFromFloat(0.8)
+	}
+
+	if s.DeleverageRatio.IsZero() {
+		s.DeleverageRatio = fixedpoint.NewFromFloat(0.25)
+	}
+
+	return nil
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return errors.New("symbol is required")
+	}
+
+	if len(s.SpotSession) == 0 {
+		return errors.New("spotSession name is required")
+	}
+
+	if len(s.FuturesSession) == 0 {
+		return errors.New("futuresSession name is required")
+	}
+
+	if len(s.Contracts) == 0 {
+		return errors.New("contracts can not be empty")
+	}
+
+	for _, c := range s.Contracts {
+		if len(c.Symbol) == 0 || c.ExpiryTime().IsZero() {
+			return fmt.Errorf("contract %+v requires symbol and expiry", c)
+		}
+	}
+
+	if s.EntryBasis.Compare(s.ExitBasis) <= 0 {
+		return errors.New("entryBasis should be greater than exitBasis")
+	}
+
+	if s.Quantity.Sign() <= 0 {
+		return errors.New("quantity should be greater than zero")
+	}
+
+	if s.QuoteInvestment.Sign() <= 0 {
+		return errors.New("quoteInvestment should be greater than zero")
+	}
+
+	return nil
+}
+
+func (s *Strategy) CrossSubscribe(sessions map[string]*bbgo.ExchangeSession) {
+	spotSession, ok := sessions[s.SpotSession]
+	if !ok {
+		return
+	}
+
+	futuresSession, ok := sessions[s.FuturesSession]
+	if !ok {
+		return
+	}
+
+	spotSession.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.Interval})
+	for _, c := range s.Contracts {
+		futuresSession.Subscribe(types.KLineChannel, c.Symbol, types.SubscribeOptions{Interval: s.Interval})
+	}
+}
+
+func (s *Strategy) CrossRun(ctx context.Context, _ bbgo.OrderExecutionRouter, sessions map[string]*bbgo.ExchangeSession) error {
+	var ok bool
+	s.spotSession, ok = sessions[s.SpotSession]
+	if !ok {
+		return fmt.Errorf("spot session %s is not defined", s.SpotSession)
+	}
+
+	s.futuresSession, ok = sessions[s.FuturesSession]
+	if !ok {
+		return fmt.Errorf("futures session %s is not defined", s.FuturesSession)
+	}
+
+	s.spotMarket, ok = s.spotSession.Market(s.Symbol)
+	if !ok {
+		return fmt.Errorf("spot market %s is not defined", s.Symbol)
+	}
+
+	if s.ProfitStats == nil || s.Reset {
+		s.ProfitStats = newProfitStats(s.spotMarket)
+	}
+
+	if s.SpotPosition == nil || s.Reset {
+		s.SpotPosition = types.NewPositionFromMarket(s.spotMarket)
+	}
+
+	if s.State == nil || s.Reset {
+		s.State = &State{}
+	}
+
+	if s.State.Contract == "" {
+		c, ok := nextContract(s.Contracts, time.Time{}, time.Now(), s.RollBefore.Duration())
+		if !ok {
+			return errors.New("all the contracts are expired or in the roll period")
+		}
+
+		s.State.Contract = c.Symbol
+	}
+
+	s.futuresMarket, ok = s.futuresSession.Market(s.State.Contract)
+	if !ok {
+		return fmt.Errorf("futures market %s is not defined", s.State.Contract)
+	}
+
+	if s.FuturesPosition == nil || s.Reset {
+		s.FuturesPosition = types.NewPositionFromMarket(s.futuresMarket)
+	}
+
+	log.Infof("loaded spot position: %s", s.SpotPosition.String())
+	log.Infof("loaded futures position: %s", s.FuturesPosition.String())
+	log.Infof("hedged %s on %s with entry basis %s",
+		s.State.HedgedQuantity.String(), s.State.Contract, s.State.EntryBasis.String())
+
+	instanceID := s.InstanceID()
+	s.spotOrderExecutor = s.allocateOrderExecutor(ctx, s.spotSession, s.Symbol, instanceID, s.SpotPosition)
+	s.futuresOrderExecutors = make(map[string]*bbgo.GeneralOrderExecutor)
+	for _, c := range s.Contracts {
+		if c.Symbol == s.State.Contract {
+			s.futuresOrderExecutors[c.Symbol] = s.allocateOrderExecutor(ctx, s.futuresSession, c.Symbol, instanceID, s.FuturesPosition)
+			continue
+		}
+
+		market, ok := s.futuresSession.Market(c.Symbol)
+		if !ok {
+			log.Warnf("futures market %s is not defined, the hedge can not be rolled to it", c.Symbol)
+			continue
+		}
+
+		s.futuresOrderExecutors[c.Symbol] = s.allocateOrderExecutor(ctx, s.futuresSession, c.Symbol, instanceID, types.NewPositionFromMarket(market))
+	}
+
+	futuresOrderExecutor, ok := s.futuresOrderExecutors[s.State.Contract]
+	if !ok {
+		futuresOrderExecutor = s.allocateOrderExecutor(ctx, s.futuresSession, s.State.Contract, instanceID, s.FuturesPosition)
+		s.futuresOrderExecutors[s.State.Contract] = futuresOrderExecutor
+	}
+	s.futuresOrderExecutor = futuresOrderExecutor
+
+	s.spotSession.MarketDataStream.OnKLineClosed(types.KLineWith(s.Symbol, s.Interval, func(kline types.KLine) {
+		s.rebalance(ctx, kline.EndTime.Time())
+	}))
+
+	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+		bbgo.Sync(ctx, s)
+	})
+
+	return nil
+}
+
+func (s *Strategy) allocateOrderExecutor(ctx context.Context, session *bbgo.ExchangeSession, symbol, instanceID string, position *types.Position) *bbgo.GeneralOrderExecutor {
+	orderExecutor := bbgo.NewGeneralOrderExecutor(session, symbol, ID, instanceID, position)
+	orderExecutor.BindEnvironment(s.Environment)
+	orderExecutor.Bind()
+	orderExecutor.TradeCollector().OnPositionUpdate(func(position *types.Position) {
+		bbgo.Sync(ctx, s)
+	})
+	orderExecutor.TradeCollector().OnTrade(func(trade types.Trade, profit fixedpoint.Value, netProfit fixedpoint.Value) {
+		s.ProfitStats.AddTrade(trade)
+		if profit.IsZero() {
+			return
+		}
+
+		s.ProfitStats.AddProfit(position.NewProfit(trade, profit, netProfit))
+	})
+	return orderExecutor
+}
+
+func (s *Strategy) rebalance(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contract, ok := findContract(s.Contracts, s.State.Contract)
+	if !ok {
+		log.Errorf("contract %s is not defined in the contracts", s.State.Contract)
+		return
+	}
+
+	spotPrice, ok := s.spotSession.LastPrice(s.Symbol)
+	if !ok {
+		return
+	}
+
+	futuresPrice, ok := s.futuresSession.LastPrice(contract.Symbol)
+	if !ok {
+		return
+	}
+
+	// the hedge is not changed until the unhedged spot quantity is sold
+	if s.State.PendingSpotUnwind.Sign() > 0 {
+		s.retrySpotUnwind(ctx)
+		return
+	}
+
+	hedged := s.State.HedgedQuantity
+	if contract.shouldRoll(now, s.RollBefore.Duration()) {
+		if hedged.Sign() > 0 {
+			s.roll(ctx, contract, spotPrice, futuresPrice, now)
+		} else if next, ok := nextContract(s.Contracts, contract.ExpiryTime(), now, s.RollBefore.Duration()); ok {
+			if err := s.switchContract(next); err != nil {
+				log.WithError(err).Errorf("unable to switch to the contract %s", next.Symbol)
+			}
+		}
+		return
+	}
+
+	if hedged.Sign() > 0 {
+		if ratio, ok := s.futuresMarginRatio(futuresPrice); ok && ratio.Compare(s.MaxMarginRatio) >= 0 {
+			quantity := hedged.Mul(s.DeleverageRatio)
+			bbgo.Notify("%s: futures margin ratio %s exceeds %s, reducing the hedge by %s",
+				s.InstanceID(), ratio.Percentage(), s.MaxMarginRatio.Percentage(), quantity.String())
+			s.closeHedge(ctx, contract, quantity, spotPrice, futuresPrice, "deleverage", now)
+			return
+		}
+	}
+
+	basis := annualizedBasis(spotPrice, futuresPrice, contract.ExpiryTime(), now)
+	switch {
+	case basis.Compare(s.EntryBasis) >= 0:
+		quantity := fixedpoint.Min(s.Quantity, s.maxHedgeQuantity(spotPrice, futuresPrice).Sub(hedged))
+		if quantity.Sign() > 0 {
+			log.Infof("annualized basis %s of %s is above %s, opening %s",
+				basis.Percentage(), contract.Symbol, s.EntryBasis.Percentage(), quantity.String())
+			s.openHedge(ctx, contract, quantity, spotPrice, futuresPrice)
+		}
+
+	case basis.Compare(s.ExitBasis) <= 0 && hedged.Sign() > 0:
+		quantity := fixedpoint.Min(s.Quantity, hedged)
+		log.Infof("annualized basis %s of %s is below %s, closing %s",
+			basis.Percentage(), contract.Symbol, s.ExitBasis.Percentage(), quantity.String())
+		s.closeHedge(ctx, contract, quantity, spotPrice, futuresPrice, "close", now)
+	}
+}
+
+// maxHedgeQuantity returns the max hedged quantity of the quote investment and the balances of both legs,
+// the quote investment is split into the spot leg and the futures margin, with leverage L,
+// L/(L+1) of the investment buys the spot and 1/(L+1) of the investment is the margin of the short leg.
+func (s *Strategy) maxHedgeQuantity(spotPrice, futuresPrice fixedpoint.Value) fixedpoint.Value {
+	hedged := s.State.HedgedQuantity
+	legQuote := s.QuoteInvestment.Mul(s.Leverage).Div(s.Leverage.Add(fixedpoint.One))
+	maxQuantity := legQuote.Div(fixedpoint.Max(spotPrice, futuresPrice))
+
+	if b, ok := s.spotSession.GetAccount().Balance(s.spotMarket.QuoteCurrency); ok {
+		maxQuantity = fixedpoint.Min(maxQuantity, hedged.Add(b.Available.Div(spotPrice)))
+	}
+
+	if b, ok := s.futuresSession.GetAccount().Balance(s.futuresMarket.QuoteCurrency); ok {
+		maxQuantity = fixedpoint.Min(maxQuantity, hedged.Add(b.Available.Mul(s.Leverage).Div(futuresPrice)))
+	}
+
+	return maxQuantity
+}
+
+// futuresMarginRatio returns the ratio of the used margin to the margin balance of the futures account,
+// it's estimated from the short leg and the quote balance when the futures account info is not available.
+func (s *Strategy) futuresMarginRatio(futuresPrice fixedpoint.Value) (fixedpoint.Value, bool) {
+	account := s.futuresSession.GetAccount()
+	if info := account.FuturesInfo; info != nil && info.TotalMarginBalance.Sign() > 0 {
+		return info.TotalPositionInitialMargin.Div(info.TotalMarginBalance), true
+	}
+
+	b, ok := account.Balance(s.futuresMarket.QuoteCurrency)
+	if !ok {
+		return fixedpoint.Zero, false
+	}
+
+	marginBalance := b.Total().Add(s.FuturesPosition.UnrealizedProfit(futuresPrice))
+	if marginBalance.Sign() <= 0 {
+		return fixedpoint.One, true
+	}
+
+	usedMargin := s.FuturesPosition.GetBase().Abs().Mul(futuresPrice).Div(s.Leverage)
+	return usedMargin.Div(marginBalance), true
+}
+
+// adjustQuantity truncates the quantity for both markets, zero is returned for the dust quantity
+func (s *Strategy) adjustQuantity(quantity, spotPrice, futuresPrice fixedpoint.Value) fixedpoint.Value {
+	quantity = s.spotMarket.TruncateQuantity(s.futuresMarket.TruncateQuantity(quantity))
+	if s.spotMarket.IsDustQuantity(quantity, spotPrice) || s.futuresMarket.IsDustQuantity(quantity, futuresPrice) {
+		return fixedpoint.Zero
+	}
+
+	return quantity
+}
+
+func (s *Strategy) spotOrder(side types.SideType, quantity fixedpoint.Value) types.SubmitOrder {
+	return types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Side:     side,
+		Type:     types.OrderTypeMarket,
+		Quantity: quantity,
+		Market:   s.spotMarket,
+		Tag:      ID,
+	}
+}
+
+func (s *Strategy) futuresOrder(side types.SideType, quantity fixedpoint.Value, reduceOnly bool) types.SubmitOrder {
+	return types.SubmitOrder{
+		Symbol:     s.futuresMarket.Symbol,
+		Side:       side,
+		Type:       types.OrderTypeMarket,
+		Quantity:   quantity,
+		Market:     s.futuresMarket,
+		ReduceOnly: reduceOnly,
+		Tag:        ID,
+	}
+}
+
+// openHedge buys the spot leg and then shorts the futures leg, the spot leg is unwound if the futures order fails
+func (s *Strategy) openHedge(ctx context.Context, contract Contract, quantity, spotPrice, futuresPrice fixedpoint.Value) {
+	quantity = s.adjustQuantity(quantity, spotPrice, futuresPrice)
+	if quantity.IsZero() {
+		return
+	}
+
+	if _, err := s.spotOrderExecutor.SubmitOrders(ctx, s.spotOrder(types.SideTypeBuy, quantity)); err != nil {
+		log.WithError(err).Errorf("unable to open the spot leg")
+		return
+	}
+
+	if _, err := s.futuresOrderExecutor.SubmitOrders(ctx, s.futuresOrder(types.SideTypeSell, quantity, false)); err != nil {
+		log.WithError(err).Errorf("unable to open the futures leg, unwinding the spot leg")
+		s.unwindSpot(ctx, quantity)
+		bbgo.Sync(ctx, s)
+		return
+	}
+
+	s.State.addHedge(quantity, futuresPrice.Sub(spotPrice))
+	bbgo.Notify("%s: hedged %s %s with %s, average entry basis %s",
+		s.InstanceID(), s.State.HedgedQuantity.String(), s.Symbol, contract.Symbol, s.State.EntryBasis.String())
+	bbgo.Sync(ctx, s)
+}
+
+// closeHedge buys back the futures leg and then sells the spot leg, the captured basis is recorded as the carry pnl.
+// The hedge is reduced once the futures leg is closed, the spot quantity is left to PendingSpotUnwind if it can not be sold.
+func (s *Strategy) closeHedge(ctx context.Context, contract Contract, quantity, spotPrice, futuresPrice fixedpoint.Value, reason string, now time.Time) {
+	if quantity.Compare(s.State.HedgedQuantity) < 0 {
+		quantity = s.adjustQuantity(quantity, spotPrice, futuresPrice)
+	}
+
+	if quantity.IsZero() {
+		return
+	}
+
+	if _, err := s.futuresOrderExecutor.SubmitOrders(ctx, s.futuresOrder(types.SideTypeBuy, quantity, true)); err != nil {
+		log.WithError(err).Errorf("unable to close the futures leg")
+		return
+	}
+
+	// the short leg is gone, the reduce-only buy can not be submitted again
+	s.recordCarry(contract, quantity, futuresPrice.Sub(spotPrice), reason, now)
+	s.unwindSpot(ctx, quantity)
+	bbgo.Sync(ctx, s)
+}
+
+// roll buys back the short leg of the expiring contract and shorts the next contract,
+// the hedge is closed when there is no next contract to roll to.
+func (s *Strategy) roll(ctx context.Context, contract Contract, spotPrice, futuresPrice fixedpoint.Value, now time.Time) {
+	quantity := s.State.HedgedQuantity
+	next, ok := nextContract(s.Contracts, contract.ExpiryTime(), now, s.RollBefore.Duration())
+	if !ok {
+		bbgo.Notify("%s: no contract to roll %s to, closing the hedge", s.InstanceID(), contract.Symbol)
+		s.closeHedge(ctx, contract, quantity, spotPrice, futuresPrice, "expiry", now)
+		return
+	}
+
+	nextPrice, ok := s.futuresSession.LastPrice(next.Symbol)
+	if !ok {
+		log.Warnf("the price of %s is not ready, the roll is delayed", next.Symbol)
+		return
+	}
+
+	if _, err := s.futuresOrderExecutor.SubmitOrders(ctx, s.futuresOrder(types.SideTypeBuy, quantity, true)); err != nil {
+		log.WithError(err).Errorf("unable to close the futures leg of %s", contract.Symbol)
+		return
+	}
+
+	s.recordCarry(contract, quantity, futuresPrice.Sub(spotPrice), "roll", now)
+
+	if err := s.switchContract(next); err != nil {
+		log.WithError(err).Errorf("unable to switch to the contract %s", next.Symbol)
+		s.unwindSpot(ctx, quantity)
+		bbgo.Sync(ctx, s)
+		return
+	}
+
+	if _, err := s.futuresOrderExecutor.SubmitOrders(ctx, s.futuresOrder(types.SideTypeSell, quantity, false)); err != nil {
+		log.WithError(err).Errorf("unable to open the futures leg of %s", next.Symbol)
+		s.unwindSpot(ctx, quantity)
+		bbgo.Sync(ctx, s)
+		return
+	}
+
+	s.State.addHedge(quantity, nextPrice.Sub(spotPrice))
+	bbgo.Notify("%s: rolled %s %s from %s to %s", s.InstanceID(), quantity.String(), s.Symbol, contract.Symbol, next.Symbol)
+	bbgo.Sync(ctx, s)
+}
+
+// unwindSpot sells the unhedged spot quantity, the quantity is added to PendingSpotUnwind if the sell fails
+func (s *Strategy) unwindSpot(ctx context.Context, quantity fixedpoint.Value) {
+	if _, err := s.spotOrderExecutor.SubmitOrders(ctx, s.spotOrder(types.SideTypeSell, quantity)); err != nil {
+		s.State.PendingSpotUnwind = s.State.PendingSpotUnwind.Add(quantity)
+		log.WithError(err).Errorf("unable to unwind the spot leg, pending spot unwind %s", s.State.PendingSpotUnwind.String())
+		bbgo.Notify("%s: unable to unwind the unhedged spot quantity %s, retrying in the next interval", s.InstanceID(), quantity.String())
+	}
+}
+
+// retrySpotUnwind sells the pending unhedged spot quantity
+func (s *Strategy) retrySpotUnwind(ctx context.Context) {
+	quantity := s.State.PendingSpotUnwind
+	if _, err := s.spotOrderExecutor.SubmitOrders(ctx, s.spotOrder(types.SideTypeSell, quantity)); err != nil {
+		log.WithError(err).Errorf("unable to unwind the pending spot quantity %s", quantity.String())
+		return
+	}
+
+	s.State.PendingSpotUnwind = fixedpoint.Zero
+	bbgo.Notify("%s: unwound the unhedged spot quantity %s", s.InstanceID(), quantity.String())
+	bbgo.Sync(ctx, s)
+}
+
+func (s *Strategy) recordCarry(contract Contract, quantity, basis fixedpoint.Value, reason string, now time.Time) {
+	record := CarryRecord{
+		Contract:   contract.Symbol,
+		Reason:     reason,
+		Quantity:   quantity,
+		EntryBasis: s.State.EntryBasis,
+		ExitBasis:  basis,
+		Time:       now,
+	}
+
+	record.PnL = s.State.reduceHedge(quantity, basis)
+	s.ProfitStats.AddCarry(record)
+	bbgo.Notify(&record)
+	bbgo.Notify(s.ProfitStats)
+}
+
+// switchContract moves the short leg to the given contract with the order executor and the position of the contract
+func (s *Strategy) switchContract(contract Contract) error {
+	market, ok := s.futuresSession.Market(contract.Symbol)
+	if !ok {
+		return fmt.Errorf("futures market %s is not defined", contract.Symbol)
+	}
+
+	orderExecutor, ok := s.futuresOrderExecutors[contract.Symbol]
+	if !ok {
+		return fmt.Errorf("the order executor of %s is not allocated", contract.Symbol)
+	}
+
+	s.State.Contract = contract.Symbol
+	s.futuresMarket = market
+	s.futuresOrderExecutor = orderExecutor
+	s.FuturesPosition = orderExecutor.Position()
+	return nil
+}
@@ -0,0 +1,132 @@
+package basis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/strategy/basis/mocks"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newTestContract(symbol string, expiry time.Time) Contract {
+	return Contract{Symbol: symbol, Expiry: types.LooseFormatTime(expiry)}
+}
+
+func Test_annualizedBasis(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	expiry := now.Add(time.Duration(36.5 * float64(24*time.Hour)))
+
+	basis := annualizedBasis(fixedpoint.NewFromFloat(100.0), fixedpoint.NewFromFloat(101.0), expiry, now)
+	assert.InDelta(t, 0.1, basis.Float64(), 0.0001)
+
+	assert.Equal(t, fixedpoint.Zero, annualizedBasis(fixedpoint.NewFromFloat(100.0), fixedpoint.NewFromFloat(101.0), now, now))
+}
+
+func Test_nextContract(t *testing.T) {
+	now := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	contracts := []Contract{
+		newTestContract("BTCUSDT_230929", time.Date(2023, 9, 29, 8, 0, 0, 0, time.UTC)),
+		newTestContract("BTCUSDT_230630", time.Date(2023, 6, 30, 8, 0, 0, 0, time.UTC)),
+		newTestContract("BTCUSDT_230331", time.Date(2023, 3, 31, 8, 0, 0, 0, time.UTC)),
+	}
+
+	c, ok := nextContract(contracts, time.Time{}, now, 24*time.Hour)
+	if assert.True(t, ok) {
+		assert.Equal(t, "BTCUSDT_230630", c.Symbol)
+	}
+
+	// in the roll period of the june contract
+	rollTime := time.Date(2023, 6, 29, 12, 0, 0, 0, time.UTC)
+	assert.True(t, c.shouldRoll(rollTime, 24*time.Hour))
+
+	next, ok := nextContract(contracts, c.ExpiryTime(), rollTime, 24*time.Hour)
+	if assert.True(t, ok) {
+		assert.Equal(t, "BTCUSDT_230929", next.Symbol)
+	}
+
+	_, ok = nextContract(contracts, next.ExpiryTime(), rollTime, 24*time.Hour)
+	assert.False(t, ok)
+}
+
+func TestState_Hedge(t *testing.T) {
+	number := fixedpoint.MustNewFromString
+	state := &State{}
+	state.addHedge(number("1"), number("200"))
+	state.addHedge(number("1"), number("100"))
+	assert.Equal(t, "2", state.HedgedQuantity.String())
+	assert.Equal(t, "150", state.EntryBasis.String())
+
+	// the basis converges from 150 to 30
+	pnl := state.reduceHedge(number("0.5"), number("30"))
+	assert.Equal(t, "60", pnl.String())
+	assert.Equal(t, "1.5", state.HedgedQuantity.String())
+
+	pnl = state.reduceHedge(number("2"), number("-10"))
+	assert.Equal(t, "240", pnl.String())
+	assert.True(t, state.HedgedQuantity.IsZero())
+	assert.True(t, state.EntryBasis.IsZero())
+}
+
+func TestProfitStats_AddCarry(t *testing.T) {
+	stats := newProfitStats(types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"})
+	stats.AddCarry(CarryRecord{Contract: "BTCUSDT_230630", Reason: "roll", PnL: fixedpoint.NewFromFloat(12.5)})
+	stats.AddCarry(CarryRecord{Contract: "BTCUSDT_230929", Reason: "close", PnL: fixedpoint.NewFromFloat(-2.5)})
+
+	assert.Equal(t, "10", stats.TotalCarryPnL.String())
+	assert.Equal(t, "USDT", stats.CarryCurrency)
+	assert.Len(t, stats.CarryRecords, 2)
+
+	// the carry pnl is not mixed into the trading pnl
+	assert.True(t, stats.AccumulatedPnL.IsZero())
+}
+
+func TestStrategy_closeHedge_PendingSpotUnwind(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	number := fixedpoint.MustNewFromString
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	contract := newTestContract("BTCUSDT_230331", now.Add(90*24*time.Hour))
+
+	spotMarket := types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT", StepSize: number("0.001"), VolumePrecision: 3}
+	futuresMarket := spotMarket
+	futuresMarket.Symbol = contract.Symbol
+
+	spotExecutor := mocks.NewMockOrderExecutor(mockCtrl)
+	futuresExecutor := mocks.NewMockOrderExecutor(mockCtrl)
+
+	s := &Strategy{
+		Symbol:               "BTCUSDT",
+		Contracts:            []Contract{contract},
+		ProfitStats:          newProfitStats(spotMarket),
+		State:                &State{Contract: contract.Symbol},
+		spotMarket:           spotMarket,
+		futuresMarket:        futuresMarket,
+		spotOrderExecutor:    spotExecutor,
+		futuresOrderExecutor: futuresExecutor,
+	}
+	s.State.addHedge(number("1"), number("300"))
+
+	ctx := context.Background()
+	gomock.InOrder(
+		futuresExecutor.EXPECT().SubmitOrders(ctx, s.futuresOrder(types.SideTypeBuy, number("1"), true)).Return(nil, nil),
+		spotExecutor.EXPECT().SubmitOrders(ctx, s.spotOrder(types.SideTypeSell, number("1"))).Return(nil, errors.New("insufficient balance")),
+	)
+
+	s.closeHedge(ctx, contract, number("1"), number("20000"), number("20100"), "close", now)
+
+	// the futures leg is closed, only the spot sell is left
+	assert.True(t, s.State.HedgedQuantity.IsZero())
+	assert.Equal(t, "1", s.State.PendingSpotUnwind.String())
+	assert.Len(t, s.ProfitStats.CarryRecords, 1)
+
+	spotExecutor.EXPECT().SubmitOrders(ctx, s.spotOrder(types.SideTypeSell, number("1"))).Return(nil, nil)
+	s.retrySpotUnwind(ctx)
+	assert.True(t, s.State.PendingSpotUnwind.IsZero())
+}
@@ -5,6 +5,7 @@ import (
 	"math"
 
 	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/indicator"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -12,6 +13,7 @@ import (
 type DynamicSpreadSettings struct {
 	AmpSpreadSettings                    *DynamicSpreadAmpSettings            `json:"amplitude"`
 	WeightedBollWidthRatioSpreadSettings *DynamicSpreadBollWidthRatioSettings `json:"weightedBollWidth"`
+	ATRSpreadSettings                    *DynamicSpreadATRSettings            `json:"atr"`
 
 	// deprecated
 	Enabled *bool `json:"enabled"`
@@ -33,6 +35,8 @@ func (ds *DynamicSpreadSettings) Initialize(symbol string, session *bbgo.Exchang
 		ds.AmpSpreadSettings.initialize(symbol, session)
 	case ds.WeightedBollWidthRatioSpreadSettings != nil:
 		ds.WeightedBollWidthRatioSpreadSettings.initialize(neutralBoll, defaultBoll)
+	case ds.ATRSpreadSettings != nil:
+		ds.ATRSpreadSettings.initialize(symbol, session)
 	case ds.Enabled != nil && *ds.Enabled:
 		// backward compatibility
 		ds.AmpSpreadSettings = &DynamicSpreadAmpSettings{
@@ -45,7 +49,7 @@ func (ds *DynamicSpreadSettings) Initialize(symbol string, session *bbgo.Exchang
 }
 
 func (ds *DynamicSpreadSettings) IsEnabled() bool {
-	return ds.AmpSpreadSettings != nil || ds.WeightedBollWidthRatioSpreadSettings != nil
+	return ds.AmpSpreadSettings != nil || ds.WeightedBollWidthRatioSpreadSettings != nil || ds.ATRSpreadSettings != nil
 }
 
 // Update dynamic spreads
@@ -55,6 +59,8 @@ func (ds *DynamicSpreadSettings) Update(kline types.KLine) {
 		ds.AmpSpreadSettings.update(kline)
 	case ds.WeightedBollWidthRatioSpreadSettings != nil:
 		// Boll bands are updated outside of settings. Do nothing.
+	case ds.ATRSpreadSettings != nil:
+		ds.ATRSpreadSettings.update(kline)
 	default:
 		// Disabled. Do nothing.
 	}
@@ -67,6 +73,8 @@ func (ds *DynamicSpreadSettings) GetAskSpread() (askSpread float64, err error) {
 		return ds.AmpSpreadSettings.getAskSpread()
 	case ds.WeightedBollWidthRatioSpreadSettings != nil:
 		return ds.WeightedBollWidthRatioSpreadSettings.getAskSpread()
+	case ds.ATRSpreadSettings != nil:
+		return ds.ATRSpreadSettings.getSpread()
 	default:
 		return 0, errors.New("dynamic spread is not enabled")
 	}
@@ -79,6 +87,8 @@ func (ds *DynamicSpreadSettings) GetBidSpread() (bidSpread float64, err error) {
 		return ds.AmpSpreadSettings.getBidSpread()
 	case ds.WeightedBollWidthRatioSpreadSettings != nil:
 		return ds.WeightedBollWidthRatioSpreadSettings.getBidSpread()
+	case ds.ATRSpreadSettings != nil:
+		return ds.ATRSpreadSettings.getSpread()
 	default:
 		return 0, errors.New("dynamic spread is not enabled")
 	}
@@ -245,3 +255,63 @@ func (ds *DynamicSpreadBollWidthRatioSettings) getWeightedBBWidthRatio(positiveS
 	}
 	return (weightedUpper - weightedLower) / (weightedDivUpper - weightedDivLower)
 }
+
+// DynamicSpreadATRSettings sets both the bid and the ask spread to the ATR in ratio of the close price,
+// so that the quotes are widened when the market is volatile.
+//
+//	dynamicSpread:
+//	  atr:
+//	    interval: 15m
+//	    window: 14
+//	    multiplier: 0.5
+//	    minSpread: 0.1%
+//	    maxSpread: 2%
+type DynamicSpreadATRSettings struct {
+	types.IntervalWindow
+
+	// Multiplier scales the ATR ratio into the spread, defaults to 1.0
+	Multiplier float64 `json:"multiplier"`
+
+	// MinSpread and MaxSpread bound the spread, the bound is not applied if it's zero
+	MinSpread fixedpoint.Value `json:"minSpread"`
+	MaxSpread fixedpoint.Value `json:"maxSpread"`
+
+	atr       *indicator.ATR
+	lastClose float64
+}
+
+func (ds *DynamicSpreadATRSettings) initialize(symbol string, session *bbgo.ExchangeSession) {
+	if ds.Multiplier <= 0. {
+		ds.Multiplier = 1.
+	}
+
+	ds.atr = session.StandardIndicatorSet(symbol).ATR(ds.IntervalWindow)
+	if lastPrice, ok := session.LastPrice(symbol); ok {
+		ds.lastClose = lastPrice.Float64()
+	}
+}
+
+func (ds *DynamicSpreadATRSettings) update(kline types.KLine) {
+	ds.lastClose = kline.Close.Float64()
+}
+
+func (ds *DynamicSpreadATRSettings) getSpread() (spread float64, err error) {
+	if ds.atr == nil || ds.atr.Length() < ds.Window || ds.lastClose <= 0. {
+		return 0, errors.New("not enough data for the atr dynamic spread yet")
+	}
+
+	return calculateATRSpread(ds.atr.Last(0), ds.lastClose, ds.Multiplier, ds.MinSpread.Float64(), ds.MaxSpread.Float64()), nil
+}
+
+func calculateATRSpread(atr, price, multiplier, minSpread, maxSpread float64) float64 {
+	spread := atr / price * multiplier
+	if minSpread > 0 {
+		spread = math.Max(spread, minSpread)
+	}
+
+	if maxSpread > 0 {
+		spread = math.Min(spread, maxSpread)
+	}
+
+	return spread
+}
@@ -62,6 +62,9 @@ type Strategy struct {
 	// you can define interval and window
 	TrendEMA *bbgo.TrendEMA `json:"trendEMA"`
 
+	// TrendFilter turns off the counter-trend side when a trend is detected by the EMA slope or the ADX
+	TrendFilter *TrendFilter `json:"trendFilter,omitempty"`
+
 	// Spread is the price spread from the middle price.
 	// For ask orders, the ask price is ((bestAsk + bestBid) / 2 * (1.0 + spread))
 	// For bid orders, the bid price is ((bestAsk + bestBid) / 2 * (1.0 - spread))
@@ -196,6 +199,14 @@ func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
 		session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.TrendEMA.Interval})
 	}
 
+	if s.TrendFilter != nil && s.TrendFilter.Interval != "" {
+		session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.TrendFilter.Interval})
+	}
+
+	if atr := s.DynamicSpread.ATRSpreadSettings; atr != nil && atr.Interval != "" {
+		session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: atr.Interval})
+	}
+
 	s.ExitMethods.SetAndSubscribe(session, s)
 }
 
@@ -204,6 +215,16 @@ func (s *Strategy) Validate() error {
 		return errors.New("symbol is required")
 	}
 
+	if s.TrendFilter != nil {
+		if err := s.TrendFilter.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if atr := s.DynamicSpread.ATRSpreadSettings; atr != nil && atr.Window <= 0 {
+		return errors.New("dynamicSpread.atr: window is required")
+	}
+
 	return nil
 }
 
@@ -422,6 +443,18 @@ func (s *Strategy) placeOrders(ctx context.Context, midPrice fixedpoint.Value, k
 		}
 	}
 
+	// trend filter protection
+	if s.TrendFilter != nil {
+		switch trend := s.TrendFilter.Trend(); trend {
+		case UpTrend:
+			log.Infof("trendFilter protection: %s %s, turning sell order off", s.Symbol, trend)
+			canSell = false
+		case DownTrend:
+			log.Infof("trendFilter protection: %s %s, turning buy order off", s.Symbol, trend)
+			canBuy = false
+		}
+	}
+
 	if canSell {
 		submitOrders = append(submitOrders, sellOrder)
 	}
@@ -473,6 +506,9 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		if s.DynamicSpread.Interval == "" {
 			s.DynamicSpread.Interval = s.Interval
 		}
+		if atr := s.DynamicSpread.ATRSpreadSettings; atr != nil && atr.Interval == "" {
+			atr.Interval = s.Interval
+		}
 		s.DynamicSpread.Initialize(s.Symbol, s.session, s.neutralBoll, s.defaultBoll)
 	}
 
@@ -533,6 +569,13 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		s.TrendEMA.Bind(session, s.orderExecutor)
 	}
 
+	if s.TrendFilter != nil {
+		if s.TrendFilter.Interval == "" {
+			s.TrendFilter.Interval = s.Interval
+		}
+		s.TrendFilter.Bind(session, s.Symbol)
+	}
+
 	if bbgo.IsBackTesting {
 		log.Warn("turning of useTickerPrice option in the back-testing environment...")
 		s.UseTickerPrice = false
@@ -0,0 +1,138 @@
+package bollmaker
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultTrendFilterMinADX = 25.0
+
+type TrendFilterMethod string
+
+const (
+	TrendFilterMethodEMASlope TrendFilterMethod = "emaSlope"
+	TrendFilterMethodADX      TrendFilterMethod = "adx"
+)
+
+// TrendFilter detects the trend with the EMA slope or the ADX, and turns off the counter-trend side of the quotes:
+// the sell order is not placed in an uptrend and the buy order is not placed in a downtrend.
+//
+//	trendFilter:
+//	  method: adx
+//	  interval: 1h
+//	  window: 14
+//	  minADX: 25
+type TrendFilter struct {
+	types.IntervalWindow
+
+	// Method is emaSlope or adx, defaults to emaSlope
+	Method TrendFilterMethod `json:"method"`
+
+	// MinSlope is the minimal EMA change ratio of the last closed kline to be a trend, e.g., 0.001 means 0.1%, used by the emaSlope method
+	MinSlope fixedpoint.Value `json:"minSlope"`
+
+	// MinADX is the minimal ADX to be a trend, the direction is decided by +DI and -DI, used by the adx method, defaults to 25
+	MinADX float64 `json:"minADX"`
+
+	// ADXSmoothing is the smoothing window of the ADX, defaults to the window
+	ADXSmoothing int `json:"adxSmoothing"`
+
+	ewma *indicator.EWMA
+	dmi  *indicator.DMI
+}
+
+func (f *TrendFilter) Validate() error {
+	switch f.Method {
+	case "", TrendFilterMethodEMASlope, TrendFilterMethodADX:
+	default:
+		return fmt.Errorf("trendFilter: unsupported method %q, use %s or %s", f.Method, TrendFilterMethodEMASlope, TrendFilterMethodADX)
+	}
+
+	if f.Window <= 0 {
+		return fmt.Errorf("trendFilter: window is required")
+	}
+
+	if f.MinSlope.Sign() < 0 || f.MinADX < 0 {
+		return fmt.Errorf("trendFilter: minSlope and minADX can not be negative")
+	}
+
+	return nil
+}
+
+func (f *TrendFilter) Bind(session *bbgo.ExchangeSession, symbol string) {
+	if f.Method == TrendFilterMethodADX {
+		if f.MinADX == 0 {
+			f.MinADX = defaultTrendFilterMinADX
+		}
+
+		if f.ADXSmoothing == 0 {
+			f.ADXSmoothing = f.Window
+		}
+
+		f.dmi = &indicator.DMI{IntervalWindow: f.IntervalWindow, ADXSmoothing: f.ADXSmoothing}
+		if kLineStore, ok := session.MarketDataStore(symbol); ok {
+			if klines, ok := kLineStore.KLinesOfInterval(f.Interval); ok {
+				for _, k := range *klines {
+					f.dmi.PushK(k)
+				}
+			}
+		}
+
+		session.MarketDataStream.OnKLineClosed(types.KLineWith(symbol, f.Interval, f.dmi.PushK))
+		return
+	}
+
+	f.ewma = session.StandardIndicatorSet(symbol).EWMA(f.IntervalWindow)
+}
+
+// Trend returns UnknownTrend until the indicator has enough data
+func (f *TrendFilter) Trend() PriceTrend {
+	if f.dmi != nil {
+		if f.dmi.ADX == nil || f.dmi.ADX.Length() == 0 || f.dmi.DIPlus.Length() == 0 {
+			return UnknownTrend
+		}
+
+		return detectADXTrend(f.dmi.DIPlus.Last(0), f.dmi.DIMinus.Last(0), f.dmi.ADX.Last(0), f.MinADX)
+	}
+
+	if f.ewma == nil || f.ewma.Length() < 2 {
+		return UnknownTrend
+	}
+
+	return detectEMASlopeTrend(f.ewma.Last(1), f.ewma.Last(0), f.MinSlope.Float64())
+}
+
+func detectEMASlopeTrend(last, current, minSlope float64) PriceTrend {
+	if last <= 0 || current <= 0 {
+		return UnknownTrend
+	}
+
+	slope := current/last - 1.0
+	switch {
+	case slope > minSlope:
+		return UpTrend
+	case slope < -minSlope:
+		return DownTrend
+	}
+
+	return NeutralTrend
+}
+
+func detectADXTrend(diPlus, diMinus, adx, minADX float64) PriceTrend {
+	if adx < minADX {
+		return NeutralTrend
+	}
+
+	switch {
+	case diPlus > diMinus:
+		return UpTrend
+	case diMinus > diPlus:
+		return DownTrend
+	}
+
+	return NeutralTrend
+}
@@ -0,0 +1,46 @@
+package bollmaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_detectEMASlopeTrend(t *testing.T) {
+	assert.Equal(t, UpTrend, detectEMASlopeTrend(100.0, 100.2, 0.001))
+	assert.Equal(t, DownTrend, detectEMASlopeTrend(100.0, 99.8, 0.001))
+	assert.Equal(t, NeutralTrend, detectEMASlopeTrend(100.0, 100.05, 0.001))
+	assert.Equal(t, UnknownTrend, detectEMASlopeTrend(0, 100.0, 0.001))
+}
+
+func Test_detectADXTrend(t *testing.T) {
+	assert.Equal(t, UpTrend, detectADXTrend(30, 15, 28, 25))
+	assert.Equal(t, DownTrend, detectADXTrend(12, 35, 40, 25))
+	assert.Equal(t, NeutralTrend, detectADXTrend(30, 15, 18, 25))
+}
+
+func TestTrendFilter_Validate(t *testing.T) {
+	filter := &TrendFilter{IntervalWindow: types.IntervalWindow{Interval: types.Interval1h, Window: 14}, Method: TrendFilterMethodADX}
+	assert.NoError(t, filter.Validate())
+
+	filter.Method = "macd"
+	assert.Error(t, filter.Validate())
+
+	filter = &TrendFilter{IntervalWindow: types.IntervalWindow{Interval: types.Interval1h, Window: 14}, MinSlope: fixedpoint.NewFromFloat(-0.001)}
+	assert.Error(t, filter.Validate())
+
+	filter = &TrendFilter{Method: TrendFilterMethodEMASlope}
+	assert.Error(t, filter.Validate())
+}
+
+func Test_calculateATRSpread(t *testing.T) {
+	assert.InDelta(t, 0.005, calculateATRSpread(50, 10000, 1.0, 0, 0), 1e-9)
+	assert.InDelta(t, 0.0025, calculateATRSpread(50, 10000, 0.5, 0, 0), 1e-9)
+
+	// bounded by the min and max spreads
+	assert.InDelta(t, 0.001, calculateATRSpread(5, 10000, 1.0, 0.001, 0.02), 1e-9)
+	assert.InDelta(t, 0.02, calculateATRSpread(500, 10000, 1.0, 0.001, 0.02), 1e-9)
+}
@@ -0,0 +1,234 @@
+package composer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "composer"
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// Strategy is a generic executor of the declarative signals,
+// it opens the position when the entry signal is true and closes it when the exit signal is true.
+type Strategy struct {
+	Environment *bbgo.Environment
+	Symbol      string `json:"symbol"`
+	Market      types.Market
+
+	Interval types.Interval `json:"interval"`
+
+	// Indicators declares the indicator streams by name
+	Indicators map[string]bbgo.IndicatorConfig `json:"indicators"`
+
+	// Entry is the signal to open the position
+	Entry *bbgo.SignalConfig `json:"entry"`
+
+	// Exit is the signal to close the position, the exit methods can be used without the exit signal
+	Exit *bbgo.SignalConfig `json:"exit,omitempty"`
+
+	// Side is the side of the position, buy for long and sell for short, defaults to buy
+	Side types.SideType `json:"side"`
+
+	bbgo.OpenPositionOptions
+
+	ExitMethods bbgo.ExitMethodSet `json:"exits"`
+
+	Position    *types.Position    `persistence:"position"`
+	ProfitStats *types.ProfitStats `persistence:"profit_stats"`
+	TradeStats  *types.TradeStats  `persistence:"trade_stats"`
+
+	session       *bbgo.ExchangeSession
+	orderExecutor *bbgo.GeneralOrderExecutor
+
+	composer    *bbgo.SignalComposer
+	entrySignal bbgo.Signal
+	exitSignal  bbgo.Signal
+
+	bbgo.StrategyController
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) InstanceID() string {
+	return fmt.Sprintf("%s:%s:%s", ID, s.Symbol, s.Interval)
+}
+
+func (s *Strategy) Defaults() error {
+	if s.Interval == "" {
+		s.Interval = types.Interval1h
+	}
+
+	if s.Side == "" {
+		s.Side = types.SideTypeBuy
+	}
+
+	return nil
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return errors.New("symbol is required")
+	}
+
+	if s.Entry == nil {
+		return errors.New("entry signal is required")
+	}
+
+	if s.Side != types.SideTypeBuy && s.Side != types.SideTypeSell {
+		return fmt.Errorf("invalid side %s, valid sides: buy, sell", s.Side)
+	}
+
+	if s.Quantity.Sign() <= 0 && s.Leverage.Sign() <= 0 {
+		return errors.New("quantity or leverage is required")
+	}
+
+	// compile the signals to report the config errors early
+	composer, err := bbgo.NewSignalComposer(s.Symbol, s.Interval, s.Indicators)
+	if err != nil {
+		return err
+	}
+
+	if _, err := composer.Compile(s.Entry); err != nil {
+		return fmt.Errorf("invalid entry signal: %w", err)
+	}
+
+	if s.Exit != nil {
+		if _, err := composer.Compile(s.Exit); err != nil {
+			return fmt.Errorf("invalid exit signal: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.Interval})
+	s.ExitMethods.SetAndSubscribe(session, s)
+}
+
+func (s *Strategy) Run(ctx context.Context, _ bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	if s.Position == nil {
+		s.Position = types.NewPositionFromMarket(s.Market)
+	}
+
+	if s.ProfitStats == nil {
+		s.ProfitStats = types.NewProfitStats(s.Market)
+	}
+
+	if s.TradeStats == nil {
+		s.TradeStats = types.NewTradeStats(s.Symbol)
+	}
+
+	var err error
+	s.composer, err = bbgo.NewSignalComposer(s.Symbol, s.Interval, s.Indicators)
+	if err != nil {
+		return err
+	}
+
+	if s.entrySignal, err = s.composer.Compile(s.Entry); err != nil {
+		return err
+	}
+
+	if s.Exit != nil {
+		if s.exitSignal, err = s.composer.Compile(s.Exit); err != nil {
+			return err
+		}
+	}
+
+	s.session = session
+	s.orderExecutor = bbgo.NewGeneralOrderExecutor(session, s.Symbol, ID, s.InstanceID(), s.Position)
+	s.orderExecutor.BindEnvironment(s.Environment)
+	s.orderExecutor.BindProfitStats(s.ProfitStats)
+	s.orderExecutor.BindTradeStats(s.TradeStats)
+	s.orderExecutor.TradeCollector().OnPositionUpdate(func(position *types.Position) {
+		bbgo.Sync(ctx, s)
+	})
+	s.orderExecutor.Bind()
+
+	for _, method := range s.ExitMethods {
+		method.Bind(session, s.orderExecutor)
+	}
+
+	s.Status = types.StrategyStatusRunning
+	s.OnSuspend(func() {
+		_ = s.orderExecutor.GracefulCancel(ctx)
+	})
+
+	s.OnEmergencyStop(func() {
+		_ = s.orderExecutor.GracefulCancel(ctx)
+		_ = s.orderExecutor.ClosePosition(ctx, fixedpoint.One, "emergencyStop")
+	})
+
+	// warm up the indicators with the preloaded klines, the signals are only evaluated on the new klines
+	if store, ok := session.MarketDataStore(s.Symbol); ok {
+		if kLines, ok := store.KLinesOfInterval(s.Interval); ok {
+			s.composer.WarmUp(*kLines)
+		}
+	}
+
+	s.composer.Bind(session.MarketDataStream, func(k types.KLine) {
+		if s.GetStatus() != types.StrategyStatusRunning {
+			return
+		}
+
+		s.evaluate(ctx, k)
+	})
+
+	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+		_ = s.orderExecutor.GracefulCancel(ctx)
+		bbgo.Sync(ctx, s)
+	})
+
+	return nil
+}
+
+// evaluate closes the position on the exit signal and opens the position on the entry signal
+func (s *Strategy) evaluate(ctx context.Context, k types.KLine) {
+	hasPosition := !s.Position.IsDust(k.Close)
+	if hasPosition {
+		if s.exitSignal == nil || !s.exitSignal.Evaluate() {
+			return
+		}
+
+		bbgo.Notify("%s: exit signal triggered at %s", s.InstanceID(), k.Close.String())
+		if err := s.orderExecutor.ClosePosition(ctx, fixedpoint.One, "signalExit"); err != nil {
+			log.WithError(err).Errorf("unable to close the position")
+		}
+		return
+	}
+
+	if !s.entrySignal.Evaluate() {
+		return
+	}
+
+	bbgo.Notify("%s: entry signal triggered at %s", s.InstanceID(), k.Close.String())
+
+	if err := s.orderExecutor.GracefulCancel(ctx); err != nil {
+		log.WithError(err).Errorf("unable to cancel the orders")
+	}
+
+	options := s.OpenPositionOptions
+	options.Long = s.Side == types.SideTypeBuy
+	options.Short = s.Side == types.SideTypeSell
+	options.Price = k.Close
+	options.Tags = []string{"signalEntry"}
+	if _, err := s.orderExecutor.OpenPosition(ctx, options); err != nil {
+		log.WithError(err).Errorf("unable to open the position")
+	}
+}
@@ -0,0 +1,33 @@
+package composer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestStrategy_Validate(t *testing.T) {
+	s := &Strategy{
+		Symbol: "BTCUSDT",
+		Indicators: map[string]bbgo.IndicatorConfig{
+			"fast": {Type: "ewma", Window: 7},
+			"slow": {Type: "ewma", Window: 25},
+		},
+		Entry: &bbgo.SignalConfig{CrossOver: &bbgo.SignalComparison{Indicator: "fast", Than: "slow"}},
+		Exit:  &bbgo.SignalConfig{CrossUnder: &bbgo.SignalComparison{Indicator: "fast", Than: "slow"}},
+	}
+	s.Quantity = fixedpoint.NewFromFloat(0.01)
+
+	assert.NoError(t, s.Defaults())
+	assert.NoError(t, s.Validate())
+
+	s.Exit = &bbgo.SignalConfig{CrossUnder: &bbgo.SignalComparison{Indicator: "fast", Than: "medium"}}
+	assert.Error(t, s.Validate())
+
+	s.Exit = nil
+	s.Indicators["fast"] = bbgo.IndicatorConfig{Type: "unknown", Window: 7}
+	assert.Error(t, s.Validate())
+}
@@ -0,0 +1,92 @@
+package coveredgrid
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Rung is one level of the sell ladder, it holds the base quantity covered by the level
+// and alternates between selling the holding above cost and re-buying it lower.
+type Rung struct {
+	Index int `json:"index"`
+
+	// SellPrice is the fixed price of the ladder level
+	SellPrice fixedpoint.Value `json:"sellPrice"`
+
+	// Quantity is the base quantity held by the rung
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// CostBasis is the average cost of the base quantity held by the rung
+	CostBasis fixedpoint.Value `json:"costBasis"`
+
+	// Side is the side of the next order of the rung, sell when the rung holds the base, buy after it's sold
+	Side types.SideType `json:"side"`
+
+	// BuyPrice is the re-buy price after the rung is sold
+	BuyPrice fixedpoint.Value `json:"buyPrice,omitempty"`
+
+	// SoldQuote is the quote amount received from the last sell
+	SoldQuote fixedpoint.Value `json:"soldQuote,omitempty"`
+
+	// OrderID is the id of the active order of the rung
+	OrderID uint64 `json:"orderID,omitempty"`
+
+	RealizedProfit fixedpoint.Value `json:"realizedProfit"`
+	NumOfCycles    int              `json:"numOfCycles"`
+}
+
+// sold updates the rung with the filled sell order and returns the realized profit of the sell
+func (r *Rung) sold(price, quantity, buybackRatio fixedpoint.Value) fixedpoint.Value {
+	profit := price.Sub(r.CostBasis).Mul(quantity)
+	r.RealizedProfit = r.RealizedProfit.Add(profit)
+	r.SoldQuote = price.Mul(quantity)
+	r.Side = types.SideTypeBuy
+	r.BuyPrice = r.SellPrice.Mul(fixedpoint.One.Sub(buybackRatio))
+	r.OrderID = 0
+	return profit
+}
+
+// bought updates the rung with the filled buy order, the rebought base becomes the new holding of the rung
+func (r *Rung) bought(price, quantity fixedpoint.Value) {
+	r.CostBasis = price
+	r.Quantity = quantity
+	r.SoldQuote = fixedpoint.Zero
+	r.Side = types.SideTypeSell
+	r.BuyPrice = fixedpoint.Zero
+	r.OrderID = 0
+	r.NumOfCycles++
+}
+
+func (r *Rung) String() string {
+	return fmt.Sprintf("rung #%d %s %s @ %s (cost %s, profit %s, cycles %d)",
+		r.Index, r.Side, r.Quantity.String(), r.orderPrice().String(),
+		r.CostBasis.String(), r.RealizedProfit.String(), r.NumOfCycles)
+}
+
+func (r *Rung) orderPrice() fixedpoint.Value {
+	if r.Side == types.SideTypeBuy {
+		return r.BuyPrice
+	}
+
+	return r.SellPrice
+}
+
+// buildLadder creates the rungs above the base price, each rung is spread above the previous one
+func buildLadder(basePrice, spread, quantity, costBasis fixedpoint.Value, numOfRungs int) []*Rung {
+	var rungs []*Rung
+	price := basePrice
+	for i := 0; i < numOfRungs; i++ {
+		price = price.Mul(fixedpoint.One.Add(spread))
+		rungs = append(rungs, &Rung{
+			Index:     i,
+			SellPrice: price,
+			Quantity:  quantity,
+			CostBasis: costBasis,
+			Side:      types.SideTypeSell,
+		})
+	}
+
+	return rungs
+}
@@ -0,0 +1,317 @@
+package coveredgrid
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "coveredgrid"
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// Strategy is a one-sided grid for the long-term holders,
+// it sells a ladder of limit orders above the cost of the existing holding and re-buys each rung lower,
+// the rung is never sold below the floor price.
+type Strategy struct {
+	Environment *bbgo.Environment
+	Symbol      string `json:"symbol"`
+	Market      types.Market
+
+	// CostPrice is the cost basis of the existing holding
+	CostPrice fixedpoint.Value `json:"costPrice"`
+
+	// FloorPrice is the protected price, no sell order is placed below it, defaults to the cost price
+	FloorPrice fixedpoint.Value `json:"floorPrice"`
+
+	// Quantity is the base quantity covered by each rung
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// NumOfRungs is the number of the ladder levels, defaults to 5
+	NumOfRungs int `json:"numOfRungs"`
+
+	// RungSpread is the price ratio between the ladder levels, the first rung is placed one spread above the cost (or the floor), defaults to 2%
+	RungSpread fixedpoint.Value `json:"rungSpread"`
+
+	// BuybackSpread is the ratio below the sell price to re-buy the rung, defaults to the rung spread
+	BuybackSpread fixedpoint.Value `json:"buybackSpread"`
+
+	// CompoundBase re-buys with all the quote of the sell, so that the profit is accumulated in the base asset
+	CompoundBase bool `json:"compoundBase"`
+
+	// Reset rebuilds the ladder from the config instead of the persisted rungs
+	Reset bool `json:"reset"`
+
+	Rungs       []*Rung            `persistence:"rungs"`
+	Position    *types.Position    `persistence:"position"`
+	ProfitStats *types.ProfitStats `persistence:"profit_stats"`
+
+	session       *bbgo.ExchangeSession
+	orderExecutor *bbgo.GeneralOrderExecutor
+	mu            sync.Mutex
+
+	bbgo.StrategyController
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) InstanceID() string {
+	return fmt.Sprintf("%s:%s", ID, s.Symbol)
+}
+
+func (s *Strategy) Defaults() error {
+	if s.NumOfRungs == 0 {
+		s.NumOfRungs = 5
+	}
+
+	if s.RungSpread.IsZero() {
+		s.RungSpread = fixedpoint.NewFromFloat(0.02)
+	}
+
+	if s.BuybackSpread.IsZero() {
+		s.BuybackSpread = s.RungSpread
+	}
+
+	if s.FloorPrice.IsZero() {
+		s.FloorPrice = s.CostPrice
+	}
+
+	return nil
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return errors.New("symbol is required")
+	}
+
+	if s.CostPrice.Sign() <= 0 {
+		return errors.New("costPrice should be greater than zero")
+	}
+
+	if s.Quantity.Sign() <= 0 {
+		return errors.New("quantity should be greater than zero")
+	}
+
+	if s.NumOfRungs <= 0 {
+		return errors.New("numOfRungs should be greater than zero")
+	}
+
+	if s.RungSpread.Sign() <= 0 {
+		return errors.New("rungSpread should be greater than zero")
+	}
+
+	if s.BuybackSpread.Sign() <= 0 || s.BuybackSpread.Compare(fixedpoint.One) >= 0 {
+		return errors.New("buybackSpread should be in (0, 1)")
+	}
+
+	return nil
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {}
+
+func (s *Strategy) Run(ctx context.Context, _ bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	if s.Position == nil || s.Reset {
+		// the existing holding is the opening position of the strategy
+		s.Position = types.NewPositionFromMarket(s.Market)
+		s.Position.Base = s.Quantity.Mul(fixedpoint.NewFromInt(int64(s.NumOfRungs)))
+		s.Position.AverageCost = s.CostPrice
+	}
+
+	if s.ProfitStats == nil || s.Reset {
+		s.ProfitStats = types.NewProfitStats(s.Market)
+	}
+
+	if len(s.Rungs) == 0 || s.Reset {
+		s.Rungs = buildLadder(fixedpoint.Max(s.CostPrice, s.FloorPrice), s.RungSpread, s.Quantity, s.CostPrice, s.NumOfRungs)
+	}
+
+	s.session = session
+	s.orderExecutor = bbgo.NewGeneralOrderExecutor(session, s.Symbol, ID, s.InstanceID(), s.Position)
+	s.orderExecutor.BindEnvironment(s.Environment)
+	s.orderExecutor.BindProfitStats(s.ProfitStats)
+	s.orderExecutor.TradeCollector().OnPositionUpdate(func(position *types.Position) {
+		bbgo.Sync(ctx, s)
+	})
+	s.orderExecutor.ActiveMakerOrders().OnFilled(func(o types.Order) {
+		s.handleFilledOrder(ctx, o)
+	})
+	s.orderExecutor.Bind()
+
+	s.Status = types.StrategyStatusRunning
+	s.OnSuspend(func() {
+		_ = s.orderExecutor.GracefulCancel(ctx)
+		s.clearOrderIDs()
+		bbgo.Sync(ctx, s)
+	})
+
+	s.OnResume(func() {
+		s.placeRungOrders(ctx)
+	})
+
+	s.OnEmergencyStop(func() {
+		_ = s.orderExecutor.GracefulCancel(ctx)
+		s.clearOrderIDs()
+		bbgo.Sync(ctx, s)
+	})
+
+	session.UserDataStream.OnStart(func() {
+		// the orders of the previous run are canceled on shutdown, place the orders of all the rungs again
+		s.clearOrderIDs()
+		s.placeRungOrders(ctx)
+	})
+
+	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+
+		if err := s.orderExecutor.GracefulCancel(ctx); err != nil {
+			log.WithError(err).Errorf("unable to cancel the rung orders")
+		}
+
+		bbgo.Sync(ctx, s)
+	})
+
+	return nil
+}
+
+func (s *Strategy) clearOrderIDs() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, r := range s.Rungs {
+		r.OrderID = 0
+	}
+}
+
+// placeRungOrders places the orders of the rungs that have no active order
+func (s *Strategy) placeRungOrders(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	available := s.availableBase()
+	for _, r := range s.Rungs {
+		if r.OrderID != 0 {
+			continue
+		}
+
+		if r.Side == types.SideTypeSell {
+			if available.Compare(r.Quantity) < 0 {
+				log.Warnf("insufficient %s balance %s to cover %s", s.Market.BaseCurrency, available.String(), r.String())
+				continue
+			}
+
+			available = available.Sub(r.Quantity)
+		}
+
+		s.placeRungOrder(ctx, r)
+	}
+}
+
+func (s *Strategy) availableBase() fixedpoint.Value {
+	balance, ok := s.session.GetAccount().Balance(s.Market.BaseCurrency)
+	if !ok {
+		return fixedpoint.Zero
+	}
+
+	return balance.Available
+}
+
+func (s *Strategy) placeRungOrder(ctx context.Context, r *Rung) {
+	submitOrder, ok := s.rungOrder(r)
+	if !ok {
+		return
+	}
+
+	createdOrders, err := s.orderExecutor.SubmitOrders(ctx, submitOrder)
+	if err != nil {
+		log.WithError(err).Errorf("unable to place the order of %s", r.String())
+		return
+	}
+
+	if len(createdOrders) > 0 {
+		r.OrderID = createdOrders[0].OrderID
+	}
+}
+
+// rungOrder creates the limit order of the rung, the sell order below the floor price is refused
+func (s *Strategy) rungOrder(r *Rung) (types.SubmitOrder, bool) {
+	price := s.Market.TruncatePrice(r.orderPrice())
+	quantity := r.Quantity
+	if r.Side == types.SideTypeSell {
+		if price.Compare(s.FloorPrice) < 0 {
+			log.Warnf("the sell price %s of rung #%d is below the floor price %s, skipped", price.String(), r.Index, s.FloorPrice.String())
+			return types.SubmitOrder{}, false
+		}
+	} else if s.CompoundBase && r.SoldQuote.Sign() > 0 {
+		quantity = r.SoldQuote.Div(price)
+	}
+
+	quantity = s.Market.RoundDownQuantityByPrecision(quantity)
+	if price.Sign() <= 0 || s.Market.IsDustQuantity(quantity, price) {
+		return types.SubmitOrder{}, false
+	}
+
+	return types.SubmitOrder{
+		Symbol:      s.Symbol,
+		Side:        r.Side,
+		Type:        types.OrderTypeLimit,
+		Price:       price,
+		Quantity:    quantity,
+		Market:      s.Market,
+		TimeInForce: types.TimeInForceGTC,
+		Tag:         fmt.Sprintf("coveredGrid#%d", r.Index),
+	}, true
+}
+
+func (s *Strategy) findRung(orderID uint64) *Rung {
+	for _, r := range s.Rungs {
+		if r.OrderID == orderID {
+			return r
+		}
+	}
+
+	return nil
+}
+
+func (s *Strategy) handleFilledOrder(ctx context.Context, o types.Order) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r := s.findRung(o.OrderID)
+	if r == nil {
+		return
+	}
+
+	price := o.Price
+	if o.AveragePrice.Sign() > 0 {
+		price = o.AveragePrice
+	}
+
+	switch o.Side {
+	case types.SideTypeSell:
+		profit := r.sold(price, o.ExecutedQuantity, s.BuybackSpread)
+		bbgo.Notify("%s: %s sold, realized profit %s %s", s.InstanceID(), r.String(), profit.String(), s.Market.QuoteCurrency)
+
+	case types.SideTypeBuy:
+		r.bought(price, o.ExecutedQuantity)
+		bbgo.Notify("%s: %s rebought", s.InstanceID(), r.String())
+	}
+
+	if s.GetStatus() == types.StrategyStatusRunning {
+		s.placeRungOrder(ctx, r)
+	}
+
+	bbgo.Sync(ctx, s)
+}
@@ -0,0 +1,102 @@
+package coveredgrid
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var testMarket = types.Market{
+	Symbol:          "BTCUSDT",
+	BaseCurrency:    "BTC",
+	QuoteCurrency:   "USDT",
+	TickSize:        fixedpoint.MustNewFromString("0.01"),
+	StepSize:        fixedpoint.MustNewFromString("0.0001"),
+	PricePrecision:  2,
+	VolumePrecision: 4,
+	MinQuantity:     fixedpoint.MustNewFromString("0.0001"),
+	MinNotional:     fixedpoint.NewFromFloat(5),
+}
+
+func Test_buildLadder(t *testing.T) {
+	rungs := buildLadder(fixedpoint.NewFromFloat(20000), fixedpoint.MustNewFromString("0.1"), fixedpoint.MustNewFromString("0.1"), fixedpoint.NewFromFloat(18000), 3)
+	if assert.Len(t, rungs, 3) {
+		assert.Equal(t, "22000", rungs[0].SellPrice.String())
+		assert.Equal(t, "24200", rungs[1].SellPrice.String())
+		assert.Equal(t, "26620", rungs[2].SellPrice.String())
+		assert.Equal(t, types.SideTypeSell, rungs[2].Side)
+		assert.Equal(t, "18000", rungs[2].CostBasis.String())
+	}
+}
+
+func TestRung_cycle(t *testing.T) {
+	r := &Rung{
+		SellPrice: fixedpoint.NewFromFloat(22000),
+		Quantity:  fixedpoint.MustNewFromString("0.1"),
+		CostBasis: fixedpoint.NewFromFloat(20000),
+		Side:      types.SideTypeSell,
+		OrderID:   1,
+	}
+
+	profit := r.sold(fixedpoint.NewFromFloat(22000), fixedpoint.MustNewFromString("0.1"), fixedpoint.MustNewFromString("0.05"))
+	assert.Equal(t, "200", profit.String())
+	assert.Equal(t, types.SideTypeBuy, r.Side)
+	assert.Equal(t, "20900", r.BuyPrice.String())
+	assert.Equal(t, "2200", r.SoldQuote.String())
+	assert.Equal(t, uint64(0), r.OrderID)
+
+	r.bought(fixedpoint.NewFromFloat(20900), fixedpoint.MustNewFromString("0.1"))
+	assert.Equal(t, types.SideTypeSell, r.Side)
+	assert.Equal(t, "20900", r.CostBasis.String())
+	assert.Equal(t, 1, r.NumOfCycles)
+
+	// the next sell realizes the profit against the new cost basis
+	profit = r.sold(fixedpoint.NewFromFloat(22000), fixedpoint.MustNewFromString("0.1"), fixedpoint.MustNewFromString("0.05"))
+	assert.Equal(t, "110", profit.String())
+	assert.Equal(t, "310", r.RealizedProfit.String())
+}
+
+func TestStrategy_rungOrder(t *testing.T) {
+	s := &Strategy{
+		Symbol:     "BTCUSDT",
+		Market:     testMarket,
+		FloorPrice: fixedpoint.NewFromFloat(21000),
+	}
+
+	r := &Rung{
+		SellPrice: fixedpoint.NewFromFloat(22000),
+		Quantity:  fixedpoint.MustNewFromString("0.1"),
+		Side:      types.SideTypeSell,
+	}
+
+	order, ok := s.rungOrder(r)
+	if assert.True(t, ok) {
+		assert.Equal(t, types.SideTypeSell, order.Side)
+		assert.Equal(t, "22000", order.Price.String())
+		assert.Equal(t, "0.1", order.Quantity.String())
+	}
+
+	// never sell below the floor price
+	r.SellPrice = fixedpoint.NewFromFloat(20000)
+	_, ok = s.rungOrder(r)
+	assert.False(t, ok)
+
+	r.Side = types.SideTypeBuy
+	r.BuyPrice = fixedpoint.NewFromFloat(20000)
+	r.SoldQuote = fixedpoint.NewFromFloat(2200)
+	order, ok = s.rungOrder(r)
+	if assert.True(t, ok) {
+		assert.Equal(t, types.SideTypeBuy, order.Side)
+		assert.Equal(t, "0.1", order.Quantity.String())
+	}
+
+	// the compound mode re-buys with all the sold quote
+	s.CompoundBase = true
+	order, ok = s.rungOrder(r)
+	if assert.True(t, ok) {
+		assert.Equal(t, "0.11", order.Quantity.String())
+	}
+}
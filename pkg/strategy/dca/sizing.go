@@ -0,0 +1,112 @@
+package dca
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type InvestmentMode string
+
+const (
+	// InvestmentModeFixed invests the same quote amount in each investment interval
+	InvestmentModeFixed InvestmentMode = "fixed"
+
+	// InvestmentModeValueAveraging invests the difference between the target value and the position value
+	InvestmentModeValueAveraging InvestmentMode = "valueAveraging"
+)
+
+// ValueAveraging grows the target value of the position by a fixed quote amount in each investment interval,
+// more is bought when the price drops and less is bought when the price rises.
+type ValueAveraging struct {
+	// TargetGrowth is the quote amount that the target value grows per investment, defaults to the budget per investment
+	TargetGrowth fixedpoint.Value `json:"targetGrowth"`
+
+	// MaxInvestment is the max quote amount of one investment, defaults to 2x of the target growth
+	MaxInvestment fixedpoint.Value `json:"maxInvestment"`
+
+	// AllowSell sells the excess value when the position value is above the target value
+	AllowSell bool `json:"allowSell"`
+}
+
+// amount returns the quote amount to invest for the given round (starts from 1),
+// a negative amount means the position value is above the target value.
+func (v *ValueAveraging) amount(round int, positionValue fixedpoint.Value) fixedpoint.Value {
+	target := v.TargetGrowth.Mul(fixedpoint.NewFromInt(int64(round)))
+	amount := target.Sub(positionValue)
+	if v.MaxInvestment.Sign() > 0 {
+		amount = fixedpoint.Min(amount, v.MaxInvestment)
+	}
+
+	return amount
+}
+
+// DipBoost multiplies the investment amount when the price dips,
+// it's triggered by the RSI or the drawdown from the recent high.
+type DipBoost struct {
+	// RSI is the interval window of the RSI indicator, the boost is triggered when the RSI is below the RSIThreshold
+	RSI *types.IntervalWindow `json:"rsi,omitempty"`
+
+	// RSIThreshold defaults to 30
+	RSIThreshold float64 `json:"rsiThreshold,omitempty"`
+
+	// Drawdown is the drop ratio from the highest price of the DrawdownWindow to trigger the boost, for example, 0.1 for 10%
+	Drawdown fixedpoint.Value `json:"drawdown,omitempty"`
+
+	// DrawdownWindow is the number of the investment interval k-lines to find the highest price, defaults to 30
+	DrawdownWindow int `json:"drawdownWindow,omitempty"`
+
+	// Multiplier is the multiplier of the investment amount when the boost is triggered, defaults to 2
+	Multiplier fixedpoint.Value `json:"multiplier,omitempty"`
+
+	rsi *indicator.RSI
+}
+
+func (b *DipBoost) Defaults() {
+	if b.RSIThreshold == 0 {
+		b.RSIThreshold = 30
+	}
+
+	if b.DrawdownWindow == 0 {
+		b.DrawdownWindow = 30
+	}
+
+	if b.Multiplier.IsZero() {
+		b.Multiplier = fixedpoint.NewFromInt(2)
+	}
+}
+
+func (b *DipBoost) Validate() error {
+	if b.RSI == nil && b.Drawdown.IsZero() {
+		return fmt.Errorf("dipBoost requires rsi or drawdown")
+	}
+
+	if b.Multiplier.Compare(fixedpoint.One) < 0 {
+		return fmt.Errorf("dipBoost multiplier should be greater than or equal to 1")
+	}
+
+	return nil
+}
+
+// triggered checks the RSI and the drawdown of the given price from the highest price of the k-lines
+func (b *DipBoost) triggered(price fixedpoint.Value, klines types.KLineWindow) (bool, string) {
+	if b.rsi != nil && b.rsi.Length() > 0 {
+		if rsi := b.rsi.Last(0); rsi < b.RSIThreshold {
+			return true, fmt.Sprintf("rsi %f is below %f", rsi, b.RSIThreshold)
+		}
+	}
+
+	if b.Drawdown.Sign() > 0 && len(klines) > 0 {
+		high := klines.Tail(b.DrawdownWindow).GetHigh()
+		if high.Sign() > 0 {
+			drawdown := high.Sub(price).Div(high)
+			if drawdown.Compare(b.Drawdown) >= 0 {
+				return true, fmt.Sprintf("drawdown %s from %s is above %s", drawdown.Percentage(), high.String(), b.Drawdown.Percentage())
+			}
+		}
+	}
+
+	return false, ""
+}
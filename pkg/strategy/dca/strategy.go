@@ -2,6 +2,7 @@ package dca
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -61,6 +62,15 @@ type Strategy struct {
 	// InvestmentInterval is the interval of each investment
 	InvestmentInterval types.Interval `json:"investmentInterval"`
 
+	// Mode is the investment mode, fixed or valueAveraging, defaults to fixed
+	Mode InvestmentMode `json:"mode,omitempty"`
+
+	// ValueAveraging is the target value config of the valueAveraging mode
+	ValueAveraging *ValueAveraging `json:"valueAveraging,omitempty"`
+
+	// DipBoost multiplies the investment when the price dips
+	DipBoost *DipBoost `json:"dipBoost,omitempty"`
+
 	budgetPerInvestment fixedpoint.Value
 
 	Position              *types.Position    `persistence:"position"`
@@ -68,6 +78,12 @@ type Strategy struct {
 	BudgetQuota           fixedpoint.Value   `persistence:"budget_quota"`
 	BudgetPeriodStartTime time.Time          `persistence:"budget_period_start_time"`
 
+	// TotalInvestment is the net quote amount invested, the sold amount is deducted
+	TotalInvestment fixedpoint.Value `persistence:"total_investment"`
+
+	// NumOfInvestments is the number of the investment intervals since the start, it's the round of the value averaging
+	NumOfInvestments int `persistence:"num_of_investments"`
+
 	session       *bbgo.ExchangeSession
 	orderExecutor *bbgo.GeneralOrderExecutor
 
@@ -78,8 +94,54 @@ func (s *Strategy) ID() string {
 	return ID
 }
 
+func (s *Strategy) Defaults() error {
+	if s.Mode == "" {
+		s.Mode = InvestmentModeFixed
+	}
+
+	if s.Mode == InvestmentModeValueAveraging && s.ValueAveraging == nil {
+		s.ValueAveraging = &ValueAveraging{}
+	}
+
+	if s.DipBoost != nil {
+		s.DipBoost.Defaults()
+	}
+
+	return nil
+}
+
+func (s *Strategy) Validate() error {
+	if s.Budget.Sign() <= 0 {
+		return errors.New("budget should be greater than zero")
+	}
+
+	if s.BudgetPeriod.Duration() == 0 {
+		return fmt.Errorf("invalid budgetPeriod %q, valid periods: day, week, month", s.BudgetPeriod)
+	}
+
+	if s.InvestmentInterval.Duration() == 0 {
+		return errors.New("investmentInterval is required")
+	}
+
+	switch s.Mode {
+	case InvestmentModeFixed, InvestmentModeValueAveraging:
+	default:
+		return fmt.Errorf("invalid mode %q, valid modes: fixed, valueAveraging", s.Mode)
+	}
+
+	if s.DipBoost != nil {
+		return s.DipBoost.Validate()
+	}
+
+	return nil
+}
+
 func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
 	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.InvestmentInterval})
+
+	if s.DipBoost != nil && s.DipBoost.RSI != nil {
+		session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.DipBoost.RSI.Interval})
+	}
 }
 
 func (s *Strategy) ClosePosition(ctx context.Context, percentage fixedpoint.Value) error {
@@ -91,7 +153,8 @@ func (s *Strategy) InstanceID() string {
 }
 
 func (s *Strategy) Run(ctx context.Context, _ bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
-	if s.BudgetQuota.IsZero() {
+	// the quota is restored from the persistence after the first budget period is started
+	if s.BudgetQuota.IsZero() && s.BudgetPeriodStartTime.IsZero() {
 		s.BudgetQuota = s.Budget
 	}
 
@@ -111,47 +174,131 @@ func (s *Strategy) Run(ctx context.Context, _ bbgo.OrderExecutor, session *bbgo.
 	s.orderExecutor.TradeCollector().OnPositionUpdate(func(position *types.Position) {
 		bbgo.Sync(ctx, s)
 	})
+	s.orderExecutor.TradeCollector().OnTrade(func(trade types.Trade, _, _ fixedpoint.Value) {
+		if trade.Side == types.SideTypeBuy {
+			s.TotalInvestment = s.TotalInvestment.Add(trade.QuoteQuantity)
+		} else {
+			s.TotalInvestment = s.TotalInvestment.Sub(trade.QuoteQuantity)
+		}
+	})
 	s.orderExecutor.Bind()
 
 	numOfInvestmentPerPeriod := fixedpoint.NewFromFloat(float64(s.BudgetPeriod.Duration()) / float64(s.InvestmentInterval.Duration()))
 	s.budgetPerInvestment = s.Budget.Div(numOfInvestmentPerPeriod)
 
-	session.UserDataStream.OnStart(func() {})
-	session.MarketDataStream.OnKLine(func(kline types.KLine) {})
-	session.MarketDataStream.OnKLineClosed(func(kline types.KLine) {
-		if kline.Symbol != s.Symbol || kline.Interval != s.InvestmentInterval {
-			return
+	if s.ValueAveraging != nil {
+		if s.ValueAveraging.TargetGrowth.IsZero() {
+			s.ValueAveraging.TargetGrowth = s.budgetPerInvestment
 		}
 
-		if s.BudgetPeriodStartTime == (time.Time{}) {
-			s.BudgetPeriodStartTime = kline.StartTime.Time().Truncate(time.Minute)
+		if s.ValueAveraging.MaxInvestment.IsZero() {
+			s.ValueAveraging.MaxInvestment = s.ValueAveraging.TargetGrowth.Mul(fixedpoint.NewFromInt(2))
 		}
+	}
 
-		if kline.EndTime.Time().Sub(s.BudgetPeriodStartTime) >= s.BudgetPeriod.Duration() {
-			// reset budget quota
-			s.BudgetQuota = s.Budget
-			s.BudgetPeriodStartTime = kline.StartTime.Time()
-		}
+	if s.DipBoost != nil && s.DipBoost.RSI != nil {
+		s.DipBoost.rsi = session.StandardIndicatorSet(s.Symbol).RSI(*s.DipBoost.RSI)
+	}
+
+	session.UserDataStream.OnStart(func() {})
+	session.MarketDataStream.OnKLine(func(kline types.KLine) {})
+	session.MarketDataStream.OnKLineClosed(types.KLineWith(s.Symbol, s.InvestmentInterval, func(kline types.KLine) {
+		s.invest(ctx, kline)
+	}))
+
+	return nil
+}
+
+func (s *Strategy) invest(ctx context.Context, kline types.KLine) {
+	if s.BudgetPeriodStartTime == (time.Time{}) {
+		s.BudgetPeriodStartTime = kline.StartTime.Time().Truncate(time.Minute)
+	}
 
-		// check if we have quota
-		if s.BudgetQuota.Compare(s.budgetPerInvestment) <= 0 {
-			return
+	if kline.EndTime.Time().Sub(s.BudgetPeriodStartTime) >= s.BudgetPeriod.Duration() {
+		// reset budget quota
+		s.BudgetQuota = s.Budget
+		s.BudgetPeriodStartTime = kline.StartTime.Time()
+	}
+
+	s.NumOfInvestments++
+
+	price := kline.Close
+	amount := s.investmentAmount(price)
+	if amount.Sign() < 0 {
+		s.sellExcess(ctx, amount.Neg(), price)
+		return
+	}
+
+	if s.DipBoost != nil {
+		var klines types.KLineWindow
+		if store, ok := s.session.MarketDataStore(s.Symbol); ok {
+			if window, ok := store.KLinesOfInterval(s.InvestmentInterval); ok {
+				klines = *window
+			}
 		}
 
-		price := kline.Close
-		quantity := s.budgetPerInvestment.Div(price)
-
-		_, err := s.orderExecutor.SubmitOrders(ctx, types.SubmitOrder{
-			Symbol:   s.Symbol,
-			Side:     types.SideTypeBuy,
-			Type:     types.OrderTypeMarket,
-			Quantity: quantity,
-			Market:   s.Market,
-		})
-		if err != nil {
-			log.WithError(err).Errorf("submit order failed")
+		if triggered, reason := s.DipBoost.triggered(price, klines); triggered {
+			amount = amount.Mul(s.DipBoost.Multiplier)
+			log.Infof("dip boost is triggered, %s, boosting the investment to %s", reason, amount.String())
 		}
+	}
+
+	// check if we have quota
+	amount = fixedpoint.Min(amount, s.BudgetQuota)
+	if amount.Sign() <= 0 {
+		return
+	}
+
+	quantity := amount.Div(price)
+	if s.Market.IsDustQuantity(quantity, price) {
+		return
+	}
+
+	_, err := s.orderExecutor.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Side:     types.SideTypeBuy,
+		Type:     types.OrderTypeMarket,
+		Quantity: quantity,
+		Market:   s.Market,
 	})
+	if err != nil {
+		log.WithError(err).Errorf("submit order failed")
+		return
+	}
 
-	return nil
+	s.BudgetQuota = s.BudgetQuota.Sub(amount)
+	bbgo.Sync(ctx, s)
+}
+
+// investmentAmount returns the quote amount to invest by the mode, a negative amount is the excess value to sell
+func (s *Strategy) investmentAmount(price fixedpoint.Value) fixedpoint.Value {
+	if s.Mode != InvestmentModeValueAveraging {
+		return s.budgetPerInvestment
+	}
+
+	positionValue := s.Position.GetBase().Mul(price)
+	amount := s.ValueAveraging.amount(s.NumOfInvestments, positionValue)
+	if amount.Sign() < 0 && !s.ValueAveraging.AllowSell {
+		return fixedpoint.Zero
+	}
+
+	return amount
+}
+
+func (s *Strategy) sellExcess(ctx context.Context, amount, price fixedpoint.Value) {
+	quantity := fixedpoint.Min(amount.Div(price), s.Position.GetBase())
+	if s.Market.IsDustQuantity(quantity, price) {
+		return
+	}
+
+	_, err := s.orderExecutor.SubmitOrders(ctx, types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Side:     types.SideTypeSell,
+		Type:     types.OrderTypeMarket,
+		Quantity: quantity,
+		Market:   s.Market,
+	})
+	if err != nil {
+		log.WithError(err).Errorf("submit order failed")
+	}
 }
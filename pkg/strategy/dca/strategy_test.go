@@ -0,0 +1,79 @@
+package dca
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestValueAveraging_amount(t *testing.T) {
+	number := fixedpoint.MustNewFromString
+	va := &ValueAveraging{
+		TargetGrowth:  number("100"),
+		MaxInvestment: number("150"),
+	}
+
+	// the target value of the 3rd round is 300
+	assert.Equal(t, "50", va.amount(3, number("250")).String())
+
+	// capped by the max investment
+	assert.Equal(t, "150", va.amount(3, number("100")).String())
+
+	// above the target value
+	assert.Equal(t, "-20", va.amount(3, number("320")).String())
+}
+
+func TestStrategy_investmentAmount(t *testing.T) {
+	number := fixedpoint.MustNewFromString
+	market := types.Market{Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT"}
+
+	s := &Strategy{
+		Symbol:              "BTCUSDT",
+		Market:              market,
+		Mode:                InvestmentModeFixed,
+		Position:            types.NewPositionFromMarket(market),
+		budgetPerInvestment: number("100"),
+		ValueAveraging:      &ValueAveraging{TargetGrowth: number("100"), MaxInvestment: number("200")},
+		NumOfInvestments:    2,
+	}
+	s.Position.Base = number("0.01")
+
+	assert.Equal(t, "100", s.investmentAmount(number("10000")).String())
+
+	s.Mode = InvestmentModeValueAveraging
+	// the position value is 100 and the target value is 200
+	assert.Equal(t, "100", s.investmentAmount(number("10000")).String())
+
+	// the position value is 300, nothing to buy
+	assert.Equal(t, "0", s.investmentAmount(number("30000")).String())
+
+	s.ValueAveraging.AllowSell = true
+	assert.Equal(t, "-100", s.investmentAmount(number("30000")).String())
+}
+
+func TestDipBoost_triggered(t *testing.T) {
+	number := fixedpoint.MustNewFromString
+	boost := &DipBoost{Drawdown: number("0.1"), DrawdownWindow: 2}
+	boost.Defaults()
+
+	klines := types.KLineWindow{
+		{High: number("20000"), Close: number("19000")},
+		{High: number("12000"), Close: number("11000")},
+		{High: number("11000"), Close: number("10000")},
+	}
+
+	// the highest price of the last 2 k-lines is 12000
+	triggered, _ := boost.triggered(number("11000"), klines)
+	assert.False(t, triggered)
+
+	triggered, reason := boost.triggered(number("10800"), klines)
+	assert.True(t, triggered)
+	assert.NotEmpty(t, reason)
+
+	assert.Equal(t, "2", boost.Multiplier.String())
+	assert.NoError(t, boost.Validate())
+	assert.Error(t, (&DipBoost{Multiplier: number("2")}).Validate())
+}
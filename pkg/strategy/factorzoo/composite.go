@@ -0,0 +1,123 @@
+package factorzoo
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/strategy/factorzoo/factors"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// Composite sizes the position by the composite score of the weighted factors,
+// the target position is the quantity times the score.
+//
+//	composite:
+//	  interval: 1d
+//	  quantity: 1.0
+//	  longOnly: true
+//	  minScore: 0.1
+//	  factors:
+//	  - name: mom
+//	    window: 1
+//	    weight: 1.0
+//	  - name: pmr
+//	    window: 60
+//	    weight: -0.5
+//	    normalization: rank
+type Composite struct {
+	Interval types.Interval `json:"interval"`
+
+	factorzoo.ScoringConfig
+
+	// Quantity is the target position quantity of the score 1.0 (or -1.0)
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// LongOnly clips the negative score to zero, so that no short position is opened
+	LongOnly bool `json:"longOnly"`
+
+	// MinScore is the minimal absolute score to hold a position, the position is closed below it
+	MinScore float64 `json:"minScore"`
+
+	scorer *factorzoo.Scorer
+
+	orderExecutor *bbgo.GeneralOrderExecutor
+	session       *bbgo.ExchangeSession
+}
+
+func (s *Composite) Validate() error {
+	if len(s.Interval) == 0 {
+		return fmt.Errorf("composite: interval is required")
+	}
+
+	if s.Quantity.Sign() <= 0 {
+		return fmt.Errorf("composite: quantity should be positive")
+	}
+
+	return s.ScoringConfig.Validate()
+}
+
+func (s *Composite) Subscribe(session *bbgo.ExchangeSession, symbol string) {
+	session.Subscribe(types.KLineChannel, symbol, types.SubscribeOptions{Interval: s.Interval})
+}
+
+func (s *Composite) Bind(session *bbgo.ExchangeSession, orderExecutor *bbgo.GeneralOrderExecutor) error {
+	s.session = session
+	s.orderExecutor = orderExecutor
+
+	position := orderExecutor.Position()
+	symbol := position.Symbol
+	store, _ := session.MarketDataStore(symbol)
+
+	scorer, err := s.ScoringConfig.NewScorer(store, s.Interval, nil)
+	if err != nil {
+		return err
+	}
+	s.scorer = scorer
+
+	session.MarketDataStream.OnKLineClosed(types.KLineWith(symbol, s.Interval, func(kline types.KLine) {
+		score, ok := s.scorer.Score()
+		if !ok {
+			return
+		}
+
+		quantity := s.targetQuantity(score).Sub(position.GetBase())
+		log.Infof("%s composite score %f, position base %s, adjusting quantity %s", symbol, score, position.GetBase().String(), quantity.String())
+
+		if position.Market.IsDustQuantity(quantity.Abs(), kline.Close) {
+			return
+		}
+
+		ctx := context.Background()
+		_ = orderExecutor.GracefulCancel(ctx)
+
+		side := types.SideTypeBuy
+		if quantity.Sign() < 0 {
+			side = types.SideTypeSell
+		}
+
+		if _, err := orderExecutor.SubmitOrders(ctx, types.SubmitOrder{
+			Symbol:   symbol,
+			Market:   position.Market,
+			Side:     side,
+			Type:     types.OrderTypeMarket,
+			Quantity: quantity.Abs(),
+			Tag:      "composite",
+		}); err != nil {
+			log.WithError(err).Errorf("can not place market order")
+		}
+	}))
+
+	return nil
+}
+
+// targetQuantity returns the signed target position base of the score
+func (s *Composite) targetQuantity(score float64) fixedpoint.Value {
+	if math.Abs(score) < s.MinScore || (s.LongOnly && score < 0) {
+		return fixedpoint.Zero
+	}
+
+	return s.Quantity.Mul(fixedpoint.NewFromFloat(score))
+}
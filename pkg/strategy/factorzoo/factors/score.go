@@ -0,0 +1,239 @@
+package factorzoo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// maxZScore is the z-score clipping bound, the clipped z-score is scaled into [-1, 1]
+const maxZScore = 3.0
+
+const defaultNormalizationWindow = 60
+
+type Normalization string
+
+const (
+	NormalizationZScore Normalization = "zscore"
+	NormalizationRank   Normalization = "rank"
+	NormalizationNone   Normalization = "none"
+)
+
+// Factor is an indicator-derived factor which can be bound to the kline window updater, e.g., the market data store
+type Factor interface {
+	types.Series
+	Bind(updater indicator.KLineWindowUpdater)
+}
+
+// NewFactor creates the built-in factor by the name
+func NewFactor(name string, iw types.IntervalWindow) (Factor, error) {
+	switch name {
+	case "pvd":
+		return &PVD{IntervalWindow: iw}, nil
+	case "pmr":
+		return &PMR{IntervalWindow: iw}, nil
+	case "mom":
+		return &MOM{IntervalWindow: iw}, nil
+	case "vmom":
+		return &VMOM{IntervalWindow: iw}, nil
+	case "rr":
+		return &RR{IntervalWindow: iw}, nil
+	case "drift":
+		return &indicator.Drift{IntervalWindow: iw}, nil
+	}
+
+	return nil, fmt.Errorf("unknown factor %q", name)
+}
+
+// FactorSetting is the setting of a factor in the composite score
+type FactorSetting struct {
+	// Name is the built-in factor name (pvd, pmr, mom, vmom, rr or drift), or the factor name provided by the strategy
+	Name string `json:"name"`
+
+	// Window is the window of the built-in factor
+	Window int `json:"window"`
+
+	// Weight is the weight of the normalized factor, a negative weight inverts the factor
+	Weight float64 `json:"weight"`
+
+	// Normalization is zscore, rank or none, defaults to zscore
+	Normalization Normalization `json:"normalization"`
+
+	// NormalizationWindow is the number of the factor values used for the normalization, defaults to 60
+	NormalizationWindow int `json:"normalizationWindow"`
+}
+
+// ScoringConfig combines the normalized factors with the weights into a composite score in [-1, 1]
+//
+//	scoring:
+//	  factors:
+//	  - name: mom
+//	    window: 1
+//	    weight: 1.0
+//	  - name: pmr
+//	    window: 60
+//	    weight: 0.5
+//	    normalization: rank
+type ScoringConfig struct {
+	Factors []FactorSetting `json:"factors"`
+}
+
+func (c *ScoringConfig) Validate() error {
+	if len(c.Factors) == 0 {
+		return fmt.Errorf("scoring: factors are required")
+	}
+
+	for _, f := range c.Factors {
+		if f.Weight == 0 {
+			return fmt.Errorf("scoring: the weight of factor %s can not be zero", f.Name)
+		}
+
+		switch f.Normalization {
+		case "", NormalizationZScore, NormalizationRank, NormalizationNone:
+		default:
+			return fmt.Errorf("scoring: unsupported normalization %q of factor %s", f.Normalization, f.Name)
+		}
+	}
+
+	return nil
+}
+
+// NewScorer creates the factors of the config and binds them to the updater,
+// the factors which are not built-in are looked up from the extra series.
+func (c *ScoringConfig) NewScorer(updater indicator.KLineWindowUpdater, interval types.Interval, extra map[string]types.Series) (*Scorer, error) {
+	scorer := &Scorer{}
+	for _, f := range c.Factors {
+		series, ok := extra[f.Name]
+		if !ok {
+			factor, err := NewFactor(f.Name, types.IntervalWindow{Interval: interval, Window: f.Window})
+			if err != nil {
+				return nil, err
+			}
+
+			factor.Bind(updater)
+			series = factor
+		}
+
+		scorer.AddFactor(f.Name, series, f.Weight, f.Normalization, f.NormalizationWindow)
+	}
+
+	return scorer, nil
+}
+
+type scoredFactor struct {
+	name          string
+	series        types.Series
+	weight        float64
+	normalization Normalization
+	window        int
+}
+
+// Scorer calculates the weighted composite score of the normalized factors
+type Scorer struct {
+	factors []scoredFactor
+}
+
+func (s *Scorer) AddFactor(name string, series types.Series, weight float64, normalization Normalization, window int) {
+	if normalization == "" {
+		normalization = NormalizationZScore
+	}
+
+	if window <= 0 {
+		window = defaultNormalizationWindow
+	}
+
+	s.factors = append(s.factors, scoredFactor{
+		name:          name,
+		series:        series,
+		weight:        weight,
+		normalization: normalization,
+		window:        window,
+	})
+}
+
+// Score returns the composite score in [-1, 1], the factors without enough values are skipped,
+// and it returns false if none of the factors is ready.
+func (s *Scorer) Score() (float64, bool) {
+	sum, totalWeight := 0.0, 0.0
+	for _, f := range s.factors {
+		v, ok := Normalize(f.series, f.normalization, f.window)
+		if !ok {
+			continue
+		}
+
+		sum += f.weight * v
+		totalWeight += math.Abs(f.weight)
+	}
+
+	if totalWeight == 0 {
+		return 0, false
+	}
+
+	return sum / totalWeight, true
+}
+
+// Normalize maps the last value of the series into [-1, 1] with the previous values in the window:
+// zscore clips the z-score at 3 standard deviations, rank maps the percentile rank and none clips the raw value.
+// It returns false if the series doesn't have enough values.
+func Normalize(series types.Series, normalization Normalization, window int) (float64, bool) {
+	if series.Length() == 0 {
+		return 0, false
+	}
+
+	last := series.Last(0)
+	if math.IsNaN(last) || math.IsInf(last, 0) {
+		return 0, false
+	}
+
+	if normalization == NormalizationNone {
+		return clip(last, 1.0), true
+	}
+
+	if series.Length() < window {
+		return 0, false
+	}
+
+	values := make([]float64, 0, window)
+	for i := 0; i < window; i++ {
+		if v := series.Last(i); !math.IsNaN(v) && !math.IsInf(v, 0) {
+			values = append(values, v)
+		}
+	}
+
+	if len(values) < 2 {
+		return 0, false
+	}
+
+	switch normalization {
+	case NormalizationRank:
+		sort.Float64s(values)
+		below := sort.SearchFloat64s(values, last)
+		return 2.0*float64(below)/float64(len(values)-1) - 1.0, true
+
+	default:
+		mean := 0.0
+		for _, v := range values {
+			mean += v
+		}
+		mean /= float64(len(values))
+
+		variance := 0.0
+		for _, v := range values {
+			variance += (v - mean) * (v - mean)
+		}
+
+		std := math.Sqrt(variance / float64(len(values)))
+		if std == 0 {
+			return 0, true
+		}
+
+		return clip((last-mean)/std, maxZScore) / maxZScore, true
+	}
+}
+
+func clip(v, bound float64) float64 {
+	return math.Max(-bound, math.Min(bound, v))
+}
@@ -0,0 +1,89 @@
+package factorzoo
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/datatype/floats"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Run("zscore", func(t *testing.T) {
+		series := &floats.Slice{1, 2, 3, 4, 5}
+		v, ok := Normalize(series, NormalizationZScore, 5)
+		assert.True(t, ok)
+		assert.InDelta(t, math.Sqrt(2)/maxZScore, v, 1e-9)
+
+		// clipped at 3 standard deviations
+		series = &floats.Slice{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 100}
+		v, ok = Normalize(series, NormalizationZScore, 11)
+		assert.True(t, ok)
+		assert.Equal(t, 1.0, v)
+	})
+
+	t.Run("rank", func(t *testing.T) {
+		series := &floats.Slice{5, 1, 4, 2, 3}
+		v, ok := Normalize(series, NormalizationRank, 5)
+		assert.True(t, ok)
+		assert.Equal(t, 0.0, v)
+
+		series.Push(0)
+		v, ok = Normalize(series, NormalizationRank, 5)
+		assert.True(t, ok)
+		assert.Equal(t, -1.0, v)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		v, ok := Normalize(&floats.Slice{2.5}, NormalizationNone, 60)
+		assert.True(t, ok)
+		assert.Equal(t, 1.0, v)
+	})
+
+	t.Run("not enough values", func(t *testing.T) {
+		_, ok := Normalize(&floats.Slice{1, 2, 3}, NormalizationZScore, 5)
+		assert.False(t, ok)
+
+		_, ok = Normalize(&floats.Slice{1, 2, 3, 4, math.NaN()}, NormalizationZScore, 5)
+		assert.False(t, ok)
+	})
+}
+
+func TestScorer_Score(t *testing.T) {
+	scorer := &Scorer{}
+	_, ok := scorer.Score()
+	assert.False(t, ok)
+
+	scorer.AddFactor("a", &floats.Slice{0.5}, 1.0, NormalizationNone, 0)
+	scorer.AddFactor("b", &floats.Slice{-1.0}, -3.0, NormalizationNone, 0)
+	scorer.AddFactor("c", &floats.Slice{1, 2}, 1.0, NormalizationZScore, 0)
+
+	// c is skipped since it doesn't have enough values
+	score, ok := scorer.Score()
+	assert.True(t, ok)
+	assert.InDelta(t, (0.5+3.0)/4.0, score, 1e-9)
+}
+
+func TestScoringConfig(t *testing.T) {
+	config := &ScoringConfig{Factors: []FactorSetting{
+		{Name: "mom", Window: 1, Weight: 1.0},
+		{Name: "nrr", Weight: 0.5, Normalization: NormalizationRank},
+	}}
+	assert.NoError(t, config.Validate())
+
+	_, err := config.NewScorer(&testKLineWindowUpdater{}, types.Interval1d, map[string]types.Series{"nrr": &floats.Slice{}})
+	assert.NoError(t, err)
+
+	_, err = config.NewScorer(&testKLineWindowUpdater{}, types.Interval1d, nil)
+	assert.Error(t, err, "nrr is not a built-in factor")
+
+	config.Factors[0].Weight = 0
+	assert.Error(t, config.Validate())
+}
+
+type testKLineWindowUpdater struct{}
+
+func (u *testKLineWindowUpdater) OnKLineWindowUpdate(func(interval types.Interval, window types.KLineWindow)) {
+}
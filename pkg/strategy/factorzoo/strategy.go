@@ -44,6 +44,9 @@ type Strategy struct {
 
 	Linear *Linear `json:"linear"`
 
+	// Composite sizes the position by the composite factor score
+	Composite *Composite `json:"composite"`
+
 	ExitMethods bbgo.ExitMethodSet `json:"exits"`
 
 	session       *bbgo.ExchangeSession
@@ -53,8 +56,22 @@ type Strategy struct {
 	bbgo.StrategyController
 }
 
+func (s *Strategy) Validate() error {
+	if s.Composite != nil {
+		return s.Composite.Validate()
+	}
+
+	return nil
+}
+
 func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
-	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.Linear.Interval})
+	if s.Linear != nil {
+		session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.Linear.Interval})
+	}
+
+	if s.Composite != nil {
+		s.Composite.Subscribe(session, s.Symbol)
+	}
 
 	if !bbgo.IsBackTesting {
 		session.Subscribe(types.MarketTradeChannel, s.Symbol, types.SubscribeOptions{})
@@ -122,6 +139,12 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		s.Linear.Bind(session, s.orderExecutor)
 	}
 
+	if s.Composite != nil {
+		if err := s.Composite.Bind(session, s.orderExecutor); err != nil {
+			return err
+		}
+	}
+
 	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
 		defer wg.Done()
 
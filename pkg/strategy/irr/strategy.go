@@ -11,6 +11,7 @@ import (
 	"github.com/c9s/bbgo/pkg/datatype/floats"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/strategy/factorzoo/factors"
 	"github.com/c9s/bbgo/pkg/types"
 
 	"github.com/sirupsen/logrus"
@@ -50,6 +51,11 @@ type Strategy struct {
 	// for negative return rate
 	nrr *NRR
 
+	// Scoring replaces the ranked negative return rate with the composite factor score for the position sizing,
+	// the negative return rate can be used as the factor "nrr"
+	Scoring *factorzoo.ScoringConfig `json:"scoring,omitempty"`
+	scorer  *factorzoo.Scorer
+
 	stopC chan struct{}
 
 	// StrategyController
@@ -200,6 +206,14 @@ func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
 	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.Interval})
 }
 
+func (s *Strategy) Validate() error {
+	if s.Scoring != nil {
+		return s.Scoring.Validate()
+	}
+
+	return nil
+}
+
 func (s *Strategy) ID() string {
 	return ID
 }
@@ -335,8 +349,26 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		s.nrr.LoadK((*klines)[0:])
 	}
 
+	if s.Scoring != nil {
+		scorer, err := s.Scoring.NewScorer(kLineStore, s.Interval, map[string]types.Series{"nrr": s.nrr})
+		if err != nil {
+			return err
+		}
+
+		s.scorer = scorer
+	}
+
 	s.session.MarketDataStream.OnKLineClosed(types.KLineWith(s.Symbol, s.Interval, func(kline types.KLine) {
 		alphaNrr := fixedpoint.NewFromFloat(s.nrr.RankedValues.Index(1))
+		if s.scorer != nil {
+			score, ok := s.scorer.Score()
+			if !ok {
+				return
+			}
+
+			// map the composite score from [-1, 1] to the inventory weight [0, 1] like the ranked return rate
+			alphaNrr = fixedpoint.NewFromFloat((score + 1.0) / 2.0)
+		}
 
 		// alpha-weighted inventory and cash
 		targetBase := s.QuantityOrAmount.CalculateQuantity(kline.Close).Mul(alphaNrr)
@@ -0,0 +1,109 @@
+package liquidationfade
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type liquidation struct {
+	side     types.SideType
+	price    fixedpoint.Value
+	quantity fixedpoint.Value
+	time     time.Time
+}
+
+// Cascade is the liquidations of one side in the detection window
+type Cascade struct {
+	// Side is the side of the liquidation orders, sell means the long positions are liquidated
+	Side types.SideType
+
+	Notional fixedpoint.Value
+	Quantity fixedpoint.Value
+
+	// AveragePrice is the volume weighted price of the liquidation orders
+	AveragePrice fixedpoint.Value
+
+	// LastPrice is the price of the latest liquidation order
+	LastPrice fixedpoint.Value
+
+	Count int
+}
+
+// cascadeDetector sums up the liquidation notional of each side in the sliding time window
+type cascadeDetector struct {
+	window       time.Duration
+	liquidations []liquidation
+}
+
+func newCascadeDetector(window time.Duration) *cascadeDetector {
+	return &cascadeDetector{window: window}
+}
+
+// add adds the liquidation and returns the cascade of the liquidation side in the window
+func (d *cascadeDetector) add(info types.LiquidationInfo) Cascade {
+	price := info.AveragePrice
+	if price.IsZero() {
+		price = info.Price
+	}
+
+	quantity := info.ExecutedQuantity
+	if quantity.IsZero() {
+		quantity = info.Quantity
+	}
+
+	now := info.TradeTime.Time()
+	d.liquidations = append(d.liquidations, liquidation{
+		side:     info.Side,
+		price:    price,
+		quantity: quantity,
+		time:     now,
+	})
+	d.truncate(now)
+	return d.cascade(info.Side)
+}
+
+func (d *cascadeDetector) truncate(now time.Time) {
+	since := now.Add(-d.window)
+	i := 0
+	for ; i < len(d.liquidations); i++ {
+		if !d.liquidations[i].time.Before(since) {
+			break
+		}
+	}
+
+	d.liquidations = d.liquidations[i:]
+}
+
+func (d *cascadeDetector) cascade(side types.SideType) Cascade {
+	c := Cascade{Side: side}
+	for _, l := range d.liquidations {
+		if l.side != side {
+			continue
+		}
+
+		c.Notional = c.Notional.Add(l.price.Mul(l.quantity))
+		c.Quantity = c.Quantity.Add(l.quantity)
+		c.LastPrice = l.price
+		c.Count++
+	}
+
+	if c.Quantity.Sign() > 0 {
+		c.AveragePrice = c.Notional.Div(c.Quantity)
+	}
+
+	return c
+}
+
+// reset removes the liquidations of the side, so that one cascade is faded only once
+func (d *cascadeDetector) reset(side types.SideType) {
+	liquidations := d.liquidations[:0]
+	for _, l := range d.liquidations {
+		if l.side != side {
+			liquidations = append(liquidations, l)
+		}
+	}
+
+	d.liquidations = liquidations
+}
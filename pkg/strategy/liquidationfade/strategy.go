@@ -0,0 +1,321 @@
+package liquidationfade
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "liquidationfade"
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// Strategy fades the liquidation cascades of the futures market,
+// when the long positions are liquidated in a cascade, it places a maker buy order below the liquidation price,
+// and when the short positions are liquidated, it places a maker sell order above the liquidation price.
+type Strategy struct {
+	Environment *bbgo.Environment
+	Symbol      string `json:"symbol"`
+	Market      types.Market
+
+	// Interval is the interval to check the order ttl and the holding period
+	Interval types.Interval `json:"interval"`
+
+	// Window is the time window to sum up the liquidations of a cascade, defaults to 1m
+	Window types.Duration `json:"window"`
+
+	// MinNotional is the min liquidation notional of one side in the window to trigger the fade
+	MinNotional fixedpoint.Value `json:"minNotional"`
+
+	// Quantity is the base quantity of each fade order
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// EntrySpread is the ratio to place the maker order away from the last liquidation price, for example, 0.002 for 0.2%
+	EntrySpread fixedpoint.Value `json:"entrySpread"`
+
+	// MaxExposure is the max notional of the position in quote currency
+	MaxExposure fixedpoint.Value `json:"maxExposure"`
+
+	// OrderTTL is the duration to cancel the unfilled fade orders, defaults to 1m
+	OrderTTL types.Duration `json:"orderTTL"`
+
+	// MaxHoldingPeriod closes the position when it's held longer than the period, defaults to 1h
+	MaxHoldingPeriod types.Duration `json:"maxHoldingPeriod"`
+
+	// Cooldown is the min duration between two fades
+	Cooldown types.Duration `json:"cooldown"`
+
+	ExitMethods bbgo.ExitMethodSet `json:"exits"`
+
+	Position    *types.Position    `persistence:"position"`
+	ProfitStats *types.ProfitStats `persistence:"profit_stats"`
+	TradeStats  *types.TradeStats  `persistence:"trade_stats"`
+
+	// PositionOpenedAt is the time the position is opened from zero, it's used for the time-based exit
+	PositionOpenedAt time.Time `persistence:"position_opened_at"`
+
+	session       *bbgo.ExchangeSession
+	orderExecutor *bbgo.GeneralOrderExecutor
+
+	detector    *cascadeDetector
+	lastFadedAt time.Time
+	mu          sync.Mutex
+
+	bbgo.StrategyController
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) InstanceID() string {
+	return fmt.Sprintf("%s:%s", ID, s.Symbol)
+}
+
+func (s *Strategy) Defaults() error {
+	if s.Interval == "" {
+		s.Interval = types.Interval1m
+	}
+
+	if s.Window == 0 {
+		s.Window = types.Duration(time.Minute)
+	}
+
+	if s.OrderTTL == 0 {
+		s.OrderTTL = types.Duration(time.Minute)
+	}
+
+	if s.MaxHoldingPeriod == 0 {
+		s.MaxHoldingPeriod = types.Duration(time.Hour)
+	}
+
+	return nil
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return errors.New("symbol is required")
+	}
+
+	if s.MinNotional.Sign() <= 0 {
+		return errors.New("minNotional should be greater than zero")
+	}
+
+	if s.Quantity.Sign() <= 0 {
+		return errors.New("quantity should be greater than zero")
+	}
+
+	if s.MaxExposure.Sign() <= 0 {
+		return errors.New("maxExposure should be greater than zero")
+	}
+
+	return nil
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	session.Subscribe(types.LiquidationChannel, s.Symbol, types.SubscribeOptions{})
+	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.Interval})
+	s.ExitMethods.SetAndSubscribe(session, s)
+}
+
+func (s *Strategy) Run(ctx context.Context, _ bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	if !session.Futures {
+		return fmt.Errorf("session %s is not a futures session, the liquidation feed is only available on the futures market", session.Name)
+	}
+
+	if s.Position == nil {
+		s.Position = types.NewPositionFromMarket(s.Market)
+	}
+
+	if s.ProfitStats == nil {
+		s.ProfitStats = types.NewProfitStats(s.Market)
+	}
+
+	if s.TradeStats == nil {
+		s.TradeStats = types.NewTradeStats(s.Symbol)
+	}
+
+	s.Status = types.StrategyStatusRunning
+	s.OnSuspend(func() {
+		_ = s.orderExecutor.GracefulCancel(ctx)
+	})
+
+	s.OnEmergencyStop(func() {
+		_ = s.orderExecutor.GracefulCancel(ctx)
+		_ = s.orderExecutor.ClosePosition(ctx, fixedpoint.One, "emergencyStop")
+	})
+
+	s.session = session
+	s.detector = newCascadeDetector(s.Window.Duration())
+
+	s.orderExecutor = bbgo.NewGeneralOrderExecutor(session, s.Symbol, ID, s.InstanceID(), s.Position)
+	s.orderExecutor.BindEnvironment(s.Environment)
+	s.orderExecutor.BindProfitStats(s.ProfitStats)
+	s.orderExecutor.BindTradeStats(s.TradeStats)
+	s.orderExecutor.TradeCollector().OnPositionUpdate(func(position *types.Position) {
+		if position.GetBase().IsZero() {
+			s.PositionOpenedAt = time.Time{}
+		} else if s.PositionOpenedAt.IsZero() {
+			s.PositionOpenedAt = time.Now()
+		}
+
+		bbgo.Sync(ctx, s)
+	})
+	s.orderExecutor.Bind()
+
+	for _, method := range s.ExitMethods {
+		method.Bind(session, s.orderExecutor)
+	}
+
+	session.MarketDataStream.OnLiquidation(func(info types.LiquidationInfo) {
+		if info.Symbol != s.Symbol || s.GetStatus() != types.StrategyStatusRunning {
+			return
+		}
+
+		s.handleLiquidation(ctx, info)
+	})
+
+	session.MarketDataStream.OnKLineClosed(types.KLineWith(s.Symbol, s.Interval, func(kline types.KLine) {
+		s.cancelExpiredOrders(ctx, kline.EndTime.Time())
+		s.checkHoldingPeriod(ctx, kline.EndTime.Time())
+	}))
+
+	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+		_ = s.orderExecutor.GracefulCancel(ctx)
+		bbgo.Sync(ctx, s)
+	})
+
+	return nil
+}
+
+func (s *Strategy) handleLiquidation(ctx context.Context, info types.LiquidationInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cascade := s.detector.add(info)
+	if cascade.Notional.Compare(s.MinNotional) < 0 {
+		return
+	}
+
+	now := info.TradeTime.Time()
+	if s.Cooldown > 0 && now.Sub(s.lastFadedAt) < s.Cooldown.Duration() {
+		return
+	}
+
+	submitOrder, ok := s.fadeOrder(cascade)
+	if !ok {
+		return
+	}
+
+	s.detector.reset(cascade.Side)
+	s.lastFadedAt = now
+
+	log.Infof("fading %d %s liquidations of %s %s: %s",
+		cascade.Count, cascade.Side, cascade.Notional.String(), s.Market.QuoteCurrency, submitOrder.String())
+
+	if _, err := s.orderExecutor.SubmitOrders(ctx, submitOrder); err != nil {
+		log.WithError(err).Errorf("unable to submit the fade order")
+	}
+}
+
+// fadeOrder creates the maker order against the cascade, it returns false when the exposure limit is reached
+func (s *Strategy) fadeOrder(cascade Cascade) (types.SubmitOrder, bool) {
+	side := types.SideTypeBuy
+	price := cascade.LastPrice.Mul(fixedpoint.One.Sub(s.EntrySpread))
+	if cascade.Side == types.SideTypeBuy {
+		side = types.SideTypeSell
+		price = cascade.LastPrice.Mul(fixedpoint.One.Add(s.EntrySpread))
+	}
+
+	price = s.Market.TruncatePrice(price)
+	if price.Sign() <= 0 {
+		return types.SubmitOrder{}, false
+	}
+
+	quantity := s.allowedQuantity(side, price)
+	if quantity.Sign() <= 0 || s.Market.IsDustQuantity(quantity, price) {
+		log.Infof("the exposure of %s reaches the limit %s, skip fading", s.Symbol, s.MaxExposure.String())
+		return types.SubmitOrder{}, false
+	}
+
+	return types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Side:     side,
+		Type:     types.OrderTypeLimitMaker,
+		Price:    price,
+		Quantity: quantity,
+		Market:   s.Market,
+		Tag:      "liquidationFade",
+	}, true
+}
+
+// allowedQuantity returns the order quantity within the max exposure,
+// the pending orders of the same side are counted into the exposure.
+func (s *Strategy) allowedQuantity(side types.SideType, price fixedpoint.Value) fixedpoint.Value {
+	exposure := s.Position.GetBase()
+	for _, o := range s.orderExecutor.ActiveMakerOrders().Orders() {
+		pending := o.Quantity.Sub(o.ExecutedQuantity)
+		if o.Side == types.SideTypeBuy {
+			exposure = exposure.Add(pending)
+		} else {
+			exposure = exposure.Sub(pending)
+		}
+	}
+
+	maxBase := s.MaxExposure.Div(price)
+	var room fixedpoint.Value
+	if side == types.SideTypeBuy {
+		room = maxBase.Sub(exposure)
+	} else {
+		room = maxBase.Add(exposure)
+	}
+
+	return s.Market.RoundDownQuantityByPrecision(fixedpoint.Min(s.Quantity, room))
+}
+
+// cancelExpiredOrders cancels the fade orders that are not filled in the order ttl
+func (s *Strategy) cancelExpiredOrders(ctx context.Context, now time.Time) {
+	var expired []types.Order
+	for _, o := range s.orderExecutor.ActiveMakerOrders().Orders() {
+		if now.Sub(o.CreationTime.Time()) >= s.OrderTTL.Duration() {
+			expired = append(expired, o)
+		}
+	}
+
+	if len(expired) == 0 {
+		return
+	}
+
+	if err := s.orderExecutor.GracefulCancel(ctx, expired...); err != nil {
+		log.WithError(err).Errorf("unable to cancel the expired fade orders")
+	}
+}
+
+// checkHoldingPeriod closes the position when it's held longer than the max holding period
+func (s *Strategy) checkHoldingPeriod(ctx context.Context, now time.Time) {
+	if s.PositionOpenedAt.IsZero() || s.Position.GetBase().IsZero() {
+		return
+	}
+
+	if now.Sub(s.PositionOpenedAt) < s.MaxHoldingPeriod.Duration() {
+		return
+	}
+
+	bbgo.Notify("%s: the position is held longer than %s, closing the position", s.InstanceID(), s.MaxHoldingPeriod.Duration())
+	if err := s.orderExecutor.ClosePosition(ctx, fixedpoint.One, "timeExit"); err != nil {
+		log.WithError(err).Errorf("unable to close the position")
+	}
+}
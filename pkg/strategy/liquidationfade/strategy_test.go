@@ -0,0 +1,110 @@
+package liquidationfade
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func newTestLiquidation(side types.SideType, price, quantity string, t time.Time) types.LiquidationInfo {
+	return types.LiquidationInfo{
+		Symbol:           "BTCUSDT",
+		Side:             side,
+		AveragePrice:     fixedpoint.MustNewFromString(price),
+		Quantity:         fixedpoint.MustNewFromString(quantity),
+		ExecutedQuantity: fixedpoint.MustNewFromString(quantity),
+		TradeTime:        types.Time(t),
+	}
+}
+
+func Test_cascadeDetector(t *testing.T) {
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	detector := newCascadeDetector(time.Minute)
+
+	detector.add(newTestLiquidation(types.SideTypeSell, "20000", "1", now))
+	detector.add(newTestLiquidation(types.SideTypeBuy, "20100", "1", now.Add(10*time.Second)))
+
+	cascade := detector.add(newTestLiquidation(types.SideTypeSell, "19800", "1", now.Add(30*time.Second)))
+	assert.Equal(t, 2, cascade.Count)
+	assert.Equal(t, "39800", cascade.Notional.String())
+	assert.Equal(t, "19900", cascade.AveragePrice.String())
+	assert.Equal(t, "19800", cascade.LastPrice.String())
+
+	// the first liquidation is out of the window
+	cascade = detector.add(newTestLiquidation(types.SideTypeSell, "19500", "2", now.Add(70*time.Second)))
+	assert.Equal(t, 2, cascade.Count)
+	assert.Equal(t, "58800", cascade.Notional.String())
+
+	detector.reset(types.SideTypeSell)
+	cascade = detector.cascade(types.SideTypeSell)
+	assert.Equal(t, 0, cascade.Count)
+	assert.Equal(t, 1, detector.cascade(types.SideTypeBuy).Count)
+}
+
+func TestStrategy_fadeOrder(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockEx := mocks.NewMockExchange(mockCtrl)
+	mockEx.EXPECT().NewStream().Return(&types.StandardStream{}).Times(2)
+
+	market := types.Market{
+		Symbol:          "BTCUSDT",
+		BaseCurrency:    "BTC",
+		QuoteCurrency:   "USDT",
+		TickSize:        fixedpoint.MustNewFromString("0.1"),
+		StepSize:        fixedpoint.MustNewFromString("0.001"),
+		PricePrecision:  1,
+		VolumePrecision: 3,
+		MinQuantity:     fixedpoint.MustNewFromString("0.001"),
+		MinNotional:     fixedpoint.NewFromFloat(5),
+	}
+
+	session := bbgo.NewExchangeSession("test", mockEx)
+	position := types.NewPositionFromMarket(market)
+
+	s := &Strategy{
+		Symbol:      "BTCUSDT",
+		Market:      market,
+		Quantity:    fixedpoint.MustNewFromString("0.1"),
+		EntrySpread: fixedpoint.MustNewFromString("0.01"),
+		MaxExposure: fixedpoint.NewFromFloat(3000),
+		Position:    position,
+	}
+	s.orderExecutor = bbgo.NewGeneralOrderExecutor(session, "BTCUSDT", ID, s.InstanceID(), position)
+
+	// the long positions are liquidated, buy below the liquidation price
+	order, ok := s.fadeOrder(Cascade{Side: types.SideTypeSell, LastPrice: fixedpoint.NewFromFloat(20000)})
+	if assert.True(t, ok) {
+		assert.Equal(t, types.SideTypeBuy, order.Side)
+		assert.Equal(t, types.OrderTypeLimitMaker, order.Type)
+		assert.Equal(t, "19800", order.Price.String())
+		assert.Equal(t, "0.1", order.Quantity.String())
+	}
+
+	// the long exposure is limited by the max exposure
+	position.Base = fixedpoint.MustNewFromString("0.1")
+	order, ok = s.fadeOrder(Cascade{Side: types.SideTypeSell, LastPrice: fixedpoint.NewFromFloat(20000)})
+	if assert.True(t, ok) {
+		assert.Equal(t, "0.051", order.Quantity.String())
+	}
+
+	position.Base = fixedpoint.MustNewFromString("0.16")
+	_, ok = s.fadeOrder(Cascade{Side: types.SideTypeSell, LastPrice: fixedpoint.NewFromFloat(20000)})
+	assert.False(t, ok)
+
+	// the short positions are liquidated, sell above the liquidation price
+	order, ok = s.fadeOrder(Cascade{Side: types.SideTypeBuy, LastPrice: fixedpoint.NewFromFloat(20000)})
+	if assert.True(t, ok) {
+		assert.Equal(t, types.SideTypeSell, order.Side)
+		assert.Equal(t, "20200", order.Price.String())
+		assert.Equal(t, "0.1", order.Quantity.String())
+	}
+}
@@ -0,0 +1,49 @@
+package pairstrading
+
+import "math"
+
+// defaultCriticalValue is the 5% critical value of the Engle-Granger cointegration test with 2 variables
+const defaultCriticalValue = -3.34
+
+// dickeyFullerStatistic returns the t-statistic of gamma in the regression
+// Δe(t) = gamma * e(t-1) + ε, a more negative statistic means the series is more likely to be stationary.
+func dickeyFullerStatistic(series []float64) float64 {
+	n := len(series) - 1
+	if n < 3 {
+		return 0
+	}
+
+	var sumXY, sumXX float64
+	for t := 1; t <= n; t++ {
+		lag := series[t-1]
+		diff := series[t] - lag
+		sumXY += lag * diff
+		sumXX += lag * lag
+	}
+
+	if sumXX == 0 {
+		return 0
+	}
+
+	gamma := sumXY / sumXX
+
+	var sse float64
+	for t := 1; t <= n; t++ {
+		e := series[t] - series[t-1] - gamma*series[t-1]
+		sse += e * e
+	}
+
+	se := math.Sqrt(sse / float64(n-1) / sumXX)
+	if se == 0 {
+		return math.Inf(-1)
+	}
+
+	return gamma / se
+}
+
+// isCointegrated runs the Engle-Granger test, y is regressed on x and the residuals are tested for the unit root
+func isCointegrated(x, y []float64, criticalValue float64) (bool, float64) {
+	alpha, beta := ols(x, y)
+	stat := dickeyFullerStatistic(spreads(x, y, alpha, beta))
+	return stat < criticalValue, stat
+}
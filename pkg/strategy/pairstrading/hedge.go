@@ -0,0 +1,146 @@
+package pairstrading
+
+import (
+	"math"
+)
+
+// HedgeRatioMethod is the method to estimate the hedge ratio between the two legs
+type HedgeRatioMethod string
+
+const (
+	HedgeRatioMethodOLS    HedgeRatioMethod = "ols"
+	HedgeRatioMethodKalman HedgeRatioMethod = "kalman"
+)
+
+// ols regresses y on x and returns the intercept and the slope
+func ols(x, y []float64) (alpha, beta float64) {
+	n := len(x)
+	if n == 0 || n != len(y) {
+		return 0, 0
+	}
+
+	var sumX, sumY float64
+	for i := 0; i < n; i++ {
+		sumX += x[i]
+		sumY += y[i]
+	}
+
+	meanX := sumX / float64(n)
+	meanY := sumY / float64(n)
+
+	var cov, varX float64
+	for i := 0; i < n; i++ {
+		dx := x[i] - meanX
+		cov += dx * (y[i] - meanY)
+		varX += dx * dx
+	}
+
+	if varX == 0 {
+		return meanY, 0
+	}
+
+	beta = cov / varX
+	alpha = meanY - beta*meanX
+	return alpha, beta
+}
+
+// KalmanHedge estimates the dynamic intercept and slope of y = alpha + beta * x with the Kalman filter,
+// the state [beta, alpha] is assumed to follow a random walk.
+type KalmanHedge struct {
+	// Delta is the ratio of the state transition covariance, the larger delta adapts the hedge ratio faster
+	Delta float64 `json:"delta"`
+
+	// ObservationVariance is the variance of the measurement noise
+	ObservationVariance float64 `json:"observationVariance"`
+
+	initialized bool
+	state       [2]float64
+	covariance  [2][2]float64
+}
+
+func (k *KalmanHedge) Defaults() {
+	if k.Delta == 0 {
+		k.Delta = 1e-4
+	}
+
+	if k.ObservationVariance == 0 {
+		k.ObservationVariance = 1e-3
+	}
+}
+
+// Update updates the state with the observation and returns the intercept and the slope
+func (k *KalmanHedge) Update(x, y float64) (alpha, beta float64) {
+	if !k.initialized {
+		k.state = [2]float64{y / x, 0}
+		k.covariance = [2][2]float64{{1, 0}, {0, 1}}
+		k.initialized = true
+	}
+
+	// predict: the covariance grows by the transition covariance
+	vw := k.Delta / (1 - k.Delta)
+	r := [2][2]float64{
+		{k.covariance[0][0] + vw, k.covariance[0][1]},
+		{k.covariance[1][0], k.covariance[1][1] + vw},
+	}
+
+	// the observation vector is [x, 1]
+	h := [2]float64{x, 1}
+	prediction := h[0]*k.state[0] + h[1]*k.state[1]
+	residual := y - prediction
+
+	rh := [2]float64{
+		r[0][0]*h[0] + r[0][1]*h[1],
+		r[1][0]*h[0] + r[1][1]*h[1],
+	}
+	variance := h[0]*rh[0] + h[1]*rh[1] + k.ObservationVariance
+
+	gain := [2]float64{rh[0] / variance, rh[1] / variance}
+	k.state[0] += gain[0] * residual
+	k.state[1] += gain[1] * residual
+
+	// covariance = r - gain * h^T * r
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			k.covariance[i][j] = r[i][j] - gain[i]*(h[0]*r[0][j]+h[1]*r[1][j])
+		}
+	}
+
+	return k.state[1], k.state[0]
+}
+
+// spreads returns the residuals of y - (alpha + beta * x)
+func spreads(x, y []float64, alpha, beta float64) []float64 {
+	out := make([]float64, len(x))
+	for i := range x {
+		out[i] = y[i] - alpha - beta*x[i]
+	}
+
+	return out
+}
+
+// zScore returns the z-score of the last value of the series
+func zScore(series []float64) float64 {
+	n := len(series)
+	if n < 2 {
+		return 0
+	}
+
+	var sum float64
+	for _, v := range series {
+		sum += v
+	}
+
+	mean := sum / float64(n)
+
+	var variance float64
+	for _, v := range series {
+		variance += (v - mean) * (v - mean)
+	}
+
+	std := math.Sqrt(variance / float64(n-1))
+	if std == 0 {
+		return 0
+	}
+
+	return (series[n-1] - mean) / std
+}
@@ -0,0 +1,538 @@
+package pairstrading
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "pairstrading"
+
+var log = logrus.WithField("strategy", ID)
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// SpreadSide is the direction of the spread position,
+// long spread buys the symbol and hedges with the pair symbol, short spread sells the symbol.
+type SpreadSide int
+
+const (
+	SpreadFlat  SpreadSide = 0
+	SpreadLong  SpreadSide = 1
+	SpreadShort SpreadSide = -1
+)
+
+type State struct {
+	SpreadSide SpreadSide `json:"spreadSide"`
+
+	// Cointegrated is the result of the last cointegration test
+	Cointegrated bool `json:"cointegrated"`
+
+	// CheckedAt is the time of the last cointegration test
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// Strategy is the pairs trading strategy, it regresses the log price of the symbol on the log price of the pair symbol,
+// and trades the z-score of the spread when the two symbols are cointegrated.
+type Strategy struct {
+	Environment *bbgo.Environment
+
+	// Symbol is the dependent leg of the regression
+	Symbol string `json:"symbol"`
+	Market types.Market
+
+	// PairSymbol is the independent leg of the regression
+	PairSymbol string `json:"pairSymbol"`
+
+	Interval types.Interval `json:"interval"`
+
+	// Window is the number of the k-lines for the hedge ratio and the z-score, defaults to 100
+	Window int `json:"window"`
+
+	// HedgeRatioMethod is ols or kalman, defaults to ols
+	HedgeRatioMethod HedgeRatioMethod `json:"hedgeRatioMethod"`
+
+	// Kalman is the config of the kalman hedge ratio method
+	Kalman *KalmanHedge `json:"kalman,omitempty"`
+
+	// EntryZScore is the z-score to open the spread position, defaults to 2
+	EntryZScore float64 `json:"entryZScore"`
+
+	// ExitZScore is the z-score to close the spread position, defaults to 0 (the mean)
+	ExitZScore float64 `json:"exitZScore"`
+
+	// StopZScore closes the spread position when the z-score keeps diverging, disabled when it's zero
+	StopZScore float64 `json:"stopZScore,omitempty"`
+
+	// QuoteQuantity is the notional of the symbol leg, the notional of the pair leg is weighted by the hedge ratio
+	QuoteQuantity fixedpoint.Value `json:"quoteQuantity"`
+
+	// CointegrationCheckInterval is the period to re-validate the cointegration, defaults to 24h
+	CointegrationCheckInterval types.Duration `json:"cointegrationCheckInterval"`
+
+	// CriticalValue is the critical value of the Engle-Granger test, defaults to -3.34 (5%)
+	CriticalValue float64 `json:"criticalValue"`
+
+	Position        *types.Position    `persistence:"position"`
+	PairPosition    *types.Position    `persistence:"pair_position"`
+	ProfitStats     *types.ProfitStats `persistence:"profit_stats"`
+	PairProfitStats *types.ProfitStats `persistence:"pair_profit_stats"`
+	State           *State             `persistence:"state"`
+
+	session                          *bbgo.ExchangeSession
+	pairMarket                       types.Market
+	orderExecutor, pairOrderExecutor *bbgo.GeneralOrderExecutor
+
+	// ys and xs are the aligned log close prices of the symbol and the pair symbol
+	ys, xs []float64
+
+	// pendingCloses are the closes waiting for the k-line of the other symbol with the same start time
+	pendingCloses map[string]map[time.Time]fixedpoint.Value
+
+	mu sync.Mutex
+
+	bbgo.StrategyController
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) InstanceID() string {
+	return fmt.Sprintf("%s:%s-%s", ID, s.Symbol, s.PairSymbol)
+}
+
+func (s *Strategy) Defaults() error {
+	if s.Interval == "" {
+		s.Interval = types.Interval1h
+	}
+
+	if s.Window == 0 {
+		s.Window = 100
+	}
+
+	if s.HedgeRatioMethod == "" {
+		s.HedgeRatioMethod = HedgeRatioMethodOLS
+	}
+
+	if s.HedgeRatioMethod == HedgeRatioMethodKalman && s.Kalman == nil {
+		s.Kalman = &KalmanHedge{}
+	}
+
+	if s.Kalman != nil {
+		s.Kalman.Defaults()
+	}
+
+	if s.EntryZScore == 0 {
+		s.EntryZScore = 2.0
+	}
+
+	if s.CointegrationCheckInterval == 0 {
+		s.CointegrationCheckInterval = types.Duration(24 * time.Hour)
+	}
+
+	if s.CriticalValue == 0 {
+		s.CriticalValue = defaultCriticalValue
+	}
+
+	return nil
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 || len(s.PairSymbol) == 0 {
+		return errors.New("symbol and pairSymbol are required")
+	}
+
+	if s.Symbol == s.PairSymbol {
+		return errors.New("symbol and pairSymbol should be different")
+	}
+
+	switch s.HedgeRatioMethod {
+	case HedgeRatioMethodOLS, HedgeRatioMethodKalman:
+	default:
+		return fmt.Errorf("invalid hedgeRatioMethod %q, valid methods: ols, kalman", s.HedgeRatioMethod)
+	}
+
+	if s.Window < 10 {
+		return errors.New("window should be greater than or equal to 10")
+	}
+
+	if s.EntryZScore <= s.ExitZScore {
+		return errors.New("entryZScore should be greater than exitZScore")
+	}
+
+	if s.StopZScore != 0 && s.StopZScore <= s.EntryZScore {
+		return errors.New("stopZScore should be greater than entryZScore")
+	}
+
+	if s.QuoteQuantity.Sign() <= 0 {
+		return errors.New("quoteQuantity should be greater than zero")
+	}
+
+	return nil
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.Interval})
+	session.Subscribe(types.KLineChannel, s.PairSymbol, types.SubscribeOptions{Interval: s.Interval})
+}
+
+func (s *Strategy) Run(ctx context.Context, _ bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	var ok bool
+	s.pairMarket, ok = session.Market(s.PairSymbol)
+	if !ok {
+		return fmt.Errorf("market %s is not defined", s.PairSymbol)
+	}
+
+	if s.Position == nil {
+		s.Position = types.NewPositionFromMarket(s.Market)
+	}
+
+	if s.PairPosition == nil {
+		s.PairPosition = types.NewPositionFromMarket(s.pairMarket)
+	}
+
+	if s.ProfitStats == nil {
+		s.ProfitStats = types.NewProfitStats(s.Market)
+	}
+
+	if s.PairProfitStats == nil {
+		s.PairProfitStats = types.NewProfitStats(s.pairMarket)
+	}
+
+	if s.State == nil {
+		s.State = &State{}
+	}
+
+	s.session = session
+	s.pendingCloses = map[string]map[time.Time]fixedpoint.Value{
+		s.Symbol:     {},
+		s.PairSymbol: {},
+	}
+
+	s.orderExecutor = s.allocateOrderExecutor(ctx, session, s.Symbol, s.Position, s.ProfitStats)
+	s.pairOrderExecutor = s.allocateOrderExecutor(ctx, session, s.PairSymbol, s.PairPosition, s.PairProfitStats)
+
+	s.Status = types.StrategyStatusRunning
+	s.OnSuspend(func() {
+		_ = s.orderExecutor.GracefulCancel(ctx)
+		_ = s.pairOrderExecutor.GracefulCancel(ctx)
+	})
+
+	s.OnEmergencyStop(func() {
+		s.closeSpread(ctx, "emergency stop")
+	})
+
+	s.preload()
+
+	session.MarketDataStream.OnKLineClosed(func(kline types.KLine) {
+		if kline.Interval != s.Interval || (kline.Symbol != s.Symbol && kline.Symbol != s.PairSymbol) {
+			return
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		y, x, ok := s.alignClose(kline)
+		if !ok {
+			return
+		}
+
+		s.update(ctx, y, x, kline.EndTime.Time())
+	})
+
+	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+		bbgo.Sync(ctx, s)
+	})
+
+	return nil
+}
+
+func (s *Strategy) allocateOrderExecutor(ctx context.Context, session *bbgo.ExchangeSession, symbol string, position *types.Position, profitStats *types.ProfitStats) *bbgo.GeneralOrderExecutor {
+	orderExecutor := bbgo.NewGeneralOrderExecutor(session, symbol, ID, s.InstanceID(), position)
+	orderExecutor.BindEnvironment(s.Environment)
+	orderExecutor.BindProfitStats(profitStats)
+	orderExecutor.TradeCollector().OnPositionUpdate(func(position *types.Position) {
+		bbgo.Sync(ctx, s)
+	})
+	orderExecutor.Bind()
+	return orderExecutor
+}
+
+// preload warms up the price series from the preloaded k-lines of the market data store
+func (s *Strategy) preload() {
+	klines, ok := s.storedKLines(s.Symbol)
+	if !ok {
+		return
+	}
+
+	pairKLines, ok := s.storedKLines(s.PairSymbol)
+	if !ok {
+		return
+	}
+
+	pairCloses := make(map[time.Time]fixedpoint.Value, len(pairKLines))
+	for _, k := range pairKLines {
+		pairCloses[k.StartTime.Time()] = k.Close
+	}
+
+	for _, k := range klines {
+		if x, ok := pairCloses[k.StartTime.Time()]; ok {
+			s.push(k.Close, x)
+		}
+	}
+}
+
+func (s *Strategy) storedKLines(symbol string) (types.KLineWindow, bool) {
+	store, ok := s.session.MarketDataStore(symbol)
+	if !ok {
+		return nil, false
+	}
+
+	klines, ok := store.KLinesOfInterval(s.Interval)
+	if !ok {
+		return nil, false
+	}
+
+	return *klines, true
+}
+
+// alignClose returns the closes of both symbols when the k-lines of the same start time are both closed
+func (s *Strategy) alignClose(kline types.KLine) (y, x fixedpoint.Value, ok bool) {
+	startTime := kline.StartTime.Time()
+	other := s.PairSymbol
+	if kline.Symbol == s.PairSymbol {
+		other = s.Symbol
+	}
+
+	otherClose, ok := s.pendingCloses[other][startTime]
+	if !ok {
+		s.pendingCloses[kline.Symbol][startTime] = kline.Close
+		return y, x, false
+	}
+
+	// drop the closes that can not be aligned anymore
+	for _, closes := range s.pendingCloses {
+		for t := range closes {
+			if !t.After(startTime) {
+				delete(closes, t)
+			}
+		}
+	}
+
+	if kline.Symbol == s.Symbol {
+		return kline.Close, otherClose, true
+	}
+
+	return otherClose, kline.Close, true
+}
+
+// push appends the log prices to the series, the kalman hedge is updated with every price
+func (s *Strategy) push(y, x fixedpoint.Value) {
+	if y.Sign() <= 0 || x.Sign() <= 0 {
+		return
+	}
+
+	ly, lx := math.Log(y.Float64()), math.Log(x.Float64())
+	s.ys = append(s.ys, ly)
+	s.xs = append(s.xs, lx)
+	if len(s.ys) > s.Window {
+		s.ys = s.ys[len(s.ys)-s.Window:]
+		s.xs = s.xs[len(s.xs)-s.Window:]
+	}
+
+	if s.HedgeRatioMethod == HedgeRatioMethodKalman {
+		s.Kalman.Update(lx, ly)
+	}
+}
+
+// hedgeRatio returns the intercept and the slope of the regression
+func (s *Strategy) hedgeRatio() (alpha, beta float64) {
+	if s.HedgeRatioMethod == HedgeRatioMethodKalman {
+		return s.Kalman.state[1], s.Kalman.state[0]
+	}
+
+	return ols(s.xs, s.ys)
+}
+
+func (s *Strategy) update(ctx context.Context, y, x fixedpoint.Value, now time.Time) {
+	s.push(y, x)
+	if len(s.ys) < s.Window {
+		return
+	}
+
+	if now.Sub(s.State.CheckedAt) >= s.CointegrationCheckInterval.Duration() {
+		s.validateCointegration(ctx, now)
+	}
+
+	alpha, beta := s.hedgeRatio()
+	z := zScore(spreads(s.xs, s.ys, alpha, beta))
+
+	side := s.decide(z)
+	if side == s.State.SpreadSide {
+		return
+	}
+
+	if side == SpreadFlat {
+		s.closeSpread(ctx, fmt.Sprintf("z-score %f", z))
+		return
+	}
+
+	log.Infof("z-score %f of the spread crosses the entry %f, opening %s spread with hedge ratio %f", z, s.EntryZScore, side, beta)
+	s.openSpread(ctx, side, y, x, beta)
+}
+
+// decide returns the spread side to open, SpreadFlat to close the spread position, or the current side to hold
+func (s *Strategy) decide(z float64) SpreadSide {
+	switch s.State.SpreadSide {
+	case SpreadFlat:
+		if !s.State.Cointegrated {
+			return SpreadFlat
+		}
+
+		if z >= s.EntryZScore {
+			return SpreadShort
+		} else if z <= -s.EntryZScore {
+			return SpreadLong
+		}
+
+	case SpreadLong:
+		if z >= -s.ExitZScore || (s.StopZScore > 0 && z <= -s.StopZScore) {
+			return SpreadFlat
+		}
+
+	case SpreadShort:
+		if z <= s.ExitZScore || (s.StopZScore > 0 && z >= s.StopZScore) {
+			return SpreadFlat
+		}
+	}
+
+	return s.State.SpreadSide
+}
+
+func (s *Strategy) validateCointegration(ctx context.Context, now time.Time) {
+	cointegrated, stat := isCointegrated(s.xs, s.ys, s.CriticalValue)
+	changed := cointegrated != s.State.Cointegrated || s.State.CheckedAt.IsZero()
+	s.State.Cointegrated = cointegrated
+	s.State.CheckedAt = now
+
+	if changed {
+		bbgo.Notify("%s: cointegration test statistic %f (critical value %f), cointegrated: %t",
+			s.InstanceID(), stat, s.CriticalValue, cointegrated)
+	}
+
+	if !cointegrated && s.State.SpreadSide != SpreadFlat {
+		s.closeSpread(ctx, "cointegration is broken")
+	}
+}
+
+func (s *Strategy) openSpread(ctx context.Context, side SpreadSide, y, x fixedpoint.Value, beta float64) {
+	submitOrder, pairSubmitOrder, ok := s.spreadOrders(side, y, x, beta)
+	if !ok {
+		log.Warnf("the spread order quantity is too small, beta: %f", beta)
+		return
+	}
+
+	if _, err := s.orderExecutor.SubmitOrders(ctx, submitOrder); err != nil {
+		log.WithError(err).Errorf("unable to submit the %s order", s.Symbol)
+		return
+	}
+
+	if _, err := s.pairOrderExecutor.SubmitOrders(ctx, pairSubmitOrder); err != nil {
+		log.WithError(err).Errorf("unable to submit the %s order, closing the %s leg", s.PairSymbol, s.Symbol)
+		if err := s.orderExecutor.ClosePosition(ctx, fixedpoint.One); err != nil {
+			log.WithError(err).Errorf("unable to close the %s leg", s.Symbol)
+		}
+		return
+	}
+
+	s.State.SpreadSide = side
+	bbgo.Notify("%s: opened %s spread, %s %s %s, %s %s %s", s.InstanceID(), side,
+		submitOrder.Side, submitOrder.Quantity.String(), s.Symbol,
+		pairSubmitOrder.Side, pairSubmitOrder.Quantity.String(), s.PairSymbol)
+	bbgo.Sync(ctx, s)
+}
+
+// spreadOrders creates the orders of both legs, the notional of the pair leg is the notional of the symbol leg times the hedge ratio
+func (s *Strategy) spreadOrders(side SpreadSide, y, x fixedpoint.Value, beta float64) (types.SubmitOrder, types.SubmitOrder, bool) {
+	sideY := types.SideTypeBuy
+	if side == SpreadShort {
+		sideY = types.SideTypeSell
+	}
+
+	// a positive hedge ratio hedges with the opposite side
+	sideX := sideY.Reverse()
+	if beta < 0 {
+		sideX = sideY
+	}
+
+	quantityY := s.Market.TruncateQuantity(s.QuoteQuantity.Div(y))
+	quantityX := s.pairMarket.TruncateQuantity(s.QuoteQuantity.Mul(fixedpoint.NewFromFloat(math.Abs(beta))).Div(x))
+	if s.Market.IsDustQuantity(quantityY, y) || s.pairMarket.IsDustQuantity(quantityX, x) {
+		return types.SubmitOrder{}, types.SubmitOrder{}, false
+	}
+
+	return types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Side:     sideY,
+		Type:     types.OrderTypeMarket,
+		Quantity: quantityY,
+		Market:   s.Market,
+		Tag:      "pairsEntry",
+	}, types.SubmitOrder{
+		Symbol:   s.PairSymbol,
+		Side:     sideX,
+		Type:     types.OrderTypeMarket,
+		Quantity: quantityX,
+		Market:   s.pairMarket,
+		Tag:      "pairsEntry",
+	}, true
+}
+
+func (s *Strategy) closeSpread(ctx context.Context, reason string) {
+	bbgo.Notify("%s: closing %s spread, %s", s.InstanceID(), s.State.SpreadSide, reason)
+
+	if err := s.orderExecutor.GracefulCancel(ctx); err != nil {
+		log.WithError(err).Errorf("unable to cancel the %s orders", s.Symbol)
+	}
+
+	if err := s.pairOrderExecutor.GracefulCancel(ctx); err != nil {
+		log.WithError(err).Errorf("unable to cancel the %s orders", s.PairSymbol)
+	}
+
+	if err := s.orderExecutor.ClosePosition(ctx, fixedpoint.One, "pairsExit"); err != nil {
+		log.WithError(err).Errorf("unable to close the %s leg", s.Symbol)
+		return
+	}
+
+	if err := s.pairOrderExecutor.ClosePosition(ctx, fixedpoint.One, "pairsExit"); err != nil {
+		log.WithError(err).Errorf("unable to close the %s leg", s.PairSymbol)
+		return
+	}
+
+	s.State.SpreadSide = SpreadFlat
+	bbgo.Sync(ctx, s)
+}
+
+func (side SpreadSide) String() string {
+	switch side {
+	case SpreadLong:
+		return "long"
+	case SpreadShort:
+		return "short"
+	}
+
+	return "flat"
+}
@@ -0,0 +1,158 @@
+package pairstrading
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func Test_ols(t *testing.T) {
+	x := []float64{1, 2, 3, 4, 5}
+	y := []float64{3, 5, 7, 9, 11}
+	alpha, beta := ols(x, y)
+	assert.InDelta(t, 1.0, alpha, 1e-9)
+	assert.InDelta(t, 2.0, beta, 1e-9)
+}
+
+func TestKalmanHedge_Update(t *testing.T) {
+	k := &KalmanHedge{}
+	k.Defaults()
+
+	var alpha, beta float64
+	for i := 0; i < 500; i++ {
+		x := 10.0 + math.Sin(float64(i)/10.0)
+		alpha, beta = k.Update(x, 0.5+1.5*x)
+	}
+
+	assert.InDelta(t, 1.5, beta, 0.05)
+	assert.InDelta(t, 0.5, alpha, 0.5)
+}
+
+func Test_zScore(t *testing.T) {
+	assert.Equal(t, 0.0, zScore([]float64{1, 1, 1}))
+	assert.InDelta(t, 1.0, zScore([]float64{-1, 0, 1}), 1e-9)
+}
+
+func Test_isCointegrated(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+
+	var x, y, walk []float64
+	price := 10.0
+	other := 10.0
+	for i := 0; i < 200; i++ {
+		price += rnd.NormFloat64() * 0.1
+		other += rnd.NormFloat64() * 0.1
+		x = append(x, price)
+		y = append(y, 1.0+2.0*price+rnd.NormFloat64()*0.05)
+		walk = append(walk, other)
+	}
+
+	ok, stat := isCointegrated(x, y, defaultCriticalValue)
+	assert.True(t, ok, "statistic: %f", stat)
+
+	ok, stat = isCointegrated(x, walk, defaultCriticalValue)
+	assert.False(t, ok, "statistic: %f", stat)
+}
+
+func TestStrategy_decide(t *testing.T) {
+	s := &Strategy{EntryZScore: 2.0, ExitZScore: 0, StopZScore: 4.0, State: &State{}}
+
+	// no entry without the cointegration
+	assert.Equal(t, SpreadFlat, s.decide(2.5))
+
+	s.State.Cointegrated = true
+	assert.Equal(t, SpreadShort, s.decide(2.5))
+	assert.Equal(t, SpreadLong, s.decide(-2.5))
+	assert.Equal(t, SpreadFlat, s.decide(1.0))
+
+	s.State.SpreadSide = SpreadShort
+	assert.Equal(t, SpreadShort, s.decide(1.0))
+	assert.Equal(t, SpreadFlat, s.decide(-0.1))
+	assert.Equal(t, SpreadFlat, s.decide(4.5))
+
+	s.State.SpreadSide = SpreadLong
+	assert.Equal(t, SpreadLong, s.decide(-1.0))
+	assert.Equal(t, SpreadFlat, s.decide(0.1))
+	assert.Equal(t, SpreadFlat, s.decide(-4.5))
+}
+
+func TestStrategy_spreadOrders(t *testing.T) {
+	s := &Strategy{
+		Symbol:        "ETHUSDT",
+		PairSymbol:    "BTCUSDT",
+		QuoteQuantity: fixedpoint.NewFromFloat(1000),
+		Market: types.Market{
+			Symbol:          "ETHUSDT",
+			StepSize:        fixedpoint.NewFromFloat(0.001),
+			VolumePrecision: 3,
+			MinQuantity:     fixedpoint.NewFromFloat(0.001),
+			MinNotional:     fixedpoint.NewFromFloat(5),
+		},
+		pairMarket: types.Market{
+			Symbol:          "BTCUSDT",
+			StepSize:        fixedpoint.NewFromFloat(0.0001),
+			VolumePrecision: 4,
+			MinQuantity:     fixedpoint.NewFromFloat(0.0001),
+			MinNotional:     fixedpoint.NewFromFloat(5),
+		},
+	}
+
+	order, pairOrder, ok := s.spreadOrders(SpreadLong, fixedpoint.NewFromFloat(1000), fixedpoint.NewFromFloat(20000), 1.5)
+	if assert.True(t, ok) {
+		assert.Equal(t, types.SideTypeBuy, order.Side)
+		assert.Equal(t, "1", order.Quantity.String())
+		assert.Equal(t, types.SideTypeSell, pairOrder.Side)
+		assert.Equal(t, "0.075", pairOrder.Quantity.String())
+	}
+
+	// a negative hedge ratio hedges with the same side
+	order, pairOrder, ok = s.spreadOrders(SpreadShort, fixedpoint.NewFromFloat(1000), fixedpoint.NewFromFloat(20000), -0.5)
+	if assert.True(t, ok) {
+		assert.Equal(t, types.SideTypeSell, order.Side)
+		assert.Equal(t, types.SideTypeSell, pairOrder.Side)
+		assert.Equal(t, "0.025", pairOrder.Quantity.String())
+	}
+
+	_, _, ok = s.spreadOrders(SpreadShort, fixedpoint.NewFromFloat(1000), fixedpoint.NewFromFloat(20000), 0.00001)
+	assert.False(t, ok)
+}
+
+func TestStrategy_alignClose(t *testing.T) {
+	s := &Strategy{Symbol: "ETHUSDT", PairSymbol: "BTCUSDT"}
+	s.pendingCloses = map[string]map[time.Time]fixedpoint.Value{s.Symbol: {}, s.PairSymbol: {}}
+
+	t0 := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	newKLine := func(symbol string, startTime time.Time, price float64) types.KLine {
+		return types.KLine{Symbol: symbol, StartTime: types.Time(startTime), Close: fixedpoint.NewFromFloat(price)}
+	}
+
+	_, _, ok := s.alignClose(newKLine("ETHUSDT", t0, 1000))
+	assert.False(t, ok)
+
+	y, x, ok := s.alignClose(newKLine("BTCUSDT", t0, 20000))
+	if assert.True(t, ok) {
+		assert.Equal(t, "1000", y.String())
+		assert.Equal(t, "20000", x.String())
+	}
+
+	// the stale close is dropped when a newer pair is aligned
+	_, _, ok = s.alignClose(newKLine("BTCUSDT", t0.Add(time.Hour), 20100))
+	assert.False(t, ok)
+	_, _, ok = s.alignClose(newKLine("BTCUSDT", t0.Add(2*time.Hour), 20200))
+	assert.False(t, ok)
+
+	y, x, ok = s.alignClose(newKLine("ETHUSDT", t0.Add(2*time.Hour), 1010))
+	if assert.True(t, ok) {
+		assert.Equal(t, "1010", y.String())
+		assert.Equal(t, "20200", x.String())
+	}
+
+	assert.Empty(t, s.pendingCloses["BTCUSDT"])
+	assert.Empty(t, s.pendingCloses["ETHUSDT"])
+}
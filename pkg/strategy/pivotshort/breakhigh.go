@@ -0,0 +1,236 @@
+package pivotshort
+
+import (
+	"context"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// BreakHigh -- the long mirror of BreakLow, when price breaks the previous pivot high (the resistance), we set a long entry
+type BreakHigh struct {
+	Symbol string
+	Market types.Market
+	types.IntervalWindow
+
+	// FastWindow is used for fast pivot (this is to filter the nearest high/low)
+	FastWindow int `json:"fastWindow"`
+
+	// Ratio is a positive number, price * (1 + ratio) will be the price triggers the long order.
+	Ratio fixedpoint.Value `json:"ratio"`
+
+	bbgo.OpenPositionOptions
+
+	// PullbackRatio is a ratio used for placing the limit order buy price
+	// limit buy price = breakHighPrice * (1 - PullbackRatio)
+	PullbackRatio fixedpoint.Value `json:"pullbackRatio"`
+
+	FakeBreakStop *FakeBreakStop `json:"fakeBreakStop"`
+
+	// Confirmation filters the break high signals before opening the long position
+	Confirmation *BreakoutConfirmation `json:"confirmation"`
+
+	lastHigh, lastFastHigh fixedpoint.Value
+
+	lastHighInvalidated bool
+
+	// lastBreakHigh is the high that the price just break
+	lastBreakHigh fixedpoint.Value
+
+	pivotHigh, fastPivotHigh *indicator.PivotHigh
+	pivotHighPrices          []fixedpoint.Value
+
+	orderExecutor *bbgo.GeneralOrderExecutor
+	session       *bbgo.ExchangeSession
+
+	// StrategyController
+	bbgo.StrategyController
+}
+
+func (s *BreakHigh) Subscribe(session *bbgo.ExchangeSession) {
+	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.Interval})
+	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: types.Interval1m})
+
+	if s.FakeBreakStop != nil {
+		session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.FakeBreakStop.Interval})
+	}
+}
+
+func (s *BreakHigh) Bind(session *bbgo.ExchangeSession, orderExecutor *bbgo.GeneralOrderExecutor) {
+	if s.FastWindow == 0 {
+		s.FastWindow = 3
+	}
+
+	s.session = session
+	s.orderExecutor = orderExecutor
+
+	// StrategyController
+	s.Status = types.StrategyStatusRunning
+
+	position := orderExecutor.Position()
+	symbol := position.Symbol
+	standardIndicator := session.StandardIndicatorSet(s.Symbol)
+
+	s.lastHigh = fixedpoint.Zero
+	s.pivotHigh = standardIndicator.PivotHigh(s.IntervalWindow)
+	s.fastPivotHigh = standardIndicator.PivotHigh(types.IntervalWindow{
+		Interval: s.Interval,
+		Window:   s.FastWindow, // make it faster
+	})
+
+	// update pivot high data
+	session.MarketDataStream.OnStart(func() {
+		if s.updatePivotHigh() {
+			bbgo.Notify("%s new pivot high: %f", s.Symbol, s.pivotHigh.Last(0))
+		}
+	})
+
+	session.MarketDataStream.OnKLineClosed(types.KLineWith(symbol, s.Interval, func(kline types.KLine) {
+		if s.updatePivotHigh() {
+			// when position is opened, do not send pivot high notify
+			if position.IsOpened(kline.Close) {
+				return
+			}
+
+			bbgo.Notify("%s new pivot high: %f", s.Symbol, s.pivotHigh.Last(0))
+		}
+	}))
+
+	if s.FakeBreakStop != nil {
+		// if the position is already opened, and we just break the high, this checks if the kline closed below the high,
+		// so that we can close the position earlier
+		session.MarketDataStream.OnKLineClosed(types.KLineWith(s.Symbol, s.FakeBreakStop.Interval, func(k types.KLine) {
+			// make sure the position is opened, and it's a long position
+			if !position.IsOpened(k.Close) || !position.IsLong() {
+				return
+			}
+
+			// make sure we recorded the last break high
+			if s.lastBreakHigh.IsZero() {
+				return
+			}
+
+			// the kline opened above the last break high, and closed below the last break high
+			if k.Open.Compare(s.lastBreakHigh) > 0 && k.Close.Compare(s.lastBreakHigh) < 0 {
+				bbgo.Notify("kLine closed below the last break high, triggering stop earlier")
+				if err := s.orderExecutor.ClosePosition(context.Background(), one, "fakeBreakStop"); err != nil {
+					log.WithError(err).Error("position close error")
+				}
+
+				// reset to zero
+				s.lastBreakHigh = fixedpoint.Zero
+			}
+		}))
+	}
+
+	session.MarketDataStream.OnKLineClosed(types.KLineWith(s.Symbol, types.Interval1m, func(kline types.KLine) {
+		if s.Confirmation != nil {
+			defer s.Confirmation.Update(kline)
+		}
+
+		if len(s.pivotHighPrices) == 0 || s.lastHigh.IsZero() {
+			log.Infof("currently there is no pivot high prices, can not check break high...")
+			return
+		}
+
+		if s.lastHighInvalidated {
+			log.Infof("the last high is invalidated, skip")
+			return
+		}
+
+		previousHigh := s.lastHigh
+		breakPrice := previousHigh.Mul(fixedpoint.One.Add(s.Ratio))
+
+		// StrategyController
+		if s.Status != types.StrategyStatusRunning {
+			return
+		}
+
+		openPrice := kline.Open
+		closePrice := kline.Close
+
+		// if the previous high is not break, or the kline is not strong enough to break it, skip
+		if closePrice.Compare(breakPrice) <= 0 {
+			return
+		}
+
+		// we need the price cross the break line, or we do nothing:
+		// 1) open < break price < close price
+		// 2) low < break price < open price and close price
+		if !((openPrice.Compare(breakPrice) < 0 && closePrice.Compare(breakPrice) > 0) ||
+			(kline.Low.Compare(breakPrice) < 0 && openPrice.Compare(breakPrice) > 0 && closePrice.Compare(breakPrice) > 0)) {
+			return
+		}
+
+		// force direction to be up
+		if closePrice.Compare(openPrice) <= 0 {
+			bbgo.Notify("%s price %f is closed lower than the open price %f, skip this break", kline.Symbol, closePrice.Float64(), openPrice.Float64())
+			// skip DOWN klines
+			return
+		}
+
+		if s.Confirmation != nil {
+			if ok, reason := s.Confirmation.Confirm(kline, previousHigh, types.SideTypeBuy); !ok {
+				bbgo.Notify("%s breakHigh signal is not confirmed: %s", kline.Symbol, reason)
+				return
+			}
+		}
+
+		bbgo.Notify("%s breakHigh signal detected, closed price %f > breakPrice %f", kline.Symbol, closePrice.Float64(), breakPrice.Float64(), kline)
+
+		if s.lastBreakHigh.IsZero() || previousHigh.Compare(s.lastBreakHigh) > 0 {
+			s.lastBreakHigh = previousHigh
+		}
+
+		if position.IsOpened(kline.Close) {
+			bbgo.Notify("%s position is already opened, skip", s.Symbol)
+			return
+		}
+
+		ctx := context.Background()
+
+		// graceful cancel all active orders
+		_ = orderExecutor.GracefulCancel(ctx)
+
+		bbgo.Notify("%s price %f breaks the previous high %f with ratio %f, opening long position", symbol, kline.Close.Float64(), previousHigh.Float64(), s.Ratio.Float64())
+		opts := s.OpenPositionOptions
+		opts.Long = true
+		opts.Price = closePrice
+		opts.Tags = []string{"breakHighMarket"}
+		if opts.LimitOrder && !s.PullbackRatio.IsZero() {
+			opts.Price = previousHigh.Mul(fixedpoint.One.Sub(s.PullbackRatio))
+		}
+
+		if _, err := s.orderExecutor.OpenPosition(ctx, opts); err != nil {
+			log.WithError(err).Errorf("failed to open long position")
+		}
+	}))
+}
+
+func (s *BreakHigh) updatePivotHigh() bool {
+	high := fixedpoint.NewFromFloat(s.pivotHigh.Last(0))
+	if high.IsZero() {
+		return false
+	}
+
+	// if the last high is different
+	lastHighChanged := high.Compare(s.lastHigh) != 0
+	if lastHighChanged {
+		s.lastHigh = high
+		s.lastHighInvalidated = false
+		s.pivotHighPrices = append(s.pivotHighPrices, high)
+	}
+
+	fastHigh := fixedpoint.NewFromFloat(s.fastPivotHigh.Last(0))
+	if !fastHigh.IsZero() {
+		if fastHigh.Compare(s.lastHigh) > 0 {
+			s.lastHighInvalidated = true
+			lastHighChanged = false
+		}
+		s.lastFastHigh = fastHigh
+	}
+
+	return lastHighChanged
+}
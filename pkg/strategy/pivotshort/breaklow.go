@@ -37,6 +37,9 @@ type BreakLow struct {
 
 	FakeBreakStop *FakeBreakStop `json:"fakeBreakStop"`
 
+	// Confirmation filters the break low signals before opening the short position
+	Confirmation *BreakoutConfirmation `json:"confirmation"`
+
 	lastLow, lastFastLow fixedpoint.Value
 
 	lastLowInvalidated bool
@@ -149,6 +152,10 @@ func (s *BreakLow) Bind(session *bbgo.ExchangeSession, orderExecutor *bbgo.Gener
 	}
 
 	session.MarketDataStream.OnKLineClosed(types.KLineWith(s.Symbol, types.Interval1m, func(kline types.KLine) {
+		if s.Confirmation != nil {
+			defer s.Confirmation.Update(kline)
+		}
+
 		if len(s.pivotLowPrices) == 0 || s.lastLow.IsZero() {
 			log.Infof("currently there is no pivot low prices, can not check break low...")
 			return
@@ -192,6 +199,13 @@ func (s *BreakLow) Bind(session *bbgo.ExchangeSession, orderExecutor *bbgo.Gener
 			return
 		}
 
+		if s.Confirmation != nil {
+			if ok, reason := s.Confirmation.Confirm(kline, previousLow, types.SideTypeSell); !ok {
+				bbgo.Notify("%s breakLow signal is not confirmed: %s", kline.Symbol, reason)
+				return
+			}
+		}
+
 		bbgo.Notify("%s breakLow signal detected, closed price %f < breakPrice %f", kline.Symbol, closePrice.Float64(), breakPrice.Float64(), kline)
 
 		if s.lastBreakLow.IsZero() || previousLow.Compare(s.lastBreakLow) < 0 {
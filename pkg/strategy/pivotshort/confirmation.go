@@ -0,0 +1,87 @@
+package pivotshort
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultConfirmationVolumeWindow = 20
+
+// BreakoutConfirmation filters the break signals before the entries, all the configured conditions must be met.
+//
+//	confirmation:
+//	  closeBeyondRatio: 0.2%
+//	  volumeSpikeRatio: 2.0
+//	  volumeWindow: 20
+type BreakoutConfirmation struct {
+	// CloseBeyondRatio requires the kline to close beyond the level by the ratio, e.g., 0.2%
+	CloseBeyondRatio fixedpoint.Value `json:"closeBeyondRatio"`
+
+	// VolumeSpikeRatio requires the volume of the break kline to be greater than the average volume of the previous klines times the ratio,
+	// the volume confirmation is disabled if it's zero
+	VolumeSpikeRatio fixedpoint.Value `json:"volumeSpikeRatio"`
+
+	// VolumeWindow is the number of the previous klines of the average volume, defaults to 20
+	VolumeWindow int `json:"volumeWindow"`
+
+	volumes []fixedpoint.Value
+}
+
+func (c *BreakoutConfirmation) volumeWindow() int {
+	if c.VolumeWindow > 0 {
+		return c.VolumeWindow
+	}
+
+	return defaultConfirmationVolumeWindow
+}
+
+// Update records the volume of the closed kline, it should be called after the kline is checked
+func (c *BreakoutConfirmation) Update(kline types.KLine) {
+	c.volumes = append(c.volumes, kline.Volume)
+	if window := c.volumeWindow(); len(c.volumes) > window {
+		c.volumes = c.volumes[len(c.volumes)-window:]
+	}
+}
+
+// Confirm checks the kline breaking the level, the side is sell for a break below the level and buy for a break above.
+// It returns the reason if the break is not confirmed.
+func (c *BreakoutConfirmation) Confirm(kline types.KLine, level fixedpoint.Value, side types.SideType) (bool, string) {
+	if c.CloseBeyondRatio.Sign() > 0 {
+		switch side {
+		case types.SideTypeSell:
+			confirmPrice := level.Mul(fixedpoint.One.Sub(c.CloseBeyondRatio))
+			if kline.Close.Compare(confirmPrice) > 0 {
+				return false, fmt.Sprintf("close price %f is not below %f (%s beyond the level %f)",
+					kline.Close.Float64(), confirmPrice.Float64(), c.CloseBeyondRatio.Percentage(), level.Float64())
+			}
+
+		case types.SideTypeBuy:
+			confirmPrice := level.Mul(fixedpoint.One.Add(c.CloseBeyondRatio))
+			if kline.Close.Compare(confirmPrice) < 0 {
+				return false, fmt.Sprintf("close price %f is not above %f (%s beyond the level %f)",
+					kline.Close.Float64(), confirmPrice.Float64(), c.CloseBeyondRatio.Percentage(), level.Float64())
+			}
+		}
+	}
+
+	if c.VolumeSpikeRatio.Sign() > 0 {
+		if len(c.volumes) < c.volumeWindow() {
+			return false, fmt.Sprintf("not enough volume data, %d < %d klines", len(c.volumes), c.volumeWindow())
+		}
+
+		sum := fixedpoint.Zero
+		for _, v := range c.volumes {
+			sum = sum.Add(v)
+		}
+
+		requiredVolume := sum.Div(fixedpoint.NewFromInt(int64(len(c.volumes)))).Mul(c.VolumeSpikeRatio)
+		if kline.Volume.Compare(requiredVolume) < 0 {
+			return false, fmt.Sprintf("volume %f is less than %f (average volume x %f)",
+				kline.Volume.Float64(), requiredVolume.Float64(), c.VolumeSpikeRatio.Float64())
+		}
+	}
+
+	return true, ""
+}
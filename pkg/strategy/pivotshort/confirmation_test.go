@@ -0,0 +1,50 @@
+package pivotshort
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestBreakoutConfirmation_CloseBeyondRatio(t *testing.T) {
+	c := &BreakoutConfirmation{CloseBeyondRatio: fixedpoint.NewFromFloat(0.01)}
+	level := fixedpoint.NewFromInt(100)
+
+	ok, _ := c.Confirm(types.KLine{Close: fixedpoint.NewFromFloat(99.5)}, level, types.SideTypeSell)
+	assert.False(t, ok)
+
+	ok, _ = c.Confirm(types.KLine{Close: fixedpoint.NewFromInt(98)}, level, types.SideTypeSell)
+	assert.True(t, ok)
+
+	ok, _ = c.Confirm(types.KLine{Close: fixedpoint.NewFromFloat(100.5)}, level, types.SideTypeBuy)
+	assert.False(t, ok)
+
+	ok, _ = c.Confirm(types.KLine{Close: fixedpoint.NewFromInt(102)}, level, types.SideTypeBuy)
+	assert.True(t, ok)
+}
+
+func TestBreakoutConfirmation_VolumeSpike(t *testing.T) {
+	c := &BreakoutConfirmation{VolumeSpikeRatio: fixedpoint.NewFromInt(2), VolumeWindow: 3}
+	level := fixedpoint.NewFromInt(100)
+	kline := types.KLine{Close: fixedpoint.NewFromInt(98), Volume: fixedpoint.NewFromInt(25)}
+
+	// not enough volume data
+	ok, _ := c.Confirm(kline, level, types.SideTypeSell)
+	assert.False(t, ok)
+
+	for _, v := range []int64{100, 10, 10, 10} {
+		c.Update(types.KLine{Volume: fixedpoint.NewFromInt(v)})
+	}
+
+	// the average volume of the last 3 klines is 10
+	ok, _ = c.Confirm(kline, level, types.SideTypeSell)
+	assert.True(t, ok)
+
+	kline.Volume = fixedpoint.NewFromInt(15)
+	ok, reason := c.Confirm(kline, level, types.SideTypeSell)
+	assert.False(t, ok)
+	assert.NotEmpty(t, reason)
+}
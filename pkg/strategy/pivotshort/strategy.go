@@ -45,6 +45,9 @@ type Strategy struct {
 	BreakLow        *BreakLow        `json:"breakLow"`
 	FailedBreakHigh *FailedBreakHigh `json:"failedBreakHigh"`
 
+	// BreakHigh is the long mirror mode of BreakLow, it opens the long position when the price breaks the previous pivot high
+	BreakHigh *BreakHigh `json:"breakHigh"`
+
 	// ResistanceShort is one of the entry method
 	ResistanceShort *ResistanceShort `json:"resistanceShort"`
 
@@ -84,6 +87,11 @@ func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
 		s.FailedBreakHigh.Subscribe(session)
 	}
 
+	if s.BreakHigh != nil {
+		dynamic.InheritStructValues(s.BreakHigh, s)
+		s.BreakHigh.Subscribe(session)
+	}
+
 	if !bbgo.IsBackTesting {
 		session.Subscribe(types.MarketTradeChannel, s.Symbol, types.SubscribeOptions{})
 	}
@@ -137,6 +145,10 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		if s.FailedBreakHigh != nil {
 			s.FailedBreakHigh.Suspend()
 		}
+
+		if s.BreakHigh != nil {
+			s.BreakHigh.Suspend()
+		}
 	})
 
 	s.OnEmergencyStop(func() {
@@ -156,6 +168,10 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		if s.FailedBreakHigh != nil {
 			s.FailedBreakHigh.EmergencyStop()
 		}
+
+		if s.BreakHigh != nil {
+			s.BreakHigh.EmergencyStop()
+		}
 	})
 
 	// initial required information
@@ -183,6 +199,10 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		s.FailedBreakHigh.Bind(session, s.orderExecutor)
 	}
 
+	if s.BreakHigh != nil {
+		s.BreakHigh.Bind(session, s.orderExecutor)
+	}
+
 	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
 		defer wg.Done()
 
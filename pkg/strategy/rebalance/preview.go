@@ -0,0 +1,189 @@
+package rebalance
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// PreviewItem is the weight and the rebalance order of one currency
+type PreviewItem struct {
+	Currency      string           `json:"currency"`
+	Price         fixedpoint.Value `json:"price"`
+	Balance       fixedpoint.Value `json:"balance"`
+	MarketValue   fixedpoint.Value `json:"marketValue"`
+	CurrentWeight fixedpoint.Value `json:"currentWeight"`
+	TargetWeight  fixedpoint.Value `json:"targetWeight"`
+
+	// Order is nil if the currency is not rebalanced, the reason is given by SkipReason
+	Order      *types.SubmitOrder `json:"order,omitempty"`
+	SkipReason string             `json:"skipReason,omitempty"`
+}
+
+// Preview is the rebalance plan of the portfolio, it's reported instead of submitting the orders in the dry run mode
+type Preview struct {
+	QuoteCurrency string           `json:"quoteCurrency"`
+	TotalValue    fixedpoint.Value `json:"totalValue"`
+	Items         []PreviewItem    `json:"items"`
+	Time          time.Time        `json:"time"`
+}
+
+// Orders returns the rebalance orders of the preview
+func (p *Preview) Orders() (orders []types.SubmitOrder) {
+	for _, item := range p.Items {
+		if item.Order != nil {
+			orders = append(orders, *item.Order)
+		}
+	}
+
+	return orders
+}
+
+func (p *Preview) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "rebalance preview, total value %s %s\n", p.TotalValue.String(), p.QuoteCurrency)
+
+	w := tabwriter.NewWriter(&buf, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CURRENCY\tPRICE\tBALANCE\tWEIGHT\tTARGET\tORDER")
+	for _, item := range p.Items {
+		order := item.SkipReason
+		if item.Order != nil {
+			order = fmt.Sprintf("%s %s %s", item.Order.Side, item.Order.Quantity.String(), item.Order.Symbol)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			item.Currency,
+			item.Price.String(),
+			item.Balance.String(),
+			item.CurrentWeight.Percentage(),
+			item.TargetWeight.Percentage(),
+			order)
+	}
+	_ = w.Flush()
+
+	return buf.String()
+}
+
+func (p *Preview) SlackAttachment() slack.Attachment {
+	var fields []slack.AttachmentField
+	for _, item := range p.Items {
+		value := fmt.Sprintf("%s → %s", item.CurrentWeight.Percentage(), item.TargetWeight.Percentage())
+		if item.Order != nil {
+			value += fmt.Sprintf(", %s %s", item.Order.Side, item.Order.Quantity.String())
+		} else if item.SkipReason != "" {
+			value += ", " + item.SkipReason
+		}
+
+		fields = append(fields, slack.AttachmentField{Title: item.Currency, Value: value, Short: true})
+	}
+
+	return slack.Attachment{
+		Title:  fmt.Sprintf("Rebalance Preview (%s %s)", p.TotalValue.FormatString(2), p.QuoteCurrency),
+		Fields: fields,
+		Footer: p.Time.Format(time.RFC822),
+	}
+}
+
+// newPreview calculates the weights of the balances and the orders to rebalance the portfolio to the target weights,
+// the currencies with the weight difference less than the threshold are not rebalanced.
+func (s *Strategy) newPreview(prices types.ValueMap, balances types.BalanceMap, markets map[string]types.Market, threshold fixedpoint.Value, now time.Time) *Preview {
+	marketValues := prices.Mul(balanceToTotal(balances))
+	currentWeights := marketValues.Normalize()
+	totalValue := marketValues.Sum()
+
+	preview := &Preview{
+		QuoteCurrency: s.QuoteCurrency,
+		TotalValue:    totalValue,
+		Time:          now,
+	}
+
+	for currency, targetWeight := range s.TargetWeights {
+		item := PreviewItem{
+			Currency:      currency,
+			Price:         prices[currency],
+			Balance:       balances[currency].Total(),
+			MarketValue:   marketValues[currency],
+			CurrentWeight: currentWeights[currency],
+			TargetWeight:  targetWeight,
+		}
+
+		if currency != s.QuoteCurrency {
+			item.Order, item.SkipReason = s.rebalanceOrder(item, totalValue, balances, markets, threshold)
+		}
+
+		preview.Items = append(preview.Items, item)
+	}
+
+	sort.Slice(preview.Items, func(i, j int) bool {
+		return preview.Items[i].Currency < preview.Items[j].Currency
+	})
+
+	return preview
+}
+
+func (s *Strategy) rebalanceOrder(item PreviewItem, totalValue fixedpoint.Value, balances types.BalanceMap, markets map[string]types.Market, threshold fixedpoint.Value) (*types.SubmitOrder, string) {
+	symbol := item.Currency + s.QuoteCurrency
+	market, ok := markets[symbol]
+	if !ok {
+		return nil, fmt.Sprintf("market %s not found", symbol)
+	}
+
+	// calculate the difference between current weight and target weight
+	// if the difference is less than threshold, then we will not create the order
+	weightDifference := item.TargetWeight.Sub(item.CurrentWeight)
+	if weightDifference.Abs().Compare(threshold) < 0 {
+		log.Infof("%s weight distance |%v - %v| = |%v| less than the threshold: %v",
+			symbol,
+			item.CurrentWeight,
+			item.TargetWeight,
+			weightDifference,
+			threshold)
+		return nil, "within threshold"
+	}
+
+	if item.Price.IsZero() {
+		return nil, "no price"
+	}
+
+	quantity := weightDifference.Mul(totalValue).Div(item.Price)
+
+	side := types.SideTypeBuy
+	if quantity.Sign() < 0 {
+		side = types.SideTypeSell
+		quantity = quantity.Abs()
+	}
+
+	maxAmount := s.adjustMaxAmountByBalance(side, item.Currency, item.Price, balances)
+	if maxAmount.Sign() > 0 {
+		quantity = bbgo.AdjustQuantityByMaxAmount(quantity, item.Price, maxAmount)
+		log.Infof("adjust the quantity %v (%s %s @ %v) by max amount %v",
+			quantity,
+			symbol,
+			side.String(),
+			item.Price,
+			maxAmount)
+	}
+
+	order := types.SubmitOrder{
+		Symbol:   symbol,
+		Side:     side,
+		Type:     s.OrderType,
+		Quantity: market.TruncateQuantity(quantity),
+		Price:    market.TruncatePrice(item.Price),
+		Market:   market,
+	}
+
+	if ok := s.checkMinimalOrderQuantity(order); !ok {
+		return nil, "below the minimal order size"
+	}
+
+	return &order, ""
+}
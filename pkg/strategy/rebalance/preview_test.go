@@ -0,0 +1,81 @@
+package rebalance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestStrategy_newPreview(t *testing.T) {
+	s := &Strategy{
+		QuoteCurrency: "USDT",
+		TargetWeights: types.ValueMap{
+			"BTC":  fixedpoint.MustNewFromString("0.5"),
+			"ETH":  fixedpoint.MustNewFromString("0.25"),
+			"USDT": fixedpoint.MustNewFromString("0.25"),
+		},
+		OrderType: types.OrderTypeLimitMaker,
+	}
+
+	markets := map[string]types.Market{
+		"BTCUSDT": {
+			Symbol: "BTCUSDT", BaseCurrency: "BTC", QuoteCurrency: "USDT",
+			StepSize: fixedpoint.MustNewFromString("0.0001"), TickSize: fixedpoint.MustNewFromString("0.01"),
+			MinQuantity: fixedpoint.MustNewFromString("0.0001"), MinNotional: fixedpoint.NewFromInt(10),
+		},
+		"ETHUSDT": {
+			Symbol: "ETHUSDT", BaseCurrency: "ETH", QuoteCurrency: "USDT",
+			StepSize: fixedpoint.MustNewFromString("0.001"), TickSize: fixedpoint.MustNewFromString("0.01"),
+			MinQuantity: fixedpoint.MustNewFromString("0.001"), MinNotional: fixedpoint.NewFromInt(10),
+		},
+	}
+
+	prices := types.ValueMap{
+		"BTC":  fixedpoint.NewFromInt(20000),
+		"ETH":  fixedpoint.NewFromInt(1000),
+		"USDT": fixedpoint.One,
+	}
+
+	// BTC 4000 (40%), ETH 2550 (25.5%), USDT 3450 (34.5%)
+	balances := types.BalanceMap{
+		"BTC":  {Currency: "BTC", Available: fixedpoint.MustNewFromString("0.2")},
+		"ETH":  {Currency: "ETH", Available: fixedpoint.MustNewFromString("2.55")},
+		"USDT": {Currency: "USDT", Available: fixedpoint.NewFromInt(3450)},
+	}
+
+	t.Run("threshold", func(t *testing.T) {
+		preview := s.newPreview(prices, balances, markets, fixedpoint.MustNewFromString("0.01"), time.Now())
+		assert.Equal(t, "10000", preview.TotalValue.String())
+		if assert.Len(t, preview.Items, 3) {
+			assert.Equal(t, "BTC", preview.Items[0].Currency)
+			assert.Equal(t, "0.4", preview.Items[0].CurrentWeight.String())
+			if assert.NotNil(t, preview.Items[0].Order) {
+				assert.Equal(t, types.SideTypeBuy, preview.Items[0].Order.Side)
+				assert.Equal(t, "0.05", preview.Items[0].Order.Quantity.String())
+			}
+
+			assert.Equal(t, "ETH", preview.Items[1].Currency)
+			assert.Nil(t, preview.Items[1].Order)
+			assert.Equal(t, "within threshold", preview.Items[1].SkipReason)
+
+			assert.Equal(t, "USDT", preview.Items[2].Currency)
+			assert.Nil(t, preview.Items[2].Order)
+		}
+
+		assert.Len(t, preview.Orders(), 1)
+	})
+
+	t.Run("no threshold", func(t *testing.T) {
+		preview := s.newPreview(prices, balances, markets, fixedpoint.Zero, time.Now())
+		orders := preview.Orders()
+		if assert.Len(t, orders, 2) {
+			assert.Equal(t, "ETHUSDT", orders[1].Symbol)
+			assert.Equal(t, types.SideTypeSell, orders[1].Side)
+			assert.Equal(t, "0.05", orders[1].Quantity.String())
+		}
+	})
+}
@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/sirupsen/logrus"
 
 	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/dynamic"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/types"
 )
@@ -27,6 +29,7 @@ func instanceID(symbol string) string {
 type Strategy struct {
 	Environment *bbgo.Environment
 
+	// Interval checks the weights on the kline close, the portfolio is rebalanced if any weight difference exceeds the threshold
 	Interval      types.Interval   `json:"interval"`
 	QuoteCurrency string           `json:"quoteCurrency"`
 	TargetWeights types.ValueMap   `json:"targetWeights"`
@@ -36,12 +39,23 @@ type Strategy struct {
 	DryRun        bool             `json:"dryRun"`
 	OnStart       bool             `json:"onStart"` // rebalance on start
 
+	// Schedule rebalances the portfolio on the cron spec regardless of the threshold, e.g., "0 0 * * 1" to rebalance weekly,
+	// the spec runs in the time zone of the environment scheduler.
+	Schedule string `json:"schedule,omitempty"`
+
+	// TWAP executes the rebalance orders with the twap engine instead of submitting the orders of OrderType
+	TWAP *TwapConfig `json:"twap,omitempty"`
+
 	PositionMap    PositionMap    `persistence:"positionMap"`
 	ProfitStatsMap ProfitStatsMap `persistence:"profitStatsMap"`
 
 	session          *bbgo.ExchangeSession
 	orderExecutorMap GeneralOrderExecutorMap
 	activeOrderBook  *bbgo.ActiveOrderBook
+	twapExecutor     *twapExecutor
+
+	// rebalanceMutex prevents the scheduled rebalance from running with the kline triggered rebalance
+	rebalanceMutex sync.Mutex
 }
 
 func (s *Strategy) Defaults() error {
@@ -81,10 +95,24 @@ func (s *Strategy) Validate() error {
 	if s.MaxAmount.Sign() < 0 {
 		return fmt.Errorf("maxAmount shoud not less than 0")
 	}
+
+	if s.Interval == "" && s.Schedule == "" {
+		return fmt.Errorf("either interval or schedule should be set")
+	}
+
+	if s.TWAP != nil {
+		if err := s.TWAP.Validate(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
+	if s.Interval == "" {
+		return
+	}
+
 	for _, symbol := range s.symbols() {
 		session.Subscribe(types.KLineChannel, symbol, types.SubscribeOptions{Interval: s.Interval})
 	}
@@ -117,42 +145,86 @@ func (s *Strategy) Run(ctx context.Context, _ bbgo.OrderExecutor, session *bbgo.
 	s.activeOrderBook = bbgo.NewActiveOrderBook("")
 	s.activeOrderBook.BindStream(s.session.UserDataStream)
 
+	if s.TWAP != nil {
+		s.twapExecutor = newTwapExecutor(s.TWAP, session)
+	}
+
 	session.UserDataStream.OnStart(func() {
 		if s.OnStart {
-			s.rebalance(ctx)
+			s.rebalance(ctx, s.Threshold)
 		}
 	})
 
-	s.session.MarketDataStream.OnKLineClosed(func(kline types.KLine) {
-		s.rebalance(ctx)
-	})
+	if s.Interval != "" {
+		s.session.MarketDataStream.OnKLineClosed(func(kline types.KLine) {
+			if kline.Interval == s.Interval {
+				s.rebalance(ctx, s.Threshold)
+			}
+		})
+	}
+
+	if s.Schedule != "" {
+		if err := s.Environment.Scheduler().Schedule(dynamic.CallID(s), "rebalance", s.Schedule, func(ctx context.Context) {
+			s.rebalance(ctx, fixedpoint.Zero)
+		}); err != nil {
+			return err
+		}
+	}
 
 	// the shutdown handler, you can cancel all orders
 	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
 		defer wg.Done()
+		if s.twapExecutor != nil {
+			s.twapExecutor.Shutdown(ctx)
+		}
 		_ = s.orderExecutorMap.GracefulCancel(ctx)
 	})
 
 	return nil
 }
 
-func (s *Strategy) rebalance(ctx context.Context) {
+// rebalance rebalances the currencies with the weight difference greater than or equal to the threshold
+func (s *Strategy) rebalance(ctx context.Context, threshold fixedpoint.Value) {
+	s.rebalanceMutex.Lock()
+	defer s.rebalanceMutex.Unlock()
+
+	if s.twapExecutor != nil {
+		if running := s.twapExecutor.Running(); len(running) > 0 {
+			log.Infof("the twap executions of %v are still running, skip rebalancing", running)
+			return
+		}
+	}
+
 	// cancel active orders before rebalance
 	if err := s.session.Exchange.CancelOrders(ctx, s.activeOrderBook.Orders()...); err != nil {
 		log.WithError(err).Errorf("failed to cancel orders")
 	}
 
-	submitOrders, err := s.generateSubmitOrders(ctx)
+	preview, err := s.preview(ctx, threshold)
 	if err != nil {
 		log.WithError(err).Error("failed to generate submit orders")
 		return
 	}
+
+	submitOrders := preview.Orders()
 	for _, order := range submitOrders {
 		log.Infof("generated submit order: %s", order.String())
 	}
 
 	if s.DryRun {
-		log.Infof("dry run, not submitting orders")
+		log.Infof("dry run, not submitting orders\n%s", preview.String())
+		bbgo.Notify(preview)
+		return
+	}
+
+	if len(submitOrders) == 0 {
+		return
+	}
+
+	if s.twapExecutor != nil {
+		if err := s.twapExecutor.Execute(ctx, submitOrders...); err != nil {
+			log.WithError(err).Error("failed to execute the twap orders")
+		}
 		return
 	}
 
@@ -195,81 +267,18 @@ func (s *Strategy) balances() (types.BalanceMap, error) {
 	return m, nil
 }
 
-func (s *Strategy) generateSubmitOrders(ctx context.Context) (submitOrders []types.SubmitOrder, err error) {
+func (s *Strategy) preview(ctx context.Context, threshold fixedpoint.Value) (*Preview, error) {
 	prices, err := s.prices(ctx)
 	if err != nil {
 		return nil, err
 	}
+
 	balances, err := s.balances()
 	if err != nil {
 		return nil, err
 	}
-	marketValues := prices.Mul(balanceToTotal(balances))
-	currentWeights := marketValues.Normalize()
-
-	for currency, targetWeight := range s.TargetWeights {
-		if currency == s.QuoteCurrency {
-			continue
-		}
-
-		symbol := currency + s.QuoteCurrency
-		currentWeight := currentWeights[currency]
-		currentPrice := prices[currency]
-
-		log.Infof("%s price: %v, current weight: %v, target weight: %v",
-			symbol,
-			currentPrice,
-			currentWeight,
-			targetWeight)
-
-		// calculate the difference between current weight and target weight
-		// if the difference is less than threshold, then we will not create the order
-		weightDifference := targetWeight.Sub(currentWeight)
-		if weightDifference.Abs().Compare(s.Threshold) < 0 {
-			log.Infof("%s weight distance |%v - %v| = |%v| less than the threshold: %v",
-				symbol,
-				currentWeight,
-				targetWeight,
-				weightDifference,
-				s.Threshold)
-			continue
-		}
-
-		quantity := weightDifference.Mul(marketValues.Sum()).Div(currentPrice)
-
-		side := types.SideTypeBuy
-		if quantity.Sign() < 0 {
-			side = types.SideTypeSell
-			quantity = quantity.Abs()
-		}
-
-		maxAmount := s.adjustMaxAmountByBalance(side, currency, currentPrice, balances)
-		if maxAmount.Sign() > 0 {
-			quantity = bbgo.AdjustQuantityByMaxAmount(quantity, currentPrice, maxAmount)
-			log.Infof("adjust the quantity %v (%s %s @ %v) by max amount %v",
-				quantity,
-				symbol,
-				side.String(),
-				currentPrice,
-				s.MaxAmount)
-		}
-
-		log.Debugf("symbol: %v, quantity: %v", symbol, quantity)
-
-		order := types.SubmitOrder{
-			Symbol:   symbol,
-			Side:     side,
-			Type:     s.OrderType,
-			Quantity: quantity,
-			Price:    currentPrice,
-		}
-
-		if ok := s.checkMinimalOrderQuantity(order); ok {
-			submitOrders = append(submitOrders, order)
-		}
-	}
 
-	return submitOrders, err
+	return s.newPreview(prices, balances, s.session.Markets(), threshold, time.Now()), nil
 }
 
 func (s *Strategy) symbols() (symbols []string) {
@@ -0,0 +1,134 @@
+package rebalance
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// TwapConfig executes the rebalance orders with the twap engine, the orders are sliced into the maker orders
+// on the best price, and the rest quantity is completed with a taker order after the deadline.
+type TwapConfig struct {
+	// SliceAmount is the quote amount of each slice order
+	SliceAmount fixedpoint.Value `json:"sliceAmount"`
+
+	// NumOfTicks is the number of the price ticks to improve the best price
+	NumOfTicks int `json:"numOfTicks"`
+
+	// UpdateInterval is the interval to re-place the slice order, defaults to 10s
+	UpdateInterval types.Duration `json:"updateInterval"`
+
+	// Deadline is the duration after that the rest quantity is completed with a taker order,
+	// the slices are only placed as the maker orders if it's zero.
+	Deadline types.Duration `json:"deadline"`
+}
+
+func (c *TwapConfig) Validate() error {
+	if c.SliceAmount.Sign() <= 0 {
+		return fmt.Errorf("twap.sliceAmount should be greater than 0")
+	}
+
+	if c.NumOfTicks < 0 {
+		return fmt.Errorf("twap.numOfTicks should not be less than 0")
+	}
+
+	return nil
+}
+
+// twapExecutor runs the twap executions of the rebalance orders, one execution per symbol
+type twapExecutor struct {
+	config  *TwapConfig
+	session *bbgo.ExchangeSession
+
+	mu         sync.Mutex
+	executions map[string]*bbgo.TwapExecution
+}
+
+func newTwapExecutor(config *TwapConfig, session *bbgo.ExchangeSession) *twapExecutor {
+	return &twapExecutor{
+		config:     config,
+		session:    session,
+		executions: make(map[string]*bbgo.TwapExecution),
+	}
+}
+
+// Running returns the symbols of the executions not done yet
+func (e *twapExecutor) Running() (symbols []string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for symbol, execution := range e.executions {
+		select {
+		case <-execution.Done():
+			delete(e.executions, symbol)
+		default:
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	return symbols
+}
+
+// Execute starts the twap executions of the orders, the order price is ignored
+func (e *twapExecutor) Execute(ctx context.Context, orders ...types.SubmitOrder) error {
+	var deadlineTime time.Time
+	if e.config.Deadline > 0 {
+		deadlineTime = time.Now().Add(e.config.Deadline.Duration())
+	}
+
+	for _, order := range orders {
+		price := order.Price
+		if price.IsZero() {
+			return fmt.Errorf("twap execution of %s requires the reference price to calculate the slice quantity", order.Symbol)
+		}
+
+		execution := &bbgo.TwapExecution{
+			Session:        e.session,
+			Symbol:         order.Symbol,
+			Side:           order.Side,
+			TargetQuantity: order.Quantity,
+			SliceQuantity:  order.Market.TruncateQuantity(e.config.SliceAmount.Div(price)),
+			NumOfTicks:     e.config.NumOfTicks,
+			UpdateInterval: e.config.UpdateInterval.Duration(),
+			DeadlineTime:   deadlineTime,
+		}
+
+		execution.OnSliceReport(func(report bbgo.TwapSliceReport) {
+			log.Infof("%s rebalance %s", order.Symbol, report.String())
+		})
+
+		if err := execution.Run(ctx); err != nil {
+			return err
+		}
+
+		e.mu.Lock()
+		e.executions[order.Symbol] = execution
+		e.mu.Unlock()
+
+		go func(execution *bbgo.TwapExecution) {
+			<-execution.Done()
+			log.Info(execution.Report().String())
+		}(execution)
+	}
+
+	return nil
+}
+
+// Shutdown stops the running executions and cancels their orders
+func (e *twapExecutor) Shutdown(ctx context.Context) {
+	e.mu.Lock()
+	executions := make([]*bbgo.TwapExecution, 0, len(e.executions))
+	for _, execution := range e.executions {
+		executions = append(executions, execution)
+	}
+	e.mu.Unlock()
+
+	for _, execution := range executions {
+		execution.Shutdown(ctx)
+	}
+}
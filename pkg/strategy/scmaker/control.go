@@ -0,0 +1,72 @@
+package scmaker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// GetStatus implements bbgo.StrategyStatusReader
+func (s *Strategy) GetStatus() types.StrategyStatus {
+	s.controlMutex.Lock()
+	defer s.controlMutex.Unlock()
+	return s.status
+}
+
+// ConfigSnapshot implements bbgo.StrategyConfigSnapshotter, the config is marshaled between the quote updates
+func (s *Strategy) ConfigSnapshot() ([]byte, error) {
+	s.controlMutex.Lock()
+	defer s.controlMutex.Unlock()
+	return json.Marshal(s)
+}
+
+// Suspend cancels all the orders and stops quoting until it's resumed
+func (s *Strategy) Suspend() error {
+	s.controlMutex.Lock()
+	defer s.controlMutex.Unlock()
+
+	s.status = types.StrategyStatusStopped
+	s.cancelOrders(s.tradingCtx)
+	bbgo.Sync(s.tradingCtx, s)
+	return nil
+}
+
+// Resume re-places the liquidity orders, the adjustment orders are placed on the next adjustment update
+func (s *Strategy) Resume() error {
+	s.controlMutex.Lock()
+	defer s.controlMutex.Unlock()
+
+	s.status = types.StrategyStatusRunning
+	s.placeLiquidityOrders(s.tradingCtx)
+	return nil
+}
+
+// EmergencyStop cancels all the orders and stops quoting
+func (s *Strategy) EmergencyStop() error {
+	return s.Suspend()
+}
+
+// Requote cancels and re-places the liquidity orders
+func (s *Strategy) Requote(ctx context.Context) error {
+	s.controlMutex.Lock()
+	defer s.controlMutex.Unlock()
+
+	if s.status != types.StrategyStatusRunning {
+		return fmt.Errorf("strategy %s is not running", s.InstanceID())
+	}
+
+	s.placeLiquidityOrders(ctx)
+	return nil
+}
+
+// cancelOrders must be called with the controlMutex held
+func (s *Strategy) cancelOrders(ctx context.Context) {
+	err := s.liquidityOrderBook.GracefulCancel(ctx, s.session.Exchange)
+	logErr(err, "unable to cancel liquidity orders")
+
+	err = s.adjustmentOrderBook.GracefulCancel(ctx, s.session.Exchange)
+	logErr(err, "unable to cancel adjustment orders")
+}
@@ -0,0 +1,54 @@
+package scmaker
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/types"
+	"github.com/c9s/bbgo/pkg/types/mocks"
+)
+
+func TestStrategy_SuspendAndRequote(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	mockExchange := mocks.NewMockExchange(mockCtrl)
+	mockExchange.EXPECT().CancelOrders(gomock.Any()).Return(nil).AnyTimes()
+
+	s := &Strategy{
+		Symbol:              "BTCUSDT",
+		session:             &bbgo.ExchangeSession{Exchange: mockExchange},
+		liquidityOrderBook:  bbgo.NewActiveOrderBook("BTCUSDT"),
+		adjustmentOrderBook: bbgo.NewActiveOrderBook("BTCUSDT"),
+		tradingCtx:          context.Background(),
+		status:              types.StrategyStatusRunning,
+	}
+
+	// the status is read and written by the api and the market data callbacks concurrently
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_ = s.Suspend()
+		}()
+		go func() {
+			defer wg.Done()
+			_ = s.GetStatus()
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, types.StrategyStatusStopped, s.GetStatus())
+	assert.Error(t, s.Requote(context.Background()))
+
+	out, err := s.ConfigSnapshot()
+	if assert.NoError(t, err) {
+		assert.Contains(t, string(out), `"symbol":"BTCUSDT"`)
+	}
+}
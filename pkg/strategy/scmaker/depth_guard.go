@@ -0,0 +1,114 @@
+package scmaker
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+var tenThousand = fixedpoint.NewFromInt(10_000)
+
+type DepthGuardAction string
+
+const (
+	// DepthGuardActionSkip skips the liquidity orders of the thin side
+	DepthGuardActionSkip DepthGuardAction = "skip"
+
+	// DepthGuardActionShrink scales the liquidity orders of the thin side by depth / minDepth
+	DepthGuardActionShrink DepthGuardAction = "shrink"
+)
+
+// DepthGuardConfig checks the displayed depth of the order book around the mid price before quoting,
+// so that the strategy is not the only liquidity of the book, e.g., during the news events.
+// The depth of each side is the quote value of the price levels within rangeBps of the mid price,
+// the own liquidity orders are excluded.
+//
+//	depthGuard:
+//	  rangeBps: 20
+//	  minDepth: 50_000
+//	  action: shrink
+type DepthGuardConfig struct {
+	RangeBps fixedpoint.Value `json:"rangeBps"`
+
+	// MinDepth is the minimal depth of each side in the quote currency
+	MinDepth fixedpoint.Value `json:"minDepth"`
+
+	// Action is skip or shrink, defaults to skip
+	Action DepthGuardAction `json:"action,omitempty"`
+}
+
+func (c *DepthGuardConfig) Validate() error {
+	if c.RangeBps.Sign() <= 0 {
+		return errors.New("depthGuard: rangeBps should be greater than zero")
+	}
+
+	if c.MinDepth.Sign() <= 0 {
+		return errors.New("depthGuard: minDepth should be greater than zero")
+	}
+
+	switch c.Action {
+	case "":
+		c.Action = DepthGuardActionSkip
+	case DepthGuardActionSkip, DepthGuardActionShrink:
+	default:
+		return fmt.Errorf("depthGuard: invalid action %q, expecting skip or shrink", c.Action)
+	}
+
+	return nil
+}
+
+// scales returns the size scales of the bid and the ask liquidity orders, the side is skipped when the scale is zero.
+// Both sides are skipped if the book has no valid mid price.
+func (c *DepthGuardConfig) scales(book types.OrderBook, own []types.Order) (bidScale, askScale float64, bidDepth, askDepth fixedpoint.Value) {
+	bid, okBid := book.BestBid()
+	ask, okAsk := book.BestAsk()
+	if !okBid || !okAsk {
+		return 0, 0, fixedpoint.Zero, fixedpoint.Zero
+	}
+
+	mid := bid.Price.Add(ask.Price).Div(fixedpoint.Two)
+	priceRange := mid.Mul(c.RangeBps).Div(tenThousand)
+
+	bidDepth = sideDepth(book.SideBook(types.SideTypeBuy), types.SideTypeBuy, mid.Sub(priceRange), own)
+	askDepth = sideDepth(book.SideBook(types.SideTypeSell), types.SideTypeSell, mid.Add(priceRange), own)
+	return c.scale(bidDepth), c.scale(askDepth), bidDepth, askDepth
+}
+
+func (c *DepthGuardConfig) scale(depth fixedpoint.Value) float64 {
+	if depth.Compare(c.MinDepth) >= 0 {
+		return 1.0
+	}
+
+	if c.Action == DepthGuardActionShrink {
+		return depth.Div(c.MinDepth).Float64()
+	}
+
+	return 0.0
+}
+
+// sideDepth sums the quote value of the price levels from the best price to the limit price,
+// the remaining quantity of the own orders on the levels is excluded.
+func sideDepth(pvs types.PriceVolumeSlice, side types.SideType, limitPrice fixedpoint.Value, own []types.Order) fixedpoint.Value {
+	depth := fixedpoint.Zero
+	for _, pv := range pvs {
+		if (side == types.SideTypeBuy && pv.Price.Compare(limitPrice) < 0) ||
+			(side == types.SideTypeSell && pv.Price.Compare(limitPrice) > 0) {
+			break
+		}
+
+		volume := pv.Volume
+		for _, order := range own {
+			if order.Side == side && order.Price.Compare(pv.Price) == 0 {
+				volume = volume.Sub(order.Quantity.Sub(order.ExecutedQuantity))
+			}
+		}
+
+		if volume.Sign() > 0 {
+			depth = depth.Add(volume.Mul(pv.Price))
+		}
+	}
+
+	return depth
+}
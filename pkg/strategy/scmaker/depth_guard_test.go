@@ -0,0 +1,70 @@
+package scmaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func newTestDepthBook() *types.SliceOrderBook {
+	book := types.NewSliceOrderBook("USDCUSDT")
+	book.Load(types.SliceOrderBook{
+		Symbol: "USDCUSDT",
+		Bids: types.PriceVolumeSlice{
+			{Price: fixedpoint.MustNewFromString("0.9999"), Volume: fixedpoint.NewFromInt(30_000)},
+			{Price: fixedpoint.MustNewFromString("0.9998"), Volume: fixedpoint.NewFromInt(30_000)},
+			{Price: fixedpoint.MustNewFromString("0.9980"), Volume: fixedpoint.NewFromInt(100_000)},
+		},
+		Asks: types.PriceVolumeSlice{
+			{Price: fixedpoint.MustNewFromString("1.0001"), Volume: fixedpoint.NewFromInt(10_000)},
+			{Price: fixedpoint.MustNewFromString("1.0002"), Volume: fixedpoint.NewFromInt(10_000)},
+			{Price: fixedpoint.MustNewFromString("1.0020"), Volume: fixedpoint.NewFromInt(100_000)},
+		},
+	})
+	return book
+}
+
+func TestDepthGuardConfig_scales(t *testing.T) {
+	config := &DepthGuardConfig{
+		RangeBps: fixedpoint.NewFromInt(5),
+		MinDepth: fixedpoint.NewFromInt(40_000),
+	}
+	assert.NoError(t, config.Validate())
+	assert.Equal(t, DepthGuardActionSkip, config.Action)
+
+	book := newTestDepthBook()
+
+	// the levels out of 5 bps are not counted
+	bidScale, askScale, bidDepth, askDepth := config.scales(book, nil)
+	assert.Equal(t, 1.0, bidScale)
+	assert.Equal(t, 0.0, askScale)
+	assert.InDelta(t, 59991.0, bidDepth.Float64(), 1e-6)
+	assert.InDelta(t, 20003.0, askDepth.Float64(), 1e-6)
+
+	config.Action = DepthGuardActionShrink
+	_, askScale, _, _ = config.scales(book, nil)
+	assert.InDelta(t, 0.5, askScale, 1e-3)
+
+	// the own orders are excluded from the depth
+	own := []types.Order{
+		{
+			SubmitOrder: types.SubmitOrder{Side: types.SideTypeBuy, Price: fixedpoint.MustNewFromString("0.9999"), Quantity: fixedpoint.NewFromInt(30_000)},
+		},
+	}
+	bidScale, _, _, _ = config.scales(book, own)
+	assert.InDelta(t, 0.75, bidScale, 1e-3)
+
+	// no quote without the book
+	bidScale, askScale, _, _ = config.scales(types.NewSliceOrderBook("USDCUSDT"), nil)
+	assert.Equal(t, 0.0, bidScale)
+	assert.Equal(t, 0.0, askScale)
+}
+
+func TestDepthGuardConfig_Validate(t *testing.T) {
+	assert.Error(t, (&DepthGuardConfig{MinDepth: fixedpoint.One}).Validate())
+	assert.Error(t, (&DepthGuardConfig{RangeBps: fixedpoint.One}).Validate())
+	assert.Error(t, (&DepthGuardConfig{RangeBps: fixedpoint.One, MinDepth: fixedpoint.One, Action: "cancel"}).Validate())
+}
@@ -1,6 +1,8 @@
 package scmaker
 
 import (
+	"fmt"
+
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 	"github.com/c9s/bbgo/pkg/indicator"
 	"github.com/c9s/bbgo/pkg/types"
@@ -42,3 +44,41 @@ func Intensity(source indicator.KLineSubscription, window int) *IntensityStream
 
 	return s
 }
+
+// IntensityState is the serializable state of IntensityStream
+type IntensityState struct {
+	Window int                 `json:"window"`
+	Values []float64           `json:"values"`
+	Buy    *indicator.RMAState `json:"buy"`
+	Sell   *indicator.RMAState `json:"sell"`
+}
+
+func (s *IntensityStream) State() *IntensityState {
+	return &IntensityState{
+		Window: s.window,
+		Values: s.Snapshot(),
+		Buy:    s.Buy.State(),
+		Sell:   s.Sell.State(),
+	}
+}
+
+func (s *IntensityStream) RestoreState(state *IntensityState) error {
+	if state.Window != s.window {
+		return fmt.Errorf("intensity state window %d does not match the indicator window %d", state.Window, s.window)
+	}
+
+	if state.Buy == nil || state.Sell == nil {
+		return fmt.Errorf("intensity state is incomplete")
+	}
+
+	if err := s.Buy.RestoreState(state.Buy); err != nil {
+		return err
+	}
+
+	if err := s.Sell.RestoreState(state.Sell); err != nil {
+		return err
+	}
+
+	s.Restore(state.Values)
+	return nil
+}
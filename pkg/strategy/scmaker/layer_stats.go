@@ -0,0 +1,305 @@
+package scmaker
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultLayerMarkOutHorizon = 30 * time.Second
+
+// LayerScaleTuningConfig shifts the liquidity size toward the layers with the better fill score,
+// the quantity of each layer is the slide rule scale times the layer weight.
+// The layer score is the fill ratio times (1 - the adverse mark-out ratio),
+// and the layer weight is 1 + strength * (score / average score - 1), clamped by the min and the max weight.
+//
+//	layerScaleTuning:
+//	  minOrders: 50
+//	  strength: 0.5
+//	  minWeight: 0.5
+//	  maxWeight: 2.0
+type LayerScaleTuningConfig struct {
+	// MinOrders is the min number of the placed orders of the layer before the layer weight is tuned, defaults to 20
+	MinOrders int `json:"minOrders,omitempty"`
+
+	// Strength is the ratio of the score deviation applied to the layer weight, defaults to 0.5
+	Strength float64 `json:"strength,omitempty"`
+
+	// MinWeight and MaxWeight clamp the layer weight, defaults to 0.5 and 2.0
+	MinWeight float64 `json:"minWeight,omitempty"`
+	MaxWeight float64 `json:"maxWeight,omitempty"`
+}
+
+func (c *LayerScaleTuningConfig) Validate() error {
+	if c.MinOrders == 0 {
+		c.MinOrders = 20
+	}
+
+	if c.Strength == 0 {
+		c.Strength = 0.5
+	}
+
+	if c.MinWeight == 0 {
+		c.MinWeight = 0.5
+	}
+
+	if c.MaxWeight == 0 {
+		c.MaxWeight = 2.0
+	}
+
+	if c.MinOrders < 0 || c.Strength < 0 || c.MinWeight < 0 {
+		return errors.New("layerScaleTuning: minOrders, strength and minWeight should not be negative")
+	}
+
+	if c.MaxWeight < c.MinWeight {
+		return errors.New("layerScaleTuning: maxWeight should be greater than minWeight")
+	}
+
+	return nil
+}
+
+// LayerStats is the fill statistics of a liquidity layer
+type LayerStats struct {
+	Layer int `json:"layer"`
+
+	NumOfOrders       int `json:"numOfOrders"`
+	NumOfFilledOrders int `json:"numOfFilledOrders"`
+	NumOfTrades       int `json:"numOfTrades"`
+
+	FilledQuantity      fixedpoint.Value `json:"filledQuantity"`
+	FilledQuoteQuantity fixedpoint.Value `json:"filledQuoteQuantity"`
+
+	// RealizedEdge is the sum of the fill price edge against the mid price at the order placement, in the quote currency
+	RealizedEdge fixedpoint.Value `json:"realizedEdge"`
+
+	// NumOfMarkOuts and NumOfAdverseMarkOuts are the mark-out samples of the fills at the mark-out horizon
+	NumOfMarkOuts        int              `json:"numOfMarkOuts"`
+	NumOfAdverseMarkOuts int              `json:"numOfAdverseMarkOuts"`
+	MarkOutPnL           fixedpoint.Value `json:"markOutPnL"`
+}
+
+// FillRatio is the ratio of the filled orders to the placed orders
+func (s *LayerStats) FillRatio() float64 {
+	if s.NumOfOrders == 0 {
+		return 0
+	}
+
+	return float64(s.NumOfFilledOrders) / float64(s.NumOfOrders)
+}
+
+// AdverseRatio is the ratio of the adverse mark-outs to the mark-out samples
+func (s *LayerStats) AdverseRatio() float64 {
+	if s.NumOfMarkOuts == 0 {
+		return 0
+	}
+
+	return float64(s.NumOfAdverseMarkOuts) / float64(s.NumOfMarkOuts)
+}
+
+// Score is the fill ratio discounted by the adverse selection
+func (s *LayerStats) Score() float64 {
+	return s.FillRatio() * (1.0 - s.AdverseRatio())
+}
+
+// LayerReport is the layer stats with the derived ratios and the tuned weight
+type LayerReport struct {
+	LayerStats
+
+	FillRatio    float64 `json:"fillRatio"`
+	AdverseRatio float64 `json:"adverseRatio"`
+	Score        float64 `json:"score"`
+	Weight       float64 `json:"weight"`
+}
+
+type layerOrder struct {
+	layer     int
+	side      types.SideType
+	midPrice  fixedpoint.Value
+	filled    bool
+	createdAt time.Time
+}
+
+// LiquidityLayerStats records the fills of the liquidity orders by the layer index
+type LiquidityLayerStats struct {
+	Symbol string `json:"symbol"`
+
+	// MarkOutHorizon is the horizon of the mark-out samples counted as the adverse selection
+	MarkOutHorizon time.Duration `json:"markOutHorizon"`
+
+	Layers []*LayerStats `json:"layers"`
+
+	mu     sync.Mutex
+	orders map[uint64]*layerOrder
+}
+
+func NewLiquidityLayerStats(symbol string, markOutHorizon time.Duration) *LiquidityLayerStats {
+	return &LiquidityLayerStats{
+		Symbol:         symbol,
+		MarkOutHorizon: markOutHorizon,
+	}
+}
+
+func (s *LiquidityLayerStats) layer(i int) *LayerStats {
+	for len(s.Layers) <= i {
+		s.Layers = append(s.Layers, &LayerStats{Layer: len(s.Layers)})
+	}
+
+	return s.Layers[i]
+}
+
+// AddOrder records the placed order of the layer, midPrice is the mid price at the placement
+func (s *LiquidityLayerStats) AddOrder(order types.Order, layer int, midPrice fixedpoint.Value, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.orders == nil {
+		s.orders = make(map[uint64]*layerOrder)
+	}
+
+	s.orders[order.OrderID] = &layerOrder{
+		layer:     layer,
+		side:      order.Side,
+		midPrice:  midPrice,
+		createdAt: now,
+	}
+
+	s.layer(layer).NumOfOrders++
+}
+
+// AddTrade records the fill of the layer order, the trades of the other orders are ignored
+func (s *LiquidityLayerStats) AddTrade(trade types.Trade) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[trade.OrderID]
+	if !ok {
+		return false
+	}
+
+	stats := s.layer(order.layer)
+	if !order.filled {
+		order.filled = true
+		stats.NumOfFilledOrders++
+	}
+
+	edge := trade.Price.Sub(order.midPrice)
+	if order.side == types.SideTypeBuy {
+		edge = edge.Neg()
+	}
+
+	stats.NumOfTrades++
+	stats.FilledQuantity = stats.FilledQuantity.Add(trade.Quantity)
+	stats.FilledQuoteQuantity = stats.FilledQuoteQuantity.Add(trade.QuoteQuantity)
+	stats.RealizedEdge = stats.RealizedEdge.Add(edge.Mul(trade.Quantity))
+	return true
+}
+
+// AddMarkOut records the mark-out of the layer order fill at the mark-out horizon
+func (s *LiquidityLayerStats) AddMarkOut(trade types.Trade, horizon time.Duration, bps, pnl fixedpoint.Value) bool {
+	if horizon != s.MarkOutHorizon {
+		return false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orders[trade.OrderID]
+	if !ok {
+		return false
+	}
+
+	stats := s.layer(order.layer)
+	stats.NumOfMarkOuts++
+	stats.MarkOutPnL = stats.MarkOutPnL.Add(pnl)
+	if bps.Sign() < 0 {
+		stats.NumOfAdverseMarkOuts++
+	}
+
+	return true
+}
+
+// Prune removes the orders placed before the given time, the fills of the removed orders are not recorded anymore
+func (s *LiquidityLayerStats) Prune(before time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for orderID, order := range s.orders {
+		if order.createdAt.Before(before) {
+			delete(s.orders, orderID)
+		}
+	}
+}
+
+// Weights returns the tuned weights of the layers 0 to n-1,
+// the layers without enough orders keep the weight 1.
+func (s *LiquidityLayerStats) Weights(n int, config *LayerScaleTuningConfig) []float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	weights := make([]float64, n)
+	for i := range weights {
+		weights[i] = 1.0
+	}
+
+	if config == nil {
+		return weights
+	}
+
+	scores := make(map[int]float64)
+	sum := 0.0
+	for i := 0; i < n && i < len(s.Layers); i++ {
+		if s.Layers[i].NumOfOrders < config.MinOrders {
+			continue
+		}
+
+		scores[i] = s.Layers[i].Score()
+		sum += scores[i]
+	}
+
+	if len(scores) == 0 || sum <= 0 {
+		return weights
+	}
+
+	avg := sum / float64(len(scores))
+	for i, score := range scores {
+		w := 1.0 + config.Strength*(score/avg-1.0)
+		weights[i] = math.Min(config.MaxWeight, math.Max(config.MinWeight, w))
+	}
+
+	return weights
+}
+
+// Reports returns the copies of the layer stats with the weights
+func (s *LiquidityLayerStats) Reports(weights []float64) []LayerReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reports := make([]LayerReport, 0, len(s.Layers))
+	for _, stats := range s.Layers {
+		weight := 1.0
+		if stats.Layer < len(weights) {
+			weight = weights[stats.Layer]
+		}
+
+		reports = append(reports, LayerReport{
+			LayerStats:   *stats,
+			FillRatio:    stats.FillRatio(),
+			AdverseRatio: stats.AdverseRatio(),
+			Score:        stats.Score(),
+			Weight:       weight,
+		})
+	}
+
+	return reports
+}
+
+// LayerAnalytics is the analytics of the liquidity layers exposed by the strategy instance API
+type LayerAnalytics struct {
+	MarkOutHorizon types.Duration `json:"markOutHorizon"`
+	Tuning         bool           `json:"tuning"`
+	Layers         []LayerReport  `json:"layers"`
+}
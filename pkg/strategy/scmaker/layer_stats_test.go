@@ -0,0 +1,66 @@
+package scmaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestLiquidityLayerStats(t *testing.T) {
+	stats := NewLiquidityLayerStats("USDCUSDT", 30*time.Second)
+	now := time.Now()
+	midPrice := fixedpoint.MustNewFromString("1.0001")
+
+	stats.AddOrder(types.Order{OrderID: 1, SubmitOrder: types.SubmitOrder{Side: types.SideTypeBuy}}, 0, midPrice, now)
+	stats.AddOrder(types.Order{OrderID: 2, SubmitOrder: types.SubmitOrder{Side: types.SideTypeSell}}, 0, midPrice, now)
+	stats.AddOrder(types.Order{OrderID: 3, SubmitOrder: types.SubmitOrder{Side: types.SideTypeBuy}}, 1, midPrice, now)
+	stats.AddOrder(types.Order{OrderID: 4, SubmitOrder: types.SubmitOrder{Side: types.SideTypeSell}}, 1, midPrice, now)
+
+	buy := types.Trade{OrderID: 1, Side: types.SideTypeBuy, Price: fixedpoint.One, Quantity: fixedpoint.NewFromInt(10)}
+	sell := types.Trade{OrderID: 2, Side: types.SideTypeSell, Price: fixedpoint.MustNewFromString("1.0002"), Quantity: fixedpoint.NewFromInt(10)}
+	assert.True(t, stats.AddTrade(buy))
+	assert.True(t, stats.AddTrade(sell))
+	assert.True(t, stats.AddTrade(types.Trade{OrderID: 3, Side: types.SideTypeBuy, Price: fixedpoint.One, Quantity: fixedpoint.One}))
+	assert.False(t, stats.AddTrade(types.Trade{OrderID: 99}), "the trade of the other order is ignored")
+
+	assert.Equal(t, "0.002", stats.Layers[0].RealizedEdge.String())
+	assert.Equal(t, 1.0, stats.Layers[0].FillRatio())
+	assert.Equal(t, 0.5, stats.Layers[1].FillRatio())
+	assert.Equal(t, 2, stats.Layers[1].NumOfOrders)
+
+	assert.False(t, stats.AddMarkOut(buy, 5*time.Second, fixedpoint.NewFromInt(-1), fixedpoint.Zero), "other horizons are ignored")
+	assert.True(t, stats.AddMarkOut(buy, 30*time.Second, fixedpoint.NewFromInt(-1), fixedpoint.MustNewFromString("-0.001")))
+	assert.True(t, stats.AddMarkOut(sell, 30*time.Second, fixedpoint.One, fixedpoint.MustNewFromString("0.001")))
+	assert.Equal(t, 0.5, stats.Layers[0].AdverseRatio())
+	assert.Equal(t, 0.5, stats.Layers[0].Score())
+
+	t.Run("weights", func(t *testing.T) {
+		// layer 1 has the better score with the zero adverse ratio
+		assert.True(t, stats.AddMarkOut(types.Trade{OrderID: 3}, 30*time.Second, fixedpoint.One, fixedpoint.Zero))
+		stats.AddOrder(types.Order{OrderID: 5, SubmitOrder: types.SubmitOrder{Side: types.SideTypeBuy}}, 0, midPrice, now)
+		stats.AddOrder(types.Order{OrderID: 6, SubmitOrder: types.SubmitOrder{Side: types.SideTypeBuy}}, 0, midPrice, now)
+
+		config := &LayerScaleTuningConfig{MinOrders: 2}
+		assert.NoError(t, config.Validate())
+
+		assert.Equal(t, []float64{1, 1, 1}, stats.Weights(3, nil))
+
+		// layer 0 score: 0.5 * 0.5 = 0.25, layer 1 score: 0.5 * 1 = 0.5
+		weights := stats.Weights(3, config)
+		assert.InDelta(t, 1.0-0.5/3.0, weights[0], 1e-9)
+		assert.InDelta(t, 1.0+0.5/3.0, weights[1], 1e-9)
+		assert.Equal(t, 1.0, weights[2], "the layer without enough orders keeps the weight")
+
+		config.Strength = 10
+		assert.Equal(t, []float64{0.5, 2, 1}, stats.Weights(3, config))
+	})
+
+	t.Run("prune", func(t *testing.T) {
+		stats.Prune(now.Add(time.Second))
+		assert.False(t, stats.AddTrade(buy))
+	})
+}
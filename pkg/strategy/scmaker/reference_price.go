@@ -0,0 +1,133 @@
+package scmaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type ReferencePriceAction string
+
+const (
+	// ReferencePriceActionPull cancels the liquidity orders and stops quoting until the deviation recovers
+	ReferencePriceActionPull ReferencePriceAction = "pull"
+
+	// ReferencePriceActionRecenter quotes around the reference mid price,
+	// and the bids (asks) do not go above (below) the reference best bid (ask)
+	ReferencePriceActionRecenter ReferencePriceAction = "recenter"
+)
+
+// ReferencePriceConfig compares the mid price of the quoting session with the mid price of the reference session,
+// so that the strategy does not quote the stale prices on a slow venue.
+//
+//	referencePrice:
+//	  session: binance
+//	  maxDeviation: 0.1%
+//	  action: recenter
+type ReferencePriceConfig struct {
+	Session string `json:"session"`
+
+	// Symbol is the symbol on the reference session, defaults to the strategy symbol
+	Symbol string `json:"symbol,omitempty"`
+
+	// MaxDeviation is the max ratio of the mid price difference to the reference mid price
+	MaxDeviation fixedpoint.Value `json:"maxDeviation"`
+
+	// Action is pull or recenter, defaults to pull
+	Action ReferencePriceAction `json:"action,omitempty"`
+}
+
+func (c *ReferencePriceConfig) Validate() error {
+	if c.Session == "" {
+		return errors.New("referencePrice: session is required")
+	}
+
+	if c.MaxDeviation.Sign() <= 0 {
+		return errors.New("referencePrice: maxDeviation should be greater than zero")
+	}
+
+	switch c.Action {
+	case "":
+		c.Action = ReferencePriceActionPull
+	case ReferencePriceActionPull, ReferencePriceActionRecenter:
+	default:
+		return fmt.Errorf("referencePrice: invalid action %q, expecting pull or recenter", c.Action)
+	}
+
+	return nil
+}
+
+func tickerMidPrice(ticker *types.Ticker) fixedpoint.Value {
+	return ticker.Buy.Add(ticker.Sell).Div(fixedpoint.Two)
+}
+
+// referenceDeviation returns the ratio of the mid price difference to the reference mid price
+func referenceDeviation(ticker, reference *types.Ticker) fixedpoint.Value {
+	referenceMid := tickerMidPrice(reference)
+	if referenceMid.Sign() <= 0 {
+		return fixedpoint.Zero
+	}
+
+	return tickerMidPrice(ticker).Sub(referenceMid).Abs().Div(referenceMid)
+}
+
+// recenterTicker returns the ticker clamped by the reference ticker,
+// the best bid is not above the reference best bid and the best ask is not below the reference best ask.
+func recenterTicker(ticker, reference *types.Ticker) *types.Ticker {
+	recentered := *ticker
+	recentered.Buy = fixedpoint.Min(ticker.Buy, reference.Buy)
+	recentered.Sell = fixedpoint.Max(ticker.Sell, reference.Sell)
+	return &recentered
+}
+
+func (s *Strategy) referenceSymbol() string {
+	if s.ReferencePrice.Symbol != "" {
+		return s.ReferencePrice.Symbol
+	}
+
+	return s.Symbol
+}
+
+// checkReferencePrice queries the reference ticker and returns it if the mid price of the ticker deviates more than the max deviation
+func (s *Strategy) checkReferencePrice(ctx context.Context, ticker *types.Ticker) (*types.Ticker, bool, error) {
+	reference, err := s.referenceSession.Exchange.QueryTicker(ctx, s.referenceSymbol())
+	if err != nil {
+		return nil, false, err
+	}
+
+	deviation := referenceDeviation(ticker, reference)
+	if deviation.Compare(s.ReferencePrice.MaxDeviation) <= 0 {
+		return reference, false, nil
+	}
+
+	log.Warnf("%s mid price %f deviates %f%% from the %s reference mid price %f",
+		s.Symbol, tickerMidPrice(ticker).Float64(), deviation.Float64()*100.0, s.ReferencePrice.Session, tickerMidPrice(reference).Float64())
+	return reference, true, nil
+}
+
+// pullQuotesOnDeviation cancels the liquidity orders when the mid price deviates from the reference mid price,
+// it's checked on the adjustment update interval, so the orders don't rest until the next liquidity update.
+func (s *Strategy) pullQuotesOnDeviation(ctx context.Context) {
+	if s.ReferencePrice == nil || s.ReferencePrice.Action != ReferencePriceActionPull || s.liquidityOrderBook.NumOfOrders() == 0 {
+		return
+	}
+
+	ticker, err := s.session.Exchange.QueryTicker(ctx, s.Symbol)
+	if logErr(err, "unable to query ticker") {
+		return
+	}
+
+	_, deviated, err := s.checkReferencePrice(ctx, ticker)
+	if logErr(err, "unable to query the reference ticker") || !deviated {
+		return
+	}
+
+	log.Infof("pulling the liquidity orders by the reference price deviation")
+	err = s.liquidityOrderBook.GracefulCancel(ctx, s.session.Exchange)
+	logErr(err, "unable to cancel liquidity orders")
+}
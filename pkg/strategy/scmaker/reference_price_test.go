@@ -0,0 +1,34 @@
+package scmaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestReferencePriceConfig_Validate(t *testing.T) {
+	c := &ReferencePriceConfig{Session: "binance", MaxDeviation: fixedpoint.MustNewFromString("0.001")}
+	assert.NoError(t, c.Validate())
+	assert.Equal(t, ReferencePriceActionPull, c.Action)
+
+	assert.Error(t, (&ReferencePriceConfig{MaxDeviation: fixedpoint.One}).Validate())
+	assert.Error(t, (&ReferencePriceConfig{Session: "binance"}).Validate())
+	assert.Error(t, (&ReferencePriceConfig{Session: "binance", MaxDeviation: fixedpoint.One, Action: "hedge"}).Validate())
+}
+
+func TestReferenceDeviation(t *testing.T) {
+	ticker := &types.Ticker{Buy: fixedpoint.MustNewFromString("1.0010"), Sell: fixedpoint.MustNewFromString("1.0012")}
+	reference := &types.Ticker{Buy: fixedpoint.MustNewFromString("0.9999"), Sell: fixedpoint.MustNewFromString("1.0001")}
+
+	assert.InDelta(t, 0.0011, referenceDeviation(ticker, reference).Float64(), 1e-9)
+	assert.Equal(t, fixedpoint.Zero, referenceDeviation(ticker, &types.Ticker{}))
+
+	// the stale bid above the reference bid is moved down
+	recentered := recenterTicker(ticker, reference)
+	assert.Equal(t, "0.9999", recentered.Buy.String())
+	assert.Equal(t, "1.0012", recentered.Sell.String())
+	assert.Equal(t, "1.001", ticker.Buy.String(), "the original ticker is not modified")
+}
@@ -0,0 +1,90 @@
+package scmaker
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// IndicatorState is the persisted state of the indicators,
+// the indicators are restored from the state on startup, and only the klines after the state are preloaded.
+type IndicatorState struct {
+	MidPriceEMA         *indicator.EWMAState         `json:"midPriceEMA,omitempty"`
+	MidPriceKalman      *indicator.KalmanFilterState `json:"midPriceKalman,omitempty"`
+	PriceRangeBollinger *indicator.BOLLState         `json:"priceRangeBollinger,omitempty"`
+	Intensity           *IntensityState              `json:"intensity,omitempty"`
+
+	// KLineTimes is the end time of the last kline pushed to the indicators of the interval
+	KLineTimes map[types.Interval]types.Time `json:"klineTimes"`
+}
+
+// canResume checks if the indicators of the interval can be resumed from the state,
+// the state is stale when the preloaded klines can not cover the klines after the state.
+func (st *IndicatorState) canResume(session *bbgo.ExchangeSession, symbol string, interval types.Interval) (time.Time, bool) {
+	if st == nil {
+		return time.Time{}, false
+	}
+
+	lastTime, ok := st.KLineTimes[interval]
+	if !ok || lastTime.Time().IsZero() {
+		return time.Time{}, false
+	}
+
+	store, ok := session.MarketDataStore(symbol)
+	if !ok {
+		return time.Time{}, false
+	}
+
+	kLines, ok := store.KLinesOfInterval(interval)
+	if !ok || len(*kLines) == 0 {
+		return lastTime.Time(), true
+	}
+
+	// there is a gap between the state and the preloaded klines
+	first := (*kLines)[0]
+	if first.StartTime.Time().After(lastTime.Time()) {
+		return time.Time{}, false
+	}
+
+	return lastTime.Time(), true
+}
+
+// trackKLineTime records the end time of the last kline of the kline stream
+func (s *Strategy) trackKLineTime(kLines *indicator.KLineStream, interval types.Interval) {
+	kLines.OnUpdate(func(k types.KLine) {
+		s.kLineTimesMutex.Lock()
+		s.kLineTimes[interval] = k.EndTime
+		s.kLineTimesMutex.Unlock()
+	})
+}
+
+// saveIndicatorState saves the state of the indicators for the next startup
+func (s *Strategy) saveIndicatorState() {
+	st := &IndicatorState{KLineTimes: make(map[types.Interval]types.Time)}
+
+	s.kLineTimesMutex.Lock()
+	for interval, t := range s.kLineTimes {
+		st.KLineTimes[interval] = t
+	}
+	s.kLineTimesMutex.Unlock()
+
+	if s.ewma != nil {
+		st.MidPriceEMA = s.ewma.State()
+	}
+
+	if s.kalman != nil {
+		st.MidPriceKalman = s.kalman.State()
+	}
+
+	if s.boll != nil {
+		st.PriceRangeBollinger = s.boll.State()
+	}
+
+	if s.intensity != nil {
+		st.Intensity = s.intensity.State()
+	}
+
+	s.IndicatorState = st
+}
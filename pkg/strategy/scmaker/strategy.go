@@ -2,9 +2,11 @@ package scmaker
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
 	"sync"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
@@ -29,6 +31,51 @@ type BollingerConfig struct {
 	K        float64        `json:"k"`
 }
 
+// KalmanFilterConfig is the config of the Kalman filter mid price smoother,
+// the lower ratio of the process noise to the measurement noise gives the smoother mid price.
+type KalmanFilterConfig struct {
+	Interval         types.Interval `json:"interval"`
+	ProcessNoise     float64        `json:"processNoise"`
+	MeasurementNoise float64        `json:"measurementNoise"`
+}
+
+func (c *KalmanFilterConfig) Validate() error {
+	if c.ProcessNoise <= 0 || c.MeasurementNoise <= 0 {
+		return errors.New("midPriceKalman: processNoise and measurementNoise should be greater than zero")
+	}
+
+	return nil
+}
+
+// RegimeFilterConfig pauses the liquidity orders by the market regime published on the message bus,
+// e.g., a trend strategy publishes "trending" to the regime topic.
+type RegimeFilterConfig struct {
+	Topic string   `json:"topic"`
+	Pause []string `json:"pause"`
+}
+
+func (c *RegimeFilterConfig) Validate() error {
+	if c.Topic == "" {
+		return errors.New("regimeFilter: topic is required")
+	}
+
+	if len(c.Pause) == 0 {
+		return errors.New("regimeFilter: pause regimes are required")
+	}
+
+	return nil
+}
+
+func (c *RegimeFilterConfig) shouldPause(regime string) bool {
+	for _, r := range c.Pause {
+		if r == regime {
+			return true
+		}
+	}
+
+	return false
+}
+
 func init() {
 	bbgo.RegisterStrategy(ID, &Strategy{})
 }
@@ -38,38 +85,112 @@ type Strategy struct {
 	Environment *bbgo.Environment
 	Market      types.Market
 
-	Symbol string `json:"symbol"`
+	Symbol string `json:"symbol" validate:"required"`
 
-	NumOfLiquidityLayers int `json:"numOfLiquidityLayers"`
+	NumOfLiquidityLayers int `json:"numOfLiquidityLayers" validate:"required"`
 
-	LiquidityUpdateInterval types.Interval   `json:"liquidityUpdateInterval"`
-	PriceRangeBollinger     *BollingerConfig `json:"priceRangeBollinger"`
+	LiquidityUpdateInterval types.Interval   `json:"liquidityUpdateInterval" validate:"required"`
+	PriceRangeBollinger     *BollingerConfig `json:"priceRangeBollinger" validate:"required"`
 	StrengthInterval        types.Interval   `json:"strengthInterval"`
 
 	AdjustmentUpdateInterval types.Interval `json:"adjustmentUpdateInterval"`
 
 	MidPriceEMA            *types.IntervalWindow `json:"midPriceEMA"`
-	LiquiditySlideRule     *bbgo.SlideRule       `json:"liquidityScale"`
+	LiquiditySlideRule     *bbgo.SlideRule       `json:"liquidityScale" validate:"required"`
 	LiquidityLayerTickSize fixedpoint.Value      `json:"liquidityLayerTickSize"`
 
+	// AdaptiveLayerSpacing scales the layer spacing with the volatility instead of the fixed liquidityLayerTickSize,
+	// so that the book widens automatically in the volatile market.
+	AdaptiveLayerSpacing *AdaptiveLayerSpacingConfig `json:"adaptiveLayerSpacing,omitempty"`
+
+	// MidPriceKalman smooths the mid price with the Kalman filter instead of the EMA
+	MidPriceKalman *KalmanFilterConfig `json:"midPriceKalman,omitempty"`
+
 	MaxExposure fixedpoint.Value `json:"maxExposure"`
 
+	// MidPricePredictor offsets the mid price anchor by the predicted drift over the order placement latency,
+	// use shadow: true to evaluate the predictions without moving the quotes.
+	MidPricePredictor *bbgo.MidPricePredictorConfig `json:"midPricePredictor,omitempty"`
+
+	// PostOnlyRetry reprices the liquidity orders rejected because they would cross the book, instead of dropping the layer
+	PostOnlyRetry *bbgo.PostOnlyRetryOptions `json:"postOnlyRetry,omitempty"`
+
 	MinProfit fixedpoint.Value `json:"minProfit"`
 
 	Position    *types.Position    `json:"position,omitempty" persistence:"position"`
 	ProfitStats *types.ProfitStats `json:"profitStats,omitempty" persistence:"profit_stats"`
 
+	// MarkOutStats is the post-trade mark-out statistics, it shows the adverse selection of the liquidity orders
+	MarkOutStats *types.MarkOutStats `json:"markOutStats,omitempty" persistence:"markout_stats"`
+
+	// LayerStats is the fill statistics of the liquidity orders by the layer index
+	LayerStats *LiquidityLayerStats `json:"layerStats,omitempty" persistence:"layer_stats"`
+
+	// LayerScaleTuning shifts the liquidity size toward the layers with the better fill score in the layer stats
+	LayerScaleTuning *LayerScaleTuningConfig `json:"layerScaleTuning,omitempty"`
+
+	// IndicatorState is the state of the indicators saved on shutdown, so that the restart doesn't need the full kline preload
+	IndicatorState *IndicatorState `json:"indicatorState,omitempty" persistence:"indicator_state"`
+
+	// PositionNetting is injected when the position netting is enabled,
+	// the opposing positions of the other strategy instances on the same symbol offset the position of this strategy.
+	PositionNetting *bbgo.PositionNettingService `json:"-"`
+
+	// TradingWindows only quotes in the UTC time windows, all the orders are canceled outside the windows
+	TradingWindows []TradingWindow `json:"tradingWindows,omitempty"`
+
+	// RequoteSchedule re-places the liquidity orders on the cron spec, e.g., "0 0 * * *" to requote at 00:00 UTC,
+	// the spec runs in the time zone of the environment scheduler.
+	RequoteSchedule string `json:"requoteSchedule,omitempty"`
+
+	// DepthGuard skips or shrinks the liquidity orders when the displayed depth around the mid price is thin
+	DepthGuard *DepthGuardConfig `json:"depthGuard,omitempty"`
+
+	// ReferencePrice pulls or re-centers the quotes when the mid price deviates from the mid price of the reference session
+	ReferencePrice *ReferencePriceConfig `json:"referencePrice,omitempty"`
+
+	// RegimeFilter pauses the liquidity orders when the regime published on the message bus is one of the pause regimes
+	RegimeFilter *RegimeFilterConfig `json:"regimeFilter,omitempty"`
+
+	// MessageBus is injected when the message bus is configured
+	MessageBus *bbgo.MessageBus `json:"-"`
+
+	// controlMutex serializes the suspend, resume and requote calls from the api with the market data callbacks,
+	// the status and the order placements are guarded by it.
+	controlMutex sync.Mutex
+	status       types.StrategyStatus
+
+	// tradingCtx is the context of Run, it's used by the suspend and resume calls
+	tradingCtx context.Context
+
 	session                                 *bbgo.ExchangeSession
+	referenceSession                        *bbgo.ExchangeSession
 	orderExecutor                           *bbgo.GeneralOrderExecutor
 	liquidityOrderBook, adjustmentOrderBook *bbgo.ActiveOrderBook
 	book                                    *types.StreamOrderBook
 
 	liquidityScale bbgo.Scale
 
+	midPricePredictor *bbgo.MidPricePredictor
+
 	// indicators
-	ewma      *indicator.EWMAStream
-	boll      *indicator.BOLLStream
-	intensity *IntensityStream
+	ewma       *indicator.EWMAStream
+	kalman     *indicator.KalmanFilterStream
+	boll       *indicator.BOLLStream
+	intensity  *IntensityStream
+	volatility *indicator.Float64Series
+
+	kLineTimes      map[types.Interval]types.Time
+	kLineTimesMutex sync.Mutex
+
+	regimePaused      bool
+	regimePausedMutex sync.Mutex
+
+	// outsideTradingWindow is updated by the kline close time, so that the windows work in the backtest
+	outsideTradingWindow bool
+
+	// decisionClock is ticked by every liquidity update, it's the liveness of the health check
+	decisionClock bbgo.DecisionClock
 }
 
 func (s *Strategy) ID() string {
@@ -80,14 +201,36 @@ func (s *Strategy) InstanceID() string {
 	return fmt.Sprintf("%s:%s", ID, s.Symbol)
 }
 
+func (s *Strategy) Validate() error {
+	if s.NumOfLiquidityLayers < 0 {
+		return errors.New("numOfLiquidityLayers should not be negative")
+	}
+
+	if s.MidPriceEMA == nil && s.MidPriceKalman == nil {
+		return errors.New("either midPriceEMA or midPriceKalman is required")
+	}
+
+	if s.PriceRangeBollinger != nil && s.PriceRangeBollinger.Window <= 0 {
+		return errors.New("priceRangeBollinger: window should be greater than zero")
+	}
+
+	return nil
+}
+
 func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {
 	session.Subscribe(types.BookChannel, s.Symbol, types.SubscribeOptions{})
 	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.AdjustmentUpdateInterval})
 	session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.LiquidityUpdateInterval})
 
-	if s.MidPriceEMA != nil {
+	if s.MidPriceKalman != nil {
+		session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.MidPriceKalman.Interval})
+	} else if s.MidPriceEMA != nil {
 		session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.MidPriceEMA.Interval})
 	}
+
+	if s.AdaptiveLayerSpacing != nil {
+		session.Subscribe(types.KLineChannel, s.Symbol, types.SubscribeOptions{Interval: s.AdaptiveLayerSpacing.Interval})
+	}
 }
 
 func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
@@ -95,7 +238,7 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 
 	s.session = session
 	s.book = types.NewStreamBook(s.Symbol)
-	s.book.BindStream(session.UserDataStream)
+	s.book.BindStream(session.MarketDataStream)
 
 	s.liquidityOrderBook = bbgo.NewActiveOrderBook(s.Symbol)
 	s.liquidityOrderBook.BindStream(session.UserDataStream)
@@ -125,6 +268,14 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		s.ProfitStats = types.NewProfitStats(s.Market)
 	}
 
+	if s.MarkOutStats == nil {
+		s.MarkOutStats = types.NewMarkOutStats(s.Symbol, bbgo.DefaultMarkOutHorizons...)
+	}
+
+	if s.LayerStats == nil {
+		s.LayerStats = NewLiquidityLayerStats(s.Symbol, defaultLayerMarkOutHorizon)
+	}
+
 	scale, err := s.LiquiditySlideRule.Scale()
 	if err != nil {
 		return err
@@ -143,22 +294,131 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 	s.orderExecutor = bbgo.NewGeneralOrderExecutor(session, s.Symbol, ID, instanceID, s.Position)
 	s.orderExecutor.BindEnvironment(s.Environment)
 	s.orderExecutor.BindProfitStats(s.ProfitStats)
+	s.orderExecutor.BindMarkOutStats(ctx, s.MarkOutStats).OnMarkOut(func(trade types.Trade, horizon time.Duration, bps, pnl fixedpoint.Value) {
+		s.LayerStats.AddMarkOut(trade, horizon, bps, pnl)
+	})
 	s.orderExecutor.Bind()
+	s.orderExecutor.TradeCollector().OnTrade(func(trade types.Trade, profit, netProfit fixedpoint.Value) {
+		s.LayerStats.AddTrade(trade)
+	})
 	s.orderExecutor.TradeCollector().OnPositionUpdate(func(position *types.Position) {
 		bbgo.Sync(ctx, s)
 	})
 
-	s.initializeMidPriceEMA(session)
+	if s.PostOnlyRetry != nil {
+		s.orderExecutor.EnablePostOnlyRetry(*s.PostOnlyRetry)
+		s.orderExecutor.OnPostOnlyRetryExhausted(func(order types.SubmitOrder, err error) {
+			log.WithError(err).Warnf("giving up the rejected liquidity order %s", order.String())
+		})
+	}
+
+	s.tradingCtx = ctx
+	s.status = types.StrategyStatusRunning
+
+	if s.MidPriceKalman != nil {
+		if err := s.MidPriceKalman.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if s.AdaptiveLayerSpacing != nil {
+		if err := s.AdaptiveLayerSpacing.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if s.LayerScaleTuning != nil {
+		if err := s.LayerScaleTuning.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if s.MidPricePredictor != nil {
+		if err := s.MidPricePredictor.Validate(); err != nil {
+			return err
+		}
+
+		s.midPricePredictor = bbgo.NewMidPricePredictor(*s.MidPricePredictor)
+		s.midPricePredictor.SetClock(s.Environment.Clock())
+		s.midPricePredictor.BindStream(session.MarketDataStream, s.Symbol)
+	}
+
+	for i := range s.TradingWindows {
+		if err := s.TradingWindows[i].Validate(); err != nil {
+			return err
+		}
+	}
+
+	if s.DepthGuard != nil {
+		if err := s.DepthGuard.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if s.ReferencePrice != nil {
+		if err := s.ReferencePrice.Validate(); err != nil {
+			return err
+		}
+
+		referenceSession, ok := s.Environment.Session(s.ReferencePrice.Session)
+		if !ok {
+			return fmt.Errorf("referencePrice: session %s is not found", s.ReferencePrice.Session)
+		}
+
+		s.referenceSession = referenceSession
+	}
+
+	if s.RegimeFilter != nil {
+		if err := s.subscribeRegime(ctx); err != nil {
+			return err
+		}
+	}
+
+	if s.RequoteSchedule != "" {
+		if err := s.Environment.Scheduler().Schedule(instanceID, "requote", s.RequoteSchedule, func(ctx context.Context) {
+			s.controlMutex.Lock()
+			defer s.controlMutex.Unlock()
+
+			s.placeLiquidityOrders(ctx)
+		}); err != nil {
+			return err
+		}
+	}
+
+	s.kLineTimes = make(map[types.Interval]types.Time)
+	if s.MidPriceKalman != nil {
+		s.initializeMidPriceKalman(session)
+	} else {
+		s.initializeMidPriceEMA(session)
+	}
 	s.initializePriceRangeBollinger(session)
 	s.initializeIntensityIndicator(session)
 
+	if s.AdaptiveLayerSpacing != nil {
+		s.initializeVolatilityIndicator(session)
+	}
+
 	session.UserDataStream.OnStart(func() {
+		s.controlMutex.Lock()
+		defer s.controlMutex.Unlock()
+
+		s.updateTradingWindow(ctx, s.Environment.Clock().Now())
 		s.placeLiquidityOrders(ctx)
 	})
 
 	session.MarketDataStream.OnKLineClosed(func(k types.KLine) {
+		if k.Symbol != s.Symbol {
+			return
+		}
+
+		s.controlMutex.Lock()
+		defer s.controlMutex.Unlock()
+
+		s.updateTradingWindow(ctx, k.EndTime.Time())
+
 		if k.Interval == s.AdjustmentUpdateInterval {
 			s.placeAdjustmentOrders(ctx)
+			s.pullQuotesOnDeviation(ctx)
 		}
 
 		if k.Interval == s.LiquidityUpdateInterval {
@@ -166,54 +426,271 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 		}
 	})
 
+	// the market data callbacks stop placing the orders before the orders are canceled
+	bbgo.OnShutdownPhase(ctx, bbgo.ShutdownPhaseStopQuoting, s.InstanceID(), func(ctx context.Context) error {
+		s.controlMutex.Lock()
+		s.status = types.StrategyStatusStopped
+		s.controlMutex.Unlock()
+		return nil
+	})
+
 	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
 		defer wg.Done()
 
-		err := s.liquidityOrderBook.GracefulCancel(ctx, s.session.Exchange)
-		logErr(err, "unable to cancel liquidity orders")
+		s.controlMutex.Lock()
+		s.cancelOrders(ctx)
+		s.controlMutex.Unlock()
+
+		if s.midPricePredictor != nil {
+			log.Infof("mid price prediction stats: %s", s.midPricePredictor.Stats().String())
+		}
 
-		err = s.adjustmentOrderBook.GracefulCancel(ctx, s.session.Exchange)
-		logErr(err, "unable to cancel adjustment orders")
+		s.saveIndicatorState()
+		bbgo.Sync(ctx, s)
 	})
 
 	return nil
 }
 
-func (s *Strategy) preloadKLines(inc *indicator.KLineStream, session *bbgo.ExchangeSession, symbol string, interval types.Interval) {
+// subscribeRegime subscribes the regime topic of the message bus,
+// the liquidity orders are canceled when the regime turns into a pause regime, and re-placed on the next liquidity update.
+func (s *Strategy) subscribeRegime(ctx context.Context) error {
+	if err := s.RegimeFilter.Validate(); err != nil {
+		return err
+	}
+
+	if s.MessageBus == nil {
+		return errors.New("regimeFilter: messageBus is not configured")
+	}
+
+	return s.MessageBus.Subscribe(s.InstanceID(), s.RegimeFilter.Topic, func(msg bbgo.Message) {
+		paused := s.RegimeFilter.shouldPause(msg.String())
+
+		s.regimePausedMutex.Lock()
+		changed := s.regimePaused != paused
+		s.regimePaused = paused
+		s.regimePausedMutex.Unlock()
+
+		if !changed {
+			return
+		}
+
+		log.Infof("market regime %q is published by %s, liquidity orders paused: %v", msg.String(), msg.Publisher, paused)
+		if paused {
+			s.controlMutex.Lock()
+			err := s.liquidityOrderBook.GracefulCancel(ctx, s.session.Exchange)
+			s.controlMutex.Unlock()
+			logErr(err, "unable to cancel liquidity orders")
+		}
+	})
+}
+
+// updateTradingWindow cancels all the orders when the time moves out of the trading windows,
+// it must be called with the controlMutex held.
+func (s *Strategy) updateTradingWindow(ctx context.Context, now time.Time) {
+	if len(s.TradingWindows) == 0 {
+		return
+	}
+
+	outside := true
+	for i := range s.TradingWindows {
+		if s.TradingWindows[i].Contains(now) {
+			outside = false
+			break
+		}
+	}
+
+	if outside == s.outsideTradingWindow {
+		return
+	}
+
+	s.outsideTradingWindow = outside
+	if !outside {
+		log.Infof("%s is in the trading windows, start quoting", now.UTC().Format(time.RFC3339))
+		return
+	}
+
+	log.Infof("%s is out of the trading windows, canceling all orders", now.UTC().Format(time.RFC3339))
+	s.cancelOrders(ctx)
+}
+
+func (s *Strategy) isRegimePaused() bool {
+	s.regimePausedMutex.Lock()
+	defer s.regimePausedMutex.Unlock()
+	return s.regimePaused
+}
+
+// OpenOrders returns the resting liquidity orders and adjustment orders
+func (s *Strategy) OpenOrders() types.OrderSlice {
+	return append(s.liquidityOrderBook.Orders(), s.adjustmentOrderBook.Orders()...)
+}
+
+// RecentTrades returns the recent trades of this strategy
+func (s *Strategy) RecentTrades() []types.Trade {
+	return s.orderExecutor.RecentTrades()
+}
+
+// LastDecisionTime returns the time of the last liquidity update
+func (s *Strategy) LastDecisionTime() time.Time {
+	return s.decisionClock.LastDecisionTime()
+}
+
+// Analytics returns the fill statistics of the liquidity layers
+func (s *Strategy) Analytics() interface{} {
+	return &LayerAnalytics{
+		MarkOutHorizon: types.Duration(s.LayerStats.MarkOutHorizon),
+		Tuning:         s.LayerScaleTuning != nil,
+		Layers:         s.LayerStats.Reports(s.layerWeights()),
+	}
+}
+
+// layerWeights returns the size weights of the liquidity layers, the weights are all 1 when the tuning is disabled
+func (s *Strategy) layerWeights() []float64 {
+	return s.LayerStats.Weights(s.NumOfLiquidityLayers+1, s.LayerScaleTuning)
+}
+
+// preloadKLines pushes the stored klines to the kline stream, the klines that end before the since time are skipped
+func (s *Strategy) preloadKLines(inc *indicator.KLineStream, session *bbgo.ExchangeSession, symbol string, interval types.Interval, since time.Time) {
 	if store, ok := session.MarketDataStore(symbol); ok {
 		if kLinesData, ok := store.KLinesOfInterval(interval); ok {
 			for _, k := range *kLinesData {
+				if !since.IsZero() && !k.EndTime.Time().After(since) {
+					continue
+				}
+
 				inc.EmitUpdate(k)
 			}
 		}
 	}
 }
 
+// restoreIndicator restores the indicator from the saved state, it returns the time to resume the kline preload
+func (s *Strategy) restoreIndicator(session *bbgo.ExchangeSession, interval types.Interval, name string, restore func(st *IndicatorState) error) time.Time {
+	since, ok := s.IndicatorState.canResume(session, s.Symbol, interval)
+	if !ok {
+		return time.Time{}
+	}
+
+	if err := restore(s.IndicatorState); err != nil {
+		log.WithError(err).Warnf("unable to restore the %s state, preloading all the klines", name)
+		return time.Time{}
+	}
+
+	log.Infof("%s is restored from the state at %s", name, since)
+	return since
+}
+
 func (s *Strategy) initializeMidPriceEMA(session *bbgo.ExchangeSession) {
-	kLines := indicator.KLines(session.MarketDataStream, s.Symbol, s.MidPriceEMA.Interval)
+	interval := s.MidPriceEMA.Interval
+	kLines := indicator.KLines(session.MarketDataStream, s.Symbol, interval)
 	s.ewma = indicator.EWMA2(indicator.ClosePrices(kLines), s.MidPriceEMA.Window)
+	s.trackKLineTime(kLines, interval)
+
+	since := s.restoreIndicator(session, interval, "mid price ema", func(st *IndicatorState) error {
+		if st.MidPriceEMA == nil {
+			return errors.New("the state is not saved")
+		}
+		return s.ewma.RestoreState(st.MidPriceEMA)
+	})
+
+	s.preloadKLines(kLines, session, s.Symbol, interval, since)
+}
+
+func (s *Strategy) initializeMidPriceKalman(session *bbgo.ExchangeSession) {
+	interval := s.MidPriceKalman.Interval
+	kLines := indicator.KLines(session.MarketDataStream, s.Symbol, interval)
+	s.kalman = indicator.KalmanFilter2(indicator.ClosePrices(kLines), s.MidPriceKalman.ProcessNoise, s.MidPriceKalman.MeasurementNoise)
+	s.trackKLineTime(kLines, interval)
+
+	since := s.restoreIndicator(session, interval, "mid price kalman filter", func(st *IndicatorState) error {
+		if st.MidPriceKalman == nil {
+			return errors.New("the state is not saved")
+		}
+		return s.kalman.RestoreState(st.MidPriceKalman)
+	})
 
-	s.preloadKLines(kLines, session, s.Symbol, s.MidPriceEMA.Interval)
+	s.preloadKLines(kLines, session, s.Symbol, interval, since)
 }
 
 func (s *Strategy) initializeIntensityIndicator(session *bbgo.ExchangeSession) {
-	kLines := indicator.KLines(session.MarketDataStream, s.Symbol, s.StrengthInterval)
+	interval := s.StrengthInterval
+	kLines := indicator.KLines(session.MarketDataStream, s.Symbol, interval)
 	s.intensity = Intensity(kLines, 10)
+	s.trackKLineTime(kLines, interval)
+
+	since := s.restoreIndicator(session, interval, "intensity", func(st *IndicatorState) error {
+		if st.Intensity == nil {
+			return errors.New("the state is not saved")
+		}
+		return s.intensity.RestoreState(st.Intensity)
+	})
 
-	s.preloadKLines(kLines, session, s.Symbol, s.StrengthInterval)
+	s.preloadKLines(kLines, session, s.Symbol, interval, since)
+}
+
+func (s *Strategy) initializeVolatilityIndicator(session *bbgo.ExchangeSession) {
+	interval := s.AdaptiveLayerSpacing.Interval
+	kLines := indicator.KLines(session.MarketDataStream, s.Symbol, interval)
+
+	switch s.AdaptiveLayerSpacing.Method {
+	case VolatilityMethodATR:
+		s.volatility = indicator.ATR2(kLines, s.AdaptiveLayerSpacing.Window).Float64Series
+	case VolatilityMethodRealized:
+		s.volatility = indicator.StdDev2(CloseChanges(kLines), s.AdaptiveLayerSpacing.Window).Float64Series
+	}
+
+	// the volatility indicator does not have the saved state, so the klines are fully preloaded
+	s.preloadKLines(kLines, session, s.Symbol, interval, time.Time{})
+}
+
+// layerTickSize returns the spacing of the liquidity layers
+func (s *Strategy) layerTickSize() fixedpoint.Value {
+	tickSize := fixedpoint.Max(s.LiquidityLayerTickSize, s.Market.TickSize)
+	if s.AdaptiveLayerSpacing == nil || s.volatility == nil || s.volatility.Length() < s.AdaptiveLayerSpacing.Window {
+		return tickSize
+	}
+
+	return fixedpoint.Max(s.AdaptiveLayerSpacing.tickSize(s.volatility.Last(0), tickSize), s.Market.TickSize)
 }
 
 func (s *Strategy) initializePriceRangeBollinger(session *bbgo.ExchangeSession) {
-	kLines := indicator.KLines(session.MarketDataStream, s.Symbol, s.PriceRangeBollinger.Interval)
+	interval := s.PriceRangeBollinger.Interval
+	kLines := indicator.KLines(session.MarketDataStream, s.Symbol, interval)
 	closePrices := indicator.ClosePrices(kLines)
 	s.boll = indicator.BOLL2(closePrices, s.PriceRangeBollinger.Window, s.PriceRangeBollinger.K)
+	s.trackKLineTime(kLines, interval)
 
-	s.preloadKLines(kLines, session, s.Symbol, s.PriceRangeBollinger.Interval)
+	since := s.restoreIndicator(session, interval, "price range bollinger", func(st *IndicatorState) error {
+		if st.PriceRangeBollinger == nil {
+			return errors.New("the state is not saved")
+		}
+		return s.boll.RestoreState(st.PriceRangeBollinger)
+	})
+
+	s.preloadKLines(kLines, session, s.Symbol, interval, since)
+}
+
+// smoothedMidPrice returns the mid price from the Kalman filter or the EMA
+func (s *Strategy) smoothedMidPrice() float64 {
+	if s.kalman != nil {
+		return s.kalman.Last(0)
+	}
+
+	return s.ewma.Last(0)
 }
 
+// placeAdjustmentOrders must be called with the controlMutex held
 func (s *Strategy) placeAdjustmentOrders(ctx context.Context) {
+	if s.status != types.StrategyStatusRunning {
+		return
+	}
+
 	_ = s.adjustmentOrderBook.GracefulCancel(ctx, s.session.Exchange)
 
+	if s.outsideTradingWindow {
+		return
+	}
+
 	if s.Position.IsDust() {
 		return
 	}
@@ -228,8 +705,10 @@ func (s *Strategy) placeAdjustmentOrders(ctx context.Context) {
 		return
 	}
 
-	baseBal, _ := s.session.Account.Balance(s.Market.BaseCurrency)
-	quoteBal, _ := s.session.Account.Balance(s.Market.QuoteCurrency)
+	// the funds reserved by the other scmaker instances on the same account are deducted,
+	// the other strategies don't reserve their funds
+	baseBal, _ := s.session.Account.AvailableBalance(s.InstanceID(), s.Market.BaseCurrency)
+	quoteBal, _ := s.session.Account.AvailableBalance(s.InstanceID(), s.Market.QuoteCurrency)
 
 	var adjOrders []types.SubmitOrder
 
@@ -281,36 +760,102 @@ func (s *Strategy) placeAdjustmentOrders(ctx context.Context) {
 	s.adjustmentOrderBook.Add(createdOrders...)
 }
 
+// placeLiquidityOrders must be called with the controlMutex held
 func (s *Strategy) placeLiquidityOrders(ctx context.Context) {
+	if s.status != types.StrategyStatusRunning {
+		return
+	}
+
+	s.decisionClock.Tick(time.Now())
+
+	// the depth is checked before canceling, so that the own orders can be excluded from the book
+	bidScale, askScale := 1.0, 1.0
+	if s.DepthGuard != nil {
+		var bidDepth, askDepth fixedpoint.Value
+		bidScale, askScale, bidDepth, askDepth = s.DepthGuard.scales(s.book.Copy(), s.liquidityOrderBook.Orders())
+		if bidScale < 1.0 || askScale < 1.0 {
+			log.Warnf("order book is thin, bid depth: %s ask depth: %s min depth: %s, liquidity scales: %f/%f",
+				bidDepth.String(), askDepth.String(), s.DepthGuard.MinDepth.String(), bidScale, askScale)
+		}
+	}
+
 	err := s.liquidityOrderBook.GracefulCancel(ctx, s.session.Exchange)
 	if logErr(err, "unable to cancel orders") {
 		return
 	}
 
+	if bidScale == 0 && askScale == 0 {
+		log.Infof("liquidity orders are skipped by the depth guard")
+		return
+	}
+
+	if s.isRegimePaused() {
+		log.Infof("liquidity orders are paused by the market regime")
+		return
+	}
+
+	if s.outsideTradingWindow {
+		return
+	}
+
 	ticker, err := s.session.Exchange.QueryTicker(ctx, s.Symbol)
 	if logErr(err, "unable to query ticker") {
 		return
 	}
 
+	// the orders are not placed without the reference price, the quoting venue might be stale
+	var referenceMidPrice fixedpoint.Value
+	if s.ReferencePrice != nil {
+		reference, deviated, err := s.checkReferencePrice(ctx, ticker)
+		if logErr(err, "unable to query the reference ticker") {
+			return
+		}
+
+		if deviated {
+			if s.ReferencePrice.Action == ReferencePriceActionPull {
+				log.Infof("liquidity orders are pulled by the reference price deviation")
+				return
+			}
+
+			referenceMidPrice = tickerMidPrice(reference)
+			ticker = recenterTicker(ticker, reference)
+		}
+	}
+
 	if _, err := s.session.UpdateAccount(ctx); err != nil {
 		logErr(err, "unable to update account")
 		return
 	}
 
-	baseBal, _ := s.session.Account.Balance(s.Market.BaseCurrency)
-	quoteBal, _ := s.session.Account.Balance(s.Market.QuoteCurrency)
+	// the funds reserved by the other scmaker instances on the same account are deducted,
+	// the other strategies don't reserve their funds
+	baseBal, _ := s.session.Account.AvailableBalance(s.InstanceID(), s.Market.BaseCurrency)
+	quoteBal, _ := s.session.Account.AvailableBalance(s.InstanceID(), s.Market.QuoteCurrency)
 
 	spread := ticker.Sell.Sub(ticker.Buy)
-	tickSize := fixedpoint.Max(s.LiquidityLayerTickSize, s.Market.TickSize)
+	tickSize := s.layerTickSize()
 
-	midPriceEMA := s.ewma.Last(0)
-	midPrice := fixedpoint.NewFromFloat(midPriceEMA)
+	smoothedMidPrice := s.smoothedMidPrice()
+	midPrice := fixedpoint.NewFromFloat(smoothedMidPrice)
+
+	if referenceMidPrice.Sign() > 0 {
+		midPrice = referenceMidPrice
+		log.Infof("re-centering the liquidity orders around the reference mid price: %f", midPrice.Float64())
+	} else if s.midPricePredictor != nil {
+		midPrice = s.midPricePredictor.Anchor(s.Environment.Clock().Now(), midPrice)
+		log.Infof("predicted mid price anchor: %f, %s", midPrice.Float64(), s.midPricePredictor.Stats().String())
+	}
 
 	bandWidth := s.boll.Last(0)
 
-	log.Infof("spread: %f mid price ema: %f boll band width: %f", spread.Float64(), midPriceEMA, bandWidth)
+	log.Infof("spread: %f smoothed mid price: %f boll band width: %f layer tick size: %f", spread.Float64(), smoothedMidPrice, bandWidth, tickSize.Float64())
 
+	// the tuned layer weights re-distribute the size between the layers, so the sum is adjusted by the weights
+	weights := s.layerWeights()
 	n := s.liquidityScale.Sum(1.0)
+	for i, w := range weights {
+		n += s.liquidityScale.Call(float64(i)) * (w - 1.0)
+	}
 
 	var bidPrices []fixedpoint.Value
 	var askPrices []fixedpoint.Value
@@ -360,7 +905,7 @@ func (s *Strategy) placeLiquidityOrders(ctx context.Context) {
 		}
 	}
 
-	makerQuota := &bbgo.QuotaTransaction{}
+	makerQuota := s.session.NewQuotaTransaction(s.InstanceID(), s.Market.BaseCurrency, s.Market.QuoteCurrency)
 	makerQuota.QuoteAsset.Add(availableQuote)
 	makerQuota.BaseAsset.Add(availableBase)
 
@@ -368,12 +913,17 @@ func (s *Strategy) placeLiquidityOrders(ctx context.Context) {
 		baseBal.String(),
 		quoteBal.String())
 
+	positionBase := s.Position.GetBase()
+	if s.PositionNetting != nil {
+		positionBase = s.PositionNetting.NettedBase(s.session.Name, s.Position)
+	}
+
 	if !s.Position.IsDust() {
-		if s.Position.IsLong() {
-			availableBase = availableBase.Sub(s.Position.Base)
+		if positionBase.Sign() > 0 {
+			availableBase = availableBase.Sub(positionBase)
 			availableBase = s.Market.RoundDownQuantityByPrecision(availableBase)
-		} else if s.Position.IsShort() {
-			posSizeInQuote := s.Position.Base.Mul(ticker.Sell)
+		} else if positionBase.Sign() < 0 {
+			posSizeInQuote := positionBase.Mul(ticker.Sell)
 			availableQuote = availableQuote.Sub(posSizeInQuote)
 		}
 	}
@@ -385,16 +935,18 @@ func (s *Strategy) placeLiquidityOrders(ctx context.Context) {
 	bidX = math.Trunc(bidX*1e8) / 1e8
 
 	var liqOrders []types.SubmitOrder
+	layers := make(map[string]int)
 	for i := 0; i <= s.NumOfLiquidityLayers; i++ {
-		bidQuantity := fixedpoint.NewFromFloat(s.liquidityScale.Call(float64(i)) * bidX)
-		askQuantity := fixedpoint.NewFromFloat(s.liquidityScale.Call(float64(i)) * askX)
+		layerScale := s.liquidityScale.Call(float64(i)) * weights[i]
+		bidQuantity := fixedpoint.NewFromFloat(layerScale * bidX * bidScale)
+		askQuantity := fixedpoint.NewFromFloat(layerScale * askX * askScale)
 		bidPrice := bidPrices[i]
 		askPrice := askPrices[i]
 
 		log.Infof("liqudity layer #%d %f/%f = %f/%f", i, askPrice.Float64(), bidPrice.Float64(), askQuantity.Float64(), bidQuantity.Float64())
 
-		placeBuy := true
-		placeSell := true
+		placeBuy := bidScale > 0
+		placeSell := askScale > 0
 		averageCost := s.Position.AverageCost
 		// when long position, do not place sell orders below the average cost
 		if !s.Position.IsDust() {
@@ -418,6 +970,7 @@ func (s *Strategy) placeLiquidityOrders(ctx context.Context) {
 		}
 
 		if placeBuy {
+			layers[layerOrderKey(types.SideTypeBuy, bidPrice)] = i
 			liqOrders = append(liqOrders, types.SubmitOrder{
 				Symbol:      s.Symbol,
 				Side:        types.SideTypeBuy,
@@ -430,6 +983,7 @@ func (s *Strategy) placeLiquidityOrders(ctx context.Context) {
 		}
 
 		if placeSell {
+			layers[layerOrderKey(types.SideTypeSell, askPrice)] = i
 			liqOrders = append(liqOrders, types.SubmitOrder{
 				Symbol:      s.Symbol,
 				Side:        types.SideTypeSell,
@@ -442,14 +996,53 @@ func (s *Strategy) placeLiquidityOrders(ctx context.Context) {
 		}
 	}
 
+	// reserve the funds until the orders are submitted,
+	// so that the other scmaker instances on the same account don't quote with the funds before the exchange locks them
+	if err := s.reserveFunds(makerQuota.BaseAsset.Locked, makerQuota.QuoteAsset.Locked); err != nil {
+		makerQuota.Rollback()
+		logErr(err, "unable to reserve the funds of the liquidity orders")
+		return
+	}
+
 	makerQuota.Commit()
 
 	createdOrders, err := s.orderExecutor.SubmitOrders(ctx, liqOrders...)
+	s.session.Account.ReleaseAll(s.InstanceID())
 	if logErr(err, "unable to place liquidity orders") {
 		return
 	}
 
 	s.liquidityOrderBook.Add(createdOrders...)
+
+	// the layer of the created order is matched by the side and the price, the repriced post-only orders are not tracked
+	now := s.Environment.Clock().Now()
+	tickerMidPrice := ticker.Buy.Add(ticker.Sell).Div(fixedpoint.Two)
+	for _, order := range createdOrders {
+		if layer, ok := layers[layerOrderKey(order.Side, order.Price)]; ok {
+			s.LayerStats.AddOrder(order, layer, tickerMidPrice, now)
+		}
+	}
+
+	// keep the orders until the mark-outs of the fills are recorded
+	s.LayerStats.Prune(now.Add(-2*s.LiquidityUpdateInterval.Duration() - s.LayerStats.MarkOutHorizon))
+}
+
+func (s *Strategy) reserveFunds(base, quote fixedpoint.Value) error {
+	account := s.session.Account
+	if err := account.Reserve(s.InstanceID(), s.Market.BaseCurrency, base); err != nil {
+		return err
+	}
+
+	if err := account.Reserve(s.InstanceID(), s.Market.QuoteCurrency, quote); err != nil {
+		account.ReleaseAll(s.InstanceID())
+		return err
+	}
+
+	return nil
+}
+
+func layerOrderKey(side types.SideType, price fixedpoint.Value) string {
+	return string(side) + "@" + price.String()
 }
 
 func profitProtectedPrice(side types.SideType, averageCost, price, feeRate, minProfit fixedpoint.Value) fixedpoint.Value {
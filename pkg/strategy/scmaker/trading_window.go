@@ -0,0 +1,114 @@
+package scmaker
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// TradingWindow is the UTC time window to quote, the window is the whole day when start and end are both empty.
+// The window crosses midnight when the end is before the start, e.g., start "22:00" and end "02:00",
+// and the weekdays are the weekdays of the window start.
+//
+//	tradingWindows:
+//	- weekdays: [ mon, tue, wed, thu, fri ]
+//	  start: "01:00"
+//	  end: "23:00"
+type TradingWindow struct {
+	// Weekdays are the weekdays of the window, e.g., mon, tue, every day when it's empty
+	Weekdays []string `json:"weekdays,omitempty"`
+
+	// Start and End are the time of the day in HH:MM (UTC)
+	Start string `json:"start,omitempty"`
+	End   string `json:"end,omitempty"`
+
+	weekdays   map[time.Weekday]struct{}
+	start, end time.Duration
+}
+
+func (w *TradingWindow) Validate() error {
+	w.weekdays = nil
+	for _, name := range w.Weekdays {
+		// both of the short names and the full names are accepted, e.g., mon and monday
+		key := strings.ToLower(name)
+		if len(key) > 3 {
+			key = key[:3]
+		}
+
+		weekday, ok := weekdayNames[key]
+		if !ok {
+			return fmt.Errorf("tradingWindows: invalid weekday %q", name)
+		}
+
+		if w.weekdays == nil {
+			w.weekdays = make(map[time.Weekday]struct{})
+		}
+
+		w.weekdays[weekday] = struct{}{}
+	}
+
+	var err error
+	if w.start, err = parseTimeOfDay(w.Start); err != nil {
+		return err
+	}
+
+	if w.end, err = parseTimeOfDay(w.End); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Contains returns true if the time is in the window
+func (w *TradingWindow) Contains(t time.Time) bool {
+	t = t.UTC()
+	tod := t.Sub(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC))
+
+	switch {
+	case w.start == w.end:
+		return w.onWeekday(t.Weekday())
+
+	case w.start < w.end:
+		return w.onWeekday(t.Weekday()) && tod >= w.start && tod < w.end
+
+	default:
+		// the window crosses midnight, the time after midnight belongs to the window started yesterday
+		if tod >= w.start {
+			return w.onWeekday(t.Weekday())
+		}
+
+		return tod < w.end && w.onWeekday((t.Weekday()+6)%7)
+	}
+}
+
+func (w *TradingWindow) onWeekday(weekday time.Weekday) bool {
+	if len(w.weekdays) == 0 {
+		return true
+	}
+
+	_, ok := w.weekdays[weekday]
+	return ok
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("tradingWindows: invalid time %q, the time should be in HH:MM", s)
+	}
+
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,60 @@
+package scmaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTradingWindow_Contains(t *testing.T) {
+	// 2023-06-02 is Friday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2023, 6, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	t.Run("weekdays", func(t *testing.T) {
+		w := &TradingWindow{Weekdays: []string{"mon", "Tuesday", "wed", "thu", "fri"}, Start: "01:00", End: "23:00"}
+		if assert.NoError(t, w.Validate()) {
+			assert.True(t, w.Contains(at(2, 1, 0)))
+			assert.True(t, w.Contains(at(2, 22, 59)))
+			assert.False(t, w.Contains(at(2, 23, 0)))
+			assert.False(t, w.Contains(at(2, 0, 30)))
+			assert.False(t, w.Contains(at(3, 12, 0)), "saturday")
+		}
+	})
+
+	t.Run("crossing midnight", func(t *testing.T) {
+		w := &TradingWindow{Weekdays: []string{"fri"}, Start: "22:00", End: "02:00"}
+		if assert.NoError(t, w.Validate()) {
+			assert.True(t, w.Contains(at(2, 23, 0)))
+			assert.True(t, w.Contains(at(3, 1, 59)), "saturday morning belongs to the friday window")
+			assert.False(t, w.Contains(at(3, 2, 0)))
+			assert.False(t, w.Contains(at(2, 1, 0)), "friday morning belongs to the thursday window")
+		}
+	})
+
+	t.Run("whole day", func(t *testing.T) {
+		w := &TradingWindow{Weekdays: []string{"sat", "sun"}}
+		if assert.NoError(t, w.Validate()) {
+			assert.True(t, w.Contains(at(3, 0, 0)))
+			assert.True(t, w.Contains(at(4, 23, 59)))
+			assert.False(t, w.Contains(at(5, 0, 0)))
+		}
+	})
+
+	t.Run("the time is converted to utc", func(t *testing.T) {
+		w := &TradingWindow{Start: "00:00", End: "08:00"}
+		if assert.NoError(t, w.Validate()) {
+			taipei := time.FixedZone("Asia/Taipei", 8*3600)
+			assert.True(t, w.Contains(time.Date(2023, 6, 2, 15, 0, 0, 0, taipei)))
+			assert.False(t, w.Contains(time.Date(2023, 6, 2, 17, 0, 0, 0, taipei)))
+		}
+	})
+}
+
+func TestTradingWindow_Validate(t *testing.T) {
+	assert.Error(t, (&TradingWindow{Weekdays: []string{"someday"}}).Validate())
+	assert.Error(t, (&TradingWindow{Start: "25:00", End: "01:00"}).Validate())
+	assert.Error(t, (&TradingWindow{Start: "1am"}).Validate())
+}
@@ -0,0 +1,95 @@
+package scmaker
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+type VolatilityMethod string
+
+const (
+	// VolatilityMethodATR uses the average true range of the klines
+	VolatilityMethodATR VolatilityMethod = "atr"
+
+	// VolatilityMethodRealized uses the standard deviation of the close price changes
+	VolatilityMethodRealized VolatilityMethod = "realized"
+)
+
+// AdaptiveLayerSpacingConfig scales the liquidity layer spacing with the rolling volatility estimate,
+// the spacing is the volatility times the multiplier, clamped by the min and the max tick size.
+// The fixed liquidityLayerTickSize is used before the volatility indicator is ready.
+type AdaptiveLayerSpacingConfig struct {
+	Method VolatilityMethod `json:"method"`
+
+	types.IntervalWindow
+
+	Multiplier fixedpoint.Value `json:"multiplier"`
+
+	// MinTickSize is the min layer spacing, defaults to liquidityLayerTickSize
+	MinTickSize fixedpoint.Value `json:"minTickSize,omitempty"`
+
+	// MaxTickSize is the max layer spacing, the spacing is not capped when it's zero
+	MaxTickSize fixedpoint.Value `json:"maxTickSize,omitempty"`
+}
+
+func (c *AdaptiveLayerSpacingConfig) Validate() error {
+	switch c.Method {
+	case VolatilityMethodATR, VolatilityMethodRealized:
+	default:
+		return fmt.Errorf("adaptiveLayerSpacing: invalid method %q, valid methods are: %s, %s", c.Method, VolatilityMethodATR, VolatilityMethodRealized)
+	}
+
+	if c.Window <= 0 {
+		return errors.New("adaptiveLayerSpacing: window should be greater than zero")
+	}
+
+	if c.Multiplier.Sign() <= 0 {
+		return errors.New("adaptiveLayerSpacing: multiplier should be greater than zero")
+	}
+
+	if c.MaxTickSize.Sign() > 0 && c.MaxTickSize.Compare(c.MinTickSize) < 0 {
+		return errors.New("adaptiveLayerSpacing: maxTickSize should be greater than minTickSize")
+	}
+
+	return nil
+}
+
+// tickSize returns the layer spacing of the volatility, the fallback tick size is returned if the volatility is not available
+func (c *AdaptiveLayerSpacingConfig) tickSize(volatility float64, fallback fixedpoint.Value) fixedpoint.Value {
+	if volatility <= 0 || math.IsNaN(volatility) || math.IsInf(volatility, 0) {
+		return fallback
+	}
+
+	minTickSize := c.MinTickSize
+	if minTickSize.IsZero() {
+		minTickSize = fallback
+	}
+
+	tickSize := fixedpoint.Max(fixedpoint.NewFromFloat(volatility).Mul(c.Multiplier), minTickSize)
+	if c.MaxTickSize.Sign() > 0 {
+		tickSize = fixedpoint.Min(tickSize, c.MaxTickSize)
+	}
+
+	return tickSize
+}
+
+// CloseChanges pushes the close price change of each kline from the previous kline
+func CloseChanges(source indicator.KLineSubscription) *indicator.Float64Series {
+	s := indicator.NewFloat64Series()
+
+	var lastClose fixedpoint.Value
+	source.AddSubscriber(func(k types.KLine) {
+		if !lastClose.IsZero() {
+			s.PushAndEmit(k.Close.Sub(lastClose).Float64())
+		}
+
+		lastClose = k.Close
+	})
+
+	return s
+}
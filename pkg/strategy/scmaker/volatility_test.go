@@ -0,0 +1,47 @@
+package scmaker
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/indicator"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestAdaptiveLayerSpacingConfig_tickSize(t *testing.T) {
+	c := &AdaptiveLayerSpacingConfig{
+		Method:         VolatilityMethodATR,
+		IntervalWindow: types.IntervalWindow{Interval: types.Interval1m, Window: 14},
+		Multiplier:     fixedpoint.NewFromFloat(0.5),
+		MaxTickSize:    fixedpoint.MustNewFromString("0.001"),
+	}
+	assert.NoError(t, c.Validate())
+
+	fallback := fixedpoint.MustNewFromString("0.0001")
+	assert.InDelta(t, 0.0002, c.tickSize(0.0004, fallback).Float64(), 1e-12)
+	assert.Equal(t, "0.0001", c.tickSize(0.0001, fallback).String(), "clamped by the fallback min tick size")
+	assert.Equal(t, "0.001", c.tickSize(0.01, fallback).String(), "clamped by the max tick size")
+	assert.Equal(t, "0.0001", c.tickSize(0, fallback).String())
+
+	c.MinTickSize = fixedpoint.MustNewFromString("0.0003")
+	assert.Equal(t, "0.0003", c.tickSize(0.0004, fallback).String())
+
+	c.Method = "garch"
+	assert.Error(t, c.Validate())
+}
+
+func TestCloseChanges(t *testing.T) {
+	kLines := &indicator.KLineStream{}
+	changes := CloseChanges(kLines)
+
+	for _, price := range []float64{1.0, 1.002, 0.999} {
+		kLines.EmitUpdate(types.KLine{Close: fixedpoint.NewFromFloat(price)})
+	}
+
+	if assert.Equal(t, 2, changes.Length()) {
+		assert.InDelta(t, 0.002, changes.Last(1), 1e-9)
+		assert.InDelta(t, -0.003, changes.Last(0), 1e-9)
+	}
+}
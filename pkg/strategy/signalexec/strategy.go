@@ -0,0 +1,216 @@
+package signalexec
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const ID = "signalexec"
+
+var log = logrus.WithField("strategy", ID)
+
+// signalQueueSize is the number of the pending signals, the signals are dropped when the queue is full
+const signalQueueSize = 64
+
+func init() {
+	bbgo.RegisterStrategy(ID, &Strategy{})
+}
+
+// Strategy executes the external signals received by the webhook (e.g., the TradingView alerts) with the market orders,
+// the buy and sell signals open or add the position, and the close signals close the position.
+type Strategy struct {
+	Environment *bbgo.Environment
+	Symbol      string `json:"symbol"`
+	Market      types.Market
+
+	// Signal filters the signals by the signal name, all the signals of the symbol are executed when it's empty
+	Signal string `json:"signal,omitempty"`
+
+	// Quantity is the base quantity of each order
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// UseSignalQuantity uses the quantity of the signal instead of the configured quantity when the signal carries the quantity
+	UseSignalQuantity bool `json:"useSignalQuantity,omitempty"`
+
+	// MaxPosition is the max absolute base position, the order quantity is reduced to fit the position in the limit
+	MaxPosition fixedpoint.Value `json:"maxPosition"`
+
+	// WebhookSignals is injected when the webhook signals are enabled
+	WebhookSignals *bbgo.WebhookSignalService `json:"-"`
+
+	Position    *types.Position    `persistence:"position"`
+	ProfitStats *types.ProfitStats `persistence:"profit_stats"`
+	TradeStats  *types.TradeStats  `persistence:"trade_stats"`
+
+	orderExecutor *bbgo.GeneralOrderExecutor
+
+	signalC chan bbgo.SignalEvent
+
+	bbgo.StrategyController
+}
+
+func (s *Strategy) ID() string {
+	return ID
+}
+
+func (s *Strategy) InstanceID() string {
+	if s.Signal != "" {
+		return fmt.Sprintf("%s:%s:%s", ID, s.Symbol, s.Signal)
+	}
+
+	return fmt.Sprintf("%s:%s", ID, s.Symbol)
+}
+
+func (s *Strategy) Validate() error {
+	if len(s.Symbol) == 0 {
+		return errors.New("symbol is required")
+	}
+
+	if s.Quantity.Sign() <= 0 {
+		return errors.New("quantity should be greater than zero")
+	}
+
+	if s.MaxPosition.Sign() <= 0 {
+		return errors.New("maxPosition should be greater than zero")
+	}
+
+	return nil
+}
+
+func (s *Strategy) Subscribe(session *bbgo.ExchangeSession) {}
+
+func (s *Strategy) Run(ctx context.Context, _ bbgo.OrderExecutor, session *bbgo.ExchangeSession) error {
+	if s.WebhookSignals == nil {
+		return errors.New("webhookSignals is not configured")
+	}
+
+	if s.Position == nil {
+		s.Position = types.NewPositionFromMarket(s.Market)
+	}
+
+	if s.ProfitStats == nil {
+		s.ProfitStats = types.NewProfitStats(s.Market)
+	}
+
+	if s.TradeStats == nil {
+		s.TradeStats = types.NewTradeStats(s.Symbol)
+	}
+
+	s.Status = types.StrategyStatusRunning
+	s.OnEmergencyStop(func() {
+		_ = s.orderExecutor.ClosePosition(ctx, fixedpoint.One, "emergencyStop")
+	})
+
+	s.orderExecutor = bbgo.NewGeneralOrderExecutor(session, s.Symbol, ID, s.InstanceID(), s.Position)
+	s.orderExecutor.BindEnvironment(s.Environment)
+	s.orderExecutor.BindProfitStats(s.ProfitStats)
+	s.orderExecutor.BindTradeStats(s.TradeStats)
+	s.orderExecutor.TradeCollector().OnPositionUpdate(func(position *types.Position) {
+		bbgo.Sync(ctx, s)
+	})
+	s.orderExecutor.Bind()
+
+	// the signals are queued and executed in order, so that the webhook request is not blocked by the order submission
+	s.signalC = make(chan bbgo.SignalEvent, signalQueueSize)
+	s.WebhookSignals.Subscribe(s.InstanceID(), bbgo.SignalFilter{Symbol: s.Symbol, Name: s.Signal}, func(e bbgo.SignalEvent) {
+		select {
+		case s.signalC <- e:
+		default:
+			log.Warnf("the signal queue is full, dropping %s", e.String())
+		}
+	})
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case e := <-s.signalC:
+				s.execute(ctx, e)
+			}
+		}
+	}()
+
+	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
+		defer wg.Done()
+		bbgo.Sync(ctx, s)
+	})
+
+	return nil
+}
+
+func (s *Strategy) execute(ctx context.Context, e bbgo.SignalEvent) {
+	if s.GetStatus() != types.StrategyStatusRunning {
+		log.Infof("strategy is not running, skipping %s", e.String())
+		return
+	}
+
+	if e.Action == bbgo.SignalActionClose {
+		bbgo.Notify("%s: closing the position by %s", s.InstanceID(), e.String())
+		if err := s.orderExecutor.ClosePosition(ctx, fixedpoint.One, "signal"); err != nil {
+			log.WithError(err).Errorf("unable to close the position")
+		}
+		return
+	}
+
+	side := types.SideTypeBuy
+	if e.Action == bbgo.SignalActionSell {
+		side = types.SideTypeSell
+	}
+
+	price, ok := s.orderExecutor.Session().LastPrice(s.Symbol)
+	if !ok || price.IsZero() {
+		price = e.Price
+	}
+
+	quantity := s.allowedQuantity(side, s.signalQuantity(e))
+	if quantity.Sign() <= 0 || (price.Sign() > 0 && s.Market.IsDustQuantity(quantity, price)) {
+		log.Infof("the position of %s reaches the limit %s, skipping %s", s.Symbol, s.MaxPosition.String(), e.String())
+		return
+	}
+
+	submitOrder := types.SubmitOrder{
+		Symbol:   s.Symbol,
+		Side:     side,
+		Type:     types.OrderTypeMarket,
+		Quantity: quantity,
+		Market:   s.Market,
+		Tag:      "signal",
+	}
+
+	bbgo.Notify("%s: executing %s: %s", s.InstanceID(), e.String(), submitOrder.String())
+	if _, err := s.orderExecutor.SubmitOrders(ctx, submitOrder); err != nil {
+		log.WithError(err).Errorf("unable to submit the signal order")
+	}
+}
+
+func (s *Strategy) signalQuantity(e bbgo.SignalEvent) fixedpoint.Value {
+	if s.UseSignalQuantity && e.Quantity.Sign() > 0 {
+		return e.Quantity
+	}
+
+	return s.Quantity
+}
+
+// allowedQuantity reduces the quantity to keep the absolute position within the max position
+func (s *Strategy) allowedQuantity(side types.SideType, quantity fixedpoint.Value) fixedpoint.Value {
+	base := s.Position.GetBase()
+
+	var room fixedpoint.Value
+	if side == types.SideTypeBuy {
+		room = s.MaxPosition.Sub(base)
+	} else {
+		room = s.MaxPosition.Add(base)
+	}
+
+	return s.Market.TruncateQuantity(fixedpoint.Min(quantity, room))
+}
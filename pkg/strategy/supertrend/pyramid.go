@@ -0,0 +1,97 @@
+package supertrend
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+// PyramidSettings scales in the position on the consecutive signals of the same direction
+//
+//	pyramid:
+//	  maxCount: 3
+//	  scaleRatio: 0.5
+type PyramidSettings struct {
+	// MaxCount is the max number of the tranches of a position, including the first entry
+	MaxCount int `json:"maxCount"`
+
+	// ScaleRatio is the quantity ratio of the scaling-in tranche to the previous tranche,
+	// the quantity is calculated by the leverage or the fixed quantity if it's zero
+	ScaleRatio fixedpoint.Value `json:"scaleRatio"`
+}
+
+func (p *PyramidSettings) Validate() error {
+	if p.MaxCount < 1 {
+		return fmt.Errorf("pyramid: maxCount should be greater than 0")
+	}
+
+	if p.ScaleRatio.Sign() < 0 {
+		return fmt.Errorf("pyramid: scaleRatio can not be negative")
+	}
+
+	return nil
+}
+
+// scaleInQuantity returns the quantity of the next tranche
+func (p *PyramidSettings) scaleInQuantity(tranches []types.PositionTranche, quantity fixedpoint.Value) fixedpoint.Value {
+	if p.ScaleRatio.IsZero() || len(tranches) == 0 {
+		return quantity
+	}
+
+	return tranches[len(tranches)-1].InitialQuantity.Mul(p.ScaleRatio)
+}
+
+// TakeProfitStep closes the ratio of the tranche when the profit reaches the R multiple,
+// where 1R is the distance between the entry price and the stop price of the tranche.
+type TakeProfitStep struct {
+	RMultiple fixedpoint.Value `json:"r"`
+
+	// Ratio is the ratio of the initial quantity of the tranche to close
+	Ratio fixedpoint.Value `json:"ratio"`
+}
+
+// TakeProfitLadder is the partial take-profit steps, e.g., 50% at 1R and 25% at 2R
+//
+//	takeProfitLadder:
+//	- r: 1.0
+//	  ratio: 50%
+//	- r: 2.0
+//	  ratio: 25%
+type TakeProfitLadder []TakeProfitStep
+
+func (l TakeProfitLadder) Validate() error {
+	totalRatio := fixedpoint.Zero
+	for i, step := range l {
+		if step.RMultiple.Sign() <= 0 || step.Ratio.Sign() <= 0 {
+			return fmt.Errorf("takeProfitLadder: r and ratio of step #%d should be positive", i)
+		}
+
+		if i > 0 && step.RMultiple.Compare(l[i-1].RMultiple) <= 0 {
+			return fmt.Errorf("takeProfitLadder: r of step #%d should be greater than the previous step", i)
+		}
+
+		totalRatio = totalRatio.Add(step.Ratio)
+	}
+
+	if totalRatio.Compare(fixedpoint.One) > 0 {
+		return fmt.Errorf("takeProfitLadder: the total ratio %s is greater than 100%%", totalRatio.Percentage())
+	}
+
+	return nil
+}
+
+// takeProfit returns the quantity to close of the tranche at the current price and the number of the executed steps after closing
+func (l TakeProfitLadder) takeProfit(tranche types.PositionTranche, currentPrice fixedpoint.Value, long bool) (quantity fixedpoint.Value, steps int) {
+	steps = tranche.TakeProfitSteps
+	r := tranche.RMultiple(currentPrice, long)
+	if r.Sign() <= 0 {
+		return fixedpoint.Zero, steps
+	}
+
+	for ; steps < len(l) && r.Compare(l[steps].RMultiple) >= 0; steps++ {
+		quantity = quantity.Add(tranche.InitialQuantity.Mul(l[steps].Ratio))
+	}
+
+	return fixedpoint.Min(quantity, tranche.Quantity), steps
+}
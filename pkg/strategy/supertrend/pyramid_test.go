@@ -0,0 +1,91 @@
+package supertrend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestTakeProfitLadder_Validate(t *testing.T) {
+	ladder := TakeProfitLadder{
+		{RMultiple: fixedpoint.One, Ratio: fixedpoint.NewFromFloat(0.5)},
+		{RMultiple: fixedpoint.NewFromInt(2), Ratio: fixedpoint.NewFromFloat(0.25)},
+	}
+	assert.NoError(t, ladder.Validate())
+	assert.NoError(t, TakeProfitLadder(nil).Validate())
+
+	ladder[1].RMultiple = fixedpoint.One
+	assert.Error(t, ladder.Validate())
+
+	ladder[1].RMultiple = fixedpoint.NewFromInt(2)
+	ladder[1].Ratio = fixedpoint.NewFromFloat(0.6)
+	assert.Error(t, ladder.Validate())
+}
+
+func TestTakeProfitLadder_takeProfit(t *testing.T) {
+	ladder := TakeProfitLadder{
+		{RMultiple: fixedpoint.One, Ratio: fixedpoint.NewFromFloat(0.5)},
+		{RMultiple: fixedpoint.NewFromInt(2), Ratio: fixedpoint.NewFromFloat(0.25)},
+	}
+
+	tranche := types.PositionTranche{
+		Price:           fixedpoint.NewFromInt(100),
+		Quantity:        fixedpoint.NewFromInt(4),
+		InitialQuantity: fixedpoint.NewFromInt(4),
+		StopPrice:       fixedpoint.NewFromInt(90),
+	}
+
+	t.Run("below 1R", func(t *testing.T) {
+		quantity, steps := ladder.takeProfit(tranche, fixedpoint.NewFromInt(105), true)
+		assert.Equal(t, fixedpoint.Zero, quantity)
+		assert.Equal(t, 0, steps)
+	})
+
+	t.Run("1R", func(t *testing.T) {
+		quantity, steps := ladder.takeProfit(tranche, fixedpoint.NewFromInt(110), true)
+		assert.Equal(t, fixedpoint.NewFromInt(2), quantity)
+		assert.Equal(t, 1, steps)
+	})
+
+	t.Run("jump to 2R", func(t *testing.T) {
+		quantity, steps := ladder.takeProfit(tranche, fixedpoint.NewFromInt(125), true)
+		assert.Equal(t, fixedpoint.NewFromInt(3), quantity)
+		assert.Equal(t, 2, steps)
+	})
+
+	t.Run("executed step", func(t *testing.T) {
+		tranche := tranche
+		tranche.Quantity = fixedpoint.NewFromInt(2)
+		tranche.TakeProfitSteps = 1
+
+		quantity, steps := ladder.takeProfit(tranche, fixedpoint.NewFromInt(115), true)
+		assert.Equal(t, fixedpoint.Zero, quantity)
+		assert.Equal(t, 1, steps)
+
+		quantity, steps = ladder.takeProfit(tranche, fixedpoint.NewFromInt(120), true)
+		assert.Equal(t, fixedpoint.One, quantity)
+		assert.Equal(t, 2, steps)
+	})
+
+	t.Run("short", func(t *testing.T) {
+		tranche := tranche
+		tranche.StopPrice = fixedpoint.NewFromInt(110)
+
+		quantity, steps := ladder.takeProfit(tranche, fixedpoint.NewFromInt(90), false)
+		assert.Equal(t, fixedpoint.NewFromInt(2), quantity)
+		assert.Equal(t, 1, steps)
+	})
+}
+
+func TestPyramidSettings_scaleInQuantity(t *testing.T) {
+	tranches := []types.PositionTranche{{InitialQuantity: fixedpoint.NewFromInt(2)}}
+
+	pyramid := &PyramidSettings{MaxCount: 3, ScaleRatio: fixedpoint.NewFromFloat(0.5)}
+	assert.Equal(t, fixedpoint.One, pyramid.scaleInQuantity(tranches, fixedpoint.NewFromInt(10)))
+
+	pyramid.ScaleRatio = fixedpoint.Zero
+	assert.Equal(t, fixedpoint.NewFromInt(10), pyramid.scaleInQuantity(tranches, fixedpoint.NewFromInt(10)))
+}
@@ -83,6 +83,19 @@ type Strategy struct {
 	// ExitMethods Exit methods
 	ExitMethods bbgo.ExitMethodSet `json:"exits"`
 
+	// Pyramid scales in the position on the consecutive signals of the same direction
+	Pyramid *PyramidSettings `json:"pyramid,omitempty"`
+
+	// TakeProfitLadder closes each tranche of the position partially at the R multiples
+	TakeProfitLadder TakeProfitLadder `json:"takeProfitLadder,omitempty"`
+
+	// RegimeTopic publishes the market regime (trending, mean-reverting or chop) classified by the Hurst exponent
+	// of the strategy interval window to the message bus topic
+	RegimeTopic string `json:"regimeTopic,omitempty"`
+
+	// MessageBus is injected when the message bus is configured
+	MessageBus *bbgo.MessageBus `json:"-"`
+
 	// whether to draw graph or not by the end of backtest
 	DrawGraph       bool   `json:"drawGraph"`
 	GraphPNLPath    string `json:"graphPNLPath"`
@@ -123,6 +136,16 @@ func (s *Strategy) Validate() error {
 		return errors.New("interval is required")
 	}
 
+	if s.Pyramid != nil {
+		if err := s.Pyramid.Validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := s.TakeProfitLadder.Validate(); err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -169,6 +192,67 @@ func (s *Strategy) ClosePosition(ctx context.Context, percentage fixedpoint.Valu
 	return err
 }
 
+// trackTranches returns true if the tranches of the position are recorded for the pyramid or the take-profit ladder
+func (s *Strategy) trackTranches() bool {
+	return s.Pyramid != nil || len(s.TakeProfitLadder) > 0
+}
+
+// trancheStopPrice returns the stop price of the new tranche, which is the stop loss price of the triggering kline if it's set,
+// or the supertrend line
+func (s *Strategy) trancheStopPrice() fixedpoint.Value {
+	if !s.currentStopLossPrice.IsZero() {
+		return s.currentStopLossPrice
+	}
+
+	return fixedpoint.NewFromFloat(s.Supertrend.Last(0))
+}
+
+// takeProfitByLadder closes the tranches partially when the price reaches the steps of the take-profit ladder
+func (s *Strategy) takeProfitByLadder(ctx context.Context, currentPrice fixedpoint.Value) {
+	base := s.Position.GetBase()
+	if s.Market.IsDustQuantity(base.Abs(), currentPrice) {
+		return
+	}
+
+	long := base.Sign() > 0
+	tranches := s.Position.GetTranches()
+	quantities := make([]fixedpoint.Value, len(tranches))
+	steps := make([]int, len(tranches))
+	quantity := fixedpoint.Zero
+	for i, tranche := range tranches {
+		quantities[i], steps[i] = s.TakeProfitLadder.takeProfit(tranche, currentPrice, long)
+		quantity = quantity.Add(quantities[i])
+	}
+
+	// the tranches could be more than the position if the position is reduced by the other exit methods
+	quantity = fixedpoint.Min(quantity, base.Abs())
+	if quantity.Compare(s.Market.MinQuantity) < 0 {
+		return
+	}
+
+	side := types.SideTypeBuy
+	if long {
+		side = types.SideTypeSell
+	}
+
+	orderForm := s.generateOrderForm(side, quantity, types.SideEffectTypeAutoRepay)
+	bbgo.Notify("%s take profit %f by the ladder at price %f", s.Symbol, quantity.Float64(), currentPrice.Float64(), orderForm)
+	if _, err := s.orderExecutor.SubmitOrders(ctx, orderForm); err != nil {
+		log.WithError(err).Errorf("can not place %s take profit order", s.Symbol)
+		return
+	}
+
+	for i, tranche := range tranches {
+		if steps[i] == tranche.TakeProfitSteps {
+			continue
+		}
+
+		tranche.Quantity = tranche.Quantity.Sub(quantities[i])
+		tranche.TakeProfitSteps = steps[i]
+		s.Position.UpdateTranche(i, tranche)
+	}
+}
+
 // setupIndicators initializes indicators
 func (s *Strategy) setupIndicators() {
 	// K-line store for indicators
@@ -206,6 +290,34 @@ func (s *Strategy) setupIndicators() {
 	}
 }
 
+// publishRegime publishes the market regime to the message bus when the regime is changed
+func (s *Strategy) publishRegime(session *bbgo.ExchangeSession) error {
+	if s.MessageBus == nil {
+		return errors.New("regimeTopic: messageBus is not configured")
+	}
+
+	kLines := indicator.KLines(session.MarketDataStream, s.Symbol, s.Interval)
+	regime := indicator.RegimeOf(indicator.Hurst(indicator.ClosePrices(kLines), s.Window, 0), 0, 0)
+
+	// the first regime is published as well, so that the consumers don't need to wait for the regime change
+	var published bool
+	var last indicator.Regime
+	regime.OnUpdate(func(v float64) {
+		current := indicator.Regime(v)
+		if published && current == last {
+			return
+		}
+
+		published, last = true, current
+		log.Infof("market regime: %s", current)
+		if err := s.MessageBus.Publish(s.InstanceID(), s.RegimeTopic, current); err != nil {
+			log.WithError(err).Errorf("unable to publish the market regime")
+		}
+	})
+
+	return nil
+}
+
 func (s *Strategy) shouldStop(kline types.KLine, stSignal types.Direction, demaSignal types.Direction, lgSignal types.Direction) bool {
 	stopNow := false
 	base := s.Position.GetBase()
@@ -411,6 +523,10 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 
 	// Sync position to redis on trade
 	s.orderExecutor.TradeCollector().OnPositionUpdate(func(position *types.Position) {
+		if position.IsDust() {
+			position.ResetTranches()
+		}
+
 		bbgo.Sync(ctx, s)
 	})
 
@@ -429,6 +545,12 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 	// Setup indicators
 	s.setupIndicators()
 
+	if s.RegimeTopic != "" {
+		if err := s.publishRegime(session); err != nil {
+			return err
+		}
+	}
+
 	// Exit methods
 	for _, method := range s.ExitMethods {
 		method.Bind(session, s.orderExecutor)
@@ -465,6 +587,11 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 			}
 		}
 
+		// Partial TP by the take-profit ladder
+		if len(s.TakeProfitLadder) > 0 {
+			s.takeProfitByLadder(ctx, closePrice)
+		}
+
 		// Get order side
 		side := s.getSide(stSignal, demaSignal, lgSignal)
 		// Set TP/SL price if needed
@@ -491,19 +618,40 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 
 			amount := s.calculateQuantity(ctx, closePrice, side)
 
+			// trancheQuantity is the quantity of the new position, excluding the opposite position amount
+			trancheQuantity := amount
+
 			// Add opposite position amount if any
 			if (side == types.SideTypeSell && s.Position.IsLong()) || (side == types.SideTypeBuy && s.Position.IsShort()) {
+				s.Position.ResetTranches()
 				if bbgo.IsBackTesting {
 					_ = s.ClosePosition(ctx, fixedpoint.One)
 					bbgo.Notify("close existing %s position before open a new position", s.Symbol)
 					amount = s.calculateQuantity(ctx, closePrice, side)
+					trancheQuantity = amount
 				} else {
 					bbgo.Notify("add existing opposite position amount %f of %s to the amount %f of open new position order", s.Position.GetQuantity().Float64(), s.Symbol, amount.Float64())
 					amount = amount.Add(s.Position.GetQuantity())
 				}
 			} else if !s.Position.IsDust(closePrice) {
-				bbgo.Notify("existing %s position has the same direction as the signal", s.Symbol)
-				return
+				tranches := s.Position.GetTranches()
+
+				// the position opened without the tranche records counts as one tranche
+				count := len(tranches)
+				if count == 0 {
+					count = 1
+				}
+
+				if s.Pyramid == nil || count >= s.Pyramid.MaxCount {
+					bbgo.Notify("existing %s position has the same direction as the signal", s.Symbol)
+					return
+				}
+
+				amount = s.Pyramid.scaleInQuantity(tranches, amount)
+				trancheQuantity = amount
+				bbgo.Notify("scale in %s position with the tranche #%d, quantity %f", s.Symbol, count+1, amount.Float64())
+			} else {
+				s.Position.ResetTranches()
 			}
 
 			orderForm := s.generateOrderForm(side, amount, types.SideEffectTypeMarginBuy)
@@ -512,6 +660,17 @@ func (s *Strategy) Run(ctx context.Context, orderExecutor bbgo.OrderExecutor, se
 			if err != nil {
 				log.WithError(err).Errorf("can not place %s open position order", s.Symbol)
 				bbgo.Notify("can not place %s open position order", s.Symbol)
+				return
+			}
+
+			// the entry price of the market order is approximated by the close price
+			if s.trackTranches() {
+				s.Position.AddTranche(types.PositionTranche{
+					Time:      kline.EndTime.Time(),
+					Price:     closePrice,
+					Quantity:  trancheQuantity,
+					StopPrice: s.trancheStopPrice(),
+				})
 			}
 		}
 	}))
@@ -45,6 +45,13 @@ type Strategy struct {
 	BalanceToleranceRange    fixedpoint.Value            `json:"balanceToleranceRange"`
 	Duration                 types.Duration              `json:"for"`
 
+	// Transfer rebalances the inventory across the sessions with the withdrawals,
+	// the asset with an in-flight transfer is not aligned by the orders until the transfer is confirmed.
+	Transfer *TransferConfig `json:"transfer,omitempty"`
+
+	// Transfers are the in-flight transfers by asset
+	Transfers map[string]*Transfer `persistence:"transfers"`
+
 	faultBalanceRecords map[string][]TimeBalance
 
 	sessions   map[string]*bbgo.ExchangeSession
@@ -85,6 +92,12 @@ func (s *Strategy) Validate() error {
 		return errors.New("quoteCurrencies is not defined")
 	}
 
+	if s.Transfer != nil {
+		if err := s.Transfer.Validate(); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -259,6 +272,16 @@ func (s *Strategy) CrossRun(ctx context.Context, _ bbgo.OrderExecutionRouter, se
 		s.sessions[sessionName] = session
 	}
 
+	if s.Transfer != nil {
+		if err := s.checkTransferSessions(s.sessions); err != nil {
+			return err
+		}
+
+		if s.Transfers == nil {
+			s.Transfers = make(map[string]*Transfer)
+		}
+	}
+
 	bbgo.OnShutdown(ctx, func(ctx context.Context, wg *sync.WaitGroup) {
 		defer wg.Done()
 		for n, session := range s.sessions {
@@ -324,11 +347,19 @@ func (s *Strategy) align(ctx context.Context, sessions map[string]*bbgo.Exchange
 	}
 
 	totalBalances, sessionBalances := s.aggregateBalances(ctx, sessions)
-	_ = sessionBalances
+
+	if s.Transfer != nil && len(sessionBalances) == len(sessions) {
+		s.alignTransfers(ctx, sessions, sessionBalances)
+	}
 
 	s.recordBalance(totalBalances)
 
 	for currency, expectedBalance := range s.ExpectedBalances {
+		if transfer, inFlight := s.Transfers[currency]; inFlight {
+			log.Infof("skip aligning %s, waiting for the %s", currency, transfer)
+			continue
+		}
+
 		q := s.calculateRefillQuantity(totalBalances, currency, expectedBalance)
 
 		if s.Duration > 0 {
@@ -0,0 +1,338 @@
+package xalign
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/c9s/bbgo/pkg/bbgo"
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+const defaultTransferConfirmationTimeout = 2 * time.Hour
+
+// TransferAddress is the whitelisted deposit address of the session
+type TransferAddress struct {
+	Address    string `json:"address"`
+	AddressTag string `json:"addressTag,omitempty"`
+	Network    string `json:"network,omitempty"`
+}
+
+// AssetTransferConfig is the per-session target balances of the asset
+type AssetTransferConfig struct {
+	// Targets maps the session name to the target available balance
+	Targets map[string]fixedpoint.Value `json:"targets"`
+
+	// MinAmount is the minimal amount of a transfer
+	MinAmount fixedpoint.Value `json:"minAmount"`
+
+	// Tolerance is the ratio of the target, the balance is refilled when it's lower than target * (1 - tolerance)
+	Tolerance fixedpoint.Value `json:"tolerance"`
+}
+
+// TransferConfig rebalances the inventory across the sessions with the withdrawals,
+// the funds are only sent to the whitelisted addresses.
+//
+//	transfer:
+//	  confirmationTimeout: 2h
+//	  assets:
+//	    BTC:
+//	      targets: { max: 0.5, binance: 0.5 }
+//	      minAmount: 0.01
+//	      tolerance: 20%
+//	  addresses:
+//	    max:
+//	      BTC: { address: "bc1...", network: "BTC" }
+type TransferConfig struct {
+	Assets map[string]*AssetTransferConfig `json:"assets"`
+
+	// Addresses maps the session name and the asset to the whitelisted deposit address
+	Addresses map[string]map[string]TransferAddress `json:"addresses"`
+
+	// ConfirmationTimeout alerts if the transfer is not confirmed after the duration, defaults to 2h
+	ConfirmationTimeout types.Duration `json:"confirmationTimeout,omitempty"`
+}
+
+func (c *TransferConfig) Validate() error {
+	for asset, assetConfig := range c.Assets {
+		if len(assetConfig.Targets) < 2 {
+			return fmt.Errorf("transfer.assets.%s.targets requires at least 2 sessions", asset)
+		}
+
+		if assetConfig.MinAmount.Sign() <= 0 {
+			return fmt.Errorf("transfer.assets.%s.minAmount should be greater than 0", asset)
+		}
+
+		if assetConfig.Tolerance.Sign() < 0 || assetConfig.Tolerance.Compare(fixedpoint.One) >= 0 {
+			return fmt.Errorf("transfer.assets.%s.tolerance should be in [0, 1)", asset)
+		}
+
+		for sessionName, target := range assetConfig.Targets {
+			if target.Sign() < 0 {
+				return fmt.Errorf("transfer.assets.%s.targets.%s should not be negative", asset, sessionName)
+			}
+		}
+	}
+
+	for sessionName, addresses := range c.Addresses {
+		for asset, address := range addresses {
+			if address.Address == "" {
+				return fmt.Errorf("transfer.addresses.%s.%s.address is empty", sessionName, asset)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c *TransferConfig) confirmationTimeout() time.Duration {
+	if c.ConfirmationTimeout > 0 {
+		return c.ConfirmationTimeout.Duration()
+	}
+
+	return defaultTransferConfirmationTimeout
+}
+
+type TransferStatus string
+
+const (
+	// TransferRequested is the status after the withdrawal request is sent
+	TransferRequested TransferStatus = "requested"
+
+	// TransferWithdrawn is the status after the withdrawal transaction is found in the withdraw history
+	TransferWithdrawn TransferStatus = "withdrawn"
+
+	// TransferConfirmed is the status after the deposit is credited on the destination session
+	TransferConfirmed TransferStatus = "confirmed"
+)
+
+// Transfer is the in-flight transfer of the asset, the asset is not aligned until the transfer is confirmed
+type Transfer struct {
+	Asset         string           `json:"asset"`
+	FromSession   string           `json:"fromSession"`
+	ToSession     string           `json:"toSession"`
+	Amount        fixedpoint.Value `json:"amount"`
+	Address       string           `json:"address"`
+	Status        TransferStatus   `json:"status"`
+	TransactionID string           `json:"transactionID,omitempty"`
+	RequestedAt   time.Time        `json:"requestedAt"`
+
+	// Alerted is set after the confirmation timeout alert is sent
+	Alerted bool `json:"alerted,omitempty"`
+}
+
+func (t *Transfer) String() string {
+	return fmt.Sprintf("transfer %s %s %s -> %s (%s)", t.Amount.String(), t.Asset, t.FromSession, t.ToSession, t.Status)
+}
+
+func (t *Transfer) SlackAttachment() slack.Attachment {
+	color := "#FFA500"
+	if t.Status == TransferConfirmed {
+		color = "#228B22"
+	}
+
+	fields := []slack.AttachmentField{
+		{Title: "Amount", Value: t.Amount.String() + " " + t.Asset, Short: true},
+		{Title: "Status", Value: string(t.Status), Short: true},
+		{Title: "From", Value: t.FromSession, Short: true},
+		{Title: "To", Value: t.ToSession, Short: true},
+	}
+
+	if t.TransactionID != "" {
+		fields = append(fields, slack.AttachmentField{Title: "Transaction ID", Value: t.TransactionID})
+	}
+
+	return slack.Attachment{
+		Title:  fmt.Sprintf("Inventory Transfer %s", t.Asset),
+		Color:  color,
+		Fields: fields,
+		Footer: "Requested at " + t.RequestedAt.Format(time.RFC822),
+	}
+}
+
+// planTransfer selects the session with the largest deficit below the tolerance as the destination and
+// the session with the largest surplus above the target as the source, the amount is the smaller one of them.
+func planTransfer(assetConfig *AssetTransferConfig, asset string, sessionBalances map[string]types.BalanceMap) (from, to string, amount fixedpoint.Value) {
+	var sessionNames []string
+	for sessionName := range assetConfig.Targets {
+		sessionNames = append(sessionNames, sessionName)
+	}
+	sort.Strings(sessionNames)
+
+	var maxDeficit, maxSurplus fixedpoint.Value
+	for _, sessionName := range sessionNames {
+		target := assetConfig.Targets[sessionName]
+		available := sessionBalances[sessionName][asset].Available
+
+		if available.Compare(target.Mul(fixedpoint.One.Sub(assetConfig.Tolerance))) < 0 {
+			if deficit := target.Sub(available); deficit.Compare(maxDeficit) > 0 {
+				maxDeficit, to = deficit, sessionName
+			}
+		} else if available.Compare(target) > 0 {
+			if surplus := available.Sub(target); surplus.Compare(maxSurplus) > 0 {
+				maxSurplus, from = surplus, sessionName
+			}
+		}
+	}
+
+	if from == "" || to == "" {
+		return "", "", fixedpoint.Zero
+	}
+
+	amount = fixedpoint.Min(maxDeficit, maxSurplus)
+	if amount.Compare(assetConfig.MinAmount) < 0 {
+		return "", "", fixedpoint.Zero
+	}
+
+	return from, to, amount
+}
+
+// checkTransferSessions checks the sessions of the transfer targets support the withdrawal and the transfer history
+func (s *Strategy) checkTransferSessions(sessions map[string]*bbgo.ExchangeSession) error {
+	for asset, assetConfig := range s.Transfer.Assets {
+		for sessionName := range assetConfig.Targets {
+			session, ok := sessions[sessionName]
+			if !ok {
+				return fmt.Errorf("transfer.assets.%s: session %s is not in the sessions of xalign", asset, sessionName)
+			}
+
+			if _, ok := session.Exchange.(types.ExchangeWithdrawalService); !ok {
+				return fmt.Errorf("transfer.assets.%s: exchange %s does not support withdrawal", asset, session.ExchangeName)
+			}
+
+			if _, ok := session.Exchange.(types.ExchangeTransferService); !ok {
+				return fmt.Errorf("transfer.assets.%s: exchange %s does not support the transfer history", asset, session.ExchangeName)
+			}
+		}
+	}
+
+	return nil
+}
+
+// alignTransfers tracks the in-flight transfers and sends the new transfers of the assets without the in-flight transfer
+func (s *Strategy) alignTransfers(ctx context.Context, sessions map[string]*bbgo.ExchangeSession, sessionBalances map[string]types.BalanceMap) {
+	for asset, transfer := range s.Transfers {
+		if s.trackTransfer(ctx, sessions, transfer) {
+			delete(s.Transfers, asset)
+		}
+	}
+
+	var assets []string
+	for asset := range s.Transfer.Assets {
+		assets = append(assets, asset)
+	}
+	sort.Strings(assets)
+
+	for _, asset := range assets {
+		if _, inFlight := s.Transfers[asset]; inFlight {
+			continue
+		}
+
+		from, to, amount := planTransfer(s.Transfer.Assets[asset], asset, sessionBalances)
+		if amount.IsZero() {
+			continue
+		}
+
+		if transfer := s.sendTransfer(ctx, sessions, asset, from, to, amount); transfer != nil {
+			s.Transfers[asset] = transfer
+		}
+	}
+
+	bbgo.Sync(ctx, s)
+}
+
+func (s *Strategy) sendTransfer(ctx context.Context, sessions map[string]*bbgo.ExchangeSession, asset, from, to string, amount fixedpoint.Value) *Transfer {
+	fromSession := sessions[from]
+	if !fromSession.Withdrawal {
+		log.Errorf("the withdrawal of session %s is not enabled, can not transfer %s %s to %s", from, amount.String(), asset, to)
+		return nil
+	}
+
+	address, ok := s.Transfer.Addresses[to][asset]
+	if !ok {
+		log.Errorf("%s address of session %s is not whitelisted, can not transfer %s %s", asset, to, amount.String(), asset)
+		bbgo.Notify("%s address of session %s is not whitelisted, can not transfer %s %s", asset, to, amount.String(), asset)
+		return nil
+	}
+
+	transfer := &Transfer{
+		Asset:       asset,
+		FromSession: from,
+		ToSession:   to,
+		Amount:      amount,
+		Address:     address.Address,
+		Status:      TransferRequested,
+		RequestedAt: time.Now(),
+	}
+
+	bbgo.Notify(transfer)
+
+	if s.DryRun {
+		return nil
+	}
+
+	withdrawalService := fromSession.Exchange.(types.ExchangeWithdrawalService)
+	if err := withdrawalService.Withdraw(ctx, asset, amount, address.Address, &types.WithdrawalOptions{
+		Network:    address.Network,
+		AddressTag: address.AddressTag,
+	}); err != nil {
+		log.WithError(err).Errorf("%s withdrawal failed", transfer)
+		bbgo.Notify("%s withdrawal failed: %v", transfer, err)
+		return nil
+	}
+
+	return transfer
+}
+
+// trackTransfer updates the status of the transfer from the transfer history, it returns true if the transfer is confirmed.
+// The withdrawal is matched by the address after the request time, and then the deposit is matched by the transaction ID.
+func (s *Strategy) trackTransfer(ctx context.Context, sessions map[string]*bbgo.ExchangeSession, transfer *Transfer) bool {
+	now := time.Now()
+	since := transfer.RequestedAt.Add(-time.Minute)
+
+	if transfer.Status == TransferRequested {
+		service := sessions[transfer.FromSession].Exchange.(types.ExchangeTransferService)
+		withdraws, err := service.QueryWithdrawHistory(ctx, transfer.Asset, since, now)
+		if err != nil {
+			log.WithError(err).Errorf("can not query the withdraw history of %s", transfer)
+			return false
+		}
+
+		for _, withdraw := range withdraws {
+			if withdraw.Address == transfer.Address && withdraw.TransactionID != "" {
+				transfer.TransactionID = withdraw.TransactionID
+				transfer.Status = TransferWithdrawn
+				log.Infof("%s is withdrawn, txn id %s", transfer, withdraw.TransactionID)
+				break
+			}
+		}
+	}
+
+	if transfer.Status == TransferWithdrawn {
+		service := sessions[transfer.ToSession].Exchange.(types.ExchangeTransferService)
+		deposits, err := service.QueryDepositHistory(ctx, transfer.Asset, since, now)
+		if err != nil {
+			log.WithError(err).Errorf("can not query the deposit history of %s", transfer)
+			return false
+		}
+
+		for _, deposit := range deposits {
+			if deposit.TransactionID == transfer.TransactionID && deposit.Status == types.DepositSuccess {
+				transfer.Status = TransferConfirmed
+				bbgo.Notify(transfer)
+				return true
+			}
+		}
+	}
+
+	if !transfer.Alerted && now.Sub(transfer.RequestedAt) > s.Transfer.confirmationTimeout() {
+		transfer.Alerted = true
+		bbgo.Notify("⚠️ %s is not confirmed after %s, please check the transfer manually", transfer, s.Transfer.confirmationTimeout())
+	}
+
+	return false
+}
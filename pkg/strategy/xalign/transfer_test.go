@@ -0,0 +1,61 @@
+package xalign
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+	"github.com/c9s/bbgo/pkg/types"
+)
+
+func TestPlanTransfer(t *testing.T) {
+	assetConfig := &AssetTransferConfig{
+		Targets: map[string]fixedpoint.Value{
+			"binance": fixedpoint.One,
+			"max":     fixedpoint.One,
+			"okex":    fixedpoint.One,
+		},
+		MinAmount: fixedpoint.MustNewFromString("0.1"),
+		Tolerance: fixedpoint.MustNewFromString("0.2"),
+	}
+
+	balances := func(binance, max, okex string) map[string]types.BalanceMap {
+		return map[string]types.BalanceMap{
+			"binance": {"BTC": {Currency: "BTC", Available: fixedpoint.MustNewFromString(binance)}},
+			"max":     {"BTC": {Currency: "BTC", Available: fixedpoint.MustNewFromString(max)}},
+			"okex":    {"BTC": {Currency: "BTC", Available: fixedpoint.MustNewFromString(okex)}},
+		}
+	}
+
+	t.Run("within tolerance", func(t *testing.T) {
+		from, to, amount := planTransfer(assetConfig, "BTC", balances("1.2", "0.85", "0.95"))
+		assert.Empty(t, from)
+		assert.Empty(t, to)
+		assert.True(t, amount.IsZero())
+	})
+
+	t.Run("refill the largest deficit from the largest surplus", func(t *testing.T) {
+		from, to, amount := planTransfer(assetConfig, "BTC", balances("1.6", "0.5", "1.1"))
+		assert.Equal(t, "binance", from)
+		assert.Equal(t, "max", to)
+		assert.Equal(t, "0.5", amount.String())
+	})
+
+	t.Run("limited by the surplus", func(t *testing.T) {
+		from, to, amount := planTransfer(assetConfig, "BTC", balances("1.3", "0.2", "1"))
+		assert.Equal(t, "binance", from)
+		assert.Equal(t, "max", to)
+		assert.Equal(t, "0.3", amount.String())
+	})
+
+	t.Run("less than the min amount", func(t *testing.T) {
+		_, _, amount := planTransfer(assetConfig, "BTC", balances("1.05", "0.7", "1"))
+		assert.True(t, amount.IsZero())
+	})
+
+	t.Run("no surplus", func(t *testing.T) {
+		_, _, amount := planTransfer(assetConfig, "BTC", balances("1", "0.5", "1"))
+		assert.True(t, amount.IsZero())
+	})
+}
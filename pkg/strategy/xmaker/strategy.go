@@ -212,7 +212,7 @@ func (s *Strategy) updateQuote(ctx context.Context, orderExecutionRouter bbgo.Or
 	// we load the balances from the account while we're generating the orders,
 	// the balance may have a chance to be deducted by other strategies or manual orders submitted by the user
 	makerBalances := s.makerSession.GetAccount().Balances()
-	makerQuota := &bbgo.QuotaTransaction{}
+	makerQuota := s.makerSession.NewQuotaTransaction(s.InstanceID(), s.makerMarket.BaseCurrency, s.makerMarket.QuoteCurrency)
 	if b, ok := makerBalances[s.makerMarket.BaseCurrency]; ok {
 		if b.Available.Compare(s.makerMarket.MinQuantity) > 0 {
 			makerQuota.BaseAsset.Add(b.Available)
@@ -584,7 +584,7 @@ func (s *Strategy) Hedge(ctx context.Context, pos fixedpoint.Value) {
 }
 
 func (s *Strategy) Validate() error {
-	if s.Quantity.IsZero() || s.QuantityScale == nil {
+	if s.Quantity.IsZero() && s.QuantityScale == nil {
 		return errors.New("quantity or quantityScale can not be empty")
 	}
 
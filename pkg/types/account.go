@@ -66,6 +66,11 @@ type Account struct {
 	CanWithdraw bool `json:"canWithdraw"`
 
 	balances BalanceMap
+
+	// reservations are the funds reserved by the strategies for the orders being placed,
+	// currency -> owner (the strategy instance ID) -> fund.
+	// The reservations are cooperative, they are not deducted from Balance and Balances.
+	reservations map[string]map[string]fixedpoint.Value
 }
 
 type FuturesAccountInfo struct {
@@ -218,6 +223,118 @@ func (a *Account) UpdateBalances(balances BalanceMap) {
 	}
 }
 
+// Reserve reserves the fund of the currency for the owner (the strategy instance ID), e.g., the quantity of the orders being placed,
+// so that the other strategies on the same account don't quote with the same balance before the exchange locks it.
+// Only the strategies reading the balances by AvailableBalance see the reservation (currently scmaker),
+// the strategies reading Balance and Balances, and the order executors, do not check it.
+// It returns an error if the fund exceeds the available balance of the owner.
+func (a *Account) Reserve(owner, currency string, fund fixedpoint.Value) error {
+	if fund.Sign() <= 0 {
+		return nil
+	}
+
+	a.Lock()
+	defer a.Unlock()
+
+	available := a.availableFor(owner, currency).Sub(a.reservations[currency][owner])
+	if fund.Compare(available) > 0 {
+		return fmt.Errorf("insufficient available balance %s for %s to reserve: want to reserve %v, available %v", currency, owner, fund, available)
+	}
+
+	if a.reservations == nil {
+		a.reservations = make(map[string]map[string]fixedpoint.Value)
+	}
+
+	if a.reservations[currency] == nil {
+		a.reservations[currency] = make(map[string]fixedpoint.Value)
+	}
+
+	a.reservations[currency][owner] = a.reservations[currency][owner].Add(fund)
+	return nil
+}
+
+// Release releases the reserved fund of the currency of the owner
+func (a *Account) Release(owner, currency string, fund fixedpoint.Value) {
+	a.Lock()
+	defer a.Unlock()
+
+	reserved, ok := a.reservations[currency][owner]
+	if !ok {
+		return
+	}
+
+	if reserved = reserved.Sub(fund); reserved.Sign() > 0 {
+		a.reservations[currency][owner] = reserved
+	} else {
+		delete(a.reservations[currency], owner)
+	}
+}
+
+// ReleaseAll releases all the reserved funds of the owner
+func (a *Account) ReleaseAll(owner string) {
+	a.Lock()
+	defer a.Unlock()
+
+	for _, owners := range a.reservations {
+		delete(owners, owner)
+	}
+}
+
+// Reserved returns the fund of the currency reserved by the owner
+func (a *Account) Reserved(owner, currency string) fixedpoint.Value {
+	a.Lock()
+	defer a.Unlock()
+	return a.reservations[currency][owner]
+}
+
+// AvailableBalance returns the balance seen by the owner,
+// the available balance is deducted by the funds reserved by the other owners through Reserve.
+func (a *Account) AvailableBalance(owner, currency string) (balance Balance, ok bool) {
+	a.Lock()
+	defer a.Unlock()
+
+	balance, ok = a.balances[currency]
+	if !ok {
+		return balance, ok
+	}
+
+	balance.Available = a.availableFor(owner, currency)
+	return balance, ok
+}
+
+// CopyReservations copies the reservations from the other account, it's used when the account is re-queried
+func (a *Account) CopyReservations(other *Account) {
+	if other == nil || other == a {
+		return
+	}
+
+	other.Lock()
+	reservations := make(map[string]map[string]fixedpoint.Value, len(other.reservations))
+	for currency, owners := range other.reservations {
+		reservations[currency] = make(map[string]fixedpoint.Value, len(owners))
+		for owner, fund := range owners {
+			reservations[currency][owner] = fund
+		}
+	}
+	other.Unlock()
+
+	a.Lock()
+	a.reservations = reservations
+	a.Unlock()
+}
+
+// availableFor returns the available balance minus the funds reserved by the other owners, the caller should hold the lock
+func (a *Account) availableFor(owner, currency string) fixedpoint.Value {
+	available := a.balances[currency].Available
+	for o, fund := range a.reservations[currency] {
+		if o != owner {
+			available = available.Sub(fund)
+		}
+	}
+
+	return fixedpoint.Max(available, fixedpoint.Zero)
+}
+
 func (a *Account) Print() {
 	a.Lock()
 	defer a.Unlock()
@@ -60,3 +60,34 @@ func TestAccountLockAndUse(t *testing.T) {
 	assert.Equal(t, balance.Available, fixedpoint.NewFromInt(900))
 	assert.Equal(t, balance.Locked, fixedpoint.Zero)
 }
+
+func TestAccountReservations(t *testing.T) {
+	a := NewAccount()
+	a.AddBalance("USDT", fixedpoint.NewFromInt(1000))
+
+	assert.NoError(t, a.Reserve("scmaker:USDCUSDT", "USDT", fixedpoint.NewFromInt(600)))
+	assert.Error(t, a.Reserve("xmaker:BTCUSDT", "USDT", fixedpoint.NewFromInt(500)), "only 400 is left for the other strategies")
+	assert.Error(t, a.Reserve("scmaker:USDCUSDT", "USDT", fixedpoint.NewFromInt(500)), "the own reservation is counted")
+
+	balance, ok := a.AvailableBalance("xmaker:BTCUSDT", "USDT")
+	assert.True(t, ok)
+	assert.Equal(t, fixedpoint.NewFromInt(400), balance.Available)
+
+	balance, ok = a.AvailableBalance("scmaker:USDCUSDT", "USDT")
+	assert.True(t, ok)
+	assert.Equal(t, fixedpoint.NewFromInt(1000), balance.Available, "the own reservation is not deducted")
+
+	b := NewAccount()
+	b.AddBalance("USDT", fixedpoint.NewFromInt(300))
+	b.CopyReservations(a)
+	balance, _ = b.AvailableBalance("xmaker:BTCUSDT", "USDT")
+	assert.Equal(t, fixedpoint.Zero, balance.Available, "the available balance is not negative")
+
+	a.Release("scmaker:USDCUSDT", "USDT", fixedpoint.NewFromInt(200))
+	assert.Equal(t, fixedpoint.NewFromInt(400), a.Reserved("scmaker:USDCUSDT", "USDT"))
+
+	a.ReleaseAll("scmaker:USDCUSDT")
+	assert.Equal(t, fixedpoint.Zero, a.Reserved("scmaker:USDCUSDT", "USDT"))
+	balance, _ = a.AvailableBalance("xmaker:BTCUSDT", "USDT")
+	assert.Equal(t, fixedpoint.NewFromInt(1000), balance.Available)
+}
@@ -0,0 +1,155 @@
+package types
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// BookSnapshot is the depth-of-market snapshot of an order book at the sampling time,
+// the bids are sorted by the price descending and the asks are sorted by the price ascending.
+type BookSnapshot struct {
+	Symbol string           `json:"symbol"`
+	Time   time.Time        `json:"time"`
+	Bids   PriceVolumeSlice `json:"bids"`
+	Asks   PriceVolumeSlice `json:"asks"`
+}
+
+// NewBookSnapshot creates the snapshot from the order book, the book should be a copy that is not updated concurrently
+func NewBookSnapshot(symbol string, book OrderBook, t time.Time) BookSnapshot {
+	return BookSnapshot{
+		Symbol: symbol,
+		Time:   t,
+		Bids:   book.SideBook(SideTypeBuy),
+		Asks:   book.SideBook(SideTypeSell),
+	}
+}
+
+// SliceOrderBook converts the snapshot to an order book, so that the snapshot can be loaded into the order book for replay
+func (s BookSnapshot) SliceOrderBook() SliceOrderBook {
+	return SliceOrderBook{
+		Symbol: s.Symbol,
+		Bids:   s.Bids.Copy(),
+		Asks:   s.Asks.Copy(),
+	}
+}
+
+func (s BookSnapshot) CsvHeader() []string {
+	return []string{"time", "symbol", "side", "level", "price", "volume"}
+}
+
+// CsvRecords returns one record per price level, the time is in unix milliseconds
+func (s BookSnapshot) CsvRecords() [][]string {
+	var records [][]string
+	ts := strconv.FormatInt(s.Time.UnixMilli(), 10)
+
+	for _, side := range []SideType{SideTypeBuy, SideTypeSell} {
+		levels := s.Bids
+		if side == SideTypeSell {
+			levels = s.Asks
+		}
+
+		for i, pv := range levels {
+			records = append(records, []string{
+				ts,
+				s.Symbol,
+				string(side),
+				strconv.Itoa(i),
+				pv.Price.String(),
+				pv.Volume.String(),
+			})
+		}
+	}
+
+	return records
+}
+
+// BookSnapshotReader reads the snapshots from the csv records written by BookSnapshot.CsvRecords,
+// the header lines are skipped, and the consecutive records of the same time and symbol are grouped into one snapshot.
+type BookSnapshotReader struct {
+	reader *csv.Reader
+
+	pending []string
+}
+
+func NewBookSnapshotReader(r io.Reader) *BookSnapshotReader {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 6
+	return &BookSnapshotReader{reader: reader}
+}
+
+func (r *BookSnapshotReader) next() ([]string, error) {
+	if r.pending != nil {
+		record := r.pending
+		r.pending = nil
+		return record, nil
+	}
+
+	for {
+		record, err := r.reader.Read()
+		if err != nil {
+			return nil, err
+		}
+
+		// skip the header lines, the header can be written more than once in the appended files
+		if record[0] == "time" {
+			continue
+		}
+
+		return record, nil
+	}
+}
+
+// Read returns the next snapshot, io.EOF is returned when there is no more snapshot
+func (r *BookSnapshotReader) Read() (*BookSnapshot, error) {
+	var snapshot *BookSnapshot
+	var ts string
+
+	for {
+		record, err := r.next()
+		if err == io.EOF && snapshot != nil {
+			return snapshot, nil
+		} else if err != nil {
+			return nil, err
+		}
+
+		if snapshot != nil && (record[0] != ts || record[1] != snapshot.Symbol) {
+			r.pending = record
+			return snapshot, nil
+		}
+
+		if snapshot == nil {
+			ms, err := strconv.ParseInt(record[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid book snapshot time %q: %w", record[0], err)
+			}
+
+			ts = record[0]
+			snapshot = &BookSnapshot{Symbol: record[1], Time: time.UnixMilli(ms)}
+		}
+
+		price, err := fixedpoint.NewFromString(record[4])
+		if err != nil {
+			return nil, fmt.Errorf("invalid book snapshot price %q: %w", record[4], err)
+		}
+
+		volume, err := fixedpoint.NewFromString(record[5])
+		if err != nil {
+			return nil, fmt.Errorf("invalid book snapshot volume %q: %w", record[5], err)
+		}
+
+		pv := PriceVolume{Price: price, Volume: volume}
+		switch SideType(record[2]) {
+		case SideTypeBuy:
+			snapshot.Bids = append(snapshot.Bids, pv)
+		case SideTypeSell:
+			snapshot.Asks = append(snapshot.Asks, pv)
+		default:
+			return nil, fmt.Errorf("invalid book snapshot side %q", record[2])
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"bytes"
+	"encoding/csv"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestBookSnapshot_CsvRoundTrip(t *testing.T) {
+	book := NewSliceOrderBook("BTCUSDT")
+	book.Load(SliceOrderBook{
+		Bids: PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(100.0), Volume: fixedpoint.NewFromFloat(1.0)},
+			{Price: fixedpoint.NewFromFloat(99.0), Volume: fixedpoint.NewFromFloat(2.0)},
+			{Price: fixedpoint.NewFromFloat(98.0), Volume: fixedpoint.NewFromFloat(3.0)},
+		},
+		Asks: PriceVolumeSlice{
+			{Price: fixedpoint.NewFromFloat(101.0), Volume: fixedpoint.NewFromFloat(1.5)},
+			{Price: fixedpoint.NewFromFloat(102.0), Volume: fixedpoint.NewFromFloat(2.5)},
+		},
+	})
+
+	t1 := time.UnixMilli(1700000000000)
+	t2 := t1.Add(time.Second)
+	snapshots := []BookSnapshot{
+		NewBookSnapshot("BTCUSDT", book.CopyDepth(2), t1),
+		NewBookSnapshot("BTCUSDT", book.Copy(), t2),
+	}
+
+	assert.Len(t, snapshots[0].Bids, 2)
+	assert.Len(t, snapshots[0].Asks, 2)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	for _, s := range snapshots {
+		assert.NoError(t, w.Write(s.CsvHeader()))
+		assert.NoError(t, w.WriteAll(s.CsvRecords()))
+	}
+
+	reader := NewBookSnapshotReader(&buf)
+	for _, want := range snapshots {
+		got, err := reader.Read()
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.Equal(t, want.Symbol, got.Symbol)
+		assert.True(t, want.Time.Equal(got.Time))
+		assert.Equal(t, want.Bids, got.Bids)
+		assert.Equal(t, want.Asks, got.Asks)
+	}
+
+	_, err := reader.Read()
+	assert.Equal(t, io.EOF, err)
+
+	replay := NewSliceOrderBook("BTCUSDT")
+	replay.Load(snapshots[1].SliceOrderBook())
+	bid, ok := replay.BestBid()
+	if assert.True(t, ok) {
+		assert.Equal(t, "100", bid.Price.String())
+	}
+}
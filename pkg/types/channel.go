@@ -12,7 +12,11 @@ const (
 	// channels for futures
 	MarkPriceChannel = Channel("markPrice")
 
-	LiquidationOrderChannel = Channel("liquidationOrder")
+	// LiquidationChannel is the public feed of the forced liquidation orders
+	LiquidationChannel = Channel("liquidation")
+
+	// Deprecated: use LiquidationChannel
+	LiquidationOrderChannel = LiquidationChannel
 
 	// ContractInfoChannel is the contract info provided by the exchange
 	ContractInfoChannel = Channel("contractInfo")
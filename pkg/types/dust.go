@@ -0,0 +1,25 @@
+package types
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// DustConversion is the result of converting the small balances
+type DustConversion struct {
+	// Currency is the currency the dust balances are converted into
+	Currency string           `json:"currency"`
+	Amount   fixedpoint.Value `json:"amount"`
+	Fee      fixedpoint.Value `json:"fee"`
+
+	Details []DustConversionDetail `json:"details,omitempty"`
+}
+
+type DustConversionDetail struct {
+	FromCurrency string           `json:"fromCurrency"`
+	FromAmount   fixedpoint.Value `json:"fromAmount"`
+	Amount       fixedpoint.Value `json:"amount"`
+	Fee          fixedpoint.Value `json:"fee"`
+	Time         time.Time        `json:"time"`
+}
@@ -114,6 +114,25 @@ type ExchangeAmountFeeProtect interface {
 	SetModifyOrderAmountForFee(ExchangeFee)
 }
 
+// ExchangeCancelOnDisconnectService is the exchange-native dead-man switch,
+// the exchange cancels the open orders if the timer is not refreshed before the timeout.
+// Setting the timeout to zero disarms the switch.
+type ExchangeCancelOnDisconnectService interface {
+	CancelAllAfter(ctx context.Context, timeout time.Duration, symbols ...string) error
+}
+
+// ExchangeOrderAmendService amends the price or the quantity of an open order in place,
+// the order keeps its queue position when only the quantity is reduced. The zero values are not changed.
+type ExchangeOrderAmendService interface {
+	AmendOrder(ctx context.Context, order Order, price, quantity fixedpoint.Value) (*Order, error)
+}
+
+// CredentialUpdater is implemented by the exchanges and the streams that can replace the api credentials at runtime,
+// the new credentials are used by the next request or the next stream connection.
+type CredentialUpdater interface {
+	SetCredentials(key, secret, passphrase string)
+}
+
 //go:generate mockgen -destination=mocks/mock_exchange_trade_history.go -package=mocks . ExchangeTradeHistoryService
 type ExchangeTradeHistoryService interface {
 	QueryTrades(ctx context.Context, symbol string, options *TradeQueryOptions) ([]Trade, error)
@@ -142,6 +161,11 @@ type ExchangeTransferService interface {
 	QueryWithdrawHistory(ctx context.Context, asset string, since, until time.Time) (allWithdraws []Withdraw, err error)
 }
 
+// ExchangeDustConversionService converts the small balances that are below the minimal order size
+type ExchangeDustConversionService interface {
+	ConvertDust(ctx context.Context, assets []string) (*DustConversion, error)
+}
+
 type ExchangeWithdrawalService interface {
 	Withdraw(ctx context.Context, asset string, amount fixedpoint.Value, address string, options *WithdrawalOptions) error
 }
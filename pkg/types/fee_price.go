@@ -0,0 +1,42 @@
+package types
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// FeePriceSource provides the price of the fee currency in the quote currency at the trade time,
+// it's used to convert the fees paid in a third currency, e.g., the BNB fees of the BTCUSDT trades.
+type FeePriceSource interface {
+	FeePrice(feeCurrency, quoteCurrency string, t time.Time) (fixedpoint.Value, bool)
+}
+
+// FeeInQuote converts the trade fee into the quote currency:
+// the quote currency fee is returned as it is, the base currency fee is converted with the trade price,
+// and the fee paid in the other currency is converted with the price from the fee price source.
+// It returns false when the fee of the other currency can not be converted.
+func FeeInQuote(trade Trade, baseCurrency, quoteCurrency string, source FeePriceSource) (fixedpoint.Value, bool) {
+	if trade.Fee.IsZero() {
+		return fixedpoint.Zero, true
+	}
+
+	switch trade.FeeCurrency {
+	case quoteCurrency:
+		return trade.Fee, true
+
+	case baseCurrency:
+		return trade.Fee.Mul(trade.Price), true
+	}
+
+	if source == nil {
+		return fixedpoint.Zero, false
+	}
+
+	price, ok := source.FeePrice(trade.FeeCurrency, quoteCurrency, trade.Time.Time())
+	if !ok || price.Sign() <= 0 {
+		return fixedpoint.Zero, false
+	}
+
+	return trade.Fee.Mul(price), true
+}
@@ -63,6 +63,115 @@ func (i Interval) Duration() time.Duration {
 	return time.Duration(i.Milliseconds()) * time.Millisecond
 }
 
+// weekIntervalEpoch is the first Monday after the unix epoch, the week interval boundaries are aligned to it in UTC,
+// so that the weekly intervals start on Monday like the exchange klines do.
+// The other intervals are aligned to the unix epoch, e.g., the 3d klines.
+var weekIntervalEpoch = time.Date(1970, time.January, 5, 0, 0, 0, 0, time.UTC)
+
+// Validate returns an error if the interval does not have a positive number and a known unit, e.g., "15m"
+func (i Interval) Validate() error {
+	n, unit := i.split()
+	if n <= 0 {
+		return fmt.Errorf("interval %q should have a positive number", i)
+	}
+
+	switch unit {
+	case "ms", "s", "m", "h", "d", "w", "mo":
+		return nil
+	}
+
+	return fmt.Errorf("interval %q has an unknown unit %q", i, unit)
+}
+
+// unit splits the interval into the number and the lower-cased unit, e.g., "15m" => 15, "m".
+// It panics if the interval is invalid like the other interval methods do.
+func (i Interval) unit() (int, string) {
+	if err := i.Validate(); err != nil {
+		panic(err)
+	}
+
+	return i.split()
+}
+
+func (i Interval) split() (int, string) {
+	n := 0
+	index := len(i)
+	for idx, rn := range string(i) {
+		if rn >= '0' && rn <= '9' {
+			n = n*10 + int(rn-'0')
+		} else {
+			index = idx
+			break
+		}
+	}
+
+	return n, strings.ToLower(string(i[index:]))
+}
+
+// Truncate returns the start time of the interval that contains t.
+// The boundaries are calculated in UTC, so the result does not depend on the location or the DST of t,
+// month intervals are aligned to the calendar months, week intervals are aligned to Monday,
+// and the other intervals are aligned to the unix epoch.
+// The returned time is in the location of t.
+func (i Interval) Truncate(t time.Time) time.Time {
+	u := t.UTC()
+
+	n, unit := i.unit()
+	if unit == "mo" {
+		months := u.Year()*12 + int(u.Month()) - 1
+		months -= int(floorMod(int64(months), int64(n)))
+		return time.Date(months/12, time.Month(months%12+1), 1, 0, 0, 0, 0, time.UTC).In(t.Location())
+	}
+
+	epoch := time.Unix(0, 0).UTC()
+	if unit == "w" {
+		epoch = weekIntervalEpoch
+	}
+
+	d := i.Duration()
+	offset := u.Sub(epoch)
+	return u.Add(-time.Duration(floorMod(int64(offset), int64(d)))).In(t.Location())
+}
+
+// NextBoundary returns the start time of the next interval after t
+func (i Interval) NextBoundary(t time.Time) time.Time {
+	return i.Advance(i.Truncate(t), 1)
+}
+
+// Advance adds n intervals to t, month intervals are added by the calendar months.
+func (i Interval) Advance(t time.Time, n int) time.Time {
+	num, unit := i.unit()
+	if unit == "mo" {
+		return t.UTC().AddDate(0, n*num, 0).In(t.Location())
+	}
+
+	return t.Add(time.Duration(n) * i.Duration())
+}
+
+// Count returns the number of the interval boundaries between the intervals of from and to,
+// it's negative if to is before from.
+func (i Interval) Count(from, to time.Time) int {
+	from, to = i.Truncate(from), i.Truncate(to)
+
+	n, unit := i.unit()
+	if unit == "mo" {
+		f, t := from.UTC(), to.UTC()
+		months := (t.Year()-f.Year())*12 + int(t.Month()) - int(f.Month())
+		return months / n
+	}
+
+	return int(to.Sub(from) / i.Duration())
+}
+
+func floorMod(a, b int64) int64 {
+	m := a % b
+	if m < 0 {
+		m += b
+	}
+
+	return m
+}
+
 func (i *Interval) UnmarshalJSON(b []byte) (err error) {
 	var a string
 	err = json.Unmarshal(b, &a)
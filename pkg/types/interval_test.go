@@ -2,6 +2,7 @@ package types
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -13,3 +14,61 @@ func TestParseInterval(t *testing.T) {
 	assert.Equal(t, ParseInterval("72d"), 72*24*60*60)
 	assert.Equal(t, ParseInterval("3Mo"), 3*30*24*60*60)
 }
+
+func TestInterval_Truncate(t *testing.T) {
+	ts := time.Date(2022, time.March, 9, 13, 47, 12, 0, time.UTC)
+	assert.Equal(t, time.Date(2022, time.March, 9, 13, 47, 0, 0, time.UTC), Interval1m.Truncate(ts))
+	assert.Equal(t, time.Date(2022, time.March, 9, 13, 45, 0, 0, time.UTC), Interval15m.Truncate(ts))
+	assert.Equal(t, time.Date(2022, time.March, 9, 12, 0, 0, 0, time.UTC), Interval4h.Truncate(ts))
+	assert.Equal(t, time.Date(2022, time.March, 9, 0, 0, 0, 0, time.UTC), Interval1d.Truncate(ts))
+	// 2022-03-07 is Monday
+	assert.Equal(t, time.Date(2022, time.March, 7, 0, 0, 0, 0, time.UTC), Interval1w.Truncate(ts))
+	assert.Equal(t, time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC), Interval1mo.Truncate(ts))
+	assert.Equal(t, time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC), Interval("3mo").Truncate(ts))
+	// the 3d intervals are aligned to the unix epoch like the exchange klines
+	assert.Equal(t, time.Date(2022, time.March, 8, 0, 0, 0, 0, time.UTC), Interval3d.Truncate(ts))
+	assert.Panics(t, func() { Interval("m").Truncate(ts) })
+
+	t.Run("location and DST", func(t *testing.T) {
+		loc, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			t.Skip("timezone database is not available")
+		}
+
+		// the DST starts at 2022-03-13 02:00 in New York
+		ts := time.Date(2022, time.March, 13, 3, 30, 0, 0, loc)
+		start := Interval1h.Truncate(ts)
+		assert.Equal(t, loc, start.Location())
+		assert.Equal(t, time.Date(2022, time.March, 13, 7, 0, 0, 0, time.UTC), start.UTC())
+		assert.Equal(t, time.Date(2022, time.March, 13, 0, 0, 0, 0, time.UTC), Interval1d.Truncate(ts).UTC())
+	})
+}
+
+func TestInterval_Validate(t *testing.T) {
+	assert.NoError(t, Interval1m.Validate())
+	assert.NoError(t, Interval("3mo").Validate())
+	assert.Error(t, Interval("m").Validate())
+	assert.Error(t, Interval("0h").Validate())
+	assert.Error(t, Interval("").Validate())
+	assert.Error(t, Interval("5x").Validate())
+}
+
+func TestInterval_NextBoundary(t *testing.T) {
+	ts := time.Date(2022, time.January, 31, 23, 59, 59, 0, time.UTC)
+	assert.Equal(t, time.Date(2022, time.February, 1, 0, 0, 0, 0, time.UTC), Interval1m.NextBoundary(ts))
+	assert.Equal(t, time.Date(2022, time.February, 1, 0, 0, 0, 0, time.UTC), Interval1mo.NextBoundary(ts))
+
+	boundary := time.Date(2022, time.February, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, boundary.Add(time.Hour), Interval1h.NextBoundary(boundary))
+}
+
+func TestInterval_Count(t *testing.T) {
+	from := time.Date(2022, time.January, 1, 0, 10, 0, 0, time.UTC)
+	to := time.Date(2022, time.January, 1, 1, 5, 0, 0, time.UTC)
+	assert.Equal(t, 55, Interval1m.Count(from, to))
+	assert.Equal(t, 4, Interval15m.Count(from, to))
+	assert.Equal(t, 1, Interval1h.Count(from, to))
+	assert.Equal(t, -1, Interval1h.Count(to, from))
+
+	assert.Equal(t, 13, Interval1mo.Count(from, time.Date(2023, time.February, 28, 0, 0, 0, 0, time.UTC)))
+}
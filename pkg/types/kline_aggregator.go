@@ -0,0 +1,207 @@
+package types
+
+import (
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// KLineAggregator builds the klines of a custom interval (e.g., 10s, 45s or 2m) locally,
+// when the exchange does not provide the interval natively.
+// The klines are aggregated from the closed klines of the source interval, or from the market trades
+// if the source interval is empty. The aggregated klines are emitted by the KLine and the KLineClosed callbacks.
+//
+//go:generate callbackgen -type KLineAggregator
+type KLineAggregator struct {
+	Symbol   string
+	Interval Interval
+
+	// SourceInterval is the interval of the source klines, the klines are aggregated from the trades if it's empty
+	SourceInterval Interval
+
+	mu sync.Mutex
+
+	// kline is the kline of the current window, it's nil if there is no data in the current window
+	kline *KLine
+
+	// nextStartTime is the start time of the window after the last closed kline
+	nextStartTime time.Time
+	lastClose     fixedpoint.Value
+
+	kLineCallbacks       []func(kline KLine)
+	kLineClosedCallbacks []func(kline KLine)
+}
+
+func NewKLineAggregator(symbol string, interval, sourceInterval Interval) *KLineAggregator {
+	return &KLineAggregator{
+		Symbol:         symbol,
+		Interval:       interval,
+		SourceInterval: sourceInterval,
+	}
+}
+
+// AggregationSourceInterval returns the largest interval of the supported intervals that divides the custom interval,
+// false is returned if none of the supported intervals can be aggregated into the custom interval.
+func AggregationSourceInterval(interval Interval, supportedIntervals map[Interval]int) (Interval, bool) {
+	d := interval.Duration()
+
+	var source Interval
+	for supported := range supportedIntervals {
+		sd := supported.Duration()
+		if sd <= 0 || sd > d || d%sd != 0 {
+			continue
+		}
+
+		if source == "" || sd > source.Duration() {
+			source = supported
+		}
+	}
+
+	return source, source != ""
+}
+
+// windowEndTime returns the end time of the window in the kline end time format, i.e., 1 millisecond before the next window
+func (a *KLineAggregator) windowEndTime(startTime time.Time) time.Time {
+	return a.Interval.Advance(startTime, 1).Add(-time.Millisecond)
+}
+
+// AddKLine aggregates the closed kline of the source interval
+func (a *KLineAggregator) AddKLine(k KLine) {
+	if k.Symbol != a.Symbol || k.Interval != a.SourceInterval || !k.Closed {
+		return
+	}
+
+	startTime := a.Interval.Truncate(k.StartTime.Time())
+	nextStartTime := a.Interval.Advance(startTime, 1)
+
+	var closed []KLine
+
+	a.mu.Lock()
+	if a.kline != nil && !a.kline.StartTime.Time().Equal(startTime) {
+		// the source klines of the previous window are missing, close it with the klines we have
+		closed = append(closed, a.closeKLine())
+	}
+
+	if a.kline == nil {
+		a.kline = &KLine{}
+		a.kline.Set(&k)
+		a.kline.StartTime = Time(startTime)
+		a.kline.Interval = a.Interval
+	} else {
+		a.kline.Merge(&k)
+	}
+
+	a.kline.EndTime = Time(a.windowEndTime(startTime))
+	a.kline.Closed = false
+
+	var update *KLine
+	if !a.SourceInterval.Advance(k.StartTime.Time(), 1).Before(nextStartTime) {
+		closed = append(closed, a.closeKLine())
+	} else {
+		kline := *a.kline
+		update = &kline
+	}
+	a.mu.Unlock()
+
+	a.emit(update, closed)
+}
+
+// AddTrade aggregates the market trade, the kline of the previous window is closed by the trade of the next window
+func (a *KLineAggregator) AddTrade(trade Trade) {
+	if trade.Symbol != a.Symbol || a.SourceInterval != "" {
+		return
+	}
+
+	startTime := a.Interval.Truncate(trade.Time.Time())
+
+	var closed []KLine
+
+	a.mu.Lock()
+	if !a.nextStartTime.IsZero() && startTime.Before(a.nextStartTime) {
+		// the window of the trade is already closed
+		a.mu.Unlock()
+		return
+	}
+
+	if a.kline != nil && a.kline.StartTime.Time().Before(startTime) {
+		closed = append(closed, a.closeKLine())
+	}
+
+	if a.kline == nil {
+		a.kline = &KLine{
+			Exchange:  trade.Exchange,
+			Symbol:    a.Symbol,
+			StartTime: Time(startTime),
+			EndTime:   Time(a.windowEndTime(startTime)),
+			Interval:  a.Interval,
+			Open:      trade.Price,
+			High:      trade.Price,
+			Low:       trade.Price,
+		}
+	}
+
+	a.kline.Close = trade.Price
+	a.kline.High = fixedpoint.Max(a.kline.High, trade.Price)
+	a.kline.Low = fixedpoint.Min(a.kline.Low, trade.Price)
+	a.kline.Volume = a.kline.Volume.Add(trade.Quantity)
+	a.kline.QuoteVolume = a.kline.QuoteVolume.Add(trade.QuoteQuantity)
+	a.kline.LastTradeID = trade.ID
+	a.kline.NumberOfTrades++
+
+	update := *a.kline
+	a.mu.Unlock()
+
+	a.emit(&update, closed)
+}
+
+// Flush closes the windows that end before now, it's called on the interval boundaries when the klines are aggregated from the trades,
+// since there might be no trade in the next window to close the current one.
+// The windows without trades are closed as the flat klines of the last close price.
+func (a *KLineAggregator) Flush(now time.Time) {
+	var closed []KLine
+
+	a.mu.Lock()
+	if a.kline != nil && !a.Interval.Advance(a.kline.StartTime.Time(), 1).After(now) {
+		closed = append(closed, a.closeKLine())
+	}
+
+	for a.kline == nil && !a.nextStartTime.IsZero() && a.lastClose.Sign() > 0 && !a.Interval.Advance(a.nextStartTime, 1).After(now) {
+		a.kline = &KLine{
+			Symbol:    a.Symbol,
+			StartTime: Time(a.nextStartTime),
+			EndTime:   Time(a.windowEndTime(a.nextStartTime)),
+			Interval:  a.Interval,
+			Open:      a.lastClose,
+			High:      a.lastClose,
+			Low:       a.lastClose,
+			Close:     a.lastClose,
+		}
+		closed = append(closed, a.closeKLine())
+	}
+	a.mu.Unlock()
+
+	a.emit(nil, closed)
+}
+
+// closeKLine closes the kline of the current window, the caller must hold the lock
+func (a *KLineAggregator) closeKLine() KLine {
+	kline := *a.kline
+	kline.Closed = true
+
+	a.kline = nil
+	a.nextStartTime = a.Interval.Advance(kline.StartTime.Time(), 1)
+	a.lastClose = kline.Close
+	return kline
+}
+
+func (a *KLineAggregator) emit(update *KLine, closed []KLine) {
+	for _, kline := range closed {
+		a.EmitKLine(kline)
+		a.EmitKLineClosed(kline)
+	}
+
+	if update != nil {
+		a.EmitKLine(*update)
+	}
+}
@@ -0,0 +1,145 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func newTestKLine(startTime time.Time, interval Interval, open, high, low, close, volume string) KLine {
+	return KLine{
+		Symbol:    "BTCUSDT",
+		StartTime: Time(startTime),
+		EndTime:   Time(interval.Advance(startTime, 1).Add(-time.Millisecond)),
+		Interval:  interval,
+		Open:      fixedpoint.MustNewFromString(open),
+		High:      fixedpoint.MustNewFromString(high),
+		Low:       fixedpoint.MustNewFromString(low),
+		Close:     fixedpoint.MustNewFromString(close),
+		Volume:    fixedpoint.MustNewFromString(volume),
+		Closed:    true,
+	}
+}
+
+func TestAggregationSourceInterval(t *testing.T) {
+	supported := map[Interval]int{
+		Interval1s:  1,
+		Interval1m:  60,
+		Interval15m: 15 * 60,
+	}
+
+	source, ok := AggregationSourceInterval(Interval("45s"), supported)
+	assert.True(t, ok)
+	assert.Equal(t, Interval1s, source)
+
+	source, ok = AggregationSourceInterval(Interval("2m"), supported)
+	assert.True(t, ok)
+	assert.Equal(t, Interval1m, source)
+
+	source, ok = AggregationSourceInterval(Interval("30m"), supported)
+	assert.True(t, ok)
+	assert.Equal(t, Interval15m, source)
+
+	_, ok = AggregationSourceInterval(Interval("10s"), map[Interval]int{Interval1m: 60})
+	assert.False(t, ok)
+}
+
+func TestKLineAggregator_AddKLine(t *testing.T) {
+	aggregator := NewKLineAggregator("BTCUSDT", Interval("2m"), Interval1m)
+
+	var updates, closed []KLine
+	aggregator.OnKLine(func(kline KLine) { updates = append(updates, kline) })
+	aggregator.OnKLineClosed(func(kline KLine) { closed = append(closed, kline) })
+
+	startTime := time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC)
+	aggregator.AddKLine(newTestKLine(startTime, Interval1m, "100", "110", "95", "105", "1"))
+	assert.Len(t, updates, 1)
+	assert.False(t, updates[0].Closed)
+	assert.Empty(t, closed)
+
+	// the unclosed and the other interval klines are ignored
+	unclosed := newTestKLine(startTime.Add(time.Minute), Interval1m, "105", "200", "50", "108", "2")
+	unclosed.Closed = false
+	aggregator.AddKLine(unclosed)
+	aggregator.AddKLine(newTestKLine(startTime, Interval5m, "105", "200", "50", "108", "2"))
+	assert.Empty(t, closed)
+
+	aggregator.AddKLine(newTestKLine(startTime.Add(time.Minute), Interval1m, "105", "120", "101", "118", "2"))
+	if assert.Len(t, closed, 1) {
+		kline := closed[0]
+		assert.Equal(t, Interval("2m"), kline.Interval)
+		assert.Equal(t, startTime, kline.StartTime.Time())
+		assert.Equal(t, startTime.Add(2*time.Minute-time.Millisecond), kline.EndTime.Time())
+		assert.Equal(t, "100", kline.Open.String())
+		assert.Equal(t, "120", kline.High.String())
+		assert.Equal(t, "95", kline.Low.String())
+		assert.Equal(t, "118", kline.Close.String())
+		assert.Equal(t, "3", kline.Volume.String())
+		assert.True(t, kline.Closed)
+	}
+
+	// the window with the missing source kline is closed by the kline of the next window
+	aggregator.AddKLine(newTestKLine(startTime.Add(2*time.Minute), Interval1m, "118", "119", "117", "117", "1"))
+	aggregator.AddKLine(newTestKLine(startTime.Add(4*time.Minute), Interval1m, "117", "117", "116", "116", "1"))
+	if assert.Len(t, closed, 2) {
+		assert.Equal(t, startTime.Add(2*time.Minute), closed[1].StartTime.Time())
+		assert.Equal(t, "117", closed[1].Close.String())
+	}
+}
+
+func TestKLineAggregator_AddTrade(t *testing.T) {
+	interval := Interval("10s")
+	aggregator := NewKLineAggregator("BTCUSDT", interval, "")
+
+	var closed []KLine
+	aggregator.OnKLineClosed(func(kline KLine) { closed = append(closed, kline) })
+
+	startTime := time.Date(2023, time.July, 1, 0, 0, 0, 0, time.UTC)
+	trade := func(offset time.Duration, price, quantity string) Trade {
+		return Trade{
+			Symbol:   "BTCUSDT",
+			Price:    fixedpoint.MustNewFromString(price),
+			Quantity: fixedpoint.MustNewFromString(quantity),
+			Time:     Time(startTime.Add(offset)),
+		}
+	}
+
+	aggregator.AddTrade(trade(time.Second, "100", "1"))
+	aggregator.AddTrade(trade(3*time.Second, "102", "1"))
+	aggregator.AddTrade(trade(9*time.Second, "99", "2"))
+	aggregator.Flush(startTime.Add(9 * time.Second))
+	assert.Empty(t, closed, "the window is not ended yet")
+
+	aggregator.Flush(startTime.Add(10 * time.Second))
+	if assert.Len(t, closed, 1) {
+		assert.Equal(t, "100", closed[0].Open.String())
+		assert.Equal(t, "102", closed[0].High.String())
+		assert.Equal(t, "99", closed[0].Low.String())
+		assert.Equal(t, "99", closed[0].Close.String())
+		assert.Equal(t, "4", closed[0].Volume.String())
+		assert.Equal(t, uint64(3), closed[0].NumberOfTrades)
+	}
+
+	// the late trade of the closed window is dropped
+	aggregator.AddTrade(trade(9500*time.Millisecond, "90", "1"))
+
+	// the window without trades is closed as the flat kline
+	aggregator.Flush(startTime.Add(20 * time.Second))
+	if assert.Len(t, closed, 2) {
+		assert.Equal(t, startTime.Add(interval.Duration()), closed[1].StartTime.Time())
+		assert.Equal(t, "99", closed[1].Open.String())
+		assert.Equal(t, "99", closed[1].Close.String())
+		assert.True(t, closed[1].Volume.IsZero())
+	}
+
+	// the trade of the next window closes the current window
+	aggregator.AddTrade(trade(25*time.Second, "101", "1"))
+	aggregator.AddTrade(trade(31*time.Second, "103", "1"))
+	if assert.Len(t, closed, 3) {
+		assert.Equal(t, startTime.Add(20*time.Second), closed[2].StartTime.Time())
+		assert.Equal(t, "101", closed[2].Close.String())
+	}
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// LiquidationInfo is the forced liquidation order of the futures market from the public liquidation feed.
+// The side is the side of the liquidation order, a sell liquidation order closes a liquidated long position.
+type LiquidationInfo struct {
+	Exchange ExchangeName `json:"exchange"`
+	Symbol   string       `json:"symbol"`
+	Side     SideType     `json:"side"`
+	Type     OrderType    `json:"type"`
+
+	Price            fixedpoint.Value `json:"price"`
+	AveragePrice     fixedpoint.Value `json:"averagePrice"`
+	Quantity         fixedpoint.Value `json:"quantity"`
+	ExecutedQuantity fixedpoint.Value `json:"executedQuantity"`
+
+	Status    OrderStatus `json:"status"`
+	TradeTime Time        `json:"tradeTime"`
+}
+
+// QuoteQuantity returns the notional value of the executed quantity
+func (l LiquidationInfo) QuoteQuantity() fixedpoint.Value {
+	price := l.AveragePrice
+	if price.IsZero() {
+		price = l.Price
+	}
+
+	quantity := l.ExecutedQuantity
+	if quantity.IsZero() {
+		quantity = l.Quantity
+	}
+
+	return price.Mul(quantity)
+}
+
+func (l LiquidationInfo) String() string {
+	return fmt.Sprintf("LIQUIDATION %s %s %s %s @ %s (%s)",
+		l.Exchange, l.Symbol, l.Side, l.Quantity.String(), l.AveragePrice.String(), l.Status)
+}
@@ -59,6 +59,14 @@ type MarginBorrowRepayService interface {
 	QueryMarginAssetMaxBorrowable(ctx context.Context, asset string) (amount fixedpoint.Value, err error)
 }
 
+// IsolatedMarginTransferService transfers the assets between the spot account and the isolated margin account of the isolated margin symbol
+type IsolatedMarginTransferService interface {
+	TransferIsolatedMarginAccountAsset(ctx context.Context, asset string, amount fixedpoint.Value, io TransferDirection) error
+
+	// QuerySpotAccount queries the spot wallet, which is the other side of the isolated margin transfer
+	QuerySpotAccount(ctx context.Context) (*Account, error)
+}
+
 type MarginInterest struct {
 	GID            uint64           `json:"gid" db:"gid"`
 	Exchange       ExchangeName     `json:"exchange" db:"exchange"`
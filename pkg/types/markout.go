@@ -0,0 +1,173 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+var tenThousand = fixedpoint.NewFromInt(10000)
+
+// MarkOut calculates the mark-out of the trade against the mid price after the trade.
+// A positive mark-out means the price moved in favor of the trade, a negative one means adverse selection.
+// bps is the mark-out in basis points of the trade price, pnl is the mark-out in quote currency.
+func MarkOut(trade Trade, midPrice fixedpoint.Value) (bps, pnl fixedpoint.Value) {
+	if trade.Price.IsZero() {
+		return fixedpoint.Zero, fixedpoint.Zero
+	}
+
+	diff := midPrice.Sub(trade.Price)
+	if trade.Side == SideTypeSell {
+		diff = diff.Neg()
+	}
+
+	return diff.Div(trade.Price).Mul(tenThousand), diff.Mul(trade.Quantity)
+}
+
+// MarkOutHorizonStats is the mark-out statistics of one horizon
+type MarkOutHorizonStats struct {
+	Horizon time.Duration `json:"horizon"`
+
+	NumOfTrades        int `json:"numOfTrades"`
+	NumOfAdverseTrades int `json:"numOfAdverseTrades"`
+
+	// TotalMarkOutBps is the sum of the mark-out bps of the trades
+	TotalMarkOutBps fixedpoint.Value `json:"totalMarkOutBps"`
+
+	// TotalMarkOutPnL is the sum of the mark-out pnl of the trades in quote currency
+	TotalMarkOutPnL fixedpoint.Value `json:"totalMarkOutPnL"`
+
+	MakerNumOfTrades     int              `json:"makerNumOfTrades"`
+	MakerTotalMarkOutBps fixedpoint.Value `json:"makerTotalMarkOutBps"`
+}
+
+// AverageMarkOutBps returns the average mark-out bps of the trades
+func (s *MarkOutHorizonStats) AverageMarkOutBps() fixedpoint.Value {
+	if s.NumOfTrades == 0 {
+		return fixedpoint.Zero
+	}
+
+	return s.TotalMarkOutBps.Div(fixedpoint.NewFromInt(int64(s.NumOfTrades)))
+}
+
+// MakerAverageMarkOutBps returns the average mark-out bps of the maker trades
+func (s *MarkOutHorizonStats) MakerAverageMarkOutBps() fixedpoint.Value {
+	if s.MakerNumOfTrades == 0 {
+		return fixedpoint.Zero
+	}
+
+	return s.MakerTotalMarkOutBps.Div(fixedpoint.NewFromInt(int64(s.MakerNumOfTrades)))
+}
+
+// AdverseRatio returns the ratio of the trades with negative mark-out
+func (s *MarkOutHorizonStats) AdverseRatio() fixedpoint.Value {
+	if s.NumOfTrades == 0 {
+		return fixedpoint.Zero
+	}
+
+	return fixedpoint.NewFromInt(int64(s.NumOfAdverseTrades)).Div(fixedpoint.NewFromInt(int64(s.NumOfTrades)))
+}
+
+// MarkOutStats is the post-trade mark-out statistics of a symbol
+type MarkOutStats struct {
+	Symbol   string                 `json:"symbol"`
+	Horizons []*MarkOutHorizonStats `json:"horizons"`
+
+	mu sync.Mutex
+}
+
+func NewMarkOutStats(symbol string, horizons ...time.Duration) *MarkOutStats {
+	stats := &MarkOutStats{
+		Symbol: symbol,
+	}
+
+	for _, horizon := range horizons {
+		stats.Horizons = append(stats.Horizons, &MarkOutHorizonStats{
+			Horizon: horizon,
+		})
+	}
+
+	return stats
+}
+
+// Add records the mark-out of the trade at the given horizon, the horizon stats is created if it does not exist.
+func (s *MarkOutStats) Add(trade Trade, horizon time.Duration, midPrice fixedpoint.Value) (bps, pnl fixedpoint.Value) {
+	bps, pnl = MarkOut(trade, midPrice)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	horizonStats := s.horizon(horizon)
+	horizonStats.NumOfTrades++
+	horizonStats.TotalMarkOutBps = horizonStats.TotalMarkOutBps.Add(bps)
+	horizonStats.TotalMarkOutPnL = horizonStats.TotalMarkOutPnL.Add(pnl)
+	if bps.Sign() < 0 {
+		horizonStats.NumOfAdverseTrades++
+	}
+
+	if trade.IsMaker {
+		horizonStats.MakerNumOfTrades++
+		horizonStats.MakerTotalMarkOutBps = horizonStats.MakerTotalMarkOutBps.Add(bps)
+	}
+
+	return bps, pnl
+}
+
+// TotalMarkOutPnL returns the accumulated mark-out pnl of the horizon
+func (s *MarkOutStats) TotalMarkOutPnL(horizon time.Duration) fixedpoint.Value {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, horizonStats := range s.Horizons {
+		if horizonStats.Horizon == horizon {
+			return horizonStats.TotalMarkOutPnL
+		}
+	}
+
+	return fixedpoint.Zero
+}
+
+// MarshalJSON locks the stats, the stats is updated by the mark-out collector while it's being persisted
+func (s *MarkOutStats) MarshalJSON() ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type markOutStats MarkOutStats
+	return json.Marshal((*markOutStats)(s))
+}
+
+func (s *MarkOutStats) horizon(horizon time.Duration) *MarkOutHorizonStats {
+	for _, horizonStats := range s.Horizons {
+		if horizonStats.Horizon == horizon {
+			return horizonStats
+		}
+	}
+
+	horizonStats := &MarkOutHorizonStats{Horizon: horizon}
+	s.Horizons = append(s.Horizons, horizonStats)
+	return horizonStats
+}
+
+func (s *MarkOutStats) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("MarkOutStats %s\n", s.Symbol))
+	for _, horizonStats := range s.Horizons {
+		sb.WriteString(fmt.Sprintf("+%s: trades %d, avg %s bps (maker %s bps), adverse %s, pnl %s\n",
+			horizonStats.Horizon,
+			horizonStats.NumOfTrades,
+			horizonStats.AverageMarkOutBps().FormatString(2),
+			horizonStats.MakerAverageMarkOutBps().FormatString(2),
+			horizonStats.AdverseRatio().FormatPercentage(2),
+			horizonStats.TotalMarkOutPnL.String(),
+		))
+	}
+
+	return sb.String()
+}
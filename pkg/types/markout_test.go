@@ -0,0 +1,51 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+func TestMarkOut(t *testing.T) {
+	number := fixedpoint.MustNewFromString
+
+	buy := Trade{Side: SideTypeBuy, Price: number("100"), Quantity: number("2"), IsMaker: true}
+	bps, pnl := MarkOut(buy, number("99"))
+	assert.Equal(t, number("-100"), bps)
+	assert.Equal(t, number("-2"), pnl)
+
+	sell := Trade{Side: SideTypeSell, Price: number("100"), Quantity: number("1")}
+	bps, pnl = MarkOut(sell, number("99.5"))
+	assert.Equal(t, number("50"), bps)
+	assert.Equal(t, number("0.5"), pnl)
+
+	stats := NewMarkOutStats("BTCUSDT", time.Second)
+	stats.Add(buy, time.Second, number("99"))
+	stats.Add(sell, time.Second, number("99.5"))
+	stats.Add(sell, 5*time.Second, number("101"))
+
+	if assert.Len(t, stats.Horizons, 2) {
+		horizonStats := stats.Horizons[0]
+		assert.Equal(t, 2, horizonStats.NumOfTrades)
+		assert.Equal(t, 1, horizonStats.NumOfAdverseTrades)
+		assert.Equal(t, number("-25"), horizonStats.AverageMarkOutBps())
+		assert.Equal(t, number("-100"), horizonStats.MakerAverageMarkOutBps())
+		assert.Equal(t, number("0.5"), horizonStats.AdverseRatio())
+		assert.Equal(t, 5*time.Second, stats.Horizons[1].Horizon)
+	}
+
+	assert.Equal(t, number("-1.5"), stats.TotalMarkOutPnL(time.Second))
+	assert.Equal(t, fixedpoint.Zero, stats.TotalMarkOutPnL(time.Minute))
+
+	data, err := json.Marshal(stats)
+	if assert.NoError(t, err) {
+		var loaded MarkOutStats
+		assert.NoError(t, json.Unmarshal(data, &loaded))
+		assert.Equal(t, "BTCUSDT", loaded.Symbol)
+		assert.Len(t, loaded.Horizons, 2)
+	}
+}
@@ -35,6 +35,16 @@ var (
 	TimeInForceFOK TimeInForce = "FOK"
 )
 
+// SelfTradePreventionMode defines which side of the matched orders is expired when the orders of the same account cross
+type SelfTradePreventionMode string
+
+var (
+	SelfTradePreventionNone        SelfTradePreventionMode = ""
+	SelfTradePreventionExpireTaker SelfTradePreventionMode = "EXPIRE_TAKER"
+	SelfTradePreventionExpireMaker SelfTradePreventionMode = "EXPIRE_MAKER"
+	SelfTradePreventionExpireBoth  SelfTradePreventionMode = "EXPIRE_BOTH"
+)
+
 // MarginOrderSideEffectType define side effect type for orders
 type MarginOrderSideEffectType string
 
@@ -137,11 +147,17 @@ type SubmitOrder struct {
 
 	GroupID uint32 `json:"groupID,omitempty"`
 
+	// SelfTradePrevention is only supported by the exchanges that implement the self-trade prevention
+	SelfTradePrevention SelfTradePreventionMode `json:"selfTradePrevention,omitempty" db:"-"`
+
 	MarginSideEffect MarginOrderSideEffectType `json:"marginSideEffect,omitempty"` // AUTO_REPAY = repay, MARGIN_BUY = borrow, defaults to  NO_SIDE_EFFECT
 
 	ReduceOnly    bool `json:"reduceOnly,omitempty" db:"reduce_only"`
 	ClosePosition bool `json:"closePosition,omitempty" db:"close_position"`
 
+	// PositionSide is required by the futures exchanges in the hedge mode, the position side is LONG or SHORT
+	PositionSide PositionSide `json:"positionSide,omitempty" db:"-"`
+
 	Tag string `json:"tag,omitempty" db:"-"`
 }
 
@@ -50,6 +50,10 @@ type Position struct {
 	// TotalFee stores the fee currency -> total fee quantity
 	TotalFee map[string]fixedpoint.Value `json:"totalFee" db:"-"`
 
+	// TotalFeeInQuote is the total fee of all the fee currencies converted into the quote currency,
+	// only the trades with the converted fee are counted.
+	TotalFeeInQuote fixedpoint.Value `json:"totalFeeInQuote,omitempty" db:"-"`
+
 	OpenedAt  time.Time `json:"openedAt,omitempty" db:"-"`
 	ChangedAt time.Time `json:"changedAt,omitempty" db:"changed_at"`
 
@@ -58,6 +62,9 @@ type Position struct {
 
 	AccumulatedProfit fixedpoint.Value `json:"accumulatedProfit,omitempty" db:"accumulated_profit"`
 
+	// Tranches are the entries of the scaled-in position, they are recorded by the strategies with the pyramid entries
+	Tranches []PositionTranche `json:"tranches,omitempty" db:"-"`
+
 	// closing is a flag for marking this position is closing
 	closing bool
 
@@ -121,6 +128,7 @@ func (p *Position) NewProfit(trade Trade, profit, netProfit fixedpoint.Value) Pr
 		// FeeInUSD:           0,
 		Fee:         trade.Fee,
 		FeeCurrency: trade.FeeCurrency,
+		FeeInQuote:  trade.FeeInQuote,
 
 		Exchange:           trade.Exchange,
 		IsMargin:           trade.IsMargin,
@@ -246,6 +254,39 @@ func (p *Position) ModifyAverageCost(price fixedpoint.Value) error {
 	return nil
 }
 
+// PositionSide is the side of the futures position.
+// In the hedge mode (dual-side position mode), the long and the short positions of the same symbol coexist,
+// the orders must be submitted with the position side LONG or SHORT.
+// In the one-way mode, the position side is BOTH.
+type PositionSide string
+
+const (
+	PositionSideBoth  = PositionSide("BOTH")
+	PositionSideLong  = PositionSide("LONG")
+	PositionSideShort = PositionSide("SHORT")
+)
+
+// IsHedge returns true if the position side is used in the hedge mode
+func (s PositionSide) IsHedge() bool {
+	return s == PositionSideLong || s == PositionSideShort
+}
+
+// FuturesPositionKey returns the key of the futures position in FuturesPositionMap,
+// the positions of the hedge mode are keyed by the symbol and the position side, e.g. BTCUSDT:LONG
+func FuturesPositionKey(symbol string, side PositionSide) string {
+	if side.IsHedge() {
+		return symbol + ":" + string(side)
+	}
+
+	return symbol
+}
+
+// Get returns the futures position of the symbol and the position side
+func (m FuturesPositionMap) Get(symbol string, side PositionSide) (FuturesPosition, bool) {
+	position, ok := m[FuturesPositionKey(symbol, side)]
+	return position, ok
+}
+
 type FuturesPosition struct {
 	Symbol        string `json:"symbol"`
 	BaseCurrency  string `json:"baseCurrency"`
@@ -264,6 +305,9 @@ type FuturesPosition struct {
 	FeeRate          *ExchangeFee                 `json:"feeRate,omitempty"`
 	ExchangeFeeRates map[ExchangeName]ExchangeFee `json:"exchangeFeeRates"`
 
+	// PositionSide is LONG or SHORT in the hedge mode, BOTH in the one-way mode
+	PositionSide PositionSide `json:"positionSide,omitempty"`
+
 	// Futures data fields
 	Isolated     bool  `json:"isolated"`
 	UpdateTime   int64 `json:"updateTime"`
@@ -298,6 +342,7 @@ func (p *Position) addTradeFee(trade Trade) {
 		p.TotalFee = make(map[string]fixedpoint.Value)
 	}
 	p.TotalFee[trade.FeeCurrency] = p.TotalFee[trade.FeeCurrency].Add(trade.Fee)
+	p.TotalFeeInQuote = p.TotalFeeInQuote.Add(trade.FeeInQuote)
 }
 
 func (p *Position) Reset() {
@@ -305,6 +350,8 @@ func (p *Position) Reset() {
 	p.Quote = fixedpoint.Zero
 	p.AverageCost = fixedpoint.Zero
 	p.TotalFee = make(map[string]fixedpoint.Value)
+	p.TotalFeeInQuote = fixedpoint.Zero
+	p.Tranches = nil
 }
 
 func (p *Position) SetFeeRate(exchangeFee ExchangeFee) {
@@ -384,6 +431,14 @@ func (p *Position) SlackAttachment() slack.Attachment {
 		}
 	}
 
+	if p.TotalFeeInQuote.Sign() > 0 {
+		fields = append(fields, slack.AttachmentField{
+			Title: fmt.Sprintf("Total Fee (%s)", p.QuoteCurrency),
+			Value: p.TotalFeeInQuote.String(),
+			Short: true,
+		})
+	}
+
 	return slack.Attachment{
 		// Pretext:       "",
 		// Text:  text,
@@ -411,6 +466,10 @@ func (p *Position) PlainText() (msg string) {
 		}
 	}
 
+	if p.TotalFeeInQuote.Sign() > 0 {
+		msg += fmt.Sprintf("\ntotal fee (%s) = %v", p.QuoteCurrency, p.TotalFeeInQuote)
+	}
+
 	return msg
 }
 
@@ -485,7 +544,10 @@ func (p *Position) AddTrade(td Trade) (profit fixedpoint.Value, netProfit fixedp
 		}
 
 	default:
-		if !td.Fee.IsZero() {
+		if !td.FeeInQuote.IsZero() {
+			// the fee is converted by the fee price source at the trade time
+			feeInQuote = td.FeeInQuote
+		} else if !td.Fee.IsZero() {
 			if p.ExchangeFeeRates != nil {
 				if exchangeFee, ok := p.ExchangeFeeRates[td.Exchange]; ok {
 					if td.IsMaker {
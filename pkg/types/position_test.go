@@ -2,6 +2,7 @@ package types
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -144,6 +145,79 @@ func TestPosition_ExchangeFeeRate_Long(t *testing.T) {
 	assert.Equal(t, expectedProfit, netProfit)
 }
 
+func TestPosition_FeeInQuote(t *testing.T) {
+	// no fee rate is configured, the converted fee is used
+	pos := NewPosition("BTCUSDT", "BTC", "USDT")
+
+	pos.AddTrade(Trade{
+		Exchange:      ExchangeBinance,
+		Price:         fixedpoint.NewFromInt(3000),
+		Quantity:      fixedpoint.NewFromInt(10),
+		QuoteQuantity: fixedpoint.NewFromInt(30000),
+		Symbol:        "BTCUSDT",
+		Side:          SideTypeBuy,
+		Fee:           fixedpoint.NewFromFloat(0.05),
+		FeeCurrency:   "BNB",
+		FeeInQuote:    fixedpoint.NewFromInt(30),
+	})
+	assert.Equal(t, "3000", pos.AverageCost.String())
+	assert.Equal(t, "3003", pos.ApproximateAverageCost.String())
+
+	_, netProfit, madeProfit := pos.AddTrade(Trade{
+		Exchange:      ExchangeBinance,
+		Price:         fixedpoint.NewFromInt(4000),
+		Quantity:      fixedpoint.NewFromInt(10),
+		QuoteQuantity: fixedpoint.NewFromInt(40000),
+		Symbol:        "BTCUSDT",
+		Side:          SideTypeSell,
+		Fee:           fixedpoint.NewFromFloat(0.08),
+		FeeCurrency:   "BNB",
+		FeeInQuote:    fixedpoint.NewFromInt(40),
+	})
+	assert.True(t, madeProfit)
+	assert.Equal(t, "9930", netProfit.String())
+	assert.Equal(t, "0.13", pos.TotalFee["BNB"].String())
+	assert.Equal(t, "70", pos.TotalFeeInQuote.String())
+}
+
+type testFeePriceSource map[string]fixedpoint.Value
+
+func (s testFeePriceSource) FeePrice(feeCurrency, quoteCurrency string, _ time.Time) (fixedpoint.Value, bool) {
+	price, ok := s[feeCurrency+quoteCurrency]
+	return price, ok
+}
+
+func TestFeeInQuote(t *testing.T) {
+	source := testFeePriceSource{"BNBUSDT": fixedpoint.NewFromInt(600)}
+	trade := Trade{
+		Price:    fixedpoint.NewFromInt(20000),
+		Quantity: fixedpoint.One,
+		Fee:      fixedpoint.NewFromFloat(0.001),
+	}
+
+	trade.FeeCurrency = "USDT"
+	fee, ok := FeeInQuote(trade, "BTC", "USDT", nil)
+	assert.True(t, ok)
+	assert.Equal(t, "0.001", fee.String())
+
+	trade.FeeCurrency = "BTC"
+	fee, ok = FeeInQuote(trade, "BTC", "USDT", nil)
+	assert.True(t, ok)
+	assert.Equal(t, "20", fee.String())
+
+	trade.FeeCurrency = "BNB"
+	_, ok = FeeInQuote(trade, "BTC", "USDT", nil)
+	assert.False(t, ok, "the fee can not be converted without the price source")
+
+	fee, ok = FeeInQuote(trade, "BTC", "USDT", source)
+	assert.True(t, ok)
+	assert.Equal(t, "0.6", fee.String())
+
+	trade.FeeCurrency = "MAX"
+	_, ok = FeeInQuote(trade, "BTC", "USDT", source)
+	assert.False(t, ok, "the fee currency price is not found")
+}
+
 func TestPosition(t *testing.T) {
 	var feeRate float64 = 0.05 * 0.01
 	feeRateValue := fixedpoint.NewFromFloat(feeRate)
@@ -325,3 +399,60 @@ func TestPosition_SetClosing(t *testing.T) {
 	ret = p.SetClosing(false)
 	assert.True(t, ret)
 }
+
+func TestFuturesPositionKey(t *testing.T) {
+	assert.Equal(t, "BTCUSDT", FuturesPositionKey("BTCUSDT", PositionSideBoth))
+	assert.Equal(t, "BTCUSDT", FuturesPositionKey("BTCUSDT", ""))
+	assert.Equal(t, "BTCUSDT:LONG", FuturesPositionKey("BTCUSDT", PositionSideLong))
+	assert.Equal(t, "BTCUSDT:SHORT", FuturesPositionKey("BTCUSDT", PositionSideShort))
+
+	positions := FuturesPositionMap{
+		"BTCUSDT:LONG":  {Symbol: "BTCUSDT", PositionSide: PositionSideLong},
+		"BTCUSDT:SHORT": {Symbol: "BTCUSDT", PositionSide: PositionSideShort},
+	}
+
+	position, ok := positions.Get("BTCUSDT", PositionSideShort)
+	assert.True(t, ok)
+	assert.Equal(t, PositionSideShort, position.PositionSide)
+
+	_, ok = positions.Get("BTCUSDT", PositionSideBoth)
+	assert.False(t, ok)
+}
+
+func TestPositionTranche_RMultiple(t *testing.T) {
+	tranche := PositionTranche{
+		Price:     fixedpoint.NewFromInt(100),
+		Quantity:  fixedpoint.One,
+		StopPrice: fixedpoint.NewFromInt(95),
+	}
+	assert.Equal(t, fixedpoint.NewFromInt(5), tranche.Risk())
+	assert.Equal(t, fixedpoint.NewFromInt(2), tranche.RMultiple(fixedpoint.NewFromInt(110), true))
+	assert.Equal(t, fixedpoint.NewFromInt(-1), tranche.RMultiple(fixedpoint.NewFromInt(95), true))
+
+	tranche.StopPrice = fixedpoint.NewFromInt(105)
+	assert.Equal(t, fixedpoint.NewFromInt(1), tranche.RMultiple(fixedpoint.NewFromInt(95), false))
+
+	tranche.StopPrice = fixedpoint.Zero
+	assert.Equal(t, fixedpoint.Zero, tranche.RMultiple(fixedpoint.NewFromInt(110), true))
+}
+
+func TestPosition_Tranches(t *testing.T) {
+	p := NewPosition("BTCUSDT", "BTC", "USDT")
+	p.AddTranche(PositionTranche{Price: fixedpoint.NewFromInt(100), Quantity: fixedpoint.One})
+	p.AddTranche(PositionTranche{Price: fixedpoint.NewFromInt(110), Quantity: fixedpoint.NewFromFloat(0.5)})
+
+	tranches := p.GetTranches()
+	assert.Len(t, tranches, 2)
+	assert.Equal(t, fixedpoint.One, tranches[0].InitialQuantity)
+
+	tranches[1].Quantity = fixedpoint.NewFromFloat(0.25)
+	tranches[1].TakeProfitSteps = 1
+	assert.NotEqual(t, tranches[1], p.Tranches[1], "the returned tranches should be a copy")
+	assert.True(t, p.UpdateTranche(1, tranches[1]))
+	assert.Equal(t, tranches[1], p.Tranches[1])
+	assert.Equal(t, fixedpoint.NewFromFloat(0.5), p.Tranches[1].InitialQuantity)
+	assert.False(t, p.UpdateTranche(2, tranches[1]))
+
+	p.ResetTranches()
+	assert.Empty(t, p.GetTranches())
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"time"
+
+	"github.com/c9s/bbgo/pkg/fixedpoint"
+)
+
+// PositionTranche is an entry of the scaled-in position
+type PositionTranche struct {
+	Time time.Time `json:"time"`
+
+	// Price is the entry price of the tranche
+	Price fixedpoint.Value `json:"price"`
+
+	// Quantity is the remaining quantity of the tranche
+	Quantity fixedpoint.Value `json:"quantity"`
+
+	// InitialQuantity is the quantity when the tranche is opened
+	InitialQuantity fixedpoint.Value `json:"initialQuantity"`
+
+	// StopPrice is the stop loss price when the tranche is opened, the distance to the entry price is the risk (1R)
+	StopPrice fixedpoint.Value `json:"stopPrice,omitempty"`
+
+	// TakeProfitSteps is the number of the partial take-profit steps executed on the tranche
+	TakeProfitSteps int `json:"takeProfitSteps,omitempty"`
+}
+
+// Risk returns the distance between the entry price and the stop price, zero if the stop price is not set
+func (t PositionTranche) Risk() fixedpoint.Value {
+	if t.StopPrice.IsZero() {
+		return fixedpoint.Zero
+	}
+
+	return t.Price.Sub(t.StopPrice).Abs()
+}
+
+// RMultiple returns the profit of the current price in the multiple of the risk, it's negative when the tranche is losing
+func (t PositionTranche) RMultiple(currentPrice fixedpoint.Value, long bool) fixedpoint.Value {
+	risk := t.Risk()
+	if risk.IsZero() {
+		return fixedpoint.Zero
+	}
+
+	if long {
+		return currentPrice.Sub(t.Price).Div(risk)
+	}
+
+	return t.Price.Sub(currentPrice).Div(risk)
+}
+
+func (p *Position) AddTranche(tranche PositionTranche) {
+	if tranche.InitialQuantity.IsZero() {
+		tranche.InitialQuantity = tranche.Quantity
+	}
+
+	p.Lock()
+	p.Tranches = append(p.Tranches, tranche)
+	p.Unlock()
+}
+
+// GetTranches returns a copy of the tranches
+func (p *Position) GetTranches() []PositionTranche {
+	p.Lock()
+	defer p.Unlock()
+
+	tranches := make([]PositionTranche, len(p.Tranches))
+	copy(tranches, p.Tranches)
+	return tranches
+}
+
+// UpdateTranche replaces the tranche at the index, it returns false if the index is out of range
+func (p *Position) UpdateTranche(index int, tranche PositionTranche) bool {
+	p.Lock()
+	defer p.Unlock()
+
+	if index < 0 || index >= len(p.Tranches) {
+		return false
+	}
+
+	p.Tranches[index] = tranche
+	return true
+}
+
+func (p *Position) ResetTranches() {
+	p.Lock()
+	p.Tranches = nil
+	p.Unlock()
+}
@@ -141,12 +141,24 @@ func (slice *PriceVolumeSlice) UnmarshalJSON(b []byte) error {
 //
 //  [["9000", "10"], ["9900", "10"], ... ]
 //
+// the object form marshaled from PriceVolumeSlice is also accepted, so that the marshaled slice can be unmarshaled back
+//
+//  [{"Price": "9000", "Volume": "10"}, ... ]
+//
 func ParsePriceVolumeSliceJSON(b []byte) (slice PriceVolumeSlice, err error) {
 	var as [][]fixedpoint.Value
 
 	err = json.Unmarshal(b, &as)
 	if err != nil {
-		return slice, err
+		var pvs []PriceVolume
+		if err2 := json.Unmarshal(b, &pvs); err2 != nil {
+			return slice, err
+		}
+
+		as = make([][]fixedpoint.Value, 0, len(pvs))
+		for _, pv := range pvs {
+			as = append(as, []fixedpoint.Value{pv.Price, pv.Volume})
+		}
 	}
 
 	for _, a := range as {
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"testing"
 
 	"github.com/c9s/bbgo/pkg/fixedpoint"
@@ -28,3 +29,20 @@ func TestPriceVolumeSlice_Remove(t *testing.T) {
 		assert.Equal(t, 2, len(slice), "with descending %v", descending)
 	}
 }
+
+func TestPriceVolumeSlice_UnmarshalJSON(t *testing.T) {
+	var slice PriceVolumeSlice
+	assert.NoError(t, json.Unmarshal([]byte(`[["9000", "10"], ["9900", "1.5"]]`), &slice))
+	assert.Equal(t, PriceVolumeSlice{
+		{Price: fixedpoint.NewFromInt(9000), Volume: fixedpoint.NewFromInt(10)},
+		{Price: fixedpoint.NewFromInt(9900), Volume: fixedpoint.NewFromFloat(1.5)},
+	}, slice)
+
+	// the marshaled slice can be unmarshaled back
+	data, err := json.Marshal(slice)
+	if assert.NoError(t, err) {
+		var slice2 PriceVolumeSlice
+		assert.NoError(t, json.Unmarshal(data, &slice2))
+		assert.Equal(t, slice, slice2)
+	}
+}
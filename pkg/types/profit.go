@@ -49,9 +49,11 @@ type Profit struct {
 
 	// FeeInUSD is the summed fee of this profit,
 	// you will need to convert the trade fee into USD since the fee currencies can be different.
+	// FeeInQuote is the trade fee converted into the quote currency at the trade time.
 	FeeInUSD    fixedpoint.Value `json:"feeInUSD" db:"fee_in_usd"`
 	Fee         fixedpoint.Value `json:"fee" db:"fee"`
 	FeeCurrency string           `json:"feeCurrency" db:"fee_currency"`
+	FeeInQuote  fixedpoint.Value `json:"feeInQuote,omitempty" db:"-"`
 	Exchange    ExchangeName     `json:"exchange" db:"exchange"`
 	IsMargin    bool             `json:"isMargin" db:"is_margin"`
 	IsFutures   bool             `json:"isFutures" db:"is_futures"`
@@ -157,13 +159,18 @@ type ProfitStats struct {
 	AccumulatedGrossProfit fixedpoint.Value `json:"accumulatedGrossProfit,omitempty"`
 	AccumulatedGrossLoss   fixedpoint.Value `json:"accumulatedGrossLoss,omitempty"`
 	AccumulatedVolume      fixedpoint.Value `json:"accumulatedVolume,omitempty"`
+	AccumulatedFee         fixedpoint.Value `json:"accumulatedFee,omitempty"`
 	AccumulatedSince       int64            `json:"accumulatedSince,omitempty"`
 
 	TodayPnL         fixedpoint.Value `json:"todayPnL,omitempty"`
 	TodayNetProfit   fixedpoint.Value `json:"todayNetProfit,omitempty"`
 	TodayGrossProfit fixedpoint.Value `json:"todayGrossProfit,omitempty"`
 	TodayGrossLoss   fixedpoint.Value `json:"todayGrossLoss,omitempty"`
+	TodayFee         fixedpoint.Value `json:"todayFee,omitempty"`
 	TodaySince       int64            `json:"todaySince,omitempty"`
+
+	// DustProceeds is the accumulated proceeds of the dust conversions, keyed by the currency of the proceeds
+	DustProceeds map[string]fixedpoint.Value `json:"dustProceeds,omitempty"`
 }
 
 func NewProfitStats(market Market) *ProfitStats {
@@ -225,12 +232,25 @@ func (s *ProfitStats) AddProfit(profit Profit) {
 	}
 }
 
+// AddDustProceeds credits the proceeds of converting the dust balances
+func (s *ProfitStats) AddDustProceeds(currency string, amount fixedpoint.Value) {
+	if s.DustProceeds == nil {
+		s.DustProceeds = make(map[string]fixedpoint.Value)
+	}
+
+	s.DustProceeds[currency] = s.DustProceeds[currency].Add(amount)
+}
+
 func (s *ProfitStats) AddTrade(trade Trade) {
 	if s.IsOver24Hours() {
 		s.ResetToday(trade.Time.Time())
 	}
 
 	s.AccumulatedVolume = s.AccumulatedVolume.Add(trade.Quantity)
+
+	// the fees are consolidated in the quote currency, only the converted fees are counted
+	s.AccumulatedFee = s.AccumulatedFee.Add(trade.FeeInQuote)
+	s.TodayFee = s.TodayFee.Add(trade.FeeInQuote)
 }
 
 // IsOver24Hours checks if the since time is over 24 hours
@@ -243,6 +263,7 @@ func (s *ProfitStats) ResetToday(t time.Time) {
 	s.TodayNetProfit = fixedpoint.Zero
 	s.TodayGrossProfit = fixedpoint.Zero
 	s.TodayGrossLoss = fixedpoint.Zero
+	s.TodayFee = fixedpoint.Zero
 
 	var beginningOfTheDay = BeginningOfTheDay(t.Local())
 	s.TodaySince = beginningOfTheDay.Unix()
@@ -339,6 +360,13 @@ func (s *ProfitStats) SlackAttachment() slack.Attachment {
 		})
 	}
 
+	if !s.AccumulatedFee.IsZero() {
+		fields = append(fields, slack.AttachmentField{
+			Title: "Accumulated Fee",
+			Value: s.AccumulatedFee.String() + " " + s.QuoteCurrency,
+		})
+	}
+
 	return slack.Attachment{
 		Color:  color,
 		Title:  title,
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// SessionEventType is the type of the recorded session event
+type SessionEventType string
+
+const (
+	SessionEventKLineClosed     SessionEventType = "kline"
+	SessionEventBookSnapshot    SessionEventType = "bookSnapshot"
+	SessionEventBookUpdate      SessionEventType = "bookUpdate"
+	SessionEventBookTicker      SessionEventType = "bookTicker"
+	SessionEventMarketTrade     SessionEventType = "marketTrade"
+	SessionEventOrderUpdate     SessionEventType = "order"
+	SessionEventTradeUpdate     SessionEventType = "trade"
+	SessionEventBalanceSnapshot SessionEventType = "balanceSnapshot"
+	SessionEventBalanceUpdate   SessionEventType = "balanceUpdate"
+	SessionEventDecision        SessionEventType = "decision"
+)
+
+// SessionEvent is one line of the session event log, the events are ordered by the sequence number
+type SessionEvent struct {
+	Seq     uint64           `json:"seq"`
+	Time    time.Time        `json:"time"`
+	Session string           `json:"session"`
+	Type    SessionEventType `json:"type"`
+	Payload json.RawMessage  `json:"payload"`
+}
+
+// StrategyDecisionAction is the action of the strategy decision
+type StrategyDecisionAction string
+
+const (
+	StrategyDecisionSubmit StrategyDecisionAction = "submit"
+	StrategyDecisionCancel StrategyDecisionAction = "cancel"
+	StrategyDecisionNote   StrategyDecisionAction = "note"
+)
+
+// StrategyDecision is the order action or the note made by a strategy instance
+type StrategyDecision struct {
+	Strategy           string                 `json:"strategy"`
+	StrategyInstanceID string                 `json:"strategyInstanceID"`
+	Symbol             string                 `json:"symbol"`
+	Action             StrategyDecisionAction `json:"action"`
+
+	// SubmitOrders are the submitted orders of the submit action
+	SubmitOrders []SubmitOrder `json:"submitOrders,omitempty"`
+
+	// Orders are the canceled orders of the cancel action, empty means all the active orders
+	Orders []Order `json:"orders,omitempty"`
+
+	// Message is the reason of the decision given by the strategy
+	Message string `json:"message,omitempty"`
+
+	// Fields are the values that the decision is made on, e.g., the indicator values
+	Fields map[string]interface{} `json:"fields,omitempty"`
+}
@@ -144,6 +144,16 @@ func (s *StandardStream) EmitAggTrade(trade Trade) {
 	}
 }
 
+func (s *StandardStream) OnLiquidation(cb func(info LiquidationInfo)) {
+	s.liquidationCallbacks = append(s.liquidationCallbacks, cb)
+}
+
+func (s *StandardStream) EmitLiquidation(info LiquidationInfo) {
+	for _, cb := range s.liquidationCallbacks {
+		cb(info)
+	}
+}
+
 func (s *StandardStream) OnFuturesPositionUpdate(cb func(futuresPositions FuturesPositionMap)) {
 	s.FuturesPositionUpdateCallbacks = append(s.FuturesPositionUpdateCallbacks, cb)
 }
@@ -193,6 +203,8 @@ type StandardStreamEventHub interface {
 
 	OnAggTrade(cb func(trade Trade))
 
+	OnLiquidation(cb func(info LiquidationInfo))
+
 	OnFuturesPositionUpdate(cb func(futuresPositions FuturesPositionMap))
 
 	OnFuturesPositionSnapshot(cb func(futuresPositions FuturesPositionMap))
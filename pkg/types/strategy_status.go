@@ -7,4 +7,7 @@ const (
 	StrategyStatusRunning StrategyStatus = "RUNNING"
 	StrategyStatusStopped StrategyStatus = "STOPPED"
 	StrategyStatusUnknown StrategyStatus = "UNKNOWN"
+
+	// StrategyStatusFailed is the status of the strategy instance that panicked
+	StrategyStatusFailed StrategyStatus = "FAILED"
 )
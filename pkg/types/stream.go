@@ -24,6 +24,11 @@ var defaultDialer = &websocket.Dialer{
 	ReadBufferSize:   4096,
 }
 
+// WebsocketDialer dials the websocket connection, *websocket.Dialer implements it
+type WebsocketDialer interface {
+	Dial(urlStr string, requestHeader http.Header) (*websocket.Conn, *http.Response, error)
+}
+
 type Stream interface {
 	StandardStreamEventHub
 
@@ -48,6 +53,9 @@ type StandardStream struct {
 
 	endpointCreator EndpointCreator
 
+	// dialer overrides the default dialer, e.g., the replay dialer of the tests
+	dialer WebsocketDialer
+
 	// Conn is the websocket connection
 	Conn *websocket.Conn
 
@@ -102,6 +110,9 @@ type StandardStream struct {
 
 	aggTradeCallbacks []func(trade Trade)
 
+	// liquidation callbacks of the futures market
+	liquidationCallbacks []func(info LiquidationInfo)
+
 	// Futures
 	FuturesPositionUpdateCallbacks []func(futuresPositions FuturesPositionMap)
 
@@ -124,6 +135,7 @@ type StandardStreamEmitter interface {
 	EmitBookSnapshot(SliceOrderBook)
 	EmitMarketTrade(Trade)
 	EmitAggTrade(Trade)
+	EmitLiquidation(LiquidationInfo)
 	EmitFuturesPositionUpdate(FuturesPositionMap)
 	EmitFuturesPositionSnapshot(FuturesPositionMap)
 }
@@ -143,6 +155,11 @@ func (s *StandardStream) GetPublicOnly() bool {
 	return s.PublicOnly
 }
 
+// SetDialer replaces the websocket dialer of the stream
+func (s *StandardStream) SetDialer(dialer WebsocketDialer) {
+	s.dialer = dialer
+}
+
 func (s *StandardStream) SetEndpointCreator(creator EndpointCreator) {
 	s.endpointCreator = creator
 }
@@ -365,7 +382,12 @@ func (s *StandardStream) Dial(ctx context.Context, args ...string) (*websocket.C
 		return nil, errors.New("can not dial, neither url nor endpoint creator is not defined, you should pass an url to Dial() or call SetEndpointCreator()")
 	}
 
-	conn, _, err := defaultDialer.Dial(url, nil)
+	var dialer WebsocketDialer = defaultDialer
+	if s.dialer != nil {
+		dialer = s.dialer
+	}
+
+	conn, _, err := dialer.Dial(url, nil)
 	if err != nil {
 		return nil, err
 	}
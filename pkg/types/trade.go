@@ -67,6 +67,10 @@ type Trade struct {
 	Fee         fixedpoint.Value `json:"fee" db:"fee"`
 	FeeCurrency string           `json:"feeCurrency" db:"fee_currency"`
 
+	// FeeInQuote is the fee converted into the quote currency at the trade time,
+	// it's set by the trade collector, zero means the fee is not converted.
+	FeeInQuote fixedpoint.Value `json:"feeInQuote,omitempty" db:"-"`
+
 	IsMargin   bool `json:"isMargin" db:"is_margin"`
 	IsFutures  bool `json:"isFutures" db:"is_futures"`
 	IsIsolated bool `json:"isIsolated" db:"is_isolated"`
@@ -141,6 +141,26 @@ func (s *IntervalProfitCollector) GetSortino() float64 {
 	return Sortino(Sub(s.Profits, 1.), 0., s.Profits.Length(), true, false)
 }
 
+// Get the max drawdown ratio of the compounded profits with the interval of profit collected.
+func (s *IntervalProfitCollector) GetMaxDrawdown() float64 {
+	if s.Profits == nil {
+		return 0
+	}
+
+	var equity, peak, maxDrawdown = 1., 1., 0.
+	for _, profit := range *s.Profits {
+		equity *= profit
+		if equity > peak {
+			peak = equity
+		}
+
+		if drawdown := (peak - equity) / peak; drawdown > maxDrawdown {
+			maxDrawdown = drawdown
+		}
+	}
+	return maxDrawdown
+}
+
 func (s *IntervalProfitCollector) GetOmega() float64 {
 	return Omega(Sub(s.Profits, 1.))
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/c9s/bbgo/pkg/datatype/floats"
 	"github.com/c9s/bbgo/pkg/fixedpoint"
 )
 
@@ -57,3 +58,8 @@ func TestTradeStats_consecutiveCounterAndAmount(t *testing.T) {
 	assert.Equal(t, "-200", stats.MaximumConsecutiveLoss.String())
 	assert.Equal(t, 2, stats.MaximumConsecutiveLosses)
 }
+
+func TestIntervalProfitCollector_GetMaxDrawdown(t *testing.T) {
+	collector := &IntervalProfitCollector{Profits: &floats.Slice{1., 1.1, 0.9, 0.9, 1.2}}
+	assert.InDelta(t, 0.19, collector.GetMaxDrawdown(), 1e-9)
+}
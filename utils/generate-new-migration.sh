@@ -2,3 +2,4 @@
 set -e
 rockhopper --config rockhopper_sqlite.yaml create --type sql $1
 rockhopper --config rockhopper_mysql.yaml create --type sql $1
+rockhopper --config rockhopper_postgres.yaml create --type sql $1